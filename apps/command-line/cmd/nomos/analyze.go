@@ -0,0 +1,149 @@
+// Package main implements the analyze command for the Nomos CLI.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+// analyzeCmd groups analyses of compiled configuration.
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze compiled configuration",
+	Long:  `Analyze compiled configuration for maintenance problems, such as keys no consumer reads.`,
+}
+
+// unusedFlags holds all flags for the analyze unused command
+var unusedFlags struct {
+	source  snapshotSource
+	schemas []string
+	json    bool
+	strict  bool
+}
+
+// unusedCmd represents the analyze unused command
+var unusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "Find keys no consumer reads and required keys that are missing",
+	Long: `Unused compiles .csl files (or reads a snapshot written by 'nomos build') and
+compares the keys against the schemas of the services that consume the
+configuration. It reports keys no consumer reads, so dead configuration can
+be removed, and keys a consumer requires that the configuration lacks.
+
+Consumer Schemas:
+  Pass one --consumer-schema per consumer, as JSON or YAML. A schema is
+  either a JSON Schema, of which properties, required, items, and
+  additionalProperties are used, or an allowlist: a list of key paths the
+  consumer reads, where a "*" segment matches any key:
+
+    ["app.name", "app.hosts", "db.users.*.role"]
+
+  A key is read when any consumer reads it. Schema nodes without properties
+  or items, and allowlisted paths, read everything beneath them. Keys not
+  listed in properties count as unread unless additionalProperties allows
+  them.
+
+Output:
+  Key paths use dots between map keys and [n] for list indices. A map no
+  consumer reads is reported once, not key by key. --json prints the report
+  as JSON.
+
+Examples:
+  # Check a fresh compile against two consumers
+  nomos analyze unused -p config/ --consumer-schema api.schema.json --consumer-schema jobs.yaml
+
+  # Fail CI when configuration is unused or incomplete
+  nomos analyze unused --from-snapshot build/config.json --consumer-schema api.schema.json --strict
+
+Exit Codes:
+  0 - Analysis completed (findings only fail with --strict)
+  1 - Findings with --strict, compilation failed, or a schema is invalid`,
+	Args: cobra.NoArgs,
+	RunE: unusedCommand,
+}
+
+func init() {
+	unusedFlags.source.addFlags(unusedCmd)
+	unusedCmd.Flags().StringArrayVar(&unusedFlags.schemas, "consumer-schema", nil, "JSON Schema or key path allowlist of a consumer (repeatable, required)")
+	_ = unusedCmd.MarkFlagRequired("consumer-schema") // Error only occurs if flag doesn't exist
+	unusedCmd.Flags().BoolVar(&unusedFlags.json, "json", false, "Print the report as JSON")
+	unusedCmd.Flags().BoolVar(&unusedFlags.strict, "strict", false, "Exit with code 1 when there are findings")
+	analyzeCmd.AddCommand(unusedCmd)
+}
+
+// unusedCommand executes the analyze unused subcommand.
+func unusedCommand(_ *cobra.Command, _ []string) error {
+	schemas := make([]*usage.Schema, 0, len(unusedFlags.schemas))
+	for _, path := range unusedFlags.schemas {
+		schema, err := usage.LoadSchema(path)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	snapshot, err := unusedFlags.source.load()
+	if err != nil {
+		return err
+	}
+
+	report := usage.Analyze(snapshot.Data, schemas)
+	if unusedFlags.json {
+		err = writeUsageJSON(os.Stdout, report)
+	} else {
+		err = writeUsageText(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if unusedFlags.strict && !report.Empty() {
+		return errors.New("configuration has unused or missing keys")
+	}
+	return nil
+}
+
+// writeUsageJSON prints report as indented JSON.
+func writeUsageJSON(w io.Writer, report usage.Report) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", encoded)
+	return err
+}
+
+// writeUsageText prints report as lists of key paths.
+func writeUsageText(w io.Writer, report usage.Report) error {
+	if report.Empty() {
+		_, err := fmt.Fprintln(w, "Every key is read by a consumer and no required key is missing.")
+		return err
+	}
+	if len(report.Unused) > 0 {
+		if _, err := fmt.Fprintf(w, "Unused keys (%d):\n", len(report.Unused)); err != nil {
+			return err
+		}
+		for _, path := range report.Unused {
+			if _, err := fmt.Fprintf(w, "  %s\n", path); err != nil {
+				return err
+			}
+		}
+	}
+	if len(report.Missing) > 0 {
+		if _, err := fmt.Fprintf(w, "Missing required keys (%d):\n", len(report.Missing)); err != nil {
+			return err
+		}
+		for _, m := range report.Missing {
+			if _, err := fmt.Fprintf(w, "  %s (required by %s)\n", m.Path, m.Schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// benchPhase is the timing of one build phase reported by --bench.
+type benchPhase struct {
+	name     string
+	duration time.Duration
+	bytes    int64 // bytes read or produced by the phase, 0 if not applicable
+}
+
+// benchReport collects build phase timings for --bench.
+type benchReport struct {
+	phases []benchPhase
+	keys   int
+}
+
+// add records a phase that took d and read or produced n bytes.
+func (r *benchReport) add(name string, d time.Duration, n int64) {
+	r.phases = append(r.phases, benchPhase{name: name, duration: d, bytes: n})
+}
+
+// Write prints the report as an aligned table with per-phase throughput.
+func (r *benchReport) Write(w io.Writer) {
+	var total time.Duration
+	fmt.Fprintf(w, "\nBenchmark (%d keys):\n", r.keys)
+	for _, p := range r.phases {
+		total += p.duration
+		line := fmt.Sprintf("  %-10s %10s  %16s", p.name, p.duration.Round(time.Microsecond), rate(float64(r.keys), p.duration, "keys/s"))
+		if p.bytes > 0 {
+			line += fmt.Sprintf("  %12s", rate(float64(p.bytes)/(1<<20), p.duration, "MB/s"))
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "  %-10s %10s  %16s\n", "total", total.Round(time.Microsecond), rate(float64(r.keys), total, "keys/s"))
+}
+
+// rate formats amount per second of d.
+func rate(amount float64, d time.Duration, unit string) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f %s", amount/d.Seconds(), unit)
+}
+
+// countLeafKeys returns the number of scalar values in data, counting list
+// elements individually.
+func countLeafKeys(data any) int {
+	switch v := data.(type) {
+	case map[string]any:
+		n := 0
+		for _, child := range v {
+			n += countLeafKeys(child)
+		}
+		return n
+	case []any:
+		n := 0
+		for _, child := range v {
+			n += countLeafKeys(child)
+		}
+		return n
+	default:
+		return 1
+	}
+}
+
+// inputSize returns the combined size of files, ignoring files that cannot
+// be stat'ed.
+func inputSize(files []string) int64 {
+	var n int64
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			n += info.Size()
+		}
+	}
+	return n
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountLeafKeys(t *testing.T) {
+	data := map[string]any{
+		"app": map[string]any{
+			"name": "demo",
+			"tags": []any{"a", "b", map[string]any{"c": 1}},
+		},
+		"empty": map[string]any{},
+		"port":  8080,
+	}
+
+	if got := countLeafKeys(data); got != 5 {
+		t.Errorf("countLeafKeys() = %d, want 5", got)
+	}
+}
+
+func TestBenchReport_Write(t *testing.T) {
+	report := benchReport{keys: 1000}
+	report.add("compile", 500*time.Millisecond, 1<<20)
+	report.add("serialize", 500*time.Millisecond, 0)
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Benchmark (1000 keys):",
+		"compile",
+		"2000.0 keys/s",
+		"2.0 MB/s",
+		"serialize",
+		"total",
+		"1000.0 keys/s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "MB/s") != 1 {
+		t.Errorf("expected MB/s only for phases with a byte count:\n%s", out)
+	}
+}
@@ -0,0 +1,67 @@
+// Package main implements the browse command for the Nomos CLI.
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/browse"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// browseFlags holds all flags for the browse command
+var browseFlags struct {
+	source snapshotSource
+}
+
+// browseCmd represents the browse command
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Explore compiled configuration in an interactive terminal UI",
+	Long: `Browse compiles .csl files (or reads a snapshot written by 'nomos build') and
+opens a terminal UI for exploring the result as a collapsible tree.
+
+The pane below the tree shows the key path, type, and value under the
+cursor, and the source file or provider its top-level key came from.
+Provenance is only available when compiling, or for snapshots written with
+--include-metadata. Secret values are masked in the tree.
+
+Keys:
+  ↑/↓, j/k       Move
+  →/l, enter     Expand
+  ←/h            Collapse, or move to the parent
+  /              Search key paths (enter to jump, n/N for next/previous)
+  y              Copy the value (strings raw, maps and lists as JSON)
+  Y              Copy the key path
+  q, esc         Quit
+
+Copying uses the OSC 52 terminal escape, which most terminals (including
+over SSH and inside tmux) forward to the local clipboard.
+
+Examples:
+  # Explore a fresh compile
+  nomos browse -p config/
+
+  # Explore a snapshot written earlier, with provenance
+  nomos browse --from-snapshot build/config.json`,
+	Args: cobra.NoArgs,
+	RunE: browseCommand,
+}
+
+func init() {
+	browseFlags.source.addFlags(browseCmd)
+}
+
+// browseCommand executes the browse subcommand.
+func browseCommand(_ *cobra.Command, _ []string) error {
+	if !ui.IsTerminal(os.Stdin) || !ui.IsTerminal(os.Stdout) {
+		return errors.New("browse needs an interactive terminal; use 'nomos get' in scripts")
+	}
+
+	snapshot, err := browseFlags.source.load()
+	if err != nil {
+		return err
+	}
+	return browse.Run(snapshot, browse.Options{})
+}
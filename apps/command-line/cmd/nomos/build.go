@@ -2,14 +2,22 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/history"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/listorder"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
 	"github.com/autonomous-bits/nomos/libs/compiler"
@@ -23,15 +31,50 @@ var buildFlags struct {
 	format                 string
 	out                    string
 	vars                   []string
+	sets                   []string
+	varFiles               []string
+	providerConfigs        []string
+	stdinConfig            bool
+	profiles               []string
 	strict                 bool
 	allowMissingProvider   bool
 	timeoutPerProvider     string
 	maxConcurrentProviders int
+	fetchRetries           int
+	partialFailure         string
 	verbose                bool
 	forceProviders         bool
+	providerMirror         string
+	allowLatest            bool
+	allowPrerelease        bool
+	allowYanked            bool
+	allowArchFallback      bool
+	maxDownloadRate        int64
+	maxDownloadBytes       int64
 	dryRun                 bool
 	includeMetadata        bool
 	encryptionKey          string
+	suppressWarnings       []string
+	sourceMap              string
+	policies               []string
+	typeCoercion           string
+	maxSnapshotBytes       int64
+	summaryJSON            string
+	bench                  bool
+	keyOrder               string
+	comments               bool
+	json                   jsonFormatFlags
+	files                  outputFileFlags
+	splitDepth             int
+	template               string
+	checksums              bool
+	recordProviders        string
+	replayProviders        string
+	debugDump              string
+	reproducible           bool
+	history                bool
+	at                     string
+	remote                 string
 }
 
 // buildCmd represents the build command
@@ -53,13 +96,266 @@ Provider Management:
   - Providers are automatically discovered and downloaded during build
   - Cached providers are reused for speed (SHA256-verified)
   - Use --force-providers to force re-download of all providers
+  - Use --provider-mirror <dir> to install offline from 'nomos providers mirror'
+  - Versions 'latest' and 'prerelease' require --allow-latest / --allow-prerelease;
+    the resolved release is pinned in the lockfile until --force-providers
+  - Deprecated provider releases install with a warning; yanked releases are
+    refused unless --allow-yanked
+  - Use --allow-arch-fallback on Apple Silicon to install darwin/amd64
+    providers, run by Rosetta, when a release has no darwin/arm64 asset;
+    the lockfile records the fallback as asset_arch
+  - Use --max-download-rate and --max-download-bytes to cap the bandwidth
+    and total bytes of all provider downloads in the build
   - Use --dry-run to preview provider operations without executing
   - Use --allow-missing-provider to tolerate missing providers (non-deterministic)
+  - Use --fetch-retries to retry provider fetches that fail with transient
+    errors (provider unavailable, timeouts, rate limits), with backoff
+  - Use --partial-failure collect-all to report every failed reference in
+    one run instead of stopping at the first, or best-effort to build
+    anyway with null placeholders reported as warnings
 
 Output Formats:
   json   - Canonical JSON with sorted keys (default)
   yaml   - YAML 1.2 format for Kubernetes, Ansible, Docker Compose
   tfvars - Terraform .tfvars format (HCL syntax)
+  template - Rendered through the Go text/template given by --template
+  custom:<name> - Custom serializer declared under serializers in
+           .nomos/config.yaml, or a nomos-serializer-<name> program on PATH.
+           Subprocess serializers read the JSON snapshot on stdin and
+           write the formatted output to stdout.
+
+Warning Suppression:
+  Warnings carry stable codes (e.g., W001) that can be silenced:
+    - Inline, for one line:       key: @alias:path  # nomos:ignore W001
+    - Inline, for a whole file:   # nomos:ignore-file W001
+    - Per project:                warnings.suppress in .nomos/config.yaml
+    - Per invocation:             --suppress-warning W001
+
+Policies:
+  Policy files hold CEL rules evaluated against the compiled data, bound to
+  the variable "data". Violations are reported as errors (exit code 1) or,
+  with severity: warning, as W003 warnings:
+
+    policies:
+      - name: prod-db-ssl
+        expr: data.prod.database.ssl == true
+        message: prod databases must have ssl=true
+
+  Pass files with --policy, or list them under policies in .nomos/config.yaml.
+
+Overrides:
+  Use --set and --var-file to overlay values on the compiled snapshot without
+  editing .csl files. Var files are YAML (or JSON) maps applied in order, then
+  each --set key.path=value; nested maps merge and other values replace:
+
+    nomos build -p config.csl --var-file prod.yaml --set app.replicas=5
+
+  --set values are strings; combine with --type-coercion to convert them.
+  Overridden keys are attributed to cli-override in metadata provenance and
+  source maps.
+
+  Use --provider-config and --stdin-config to replace source declaration
+  configuration before providers start, e.g. to point the file provider at
+  another directory in CI. --stdin-config reads a JSON object keyed by
+  source alias from stdin; each --provider-config alias=key=value is applied
+  after it:
+
+    echo '{"files": {"directory": "./ci"}}' | nomos build -p config.csl --stdin-config
+    nomos build -p config.csl --provider-config files=directory=./ci
+
+  The overrides are recorded in the provider_config_overrides metadata field.
+
+Profiles:
+  A top-level profile section holds small per-profile variations, merged
+  over the rest of the data when selected with --profile. Profiles apply in
+  order, later ones winning; profiles not selected are dropped before any
+  provider fetch:
+
+    app:
+      replicas: 1
+
+    profile:
+      prod:
+        app:
+          replicas: 3
+
+    nomos build -p config.csl --profile prod
+
+  Selecting a profile no file declares is an error. The selection is
+  recorded in the profiles metadata field.
+
+Type Coercion:
+  Scalar values compile to strings, so "port: 8080" becomes "8080" in every
+  format. Use --type-coercion to convert numeric and boolean strings:
+    off     - keep strings as strings (default)
+    strict  - convert canonical forms only: 8080, -1, 3.14, true, false
+    lenient - also convert 007, +1, 1e3, yes/no, on/off
+  Set a project default with type_coercion in .nomos/config.yaml. The policy
+  in effect is recorded as type_coercion in --include-metadata output.
+
+Key Order:
+  Map keys are sorted alphabetically by default. Use --key-order to choose
+  another order for json, yaml, and tfvars output:
+    alphabetical    - sort keys (default)
+    source          - keys in the order the .csl files define them
+    priority:a,b,c  - the named keys first, in that order, then the rest
+                      alphabetically (names match at every depth)
+  Set a default per format with key_order in .nomos/config.yaml:
+
+    key_order:
+      yaml: priority:apiVersion,kind,metadata
+      json: source
+
+  Metadata keys are always sorted.
+
+JSON Formatting:
+  JSON is indented by two spaces, with <, > and & written as-is. For output
+  that must match another tool byte for byte:
+    --json-indent 4|tab        indentation of each level (1-8 spaces or tab)
+    --json-minify              no whitespace between tokens
+    --json-trailing-newline    end with a newline; =false for none (default:
+                               newline on stdout, none in files)
+    --json-escape-html         escape <, > and & as \u003c, \u003e and \u0026
+
+Comments:
+  With --comments, yaml output keeps the comment lines written directly
+  above each .csl key, so generated files document themselves:
+
+    # Listen port           →   # Listen port
+    port: 8080                  port: "8080"
+
+  A blank line detaches a comment from the key below it. Keys produced by a
+  reference carry the comment of the key holding the reference only.
+
+Templates:
+  --format template --template <file> renders the compiled data through a
+  Go text/template, for formats such as nginx.conf or ini files. The
+  template's dot is the data (with --include-metadata, .data and .metadata);
+  missing keys are errors. Besides the text/template built-ins it can call:
+    get "key.path"   value at a key path, as 'nomos get' resolves it
+    indent n s       s with every line indented by n spaces
+    toJson v         v as compact JSON
+    quote v          v as a double-quoted string
+
+    {{ range $name, $u := .upstreams }}upstream {{ $name }} {
+    {{ indent 2 (printf "server %s;" $u.host) }}
+    }
+    {{ end }}
+
+Source Maps:
+  Use --source-map <file> to write a JSON source map alongside the output.
+  It maps every output key path (e.g., app.server.port) to the file, line,
+  and column that defined it, plus any references that contributed its value
+  and the comment above its definition.
+
+Benchmarking:
+  Use --bench to print per-phase timings and throughput to stderr after the
+  build: compile (parsing, reference resolution, and merging), serialize,
+  and write. Throughput is reported in leaf keys per second and MB/s:
+
+    Benchmark (100000 keys):
+      compile       312.4ms   320102.4 keys/s     17.9 MB/s
+      serialize        61ms  1639344.3 keys/s     42.6 MB/s
+      write           2.1ms  47619047.6 keys/s   1238.1 MB/s
+      total         375.5ms   266311.6 keys/s
+
+  Compile MB/s is measured against the combined size of the .csl inputs;
+  serialize and write MB/s against the output size.
+
+Large Snapshots:
+  JSON and YAML output is streamed to --out (or stdout) as it is encoded
+  rather than built in memory first, so large snapshots need roughly the
+  memory of the compiled data alone. --bench and the tfvars and custom
+  formats use the buffered path.
+
+  Use --max-snapshot-bytes to fail the build when the compiled data would
+  exceed a size budget (measured as compact JSON), before any output is
+  written:
+
+    nomos build -p config.csl --max-snapshot-bytes 268435456
+
+Output Files:
+  Every output file, including --source-map, is written to a temporary file
+  in the destination directory, synced to disk, and renamed into place, so a
+  build that fails or dies mid-write never leaves a truncated file. To
+  protect existing files:
+    --no-clobber   fail instead of replacing an existing file
+    --backup       copy an existing file to <file>.<UTC timestamp>.bak
+                   (e.g. out.json.20250101T120000Z.bak) before replacing it
+
+  --checksums writes outputs.sha256 beside the output (in the directory of
+  a split build), listing the SHA-256 of every file written, source map
+  included, plus the content hash of the compiled data. Verify with:
+
+    cd build && sha256sum -c outputs.sha256
+
+Recording Providers:
+  --record-providers <dir> saves every provider response of the build (one
+  JSON file per source alias) so the build can be repeated offline:
+
+    nomos build -p config.csl --record-providers testdata/providers
+    nomos build -p config.csl --replay-providers testdata/providers
+
+  A replayed build installs and starts no providers; a source or path that
+  is not in the recording fails the build. Recordings hold fetched values,
+  secrets included, in plain text, so keep them out of version control
+  unless the data is safe to share.
+
+Debug Dumps:
+  --debug-dump <dir> writes the intermediate state of the build for bug
+  reports, even when the build fails:
+
+    manifest.json        format version and input files
+    ast/NNN-<file>.json  parsed AST of each input file
+    references.json      each reference, the provider that served it, and
+                         its resolved value
+    merges.json          each key set more than once (by a later file, a
+                         spread, a repeated key, --set, or --var-file) and whether the
+                         values merged or the later one replaced the other
+
+  Secrets are redacted, but other resolved values are included; review the
+  dump before sharing it.
+
+Split Output:
+  Use --split-depth N with --out <dir> to write one file per map N levels
+  deep instead of a single file, named after its keys:
+
+    nomos build -p config.csl --format yaml --split-depth 1 -o out
+    # out/app.yaml, out/database.yaml, out/index.json
+
+  With --split-depth 2, services.api is written to out/services/api.yaml.
+  Every value down to that depth must be a map. index.json lists each
+  document's key, path, media type, and SHA-256; files from earlier builds
+  are left in place but not listed.
+
+Git Revisions:
+  --at <revision> compiles the files as of a git branch, tag, or commit,
+  read from git's object database; the working tree, index, and HEAD are
+  never touched. --path is taken relative to the same directory in the
+  revision. Var files, policies, and .nomos/config.yaml come from the
+  working tree. 'nomos diff --from-ref' compares a revision with the
+  working tree.
+
+Remote Compilation:
+  --remote <addr> sends the build to a nomosd server, which compiles it
+  with its own providers, credentials, and policies and keeps provider
+  processes running between builds. The .csl files beneath the project
+  root are sent with the var files and overrides of the build; with --at,
+  only the revision is sent and the server compiles it from its own
+  repository. Output is written locally as usual. Set NOMOS_REMOTE_TOKEN
+  when the server requires a token.
+
+    nomos build -p config/ --remote nomosd.internal:7420 -o out.json
+
+  Flags that need local providers or files the server cannot see, such as
+  --policy, --encryption-key, --provider-config, --record-providers, and
+  --debug-dump, cannot be combined with --remote.
+
+Build History:
+  --history records the build in .nomos/history once its output is written,
+  for later inspection with 'nomos history list|show|diff'. Set
+  history.enabled in .nomos/config.yaml to record every successful build;
+  max_entries and max_age there limit how many builds are kept.
 
 Metadata Control:
   By default, output contains only configuration data (clean, minimal).
@@ -75,6 +371,13 @@ Metadata Control:
   With --include-metadata:
     {"data": {"app": "example", "env": "prod"}, "metadata": {...}}
 
+  The metadata timestamps normally record when the build ran. When the
+  SOURCE_DATE_EPOCH environment variable is set they are fixed at that
+  time instead, and --reproducible fixes them at the Unix epoch when it is
+  unset, so identical builds write byte-identical output:
+
+    SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) nomos build -p config.csl --include-metadata
+
 Examples:
   # Compile to JSON (default)
   nomos build -p config.csl -o output.json
@@ -111,40 +414,121 @@ func init() {
 	// Required flags
 	buildCmd.Flags().StringVarP(&buildFlags.path, "path", "p", "", "Path to .csl file or directory (required)")
 	_ = buildCmd.MarkFlagRequired("path") // Error only occurs if flag doesn't exist
+	buildCmd.Flags().StringVar(&buildFlags.at, "at", "", "Compile the files as of this git revision (branch, tag, or commit) instead of the working tree")
+	buildCmd.Flags().StringVar(&buildFlags.remote, "remote", "", "Compile on the nomosd server at this address (host:port or URL) instead of locally")
 
 	// Output flags
-	buildCmd.Flags().StringVarP(&buildFlags.format, "format", "f", "json", "Output format: json, yaml, or tfvars")
+	buildCmd.Flags().StringVarP(&buildFlags.format, "format", "f", "json", "Output format: json, yaml, tfvars, template, or custom:<name>")
 	buildCmd.Flags().StringVarP(&buildFlags.out, "out", "o", "", "Output file (default: stdout)")
 
 	// Configuration flags
 	buildCmd.Flags().StringSliceVar(&buildFlags.vars, "var", nil, "Set variable: key=value (repeatable)")
+	buildCmd.Flags().StringArrayVar(&buildFlags.sets, "set", nil, "Override a compiled value: key.path=value (repeatable)")
+	buildCmd.Flags().StringSliceVar(&buildFlags.varFiles, "var-file", nil, "YAML or JSON file of values overlaid on the compiled snapshot (repeatable)")
+	buildCmd.Flags().StringArrayVar(&buildFlags.providerConfigs, "provider-config", nil, "Override source declaration config: alias=key=value (repeatable)")
+	buildCmd.Flags().BoolVar(&buildFlags.stdinConfig, "stdin-config", false, "Read source declaration config overrides from stdin as JSON keyed by alias")
+	buildCmd.Flags().StringSliceVar(&buildFlags.profiles, "profile", nil, "Merge a profile declared under profile: into the data (repeatable, later wins)")
 	buildCmd.Flags().BoolVar(&buildFlags.strict, "strict", false, "Treat warnings as errors")
+	buildCmd.Flags().StringSliceVar(&buildFlags.suppressWarnings, "suppress-warning", nil, "Suppress warning code, e.g. W001 (repeatable)")
+	buildCmd.Flags().StringSliceVar(&buildFlags.policies, "policy", nil, "Policy file evaluated against the compiled data (repeatable)")
+	buildCmd.Flags().StringVar(&buildFlags.typeCoercion, "type-coercion", "", "Convert numeric and boolean strings: strict, lenient, or off (default off)")
 
 	// Provider flags
 	buildCmd.Flags().BoolVar(&buildFlags.allowMissingProvider, "allow-missing-provider", false, "Allow compilation with missing providers")
 	buildCmd.Flags().StringVar(&buildFlags.timeoutPerProvider, "timeout-per-provider", "30s", "Timeout for provider operations (e.g., 5s, 1m)")
 	buildCmd.Flags().IntVar(&buildFlags.maxConcurrentProviders, "max-concurrent-providers", 4, "Max concurrent provider operations")
+	buildCmd.Flags().IntVar(&buildFlags.fetchRetries, "fetch-retries", 0, "Retry provider fetches failing with transient errors this many times, with backoff")
+	buildCmd.Flags().StringVar(&buildFlags.partialFailure, "partial-failure", "", "How failed references affect the build: fail-fast, collect-all, or best-effort (default fail-fast)")
 	buildCmd.Flags().BoolVar(&buildFlags.forceProviders, "force-providers", false, "Force re-download of all providers")
+	buildCmd.Flags().BoolVar(&buildFlags.allowLatest, "allow-latest", false, "Resolve providers declared with version 'latest' and pin the result in the lockfile")
+	buildCmd.Flags().BoolVar(&buildFlags.allowPrerelease, "allow-prerelease", false, "Resolve providers declared with version 'prerelease' and pin the result in the lockfile")
+	buildCmd.Flags().BoolVar(&buildFlags.allowYanked, "allow-yanked", false, "Install provider releases their authors have yanked")
+	buildCmd.Flags().Int64Var(&buildFlags.maxDownloadRate, "max-download-rate", 0, "Limit provider downloads to this many bytes per second in total (0 = no limit)")
+	buildCmd.Flags().Int64Var(&buildFlags.maxDownloadBytes, "max-download-bytes", 0, "Fail when provider downloads exceed this many bytes in total (0 = no limit)")
+	buildCmd.Flags().BoolVar(&buildFlags.allowArchFallback, "allow-arch-fallback", false, "Install darwin/amd64 providers on Apple Silicon when a release has no darwin/arm64 asset")
+	buildCmd.Flags().StringVar(&buildFlags.providerMirror, "provider-mirror", "", "Install providers from a directory written by 'nomos providers mirror' instead of GitHub")
 	buildCmd.Flags().BoolVar(&buildFlags.dryRun, "dry-run", false, "Preview provider operations without executing")
+	buildCmd.Flags().StringVar(&buildFlags.recordProviders, "record-providers", "", "Record every provider response into this directory")
+	buildCmd.Flags().StringVar(&buildFlags.replayProviders, "replay-providers", "", "Serve provider responses from a directory written by --record-providers instead of running providers")
+	buildCmd.MarkFlagsMutuallyExclusive("record-providers", "replay-providers")
+	buildCmd.Flags().StringVar(&buildFlags.debugDump, "debug-dump", "", "Write parsed ASTs, resolved references, and merge decisions into this directory for bug reports")
 
 	// Output flags
 	buildCmd.Flags().BoolVar(&buildFlags.includeMetadata, "include-metadata", false, "Include compilation metadata in output (timestamps, source files, provenance)")
+	buildCmd.Flags().BoolVar(&buildFlags.reproducible, "reproducible", false, "Fix metadata timestamps at SOURCE_DATE_EPOCH, or the Unix epoch if unset, so identical builds are byte-identical")
+	buildCmd.Flags().StringVar(&buildFlags.sourceMap, "source-map", "", "Write a JSON source map of output keys to the given file")
+	buildCmd.Flags().StringVar(&buildFlags.keyOrder, "key-order", "", "Map key order: alphabetical, source, or priority:<key>,<key>... (default alphabetical)")
+	buildCmd.Flags().BoolVar(&buildFlags.comments, "comments", false, "Write .csl comments above their keys in yaml output")
+	buildCmd.Flags().StringVar(&buildFlags.template, "template", "", "Go text/template file rendered by --format template")
+	buildFlags.json.addFlags(buildCmd)
+	buildFlags.files.addFlags(buildCmd)
+	buildCmd.Flags().BoolVar(&buildFlags.history, "history", false, "Record this build in .nomos/history even when history is not enabled in .nomos/config.yaml")
+	buildCmd.Flags().BoolVar(&buildFlags.checksums, "checksums", false, "Write an outputs.sha256 manifest of the written files beside the output")
+	buildCmd.Flags().IntVar(&buildFlags.splitDepth, "split-depth", 0, "Write one file per map at this depth (1 = top-level keys) into the --out directory, with an index.json manifest")
+	buildCmd.Flags().StringVar(&buildFlags.summaryJSON, "summary-json", "", "Write the build summary (files, providers, references, warnings, artifacts, duration) as JSON to this file, also for failed builds")
+	buildCmd.Flags().Int64Var(&buildFlags.maxSnapshotBytes, "max-snapshot-bytes", 0, "Fail when compiled data exceeds this many bytes as compact JSON (0 = no limit)")
 
-	// Debug flags
 	// Debug flags
 	buildCmd.Flags().BoolVarP(&buildFlags.verbose, "verbose", "v", false, "Enable verbose output")
+	buildCmd.Flags().BoolVar(&buildFlags.bench, "bench", false, "Print per-phase timings and throughput to stderr")
 
 	// Encryption flags
 	buildCmd.Flags().StringVar(&buildFlags.encryptionKey, "encryption-key", "", "Path to encryption key file (generated by 'nomos keys generate')")
 }
 
 // buildCommand executes the build subcommand.
-func buildCommand(_ *cobra.Command, _ []string) error {
+func buildCommand(cmd *cobra.Command, _ []string) (err error) {
 	// Validate flags
 	if buildFlags.maxConcurrentProviders < 0 {
 		return fmt.Errorf("max-concurrent-providers must be non-negative (got %d)", buildFlags.maxConcurrentProviders)
 	}
 
+	// Report the build once everything, including deferred history and
+	// checksums, is written
+	summary := newBuildSummary(time.Now())
+	defer func() {
+		if buildFlags.dryRun {
+			return
+		}
+		summary.finish(time.Now(), err)
+		if err == nil && !globalFlags.quiet {
+			summary.Write(os.Stderr)
+		}
+		if buildFlags.summaryJSON != "" {
+			if writeErr := summary.writeJSON(buildFlags.summaryJSON); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}
+	}()
+
+	if buildFlags.remote != "" {
+		for _, name := range remoteIncompatibleFlags {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s cannot be used with --remote", name)
+			}
+		}
+	}
+
+	// Compile the files of a git revision, extracted from the object
+	// database, in place of the working tree. A remote build sends the
+	// revision to the server instead.
+	path, root := buildFlags.path, projectRoot
+	if buildFlags.at != "" && buildFlags.remote == "" {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		tree, err := openRevision(ctx, buildFlags.at)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tree.Close() }()
+		if path, err = tree.Path(projectRoot, buildFlags.path); err != nil {
+			return err
+		}
+		root = tree.Dir
+	}
+
 	// Load encryption key if provided
 	var encryptionKey []byte
 	if buildFlags.encryptionKey != "" {
@@ -155,15 +539,40 @@ func buildCommand(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	// Read provider config overrides from stdin if requested
+	var providerConfigJSON []byte
+	if buildFlags.stdinConfig {
+		var err error
+		providerConfigJSON, err = io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read provider config from stdin: %w", err)
+		}
+	}
+
+	// Load project-level settings (.nomos/config.yaml)
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+
 	// Phase 0: Provider Management (before compilation)
 	// Convert build flags to provider options
 	providerFlags := providercmd.BuildFlags{
-		Path:                   buildFlags.path,
+		Path:                   path,
 		ForceProviders:         buildFlags.forceProviders,
 		DryRun:                 buildFlags.dryRun,
 		TimeoutPerProvider:     buildFlags.timeoutPerProvider,
 		MaxConcurrentProviders: buildFlags.maxConcurrentProviders,
 		AllowMissingProvider:   buildFlags.allowMissingProvider,
+		ProviderMirror:         buildFlags.providerMirror,
+		AllowLatest:            buildFlags.allowLatest,
+		AllowPrerelease:        buildFlags.allowPrerelease,
+		AllowYanked:            buildFlags.allowYanked,
+		AllowArchFallback:      buildFlags.allowArchFallback,
+		MaxDownloadRate:        buildFlags.maxDownloadRate,
+		MaxDownloadBytes:       buildFlags.maxDownloadBytes,
+		CredentialHelper:       projectCfg.CredentialHelper,
+		TrustedProviders:       projectCfg.TrustedProviders,
 	}
 
 	providerOpts, err := providercmd.NewProviderOptionsFromBuildFlags(providerFlags)
@@ -171,15 +580,23 @@ func buildCommand(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("invalid provider options: %w", err)
 	}
 
-	// Ensure providers are available (discover, download, validate)
-	providerSummary, err := providercmd.EnsureProviders(providerOpts)
-	if err != nil {
-		return fmt.Errorf("provider management failed: %w", err)
-	}
+	// Ensure providers are available (discover, download, validate). A
+	// replayed build never starts a provider, so none need installing, and
+	// a remote build runs the server's providers. Providers of forbidden
+	// source declarations are not downloaded; compiling reports the
+	// declarations as located errors.
+	permitted := compiler.CheckProviderPermissions(context.Background(), path, projectCfg.ProviderPermissions) == nil
+	if buildFlags.replayProviders == "" && buildFlags.remote == "" && permitted {
+		providerSummary, err := providercmd.EnsureProviders(providerOpts)
+		if err != nil {
+			return fmt.Errorf("provider management failed: %w", err)
+		}
 
-	// Print provider summary unless quiet
-	if !globalFlags.quiet && providerSummary != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", providerSummary.String())
+		// Print provider summary unless quiet
+		if !globalFlags.quiet && providerSummary != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", providerSummary.String())
+		}
+		summary.setProviders(providerSummary)
 	}
 
 	// If dry-run mode, exit successfully after showing provider summary
@@ -187,27 +604,45 @@ func buildCommand(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	// Create provider registries (supports external providers via lockfile)
-	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
-
-	// Build compiler options
-	opts, err := options.BuildOptions(options.BuildParams{
-		Path:                   buildFlags.path,
-		Vars:                   buildFlags.vars,
-		TimeoutPerProvider:     buildFlags.timeoutPerProvider,
-		MaxConcurrentProviders: buildFlags.maxConcurrentProviders,
-		AllowMissingProvider:   buildFlags.allowMissingProvider,
-		ProviderRegistry:       providerRegistry,
-		ProviderTypeRegistry:   providerTypeRegistry,
-		EncryptionKey:          encryptionKey,
-	})
+	keyOrder, err := keyOrderFor(buildFlags.format, buildFlags.keyOrder, projectCfg)
+	if err != nil {
+		return err
+	}
+	if buildFlags.comments && serialize.OutputFormat(strings.ToLower(buildFlags.format)) != serialize.FormatYAML {
+		return fmt.Errorf("--comments applies to yaml output, not %s", buildFlags.format)
+	}
+	jsonFormat, err := buildFlags.json.jsonFormat(cmd, buildFlags.format, buildFlags.out)
+	if err != nil {
+		return err
+	}
+	if err := validateSplitFlags(); err != nil {
+		return err
+	}
+	if buildFlags.checksums && buildFlags.out == "" {
+		return fmt.Errorf("--checksums needs --out")
+	}
+	tmpl, err := loadTemplate(buildFlags.format, buildFlags.template)
+	if err != nil {
+		return err
+	}
+	serializeOpts := serialize.Options{KeyOrder: keyOrder, Comments: buildFlags.comments, JSON: jsonFormat, Template: tmpl}
+
+	// Call compiler, here or on the --remote server
+	var bench benchReport
+	sourceMap := buildFlags.sourceMap != "" || keyOrder.Policy == serialize.KeyOrderSource || buildFlags.comments
+	var result compiler.CompilationResult
+	var diags []compiler.Diagnostic
+	if buildFlags.remote != "" {
+		result, diags, err = compileRemote(cmd.Context(), path, sourceMap, projectCfg, &bench)
+	} else {
+		result, err = compileLocal(path, root, sourceMap, projectCfg, encryptionKey, providerConfigJSON, &bench)
+		diags = result.Diagnostics()
+	}
 	if err != nil {
-		return fmt.Errorf("invalid options: %w", err)
+		return err
 	}
 
-	// Call compiler
-	ctx := context.Background()
-	result := compiler.Compile(ctx, opts)
+	summary.setResult(result, buildFlags.remote != "")
 
 	snapshot := result.Snapshot
 	var compileErr error
@@ -215,22 +650,13 @@ func buildCommand(_ *cobra.Command, _ []string) error {
 		compileErr = result.Error()
 	}
 
-	// Create diagnostics formatter
-	useColor := shouldUseColor()
-	formatter := diagnostics.NewFormatter(useColor)
-
 	// Handle diagnostics
 	hasErrors := len(snapshot.Metadata.Errors) > 0
 	hasWarnings := len(snapshot.Metadata.Warnings) > 0
 
-	// Print warnings (unless quiet)
-	if hasWarnings && !globalFlags.quiet {
-		formatter.PrintWarnings(os.Stderr, snapshot.Metadata.Warnings)
-	}
-
-	// Print errors (unless quiet)
-	if hasErrors && !globalFlags.quiet {
-		formatter.PrintErrors(os.Stderr, snapshot.Metadata.Errors)
+	// Print warnings and errors (unless quiet)
+	if !globalFlags.quiet {
+		writeDiagnostics(result, diags)
 	}
 
 	// Print validation summary (unless quiet)
@@ -262,59 +688,397 @@ func buildCommand(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("compilation completed with warnings (strict mode)")
 	}
 
+	// Record the build once its output is written
+	if buildFlags.history || projectCfg.History.Enabled {
+		defer func() {
+			if err == nil {
+				err = recordHistory(snapshot, projectCfg.History)
+			}
+		}()
+	}
+
 	// Serialize output based on format
-	output, err := serializeSnapshot(snapshot, buildFlags.format, buildFlags.includeMetadata)
+	serializers, err := newSerializerRegistry(projectCfg)
+	if err != nil {
+		return err
+	}
+	types, err := newFileTypes(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	files := buildFlags.files
+	files.summary = summary
+	if buildFlags.checksums {
+		files.manifest = &checksumManifest{}
+	}
+
+	// Write source map
+	if buildFlags.sourceMap != "" {
+		if err := writeSourceMap(buildFlags.sourceMap, snapshot.SourceMap, files); err != nil {
+			return err
+		}
+	}
+
+	if buildFlags.splitDepth > 0 {
+		if err := writeSplitOutput(snapshot, buildFlags.format, buildFlags.splitDepth, buildFlags.out, serializers, types, files, serializeOpts); err != nil {
+			return err
+		}
+		return files.manifest.write(filepath.Clean(buildFlags.out), snapshot, files)
+	}
+
+	// Stream JSON and YAML straight to the destination; --bench needs the
+	// serialize and write phases timed separately, so it stays buffered
+	if !buildFlags.bench && isStreamable(buildFlags.format) {
+		if err := streamOutput(snapshot, buildFlags.format, buildFlags.includeMetadata, buildFlags.out, types, files, serializeOpts); err != nil {
+			return err
+		}
+		return writeBuildChecksums(files, snapshot, types)
+	}
+
+	start := time.Now()
+	output, err := serializeSnapshot(snapshot, buildFlags.format, buildFlags.includeMetadata, serializers, serializeOpts)
 	if err != nil {
 		return fmt.Errorf("failed to serialize output: %w", err)
 	}
+	bench.add("serialize", time.Since(start), int64(len(output)))
 
 	// Write output
-	if buildFlags.out != "" {
-		// Resolve output path with extension handling
-		resolvedPath, err := resolveOutputPath(buildFlags.out, serialize.OutputFormat(strings.ToLower(buildFlags.format)))
+	start = time.Now()
+	if err := writeOutput(output, buildFlags.out, buildFlags.format, types, files); err != nil {
+		return err
+	}
+	bench.add("write", time.Since(start), int64(len(output)))
+
+	if buildFlags.bench {
+		bench.keys = countLeafKeys(snapshot.Data)
+		bench.Write(os.Stderr)
+	}
+	return writeBuildChecksums(files, snapshot, types)
+}
+
+// projectBuildParams returns the compiler options set in
+// .nomos/config.yaml, which builds and golden tests share; callers add the
+// rest, and may extend the lists, which are copies.
+func projectBuildParams(projectCfg projectconfig.Config) options.BuildParams {
+	return options.BuildParams{
+		SuppressWarnings:        slices.Clone(projectCfg.Warnings.Suppress),
+		PolicyFiles:             slices.Clone(projectCfg.Policies),
+		TypeCoercion:            projectCfg.TypeCoercion,
+		ProviderPermissions:     projectCfg.ProviderPermissions,
+		Limits:                  projectCfg.Limits,
+		MaxProviderMessageBytes: projectCfg.MaxProviderMessageBytes,
+	}
+}
+
+// compileLocal compiles path in this process with the providers installed
+// for the project, recording or replaying provider responses and writing a
+// debug dump as the build flags ask.
+func compileLocal(path, root string, sourceMap bool, projectCfg projectconfig.Config, encryptionKey, providerConfigJSON []byte, bench *benchReport) (compiler.CompilationResult, error) {
+	// Create provider registries (supports external providers via lockfile)
+	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
+	var recording *compiler.ProviderRecording
+	var err error
+	switch {
+	case buildFlags.replayProviders != "":
+		recording, err = compiler.LoadProviderRecording(buildFlags.replayProviders)
 		if err != nil {
-			return fmt.Errorf("invalid output path: %w", err)
+			return compiler.CompilationResult{}, err
 		}
+		providerTypeRegistry = compiler.NewReplayProviderTypeRegistry(recording)
+	case buildFlags.recordProviders != "":
+		recording = compiler.NewProviderRecording()
+		providerTypeRegistry = compiler.NewRecordingProviderTypeRegistry(providerTypeRegistry, recording)
+	}
 
-		// Ensure output directory exists
-		dir := filepath.Dir(resolvedPath)
-		if dir != "" && dir != "." {
-			if err := os.MkdirAll(dir, 0750); err != nil {
-				return fmt.Errorf("cannot create output directory: %w", err)
-			}
+	// Build compiler options
+	params := projectBuildParams(projectCfg)
+	params.Path = path
+	params.Vars = buildFlags.vars
+	params.TimeoutPerProvider = buildFlags.timeoutPerProvider
+	params.MaxConcurrentProviders = buildFlags.maxConcurrentProviders
+	params.AllowMissingProvider = buildFlags.allowMissingProvider
+	params.PartialFailure = buildFlags.partialFailure
+	params.FetchRetries = buildFlags.fetchRetries
+	params.ProviderRegistry = providerRegistry
+	params.ProviderTypeRegistry = providerTypeRegistry
+	params.EncryptionKey = encryptionKey
+	params.SuppressWarnings = append(params.SuppressWarnings, buildFlags.suppressWarnings...)
+	params.SourceMap = sourceMap
+	params.PolicyFiles = append(params.PolicyFiles, buildFlags.policies...)
+	params.TypeCoercion = cmp.Or(buildFlags.typeCoercion, params.TypeCoercion)
+	params.MaxSnapshotBytes = buildFlags.maxSnapshotBytes
+	params.VarFiles = buildFlags.varFiles
+	params.Sets = buildFlags.sets
+	params.ProviderConfigJSON = providerConfigJSON
+	params.ProviderConfigs = buildFlags.providerConfigs
+	params.Profiles = buildFlags.profiles
+	params.ProjectRoot = root
+	params.SourceDateEpoch = os.Getenv("SOURCE_DATE_EPOCH")
+	params.Reproducible = buildFlags.reproducible
+	opts, err := options.BuildOptions(params)
+	if err != nil {
+		return compiler.CompilationResult{}, fmt.Errorf("invalid options: %w", err)
+	}
+	if buildFlags.debugDump != "" {
+		opts.DebugDump = compiler.NewDebugDump()
+	}
+
+	// Compare list orders with the previous build to flag providers whose
+	// lists change order between runs
+	listOrders := listorder.Open(listorder.DefaultPath)
+	opts.PreviousListOrders, err = listOrders.Load(path)
+	if err != nil && !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Call compiler
+	ctx := context.Background()
+	start := time.Now()
+	result := compiler.Compile(ctx, opts)
+	bench.add("compile", time.Since(start), inputSize(result.Snapshot.Metadata.InputFiles))
+
+	if !result.HasErrors() {
+		if err := listOrders.Save(path, result.Snapshot.Metadata.ListOrders); err != nil && !globalFlags.quiet {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Save the recording even when compilation fails, so the failure can
+	// be replayed
+	if buildFlags.recordProviders != "" {
+		if err := recording.Save(buildFlags.recordProviders); err != nil {
+			return compiler.CompilationResult{}, err
+		}
+		if !globalFlags.quiet {
+			fmt.Fprintf(os.Stderr, "Provider responses recorded to %s\n", buildFlags.recordProviders)
+		}
+	}
+	if opts.DebugDump != nil {
+		if err := opts.DebugDump.Save(buildFlags.debugDump); err != nil {
+			return compiler.CompilationResult{}, err
+		}
+		if !globalFlags.quiet {
+			fmt.Fprintf(os.Stderr, "Debug dump written to %s\n", buildFlags.debugDump)
+		}
+	}
+
+	return result, nil
+}
+
+// recordHistory adds a successful build to the project's build history and
+// applies the configured retention.
+func recordHistory(snapshot compiler.Snapshot, cfg projectconfig.HistoryConfig) error {
+	keep := history.Retention{MaxEntries: cfg.MaxEntries, MaxAge: cfg.MaxAgeDuration()}
+	entry, err := history.Open(history.DefaultDir).Record(snapshot, buildFlags.path, time.Now(), keep)
+	if err != nil {
+		return err
+	}
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Build recorded in history as #%d\n", entry.ID)
+	}
+	return nil
+}
+
+// writeOutput writes serialized output to out, appending the format's default
+// extension from types when needed, or to stdout when out is empty.
+func writeOutput(output []byte, out, format string, types *serialize.FileTypes, files outputFileFlags) error {
+	if out != "" {
+		// Resolve output path with extension handling
+		resolvedPath, err := resolveOutputPath(out, serialize.OutputFormat(strings.ToLower(format)), types)
+		if err != nil {
+			return fmt.Errorf("invalid output path: %w", err)
 		}
 
-		// Write file using resolved path
-		if err := os.WriteFile(resolvedPath, output, 0600); err != nil {
-			return fmt.Errorf("cannot write output file: %w", err)
+		if err := files.writeFile(resolvedPath, func(w io.Writer) error {
+			_, err := w.Write(output)
+			return err
+		}); err != nil {
+			return err
 		}
 
 		if !globalFlags.quiet {
 			fmt.Fprintf(os.Stderr, "Output written to %s\n", resolvedPath)
 		}
 	} else {
-		// Write to stdout
+		// Write to stdout. JSON carries its own trailing newline policy
+		if serialize.OutputFormat(strings.ToLower(format)) == serialize.FormatJSON {
+			_, err := os.Stdout.Write(output)
+			files.summary.addArtifact(stdoutArtifact, int64(len(output)))
+			return err
+		}
 		fmt.Println(string(output))
+		files.summary.addArtifact(stdoutArtifact, int64(len(output))+1)
 	}
 
 	return nil
 }
 
+// isStreamable reports whether format has a streaming serializer.
+func isStreamable(format string) bool {
+	switch serialize.OutputFormat(strings.ToLower(format)) {
+	case serialize.FormatJSON, serialize.FormatYAML:
+		return true
+	default:
+		return false
+	}
+}
+
+// streamOutput serializes snapshot directly to out, or to stdout when out is
+// empty, producing the same bytes as serializeSnapshot followed by
+// writeOutput. A failed encoding leaves any existing output file untouched.
+func streamOutput(snapshot compiler.Snapshot, format string, includeMetadata bool, out string, types *serialize.FileTypes, files outputFileFlags, opts serialize.Options) error {
+	normalizedFormat := serialize.OutputFormat(strings.ToLower(format))
+	write := func(w io.Writer) error {
+		var err error
+		if normalizedFormat == serialize.FormatYAML {
+			err = opts.WriteYAML(w, snapshot, includeMetadata)
+		} else {
+			err = opts.WriteJSON(w, snapshot, includeMetadata)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to serialize output: %w", err)
+		}
+		return nil
+	}
+
+	if out == "" {
+		stdout := &countingWriter{w: os.Stdout}
+		if err := write(stdout); err != nil {
+			return err
+		}
+		if normalizedFormat != serialize.FormatJSON {
+			fmt.Fprintln(stdout)
+		}
+		files.summary.addArtifact(stdoutArtifact, stdout.n)
+		return nil
+	}
+
+	resolvedPath, err := resolveOutputPath(out, normalizedFormat, types)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	if err := files.writeFile(resolvedPath, write); err != nil {
+		return err
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Output written to %s\n", resolvedPath)
+	}
+	return nil
+}
+
+// writeSourceMap writes the snapshot's source map as indented JSON.
+func writeSourceMap(path string, sm *compiler.SourceMap, files outputFileFlags) error {
+	data, err := json.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize source map: %w", err)
+	}
+
+	if err := files.writeFile(path, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Source map written to %s\n", path)
+	}
+	return nil
+}
+
+// newSerializerRegistry registers the custom serializers declared in the
+// project configuration.
+func newSerializerRegistry(cfg projectconfig.Config) (*serialize.Registry, error) {
+	registry := serialize.NewRegistry()
+	for name, sc := range cfg.Serializers {
+		var s serialize.Serializer
+		if sc.Plugin != "" {
+			var err error
+			if s, err = serialize.LoadPlugin(sc.Plugin); err != nil {
+				return nil, err
+			}
+		} else {
+			s = serialize.NewCommandSerializer(sc.Command)
+		}
+		if err := registry.Register(name, s); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// newFileTypes applies the formats section of the project configuration to
+// the built-in file types.
+func newFileTypes(cfg projectconfig.Config) (*serialize.FileTypes, error) {
+	types := serialize.NewFileTypes()
+	for format, fc := range cfg.Formats {
+		ft := serialize.FileType{Extensions: fc.Extensions, MediaType: fc.MediaType}
+		if err := types.Set(serialize.OutputFormat(strings.ToLower(format)), ft); err != nil {
+			return nil, err
+		}
+	}
+	return types, nil
+}
+
+// loadTemplate loads the --template file for the template format, which
+// requires one; the flag is refused for other formats.
+func loadTemplate(format, path string) (*serialize.Template, error) {
+	if serialize.OutputFormat(strings.ToLower(format)) != serialize.FormatTemplate {
+		if path != "" {
+			return nil, fmt.Errorf("--template applies to template output, not %s", format)
+		}
+		return nil, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("--format template needs --template <file>")
+	}
+	return serialize.LoadTemplate(path)
+}
+
+// keyOrderFor returns the key order for format: the --key-order flag when
+// set, otherwise key_order for the format in the project configuration.
+// Custom serializers and templates order keys themselves, so the flag is
+// refused for them.
+func keyOrderFor(format, flag string, cfg projectconfig.Config) (serialize.KeyOrder, error) {
+	normalizedFormat := serialize.OutputFormat(strings.ToLower(format))
+	if normalizedFormat.IsCustom() || normalizedFormat == serialize.FormatTemplate {
+		if flag != "" {
+			return serialize.KeyOrder{}, fmt.Errorf("--key-order applies to json, yaml, and tfvars output, not %s", format)
+		}
+		return serialize.KeyOrder{}, nil
+	}
+	return serialize.ParseKeyOrder(cmp.Or(flag, cfg.KeyOrder[string(normalizedFormat)]))
+}
+
 // serializeSnapshot serializes a snapshot to the requested format.
-// Supported formats: json, yaml, tfvars
-func serializeSnapshot(snapshot compiler.Snapshot, format string, includeMetadata bool) ([]byte, error) {
+// Supported formats: json, yaml, tfvars, template (rendering opts.Template),
+// and custom:<name> for serializers in the given registry (which may be
+// nil). opts applies to the built-in formats only.
+func serializeSnapshot(snapshot compiler.Snapshot, format string, includeMetadata bool, serializers *serialize.Registry, opts serialize.Options) ([]byte, error) {
 	// Normalize format to lowercase for case-insensitive matching
 	normalizedFormat := strings.ToLower(format)
 
+	if f := serialize.OutputFormat(normalizedFormat); f.IsCustom() && f.CustomName() != "" {
+		s, err := serializers.Lookup(f.CustomName())
+		if err != nil {
+			return nil, err
+		}
+		return s.Serialize(snapshot, includeMetadata)
+	}
+
 	switch serialize.OutputFormat(normalizedFormat) {
 	case serialize.FormatJSON:
-		return serialize.ToJSON(snapshot, includeMetadata)
+		return opts.ToJSON(snapshot, includeMetadata)
 	case serialize.FormatYAML:
-		return serialize.ToYAML(snapshot, includeMetadata)
+		return opts.ToYAML(snapshot, includeMetadata)
 	case serialize.FormatTfvars:
-		return serialize.ToTfvars(snapshot, includeMetadata)
+		return opts.ToTfvars(snapshot, includeMetadata)
+	case serialize.FormatTemplate:
+		return opts.ToTemplate(snapshot, includeMetadata)
 	default:
-		return nil, fmt.Errorf("unsupported format: %s (supported: json, yaml, tfvars)", format)
+		return nil, fmt.Errorf("unsupported format: %s (supported: json, yaml, tfvars, template, custom:<name>)", format)
 	}
 }
 
@@ -330,24 +1094,26 @@ func serializeSnapshot(snapshot compiler.Snapshot, format string, includeMetadat
 //   - The format is valid
 //
 // Extension Detection:
-// Only recognized file extensions are treated as actual extensions.
+// Only recognized file extensions are treated as actual extensions: those
+// registered in types for any format, plus a few generic ones (.txt, .conf).
 // Unrecognized suffixes (like .prod, .v2) are treated as part of the
 // filename and the format extension is appended.
 //
 // Parameters:
 //   - outputPath: User-provided output path (may or may not have extension)
 //   - format: Output format (json, yaml, tfvars)
+//   - types: File types supplying the format extensions
 //
 // Returns:
 //   - Fully resolved path with appropriate extension
 //   - Error if path is invalid or format is unsupported
 //
 // Examples:
-//   - resolveOutputPath("output", FormatYAML) → "output.yaml", nil
-//   - resolveOutputPath("config.yml", FormatYAML) → "config.yml", nil
-//   - resolveOutputPath("config.prod", FormatJSON) → "config.prod.json", nil
-//   - resolveOutputPath("", FormatJSON) → "", error
-func resolveOutputPath(outputPath string, format serialize.OutputFormat) (string, error) {
+//   - resolveOutputPath("output", FormatYAML, types) → "output.yaml", nil
+//   - resolveOutputPath("config.yml", FormatYAML, types) → "config.yml", nil
+//   - resolveOutputPath("config.prod", FormatJSON, types) → "config.prod.json", nil
+//   - resolveOutputPath("", FormatJSON, types) → "", error
+func resolveOutputPath(outputPath string, format serialize.OutputFormat, types *serialize.FileTypes) (string, error) {
 	// Validate path is not empty or whitespace-only
 	trimmedPath := strings.TrimSpace(outputPath)
 	if trimmedPath == "" {
@@ -368,7 +1134,7 @@ func resolveOutputPath(outputPath string, format serialize.OutputFormat) (string
 
 	// Check if it's just an extension (e.g., ".json", ".yaml", ".tfvars")
 	// These are invalid because there's no actual filename
-	if base == ".json" || base == ".yaml" || base == ".yml" || base == ".tfvars" {
+	if types.IsExtension(base) {
 		return "", fmt.Errorf("invalid output path: path cannot be just an extension")
 	}
 
@@ -377,21 +1143,17 @@ func resolveOutputPath(outputPath string, format serialize.OutputFormat) (string
 		return "", err
 	}
 
-	// Check for special multi-part extension .auto.tfvars
-	if strings.HasSuffix(cleanedPath, ".auto.tfvars") {
+	// Extensions of any registered format, including multi-part ones such
+	// as .auto.tfvars, are always preserved
+	if _, ok := types.FormatOf(cleanedPath); ok {
 		return cleanedPath, nil
 	}
 
 	// Get the extension
 	ext := filepath.Ext(cleanedPath)
 
-	// Check if path has a recognized file extension
-	// Standard file extensions that should always be preserved
+	// Other standard file extensions that should always be preserved
 	recognizedExtensions := map[string]bool{
-		".json":   true,
-		".yaml":   true,
-		".yml":    true,
-		".tfvars": true,
 		".txt":    true,
 		".conf":   true,
 		".hcl":    true,
@@ -424,23 +1186,32 @@ func resolveOutputPath(outputPath string, format serialize.OutputFormat) (string
 	}
 
 	// No recognized extension - append format's default extension
-	return cleanedPath + format.Extension(), nil
+	return cleanedPath + types.Extension(format), nil
 }
 
-// shouldUseColor determines whether to colorize output based on flags and terminal
-func shouldUseColor() bool {
-	switch globalFlags.color {
-	case "always":
-		return true
-	case "never":
-		return false
-	case "auto":
-		// Check if stderr is a terminal
-		if fileInfo, err := os.Stderr.Stat(); err == nil {
-			return (fileInfo.Mode() & os.ModeCharDevice) != 0
-		}
-		return false
-	default:
-		return false
+// repositoryRoot returns the directory that CI annotations and reports
+// locate files relative to: the GitHub Actions workspace when set, else the
+// project root.
+func repositoryRoot() string {
+	// Annotations attach to paths relative to the repository root
+	return cmp.Or(os.Getenv("GITHUB_WORKSPACE"), projectRoot)
+}
+
+// printDiagnostics writes the warnings and errors of result to stderr in
+// the --diagnostics-format: readable text, or GitHub Actions workflow
+// commands that annotate the files of a pull request.
+func printDiagnostics(result compiler.CompilationResult) {
+	writeDiagnostics(result, result.Diagnostics())
+}
+
+// writeDiagnostics is printDiagnostics with the located diagnostics of
+// result given separately, as they are for a remote build.
+func writeDiagnostics(result compiler.CompilationResult, diags []compiler.Diagnostic) {
+	if globalFlags.diagnosticsFormat == diagnosticsFormatGitHub {
+		_ = diagnostics.WriteGitHub(os.Stderr, diags, repositoryRoot()) // Ignore write errors
+		return
 	}
+	formatter := diagnostics.NewFormatter(styleFor(os.Stderr).Enabled())
+	formatter.PrintWarnings(os.Stderr, result.Warnings())
+	formatter.PrintErrors(os.Stderr, result.Errors())
 }
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
 	"github.com/autonomous-bits/nomos/libs/compiler"
 )
@@ -43,7 +47,7 @@ func TestSerializeSnapshot_CaseInsensitive(t *testing.T) {
 			// Create minimal snapshot with simple test data
 			snapshot := createMinimalSnapshot()
 
-			output, err := serializeSnapshot(snapshot, tt.format, true)
+			output, err := serializeSnapshot(snapshot, tt.format, true, nil, serialize.Options{})
 
 			// Verify error expectation
 			if (err != nil) != tt.wantErr {
@@ -149,7 +153,7 @@ func TestSerializeSnapshot_InvalidFormat(t *testing.T) {
 			// Create minimal snapshot
 			snapshot := createMinimalSnapshot()
 
-			output, err := serializeSnapshot(snapshot, tt.format, true)
+			output, err := serializeSnapshot(snapshot, tt.format, true, nil, serialize.Options{})
 
 			// Verify error expectation
 			if (err != nil) != tt.wantErr {
@@ -198,7 +202,7 @@ func TestSerializeSnapshot_DefaultFormat(t *testing.T) {
 		snapshot := createMinimalSnapshot()
 
 		// Test explicit "json" format (what the flag defaults to)
-		output, err := serializeSnapshot(snapshot, "json", true)
+		output, err := serializeSnapshot(snapshot, "json", true, nil, serialize.Options{})
 
 		if err != nil {
 			t.Errorf("serializeSnapshot() with default format 'json' returned error: %v", err)
@@ -229,7 +233,7 @@ func TestSerializeSnapshot_DefaultFormat(t *testing.T) {
 
 		snapshot := createMinimalSnapshot()
 
-		output, err := serializeSnapshot(snapshot, "", true)
+		output, err := serializeSnapshot(snapshot, "", true, nil, serialize.Options{})
 
 		// Empty format should be treated as invalid
 		if err == nil {
@@ -248,8 +252,111 @@ func TestSerializeSnapshot_DefaultFormat(t *testing.T) {
 	})
 }
 
+// TestSerializeSnapshot_CustomFormat verifies that custom:<name> formats are
+// dispatched to the serializer registry.
+func TestSerializeSnapshot_CustomFormat(t *testing.T) {
+	serializers := serialize.NewRegistry()
+	if err := serializers.Register("keys", serialize.SerializerFunc(func(s compiler.Snapshot, _ bool) ([]byte, error) {
+		return []byte(strings.Join(sortedKeys(s.Data), ",")), nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := serializeSnapshot(createMinimalSnapshot(), "custom:KEYS", false, serializers, serialize.Options{})
+	if err != nil {
+		t.Fatalf("serializeSnapshot() unexpected error: %v", err)
+	}
+	if string(output) != "test_key" {
+		t.Errorf("serializeSnapshot() = %q, want %q", output, "test_key")
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := serializeSnapshot(createMinimalSnapshot(), "custom:missing", false, serializers, serialize.Options{}); err == nil {
+		t.Error("serializeSnapshot() expected error for unregistered custom format")
+	}
+}
+
+// TestStreamOutput_MatchesBufferedOutput verifies that streamed JSON and YAML
+// files are byte-identical to the buffered serializer output.
+func TestStreamOutput_MatchesBufferedOutput(t *testing.T) {
+	snapshot := createMinimalSnapshot()
+	snapshot.Data["nested"] = map[string]any{"list": []any{"a", map[string]any{"b": "<c>"}}, "empty": map[string]any{}}
+
+	priority := serialize.Options{KeyOrder: serialize.KeyOrder{Policy: serialize.KeyOrderPriority, Priority: []string{"nested", "list"}}}
+	for _, format := range []string{"json", "yaml"} {
+		for _, includeMetadata := range []bool{false, true} {
+			opts := serialize.Options{}
+			if includeMetadata {
+				opts = priority
+			}
+			want, err := serializeSnapshot(snapshot, format, includeMetadata, nil, opts)
+			if err != nil {
+				t.Fatalf("serializeSnapshot(%s) unexpected error: %v", format, err)
+			}
+
+			out := filepath.Join(t.TempDir(), "out."+format)
+			if err := streamOutput(snapshot, format, includeMetadata, out, serialize.NewFileTypes(), outputFileFlags{}, opts); err != nil {
+				t.Fatalf("streamOutput(%s) unexpected error: %v", format, err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("streamOutput(%s, metadata=%v) =\n%s\nwant\n%s", format, includeMetadata, got, want)
+			}
+
+			entries, _ := os.ReadDir(filepath.Dir(out))
+			if len(entries) != 1 {
+				t.Errorf("streamOutput(%s) left temporary files behind: %d entries", format, len(entries))
+			}
+		}
+	}
+}
+
+// TestKeyOrderFor verifies that --key-order overrides the project's
+// per-format key_order, and is refused for custom formats.
+func TestKeyOrderFor(t *testing.T) {
+	cfg := projectconfig.Config{KeyOrder: map[string]string{"yaml": "source"}}
+	tests := []struct {
+		format  string
+		flag    string
+		want    serialize.KeyOrder
+		wantErr bool
+	}{
+		{format: "yaml", want: serialize.KeyOrder{Policy: serialize.KeyOrderSource}},
+		{format: "YAML", want: serialize.KeyOrder{Policy: serialize.KeyOrderSource}},
+		{format: "yaml", flag: "alphabetical", want: serialize.KeyOrder{}},
+		{format: "json", want: serialize.KeyOrder{}},
+		{format: "json", flag: "priority:name", want: serialize.KeyOrder{Policy: serialize.KeyOrderPriority, Priority: []string{"name"}}},
+		{format: "json", flag: "reverse", wantErr: true},
+		{format: "custom:toml", want: serialize.KeyOrder{}},
+		{format: "custom:toml", flag: "source", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := keyOrderFor(tt.format, tt.flag, cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("keyOrderFor(%q, %q) error = %v, wantErr %v", tt.format, tt.flag, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keyOrderFor(%q, %q) = %#v, want %#v", tt.format, tt.flag, got, tt.want)
+		}
+	}
+}
+
 // Helper functions for test assertions
 
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // createMinimalSnapshot creates a minimal valid snapshot for testing.
 // This includes just enough data to produce valid serialization output
 // without requiring complex test fixtures.
@@ -408,7 +515,7 @@ func TestResolveOutputPath_AutoAppendExtension(t *testing.T) {
 			inputPath := filepath.Join(tmpDir, tt.outputPath)
 			expectedFullPath := filepath.Join(tmpDir, tt.expectedPath)
 
-			result, err := resolveOutputPath(inputPath, tt.format)
+			result, err := resolveOutputPath(inputPath, tt.format, serialize.NewFileTypes())
 
 			if err != nil {
 				t.Fatalf("resolveOutputPath() unexpected error: %v", err)
@@ -419,7 +526,7 @@ func TestResolveOutputPath_AutoAppendExtension(t *testing.T) {
 			}
 
 			// Verify the path ends with the correct extension
-			expectedExt := tt.format.Extension()
+			expectedExt := serialize.NewFileTypes().Extension(tt.format)
 			if !strings.HasSuffix(result, expectedExt) {
 				t.Errorf("resolveOutputPath() result %q does not end with expected extension %q", result, expectedExt)
 			}
@@ -531,7 +638,7 @@ func TestResolveOutputPath_PreserveExplicitExtension(t *testing.T) {
 			inputPath := filepath.Join(tmpDir, tt.outputPath)
 			expectedFullPath := filepath.Join(tmpDir, tt.expectedPath)
 
-			result, err := resolveOutputPath(inputPath, tt.format)
+			result, err := resolveOutputPath(inputPath, tt.format, serialize.NewFileTypes())
 
 			if err != nil {
 				t.Fatalf("resolveOutputPath() unexpected error: %v", err)
@@ -671,7 +778,7 @@ func TestResolveOutputPath_ExtensionMismatch(t *testing.T) {
 			inputPath := filepath.Join(tmpDir, tt.outputPath)
 			expectedFullPath := filepath.Join(tmpDir, tt.expectedPath)
 
-			result, err := resolveOutputPath(inputPath, tt.format)
+			result, err := resolveOutputPath(inputPath, tt.format, serialize.NewFileTypes())
 
 			if err != nil {
 				t.Fatalf("resolveOutputPath() unexpected error: %v", err)
@@ -692,7 +799,7 @@ func TestResolveOutputPath_ExtensionMismatch(t *testing.T) {
 			}
 
 			// Verify we didn't append the format's default extension
-			formatExt := tt.format.Extension()
+			formatExt := serialize.NewFileTypes().Extension(tt.format)
 			if strings.HasSuffix(result, formatExt) && originalExt != formatExt {
 				t.Errorf("resolveOutputPath() incorrectly appended format extension %q\n"+
 					"Original extension %q should be preserved\nReason: %s",
@@ -865,7 +972,7 @@ func TestResolveOutputPath_EdgeCases(t *testing.T) {
 				inputPath = filepath.Join(tmpDir, tt.outputPath)
 			}
 
-			result, err := resolveOutputPath(inputPath, tt.format)
+			result, err := resolveOutputPath(inputPath, tt.format, serialize.NewFileTypes())
 
 			if tt.expectError {
 				if err == nil {
@@ -952,7 +1059,7 @@ func TestResolveOutputPath_IntegrationWithFormats(t *testing.T) {
 			tmpDir := t.TempDir()
 			inputPath := filepath.Join(tmpDir, tt.outputPath)
 
-			result, err := resolveOutputPath(inputPath, tt.format)
+			result, err := resolveOutputPath(inputPath, tt.format, serialize.NewFileTypes())
 
 			if tt.expectError {
 				if err == nil {
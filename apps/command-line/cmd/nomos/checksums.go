@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// checksumManifestName is the file --checksums writes beside the output.
+const checksumManifestName = "outputs.sha256"
+
+// checksumManifest collects the SHA-256 digests of the files a build writes.
+type checksumManifest struct {
+	digests map[string]string // path as written → hex digest
+}
+
+func (m *checksumManifest) add(path string, sum []byte) {
+	if m.digests == nil {
+		m.digests = make(map[string]string)
+	}
+	m.digests[path] = hex.EncodeToString(sum)
+}
+
+// write writes the manifest to dir in the format of sha256sum, with paths
+// relative to dir, so running 'sha256sum -c outputs.sha256' in dir verifies
+// every file. A leading comment carries the content hash of snapshot, which
+// identifies the compiled data independently of the output format.
+// A nil manifest writes nothing.
+func (m *checksumManifest) write(dir string, snapshot compiler.Snapshot, files outputFileFlags) error {
+	if m == nil {
+		return nil
+	}
+	contentHash, err := serialize.ContentHash(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to hash snapshot: %w", err)
+	}
+
+	paths := make([]string, 0, len(m.digests))
+	for path := range m.digests {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// The manifest does not list itself
+	files.manifest = nil
+	path := filepath.Join(dir, checksumManifestName)
+	if err := files.writeFile(path, func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "# snapshot sha256:%s\n", contentHash); err != nil {
+			return err
+		}
+		for _, p := range paths {
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				rel = p
+			}
+			if _, err := fmt.Fprintf(w, "%s  %s\n", m.digests[p], filepath.ToSlash(rel)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Checksums written to %s\n", path)
+	}
+	return nil
+}
+
+// writeBuildChecksums writes the manifest recorded in files, if any, beside
+// the --out file of a single-document build.
+func writeBuildChecksums(files outputFileFlags, snapshot compiler.Snapshot, types *serialize.FileTypes) error {
+	if files.manifest == nil {
+		return nil
+	}
+	path, err := resolveOutputPath(buildFlags.out, serialize.OutputFormat(strings.ToLower(buildFlags.format)), types)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	return files.manifest.write(filepath.Dir(path), snapshot, files)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestChecksumManifest tests that written files are listed relative to the
+// manifest with the snapshot content hash.
+func TestChecksumManifest(t *testing.T) {
+	globalFlags.quiet = true
+	t.Cleanup(func() { globalFlags.quiet = false })
+
+	dir := t.TempDir()
+	files := outputFileFlags{manifest: &checksumManifest{}}
+	for _, name := range []string{"b.json", filepath.Join("nested", "a.json")} {
+		content := name
+		if err := files.writeFile(filepath.Join(dir, name), func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot := compiler.Snapshot{Data: map[string]any{"app": "api"}}
+	if err := files.manifest.write(dir, snapshot, files); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, checksumManifestName)) //nolint:gosec // G304: Test file in a temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	contentHash, _ := serialize.ContentHash(snapshot)
+	want := "# snapshot sha256:" + contentHash + "\n" +
+		sum("b.json") + "  b.json\n" +
+		sum(filepath.Join("nested", "a.json")) + "  nested/a.json\n"
+	if string(got) != want {
+		t.Errorf("manifest =\n%s\nwant\n%s", got, want)
+	}
+}
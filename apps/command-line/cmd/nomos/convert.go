@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// convertFlags holds all flags for the convert command
+var convertFlags struct {
+	from            string
+	format          string
+	out             string
+	includeMetadata bool
+	keyOrder        string
+	json            jsonFormatFlags
+	files           outputFileFlags
+	template        string
+}
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert <snapshot>",
+	Short: "Re-serialize an existing snapshot to another output format",
+	Long: `Convert reads a snapshot file produced by 'nomos build' and writes it in
+another output format, without recompiling or contacting providers.
+
+Input Formats:
+  json, yaml - Detected from the file extension (.json, .yaml, .yml, or
+               those set under formats in .nomos/config.yaml);
+               use --from to override. Use "-" to read from stdin (requires --from).
+
+  Snapshots built with --include-metadata (a "data" and a "metadata" section)
+  are recognized; pass --include-metadata to carry the metadata through.
+
+Output Formats:
+  json, yaml, tfvars, template (with --template <file>), and custom:<name>
+  (see 'nomos build --help').
+
+JSON Formatting:
+  --json-indent, --json-minify, --json-trailing-newline, and --json-escape-html
+  control JSON whitespace and escaping (see 'nomos build --help').
+
+Key Order:
+  Keys are sorted alphabetically unless --key-order or key_order for the
+  format in .nomos/config.yaml gives another order (see 'nomos build --help').
+  Snapshot files carry no source map, so source order is refused; it is only
+  available from 'nomos build'.
+
+Output Files:
+  Files are written atomically. --no-clobber refuses to replace an existing
+  file and --backup keeps a timestamped copy of it (see 'nomos build --help').
+
+Examples:
+  # Produce a .tfvars flavor of an existing JSON snapshot
+  nomos convert snapshot.json --format tfvars -o terraform.tfvars
+
+  # YAML to JSON on stdout
+  nomos convert config.yaml --format json
+
+Exit Codes:
+  0 - Success
+  1 - Conversion errors
+  2 - Invalid usage or flags`,
+	Args: cobra.ExactArgs(1),
+	RunE: convertCommand,
+}
+
+func init() {
+	convertCmd.Flags().StringVar(&convertFlags.from, "from", "", "Input format: json or yaml (default: detected from extension)")
+	convertCmd.Flags().StringVarP(&convertFlags.format, "format", "f", "json", "Output format: json, yaml, tfvars, template, or custom:<name>")
+	convertCmd.Flags().StringVarP(&convertFlags.out, "out", "o", "", "Output file (default: stdout)")
+	convertCmd.Flags().BoolVar(&convertFlags.includeMetadata, "include-metadata", false, "Include snapshot metadata in output")
+	convertCmd.Flags().StringVar(&convertFlags.keyOrder, "key-order", "", "Map key order: alphabetical or priority:<key>,<key>... (default alphabetical)")
+	convertFlags.json.addFlags(convertCmd)
+	convertFlags.files.addFlags(convertCmd)
+	convertCmd.Flags().StringVar(&convertFlags.template, "template", "", "Go text/template file rendered by --format template")
+}
+
+// convertCommand executes the convert subcommand.
+func convertCommand(cmd *cobra.Command, args []string) error {
+	input := args[0]
+
+	// Load project-level settings (.nomos/config.yaml) for custom serializers
+	// and file types
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+	types, err := newFileTypes(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	from, err := snapshotInputFormat(input, convertFlags.from, types)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(input) //nolint:gosec // G304: Path is provided by the user
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read snapshot: %w", err)
+	}
+
+	snapshot, err := serialize.Decode(data, from)
+	if err != nil {
+		return err
+	}
+
+	serializers, err := newSerializerRegistry(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	keyOrder, err := snapshotKeyOrder(convertFlags.format, convertFlags.keyOrder, projectCfg)
+	if err != nil {
+		return err
+	}
+
+	jsonFormat, err := convertFlags.json.jsonFormat(cmd, convertFlags.format, convertFlags.out)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := loadTemplate(convertFlags.format, convertFlags.template)
+	if err != nil {
+		return err
+	}
+
+	output, err := serializeSnapshot(snapshot, convertFlags.format, convertFlags.includeMetadata, serializers, serialize.Options{KeyOrder: keyOrder, JSON: jsonFormat, Template: tmpl})
+	if err != nil {
+		return fmt.Errorf("failed to serialize output: %w", err)
+	}
+
+	return writeOutput(output, convertFlags.out, convertFlags.format, types, convertFlags.files)
+}
+
+// snapshotKeyOrder returns the key order for re-serializing snapshot files,
+// as keyOrderFor does. Snapshot files carry no source map, so source order,
+// whether from the flag or the project configuration, is an error rather
+// than a silent fallback to alphabetical order.
+func snapshotKeyOrder(format, flag string, cfg projectconfig.Config) (serialize.KeyOrder, error) {
+	order, err := keyOrderFor(format, flag, cfg)
+	if err != nil {
+		return serialize.KeyOrder{}, err
+	}
+	if order.Policy == serialize.KeyOrderSource {
+		return serialize.KeyOrder{}, compiler.WithHint(fmt.Errorf("source key order needs a source map, which snapshot files do not carry"),
+			compiler.Hint{Text: "pass --key-order alphabetical or priority:<key>,<key>..., or use 'nomos build --key-order source'"})
+	}
+	return order, nil
+}
+
+// snapshotInputFormat returns the explicit input format, or detects it from
+// the file extension registered in types.
+func snapshotInputFormat(path, explicit string, types *serialize.FileTypes) (serialize.OutputFormat, error) {
+	if explicit != "" {
+		format := serialize.OutputFormat(strings.ToLower(explicit))
+		if format != serialize.FormatJSON && format != serialize.FormatYAML {
+			return "", fmt.Errorf("unsupported input format: %q (supported: json, yaml)", explicit)
+		}
+		return format, nil
+	}
+
+	if format, ok := types.FormatOf(path); ok && (format == serialize.FormatJSON || format == serialize.FormatYAML) {
+		return format, nil
+	}
+	return "", compiler.WithHint(fmt.Errorf("cannot detect input format of %q", path),
+		compiler.Hint{Text: "pass --from json or --from yaml"})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+)
+
+// TestSnapshotInputFormat verifies input format detection for convert.
+func TestSnapshotInputFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		explicit string
+		want     serialize.OutputFormat
+		wantErr  bool
+	}{
+		{name: "json extension", path: "snap.json", want: serialize.FormatJSON},
+		{name: "yaml extension", path: "snap.YAML", want: serialize.FormatYAML},
+		{name: "yml extension", path: "snap.yml", want: serialize.FormatYAML},
+		{name: "explicit overrides extension", path: "snap.json", explicit: "YAML", want: serialize.FormatYAML},
+		{name: "stdin requires explicit", path: "-", wantErr: true},
+		{name: "unknown extension", path: "snap.tfvars", wantErr: true},
+		{name: "unsupported explicit", path: "snap.json", explicit: "tfvars", wantErr: true},
+		{name: "configured extension", path: "snap.snapshot", want: serialize.FormatJSON},
+	}
+
+	types := serialize.NewFileTypes()
+	if err := types.Set(serialize.FormatJSON, serialize.FileType{Extensions: []string{".json", ".snapshot"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snapshotInputFormat(tt.path, tt.explicit, types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("snapshotInputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("snapshotInputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSnapshotKeyOrder verifies that convert and merge take key_order from
+// the project configuration and refuse source order.
+func TestSnapshotKeyOrder(t *testing.T) {
+	cfg := projectconfig.Config{KeyOrder: map[string]string{"json": "priority:kind", "yaml": "source"}}
+	tests := []struct {
+		format  string
+		flag    string
+		want    serialize.KeyOrder
+		wantErr bool
+	}{
+		{format: "json", want: serialize.KeyOrder{Policy: serialize.KeyOrderPriority, Priority: []string{"kind"}}},
+		{format: "json", flag: "alphabetical", want: serialize.KeyOrder{}},
+		{format: "json", flag: "source", wantErr: true},
+		{format: "yaml", wantErr: true},
+		{format: "yaml", flag: "priority:name", want: serialize.KeyOrder{Policy: serialize.KeyOrderPriority, Priority: []string{"name"}}},
+	}
+	for _, tt := range tests {
+		got, err := snapshotKeyOrder(tt.format, tt.flag, cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("snapshotKeyOrder(%q, %q) error = %v, wantErr %v", tt.format, tt.flag, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("snapshotKeyOrder(%q, %q) = %#v, want %#v", tt.format, tt.flag, got, tt.want)
+		}
+	}
+}
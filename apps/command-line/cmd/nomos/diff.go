@@ -0,0 +1,123 @@
+// Package main implements the diff command for the Nomos CLI.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/golden"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// diffFlags holds all flags for the diff command
+var diffFlags struct {
+	source   snapshotSource
+	fromRef  string
+	toRef    string
+	exitCode bool
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how compiled configuration differs between git revisions",
+	Long: `Diff compiles --path as of the git revision --from-ref and again from the
+working tree (or --to-ref), and prints how the compiled data differs, for
+previews of the configuration a pull request would produce.
+
+The files of a revision are read from git's object database; the working
+tree, index, and HEAD are never touched, so no checkout is needed and
+uncommitted changes are safe. Var files and .nomos/config.yaml are read
+from the working tree, and installed providers are reused.
+
+Output:
+  The data of both sides is compared as indented JSON. Lines only in the
+  --from-ref side are prefixed with "-", lines only in the other side with
+  "+"; runs of unchanged lines are elided.
+
+Examples:
+  # What this branch changes compared with main
+  nomos diff -p config/ --from-ref main
+
+  # Between two tags, failing when they differ
+  nomos diff -p config/ --from-ref v1.2.0 --to-ref v1.3.0 --exit-code
+
+Exit Codes:
+  0 - Compiled (no differences, or differences without --exit-code)
+  1 - Differences with --exit-code, compilation failed, or unknown revision`,
+	Args: cobra.NoArgs,
+	RunE: diffCommand,
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffFlags.source.path, "path", "p", "", "Path to .csl file or directory to compile (required)")
+	_ = diffCmd.MarkFlagRequired("path") // Error only occurs if flag doesn't exist
+	diffCmd.Flags().StringVar(&diffFlags.fromRef, "from-ref", "", "Git revision (branch, tag, or commit) to compare from (required)")
+	_ = diffCmd.MarkFlagRequired("from-ref") // Error only occurs if flag doesn't exist
+	diffCmd.Flags().StringVar(&diffFlags.toRef, "to-ref", "", "Git revision to compare to (default: the working tree)")
+	diffCmd.Flags().StringSliceVar(&diffFlags.source.vars, "var", nil, "Set variable: key=value (repeatable)")
+	diffCmd.Flags().StringArrayVar(&diffFlags.source.sets, "set", nil, "Override a compiled value: key.path=value (repeatable)")
+	diffCmd.Flags().StringSliceVar(&diffFlags.source.varFiles, "var-file", nil, "YAML or JSON file of values overlaid on the compiled snapshot (repeatable)")
+	diffCmd.Flags().BoolVar(&diffFlags.exitCode, "exit-code", false, "Exit with code 1 when the compiled data differs")
+}
+
+// diffCommand executes the diff command.
+func diffCommand(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	labels := [2]string{diffFlags.fromRef, "working tree"}
+	if diffFlags.toRef != "" {
+		labels[1] = diffFlags.toRef
+	}
+	var snapshots [2]compiler.Snapshot
+	for i, ref := range []string{diffFlags.fromRef, diffFlags.toRef} {
+		var err error
+		if snapshots[i], err = diffFlags.source.loadAt(ctx, ref); err != nil {
+			return err
+		}
+	}
+
+	differ, err := writeSnapshotDiff(os.Stdout, labels, snapshots)
+	if err != nil {
+		return err
+	}
+	if differ && diffFlags.exitCode {
+		return fmt.Errorf("compiled configuration differs between %s and %s", labels[0], labels[1])
+	}
+	return nil
+}
+
+// writeSnapshotDiff prints the difference between the data of two
+// snapshots as indented JSON, under headers naming them, or a note that
+// they are equal. It reports whether they differ. Changed lines are colored
+// when output to w is.
+func writeSnapshotDiff(w io.Writer, labels [2]string, snapshots [2]compiler.Snapshot) (bool, error) {
+	style := styleFor(w)
+	var texts [2]string
+	for i, snapshot := range snapshots {
+		var buf bytes.Buffer
+		if err := (serialize.Options{}).WriteJSON(&buf, snapshot, false); err != nil {
+			return false, fmt.Errorf("failed to encode %s: %w", labels[i], err)
+		}
+		texts[i] = buf.String()
+	}
+
+	diff := golden.Diff(texts[0], texts[1])
+	if diff == "" {
+		_, err := fmt.Fprintf(w, "No changes between %s and %s\n", labels[0], labels[1])
+		return false, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n%s\n", style.Header("--- "+labels[0]), style.Header("+++ "+labels[1])); err != nil {
+		return true, err
+	}
+	_, err := io.WriteString(w, style.Diff(diff))
+	return true, err
+}
@@ -0,0 +1,92 @@
+// Package main implements the get command for the Nomos CLI.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// exitKeyNotFound is the exit code of get when the key path is absent.
+const exitKeyNotFound = 3
+
+// getFlags holds all flags for the get command
+var getFlags struct {
+	source snapshotSource
+	json   bool
+}
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get <key.path>",
+	Short: "Print one value from compiled configuration",
+	Long: `Get compiles .csl files (or reads a snapshot written by 'nomos build') and
+prints the value at a single key path, in place of piping the output to jq.
+
+Key paths use dots between map keys and [n] for list indices, as in source
+maps: app.database.host, app.tags[0].
+
+Output:
+  By default strings are printed raw and numbers and booleans as written;
+  maps and lists are printed as indented JSON. With --json every value is
+  printed as JSON, so strings are quoted.
+
+Sources:
+  --path compiles with the providers installed by 'nomos build' (from the
+  lockfile); nothing is downloaded. --from-snapshot reads a JSON or YAML
+  snapshot, with or without --include-metadata.
+
+Examples:
+  # Print a host name for a shell script
+  nomos get app.database.host -p config.csl
+
+  # Read from a snapshot written earlier
+  nomos get app.tags[0] --from-snapshot build/config.json
+
+  # Print a map as typed JSON
+  nomos get app.database --json -p config/ --set app.env=prod
+
+Exit Codes:
+  0 - The key was found and printed
+  1 - Compilation failed, or the snapshot could not be read
+  3 - The key path does not exist`,
+	Args: cobra.ExactArgs(1),
+	RunE: getCommand,
+}
+
+func init() {
+	getFlags.source.addFlags(getCmd)
+	getCmd.Flags().BoolVar(&getFlags.json, "json", false, "Print the value as JSON (strings quoted)")
+}
+
+// getCommand executes the get subcommand.
+func getCommand(_ *cobra.Command, args []string) error {
+	snapshot, err := getFlags.source.load()
+	if err != nil {
+		return err
+	}
+
+	value, ok := snapshot.Lookup(args[0])
+	if !ok {
+		return &exitCodeError{code: exitKeyNotFound, err: fmt.Errorf("key %q not found", args[0])}
+	}
+	return writeValue(os.Stdout, value, getFlags.json)
+}
+
+// writeValue prints value followed by a newline: strings raw unless asJSON,
+// everything else as JSON, indented when it is a map or list.
+func writeValue(w io.Writer, value any, asJSON bool) error {
+	if s, ok := value.(string); ok && !asJSON {
+		_, err := fmt.Fprintln(w, s)
+		return err
+	}
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", encoded)
+	return err
+}
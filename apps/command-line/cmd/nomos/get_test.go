@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestWriteValue verifies raw and JSON printing of values.
+func TestWriteValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		asJSON bool
+		want   string
+	}{
+		{name: "raw string", value: "db.internal", want: "db.internal\n"},
+		{name: "json string", value: "db.internal", asJSON: true, want: "\"db.internal\"\n"},
+		{name: "integer", value: int64(5432), want: "5432\n"},
+		{name: "float", value: 8080.0, want: "8080\n"},
+		{name: "bool", value: true, want: "true\n"},
+		{name: "null", value: nil, want: "null\n"},
+		{name: "list", value: []any{"a", "b"}, want: "[\n  \"a\",\n  \"b\"\n]\n"},
+		{name: "map", value: map[string]any{"port": "5432", "host": "db"}, want: "{\n  \"host\": \"db\",\n  \"port\": \"5432\"\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeValue(&buf, tt.value, tt.asJSON); err != nil {
+				t.Fatalf("writeValue() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExitCode verifies that exitCodeError sets the exit code, even wrapped.
+func TestExitCode(t *testing.T) {
+	notFound := &exitCodeError{code: exitKeyNotFound, err: errors.New("key not found")}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "plain error", err: errors.New("compilation failed"), want: 1},
+		{name: "exit code error", err: notFound, want: exitKeyNotFound},
+		{name: "wrapped", err: fmt.Errorf("get: %w", notFound), want: exitKeyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package main implements the history command for the Nomos CLI.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/history"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd groups the commands that inspect recorded builds.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Inspect past builds recorded in .nomos/history",
+	Long: `History inspects the builds recorded in .nomos/history, so the output of a
+build can be compared with earlier ones without external storage.
+
+Recording Builds:
+  Builds are recorded when history is enabled in .nomos/config.yaml, or
+  for a single build with 'nomos build --history'. Only successful builds
+  are recorded. Retention limits apply after each recorded build:
+
+    history:
+      enabled: true
+      max_entries: 50    # keep the 50 most recent builds
+      max_age: 720h      # drop builds older than 30 days
+
+  Each distinct compiled snapshot is stored once, named after its content
+  hash, so repeated builds of unchanged configuration cost almost nothing.
+
+Build References:
+  latest   the newest build
+  3        the build with ID 3, as shown by 'nomos history list'
+  24h      the newest build recorded at least this long ago`,
+}
+
+// historyListFlags holds all flags for the history list command
+var historyListFlags struct {
+	json bool
+}
+
+// historyListCmd represents the history list command
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded builds",
+	Long: `List prints the recorded builds, oldest first, with their ID, time, content
+hash, and compiled path.
+
+Examples:
+  nomos history list
+  nomos history list --json`,
+	Args: cobra.NoArgs,
+	RunE: historyListCommand,
+}
+
+// historyShowFlags holds all flags for the history show command
+var historyShowFlags struct {
+	format string
+}
+
+// historyShowCmd represents the history show command
+var historyShowCmd = &cobra.Command{
+	Use:   "show [<build>]",
+	Short: "Print the compiled data of a recorded build",
+	Long: `Show prints the compiled data of a recorded build (default: latest) as JSON
+or YAML.
+
+Examples:
+  nomos history show
+  nomos history show 3 --format yaml
+  nomos history show 24h > yesterday.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: historyShowCommand,
+}
+
+// historyDiffCmd represents the history diff command
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <build> [<build>]",
+	Short: "Show what changed between two recorded builds",
+	Long: `Diff compares the compiled data of two recorded builds as indented JSON.
+Lines only in the first build are prefixed with "-", lines only in the second
+with "+". Without a second build, the first is compared with the latest.
+
+Examples:
+  # What changed since yesterday
+  nomos history diff 24h
+
+  # Between two builds
+  nomos history diff 3 5`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: historyDiffCommand,
+}
+
+func init() {
+	historyListCmd.Flags().BoolVar(&historyListFlags.json, "json", false, "Print the builds as JSON")
+	historyShowCmd.Flags().StringVarP(&historyShowFlags.format, "format", "f", "json", "Output format: json or yaml")
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyDiffCmd)
+}
+
+// historyListCommand executes the history list subcommand.
+func historyListCommand(_ *cobra.Command, _ []string) error {
+	entries, err := history.Open(history.DefaultDir).List()
+	if err != nil {
+		return err
+	}
+
+	if historyListFlags.json {
+		encoded, err := json.MarshalIndent(append([]history.Entry{}, entries...), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode history: %w", err)
+		}
+		_, err = fmt.Fprintf(os.Stdout, "%s\n", encoded)
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No builds recorded; enable history in .nomos/config.yaml or build with --history.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tHASH\tPATH")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.ID, e.Time.Local().Format(time.DateTime), e.Hash[:12], e.Path)
+	}
+	return w.Flush()
+}
+
+// historyShowCommand executes the history show subcommand.
+func historyShowCommand(_ *cobra.Command, args []string) error {
+	ref := "latest"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	store := history.Open(history.DefaultDir)
+	entry, err := store.Resolve(ref, time.Now())
+	if err != nil {
+		return err
+	}
+	snapshot, err := store.Load(entry)
+	if err != nil {
+		return err
+	}
+
+	switch serialize.OutputFormat(strings.ToLower(historyShowFlags.format)) {
+	case serialize.FormatJSON:
+		opts := serialize.Options{JSON: serialize.JSONFormat{TrailingNewline: true}}
+		return opts.WriteJSON(os.Stdout, snapshot, false)
+	case serialize.FormatYAML:
+		return serialize.Options{}.WriteYAML(os.Stdout, snapshot, false)
+	default:
+		return fmt.Errorf("invalid format %q (expected json or yaml)", historyShowFlags.format)
+	}
+}
+
+// historyDiffCommand executes the history diff subcommand.
+func historyDiffCommand(_ *cobra.Command, args []string) error {
+	refs := append(append([]string{}, args...), "latest")[:2]
+	store := history.Open(history.DefaultDir)
+	now := time.Now()
+
+	var labels [2]string
+	var snapshots [2]compiler.Snapshot
+	for i, ref := range refs {
+		entry, err := store.Resolve(ref, now)
+		if err != nil {
+			return err
+		}
+		if snapshots[i], err = store.Load(entry); err != nil {
+			return err
+		}
+		labels[i] = fmt.Sprintf("#%d %s", entry.ID, entry.Time.Local().Format(time.DateTime))
+	}
+
+	_, err := writeSnapshotDiff(os.Stdout, labels, snapshots)
+	return err
+}
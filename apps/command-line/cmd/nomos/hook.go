@@ -0,0 +1,197 @@
+// Package main implements the hook command for the Nomos CLI.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/githook"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// hookCmd groups the commands that manage the git pre-commit hook.
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Install a git pre-commit hook that validates staged .csl files",
+	Long: `Hook manages a git pre-commit hook that validates the .csl files staged for
+a commit, so teams can share one supported hook instead of bespoke scripts.
+
+The hook runs 'nomos hook run', which validates each directory containing
+a staged .csl file exactly as 'nomos validate' would, and blocks the
+commit when any of them has errors. Commits without .csl changes cost
+only a git diff of the index. Skip the hook for a single commit with
+'git commit --no-verify'.
+
+The hook is written to the hooks directory git uses for the repository,
+honouring core.hooksPath. A hook installed by other means is never
+replaced or removed unless --force is given.`,
+}
+
+// hookInstallFlags holds all flags for the hook install command
+var hookInstallFlags struct {
+	force   bool
+	command string
+}
+
+// hookInstallCmd represents the hook install command
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook",
+	Long: `Install writes a pre-commit hook to the git repository containing the
+current directory. Reinstalling replaces a hook installed by nomos.
+
+The hook calls nomos from PATH; use --command when nomos is installed
+elsewhere or under another name.
+
+Examples:
+  nomos hook install
+  nomos hook install --command /usr/local/bin/nomos
+  nomos hook install --force   # replace an existing pre-commit hook`,
+	Args: cobra.NoArgs,
+	RunE: hookInstallCommand,
+}
+
+// hookUninstallCmd represents the hook uninstall command
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the pre-commit hook installed by nomos",
+	Args:  cobra.NoArgs,
+	RunE:  hookUninstallCommand,
+}
+
+// hookRunFlags holds all flags for the hook run command
+var hookRunFlags struct {
+	suppressWarnings []string
+}
+
+// hookRunCmd represents the hook run command
+var hookRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Validate the .csl files staged for commit",
+	Long: `Run validates each directory that contains a .csl file added, modified,
+or renamed in the git index. It is what the installed hook executes, and
+can be run by hand before committing.
+
+Directories are validated as they are in the working tree, like
+'nomos validate -p <dir>'.
+
+Exit Codes:
+  0 - No staged .csl files, or all of them validated
+  1 - Validation failed or git could not list the staged files`,
+	Args: cobra.ArbitraryArgs, // Git passes no arguments to pre-commit; tolerate future ones
+	RunE: hookRunCommand,
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookInstallFlags.force, "force", false, "Replace an existing pre-commit hook not installed by nomos")
+	hookInstallCmd.Flags().StringVar(&hookInstallFlags.command, "command", "nomos", "Command the hook runs nomos with")
+	hookRunCmd.Flags().StringSliceVar(&hookRunFlags.suppressWarnings, "suppress-warning", nil, "Suppress warning code, e.g. W001 (repeatable)")
+
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+}
+
+// hookInstallCommand executes the hook install command.
+func hookInstallCommand(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if strings.TrimSpace(hookInstallFlags.command) == "" {
+		return fmt.Errorf("--command must not be empty")
+	}
+	script := githook.Script(hookInstallFlags.command + " hook run")
+	path, err := githook.Install(ctx, projectRoot, githook.PreCommit, script, hookInstallFlags.force)
+	if errors.Is(err, githook.ErrForeignHook) {
+		return compiler.WithHint(err, compiler.Hint{Text: "pass --force to replace it"})
+	}
+	if err != nil {
+		return err
+	}
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Installed pre-commit hook at %s\n", path)
+	}
+	return nil
+}
+
+// hookUninstallCommand executes the hook uninstall command.
+func hookUninstallCommand(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	path, removed, err := githook.Uninstall(ctx, projectRoot, githook.PreCommit)
+	if err != nil {
+		return err
+	}
+	if !globalFlags.quiet {
+		if removed {
+			fmt.Fprintf(os.Stderr, "Removed pre-commit hook %s\n", path)
+		} else {
+			fmt.Fprintf(os.Stderr, "No pre-commit hook installed at %s\n", path)
+		}
+	}
+	return nil
+}
+
+// hookRunCommand executes the hook run command.
+func hookRunCommand(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	files, err := githook.StagedFiles(ctx, projectRoot, ".csl")
+	if err != nil {
+		return fmt.Errorf("cannot list staged files: %w", err)
+	}
+
+	var dirs []string
+	for _, file := range files {
+		if dir := filepath.Dir(file); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+
+	if len(dirs) == 0 {
+		if !globalFlags.quiet {
+			fmt.Fprintf(os.Stderr, "No staged .csl files\n")
+		}
+		return nil
+	}
+
+	failed := 0
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			continue // Staged, then removed from the working tree
+		}
+		result, err := compileForValidation(dir, hookRunFlags.suppressWarnings)
+		if err != nil {
+			return err
+		}
+		if !globalFlags.quiet || result.HasErrors() {
+			printDiagnostics(result)
+		}
+		if result.HasErrors() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("validation failed in %d of %d directories with staged .csl files", failed, len(dirs))
+	}
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Validated %d directories with staged .csl files\n", len(dirs))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/spf13/cobra"
+)
+
+// jsonFormatFlags holds the JSON whitespace and escaping flags shared by
+// build and convert.
+type jsonFormatFlags struct {
+	indent          string
+	minify          bool
+	trailingNewline bool
+	escapeHTML      bool
+}
+
+// jsonFormatFlagNames lists the flags registered by addFlags.
+var jsonFormatFlagNames = []string{"json-indent", "json-minify", "json-trailing-newline", "json-escape-html"}
+
+// addFlags registers the JSON format flags on cmd.
+func (f *jsonFormatFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.indent, "json-indent", "2", "JSON indentation: a number of spaces (1-8) or tab")
+	cmd.Flags().BoolVar(&f.minify, "json-minify", false, "Write JSON without whitespace between tokens")
+	cmd.Flags().BoolVar(&f.trailingNewline, "json-trailing-newline", false, "End JSON output with a newline (default: on stdout only)")
+	cmd.Flags().BoolVar(&f.escapeHTML, "json-escape-html", false, "Escape <, > and & in JSON strings as \\u003c, \\u003e and \\u0026")
+}
+
+// jsonFormat returns the JSON format the flags of cmd select for output in
+// format written to out ("" for stdout). Unless --json-trailing-newline is
+// given, JSON ends with a newline on stdout and without one in files. The
+// flags are refused for other formats.
+func (f *jsonFormatFlags) jsonFormat(cmd *cobra.Command, format, out string) (serialize.JSONFormat, error) {
+	if serialize.OutputFormat(strings.ToLower(format)) != serialize.FormatJSON {
+		for _, name := range jsonFormatFlagNames {
+			if cmd.Flags().Changed(name) {
+				return serialize.JSONFormat{}, fmt.Errorf("--%s applies to json output, not %s", name, format)
+			}
+		}
+		return serialize.JSONFormat{}, nil
+	}
+
+	indent, err := parseJSONIndent(f.indent)
+	if err != nil {
+		return serialize.JSONFormat{}, err
+	}
+	trailingNewline := out == ""
+	if cmd.Flags().Changed("json-trailing-newline") {
+		trailingNewline = f.trailingNewline
+	}
+	return serialize.JSONFormat{
+		Indent:          indent,
+		Minify:          f.minify,
+		TrailingNewline: trailingNewline,
+		EscapeHTML:      f.escapeHTML,
+	}, nil
+}
+
+// parseJSONIndent converts a --json-indent value, a number of spaces or
+// "tab", to the indentation of one level.
+func parseJSONIndent(value string) (string, error) {
+	if strings.EqualFold(value, "tab") {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 8 {
+		return "", fmt.Errorf("invalid --json-indent %q: use a number of spaces from 1 to 8, or tab (--json-minify removes whitespace)", value)
+	}
+	return strings.Repeat(" ", n), nil
+}
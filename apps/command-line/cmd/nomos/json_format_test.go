@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/spf13/cobra"
+)
+
+func TestJSONFormatFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		format  string
+		out     string
+		want    serialize.JSONFormat
+		wantErr string
+	}{
+		{name: "stdout default", format: "json", want: serialize.JSONFormat{Indent: "  ", TrailingNewline: true}},
+		{name: "file default", format: "json", out: "out.json", want: serialize.JSONFormat{Indent: "  "}},
+		{name: "no newline on stdout", args: []string{"--json-trailing-newline=false"}, format: "json", want: serialize.JSONFormat{Indent: "  "}},
+		{name: "newline in file", args: []string{"--json-trailing-newline"}, format: "JSON", out: "out.json", want: serialize.JSONFormat{Indent: "  ", TrailingNewline: true}},
+		{name: "tab", args: []string{"--json-indent", "TAB"}, format: "json", out: "out.json", want: serialize.JSONFormat{Indent: "\t"}},
+		{
+			name:   "minify and escape",
+			args:   []string{"--json-indent=4", "--json-minify", "--json-escape-html"},
+			format: "json", out: "out.json",
+			want: serialize.JSONFormat{Indent: "    ", Minify: true, EscapeHTML: true},
+		},
+		{name: "zero indent", args: []string{"--json-indent", "0"}, format: "json", wantErr: "invalid --json-indent"},
+		{name: "other format", args: []string{"--json-minify"}, format: "yaml", wantErr: "--json-minify applies to json output, not yaml"},
+		{name: "other format defaults", format: "tfvars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var flags jsonFormatFlags
+			cmd := &cobra.Command{}
+			flags.addFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := flags.jsonFormat(cmd, tt.format, tt.out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("jsonFormat() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("jsonFormat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("jsonFormat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,18 +2,38 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
 )
 
 func main() {
-	// Setup color output based on flags
-	setupColorOutput()
-
 	// Execute root command
 	if err := Execute(); err != nil {
 		// Cobra already prints the error, but we control the exit code
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		diagnostics.NewFormatter(styleFor(os.Stderr).Enabled()).PrintHints(os.Stderr, err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCodeError makes nomos exit with code instead of 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit code for an error returned by Execute.
+func exitCode(err error) int {
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
 	}
+	return 1
 }
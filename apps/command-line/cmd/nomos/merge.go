@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// mergeFlags holds all flags for the merge command
+var mergeFlags struct {
+	strategy        string
+	from            string
+	format          string
+	out             string
+	includeMetadata bool
+	keyOrder        string
+	json            jsonFormatFlags
+	files           outputFileFlags
+	template        string
+}
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge <snapshot> <snapshot>...",
+	Short: "Combine snapshots built separately into one",
+	Long: `Merge deep-merges snapshot files produced by separate 'nomos build' runs,
+such as per-domain snapshots of one platform, from left to right. Maps merge
+key by key; other values replace. Provenance, input files, provider aliases,
+and sensitive keys of snapshots built with --include-metadata are combined.
+
+Conflicts:
+  A key path that two snapshots set to different values (two scalars, two
+  lists, or a map and a scalar) is a conflict. --strategy decides:
+    error      - fail listing every conflicting path (default)
+    last-wins  - keep the later snapshot's value, with a warning
+    first-wins - keep the earlier snapshot's value, with a warning
+
+Input and Output Formats:
+  Inputs are JSON or YAML, detected as by 'nomos convert'. Output formats,
+  JSON formatting, key order, and output file flags are those of
+  'nomos convert'; pass --include-metadata to write the merged metadata.
+
+Examples:
+  # Combine per-domain snapshots, failing on conflicts
+  nomos merge network.json data.json -o platform.json
+
+  # Let later snapshots override earlier ones
+  nomos merge base.yaml team.yaml --strategy last-wins --format yaml
+
+Exit Codes:
+  0 - Success
+  1 - Conflicting keys or merge errors
+  2 - Invalid usage or flags`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: mergeCommand,
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeFlags.strategy, "strategy", "error", "Conflict strategy: error, last-wins, or first-wins")
+	mergeCmd.Flags().StringVar(&mergeFlags.from, "from", "", "Input format of every snapshot: json or yaml (default: detected from extension)")
+	mergeCmd.Flags().StringVarP(&mergeFlags.format, "format", "f", "json", "Output format: json, yaml, tfvars, template, or custom:<name>")
+	mergeCmd.Flags().StringVarP(&mergeFlags.out, "out", "o", "", "Output file (default: stdout)")
+	mergeCmd.Flags().BoolVar(&mergeFlags.includeMetadata, "include-metadata", false, "Include merged snapshot metadata in output")
+	mergeCmd.Flags().StringVar(&mergeFlags.keyOrder, "key-order", "", "Map key order: alphabetical or priority:<key>,<key>... (default alphabetical)")
+	mergeFlags.json.addFlags(mergeCmd)
+	mergeFlags.files.addFlags(mergeCmd)
+	mergeCmd.Flags().StringVar(&mergeFlags.template, "template", "", "Go text/template file rendered by --format template")
+}
+
+// mergeCommand executes the merge subcommand.
+func mergeCommand(cmd *cobra.Command, args []string) error {
+	strategy, err := compiler.ParseMergeStrategy(mergeFlags.strategy)
+	if err != nil {
+		return err
+	}
+
+	// Load project-level settings (.nomos/config.yaml) for custom serializers
+	// and file types
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+	types, err := newFileTypes(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	var merged compiler.Snapshot
+	var conflicts []string
+	for i, input := range args {
+		from, err := snapshotInputFormat(input, mergeFlags.from, types)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(input) //nolint:gosec // G304: Path is provided by the user
+		if err != nil {
+			return fmt.Errorf("cannot read snapshot: %w", err)
+		}
+		snapshot, err := serialize.Decode(data, from)
+		if err != nil {
+			return fmt.Errorf("%s: %w", input, err)
+		}
+
+		if i == 0 {
+			merged = snapshot
+			continue
+		}
+		previous := merged
+		merged, err = compiler.MergeSnapshots(previous, snapshot, strategy)
+		if err != nil {
+			var conflictErr *compiler.MergeConflictError
+			if errors.As(err, &conflictErr) {
+				return compiler.WithHint(fmt.Errorf("cannot merge %s: %w", input, err),
+					compiler.Hint{Text: "pass --strategy last-wins or first-wins to pick a side"})
+			}
+			return fmt.Errorf("cannot merge %s: %w", input, err)
+		}
+
+		// Report the conflicts resolved by the strategy, not the
+		// warnings the inputs were built with
+		for _, w := range merged.Metadata.Warnings {
+			if !slices.Contains(previous.Metadata.Warnings, w) && !slices.Contains(snapshot.Metadata.Warnings, w) {
+				conflicts = append(conflicts, w)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 && !globalFlags.quiet {
+		diagnostics.NewFormatter(styleFor(os.Stderr).Enabled()).PrintWarnings(os.Stderr, conflicts)
+	}
+
+	serializers, err := newSerializerRegistry(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	keyOrder, err := snapshotKeyOrder(mergeFlags.format, mergeFlags.keyOrder, projectCfg)
+	if err != nil {
+		return err
+	}
+
+	jsonFormat, err := mergeFlags.json.jsonFormat(cmd, mergeFlags.format, mergeFlags.out)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := loadTemplate(mergeFlags.format, mergeFlags.template)
+	if err != nil {
+		return err
+	}
+
+	output, err := serializeSnapshot(merged, mergeFlags.format, mergeFlags.includeMetadata, serializers, serialize.Options{KeyOrder: keyOrder, JSON: jsonFormat, Template: tmpl})
+	if err != nil {
+		return fmt.Errorf("failed to serialize output: %w", err)
+	}
+
+	return writeOutput(output, mergeFlags.out, mergeFlags.format, types, mergeFlags.files)
+}
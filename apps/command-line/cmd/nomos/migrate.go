@@ -0,0 +1,143 @@
+// Package main implements the migrate command for the Nomos CLI.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/golden"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/migrate"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/traverse"
+	"github.com/spf13/cobra"
+)
+
+// migrateFlags holds all flags for the migrate command
+var migrateFlags struct {
+	path   string
+	dryRun bool
+}
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite deprecated syntax in .csl files",
+	Long: `Migrate rewrites deprecated constructs in .csl files into the syntax the
+parser supports, so files written for older releases compile again.
+
+Rewrites:
+  import:base                        →  @base:*
+  import:base:app.settings           →  @base:app.settings
+  reference:net:config:vpc.cidr      →  vpc_cidr: @net:config.vpc.cidr
+    host: reference:db:primary.host  →    host: @db:primary.host
+
+A top-level reference statement becomes a section named after the last
+field of its path, with dots replaced by underscores; rename it where
+another name fits better. Bare reference: values, which are now read as
+text, become inline references; quoted ones are left alone.
+
+Only the deprecated constructs change: comments, indentation, and line
+endings stay as written. Every file must parse after migration, or no file
+is written. Running migrate again changes nothing.
+
+Examples:
+  nomos migrate -p config/ --dry-run   # show the changes as a diff
+  nomos migrate -p config/`,
+	Args: cobra.NoArgs,
+	RunE: migrateCommand,
+}
+
+func init() {
+	migrateCmd.Flags().StringVarP(&migrateFlags.path, "path", "p", "", "Path to .csl file or directory to migrate (required)")
+	_ = migrateCmd.MarkFlagRequired("path") // Error only occurs if flag doesn't exist
+	migrateCmd.Flags().BoolVar(&migrateFlags.dryRun, "dry-run", false, "Print the changes as a diff without writing files")
+}
+
+// migratedFile is a file with deprecated syntax and its migration.
+type migratedFile struct {
+	path   string
+	src    []byte
+	result *migrate.Result
+}
+
+// migrateCommand executes the migrate command.
+func migrateCommand(_ *cobra.Command, _ []string) error {
+	files, err := traverse.DiscoverFiles(migrateFlags.path)
+	if err != nil {
+		return err
+	}
+
+	// Migrate every file before writing any, so a file that cannot be
+	// migrated leaves the tree untouched
+	var migrated []migratedFile
+	for _, file := range files {
+		//nolint:gosec // G304: Path comes from user CLI input
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", displayPath(file), err)
+		}
+		result, err := migrate.Source(file, src)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", displayPath(file), err)
+		}
+		if len(result.Changes) > 0 {
+			migrated = append(migrated, migratedFile{path: file, src: src, result: result})
+		}
+	}
+
+	if migrateFlags.dryRun {
+		if globalFlags.quiet {
+			return nil
+		}
+		return writeMigrationDiff(os.Stdout, migrated, len(files))
+	}
+
+	for _, m := range migrated {
+		info, err := os.Stat(m.path)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", displayPath(m.path), err)
+		}
+		//nolint:gosec // G306: Keeps the permissions of the user's file
+		if err := os.WriteFile(m.path, m.result.Source, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", displayPath(m.path), err)
+		}
+		if !globalFlags.quiet {
+			fmt.Printf("Migrated %s (%s)\n", displayPath(m.path), plural(len(m.result.Changes), "change"))
+		}
+	}
+	if len(migrated) == 0 && !globalFlags.quiet {
+		fmt.Printf("No deprecated syntax found in %s\n", plural(len(files), "file"))
+	}
+	return nil
+}
+
+// writeMigrationDiff prints the changes migration would make to each file
+// as a diff, followed by a count of the files to migrate.
+func writeMigrationDiff(w io.Writer, migrated []migratedFile, total int) error {
+	if len(migrated) == 0 {
+		_, err := fmt.Fprintf(w, "No deprecated syntax found in %s\n", plural(total, "file"))
+		return err
+	}
+
+	style := styleFor(w)
+	changes := 0
+	for _, m := range migrated {
+		name := displayPath(m.path)
+		diff := golden.Diff(string(m.src), string(m.result.Source))
+		if _, err := fmt.Fprintf(w, "%s\n%s\n%s", style.Header("--- "+name), style.Header("+++ "+name+" (migrated)"), style.Diff(diff)); err != nil {
+			return err
+		}
+		changes += len(m.result.Changes)
+	}
+	_, err := fmt.Fprintf(w, "\n%s in %s would be migrated; run without --dry-run to write them\n",
+		plural(changes, "change"), plural(len(migrated), "file"))
+	return err
+}
+
+// plural formats a count of noun, adding "s" unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
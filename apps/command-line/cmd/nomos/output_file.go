@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// backupTimeLayout names backups of replaced output files. It sorts
+// chronologically and contains no characters that need quoting in a shell.
+const backupTimeLayout = "20060102T150405Z"
+
+// outputFileFlags holds the flags that protect existing output files,
+// shared by build and convert.
+type outputFileFlags struct {
+	noClobber bool
+	backup    bool
+
+	// manifest, when set, records every file written for --checksums.
+	manifest *checksumManifest
+
+	// summary, when set, records every file written and its size.
+	summary *buildSummary
+}
+
+// addFlags registers the output file flags on cmd.
+func (f *outputFileFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.noClobber, "no-clobber", false, "Fail instead of replacing an existing output file")
+	cmd.Flags().BoolVar(&f.backup, "backup", false, "Keep an existing output file as <file>.<UTC timestamp>.bak before replacing it")
+	cmd.MarkFlagsMutuallyExclusive("no-clobber", "backup")
+}
+
+// writeFile writes the output of encode to path. The output goes to a
+// temporary file in the destination directory that is synced to disk and
+// then renamed over path, so readers see either the old file or the complete
+// new one, never a truncated file. Errors returned by encode are passed
+// through unchanged and leave any existing file untouched.
+func (f outputFileFlags) writeFile(path string, encode func(io.Writer) error) error {
+	if f.noClobber {
+		if _, err := os.Lstat(path); err == nil {
+			return errOutputExists(path)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("cannot create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	digest := sha256.New()
+	written := &countingWriter{w: io.MultiWriter(tmp, digest)}
+	if err := encode(written); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+
+	if f.backup {
+		if err := backupFile(path, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if f.noClobber {
+		// A hard link fails if path appeared while encoding, where a rename
+		// would silently replace it
+		err = os.Link(tmp.Name(), path)
+		if errors.Is(err, fs.ErrExist) {
+			return errOutputExists(path)
+		}
+	} else {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+
+	syncDir(dir)
+	if f.manifest != nil {
+		f.manifest.add(path, digest.Sum(nil))
+	}
+	f.summary.addArtifact(path, written.n)
+	return nil
+}
+
+// backupFile copies the file at path, if there is one, to
+// <path>.<timestamp>.bak. An existing backup of the same name is never
+// replaced.
+func backupFile(path string, now time.Time) error {
+	src, err := os.Open(path) //nolint:gosec // G304: Path is the user's output file
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	defer func() { _ = src.Close() }()
+
+	backupPath := path + "." + now.UTC().Format(backupTimeLayout) + ".bak"
+	dst, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // G304: Path derives from the user's output file
+	if err != nil {
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	if err := dst.Sync(); err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Backup written to %s\n", backupPath)
+	}
+	return nil
+}
+
+// syncDir flushes the directory entry of a renamed file to disk. Platforms
+// that cannot sync directories are left to the file system's own ordering.
+func syncDir(dir string) {
+	d, err := os.Open(dir) //nolint:gosec // G304: Directory of the user's output file
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// errOutputExists reports an output file that --no-clobber keeps.
+func errOutputExists(path string) error {
+	return compiler.WithHint(fmt.Errorf("%s already exists", path), compiler.Hint{Text: "remove it, or drop --no-clobber"})
+}
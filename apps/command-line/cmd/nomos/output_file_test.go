@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOutputFileFlags_WriteFile tests replacing, refusing to replace, and
+// backing up existing output files.
+func TestOutputFileFlags_WriteFile(t *testing.T) {
+	globalFlags.quiet = true
+	t.Cleanup(func() { globalFlags.quiet = false })
+
+	content := func(s string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // G304: Test file in a temp dir
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	entries := func(t *testing.T, dir string) []string {
+		t.Helper()
+		list, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(list))
+		for i, e := range list {
+			names[i] = e.Name()
+		}
+		return names
+	}
+
+	t.Run("replaces existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "out.json")
+		if err := (outputFileFlags{}).writeFile(path, content("old")); err != nil {
+			t.Fatal(err)
+		}
+		if err := (outputFileFlags{}).writeFile(path, content("new")); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(t, path); got != "new" {
+			t.Errorf("content = %q, want new", got)
+		}
+		if got := entries(t, filepath.Dir(path)); len(got) != 1 {
+			t.Errorf("directory holds %v, want only out.json", got)
+		}
+	})
+
+	t.Run("failed encoding keeps existing file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.json")
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		errEncode := errors.New("encode failed")
+		err := (outputFileFlags{}).writeFile(path, func(w io.Writer) error {
+			_, _ = io.WriteString(w, "partial")
+			return errEncode
+		})
+		if !errors.Is(err, errEncode) {
+			t.Fatalf("writeFile error = %v, want %v", err, errEncode)
+		}
+		if got := read(t, path); got != "old" {
+			t.Errorf("content = %q, want old", got)
+		}
+		if got := entries(t, dir); len(got) != 1 {
+			t.Errorf("directory holds %v, want only out.json", got)
+		}
+	})
+
+	t.Run("no clobber", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.json")
+		files := outputFileFlags{noClobber: true}
+		if err := files.writeFile(path, content("first")); err != nil {
+			t.Fatalf("writeFile to a new file error = %v", err)
+		}
+		err := files.writeFile(path, content("second"))
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("writeFile error = %v, want already exists", err)
+		}
+		if got := read(t, path); got != "first" {
+			t.Errorf("content = %q, want first", got)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.json")
+		files := outputFileFlags{backup: true}
+		if err := files.writeFile(path, content("old")); err != nil {
+			t.Fatal(err)
+		}
+		if got := entries(t, dir); len(got) != 1 {
+			t.Errorf("new file was backed up: %v", got)
+		}
+		if err := files.writeFile(path, content("new")); err != nil {
+			t.Fatal(err)
+		}
+		backups, _ := filepath.Glob(path + ".*.bak")
+		if len(backups) != 1 {
+			t.Fatalf("backups = %v, want one", backups)
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(backups[0], path+"."), ".bak")
+		if _, err := time.Parse(backupTimeLayout, stamp); err != nil {
+			t.Errorf("backup name %s: %v", backups[0], err)
+		}
+		if got := read(t, backups[0]); got != "old" {
+			t.Errorf("backup content = %q, want old", got)
+		}
+		if got := read(t, path); got != "new" {
+			t.Errorf("content = %q, want new", got)
+		}
+	})
+}
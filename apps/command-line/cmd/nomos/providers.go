@@ -2,11 +2,19 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -22,52 +30,187 @@ var providersCmd = &cobra.Command{
 // providersListCmd represents the providers list command
 var providersListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List installed providers",
-	Long:  `List all providers installed in the .nomos/providers directory`,
-	RunE:  providersListCommand,
+	Short: "List declared and installed providers",
+	Long: `List the providers declared in .csl files under --path together with their
+entries in .nomos/providers.lock.json for the host platform.
+
+The STATE column reports whether each provider is ready to use:
+  installed  - the locked binary is present and matches its checksum
+  not-locked - declared but not yet installed; run 'nomos build'
+  missing    - locked, but the binary is not in the providers directory
+               (.nomos/providers, or providers/ under --nomos-dir)
+  modified   - the binary does not match the locked checksum
+  unused     - locked, but no .csl file declares it`,
+	RunE: providersListCommand,
+}
+
+// providersInfoCmd represents the providers info command
+var providersInfoCmd = &cobra.Command{
+	Use:   "info <alias>",
+	Short: "Show details for one provider",
+	Long: `Show the lockfile details of one provider for the host platform: type,
+version, release URL, asset name, size, checksum, install path, install
+state, and when the binary was last verified against its release.`,
+	Args: cobra.ExactArgs(1),
+	RunE: providersInfoCommand,
+}
+
+// providersMirrorCmd represents the providers mirror command
+var providersMirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Download providers for several platforms into a mirror directory",
+	Long: `Download the provider binaries declared in .csl files under --path for each
+--platform into a mirror directory, and record them in <dir>/manifest.json.
+
+The mirror uses the same layout as .nomos/providers, so it can be produced on
+one machine (for example a linux CI runner) and copied to developers on other
+platforms. Builds install from it without network access via
+'nomos build --provider-mirror <dir>'.
+
+Binaries already in the mirror with a matching checksum are kept unless
+--force is given.`,
+	Example: `  # Fetch providers for CI and for Apple Silicon laptops
+  nomos providers mirror -p ./config --platform linux/amd64 --platform darwin/arm64 --out ./provider-mirror
+
+  # Install from the mirror offline
+  nomos build -p ./config --provider-mirror ./provider-mirror`,
+	RunE: providersMirrorCommand,
+}
+
+// providersResolveCmd represents the providers resolve command
+var providersResolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Align provider aliases declared at different versions",
+	Long: `List each provider alias declared at more than one version in .csl files
+under --path, with the file and line of every declaration, and propose the
+highest release version declared. After confirmation, the version fields of
+the other declarations are rewritten to the proposed version.
+
+A file declaring one alias at two versions fails the build; versions that
+differ between files are allowed but run one provider per version.
+Declarations pinned by digest are listed but not rewritten.`,
+	Example: `  # Review and confirm the proposed versions
+  nomos providers resolve -p ./config
+
+  # Rewrite without prompting
+  nomos providers resolve -p ./config --yes`,
+	RunE: providersResolveCommand,
+}
+
+// providersDoctorCmd represents the providers doctor command
+var providersDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that providers start and are ready to use",
+	Long: `Start each provider declared in .csl files under --path, and each lockfile
+entry for the host platform, and report whether it is ready to use, with a
+hint for every problem found. For each provider doctor checks that:
+
+  install  - the locked binary is present and matches its checksum
+  platform - the binary is built for the host OS and architecture
+  start    - the binary starts and reports its port (its stderr is shown
+             when it fails)
+  health   - the Health RPC reports ok
+  config   - Init accepts the configuration of its source declaration
+             (skipped when the configuration uses references or functions)
+  version  - the Info RPC reports the locked version
+
+Warnings do not make a provider unready.`,
+	Example: `  # Diagnose the providers of a project
+  nomos providers doctor -p ./config
+
+  # Machine-readable report for CI
+  nomos providers doctor -p ./config --json`,
+	RunE: providersDoctorCommand,
+}
+
+var providersMirrorFlags struct {
+	path            string
+	out             string
+	platforms       []string
+	force           bool
+	timeout         string
+	allowLatest     bool
+	allowPrerelease bool
+	allowYanked     bool
+}
+
+var providersResolveFlags struct {
+	path       string
+	yes        bool
+	dryRun     bool
+	jsonOutput bool
+}
+
+var providersDoctorFlags struct {
+	path       string
+	jsonOutput bool
+	timeout    string
 }
 
 var providersListFlags struct {
+	path       string
+	jsonOutput bool
+}
+
+var providersInfoFlags struct {
+	path       string
 	jsonOutput bool
 }
 
 func init() {
 	providersCmd.AddCommand(providersListCmd)
+	providersListCmd.Flags().StringVarP(&providersListFlags.path, "path", "p", ".", "Path to .csl file or directory declaring providers")
 	providersListCmd.Flags().BoolVar(&providersListFlags.jsonOutput, "json", false, "Output as JSON")
-}
 
-// providersListCommand executes the providers list subcommand.
-func providersListCommand(_ *cobra.Command, _ []string) error {
-	// Read lockfile
-	lockPath := filepath.Join(".nomos", "providers.lock.json")
+	providersCmd.AddCommand(providersInfoCmd)
+	providersInfoCmd.Flags().StringVarP(&providersInfoFlags.path, "path", "p", ".", "Path to .csl file or directory declaring providers")
+	providersInfoCmd.Flags().BoolVar(&providersInfoFlags.jsonOutput, "json", false, "Output as JSON")
+
+	providersCmd.AddCommand(providersMirrorCmd)
+	providersMirrorCmd.Flags().StringVarP(&providersMirrorFlags.path, "path", "p", ".", "Path to .csl file or directory declaring providers")
+	providersMirrorCmd.Flags().StringVarP(&providersMirrorFlags.out, "out", "o", "provider-mirror", "Mirror directory to populate")
+	providersMirrorCmd.Flags().StringSliceVar(&providersMirrorFlags.platforms, "platform", nil, "Target platform as os/arch (repeatable, default: host platform)")
+	providersMirrorCmd.Flags().BoolVar(&providersMirrorFlags.force, "force", false, "Re-download binaries already in the mirror")
+	providersMirrorCmd.Flags().BoolVar(&providersMirrorFlags.allowLatest, "allow-latest", false, "Resolve providers declared with version 'latest'")
+	providersMirrorCmd.Flags().BoolVar(&providersMirrorFlags.allowPrerelease, "allow-prerelease", false, "Resolve providers declared with version 'prerelease'")
+	providersMirrorCmd.Flags().BoolVar(&providersMirrorFlags.allowYanked, "allow-yanked", false, "Mirror provider releases their authors have yanked")
+	providersMirrorCmd.Flags().StringVar(&providersMirrorFlags.timeout, "timeout-per-provider", "30s", "Timeout for each download (e.g., 5s, 1m)")
+
+	providersCmd.AddCommand(providersResolveCmd)
+	providersResolveCmd.Flags().StringVarP(&providersResolveFlags.path, "path", "p", ".", "Path to .csl file or directory declaring providers")
+	providersResolveCmd.Flags().BoolVarP(&providersResolveFlags.yes, "yes", "y", false, "Rewrite files without asking for confirmation")
+	providersResolveCmd.Flags().BoolVar(&providersResolveFlags.dryRun, "dry-run", false, "List conflicts and proposed versions without rewriting files")
+	providersResolveCmd.Flags().BoolVar(&providersResolveFlags.jsonOutput, "json", false, "Output conflicts as JSON without rewriting files")
+
+	providersCmd.AddCommand(providersDoctorCmd)
+	providersDoctorCmd.Flags().StringVarP(&providersDoctorFlags.path, "path", "p", ".", "Path to .csl file or directory declaring providers")
+	providersDoctorCmd.Flags().BoolVar(&providersDoctorFlags.jsonOutput, "json", false, "Output the report as JSON")
+	providersDoctorCmd.Flags().StringVar(&providersDoctorFlags.timeout, "timeout-per-provider", "10s", "Timeout for starting and checking each provider (e.g., 5s, 1m)")
+}
 
-	//nolint:gosec // G304: Path is hardcoded to .nomos/providers.lock.json, safe
-	data, err := os.ReadFile(lockPath)
+// loadProviderInfos lists the providers declared under path joined with
+// the lockfile, which may be absent.
+func loadProviderInfos(path string) ([]providercmd.ProviderInfo, error) {
+	lock, err := providercmd.ReadLockFile()
 	if err != nil {
-		if os.IsNotExist(err) {
-			if !globalFlags.quiet {
-				fmt.Println("No providers installed. Run 'nomos build' to install providers.")
-			}
-			return nil
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
 		}
-		return fmt.Errorf("failed to read lockfile: %w", err)
-	}
-
-	var lock providercmd.LockFile
-	if err := json.Unmarshal(data, &lock); err != nil {
-		return fmt.Errorf("failed to parse lockfile: %w", err)
+		lock = nil
 	}
+	return providercmd.ListProviders([]string{path}, lock, "", "")
+}
 
-	if len(lock.Providers) == 0 {
-		if !globalFlags.quiet {
-			fmt.Println("No providers installed.")
-		}
-		return nil
+// providersListCommand executes the providers list subcommand.
+func providersListCommand(_ *cobra.Command, _ []string) error {
+	infos, err := loadProviderInfos(providersListFlags.path)
+	if err != nil {
+		return err
 	}
 
 	// JSON output
 	if providersListFlags.jsonOutput {
-		output, err := json.MarshalIndent(lock.Providers, "", "  ")
+		output, err := json.MarshalIndent(infos, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal JSON: %w", err)
 		}
@@ -75,12 +218,19 @@ func providersListCommand(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	if len(infos) == 0 {
+		if !globalFlags.quiet {
+			fmt.Println("No providers declared or installed.")
+		}
+		return nil
+	}
+
 	// Table output
 	table := tablewriter.NewWriter(os.Stdout)
-	table.Header("Alias", "Type", "Version", "OS", "Arch", "Path")
+	table.Header("Alias", "Type", "Version", "Checksum", "State")
 
-	for _, p := range lock.Providers {
-		if err := table.Append(p.Alias, p.Type, p.Version, p.OS, p.Arch, p.Path); err != nil {
+	for _, p := range infos {
+		if err := table.Append(p.Alias, p.Type, p.Version, shortChecksum(p.Checksum), string(p.State)); err != nil {
 			return fmt.Errorf("failed to append table row: %w", err)
 		}
 	}
@@ -90,8 +240,330 @@ func providersListCommand(_ *cobra.Command, _ []string) error {
 	}
 
 	if !globalFlags.quiet {
-		fmt.Printf("\nTotal: %d provider(s)\n", len(lock.Providers))
+		fmt.Printf("\nTotal: %d provider(s)\n", len(infos))
+	}
+
+	return nil
+}
+
+// providersInfoCommand executes the providers info subcommand.
+func providersInfoCommand(_ *cobra.Command, args []string) error {
+	infos, err := loadProviderInfos(providersInfoFlags.path)
+	if err != nil {
+		return err
+	}
+
+	alias := args[0]
+	var info *providercmd.ProviderInfo
+	for i := range infos {
+		if infos[i].Alias == alias {
+			info = &infos[i]
+			break
+		}
+	}
+	if info == nil {
+		return fmt.Errorf("provider %q is not declared under %s or locked for this platform", alias, providersInfoFlags.path)
+	}
+
+	if providersInfoFlags.jsonOutput {
+		output, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	rows := [][2]string{
+		{"Alias", info.Alias},
+		{"Type", info.Type},
+		{"Version", info.Version},
+		{"Platform", info.OS + "/" + info.Arch},
+		{"State", string(info.State)},
+		{"Release status", releaseStatus(info)},
+		{"Release", info.ReleaseURL},
+		{"Asset", info.Asset},
+		{"Size", formatSize(info.Size)},
+		{"Checksum", info.Checksum},
+		{"Path", info.Path},
+		{"Shared by", strings.Join(info.SharedBy, ", ")},
+		{"Last verified", info.VerifiedAt},
+	}
+	for _, row := range rows {
+		value := row[1]
+		if value == "" {
+			value = "-"
+		}
+		fmt.Printf("%-14s %s\n", row[0]+":", value)
+	}
+	return nil
+}
+
+// providersMirrorCommand executes the providers mirror subcommand.
+func providersMirrorCommand(_ *cobra.Command, _ []string) error {
+	platforms := make([]providercmd.Platform, 0, len(providersMirrorFlags.platforms))
+	for _, s := range providersMirrorFlags.platforms {
+		platform, err := providercmd.ParsePlatform(s)
+		if err != nil {
+			return err
+		}
+		platforms = append(platforms, platform)
+	}
+
+	timeout, err := time.ParseDuration(providersMirrorFlags.timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout duration %q: %w", providersMirrorFlags.timeout, err)
 	}
 
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+
+	result, err := providercmd.MirrorProviders(providercmd.MirrorOptions{
+		Paths:            []string{providersMirrorFlags.path},
+		Dir:              providersMirrorFlags.out,
+		Platforms:        platforms,
+		Force:            providersMirrorFlags.force,
+		Timeout:          timeout,
+		CredentialHelper: projectCfg.CredentialHelper,
+		AllowLatest:      providersMirrorFlags.allowLatest,
+		AllowPrerelease:  providersMirrorFlags.allowPrerelease,
+		AllowYanked:      providersMirrorFlags.allowYanked,
+	})
+	if result != nil && !globalFlags.quiet {
+		for _, r := range result.Results {
+			fmt.Fprintf(os.Stderr, "  %s@%s %s/%s: %s\n", r.Type, r.Version, r.OS, r.Arch, r.Status)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", result.Summary.String())
+	}
+	if err != nil {
+		return fmt.Errorf("provider mirror failed: %w", err)
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Mirror written to %s\n", providersMirrorFlags.out)
+	}
 	return nil
 }
+
+// providersResolveCommand executes the providers resolve subcommand.
+func providersResolveCommand(cmd *cobra.Command, _ []string) error {
+	conflicts, err := providercmd.FindVersionConflicts([]string{providersResolveFlags.path})
+	if err != nil {
+		return err
+	}
+
+	if providersResolveFlags.jsonOutput {
+		output, err := json.MarshalIndent(conflicts, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(conflicts) == 0 {
+		if !globalFlags.quiet {
+			fmt.Println("No provider version conflicts found.")
+		}
+		return nil
+	}
+
+	changes, files := 0, make(map[string]bool)
+	for _, c := range conflicts {
+		printVersionConflict(c)
+		for _, d := range c.Changes() {
+			changes++
+			files[d.File] = true
+		}
+	}
+
+	if changes == 0 {
+		fmt.Println("Nothing to rewrite; update the versions listed above by hand.")
+		return nil
+	}
+	if providersResolveFlags.dryRun {
+		fmt.Printf("%d declaration(s) in %d file(s) would be rewritten.\n", changes, len(files))
+		return nil
+	}
+	if !providersResolveFlags.yes {
+		fmt.Printf("Rewrite %d declaration(s) in %d file(s) to the proposed versions? [y/N]: ", changes, len(files))
+		answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil {
+			// No newline was read; end the prompt line
+			fmt.Println()
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("No files changed.")
+			return nil
+		}
+	}
+
+	rewritten, err := providercmd.ResolveVersionConflicts(conflicts)
+	if err != nil {
+		return fmt.Errorf("provider resolve failed after rewriting %d declaration(s): %w", rewritten, err)
+	}
+	fmt.Printf("Rewrote %d declaration(s). Run 'nomos build' to install the new versions.\n", rewritten)
+	return nil
+}
+
+// providersDoctorCommand executes the providers doctor subcommand.
+func providersDoctorCommand(_ *cobra.Command, _ []string) error {
+	timeout, err := time.ParseDuration(providersDoctorFlags.timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout duration %q: %w", providersDoctorFlags.timeout, err)
+	}
+
+	lock, err := providercmd.ReadLockFile()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		lock = nil
+	}
+
+	reports, err := providercmd.Diagnose(context.Background(), providercmd.DoctorOptions{
+		Paths:   []string{providersDoctorFlags.path},
+		Lock:    lock,
+		Timeout: timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	notReady := 0
+	for _, r := range reports {
+		if !r.Ready() {
+			notReady++
+		}
+	}
+
+	if providersDoctorFlags.jsonOutput {
+		output, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	} else if len(reports) == 0 {
+		if !globalFlags.quiet {
+			fmt.Println("No providers declared or installed.")
+		}
+		return nil
+	} else {
+		for _, r := range reports {
+			if globalFlags.quiet && r.Ready() {
+				continue
+			}
+			printDiagnosis(r)
+		}
+		if !globalFlags.quiet {
+			fmt.Printf("%d of %d provider(s) ready\n", len(reports)-notReady, len(reports))
+		}
+	}
+
+	if notReady > 0 {
+		return fmt.Errorf("%d provider(s) not ready", notReady)
+	}
+	return nil
+}
+
+// printDiagnosis prints the readiness checks of one provider with the hint
+// of each problem found.
+func printDiagnosis(r providercmd.ProviderDiagnosis) {
+	verdict := "ready"
+	if !r.Ready() {
+		verdict = "NOT READY"
+	}
+	fmt.Printf("%s (%s %s): %s\n", r.Alias, r.Type, r.Version, verdict)
+	for _, c := range r.Checks {
+		line := fmt.Sprintf("  [%-4s] %-8s", c.Status, c.Name)
+		if c.Detail != "" {
+			line += " " + c.Detail
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+		if c.Hint != "" && (c.Status == providercmd.CheckFail || c.Status == providercmd.CheckWarn) {
+			fmt.Printf("         hint: %s\n", c.Hint)
+		}
+	}
+	if r.Stderr != "" {
+		fmt.Println("  provider stderr:")
+		for _, line := range strings.Split(strings.TrimRight(r.Stderr, "\n"), "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+	fmt.Println()
+}
+
+// printVersionConflict lists the declarations of one conflicting alias and
+// the change proposed for each.
+func printVersionConflict(c providercmd.VersionConflict) {
+	fmt.Printf("%s (%s):\n", c.Alias, c.Type)
+	for _, d := range c.Declarations {
+		location := fmt.Sprintf("%s:%d", displayPath(d.File), d.Line)
+		change := d.Version
+		switch {
+		case d.Version == c.Proposed || c.Proposed == "":
+		case d.Digest != "":
+			change += " (pinned by digest; update by hand)"
+		case d.Checksum != "":
+			change += " (pinned by checksum; update by hand)"
+		default:
+			change += " -> " + c.Proposed
+			if providercmd.IsMajorChange(d.Version, c.Proposed) {
+				change += " (major upgrade)"
+			}
+		}
+		fmt.Printf("  %-28s %s\n", location, change)
+	}
+	switch {
+	case c.Proposed == "":
+		fmt.Println("  No release version declared; pin one by hand.")
+	case c.InFile:
+		fmt.Printf("  Proposed: %s (required: a file declares several versions)\n", c.Proposed)
+	default:
+		fmt.Printf("  Proposed: %s\n", c.Proposed)
+	}
+}
+
+// displayPath returns path relative to the working directory when it is
+// inside it.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// releaseStatus describes a deprecated or yanked release, or returns "".
+func releaseStatus(info *providercmd.ProviderInfo) string {
+	if info.ReleaseMessage == "" {
+		return info.ReleaseStatus
+	}
+	return info.ReleaseStatus + " (" + info.ReleaseMessage + ")"
+}
+
+// shortChecksum abbreviates a checksum for table display.
+func shortChecksum(checksum string) string {
+	checksum = strings.TrimPrefix(checksum, "sha256:")
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
+
+// formatSize formats a byte count, or returns "" when it is unknown.
+func formatSize(n int64) string {
+	if n <= 0 {
+		return ""
+	}
+	if n < 1<<20 {
+		return strconv.FormatInt(n, 10) + " bytes"
+	}
+	return fmt.Sprintf("%.1f MB (%d bytes)", float64(n)/(1<<20), n)
+}
@@ -0,0 +1,97 @@
+// Package main implements remote compilation for the build command.
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/remote"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// remoteTokenEnvVar holds the bearer token sent to the --remote server.
+const remoteTokenEnvVar = "NOMOS_REMOTE_TOKEN"
+
+// remoteIncompatibleFlags are the build flags that need local providers or
+// local files the server cannot read.
+var remoteIncompatibleFlags = []string{
+	"policy",
+	"encryption-key",
+	"provider-config",
+	"stdin-config",
+	"record-providers",
+	"replay-providers",
+	"debug-dump",
+	"dry-run",
+}
+
+// compileRemote compiles path on the nomosd server at --remote. It sends
+// the .csl files beneath the project root, or just the --at revision for
+// the server to read from its own repository, with the var files and
+// overrides of the build. The returned result carries the server's
+// snapshot; its located diagnostics are returned separately.
+func compileRemote(ctx context.Context, path string, sourceMap bool, projectCfg projectconfig.Config, bench *benchReport) (compiler.CompilationResult, []compiler.Diagnostic, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return compiler.CompilationResult{}, nil, fmt.Errorf("invalid path: %w", err)
+	}
+	rel, err := filepath.Rel(projectRoot, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return compiler.CompilationResult{}, nil, fmt.Errorf("--path %s must be inside the project root %s to build remotely", path, projectRoot)
+	}
+
+	req := remote.CompileRequest{
+		Path:                 filepath.ToSlash(rel),
+		Ref:                  buildFlags.at,
+		Vars:                 buildFlags.vars,
+		Sets:                 buildFlags.sets,
+		Profiles:             buildFlags.profiles,
+		SuppressWarnings:     append(projectCfg.Warnings.Suppress, buildFlags.suppressWarnings...),
+		TypeCoercion:         cmp.Or(buildFlags.typeCoercion, projectCfg.TypeCoercion),
+		AllowMissingProvider: buildFlags.allowMissingProvider,
+		PartialFailure:       buildFlags.partialFailure,
+		FetchRetries:         buildFlags.fetchRetries,
+		SourceMap:            sourceMap,
+		MaxSnapshotBytes:     buildFlags.maxSnapshotBytes,
+		SourceDateEpoch:      os.Getenv("SOURCE_DATE_EPOCH"),
+		Reproducible:         buildFlags.reproducible,
+	}
+	for _, varFile := range buildFlags.varFiles {
+		content, err := os.ReadFile(varFile) //nolint:gosec // G304: Var file path is provided by the user
+		if err != nil {
+			return compiler.CompilationResult{}, nil, fmt.Errorf("failed to read var file: %w", err)
+		}
+		req.VarFiles = append(req.VarFiles, string(content))
+	}
+	if req.Ref == "" {
+		if req.Files, err = remote.Bundle(projectRoot); err != nil {
+			return compiler.CompilationResult{}, nil, err
+		}
+	}
+
+	client := remote.Client{Addr: buildFlags.remote, Token: os.Getenv(remoteTokenEnvVar)}
+	start := time.Now()
+	resp, err := client.Compile(ctx, req)
+	if err != nil {
+		return compiler.CompilationResult{}, nil, err
+	}
+	bench.add("compile", time.Since(start), inputSize(resp.Snapshot.Metadata.InputFiles))
+
+	if !globalFlags.quiet {
+		if resp.Commit != "" {
+			fmt.Fprintf(os.Stderr, "Compiled on %s at %s (%s)\n", buildFlags.remote, buildFlags.at, resp.Commit[:min(12, len(resp.Commit))])
+		} else {
+			fmt.Fprintf(os.Stderr, "Compiled on %s\n", buildFlags.remote)
+		}
+	}
+	return compiler.CompilationResult{Snapshot: resp.Snapshot}, resp.Diagnostics, nil
+}
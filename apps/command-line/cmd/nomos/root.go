@@ -3,9 +3,13 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"runtime/debug"
+	"path/filepath"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -27,20 +31,38 @@ by promoting re-use and cascading overrides.
 
 These configuration scripts compile to versioned snapshots that serve as
 inputs for infrastructure as code.`,
-	SilenceUsage:  true, // Don't show usage on errors
-	SilenceErrors: true, // We handle errors ourselves
+	SilenceUsage:      true, // Don't show usage on errors
+	SilenceErrors:     true, // We handle errors ourselves
+	PersistentPreRunE: enterProjectRoot,
 }
 
 // globalFlags holds flags that apply to all commands
 var globalFlags struct {
-	color string
-	quiet bool
+	color             string
+	quiet             bool
+	chdir             string
+	nomosDir          string
+	diagnosticsFormat string
 }
 
+// Values of --diagnostics-format.
+const (
+	diagnosticsFormatText   = "text"
+	diagnosticsFormatGitHub = "github"
+)
+
+// projectRoot is the absolute directory that every relative path resolves
+// against: input and output paths, policy and var files, and .nomos. It is
+// the --chdir directory, or the directory nomos was started in.
+var projectRoot string
+
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&globalFlags.color, "color", "auto", "Colorize output: auto, always, never")
 	rootCmd.PersistentFlags().BoolVarP(&globalFlags.quiet, "quiet", "q", false, "Suppress non-error output")
+	rootCmd.PersistentFlags().StringVarP(&globalFlags.chdir, "chdir", "C", "", "Run as if started in this directory; all relative paths and .nomos resolve against it")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.diagnosticsFormat, "diagnostics-format", diagnosticsFormatText, "Print compiler errors and warnings as text or as github workflow commands that annotate pull requests")
+	rootCmd.PersistentFlags().StringVar(&globalFlags.nomosDir, "nomos-dir", "", "Directory to install providers in (default .nomos; env NOMOS_DIR); the lockfile stays in .nomos")
 
 	// Add commands
 	rootCmd.AddCommand(buildCmd)
@@ -50,6 +72,18 @@ func init() {
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(providersCmd)
 	rootCmd.AddCommand(keysCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(browseCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
 
 	// Add shell completion commands
 	rootCmd.AddCommand(completionCmd)
@@ -122,56 +156,65 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
-// versionCmd represents the version command
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print version information",
-	Long:  `Print version information including build metadata`,
-	RunE:  runVersion,
+// colorMode is the --color value in effect, validated before any command
+// runs.
+var colorMode = ui.ColorAuto
+
+// styleFor returns the style of output written to w, honoring --color,
+// NO_COLOR, and whether w is a terminal.
+func styleFor(w io.Writer) ui.Style {
+	return ui.StyleFor(colorMode, w)
 }
 
-// runVersion displays version information.
-func runVersion(_ *cobra.Command, _ []string) error {
-	if globalFlags.quiet {
-		fmt.Println(version)
-		return nil
+// enterProjectRoot changes to the --chdir directory, if given, before any
+// command runs, so that relative paths never mix the caller's working
+// directory with the project's, records the project root, and selects the
+// nomos directory providers are installed in.
+func enterProjectRoot(_ *cobra.Command, _ []string) error {
+	switch globalFlags.diagnosticsFormat {
+	case diagnosticsFormatText, diagnosticsFormatGitHub:
+	default:
+		return fmt.Errorf("invalid --diagnostics-format %q (expected text or github)", globalFlags.diagnosticsFormat)
 	}
-
-	fmt.Printf("Nomos CLI Version: %s\n", version)
-	fmt.Printf("Commit: %s\n", commit)
-	fmt.Printf("Build Date: %s\n", buildDate)
-
-	// Try to get module version info
-	if info, ok := debug.ReadBuildInfo(); ok {
-		fmt.Printf("Go Version: %s\n", info.GoVersion)
-		if version == "dev" {
-			// In development, show the module path
-			fmt.Printf("Module: %s\n", info.Main.Path)
-			if info.Main.Version != "(devel)" {
-				fmt.Printf("Module Version: %s\n", info.Main.Version)
-			}
+	mode, err := ui.ParseColorMode(globalFlags.color)
+	if err != nil {
+		return err
+	}
+	colorMode = mode
+	if globalFlags.chdir != "" {
+		if err := os.Chdir(globalFlags.chdir); err != nil {
+			return fmt.Errorf("invalid --chdir: %w", err)
 		}
 	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve project root: %w", err)
+	}
+	projectRoot = wd
 
-	return nil
+	return selectNomosDir()
 }
 
-// setupColorOutput configures color output based on flags and terminal capabilities
-func setupColorOutput() {
-	// This will be implemented with color support in Phase 2.3
-	// For now, just a placeholder
-	switch globalFlags.color {
-	case "always":
-		// Force color on
-		_ = os.Setenv("CLICOLOR_FORCE", "1") // Ignore error, not critical
-	case "never":
-		// Force color off
-		_ = os.Setenv("NO_COLOR", "1") // Ignore error, not critical
-	case "auto":
-		// Let libraries auto-detect
-	default:
-		// Invalid value, default to auto
+// selectNomosDir selects the nomos directory from --nomos-dir, NOMOS_DIR, or
+// nomos_dir in the project configuration, in that order. The project
+// configuration is only read when neither of the others is set.
+func selectNomosDir() error {
+	var configured string
+	if globalFlags.nomosDir == "" && os.Getenv(nomosdir.EnvVar) == "" {
+		cfg, err := projectconfig.Load(projectconfig.DefaultPath)
+		if err != nil {
+			return err
+		}
+		configured = cfg.NomosDir
 	}
+
+	dir := nomosdir.Resolve(globalFlags.nomosDir, configured)
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid nomos directory %q: %w", dir, err)
+	}
+	nomosdir.Set(abs)
+	return nil
 }
 
 // flagErrorFunc is called when there's an error parsing flags or an unknown command
@@ -0,0 +1,110 @@
+// Package main implements the self-update command for the Nomos CLI.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/selfupdate"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+	"github.com/spf13/cobra"
+)
+
+// selfUpdateFlags holds all flags for the self-update command
+var selfUpdateFlags struct {
+	version           string
+	check             bool
+	force             bool
+	verifyAttestation bool
+}
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update nomos to the latest or a given release",
+	Long: `Self-update replaces this nomos binary with a release of the nomos CLI
+from GitHub, for the platform it runs on.
+
+The release is downloaded like a provider: GITHUB_TOKEN, GH_TOKEN, git
+credential helpers, the GitHub CLI's login, and .netrc authenticate the
+requests. The archive is verified against the digest GitHub publishes for
+it, and a release without one is refused. With --verify-attestation the
+binary must also carry a build attestation from the nomos release
+workflow, checked with the GitHub CLI (gh).
+
+The new binary is staged next to the current one and must run before it
+is moved into place. If moving it fails, or the installed binary does not
+run, the previous binary is restored.
+
+Examples:
+  nomos self-update                     # install the latest release
+  nomos self-update --check             # report the latest release only
+  nomos self-update --version 1.4.0     # install a specific release
+  nomos self-update --version prerelease`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().StringVar(&selfUpdateFlags.version, "version", downloader.ChannelLatest, "Release to install: a version such as 1.4.0, latest, or prerelease")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateFlags.check, "check", false, "Report the release that would be installed without installing it")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateFlags.force, "force", false, "Reinstall the release even if it is the current version")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateFlags.verifyAttestation, "verify-attestation", false, "Require a build attestation from the nomos release workflow (needs gh)")
+}
+
+// runSelfUpdate updates the running binary.
+func runSelfUpdate(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tokens := downloader.DefaultTokenSource("")
+	opts := selfupdate.Options{
+		Client:  downloader.NewClient(&downloader.ClientOptions{TokenSource: tokens}),
+		Version: selfUpdateFlags.version,
+		Current: version,
+		Force:   selfUpdateFlags.force,
+		DryRun:  selfUpdateFlags.check,
+	}
+	if selfUpdateFlags.verifyAttestation {
+		opts.Verify = func(ctx context.Context, path string) error {
+			token, _ := tokens.Token(ctx, "api.github.com")
+			return selfupdate.VerifyAttestation(ctx, path, token)
+		}
+	}
+
+	result, err := selfupdate.Update(ctx, opts)
+	if err != nil {
+		return selfUpdateHint(err)
+	}
+
+	if globalFlags.quiet {
+		return nil
+	}
+	switch {
+	case result.Updated:
+		fmt.Printf("Updated %s from %s to %s\n", result.Path, result.Previous, result.Version)
+	case result.UpToDate():
+		fmt.Printf("nomos %s is up to date\n", result.Version)
+	default:
+		fmt.Printf("nomos %s is available (current: %s); run 'nomos self-update' to install it\n", result.Version, result.Previous)
+	}
+	return nil
+}
+
+// selfUpdateHint attaches remediation advice to err.
+func selfUpdateHint(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return compiler.WithHint(err, compiler.Hint{Text: "rerun with permission to write the nomos binary, or update nomos the way it was installed"})
+	case errors.Is(err, selfupdate.ErrNoChecksum):
+		return compiler.WithHint(err, compiler.Hint{Text: "download the release from GitHub and verify it by hand"})
+	case errors.Is(err, downloader.ErrRateLimitExceeded):
+		return compiler.WithHint(err, compiler.Hint{Text: "set GITHUB_TOKEN to raise the GitHub API rate limit"})
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+// Package main implements snapshot loading shared by the get, browse,
+// report, analyze, and diff commands.
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/gitref"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// snapshotSource holds the flags of commands that read a snapshot either by
+// compiling --path or from a file written earlier with --from-snapshot.
+type snapshotSource struct {
+	path         string
+	fromSnapshot string
+	vars         []string
+	sets         []string
+	varFiles     []string
+
+	// root overrides the project root recorded in metadata, for sources
+	// compiled from a git revision.
+	root string
+}
+
+// addFlags registers the source flags on cmd; exactly one of --path and
+// --from-snapshot is required.
+func (s *snapshotSource) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.path, "path", "p", "", "Path to .csl file or directory to compile")
+	cmd.Flags().StringVar(&s.fromSnapshot, "from-snapshot", "", "Read a JSON or YAML snapshot instead of compiling")
+	cmd.MarkFlagsOneRequired("path", "from-snapshot")
+	cmd.MarkFlagsMutuallyExclusive("path", "from-snapshot")
+	cmd.Flags().StringSliceVar(&s.vars, "var", nil, "Set variable: key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&s.sets, "set", nil, "Override a compiled value: key.path=value (repeatable)")
+	cmd.Flags().StringSliceVar(&s.varFiles, "var-file", nil, "YAML or JSON file of values overlaid on the compiled snapshot (repeatable)")
+}
+
+// load reads --from-snapshot or compiles --path. Compiling uses the
+// providers already installed by 'nomos build'; nothing is downloaded.
+func (s *snapshotSource) load() (compiler.Snapshot, error) {
+	if s.fromSnapshot != "" {
+		return compiler.LoadSnapshot(s.fromSnapshot)
+	}
+
+	// Load project-level settings (.nomos/config.yaml)
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return compiler.Snapshot{}, err
+	}
+
+	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
+	opts, err := options.BuildOptions(options.BuildParams{
+		Path:                    s.path,
+		Vars:                    s.vars,
+		ProviderRegistry:        providerRegistry,
+		ProviderTypeRegistry:    providerTypeRegistry,
+		SuppressWarnings:        projectCfg.Warnings.Suppress,
+		TypeCoercion:            projectCfg.TypeCoercion,
+		VarFiles:                s.varFiles,
+		Sets:                    s.sets,
+		ProjectRoot:             cmp.Or(s.root, projectRoot),
+		ProviderPermissions:     projectCfg.ProviderPermissions,
+		Limits:                  projectCfg.Limits,
+		MaxProviderMessageBytes: projectCfg.MaxProviderMessageBytes,
+	})
+	if err != nil {
+		return compiler.Snapshot{}, fmt.Errorf("invalid options: %w", err)
+	}
+
+	result := compiler.Compile(context.Background(), opts)
+	if !globalFlags.quiet {
+		printDiagnostics(result)
+	}
+	if result.HasErrors() {
+		return compiler.Snapshot{}, fmt.Errorf("compilation failed: %w", result.Error())
+	}
+	return result.Snapshot, nil
+}
+
+// loadAt compiles --path as of the git revision ref, or loads the source
+// as load does when ref is empty. Var files and project settings are read
+// from the working tree.
+func (s snapshotSource) loadAt(ctx context.Context, ref string) (compiler.Snapshot, error) {
+	if ref == "" {
+		return s.load()
+	}
+	if s.path == "" {
+		return compiler.Snapshot{}, fmt.Errorf("compiling at git revision %s needs --path", ref)
+	}
+	tree, err := openRevision(ctx, ref)
+	if err != nil {
+		return compiler.Snapshot{}, err
+	}
+	defer func() { _ = tree.Close() }()
+
+	if s.path, err = tree.Path(projectRoot, s.path); err != nil {
+		return compiler.Snapshot{}, err
+	}
+	s.root = tree.Dir
+	return s.load()
+}
+
+// openRevision extracts the project as of the git revision ref.
+func openRevision(ctx context.Context, ref string) (*gitref.Tree, error) {
+	tree, err := gitref.Open(ctx, projectRoot, ref)
+	if err != nil {
+		return nil, err
+	}
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Compiling at %s (%s)\n", ref, tree.Commit[:min(12, len(tree.Commit))])
+	}
+	return tree, nil
+}
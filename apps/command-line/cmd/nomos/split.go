@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// splitIndexName is the manifest written beside split output documents.
+const splitIndexName = "index.json"
+
+// splitIndex is the manifest of a split build. Consumers read it to find the
+// documents of the current build; files left by earlier builds are not
+// listed.
+type splitIndex struct {
+	Format    string          `json:"format"`
+	Depth     int             `json:"depth"`
+	Documents []splitDocument `json:"documents"`
+}
+
+// splitDocument describes one document of a split build.
+type splitDocument struct {
+	// Key is the dotted key path of the document's data.
+	Key string `json:"key"`
+
+	// Path is the document's file, relative to the index and always
+	// slash-separated.
+	Path string `json:"path"`
+
+	MediaType string `json:"media_type"`
+	SHA256    string `json:"sha256"`
+}
+
+// writeSplitOutput writes one file per document of snapshot split at depth
+// into the directory dir, named after the document's keys, followed by an
+// index manifest. Every document is serialized before any file is written.
+func writeSplitOutput(snapshot compiler.Snapshot, format string, depth int, dir string, serializers *serialize.Registry, types *serialize.FileTypes, files outputFileFlags, opts serialize.Options) error {
+	normalizedFormat := serialize.OutputFormat(strings.ToLower(format))
+	ext := types.Extension(normalizedFormat)
+	if ext == "" {
+		return fmt.Errorf("format %s has no file extension to name split documents; set one under formats in .nomos/config.yaml", format)
+	}
+
+	docs, err := serialize.Split(snapshot, depth)
+	if err != nil {
+		return err
+	}
+
+	index := splitIndex{Format: string(normalizedFormat), Depth: depth, Documents: []splitDocument{}}
+	outputs := make([][]byte, len(docs))
+	for i, doc := range docs {
+		for _, key := range doc.Path {
+			if !isFileNameKey(key) {
+				return fmt.Errorf("cannot split %s: key %q cannot be used as a file name", doc.Key(), key)
+			}
+		}
+		rel := path.Join(doc.Path...) + ext
+		if rel == splitIndexName {
+			return fmt.Errorf("cannot split %s: %s is reserved for the index", doc.Key(), splitIndexName)
+		}
+
+		output, err := serializeSnapshot(doc.Snapshot, format, false, serializers, opts)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s: %w", doc.Key(), err)
+		}
+		sum := sha256.Sum256(output)
+		outputs[i] = output
+		index.Documents = append(index.Documents, splitDocument{
+			Key:       doc.Key(),
+			Path:      rel,
+			MediaType: types.MediaType(normalizedFormat),
+			SHA256:    hex.EncodeToString(sum[:]),
+		})
+	}
+
+	for i, doc := range index.Documents {
+		output := outputs[i]
+		if err := files.writeFile(filepath.Join(dir, filepath.FromSlash(doc.Path)), func(w io.Writer) error {
+			_, err := w.Write(output)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize split index: %w", err)
+	}
+	indexPath := filepath.Join(dir, splitIndexName)
+	if err := files.writeFile(indexPath, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stderr, "Output written to %s (%d documents, index %s)\n", dir, len(index.Documents), indexPath)
+	}
+	return nil
+}
+
+// isFileNameKey reports whether key can name a file or directory on every
+// platform nomos supports without escaping.
+func isFileNameKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, ".") && !strings.ContainsAny(key, `/\:*?"<>|`+"\x00")
+}
+
+// validateSplitFlags checks that --split-depth is combined only with flags
+// that make sense for multiple documents.
+func validateSplitFlags() error {
+	switch {
+	case buildFlags.splitDepth < 0:
+		return fmt.Errorf("--split-depth must be non-negative (got %d)", buildFlags.splitDepth)
+	case buildFlags.splitDepth == 0:
+		return nil
+	case buildFlags.out == "":
+		return fmt.Errorf("--split-depth needs --out <directory>")
+	case buildFlags.includeMetadata:
+		return fmt.Errorf("--split-depth cannot be combined with --include-metadata")
+	case buildFlags.bench:
+		return fmt.Errorf("--split-depth cannot be combined with --bench")
+	}
+	return nil
+}
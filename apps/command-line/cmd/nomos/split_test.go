@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestWriteSplitOutput tests that each document and the index are written.
+func TestWriteSplitOutput(t *testing.T) {
+	globalFlags.quiet = true
+	t.Cleanup(func() { globalFlags.quiet = false })
+
+	snapshot := compiler.Snapshot{Data: map[string]any{
+		"app":      map[string]any{"port": "8080"},
+		"database": map[string]any{"host": "db"},
+	}}
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := writeSplitOutput(snapshot, "yaml", 1, dir, nil, serialize.NewFileTypes(), outputFileFlags{}, serialize.Options{}); err != nil {
+		t.Fatalf("writeSplitOutput() error = %v", err)
+	}
+
+	app, err := os.ReadFile(filepath.Join(dir, "app.yaml")) //nolint:gosec // G304: Test file in a temp dir
+	if err != nil || string(app) != "port: \"8080\"\n" {
+		t.Errorf("app.yaml = %q, %v", app, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.json")) //nolint:gosec // G304: Test file in a temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index splitIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, doc := range index.Documents {
+		paths = append(paths, doc.Path)
+		if doc.MediaType != "application/yaml" || len(doc.SHA256) != 64 {
+			t.Errorf("document %+v", doc)
+		}
+	}
+	if want := []string{"app.yaml", "database.yaml"}; !reflect.DeepEqual(paths, want) || index.Format != "yaml" || index.Depth != 1 {
+		t.Errorf("index = %+v, want paths %v", index, want)
+	}
+}
+
+// TestWriteSplitOutput_Errors tests keys and formats that cannot be split
+// into files.
+func TestWriteSplitOutput_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		format  string
+		wantErr string
+	}{
+		{name: "path separator", data: map[string]any{"a/b": map[string]any{}}, format: "json", wantErr: "cannot be used as a file name"},
+		{name: "hidden file", data: map[string]any{".env": map[string]any{}}, format: "json", wantErr: "cannot be used as a file name"},
+		{name: "index collision", data: map[string]any{"index": map[string]any{}}, format: "json", wantErr: "reserved for the index"},
+		{name: "no extension", data: map[string]any{"app": map[string]any{}}, format: "custom:toml", wantErr: "no file extension"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := writeSplitOutput(compiler.Snapshot{Data: tt.data}, tt.format, 1, dir, nil, serialize.NewFileTypes(), outputFileFlags{}, serialize.Options{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("writeSplitOutput() error = %v, want %q", err, tt.wantErr)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("failed split wrote %d files", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// stdoutArtifact is the path under which output written to stdout is listed
+// in a build summary.
+const stdoutArtifact = "-"
+
+// buildSummary reports what a build did. It is printed to stderr at the end
+// of a successful build and written as JSON by --summary-json.
+type buildSummary struct {
+	Success     bool `json:"success"`
+	FilesParsed int  `json:"files_parsed"`
+
+	// Providers counts the providers installed for the build; nil when
+	// none were, as in remote and replayed builds.
+	Providers *providerUsage `json:"providers,omitempty"`
+
+	// ReferencesResolved is nil for remote builds, whose server does not
+	// report it.
+	ReferencesResolved *int `json:"references_resolved,omitempty"`
+
+	Warnings   int             `json:"warnings"`
+	Errors     int             `json:"errors"`
+	Artifacts  []buildArtifact `json:"artifacts"`
+	DurationMS int64           `json:"duration_ms"`
+
+	start    time.Time
+	duration time.Duration
+}
+
+// providerUsage counts the providers of a build by how they were installed.
+type providerUsage struct {
+	Total      int `json:"total"`
+	Cached     int `json:"cached"`
+	Downloaded int `json:"downloaded"`
+}
+
+// buildArtifact is a file written by a build, or stdoutArtifact.
+type buildArtifact struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// newBuildSummary starts timing a build.
+func newBuildSummary(start time.Time) *buildSummary {
+	return &buildSummary{start: start, Artifacts: []buildArtifact{}}
+}
+
+// setProviders records the outcome of installing the build's providers.
+func (s *buildSummary) setProviders(p *providercmd.ProviderSummary) {
+	if p == nil {
+		return
+	}
+	s.Providers = &providerUsage{Total: p.Total, Cached: p.Cached, Downloaded: p.Downloaded}
+}
+
+// setResult records the compilation result. remote builds leave
+// ReferencesResolved unset.
+func (s *buildSummary) setResult(result compiler.CompilationResult, remote bool) {
+	s.FilesParsed = len(result.Snapshot.Metadata.InputFiles)
+	s.Warnings = len(result.Snapshot.Metadata.Warnings)
+	s.Errors = len(result.Snapshot.Metadata.Errors)
+	if !remote {
+		n := result.ReferencesResolved()
+		s.ReferencesResolved = &n
+	}
+}
+
+// addArtifact records n bytes written to path. A nil summary records
+// nothing.
+func (s *buildSummary) addArtifact(path string, n int64) {
+	if s == nil {
+		return
+	}
+	s.Artifacts = append(s.Artifacts, buildArtifact{Path: path, Bytes: n})
+}
+
+// finish stops timing the build, which succeeded if err is nil.
+func (s *buildSummary) finish(now time.Time, err error) {
+	s.Success = err == nil
+	s.duration = now.Sub(s.start)
+	s.DurationMS = s.duration.Milliseconds()
+}
+
+// Write prints the summary as an aligned list.
+func (s *buildSummary) Write(w io.Writer) {
+	fmt.Fprintf(w, "\nBuild summary:\n")
+	fmt.Fprintf(w, "  %-21s %d\n", "Files parsed:", s.FilesParsed)
+	if s.Providers != nil {
+		fmt.Fprintf(w, "  %-21s %d (%d cached, %d downloaded)\n", "Providers:", s.Providers.Total, s.Providers.Cached, s.Providers.Downloaded)
+	}
+	if s.ReferencesResolved != nil {
+		fmt.Fprintf(w, "  %-21s %d\n", "References resolved:", *s.ReferencesResolved)
+	}
+	fmt.Fprintf(w, "  %-21s %d\n", "Warnings:", s.Warnings)
+	label := "Artifacts:"
+	if len(s.Artifacts) == 0 {
+		fmt.Fprintf(w, "  %-21s none\n", label)
+	}
+	for _, a := range s.Artifacts {
+		path := a.Path
+		if path == stdoutArtifact {
+			path = "stdout"
+		}
+		fmt.Fprintf(w, "  %-21s %s (%s)\n", label, path, artifactSize(a.Bytes))
+		label = ""
+	}
+	fmt.Fprintf(w, "  %-21s %s\n", "Duration:", s.duration.Round(time.Millisecond))
+}
+
+// artifactSize formats the size of an artifact, which may be empty.
+func artifactSize(n int64) string {
+	if n <= 0 {
+		return "0 bytes"
+	}
+	return formatSize(n)
+}
+
+// writeJSON writes the summary as indented JSON to path.
+func (s *buildSummary) writeJSON(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize build summary: %w", err)
+	}
+	if err := (outputFileFlags{}).writeFile(path, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to write build summary: %w", err)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+func TestBuildSummary_Write(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary := newBuildSummary(start)
+	summary.setProviders(&providercmd.ProviderSummary{Total: 3, Cached: 2, Downloaded: 1})
+	summary.setResult(compiler.CompilationResult{Snapshot: compiler.Snapshot{Metadata: compiler.Metadata{
+		InputFiles: []string{"a.csl", "b.csl"},
+		Warnings:   []string{"W001"},
+	}}}, false)
+	summary.addArtifact("build/config.json", 2048)
+	summary.addArtifact(stdoutArtifact, 0)
+	summary.finish(start.Add(1500*time.Millisecond), nil)
+
+	var buf bytes.Buffer
+	summary.Write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Build summary:",
+		"Files parsed:         2",
+		"Providers:            3 (2 cached, 1 downloaded)",
+		"References resolved:  0",
+		"Warnings:             1",
+		"Artifacts:            build/config.json (2048 bytes)",
+		"                      stdout (0 bytes)",
+		"Duration:             1.5s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBuildSummary_WriteRemote(t *testing.T) {
+	summary := newBuildSummary(time.Now())
+	summary.setResult(compiler.CompilationResult{}, true)
+	summary.finish(time.Now(), nil)
+
+	var buf bytes.Buffer
+	summary.Write(&buf)
+	out := buf.String()
+
+	for _, absent := range []string{"Providers:", "References resolved:"} {
+		if strings.Contains(out, absent) {
+			t.Errorf("remote summary lists %q:\n%s", absent, out)
+		}
+	}
+	if !strings.Contains(out, "Artifacts:            none") {
+		t.Errorf("summary without artifacts should say none:\n%s", out)
+	}
+}
+
+func TestBuildSummary_WriteJSON(t *testing.T) {
+	start := time.Now()
+	summary := newBuildSummary(start)
+	summary.setResult(compiler.CompilationResult{Snapshot: compiler.Snapshot{Metadata: compiler.Metadata{
+		InputFiles: []string{"a.csl"},
+		Errors:     []string{"boom"},
+	}}}, false)
+	summary.finish(start.Add(250*time.Millisecond), errors.New("compilation failed"))
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := summary.writeJSON(path); err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Test file
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, data)
+	}
+	want := map[string]any{
+		"success":             false,
+		"files_parsed":        1.0,
+		"references_resolved": 0.0,
+		"warnings":            0.0,
+		"errors":              1.0,
+		"artifacts":           []any{},
+		"duration_ms":         250.0,
+	}
+	for key, value := range want {
+		if gotValue, ok := got[key]; !ok || !jsonEqual(gotValue, value) {
+			t.Errorf("%s = %v, want %v", key, gotValue, value)
+		}
+	}
+	if _, ok := got["providers"]; ok {
+		t.Errorf("providers listed for a build that installed none: %s", data)
+	}
+}
+
+func TestOutputFileFlags_WriteFileRecordsArtifact(t *testing.T) {
+	summary := newBuildSummary(time.Now())
+	files := outputFileFlags{summary: summary}
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	if err := files.writeFile(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+
+	want := []buildArtifact{{Path: path, Bytes: 3}}
+	if len(summary.Artifacts) != 1 || summary.Artifacts[0] != want[0] {
+		t.Errorf("Artifacts = %+v, want %+v", summary.Artifacts, want)
+	}
+}
+
+// jsonEqual compares decoded JSON values.
+func jsonEqual(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
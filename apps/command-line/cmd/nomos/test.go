@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/golden"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// testFlags holds all flags for the test command
+var testFlags struct {
+	format   string
+	update   bool
+	vars     []string
+	template string
+}
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test [dir]",
+	Short: "Compile fixtures and compare them against golden outputs",
+	Long: `Test compiles configuration fixtures and compares each result with a
+checked-in golden file, reporting a diff for every mismatch.
+
+Test Layout (default directory: tests):
+  Each .csl file and each subdirectory is one test case. Its expected output
+  lives beside it, named <case>.golden.<ext> for the selected format:
+
+    tests/
+      prod.csl             compiled alone
+      prod.golden.json     expected output
+      staging/             compiled as a directory
+      staging.golden.json  expected output
+
+  Golden files hold data only (no metadata), so they are stable across runs.
+
+Updating Golden Files:
+  Run with --update to write the current output to every golden file, then
+  review the changes with your version control tool.
+
+Examples:
+  # Run all cases under ./tests
+  nomos test
+
+  # Compare YAML output from a custom directory
+  nomos test configs/tests --format yaml
+
+  # Accept the current output as the new expectation
+  nomos test --update
+
+Exit Codes:
+  0 - All cases passed (or golden files updated)
+  1 - One or more cases failed
+  2 - Invalid usage or flags`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: testCommand,
+}
+
+func init() {
+	testCmd.Flags().StringVarP(&testFlags.format, "format", "f", "json", "Output format to compare: json, yaml, tfvars, template, or custom:<name>")
+	testCmd.Flags().BoolVar(&testFlags.update, "update", false, "Write current output to golden files instead of comparing")
+	testCmd.Flags().StringSliceVar(&testFlags.vars, "var", nil, "Set variable: key=value (repeatable)")
+	testCmd.Flags().StringVar(&testFlags.template, "template", "", "Go text/template file rendered by --format template")
+}
+
+// testCommand executes the test subcommand.
+func testCommand(_ *cobra.Command, args []string) error {
+	dir := "tests"
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	format := serialize.OutputFormat(strings.ToLower(testFlags.format))
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	// Load project-level settings (.nomos/config.yaml)
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+	types, err := newFileTypes(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	cases, err := golden.Discover(dir, types.Extension(format))
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no test cases found in %s", dir)
+	}
+
+	serializers, err := newSerializerRegistry(projectCfg)
+	if err != nil {
+		return err
+	}
+
+	keyOrder, err := keyOrderFor(testFlags.format, "", projectCfg)
+	if err != nil {
+		return err
+	}
+	tmpl, err := loadTemplate(testFlags.format, testFlags.template)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, tc := range cases {
+		output, err := compileTestCase(tc, projectCfg, serializers, serialize.Options{KeyOrder: keyOrder, Template: tmpl})
+		if err == nil {
+			err = checkGolden(tc, output, testFlags.update)
+		}
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "FAIL %s\n", tc.Name)
+			fmt.Fprintln(os.Stdout, indent(err.Error(), "    "))
+			for _, hint := range diagnostics.NewFormatter(styleFor(os.Stdout).Enabled()).FormatHints(err) {
+				fmt.Fprintln(os.Stdout, indent(hint, "    "))
+			}
+			continue
+		}
+		if !globalFlags.quiet {
+			fmt.Fprintf(os.Stdout, "ok   %s\n", tc.Name)
+		}
+	}
+
+	if !globalFlags.quiet {
+		fmt.Fprintf(os.Stdout, "\n%d passed, %d failed\n", len(cases)-failed, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test case(s) failed", failed, len(cases))
+	}
+	return nil
+}
+
+// compileTestCase compiles a case with the project's compiler options, as
+// builds do, and serializes its data in the selected format, ordering keys
+// as the project's key_order does for builds.
+func compileTestCase(tc golden.Case, projectCfg projectconfig.Config, serializers *serialize.Registry, serializeOpts serialize.Options) ([]byte, error) {
+	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
+
+	params := projectBuildParams(projectCfg)
+	params.Path = tc.Path
+	params.Vars = testFlags.vars
+	params.ProviderRegistry = providerRegistry
+	params.ProviderTypeRegistry = providerTypeRegistry
+	params.SourceMap = serializeOpts.KeyOrder.Policy == serialize.KeyOrderSource
+	params.ProjectRoot = projectRoot
+	opts, err := options.BuildOptions(params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	result := compiler.Compile(context.Background(), opts)
+	if result.HasErrors() {
+		return nil, fmt.Errorf("compilation failed: %w", result.Error())
+	}
+
+	output, err := serializeSnapshot(result.Snapshot, testFlags.format, false, serializers, serializeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize output: %w", err)
+	}
+	return append(output, '\n'), nil
+}
+
+// checkGolden compares output with the case's golden file, or rewrites the
+// golden file when update is set.
+func checkGolden(tc golden.Case, output []byte, update bool) error {
+	if update {
+		if err := os.WriteFile(tc.GoldenPath, output, 0600); err != nil {
+			return fmt.Errorf("cannot write golden file: %w", err)
+		}
+		return nil
+	}
+
+	want, err := os.ReadFile(tc.GoldenPath) //nolint:gosec // G304: Path is derived from the test directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return compiler.WithHint(fmt.Errorf("golden file %s does not exist", tc.GoldenPath),
+				compiler.Hint{Text: "run with --update to create it"})
+		}
+		return fmt.Errorf("cannot read golden file: %w", err)
+	}
+
+	if diff := golden.Diff(string(want), string(output)); diff != "" {
+		return fmt.Errorf("output differs from %s (-want +got):\n%s", tc.GoldenPath, strings.TrimSuffix(diff, "\n"))
+	}
+	return nil
+}
+
+// indent prefixes every line of s with prefix.
+func indent(s, prefix string) string {
+	var b strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(prefix + line)
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/golden"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCheckGolden verifies comparison against and updating of golden files.
+func TestCheckGolden(t *testing.T) {
+	tc := golden.Case{Name: "prod", GoldenPath: filepath.Join(t.TempDir(), "prod.golden.json")}
+	output := []byte("{\n  \"app\": \"demo\"\n}\n")
+
+	err := checkGolden(tc, output, false)
+	if hints := compiler.Hints(err); err == nil || len(hints) != 1 || !strings.Contains(hints[0].Text, "--update") {
+		t.Fatalf("checkGolden() error = %v, want missing golden file hint", err)
+	}
+
+	if err := checkGolden(tc, output, true); err != nil {
+		t.Fatalf("checkGolden(update) unexpected error: %v", err)
+	}
+	if err := checkGolden(tc, output, false); err != nil {
+		t.Errorf("checkGolden() after update unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(tc.GoldenPath, []byte("{\n  \"app\": \"other\"\n}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = checkGolden(tc, output, false)
+	if err == nil || !strings.Contains(err.Error(), "-  \"app\": \"other\"") || !strings.Contains(err.Error(), "+  \"app\": \"demo\"") {
+		t.Errorf("checkGolden() error = %v, want diff", err)
+	}
+}
+
+// TestCompileTestCase_ProjectConfig verifies that golden test cases compile
+// with the type coercion and policies of the project configuration, as
+// builds do.
+func TestCompileTestCase_ProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	tc := golden.Case{Name: "app", Path: filepath.Join(dir, "app.csl")}
+	if err := os.WriteFile(tc.Path, []byte("db:\n  port: 5432\n  ssl: false\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy := filepath.Join(dir, "security.yaml")
+	if err := os.WriteFile(policy, []byte("policies:\n  - name: ssl\n    expr: data.db.ssl == true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := testFlags.format
+	testFlags.format = "json"
+	t.Cleanup(func() { testFlags.format = saved })
+	serializers, err := newSerializerRegistry(projectconfig.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := compileTestCase(tc, projectconfig.Config{TypeCoercion: "strict"}, serializers, serialize.Options{})
+	if err != nil {
+		t.Fatalf("compileTestCase(strict) unexpected error: %v", err)
+	}
+	if !strings.Contains(string(output), `"port": 5432`) || !strings.Contains(string(output), `"ssl": false`) {
+		t.Errorf("compileTestCase(strict) = %s, want a number and a boolean", output)
+	}
+
+	output, err = compileTestCase(tc, projectconfig.Config{TypeCoercion: "off"}, serializers, serialize.Options{})
+	if err != nil {
+		t.Fatalf("compileTestCase(off) unexpected error: %v", err)
+	}
+	if !strings.Contains(string(output), `"port": "5432"`) {
+		t.Errorf("compileTestCase(off) = %s, want a string", output)
+	}
+
+	_, err = compileTestCase(tc, projectconfig.Config{TypeCoercion: "strict", Policies: []string{policy}}, serializers, serialize.Options{})
+	if err == nil || !strings.Contains(err.Error(), "ssl") {
+		t.Errorf("compileTestCase(policies) error = %v, want the ssl policy to fail", err)
+	}
+}
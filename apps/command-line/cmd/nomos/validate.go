@@ -4,18 +4,22 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
 	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
 	"github.com/autonomous-bits/nomos/libs/compiler"
 	"github.com/spf13/cobra"
 )
 
 // validateFlags holds flags for the validate command
 var validateFlags struct {
-	path    string
-	verbose bool
+	path             string
+	verbose          bool
+	suppressWarnings []string
+	sarif            string
 }
 
 // validateCmd represents the validate command
@@ -30,11 +34,22 @@ This is useful for:
   - CI/CD pipelines
   - Quick syntax verification
   - Editor integrations
+  - Code scanning dashboards (--sarif)
 
 The validate command performs parsing and type checking but does not:
   - Invoke providers
   - Generate output snapshots
-  - Perform provider resolution`,
+  - Perform provider resolution
+
+SARIF Reports:
+  --sarif writes the errors and warnings as a SARIF 2.1.0 log ("-" for
+  stdout) for GitHub code scanning and other SARIF consumers. Each result
+  names its rule (the warning code, or "error" and "warning" for uncoded
+  diagnostics) and its source region; paths are relative to the repository
+  root (GITHUB_WORKSPACE, else the project root). The report is written
+  whether or not validation passes.
+
+  nomos validate -p config/ --sarif nomos.sarif`,
 	RunE: validateCommand,
 }
 
@@ -42,51 +57,36 @@ func init() {
 	validateCmd.Flags().StringVarP(&validateFlags.path, "path", "p", "", "Path to .csl file or directory (required)")
 	_ = validateCmd.MarkFlagRequired("path") // Error only occurs if flag doesn't exist
 	validateCmd.Flags().BoolVarP(&validateFlags.verbose, "verbose", "v", false, "Enable verbose output")
+	validateCmd.Flags().StringSliceVar(&validateFlags.suppressWarnings, "suppress-warning", nil, "Suppress warning code, e.g. W001 (repeatable)")
+	validateCmd.Flags().StringVar(&validateFlags.sarif, "sarif", "", "Write diagnostics as a SARIF 2.1.0 log to this file (\"-\" for stdout)")
 }
 
 // validateCommand executes the validate subcommand.
 func validateCommand(_ *cobra.Command, _ []string) error {
-	// Create provider registries (validation-only, no actual providers needed)
-	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
-
-	// Build compiler options with validation-only mode
-	opts, err := options.BuildOptions(options.BuildParams{
-		Path:                 validateFlags.path,
-		Vars:                 nil,  // No vars needed for validation
-		AllowMissingProvider: true, // Don't require providers for validation
-		ProviderRegistry:     providerRegistry,
-		ProviderTypeRegistry: providerTypeRegistry,
-	})
+	result, err := compileForValidation(validateFlags.path, validateFlags.suppressWarnings)
 	if err != nil {
-		return fmt.Errorf("invalid options: %w", err)
+		return err
 	}
 
-	// Call compiler (validation will happen during compilation)
-	ctx := context.Background()
-	result := compiler.Compile(ctx, opts)
-
 	snapshot := result.Snapshot
 	var compileErr error
 	if result.HasErrors() {
 		compileErr = result.Error()
 	}
 
-	// Create diagnostics formatter
-	useColor := shouldUseColor()
-	formatter := diagnostics.NewFormatter(useColor)
-
 	// Handle diagnostics
 	hasErrors := len(snapshot.Metadata.Errors) > 0
 	hasWarnings := len(snapshot.Metadata.Warnings) > 0
 
-	// Print warnings (unless quiet)
-	if hasWarnings && !globalFlags.quiet {
-		formatter.PrintWarnings(os.Stderr, snapshot.Metadata.Warnings)
+	if validateFlags.sarif != "" {
+		if err := writeSARIF(validateFlags.sarif, result); err != nil {
+			return err
+		}
 	}
 
-	// Print errors (unless quiet)
-	if hasErrors && !globalFlags.quiet {
-		formatter.PrintErrors(os.Stderr, snapshot.Metadata.Errors)
+	// Print warnings and errors (unless quiet)
+	if !globalFlags.quiet {
+		printDiagnostics(result)
 	}
 
 	// Print validation summary
@@ -117,3 +117,52 @@ func validateCommand(_ *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// compileForValidation compiles path without invoking providers, as
+// validate does, suppressing the given warning codes in addition to those
+// of the project configuration.
+func compileForValidation(path string, suppressWarnings []string) (compiler.CompilationResult, error) {
+	// Load project-level settings (.nomos/config.yaml)
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return compiler.CompilationResult{}, err
+	}
+
+	// Create provider registries (validation-only, no actual providers needed)
+	providerRegistry, providerTypeRegistry := options.NewProviderRegistries()
+
+	// Build compiler options with validation-only mode
+	opts, err := options.BuildOptions(options.BuildParams{
+		Path:                    path,
+		Vars:                    nil,  // No vars needed for validation
+		AllowMissingProvider:    true, // Don't require providers for validation
+		ProviderRegistry:        providerRegistry,
+		ProviderTypeRegistry:    providerTypeRegistry,
+		SuppressWarnings:        append(projectCfg.Warnings.Suppress, suppressWarnings...),
+		ProjectRoot:             projectRoot,
+		ProviderPermissions:     projectCfg.ProviderPermissions,
+		Limits:                  projectCfg.Limits,
+		MaxProviderMessageBytes: projectCfg.MaxProviderMessageBytes,
+	})
+	if err != nil {
+		return compiler.CompilationResult{}, fmt.Errorf("invalid options: %w", err)
+	}
+
+	// Call compiler (validation will happen during compilation)
+	return compiler.Compile(context.Background(), opts), nil
+}
+
+// writeSARIF writes the diagnostics of result as a SARIF log to path, or to
+// stdout when path is "-".
+func writeSARIF(path string, result compiler.CompilationResult) error {
+	encode := func(w io.Writer) error {
+		return diagnostics.WriteSARIF(w, result.Diagnostics(), repositoryRoot(), version)
+	}
+	if path == "-" {
+		return encode(os.Stdout)
+	}
+	if err := (outputFileFlags{}).writeFile(path, encode); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
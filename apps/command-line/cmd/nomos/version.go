@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// versionFlags holds all flags for the version command
+var versionFlags struct {
+	json bool
+}
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version information",
+	Long: `Print version information including build metadata.
+
+Lists the CLI version, the git commit and date it was built from, the Go
+version and platform, and the provider protocol versions the compiler
+speaks. Include the output in bug reports.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionFlags.json, "json", false, "Print the version information as JSON")
+}
+
+// versionInfo describes the running nomos binary.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+
+	// ProviderProtocol is the range of provider protocol versions the
+	// compiler speaks.
+	ProviderProtocol protocolRange `json:"provider_protocol"`
+}
+
+// protocolRange is an inclusive range of provider protocol versions.
+type protocolRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// String formats the range as "v1", or "v1 to v2" when it spans versions.
+func (r protocolRange) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprintf("v%d", r.Min)
+	}
+	return fmt.Sprintf("v%d to v%d", r.Min, r.Max)
+}
+
+// currentVersion returns the version information of this binary. A commit
+// not set by the build system is taken from the VCS stamp Go embeds.
+func currentVersion() versionInfo {
+	info := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		ProviderProtocol: protocolRange{
+			Min: compiler.MinProviderProtocolVersion,
+			Max: compiler.MaxProviderProtocolVersion,
+		},
+	}
+	if build, ok := debug.ReadBuildInfo(); ok && info.Commit == "unknown" {
+		info.Commit = vcsCommit(build.Settings)
+	}
+	return info
+}
+
+// vcsCommit returns the revision in the VCS build settings, marked
+// "-dirty" if the working tree was modified, or "unknown".
+func vcsCommit(settings []debug.BuildSetting) string {
+	revision, modified := "unknown", false
+	for _, s := range settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if modified && revision != "unknown" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// runVersion displays version information.
+func runVersion(_ *cobra.Command, _ []string) error {
+	if globalFlags.quiet && !versionFlags.json {
+		fmt.Println(version)
+		return nil
+	}
+	return writeVersion(os.Stdout, currentVersion(), versionFlags.json)
+}
+
+// writeVersion prints info as a list, or as JSON.
+func writeVersion(w io.Writer, info versionInfo, asJSON bool) error {
+	if asJSON {
+		encoded, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize version information: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(encoded))
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "Nomos CLI Version: %s\nCommit: %s\nBuild Date: %s\nGo Version: %s\nPlatform: %s\nProvider Protocol: %s\n",
+		info.Version, info.Commit, info.BuildDate, info.GoVersion, info.Platform, info.ProviderProtocol)
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	info := versionInfo{
+		Version:          "v2.1.0",
+		Commit:           "a1b2c3d",
+		BuildDate:        "2026-10-01T12:00:00Z",
+		GoVersion:        "go1.26.0",
+		Platform:         "linux/amd64",
+		ProviderProtocol: protocolRange{Min: 1, Max: 2},
+	}
+
+	var text bytes.Buffer
+	if err := writeVersion(&text, info, false); err != nil {
+		t.Fatalf("writeVersion() error = %v", err)
+	}
+	want := "Nomos CLI Version: v2.1.0\n" +
+		"Commit: a1b2c3d\n" +
+		"Build Date: 2026-10-01T12:00:00Z\n" +
+		"Go Version: go1.26.0\n" +
+		"Platform: linux/amd64\n" +
+		"Provider Protocol: v1 to v2\n"
+	if text.String() != want {
+		t.Errorf("writeVersion() = %q, want %q", text.String(), want)
+	}
+
+	var encoded bytes.Buffer
+	if err := writeVersion(&encoded, info, true); err != nil {
+		t.Fatalf("writeVersion(json) error = %v", err)
+	}
+	var got versionInfo
+	if err := json.Unmarshal(encoded.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, encoded.String())
+	}
+	if got != info {
+		t.Errorf("JSON round trip = %+v, want %+v", got, info)
+	}
+	if !strings.Contains(encoded.String(), `"provider_protocol": {`) {
+		t.Errorf("JSON output lacks provider_protocol:\n%s", encoded.String())
+	}
+}
+
+func TestCurrentVersion(t *testing.T) {
+	info := currentVersion()
+	if info.Version != version || info.GoVersion == "" || info.Platform == "" {
+		t.Errorf("currentVersion() = %+v, want the build metadata filled in", info)
+	}
+	if info.ProviderProtocol.Min < 1 || info.ProviderProtocol.Max < info.ProviderProtocol.Min {
+		t.Errorf("ProviderProtocol = %+v, want a non-empty range", info.ProviderProtocol)
+	}
+	if got := (protocolRange{Min: 1, Max: 1}).String(); got != "v1" {
+		t.Errorf("String() = %q, want v1", got)
+	}
+}
+
+func TestVCSCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings []debug.BuildSetting
+		want     string
+	}{
+		{name: "no stamp", want: "unknown"},
+		{name: "clean", settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abc123"}, {Key: "vcs.modified", Value: "false"}}, want: "abc123"},
+		{name: "dirty", settings: []debug.BuildSetting{{Key: "vcs.modified", Value: "true"}, {Key: "vcs.revision", Value: "abc123"}}, want: "abc123-dirty"},
+		{name: "modified without revision", settings: []debug.BuildSetting{{Key: "vcs.modified", Value: "true"}}, want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vcsCommit(tt.settings); got != tt.want {
+				t.Errorf("vcsCommit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+// Package main provides the nomosd entry point, a long-running server that
+// compiles Nomos configuration for nomos build --remote.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/gitref"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/projectconfig"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/remote"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/spf13/cobra"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// tokenEnvVar holds the bearer token clients must present, when set.
+const tokenEnvVar = "NOMOSD_TOKEN"
+
+// webhookSecretEnvVar holds the secret GitHub webhook deliveries are
+// signed with.
+const webhookSecretEnvVar = "NOMOSD_WEBHOOK_SECRET"
+
+// shutdownTimeout bounds how long in-flight compilations may finish after
+// a shutdown signal.
+const shutdownTimeout = 30 * time.Second
+
+// flags holds the command line flags of nomosd
+var flags struct {
+	listen                 string
+	chdir                  string
+	repo                   string
+	tokenFile              string
+	maxRequestBytes        int64
+	timeoutPerProvider     string
+	maxConcurrentProviders int
+	targets                string
+	webhookSecretFile      string
+	publishDir             string
+	buildWorkers           int
+	publishLock            string
+	publishLockTimeout     time.Duration
+}
+
+// rootCmd represents nomosd
+var rootCmd = &cobra.Command{
+	Use:   "nomosd",
+	Short: "Compile Nomos configuration for remote clients",
+	Long: `nomosd is a long-running server that compiles Nomos configuration sent by
+'nomos build --remote', so provider credentials, provider processes, and
+their caches live on one server instead of every workstation and CI runner.
+
+Provider processes are started on first use and kept running between
+requests. Providers are those installed in the project directory's .nomos,
+as 'nomos build' installs them, and the policies, warning suppressions, and
+type coercion of its .nomos/config.yaml apply to every compilation.
+
+API:
+  POST /v1/compile   compile a bundle of .csl files, or a git revision of
+                     --repo, and answer the snapshot and its diagnostics
+  GET  /healthz      report that the server is up
+
+Authentication:
+  When NOMOSD_TOKEN or --token-file is set, compile requests must carry
+  "Authorization: Bearer <token>". Serve TLS through a reverse proxy.
+
+Push-Triggered Builds:
+  With --targets, nomosd also receives GitHub push webhooks for --repo and
+  rebuilds the targets a push affects, writing each artifact to
+  --publish-dir. The targets file names the branch to follow and the
+  targets to build:
+
+    branch: main
+    targets:
+      - name: prod
+        path: config/prod
+        format: yaml
+        profiles: [prod]
+        watch: [config/shared]
+
+  A target is affected when the push changes a file beneath its path (or
+  its path's directory, for a file) or a watched path; when GitHub does not
+  list every changed file, all targets are. Each target is queued at most
+  once, at the newest pushed commit, and --build-workers builds run at
+  once. Commits missing from --repo are fetched from its default remote.
+
+  With --publish-lock, each publish holds a lock of its target, so
+  nomosd instances sharing --publish-dir publish a target one at a time,
+  and an artifact is never replaced by one built at an older commit:
+
+    file                    lock files in --publish-dir
+    etcd:<url>              keys in etcd, e.g. etcd:http://etcd:2379
+
+    POST /v1/webhooks/github   receive a push; deliveries must be signed
+                               with NOMOSD_WEBHOOK_SECRET or
+                               --webhook-secret-file
+    GET  /v1/builds            report the latest build of each target
+
+Examples:
+  nomosd
+  nomosd --listen :7420 --repo /srv/config-repo --token-file /etc/nomosd/token
+  nomosd --repo /srv/config-repo --targets /etc/nomosd/targets.yaml \
+    --webhook-secret-file /etc/nomosd/webhook-secret --publish-dir /srv/artifacts`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Version:       version,
+	RunE:          run,
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&flags.listen, "listen", "127.0.0.1:7420", "Address to listen on")
+	rootCmd.Flags().StringVarP(&flags.chdir, "chdir", "C", "", "Project directory holding .nomos (default: the current directory)")
+	rootCmd.Flags().StringVar(&flags.repo, "repo", "", "Git repository to compile requested revisions from (default: revisions are rejected)")
+	rootCmd.Flags().StringVar(&flags.tokenFile, "token-file", "", "File holding the bearer token clients must present (default: $"+tokenEnvVar+")")
+	rootCmd.Flags().Int64Var(&flags.maxRequestBytes, "max-request-bytes", remote.DefaultMaxRequestBytes, "Largest compile request accepted, in bytes")
+	rootCmd.Flags().StringVar(&flags.timeoutPerProvider, "timeout-per-provider", "", "Timeout for each provider fetch (e.g., 5s, 1m)")
+	rootCmd.Flags().IntVar(&flags.maxConcurrentProviders, "max-concurrent-providers", 0, "Maximum concurrent provider fetches per compilation (0 = unlimited)")
+	rootCmd.Flags().StringVar(&flags.targets, "targets", "", "Targets file enabling push-triggered builds (requires --repo and --publish-dir)")
+	rootCmd.Flags().StringVar(&flags.webhookSecretFile, "webhook-secret-file", "", "File holding the GitHub webhook secret (default: $"+webhookSecretEnvVar+")")
+	rootCmd.Flags().StringVar(&flags.publishDir, "publish-dir", "", "Directory push-triggered builds publish their artifacts to")
+	rootCmd.Flags().IntVar(&flags.buildWorkers, "build-workers", remote.DefaultTriggerWorkers, "Maximum push-triggered builds run at once")
+	rootCmd.Flags().StringVar(&flags.publishLock, "publish-lock", "", "Lock each target while publishing it: file or etcd:<url> (default: no locking)")
+	rootCmd.Flags().DurationVar(&flags.publishLockTimeout, "publish-lock-timeout", remote.DefaultLockTimeout, "How long a publish waits for its target's lock")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run serves the API until interrupted.
+func run(_ *cobra.Command, _ []string) error {
+	if flags.chdir != "" {
+		if err := os.Chdir(flags.chdir); err != nil {
+			return fmt.Errorf("invalid --chdir: %w", err)
+		}
+	}
+	projectCfg, err := projectconfig.Load(projectconfig.DefaultPath)
+	if err != nil {
+		return err
+	}
+	nomosDir, err := filepath.Abs(nomosdir.Resolve("", projectCfg.NomosDir))
+	if err != nil {
+		return fmt.Errorf("invalid nomos directory: %w", err)
+	}
+	nomosdir.Set(nomosDir)
+
+	token, err := secret(tokenEnvVar, flags.tokenFile)
+	if err != nil {
+		return fmt.Errorf("cannot read token file: %w", err)
+	}
+
+	repo := flags.repo
+	if repo != "" {
+		if repo, err = filepath.Abs(repo); err != nil {
+			return fmt.Errorf("invalid --repo: %w", err)
+		}
+	}
+
+	var sessionOpts compiler.SessionOptions
+	resolver, err := options.NewProviderResolver()
+	if err != nil {
+		return fmt.Errorf("invalid provider lockfile: %w", err)
+	}
+	if resolver != nil {
+		sessionOpts.Resolver = resolver
+	}
+	session := compiler.NewSession(sessionOpts)
+
+	logger := log.New(os.Stderr, "nomosd: ", log.LstdFlags)
+	server := &remote.Server{
+		Session: session,
+		Base: options.BuildParams{
+			PolicyFiles:             projectCfg.Policies,
+			SuppressWarnings:        projectCfg.Warnings.Suppress,
+			TypeCoercion:            projectCfg.TypeCoercion,
+			TimeoutPerProvider:      flags.timeoutPerProvider,
+			MaxConcurrentProviders:  flags.maxConcurrentProviders,
+			ProviderPermissions:     projectCfg.ProviderPermissions,
+			Limits:                  projectCfg.Limits,
+			MaxProviderMessageBytes: projectCfg.MaxProviderMessageBytes,
+		},
+		Repo:            repo,
+		Token:           token,
+		MaxRequestBytes: flags.maxRequestBytes,
+		Logger:          logger,
+	}
+
+	handler := server.Handler()
+	var trigger *remote.Trigger
+	if flags.targets != "" {
+		if trigger, err = newTrigger(server); err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/", handler)
+		mux.Handle(remote.GitHubWebhookPath, trigger.Handler())
+		mux.Handle(remote.BuildsPath, trigger.Handler())
+		handler = mux
+	}
+
+	listener, err := net.Listen("tcp", flags.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	builds, stopBuilds := context.WithCancel(context.Background())
+	defer stopBuilds()
+	buildsDone := make(chan struct{})
+	go func() {
+		defer close(buildsDone)
+		if trigger != nil {
+			trigger.Run(builds)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	shutdown := make(chan error, 1)
+	go func() {
+		<-signals
+		logger.Printf("shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := httpServer.Shutdown(ctx)
+		stopBuilds()
+		<-buildsDone
+		shutdown <- errors.Join(err, session.Close(ctx))
+	}()
+
+	logger.Printf("listening on %s", listener.Addr())
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		stopBuilds()
+		<-buildsDone
+		_ = session.Close(context.Background())
+		return err
+	}
+	return <-shutdown
+}
+
+// newTrigger configures push-triggered builds of the --targets file,
+// compiled by server.
+func newTrigger(server *remote.Server) (*remote.Trigger, error) {
+	if server.Repo == "" {
+		return nil, errors.New("--targets requires --repo")
+	}
+	if flags.publishDir == "" {
+		return nil, errors.New("--targets requires --publish-dir")
+	}
+	targets, err := remote.LoadTargets(flags.targets)
+	if err != nil {
+		return nil, err
+	}
+	webhookSecret, err := secret(webhookSecretEnvVar, flags.webhookSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read webhook secret file: %w", err)
+	}
+	if webhookSecret == "" {
+		return nil, fmt.Errorf("--targets requires a webhook secret (--webhook-secret-file or $%s)", webhookSecretEnvVar)
+	}
+	publisher, err := newPublisher(server.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return &remote.Trigger{
+		Server:    server,
+		Targets:   targets,
+		Secret:    webhookSecret,
+		Publisher: publisher,
+		Workers:   flags.buildWorkers,
+		Logger:    server.Logger,
+	}, nil
+}
+
+// newPublisher returns the publisher of push-triggered builds, locking
+// targets as --publish-lock selects. Ancestry is checked in repo.
+func newPublisher(repo string) (remote.Publisher, error) {
+	var publisher remote.Publisher = remote.DirPublisher{Dir: flags.publishDir}
+	var locker remote.Locker
+	switch backend := flags.publishLock; {
+	case backend == "":
+		return publisher, nil
+	case backend == "file":
+		locker = remote.FileLocker{Dir: flags.publishDir}
+	case strings.HasPrefix(backend, "etcd:"):
+		endpoint := strings.TrimPrefix(backend, "etcd:")
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return nil, fmt.Errorf("invalid --publish-lock %q: etcd endpoint must be an http or https URL", backend)
+		}
+		locker = remote.EtcdLocker{Endpoint: endpoint}
+	default:
+		return nil, fmt.Errorf("invalid --publish-lock %q: must be file or etcd:<url>", backend)
+	}
+	return remote.LockingPublisher{
+		Publisher: publisher,
+		Locker:    locker,
+		Timeout:   flags.publishLockTimeout,
+		IsAncestor: func(ctx context.Context, ancestor, descendant string) (bool, error) {
+			return gitref.IsAncestor(ctx, repo, ancestor, descendant)
+		},
+	}, nil
+}
+
+// secret returns the trimmed content of file, or the environment variable
+// envVar when file is empty.
+func secret(envVar, file string) (string, error) {
+	if file == "" {
+		return os.Getenv(envVar), nil
+	}
+	content, err := os.ReadFile(file) //nolint:gosec // G304: Path is named on the command line
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
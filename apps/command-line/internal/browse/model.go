@@ -0,0 +1,387 @@
+package browse
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultHeight is the terminal height assumed until the first
+// WindowSizeMsg arrives.
+const defaultHeight = 24
+
+// detailLines is the height of the detail pane below the tree.
+const detailLines = 5
+
+const helpText = "↑/↓ move  ←/→ collapse/expand  / search  n/N next/prev  y copy value  Y copy path  q quit"
+
+var (
+	cursorStyle    = lipgloss.NewStyle().Reverse(true)
+	labelStyle     = lipgloss.NewStyle().Bold(true)
+	faintStyle     = lipgloss.NewStyle().Faint(true)
+	separatorStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// Options configures the browser.
+type Options struct {
+	// Copy puts text on the system clipboard. Defaults to writing an OSC 52
+	// sequence to stderr, which terminals (including over SSH and tmux)
+	// forward to the local clipboard.
+	Copy func(text string) error
+}
+
+// Model is the bubbletea model of the browser.
+type Model struct {
+	roots      []*node
+	rows       []*node
+	cursor     int
+	offset     int
+	width      int
+	height     int
+	provenance map[string]compiler.Provenance
+	sensitive  map[string]bool
+	copy       func(string) error
+
+	searching bool
+	query     string
+	matches   []*node
+	match     int
+	status    string
+}
+
+// New returns a browser over snapshot with its top-level keys collapsed.
+func New(snapshot compiler.Snapshot, opts Options) *Model {
+	m := &Model{
+		roots:      buildTree(snapshot.Data),
+		height:     defaultHeight,
+		provenance: snapshot.Metadata.PerKeyProvenance,
+		sensitive:  make(map[string]bool, len(snapshot.Metadata.SensitiveKeys)),
+		copy:       opts.Copy,
+	}
+	for _, key := range snapshot.Metadata.SensitiveKeys {
+		m.sensitive[key] = true
+	}
+	if m.copy == nil {
+		m.copy = copyOSC52
+	}
+	m.refresh()
+	return m
+}
+
+// Run opens the browser on the terminal and returns when the user quits.
+func Run(snapshot compiler.Snapshot, opts Options) error {
+	_, err := tea.NewProgram(New(snapshot, opts), tea.WithAltScreen()).Run()
+	return err
+}
+
+// copyOSC52 asks the terminal to put text on the clipboard.
+func copyOSC52(text string) error {
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		if m.searching {
+			m.updateSearch(msg)
+			return m, nil
+		}
+		return m, m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+// updateBrowse handles a key press while navigating the tree.
+func (m *Model) updateBrowse(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+	switch msg.String() {
+	case "q", "ctrl+c", "esc":
+		return tea.Quit
+	case "up", "k":
+		m.moveTo(m.cursor - 1)
+	case "down", "j":
+		m.moveTo(m.cursor + 1)
+	case "pgup":
+		m.moveTo(m.cursor - m.treeHeight())
+	case "pgdown":
+		m.moveTo(m.cursor + m.treeHeight())
+	case "home", "g":
+		m.moveTo(0)
+	case "end", "G":
+		m.moveTo(len(m.rows) - 1)
+	case "right", "l", "enter":
+		if n := m.current(); n != nil && n.isContainer() {
+			if n.expanded && len(n.children) > 0 {
+				m.moveTo(m.cursor + 1)
+			} else {
+				n.expanded = true
+				m.refresh()
+			}
+		}
+	case "left", "h":
+		if n := m.current(); n != nil {
+			if n.expanded {
+				n.expanded = false
+				m.refresh()
+			} else if n.parent != nil {
+				m.reveal(n.parent)
+			}
+		}
+	case "/":
+		m.searching, m.query = true, ""
+	case "n":
+		m.nextMatch(1)
+	case "N":
+		m.nextMatch(-1)
+	case "y":
+		if n := m.current(); n != nil {
+			m.copyToClipboard(copyText(n.value), "value of "+n.path)
+		}
+	case "Y":
+		if n := m.current(); n != nil {
+			m.copyToClipboard(n.path, "key path "+n.path)
+		}
+	}
+	return nil
+}
+
+// updateSearch handles a key press while typing a search query.
+func (m *Model) updateSearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		m.search()
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.searching, m.query = false, ""
+	case tea.KeyBackspace:
+		if r := []rune(m.query); len(r) > 0 {
+			m.query = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.query += string(msg.Runes)
+	}
+}
+
+// search collects the nodes whose key path contains the query, ignoring
+// case, and jumps to the first.
+func (m *Model) search() {
+	m.matches, m.match = nil, -1
+	if m.query == "" {
+		return
+	}
+	query := strings.ToLower(m.query)
+	walk(m.roots, func(n *node) {
+		if strings.Contains(strings.ToLower(n.path), query) {
+			m.matches = append(m.matches, n)
+		}
+	})
+	if len(m.matches) == 0 {
+		m.status = fmt.Sprintf("no keys match %q", m.query)
+		return
+	}
+	m.nextMatch(1)
+}
+
+// nextMatch moves to the next (dir 1) or previous (dir -1) search match.
+func (m *Model) nextMatch(dir int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (m.match + dir + len(m.matches)) % len(m.matches)
+	m.reveal(m.matches[m.match])
+	m.status = fmt.Sprintf("match %d of %d for %q", m.match+1, len(m.matches), m.query)
+}
+
+// copyToClipboard copies text and reports the outcome in the status line.
+func (m *Model) copyToClipboard(text, what string) {
+	if err := m.copy(text); err != nil {
+		m.status = fmt.Sprintf("copy failed: %v", err)
+		return
+	}
+	m.status = "copied " + what
+}
+
+// reveal expands the ancestors of n and moves the cursor to it.
+func (m *Model) reveal(n *node) {
+	for p := n.parent; p != nil; p = p.parent {
+		p.expanded = true
+	}
+	m.refresh()
+	for i, row := range m.rows {
+		if row == n {
+			m.moveTo(i)
+			return
+		}
+	}
+}
+
+// refresh recomputes the visible rows after nodes expand or collapse,
+// keeping the cursor on the same node where it is still shown.
+func (m *Model) refresh() {
+	current := m.current()
+	m.rows = visible(m.rows[:0], m.roots)
+	for i, row := range m.rows {
+		if row == current {
+			m.cursor = i
+			break
+		}
+	}
+	m.moveTo(m.cursor)
+}
+
+// moveTo places the cursor on row i, clamped to the rows shown.
+func (m *Model) moveTo(i int) {
+	m.cursor = max(0, min(i, len(m.rows)-1))
+	m.scroll()
+}
+
+// scroll keeps the cursor row inside the tree viewport.
+func (m *Model) scroll() {
+	height := m.treeHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(0, m.offset)
+}
+
+// treeHeight is the number of tree rows that fit above the detail pane,
+// separator, and status line.
+func (m *Model) treeHeight() int {
+	return max(1, m.height-detailLines-2)
+}
+
+// current returns the node under the cursor, or nil for an empty snapshot.
+func (m *Model) current() *node {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor]
+}
+
+// isSensitive reports whether n or one of its ancestors holds a secret.
+func (m *Model) isSensitive(n *node) bool {
+	for ; n != nil; n = n.parent {
+		if m.sensitive[n.path] {
+			return true
+		}
+	}
+	return false
+}
+
+// View implements tea.Model.
+func (m *Model) View() string {
+	var b strings.Builder
+
+	height := m.treeHeight()
+	for i := m.offset; i < m.offset+height; i++ {
+		if i < len(m.rows) {
+			b.WriteString(m.renderRow(i))
+		} else if i == 0 {
+			b.WriteString(faintStyle.Render("(empty snapshot)"))
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", max(1, m.width))))
+	b.WriteByte('\n')
+	for _, line := range m.detail() {
+		b.WriteString(m.fit(line))
+		b.WriteByte('\n')
+	}
+
+	switch {
+	case m.searching:
+		b.WriteString(m.fit("/" + m.query + "█"))
+	case m.status != "":
+		b.WriteString(m.fit(m.status))
+	default:
+		b.WriteString(faintStyle.Render(m.fit(helpText)))
+	}
+	return b.String()
+}
+
+// renderRow renders tree row i.
+func (m *Model) renderRow(i int) string {
+	n := m.rows[i]
+	marker := "  "
+	if n.isContainer() {
+		marker = "▸ "
+		if n.expanded {
+			marker = "▾ "
+		}
+	}
+
+	line := strings.Repeat("  ", n.depth) + marker + n.label
+	if !n.expanded {
+		value := summary(n.value)
+		if m.isSensitive(n) && !n.isContainer() {
+			value = "(sensitive)"
+		}
+		line += ": " + value
+	}
+	line = m.fit(line)
+	if i == m.cursor {
+		return cursorStyle.Render(line)
+	}
+	return line
+}
+
+// detail returns the lines of the detail pane for the node under the
+// cursor, padded to detailLines.
+func (m *Model) detail() []string {
+	lines := make([]string, 0, detailLines)
+	if n := m.current(); n != nil {
+		lines = append(lines,
+			labelStyle.Render("Path:   ")+n.path,
+			labelStyle.Render("Type:   ")+typeName(n.value),
+			labelStyle.Render("Source: ")+m.source(n),
+		)
+		value := summary(n.value)
+		if m.isSensitive(n) {
+			value = "(sensitive; y copies it)"
+		}
+		lines = append(lines, labelStyle.Render("Value:  ")+value)
+	}
+	for len(lines) < detailLines {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// source describes the provenance of n's top-level key.
+func (m *Model) source(n *node) string {
+	prov, ok := m.provenance[topLevelKey(n.path)]
+	if !ok {
+		return "unknown (snapshot has no metadata)"
+	}
+	if prov.ProviderAlias != "" {
+		return fmt.Sprintf("%s (provider %s)", prov.Source, prov.ProviderAlias)
+	}
+	return prov.Source
+}
+
+// fit truncates s to the terminal width.
+func (m *Model) fit(s string) string {
+	if m.width <= 0 {
+		return s
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(s)
+}
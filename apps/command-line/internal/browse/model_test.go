@@ -0,0 +1,201 @@
+package browse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testSnapshot() compiler.Snapshot {
+	return compiler.Snapshot{
+		Data: map[string]any{
+			"app": map[string]any{
+				"name": "demo",
+				"tags": []any{"web", "api"},
+			},
+			"database": map[string]any{
+				"host":     "db.internal",
+				"password": "hunter2",
+				"port":     5432.0,
+			},
+		},
+		Metadata: compiler.Metadata{
+			PerKeyProvenance: map[string]compiler.Provenance{
+				"app":      {Source: "/src/app.csl"},
+				"database": {Source: "/src/db.csl", ProviderAlias: "vault"},
+			},
+			SensitiveKeys: []string{"database.password"},
+		},
+	}
+}
+
+// press sends keys to m, one message per key name.
+func press(m *Model, keys ...string) {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "up", "down", "left", "right", "enter", "esc", "backspace":
+			msg = tea.KeyMsg{Type: map[string]tea.KeyType{
+				"up": tea.KeyUp, "down": tea.KeyDown, "left": tea.KeyLeft, "right": tea.KeyRight,
+				"enter": tea.KeyEnter, "esc": tea.KeyEsc, "backspace": tea.KeyBackspace,
+			}[key]}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m.Update(msg)
+	}
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m := New(testSnapshot(), Options{})
+
+	if got := len(m.rows); got != 2 {
+		t.Fatalf("rows = %d, want the 2 top-level keys collapsed", got)
+	}
+
+	tests := []struct {
+		keys     []string
+		wantPath string
+		wantRows int
+	}{
+		{keys: []string{"right"}, wantPath: "app", wantRows: 4},
+		{keys: []string{"right"}, wantPath: "app.name", wantRows: 4},
+		{keys: []string{"down"}, wantPath: "app.tags", wantRows: 4},
+		{keys: []string{"enter"}, wantPath: "app.tags", wantRows: 6},
+		{keys: []string{"down"}, wantPath: "app.tags[0]", wantRows: 6},
+		{keys: []string{"left"}, wantPath: "app.tags", wantRows: 6},
+		{keys: []string{"left"}, wantPath: "app.tags", wantRows: 4},
+		{keys: []string{"left"}, wantPath: "app", wantRows: 4},
+		{keys: []string{"left"}, wantPath: "app", wantRows: 2},
+		{keys: []string{"G"}, wantPath: "database", wantRows: 2},
+		{keys: []string{"down", "down"}, wantPath: "database", wantRows: 2},
+		{keys: []string{"g"}, wantPath: "app", wantRows: 2},
+	}
+	for _, tt := range tests {
+		press(m, tt.keys...)
+		if got := m.current().path; got != tt.wantPath {
+			t.Errorf("after %v: cursor on %q, want %q", tt.keys, got, tt.wantPath)
+		}
+		if got := len(m.rows); got != tt.wantRows {
+			t.Errorf("after %v: %d rows, want %d", tt.keys, got, tt.wantRows)
+		}
+	}
+}
+
+func TestModel_Search(t *testing.T) {
+	m := New(testSnapshot(), Options{})
+
+	press(m, "/", "H", "O", "S", "T", "enter")
+	if got := m.current().path; got != "database.host" {
+		t.Fatalf("cursor on %q, want database.host revealed by search", got)
+	}
+	if !strings.Contains(m.View(), `match 1 of 1 for "HOST"`) {
+		t.Errorf("View() does not report the match:\n%s", m.View())
+	}
+
+	press(m, "/", "a", "g", "x", "backspace", "enter")
+	if got := m.current().path; got != "app.tags" {
+		t.Errorf("cursor on %q, want app.tags", got)
+	}
+	press(m, "n")
+	if got := m.current().path; got != "app.tags[0]" {
+		t.Errorf("after n: cursor on %q, want app.tags[0]", got)
+	}
+	press(m, "N", "N")
+	if got := m.current().path; got != "app.tags[1]" {
+		t.Errorf("after N N: cursor on %q, want app.tags[1] (wrapping)", got)
+	}
+
+	press(m, "/", "z", "z", "enter")
+	if !strings.Contains(m.View(), `no keys match "zz"`) {
+		t.Errorf("View() does not report the miss:\n%s", m.View())
+	}
+
+	// Escape abandons the query without moving
+	press(m, "/", "n", "a", "m", "e", "esc")
+	if got := m.current().path; got != "app.tags[1]" {
+		t.Errorf("after esc: cursor on %q, want app.tags[1]", got)
+	}
+}
+
+func TestModel_Copy(t *testing.T) {
+	var copied []string
+	m := New(testSnapshot(), Options{Copy: func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}})
+
+	press(m, "/", "host", "enter", "y", "Y", "left", "left", "y")
+	want := []string{"db.internal", "database.host", "{\n  \"host\": \"db.internal\",\n  \"password\": \"hunter2\",\n  \"port\": 5432\n}"}
+	if strings.Join(copied, "|") != strings.Join(want, "|") {
+		t.Errorf("copied %q, want %q", copied, want)
+	}
+
+	m.copy = func(string) error { return errors.New("no terminal") }
+	press(m, "y")
+	if !strings.Contains(m.View(), "copy failed: no terminal") {
+		t.Errorf("View() does not report the copy failure:\n%s", m.View())
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := New(testSnapshot(), Options{})
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 12})
+
+	press(m, "G", "right", "down", "down")
+	view := m.View()
+	for _, want := range []string{
+		"▾ database",
+		"host: \"db.internal\"",
+		"password: (sensitive)",
+		"port: 5432",
+		"Path:   database.password",
+		"Source: /src/db.csl (provider vault)",
+		"Value:  (sensitive; y copies it)",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "hunter2") {
+		t.Errorf("View() shows a sensitive value:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n") + 1; lines != 12 {
+		t.Errorf("View() has %d lines, want the terminal height 12", lines)
+	}
+}
+
+func TestModel_Scroll(t *testing.T) {
+	data := map[string]any{}
+	for _, k := range strings.Split("a b c d e f g h i j", " ") {
+		data[k] = k
+	}
+	m := New(compiler.Snapshot{Data: data}, Options{})
+	m.Update(tea.WindowSizeMsg{Width: 40, Height: 10})
+
+	press(m, "G")
+	view := m.View()
+	if strings.Contains(view, "a: ") || !strings.Contains(view, "j: \"j\"") {
+		t.Errorf("View() did not scroll to the last row:\n%s", view)
+	}
+	if !strings.Contains(view, "Source: unknown") {
+		t.Errorf("View() missing unknown provenance:\n%s", view)
+	}
+}
+
+func TestModel_Quit(t *testing.T) {
+	m := New(compiler.Snapshot{}, Options{})
+	if !strings.Contains(m.View(), "(empty snapshot)") {
+		t.Errorf("View() = %q, want the empty snapshot note", m.View())
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatal("q returned no command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("q returned %T, want tea.QuitMsg", cmd())
+	}
+}
@@ -0,0 +1,156 @@
+// Package browse implements the terminal UI of 'nomos browse': a
+// collapsible tree of a compiled snapshot with key search, a detail pane
+// showing each value's provenance, and copying of values and key paths.
+package browse
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// node is one key or list element of the snapshot tree.
+type node struct {
+	// label is the map key, or "[n]" for a list element.
+	label string
+
+	// path is the key path in source map syntax, e.g. "app.tags[0]".
+	path string
+
+	depth    int
+	value    any
+	children []*node
+	parent   *node
+	expanded bool
+}
+
+// isContainer reports whether n is a map or list that can be expanded.
+func (n *node) isContainer() bool {
+	switch n.value.(type) {
+	case map[string]any, []any:
+		return true
+	default:
+		return false
+	}
+}
+
+// buildTree returns the top-level nodes of data, with map keys sorted as in
+// serialized output.
+func buildTree(data map[string]any) []*node {
+	return childNodes(nil, data)
+}
+
+// childNodes returns the children of a map or list value under parent.
+func childNodes(parent *node, value any) []*node {
+	prefix, depth := "", 0
+	if parent != nil {
+		prefix, depth = parent.path, parent.depth+1
+	}
+
+	var nodes []*node
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			nodes = append(nodes, &node{label: k, path: path, depth: depth, value: v[k], parent: parent})
+		}
+	case []any:
+		for i, item := range v {
+			label := fmt.Sprintf("[%d]", i)
+			nodes = append(nodes, &node{label: label, path: prefix + label, depth: depth, value: item, parent: parent})
+		}
+	}
+	for _, n := range nodes {
+		n.children = childNodes(n, n.value)
+	}
+	return nodes
+}
+
+// visible appends the nodes shown for roots, descending only into expanded
+// containers.
+func visible(dst []*node, roots []*node) []*node {
+	for _, n := range roots {
+		dst = append(dst, n)
+		if n.expanded {
+			dst = visible(dst, n.children)
+		}
+	}
+	return dst
+}
+
+// walk calls fn for every node in display order, expanded or not.
+func walk(roots []*node, fn func(*node)) {
+	for _, n := range roots {
+		fn(n)
+		walk(n.children, fn)
+	}
+}
+
+// topLevelKey returns the first key of a key path, under which provenance
+// is recorded.
+func topLevelKey(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// summary renders a value on one line: scalars as JSON, containers by size.
+func summary(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return fmt.Sprintf("{%d keys}", len(v))
+	case []any:
+		return fmt.Sprintf("[%d items]", len(v))
+	default:
+		return compactJSON(v)
+	}
+}
+
+// typeName describes a value's type for the detail pane.
+func typeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "map"
+	case []any:
+		return "list"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// copyText returns what copying a value puts on the clipboard: strings raw,
+// everything else as indented JSON, as 'nomos get' prints them.
+func copyText(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+func compactJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
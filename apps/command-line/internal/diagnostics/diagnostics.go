@@ -11,34 +11,25 @@ import (
 	"io"
 	"strings"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/ui"
 	"github.com/autonomous-bits/nomos/libs/compiler"
-	"github.com/fatih/color"
 )
 
 // Formatter formats compiler diagnostics for CLI output.
 type Formatter struct {
 	// UseColor enables colored output for terminals.
 	UseColor bool
-
-	// Color functions for styling
-	errorColor   *color.Color
-	warningColor *color.Color
-	boldColor    *color.Color
 }
 
-// NewFormatter creates a new diagnostics formatter.
+// NewFormatter creates a new diagnostics formatter. Callers decide
+// useColor for the destination with ui.ColorEnabled.
 func NewFormatter(useColor bool) *Formatter {
-	f := &Formatter{
-		UseColor:     useColor,
-		errorColor:   color.New(color.FgRed, color.Bold),
-		warningColor: color.New(color.FgYellow, color.Bold),
-		boldColor:    color.New(color.Bold),
-	}
+	return &Formatter{UseColor: useColor}
+}
 
-	// Configure color output based on useColor flag
-	// Note: We don't set color.NoColor globally as it affects all instances
-	// Instead, we control color output through formatMessage
-	return f
+// style returns the ui.Style of f.
+func (f *Formatter) style() ui.Style {
+	return ui.NewStyle(f.UseColor)
 }
 
 // FormatErrors formats error diagnostics from compiler metadata.
@@ -50,7 +41,7 @@ func (f *Formatter) FormatErrors(errors []string) []string {
 
 	formatted := make([]string, 0, len(errors))
 	for _, errMsg := range errors {
-		formatted = append(formatted, f.formatMessage("error", errMsg))
+		formatted = append(formatted, f.style().Error(errMsg))
 	}
 	return formatted
 }
@@ -64,57 +55,19 @@ func (f *Formatter) FormatWarnings(warnings []string) []string {
 
 	formatted := make([]string, 0, len(warnings))
 	for _, warnMsg := range warnings {
-		formatted = append(formatted, f.formatMessage("warning", warnMsg))
+		formatted = append(formatted, f.style().Warning(warnMsg))
 	}
 	return formatted
 }
 
-// formatMessage formats a single diagnostic message.
-// The message is expected to already contain file:line:col information
-// from the compiler's diagnostic formatting.
-func (f *Formatter) formatMessage(severity, message string) string {
-	if !f.UseColor {
-		return message
-	}
-
-	// Temporarily enable color for this specific formatting
-	oldNoColor := color.NoColor
-	color.NoColor = false
-	defer func() { color.NoColor = oldNoColor }()
-
-	// Apply color based on severity
-	switch severity {
-	case "error":
-		return f.errorColor.Sprint(message)
-	case "warning":
-		return f.warningColor.Sprint(message)
-	default:
-		return message
-	}
-}
-
 // PrintErrors writes formatted errors to the provided writer.
 func (f *Formatter) PrintErrors(w io.Writer, errors []string) {
 	if len(errors) == 0 {
 		return
 	}
 
-	// Temporarily enable color if requested
-	if f.UseColor {
-		oldNoColor := color.NoColor
-		color.NoColor = false
-		defer func() { color.NoColor = oldNoColor }()
-	}
-
-	// Print header
-	if f.UseColor {
-		_, _ = f.errorColor.Fprintf(w, "Errors:\n") // Ignore write errors
-	} else {
-		_, _ = fmt.Fprintf(w, "Errors:\n") // Ignore write errors
-	}
-
-	formatted := f.FormatErrors(errors)
-	for _, msg := range formatted {
+	_, _ = fmt.Fprintf(w, "%s\n", f.style().Error("Errors:")) // Ignore write errors
+	for _, msg := range f.FormatErrors(errors) {
 		_, _ = fmt.Fprintln(w, msg)
 	}
 }
@@ -125,23 +78,37 @@ func (f *Formatter) PrintWarnings(w io.Writer, warnings []string) {
 		return
 	}
 
-	// Temporarily enable color if requested
-	if f.UseColor {
-		oldNoColor := color.NoColor
-		color.NoColor = false
-		defer func() { color.NoColor = oldNoColor }()
+	_, _ = fmt.Fprintf(w, "%s\n", f.style().Warning("Warnings:")) // Ignore write errors
+	for _, msg := range f.FormatWarnings(warnings) {
+		_, _ = fmt.Fprintln(w, msg)
+	}
+}
+
+// FormatHints formats the remediation hints carried by err, as collected
+// by compiler.Hints: a "help:" line with the advice of each hint, followed
+// by a "docs:" line when the hint links to documentation.
+func (f *Formatter) FormatHints(err error) []string {
+	hints := compiler.Hints(err)
+	if len(hints) == 0 {
+		return nil
 	}
 
-	// Print header
-	if f.UseColor {
-		_, _ = f.warningColor.Fprintf(w, "Warnings:\n") // Ignore write errors
-	} else {
-		_, _ = fmt.Fprintf(w, "Warnings:\n") // Ignore write errors
+	formatted := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		if hint.Text != "" {
+			formatted = append(formatted, f.style().Bold("help:")+" "+hint.Text)
+		}
+		if hint.DocsURL != "" {
+			formatted = append(formatted, f.style().Bold("docs:")+" "+hint.DocsURL)
+		}
 	}
+	return formatted
+}
 
-	formatted := f.FormatWarnings(warnings)
-	for _, msg := range formatted {
-		_, _ = fmt.Fprintln(w, msg)
+// PrintHints writes the formatted hints of err to the provided writer.
+func (f *Formatter) PrintHints(w io.Writer, err error) {
+	for _, line := range f.FormatHints(err) {
+		_, _ = fmt.Fprintln(w, line) // Ignore write errors
 	}
 }
 
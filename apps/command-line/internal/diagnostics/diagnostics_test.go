@@ -2,6 +2,8 @@ package diagnostics_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -181,8 +183,7 @@ func TestFormatter_ColorOutput(t *testing.T) {
 	}
 
 	// Should contain ANSI color codes (red for errors)
-	// fatih/color uses different escape sequences
-	if !strings.Contains(result[0], "\x1b[") && !strings.Contains(result[0], "\033[") {
+	if !strings.Contains(result[0], "\x1b[") {
 		t.Errorf("Expected color codes in output, got: %s", result[0])
 	}
 }
@@ -292,3 +293,31 @@ func TestFormatter_FormatErrors_PreservesOriginalFormat(t *testing.T) {
 		t.Errorf("Expected preserved caret marker, got: %s", result[0])
 	}
 }
+
+// TestFormatter_PrintHints tests that the hints of an error are printed after
+// it, one "help:" line each and a "docs:" line for linked documentation.
+func TestFormatter_PrintHints(t *testing.T) {
+	// Arrange
+	formatter := diagnostics.NewFormatter(false)
+	err := fmt.Errorf("build failed: %w", compiler.WithHint(
+		compiler.WithHint(errors.New("provider unavailable"), compiler.Hint{Text: "run 'nomos providers doctor'", DocsURL: "https://example.com/doctor"}),
+		compiler.Hint{Text: "pass --allow-yanked to install it anyway"},
+	))
+	var buf bytes.Buffer
+
+	// Act
+	formatter.PrintHints(&buf, err)
+	formatter.PrintHints(&buf, errors.New("no advice"))
+
+	// Assert
+	want := "help: pass --allow-yanked to install it anyway\n" +
+		"help: run 'nomos providers doctor'\n" +
+		"docs: https://example.com/doctor\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintHints() wrote %q, want %q", got, want)
+	}
+
+	if got := diagnostics.NewFormatter(true).FormatHints(err)[0]; got != "\x1b[1mhelp:\x1b[0m pass --allow-yanked to install it anyway" {
+		t.Errorf("colored hint = %q", got)
+	}
+}
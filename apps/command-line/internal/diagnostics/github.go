@@ -0,0 +1,64 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// WriteGitHub writes diags as GitHub Actions workflow commands, one per
+// line, so the runner turns them into annotations on the lines of a pull
+// request diff:
+//
+//	::error file=config/app.csl,line=3,col=7::unresolved reference ...
+//	::warning file=config/app.csl,line=9,col=1,title=W001::provider ...
+//
+// File paths are made relative to base, which should be the repository
+// root (GITHUB_WORKSPACE), because annotations only attach to paths
+// relative to it. Diagnostics without a location annotate the run.
+func WriteGitHub(w io.Writer, diags []compiler.Diagnostic, base string) error {
+	for _, d := range diags {
+		var props []string
+		if d.File != "" {
+			file := d.File
+			if rel, ok := relativePath(file, base); ok {
+				file = rel
+			}
+			props = append(props, "file="+escapeProperty(filepath.ToSlash(file)))
+			if d.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", d.Line))
+			}
+			if d.Column > 0 {
+				props = append(props, fmt.Sprintf("col=%d", d.Column))
+			}
+		}
+		if d.Code != "" {
+			props = append(props, "title="+escapeProperty(string(d.Code)))
+		}
+
+		command := "error"
+		if d.Severity == compiler.SeverityWarning {
+			command = "warning"
+		}
+		if len(props) > 0 {
+			command += " " + strings.Join(props, ",")
+		}
+		if _, err := fmt.Fprintf(w, "::%s::%s\n", command, escapeData(d.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes a workflow command message, which may span lines.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
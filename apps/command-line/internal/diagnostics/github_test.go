@@ -0,0 +1,35 @@
+package diagnostics_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestWriteGitHub tests that diagnostics become workflow commands with
+// repository-relative paths and escaped values.
+func TestWriteGitHub(t *testing.T) {
+	// Arrange
+	diags := []compiler.Diagnostic{
+		{Severity: compiler.SeverityError, Message: "unresolved reference", File: "/repo/config/app.csl", Line: 3, Column: 7},
+		{Severity: compiler.SeverityWarning, Code: compiler.WarnMissingProvider, Message: "provider missing", File: "/elsewhere/a,b.csl", Line: 9, Column: 1},
+		{Severity: compiler.SeverityError, Message: "policy failed: 100%\nsee docs"},
+	}
+	var buf bytes.Buffer
+
+	// Act
+	err := diagnostics.WriteGitHub(&buf, diags, "/repo")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("WriteGitHub() error = %v", err)
+	}
+	want := "::error file=config/app.csl,line=3,col=7::unresolved reference\n" +
+		"::warning file=/elsewhere/a%2Cb.csl,line=9,col=1,title=W001::provider missing\n" +
+		"::error::policy failed: 100%25%0Asee docs\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteGitHub() =\n%s\nwant\n%s", got, want)
+	}
+}
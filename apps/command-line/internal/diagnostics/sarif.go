@@ -0,0 +1,260 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// SARIF rule IDs of diagnostics without a warning code.
+const (
+	sarifErrorRule   = "error"
+	sarifWarningRule = "warning"
+)
+
+// sarifRules describes the rules a SARIF log can report, keyed by rule ID.
+var sarifRules = map[string]sarifRule{
+	sarifErrorRule: {
+		Name:             "CompilationError",
+		ShortDescription: sarifText{Text: "Configuration does not compile"},
+		FullDescription:  sarifText{Text: "A syntax error, unresolved reference, failed function call, or other problem that stops the configuration from compiling."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "error",
+		},
+	},
+	sarifWarningRule: {
+		Name:             "CompilationWarning",
+		ShortDescription: sarifText{Text: "Compiler warning"},
+		FullDescription:  sarifText{Text: "A non-fatal problem reported by the compiler without a warning code."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "warning",
+		},
+	},
+	string(compiler.WarnMissingProvider): {
+		Name:             "MissingProvider",
+		ShortDescription: sarifText{Text: "Provider unavailable"},
+		FullDescription:  sarifText{Text: "A reference names a provider that could not be obtained, so its value is missing from the compiled configuration. Silence with \"# nomos:ignore W001\"."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "warning",
+		},
+	},
+	string(compiler.WarnFetchFailed): {
+		Name:             "FetchFailed",
+		ShortDescription: sarifText{Text: "Provider fetch failed"},
+		FullDescription:  sarifText{Text: "A provider could not fetch a referenced value, so it is missing from the compiled configuration. Silence with \"# nomos:ignore W002\"."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "warning",
+		},
+	},
+	string(compiler.WarnPolicyViolation): {
+		Name:             "PolicyViolation",
+		ShortDescription: sarifText{Text: "Policy violated"},
+		FullDescription:  sarifText{Text: "The compiled configuration violates a policy whose severity is warning. Silence with \"# nomos:ignore W003\"."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "warning",
+		},
+	},
+	string(compiler.WarnNondeterministicOrder): {
+		Name:             "NondeterministicOrder",
+		ShortDescription: sarifText{Text: "Provider list order changed"},
+		FullDescription:  sarifText{Text: "A provider returned the same list elements as in the previous build in a different order, so the compiled configuration changes between runs without a change in content. Silence with \"# nomos:ignore W004\"."},
+		DefaultConfiguration: sarifConfiguration{
+			Level: "warning",
+		},
+	},
+}
+
+// WriteSARIF writes diags as a SARIF 2.1.0 log, the format GitHub code
+// scanning and other static analysis dashboards import. Each diagnostic
+// becomes a result of the rule named by its warning code; uncoded errors
+// and warnings fall under the rules "error" and "warning". The log
+// describes every rule it references.
+//
+// File paths under base, which should be the repository root, are written
+// relative to the %SRCROOT% base URI, as code scanning requires; other
+// paths are written as file URIs. version is the tool version recorded in
+// the log.
+func WriteSARIF(w io.Writer, diags []compiler.Diagnostic, base, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "nomos",
+			InformationURI: "https://github.com/autonomous-bits/nomos",
+			Version:        version,
+			Rules:          []sarifRule{},
+		}},
+		Results: make([]sarifResult, 0, len(diags)),
+	}
+	if base != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			"%SRCROOT%": {URI: fileURI(base) + "/"},
+		}
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, d := range diags {
+		ruleID := string(d.Code)
+		if ruleID == "" {
+			ruleID = sarifErrorRule
+			if d.Severity == compiler.SeverityWarning {
+				ruleID = sarifWarningRule
+			}
+		}
+		index, ok := ruleIndex[ruleID]
+		if !ok {
+			rule := sarifRules[ruleID]
+			rule.ID = ruleID
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[ruleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		level := "error"
+		if d.Severity == compiler.SeverityWarning {
+			level = "warning"
+		}
+		result := sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     level,
+			Message:   sarifText{Text: d.Message},
+		}
+		if d.File != "" {
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: artifactLocation(d.File, base),
+				Region:           region(d),
+			}}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// artifactLocation locates file relative to %SRCROOT% when it is under
+// base, and by its absolute file URI otherwise.
+func artifactLocation(file, base string) sarifArtifactLocation {
+	if rel, ok := relativePath(file, base); ok {
+		return sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: "%SRCROOT%"}
+	}
+	if filepath.IsAbs(file) {
+		return sarifArtifactLocation{URI: fileURI(file)}
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(file)}).String()}
+}
+
+// region converts the location of d to a SARIF region. SARIF end columns
+// are exclusive, while compiler end columns point at the last character.
+func region(d compiler.Diagnostic) *sarifRegion {
+	if d.Line <= 0 {
+		return nil
+	}
+	r := &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+	if d.EndLine > 0 {
+		r.EndLine, r.EndColumn = d.EndLine, d.EndColumn+1
+	}
+	return r
+}
+
+// relativePath returns file relative to base when file is an absolute path
+// beneath base.
+func relativePath(file, base string) (string, bool) {
+	if base == "" || !filepath.IsAbs(file) {
+		return "", false
+	}
+	rel, err := filepath.Rel(base, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// fileURI returns the file URI of the absolute path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows drive letters
+	}
+	return (&url.URL{Scheme: "file", Path: strings.TrimSuffix(path, "/")}).String()
+}
+
+// The SARIF 2.1.0 objects written by WriteSARIF. Only the properties nomos
+// fills in are declared.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool               sarifTool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []sarifResult                    `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Version        string      `json:"version,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		Name                 string             `json:"name,omitempty"`
+		ShortDescription     sarifText          `json:"shortDescription,omitzero"`
+		FullDescription      sarifText          `json:"fullDescription,omitzero"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration,omitzero"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifText struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifText       `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+)
@@ -0,0 +1,107 @@
+package diagnostics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/diagnostics"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestWriteSARIF tests that diagnostics become SARIF results with rule
+// metadata and source regions relative to the repository root.
+func TestWriteSARIF(t *testing.T) {
+	// Arrange
+	diags := []compiler.Diagnostic{
+		{Severity: compiler.SeverityError, Message: "unresolved reference", File: "/repo/config/app.csl", Line: 3, Column: 7, EndLine: 3, EndColumn: 18},
+		{Severity: compiler.SeverityWarning, Code: compiler.WarnMissingProvider, Message: "provider missing", File: "/elsewhere/b.csl", Line: 9, Column: 1},
+		{Severity: compiler.SeverityWarning, Code: compiler.WarnMissingProvider, Message: "provider missing", File: "/repo/c.csl", Line: 2, Column: 4},
+		{Severity: compiler.SeverityError, Message: "policy failed"},
+	}
+	var buf bytes.Buffer
+
+	// Act
+	err := diagnostics.WriteSARIF(&buf, diags, "/repo", "v1.0.0")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Version string `json:"version"`
+					Rules   []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string `json:"uri"`
+							URIBaseID string `json:"uriBaseId"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+							EndLine     int `json:"endLine"`
+							EndColumn   int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("WriteSARIF() wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Version != "v1.0.0" {
+		t.Fatalf("WriteSARIF() log = %s, want one 2.1.0 run by v1.0.0", buf.String())
+	}
+	run := log.Runs[0]
+
+	rules := run.Tool.Driver.Rules
+	if len(rules) != 2 || rules[0].ID != "error" || rules[1].ID != "W001" || rules[1].ShortDescription.Text == "" {
+		t.Errorf("rules = %+v, want described error and W001 rules", rules)
+	}
+
+	if len(run.Results) != len(diags) {
+		t.Fatalf("results = %+v, want one per diagnostic", run.Results)
+	}
+	first := run.Results[0]
+	if first.RuleID != "error" || first.RuleIndex != 0 || first.Level != "error" || len(first.Locations) != 1 {
+		t.Fatalf("results[0] = %+v, want a located error", first)
+	}
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "config/app.csl" || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("results[0] artifact = %+v, want config/app.csl under %%SRCROOT%%", loc.ArtifactLocation)
+	}
+	if r := loc.Region; r.StartLine != 3 || r.StartColumn != 7 || r.EndLine != 3 || r.EndColumn != 19 {
+		t.Errorf("results[0] region = %+v, want 3:7-3:19 (exclusive end)", r)
+	}
+
+	outside := run.Results[1]
+	if outside.RuleID != "W001" || outside.RuleIndex != 1 || outside.Level != "warning" {
+		t.Errorf("results[1] = %+v, want a W001 warning", outside)
+	}
+	if artifact := outside.Locations[0].PhysicalLocation.ArtifactLocation; artifact.URI != "file:///elsewhere/b.csl" || artifact.URIBaseID != "" {
+		t.Errorf("results[1] artifact = %+v, want an absolute file URI", artifact)
+	}
+	if run.Results[2].RuleIndex != 1 {
+		t.Errorf("results[2] rule index = %d, want the shared W001 rule", run.Results[2].RuleIndex)
+	}
+	if unlocated := run.Results[3]; unlocated.RuleID != "error" || len(unlocated.Locations) != 0 {
+		t.Errorf("results[3] = %+v, want an unlocated error", unlocated)
+	}
+}
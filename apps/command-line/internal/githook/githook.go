@@ -0,0 +1,152 @@
+// Package githook installs the git pre-commit hook that checks .csl files
+// before they are committed, and lists the files a commit would change.
+//
+// Hooks are written to the hooks directory git itself uses, so
+// core.hooksPath and linked worktrees are honoured. Installed hooks carry
+// a marker line; hooks without it belong to someone else and are never
+// replaced or removed unless forced.
+package githook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PreCommit is the name of the hook Install writes.
+const PreCommit = "pre-commit"
+
+// marker identifies hooks written by Install.
+const marker = "# Installed by nomos hook install."
+
+// ErrForeignHook reports an existing hook that was not installed by nomos.
+var ErrForeignHook = errors.New("hook was not installed by nomos")
+
+// Script returns a hook script that runs command with the hook's arguments.
+// command is a shell command line, such as "nomos hook run".
+func Script(command string) string {
+	return "#!/bin/sh\n" +
+		marker + " Remove with nomos hook uninstall.\n" +
+		"# Skip once with git commit --no-verify.\n" +
+		"exec " + command + " \"$@\"\n"
+}
+
+// Install writes script as the hook name of the git repository containing
+// dir and returns its path. An existing hook not installed by nomos is
+// replaced only with force; otherwise Install returns ErrForeignHook.
+func Install(ctx context.Context, dir, name, script string, force bool) (string, error) {
+	path, err := hookPath(ctx, dir, name)
+	if err != nil {
+		return "", err
+	}
+	if !force {
+		if owned, err := replaceable(path); err != nil {
+			return "", err
+		} else if !owned {
+			return "", fmt.Errorf("%s: %w", path, ErrForeignHook)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("cannot create hooks directory: %w", err)
+	}
+	//nolint:gosec // G306: Git only runs executable hooks
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", fmt.Errorf("cannot write hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	//nolint:gosec // G302: Git only runs executable hooks
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", fmt.Errorf("cannot make hook executable: %w", err)
+	}
+	return path, nil
+}
+
+// Uninstall removes the hook name of the git repository containing dir if
+// nomos installed it, and returns its path. It reports whether a hook was
+// removed; hooks not installed by nomos yield ErrForeignHook.
+func Uninstall(ctx context.Context, dir, name string) (string, bool, error) {
+	path, err := hookPath(ctx, dir, name)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+		return path, false, nil
+	}
+	owned, err := replaceable(path)
+	if err != nil {
+		return "", false, err
+	}
+	if !owned {
+		return "", false, fmt.Errorf("%s: %w", path, ErrForeignHook)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", false, fmt.Errorf("cannot remove hook: %w", err)
+	}
+	return path, true, nil
+}
+
+// StagedFiles returns the absolute paths of the files with extension ext
+// that are added, copied, modified, or renamed in the index of the git
+// repository containing dir, in the order git lists them.
+func StagedFiles(ctx context.Context, dir, ext string) ([]string, error) {
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	out, err := git(ctx, top, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR", "--", "*"+ext)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(top, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// hookPath returns the path of the hook name in the repository containing
+// dir.
+func hookPath(ctx context.Context, dir, name string) (string, error) {
+	path, err := git(ctx, dir, "rev-parse", "--path-format=absolute", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// replaceable reports whether the hook at path is absent or was written by
+// Install.
+func replaceable(path string) (bool, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Path is the repository's own hook
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read hook: %w", err)
+	}
+	return bytes.Contains(content, []byte(marker)), nil
+}
+
+// git runs git in dir and returns its output without the trailing newline.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	//nolint:gosec // G204: Arguments are fixed git subcommands
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", fmt.Errorf("cannot run git: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,148 @@
+package githook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newRepo creates a git repository and returns its directory.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	ctx := context.Background()
+	dir := newRepo(t)
+	sub := filepath.Join(dir, "config")
+	if err := os.MkdirAll(sub, 0750); err != nil {
+		t.Fatal(err)
+	}
+	script := Script("nomos hook run")
+
+	path, err := Install(ctx, sub, PreCommit, script, false)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if want := filepath.Join(dir, ".git", "hooks", PreCommit); path != want {
+		t.Errorf("Install() path = %s, want %s", path, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("hook not written: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("hook mode = %v, want executable", info.Mode())
+	}
+
+	// Reinstalling replaces a nomos hook
+	if _, err := Install(ctx, dir, PreCommit, Script("nomos hook run -q"), false); err != nil {
+		t.Errorf("Install() over a nomos hook error = %v", err)
+	}
+
+	// Hooks written by others are kept unless forced
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(ctx, dir, PreCommit, script, false); !errors.Is(err, ErrForeignHook) {
+		t.Errorf("Install() over a foreign hook error = %v, want ErrForeignHook", err)
+	}
+	if _, err := Install(ctx, dir, PreCommit, script, true); err != nil {
+		t.Errorf("Install(force) error = %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != script {
+		t.Errorf("hook after forced install = %q, want %q", content, script)
+	}
+}
+
+func TestInstall_NotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	if _, err := Install(context.Background(), dir, PreCommit, Script("nomos hook run"), false); err == nil {
+		t.Error("Install() outside a repository succeeded")
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	ctx := context.Background()
+	dir := newRepo(t)
+
+	if _, removed, err := Uninstall(ctx, dir, PreCommit); err != nil || removed {
+		t.Errorf("Uninstall() without a hook = %v, %v; want nothing removed", removed, err)
+	}
+
+	path, err := Install(ctx, dir, PreCommit, Script("nomos hook run"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, removed, err := Uninstall(ctx, dir, PreCommit); err != nil || !removed {
+		t.Errorf("Uninstall() = %v, %v; want the hook removed", removed, err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("hook still exists: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Uninstall(ctx, dir, PreCommit); !errors.Is(err, ErrForeignHook) {
+		t.Errorf("Uninstall() of a foreign hook error = %v, want ErrForeignHook", err)
+	}
+}
+
+func TestStagedFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := newRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.csl", "a: '1'\n")
+	write("gone.csl", "g: '1'\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	write("a.csl", "a: '2'\n")
+	write("config/b c.csl", "b: '1'\n")
+	write("notes.md", "notes\n")
+	write("unstaged.csl", "u: '1'\n")
+	runGit(t, dir, "add", "a.csl", "config/b c.csl", "notes.md")
+	runGit(t, dir, "rm", "-q", "gone.csl")
+
+	files, err := StagedFiles(ctx, filepath.Join(dir, "config"), ".csl")
+	if err != nil {
+		t.Fatalf("StagedFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a.csl"), filepath.Join(dir, "config", "b c.csl")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("StagedFiles() = %v, want %v", files, want)
+	}
+}
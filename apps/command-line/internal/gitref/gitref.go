@@ -0,0 +1,226 @@
+// Package gitref reads project files as of a git revision, so configuration
+// can be compiled at a commit without checking it out.
+//
+// The files come from git's object database through git archive; the work
+// tree, index, and HEAD are never touched, so uncommitted changes are safe
+// and concurrent runs do not interfere.
+package gitref
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Tree is the project as of a revision, extracted into a temporary
+// directory. Close removes it.
+type Tree struct {
+	// Commit is the full hash the revision resolved to.
+	Commit string
+
+	// Dir is the directory that corresponds, at Commit, to the working
+	// directory the tree was opened from.
+	Dir string
+
+	root string
+}
+
+// Open extracts the revision ref (a branch, tag, or commit, as git
+// rev-parse accepts) of the git repository containing dir.
+func Open(ctx context.Context, dir, ref string) (*Tree, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", ref)
+	}
+	commit, err := git(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown git revision %q: %w", ref, err)
+	}
+	prefix, err := git(ctx, dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	// Run from a subdirectory, git archive would include only that
+	// subdirectory, so it runs at the top of the work tree
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.MkdirTemp("", "nomos-"+commit[:min(12, len(commit))]+"-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract git revision: %w", err)
+	}
+	tree := &Tree{Commit: commit, Dir: filepath.Join(root, filepath.FromSlash(prefix)), root: root}
+
+	//nolint:gosec // G204: The revision was resolved to a commit hash above
+	cmd := exec.CommandContext(ctx, "git", "archive", "--format=tar", commit)
+	cmd.Dir = top
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("cannot run git: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("cannot run git: %w", err)
+	}
+	extractErr := extract(out, root)
+	if extractErr != nil {
+		// Unblock git if extraction stopped early
+		_, _ = io.Copy(io.Discard, out)
+	}
+	if err := cmd.Wait(); err != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("git archive %s failed: %w: %s", ref, err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("cannot extract git revision %s: %w", ref, extractErr)
+	}
+
+	if err := os.MkdirAll(tree.Dir, 0750); err != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("cannot extract git revision: %w", err)
+	}
+	return tree, nil
+}
+
+// Fetch updates the remote-tracking refs of the git repository containing
+// dir from its default remote, so commits pushed elsewhere can be opened.
+func Fetch(ctx context.Context, dir string) error {
+	if _, err := git(ctx, dir, "fetch", "--quiet"); err != nil {
+		return fmt.Errorf("git fetch failed: %w", err)
+	}
+	return nil
+}
+
+// IsAncestor reports whether commit ancestor is an ancestor of commit
+// descendant in the git repository containing dir. A commit is its own
+// ancestor.
+func IsAncestor(ctx context.Context, dir, ancestor, descendant string) (bool, error) {
+	//nolint:gosec // G204: Arguments are commit hashes
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	case strings.TrimSpace(stderr.String()) != "":
+		return false, fmt.Errorf("cannot compare commits: %s", strings.TrimSpace(stderr.String()))
+	default:
+		return false, fmt.Errorf("cannot compare commits: %w", err)
+	}
+}
+
+// Path maps path, relative to the directory the tree was opened from, into
+// the tree. Absolute paths are made relative to workDir first; paths
+// outside the repository cannot be mapped.
+func (t *Tree) Path(workDir, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(workDir, path)
+		if err != nil {
+			return "", fmt.Errorf("cannot map %s into git revision: %w", path, err)
+		}
+		path = rel
+	}
+	mapped := filepath.Join(t.Dir, path)
+	if !isWithin(t.root, mapped) {
+		return "", fmt.Errorf("%s is outside the git repository", path)
+	}
+	return mapped, nil
+}
+
+// Close removes the extracted files.
+func (t *Tree) Close() error {
+	return os.RemoveAll(t.root)
+}
+
+// extract writes the regular files and directories of a tar stream into
+// dir. Symbolic links are recreated only when they stay inside dir.
+func extract(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !isWithin(dir, target) {
+			return fmt.Errorf("archive entry %q escapes the target directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) //nolint:gosec // G304: Target is checked to stay inside dir
+			if err != nil {
+				return err
+			}
+			//nolint:gosec // G110: Content comes from the user's own repository
+			if _, err := io.Copy(f, tr); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			link := header.Linkname
+			if !filepath.IsAbs(link) && isWithin(dir, filepath.Join(filepath.Dir(target), link)) {
+				if err := os.Symlink(link, target); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// isWithin reports whether path is dir or beneath it.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	//nolint:gosec // G204: Arguments are fixed git subcommands and a checked revision
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", errors.New("not found")
+		}
+		return "", fmt.Errorf("cannot run git: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
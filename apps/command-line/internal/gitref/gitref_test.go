@@ -0,0 +1,124 @@
+package gitref
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a repository with a commit of config/app.csl, then
+// changes the file in the work tree without committing.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config", "app.csl"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q")
+	write("committed")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("tag", "v1")
+	write("uncommitted")
+	return dir
+}
+
+func TestOpen(t *testing.T) {
+	repo := initRepo(t)
+	workDir := filepath.Join(repo, "config")
+
+	tree, err := Open(context.Background(), workDir, "v1")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(tree.Commit) < 40 {
+		t.Errorf("Commit = %q, want a full hash", tree.Commit)
+	}
+
+	// Dir corresponds to the work directory, a subdirectory of the repository
+	path, err := tree.Path(workDir, "app.csl")
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "committed" {
+		t.Errorf("app.csl at v1 = %q, %v; want committed content", content, err)
+	}
+	if abs, err := tree.Path(workDir, filepath.Join(workDir, "app.csl")); err != nil || abs != path {
+		t.Errorf("Path(absolute) = %q, %v; want %q", abs, err, path)
+	}
+	if _, err := tree.Path(workDir, "../../outside.csl"); err == nil {
+		t.Error("Path() outside the repository expected an error")
+	}
+
+	// The work tree keeps its uncommitted change
+	if content, _ := os.ReadFile(filepath.Join(workDir, "app.csl")); string(content) != "uncommitted" {
+		t.Errorf("work tree app.csl = %q, want it untouched", content)
+	}
+
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tree.Dir); !os.IsNotExist(err) {
+		t.Errorf("Close() left %s behind", tree.Dir)
+	}
+}
+
+func TestOpen_UnknownRevision(t *testing.T) {
+	repo := initRepo(t)
+	for _, ref := range []string{"no-such-branch", "--output=x"} {
+		if _, err := Open(context.Background(), repo, ref); err == nil {
+			t.Errorf("Open(%q) expected an error", ref)
+		}
+	}
+}
+
+func TestIsAncestor(t *testing.T) {
+	repo := initRepo(t)
+	cmd := exec.Command("git", "commit", "-q", "-a", "-m", "second")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+	var commits []string
+	for _, ref := range []string{"v1", "HEAD"} {
+		tree, err := Open(context.Background(), repo, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, tree.Commit)
+		_ = tree.Close()
+	}
+
+	if ok, err := IsAncestor(context.Background(), repo, commits[0], commits[1]); err != nil || !ok {
+		t.Errorf("IsAncestor(v1, HEAD) = %v, %v; want true", ok, err)
+	}
+	if ok, err := IsAncestor(context.Background(), repo, commits[1], commits[0]); err != nil || ok {
+		t.Errorf("IsAncestor(HEAD, v1) = %v, %v; want false", ok, err)
+	}
+	if _, err := IsAncestor(context.Background(), repo, commits[0], "0123456789012345678901234567890123456789"); err == nil {
+		t.Error("IsAncestor() with an unknown commit expected an error")
+	}
+}
@@ -0,0 +1,143 @@
+// Package golden discovers golden-file test cases for Nomos configurations
+// and renders readable diffs between expected and actual output.
+//
+// A test directory holds one case per .csl file or subdirectory. Each case
+// is compiled and compared with a golden file beside it, named after the
+// case with a ".golden" infix and the output format's extension:
+//
+//	tests/
+//	  prod.csl            compiled alone
+//	  prod.golden.json    expected output
+//	  staging/            compiled as a directory
+//	  staging.golden.json expected output
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Case is a single golden-file test case.
+type Case struct {
+	// Name identifies the case (the file or directory name without extension).
+	Name string
+
+	// Path is the .csl file or directory to compile.
+	Path string
+
+	// GoldenPath is the file holding the expected output.
+	GoldenPath string
+}
+
+// Discover returns the cases in dir, sorted by name. ext is the golden file
+// extension including the leading dot (e.g. ".json").
+func Discover(dir, ext string) ([]Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read test directory: %w", err)
+	}
+
+	var cases []Case
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, ".csl"):
+			name = strings.TrimSuffix(name, ".csl")
+		default:
+			continue
+		}
+
+		cases = append(cases, Case{
+			Name:       name,
+			Path:       filepath.Join(dir, entry.Name()),
+			GoldenPath: filepath.Join(dir, name+".golden"+ext),
+		})
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Diff returns a line-oriented diff of want and got, or "" if they are equal.
+// Removed lines are prefixed with "-", added lines with "+", and unchanged
+// lines with a space; runs of unchanged lines longer than the context are
+// elided.
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// Longest common subsequence table, computed from the end.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+
+	return strings.Join(elide(lines, 3), "\n") + "\n"
+}
+
+// elide replaces unchanged runs more than context lines away from a change
+// with a single "..." marker.
+func elide(lines []string, context int) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+
+	var out []string
+	skipped := false
+	for i, line := range lines {
+		if keep[i] {
+			out = append(out, line)
+			skipped = false
+			continue
+		}
+		if !skipped {
+			out = append(out, "...")
+			skipped = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"prod.csl", "prod.golden.json", "README.md", ".hidden.csl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"staging", ".git"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases, err := Discover(dir, ".json")
+	if err != nil {
+		t.Fatalf("Discover() unexpected error: %v", err)
+	}
+
+	want := []Case{
+		{Name: "prod", Path: filepath.Join(dir, "prod.csl"), GoldenPath: filepath.Join(dir, "prod.golden.json")},
+		{Name: "staging", Path: filepath.Join(dir, "staging"), GoldenPath: filepath.Join(dir, "staging.golden.json")},
+	}
+	if !reflect.DeepEqual(cases, want) {
+		t.Errorf("Discover() = %+v, want %+v", cases, want)
+	}
+
+	if _, err := Discover(filepath.Join(dir, "missing"), ".json"); err == nil {
+		t.Error("Discover() expected error for missing directory")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "equal",
+			want: "a\nb\n",
+			got:  "a\nb\n",
+			diff: "",
+		},
+		{
+			name: "changed line",
+			want: "a\nb\nc\n",
+			got:  "a\nx\nc\n",
+			diff: " a\n-b\n+x\n c\n",
+		},
+		{
+			name: "added line",
+			want: "a\n",
+			got:  "a\nb\n",
+			diff: " a\n+b\n",
+		},
+		{
+			name: "elides distant context",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			got:  "1\n2\n3\n4\n5\n6\n7\nX\n",
+			diff: "...\n 5\n 6\n 7\n-8\n+X\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package history keeps a local record of past builds, so the output of a
+// build can be compared with earlier ones without external storage.
+//
+// A history directory holds each distinct compiled snapshot once, named
+// after its content hash, and an index of the builds that produced them:
+//
+//	.nomos/history/
+//	  index.json                  builds, oldest first
+//	  objects/<sha256>.json       compiled data as canonical JSON
+//
+// Builds with identical data share one object. Retention limits drop the
+// oldest builds from the index and delete objects no build refers to.
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// DefaultDir is the history directory, relative to the project root.
+const DefaultDir = ".nomos/history"
+
+// ErrNotFound is returned when a reference matches no recorded build.
+var ErrNotFound = errors.New("no such build in history")
+
+// Entry describes one recorded build.
+type Entry struct {
+	// ID numbers builds in the order they were recorded, starting at 1.
+	// IDs are never reused, even after older builds are pruned.
+	ID int `json:"id"`
+
+	// Time is when the build was recorded, in UTC.
+	Time time.Time `json:"time"`
+
+	// Hash is the content hash of the compiled data, which names its object.
+	Hash string `json:"hash"`
+
+	// Path is the --path the build compiled.
+	Path string `json:"path"`
+
+	// InputFiles are the source files the build read.
+	InputFiles []string `json:"input_files"`
+
+	// Profiles are the profiles the build merged, if any.
+	Profiles []string `json:"profiles,omitempty"`
+}
+
+// Retention limits how many builds a store keeps. Zero values mean no limit.
+type Retention struct {
+	// MaxEntries is the number of most recent builds kept.
+	MaxEntries int
+
+	// MaxAge drops builds recorded longer ago than this.
+	MaxAge time.Duration
+}
+
+// Store is a history directory.
+type Store struct {
+	dir string
+}
+
+// index is the content of index.json.
+type index struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Open returns the store in dir. The directory is created by the first
+// Record; reading a store that does not exist yields no builds.
+func Open(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Record adds snapshot, compiled from path, to the store as a build made
+// at now, then applies keep. The new build is never pruned.
+func (s *Store) Record(snapshot compiler.Snapshot, path string, now time.Time, keep Retention) (Entry, error) {
+	var data bytes.Buffer
+	opts := serialize.Options{JSON: serialize.JSONFormat{Minify: true}}
+	if err := opts.WriteJSON(&data, compiler.Snapshot{Data: snapshot.Data}, false); err != nil {
+		return Entry{}, fmt.Errorf("failed to encode snapshot for history: %w", err)
+	}
+	hash, err := serialize.ContentHash(snapshot)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to hash snapshot for history: %w", err)
+	}
+
+	objectPath := s.objectPath(hash)
+	if _, err := os.Stat(objectPath); errors.Is(err, fs.ErrNotExist) {
+		if err := writeFile(objectPath, data.Bytes()); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{
+		ID:         1,
+		Time:       now.UTC(),
+		Hash:       hash,
+		Path:       path,
+		InputFiles: append([]string{}, snapshot.Metadata.InputFiles...),
+		Profiles:   snapshot.Metadata.Profiles,
+	}
+	if n := len(idx.Entries); n > 0 {
+		entry.ID = idx.Entries[n-1].ID + 1
+	}
+	idx.Entries = append(idx.Entries, entry)
+	idx.Entries = keep.apply(idx.Entries, now)
+
+	encoded, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode history index: %w", err)
+	}
+	if err := writeFile(filepath.Join(s.dir, "index.json"), append(encoded, '\n')); err != nil {
+		return Entry{}, err
+	}
+	return entry, s.removeUnreferenced(idx.Entries)
+}
+
+// apply returns the entries, oldest first, that keep retains at now. The
+// newest entry is always retained.
+func (keep Retention) apply(entries []Entry, now time.Time) []Entry {
+	first := 0
+	if keep.MaxEntries > 0 && len(entries) > keep.MaxEntries {
+		first = len(entries) - keep.MaxEntries
+	}
+	if keep.MaxAge > 0 {
+		cutoff := now.Add(-keep.MaxAge)
+		for first < len(entries)-1 && entries[first].Time.Before(cutoff) {
+			first++
+		}
+	}
+	return entries[first:]
+}
+
+// removeUnreferenced deletes the objects no entry refers to.
+func (s *Store) removeUnreferenced(entries []Entry) error {
+	referenced := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		referenced[e.Hash+".json"] = true
+	}
+	objects, err := os.ReadDir(filepath.Join(s.dir, "objects"))
+	if err != nil {
+		return fmt.Errorf("cannot read history: %w", err)
+	}
+	for _, object := range objects {
+		if referenced[object.Name()] || strings.HasPrefix(object.Name(), ".") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, "objects", object.Name())); err != nil {
+			return fmt.Errorf("cannot prune history: %w", err)
+		}
+	}
+	return nil
+}
+
+// List returns the recorded builds, oldest first.
+func (s *Store) List() ([]Entry, error) {
+	idx, err := s.readIndex()
+	return idx.Entries, err
+}
+
+// Resolve finds the build ref refers to:
+//
+//	latest   the newest build
+//	3        the build with ID 3
+//	24h      the newest build recorded at least this long before now
+//
+// Durations use Go syntax (e.g. 90m, 24h).
+func (s *Store) Resolve(ref string, now time.Time) (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if ref == "latest" {
+		if len(entries) == 0 {
+			return Entry{}, fmt.Errorf("%w: history is empty", ErrNotFound)
+		}
+		return entries[len(entries)-1], nil
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, e := range entries {
+			if e.ID == id {
+				return e, nil
+			}
+		}
+		return Entry{}, fmt.Errorf("%w: #%d", ErrNotFound, id)
+	}
+	age, err := time.ParseDuration(ref)
+	if err != nil || age < 0 {
+		return Entry{}, fmt.Errorf("invalid build reference %q (expected latest, a build ID, or a duration such as 24h)", ref)
+	}
+	cutoff := now.Add(-age)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Time.After(cutoff) {
+			return entries[i], nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w: none recorded %s or longer ago", ErrNotFound, ref)
+}
+
+// Load returns the compiled data of e.
+func (s *Store) Load(e Entry) (compiler.Snapshot, error) {
+	snapshot, err := compiler.LoadSnapshot(s.objectPath(e.Hash))
+	if err != nil {
+		return compiler.Snapshot{}, fmt.Errorf("cannot load build #%d: %w", e.ID, err)
+	}
+	return snapshot, nil
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash+".json")
+}
+
+func (s *Store) readIndex() (index, error) {
+	var idx index
+	content, err := os.ReadFile(filepath.Join(s.dir, "index.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, fmt.Errorf("cannot read history: %w", err)
+	}
+	if err := json.Unmarshal(content, &idx); err != nil {
+		return idx, fmt.Errorf("invalid history index %s: %w", filepath.Join(s.dir, "index.json"), err)
+	}
+	return idx, nil
+}
+
+// writeFile replaces path with content through a temporary file and a
+// rename, so an interrupted build never leaves a truncated index or object.
+func writeFile(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+func snapshotOf(data map[string]any) compiler.Snapshot {
+	return compiler.Snapshot{Data: data, Metadata: compiler.Metadata{InputFiles: []string{"config.csl"}}}
+}
+
+func objectCount(t *testing.T, dir string) int {
+	t.Helper()
+	objects, err := os.ReadDir(filepath.Join(dir, "objects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(objects)
+}
+
+func TestStore_RecordAndResolve(t *testing.T) {
+	dir := t.TempDir()
+	store := Open(dir)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	builds := []map[string]any{
+		{"app": map[string]any{"port": "80"}},
+		{"app": map[string]any{"port": "80"}},
+		{"app": map[string]any{"port": "8080"}},
+	}
+	for i, data := range builds {
+		entry, err := store.Record(snapshotOf(data), "config.csl", start.Add(time.Duration(i)*time.Hour), Retention{})
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if entry.ID != i+1 {
+			t.Errorf("entry ID = %d, want %d", entry.ID, i+1)
+		}
+	}
+
+	// Identical data is stored once
+	if n := objectCount(t, dir); n != 2 {
+		t.Errorf("objects = %d, want 2", n)
+	}
+
+	now := start.Add(3 * time.Hour)
+	for ref, want := range map[string]int{"latest": 3, "1": 1, "90m": 2, "2h": 2, "3h": 1} {
+		entry, err := store.Resolve(ref, now)
+		if err != nil {
+			t.Errorf("Resolve(%q) error = %v", ref, err)
+			continue
+		}
+		if entry.ID != want {
+			t.Errorf("Resolve(%q) = #%d, want #%d", ref, entry.ID, want)
+		}
+	}
+	for _, ref := range []string{"7", "4h"} {
+		if _, err := store.Resolve(ref, now); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q) error = %v, want ErrNotFound", ref, err)
+		}
+	}
+	if _, err := store.Resolve("yesterday", now); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve(yesterday) error = %v, want an invalid reference", err)
+	}
+
+	latest, _ := store.Resolve("latest", now)
+	snapshot, err := store.Load(latest)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(snapshot.Data, builds[2]) {
+		t.Errorf("Load() data = %v, want %v", snapshot.Data, builds[2])
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	dir := t.TempDir()
+	store := Open(dir)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	record := func(i int, keep Retention) {
+		t.Helper()
+		data := map[string]any{"build": string(rune('a' + i))}
+		if _, err := store.Record(snapshotOf(data), "config.csl", start.Add(time.Duration(i)*24*time.Hour), keep); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	ids := func() []int {
+		t.Helper()
+		entries, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	for i := range 4 {
+		record(i, Retention{MaxEntries: 3})
+	}
+	if got := ids(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("IDs after MaxEntries = %v, want [2 3 4]", got)
+	}
+	if n := objectCount(t, dir); n != 3 {
+		t.Errorf("objects after pruning = %d, want 3", n)
+	}
+
+	// Build 5 is recorded 4 days after build 1; a 48h limit keeps 3 to 5
+	record(4, Retention{MaxAge: 48 * time.Hour})
+	if got := ids(); !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Errorf("IDs after MaxAge = %v, want [3 4 5]", got)
+	}
+
+	// The newest build survives any limit, and IDs are not reused
+	record(10, Retention{MaxAge: time.Hour})
+	if got := ids(); !reflect.DeepEqual(got, []int{6}) {
+		t.Errorf("IDs after expiring all = %v, want [6]", got)
+	}
+}
+
+func TestStore_Empty(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "missing"))
+	entries, err := store.List()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List() = %v, %v; want no builds", entries, err)
+	}
+	if _, err := store.Resolve("latest", time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve(latest) error = %v, want ErrNotFound", err)
+	}
+}
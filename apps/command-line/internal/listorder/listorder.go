@@ -0,0 +1,126 @@
+// Package listorder keeps the list order digests of past builds, so a build
+// can flag providers that return the same list elements in a different
+// order from run to run.
+//
+// The file holds the compiler's Metadata.ListOrders of the last successful
+// build of each --path:
+//
+//	{
+//	  "builds": {
+//	    "app.csl": [{"reference": "@cfg:hosts", "provider": "cfg", ...}]
+//	  }
+//	}
+//
+// Builds whose providers return no lists leave the file untouched.
+package listorder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// DefaultPath is the list order file, relative to the project root.
+const DefaultPath = ".nomos/list-orders.json"
+
+// Store is a list order file.
+type Store struct {
+	path string
+}
+
+// file is the content of a list order file.
+type file struct {
+	Builds map[string][]compiler.ListOrder `json:"builds"`
+}
+
+// Open returns the store in path. The file is created by the first Save;
+// loading from a file that does not exist yields no list orders.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load returns the list orders last saved for build.
+func (s *Store) Load(build string) ([]compiler.ListOrder, error) {
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return f.Builds[buildKey(build)], nil
+}
+
+// Save replaces the list orders of build with orders, removing build when
+// orders is empty.
+func (s *Store) Save(build string, orders []compiler.ListOrder) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	key := buildKey(build)
+	if _, ok := f.Builds[key]; !ok && len(orders) == 0 {
+		return nil
+	}
+	if len(orders) == 0 {
+		delete(f.Builds, key)
+	} else {
+		f.Builds[key] = orders
+	}
+
+	encoded, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode list orders: %w", err)
+	}
+	return writeFile(s.path, append(encoded, '\n'))
+}
+
+// read returns the content of the file, empty if it does not exist.
+func (s *Store) read() (file, error) {
+	f := file{Builds: map[string][]compiler.ListOrder{}}
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, fmt.Errorf("cannot read list orders: %w", err)
+	}
+	if err := json.Unmarshal(content, &f); err != nil {
+		return f, fmt.Errorf("cannot read list orders from %s: %w", s.path, err)
+	}
+	if f.Builds == nil {
+		f.Builds = map[string][]compiler.ListOrder{}
+	}
+	return f, nil
+}
+
+// buildKey normalizes a --path so spellings of the same path share orders.
+func buildKey(build string) string {
+	return filepath.ToSlash(filepath.Clean(build))
+}
+
+// writeFile atomically replaces path with content.
+func writeFile(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("cannot write list orders: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write list orders: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("cannot write list orders: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write list orders: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write list orders: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package listorder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".nomos", "list-orders.json")
+	store := Open(path)
+
+	orders, err := store.Load("app.csl")
+	if err != nil || orders != nil {
+		t.Fatalf("Load() from a missing file = %v, %v, want none", orders, err)
+	}
+	if err := store.Save("app.csl", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Save() without orders created the file (stat error = %v)", err)
+	}
+
+	want := []compiler.ListOrder{{Reference: "@cfg:hosts", Provider: "cfg", OrderHash: "sha256:1", ContentHash: "sha256:2"}}
+	if err := store.Save("./app.csl", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save("other.csl", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, err := store.Load("app.csl"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, %v, want %v", got, err, want)
+	}
+
+	// A build whose providers no longer return lists drops its orders
+	if err := store.Save("app.csl", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Load("app.csl"); got != nil {
+		t.Errorf("Load() after clearing = %v, want none", got)
+	}
+	if got, _ := store.Load("other.csl"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Load(other.csl) = %v, want %v", got, want)
+	}
+}
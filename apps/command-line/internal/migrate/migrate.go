@@ -0,0 +1,273 @@
+// Package migrate rewrites deprecated Nomos syntax into its supported form.
+//
+// Files are parsed with legacy statements enabled, and each deprecated
+// construct found in the AST is replaced in the source text at its span, so
+// comments, indentation, and the rest of the file stay as written. The
+// rewritten file must parse without legacy statements, or nothing is
+// changed.
+//
+// Rewrites:
+//
+//	import:base                        →  @base:*
+//	import:base:app.settings           →  @base:app.settings
+//	reference:net:config:vpc.cidr      →  vpc_cidr: @net:config.vpc.cidr
+//	  host: reference:db:primary.host  →    host: @db:primary.host
+//
+// A top-level reference statement becomes a section named after the last
+// field of its path, with dots replaced by underscores; rename it afterwards
+// where another name fits better.
+package migrate
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// legacyPrefix introduces the reference values that were written without
+// "@" before inline references were introduced.
+const legacyPrefix = "reference:"
+
+// byteOrderMark is the UTF-8 byte order mark the parser ignores.
+const byteOrderMark = "\ufeff"
+
+// Change is one rewrite of deprecated syntax.
+type Change struct {
+	// Line is the 1-indexed line of the construct in the original file.
+	Line int
+
+	// Old is the deprecated text, and New its replacement.
+	Old string
+	New string
+}
+
+// Result is the outcome of migrating one file.
+type Result struct {
+	// Source is the migrated file, identical to the input without changes.
+	Source []byte
+
+	// Changes lists the rewrites in source order.
+	Changes []Change
+}
+
+// Source migrates the file src read from filename. The rewritten file keeps
+// the line endings and byte order mark of src.
+func Source(filename string, src []byte) (*Result, error) {
+	original := string(src)
+	text := normalize(original)
+
+	p := parser.NewParser(parser.WithLegacyStatements(true))
+	tree, err := p.Parse(strings.NewReader(text), filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &migration{text: text, lines: lineOffsets(text), sections: make(map[string]bool)}
+	for _, stmt := range tree.Statements {
+		if section, ok := stmt.(*ast.SectionDecl); ok {
+			m.sections[section.Name] = true
+		}
+	}
+	for _, stmt := range tree.Statements {
+		m.statement(stmt)
+	}
+	if len(m.changes) == 0 {
+		return &Result{Source: src}, nil
+	}
+
+	migrated := m.apply()
+	if _, err := parser.Parse(strings.NewReader(migrated), filename); err != nil {
+		return nil, fmt.Errorf("migrated file does not parse: %w", err)
+	}
+
+	if strings.Contains(original, "\r\n") {
+		migrated = strings.ReplaceAll(migrated, "\n", "\r\n")
+	}
+	if strings.HasPrefix(original, byteOrderMark) {
+		migrated = byteOrderMark + migrated
+	}
+
+	changes := make([]Change, len(m.changes))
+	for i, e := range m.changes {
+		changes[i] = Change{Line: e.line, Old: text[e.start:e.end], New: e.text}
+	}
+	return &Result{Source: []byte(migrated), Changes: changes}, nil
+}
+
+// edit replaces text[start:end] with text.
+type edit struct {
+	start, end int
+	line       int
+	text       string
+}
+
+// migration collects the edits of one file.
+type migration struct {
+	text    string
+	lines   []int // Byte offset of the start of each line
+	changes []edit
+
+	// sections holds the top-level section names in use, so sections made
+	// from reference statements get unique names.
+	sections map[string]bool
+}
+
+// statement records the edits of a top-level statement.
+func (m *migration) statement(stmt ast.Stmt) {
+	switch s := stmt.(type) {
+	case *ast.LegacyStmt:
+		m.legacy(s)
+	case *ast.SectionDecl:
+		if s.Value != nil {
+			m.expr(s.Value)
+		}
+		for _, entry := range s.Entries {
+			m.expr(entry.Value)
+		}
+	}
+}
+
+// legacy records the rewrite of a legacy top-level statement.
+func (m *migration) legacy(s *ast.LegacyStmt) {
+	switch s.Keyword {
+	case "import":
+		path := "*"
+		if len(s.Path) > 0 {
+			path = strings.Join(s.Path, ".")
+		}
+		m.replace(s.SourceSpan, "@"+s.Alias+":"+path)
+	case "reference":
+		if len(s.Path) == 0 {
+			return
+		}
+		key := m.sectionName(s.Alias, s.Path[len(s.Path)-1])
+		m.replace(s.SourceSpan, key+": @"+s.Alias+":"+strings.Join(s.Path, "."))
+	}
+}
+
+// expr records the rewrites of the legacy references in a value.
+func (m *migration) expr(e ast.Expr) {
+	switch v := e.(type) {
+	case *ast.StringLiteral:
+		m.literal(v)
+	case *ast.MapExpr:
+		for _, entry := range v.Entries {
+			m.expr(entry.Value)
+		}
+	case *ast.ListExpr:
+		for _, element := range v.Elements {
+			m.expr(element)
+		}
+	case *ast.MarkedExpr:
+		m.expr(v.Expr)
+	case *ast.CallExpr:
+		for _, arg := range v.Args {
+			m.expr(arg)
+		}
+	}
+}
+
+// literal records the rewrite of a bare "reference:alias:path" string,
+// which the parser reads as text since references took the "@" form.
+// Quoted strings are left alone: quoting makes the text intentional.
+func (m *migration) literal(lit *ast.StringLiteral) {
+	if !strings.HasPrefix(lit.Value, legacyPrefix) {
+		return
+	}
+	start, end, ok := m.offsets(lit.SourceSpan)
+	if !ok || m.text[start:end] != lit.Value {
+		return
+	}
+	fields := strings.Split(strings.TrimPrefix(lit.Value, legacyPrefix), ":")
+	if len(fields) < 2 || slices.Contains(fields, "") {
+		return
+	}
+	m.replace(lit.SourceSpan, "@"+fields[0]+":"+strings.Join(fields[1:], "."))
+}
+
+// sectionName derives a top-level key from the last path field of a
+// reference statement, such as "vpc_cidr" from "vpc.cidr", unique among
+// the sections of the file.
+func (m *migration) sectionName(alias, last string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '[' || r == '-':
+			return '_'
+		case r == ']' || r == '*':
+			return -1
+		}
+		return r
+	}, last)
+	name = strings.Trim(name, "_")
+	if name == "" {
+		name = alias
+	}
+
+	unique := name
+	for i := 2; m.sections[unique]; i++ {
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	m.sections[unique] = true
+	return unique
+}
+
+// replace records the replacement of the text at span.
+func (m *migration) replace(span ast.SourceSpan, text string) {
+	start, end, ok := m.offsets(span)
+	if !ok {
+		return
+	}
+	m.changes = append(m.changes, edit{start: start, end: end, line: span.StartLine, text: text})
+}
+
+// offsets returns the byte range of span, whose columns are 1-indexed and
+// whose end column is inclusive.
+func (m *migration) offsets(span ast.SourceSpan) (int, int, bool) {
+	if span.StartLine < 1 || span.EndLine > len(m.lines) || span.StartLine > span.EndLine {
+		return 0, 0, false
+	}
+	start := m.lines[span.StartLine-1] + span.StartCol - 1
+	end := m.lines[span.EndLine-1] + span.EndCol
+	if start < 0 || end > len(m.text) || start >= end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// apply returns the text with all edits made.
+func (m *migration) apply() string {
+	sort.Slice(m.changes, func(i, j int) bool { return m.changes[i].start < m.changes[j].start })
+	var b strings.Builder
+	last := 0
+	for _, e := range m.changes {
+		b.WriteString(m.text[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(m.text[last:])
+	return b.String()
+}
+
+// lineOffsets returns the byte offset of the start of each line of text.
+func lineOffsets(text string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// normalize removes a byte order mark and converts line endings to "\n",
+// as the parser does before it records spans.
+func normalize(text string) string {
+	text = strings.TrimPrefix(text, byteOrderMark)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
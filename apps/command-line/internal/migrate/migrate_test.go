@@ -0,0 +1,114 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		lines []int
+	}{
+		{
+			name: "top-level statements",
+			input: "source:\n  alias: 'net'\n  type: 'folder'\n\n" +
+				"# Shared settings\n" +
+				"import:base\n" +
+				"import:base:app.settings   # kept\n" +
+				"reference:net:config:vpc.cidr\n" +
+				"reference:net:vpc.cidr\n",
+			want: "source:\n  alias: 'net'\n  type: 'folder'\n\n" +
+				"# Shared settings\n" +
+				"@base:*\n" +
+				"@base:app.settings   # kept\n" +
+				"vpc_cidr: @net:config.vpc.cidr\n" +
+				"vpc_cidr_2: @net:vpc.cidr\n",
+			lines: []int{6, 7, 8, 9},
+		},
+		{
+			name: "inline values",
+			input: "app:\n" +
+				"  host: reference:db:primary.host\n" +
+				"  quoted: 'reference:db:primary.host'\n" +
+				"  servers:\n" +
+				"    - reference:net:web.ip\n" +
+				"    - web02\n" +
+				"region: reference:net:region\n",
+			want: "app:\n" +
+				"  host: @db:primary.host\n" +
+				"  quoted: 'reference:db:primary.host'\n" +
+				"  servers:\n" +
+				"    - @net:web.ip\n" +
+				"    - web02\n" +
+				"region: @net:region\n",
+			lines: []int{2, 5, 7},
+		},
+		{
+			name:  "section name in use",
+			input: "primary_host: 'old'\nreference:db:primary.host\n",
+			want:  "primary_host: 'old'\nprimary_host_2: @db:primary.host\n",
+			lines: []int{2},
+		},
+		{
+			name:  "line endings kept",
+			input: "\ufeffapp:\r\n  host: reference:db:host\r\n",
+			want:  "\ufeffapp:\r\n  host: @db:host\r\n",
+			lines: []int{2},
+		},
+		{
+			name:  "nothing to migrate",
+			input: "app:\n  host: @db:primary.host\n",
+			want:  "app:\n  host: @db:primary.host\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Source("app.csl", []byte(tt.input))
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			if got := string(result.Source); got != tt.want {
+				t.Errorf("Source() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			var lines []int
+			for _, c := range result.Changes {
+				lines = append(lines, c.Line)
+				if c.Old == "" || c.New == "" || c.Old == c.New {
+					t.Errorf("change %+v does not rewrite anything", c)
+				}
+			}
+			if len(lines) != len(tt.lines) {
+				t.Fatalf("changes on lines %v, want %v", lines, tt.lines)
+			}
+			for i := range lines {
+				if lines[i] != tt.lines[i] {
+					t.Errorf("changes on lines %v, want %v", lines, tt.lines)
+					break
+				}
+			}
+
+			// Migrating again changes nothing
+			again, err := Source("app.csl", result.Source)
+			if err != nil || len(again.Changes) != 0 {
+				t.Errorf("second migration = %+v, %v; want no changes", again, err)
+			}
+		})
+	}
+}
+
+func TestSource_Errors(t *testing.T) {
+	for _, input := range []string{
+		"app:\n  host: 'unterminated\n  $bad\n",
+		"reference:\n",
+	} {
+		if _, err := Source("app.csl", []byte(input)); err == nil {
+			t.Errorf("Source(%q) succeeded, want a parse error", input)
+		} else if !strings.Contains(err.Error(), "app.csl") {
+			t.Errorf("Source(%q) error = %v, want it to name the file", input, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package nomosdir locates the directories the CLI keeps provider state in.
+//
+// The project's .nomos directory holds the provider lockfile, the provider
+// manifest, and the project configuration. Installed provider binaries live
+// under providers/ in the nomos directory, which defaults to the project's
+// .nomos but can be moved, for example to a shared CI cache volume or out of
+// a read-only source checkout. The lockfile stays in the project either way,
+// so it can be committed and reviewed with the sources.
+//
+// The nomos directory is chosen by, in order of precedence, the --nomos-dir
+// flag, the NOMOS_DIR environment variable, and nomos_dir in
+// .nomos/config.yaml.
+//
+// Downloaded binaries are also kept in a content-addressable store shared by
+// all projects, from which installations are linked; see StoreDir.
+package nomosdir
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+)
+
+// ProjectDir is the project's .nomos directory, relative to the project root.
+const ProjectDir = ".nomos"
+
+// EnvVar is the environment variable that overrides the nomos directory.
+const EnvVar = "NOMOS_DIR"
+
+// StoreEnvVar is the environment variable that moves the provider store, or
+// disables it when set to "off".
+const StoreEnvVar = "NOMOS_STORE_DIR"
+
+// LockfilePath is the provider lockfile, relative to the project root.
+const LockfilePath = ProjectDir + "/providers.lock.json"
+
+// ManifestPath is the provider manifest, relative to the project root.
+const ManifestPath = ProjectDir + "/providers.yaml"
+
+// dir is the nomos directory in effect.
+var dir = ProjectDir
+
+// Resolve returns the nomos directory selected by flag, the environment, and
+// configured (from the project configuration), in order of precedence,
+// falling back to ProjectDir.
+func Resolve(flag, configured string) string {
+	return cmp.Or(flag, os.Getenv(EnvVar), configured, ProjectDir)
+}
+
+// Set makes d the nomos directory for the rest of the process. An empty d
+// restores ProjectDir.
+func Set(d string) {
+	dir = cmp.Or(d, ProjectDir)
+}
+
+// Dir returns the nomos directory in effect.
+func Dir() string {
+	return dir
+}
+
+// ProvidersDir returns the directory installed provider binaries live in,
+// laid out as {owner}/{repo}/{version}/{os}_{arch}/provider.
+func ProvidersDir() string {
+	return filepath.Join(dir, "providers")
+}
+
+// StoreDir returns the content-addressable store provider binaries are
+// linked from: NOMOS_STORE_DIR, or nomos/store in the user cache directory.
+// It returns "" when the store is disabled or no cache directory is known.
+func StoreDir() string {
+	switch env := os.Getenv(StoreEnvVar); env {
+	case "off":
+		return ""
+	case "":
+	default:
+		return env
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cache, "nomos", "store")
+}
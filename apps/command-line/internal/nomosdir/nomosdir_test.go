@@ -0,0 +1,62 @@
+package nomosdir
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		env        string
+		configured string
+		want       string
+	}{
+		{name: "default", want: ProjectDir},
+		{name: "project config", configured: "/cache/config", want: "/cache/config"},
+		{name: "environment over project config", env: "/cache/env", configured: "/cache/config", want: "/cache/env"},
+		{name: "flag over environment", flag: "/cache/flag", env: "/cache/env", configured: "/cache/config", want: "/cache/flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvVar, tt.env)
+			if got := Resolve(tt.flag, tt.configured); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Cleanup(func() { Set("") })
+
+	Set("/cache/nomos")
+	if got, want := ProvidersDir(), filepath.Join("/cache/nomos", "providers"); got != want {
+		t.Errorf("ProvidersDir() = %q, want %q", got, want)
+	}
+
+	Set("")
+	if got := Dir(); got != ProjectDir {
+		t.Errorf("Dir() = %q, want %q", got, ProjectDir)
+	}
+}
+
+func TestStoreDir(t *testing.T) {
+	t.Setenv(StoreEnvVar, "/cache/store")
+	if got := StoreDir(); got != "/cache/store" {
+		t.Errorf("StoreDir() = %q, want %q", got, "/cache/store")
+	}
+
+	t.Setenv(StoreEnvVar, "off")
+	if got := StoreDir(); got != "" {
+		t.Errorf("StoreDir() = %q, want empty when disabled", got)
+	}
+
+	t.Setenv(StoreEnvVar, "")
+	t.Setenv("XDG_CACHE_HOME", "/xdg")
+	t.Setenv("HOME", "/home/user")
+	if got := StoreDir(); filepath.Base(got) != "store" {
+		t.Errorf("StoreDir() = %q, want a store in the user cache directory", got)
+	}
+}
@@ -2,13 +2,19 @@
 package options
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
 	"github.com/autonomous-bits/nomos/libs/compiler"
+	"gopkg.in/yaml.v3"
 )
 
 // BuildParams holds the parameters for building compiler.Options.
@@ -28,6 +34,14 @@ type BuildParams struct {
 	// AllowMissingProvider allows missing provider fetches.
 	AllowMissingProvider bool
 
+	// PartialFailure is the partial failure mode: fail-fast, collect-all,
+	// or best-effort. Empty means fail-fast.
+	PartialFailure string
+
+	// FetchRetries is how many times a provider fetch failing with a
+	// transient error is retried.
+	FetchRetries int
+
 	// ProviderRegistry is the registry to use for providers.
 	// If nil, NewProviderRegistries creates a default empty registry.
 	ProviderRegistry compiler.ProviderRegistry
@@ -38,6 +52,69 @@ type BuildParams struct {
 
 	// EncryptionKey is the AES-256 key used to encrypt marked secrets.
 	EncryptionKey []byte
+
+	// SuppressWarnings lists warning codes to silence (e.g., "W001").
+	SuppressWarnings []string
+
+	// SourceMap requests a source map in the compiled snapshot.
+	SourceMap bool
+
+	// PolicyFiles lists YAML policy files evaluated against the compiled data.
+	PolicyFiles []string
+
+	// TypeCoercion is the type coercion policy: strict, lenient, or off.
+	// Empty means off.
+	TypeCoercion string
+
+	// MaxSnapshotBytes fails compilation when the resolved data would exceed
+	// this many bytes as compact JSON. Zero means no limit.
+	MaxSnapshotBytes int64
+
+	// VarFiles lists YAML or JSON files whose values are overlaid on the
+	// compiled snapshot, in order.
+	VarFiles []string
+
+	// Sets holds override values in key.path=value form, applied after
+	// VarFiles.
+	Sets []string
+
+	// ProviderConfigJSON is a JSON object of source declaration config
+	// overrides keyed by provider alias, such as read from stdin with
+	// --stdin-config. Empty means none.
+	ProviderConfigJSON []byte
+
+	// ProviderConfigs holds source declaration config overrides in
+	// alias=key=value form, applied after ProviderConfigJSON.
+	ProviderConfigs []string
+
+	// Profiles selects the profiles declared in the input files to merge
+	// into the data, in order.
+	Profiles []string
+
+	// ProviderPermissions, when set, restricts the source declarations the
+	// input files may make.
+	ProviderPermissions *compiler.ProviderPermissions
+
+	// Limits bounds input file sizes and the depth, keys, and reference
+	// fan-out of provider values and the compiled data.
+	Limits compiler.Limits
+
+	// MaxProviderMessageBytes is the largest provider response accepted;
+	// zero means compiler.DefaultMaxProviderMessageBytes.
+	MaxProviderMessageBytes int
+
+	// ProjectRoot is the directory relative paths are anchored to, recorded
+	// in the snapshot metadata. If empty, the current working directory.
+	ProjectRoot string
+
+	// SourceDateEpoch is the value of the SOURCE_DATE_EPOCH environment
+	// variable: seconds since the Unix epoch. When set, the metadata
+	// timestamps are fixed at that time.
+	SourceDateEpoch string
+
+	// Reproducible fixes the metadata timestamps at the Unix epoch when
+	// SourceDateEpoch is empty, so identical builds write identical metadata.
+	Reproducible bool
 }
 
 // NewProviderRegistries creates default provider and provider type registries.
@@ -55,32 +132,13 @@ type BuildParams struct {
 func NewProviderRegistries() (compiler.ProviderRegistry, compiler.ProviderTypeRegistry) {
 	providerRegistry := compiler.NewProviderRegistry()
 
-	// Check for lockfile in current directory
-	lockfilePath := ".nomos/providers.lock.json"
-	manifestPath := ".nomos/providers.yaml"
-
-	// Check if lockfile exists
-	if _, err := os.Stat(lockfilePath); err != nil {
+	resolver, err := NewProviderResolver()
+	if err != nil || resolver == nil {
 		// BREAKING CHANGE: No fallback to in-process providers
 		// Return empty registry - compiler will fail with clear error
 		return providerRegistry, compiler.NewProviderTypeRegistry()
 	}
 
-	// Lockfile exists - use external providers via providerproc
-	baseDirFunc := func() string {
-		// Get absolute path to .nomos/providers directory
-		wd, _ := os.Getwd()
-		return filepath.Join(wd, ".nomos", "providers")
-	}
-
-	// Create lockfile-based resolver
-	resolver, err := compiler.NewLockfileProviderResolver(lockfilePath, manifestPath, baseDirFunc)
-	if err != nil {
-		// BREAKING CHANGE: No fallback to in-process providers
-		// Return empty registry - compiler will fail with clear error about malformed lockfile
-		return providerRegistry, compiler.NewProviderTypeRegistry()
-	}
-
 	// Create provider type registry with lockfile resolver
 	// The registry will internally create and manage the provider process manager
 	// Provider subprocesses will be cleaned up by the OS when the CLI process exits
@@ -89,11 +147,43 @@ func NewProviderRegistries() (compiler.ProviderRegistry, compiler.ProviderTypeRe
 	return providerRegistry, providerTypeRegistry
 }
 
+// NewProviderResolver creates a resolver that locates the external
+// providers installed for the lockfile (.nomos/providers.lock.json). It
+// returns nil without error when there is no lockfile, and an error when
+// the lockfile is malformed.
+func NewProviderResolver() (*compiler.LockfileProviderResolver, error) {
+	lockfilePath := nomosdir.LockfilePath
+	manifestPath := nomosdir.ManifestPath
+
+	// Check if lockfile exists
+	if _, err := os.Stat(lockfilePath); err != nil {
+		return nil, nil
+	}
+
+	baseDirFunc := func() string {
+		// Get absolute path to the installed providers directory
+		dir, _ := filepath.Abs(nomosdir.ProvidersDir())
+		return dir
+	}
+
+	// Create lockfile-based resolver
+	return compiler.NewLockfileProviderResolver(lockfilePath, manifestPath, baseDirFunc)
+}
+
 // BuildOptions constructs compiler.Options from BuildParams.
 // This function handles:
 // - Variable parsing and validation
 // - Timeout duration parsing
+// - Partial failure mode and fetch retry mapping
 // - Provider registry wiring
+// - Warning suppression codes
+// - Policy file loading
+// - Type coercion policy parsing
+// - Snapshot size limit validation
+// - Override parsing from var files and --set values
+// - Provider config override parsing from JSON and alias=key=value values
+// - Profile selection
+// - Project root resolution
 // - All field mapping from CLI flags to compiler.Options
 func BuildOptions(params BuildParams) (compiler.Options, error) {
 	opts := compiler.Options{
@@ -130,5 +220,174 @@ func BuildOptions(params BuildParams) (compiler.Options, error) {
 	// Set max concurrent providers
 	opts.Timeouts.MaxConcurrentProviders = params.MaxConcurrentProviders
 
+	partialFailure, err := compiler.ParsePartialFailureMode(params.PartialFailure)
+	if err != nil {
+		return compiler.Options{}, err
+	}
+	opts.PartialFailureMode = partialFailure
+	if params.FetchRetries < 0 {
+		return compiler.Options{}, fmt.Errorf("fetch-retries must be non-negative (got %d)", params.FetchRetries)
+	}
+	opts.FetchRetry.MaxAttempts = params.FetchRetries + 1
+
+	// Map suppressed warning codes
+	for _, code := range params.SuppressWarnings {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		opts.SuppressWarnings = append(opts.SuppressWarnings, compiler.WarningCode(code))
+	}
+
+	opts.SourceMap = params.SourceMap
+
+	coercion, err := compiler.ParseTypeCoercion(params.TypeCoercion)
+	if err != nil {
+		return compiler.Options{}, err
+	}
+	opts.TypeCoercion = coercion
+
+	if params.MaxSnapshotBytes < 0 {
+		return compiler.Options{}, fmt.Errorf("max-snapshot-bytes must be non-negative (got %d)", params.MaxSnapshotBytes)
+	}
+	opts.MaxSnapshotBytes = params.MaxSnapshotBytes
+
+	overrides, err := parseOverrides(params.VarFiles, params.Sets)
+	if err != nil {
+		return compiler.Options{}, err
+	}
+	opts.Overrides = overrides
+
+	opts.ProviderConfigOverrides, err = parseProviderConfigOverrides(params.ProviderConfigJSON, params.ProviderConfigs)
+	if err != nil {
+		return compiler.Options{}, err
+	}
+	opts.ProviderPermissions = params.ProviderPermissions
+
+	if err := params.Limits.Validate(); err != nil {
+		return compiler.Options{}, err
+	}
+	opts.Limits = params.Limits
+	opts.MaxProviderMessageBytes = params.MaxProviderMessageBytes
+
+	// Map selected profiles
+	for _, name := range params.Profiles {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.Profiles = append(opts.Profiles, name)
+		}
+	}
+
+	projectRoot := params.ProjectRoot
+	if projectRoot == "" {
+		projectRoot, err = os.Getwd()
+		if err != nil {
+			return compiler.Options{}, fmt.Errorf("failed to resolve project root: %w", err)
+		}
+	}
+	opts.ProjectRoot, err = filepath.Abs(projectRoot)
+	if err != nil {
+		return compiler.Options{}, fmt.Errorf("failed to resolve project root: %w", err)
+	}
+
+	clock, err := fixedClock(params.SourceDateEpoch, params.Reproducible)
+	if err != nil {
+		return compiler.Options{}, err
+	}
+	opts.Clock = clock
+
+	// Load policies
+	for _, path := range params.PolicyFiles {
+		policies, err := compiler.LoadPolicies(path)
+		if err != nil {
+			return compiler.Options{}, err
+		}
+		opts.Policies = append(opts.Policies, policies...)
+	}
+
 	return opts, nil
 }
+
+// parseOverrides loads var files in order and then applies --set values,
+// later values winning with the compiler's deep-merge semantics. It returns
+// nil when there is nothing to override.
+func parseOverrides(varFiles, sets []string) (map[string]any, error) {
+	var overrides map[string]any
+	for _, path := range varFiles {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: Var file path is provided by the user
+		if err != nil {
+			return nil, fmt.Errorf("failed to read var file: %w", err)
+		}
+		var values map[string]any
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse var file %q: %w", path, err)
+		}
+		overrides = compiler.DeepMerge(overrides, values)
+	}
+
+	for _, s := range sets {
+		keyPath, value, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid set format %q (expected key.path=value)", s)
+		}
+		keys := strings.Split(keyPath, ".")
+		if slices.Contains(keys, "") {
+			return nil, fmt.Errorf("invalid set format %q (key path segments cannot be empty)", s)
+		}
+
+		// Build the nested map for the key path from the innermost key out
+		var nested any = value
+		for i := len(keys) - 1; i >= 0; i-- {
+			nested = map[string]any{keys[i]: nested}
+		}
+		overrides = compiler.DeepMerge(overrides, nested.(map[string]any))
+	}
+	return overrides, nil
+}
+
+// parseProviderConfigOverrides decodes the JSON overrides document, if
+// any, and then applies each alias=key=value, later values replacing
+// earlier ones. It returns nil when there is nothing to override.
+func parseProviderConfigOverrides(document []byte, values []string) (map[string]map[string]any, error) {
+	var overrides map[string]map[string]any
+	if len(bytes.TrimSpace(document)) > 0 {
+		if err := json.Unmarshal(document, &overrides); err != nil {
+			return nil, fmt.Errorf("invalid provider config JSON (expected an object of objects keyed by alias): %w", err)
+		}
+	}
+
+	for _, v := range values {
+		alias, rest, _ := strings.Cut(v, "=")
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok || alias == "" || key == "" {
+			return nil, fmt.Errorf("invalid provider-config format %q (expected alias=key=value)", v)
+		}
+		if overrides == nil {
+			overrides = make(map[string]map[string]any)
+		}
+		if overrides[alias] == nil {
+			overrides[alias] = make(map[string]any)
+		}
+		overrides[alias][key] = value
+	}
+	return overrides, nil
+}
+
+// fixedClock returns the clock for the metadata timestamps: the time of
+// sourceDateEpoch if set, otherwise the Unix epoch if reproducible, and nil
+// (the current time) if neither.
+func fixedClock(sourceDateEpoch string, reproducible bool) (func() time.Time, error) {
+	var fixed time.Time
+	switch {
+	case sourceDateEpoch != "":
+		seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a non-negative number of seconds", sourceDateEpoch)
+		}
+		fixed = time.Unix(seconds, 0).UTC()
+	case reproducible:
+		fixed = time.Unix(0, 0).UTC()
+	default:
+		return nil, nil
+	}
+	return func() time.Time { return fixed }, nil
+}
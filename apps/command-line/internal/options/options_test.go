@@ -1,6 +1,9 @@
 package options
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -232,3 +235,282 @@ func Test_BuildOptions_RegistryInjection(t *testing.T) {
 		t.Error("expected ProviderTypeRegistry to be set")
 	}
 }
+
+// Test_BuildOptions_SuppressWarnings verifies warning codes are normalized and mapped
+func Test_BuildOptions_SuppressWarnings(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{
+		Path:             "/path/to/file.csl",
+		SuppressWarnings: []string{"w001", " W002 ", ""},
+	})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+
+	want := []compiler.WarningCode{"W001", "W002"}
+	if len(opts.SuppressWarnings) != len(want) {
+		t.Fatalf("opts.SuppressWarnings = %v, want %v", opts.SuppressWarnings, want)
+	}
+	for i := range want {
+		if opts.SuppressWarnings[i] != want[i] {
+			t.Errorf("opts.SuppressWarnings[%d] = %q, want %q", i, opts.SuppressWarnings[i], want[i])
+		}
+	}
+}
+
+// Test_BuildOptions_PolicyFiles verifies policy files are loaded into options
+func Test_BuildOptions_PolicyFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "security.yaml")
+	second := filepath.Join(dir, "style.yaml")
+	if err := os.WriteFile(first, []byte("policies:\n  - name: ssl\n    expr: data.db.ssl == true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("policies:\n  - name: env\n    expr: has(data.env)\n    severity: warning\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := BuildOptions(BuildParams{
+		Path:        "/path/to/file.csl",
+		PolicyFiles: []string{first, second},
+	})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if len(opts.Policies) != 2 || opts.Policies[0].Name != "ssl" || opts.Policies[1].Severity != compiler.PolicySeverityWarning {
+		t.Errorf("opts.Policies = %+v", opts.Policies)
+	}
+
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", PolicyFiles: []string{filepath.Join(dir, "missing.yaml")}}); err == nil {
+		t.Error("BuildOptions() expected error for missing policy file")
+	}
+}
+
+// Test_BuildOptions_TypeCoercion verifies the coercion policy is parsed
+func Test_BuildOptions_TypeCoercion(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", TypeCoercion: "Strict"})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if opts.TypeCoercion != compiler.TypeCoercionStrict {
+		t.Errorf("opts.TypeCoercion = %q, want %q", opts.TypeCoercion, compiler.TypeCoercionStrict)
+	}
+
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", TypeCoercion: "loose"}); err == nil {
+		t.Error("BuildOptions() expected error for invalid type coercion")
+	}
+}
+
+// Test_BuildOptions_PartialFailure verifies the partial failure mode and
+// fetch retries are mapped and validated
+func Test_BuildOptions_PartialFailure(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", PartialFailure: "collect-all", FetchRetries: 2})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if opts.PartialFailureMode != compiler.PartialFailureCollectAll {
+		t.Errorf("opts.PartialFailureMode = %q, want %q", opts.PartialFailureMode, compiler.PartialFailureCollectAll)
+	}
+	if opts.FetchRetry.MaxAttempts != 3 {
+		t.Errorf("opts.FetchRetry.MaxAttempts = %d, want 3", opts.FetchRetry.MaxAttempts)
+	}
+
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", PartialFailure: "ignore"}); err == nil {
+		t.Error("BuildOptions() expected error for invalid partial failure mode")
+	}
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", FetchRetries: -1}); err == nil {
+		t.Error("BuildOptions() expected error for negative fetch retries")
+	}
+}
+
+// Test_BuildOptions_MaxSnapshotBytes verifies the snapshot size limit is
+// passed through and rejected when negative
+func Test_BuildOptions_MaxSnapshotBytes(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", MaxSnapshotBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if opts.MaxSnapshotBytes != 1<<20 {
+		t.Errorf("opts.MaxSnapshotBytes = %d, want %d", opts.MaxSnapshotBytes, 1<<20)
+	}
+
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", MaxSnapshotBytes: -1}); err == nil {
+		t.Error("BuildOptions() expected error for negative max snapshot bytes")
+	}
+}
+
+// Test_BuildOptions_Overrides verifies var files and --set values are merged
+// into overrides, with --set values applied last
+func Test_BuildOptions_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "base.yaml")
+	second := filepath.Join(dir, "prod.json")
+	if err := os.WriteFile(first, []byte("app:\n  replicas: 2\n  region: us-east-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte(`{"app": {"region": "eu-west-1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := BuildOptions(BuildParams{
+		Path:     "/path/to/file.csl",
+		VarFiles: []string{first, second},
+		Sets:     []string{"app.replicas=5", "app.image.tag=v1=rc"},
+	})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"app": map[string]any{
+			"replicas": "5",
+			"region":   "eu-west-1",
+			"image":    map[string]any{"tag": "v1=rc"},
+		},
+	}
+	if !reflect.DeepEqual(opts.Overrides, want) {
+		t.Errorf("opts.Overrides = %#v, want %#v", opts.Overrides, want)
+	}
+
+	opts, err = BuildOptions(BuildParams{Path: "/path/to/file.csl"})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if opts.Overrides != nil {
+		t.Errorf("opts.Overrides = %v, want nil", opts.Overrides)
+	}
+
+	for _, set := range []string{"novalue", "=x", "app..port=1", "app.=1"} {
+		if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", Sets: []string{set}}); err == nil {
+			t.Errorf("BuildOptions() expected error for set %q", set)
+		}
+	}
+	if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", VarFiles: []string{filepath.Join(dir, "missing.yaml")}}); err == nil {
+		t.Error("BuildOptions() expected error for missing var file")
+	}
+}
+
+// Test_BuildOptions_Profiles verifies that profiles are mapped in order,
+// skipping blank names.
+func Test_BuildOptions_Profiles(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{
+		Path:     "/path/to/file.csl",
+		Profiles: []string{"prod", " ", " eu "},
+	})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if want := []string{"prod", "eu"}; !reflect.DeepEqual(opts.Profiles, want) {
+		t.Errorf("opts.Profiles = %v, want %v", opts.Profiles, want)
+	}
+}
+
+// Test_BuildOptions_ProviderConfigOverrides verifies that the JSON document
+// is decoded and alias=key=value overrides are applied after it.
+func Test_BuildOptions_ProviderConfigOverrides(t *testing.T) {
+	opts, err := BuildOptions(BuildParams{
+		Path:               "/path/to/file.csl",
+		ProviderConfigJSON: []byte(`{"files": {"directory": "./base", "recursive": true}}`),
+		ProviderConfigs:    []string{"files=directory=./ci", "vault=address=http://v:8200/?a=b"},
+	})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	want := map[string]map[string]any{
+		"files": {"directory": "./ci", "recursive": true},
+		"vault": {"address": "http://v:8200/?a=b"},
+	}
+	if !reflect.DeepEqual(opts.ProviderConfigOverrides, want) {
+		t.Errorf("opts.ProviderConfigOverrides = %#v, want %#v", opts.ProviderConfigOverrides, want)
+	}
+
+	opts, err = BuildOptions(BuildParams{Path: "/path/to/file.csl", ProviderConfigJSON: []byte("\n")})
+	if err != nil {
+		t.Fatalf("BuildOptions() unexpected error: %v", err)
+	}
+	if opts.ProviderConfigOverrides != nil {
+		t.Errorf("opts.ProviderConfigOverrides = %v, want nil", opts.ProviderConfigOverrides)
+	}
+
+	for _, v := range []string{"files", "files=directory", "=directory=x", "files==x"} {
+		if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", ProviderConfigs: []string{v}}); err == nil {
+			t.Errorf("BuildOptions() expected error for provider config %q", v)
+		}
+	}
+	for _, doc := range []string{`[1]`, `{"files": "x"}`, `{`} {
+		if _, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", ProviderConfigJSON: []byte(doc)}); err == nil {
+			t.Errorf("BuildOptions() expected error for provider config JSON %s", doc)
+		}
+	}
+}
+
+// Test_BuildOptions_ProjectRoot verifies the project root defaults to the
+// working directory and is made absolute
+func Test_BuildOptions_ProjectRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		root string
+		want string
+	}{
+		{name: "default", root: "", want: wd},
+		{name: "absolute", root: "/srv/project", want: "/srv/project"},
+		{name: "relative", root: "project", want: filepath.Join(wd, "project")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", ProjectRoot: tt.root})
+			if err != nil {
+				t.Fatalf("BuildOptions() unexpected error: %v", err)
+			}
+			if opts.ProjectRoot != tt.want {
+				t.Errorf("opts.ProjectRoot = %q, want %q", opts.ProjectRoot, tt.want)
+			}
+		})
+	}
+}
+
+// Test_BuildOptions_Clock verifies SOURCE_DATE_EPOCH and --reproducible fix
+// the metadata timestamps
+func Test_BuildOptions_Clock(t *testing.T) {
+	tests := []struct {
+		name         string
+		epoch        string
+		reproducible bool
+		want         time.Time
+		wantNil      bool
+		wantErr      bool
+	}{
+		{name: "default", wantNil: true},
+		{name: "reproducible", reproducible: true, want: time.Unix(0, 0).UTC()},
+		{name: "source date epoch", epoch: "1700000000", want: time.Unix(1700000000, 0).UTC()},
+		{name: "epoch wins", epoch: "1700000000", reproducible: true, want: time.Unix(1700000000, 0).UTC()},
+		{name: "invalid epoch", epoch: "yesterday", wantErr: true},
+		{name: "negative epoch", epoch: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := BuildOptions(BuildParams{Path: "/path/to/file.csl", SourceDateEpoch: tt.epoch, Reproducible: tt.reproducible})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("BuildOptions() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildOptions() unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if opts.Clock != nil {
+					t.Error("opts.Clock should be nil")
+				}
+				return
+			}
+			if got := opts.Clock(); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("opts.Clock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ownership
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uncommittedHash is the hash git blame reports for lines not yet committed.
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// GitHistory returns a History that runs git blame on each file and
+// reports the newest change among its lines, so uncommitted edits count as
+// changes made now. Untracked files, files outside a git work tree, and
+// all files when git is not installed have no history.
+func GitHistory(ctx context.Context) History {
+	return func(path string) (*Change, bool) {
+		//nolint:gosec // G204: git is run with a file path from the snapshot's provenance
+		cmd := exec.CommandContext(ctx, "git", "blame", "--porcelain", "--", filepath.Base(path))
+		cmd.Dir = filepath.Dir(path)
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, false
+		}
+		return parseBlame(bytes.NewReader(out))
+	}
+}
+
+// parseBlame returns the newest change in git blame --porcelain output, by
+// committer time.
+func parseBlame(r io.Reader) (*Change, bool) {
+	type commit struct {
+		author string
+		time   int64
+	}
+	commits := make(map[string]*commit)
+	var current *commit
+	var newest string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			if current != nil {
+				current.author = value
+			}
+		case "committer-time":
+			if current != nil {
+				current.time, _ = strconv.ParseInt(value, 10, 64)
+			}
+		default:
+			// Other lines start with the hash of the line's commit
+			if !isHash(key) || value == "" {
+				continue
+			}
+			if commits[key] == nil {
+				commits[key] = &commit{}
+			}
+			current = commits[key]
+			if newest == "" {
+				newest = key
+			}
+		}
+	}
+	if scanner.Err() != nil || newest == "" {
+		return nil, false
+	}
+
+	for hash, c := range commits {
+		if c.time > commits[newest].time || (c.time == commits[newest].time && hash < newest) {
+			newest = hash
+		}
+	}
+	change := &Change{
+		Commit: newest,
+		Author: commits[newest].author,
+		Time:   time.Unix(commits[newest].time, 0).UTC(),
+	}
+	if newest == uncommittedHash {
+		change.Commit = ""
+	}
+	return change, true
+}
+
+// isHash reports whether s is a full hexadecimal commit hash.
+func isHash(s string) bool {
+	if len(s) != len(uncommittedHash) {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
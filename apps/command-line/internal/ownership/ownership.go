@@ -0,0 +1,168 @@
+// Package ownership aggregates the provenance of a compiled snapshot into a
+// report of which source files and providers own which top-level keys.
+package ownership
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// Report lists the owners of a snapshot's top-level keys.
+type Report struct {
+	// TotalKeys is the number of top-level keys with provenance.
+	TotalKeys int `json:"total_keys"`
+
+	// Sources groups keys by the source that contributed them: a .csl file,
+	// compiler.OverrideSource, or compiler.ProviderDefaultSource. Sorted by
+	// source.
+	Sources []Source `json:"sources"`
+
+	// Providers groups keys by the provider alias that resolved them.
+	// Sorted by alias.
+	Providers []Provider `json:"providers"`
+}
+
+// Source is the set of keys one source contributed.
+type Source struct {
+	Source   string   `json:"source"`
+	KeyCount int      `json:"key_count"`
+	Keys     []string `json:"keys"`
+
+	// LastChange is the most recent change to the source file, nil when the
+	// source is not a file under version control or history was skipped.
+	LastChange *Change `json:"last_change"`
+}
+
+// Provider is the set of keys one provider alias resolved.
+type Provider struct {
+	Alias    string   `json:"alias"`
+	KeyCount int      `json:"key_count"`
+	Keys     []string `json:"keys"`
+}
+
+// Change describes the most recent change to a file.
+type Change struct {
+	// Commit is the commit hash, empty for uncommitted changes.
+	Commit string    `json:"commit"`
+	Author string    `json:"author"`
+	Time   time.Time `json:"time"`
+}
+
+// History returns the most recent change to the file at path, or false if
+// it has none, for example because it is not under version control.
+type History func(path string) (*Change, bool)
+
+// Build aggregates provenance into a report. history, if not nil, fills in
+// the last change of each source file; it is called once per source.
+func Build(provenance map[string]compiler.Provenance, history History) Report {
+	sources := make(map[string][]string)
+	providers := make(map[string][]string)
+	for key, p := range provenance {
+		sources[p.Source] = append(sources[p.Source], key)
+		if p.ProviderAlias != "" {
+			providers[p.ProviderAlias] = append(providers[p.ProviderAlias], key)
+		}
+	}
+
+	report := Report{
+		TotalKeys: len(provenance),
+		Sources:   []Source{},
+		Providers: []Provider{},
+	}
+	for _, name := range sortedKeys(sources) {
+		keys := sources[name]
+		sort.Strings(keys)
+		source := Source{Source: name, KeyCount: len(keys), Keys: keys}
+		if history != nil && isFileSource(name) {
+			if change, ok := history(name); ok {
+				source.LastChange = change
+			}
+		}
+		report.Sources = append(report.Sources, source)
+	}
+	for _, alias := range sortedKeys(providers) {
+		keys := providers[alias]
+		sort.Strings(keys)
+		report.Providers = append(report.Providers, Provider{Alias: alias, KeyCount: len(keys), Keys: keys})
+	}
+	return report
+}
+
+// isFileSource reports whether source names a file rather than one of the
+// compiler's synthetic sources.
+func isFileSource(source string) bool {
+	return source != "" && source != compiler.OverrideSource && source != compiler.ProviderDefaultSource
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoded, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", encoded)
+	return err
+}
+
+// WriteHTML writes the report as a self-contained HTML page.
+func (r Report) WriteHTML(w io.Writer) error {
+	if err := htmlTemplate.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("ownership").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Nomos key ownership</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Key ownership</h1>
+<p>{{.TotalKeys}} top-level keys.</p>
+<h2>By source</h2>
+<table>
+<tr><th>Source</th><th>Keys</th><th>Last change</th><th>Key paths</th></tr>
+{{- range .Sources}}
+<tr><td><code>{{.Source}}</code></td><td>{{.KeyCount}}</td><td>{{with .LastChange}}{{date .Time}} by {{.Author}}{{with .Commit}} (<code>{{.}}</code>){{end}}{{end}}</td><td>{{range $i, $k := .Keys}}{{if $i}}, {{end}}<code>{{$k}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+<h2>By provider</h2>
+{{- if .Providers}}
+<table>
+<tr><th>Alias</th><th>Keys</th><th>Key paths</th></tr>
+{{- range .Providers}}
+<tr><td><code>{{.Alias}}</code></td><td>{{.KeyCount}}</td><td>{{range $i, $k := .Keys}}{{if $i}}, {{end}}<code>{{$k}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No keys were attributed to a provider.</p>
+{{- end}}
+</body>
+</html>
+`))
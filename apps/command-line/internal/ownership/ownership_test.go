@@ -0,0 +1,110 @@
+package ownership
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+func TestBuild(t *testing.T) {
+	provenance := map[string]compiler.Provenance{
+		"network":  {Source: "/src/network.csl"},
+		"dns":      {Source: "/src/network.csl"},
+		"database": {Source: "/src/data.csl"},
+		"replicas": {Source: compiler.OverrideSource},
+		"logging":  {Source: compiler.ProviderDefaultSource, ProviderAlias: "defaults"},
+	}
+	changed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var looked []string
+	history := func(path string) (*Change, bool) {
+		looked = append(looked, path)
+		if path == "/src/network.csl" {
+			return &Change{Commit: "abc", Author: "Network Team", Time: changed}, true
+		}
+		return nil, false
+	}
+
+	report := Build(provenance, history)
+
+	want := Report{
+		TotalKeys: 5,
+		Sources: []Source{
+			{Source: "/src/data.csl", KeyCount: 1, Keys: []string{"database"}},
+			{Source: "/src/network.csl", KeyCount: 2, Keys: []string{"dns", "network"}, LastChange: &Change{Commit: "abc", Author: "Network Team", Time: changed}},
+			{Source: compiler.OverrideSource, KeyCount: 1, Keys: []string{"replicas"}},
+			{Source: compiler.ProviderDefaultSource, KeyCount: 1, Keys: []string{"logging"}},
+		},
+		Providers: []Provider{{Alias: "defaults", KeyCount: 1, Keys: []string{"logging"}}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Build() = %+v, want %+v", report, want)
+	}
+	if want := []string{"/src/data.csl", "/src/network.csl"}; !reflect.DeepEqual(looked, want) {
+		t.Errorf("history looked up %v, want only files %v", looked, want)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("JSON round trip = %+v, want %+v", decoded, want)
+	}
+
+	buf.Reset()
+	if err := report.WriteHTML(&buf); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	for _, s := range []string{"<code>/src/network.csl</code>", "2026-03-01T12:00:00Z by Network Team", "<code>defaults</code>"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("HTML report lacks %q:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	const older = "1111111111111111111111111111111111111111"
+	const newer = "2222222222222222222222222222222222222222"
+	porcelain := strings.Join([]string{
+		older + " 1 1 2",
+		"author Alice",
+		"committer-time 1700000000",
+		"filename app.csl",
+		"\tapp:",
+		older + " 2 2",
+		"\t  name: 'demo'",
+		newer + " 3 3 1",
+		"author Bob",
+		"committer-time 1700100000",
+		"previous " + older + " app.csl",
+		"filename app.csl",
+		"\t  replicas: '3'",
+	}, "\n")
+
+	change, ok := parseBlame(strings.NewReader(porcelain))
+	if !ok {
+		t.Fatal("parseBlame() found no change")
+	}
+	want := &Change{Commit: newer, Author: "Bob", Time: time.Unix(1700100000, 0).UTC()}
+	if !reflect.DeepEqual(change, want) {
+		t.Errorf("parseBlame() = %+v, want %+v", change, want)
+	}
+
+	uncommitted := uncommittedHash + " 1 1 1\nauthor Not Committed Yet\ncommitter-time 1800000000\n\tapp:\n" + porcelain
+	if change, _ := parseBlame(strings.NewReader(uncommitted)); change.Commit != "" || change.Author != "Not Committed Yet" {
+		t.Errorf("parseBlame() with uncommitted lines = %+v, want an uncommitted change", change)
+	}
+
+	if _, ok := parseBlame(strings.NewReader("")); ok {
+		t.Error("parseBlame() of empty output found a change")
+	}
+}
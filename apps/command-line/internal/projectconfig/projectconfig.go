@@ -0,0 +1,242 @@
+// Package projectconfig loads project-level Nomos settings.
+//
+// Settings live in .nomos/config.yaml alongside the provider lockfile and
+// manifest, and apply to every command run from the project directory:
+//
+//	warnings:
+//	  suppress: [W001]
+//	policies:
+//	  - policies/security.yaml
+//	type_coercion: strict
+//	nomos_dir: /var/cache/nomos
+//	key_order:
+//	  yaml: priority:apiVersion,kind,metadata
+//	serializers:
+//	  toml:
+//	    command: [nomos-toml, --indent=2]
+//	  acme:
+//	    plugin: ./plugins/acme.so
+//	formats:
+//	  yaml:
+//	    extensions: [.yml, .yaml]
+//	  custom:toml:
+//	    extensions: [.toml]
+//	    media_type: application/toml
+//	credential_helper: gh auth git-credential
+//	history:
+//	  enabled: true
+//	  max_entries: 50
+//	  max_age: 720h
+//	provider_permissions:
+//	  types:
+//	    file:
+//	      config:
+//	        directory:
+//	          allow: [./data/**]
+//	trusted_providers:
+//	  - source: autonomous-bits/*
+//	  - source: acme/nomos-provider-vault
+//	    signer: acme/release-workflows/.github/workflows/release.yml
+//	max_provider_message_bytes: 16777216
+//
+// The file is optional; a missing file yields the zero Config.
+package projectconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the location of the project configuration file, relative
+// to the working directory.
+const DefaultPath = ".nomos/config.yaml"
+
+// Config holds project-level settings.
+type Config struct {
+	// Warnings configures compiler warning handling.
+	Warnings WarningsConfig `yaml:"warnings"`
+
+	// Serializers declares custom output formats selected with
+	// --format custom:<name>, keyed by name.
+	Serializers map[string]SerializerConfig `yaml:"serializers"`
+
+	// Policies lists policy files evaluated on every build, relative to the
+	// working directory.
+	Policies []string `yaml:"policies"`
+
+	// TypeCoercion is the default type coercion policy (strict, lenient, or
+	// off); the --type-coercion flag overrides it.
+	TypeCoercion string `yaml:"type_coercion"`
+
+	// NomosDir moves installed provider binaries out of the project's .nomos
+	// directory, relative to the working directory; --nomos-dir and
+	// NOMOS_DIR override it. The lockfile stays in the project.
+	NomosDir string `yaml:"nomos_dir"`
+
+	// KeyOrder maps an output format (json, yaml, or tfvars) to its default
+	// map key order, in --key-order syntax; the flag overrides it.
+	KeyOrder map[string]string `yaml:"key_order"`
+
+	// Formats overrides the file extensions and media types of output
+	// formats, keyed by --format value (json, yaml, tfvars, or
+	// custom:<name>).
+	Formats map[string]FormatConfig `yaml:"formats"`
+
+	// CredentialHelper is a git credential helper command asked for GitHub
+	// tokens when GITHUB_TOKEN and GH_TOKEN are unset, before the GitHub
+	// CLI's configuration and .netrc.
+	CredentialHelper string `yaml:"credential_helper"`
+
+	// History configures the record of past builds kept in .nomos/history.
+	History HistoryConfig `yaml:"history"`
+
+	// ProviderPermissions, when set, restricts the provider types, aliases,
+	// and config values source declarations may use.
+	ProviderPermissions *compiler.ProviderPermissions `yaml:"provider_permissions"`
+
+	// TrustedProviders, when set, lists the provider owner/repo patterns
+	// .csl files may declare, optionally with the signer whose attestation
+	// installed binaries must carry. Builds fail on other providers.
+	TrustedProviders []providercmd.TrustedProvider `yaml:"trusted_providers"`
+
+	// Limits bounds input file sizes and the nesting depth, keys, and
+	// reference fan-out of provider values and the compiled data.
+	Limits compiler.Limits `yaml:"limits"`
+
+	// MaxProviderMessageBytes raises the largest response accepted from a
+	// provider above the gRPC default of 4 MiB (0 = default).
+	MaxProviderMessageBytes int `yaml:"max_provider_message_bytes"`
+}
+
+// HistoryConfig configures build history.
+type HistoryConfig struct {
+	// Enabled records every successful build; without it, only builds run
+	// with --history are recorded.
+	Enabled bool `yaml:"enabled"`
+
+	// MaxEntries keeps at most this many builds (0 = no limit).
+	MaxEntries int `yaml:"max_entries"`
+
+	// MaxAge drops builds older than this Go duration, e.g. 720h (empty =
+	// no limit).
+	MaxAge string `yaml:"max_age"`
+}
+
+// MaxAgeDuration returns MaxAge parsed, or 0 when it is empty. Load has
+// already rejected invalid values.
+func (h HistoryConfig) MaxAgeDuration() time.Duration {
+	d, _ := time.ParseDuration(h.MaxAge)
+	return d
+}
+
+// FormatConfig overrides the file type of an output format. Unset fields
+// keep the built-in value.
+type FormatConfig struct {
+	// Extensions lists file extensions with their leading dot; the first is
+	// appended to output paths without one.
+	Extensions []string `yaml:"extensions"`
+
+	// MediaType is the MIME type of the format's output.
+	MediaType string `yaml:"media_type"`
+}
+
+// SerializerConfig declares a custom output serializer. Exactly one of
+// Command or Plugin must be set.
+type SerializerConfig struct {
+	// Command is the program and arguments of a subprocess serializer.
+	Command []string `yaml:"command"`
+
+	// Plugin is the path to a Go plugin exporting a Serialize function.
+	Plugin string `yaml:"plugin"`
+}
+
+// WarningsConfig configures compiler warning handling.
+type WarningsConfig struct {
+	// Suppress lists warning codes (e.g., "W001") silenced for every build.
+	Suppress []string `yaml:"suppress"`
+}
+
+// Load reads the configuration file at path. A missing file is not an error.
+func Load(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is the project config location
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+
+	for i, code := range cfg.Warnings.Suppress {
+		cfg.Warnings.Suppress[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+
+	if _, err := compiler.ParseTypeCoercion(cfg.TypeCoercion); err != nil {
+		return cfg, fmt.Errorf("invalid project config %s: %w", path, err)
+	}
+
+	for format, spec := range cfg.KeyOrder {
+		switch serialize.OutputFormat(format) {
+		case serialize.FormatJSON, serialize.FormatYAML, serialize.FormatTfvars:
+		default:
+			return cfg, fmt.Errorf("invalid project config %s: key_order format %q (supported: json, yaml, tfvars)", path, format)
+		}
+		if _, err := serialize.ParseKeyOrder(spec); err != nil {
+			return cfg, fmt.Errorf("invalid project config %s: key_order for %s: %w", path, format, err)
+		}
+	}
+	fileTypes := serialize.NewFileTypes()
+	for format, fc := range cfg.Formats {
+		ft := serialize.FileType{Extensions: fc.Extensions, MediaType: fc.MediaType}
+		if err := fileTypes.Set(serialize.OutputFormat(strings.ToLower(format)), ft); err != nil {
+			return cfg, fmt.Errorf("invalid project config %s: formats: %w", path, err)
+		}
+	}
+	for name, s := range cfg.Serializers {
+		if (len(s.Command) == 0) == (s.Plugin == "") {
+			return cfg, fmt.Errorf("invalid project config %s: serializer %q must set exactly one of command or plugin", path, name)
+		}
+	}
+
+	if cfg.History.MaxEntries < 0 {
+		return cfg, fmt.Errorf("invalid project config %s: history max_entries must not be negative", path)
+	}
+	if cfg.History.MaxAge != "" {
+		if d, err := time.ParseDuration(cfg.History.MaxAge); err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid project config %s: history max_age %q is not a positive duration such as 720h", path, cfg.History.MaxAge)
+		}
+	}
+
+	if cfg.ProviderPermissions != nil {
+		if err := cfg.ProviderPermissions.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid project config %s: %w", path, err)
+		}
+	}
+
+	if err := providercmd.ValidateTrustedProviders(cfg.TrustedProviders); err != nil {
+		return cfg, fmt.Errorf("invalid project config %s: %w", path, err)
+	}
+
+	if err := cfg.Limits.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid project config %s: %w", path, err)
+	}
+
+	if cfg.MaxProviderMessageBytes < 0 {
+		return cfg, fmt.Errorf("invalid project config %s: max_provider_message_bytes must not be negative (got %d)", path, cfg.MaxProviderMessageBytes)
+	}
+
+	return cfg, nil
+}
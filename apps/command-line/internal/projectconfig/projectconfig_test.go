@@ -0,0 +1,331 @@
+package projectconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/providercmd"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("missing file yields zero config", func(t *testing.T) {
+		cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Warnings.Suppress) != 0 {
+			t.Errorf("expected no suppressions, got %v", cfg.Warnings.Suppress)
+		}
+	})
+
+	t.Run("reads and normalizes warning codes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("warnings:\n  suppress: [w001, ' W002 ']\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"W001", "W002"}
+		if !reflect.DeepEqual(cfg.Warnings.Suppress, want) {
+			t.Errorf("Suppress = %v, want %v", cfg.Warnings.Suppress, want)
+		}
+	})
+
+	t.Run("reads serializers", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "serializers:\n  toml:\n    command: [nomos-toml, --indent=2]\n  acme:\n    plugin: ./acme.so\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]SerializerConfig{
+			"toml": {Command: []string{"nomos-toml", "--indent=2"}},
+			"acme": {Plugin: "./acme.so"},
+		}
+		if !reflect.DeepEqual(cfg.Serializers, want) {
+			t.Errorf("Serializers = %v, want %v", cfg.Serializers, want)
+		}
+	})
+
+	t.Run("reads nomos directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("nomos_dir: /var/cache/nomos\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.NomosDir != "/var/cache/nomos" {
+			t.Errorf("NomosDir = %q, want %q", cfg.NomosDir, "/var/cache/nomos")
+		}
+	})
+
+	t.Run("reads key order", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "key_order:\n  yaml: priority:apiVersion,kind\n  json: source\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]string{"yaml": "priority:apiVersion,kind", "json": "source"}
+		if !reflect.DeepEqual(cfg.KeyOrder, want) {
+			t.Errorf("KeyOrder = %v, want %v", cfg.KeyOrder, want)
+		}
+	})
+
+	t.Run("invalid key order", func(t *testing.T) {
+		for _, content := range []string{
+			"key_order:\n  json: reverse\n",
+			"key_order:\n  toml: source\n",
+		} {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "key_order") {
+				t.Errorf("Load(%q) error = %v, want key_order error", content, err)
+			}
+		}
+	})
+
+	t.Run("reads formats", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "formats:\n  yaml:\n    extensions: [.yml, .yaml]\n  custom:toml:\n    extensions: [.toml]\n    media_type: application/toml\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]FormatConfig{
+			"yaml":        {Extensions: []string{".yml", ".yaml"}},
+			"custom:toml": {Extensions: []string{".toml"}, MediaType: "application/toml"},
+		}
+		if !reflect.DeepEqual(cfg.Formats, want) {
+			t.Errorf("Formats = %v, want %v", cfg.Formats, want)
+		}
+	})
+
+	t.Run("reads credential helper", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("credential_helper: gh auth git-credential\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.CredentialHelper != "gh auth git-credential" {
+			t.Errorf("CredentialHelper = %q, want %q", cfg.CredentialHelper, "gh auth git-credential")
+		}
+	})
+
+	t.Run("reads history", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("history:\n  enabled: true\n  max_entries: 50\n  max_age: 720h\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.History.Enabled || cfg.History.MaxEntries != 50 || cfg.History.MaxAgeDuration() != 720*time.Hour {
+			t.Errorf("History = %+v, want enabled with 50 entries and 720h", cfg.History)
+		}
+	})
+
+	t.Run("invalid history", func(t *testing.T) {
+		for _, content := range []string{
+			"history:\n  max_entries: -1\n",
+			"history:\n  max_age: 30d\n",
+		} {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "history") {
+				t.Errorf("Load(%q) error = %v, want history error", content, err)
+			}
+		}
+	})
+
+	t.Run("reads provider permissions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "provider_permissions:\n  types:\n    file:\n      aliases: [configs]\n      config:\n        directory:\n          allow: [./data/**]\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := &compiler.ProviderPermissions{Types: map[string]compiler.ProviderTypePermission{
+			"file": {
+				Aliases: []string{"configs"},
+				Config:  map[string]compiler.ConfigValuePermission{"directory": {Allow: []string{"./data/**"}}},
+			},
+		}}
+		if !reflect.DeepEqual(cfg.ProviderPermissions, want) {
+			t.Errorf("ProviderPermissions = %+v, want %+v", cfg.ProviderPermissions, want)
+		}
+	})
+
+	t.Run("invalid provider permissions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "provider_permissions:\n  types:\n    file:\n      config:\n        directory:\n          deny: ['[etc']\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+			t.Errorf("Load() error = %v, want invalid pattern error", err)
+		}
+	})
+
+	t.Run("trusted providers", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "trusted_providers:\n  - source: autonomous-bits/*\n  - source: acme/vault\n    signer: acme/vault/.github/workflows/release.yml\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []providercmd.TrustedProvider{
+			{Source: "autonomous-bits/*"},
+			{Source: "acme/vault", Signer: "acme/vault/.github/workflows/release.yml"},
+		}
+		if !reflect.DeepEqual(cfg.TrustedProviders, want) {
+			t.Errorf("TrustedProviders = %+v, want %+v", cfg.TrustedProviders, want)
+		}
+
+		if err := os.WriteFile(path, []byte("trusted_providers:\n  - source: acme\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "owner/repo") {
+			t.Errorf("Load() error = %v, want owner/repo error", err)
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "limits:\n  max_file_bytes: 1048576\n  max_depth: 32\n  max_reference_fan_out: 100\n  max_keys: 100000\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := compiler.Limits{MaxFileBytes: 1 << 20, MaxDepth: 32, MaxReferenceFanOut: 100, MaxKeys: 100000}
+		if cfg.Limits != want {
+			t.Errorf("Limits = %+v, want %+v", cfg.Limits, want)
+		}
+
+		if err := os.WriteFile(path, []byte("limits:\n  max_depth: -1\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "max_depth must not be negative") {
+			t.Errorf("Load() error = %v, want negative max_depth error", err)
+		}
+	})
+
+	t.Run("max provider message bytes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("max_provider_message_bytes: 16777216\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.MaxProviderMessageBytes != 16<<20 {
+			t.Errorf("MaxProviderMessageBytes = %d, want %d", cfg.MaxProviderMessageBytes, 16<<20)
+		}
+
+		if err := os.WriteFile(path, []byte("max_provider_message_bytes: -1\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "max_provider_message_bytes must not be negative") {
+			t.Errorf("Load() error = %v, want negative max_provider_message_bytes error", err)
+		}
+	})
+
+	t.Run("invalid formats", func(t *testing.T) {
+		for _, content := range []string{
+			"formats:\n  json:\n    extensions: [json]\n",
+			"formats:\n  toml:\n    extensions: [.toml]\n",
+		} {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "formats") {
+				t.Errorf("Load(%q) error = %v, want formats error", content, err)
+			}
+		}
+	})
+
+	t.Run("serializer needs exactly one of command or plugin", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("serializers:\n  toml: {}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Fatal("expected error for serializer without command or plugin")
+		}
+	})
+
+	t.Run("invalid type coercion", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("type_coercion: loose\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Fatal("expected error for invalid type coercion")
+		}
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("warnings: [\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Fatal("expected error for invalid yaml")
+		}
+	})
+}
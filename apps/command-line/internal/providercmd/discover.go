@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/autonomous-bits/nomos/libs/compiler"
 	"github.com/autonomous-bits/nomos/libs/parser"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
@@ -69,8 +70,10 @@ func discoverCslFiles(path string) ([]string, error) {
 
 // DiscoverProviders scans .csl files and extracts provider requirements.
 // It parses each file and extracts SourceDecl nodes, converting them to
-// DiscoveredProvider structs. Duplicate provider aliases are automatically
-// deduplicated (first occurrence wins).
+// DiscoveredProvider structs. Declarations of an alias at a version already
+// discovered are deduplicated (first occurrence wins), so an alias pinned at
+// different versions in different files is returned once per version. Built-in provider types such as
+// 'tfstate' are served by the compiler and are not returned.
 //
 // Paths can be individual .csl files or directories. Directories are expanded
 // to include all .csl files in lexicographic order (non-recursive).
@@ -100,7 +103,7 @@ func DiscoverProviders(paths []string) ([]DiscoveredProvider, error) {
 
 		tree, err := parser.Parse(file, path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			return nil, parseError(path, err)
 		}
 
 		// Extract source declarations from AST
@@ -110,11 +113,17 @@ func DiscoverProviders(paths []string) ([]DiscoveredProvider, error) {
 				continue
 			}
 
+			// Built-in types need no binary
+			if compiler.IsBuiltinProviderType(srcDecl.Type) {
+				continue
+			}
+
 			// Skip duplicates
-			if seen[srcDecl.Alias] {
+			key := srcDecl.Alias + "@" + srcDecl.Version
+			if seen[key] {
 				continue
 			}
-			seen[srcDecl.Alias] = true
+			seen[key] = true
 
 			// Convert config expressions to values
 			config := make(map[string]any)
@@ -126,10 +135,13 @@ func DiscoverProviders(paths []string) ([]DiscoveredProvider, error) {
 			version := srcDecl.Version
 
 			providers = append(providers, DiscoveredProvider{
-				Alias:   srcDecl.Alias,
-				Type:    srcDecl.Type,
-				Version: version,
-				Config:  config,
+				Alias:    srcDecl.Alias,
+				Type:     srcDecl.Type,
+				Version:  version,
+				Digest:   srcDecl.Digest,
+				Checksum: srcDecl.Checksum,
+				Config:   config,
+				File:     path,
 			})
 		}
 	}
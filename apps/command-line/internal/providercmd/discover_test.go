@@ -155,7 +155,7 @@ func TestDiscoverProviders_SkipsDuplicates(t *testing.T) {
 source:
   alias: 'provider'
   type: 'owner/repo2'
-  version: '2.0.0'
+  version: '1.0.0'
 `
 
 	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
@@ -182,6 +182,68 @@ source:
 	}
 }
 
+// TestDiscoverProviders_VersionPerFile tests that an alias pinned at
+// different versions in different files is discovered once per version,
+// recording the file of each.
+func TestDiscoverProviders_VersionPerFile(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '1.0.0'\n",
+		"b.csl": "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '2.0.0'\n",
+		"c.csl": "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '1.0.0'\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	providers, err := DiscoverProviders([]string{tempDir})
+	if err != nil {
+		t.Fatalf("DiscoverProviders failed: %v", err)
+	}
+
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers (one per version), got %d: %+v", len(providers), providers)
+	}
+	for i, want := range []struct{ version, file string }{{"1.0.0", "a.csl"}, {"2.0.0", "b.csl"}} {
+		if providers[i].Version != want.version || filepath.Base(providers[i].File) != want.file {
+			t.Errorf("providers[%d] = %s from %s, want %s from %s",
+				i, providers[i].Version, providers[i].File, want.version, want.file)
+		}
+	}
+	if err := detectVersionConflicts(providers); err != nil {
+		t.Errorf("detectVersionConflicts() error = %v, want nil for versions in different files", err)
+	}
+}
+
+// TestDiscoverProviders_SkipsBuiltinTypes tests that built-in provider types
+// are not returned for download.
+func TestDiscoverProviders_SkipsBuiltinTypes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.csl")
+	configContent := `source:
+  alias: 'network'
+  type: 'tfstate'
+  path: './infra'
+
+source:
+  alias: 'configs'
+  type: 'owner/repo'
+  version: '1.0.0'
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	providers, err := DiscoverProviders([]string{configPath})
+	if err != nil {
+		t.Fatalf("DiscoverProviders failed: %v", err)
+	}
+	if len(providers) != 1 || providers[0].Alias != "configs" {
+		t.Errorf("providers = %+v, want only 'configs'", providers)
+	}
+}
+
 // TestDiscoverProviders_InvalidFile tests error handling for non-existent files.
 func TestDiscoverProviders_InvalidFile(t *testing.T) {
 	// Act: Try to discover from non-existent file
@@ -0,0 +1,383 @@
+package providercmd
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// CheckStatus is the outcome of one readiness check.
+type CheckStatus string
+
+const (
+	// CheckPass indicates the check succeeded.
+	CheckPass CheckStatus = "pass"
+
+	// CheckWarn indicates the provider may work but needs attention.
+	CheckWarn CheckStatus = "warn"
+
+	// CheckFail indicates the provider cannot be used until fixed.
+	CheckFail CheckStatus = "fail"
+
+	// CheckSkip indicates the check could not run, usually because an
+	// earlier check failed.
+	CheckSkip CheckStatus = "skip"
+)
+
+// Readiness check names, in the order Diagnose runs them.
+const (
+	CheckInstall  = "install"
+	CheckPlatform = "platform"
+	CheckStart    = "start"
+	CheckHealth   = "health"
+	CheckConfig   = "config"
+	CheckVersion  = "version"
+)
+
+// DefaultDoctorTimeout bounds how long Diagnose waits for one provider.
+const DefaultDoctorTimeout = 10 * time.Second
+
+// DoctorCheck is the result of one readiness check of a provider.
+type DoctorCheck struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+
+	// Hint tells the user how to fix a failed or warning check.
+	Hint string `json:"hint,omitempty"`
+}
+
+// ProviderDiagnosis is the readiness report of one provider.
+type ProviderDiagnosis struct {
+	Alias   string        `json:"alias"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	File    string        `json:"file,omitempty"` // .csl file of the declaration
+	Path    string        `json:"path,omitempty"` // binary path relative to the providers directory
+	Checks  []DoctorCheck `json:"checks"`
+
+	// Stderr holds the end of what the provider wrote to stderr when it
+	// failed to start.
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// Ready reports whether no check failed.
+func (d ProviderDiagnosis) Ready() bool {
+	for _, c := range d.Checks {
+		if c.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *ProviderDiagnosis) add(name string, status CheckStatus, detail, hint string) {
+	d.Checks = append(d.Checks, DoctorCheck{Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+// DoctorOptions configures Diagnose.
+type DoctorOptions struct {
+	// Paths are the .csl files or directories declaring providers.
+	Paths []string
+
+	// Lock is the lockfile; nil is treated as an empty lockfile.
+	Lock *LockFile
+
+	// Timeout bounds the probe of each provider (default DefaultDoctorTimeout).
+	Timeout time.Duration
+
+	// Probe starts a provider binary and reports how it answered
+	// (default compiler.ProbeProvider).
+	Probe func(ctx context.Context, binaryPath string, opts *compiler.ProviderInitOptions) (compiler.ProviderProbe, error)
+}
+
+// Diagnose checks that every provider declared under opts.Paths, and every
+// lockfile entry for the host platform that none declares, is ready to use:
+// the locked binary is installed, built for the host platform, starts and
+// reports healthy, accepts its declared configuration, and reports the
+// locked version. Reports are sorted by alias.
+func Diagnose(ctx context.Context, opts DoctorOptions) ([]ProviderDiagnosis, error) {
+	if opts.Lock == nil {
+		opts.Lock = &LockFile{}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultDoctorTimeout
+	}
+	if opts.Probe == nil {
+		opts.Probe = compiler.ProbeProvider
+	}
+
+	declared, err := DiscoverProviders(opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]ProviderDiagnosis, 0, len(declared))
+	used := make(map[*ProviderEntry]bool)
+	for _, p := range declared {
+		d := ProviderDiagnosis{Alias: p.Alias, Type: p.Type, Version: p.Version, File: p.File}
+		entry := lockEntry(opts.Lock, p.Alias, p.Type, p.Version, runtime.GOOS, runtime.GOARCH)
+		if entry == nil {
+			d.add(CheckInstall, CheckFail, fmt.Sprintf("no lockfile entry for %s/%s", runtime.GOOS, runtime.GOARCH),
+				"run 'nomos build' to install it")
+			reports = append(reports, d)
+			continue
+		}
+		used[entry] = true
+		d.Path = entry.Path
+		initOpts := &compiler.ProviderInitOptions{Alias: p.Alias, Config: p.Config, SourceFilePath: p.File}
+		diagnoseEntry(ctx, &d, *entry, initOpts, opts)
+		reports = append(reports, d)
+	}
+
+	for i := range opts.Lock.Providers {
+		entry := &opts.Lock.Providers[i]
+		if used[entry] || entry.OS != runtime.GOOS || entry.Arch != runtime.GOARCH {
+			continue
+		}
+		d := ProviderDiagnosis{Alias: entry.Alias, Type: entry.Type, Version: entry.Version, Path: entry.Path}
+		diagnoseEntry(ctx, &d, *entry, nil, opts)
+		reports = append(reports, d)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Alias < reports[j].Alias })
+	return reports, nil
+}
+
+// diagnoseEntry runs the checks of a locked provider. initOpts is nil for
+// entries no .csl file declares, whose configuration is unknown.
+func diagnoseEntry(ctx context.Context, d *ProviderDiagnosis, entry ProviderEntry, initOpts *compiler.ProviderInitOptions, opts DoctorOptions) {
+	binaryPath := filepath.Join(nomosdir.ProvidersDir(), entry.Path)
+
+	switch CheckInstallState(entry) {
+	case InstallStateMissing:
+		d.add(CheckInstall, CheckFail, "binary not found at "+binaryPath, "run 'nomos build' to download it again")
+		return
+	case InstallStateModified:
+		d.add(CheckInstall, CheckFail, "binary does not match the locked checksum or is not executable",
+			fmt.Sprintf("delete %s and run 'nomos build' to reinstall it", binaryPath))
+		return
+	default:
+		d.add(CheckInstall, CheckPass, binaryPath, "")
+	}
+
+	host := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	platform, known, err := BinaryPlatform(binaryPath)
+	switch {
+	case err != nil:
+		d.add(CheckPlatform, CheckWarn, err.Error(), "")
+	case !known:
+		d.add(CheckPlatform, CheckPass, "script or WASM module, runs on any platform", "")
+	case platform != host:
+		d.add(CheckPlatform, CheckFail, fmt.Sprintf("binary is built for %s, host is %s", platform, host),
+			fmt.Sprintf("delete %s and run 'nomos build' to install the %s asset", binaryPath, host))
+		d.add(CheckStart, CheckSkip, "binary cannot run on this platform", "")
+		return
+	default:
+		d.add(CheckPlatform, CheckPass, platform.String(), "")
+	}
+
+	// References are resolved during build, so a configuration using them
+	// cannot be validated here
+	probeInit := initOpts
+	configNote := ""
+	if initOpts == nil {
+		configNote = "no .csl file declares this provider"
+	} else if hasUnresolvedValue(initOpts.Config) {
+		probeInit = nil
+		configNote = "configuration uses references or functions, which are resolved during build"
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	probe, err := opts.Probe(probeCtx, binaryPath, probeInit)
+	if err != nil {
+		d.add(CheckStart, CheckFail, err.Error(), startHint(err, opts.Timeout))
+		d.Stderr = probe.Stderr
+		return
+	}
+	d.add(CheckStart, CheckPass, "", "")
+
+	switch probe.Health {
+	case "ok":
+		d.add(CheckHealth, CheckPass, probe.HealthMessage, "")
+	default:
+		detail := probe.Health
+		if probe.HealthMessage != "" {
+			detail += ": " + probe.HealthMessage
+		}
+		d.add(CheckHealth, CheckWarn, detail, "the provider reports it is not fully ready; fetches may fail")
+	}
+
+	switch {
+	case probeInit == nil:
+		d.add(CheckConfig, CheckSkip, configNote, "")
+	case probe.InitError != nil:
+		d.add(CheckConfig, CheckFail, probe.InitError.Error(),
+			fmt.Sprintf("fix the source declaration of '%s' in %s", initOpts.Alias, initOpts.SourceFilePath))
+	default:
+		d.add(CheckConfig, CheckPass, "", "")
+	}
+
+	switch {
+	case probe.Version == "":
+		d.add(CheckVersion, CheckSkip, "provider did not report its version", "")
+	case strings.TrimPrefix(probe.Version, "v") != strings.TrimPrefix(entry.Version, "v"):
+		d.add(CheckVersion, CheckWarn, fmt.Sprintf("provider reports %s, lockfile has %s", probe.Version, entry.Version),
+			"the release may contain a mislabeled binary; report it to the provider's author")
+	default:
+		d.add(CheckVersion, CheckPass, probe.Version, "")
+	}
+}
+
+// startHint suggests a fix for a provider that failed to start.
+func startHint(err error, timeout time.Duration) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("the provider did not answer within %s; retry with a longer --timeout-per-provider", timeout)
+	}
+	return "see the provider's stderr below; reinstalling with 'nomos build' may help if the binary is damaged"
+}
+
+// hasUnresolvedValue reports whether a declared configuration holds a nil
+// value, which DiscoverProviders records for references and function calls.
+func hasUnresolvedValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		for _, child := range v {
+			if hasUnresolvedValue(child) {
+				return true
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if hasUnresolvedValue(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BinaryPlatform reports the platform an executable was built for, read
+// from its ELF, Mach-O, or PE header. Scripts starting with "#!" and WASM
+// modules, which the compiler runs in-process, report known=false. Universal Mach-O binaries report the host architecture when
+// they include it.
+func BinaryPlatform(path string) (platform Platform, known bool, err error) {
+	//nolint:gosec // G304: Path comes from the lockfile
+	f, err := os.Open(path)
+	if err != nil {
+		return Platform{}, false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	magic := make([]byte, 4)
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return Platform{}, false, fmt.Errorf("cannot read executable header: %w", err)
+	}
+	if bytes.HasPrefix(magic, []byte("#!")) || bytes.Equal(magic, []byte("\x00asm")) {
+		return Platform{}, false, nil
+	}
+
+	if ef, err := elf.NewFile(f); err == nil {
+		return elfPlatform(ef), true, nil
+	}
+	if mf, err := macho.NewFile(f); err == nil {
+		return Platform{OS: "darwin", Arch: machoArch(mf.Cpu)}, true, nil
+	}
+	if ff, err := macho.NewFatFile(f); err == nil {
+		p := Platform{OS: "darwin"}
+		for _, a := range ff.Arches {
+			arch := machoArch(a.Cpu)
+			if p.Arch == "" || arch == runtime.GOARCH {
+				p.Arch = arch
+			}
+		}
+		return p, true, nil
+	}
+	if pf, err := pe.NewFile(f); err == nil {
+		return Platform{OS: "windows", Arch: peArch(pf.Machine)}, true, nil
+	}
+	return Platform{}, false, errors.New("unrecognized executable format")
+}
+
+// elfPlatform maps an ELF header to a Go platform. ELF binaries for Linux
+// usually carry the System V ABI, so that is reported as linux.
+func elfPlatform(f *elf.File) Platform {
+	p := Platform{OS: "linux"}
+	switch f.OSABI {
+	case elf.ELFOSABI_FREEBSD:
+		p.OS = "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		p.OS = "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		p.OS = "openbsd"
+	}
+	switch f.Machine {
+	case elf.EM_X86_64:
+		p.Arch = "amd64"
+	case elf.EM_AARCH64:
+		p.Arch = "arm64"
+	case elf.EM_386:
+		p.Arch = "386"
+	case elf.EM_ARM:
+		p.Arch = "arm"
+	case elf.EM_RISCV:
+		p.Arch = "riscv64"
+	case elf.EM_PPC64:
+		p.Arch = "ppc64"
+		if f.ByteOrder == binary.LittleEndian {
+			p.Arch = "ppc64le"
+		}
+	case elf.EM_S390:
+		p.Arch = "s390x"
+	case elf.EM_LOONGARCH:
+		p.Arch = "loong64"
+	default:
+		p.Arch = strings.ToLower(strings.TrimPrefix(f.Machine.String(), "EM_"))
+	}
+	return p
+}
+
+// machoArch maps a Mach-O CPU type to a Go architecture.
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.Cpu386:
+		return "386"
+	default:
+		return strings.ToLower(strings.TrimPrefix(cpu.String(), "Cpu"))
+	}
+}
+
+// peArch maps a PE machine type to a Go architecture.
+func peArch(machine uint16) string {
+	switch machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386"
+	default:
+		return fmt.Sprintf("0x%x", machine)
+	}
+}
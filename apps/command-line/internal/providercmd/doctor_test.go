@@ -0,0 +1,207 @@
+package providercmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestDiagnose verifies each readiness check is reported from the lockfile,
+// the binaries on disk, and the probe of each provider.
+func TestDiagnose(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	csl := `source:
+  alias: 'configs'
+  type: 'owner/configs'
+  version: '1.0.0'
+  directory: './data'
+  options:
+    recursive: 'true'
+
+source:
+  alias: 'badconf'
+  type: 'owner/badconf'
+  version: '1.0.0'
+
+source:
+  alias: 'crash'
+  type: 'owner/crash'
+  version: '1.0.0'
+
+source:
+  alias: 'refs'
+  type: 'owner/refs'
+  version: '1.0.0'
+  token: @configs:token
+
+source:
+  alias: 'missing'
+  type: 'owner/missing'
+  version: '1.0.0'
+
+source:
+  alias: 'secrets'
+  type: 'owner/secrets'
+  version: '2.0.0'
+`
+	if err := os.WriteFile("app.csl", []byte(csl), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	lock := &LockFile{}
+	for _, name := range []string{"configs", "badconf", "crash", "refs", "missing"} {
+		rel := filepath.Join("owner", name, "1.0.0", platform, "provider")
+		lock.Providers = append(lock.Providers, ProviderEntry{
+			Alias: name, Type: "owner/" + name, Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH, Path: rel,
+		})
+		if name == "missing" {
+			continue
+		}
+		binPath := filepath.Join(".nomos", "providers", rel)
+		if err := os.MkdirAll(filepath.Dir(binPath), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(binPath, []byte("#!/bin/sh\n"), 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+			t.Fatal(err)
+		}
+	}
+
+	probed := make(map[string]*compiler.ProviderInitOptions)
+	probe := func(_ context.Context, binaryPath string, opts *compiler.ProviderInitOptions) (compiler.ProviderProbe, error) {
+		name := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(binaryPath))))
+		probed[name] = opts
+		switch name {
+		case "crash":
+			return compiler.ProviderProbe{Stderr: "panic: boom\n"}, errors.New("provider exited before reporting its port")
+		case "badconf":
+			return compiler.ProviderProbe{Health: "ok", Version: "1.0.0", InitError: errors.New("directory is required")}, nil
+		case "refs":
+			return compiler.ProviderProbe{Health: "degraded", HealthMessage: "cache cold", Version: "0.9.0"}, nil
+		default:
+			return compiler.ProviderProbe{Health: "ok", Version: "v1.0.0"}, nil
+		}
+	}
+
+	reports, err := Diagnose(context.Background(), DoctorOptions{Paths: []string{"."}, Lock: lock, Probe: probe})
+	if err != nil {
+		t.Fatalf("Diagnose() error: %v", err)
+	}
+
+	byAlias := make(map[string]ProviderDiagnosis)
+	for i, r := range reports {
+		if i > 0 && reports[i-1].Alias > r.Alias {
+			t.Errorf("Diagnose() not sorted by alias: %q before %q", reports[i-1].Alias, r.Alias)
+		}
+		byAlias[r.Alias] = r
+	}
+	if len(byAlias) != 6 {
+		t.Fatalf("Diagnose() returned %d reports, want 6: %+v", len(reports), reports)
+	}
+
+	check := func(alias, name string) DoctorCheck {
+		for _, c := range byAlias[alias].Checks {
+			if c.Name == name {
+				return c
+			}
+		}
+		return DoctorCheck{}
+	}
+
+	tests := []struct {
+		alias, check string
+		want         CheckStatus
+	}{
+		{"configs", CheckInstall, CheckPass},
+		{"configs", CheckPlatform, CheckPass},
+		{"configs", CheckHealth, CheckPass},
+		{"configs", CheckConfig, CheckPass},
+		{"configs", CheckVersion, CheckPass},
+		{"badconf", CheckConfig, CheckFail},
+		{"crash", CheckStart, CheckFail},
+		{"crash", CheckHealth, ""},
+		{"refs", CheckHealth, CheckWarn},
+		{"refs", CheckConfig, CheckSkip},
+		{"refs", CheckVersion, CheckWarn},
+		{"missing", CheckInstall, CheckFail},
+		{"secrets", CheckInstall, CheckFail},
+	}
+	for _, tt := range tests {
+		if got := check(tt.alias, tt.check).Status; got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.alias, tt.check, got, tt.want)
+		}
+	}
+
+	for alias, ready := range map[string]bool{"configs": true, "refs": true, "badconf": false, "crash": false, "missing": false, "secrets": false} {
+		if byAlias[alias].Ready() != ready {
+			t.Errorf("%s Ready() = %v, want %v", alias, !ready, ready)
+		}
+	}
+
+	if byAlias["crash"].Stderr != "panic: boom\n" {
+		t.Errorf("crash Stderr = %q", byAlias["crash"].Stderr)
+	}
+	if hint := check("badconf", CheckConfig).Hint; !strings.Contains(hint, "app.csl") {
+		t.Errorf("badconf config hint = %q, want the declaring file", hint)
+	}
+
+	// The declared configuration is passed to Init, except when it uses
+	// references; missing binaries are never started
+	if opts := probed["configs"]; opts == nil || opts.Config["directory"] != "./data" ||
+		opts.Config["options"].(map[string]any)["recursive"] != "true" {
+		t.Errorf("configs probed with %+v, want its declared configuration", opts)
+	}
+	if opts, ok := probed["refs"]; !ok || opts != nil {
+		t.Errorf("refs probed with %+v, want no Init", opts)
+	}
+	if _, ok := probed["missing"]; ok {
+		t.Error("Diagnose() started a missing binary")
+	}
+}
+
+// TestBinaryPlatform verifies the platform is read from executable headers.
+func TestBinaryPlatform(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform, known, err := BinaryPlatform(exe)
+	if err != nil || !known {
+		t.Fatalf("BinaryPlatform(test binary) = %v, %v, %v", platform, known, err)
+	}
+	if want := (Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}); platform != want {
+		t.Errorf("BinaryPlatform(test binary) = %s, want %s", platform, want)
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, known, err := BinaryPlatform(script); err != nil || known {
+		t.Errorf("BinaryPlatform(script) known = %v, err = %v; want unknown without error", known, err)
+	}
+
+	module := filepath.Join(dir, "module")
+	if err := os.WriteFile(module, []byte("\x00asm\x01\x00\x00\x00"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, known, err := BinaryPlatform(module); err != nil || known {
+		t.Errorf("BinaryPlatform(WASM module) known = %v, err = %v; want unknown without error", known, err)
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	if err := os.WriteFile(garbage, []byte("not an executable"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := BinaryPlatform(garbage); err == nil {
+		t.Error("BinaryPlatform(garbage) succeeded, want error")
+	}
+}
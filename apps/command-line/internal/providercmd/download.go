@@ -3,10 +3,14 @@ package providercmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/libs/compiler"
 	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
 )
 
@@ -16,12 +20,13 @@ import (
 //
 // The function performs the following steps:
 //  1. Reads the existing lockfile (if present)
-//  2. For each provider:
+//  2. Groups aliases with the same type and version, which share one binary
+//     and one lockfile entry, then for each group:
 //     - If opts.Force is true: downloads regardless of lockfile
 //     - Otherwise: checks if provider exists in lockfile with matching version/OS/arch
 //     - If found in lockfile with matching metadata: skips download
 //     - If not found or mismatched: downloads the provider binary
-//  3. Returns an array of ProviderResult for each provider
+//  3. Returns an array of ProviderResult for each group
 //
 // In dry-run mode (opts.DryRun), the function returns preview results without
 // performing actual downloads.
@@ -63,8 +68,10 @@ func DownloadProviders(providers []DiscoveredProvider, opts ProviderOptions) ([]
 		existingLock = nil
 	}
 
-	// Process each provider
-	for _, p := range providers {
+	// Process each group of aliases sharing a binary
+	for _, group := range groupSharedProviders(providers) {
+		p := group[0]
+		aliases := providerAliases(group)
 		result := ProviderResult{
 			Alias:   p.Alias,
 			Type:    p.Type,
@@ -72,26 +79,36 @@ func DownloadProviders(providers []DiscoveredProvider, opts ProviderOptions) ([]
 			OS:      opts.OS,
 			Arch:    opts.Arch,
 		}
+		if len(aliases) > 1 {
+			result.Aliases = aliases
+		}
 
 		// T043: When force flag is set, skip lockfile check and force re-download
 		if opts.Force {
 			// T044: Delete existing cached binary before re-download
 			if existingLock != nil {
-				if existingEntry := findProviderInLockfile(existingLock, p.Alias, p.Type, p.Version, opts.OS, opts.Arch); existingEntry != nil {
+				if existingEntry := findSharedEntry(existingLock, aliases, p, opts.OS, opts.Arch); existingEntry != nil {
 					deleteProviderBinary(*existingEntry)
 				}
 			}
 		} else {
 			// Normal flow: Check lockfile for existing valid provider
 			if existingLock != nil {
-				if existingEntry := findProviderInLockfile(existingLock, p.Alias, p.Type, p.Version, opts.OS, opts.Arch); existingEntry != nil {
-					// Validate existing provider binary
-					if validateErr := ValidateProvider(*existingEntry); validateErr == nil {
+				if existingEntry := findSharedEntry(existingLock, aliases, p, opts.OS, opts.Arch); existingEntry != nil {
+					// Validate existing provider binary, which must have been
+					// verified against the signer now required
+					trust, _ := trustFor(opts.Trusted, p.Type)
+					if validateErr := ValidateProvider(*existingEntry); validateErr == nil && (trust.Signer == "" || existingEntry.Signer == trust.Signer) {
 						// Provider exists and is valid - skip download
 						result.Status = ProviderStatusSkipped
 						result.Path = existingEntry.Path
 						result.Size = existingEntry.Size
 						results = append(results, result)
+
+						// Record aliases that now share the cached entry
+						if shared, changed := withAliases(*existingEntry, aliases); changed {
+							entries = append(entries, shared)
+						}
 						continue
 					}
 					// Validation failed - will re-download below
@@ -121,6 +138,7 @@ func DownloadProviders(providers []DiscoveredProvider, opts ProviderOptions) ([]
 		results = append(results, result)
 
 		// Collect entry for lockfile update
+		entry, _ = withAliases(entry, aliases)
 		entries = append(entries, entry)
 	}
 
@@ -130,7 +148,34 @@ func DownloadProviders(providers []DiscoveredProvider, opts ProviderOptions) ([]
 // downloadProvider downloads and installs a single provider binary.
 // This is extracted from the existing installProvider() logic in init.go
 // for reuse across different command contexts.
+//
+// When the provider's trusted_providers entry names a signer, the installed
+// binary must be attested by it.
 func downloadProvider(p DiscoveredProvider, opts ProviderOptions) (ProviderEntry, error) {
+	var entry ProviderEntry
+	var err error
+	if opts.MirrorDir != "" {
+		entry, err = installFromMirror(p, opts)
+	} else {
+		entry, err = downloadProviderTo(p, opts, nomosdir.ProvidersDir())
+	}
+	if err != nil {
+		return ProviderEntry{}, err
+	}
+
+	if trust, _ := trustFor(opts.Trusted, p.Type); trust.Signer != "" {
+		if err := verifySigner(entry, trust.Signer, opts); err != nil {
+			return ProviderEntry{}, err
+		}
+		entry.Signer = trust.Signer
+	}
+	return entry, nil
+}
+
+// downloadProviderTo downloads a single provider binary for opts.OS/opts.Arch
+// into root using the InstallPath layout.
+// The returned entry's Path is relative to root.
+func downloadProviderTo(p DiscoveredProvider, opts ProviderOptions, root string) (ProviderEntry, error) {
 	// Parse owner/repo from provider type
 	owner, repo, err := parseOwnerRepo(p.Type)
 	if err != nil {
@@ -149,56 +194,95 @@ func downloadProvider(p DiscoveredProvider, opts ProviderOptions) (ProviderEntry
 		defer cancel()
 	}
 
-	// Create downloader client with optional GitHub token
+	// Create downloader client with optional GitHub tokens
 	client := downloader.NewClient(&downloader.ClientOptions{
-		GitHubToken: opts.GitHubToken,
+		TokenSource:   opts.Tokens,
+		ArchFallbacks: opts.ArchFallbacks,
+		Bandwidth:     opts.Bandwidth,
+		StoreDir:      nomosdir.StoreDir(),
 	})
 
 	// Build ProviderSpec for downloader
 	spec := &downloader.ProviderSpec{
-		Owner:   owner,
-		Repo:    repo,
-		Version: p.Version,
-		OS:      opts.OS,
-		Arch:    opts.Arch,
+		Owner:       owner,
+		Repo:        repo,
+		Version:     p.Version,
+		OS:          opts.OS,
+		Arch:        opts.Arch,
+		AllowYanked: opts.AllowYanked,
 	}
 
 	// Resolve asset from GitHub Releases
 	asset, err := client.ResolveAsset(ctx, spec)
 	if err != nil {
+		if errors.Is(err, downloader.ErrReleaseYanked) {
+			return ProviderEntry{}, compiler.WithHint(err, allowYankedHint)
+		}
 		return ProviderEntry{}, fmt.Errorf("failed to resolve provider from GitHub: %w", err)
 	}
+	warnReleaseStatus(p, asset)
+	if asset.FallbackArch != "" {
+		fmt.Fprintf(os.Stderr, "Warning: provider %q (%s@%s) has no %s/%s release asset; installing the %s/%s build instead\n",
+			p.Alias, p.Type, asset.Version, opts.OS, opts.Arch, opts.OS, asset.FallbackArch)
+	}
+
+	// A channel resolves to a concrete release, which is what gets pinned
+	version, channel := p.Version, ""
+	if downloader.IsChannel(p.Version) {
+		channel = p.Version
+		version = strings.TrimPrefix(asset.Version, "v")
+		fmt.Fprintf(os.Stderr, "Resolved %s/%s@%s to %s\n", owner, repo, channel, version)
+	}
+
+	// A pinned digest must match the published one and is enforced by the download
+	if p.Digest != "" {
+		if asset.Checksum != "" && asset.Checksum != p.Digest {
+			return ProviderEntry{}, fmt.Errorf("%w: release asset %s has digest %s, declaration pins %s",
+				ErrChecksumMismatch, asset.Name, asset.Checksum, p.Digest)
+		}
+		asset.Checksum = p.Digest
+	}
+
+	// A pinned checksum must match the installed binary
+	asset.BinaryChecksum = p.Checksum
 
 	// Determine installation directory
-	// Pattern: .nomos/providers/{owner}/{repo}/{version}/{os-arch}/
-	destDir := filepath.Join(".nomos", "providers", owner, repo, p.Version, fmt.Sprintf("%s-%s", opts.OS, opts.Arch))
+	// Pattern: {root}/{owner}/{repo}/{version}/{os}_{arch}/
+	// The path is stored relative to root for portability
+	relativePath := InstallPath(owner, repo, version, opts.OS, opts.Arch)
+	destDir := filepath.Join(root, filepath.Dir(relativePath))
 
 	// Download and install binary
 	result, err := client.DownloadAndInstall(ctx, asset, destDir)
 	if err != nil {
+		if errors.Is(err, downloader.ErrBudgetExceeded) {
+			return ProviderEntry{}, fmt.Errorf("failed to download provider binary: %w (raise --max-download-bytes to allow more)", err)
+		}
 		return ProviderEntry{}, fmt.Errorf("failed to download provider binary: %w", err)
 	}
 
 	// Normalize version format (add 'v' prefix if missing)
-	releaseTag := p.Version
-	if len(p.Version) > 0 && p.Version[0] != 'v' {
-		releaseTag = "v" + p.Version
+	releaseTag := version
+	if len(version) > 0 && version[0] != 'v' {
+		releaseTag = "v" + version
 	}
 
-	// Build relative path for lockfile entry
-	// Path is relative to .nomos/providers/ for portability
-	relativePath := filepath.Join(owner, repo, p.Version, fmt.Sprintf("%s-%s", opts.OS, opts.Arch), "provider")
-
 	// Construct ProviderEntry with GitHub metadata
 	entry := ProviderEntry{
-		Alias:    p.Alias,
-		Type:     p.Type,
-		Version:  p.Version,
-		OS:       opts.OS,
-		Arch:     opts.Arch,
-		Checksum: result.Checksum,
-		Size:     result.Size,
-		Path:     relativePath,
+		Alias:          p.Alias,
+		Type:           p.Type,
+		Version:        version,
+		OS:             opts.OS,
+		Arch:           opts.Arch,
+		Checksum:       result.Checksum,
+		Size:           result.Size,
+		Path:           relativePath,
+		VerifiedAt:     timeNowRFC3339(),
+		Channel:        channel,
+		Digest:         p.Digest,
+		ReleaseStatus:  string(asset.Status),
+		ReleaseMessage: asset.StatusMessage,
+		AssetArch:      asset.FallbackArch,
 		Source: map[string]interface{}{
 			"github": map[string]interface{}{
 				"owner":       owner,
@@ -212,13 +296,26 @@ func downloadProvider(p DiscoveredProvider, opts ProviderOptions) (ProviderEntry
 	return entry, nil
 }
 
+// warnReleaseStatus warns on stderr when the resolved release is deprecated,
+// or yanked and installed because yanked releases were allowed.
+func warnReleaseStatus(p DiscoveredProvider, asset *downloader.AssetInfo) {
+	if asset.Status == downloader.ReleaseStatusActive {
+		return
+	}
+	msg := fmt.Sprintf("Warning: provider %q (%s@%s) is %s", p.Alias, p.Type, asset.Version, asset.Status)
+	if asset.StatusMessage != "" {
+		msg += ": " + asset.StatusMessage
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
 // findProviderInLockfile searches for a provider in the lockfile that matches
 // the given criteria. Returns nil if not found.
 func findProviderInLockfile(lock *LockFile, alias, providerType, version, os, arch string) *ProviderEntry {
 	for _, entry := range lock.Providers {
-		if entry.Alias == alias &&
+		if entry.HasAlias(alias) &&
 			entry.Type == providerType &&
-			entry.Version == version &&
+			entry.MatchesVersion(version) &&
 			entry.OS == os &&
 			entry.Arch == arch {
 			return &entry
@@ -227,6 +324,67 @@ func findProviderInLockfile(lock *LockFile, alias, providerType, version, os, ar
 	return nil
 }
 
+// findSharedEntry returns the lockfile entry for the type and version of p
+// and the given platform that serves any of aliases. A digest p pins must
+// match the digest the entry was pinned with, and a checksum the entry's
+// binary checksum. Returns nil if not found.
+func findSharedEntry(lock *LockFile, aliases []string, p DiscoveredProvider, os, arch string) *ProviderEntry {
+	for _, alias := range aliases {
+		entry := findProviderInLockfile(lock, alias, p.Type, p.Version, os, arch)
+		if entry != nil && (p.Digest == "" || entry.Digest == p.Digest) &&
+			(p.Checksum == "" || normalizeChecksum(entry.Checksum) == normalizeChecksum(p.Checksum)) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// groupSharedProviders groups discovered providers by type, version, and
+// pinned digest and checksum, preserving declaration order. Aliases in a group run the same binary.
+func groupSharedProviders(providers []DiscoveredProvider) [][]DiscoveredProvider {
+	index := make(map[string]int)
+	groups := make([][]DiscoveredProvider, 0, len(providers))
+	for _, p := range providers {
+		key := p.Type + "@" + p.Version + "#" + p.Digest + "#" + p.Checksum
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], p)
+	}
+	return groups
+}
+
+// providerAliases returns the aliases of the given providers in order.
+func providerAliases(providers []DiscoveredProvider) []string {
+	aliases := make([]string, len(providers))
+	for i, p := range providers {
+		aliases[i] = p.Alias
+	}
+	return aliases
+}
+
+// withAliases returns entry extended to serve aliases, keeping its existing
+// aliases first. changed reports whether any alias was added.
+func withAliases(entry ProviderEntry, aliases []string) (ProviderEntry, bool) {
+	all := append([]string(nil), entry.AliasList()...)
+	changed := false
+	for _, alias := range aliases {
+		if !entry.HasAlias(alias) {
+			all = append(all, alias)
+			changed = true
+		}
+	}
+	entry.Alias = all[0]
+	entry.Aliases = nil
+	if len(all) > 1 {
+		entry.Aliases = all
+	}
+	return entry, changed
+}
+
 // deleteProviderBinary removes an existing provider binary from the cache.
 // It constructs the full path from the lockfile entry and removes the file.
 // If the file doesn't exist, no error is returned. Deletion failures are
@@ -238,7 +396,7 @@ func deleteProviderBinary(entry ProviderEntry) {
 	}
 
 	// Build full path to provider binary
-	fullPath := filepath.Join(".nomos", "providers", entry.Path)
+	fullPath := filepath.Join(nomosdir.ProvidersDir(), entry.Path)
 
 	// Attempt to remove the file
 	if err := os.Remove(fullPath); err != nil {
@@ -1,9 +1,13 @@
 package providercmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -283,6 +287,134 @@ func createTestFile(path string) error {
 	return os.WriteFile(path, []byte("test"), 0600)
 }
 
+// TestDownloadProviders_SharedAliases verifies that aliases with the same
+// type and version reuse one cached binary and are recorded on one entry.
+func TestDownloadProviders_SharedAliases(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	content := []byte("fake provider binary")
+	hash := sha256.Sum256(content)
+	rel := filepath.Join("owner", "repo", "1.0.0", "linux-amd64", "provider")
+	binPath := filepath.Join(".nomos", "providers", rel)
+	if err := os.MkdirAll(filepath.Dir(binPath), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binPath, content, 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+		t.Fatal(err)
+	}
+	if err := WriteLockFile(LockFile{Providers: []ProviderEntry{{
+		Alias: "team1/configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64",
+		Checksum: "sha256:" + hex.EncodeToString(hash[:]), Size: int64(len(content)), Path: rel,
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := []DiscoveredProvider{
+		{Alias: "team1/configs", Type: "owner/repo", Version: "1.0.0"},
+		{Alias: "team2/configs", Type: "owner/repo", Version: "1.0.0"},
+	}
+	results, entries, err := DownloadProviders(providers, ProviderOptions{OS: "linux", Arch: "amd64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantAliases := []string{"team1/configs", "team2/configs"}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1 shared result", len(results))
+	}
+	if results[0].Status != ProviderStatusSkipped {
+		t.Errorf("status = %q, want %q", results[0].Status, ProviderStatusSkipped)
+	}
+	if !reflect.DeepEqual(results[0].Aliases, wantAliases) {
+		t.Errorf("result aliases = %v, want %v", results[0].Aliases, wantAliases)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1 updated entry", len(entries))
+	}
+	if entries[0].Alias != "team1/configs" || !reflect.DeepEqual(entries[0].Aliases, wantAliases) {
+		t.Errorf("entry aliases = %q %v, want %q %v", entries[0].Alias, entries[0].Aliases, "team1/configs", wantAliases)
+	}
+
+	// Once recorded, the shared entry needs no further update
+	if err := WriteLockFile(MergeLockFiles(nil, entries)); err != nil {
+		t.Fatal(err)
+	}
+	_, entries, err = DownloadProviders(providers, ProviderOptions{OS: "linux", Arch: "amd64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("got %d entries on second run, want 0", len(entries))
+	}
+}
+
+// TestDownloadProviders_ChannelPinnedInLockfile verifies that a channel
+// declaration reuses the release pinned by an earlier install.
+func TestDownloadProviders_ChannelPinnedInLockfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	content := []byte("fake provider binary")
+	hash := sha256.Sum256(content)
+	rel := filepath.Join("owner", "repo", "1.4.0", "linux-amd64", "provider")
+	binPath := filepath.Join(".nomos", "providers", rel)
+	if err := os.MkdirAll(filepath.Dir(binPath), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binPath, content, 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+		t.Fatal(err)
+	}
+	if err := WriteLockFile(LockFile{Providers: []ProviderEntry{{
+		Alias: "configs", Type: "owner/repo", Version: "1.4.0", Channel: "latest", OS: "linux", Arch: "amd64",
+		Checksum: "sha256:" + hex.EncodeToString(hash[:]), Size: int64(len(content)), Path: rel,
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "latest"}}
+	results, entries, err := DownloadProviders(providers, ProviderOptions{OS: "linux", Arch: "amd64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != ProviderStatusSkipped || results[0].Path != rel {
+		t.Errorf("results = %+v, want pinned release reused", results)
+	}
+	if len(entries) != 0 {
+		t.Errorf("got %d entries, want lockfile unchanged", len(entries))
+	}
+}
+
+// TestFindSharedEntry_Digest verifies that a pinned digest must match the
+// digest recorded in the lockfile, and a pinned checksum the binary
+// checksum.
+func TestFindSharedEntry_Digest(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	other := "sha256:" + strings.Repeat("f", 64)
+	lock := &LockFile{Providers: []ProviderEntry{
+		{Alias: "pinned", Type: "owner/repo", Version: "v1.0.0", OS: "linux", Arch: "amd64", Digest: digest, Checksum: digest},
+	}}
+	find := func(digest, checksum string) *ProviderEntry {
+		p := DiscoveredProvider{Type: "owner/repo", Version: "v1.0.0", Digest: digest, Checksum: checksum}
+		return findSharedEntry(lock, []string{"pinned"}, p, "linux", "amd64")
+	}
+
+	if find(digest, "") == nil {
+		t.Error("expected entry with matching digest")
+	}
+	if find("", "") == nil {
+		t.Error("expected entry when no digest is pinned")
+	}
+	if find(other, "") != nil {
+		t.Error("expected no entry for a different pinned digest")
+	}
+	if find("", digest) == nil {
+		t.Error("expected entry with matching checksum")
+	}
+	if find("", other) != nil {
+		t.Error("expected no entry for a different pinned checksum")
+	}
+}
+
 // Note: Testing actual download functionality requires integration tests with GitHub API.
 // The downloadProvider function is tested in integration tests with the --integration build tag.
 // Unit tests for DownloadProviders focus on:
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"os"
 	"sort"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
 )
 
 // EnsureProviders is the primary entry point that orchestrates the complete
@@ -17,7 +21,8 @@ import (
 //  1. Discover Phase:
 //     - Extracts provider declarations from .csl files
 //     - Validates all providers have versions (returns ErrMissingVersion if not)
-//     - Detects version conflicts (returns ErrVersionConflict if found)
+//     - Detects version conflicts within a file (returns ErrVersionConflict if found)
+//     - Rejects providers outside opts.Trusted (returns ErrUntrustedProvider)
 //     - Returns empty summary if no providers found
 //
 //  2. Download Phase:
@@ -69,11 +74,30 @@ func EnsureProviders(opts ProviderOptions) (*ProviderSummary, error) {
 		return nil, err
 	}
 
-	// Detect version conflicts across files
+	// Detect version conflicts within files; different files may pin
+	// different versions of a provider
 	if err := detectVersionConflicts(providers); err != nil {
 		return nil, err
 	}
 
+	// Release channels must be opted into
+	if err := validateChannels(providers, opts.AllowLatest, opts.AllowPrerelease); err != nil {
+		return nil, err
+	}
+
+	// Providers must come from trusted sources
+	if err := checkTrust(providers, opts.Trusted); err != nil {
+		return nil, err
+	}
+
+	// Move providers installed under an older layout, so their lockfile
+	// entries stay cached
+	if !opts.DryRun {
+		if err := migrateLockFile(nomosdir.ProvidersDir()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to migrate provider layout: %v\n", err)
+		}
+	}
+
 	// Phase 2: Download providers
 	results, downloadEntries, err := downloadProvidersWithEntries(providers, opts)
 	if err != nil {
@@ -91,7 +115,7 @@ func EnsureProviders(opts ProviderOptions) (*ProviderSummary, error) {
 
 	// Phase 3: Update lockfile (skip in dry-run mode)
 	if !opts.DryRun {
-		if err := updateLockfile(downloadEntries); err != nil {
+		if err := updateLockfile(providers, downloadEntries); err != nil {
 			// Providers were downloaded successfully but lockfile update failed
 			// This is a critical error - return with partial summary
 			return summary, fmt.Errorf("providers downloaded but lockfile update failed: %w", err)
@@ -114,21 +138,43 @@ func validateProviderVersions(providers []DiscoveredProvider) error {
 	return nil
 }
 
-// detectVersionConflicts checks if the same provider type has different versions
-// across multiple .csl files. Returns ErrVersionConflict if conflicts are found.
+// validateChannels checks that providers declaring a release channel have
+// been opted into it. Returns ErrChannelNotAllowed otherwise.
+func validateChannels(providers []DiscoveredProvider, allowLatest, allowPrerelease bool) error {
+	for _, p := range providers {
+		switch {
+		case p.Version == downloader.ChannelLatest && !allowLatest:
+			return compiler.WithHint(fmt.Errorf("%w: provider %q (type %q) uses version 'latest'",
+				ErrChannelNotAllowed, p.Alias, p.Type), compiler.Hint{Text: "pass --allow-latest to resolve it"})
+		case p.Version == downloader.ChannelPrerelease && !allowPrerelease:
+			return compiler.WithHint(fmt.Errorf("%w: provider %q (type %q) uses version 'prerelease'",
+				ErrChannelNotAllowed, p.Alias, p.Type), compiler.Hint{Text: "pass --allow-prerelease to resolve it"})
+		}
+	}
+	return nil
+}
+
+// detectVersionConflicts checks if a .csl file declares the same provider
+// alias at different versions. Different files may pin different versions:
+// the compiler runs one provider per version and scopes each to the files
+// declaring it. Returns ErrVersionConflict if conflicts are found.
 func detectVersionConflicts(providers []DiscoveredProvider) error {
-	// Track versions for each provider type
-	versions := make(map[string]map[string]bool) // type → versions
+	// Track versions for each alias in each file
+	versions := make(map[[2]string]map[string]bool) // {file, alias} → versions
+	order := make([][2]string, 0, len(providers))
 
 	for _, p := range providers {
-		if _, exists := versions[p.Type]; !exists {
-			versions[p.Type] = make(map[string]bool)
+		key := [2]string{p.File, p.Alias}
+		if _, exists := versions[key]; !exists {
+			versions[key] = make(map[string]bool)
+			order = append(order, key)
 		}
-		versions[p.Type][p.Version] = true
+		versions[key][p.Version] = true
 	}
 
 	// Check for conflicts
-	for providerType, versionSet := range versions {
+	for _, key := range order {
+		versionSet := versions[key]
 		if len(versionSet) > 1 {
 			// Collect version list for error message (sorted for determinism)
 			versionList := make([]string, 0, len(versionSet))
@@ -137,8 +183,8 @@ func detectVersionConflicts(providers []DiscoveredProvider) error {
 			}
 			sort.Strings(versionList)
 
-			return fmt.Errorf("%w: provider %q has conflicting versions: %v",
-				ErrVersionConflict, providerType, versionList)
+			return compiler.WithHint(fmt.Errorf("%w: provider %q has conflicting versions in %s: %v",
+				ErrVersionConflict, key[1], key[0], versionList), compiler.Hint{Text: "run 'nomos providers resolve' to align them"})
 		}
 	}
 
@@ -159,11 +205,12 @@ func downloadProvidersWithEntries(providers []DiscoveredProvider, opts ProviderO
 }
 
 // updateLockfile reads the existing lockfile, merges newly installed provider
-// entries, and writes the updated lockfile atomically.
+// entries, drops versions no longer declared, and writes the updated lockfile
+// atomically.
 //
 // This function receives complete ProviderEntry objects (with Checksum and
 // Source metadata) for newly installed providers only.
-func updateLockfile(newEntries []ProviderEntry) error {
+func updateLockfile(providers []DiscoveredProvider, newEntries []ProviderEntry) error {
 	// If no new installations, skip lockfile update
 	if len(newEntries) == 0 {
 		return nil
@@ -182,6 +229,13 @@ func updateLockfile(newEntries []ProviderEntry) error {
 
 	// Merge lockfiles
 	merged := MergeLockFiles(existingLock, newEntries)
+	merged.Providers = pruneUndeclaredVersions(merged.Providers, providers)
+
+	// Leave a lockfile that already pins these providers alone, so that a
+	// read-only checkout can build into a fresh provider directory
+	if existingLock != nil && sameEntries(existingLock.Providers, merged.Providers) {
+		return nil
+	}
 
 	// Write merged lockfile
 	if err := WriteLockFile(merged); err != nil {
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
 )
 
 // TestEnsureProviders_EmptyPaths tests error handling for empty paths.
@@ -95,33 +97,29 @@ func TestEnsureProviders_MissingVersion(t *testing.T) {
 	}
 }
 
-// TestEnsureProviders_VersionConflict tests detection of version conflicts.
+// TestEnsureProviders_VersionConflict tests detection of version conflicts
+// within a file.
 func TestEnsureProviders_VersionConflict(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Create two config files with conflicting versions
+	// Create a config file declaring one alias at two versions
 	config1Path := filepath.Join(tmpDir, "config1.csl")
 	config1Content := `source:
   alias: 'aws'
   type: 'owner/repo'
   version: '1.0.0'
-`
-	if err := os.WriteFile(config1Path, []byte(config1Content), 0600); err != nil {
-		t.Fatalf("failed to write config1: %v", err)
-	}
 
-	config2Path := filepath.Join(tmpDir, "config2.csl")
-	config2Content := `source:
-  alias: 'gcp'
+source:
+  alias: 'aws'
   type: 'owner/repo'
   version: '2.0.0'
 `
-	if err := os.WriteFile(config2Path, []byte(config2Content), 0600); err != nil {
-		t.Fatalf("failed to write config2: %v", err)
+	if err := os.WriteFile(config1Path, []byte(config1Content), 0600); err != nil {
+		t.Fatalf("failed to write config1: %v", err)
 	}
 
 	opts := ProviderOptions{
-		Paths: []string{config1Path, config2Path},
+		Paths: []string{config1Path},
 		OS:    runtime.GOOS,
 		Arch:  runtime.GOARCH,
 	}
@@ -243,6 +241,38 @@ func TestValidateProviderVersions(t *testing.T) {
 	}
 }
 
+// TestValidateChannels tests that release channels require opt-in.
+func TestValidateChannels(t *testing.T) {
+	latest := []DiscoveredProvider{{Alias: "aws", Type: "owner/repo", Version: "latest"}}
+	prerelease := []DiscoveredProvider{{Alias: "aws", Type: "owner/repo", Version: "prerelease"}}
+
+	tests := []struct {
+		name            string
+		providers       []DiscoveredProvider
+		allowLatest     bool
+		allowPrerelease bool
+		wantErr         bool
+	}{
+		{name: "pinned version needs no opt-in", providers: []DiscoveredProvider{{Alias: "aws", Type: "owner/repo", Version: "1.0.0"}}},
+		{name: "latest without opt-in", providers: latest, wantErr: true},
+		{name: "latest with opt-in", providers: latest, allowLatest: true},
+		{name: "prerelease with latest opt-in only", providers: prerelease, allowLatest: true, wantErr: true},
+		{name: "prerelease with opt-in", providers: prerelease, allowPrerelease: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateChannels(tt.providers, tt.allowLatest, tt.allowPrerelease)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateChannels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrChannelNotAllowed) {
+				t.Errorf("error should be %v, got %v", ErrChannelNotAllowed, err)
+			}
+		})
+	}
+}
+
 // TestDetectVersionConflicts tests the version conflict detection helper.
 func TestDetectVersionConflicts(t *testing.T) {
 	tests := []struct {
@@ -254,24 +284,32 @@ func TestDetectVersionConflicts(t *testing.T) {
 		{
 			name: "no conflicts - different types",
 			providers: []DiscoveredProvider{
-				{Alias: "aws", Type: "owner/repo1", Version: "1.0.0"},
-				{Alias: "gcp", Type: "owner/repo2", Version: "2.0.0"},
+				{Alias: "aws", Type: "owner/repo1", Version: "1.0.0", File: "a.csl"},
+				{Alias: "gcp", Type: "owner/repo2", Version: "2.0.0", File: "a.csl"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "no conflicts - same type different aliases",
+			providers: []DiscoveredProvider{
+				{Alias: "aws1", Type: "owner/repo", Version: "1.0.0", File: "a.csl"},
+				{Alias: "aws2", Type: "owner/repo", Version: "2.0.0", File: "a.csl"},
 			},
 			wantErr: false,
 		},
 		{
-			name: "no conflicts - same type same version",
+			name: "no conflicts - same alias different files",
 			providers: []DiscoveredProvider{
-				{Alias: "aws1", Type: "owner/repo", Version: "1.0.0"},
-				{Alias: "aws2", Type: "owner/repo", Version: "1.0.0"},
+				{Alias: "aws", Type: "owner/repo", Version: "1.0.0", File: "a.csl"},
+				{Alias: "aws", Type: "owner/repo", Version: "2.0.0", File: "b.csl"},
 			},
 			wantErr: false,
 		},
 		{
-			name: "conflict - same type different versions",
+			name: "conflict - same alias different versions in a file",
 			providers: []DiscoveredProvider{
-				{Alias: "aws1", Type: "owner/repo", Version: "1.0.0"},
-				{Alias: "aws2", Type: "owner/repo", Version: "2.0.0"},
+				{Alias: "aws", Type: "owner/repo", Version: "1.0.0", File: "a.csl"},
+				{Alias: "aws", Type: "owner/repo", Version: "2.0.0", File: "a.csl"},
 			},
 			wantErr: true,
 			errIs:   ErrVersionConflict,
@@ -279,9 +317,9 @@ func TestDetectVersionConflicts(t *testing.T) {
 		{
 			name: "conflict - three different versions",
 			providers: []DiscoveredProvider{
-				{Alias: "p1", Type: "owner/repo", Version: "1.0.0"},
-				{Alias: "p2", Type: "owner/repo", Version: "2.0.0"},
-				{Alias: "p3", Type: "owner/repo", Version: "3.0.0"},
+				{Alias: "p", Type: "owner/repo", Version: "1.0.0", File: "a.csl"},
+				{Alias: "p", Type: "owner/repo", Version: "2.0.0", File: "b.csl"},
+				{Alias: "p", Type: "owner/repo", Version: "3.0.0", File: "b.csl"},
 			},
 			wantErr: true,
 			errIs:   ErrVersionConflict,
@@ -437,3 +475,58 @@ func TestBuildSummary(t *testing.T) {
 		})
 	}
 }
+
+// TestEnsureProviders_NomosDir verifies that providers install into an
+// overridden nomos directory while the lockfile stays in the project, and
+// that a lockfile already pinning them is not rewritten.
+func TestEnsureProviders_NomosDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("app.csl", []byte(mirrorTestCSL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+	opts := ProviderOptions{Paths: []string{"app.csl"}, OS: "linux", Arch: "amd64", MirrorDir: "mirror"}
+
+	// Pin the provider in the project lockfile
+	if _, err := EnsureProviders(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lock, err := ReadLockFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Timestamp, lock.Providers[0].VerifiedAt = "2020-01-01T00:00:00Z", "2020-01-01T00:00:00Z"
+	if err := WriteLockFile(*lock); err != nil {
+		t.Fatal(err)
+	}
+	pinned, err := os.ReadFile(nomosdir.LockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build again into an empty shared cache
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	nomosdir.Set(cacheDir)
+	t.Cleanup(func() { nomosdir.Set("") })
+
+	summary, err := EnsureProviders(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Downloaded != 1 {
+		t.Errorf("summary = %+v, want 1 installed into the cache", summary)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "providers", "owner", "repo", "1.0.0", "linux-amd64", "provider")); err != nil {
+		t.Errorf("provider not installed into the nomos directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "providers.lock.json")); !os.IsNotExist(err) {
+		t.Errorf("lockfile written to the nomos directory (stat error = %v)", err)
+	}
+	got, err := os.ReadFile(nomosdir.LockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(pinned) {
+		t.Errorf("lockfile rewritten:\n%s\nwant unchanged:\n%s", got, pinned)
+	}
+}
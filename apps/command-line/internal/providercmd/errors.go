@@ -1,13 +1,19 @@
 // Package providercmd implements provider management functionality for the nomos CLI.
 package providercmd
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/parser"
+)
 
 // Sentinel errors for provider management operations.
 var (
-	// ErrVersionConflict is returned when multiple .csl files declare the same
-	// provider with different versions.
-	ErrVersionConflict = errors.New("conflicting provider versions across files")
+	// ErrVersionConflict is returned when a .csl file declares the same
+	// provider alias with different versions.
+	ErrVersionConflict = errors.New("conflicting provider versions")
 
 	// ErrMissingVersion is returned when a provider source declaration is
 	// missing the required version field.
@@ -21,4 +27,32 @@ var (
 	// GitHub releases fails due to network errors, missing assets, or
 	// unavailable releases.
 	ErrDownloadFailed = errors.New("provider download failed")
+
+	// ErrChannelNotAllowed is returned when a provider declares a release
+	// channel ("latest" or "prerelease") without the matching opt-in flag.
+	ErrChannelNotAllowed = errors.New("release channel not allowed")
+
+	// ErrUntrustedProvider is returned when a .csl file declares a provider
+	// outside the project's trusted_providers.
+	ErrUntrustedProvider = errors.New("provider not trusted")
+
+	// ErrSignerMismatch is returned when an installed provider binary is not
+	// attested by the signer its trusted_providers entry requires.
+	ErrSignerMismatch = errors.New("provider signer not verified")
 )
+
+// allowYankedHint is the hint of a yanked release that was not installed.
+var allowYankedHint = compiler.Hint{Text: "pass --allow-yanked to install it anyway"}
+
+// migrateHint is the hint of deprecated syntax the parser rejects.
+var migrateHint = compiler.Hint{Text: "run 'nomos migrate' to rewrite the deprecated syntax"}
+
+// parseError reports that the .csl file at path failed to parse, with
+// migrateHint if it holds deprecated syntax.
+func parseError(path string, err error) error {
+	err = fmt.Errorf("failed to parse %s: %w", path, err)
+	if parser.IsLegacySyntax(err) {
+		return compiler.WithHint(err, migrateHint)
+	}
+	return err
+}
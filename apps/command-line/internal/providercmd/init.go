@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
 	"github.com/autonomous-bits/nomos/libs/parser"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
@@ -38,6 +39,10 @@ type Options struct {
 
 // LockFile represents the .nomos/providers.lock.json structure.
 type LockFile struct {
+	// Version is the lockfile schema version (see LockFileVersion); zero
+	// for lockfiles written before versioning.
+	Version int `json:"version,omitempty"`
+
 	// Timestamp records when the lockfile was last written (RFC3339 format).
 	Timestamp string          `json:"timestamp,omitempty"`
 	Providers []ProviderEntry `json:"providers"`
@@ -54,14 +59,74 @@ type ProviderEntry struct {
 	Checksum string                 `json:"checksum,omitempty"`
 	Size     int64                  `json:"size,omitempty"`
 	Path     string                 `json:"path"`
+
+	// VerifiedAt records when the binary's checksum was last verified
+	// against the release asset (RFC3339 format).
+	VerifiedAt string `json:"verified_at,omitempty"`
+
+	// Aliases lists every alias sharing this binary when more than one
+	// source declaration uses the same type and version. Alias holds the
+	// first of them.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Channel is the release channel ("latest" or "prerelease") the
+	// declaration used; Version is the release it resolved to.
+	Channel string `json:"channel,omitempty"`
+
+	// Digest is the release asset digest the declaration pinned.
+	Digest string `json:"digest,omitempty"`
+
+	// ReleaseStatus is "deprecated" or "yanked" when the release declared
+	// that status at install time, with the author's ReleaseMessage.
+	ReleaseStatus  string `json:"release_status,omitempty"`
+	ReleaseMessage string `json:"release_message,omitempty"`
+
+	// AssetArch is the architecture of the installed binary when it differs
+	// from Arch: the release had no asset for Arch and an architecture
+	// fallback, such as amd64 under Rosetta on Apple Silicon, was used.
+	AssetArch string `json:"asset_arch,omitempty"`
+
+	// Signer is the workflow verified to have attested the binary, when the
+	// project's trusted_providers required one.
+	Signer string `json:"signer,omitempty"`
+}
+
+// MatchesVersion reports whether the entry satisfies a declared version,
+// which may be a release channel pinned by an earlier install.
+func (e ProviderEntry) MatchesVersion(version string) bool {
+	if downloader.IsChannel(version) {
+		return e.Channel == version
+	}
+	return e.Version == version
+}
+
+// AliasList returns every alias served by the entry, starting with Alias.
+func (e ProviderEntry) AliasList() []string {
+	if len(e.Aliases) == 0 {
+		return []string{e.Alias}
+	}
+	return e.Aliases
+}
+
+// HasAlias reports whether the entry serves the given alias.
+func (e ProviderEntry) HasAlias(alias string) bool {
+	for _, a := range e.AliasList() {
+		if a == alias {
+			return true
+		}
+	}
+	return false
 }
 
 // DiscoveredProvider represents a provider discovered from .csl files.
 type DiscoveredProvider struct {
-	Alias   string
-	Type    string
-	Version string
-	Config  map[string]any
+	Alias    string
+	Type     string
+	Version  string
+	Digest   string // optional asset digest pinning Version
+	Checksum string // optional checksum pinning the installed binary
+	Config   map[string]any
+	File     string // .csl file of the first declaration
 }
 
 // Run executes the init command with the given options.
@@ -166,7 +231,7 @@ func Run(opts Options) (*InitResult, error) {
 	}
 
 	// Write lock file
-	lockFile := LockFile{Providers: lockEntries}
+	lockFile := LockFile{Version: LockFileVersion, Providers: lockEntries}
 	if err := writeLockFile(lockFile); err != nil {
 		return result, fmt.Errorf("failed to write lock file: %w", err)
 	}
@@ -189,7 +254,7 @@ func discoverProviders(paths []string) ([]DiscoveredProvider, error) {
 
 		tree, err := parser.Parse(file, path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			return nil, parseError(path, err)
 		}
 
 		// Extract source declarations from AST
@@ -226,15 +291,30 @@ func discoverProviders(paths []string) ([]DiscoveredProvider, error) {
 	return providers, nil
 }
 
-// exprToValue converts an AST expression to a Go value.
+// exprToValue converts an AST expression to a Go value. References and
+// function calls, which are resolved during build, convert to nil, as does
+// a map that spreads a reference.
 func exprToValue(expr ast.Expr) any {
 	switch e := expr.(type) {
 	case *ast.StringLiteral:
 		return e.Value
-	case *ast.ReferenceExpr:
-		// For now, just return nil for references
-		// They would need to be resolved against providers
-		return nil
+	case *ast.MarkedExpr:
+		return exprToValue(e.Expr)
+	case *ast.MapExpr:
+		m := make(map[string]any, len(e.Entries))
+		for _, entry := range e.Entries {
+			if entry.Spread {
+				return nil
+			}
+			m[entry.Key] = exprToValue(entry.Value)
+		}
+		return m
+	case *ast.ListExpr:
+		list := make([]any, len(e.Elements))
+		for i, elem := range e.Elements {
+			list[i] = exprToValue(elem)
+		}
+		return list
 	default:
 		return nil
 	}
@@ -251,11 +331,10 @@ func installProvider(p DiscoveredProvider, opts Options) (ProviderEntry, error)
 	// Create context for download operations
 	ctx := context.Background()
 
-	// Create downloader client
-	// Check for GitHub token in environment for higher rate limits
-	githubToken := os.Getenv("GITHUB_TOKEN")
+	// Create downloader client, with GitHub tokens from the environment and
+	// credential stores for higher rate limits
 	client := downloader.NewClient(&downloader.ClientOptions{
-		GitHubToken: githubToken,
+		TokenSource: downloader.DefaultTokenSource(""),
 	})
 
 	// Build ProviderSpec
@@ -274,8 +353,9 @@ func installProvider(p DiscoveredProvider, opts Options) (ProviderEntry, error)
 	}
 
 	// Determine installation directory
-	// Pattern: .nomos/providers/{owner}/{repo}/{version}/{os-arch}/
-	destDir := filepath.Join(".nomos", "providers", owner, repo, p.Version, fmt.Sprintf("%s-%s", opts.OS, opts.Arch))
+	// Pattern: <nomos dir>/providers/{owner}/{repo}/{version}/{os}_{arch}/
+	relativePath := InstallPath(owner, repo, p.Version, opts.OS, opts.Arch)
+	destDir := filepath.Join(nomosdir.ProvidersDir(), filepath.Dir(relativePath))
 
 	// Download and install binary
 	result, err := client.DownloadAndInstall(ctx, asset, destDir)
@@ -290,19 +370,19 @@ func installProvider(p DiscoveredProvider, opts Options) (ProviderEntry, error)
 	}
 
 	// Build ProviderEntry with GitHub metadata
-	// Store path relative to destDir for portability
-	// The resolver will join this with the base directory at runtime
-	relativePath := filepath.Join(owner, repo, p.Version, fmt.Sprintf("%s-%s", opts.OS, opts.Arch), "provider")
+	// The path is stored relative to the providers directory for portability;
+	// the resolver joins it with the base directory at runtime
 
 	entry := ProviderEntry{
-		Alias:    p.Alias,
-		Type:     p.Type,
-		Version:  p.Version,
-		OS:       opts.OS,
-		Arch:     opts.Arch,
-		Checksum: result.Checksum,
-		Size:     result.Size,
-		Path:     relativePath,
+		Alias:      p.Alias,
+		Type:       p.Type,
+		Version:    p.Version,
+		OS:         opts.OS,
+		Arch:       opts.Arch,
+		Checksum:   result.Checksum,
+		Size:       result.Size,
+		Path:       relativePath,
+		VerifiedAt: timeNowRFC3339(),
 		Source: map[string]interface{}{
 			"github": map[string]interface{}{
 				"owner":       owner,
@@ -345,7 +425,7 @@ func parseOwnerRepo(providerType string) (owner, repo string, err error) {
 // writeLockFile writes the lock file to .nomos/providers.lock.json atomically.
 // Uses temp file + rename pattern for crash safety.
 func writeLockFile(lock LockFile) error {
-	lockPath := filepath.Join(".nomos", "providers.lock.json")
+	lockPath := nomosdir.LockfilePath
 
 	// Ensure directory exists
 	lockDir := filepath.Dir(lockPath)
@@ -406,7 +486,7 @@ func writeLockFile(lock LockFile) error {
 
 // readLockFile reads the existing lockfile, returns nil if not found or invalid.
 func readLockFile() *LockFile {
-	lockPath := filepath.Join(".nomos", "providers.lock.json")
+	lockPath := nomosdir.LockfilePath
 
 	//nolint:gosec // G304: Path is hardcoded to .nomos/providers.lock.json, safe
 	data, err := os.ReadFile(lockPath)
@@ -426,9 +506,9 @@ func readLockFile() *LockFile {
 // the given criteria. Returns nil if not found.
 func findProviderInLock(lock *LockFile, alias, providerType, version, os, arch string) *ProviderEntry {
 	for _, entry := range lock.Providers {
-		if entry.Alias == alias &&
+		if entry.HasAlias(alias) &&
 			entry.Type == providerType &&
-			entry.Version == version &&
+			entry.MatchesVersion(version) &&
 			entry.OS == os &&
 			entry.Arch == arch {
 			return &entry
@@ -0,0 +1,190 @@
+// Package providercmd implements provider management functionality for the nomos CLI.
+package providercmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+)
+
+// InstallState describes whether a provider is ready to use on a platform.
+type InstallState string
+
+const (
+	// InstallStateInstalled indicates the locked binary is present and
+	// matches its checksum.
+	InstallStateInstalled InstallState = "installed"
+
+	// InstallStateNotLocked indicates a declared provider has no lockfile
+	// entry for the platform. Running 'nomos build' installs it.
+	InstallStateNotLocked InstallState = "not-locked"
+
+	// InstallStateMissing indicates the lockfile entry exists but the
+	// binary is not on disk.
+	InstallStateMissing InstallState = "missing"
+
+	// InstallStateModified indicates the binary on disk does not match the
+	// locked checksum or is not executable.
+	InstallStateModified InstallState = "modified"
+
+	// InstallStateUnused indicates a lockfile entry for the platform that
+	// no .csl file declares.
+	InstallStateUnused InstallState = "unused"
+)
+
+// ProviderInfo describes a provider declared in .csl files, a lockfile
+// entry, or both.
+type ProviderInfo struct {
+	Alias      string       `json:"alias"`
+	Type       string       `json:"type"`
+	Version    string       `json:"version"`
+	OS         string       `json:"os"`
+	Arch       string       `json:"arch"`
+	Checksum   string       `json:"checksum,omitempty"`
+	Size       int64        `json:"size,omitempty"`
+	Path       string       `json:"path,omitempty"`
+	ReleaseURL string       `json:"release_url,omitempty"`
+	Asset      string       `json:"asset,omitempty"`
+	VerifiedAt string       `json:"verified_at,omitempty"`
+	State      InstallState `json:"state"`
+
+	// SharedBy lists every alias using the same lockfile entry when
+	// more than one alias shares it.
+	SharedBy []string `json:"shared_by,omitempty"`
+
+	// ReleaseStatus is "deprecated" or "yanked" when the release declared
+	// that status at install time, with the author's ReleaseMessage.
+	ReleaseStatus  string `json:"release_status,omitempty"`
+	ReleaseMessage string `json:"release_message,omitempty"`
+}
+
+// ListProviders reports the providers declared in the .csl files under
+// paths, joined with their lockfile entries for goos/goarch (the host
+// platform when empty). Lockfile entries for the platform that are not
+// declared are included with InstallStateUnused. lock may be nil.
+//
+// Results are sorted by alias. Binaries are checked but never modified.
+func ListProviders(paths []string, lock *LockFile, goos, goarch string) ([]ProviderInfo, error) {
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	if lock == nil {
+		lock = &LockFile{}
+	}
+
+	declared, err := DiscoverProviders(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ProviderInfo, 0, len(declared))
+	used := make(map[*ProviderEntry]bool)
+	for _, p := range declared {
+		info := ProviderInfo{Alias: p.Alias, Type: p.Type, Version: p.Version, OS: goos, Arch: goarch}
+		entry := lockEntry(lock, p.Alias, p.Type, p.Version, goos, goarch)
+		if entry == nil {
+			info.State = InstallStateNotLocked
+		} else {
+			used[entry] = true
+			info = providerInfoFromEntry(*entry)
+			info.Alias = p.Alias
+		}
+		infos = append(infos, info)
+	}
+
+	for i := range lock.Providers {
+		entry := &lock.Providers[i]
+		if used[entry] || entry.OS != goos || entry.Arch != goarch {
+			continue
+		}
+		info := providerInfoFromEntry(*entry)
+		info.State = InstallStateUnused
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Alias < infos[j].Alias })
+	return infos, nil
+}
+
+// lockEntry returns a pointer into lock.Providers for the matching entry,
+// or nil if there is none.
+func lockEntry(lock *LockFile, alias, providerType, version, goos, goarch string) *ProviderEntry {
+	for i := range lock.Providers {
+		e := &lock.Providers[i]
+		if e.HasAlias(alias) && e.Type == providerType && e.MatchesVersion(version) && e.OS == goos && e.Arch == goarch {
+			return e
+		}
+	}
+	return nil
+}
+
+// providerInfoFromEntry builds a ProviderInfo from a lockfile entry,
+// including its release details and current install state.
+func providerInfoFromEntry(entry ProviderEntry) ProviderInfo {
+	info := ProviderInfo{
+		Alias:      entry.Alias,
+		Type:       entry.Type,
+		Version:    entry.Version,
+		OS:         entry.OS,
+		Arch:       entry.Arch,
+		Checksum:   entry.Checksum,
+		Size:       entry.Size,
+		Path:       entry.Path,
+		VerifiedAt: entry.VerifiedAt,
+		State:      CheckInstallState(entry),
+		SharedBy:   entry.Aliases,
+
+		ReleaseStatus:  entry.ReleaseStatus,
+		ReleaseMessage: entry.ReleaseMessage,
+	}
+	info.ReleaseURL, info.Asset = githubRelease(entry)
+	return info
+}
+
+// CheckInstallState inspects the binary for a lockfile entry. Unlike
+// ValidateProvider, it never deletes a binary that fails verification.
+func CheckInstallState(entry ProviderEntry) InstallState {
+	fullPath := filepath.Join(nomosdir.ProvidersDir(), entry.Path)
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return InstallStateMissing
+	}
+
+	if entry.Checksum != "" {
+		checksum, err := fileChecksum(fullPath)
+		if err != nil || checksum != normalizeChecksum(entry.Checksum) {
+			return InstallStateModified
+		}
+	}
+
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return InstallStateModified
+	}
+
+	return InstallStateInstalled
+}
+
+// githubRelease returns the release page URL and asset name recorded in a
+// lockfile entry's GitHub source, or empty strings if it has none.
+func githubRelease(entry ProviderEntry) (url, asset string) {
+	gh, ok := entry.Source["github"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	owner, _ := gh["owner"].(string)
+	repo, _ := gh["repo"].(string)
+	tag, _ := gh["release_tag"].(string)
+	asset, _ = gh["asset"].(string)
+	if owner != "" && repo != "" && tag != "" {
+		url = fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s", owner, repo, tag)
+	}
+	return url, asset
+}
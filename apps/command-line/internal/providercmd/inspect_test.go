@@ -0,0 +1,112 @@
+package providercmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestListProviders verifies declared providers are joined with lockfile
+// entries and that install state is reported without modifying binaries.
+func TestListProviders(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	csl := `source:
+  alias: 'configs'
+  type: 'owner/repo'
+  version: '1.0.0'
+
+source:
+  alias: 'secrets'
+  type: 'owner/secrets'
+  version: '2.0.0'
+
+source:
+  alias: 'broken'
+  type: 'owner/broken'
+  version: '0.1.0'
+`
+	if err := os.WriteFile("app.csl", []byte(csl), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	binContent := []byte("fake provider binary")
+	hash := sha256.Sum256(binContent)
+	for _, rel := range []string{"owner/repo/1.0.0/linux-amd64/provider", "owner/broken/0.1.0/linux-amd64/provider"} {
+		binPath := filepath.Join(".nomos", "providers", rel)
+		if err := os.MkdirAll(filepath.Dir(binPath), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(binPath, binContent, 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+			t.Fatal(err)
+		}
+	}
+
+	lock := &LockFile{Providers: []ProviderEntry{
+		{
+			Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64",
+			Checksum: "sha256:" + hex.EncodeToString(hash[:]), Size: int64(len(binContent)),
+			Path:       "owner/repo/1.0.0/linux-amd64/provider",
+			VerifiedAt: "2026-01-01T00:00:00Z",
+			Source: map[string]interface{}{"github": map[string]interface{}{
+				"owner": "owner", "repo": "repo", "release_tag": "v1.0.0", "asset": "repo-linux-amd64",
+			}},
+		},
+		{
+			Alias: "broken", Type: "owner/broken", Version: "0.1.0", OS: "linux", Arch: "amd64",
+			Checksum: "deadbeef", Path: "owner/broken/0.1.0/linux-amd64/provider",
+		},
+		{Alias: "legacy", Type: "owner/legacy", Version: "0.9.0", OS: "linux", Arch: "amd64", Path: "owner/legacy/0.9.0/linux-amd64/provider"},
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "darwin", Arch: "arm64", Path: "owner/repo/1.0.0/darwin-arm64/provider"},
+	}}
+
+	infos, err := ListProviders([]string{"."}, lock, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("ListProviders() error: %v", err)
+	}
+
+	wantStates := map[string]InstallState{
+		"broken":  InstallStateModified,
+		"configs": InstallStateInstalled,
+		"legacy":  InstallStateUnused,
+		"secrets": InstallStateNotLocked,
+	}
+	if len(infos) != len(wantStates) {
+		t.Fatalf("ListProviders() returned %d providers, want %d: %+v", len(infos), len(wantStates), infos)
+	}
+	for i, info := range infos {
+		if i > 0 && infos[i-1].Alias > info.Alias {
+			t.Errorf("ListProviders() not sorted by alias: %q before %q", infos[i-1].Alias, info.Alias)
+		}
+		if info.State != wantStates[info.Alias] {
+			t.Errorf("%s: State = %q, want %q", info.Alias, info.State, wantStates[info.Alias])
+		}
+	}
+
+	configs := infos[1]
+	if configs.ReleaseURL != "https://github.com/owner/repo/releases/tag/v1.0.0" || configs.Asset != "repo-linux-amd64" {
+		t.Errorf("configs release = %q, asset = %q", configs.ReleaseURL, configs.Asset)
+	}
+	if configs.VerifiedAt != "2026-01-01T00:00:00Z" {
+		t.Errorf("configs VerifiedAt = %q", configs.VerifiedAt)
+	}
+
+	// Inspection must not delete a binary that fails verification
+	if _, err := os.Stat(filepath.Join(".nomos", "providers", "owner/broken/0.1.0/linux-amd64/provider")); err != nil {
+		t.Errorf("ListProviders() removed the modified binary: %v", err)
+	}
+}
+
+// TestCheckInstallState_Missing verifies a locked entry without a binary is
+// reported as missing.
+func TestCheckInstallState_Missing(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	entry := ProviderEntry{Path: "owner/repo/1.0.0/linux-amd64/provider", Checksum: "abc"}
+	if got := CheckInstallState(entry); got != InstallStateMissing {
+		t.Errorf("CheckInstallState() = %q, want %q", got, InstallStateMissing)
+	}
+}
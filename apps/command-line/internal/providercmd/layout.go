@@ -0,0 +1,126 @@
+// Package providercmd implements provider management functionality for the nomos CLI.
+package providercmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LockFileVersion is the lockfile schema version written by this CLI.
+//
+// Version 2 installs providers at {owner}/{repo}/{version}/{os}_{arch}/provider
+// (see InstallPath). Lockfiles without a version predate it and pin binaries
+// under {os}-{arch} directories, which MigrateLayout moves.
+const LockFileVersion = 2
+
+// ProviderBinaryName is the file name of an installed provider binary.
+const ProviderBinaryName = "provider"
+
+// InstallPath returns the path of a provider binary relative to the
+// providers directory: {owner}/{repo}/{version}/{os}_{arch}/provider.
+// Scoping by version and platform lets several versions of one provider be
+// installed side by side and pruned one directory at a time.
+func InstallPath(owner, repo, version, goos, goarch string) string {
+	return filepath.Join(owner, repo, version, goos+"_"+goarch, ProviderBinaryName)
+}
+
+// entryInstallPath returns the layout v2 path of a lockfile entry, or ""
+// when the entry's type is not an owner/repo pair.
+func entryInstallPath(entry ProviderEntry) string {
+	owner, repo, err := parseOwnerRepo(entry.Type)
+	if err != nil {
+		return ""
+	}
+	return InstallPath(owner, repo, entry.Version, entry.OS, entry.Arch)
+}
+
+// MigrateLayout moves the binaries of lock's entries that are not yet at
+// their InstallPath under providersDir and rewrites the entries' paths. Entries
+// with absolute paths or non owner/repo types are left alone, as are entries
+// whose binary is missing, which the next install downloads afresh. Once every
+// entry is migrated, lock.Version is set to LockFileVersion.
+//
+// It returns the number of binaries moved. Migration is idempotent, so a
+// failure part way through can be retried.
+func MigrateLayout(lock *LockFile, providersDir string) (int, error) {
+	moved := 0
+	complete := true
+	for i, entry := range lock.Providers {
+		target := entryInstallPath(entry)
+		if target == "" || filepath.IsAbs(entry.Path) || filepath.Clean(entry.Path) == target {
+			continue
+		}
+
+		oldPath := filepath.Join(providersDir, entry.Path)
+		newPath := filepath.Join(providersDir, target)
+		if _, err := os.Stat(oldPath); errors.Is(err, fs.ErrNotExist) {
+			// Nothing to move: point at the new location if a binary is
+			// already there, otherwise leave the entry for reinstallation
+			if _, err := os.Stat(newPath); err == nil {
+				lock.Providers[i].Path = target
+			} else {
+				complete = false
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(newPath), 0750); err != nil {
+			return moved, fmt.Errorf("failed to migrate provider %s: %w", entry.Alias, err)
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return moved, fmt.Errorf("failed to migrate provider %s: %w", entry.Alias, err)
+		}
+		removeEmptyDirs(filepath.Dir(oldPath), providersDir)
+		lock.Providers[i].Path = target
+		moved++
+	}
+
+	if complete {
+		lock.Version = LockFileVersion
+	}
+	return moved, nil
+}
+
+// removeEmptyDirs removes dir and its parents up to, but not including,
+// root for as long as they are empty.
+func removeEmptyDirs(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// migrateLockFile migrates the project's lockfile to the current layout,
+// writing it back only when something changed. A missing lockfile has
+// nothing to migrate.
+func migrateLockFile(providersDir string) error {
+	lock, err := ReadLockFile()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if lock.Version >= LockFileVersion {
+		return nil
+	}
+
+	before := append([]ProviderEntry(nil), lock.Providers...)
+	moved, err := MigrateLayout(lock, providersDir)
+	if err != nil {
+		return err
+	}
+	if moved > 0 {
+		fmt.Fprintf(os.Stderr, "Migrated %d provider(s) to the version %d install layout\n", moved, LockFileVersion)
+	}
+	if lock.Version < LockFileVersion && sameEntries(before, lock.Providers) {
+		return nil
+	}
+	return WriteLockFile(*lock)
+}
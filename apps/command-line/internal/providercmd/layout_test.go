@@ -0,0 +1,123 @@
+package providercmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInstallPath tests the version- and platform-scoped install layout.
+func TestInstallPath(t *testing.T) {
+	got := InstallPath("owner", "repo", "1.2.0", "darwin", "arm64")
+	want := filepath.Join("owner", "repo", "1.2.0", "darwin_arm64", "provider")
+	if got != want {
+		t.Errorf("InstallPath() = %q, want %q", got, want)
+	}
+}
+
+// TestMigrateLayout tests moving binaries from the legacy layout and
+// rewriting their lockfile entries.
+func TestMigrateLayout(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join("owner", "repo", "1.0.0", "linux-amd64", "provider")
+	if err := createTestFile(filepath.Join(dir, legacy)); err != nil {
+		t.Fatal(err)
+	}
+	current := InstallPath("owner", "other", "2.0.0", "linux", "amd64")
+	if err := createTestFile(filepath.Join(dir, current)); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := &LockFile{Providers: []ProviderEntry{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: legacy},
+		{Alias: "other", Type: "owner/other", Version: "2.0.0", OS: "linux", Arch: "amd64", Path: current},
+		{Alias: "local", Type: "local", Version: "0.1.0", OS: "linux", Arch: "amd64", Path: "local/provider"},
+	}}
+
+	moved, err := MigrateLayout(lock, dir)
+	if err != nil {
+		t.Fatalf("MigrateLayout() error = %v", err)
+	}
+	if moved != 1 {
+		t.Errorf("moved = %d, want 1", moved)
+	}
+	if lock.Version != LockFileVersion {
+		t.Errorf("Version = %d, want %d", lock.Version, LockFileVersion)
+	}
+
+	want := InstallPath("owner", "repo", "1.0.0", "linux", "amd64")
+	if lock.Providers[0].Path != want {
+		t.Errorf("migrated path = %q, want %q", lock.Providers[0].Path, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, want)); err != nil {
+		t.Errorf("binary not at new path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "owner", "repo", "1.0.0", "linux-amd64")); !os.IsNotExist(err) {
+		t.Errorf("empty legacy directory left behind (stat error = %v)", err)
+	}
+	if lock.Providers[1].Path != current || lock.Providers[2].Path != "local/provider" {
+		t.Errorf("unexpected changes to other entries: %+v", lock.Providers[1:])
+	}
+
+	// Migration is idempotent
+	if moved, err := MigrateLayout(lock, dir); err != nil || moved != 0 {
+		t.Errorf("second MigrateLayout() = %d, %v; want 0, nil", moved, err)
+	}
+}
+
+// TestMigrateLayout_MissingBinary tests that entries without a binary are
+// left for reinstallation and keep the lockfile at its old version.
+func TestMigrateLayout_MissingBinary(t *testing.T) {
+	legacy := filepath.Join("owner", "repo", "1.0.0", "linux-amd64", "provider")
+	lock := &LockFile{Providers: []ProviderEntry{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: legacy},
+	}}
+
+	moved, err := MigrateLayout(lock, t.TempDir())
+	if err != nil || moved != 0 {
+		t.Fatalf("MigrateLayout() = %d, %v; want 0, nil", moved, err)
+	}
+	if lock.Version != 0 || lock.Providers[0].Path != legacy {
+		t.Errorf("lock = %+v, want it unchanged", lock)
+	}
+}
+
+// TestMigrateLockFile tests migrating the project lockfile in place.
+func TestMigrateLockFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	legacy := filepath.Join("owner", "repo", "1.0.0", "linux-amd64", "provider")
+	if err := createTestFile(filepath.Join(".nomos", "providers", legacy)); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteLockFile(LockFile{Providers: []ProviderEntry{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: legacy},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateLockFile(filepath.Join(".nomos", "providers")); err != nil {
+		t.Fatalf("migrateLockFile() error = %v", err)
+	}
+	lock, err := ReadLockFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Version != LockFileVersion || lock.Providers[0].Path != InstallPath("owner", "repo", "1.0.0", "linux", "amd64") {
+		t.Errorf("lock = %+v, want migrated entry at version %d", lock, LockFileVersion)
+	}
+}
+
+// TestReadLockFile_NewerVersion tests that lockfiles from a newer nomos
+// are rejected instead of misread.
+func TestReadLockFile_NewerVersion(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := WriteLockFile(LockFile{Version: LockFileVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadLockFile()
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("ReadLockFile() error = %v, want newer version error", err)
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
 )
 
 // ReadLockFile reads the existing lockfile from .nomos/providers.lock.json.
@@ -15,7 +19,7 @@ import (
 // This differs from the previous implementation which returned nil on error;
 // it now returns explicit errors for better error handling.
 func ReadLockFile() (*LockFile, error) {
-	lockPath := filepath.Join(".nomos", "providers.lock.json")
+	lockPath := nomosdir.LockfilePath
 
 	//nolint:gosec // G304: Path is hardcoded to .nomos/providers.lock.json, safe
 	data, err := os.ReadFile(lockPath)
@@ -27,6 +31,9 @@ func ReadLockFile() (*LockFile, error) {
 	if err := json.Unmarshal(data, &lock); err != nil {
 		return nil, fmt.Errorf("failed to parse lockfile JSON: %w", err)
 	}
+	if lock.Version > LockFileVersion {
+		return nil, fmt.Errorf("lockfile version %d is newer than this nomos supports (%d); upgrade nomos", lock.Version, LockFileVersion)
+	}
 
 	return &lock, nil
 }
@@ -37,7 +44,7 @@ func ReadLockFile() (*LockFile, error) {
 // The timestamp field is automatically set to the current time in RFC3339 format
 // if not already set.
 func WriteLockFile(lock LockFile) error {
-	lockPath := filepath.Join(".nomos", "providers.lock.json")
+	lockPath := nomosdir.LockfilePath
 
 	// Ensure directory exists
 	lockDir := filepath.Dir(lockPath)
@@ -56,8 +63,16 @@ func WriteLockFile(lock LockFile) error {
 		return fmt.Errorf("failed to marshal lockfile: %w", err)
 	}
 
+	return writeFileAtomic(lockPath, data)
+}
+
+// writeFileAtomic writes data to path via a temp file in the same directory
+// and a rename, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
 	// Write to temp file in same directory for atomic rename
-	tmpFile, err := os.CreateTemp(lockDir, ".providers.lock.*.tmp")
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -88,7 +103,7 @@ func WriteLockFile(lock LockFile) error {
 	tmpFile = nil // Prevent cleanup in defer
 
 	// Atomic rename
-	if err := os.Rename(tmpPath, lockPath); err != nil {
+	if err := os.Rename(tmpPath, path); err != nil {
 		_ = os.Remove(tmpPath) // Clean up on rename failure
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
@@ -98,8 +113,11 @@ func WriteLockFile(lock LockFile) error {
 
 // MergeLockFiles merges an existing lockfile with new provider entries.
 // It preserves all existing entries and updates them with matching new entries
-// based on alias, type, OS, and arch. New entries that don't match existing ones
-// are appended.
+// based on alias, type, version, OS, and arch, so an alias pinned at several
+// versions keeps one entry per version. An existing entry matches when it serves
+// any alias of the new entry, so entries folded into a shared entry (see
+// ProviderEntry.Aliases) are replaced by it. New entries that don't match
+// existing ones are appended.
 //
 // This function never removes entries automatically - manual cleanup must be
 // performed separately if needed.
@@ -107,29 +125,39 @@ func WriteLockFile(lock LockFile) error {
 // The returned lockfile has a fresh timestamp set.
 func MergeLockFiles(existing *LockFile, newEntries []ProviderEntry) LockFile {
 	merged := LockFile{
+		Version:   LockFileVersion,
 		Timestamp: timeNowRFC3339(),
 		Providers: []ProviderEntry{},
 	}
 
-	// Add all existing entries
+	// Add all existing entries, which keep a lockfile that is not fully
+	// migrated at its version
 	if existing != nil {
+		merged.Version = existing.Version
 		merged.Providers = append(merged.Providers, existing.Providers...)
 	}
 
 	// Add or update with new entries
 	for _, newEntry := range newEntries {
 		found := false
-		for i, existingEntry := range merged.Providers {
-			if existingEntry.Alias == newEntry.Alias &&
-				existingEntry.Type == newEntry.Type &&
-				existingEntry.OS == newEntry.OS &&
-				existingEntry.Arch == newEntry.Arch {
+		kept := merged.Providers[:0]
+		for _, existingEntry := range merged.Providers {
+			if !sharesAlias(existingEntry, newEntry) ||
+				existingEntry.Type != newEntry.Type ||
+				existingEntry.Version != newEntry.Version ||
+				existingEntry.OS != newEntry.OS ||
+				existingEntry.Arch != newEntry.Arch {
+				kept = append(kept, existingEntry)
+				continue
+			}
+			if !found {
 				// Update existing entry
-				merged.Providers[i] = newEntry
+				kept = append(kept, newEntry)
 				found = true
-				break
 			}
+			// Further matches are now served by newEntry
 		}
+		merged.Providers = kept
 		if !found {
 			// Append new entry
 			merged.Providers = append(merged.Providers, newEntry)
@@ -138,3 +166,63 @@ func MergeLockFiles(existing *LockFile, newEntries []ProviderEntry) LockFile {
 
 	return merged
 }
+
+// sameEntries reports whether two lists of lockfile entries pin the same
+// providers, ignoring when each binary was last verified.
+func sameEntries(a, b []ProviderEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.VerifiedAt, y.VerifiedAt = "", ""
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneUndeclaredVersions removes aliases from entries whose type and version
+// they are no longer declared at, dropping entries left without aliases.
+// Aliases that are not declared at all are kept, as the providers may be
+// discovered from other paths.
+func pruneUndeclaredVersions(entries []ProviderEntry, providers []DiscoveredProvider) []ProviderEntry {
+	declared := make(map[string][]DiscoveredProvider) // alias → declarations
+	for _, p := range providers {
+		declared[p.Alias] = append(declared[p.Alias], p)
+	}
+
+	kept := make([]ProviderEntry, 0, len(entries))
+	for _, entry := range entries {
+		aliases := make([]string, 0, len(entry.AliasList()))
+		for _, alias := range entry.AliasList() {
+			decls, ok := declared[alias]
+			if !ok || slices.ContainsFunc(decls, func(p DiscoveredProvider) bool {
+				return p.Type == entry.Type && entry.MatchesVersion(p.Version)
+			}) {
+				aliases = append(aliases, alias)
+			}
+		}
+		if len(aliases) == 0 {
+			continue
+		}
+		entry.Alias = aliases[0]
+		entry.Aliases = nil
+		if len(aliases) > 1 {
+			entry.Aliases = aliases
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// sharesAlias reports whether two lockfile entries serve a common alias.
+func sharesAlias(a, b ProviderEntry) bool {
+	for _, alias := range b.AliasList() {
+		if a.HasAlias(alias) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -469,6 +470,51 @@ func TestMergeLockFiles(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "shared entry replaces entries for its aliases",
+			existing: &LockFile{
+				Timestamp: "2026-01-10T10:00:00Z",
+				Providers: []ProviderEntry{
+					{Alias: "team1/configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: "path1"},
+					{Alias: "other", Type: "owner/other", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: "path2"},
+					{Alias: "team2/configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: "path1"},
+				},
+			},
+			newEntries: []ProviderEntry{
+				{
+					Alias: "team1/configs", Aliases: []string{"team1/configs", "team2/configs"},
+					Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: "path1",
+				},
+			},
+			wantCount: 2,
+			validate: func(t *testing.T, merged LockFile) {
+				t.Helper()
+				if !merged.Providers[0].HasAlias("team2/configs") {
+					t.Errorf("first entry = %+v, want shared entry in place of team1/configs", merged.Providers[0])
+				}
+				if merged.Providers[1].Alias != "other" {
+					t.Errorf("second entry alias = %q, want %q", merged.Providers[1].Alias, "other")
+				}
+			},
+		},
+		{
+			name: "alias keeps one entry per version",
+			existing: &LockFile{
+				Providers: []ProviderEntry{
+					{Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: "linux", Arch: "amd64", Path: "path1"},
+				},
+			},
+			newEntries: []ProviderEntry{
+				{Alias: "configs", Type: "owner/repo", Version: "2.0.0", OS: "linux", Arch: "amd64", Path: "path2"},
+			},
+			wantCount: 2,
+			validate: func(t *testing.T, merged LockFile) {
+				t.Helper()
+				if merged.Providers[0].Version != "1.0.0" || merged.Providers[1].Version != "2.0.0" {
+					t.Errorf("versions = %s, %s; want 1.0.0, 2.0.0", merged.Providers[0].Version, merged.Providers[1].Version)
+				}
+			},
+		},
 		{
 			name: "merge sets fresh timestamp",
 			existing: &LockFile{
@@ -521,3 +567,39 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// TestPruneUndeclaredVersions tests dropping lockfile entries for versions
+// that are no longer declared.
+func TestPruneUndeclaredVersions(t *testing.T) {
+	entries := []ProviderEntry{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", Path: "path1"},
+		{Alias: "configs", Aliases: []string{"configs", "shared"}, Type: "owner/repo", Version: "2.0.0", Path: "path2"},
+		{Alias: "other", Type: "owner/other", Version: "1.0.0", Path: "path3"},
+		{Alias: "latest", Type: "owner/repo", Version: "3.0.0", Channel: "latest", Path: "path4"},
+	}
+	providers := []DiscoveredProvider{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0"},
+		{Alias: "shared", Type: "owner/repo", Version: "2.0.0"},
+		{Alias: "latest", Type: "owner/repo", Version: "latest"},
+	}
+
+	got := pruneUndeclaredVersions(entries, providers)
+
+	want := []ProviderEntry{
+		{Alias: "configs", Type: "owner/repo", Version: "1.0.0", Path: "path1"},
+		{Alias: "shared", Type: "owner/repo", Version: "2.0.0", Path: "path2"},
+		{Alias: "other", Type: "owner/other", Version: "1.0.0", Path: "path3"},
+		{Alias: "latest", Type: "owner/repo", Version: "3.0.0", Channel: "latest", Path: "path4"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pruneUndeclaredVersions() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// An alias moved to a new version loses its old entry
+	got = pruneUndeclaredVersions(got, []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "2.0.0"}})
+	for _, entry := range got {
+		if entry.HasAlias("configs") {
+			t.Errorf("entry %+v still serves configs at an undeclared version", entry)
+		}
+	}
+}
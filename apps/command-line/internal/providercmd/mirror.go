@@ -0,0 +1,349 @@
+// Package providercmd implements provider management functionality for the nomos CLI.
+package providercmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+)
+
+// MirrorManifestName is the manifest file at the root of a provider mirror.
+const MirrorManifestName = "manifest.json"
+
+// Platform is a target operating system and architecture pair.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// String returns the platform in os/arch form (e.g. "darwin/arm64").
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// ParsePlatform parses an os/arch string such as "linux/amd64".
+func ParsePlatform(s string) (Platform, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return Platform{}, fmt.Errorf("invalid platform %q: expected os/arch (e.g. linux/amd64)", s)
+	}
+	return Platform{OS: goos, Arch: goarch}, nil
+}
+
+// MirrorManifest lists the provider binaries stored in a mirror directory.
+// It uses the lockfile entry format; each entry's Path is relative to the
+// mirror directory, which mirrors the .nomos/providers layout.
+type MirrorManifest struct {
+	// Timestamp records when the manifest was last written (RFC3339 format).
+	Timestamp string          `json:"timestamp,omitempty"`
+	Providers []ProviderEntry `json:"providers"`
+}
+
+// MirrorOptions configures MirrorProviders.
+type MirrorOptions struct {
+	// Paths are the input .csl files to scan for provider declarations
+	Paths []string
+
+	// Dir is the mirror directory to populate
+	Dir string
+
+	// Platforms are the OS/arch pairs to fetch (default: host platform)
+	Platforms []Platform
+
+	// Force re-downloads binaries already present in the mirror
+	Force bool
+
+	// Timeout is the timeout per provider download
+	Timeout time.Duration
+
+	// CredentialHelper is the git credential helper command configured in
+	// the project file, consulted for GitHub tokens
+	CredentialHelper string
+
+	// AllowLatest permits providers declared with version 'latest'
+	AllowLatest bool
+
+	// AllowPrerelease permits providers declared with version 'prerelease'
+	AllowPrerelease bool
+
+	// AllowYanked mirrors releases their authors have yanked
+	AllowYanked bool
+}
+
+// MirrorResult reports what MirrorProviders did for each provider and platform.
+type MirrorResult struct {
+	// Results has one entry per provider binary and platform
+	Results []ProviderResult
+
+	// Summary aggregates Results
+	Summary ProviderSummary
+}
+
+// MirrorProviders downloads the binaries of the providers declared under
+// opts.Paths for every platform in opts.Platforms into opts.Dir and records
+// them in the mirror's manifest. Binaries already in the mirror with a
+// matching checksum are kept unless opts.Force is set.
+//
+// The manifest is written even when a download fails, so a rerun only
+// fetches what is still missing.
+func MirrorProviders(opts MirrorOptions) (*MirrorResult, error) {
+	if len(opts.Paths) == 0 {
+		return nil, errors.New("no input paths provided")
+	}
+	if opts.Dir == "" {
+		return nil, errors.New("no mirror directory provided")
+	}
+	if len(opts.Platforms) == 0 {
+		opts.Platforms = []Platform{{OS: runtime.GOOS, Arch: runtime.GOARCH}}
+	}
+
+	providers, err := DiscoverProviders(opts.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover providers: %w", err)
+	}
+	if err := validateProviderVersions(providers); err != nil {
+		return nil, err
+	}
+	if err := detectVersionConflicts(providers); err != nil {
+		return nil, err
+	}
+	if err := validateChannels(providers, opts.AllowLatest, opts.AllowPrerelease); err != nil {
+		return nil, err
+	}
+
+	manifest, err := ReadMirrorManifest(opts.Dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		manifest = &MirrorManifest{}
+	}
+
+	tokens := downloader.DefaultTokenSource(opts.CredentialHelper)
+	result := &MirrorResult{}
+	var downloadErr error
+	for _, group := range groupSharedProviders(providers) {
+		p := group[0]
+		aliases := providerAliases(group)
+		for _, platform := range opts.Platforms {
+			providerResult := ProviderResult{
+				Alias:   p.Alias,
+				Type:    p.Type,
+				Version: p.Version,
+				OS:      platform.OS,
+				Arch:    platform.Arch,
+			}
+			if len(aliases) > 1 {
+				providerResult.Aliases = aliases
+			}
+
+			if existing := manifest.find(p.Type, p.Version, platform); existing != nil && !opts.Force {
+				if verifyChecksum(filepath.Join(opts.Dir, existing.Path), existing.Checksum) == nil {
+					providerResult.Status = ProviderStatusSkipped
+					providerResult.Path = existing.Path
+					providerResult.Size = existing.Size
+					result.Results = append(result.Results, providerResult)
+					*existing, _ = withAliases(*existing, aliases)
+					continue
+				}
+			}
+
+			downloadOpts := ProviderOptions{
+				OS:          platform.OS,
+				Arch:        platform.Arch,
+				Timeout:     opts.Timeout,
+				Tokens:      tokens,
+				AllowYanked: opts.AllowYanked,
+			}
+			entry, err := downloadProviderTo(p, downloadOpts, opts.Dir)
+			if err != nil {
+				providerResult.Status = ProviderStatusFailed
+				providerResult.Error = fmt.Errorf("failed to mirror provider %q for %s: %w", p.Alias, platform, err)
+				result.Results = append(result.Results, providerResult)
+				downloadErr = providerResult.Error
+				break
+			}
+
+			providerResult.Status = ProviderStatusInstalled
+			providerResult.Path = entry.Path
+			providerResult.Size = entry.Size
+			result.Results = append(result.Results, providerResult)
+
+			entry, _ = withAliases(entry, aliases)
+			manifest.put(entry)
+		}
+		if downloadErr != nil {
+			break
+		}
+	}
+
+	result.Summary = *buildSummary(result.Results)
+	if err := WriteMirrorManifest(opts.Dir, *manifest); err != nil {
+		return result, err
+	}
+	return result, downloadErr
+}
+
+// ReadMirrorManifest reads the manifest of the mirror at dir.
+func ReadMirrorManifest(dir string) (*MirrorManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MirrorManifestName)) //nolint:gosec // G304: Mirror directory is chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror manifest: %w", err)
+	}
+
+	var manifest MirrorManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror manifest JSON: %w", err)
+	}
+	return &manifest, nil
+}
+
+// WriteMirrorManifest writes the manifest of the mirror at dir atomically,
+// setting a fresh timestamp.
+func WriteMirrorManifest(dir string, manifest MirrorManifest) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	manifest.Timestamp = timeNowRFC3339()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror manifest: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(dir, MirrorManifestName), data)
+}
+
+// find returns the manifest entry for the given type, version, and platform,
+// or nil if there is none.
+func (m *MirrorManifest) find(providerType, version string, platform Platform) *ProviderEntry {
+	for i := range m.Providers {
+		e := &m.Providers[i]
+		if e.Type == providerType && e.MatchesVersion(version) && e.OS == platform.OS && e.Arch == platform.Arch {
+			return e
+		}
+	}
+	return nil
+}
+
+// put adds entry to the manifest, replacing any entry for the same type,
+// platform, and version or release channel.
+func (m *MirrorManifest) put(entry ProviderEntry) {
+	for i, e := range m.Providers {
+		sameRelease := e.Version == entry.Version || (entry.Channel != "" && e.Channel == entry.Channel)
+		if e.Type == entry.Type && e.OS == entry.OS && e.Arch == entry.Arch && sameRelease {
+			m.Providers[i] = entry
+			return
+		}
+	}
+	m.Providers = append(m.Providers, entry)
+}
+
+// installFromMirror copies the binary for p and opts.OS/opts.Arch from
+// opts.MirrorDir into the providers directory after verifying its checksum.
+// The mirror manifest must list the binary; nothing is fetched from the
+// network.
+func installFromMirror(p DiscoveredProvider, opts ProviderOptions) (ProviderEntry, error) {
+	manifest, err := ReadMirrorManifest(opts.MirrorDir)
+	if err != nil {
+		return ProviderEntry{}, err
+	}
+
+	platform := Platform{OS: opts.OS, Arch: opts.Arch}
+	found := manifest.find(p.Type, p.Version, platform)
+	if found != nil && p.Digest != "" && found.Digest != p.Digest {
+		return ProviderEntry{}, fmt.Errorf("%w: mirror %s holds %s@%s with digest %q, declaration pins %s",
+			ErrChecksumMismatch, opts.MirrorDir, p.Type, p.Version, found.Digest, p.Digest)
+	}
+	if found == nil {
+		return ProviderEntry{}, fmt.Errorf("%s@%s for %s not found in mirror %s", p.Type, p.Version, platform, opts.MirrorDir)
+	}
+	if p.Checksum != "" && normalizeChecksum(found.Checksum) != normalizeChecksum(p.Checksum) {
+		return ProviderEntry{}, fmt.Errorf("%w: mirror %s holds %s@%s with checksum %q, declaration pins %s",
+			ErrChecksumMismatch, opts.MirrorDir, p.Type, p.Version, found.Checksum, p.Checksum)
+	}
+	entry := *found
+	if entry.ReleaseStatus == string(downloader.ReleaseStatusYanked) && !opts.AllowYanked {
+		return ProviderEntry{}, compiler.WithHint(fmt.Errorf("%w: %s@%s in mirror %s",
+			downloader.ErrReleaseYanked, p.Type, entry.Version, opts.MirrorDir), allowYankedHint)
+	}
+	warnReleaseStatus(p, &downloader.AssetInfo{
+		Version:       entry.Version,
+		Status:        downloader.ReleaseStatus(entry.ReleaseStatus),
+		StatusMessage: entry.ReleaseMessage,
+	})
+
+	fmt.Fprintf(os.Stderr, "Installing %s@%s for %s-%s from mirror...\n", p.Type, p.Version, opts.OS, opts.Arch)
+
+	src := filepath.Join(opts.MirrorDir, entry.Path)
+	if err := verifyChecksum(src, entry.Checksum); err != nil {
+		return ProviderEntry{}, fmt.Errorf("mirrored binary %s: %w", src, err)
+	}
+	if err := copyExecutable(src, filepath.Join(nomosdir.ProvidersDir(), entry.Path)); err != nil {
+		return ProviderEntry{}, fmt.Errorf("failed to install mirrored binary: %w", err)
+	}
+
+	entry.Alias = p.Alias
+	entry.Aliases = nil
+	entry.VerifiedAt = timeNowRFC3339()
+	return entry, nil
+}
+
+// verifyChecksum checks that the file at path has the given checksum.
+func verifyChecksum(path, checksum string) error {
+	actual, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if normalizeChecksum(actual) != normalizeChecksum(checksum) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", checksum, actual)
+	}
+	return nil
+}
+
+// copyExecutable copies src to dst via a temp file and rename, creating
+// dst's directory and marking the result executable.
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // G304: Path comes from the mirror manifest
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".provider.*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := io.Copy(tmp, in); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil { //nolint:gosec // G302: Provider binaries must be executable
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
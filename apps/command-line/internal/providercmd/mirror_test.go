@@ -0,0 +1,213 @@
+package providercmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+)
+
+// TestParsePlatform tests os/arch parsing.
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Platform
+		wantErr bool
+	}{
+		{input: "linux/amd64", want: Platform{OS: "linux", Arch: "amd64"}},
+		{input: "darwin/arm64", want: Platform{OS: "darwin", Arch: "arm64"}},
+		{input: "linux", wantErr: true},
+		{input: "/amd64", wantErr: true},
+		{input: "linux/", wantErr: true},
+		{input: "linux/amd64/v2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePlatform(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeMirror creates a mirror at dir holding one binary per platform for
+// owner/repo@1.0.0 and returns the binary content.
+func writeMirror(t *testing.T, dir string, platforms ...Platform) []byte {
+	t.Helper()
+	content := []byte("mirrored provider binary")
+	hash := sha256.Sum256(content)
+
+	var manifest MirrorManifest
+	for _, platform := range platforms {
+		rel := filepath.Join("owner", "repo", "1.0.0", platform.OS+"-"+platform.Arch, "provider")
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, rel), content, 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+			t.Fatal(err)
+		}
+		manifest.Providers = append(manifest.Providers, ProviderEntry{
+			Alias: "configs", Type: "owner/repo", Version: "1.0.0", OS: platform.OS, Arch: platform.Arch,
+			Checksum: "sha256:" + hex.EncodeToString(hash[:]), Size: int64(len(content)), Path: rel,
+		})
+	}
+	if err := WriteMirrorManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+const mirrorTestCSL = `source:
+  alias: 'configs'
+  type: 'owner/repo'
+  version: '1.0.0'
+`
+
+// TestMirrorProviders_KeepsVerifiedBinaries verifies that binaries already
+// in the mirror are reused without network access.
+func TestMirrorProviders_KeepsVerifiedBinaries(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("app.csl", []byte(mirrorTestCSL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	platforms := []Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}}
+	writeMirror(t, "mirror", platforms...)
+
+	result, err := MirrorProviders(MirrorOptions{Paths: []string{"app.csl"}, Dir: "mirror", Platforms: platforms})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Summary.Total != 2 || result.Summary.Cached != 2 {
+		t.Errorf("summary = %+v, want 2 cached of 2", result.Summary)
+	}
+
+	manifest, err := ReadMirrorManifest("mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Providers) != 2 {
+		t.Errorf("manifest has %d entries, want 2", len(manifest.Providers))
+	}
+}
+
+// TestDownloadProviders_FromMirror verifies that builds install binaries
+// from a mirror and record them in the lockfile.
+func TestDownloadProviders_FromMirror(t *testing.T) {
+	t.Chdir(t.TempDir())
+	content := writeMirror(t, "mirror", Platform{OS: "darwin", Arch: "arm64"})
+
+	providers := []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "1.0.0"}}
+	opts := ProviderOptions{OS: "darwin", Arch: "arm64", MirrorDir: "mirror"}
+	results, entries, err := DownloadProviders(providers, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != ProviderStatusInstalled {
+		t.Fatalf("results = %+v, want one installed", results)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if err := ValidateProvider(entries[0]); err != nil {
+		t.Errorf("installed binary failed validation: %v", err)
+	}
+	installed, err := os.ReadFile(filepath.Join(".nomos", "providers", entries[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(installed) != string(content) {
+		t.Error("installed binary differs from mirrored binary")
+	}
+
+	// Platforms missing from the mirror fail without falling back to GitHub
+	opts.OS, opts.Arch = "linux", "amd64"
+	if _, _, err := DownloadProviders(providers, opts); err == nil {
+		t.Error("expected error for platform missing from mirror")
+	}
+}
+
+// TestDownloadProviders_FromMirrorChecksumMismatch verifies that a tampered
+// mirrored binary is rejected.
+func TestDownloadProviders_FromMirrorChecksumMismatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+	rel := filepath.Join("mirror", "owner", "repo", "1.0.0", "linux-amd64", "provider")
+	if err := os.WriteFile(rel, []byte("tampered"), 0700); err != nil { //nolint:gosec // G306: Test binary needs execute permission
+		t.Fatal(err)
+	}
+
+	providers := []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "1.0.0"}}
+	_, _, err := DownloadProviders(providers, ProviderOptions{OS: "linux", Arch: "amd64", MirrorDir: "mirror"})
+	if err == nil {
+		t.Fatal("expected checksum error")
+	}
+	if _, statErr := os.Stat(filepath.Join(".nomos", "providers", "owner")); !os.IsNotExist(statErr) {
+		t.Error("tampered binary should not be installed")
+	}
+}
+
+// TestDownloadProviders_FromMirrorPinnedChecksum verifies that a checksum
+// pinned in the declaration must match the mirrored binary.
+func TestDownloadProviders_FromMirrorPinnedChecksum(t *testing.T) {
+	t.Chdir(t.TempDir())
+	content := writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+	hash := sha256.Sum256(content)
+	opts := ProviderOptions{OS: "linux", Arch: "amd64", MirrorDir: "mirror"}
+
+	providers := []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "1.0.0", Checksum: "sha256:" + strings.Repeat("0", 64)}}
+	if _, _, err := DownloadProviders(providers, opts); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("DownloadProviders() error = %v, want ErrChecksumMismatch", err)
+	}
+
+	providers[0].Checksum = "sha256:" + hex.EncodeToString(hash[:])
+	if _, _, err := DownloadProviders(providers, opts); err != nil {
+		t.Errorf("DownloadProviders() with the binary's checksum = %v, want nil", err)
+	}
+}
+
+// TestDownloadProviders_FromMirrorYanked verifies that yanked mirrored
+// releases are refused unless AllowYanked is set.
+func TestDownloadProviders_FromMirrorYanked(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+	manifest, err := ReadMirrorManifest("mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest.Providers[0].ReleaseStatus = string(downloader.ReleaseStatusYanked)
+	manifest.Providers[0].ReleaseMessage = "corrupts state"
+	if err := WriteMirrorManifest("mirror", *manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := []DiscoveredProvider{{Alias: "configs", Type: "owner/repo", Version: "1.0.0"}}
+	opts := ProviderOptions{OS: "linux", Arch: "amd64", MirrorDir: "mirror"}
+	if _, _, err := DownloadProviders(providers, opts); !errors.Is(err, downloader.ErrReleaseYanked) {
+		t.Fatalf("expected ErrReleaseYanked, got %v", err)
+	}
+
+	opts.AllowYanked = true
+	_, entries, err := DownloadProviders(providers, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ReleaseStatus != string(downloader.ReleaseStatusYanked) {
+		t.Errorf("entries = %+v, want yanked status recorded", entries)
+	}
+}
@@ -3,9 +3,10 @@ package providercmd
 
 import (
 	"fmt"
-	"os"
 	"runtime"
 	"time"
+
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
 )
 
 // ProviderOptions holds configuration for provider management during build.
@@ -34,8 +35,33 @@ type ProviderOptions struct {
 	// AllowMissing allows compilation to continue with missing providers
 	AllowMissing bool
 
-	// GitHubToken is the GitHub personal access token for API requests
-	GitHubToken string
+	// Tokens supplies GitHub tokens for API requests and downloads
+	Tokens downloader.TokenSource
+
+	// MirrorDir installs providers from a directory written by
+	// MirrorProviders instead of downloading from GitHub
+	MirrorDir string
+
+	// AllowLatest permits providers declared with version 'latest'
+	AllowLatest bool
+
+	// AllowPrerelease permits providers declared with version 'prerelease'
+	AllowPrerelease bool
+
+	// AllowYanked installs releases their authors have yanked
+	AllowYanked bool
+
+	// ArchFallbacks lists architectures to install when a release has no
+	// asset for the target (see downloader.ClientOptions.ArchFallbacks)
+	ArchFallbacks map[string][]string
+
+	// Bandwidth limits the download rate and total bytes of every provider
+	// download in the build; nil means unlimited
+	Bandwidth *downloader.Bandwidth
+
+	// Trusted, when set, lists the providers .csl files may declare and
+	// the signers their binaries must be attested by
+	Trusted []TrustedProvider
 }
 
 // BuildFlags represents the flags from the build command.
@@ -57,17 +83,62 @@ type BuildFlags struct {
 
 	// AllowMissingProvider allows compilation to continue with missing providers
 	AllowMissingProvider bool
+
+	// ProviderMirror is a mirror directory to install providers from
+	ProviderMirror string
+
+	// AllowLatest permits providers declared with version 'latest'
+	AllowLatest bool
+
+	// AllowPrerelease permits providers declared with version 'prerelease'
+	AllowPrerelease bool
+
+	// AllowYanked installs releases their authors have yanked
+	AllowYanked bool
+
+	// AllowArchFallback installs darwin/amd64 providers on Apple Silicon
+	// when a release has no darwin/arm64 asset
+	AllowArchFallback bool
+
+	// MaxDownloadRate limits provider downloads to this many bytes per
+	// second in total (0 = unlimited)
+	MaxDownloadRate int64
+
+	// MaxDownloadBytes fails the build once provider downloads exceed this
+	// many bytes (0 = unlimited)
+	MaxDownloadBytes int64
+
+	// CredentialHelper is the git credential helper command configured in
+	// the project file, consulted for GitHub tokens
+	CredentialHelper string
+
+	// TrustedProviders is the trusted_providers list of the project file
+	TrustedProviders []TrustedProvider
 }
 
 // NewProviderOptionsFromBuildFlags creates ProviderOptions from build command flags.
 // Returns an error if the timeout duration cannot be parsed.
 func NewProviderOptionsFromBuildFlags(flags BuildFlags) (ProviderOptions, error) {
 	opts := ProviderOptions{
-		Paths:         []string{flags.Path},
-		Force:         flags.ForceProviders,
-		DryRun:        flags.DryRun,
-		MaxConcurrent: flags.MaxConcurrentProviders,
-		AllowMissing:  flags.AllowMissingProvider,
+		Paths:           []string{flags.Path},
+		Force:           flags.ForceProviders,
+		DryRun:          flags.DryRun,
+		MaxConcurrent:   flags.MaxConcurrentProviders,
+		AllowMissing:    flags.AllowMissingProvider,
+		MirrorDir:       flags.ProviderMirror,
+		AllowLatest:     flags.AllowLatest,
+		AllowPrerelease: flags.AllowPrerelease,
+		AllowYanked:     flags.AllowYanked,
+		Trusted:         flags.TrustedProviders,
+	}
+	if flags.AllowArchFallback {
+		opts.ArchFallbacks = downloader.RosettaArchFallbacks()
+	}
+	if flags.MaxDownloadRate < 0 || flags.MaxDownloadBytes < 0 {
+		return ProviderOptions{}, fmt.Errorf("download limits must be non-negative (got rate %d, bytes %d)", flags.MaxDownloadRate, flags.MaxDownloadBytes)
+	}
+	if flags.MaxDownloadRate > 0 || flags.MaxDownloadBytes > 0 {
+		opts.Bandwidth = downloader.NewBandwidth(flags.MaxDownloadRate, flags.MaxDownloadBytes)
 	}
 
 	// Set defaults for OS/Arch
@@ -83,8 +154,8 @@ func NewProviderOptionsFromBuildFlags(flags BuildFlags) (ProviderOptions, error)
 		opts.Timeout = timeout
 	}
 
-	// Get GitHub token from environment
-	opts.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	// Read GitHub tokens from the environment and credential stores
+	opts.Tokens = downloader.DefaultTokenSource(flags.CredentialHelper)
 
 	return opts, nil
 }
@@ -0,0 +1,305 @@
+// Package providercmd implements provider management functionality for the nomos CLI.
+package providercmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+)
+
+// VersionDeclaration is one source declaration of a provider alias.
+type VersionDeclaration struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"` // line of the version field, else of the declaration
+	Version string `json:"version"`
+	Digest  string `json:"digest,omitempty"`
+
+	// Checksum pins the installed binary, so the version cannot change
+	// without it.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// VersionConflict lists the declarations of a provider alias that pin
+// different versions, with the version proposed to align them. Type is the
+// type of the alias's first declaration.
+type VersionConflict struct {
+	Alias        string               `json:"alias"`
+	Type         string               `json:"type"`
+	Declarations []VersionDeclaration `json:"declarations"`
+
+	// Proposed is the highest release version declared, or "" when the
+	// declarations only use release channels.
+	Proposed string `json:"proposed,omitempty"`
+
+	// InFile reports whether a single file declares several versions, which
+	// fails the build with ErrVersionConflict. Versions in different files
+	// are allowed but run one provider per version.
+	InFile bool `json:"in_file"`
+}
+
+// Changes returns the declarations that ResolveVersionConflicts rewrites
+// to the proposed version. Declarations pinned by digest or checksum are
+// left alone: the pin names an asset or binary of the declared version, so
+// they must be updated by hand.
+func (c VersionConflict) Changes() []VersionDeclaration {
+	if c.Proposed == "" {
+		return nil
+	}
+	changes := make([]VersionDeclaration, 0, len(c.Declarations))
+	for _, d := range c.Declarations {
+		if d.Version != c.Proposed && d.Digest == "" && d.Checksum == "" {
+			changes = append(changes, d)
+		}
+	}
+	return changes
+}
+
+// FindVersionConflicts scans the .csl files under paths, expanded as by
+// DiscoverProviders, and returns each provider alias declared at more than
+// one version, in order of first declaration.
+func FindVersionConflicts(paths []string) ([]VersionConflict, error) {
+	var conflicts []VersionConflict
+	index := make(map[string]int) // alias → conflicts index
+
+	for _, path := range paths {
+		files, err := discoverCslFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover files at %s: %w", path, err)
+		}
+		for _, file := range files {
+			decls, err := parseSourceDecls(file)
+			if err != nil {
+				return nil, err
+			}
+			lines, err := readLines(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, decl := range decls {
+				if compiler.IsBuiltinProviderType(decl.Type) {
+					continue
+				}
+				i, ok := index[decl.Alias]
+				if !ok {
+					i = len(conflicts)
+					index[decl.Alias] = i
+					conflicts = append(conflicts, VersionConflict{Alias: decl.Alias, Type: decl.Type})
+				}
+				conflicts[i].Declarations = append(conflicts[i].Declarations, VersionDeclaration{
+					File:     file,
+					Line:     versionLine(lines, decl),
+					Version:  decl.Version,
+					Digest:   decl.Digest,
+					Checksum: decl.Checksum,
+				})
+			}
+		}
+	}
+
+	result := make([]VersionConflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		versions := make([]string, 0, len(c.Declarations))
+		perFile := make(map[string]string)
+		for _, d := range c.Declarations {
+			if !slices.Contains(versions, d.Version) {
+				versions = append(versions, d.Version)
+			}
+			if v, ok := perFile[d.File]; ok && v != d.Version {
+				c.InFile = true
+			}
+			perFile[d.File] = d.Version
+		}
+		if len(versions) < 2 {
+			continue
+		}
+		c.Proposed = highestVersion(versions)
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// ResolveVersionConflicts rewrites the version field of each declaration
+// returned by VersionConflict.Changes to the proposed version, keeping
+// the rest of each file as written. It returns the number of declarations
+// rewritten.
+func ResolveVersionConflicts(conflicts []VersionConflict) (int, error) {
+	byFile := make(map[string]map[int]string) // file → line → new version
+	var files []string
+	for _, c := range conflicts {
+		for _, d := range c.Changes() {
+			if byFile[d.File] == nil {
+				byFile[d.File] = make(map[int]string)
+				files = append(files, d.File)
+			}
+			byFile[d.File][d.Line] = c.Proposed
+		}
+	}
+
+	rewritten := 0
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to rewrite %s: %w", file, err)
+		}
+		lines, err := readLines(file)
+		if err != nil {
+			return rewritten, err
+		}
+		for line, version := range byFile[file] {
+			updated, ok := replaceVersion(lines[line-1], version)
+			if !ok {
+				return rewritten, fmt.Errorf("failed to rewrite %s:%d: no version field on the line", file, line)
+			}
+			lines[line-1] = updated
+		}
+		//nolint:gosec // G306: Keeps the permissions of the user's file
+		if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+			return rewritten, fmt.Errorf("failed to rewrite %s: %w", file, err)
+		}
+		rewritten += len(byFile[file])
+	}
+	return rewritten, nil
+}
+
+// parseSourceDecls returns the source declarations of a .csl file.
+func parseSourceDecls(path string) ([]*ast.SourceDecl, error) {
+	//nolint:gosec // G304: Path comes from user CLI input, intentional file inclusion
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	tree, err := parser.Parse(file, path)
+	if err != nil {
+		return nil, parseError(path, err)
+	}
+
+	var decls []*ast.SourceDecl
+	for _, stmt := range tree.Statements {
+		if decl, ok := stmt.(*ast.SourceDecl); ok {
+			decls = append(decls, decl)
+		}
+	}
+	return decls, nil
+}
+
+// readLines returns the lines of a file, without their line endings.
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path comes from user CLI input
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// versionFieldPattern matches a source declaration's version field,
+// capturing the text before the value, the quote, and the value.
+var versionFieldPattern = regexp.MustCompile(`^(\s*version:\s*)(['"]?)([^'"\s#]*)(['"]?)`)
+
+// versionLine returns the line of decl's version field, or the line the
+// declaration starts on.
+func versionLine(lines []string, decl *ast.SourceDecl) int {
+	end := min(decl.SourceSpan.EndLine, len(lines))
+	for line := decl.SourceSpan.StartLine; line >= 1 && line <= end; line++ {
+		if m := versionFieldPattern.FindStringSubmatch(lines[line-1]); m != nil && m[3] == decl.Version {
+			return line
+		}
+	}
+	return decl.SourceSpan.StartLine
+}
+
+// replaceVersion replaces the version value on line, keeping its quotes.
+func replaceVersion(line, version string) (string, bool) {
+	m := versionFieldPattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line, false
+	}
+	// m[6]:m[7] is the value
+	return line[:m[6]] + version + line[m[7]:], true
+}
+
+// highestVersion returns the highest release version in versions, or ""
+// when none is a release version.
+func highestVersion(versions []string) string {
+	best := ""
+	for _, v := range versions {
+		if downloader.IsChannel(v) {
+			continue
+		}
+		if _, ok := parseVersion(v); !ok {
+			continue
+		}
+		if best == "" || compareVersions(v, best) > 0 {
+			best = v
+		}
+	}
+	return best
+}
+
+// IsMajorChange reports whether moving from one release version to another
+// changes the major version, which may break compatibility.
+func IsMajorChange(from, to string) bool {
+	a, okA := parseVersion(from)
+	b, okB := parseVersion(to)
+	return okA && okB && a.parts[0] != b.parts[0]
+}
+
+// semver is a parsed release version.
+type semver struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseVersion parses versions of the form [v]MAJOR[.MINOR[.PATCH]][-PRE][+BUILD].
+func parseVersion(v string) (semver, bool) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, _ := strings.Cut(v, "-")
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return semver{}, false
+	}
+	var s semver
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		s.parts[i] = n
+	}
+	s.prerelease = pre
+	return s, true
+}
+
+// compareVersions compares two release versions, returning -1, 0, or 1.
+// A pre-release sorts before its release.
+func compareVersions(a, b string) int {
+	x, _ := parseVersion(a)
+	y, _ := parseVersion(b)
+	for i := range x.parts {
+		if c := x.parts[i] - y.parts[i]; c != 0 {
+			if c < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case x.prerelease == y.prerelease:
+		return 0
+	case x.prerelease == "":
+		return 1
+	case y.prerelease == "":
+		return -1
+	}
+	return strings.Compare(x.prerelease, y.prerelease)
+}
@@ -0,0 +1,150 @@
+package providercmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindVersionConflicts tests listing aliases declared at several
+// versions with the file and line of each declaration.
+func TestFindVersionConflicts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '1.2.0'\n",
+		"b.csl": "app:\n  name: 'b'\n\nsource:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: \"1.10.0\"\n\nsource:\n  alias: 'other'\n  type: 'owner/other'\n  version: '1.0.0'\n",
+		"c.csl": "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '0.9.0'\n  digest: 'sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'\n\nsource:\n  alias: 'other'\n  type: 'owner/other'\n  version: '1.0.0'\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conflicts, err := FindVersionConflicts([]string{dir})
+	if err != nil {
+		t.Fatalf("FindVersionConflicts() error = %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want 1: %+v", len(conflicts), conflicts)
+	}
+
+	c := conflicts[0]
+	if c.Alias != "configs" || c.Type != "owner/repo" || c.Proposed != "1.10.0" || c.InFile {
+		t.Errorf("conflict = %+v, want configs (owner/repo) proposing 1.10.0 across files", c)
+	}
+	want := []VersionDeclaration{
+		{File: filepath.Join(dir, "a.csl"), Line: 4, Version: "1.2.0"},
+		{File: filepath.Join(dir, "b.csl"), Line: 7, Version: "1.10.0"},
+		{File: filepath.Join(dir, "c.csl"), Line: 4, Version: "0.9.0", Digest: "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+	}
+	if len(c.Declarations) != len(want) {
+		t.Fatalf("declarations = %+v, want %+v", c.Declarations, want)
+	}
+	for i := range want {
+		if c.Declarations[i] != want[i] {
+			t.Errorf("declaration %d = %+v, want %+v", i, c.Declarations[i], want[i])
+		}
+	}
+
+	// The digest-pinned declaration is left for the user
+	changes := c.Changes()
+	if len(changes) != 1 || changes[0].Version != "1.2.0" {
+		t.Errorf("Changes() = %+v, want only the 1.2.0 declaration", changes)
+	}
+}
+
+// TestFindVersionConflicts_InFile tests flagging a file that declares one
+// alias at several versions.
+func TestFindVersionConflicts_InFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.csl")
+	content := "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: 'latest'\n\nsource:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '2.0.0'\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err := FindVersionConflicts([]string{path})
+	if err != nil {
+		t.Fatalf("FindVersionConflicts() error = %v", err)
+	}
+	if len(conflicts) != 1 || !conflicts[0].InFile {
+		t.Fatalf("conflicts = %+v, want one in-file conflict", conflicts)
+	}
+	// Channels are never proposed
+	if conflicts[0].Proposed != "2.0.0" {
+		t.Errorf("Proposed = %q, want %q", conflicts[0].Proposed, "2.0.0")
+	}
+}
+
+// TestResolveVersionConflicts tests rewriting declarations to the proposed
+// version while keeping the rest of each file.
+func TestResolveVersionConflicts(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.csl")
+	b := filepath.Join(dir, "b.csl")
+	aContent := "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '1.0.0' # pinned for now\n  directory: './data'\n"
+	bContent := "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: \"1.1.0\"\n"
+	if err := os.WriteFile(a, []byte(aContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(bContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err := FindVersionConflicts([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten, err := ResolveVersionConflicts(conflicts)
+	if err != nil {
+		t.Fatalf("ResolveVersionConflicts() error = %v", err)
+	}
+	if rewritten != 1 {
+		t.Errorf("rewritten = %d, want 1", rewritten)
+	}
+
+	got, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "source:\n  alias: 'configs'\n  type: 'owner/repo'\n  version: '1.1.0' # pinned for now\n  directory: './data'\n"
+	if string(got) != want {
+		t.Errorf("a.csl =\n%s\nwant\n%s", got, want)
+	}
+	if got, _ := os.ReadFile(b); string(got) != bContent {
+		t.Errorf("b.csl changed:\n%s", got)
+	}
+
+	// Resolved declarations no longer conflict
+	if conflicts, err := FindVersionConflicts([]string{dir}); err != nil || len(conflicts) != 0 {
+		t.Errorf("FindVersionConflicts() after resolve = %+v, %v; want none", conflicts, err)
+	}
+}
+
+// TestCompareVersions tests release version ordering.
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if !IsMajorChange("1.4.0", "2.0.0") || IsMajorChange("1.4.0", "v1.9.0") {
+		t.Error("IsMajorChange() misreports major version changes")
+	}
+	if got := highestVersion([]string{"latest", "main", "0.9.0", "0.10.0"}); got != "0.10.0" {
+		t.Errorf("highestVersion() = %q, want %q", got, "0.10.0")
+	}
+}
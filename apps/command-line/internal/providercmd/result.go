@@ -21,6 +21,10 @@ type ProviderResult struct {
 	// Alias is the provider alias
 	Alias string
 
+	// Aliases lists every alias sharing this provider's binary when more
+	// than one declaration uses the same type and version
+	Aliases []string
+
 	// Type is the provider type (owner/repo format)
 	Type string
 
@@ -0,0 +1,113 @@
+// Package providercmd implements provider management functionality for the nomos CLI.
+package providercmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+)
+
+// TrustedProvider admits the provider types matching Source, so a project
+// can restrict the providers its .csl files may declare. In YAML:
+//
+//	trusted_providers:
+//	  - source: autonomous-bits/*
+//	  - source: acme/nomos-provider-vault
+//	    signer: acme/release-workflows/.github/workflows/release.yml
+type TrustedProvider struct {
+	// Source is an owner/repo pattern in path.Match syntax, such as
+	// "autonomous-bits/*".
+	Source string `yaml:"source"`
+
+	// Signer, when set, is the GitHub Actions workflow that must have
+	// attested the installed binary, as accepted by
+	// 'gh attestation verify --signer-workflow'.
+	Signer string `yaml:"signer,omitempty"`
+}
+
+// ValidateTrustedProviders reports malformed sources.
+func ValidateTrustedProviders(trusted []TrustedProvider) error {
+	for _, t := range trusted {
+		if strings.Count(t.Source, "/") != 1 {
+			return fmt.Errorf("trusted provider source %q must be an owner/repo pattern", t.Source)
+		}
+		if _, err := path.Match(t.Source, ""); err != nil {
+			return fmt.Errorf("trusted provider source %q: %w", t.Source, err)
+		}
+	}
+	return nil
+}
+
+// trustFor returns the first entry of trusted admitting providerType.
+func trustFor(trusted []TrustedProvider, providerType string) (TrustedProvider, bool) {
+	for _, t := range trusted {
+		if ok, _ := path.Match(t.Source, providerType); ok {
+			return t, true
+		}
+	}
+	return TrustedProvider{}, false
+}
+
+// checkTrust returns an error wrapping ErrUntrustedProvider for each
+// provider no entry of trusted admits. An empty trusted admits every
+// provider.
+func checkTrust(providers []DiscoveredProvider, trusted []TrustedProvider) error {
+	if len(trusted) == 0 {
+		return nil
+	}
+	sources := make([]string, len(trusted))
+	for i, t := range trusted {
+		sources[i] = t.Source
+	}
+	var errs []error
+	for _, p := range providers {
+		if _, ok := trustFor(trusted, p.Type); !ok {
+			errs = append(errs, fmt.Errorf("%w: provider %q (type %q) in %s (trusted: %s)",
+				ErrUntrustedProvider, p.Alias, p.Type, p.File, strings.Join(sources, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verifySigner checks that signer attested the installed binary of entry,
+// using the GitHub CLI, and removes the binary when it did not.
+func verifySigner(entry ProviderEntry, signer string, opts ProviderOptions) error {
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	gh, err := exec.LookPath("gh")
+	if err != nil {
+		deleteProviderBinary(entry)
+		return fmt.Errorf("%w: verifying signer %q requires the GitHub CLI (gh): %w", ErrSignerMismatch, signer, err)
+	}
+	binary := filepath.Join(nomosdir.ProvidersDir(), entry.Path)
+	//nolint:gosec // G204: The signer is configured by the project
+	cmd := exec.CommandContext(ctx, gh, "attestation", "verify", binary, "--repo", entry.Type, "--signer-workflow", signer)
+	cmd.Env = os.Environ()
+	if opts.Tokens != nil {
+		if token, err := opts.Tokens.Token(ctx, "api.github.com"); err == nil && token != "" {
+			cmd.Env = append(cmd.Env, "GH_TOKEN="+token)
+		}
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		deleteProviderBinary(entry)
+		return fmt.Errorf("%w: %s@%s is not attested by %s: %w: %s",
+			ErrSignerMismatch, entry.Type, entry.Version, signer, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package providercmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
+)
+
+func TestEnsureProviders_Trusted(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("app.csl", []byte(mirrorTestCSL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+	opts := ProviderOptions{Paths: []string{"app.csl"}, OS: "linux", Arch: "amd64", MirrorDir: "mirror"}
+
+	opts.Trusted = []TrustedProvider{{Source: "autonomous-bits/*"}}
+	_, err := EnsureProviders(opts)
+	if !errors.Is(err, ErrUntrustedProvider) || !strings.Contains(err.Error(), `provider "configs" (type "owner/repo")`) {
+		t.Fatalf("EnsureProviders() error = %v, want ErrUntrustedProvider for owner/repo", err)
+	}
+	if _, err := os.Stat(nomosdir.LockfilePath); !os.IsNotExist(err) {
+		t.Errorf("untrusted provider installed (stat error = %v)", err)
+	}
+
+	opts.Trusted = append(opts.Trusted, TrustedProvider{Source: "owner/*"})
+	if _, err := EnsureProviders(opts); err != nil {
+		t.Errorf("EnsureProviders() with owner/* trusted = %v, want nil", err)
+	}
+}
+
+func TestEnsureProviders_Signer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gh script requires a POSIX shell")
+	}
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("app.csl", []byte(mirrorTestCSL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeMirror(t, "mirror", Platform{OS: "linux", Arch: "amd64"})
+
+	// A fake GitHub CLI attesting binaries signed by owner/repo's release workflow
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+  "attestation verify "*" --repo owner/repo --signer-workflow owner/repo/.github/workflows/release.yml") exit 0 ;;
+esac
+echo "verification failed" >&2
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(bin, "gh"), []byte(script), 0700); err != nil { //nolint:gosec // G306: The script must be executable
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	release := "owner/repo/.github/workflows/release.yml"
+	opts := ProviderOptions{Paths: []string{"app.csl"}, OS: "linux", Arch: "amd64", MirrorDir: "mirror",
+		Trusted: []TrustedProvider{{Source: "owner/repo", Signer: release}}}
+	if _, err := EnsureProviders(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lock, err := ReadLockFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Providers[0].Signer != release {
+		t.Errorf("lockfile signer = %q, want %q", lock.Providers[0].Signer, release)
+	}
+
+	// Requiring another signer re-verifies the cached binary
+	opts.Trusted[0].Signer = "owner/other/.github/workflows/release.yml"
+	_, err = EnsureProviders(opts)
+	if !errors.Is(err, ErrSignerMismatch) || !strings.Contains(err.Error(), "verification failed") {
+		t.Fatalf("EnsureProviders() error = %v, want ErrSignerMismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(nomosdir.ProvidersDir(), lock.Providers[0].Path)); !os.IsNotExist(err) {
+		t.Errorf("unverified binary kept (stat error = %v)", err)
+	}
+}
+
+func TestValidateTrustedProviders(t *testing.T) {
+	for _, source := range []string{"acme", "acme/vault/extra", "acme/[vault"} {
+		if err := ValidateTrustedProviders([]TrustedProvider{{Source: source}}); err == nil {
+			t.Errorf("ValidateTrustedProviders(%q) = nil, want an error", source)
+		}
+	}
+	if err := ValidateTrustedProviders([]TrustedProvider{{Source: "acme/*"}}); err != nil {
+		t.Errorf("ValidateTrustedProviders(acme/*) = %v, want nil", err)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/nomosdir"
 )
 
 // ValidateProvider performs checksum verification on an installed provider binary.
@@ -22,7 +25,7 @@ import (
 // Returns nil if validation succeeds, or an error describing the validation failure.
 func ValidateProvider(entry ProviderEntry) error {
 	// Build full path to provider binary
-	fullPath := filepath.Join(".nomos", "providers", entry.Path)
+	fullPath := filepath.Join(nomosdir.ProvidersDir(), entry.Path)
 
 	// Check if binary exists
 	info, err := os.Stat(fullPath)
@@ -34,23 +37,13 @@ func ValidateProvider(entry ProviderEntry) error {
 	}
 
 	// Compute SHA256 checksum
-	//nolint:gosec // G304: Path is constructed from validated lockfile entry
-	data, err := os.ReadFile(fullPath)
+	actualChecksum, err := fileChecksum(fullPath)
 	if err != nil {
 		return fmt.Errorf("failed to read provider binary for checksum: %w", err)
 	}
 
-	hash := sha256.Sum256(data)
-	actualChecksum := hex.EncodeToString(hash[:])
-
-	// Normalize expected checksum (handle both "sha256:..." and plain hex formats)
-	expectedChecksum := entry.Checksum
-	if len(expectedChecksum) > 7 && expectedChecksum[:7] == "sha256:" {
-		expectedChecksum = expectedChecksum[7:]
-	}
-
 	// Compare checksums
-	if actualChecksum != expectedChecksum {
+	if actualChecksum != normalizeChecksum(entry.Checksum) {
 		// Delete corrupted binary
 		if removeErr := os.Remove(fullPath); removeErr != nil {
 			// Log but don't fail - return the checksum error
@@ -68,3 +61,20 @@ func ValidateProvider(entry ProviderEntry) error {
 
 	return nil
 }
+
+// fileChecksum returns the hex-encoded SHA256 checksum of the file at path.
+func fileChecksum(path string) (string, error) {
+	//nolint:gosec // G304: Path is constructed from validated lockfile entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// normalizeChecksum strips an optional "sha256:" prefix so checksums in
+// either format can be compared as plain hex.
+func normalizeChecksum(checksum string) string {
+	return strings.TrimPrefix(checksum, "sha256:")
+}
@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Client sends compile requests to a nomosd server.
+type Client struct {
+	// Addr is the server address, as host:port or an http or https URL.
+	Addr string
+
+	// Token, when set, is sent as a bearer token.
+	Token string
+
+	// HTTPClient sends the requests. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Compile sends req to the server and returns its response. Compilation
+// errors are reported in the response, not as an error.
+func (c *Client) Compile(ctx context.Context, req CompileRequest) (CompileResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return CompileResponse{}, fmt.Errorf("cannot encode compile request: %w", err)
+	}
+
+	base := c.Addr
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+CompilePath, bytes.NewReader(body))
+	if err != nil {
+		return CompileResponse{}, fmt.Errorf("invalid remote address %q: %w", c.Addr, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return CompileResponse{}, fmt.Errorf("remote compile failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(content, &errResp) != nil || errResp.Error == "" {
+			errResp.Error = strings.TrimSpace(string(content))
+		}
+		return CompileResponse{}, fmt.Errorf("remote compile failed: %s: %s", resp.Status, errResp.Error)
+	}
+	var result CompileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return CompileResponse{}, fmt.Errorf("invalid response from %s: %w", c.Addr, err)
+	}
+	return result, nil
+}
+
+// Bundle returns the .csl files beneath root, keyed by slash-separated
+// path relative to root, for CompileRequest.Files. Hidden directories,
+// such as .git and .nomos, are skipped.
+func Bundle(root string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(path) != ".csl" {
+			return nil
+		}
+		content, err := os.ReadFile(path) //nolint:gosec // G304: Path is found beneath the project root
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot bundle .csl files: %w", err)
+	}
+	return files, nil
+}
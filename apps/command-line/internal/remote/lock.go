@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultLockTimeout bounds how long a LockingPublisher waits for a
+// target's lock when LockingPublisher.Timeout is unset.
+const DefaultLockTimeout = time.Minute
+
+// DefaultLockTTL is how long a lock outlives a publisher that stopped
+// without releasing it, when a Locker's TTL is unset.
+const DefaultLockTTL = 5 * time.Minute
+
+// lockPollInterval is how often a held lock is retried.
+const lockPollInterval = 250 * time.Millisecond
+
+// ErrStaleCommit is returned when a target was already published at a
+// commit descending from the one being published.
+var ErrStaleCommit = errors.New("a newer commit is already published")
+
+// ErrLockLost is returned when a lock expired or was taken over before it
+// was released.
+var ErrLockLost = errors.New("publish lock lost")
+
+// LockInfo identifies the holder of a publish lock.
+type LockInfo struct {
+	ID       string    `json:"id"`
+	Owner    string    `json:"owner"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// newLockInfo returns a LockInfo with a random ID, owned by this process.
+func newLockInfo() LockInfo {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return LockInfo{
+		ID:       hex.EncodeToString(id),
+		Owner:    fmt.Sprintf("nomosd@%s:%d", host, os.Getpid()),
+		Acquired: time.Now().UTC(),
+	}
+}
+
+// Locker serializes the publishes of a target across nomosd instances
+// sharing a backend, like Terraform state locking.
+type Locker interface {
+	// Lock acquires the lock of target, waiting while another holder has
+	// it, until ctx is done.
+	Lock(ctx context.Context, target string, info LockInfo) (PublishLock, error)
+}
+
+// PublishLock is a held target lock.
+type PublishLock interface {
+	// Published returns the commit target was last published at under the
+	// lock, or "" when none was recorded.
+	Published() string
+
+	// Unlock releases the lock, first recording commit as published unless
+	// it is empty. It returns an error wrapping ErrLockLost when the lock
+	// is no longer held.
+	Unlock(ctx context.Context, commit string) error
+}
+
+// LockingPublisher holds the lock of a target while publishing it, so
+// concurrent publishes of the same target are serialized, and refuses to
+// replace an artifact published at a newer commit.
+type LockingPublisher struct {
+	// Publisher delivers the artifacts.
+	Publisher Publisher
+
+	// Locker provides the target locks.
+	Locker Locker
+
+	// Timeout bounds how long a publish waits for its lock. Zero means
+	// DefaultLockTimeout.
+	Timeout time.Duration
+
+	// IsAncestor reports whether commit ancestor is an ancestor of commit
+	// descendant. When unset, any commit may replace the published one.
+	IsAncestor func(ctx context.Context, ancestor, descendant string) (bool, error)
+}
+
+// Publish implements Publisher.
+func (p LockingPublisher) Publish(ctx context.Context, target Target, commit string, content []byte) (err error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	lock, err := p.Locker.Lock(lockCtx, target.Name, newLockInfo())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+
+	published := ""
+	defer func() {
+		// Unlock even when ctx is canceled, so the lock is not left to expire
+		if unlockErr := lock.Unlock(context.WithoutCancel(ctx), published); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("cannot publish %s: %w", target.Name, unlockErr))
+		}
+	}()
+
+	if last := lock.Published(); last != "" && last != commit && p.IsAncestor != nil {
+		stale, err := p.IsAncestor(ctx, commit, last)
+		if err != nil {
+			return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+		}
+		if stale {
+			return fmt.Errorf("cannot publish %s at %s: %w (%s)", target.Name, shortCommit(commit), ErrStaleCommit, shortCommit(last))
+		}
+	}
+	if err := p.Publisher.Publish(ctx, target, commit, content); err != nil {
+		return err
+	}
+	published = commit
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for messages.
+func shortCommit(commit string) string {
+	return commit[:min(12, len(commit))]
+}
+
+// waitLock sleeps for the lock poll interval, returning an error naming
+// the holder once ctx is done.
+func waitLock(ctx context.Context, target string, holder LockInfo) error {
+	select {
+	case <-ctx.Done():
+		if holder.Owner != "" {
+			return fmt.Errorf("lock of %s held by %s since %s: %w",
+				target, holder.Owner, holder.Acquired.Format(time.RFC3339), ctx.Err())
+		}
+		return fmt.Errorf("cannot lock %s: %w", target, ctx.Err())
+	case <-time.After(lockPollInterval):
+		return nil
+	}
+}
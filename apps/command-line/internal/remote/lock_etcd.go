@@ -0,0 +1,196 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultEtcdPrefix is the key prefix of EtcdLocker when Prefix is unset.
+const defaultEtcdPrefix = "/nomosd/"
+
+// EtcdLocker locks targets with keys in etcd, through the JSON gateway of
+// its v3 API. A lock is a key bound to a lease of TTL, created only while
+// absent, so it expires with a publisher that stopped without unlocking.
+type EtcdLocker struct {
+	// Endpoint is the base URL of an etcd member, such as
+	// http://etcd:2379.
+	Endpoint string
+
+	// Prefix is prepended to the lock and published-commit keys, which are
+	// <prefix>locks/<target> and <prefix>published/<target>. Empty means
+	// "/nomosd/".
+	Prefix string
+
+	// TTL is the lease of a lock. Zero means DefaultLockTTL.
+	TTL time.Duration
+
+	// HTTPClient sends the requests. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Lock implements Locker.
+func (l EtcdLocker) Lock(ctx context.Context, target string, info LockInfo) (PublishLock, error) {
+	ttl := l.TTL
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	value, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+	}
+	var lease struct {
+		ID string `json:"ID"`
+	}
+	if err := l.call(ctx, "/v3/lease/grant", map[string]any{"TTL": int64(ttl.Seconds())}, &lease); err != nil {
+		return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+	}
+	lock := &etcdLock{locker: l, target: target, lease: lease.ID, value: value}
+
+	for {
+		var txn etcdTxnResponse
+		err := l.call(ctx, "/v3/kv/txn", map[string]any{
+			"compare": []any{map[string]any{"key": l.key("locks/", target), "target": "CREATE", "create_revision": "0"}},
+			"success": []any{
+				map[string]any{"request_put": map[string]any{"key": l.key("locks/", target), "value": etcdBytes(value), "lease": lease.ID}},
+				map[string]any{"request_range": map[string]any{"key": l.key("published/", target)}},
+			},
+			"failure": []any{map[string]any{"request_range": map[string]any{"key": l.key("locks/", target)}}},
+		}, &txn)
+		if err != nil {
+			lock.revoke()
+			return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+		}
+		if txn.Succeeded {
+			if published, ok := txn.value(1); ok {
+				lock.published = string(published)
+			}
+			return lock, nil
+		}
+
+		var holder LockInfo
+		if current, ok := txn.value(0); ok {
+			_ = json.Unmarshal(current, &holder)
+		}
+		if err := waitLock(ctx, target, holder); err != nil {
+			lock.revoke()
+			return nil, err
+		}
+	}
+}
+
+// key returns the base64-encoded etcd key of target under kind.
+func (l EtcdLocker) key(kind, target string) string {
+	prefix := l.Prefix
+	if prefix == "" {
+		prefix = defaultEtcdPrefix
+	}
+	return etcdBytes([]byte(prefix + kind + target))
+}
+
+// call posts request to the gateway path and decodes the response into
+// response.
+func (l EtcdLocker) call(ctx context.Context, path string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(l.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	httpClient := l.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("etcd request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("etcd %s answered %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// etcdBytes encodes bytes as the JSON gateway expects them.
+func etcdBytes(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// etcdTxnResponse is the part of a transaction response an EtcdLocker
+// reads.
+type etcdTxnResponse struct {
+	Succeeded bool `json:"succeeded"`
+	Responses []struct {
+		ResponseRange *struct {
+			Kvs []struct {
+				Value string `json:"value"`
+			} `json:"kvs"`
+		} `json:"response_range"`
+	} `json:"responses"`
+}
+
+// value returns the value read by the i-th operation of the transaction,
+// when it was a range that found a key.
+func (r etcdTxnResponse) value(i int) ([]byte, bool) {
+	if i >= len(r.Responses) || r.Responses[i].ResponseRange == nil || len(r.Responses[i].ResponseRange.Kvs) == 0 {
+		return nil, false
+	}
+	value, err := base64.StdEncoding.DecodeString(r.Responses[i].ResponseRange.Kvs[0].Value)
+	return value, err == nil
+}
+
+// etcdLock is a lock held by an EtcdLocker.
+type etcdLock struct {
+	locker    EtcdLocker
+	target    string
+	lease     string
+	value     []byte
+	published string
+}
+
+// Published implements PublishLock.
+func (l *etcdLock) Published() string {
+	return l.published
+}
+
+// Unlock implements PublishLock.
+func (l *etcdLock) Unlock(ctx context.Context, commit string) error {
+	defer l.revoke()
+
+	// Record the commit only while the lock key is still ours
+	ops := []any{}
+	if commit != "" {
+		ops = append(ops, map[string]any{"request_put": map[string]any{"key": l.locker.key("published/", l.target), "value": etcdBytes([]byte(commit))}})
+	}
+	var txn etcdTxnResponse
+	err := l.locker.call(ctx, "/v3/kv/txn", map[string]any{
+		"compare": []any{map[string]any{"key": l.locker.key("locks/", l.target), "target": "VALUE", "value": etcdBytes(l.value)}},
+		"success": ops,
+	}, &txn)
+	if err != nil {
+		return fmt.Errorf("cannot unlock %s: %w", l.target, err)
+	}
+	if !txn.Succeeded {
+		return fmt.Errorf("%w: lease of %s expired", ErrLockLost, l.target)
+	}
+	return nil
+}
+
+// revoke ends the lease of the lock, deleting the lock key.
+func (l *etcdLock) revoke() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var response struct{}
+	_ = l.locker.call(ctx, "/v3/lease/revoke", map[string]any{"ID": l.lease}, &response)
+}
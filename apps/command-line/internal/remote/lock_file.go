@@ -0,0 +1,130 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileLocker locks targets with lock files in Dir, for nomosd instances
+// publishing to a shared filesystem. A target's lock is .<target>.lock and
+// the commit it was last published at is kept in .<target>.published.
+type FileLocker struct {
+	Dir string
+
+	// TTL is how long a lock file is honored before it is taken over as
+	// left behind by a stopped publisher. Zero means DefaultLockTTL.
+	TTL time.Duration
+}
+
+// Lock implements Locker.
+func (l FileLocker) Lock(ctx context.Context, target string, info LockInfo) (PublishLock, error) {
+	if err := os.MkdirAll(l.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+	}
+	ttl := l.TTL
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+	}
+	path := filepath.Join(l.Dir, "."+target+".lock")
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // G304: Path is built from the publish directory and a target name
+		if err == nil {
+			_, err = f.Write(data)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+			}
+			published, err := os.ReadFile(l.publishedPath(target))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+			}
+			return &fileLock{locker: l, target: target, path: path, id: info.ID, published: strings.TrimSpace(string(published))}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("cannot lock %s: %w", target, err)
+		}
+
+		holder, err := readLockInfo(path)
+		if err != nil {
+			// A holder may have stopped before writing its info
+			if stat, statErr := os.Stat(path); statErr == nil {
+				holder.Acquired = stat.ModTime()
+			}
+		}
+		if !holder.Acquired.IsZero() && time.Since(holder.Acquired) > ttl {
+			// The holder stopped without unlocking
+			_ = os.Remove(path)
+			continue
+		}
+		if err := waitLock(ctx, target, holder); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// publishedPath returns the file recording the published commit of target.
+func (l FileLocker) publishedPath(target string) string {
+	return filepath.Join(l.Dir, "."+target+".published")
+}
+
+// readLockInfo reads the holder recorded in a lock file.
+func readLockInfo(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is built from the publish directory and a target name
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+// fileLock is a lock held by a FileLocker.
+type fileLock struct {
+	locker    FileLocker
+	target    string
+	path      string
+	id        string
+	published string
+}
+
+// Published implements PublishLock.
+func (l *fileLock) Published() string {
+	return l.published
+}
+
+// Unlock implements PublishLock.
+func (l *fileLock) Unlock(_ context.Context, commit string) error {
+	if holder, err := readLockInfo(l.path); err != nil || holder.ID != l.id {
+		return fmt.Errorf("%w: lock of %s was taken over", ErrLockLost, l.target)
+	}
+	if commit != "" {
+		tmp := l.locker.publishedPath(l.target) + ".tmp"
+		if err := os.WriteFile(tmp, []byte(commit+"\n"), 0600); err != nil {
+			_ = os.Remove(l.path)
+			return fmt.Errorf("cannot record published commit of %s: %w", l.target, err)
+		}
+		if err := os.Rename(tmp, l.locker.publishedPath(l.target)); err != nil {
+			_ = os.Remove(l.path)
+			return fmt.Errorf("cannot record published commit of %s: %w", l.target, err)
+		}
+	}
+	if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("cannot unlock %s: %w", l.target, err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd serves the parts of the etcd v3 JSON gateway an EtcdLocker
+// uses.
+type fakeEtcd struct {
+	mu     sync.Mutex
+	kvs    map[string]string // base64 key to base64 value
+	leases map[string]string // base64 key to lease ID
+	next   int
+}
+
+func newFakeEtcd(t *testing.T) (*fakeEtcd, string) {
+	t.Helper()
+	e := &fakeEtcd{kvs: map[string]string{}, leases: map[string]string{}}
+	server := httptest.NewServer(http.HandlerFunc(e.serve))
+	t.Cleanup(server.Close)
+	return e, server.URL
+}
+
+type fakeOp struct {
+	RequestPut *struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		Lease string `json:"lease"`
+	} `json:"request_put"`
+	RequestRange *struct {
+		Key string `json:"key"`
+	} `json:"request_range"`
+}
+
+func (e *fakeEtcd) serve(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var req struct {
+		ID      string `json:"ID"`
+		Compare []struct {
+			Key    string `json:"key"`
+			Target string `json:"target"`
+			Value  string `json:"value"`
+		} `json:"compare"`
+		Success []fakeOp `json:"success"`
+		Failure []fakeOp `json:"failure"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		e.next++
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": strconv.Itoa(e.next), "TTL": "300"})
+	case "/v3/lease/revoke":
+		for key, lease := range e.leases {
+			if lease == req.ID {
+				delete(e.kvs, key)
+				delete(e.leases, key)
+			}
+		}
+		_, _ = w.Write([]byte("{}"))
+	case "/v3/kv/txn":
+		succeeded := true
+		for _, c := range req.Compare {
+			value, exists := e.kvs[c.Key]
+			if (c.Target == "CREATE" && exists) || (c.Target == "VALUE" && value != c.Value) {
+				succeeded = false
+			}
+		}
+		ops := req.Success
+		if !succeeded {
+			ops = req.Failure
+		}
+		var responses []any
+		for _, op := range ops {
+			switch {
+			case op.RequestPut != nil:
+				e.kvs[op.RequestPut.Key] = op.RequestPut.Value
+				if op.RequestPut.Lease != "" {
+					e.leases[op.RequestPut.Key] = op.RequestPut.Lease
+				}
+				responses = append(responses, map[string]any{"response_put": map[string]any{}})
+			case op.RequestRange != nil:
+				kvs := []any{}
+				if value, ok := e.kvs[op.RequestRange.Key]; ok {
+					kvs = append(kvs, map[string]string{"key": op.RequestRange.Key, "value": value})
+				}
+				responses = append(responses, map[string]any{"response_range": map[string]any{"kvs": kvs}})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"succeeded": succeeded, "responses": responses})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// expire drops the lock key of target, as etcd does when its lease ends.
+func (e *fakeEtcd) expire(target string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := base64.StdEncoding.EncodeToString([]byte(defaultEtcdPrefix + "locks/" + target))
+	delete(e.kvs, key)
+	delete(e.leases, key)
+}
+
+func TestLockers(t *testing.T) {
+	_, etcdURL := newFakeEtcd(t)
+	lockers := map[string]Locker{
+		"file": FileLocker{Dir: t.TempDir()},
+		"etcd": EtcdLocker{Endpoint: etcdURL},
+	}
+	for name, locker := range lockers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			first, err := locker.Lock(ctx, "prod", LockInfo{ID: "1", Owner: "first", Acquired: time.Now()})
+			if err != nil {
+				t.Fatalf("Lock() error = %v", err)
+			}
+			if first.Published() != "" {
+				t.Errorf("Published() = %q, want none", first.Published())
+			}
+
+			// A second holder waits until the first unlocks
+			waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			_, err = locker.Lock(waitCtx, "prod", LockInfo{ID: "2", Owner: "second", Acquired: time.Now()})
+			cancel()
+			if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "held by first") {
+				t.Fatalf("Lock() while held error = %v, want a deadline naming the holder", err)
+			}
+			if _, err := locker.Lock(ctx, "staging", LockInfo{ID: "3", Owner: "third", Acquired: time.Now()}); err != nil {
+				t.Errorf("Lock() of another target error = %v", err)
+			}
+
+			acquired := make(chan PublishLock)
+			go func() {
+				second, err := locker.Lock(ctx, "prod", LockInfo{ID: "2", Owner: "second", Acquired: time.Now()})
+				if err != nil {
+					t.Errorf("Lock() after unlock error = %v", err)
+				}
+				acquired <- second
+			}()
+			if err := first.Unlock(ctx, "abc123"); err != nil {
+				t.Fatalf("Unlock() error = %v", err)
+			}
+			second := <-acquired
+			if second == nil {
+				return
+			}
+			if second.Published() != "abc123" {
+				t.Errorf("Published() = %q, want abc123", second.Published())
+			}
+			if err := second.Unlock(ctx, ""); err != nil {
+				t.Errorf("Unlock() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFileLocker_Stale(t *testing.T) {
+	dir := t.TempDir()
+	locker := FileLocker{Dir: dir, TTL: time.Minute}
+	ctx := context.Background()
+	if _, err := locker.Lock(ctx, "prod", LockInfo{ID: "1", Owner: "crashed", Acquired: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := locker.Lock(ctx, "prod", LockInfo{ID: "2", Owner: "next", Acquired: time.Now()})
+	if err != nil {
+		t.Fatalf("Lock() over an expired lock error = %v", err)
+	}
+
+	// A takeover is detected when the taken-over lock is released
+	if err := os.WriteFile(filepath.Join(dir, ".prod.lock"), []byte(`{"id":"3"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Unlock(ctx, "abc123"); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Unlock() error = %v, want ErrLockLost", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".prod.published")); !os.IsNotExist(err) {
+		t.Errorf("commit recorded without the lock (stat error = %v)", err)
+	}
+}
+
+func TestEtcdLocker_Expired(t *testing.T) {
+	etcd, url := newFakeEtcd(t)
+	lock, err := EtcdLocker{Endpoint: url}.Lock(context.Background(), "prod", LockInfo{ID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	etcd.expire("prod")
+	if err := lock.Unlock(context.Background(), "abc123"); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Unlock() error = %v, want ErrLockLost", err)
+	}
+}
+
+// recordingPublisher records the commits it publishes.
+type recordingPublisher struct {
+	commits []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _ Target, commit string, _ []byte) error {
+	p.commits = append(p.commits, commit)
+	return nil
+}
+
+func TestLockingPublisher(t *testing.T) {
+	// Commits are ordered by name: "a" is an ancestor of "b"
+	isAncestor := func(_ context.Context, ancestor, descendant string) (bool, error) {
+		return ancestor <= descendant, nil
+	}
+	inner := &recordingPublisher{}
+	p := LockingPublisher{Publisher: inner, Locker: FileLocker{Dir: t.TempDir()}, IsAncestor: isAncestor}
+	target := Target{Name: "prod"}
+	ctx := context.Background()
+
+	for _, commit := range []string{"b", "b", "c"} {
+		if err := p.Publish(ctx, target, commit, nil); err != nil {
+			t.Fatalf("Publish(%s) error = %v", commit, err)
+		}
+	}
+	if err := p.Publish(ctx, target, "a", nil); !errors.Is(err, ErrStaleCommit) {
+		t.Errorf("Publish(a) error = %v, want ErrStaleCommit", err)
+	}
+	if strings.Join(inner.commits, ",") != "b,b,c" {
+		t.Errorf("published %v, want b,b,c", inner.commits)
+	}
+
+	// The lock was released after the refused publish
+	if err := p.Publish(ctx, target, "d", nil); err != nil {
+		t.Errorf("Publish(d) error = %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Publisher delivers the artifacts of push-triggered builds.
+type Publisher interface {
+	// Publish delivers content, the artifact of target built at commit.
+	Publish(ctx context.Context, target Target, commit string, content []byte) error
+}
+
+// DirPublisher publishes artifacts as files in Dir, named by
+// Target.Artifact. Each file is replaced atomically, so readers never see
+// a partial artifact.
+type DirPublisher struct {
+	Dir string
+}
+
+// Publish implements Publisher.
+func (p DirPublisher) Publish(_ context.Context, target Target, _ string, content []byte) error {
+	if err := os.MkdirAll(p.Dir, 0750); err != nil {
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	tmp, err := os.CreateTemp(p.Dir, "."+target.Name+"-*")
+	if err != nil {
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { //nolint:gosec // G302: Artifacts are meant to be read by deploy tooling
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(p.Dir, target.Artifact())); err != nil {
+		return fmt.Errorf("cannot publish %s: %w", target.Name, err)
+	}
+	return nil
+}
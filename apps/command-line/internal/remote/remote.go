@@ -0,0 +1,96 @@
+// Package remote implements the HTTP API through which nomosd compiles
+// configuration on behalf of nomos build --remote, so provider credentials,
+// provider processes, and their caches live on one long-running server
+// instead of every workstation and CI runner.
+//
+// The API has two endpoints:
+//
+//	POST /v1/compile   compile a CompileRequest, answering a CompileResponse
+//	GET  /healthz      report that the server is up
+//
+// Requests and responses are JSON. A request carries either a bundle of
+// .csl files or a git revision of the server's repository. Compilation
+// errors are part of a successful response; other failures answer with an
+// HTTP error status and an ErrorResponse. File paths in responses are
+// relative to the root of the bundle or revision.
+//
+// A Trigger adds two more, which rebuild and publish declared Targets
+// when GitHub reports a push to the repository:
+//
+//	POST /v1/webhooks/github   receive a signed GitHub webhook delivery
+//	GET  /v1/builds            report the latest build of each target
+//
+// A LockingPublisher serializes the publishes of a target across servers
+// with a Locker, such as a FileLocker or an EtcdLocker.
+package remote
+
+import (
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// Endpoint paths of the API.
+const (
+	CompilePath       = "/v1/compile"
+	HealthPath        = "/healthz"
+	GitHubWebhookPath = "/v1/webhooks/github"
+	BuildsPath        = "/v1/builds"
+)
+
+// CompileRequest asks the server to compile configuration.
+type CompileRequest struct {
+	// Path is the .csl file or directory to compile, slash-separated and
+	// relative to the root of Files or of the revision.
+	Path string `json:"path"`
+
+	// Files maps slash-separated paths relative to the project root to
+	// their content. It must hold every .csl file the compilation reads.
+	// Ignored when Ref is set.
+	Files map[string]string `json:"files,omitempty"`
+
+	// Ref is a git revision of the server's repository to compile instead
+	// of Files.
+	Ref string `json:"ref,omitempty"`
+
+	// Vars, Sets, and Profiles are as for nomos build --var, --set, and
+	// --profile.
+	Vars     []string `json:"vars,omitempty"`
+	Sets     []string `json:"sets,omitempty"`
+	Profiles []string `json:"profiles,omitempty"`
+
+	// VarFiles holds the contents of YAML or JSON var files, overlaid in
+	// order.
+	VarFiles []string `json:"var_files,omitempty"`
+
+	// SuppressWarnings lists warning codes to silence in addition to the
+	// server's own.
+	SuppressWarnings []string `json:"suppress_warnings,omitempty"`
+
+	// TypeCoercion overrides the server's type coercion policy when set.
+	TypeCoercion string `json:"type_coercion,omitempty"`
+
+	AllowMissingProvider bool   `json:"allow_missing_provider,omitempty"`
+	PartialFailure       string `json:"partial_failure,omitempty"`
+	FetchRetries         int    `json:"fetch_retries,omitempty"`
+	SourceMap            bool   `json:"source_map,omitempty"`
+	MaxSnapshotBytes     int64  `json:"max_snapshot_bytes,omitempty"`
+	SourceDateEpoch      string `json:"source_date_epoch,omitempty"`
+	Reproducible         bool   `json:"reproducible,omitempty"`
+}
+
+// CompileResponse is the result of a compilation, successful or not.
+type CompileResponse struct {
+	// Snapshot is the compiled snapshot. Compilation errors are listed in
+	// its metadata.
+	Snapshot compiler.Snapshot `json:"snapshot"`
+
+	// Diagnostics are the located errors and warnings of the compilation.
+	Diagnostics []compiler.Diagnostic `json:"diagnostics"`
+
+	// Commit is the commit a Ref resolved to.
+	Commit string `json:"commit,omitempty"`
+}
+
+// ErrorResponse describes a request the server could not compile.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,124 @@
+package remote
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// newTestServer serves a Server with a provider-less session.
+func newTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	session := compiler.NewSession(compiler.SessionOptions{})
+	t.Cleanup(func() { _ = session.Close(context.Background()) })
+	ts := httptest.NewServer((&Server{Session: session, Token: token}).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClientCompile(t *testing.T) {
+	ts := newTestServer(t, "")
+	client := &Client{Addr: ts.URL}
+
+	resp, err := client.Compile(context.Background(), CompileRequest{
+		Path: "config",
+		Files: map[string]string{
+			"config/app.csl": "app:\n  name: 'demo'\n  port: '80'\n",
+		},
+		Sets:     []string{"app.port=8080"},
+		VarFiles: []string{"app:\n  region: eu\n"},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := map[string]any{"name": "demo", "port": "8080", "region": "eu"}
+	if got := resp.Snapshot.Data["app"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Compile() app = %v, want %v", got, want)
+	}
+	if files := resp.Snapshot.Metadata.InputFiles; len(files) != 1 || files[0] != "config/app.csl" {
+		t.Errorf("InputFiles = %v, want [config/app.csl] relative to the bundle", files)
+	}
+}
+
+func TestClientCompile_Diagnostics(t *testing.T) {
+	ts := newTestServer(t, "")
+	client := &Client{Addr: strings.TrimPrefix(ts.URL, "http://")}
+
+	resp, err := client.Compile(context.Background(), CompileRequest{
+		Path:  "app.csl",
+		Files: map[string]string{"app.csl": "app: 'demo'\nbad: 'unterminated\n"},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(resp.Snapshot.Metadata.Errors) == 0 || len(resp.Diagnostics) == 0 {
+		t.Fatalf("Compile() = %+v, want compilation errors", resp)
+	}
+	d := resp.Diagnostics[0]
+	if d.File != "app.csl" || d.Line != 2 {
+		t.Errorf("Diagnostics[0] = %+v, want app.csl:2", d)
+	}
+	if msg := strings.Join(resp.Snapshot.Metadata.Errors, "\n"); strings.Contains(msg, os.TempDir()) {
+		t.Errorf("errors expose the server's scratch directory: %s", msg)
+	}
+}
+
+func TestClientCompile_Rejected(t *testing.T) {
+	ts := newTestServer(t, "secret")
+
+	tests := []struct {
+		name  string
+		token string
+		req   CompileRequest
+		want  string
+	}{
+		{name: "missing token", req: CompileRequest{Path: "."}, want: "401"},
+		{name: "wrong token", token: "guess", req: CompileRequest{Path: "."}, want: "401"},
+		{name: "escaping path", token: "secret", req: CompileRequest{Path: "../etc"}, want: "400"},
+		{name: "escaping file", token: "secret", req: CompileRequest{Path: ".", Files: map[string]string{"../x.csl": "a: '1'\n"}}, want: "400"},
+		{name: "revision without repository", token: "secret", req: CompileRequest{Path: ".", Ref: "main"}, want: "does not compile git revisions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{Addr: ts.URL, Token: tt.token}
+			_, err := client.Compile(context.Background(), tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"app.csl":              "a: '1'\n",
+		"config/db.csl":        "b: '1'\n",
+		"config/notes.md":      "notes\n",
+		".nomos/ignored.csl":   "c: '1'\n",
+		".git/hooks/other.csl": "d: '1'\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Bundle(root)
+	if err != nil {
+		t.Fatalf("Bundle() error = %v", err)
+	}
+	want := map[string]string{"app.csl": "a: '1'\n", "config/db.csl": "b: '1'\n"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Bundle() = %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,253 @@
+package remote
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/gitref"
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/options"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// DefaultMaxRequestBytes limits the size of a compile request.
+const DefaultMaxRequestBytes = 32 << 20
+
+// Server compiles requests within one compiler session, so provider
+// processes started for one request serve the next.
+type Server struct {
+	// Session compiles every request.
+	Session *compiler.Session
+
+	// Base holds the parameters every compilation starts from, such as
+	// PolicyFiles, SuppressWarnings, TypeCoercion, and provider timeouts.
+	// Requests add to or override them; Path, ProjectRoot, and the
+	// provider registries are set per request.
+	Base options.BuildParams
+
+	// Repo is the git repository revisions are compiled from. Empty
+	// rejects requests with a Ref.
+	Repo string
+
+	// Token, when set, is the bearer token requests must present.
+	Token string
+
+	// MaxRequestBytes limits the size of a request body. Zero means
+	// DefaultMaxRequestBytes.
+	MaxRequestBytes int64
+
+	// Logger, when set, logs each compile request.
+	Logger *log.Logger
+}
+
+// requestError is a failure answered with an HTTP status other than 500.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+
+func (e *requestError) Unwrap() error { return e.err }
+
+// badRequest reports a request that cannot be compiled as sent.
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+CompilePath, s.handleCompile)
+	mux.HandleFunc("GET "+HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+// handleCompile serves POST /v1/compile.
+func (s *Server) handleCompile(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status, body := s.serveCompile(w, r)
+	writeJSON(w, status, body)
+	if s.Logger != nil {
+		s.Logger.Printf("%s %s %d %s", r.Method, r.URL.Path, status, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// serveCompile answers a compile request with a status and response body.
+func (s *Server) serveCompile(w http.ResponseWriter, r *http.Request) (int, any) {
+	if !s.authorized(r) {
+		return http.StatusUnauthorized, ErrorResponse{Error: "missing or invalid bearer token"}
+	}
+
+	limit := s.MaxRequestBytes
+	if limit <= 0 {
+		limit = DefaultMaxRequestBytes
+	}
+	var req CompileRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("request exceeds %d bytes", limit)}
+		}
+		return http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+
+	resp, err := s.compile(r.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var reqErr *requestError
+		switch {
+		case errors.As(err, &reqErr):
+			status = reqErr.status
+		case errors.Is(err, compiler.ErrSessionClosed):
+			status = http.StatusServiceUnavailable
+		}
+		return status, ErrorResponse{Error: err.Error()}
+	}
+	return http.StatusOK, resp
+}
+
+// authorized reports whether r carries the bearer token, when one is set.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// compile compiles req in a scratch directory and returns the response
+// with paths relative to the compiled tree.
+func (s *Server) compile(ctx context.Context, req CompileRequest) (json.RawMessage, error) {
+	if !filepath.IsLocal(filepath.FromSlash(req.Path)) {
+		return nil, badRequest("path %q must be relative to the project root", req.Path)
+	}
+
+	work, err := os.MkdirTemp("", "nomosd-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(work) }()
+
+	var root, commit string
+	if req.Ref != "" {
+		if s.Repo == "" {
+			return nil, badRequest("this server does not compile git revisions")
+		}
+		tree, err := gitref.Open(ctx, s.Repo, req.Ref)
+		if err != nil {
+			return nil, badRequest("%w", err)
+		}
+		defer func() { _ = tree.Close() }()
+		root, commit = tree.Dir, tree.Commit
+	} else {
+		root = filepath.Join(work, "src")
+		if err := writeBundle(root, req.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.build(ctx, root, work, req)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(CompileResponse{
+		Snapshot:    result.Snapshot,
+		Diagnostics: result.Diagnostics(),
+		Commit:      commit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode response: %w", err)
+	}
+	return relativize(relativize(encoded, root), work), nil
+}
+
+// build compiles req.Path beneath root with the server's base parameters
+// and the request's own. Var files are written to work.
+func (s *Server) build(ctx context.Context, root, work string, req CompileRequest) (compiler.CompilationResult, error) {
+	params := s.Base
+	params.Path = filepath.Join(root, filepath.FromSlash(req.Path))
+	params.ProjectRoot = root
+	params.Vars = req.Vars
+	params.Sets = req.Sets
+	params.Profiles = req.Profiles
+	params.SuppressWarnings = append(append([]string(nil), s.Base.SuppressWarnings...), req.SuppressWarnings...)
+	params.TypeCoercion = cmp.Or(req.TypeCoercion, s.Base.TypeCoercion)
+	params.AllowMissingProvider = req.AllowMissingProvider
+	params.PartialFailure = req.PartialFailure
+	params.FetchRetries = req.FetchRetries
+	params.SourceMap = req.SourceMap
+	params.MaxSnapshotBytes = req.MaxSnapshotBytes
+	params.SourceDateEpoch = req.SourceDateEpoch
+	params.Reproducible = req.Reproducible
+	params.VarFiles = nil
+	for i, content := range req.VarFiles {
+		path := filepath.Join(work, fmt.Sprintf("var-file-%d.yaml", i+1))
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return compiler.CompilationResult{}, err
+		}
+		params.VarFiles = append(params.VarFiles, path)
+	}
+
+	opts, err := options.BuildOptions(params)
+	if err != nil {
+		return compiler.CompilationResult{}, badRequest("invalid options: %w", err)
+	}
+	return s.Session.Compile(ctx, opts)
+}
+
+// writeBundle writes files beneath root, rejecting paths that would leave
+// it.
+func writeBundle(root string, files map[string]string) error {
+	for name, content := range files {
+		path := filepath.FromSlash(name)
+		if !filepath.IsLocal(path) {
+			return badRequest("file %q must be relative to the project root", name)
+		}
+		target := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0600); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(root, 0750)
+}
+
+// relativize rewrites the paths beneath dir in encoded JSON relative to
+// it, and dir itself as ".", so responses do not expose the server's
+// scratch directories.
+func relativize(encoded []byte, dir string) []byte {
+	quote := func(s string) []byte {
+		b, _ := json.Marshal(s) // Strings always encode
+		return b
+	}
+	prefix := quote(dir + string(filepath.Separator))
+	encoded = bytes.ReplaceAll(encoded, prefix[1:len(prefix)-1], nil)
+	return bytes.ReplaceAll(encoded, quote(dir), []byte(`"."`))
+}
+
+// writeJSON writes body as the JSON response with status.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if raw, ok := body.(json.RawMessage); ok {
+		_, _ = w.Write(raw)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,153 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/serialize"
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// Targets declares what nomosd rebuilds when the repository's branch is
+// pushed. It is read from YAML:
+//
+//	branch: main
+//	targets:
+//	  - name: prod
+//	    path: config/prod
+//	    format: yaml
+//	    profiles: [prod]
+//	    watch: [config/shared]
+type Targets struct {
+	// Branch is the branch whose pushes trigger builds. Defaults to main.
+	Branch string `yaml:"branch"`
+
+	// Targets are the builds to run.
+	Targets []Target `yaml:"targets"`
+}
+
+// Target is one build published on push.
+type Target struct {
+	// Name identifies the target in the queue and names its artifact.
+	Name string `yaml:"name"`
+
+	// Path is the .csl file or directory to compile, slash-separated and
+	// relative to the repository.
+	Path string `yaml:"path"`
+
+	// Format is json, yaml, or tfvars. Defaults to json.
+	Format string `yaml:"format"`
+
+	// Vars and Profiles are as for nomos build --var and --profile.
+	Vars     []string `yaml:"vars"`
+	Profiles []string `yaml:"profiles"`
+
+	// IncludeMetadata adds compilation metadata to the artifact.
+	IncludeMetadata bool `yaml:"include_metadata"`
+
+	// Watch lists further slash-separated paths, relative to the
+	// repository, whose changes rebuild the target. Changes beneath Path,
+	// or beneath its directory when Path is a file, always do.
+	Watch []string `yaml:"watch"`
+}
+
+// LoadTargets reads and validates the targets file at path.
+func LoadTargets(path string) (Targets, error) {
+	var t Targets
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is the targets file named on the command line
+	if err != nil {
+		return t, fmt.Errorf("failed to read targets: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return t, fmt.Errorf("failed to parse targets %s: %w", path, err)
+	}
+	if t.Branch == "" {
+		t.Branch = "main"
+	}
+	if len(t.Targets) == 0 {
+		return t, fmt.Errorf("invalid targets %s: no targets declared", path)
+	}
+	seen := make(map[string]bool, len(t.Targets))
+	for i := range t.Targets {
+		target := &t.Targets[i]
+		if err := target.validate(); err != nil {
+			return t, fmt.Errorf("invalid targets %s: %w", path, err)
+		}
+		if seen[target.Name] {
+			return t, fmt.Errorf("invalid targets %s: duplicate target %q", path, target.Name)
+		}
+		seen[target.Name] = true
+	}
+	return t, nil
+}
+
+// validate checks the target and fills in its defaults.
+func (t *Target) validate() error {
+	if t.Name == "" || strings.ContainsAny(t.Name, `/\`) || strings.HasPrefix(t.Name, ".") {
+		return fmt.Errorf("target name %q must be a non-empty file name", t.Name)
+	}
+	for _, p := range append([]string{t.Path}, t.Watch...) {
+		if !filepath.IsLocal(filepath.FromSlash(p)) {
+			return fmt.Errorf("target %s: path %q must be relative to the repository", t.Name, p)
+		}
+	}
+	if t.Format == "" {
+		t.Format = string(serialize.FormatJSON)
+	}
+	switch serialize.OutputFormat(t.Format) {
+	case serialize.FormatJSON, serialize.FormatYAML, serialize.FormatTfvars:
+	default:
+		return fmt.Errorf("target %s: format %q (supported: json, yaml, tfvars)", t.Name, t.Format)
+	}
+	return nil
+}
+
+// Affected returns the targets a change to the slash-separated files
+// rebuilds.
+func (t Targets) Affected(files []string) []Target {
+	var affected []Target
+	for _, target := range t.Targets {
+		if slices.ContainsFunc(files, target.watches) {
+			affected = append(affected, target)
+		}
+	}
+	return affected
+}
+
+// watches reports whether a change to file rebuilds the target.
+func (t Target) watches(file string) bool {
+	dirs := append([]string{t.Path}, t.Watch...)
+	if path.Ext(t.Path) == ".csl" {
+		dirs[0] = path.Dir(t.Path)
+	}
+	for _, dir := range dirs {
+		dir = path.Clean(dir)
+		if dir == "." || file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Artifact returns the file name the target is published as.
+func (t Target) Artifact() string {
+	return t.Name + serialize.OutputFormat(t.Format).Extension()
+}
+
+// serialize encodes snapshot in the target's format.
+func (t Target) serialize(snapshot compiler.Snapshot) ([]byte, error) {
+	var opts serialize.Options
+	switch serialize.OutputFormat(t.Format) {
+	case serialize.FormatYAML:
+		return opts.ToYAML(snapshot, t.IncludeMetadata)
+	case serialize.FormatTfvars:
+		return opts.ToTfvars(snapshot, t.IncludeMetadata)
+	default:
+		return opts.ToJSON(snapshot, t.IncludeMetadata)
+	}
+}
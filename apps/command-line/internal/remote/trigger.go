@@ -0,0 +1,399 @@
+package remote
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autonomous-bits/nomos/apps/command-line/internal/gitref"
+)
+
+// DefaultTriggerWorkers is the number of push-triggered builds run at once
+// when Trigger.Workers is unset.
+const DefaultTriggerWorkers = 2
+
+// githubMaxCommits is the most commits GitHub lists in a push event. A
+// push listing that many may have changed files the event does not name.
+const githubMaxCommits = 20
+
+// maxWebhookBytes is GitHub's limit on webhook payloads.
+const maxWebhookBytes = 25 << 20
+
+// maxDeliveries bounds how many delivery IDs are remembered to drop
+// redeliveries.
+const maxDeliveries = 1024
+
+// commitHash matches a full SHA-1 or SHA-256 commit hash.
+var commitHash = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Build states reported by GET /v1/builds.
+const (
+	BuildQueued    = "queued"
+	BuildRunning   = "running"
+	BuildSucceeded = "succeeded"
+	BuildFailed    = "failed"
+)
+
+// BuildStatus is the latest build of a target.
+type BuildStatus struct {
+	Target   string    `json:"target"`
+	Commit   string    `json:"commit"`
+	State    string    `json:"state"`
+	Finished time.Time `json:"finished,omitzero"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BuildsResponse answers GET /v1/builds, ordered by target name.
+type BuildsResponse struct {
+	Builds []BuildStatus `json:"builds"`
+}
+
+// TriggerResponse answers a webhook delivery. Status is queued, unaffected,
+// duplicate, ignored, or pong.
+type TriggerResponse struct {
+	Status  string   `json:"status"`
+	Commit  string   `json:"commit,omitempty"`
+	Targets []string `json:"targets,omitempty"`
+}
+
+// Trigger rebuilds and publishes targets when GitHub reports a push to the
+// server's repository. A target is queued at most once: a push while its
+// build is pending moves the build to the newer commit. At most Workers
+// builds run at once, and never two of the same target.
+type Trigger struct {
+	// Server compiles the targets from its Repo, which must be set. Its
+	// Token also guards GET /v1/builds.
+	Server *Server
+
+	// Targets are the builds run on pushes to Targets.Branch.
+	Targets Targets
+
+	// Secret is the webhook secret deliveries are signed with. Deliveries
+	// are rejected while it is empty.
+	Secret string
+
+	// Publisher delivers the artifacts.
+	Publisher Publisher
+
+	// Workers limits how many builds run at once. Zero means
+	// DefaultTriggerWorkers.
+	Workers int
+
+	// Logger, when set, logs each delivery and build.
+	Logger *log.Logger
+
+	setup      sync.Once
+	mu         sync.Mutex
+	wake       *sync.Cond
+	order      []string          // names of pending targets, oldest first
+	pending    map[string]string // commit of each pending target
+	running    map[string]bool
+	status     map[string]BuildStatus
+	deliveries []string // recent delivery IDs, oldest first
+}
+
+// pushEvent is the part of a GitHub push event payload a Trigger reads.
+type pushEvent struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// changedFiles returns the files the push changed, or false when the event
+// does not list them all.
+func (e pushEvent) changedFiles() ([]string, bool) {
+	if len(e.Commits) == 0 || len(e.Commits) >= githubMaxCommits {
+		return nil, false
+	}
+	var files []string
+	for _, c := range e.Commits {
+		files = append(append(append(files, c.Added...), c.Removed...), c.Modified...)
+	}
+	return files, true
+}
+
+// init prepares the queue.
+func (t *Trigger) init() {
+	t.setup.Do(func() {
+		t.wake = sync.NewCond(&t.mu)
+		t.pending = make(map[string]string)
+		t.running = make(map[string]bool)
+		t.status = make(map[string]BuildStatus)
+	})
+}
+
+// Handler returns the HTTP handler serving POST /v1/webhooks/github and
+// GET /v1/builds.
+func (t *Trigger) Handler() http.Handler {
+	t.init()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+GitHubWebhookPath, t.handleGitHub)
+	mux.HandleFunc("GET "+BuildsPath, t.handleBuilds)
+	return mux
+}
+
+// handleGitHub serves POST /v1/webhooks/github.
+func (t *Trigger) handleGitHub(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status, body := t.serveGitHub(w, r)
+	writeJSON(w, status, body)
+	t.logf("%s %s %s %d %s", r.Method, r.URL.Path, r.Header.Get("X-GitHub-Delivery"), status, time.Since(start).Round(time.Millisecond))
+}
+
+// serveGitHub answers a webhook delivery with a status and response body,
+// queueing the targets a push affects.
+func (t *Trigger) serveGitHub(w http.ResponseWriter, r *http.Request) (int, any) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("payload exceeds %d bytes", maxWebhookBytes)}
+		}
+		return http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if !t.verify(r.Header.Get("X-Hub-Signature-256"), payload) {
+		return http.StatusUnauthorized, ErrorResponse{Error: "missing or invalid webhook signature"}
+	}
+
+	switch r.Header.Get("X-GitHub-Event") {
+	case "ping":
+		return http.StatusOK, TriggerResponse{Status: "pong"}
+	case "push":
+	default:
+		return http.StatusOK, TriggerResponse{Status: "ignored"}
+	}
+
+	var event pushEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid push event: %v", err)}
+	}
+	if event.Deleted || event.Ref != "refs/heads/"+t.Targets.Branch {
+		return http.StatusOK, TriggerResponse{Status: "ignored"}
+	}
+	if !commitHash.MatchString(event.After) {
+		return http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid push event: commit %q", event.After)}
+	}
+	if t.redelivered(r.Header.Get("X-GitHub-Delivery")) {
+		return http.StatusOK, TriggerResponse{Status: "duplicate", Commit: event.After}
+	}
+
+	targets := t.Targets.Targets
+	if files, ok := event.changedFiles(); ok {
+		targets = t.Targets.Affected(files)
+	}
+	if len(targets) == 0 {
+		return http.StatusOK, TriggerResponse{Status: "unaffected", Commit: event.After}
+	}
+	resp := TriggerResponse{Status: "queued", Commit: event.After}
+	for _, target := range targets {
+		t.enqueue(target.Name, event.After)
+		resp.Targets = append(resp.Targets, target.Name)
+	}
+	return http.StatusAccepted, resp
+}
+
+// verify reports whether signature, an X-Hub-Signature-256 header, is the
+// HMAC of payload under the secret.
+func (t *Trigger) verify(signature string, payload []byte) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok || t.Secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(t.Secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// redelivered records the delivery ID and reports whether it was seen
+// before, as when GitHub or an operator redelivers an event.
+func (t *Trigger) redelivered(id string) bool {
+	if id == "" {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if slices.Contains(t.deliveries, id) {
+		return true
+	}
+	t.deliveries = append(t.deliveries, id)
+	if len(t.deliveries) > maxDeliveries {
+		t.deliveries = t.deliveries[1:]
+	}
+	return false
+}
+
+// handleBuilds serves GET /v1/builds.
+func (t *Trigger) handleBuilds(w http.ResponseWriter, r *http.Request) {
+	if !t.Server.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "missing or invalid bearer token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, BuildsResponse{Builds: t.Builds()})
+}
+
+// Builds returns the latest build of each target built or queued since
+// the server started, ordered by target name.
+func (t *Trigger) Builds() []BuildStatus {
+	t.init()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	builds := make([]BuildStatus, 0, len(t.status))
+	for _, status := range t.status {
+		builds = append(builds, status)
+	}
+	slices.SortFunc(builds, func(a, b BuildStatus) int { return strings.Compare(a.Target, b.Target) })
+	return builds
+}
+
+// enqueue queues a build of the named target at commit, replacing the
+// commit of a build already pending.
+func (t *Trigger) enqueue(name, commit string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.pending[name]; !ok {
+		t.order = append(t.order, name)
+	}
+	t.pending[name] = commit
+	t.status[name] = BuildStatus{Target: name, Commit: commit, State: BuildQueued}
+	t.wake.Broadcast()
+}
+
+// Run builds queued targets until ctx is done. Builds still queued then
+// are dropped; builds in progress are canceled.
+func (t *Trigger) Run(ctx context.Context) {
+	t.init()
+	stop := context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.wake.Broadcast()
+	})
+	defer stop()
+
+	workers := t.Workers
+	if workers <= 0 {
+		workers = DefaultTriggerWorkers
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for {
+				name, commit, ok := t.next(ctx)
+				if !ok {
+					return
+				}
+				t.finish(name, commit, t.build(ctx, name, commit))
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// next waits for a pending target that is not being built and marks it
+// running. It returns false once ctx is done.
+func (t *Trigger) next(ctx context.Context) (string, string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ctx.Err() == nil {
+		for i, name := range t.order {
+			if t.running[name] {
+				continue
+			}
+			commit := t.pending[name]
+			t.order = slices.Delete(t.order, i, i+1)
+			delete(t.pending, name)
+			t.running[name] = true
+			t.status[name] = BuildStatus{Target: name, Commit: commit, State: BuildRunning}
+			return name, commit, true
+		}
+		t.wake.Wait()
+	}
+	return "", "", false
+}
+
+// finish records the outcome of a build. A newer build of the target
+// queued meanwhile keeps its queued status.
+func (t *Trigger) finish(name, commit string, err error) {
+	status := BuildStatus{Target: name, Commit: commit, State: BuildSucceeded, Finished: time.Now().UTC()}
+	if err != nil {
+		status.State, status.Error = BuildFailed, err.Error()
+		t.logf("build of %s at %s failed: %v", name, commit[:min(12, len(commit))], err)
+	} else {
+		t.logf("built and published %s at %s", name, commit[:min(12, len(commit))])
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.running, name)
+	if _, queued := t.pending[name]; !queued {
+		t.status[name] = status
+	}
+	t.wake.Broadcast()
+}
+
+// build compiles the named target at commit and publishes its artifact.
+func (t *Trigger) build(ctx context.Context, name, commit string) error {
+	i := slices.IndexFunc(t.Targets.Targets, func(target Target) bool { return target.Name == name })
+	if i < 0 {
+		return fmt.Errorf("unknown target %q", name)
+	}
+	target := t.Targets.Targets[i]
+
+	tree, err := gitref.Open(ctx, t.Server.Repo, commit)
+	if err != nil {
+		// The push may not have reached the server's clone yet
+		if fetchErr := gitref.Fetch(ctx, t.Server.Repo); fetchErr != nil {
+			return errors.Join(err, fetchErr)
+		}
+		if tree, err = gitref.Open(ctx, t.Server.Repo, commit); err != nil {
+			return err
+		}
+	}
+	defer func() { _ = tree.Close() }()
+
+	// Targets have no var files, so the build needs no scratch directory
+	result, err := t.Server.build(ctx, tree.Dir, "", CompileRequest{
+		Path:     target.Path,
+		Vars:     target.Vars,
+		Profiles: target.Profiles,
+	})
+	if err != nil {
+		return err
+	}
+	if result.HasErrors() {
+		return errors.New(strings.ReplaceAll(result.Error().Error(), tree.Dir+string(filepath.Separator), ""))
+	}
+	content, err := target.serialize(result.Snapshot)
+	if err != nil {
+		return err
+	}
+	return t.Publisher.Publish(ctx, target, tree.Commit, content)
+}
+
+// logf logs through the Logger, when set.
+func (t *Trigger) logf(format string, args ...any) {
+	if t.Logger != nil {
+		t.Logger.Printf(format, args...)
+	}
+}
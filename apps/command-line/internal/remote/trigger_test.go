@@ -0,0 +1,258 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// commitRepo creates a repository with a commit of files and returns it
+// and the commit hash.
+func commitRepo(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	return dir, git("rev-parse", "HEAD")
+}
+
+// deliver posts a push event to the trigger's webhook, signed with secret.
+func deliver(t *testing.T, url, secret, event, id string, payload any) (int, TriggerResponse) {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req, err := http.NewRequest(http.MethodPost, url+GitHubWebhookPath, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", id)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var result TriggerResponse
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// push returns a push event of commit to main changing files.
+func push(commit string, files ...string) map[string]any {
+	return map[string]any{
+		"ref":     "refs/heads/main",
+		"after":   commit,
+		"commits": []map[string]any{{"modified": files}},
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	repo, commit := commitRepo(t, map[string]string{
+		"config/prod/app.csl": "app:\n  env: 'prod'\n",
+		"config/dev/app.csl":  "app:\n  env: 'dev'\n",
+	})
+	session := compiler.NewSession(compiler.SessionOptions{})
+	t.Cleanup(func() { _ = session.Close(context.Background()) })
+	publishDir := t.TempDir()
+	trigger := &Trigger{
+		Server: &Server{Session: session, Repo: repo},
+		Targets: Targets{Branch: "main", Targets: []Target{
+			{Name: "prod", Path: "config/prod", Format: "yaml"},
+			{Name: "dev", Path: "config/dev", Format: "json"},
+		}},
+		Secret:    "s3cret",
+		Publisher: DirPublisher{Dir: publishDir},
+	}
+	ts := httptest.NewServer(trigger.Handler())
+	t.Cleanup(ts.Close)
+
+	if status, resp := deliver(t, ts.URL, "s3cret", "ping", "1", map[string]any{}); status != http.StatusOK || resp.Status != "pong" {
+		t.Errorf("ping = %d %+v, want 200 pong", status, resp)
+	}
+	if status, _ := deliver(t, ts.URL, "guess", "push", "2", push(commit, "config/prod/app.csl")); status != http.StatusUnauthorized {
+		t.Errorf("wrongly signed push = %d, want 401", status)
+	}
+	other := push(commit, "config/prod/app.csl")
+	other["ref"] = "refs/heads/feature"
+	if status, resp := deliver(t, ts.URL, "s3cret", "push", "3", other); status != http.StatusOK || resp.Status != "ignored" {
+		t.Errorf("push to another branch = %d %+v, want 200 ignored", status, resp)
+	}
+	if status, resp := deliver(t, ts.URL, "s3cret", "push", "4", push(commit, "README.md")); status != http.StatusOK || resp.Status != "unaffected" {
+		t.Errorf("push outside targets = %d %+v, want 200 unaffected", status, resp)
+	}
+
+	status, resp := deliver(t, ts.URL, "s3cret", "push", "5", push(commit, "config/prod/app.csl"))
+	if status != http.StatusAccepted || !reflect.DeepEqual(resp.Targets, []string{"prod"}) {
+		t.Fatalf("push = %d %+v, want 202 queued [prod]", status, resp)
+	}
+	if status, resp := deliver(t, ts.URL, "s3cret", "push", "5", push(commit, "config/prod/app.csl")); status != http.StatusOK || resp.Status != "duplicate" {
+		t.Errorf("redelivered push = %d %+v, want 200 duplicate", status, resp)
+	}
+	if builds := trigger.Builds(); len(builds) != 1 || builds[0].State != BuildQueued {
+		t.Errorf("Builds() = %+v, want prod queued", builds)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		trigger.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(30 * time.Second)
+	for trigger.Builds()[0].State != BuildSucceeded {
+		if b := trigger.Builds()[0]; b.State == BuildFailed || time.Now().After(deadline) {
+			t.Fatalf("build = %+v, want succeeded", b)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	content, err := os.ReadFile(filepath.Join(publishDir, "prod.yaml"))
+	if err != nil {
+		t.Fatalf("artifact not published: %v", err)
+	}
+	if !strings.Contains(string(content), "env: prod") {
+		t.Errorf("artifact = %q, want the prod configuration", content)
+	}
+	if _, err := os.Stat(filepath.Join(publishDir, "dev.json")); !os.IsNotExist(err) {
+		t.Errorf("unaffected target dev was published")
+	}
+}
+
+func TestTrigger_Coalesce(t *testing.T) {
+	trigger := &Trigger{}
+	trigger.init()
+	trigger.enqueue("prod", "a")
+	trigger.enqueue("dev", "b")
+	trigger.enqueue("prod", "c")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []string
+	for range 2 {
+		name, commit, ok := trigger.next(ctx)
+		if !ok {
+			t.Fatal("next() = false, want a queued build")
+		}
+		got = append(got, name+"@"+commit)
+	}
+	if want := []string{"prod@c", "dev@b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("builds = %v, want %v", got, want)
+	}
+
+	// A push while prod builds waits for that build to finish
+	trigger.enqueue("prod", "d")
+	cancel()
+	if name, _, ok := trigger.next(ctx); ok {
+		t.Errorf("next() = %s, want nothing while prod is running", name)
+	}
+	trigger.finish("prod", "c", nil)
+	if b := trigger.Builds()[1]; b.Target != "prod" || b.State != BuildQueued || b.Commit != "d" {
+		t.Errorf("Builds() prod = %+v, want the newer build still queued", b)
+	}
+}
+
+func TestTargetsAffected(t *testing.T) {
+	targets := Targets{Targets: []Target{
+		{Name: "prod", Path: "config/prod"},
+		{Name: "app", Path: "apps/app.csl", Watch: []string{"shared"}},
+		{Name: "all", Path: "."},
+	}}
+
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{files: []string{"config/prod/db.csl"}, want: []string{"prod", "all"}},
+		{files: []string{"config/production.csl"}, want: []string{"all"}},
+		{files: []string{"apps/other.csl"}, want: []string{"app", "all"}},
+		{files: []string{"shared/base.csl"}, want: []string{"app", "all"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, target := range targets.Affected(tt.files) {
+			got = append(got, target.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Affected(%v) = %v, want %v", tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "targets:\n  - name: prod\n    path: config/prod\n"},
+		{name: "none", content: "branch: main\n", wantErr: "no targets"},
+		{name: "duplicate", content: "targets:\n  - {name: a, path: x}\n  - {name: a, path: y}\n", wantErr: "duplicate target"},
+		{name: "escaping path", content: "targets:\n  - {name: a, path: ../x}\n", wantErr: "relative to the repository"},
+		{name: "bad format", content: "targets:\n  - {name: a, path: x, format: template}\n", wantErr: "format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			targets, err := LoadTargets(path)
+			if tt.wantErr == "" {
+				if err != nil || targets.Branch != "main" || targets.Targets[0].Format != "json" {
+					t.Errorf("LoadTargets() = %+v, %v, want defaults applied", targets, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadTargets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
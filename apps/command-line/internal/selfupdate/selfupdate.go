@@ -0,0 +1,262 @@
+// Package selfupdate replaces the running nomos binary with a release
+// downloaded from GitHub.
+//
+// Releases are resolved and downloaded with the provider downloader, so the
+// same token sources, retries, and checksum verification apply as for
+// providers. A release is only installed when GitHub publishes a digest for
+// its asset. The new binary is staged next to the one it replaces, must run
+// before it is moved into place, and the previous binary is restored if the
+// swap or a run of the installed binary fails.
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+)
+
+// Release coordinates of the nomos CLI.
+const (
+	Owner     = "autonomous-bits"
+	Repo      = "nomos"
+	TagPrefix = "apps/command-line/"
+
+	// SignerWorkflow is the workflow that attests release binaries.
+	SignerWorkflow = Owner + "/" + Repo + "/.github/workflows/release.yml"
+)
+
+// ErrNoChecksum reports a release asset without a published digest, which
+// self-update refuses to install.
+var ErrNoChecksum = errors.New("release publishes no checksum for the asset")
+
+// ErrBrokenBinary reports a downloaded or installed binary that does not run.
+var ErrBrokenBinary = errors.New("updated binary does not run")
+
+// Options configures Update.
+type Options struct {
+	// Client resolves and downloads the release.
+	Client *downloader.Client
+
+	// Version is the release to install, such as "1.2.0", or a channel.
+	// Empty means downloader.ChannelLatest.
+	Version string
+
+	// Current is the version of the running binary.
+	Current string
+
+	// Executable is the binary to replace. Empty means the running
+	// executable, with symbolic links resolved.
+	Executable string
+
+	// OS and Arch select the release asset. Empty means the running system.
+	OS   string
+	Arch string
+
+	// Force reinstalls the release even if it is the current version.
+	Force bool
+
+	// DryRun resolves the release without downloading or installing it.
+	DryRun bool
+
+	// Verify, if set, is called with the path of the downloaded binary
+	// before it is installed, such as VerifyAttestation.
+	Verify func(ctx context.Context, path string) error
+
+	// Probe runs a binary and returns the version it reports. Nil means
+	// running it with "version --quiet".
+	Probe func(ctx context.Context, path string) (string, error)
+}
+
+// Result describes the outcome of Update.
+type Result struct {
+	// Path is the binary that was, or would be, replaced.
+	Path string
+
+	// Previous is the version of the binary before the update.
+	Previous string
+
+	// Version is the version of the resolved release.
+	Version string
+
+	// Asset is the name of the downloaded release asset.
+	Asset string
+
+	// Updated reports whether the binary was replaced.
+	Updated bool
+}
+
+// UpToDate reports whether the binary already was at the resolved release.
+func (r *Result) UpToDate() bool {
+	return normalize(r.Previous) == normalize(r.Version)
+}
+
+// Update installs the release selected by opts in place of the executable.
+// Without Force, a binary already at the resolved version is left alone.
+func Update(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Client == nil {
+		return nil, errors.New("selfupdate: no downloader client")
+	}
+	exe, err := executable(opts.Executable)
+	if err != nil {
+		return nil, err
+	}
+	version := opts.Version
+	if version == "" {
+		version = downloader.ChannelLatest
+	}
+
+	asset, err := opts.Client.ResolveAsset(ctx, &downloader.ProviderSpec{
+		Owner:     Owner,
+		Repo:      Repo,
+		TagPrefix: TagPrefix,
+		Version:   version,
+		OS:        opts.OS,
+		Arch:      opts.Arch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve nomos release %s: %w", version, err)
+	}
+
+	result := &Result{Path: exe, Previous: opts.Current, Version: asset.Version, Asset: asset.Name}
+	if opts.DryRun || (result.UpToDate() && !opts.Force) {
+		return result, nil
+	}
+	if asset.Checksum == "" {
+		return nil, fmt.Errorf("%s %s: %w", asset.Name, asset.Version, ErrNoChecksum)
+	}
+
+	// Stage the download next to the executable, so the final rename
+	// stays on one file system and is atomic
+	staging, err := os.MkdirTemp(filepath.Dir(exe), ".nomos-update-")
+	if err != nil {
+		return nil, fmt.Errorf("cannot write to %s: %w", filepath.Dir(exe), err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	// The downloader keeps its temporary files beside the destination
+	installed, err := opts.Client.DownloadAndInstall(ctx, asset, filepath.Join(staging, "bin"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	if opts.Verify != nil {
+		if err := opts.Verify(ctx, installed.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	probe := opts.Probe
+	if probe == nil {
+		probe = runVersion
+	}
+	if _, err := probe(ctx, installed.Path); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrBrokenBinary, asset.Name, err)
+	}
+
+	if err := replace(ctx, exe, installed.Path, probe); err != nil {
+		return nil, err
+	}
+	result.Updated = true
+	return result, nil
+}
+
+// replace moves binary over exe, keeping exe as a backup until the
+// installed binary has run, and restores the backup on failure.
+func replace(ctx context.Context, exe, binary string, probe func(context.Context, string) (string, error)) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", exe, err)
+	}
+	if err := os.Chmod(binary, info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("cannot make %s executable: %w", binary, err)
+	}
+
+	backup := exe + ".old"
+	_ = os.Remove(backup)
+	if err := os.Rename(exe, backup); err != nil {
+		return fmt.Errorf("cannot move %s aside: %w", exe, err)
+	}
+	if err := os.Rename(binary, exe); err != nil {
+		return rollback(exe, backup, fmt.Errorf("cannot install %s: %w", exe, err))
+	}
+	if _, err := probe(ctx, exe); err != nil {
+		return rollback(exe, backup, fmt.Errorf("%w: %s: %w", ErrBrokenBinary, exe, err))
+	}
+
+	// Windows keeps the running executable locked; the backup is then
+	// replaced by the next update
+	_ = os.Remove(backup)
+	return nil
+}
+
+// rollback restores backup as exe and returns cause, noting a failed
+// restore.
+func rollback(exe, backup string, cause error) error {
+	_ = os.Remove(exe)
+	if err := os.Rename(backup, exe); err != nil {
+		return fmt.Errorf("%w; restoring the previous binary from %s failed: %w", cause, backup, err)
+	}
+	return fmt.Errorf("%w; the previous binary was restored", cause)
+}
+
+// VerifyAttestation checks with the GitHub CLI (gh) that the binary at path
+// was built and attested by the nomos release workflow. token, if not
+// empty, authenticates gh.
+func VerifyAttestation(ctx context.Context, path, token string) error {
+	gh, err := exec.LookPath("gh")
+	if err != nil {
+		return fmt.Errorf("verifying the release attestation requires the GitHub CLI (gh): %w", err)
+	}
+	//nolint:gosec // G204: Arguments are fixed apart from the staged binary
+	cmd := exec.CommandContext(ctx, gh, "attestation", "verify", path, "--repo", Owner+"/"+Repo, "--signer-workflow", SignerWorkflow)
+	cmd.Env = os.Environ()
+	if token != "" {
+		cmd.Env = append(cmd.Env, "GH_TOKEN="+token)
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("release binary is not attested by %s: %w: %s", SignerWorkflow, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// runVersion runs the binary at path with "version --quiet" and returns
+// its output.
+func runVersion(ctx context.Context, path string) (string, error) {
+	//nolint:gosec // G204: path is the binary being installed
+	out, err := exec.CommandContext(ctx, path, "version", "--quiet").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// executable returns path, or the running executable, with symbolic links
+// resolved so package manager shims are updated at their target.
+func executable(path string) (string, error) {
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate the nomos binary: %w", err)
+		}
+		path = exe
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the nomos binary: %w", err)
+	}
+	return resolved, nil
+}
+
+// normalize strips a leading "v" so "1.2.0" and "v1.2.0" compare equal.
+func normalize(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
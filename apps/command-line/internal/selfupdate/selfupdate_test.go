@@ -0,0 +1,213 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	downloader "github.com/autonomous-bits/nomos/libs/provider-downloader"
+)
+
+// newReleaseServer serves a nomos CLI release v1.2.0 for linux/amd64 whose
+// archive holds binary. The asset digest is omitted unless withDigest.
+func newReleaseServer(t *testing.T, binary []byte, withDigest bool) *downloader.Client {
+	t.Helper()
+
+	const name = "nomos-v1.2.0-linux-amd64"
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(binary))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(archive.Bytes())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/autonomous-bits/nomos/releases":
+			asset := map[string]any{
+				"name":                 name + ".tar.gz",
+				"browser_download_url": server.URL + "/download/" + name + ".tar.gz",
+				"size":                 archive.Len(),
+			}
+			if withDigest {
+				asset["digest"] = "sha256:" + hex.EncodeToString(sum[:])
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"tag_name": "libs/compiler/v3.0.0", "assets": []any{}},
+				{"tag_name": TagPrefix + "v1.2.0", "assets": []any{asset}},
+			})
+		case "/download/" + name + ".tar.gz":
+			_, _ = w.Write(archive.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return downloader.NewClient(&downloader.ClientOptions{BaseURL: server.URL, HTTPClient: server.Client()})
+}
+
+// writeExecutable writes the binary being updated and returns its path.
+func writeExecutable(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "nomos")
+	//nolint:gosec // G306: Test binary
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return exe
+}
+
+// probeFailing returns a probe that fails for the binary at path.
+func probeFailing(path string) func(context.Context, string) (string, error) {
+	return func(_ context.Context, p string) (string, error) {
+		if p == path || path == "" {
+			return "", errors.New("exec format error")
+		}
+		return "v1.2.0", nil
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	client := newReleaseServer(t, []byte("new"), true)
+	exe := writeExecutable(t)
+
+	result, err := Update(context.Background(), Options{
+		Client: client, Current: "v1.1.0", Executable: exe, OS: "linux", Arch: "amd64",
+		Probe: probeFailing("none"),
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !result.Updated || result.Version != "v1.2.0" || result.Asset != "nomos-v1.2.0-linux-amd64.tar.gz" {
+		t.Errorf("Update() = %+v, want v1.2.0 installed", result)
+	}
+	//nolint:gosec // G304: Test file read
+	if got, _ := os.ReadFile(exe); string(got) != "new" {
+		t.Errorf("binary = %q, want the release binary", got)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exe))
+	if len(entries) != 1 {
+		t.Errorf("directory holds %v, want only the binary", entries)
+	}
+}
+
+func TestUpdate_UpToDateAndDryRun(t *testing.T) {
+	client := newReleaseServer(t, []byte("new"), true)
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "up to date", opts: Options{Current: "1.2.0"}},
+		{name: "dry run", opts: Options{Current: "v1.1.0", DryRun: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exe := writeExecutable(t)
+			opts := tt.opts
+			opts.Client, opts.Executable, opts.OS, opts.Arch = client, exe, "linux", "amd64"
+			opts.Probe = probeFailing("")
+
+			result, err := Update(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if result.Updated || result.Version != "v1.2.0" {
+				t.Errorf("Update() = %+v, want v1.2.0 resolved but not installed", result)
+			}
+			//nolint:gosec // G304: Test file read
+			if got, _ := os.ReadFile(exe); string(got) != "old" {
+				t.Errorf("binary = %q, want it unchanged", got)
+			}
+		})
+	}
+}
+
+func TestUpdate_Failures(t *testing.T) {
+	tests := []struct {
+		name       string
+		withDigest bool
+		probe      func(exe string) func(context.Context, string) (string, error)
+		verify     func(context.Context, string) error
+		want       error
+		wantText   string
+	}{
+		{
+			name:  "no checksum",
+			probe: func(string) func(context.Context, string) (string, error) { return probeFailing("none") },
+			want:  ErrNoChecksum,
+		},
+		{
+			name:       "verification fails",
+			withDigest: true,
+			probe:      func(string) func(context.Context, string) (string, error) { return probeFailing("none") },
+			verify:     func(context.Context, string) error { return errors.New("not attested") },
+			wantText:   "not attested",
+		},
+		{
+			name:       "download does not run",
+			withDigest: true,
+			probe:      func(string) func(context.Context, string) (string, error) { return probeFailing("") },
+			want:       ErrBrokenBinary,
+		},
+		{
+			name:       "installed binary does not run",
+			withDigest: true,
+			probe:      probeFailing,
+			want:       ErrBrokenBinary,
+			wantText:   "previous binary was restored",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newReleaseServer(t, []byte("new"), tt.withDigest)
+			exe := writeExecutable(t)
+
+			_, err := Update(context.Background(), Options{
+				Client: client, Current: "v1.1.0", Executable: exe, OS: "linux", Arch: "amd64",
+				Probe: tt.probe(exe), Verify: tt.verify,
+			})
+			if err == nil {
+				t.Fatal("Update() succeeded, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Update() error = %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("Update() error = %v, want it to mention %q", err, tt.wantText)
+			}
+			//nolint:gosec // G304: Test file read
+			if got, _ := os.ReadFile(exe); string(got) != "old" {
+				t.Errorf("binary = %q, want the previous binary", got)
+			}
+			entries, _ := os.ReadDir(filepath.Dir(exe))
+			if len(entries) != 1 {
+				t.Errorf("directory holds %v, want only the binary", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// Decode reads a snapshot previously written by ToJSON or ToYAML.
+//
+// Both output shapes are accepted: data-only documents, and documents built
+// with includeMetadata that wrap the data in "data" and "metadata" sections.
+// A document is treated as wrapped when its root holds exactly those two
+// keys and both are maps.
+//
+// Integral numbers are decoded as int64 and all other numbers as float64, so
+// re-serializing a decoded snapshot preserves integer formatting.
+func Decode(data []byte, format OutputFormat) (compiler.Snapshot, error) {
+	var root any
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&root); err != nil {
+			return compiler.Snapshot{}, fmt.Errorf("failed to decode JSON snapshot: %w", err)
+		}
+		if dec.More() {
+			return compiler.Snapshot{}, fmt.Errorf("failed to decode JSON snapshot: unexpected data after top-level value")
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return compiler.Snapshot{}, fmt.Errorf("failed to decode YAML snapshot: %w", err)
+		}
+	default:
+		return compiler.Snapshot{}, fmt.Errorf("unsupported snapshot input format: %q (supported: json, yaml)", format)
+	}
+
+	doc, ok := normalizeDecoded(root).(map[string]any)
+	if !ok {
+		return compiler.Snapshot{}, fmt.Errorf("snapshot root must be a map, got %T", root)
+	}
+
+	dataSection, hasData := doc["data"].(map[string]any)
+	metaSection, hasMeta := doc["metadata"].(map[string]any)
+	if len(doc) != 2 || !hasData || !hasMeta {
+		return compiler.Snapshot{Data: doc}, nil
+	}
+
+	snapshot := compiler.Snapshot{Data: dataSection}
+	raw, err := json.Marshal(metaSection)
+	if err != nil {
+		return compiler.Snapshot{}, fmt.Errorf("failed to decode snapshot metadata: %w", err)
+	}
+	if err := json.Unmarshal(raw, &snapshot.Metadata); err != nil {
+		return compiler.Snapshot{}, fmt.Errorf("failed to decode snapshot metadata: %w", err)
+	}
+	return snapshot, nil
+}
+
+// normalizeDecoded converts decoder output to the value types produced by
+// the compiler: map[string]any, []any, string, bool, int64 and float64.
+func normalizeDecoded(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			val[k] = normalizeDecoded(child)
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = normalizeDecoded(child)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return val.String()
+		}
+		return f
+	case int:
+		return int64(val)
+	case uint64:
+		if val <= math.MaxInt64 {
+			return int64(val)
+		}
+		return float64(val)
+	default:
+		return v
+	}
+}
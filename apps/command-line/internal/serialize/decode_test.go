@@ -0,0 +1,95 @@
+package serialize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestDecode_RoundTrip tests that snapshots written by ToJSON and ToYAML
+// decode back to the same data and metadata.
+func TestDecode_RoundTrip(t *testing.T) {
+	snapshot := compiler.Snapshot{
+		Data: map[string]any{
+			"app":   "demo",
+			"port":  int64(8080),
+			"ratio": 0.5,
+			"tags":  []any{"a", true, nil},
+			"db":    map[string]any{"host": "localhost"},
+		},
+		Metadata: compiler.Metadata{
+			InputFiles: []string{"app.csl"},
+			StartTime:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:    time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+			PerKeyProvenance: map[string]compiler.Provenance{
+				"app": {Source: "app.csl"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format OutputFormat
+		encode func(compiler.Snapshot, bool) ([]byte, error)
+	}{
+		{"json", FormatJSON, ToJSON},
+		{"yaml", FormatYAML, ToYAML},
+	}
+
+	for _, tt := range tests {
+		for _, withMeta := range []bool{false, true} {
+			t.Run(tt.name, func(t *testing.T) {
+				encoded, err := tt.encode(snapshot, withMeta)
+				if err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+
+				got, err := Decode(encoded, tt.format)
+				if err != nil {
+					t.Fatalf("Decode() unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got.Data, snapshot.Data) {
+					t.Errorf("Data = %#v, want %#v", got.Data, snapshot.Data)
+				}
+
+				if !withMeta {
+					return
+				}
+				if !reflect.DeepEqual(got.Metadata.InputFiles, snapshot.Metadata.InputFiles) {
+					t.Errorf("InputFiles = %v, want %v", got.Metadata.InputFiles, snapshot.Metadata.InputFiles)
+				}
+				if !got.Metadata.EndTime.Equal(snapshot.Metadata.EndTime) {
+					t.Errorf("EndTime = %v, want %v", got.Metadata.EndTime, snapshot.Metadata.EndTime)
+				}
+				if got.Metadata.PerKeyProvenance["app"].Source != "app.csl" {
+					t.Errorf("PerKeyProvenance = %v", got.Metadata.PerKeyProvenance)
+				}
+			})
+		}
+	}
+}
+
+// TestDecode_Errors tests rejection of malformed and unsupported input.
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format OutputFormat
+	}{
+		{"invalid json", "{", FormatJSON},
+		{"trailing json", "{} {}", FormatJSON},
+		{"non-map root", "[1, 2]", FormatJSON},
+		{"invalid yaml", "a: [", FormatYAML},
+		{"tfvars input", "a = 1", FormatTfvars},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode([]byte(tt.input), tt.format); err == nil {
+				t.Errorf("Decode(%q) expected error", tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package serialize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FileType describes the files an output format produces.
+type FileType struct {
+	// Extensions lists the format's file extensions with their leading dot.
+	// The first is appended to output paths that have no extension.
+	Extensions []string
+
+	// MediaType is the MIME type of the output, e.g. "application/json".
+	MediaType string
+}
+
+// builtinFileTypes returns the file types of the built-in formats. HCL has
+// no registered media type, so tfvars is plain text.
+func builtinFileTypes() map[OutputFormat]FileType {
+	return map[OutputFormat]FileType{
+		FormatJSON:   {Extensions: []string{".json"}, MediaType: "application/json"},
+		FormatYAML:   {Extensions: []string{".yaml", ".yml"}, MediaType: "application/yaml"},
+		FormatTfvars: {Extensions: []string{".tfvars", ".auto.tfvars"}, MediaType: "text/plain"},
+	}
+}
+
+// FileTypes maps output formats, including custom:<name> formats, to their
+// file extensions and media types. Set must not be called concurrently with
+// lookups.
+type FileTypes struct {
+	types map[OutputFormat]FileType
+}
+
+// NewFileTypes returns file types holding the built-in formats: .json,
+// .yaml and .yml, and .tfvars and .auto.tfvars.
+func NewFileTypes() *FileTypes {
+	return &FileTypes{types: builtinFileTypes()}
+}
+
+// Set overrides the file type of format, or adds one for a custom format.
+// Empty fields of ft keep their current value. Extensions are lowercased
+// and must start with a dot.
+func (t *FileTypes) Set(format OutputFormat, ft FileType) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+	current := t.types[format]
+	if len(ft.Extensions) > 0 {
+		current.Extensions = make([]string, len(ft.Extensions))
+		for i, ext := range ft.Extensions {
+			ext = strings.ToLower(strings.TrimSpace(ext))
+			if len(ext) < 2 || ext[0] != '.' || strings.ContainsAny(ext, `/\`) {
+				return fmt.Errorf("invalid extension %q for format %s: extensions start with a dot, e.g. .yml", ext, format)
+			}
+			current.Extensions[i] = ext
+		}
+	}
+	if ft.MediaType != "" {
+		current.MediaType = ft.MediaType
+	}
+	t.types[format] = current
+	return nil
+}
+
+// Lookup returns the file type of format.
+func (t *FileTypes) Lookup(format OutputFormat) (FileType, bool) {
+	ft, ok := t.types[format]
+	return ft, ok
+}
+
+// Extension returns the default extension of format, or "" if it has none.
+func (t *FileTypes) Extension(format OutputFormat) string {
+	if ft := t.types[format]; len(ft.Extensions) > 0 {
+		return ft.Extensions[0]
+	}
+	return ""
+}
+
+// MediaType returns the media type of format, or "application/octet-stream"
+// if it has none.
+func (t *FileTypes) MediaType(format OutputFormat) string {
+	if ft := t.types[format]; ft.MediaType != "" {
+		return ft.MediaType
+	}
+	return "application/octet-stream"
+}
+
+// IsExtension reports whether name, ignoring case, is one of the
+// extensions of any format, such as ".yml".
+func (t *FileTypes) IsExtension(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ft := range t.types {
+		for _, ext := range ft.Extensions {
+			if ext == lower {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatOf returns the format whose extension ends path, ignoring case.
+// The longest matching extension wins and ties go to the alphabetically
+// first format. A path that is only an extension has no format.
+func (t *FileTypes) FormatOf(path string) (OutputFormat, bool) {
+	lower := strings.ToLower(path)
+	var best OutputFormat
+	bestLen := 0
+	for _, format := range t.formats() {
+		for _, ext := range t.types[format].Extensions {
+			if len(ext) > bestLen && len(lower) > len(ext) && strings.HasSuffix(lower, ext) {
+				best, bestLen = format, len(ext)
+			}
+		}
+	}
+	return best, bestLen > 0
+}
+
+// formats returns the registered formats in sorted order.
+func (t *FileTypes) formats() []OutputFormat {
+	formats := make([]OutputFormat, 0, len(t.types))
+	for f := range t.types {
+		formats = append(formats, f)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+}
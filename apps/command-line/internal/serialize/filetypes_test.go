@@ -0,0 +1,96 @@
+package serialize
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFileTypes_Builtin tests the extensions and media types of the built-in
+// formats.
+func TestFileTypes_Builtin(t *testing.T) {
+	types := NewFileTypes()
+	tests := []struct {
+		format    OutputFormat
+		extension string
+		mediaType string
+	}{
+		{format: FormatJSON, extension: ".json", mediaType: "application/json"},
+		{format: FormatYAML, extension: ".yaml", mediaType: "application/yaml"},
+		{format: FormatTfvars, extension: ".tfvars", mediaType: "text/plain"},
+		{format: "custom:toml", extension: "", mediaType: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := types.Extension(tt.format); got != tt.extension {
+			t.Errorf("Extension(%s) = %q, want %q", tt.format, got, tt.extension)
+		}
+		if got := types.MediaType(tt.format); got != tt.mediaType {
+			t.Errorf("MediaType(%s) = %q, want %q", tt.format, got, tt.mediaType)
+		}
+		if tt.format.IsCustom() {
+			continue
+		}
+		if got := tt.format.Extension(); got != tt.extension {
+			t.Errorf("%s.Extension() = %q, want %q", tt.format, got, tt.extension)
+		}
+	}
+}
+
+// TestFileTypes_FormatOf tests format detection from file names.
+func TestFileTypes_FormatOf(t *testing.T) {
+	types := NewFileTypes()
+	if err := types.Set("custom:toml", FileType{Extensions: []string{".TOML"}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   string
+		want   OutputFormat
+		wantOK bool
+	}{
+		{path: "out.json", want: FormatJSON, wantOK: true},
+		{path: "dir/out.YML", want: FormatYAML, wantOK: true},
+		{path: "prod.auto.tfvars", want: FormatTfvars, wantOK: true},
+		{path: "app.toml", want: "custom:toml", wantOK: true},
+		{path: ".json"},
+		{path: "out.json.bak"},
+		{path: "out"},
+	}
+	for _, tt := range tests {
+		got, ok := types.FormatOf(tt.path)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("FormatOf(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if !types.IsExtension(".Toml") || types.IsExtension("app.toml") {
+		t.Error("IsExtension does not match exact extensions only")
+	}
+}
+
+// TestFileTypes_Set tests overriding and validating file types.
+func TestFileTypes_Set(t *testing.T) {
+	types := NewFileTypes()
+	if err := types.Set(FormatYAML, FileType{Extensions: []string{".yml", ".yaml"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := types.Extension(FormatYAML); got != ".yml" {
+		t.Errorf("Extension(yaml) = %q, want .yml", got)
+	}
+	// The media type is kept when only extensions are set
+	if got := types.MediaType(FormatYAML); got != "application/yaml" {
+		t.Errorf("MediaType(yaml) = %q, want application/yaml", got)
+	}
+
+	for _, tt := range []struct {
+		format  OutputFormat
+		ft      FileType
+		wantErr string
+	}{
+		{format: FormatJSON, ft: FileType{Extensions: []string{"json"}}, wantErr: "invalid extension"},
+		{format: FormatJSON, ft: FileType{Extensions: []string{"./x.json"}}, wantErr: "invalid extension"},
+		{format: "toml", ft: FileType{Extensions: []string{".toml"}}, wantErr: "unsupported format"},
+	} {
+		if err := types.Set(tt.format, tt.ft); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Set(%s, %v) error = %v, want %q", tt.format, tt.ft, err, tt.wantErr)
+		}
+	}
+}
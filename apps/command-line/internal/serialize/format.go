@@ -1,6 +1,9 @@
 package serialize
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // OutputFormat represents supported serialization formats.
 type OutputFormat string
@@ -14,37 +17,53 @@ const (
 
 	// FormatTfvars is the HCL .tfvars output format.
 	FormatTfvars OutputFormat = "tfvars"
+
+	// FormatTemplate renders a user-provided Go template (see Template).
+	FormatTemplate OutputFormat = "template"
 )
 
+// CustomFormatPrefix selects a serializer from a Registry, e.g. "custom:toml".
+const CustomFormatPrefix = "custom:"
+
+// IsCustom reports whether the format names a registered custom serializer.
+func (f OutputFormat) IsCustom() bool {
+	return strings.HasPrefix(string(f), CustomFormatPrefix)
+}
+
+// CustomName returns the serializer name of a custom format ("toml" for
+// "custom:toml"), or "" if the format is not custom.
+func (f OutputFormat) CustomName() string {
+	if !f.IsCustom() {
+		return ""
+	}
+	return strings.TrimPrefix(string(f), CustomFormatPrefix)
+}
+
 // Validate checks if the format is supported.
-// Returns an error if the format is not one of: json, yaml, tfvars.
+// Returns an error if the format is not one of: json, yaml, tfvars,
+// template, or custom:<name>. Whether a custom serializer is actually
+// registered is checked when it is looked up in a Registry.
 //
 // Note: Validation is case-sensitive. Use strings.ToLower() before
 // calling Validate() if case-insensitive format selection is needed.
 func (f OutputFormat) Validate() error {
 	switch f {
-	case FormatJSON, FormatYAML, FormatTfvars:
+	case FormatJSON, FormatYAML, FormatTfvars, FormatTemplate:
 		return nil
 	default:
-		return fmt.Errorf("unsupported format: %q (supported: json, yaml, tfvars)", f)
+		if f.IsCustom() && f.CustomName() != "" {
+			return nil
+		}
+		return fmt.Errorf("unsupported format: %q (supported: json, yaml, tfvars, template, custom:<name>)", f)
 	}
 }
 
-// Extension returns the default file extension for this format.
-// Returns:
-//   - ".json" for FormatJSON
-//   - ".yaml" for FormatYAML
-//   - ".tfvars" for FormatTfvars
-//   - "" (empty string) for invalid formats
+// Extension returns the default file extension of a built-in format:
+// ".json", ".yaml", or ".tfvars". It returns "" for the template, custom
+// and invalid formats; use FileTypes for extensions configured per project.
 func (f OutputFormat) Extension() string {
-	switch f {
-	case FormatJSON:
-		return ".json"
-	case FormatYAML:
-		return ".yaml"
-	case FormatTfvars:
-		return ".tfvars"
-	default:
-		return ""
+	if ft, ok := builtinFileTypes()[f]; ok {
+		return ft.Extensions[0]
 	}
+	return ""
 }
@@ -28,6 +28,11 @@ func TestOutputFormat_Validate(t *testing.T) {
 			format:  FormatTfvars,
 			wantErr: false,
 		},
+		{
+			name:    "template format valid",
+			format:  FormatTemplate,
+			wantErr: false,
+		},
 		{
 			name:    "invalid format",
 			format:  OutputFormat("invalid"),
@@ -160,6 +165,7 @@ func TestOutputFormat_ErrorMessage(t *testing.T) {
 		"json",
 		"yaml",
 		"tfvars",
+		"template",
 	}
 
 	for _, substring := range requiredSubstrings {
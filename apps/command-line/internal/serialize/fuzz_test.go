@@ -0,0 +1,62 @@
+package serialize
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzSerialize checks that every built-in serializer accepts any snapshot
+// Decode produces, and that JSON and YAML output decodes again to a
+// snapshot that serializes to the same bytes.
+//
+// The seed corpus is in testdata/fuzz/FuzzSerialize. Run with:
+//
+//	go test -run '^$' -fuzz FuzzSerialize ./internal/serialize
+func FuzzSerialize(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input []byte, includeMetadata bool) {
+		snapshot, err := Decode(input, FormatJSON)
+		if err != nil {
+			return
+		}
+
+		for format, serialize := range map[OutputFormat]SerializerFunc{FormatJSON: ToJSON, FormatYAML: ToYAML} {
+			out, err := serialize(snapshot, includeMetadata)
+			if err != nil {
+				t.Fatalf("%s: serialize error = %v", format, err)
+			}
+			decoded, err := Decode(out, format)
+			if err != nil {
+				t.Fatalf("%s: Decode of own output error = %v\n%s", format, err, out)
+			}
+			again, err := serialize(decoded, includeMetadata)
+			if err != nil {
+				t.Fatalf("%s: serialize of decoded snapshot error = %v", format, err)
+			}
+			if !bytes.Equal(out, again) {
+				t.Errorf("%s: output changed after a round trip:\n%s\nthen\n%s", format, out, again)
+			}
+		}
+
+		// tfvars rejects keys Terraform cannot name; it must not panic
+		_, _ = ToTfvars(snapshot, includeMetadata)
+	})
+}
+
+// FuzzDecode checks that Decode returns an error rather than panicking on
+// malformed JSON and YAML input.
+//
+// The seed corpus is in testdata/fuzz/FuzzDecode. Run with:
+//
+//	go test -run '^$' -fuzz FuzzDecode ./internal/serialize
+func FuzzDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input []byte, yamlInput bool) {
+		format := FormatJSON
+		if yamlInput {
+			format = FormatYAML
+		}
+		snapshot, err := Decode(input, format)
+		if err == nil && snapshot.Data == nil {
+			t.Errorf("Decode(%q) returned no data and no error", input)
+		}
+	})
+}
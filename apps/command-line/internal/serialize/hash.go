@@ -0,0 +1,21 @@
+package serialize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// ContentHash returns the hex SHA-256 of the snapshot's data encoded as
+// minified canonical JSON. It depends only on the data, not on metadata,
+// key order options, or the output format, so equal configurations hash
+// equally across builds and machines.
+func ContentHash(snapshot compiler.Snapshot) (string, error) {
+	h := sha256.New()
+	opts := Options{JSON: JSONFormat{Minify: true}}
+	if err := opts.WriteJSON(h, compiler.Snapshot{Data: snapshot.Data}, false); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,30 @@
+package serialize
+
+import (
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestContentHash tests that the hash covers the data only.
+func TestContentHash(t *testing.T) {
+	snapshot := orderSnapshot()
+	want, err := ContentHash(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 64 {
+		t.Errorf("ContentHash() = %q, want 64 hex digits", want)
+	}
+
+	// Metadata and source maps do not change the hash
+	stripped := compiler.Snapshot{Data: snapshot.Data}
+	if got, _ := ContentHash(stripped); got != want {
+		t.Errorf("ContentHash() without metadata = %s, want %s", got, want)
+	}
+
+	changed := compiler.Snapshot{Data: map[string]any{"kind": "Other"}}
+	if got, _ := ContentHash(changed); got == want {
+		t.Error("ContentHash() equal for different data")
+	}
+}
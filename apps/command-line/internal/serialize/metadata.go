@@ -0,0 +1,89 @@
+package serialize
+
+import (
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/snapshotmeta"
+)
+
+// metadataEnvelope converts compiler metadata to the published metadata
+// schema.
+func metadataEnvelope(m compiler.Metadata) snapshotmeta.Metadata {
+	env := snapshotmeta.Metadata{
+		SchemaVersion:           snapshotmeta.SchemaVersion,
+		StartTime:               m.StartTime,
+		EndTime:                 m.EndTime,
+		InputFiles:              m.InputFiles,
+		ProviderAliases:         m.ProviderAliases,
+		Errors:                  m.Errors,
+		Warnings:                m.Warnings,
+		TypeCoercion:            string(m.TypeCoercion),
+		SensitiveKeys:           m.SensitiveKeys,
+		ProjectRoot:             m.ProjectRoot,
+		ProviderConfigOverrides: m.ProviderConfigOverrides,
+		Profiles:                m.Profiles,
+	}
+	if m.PerKeyProvenance != nil {
+		env.PerKeyProvenance = make(map[string]snapshotmeta.Provenance, len(m.PerKeyProvenance))
+		for k, p := range m.PerKeyProvenance {
+			env.PerKeyProvenance[k] = snapshotmeta.Provenance{Source: p.Source, ProviderAlias: p.ProviderAlias}
+		}
+	}
+	for _, o := range m.ListOrders {
+		env.ListOrders = append(env.ListOrders, snapshotmeta.ListOrder(o))
+	}
+	return env
+}
+
+// metadataValue returns the metadata envelope as a generic map keyed by the
+// schema's JSON field names, ready for canonical serialization. Every
+// serializer writes metadata through this function so that all formats
+// carry exactly the fields of snapshotmeta.Metadata.
+func metadataValue(m compiler.Metadata) map[string]any {
+	env := metadataEnvelope(m)
+
+	var provenance map[string]any
+	if env.PerKeyProvenance != nil {
+		provenance = make(map[string]any, len(env.PerKeyProvenance))
+		for k, p := range env.PerKeyProvenance {
+			provenance[k] = map[string]any{
+				"provider_alias": p.ProviderAlias,
+				"source":         p.Source,
+			}
+		}
+	}
+
+	var configOverrides map[string]any
+	if env.ProviderConfigOverrides != nil {
+		configOverrides = make(map[string]any, len(env.ProviderConfigOverrides))
+		for alias, config := range env.ProviderConfigOverrides {
+			configOverrides[alias] = config
+		}
+	}
+
+	var listOrders []any
+	for _, o := range env.ListOrders {
+		listOrders = append(listOrders, map[string]any{
+			"content_hash": o.ContentHash,
+			"order_hash":   o.OrderHash,
+			"provider":     o.Provider,
+			"reference":    o.Reference,
+		})
+	}
+
+	return map[string]any{
+		"end_time":                  env.EndTime,
+		"errors":                    env.Errors,
+		"input_files":               env.InputFiles,
+		"list_orders":               listOrders,
+		"per_key_provenance":        provenance,
+		"profiles":                  env.Profiles,
+		"project_root":              env.ProjectRoot,
+		"provider_aliases":          env.ProviderAliases,
+		"provider_config_overrides": configOverrides,
+		"schema_version":            env.SchemaVersion,
+		"sensitive_keys":            env.SensitiveKeys,
+		"start_time":                env.StartTime,
+		"type_coercion":             env.TypeCoercion,
+		"warnings":                  env.Warnings,
+	}
+}
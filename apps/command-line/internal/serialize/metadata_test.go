@@ -0,0 +1,120 @@
+package serialize
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/snapshotmeta"
+	"gopkg.in/yaml.v3"
+)
+
+// TestMetadata_MatchesSchema verifies that JSON and YAML metadata carry
+// exactly the fields of the published snapshotmeta.Metadata type.
+func TestMetadata_MatchesSchema(t *testing.T) {
+	var want []string
+	typ := reflect.TypeFor[snapshotmeta.Metadata]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		want = append(want, name)
+	}
+	sort.Strings(want)
+
+	snapshot := compiler.Snapshot{
+		Data: map[string]any{"app": "demo"},
+		Metadata: compiler.Metadata{
+			StartTime:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:          time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+			InputFiles:       []string{"app.csl"},
+			PerKeyProvenance: map[string]compiler.Provenance{"app": {Source: "app.csl"}},
+			TypeCoercion:     compiler.TypeCoercionStrict,
+		},
+	}
+
+	jsonOut, err := ToJSON(snapshot, true)
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
+	yamlOut, err := ToYAML(snapshot, true)
+	if err != nil {
+		t.Fatalf("ToYAML() error: %v", err)
+	}
+
+	for format, unmarshal := range map[string]func() (map[string]any, error){
+		"json": func() (map[string]any, error) {
+			var doc map[string]map[string]any
+			err := json.Unmarshal(jsonOut, &doc)
+			return doc["metadata"], err
+		},
+		"yaml": func() (map[string]any, error) {
+			var doc map[string]map[string]any
+			err := yaml.Unmarshal(yamlOut, &doc)
+			return doc["metadata"], err
+		},
+	} {
+		meta, err := unmarshal()
+		if err != nil {
+			t.Fatalf("%s: failed to decode output: %v", format, err)
+		}
+		var got []string
+		for k := range meta {
+			got = append(got, k)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s metadata keys = %v, want %v", format, got, want)
+		}
+	}
+
+	doc, err := snapshotmeta.Decode(jsonOut)
+	if err != nil {
+		t.Fatalf("snapshotmeta.Decode() error: %v", err)
+	}
+	if doc.Metadata.SchemaVersion != snapshotmeta.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", doc.Metadata.SchemaVersion, snapshotmeta.SchemaVersion)
+	}
+	if doc.Metadata.PerKeyProvenance["app"].Source != "app.csl" || doc.Metadata.TypeCoercion != "strict" {
+		t.Errorf("Metadata = %+v", doc.Metadata)
+	}
+}
+
+// TestMetadata_LoadSnapshot verifies that compiler.ParseSnapshot reads back
+// JSON and YAML written with metadata.
+func TestMetadata_LoadSnapshot(t *testing.T) {
+	snapshot := compiler.Snapshot{
+		Data: map[string]any{"app": map[string]any{"name": "demo", "tags": []any{"web"}}},
+		Metadata: compiler.Metadata{
+			StartTime:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:          time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+			InputFiles:       []string{"app.csl"},
+			PerKeyProvenance: map[string]compiler.Provenance{"app": {Source: "app.csl"}},
+			TypeCoercion:     compiler.TypeCoercionStrict,
+			ProjectRoot:      "/src",
+		},
+	}
+
+	for format, serialize := range map[string]func(compiler.Snapshot, bool) ([]byte, error){"json": ToJSON, "yaml": ToYAML} {
+		out, err := serialize(snapshot, true)
+		if err != nil {
+			t.Fatalf("%s: serialize error: %v", format, err)
+		}
+		loaded, err := compiler.ParseSnapshot(out)
+		if err != nil {
+			t.Fatalf("%s: ParseSnapshot() error: %v", format, err)
+		}
+		if !reflect.DeepEqual(loaded.Data, snapshot.Data) {
+			t.Errorf("%s: Data = %#v, want %#v", format, loaded.Data, snapshot.Data)
+		}
+		if !loaded.Metadata.EndTime.Equal(snapshot.Metadata.EndTime) || loaded.Metadata.ProjectRoot != "/src" ||
+			loaded.Metadata.PerKeyProvenance["app"].Source != "app.csl" {
+			t.Errorf("%s: Metadata = %+v", format, loaded.Metadata)
+		}
+		if tag, ok := loaded.Lookup("app.tags[0]"); !ok || tag != "web" {
+			t.Errorf("%s: Lookup(app.tags[0]) = %v, %v", format, tag, ok)
+		}
+	}
+}
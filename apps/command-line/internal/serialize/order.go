@@ -0,0 +1,240 @@
+package serialize
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// KeyOrderPolicy names a rule for ordering map keys in serialized data.
+type KeyOrderPolicy string
+
+const (
+	// KeyOrderAlphabetical sorts keys bytewise. It is the default.
+	KeyOrderAlphabetical KeyOrderPolicy = "alphabetical"
+
+	// KeyOrderSource orders keys by the .csl location that defined them,
+	// following the snapshot's source map.
+	KeyOrderSource KeyOrderPolicy = "source"
+
+	// KeyOrderPriority writes the keys named in KeyOrder.Priority first, in
+	// that order, and the rest alphabetically.
+	KeyOrderPriority KeyOrderPolicy = "priority"
+)
+
+// KeyOrder selects how map keys in the data section are ordered. Metadata
+// keys are always sorted, and list order is never changed. The zero value
+// orders keys alphabetically.
+//
+// Every policy is deterministic: keys the policy does not rank (keys
+// without a source map entry, or not named in Priority) follow the ranked
+// keys alphabetically.
+type KeyOrder struct {
+	// Policy is the ordering rule; empty means KeyOrderAlphabetical.
+	Policy KeyOrderPolicy
+
+	// Priority lists the key names written first under KeyOrderPriority.
+	// Names match at every depth, e.g. [apiVersion kind metadata] for
+	// Kubernetes manifests.
+	Priority []string
+}
+
+// ParseKeyOrder parses a key order as written on the command line and in
+// .nomos/config.yaml: "alphabetical", "source", or "priority:" followed by
+// comma-separated key names.
+func ParseKeyOrder(spec string) (KeyOrder, error) {
+	spec = strings.TrimSpace(spec)
+	policy, list, hasList := strings.Cut(spec, ":")
+	switch KeyOrderPolicy(strings.ToLower(policy)) {
+	case "", KeyOrderAlphabetical:
+		if !hasList {
+			return KeyOrder{}, nil
+		}
+	case KeyOrderSource:
+		if !hasList {
+			return KeyOrder{Policy: KeyOrderSource}, nil
+		}
+	case KeyOrderPriority:
+		var priority []string
+		for _, key := range strings.Split(list, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				priority = append(priority, key)
+			}
+		}
+		if len(priority) == 0 {
+			return KeyOrder{}, fmt.Errorf("invalid key order %q: priority needs at least one key, e.g. priority:name,version", spec)
+		}
+		return KeyOrder{Policy: KeyOrderPriority, Priority: priority}, nil
+	}
+	return KeyOrder{}, fmt.Errorf("invalid key order %q (supported: alphabetical, source, priority:<key>,<key>...)", spec)
+}
+
+// String returns the key order in the form ParseKeyOrder accepts.
+func (o KeyOrder) String() string {
+	switch o.Policy {
+	case "":
+		return string(KeyOrderAlphabetical)
+	case KeyOrderPriority:
+		return string(KeyOrderPriority) + ":" + strings.Join(o.Priority, ",")
+	default:
+		return string(o.Policy)
+	}
+}
+
+// keyOrderer orders the keys of data maps for one snapshot and, for YAML,
+// looks up their comments. A nil *keyOrderer sorts alphabetically.
+type keyOrderer struct {
+	policy   KeyOrderPolicy
+	priority map[string]int
+	files    map[string]int
+	comments bool
+
+	// entries is the source map, set for source order and comments; key
+	// paths are only tracked when it is.
+	entries map[string]compiler.SourceMapEntry
+}
+
+// newKeyOrderer prepares o for snapshot. It returns nil for alphabetical
+// order, so the default path does no extra work.
+func newKeyOrderer(o KeyOrder, snapshot compiler.Snapshot) (*keyOrderer, error) {
+	switch o.Policy {
+	case "", KeyOrderAlphabetical:
+		return nil, nil
+	case KeyOrderPriority:
+		priority := make(map[string]int, len(o.Priority))
+		for i, key := range o.Priority {
+			if _, dup := priority[key]; !dup {
+				priority[key] = i
+			}
+		}
+		return &keyOrderer{policy: o.Policy, priority: priority}, nil
+	case KeyOrderSource:
+		if snapshot.SourceMap == nil {
+			return nil, fmt.Errorf("source key order needs a source map; compile with source maps enabled")
+		}
+		files := make(map[string]int, len(snapshot.Metadata.InputFiles))
+		for i, file := range snapshot.Metadata.InputFiles {
+			files[file] = i
+		}
+		return &keyOrderer{policy: o.Policy, entries: snapshot.SourceMap.Entries, files: files}, nil
+	default:
+		return nil, fmt.Errorf("unknown key order policy %q", o.Policy)
+	}
+}
+
+// withComments returns a copy of o that also looks up key comments in the
+// snapshot's source map.
+func (o *keyOrderer) withComments(snapshot compiler.Snapshot) (*keyOrderer, error) {
+	if snapshot.SourceMap == nil {
+		return nil, fmt.Errorf("YAML comments need a source map; compile with source maps enabled")
+	}
+	c := keyOrderer{policy: KeyOrderAlphabetical}
+	if o != nil {
+		c = *o
+	}
+	c.entries, c.comments = snapshot.SourceMap.Entries, true
+	return &c, nil
+}
+
+// comment returns the source comment of the key at keyPath, or "" when
+// comments are off.
+func (o *keyOrderer) comment(keyPath string) string {
+	if o == nil || !o.comments {
+		return ""
+	}
+	return o.entries[keyPath].Comment
+}
+
+// keys returns the keys of m, the map at keyPath, in output order.
+func (o *keyOrderer) keys(keyPath string, m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if o == nil {
+		return keys
+	}
+
+	switch o.policy {
+	case KeyOrderPriority:
+		sort.SliceStable(keys, func(i, j int) bool {
+			pi, iRanked := o.priority[keys[i]]
+			pj, jRanked := o.priority[keys[j]]
+			if iRanked != jRanked {
+				return iRanked
+			}
+			return iRanked && pi < pj
+		})
+	case KeyOrderSource:
+		locations := make([]*compiler.SourceLocation, len(keys))
+		for i, k := range keys {
+			if entry, ok := o.entries[o.mapChild(keyPath, k)]; ok {
+				locations[i] = &entry.Location
+			}
+		}
+		sort.Stable(bySource{keys: keys, locations: locations, files: o.files})
+	}
+	return keys
+}
+
+// mapChild returns the key path of key k in the map at keyPath. Paths are
+// only tracked for source order and comments; otherwise it returns "".
+func (o *keyOrderer) mapChild(keyPath, k string) string {
+	if o == nil || o.entries == nil {
+		return ""
+	}
+	if keyPath == "" {
+		return k
+	}
+	return keyPath + "." + k
+}
+
+// listChild returns the key path of element i of the list at keyPath, or ""
+// when paths are not tracked.
+func (o *keyOrderer) listChild(keyPath string, i int) string {
+	if o == nil || o.entries == nil {
+		return ""
+	}
+	return keyPath + "[" + strconv.Itoa(i) + "]"
+}
+
+// bySource sorts keys by the position of their source locations: input
+// file order, then line and column. Keys without a location sort last.
+type bySource struct {
+	keys      []string
+	locations []*compiler.SourceLocation
+	files     map[string]int
+}
+
+func (s bySource) Len() int { return len(s.keys) }
+
+func (s bySource) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.locations[i], s.locations[j] = s.locations[j], s.locations[i]
+}
+
+func (s bySource) Less(i, j int) bool {
+	a, b := s.locations[i], s.locations[j]
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	if a.File != b.File {
+		fa, aKnown := s.files[a.File]
+		fb, bKnown := s.files[b.File]
+		if aKnown != bKnown {
+			return aKnown
+		}
+		if aKnown && fa != fb {
+			return fa < fb
+		}
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
@@ -0,0 +1,264 @@
+package serialize
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestParseKeyOrder tests parsing of --key-order and key_order values.
+func TestParseKeyOrder(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    KeyOrder
+		wantErr string
+	}{
+		{spec: "", want: KeyOrder{}},
+		{spec: "alphabetical", want: KeyOrder{}},
+		{spec: "Source", want: KeyOrder{Policy: KeyOrderSource}},
+		{spec: "priority:apiVersion, kind,,metadata", want: KeyOrder{Policy: KeyOrderPriority, Priority: []string{"apiVersion", "kind", "metadata"}}},
+		{spec: "priority:", wantErr: "priority needs at least one key"},
+		{spec: "priority", wantErr: "priority needs at least one key"},
+		{spec: "source:app", wantErr: "invalid key order"},
+		{spec: "reverse", wantErr: "invalid key order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseKeyOrder(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseKeyOrder(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyOrder(%q) error = %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeyOrder(%q) = %#v, want %#v", tt.spec, got, tt.want)
+			}
+
+			// String round-trips
+			again, err := ParseKeyOrder(got.String())
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ParseKeyOrder(%q) = %#v, %v, want %#v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+// orderSnapshot returns a snapshot whose source map defines keys out of
+// alphabetical order across two files.
+func orderSnapshot() compiler.Snapshot {
+	loc := func(file string, line int) compiler.SourceMapEntry {
+		return compiler.SourceMapEntry{Location: compiler.SourceLocation{File: file, Line: line, Column: 1}}
+	}
+	return compiler.Snapshot{
+		Data: map[string]any{
+			"service": map[string]any{
+				"port":  "8080",
+				"name":  "api",
+				"debug": "false",
+				"tags":  []any{map[string]any{"zulu": "1", "alpha": "2"}},
+			},
+			"kind":       "Service",
+			"apiVersion": "v1",
+			"extra":      "from provider",
+		},
+		Metadata: compiler.Metadata{
+			InputFiles: []string{"/src/a.csl", "/src/b.csl"},
+			PerKeyProvenance: map[string]compiler.Provenance{
+				"service": {Source: "/src/b.csl"},
+				"kind":    {Source: "/src/a.csl"},
+			},
+		},
+		SourceMap: &compiler.SourceMap{Version: compiler.SourceMapVersion, Entries: map[string]compiler.SourceMapEntry{
+			"kind":                  loc("/src/a.csl", 1),
+			"apiVersion":            loc("/src/a.csl", 2),
+			"service":               loc("/src/b.csl", 1),
+			"service.port":          loc("/src/b.csl", 2),
+			"service.name":          loc("/src/b.csl", 3),
+			"service.tags":          loc("/src/b.csl", 4),
+			"service.tags[0].zulu":  loc("/src/b.csl", 5),
+			"service.tags[0].alpha": loc("/src/b.csl", 6),
+			"service.debug":         loc("/src/a.csl", 9),
+			"unrelated.key.path":    loc("/src/c.csl", 1),
+		}},
+	}
+}
+
+// keyOrderOf returns the given keys in the order they first appear in
+// output.
+func keyOrderOf(t *testing.T, output []byte, keys ...string) []string {
+	t.Helper()
+	type found struct {
+		key string
+		at  int
+	}
+	var positions []found
+	for _, k := range keys {
+		at := bytes.Index(output, []byte(k))
+		if at < 0 {
+			t.Fatalf("key %q not in output:\n%s", k, output)
+		}
+		positions = append(positions, found{k, at})
+	}
+	for i := range positions {
+		for j := i + 1; j < len(positions); j++ {
+			if positions[j].at < positions[i].at {
+				positions[i], positions[j] = positions[j], positions[i]
+			}
+		}
+	}
+	ordered := make([]string, len(positions))
+	for i, p := range positions {
+		ordered[i] = p.key
+	}
+	return ordered
+}
+
+// TestOptions_KeyOrder tests each policy in every built-in format.
+func TestOptions_KeyOrder(t *testing.T) {
+	snapshot := orderSnapshot()
+	topLevel := []string{"apiVersion", "extra", "kind", "service"}
+	nested := []string{"port", "name", "debug", "tags"}
+	inList := []string{"zulu", "alpha"}
+
+	tests := []struct {
+		name       string
+		order      KeyOrder
+		wantTop    []string
+		wantNested []string
+		wantInList []string
+	}{
+		{
+			name:       "alphabetical",
+			wantTop:    []string{"apiVersion", "extra", "kind", "service"},
+			wantNested: []string{"debug", "name", "port", "tags"},
+			wantInList: []string{"alpha", "zulu"},
+		},
+		{
+			// debug is defined in the earlier input file; extra has no entry
+			name:       "source",
+			order:      KeyOrder{Policy: KeyOrderSource},
+			wantTop:    []string{"kind", "apiVersion", "service", "extra"},
+			wantNested: []string{"debug", "port", "name", "tags"},
+			wantInList: []string{"zulu", "alpha"},
+		},
+		{
+			name:       "priority",
+			order:      KeyOrder{Policy: KeyOrderPriority, Priority: []string{"kind", "apiVersion", "name", "zulu"}},
+			wantTop:    []string{"kind", "apiVersion", "extra", "service"},
+			wantNested: []string{"name", "debug", "port", "tags"},
+			wantInList: []string{"zulu", "alpha"},
+		},
+	}
+	formats := map[string]func(Options) ([]byte, error){
+		"json":   func(o Options) ([]byte, error) { return o.ToJSON(snapshot, false) },
+		"yaml":   func(o Options) ([]byte, error) { return o.ToYAML(snapshot, false) },
+		"tfvars": func(o Options) ([]byte, error) { return o.ToTfvars(snapshot, false) },
+	}
+	quote := map[string]func(string) string{
+		"json":   func(k string) string { return `"` + k + `"` },
+		"yaml":   func(k string) string { return k + ":" },
+		"tfvars": func(k string) string { return k + " =" },
+	}
+	for _, tt := range tests {
+		for format, serialize := range formats {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				output, err := serialize(Options{KeyOrder: tt.order})
+				if err != nil {
+					t.Fatalf("serialize error = %v", err)
+				}
+				q := quote[format]
+				check := func(keys, want []string) {
+					t.Helper()
+					quoted := make([]string, len(keys))
+					wantQuoted := make([]string, len(want))
+					for i := range keys {
+						quoted[i], wantQuoted[i] = q(keys[i]), q(want[i])
+					}
+					if got := keyOrderOf(t, output, quoted...); !reflect.DeepEqual(got, wantQuoted) {
+						t.Errorf("order = %q, want %q\n%s", got, wantQuoted, output)
+					}
+				}
+				check(topLevel, tt.wantTop)
+				check(nested, tt.wantNested)
+				check(inList, tt.wantInList)
+			})
+		}
+	}
+}
+
+// TestOptions_KeyOrder_Alphabetical tests that the zero Options matches the
+// package-level serializers byte for byte.
+func TestOptions_KeyOrder_Alphabetical(t *testing.T) {
+	snapshot := orderSnapshot()
+	for _, includeMetadata := range []bool{false, true} {
+		want, _ := ToJSON(snapshot, includeMetadata)
+		got, err := Options{KeyOrder: KeyOrder{Policy: KeyOrderAlphabetical}}.ToJSON(snapshot, includeMetadata)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("JSON differs (err %v):\n%s\nwant\n%s", err, got, want)
+		}
+		want, _ = ToYAML(snapshot, includeMetadata)
+		got, err = Options{}.ToYAML(snapshot, includeMetadata)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("YAML differs (err %v):\n%s\nwant\n%s", err, got, want)
+		}
+	}
+}
+
+// TestOptions_KeyOrder_Metadata tests that metadata keys stay sorted under
+// other data orders.
+func TestOptions_KeyOrder_Metadata(t *testing.T) {
+	snapshot := orderSnapshot()
+	opts := Options{KeyOrder: KeyOrder{Policy: KeyOrderPriority, Priority: []string{"source", "service", "kind"}}}
+
+	for name, serialize := range map[string]func() ([]byte, error){
+		"json": func() ([]byte, error) { return opts.ToJSON(snapshot, true) },
+		"yaml": func() ([]byte, error) { return opts.ToYAML(snapshot, true) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			output, err := serialize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			metadata := output[bytes.Index(output, []byte("metadata")):]
+			// Provenance entries and their fields stay alphabetical
+			if got := keyOrderOf(t, metadata, "kind", "service"); got[0] != "kind" {
+				t.Errorf("per_key_provenance order = %v, want alphabetical\n%s", got, metadata)
+			}
+			if got := keyOrderOf(t, metadata, "provider_alias", "source"); got[0] != "provider_alias" {
+				t.Errorf("provenance field order = %v, want alphabetical\n%s", got, metadata)
+			}
+			// Data before metadata, data keys by priority
+			if got := keyOrderOf(t, output, "data", "metadata"); got[0] != "data" {
+				t.Errorf("section order = %v", got)
+			}
+			if got := keyOrderOf(t, output, "service", "kind"); got[0] != "service" {
+				t.Errorf("data order = %v, want service first\n%s", got, output)
+			}
+		})
+	}
+}
+
+// TestOptions_KeyOrder_SourceNeedsSourceMap tests that source order is
+// refused for snapshots compiled without a source map.
+func TestOptions_KeyOrder_SourceNeedsSourceMap(t *testing.T) {
+	snapshot := orderSnapshot()
+	snapshot.SourceMap = nil
+	opts := Options{KeyOrder: KeyOrder{Policy: KeyOrderSource}}
+
+	if _, err := opts.ToJSON(snapshot, false); err == nil || !strings.Contains(err.Error(), "needs a source map") {
+		t.Errorf("ToJSON error = %v, want source map error", err)
+	}
+	if err := opts.WriteYAML(&bytes.Buffer{}, snapshot, false); err == nil {
+		t.Error("WriteYAML succeeded without a source map")
+	}
+	if _, err := opts.ToTfvars(snapshot, false); err == nil {
+		t.Error("ToTfvars succeeded without a source map")
+	}
+}
@@ -0,0 +1,186 @@
+package serialize
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// Serializer converts a compiled snapshot into an output format.
+//
+// Custom serializers are selected with --format custom:<name>. They are
+// registered in a Registry, either in-process or from external programs:
+//
+//   - Subprocess serializers (see NewCommandSerializer) receive the snapshot
+//     as canonical JSON on stdin and write the formatted output to stdout.
+//   - Go plugins (see LoadPlugin) export a Serialize symbol of type
+//     func([]byte) ([]byte, error) that receives the same JSON document.
+type Serializer interface {
+	Serialize(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error)
+}
+
+// SerializerFunc adapts an ordinary function to the Serializer interface.
+type SerializerFunc func(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error)
+
+// Serialize calls f(snapshot, includeMetadata).
+func (f SerializerFunc) Serialize(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	return f(snapshot, includeMetadata)
+}
+
+// CommandPrefix is the executable name prefix used to discover subprocess
+// serializers on PATH: format custom:toml runs "nomos-serializer-toml".
+const CommandPrefix = "nomos-serializer-"
+
+// Registry holds custom serializers by name. It is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	serializers map[string]Serializer
+}
+
+// NewRegistry creates an empty serializer registry.
+func NewRegistry() *Registry {
+	return &Registry{serializers: make(map[string]Serializer)}
+}
+
+// Register adds a serializer under name. Names are case-insensitive.
+// Registering the same name twice is an error.
+func (r *Registry) Register(name string, s Serializer) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("serializer name must not be empty")
+	}
+	if s == nil {
+		return fmt.Errorf("serializer %q must not be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.serializers[name]; exists {
+		return fmt.Errorf("serializer %q already registered", name)
+	}
+	r.serializers[name] = s
+	return nil
+}
+
+// Lookup returns the serializer registered under name. If none is
+// registered, an executable named CommandPrefix+name on PATH is used.
+func (r *Registry) Lookup(name string) (Serializer, error) {
+	name = strings.ToLower(name)
+
+	if r != nil {
+		r.mu.RLock()
+		s, ok := r.serializers[name]
+		r.mu.RUnlock()
+		if ok {
+			return s, nil
+		}
+	}
+
+	if path, err := exec.LookPath(CommandPrefix + name); err == nil {
+		return NewCommandSerializer([]string{path}), nil
+	}
+
+	return nil, fmt.Errorf("unknown custom serializer %q (registered: %s; or install %s%s on PATH)",
+		name, strings.Join(r.Names(), ", "), CommandPrefix, name)
+}
+
+// Names returns the registered serializer names in sorted order.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.serializers))
+	for name := range r.serializers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commandSerializer runs an external program speaking the stdin/stdout protocol.
+type commandSerializer struct {
+	argv []string
+}
+
+// NewCommandSerializer returns a Serializer that runs argv, writes the
+// canonical JSON snapshot (as produced by ToJSON) to its stdin, and returns
+// its stdout. A non-zero exit status is reported with the program's stderr.
+func NewCommandSerializer(argv []string) Serializer {
+	return &commandSerializer{argv: append([]string(nil), argv...)}
+}
+
+func (c *commandSerializer) Serialize(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	if len(c.argv) == 0 {
+		return nil, fmt.Errorf("serializer command must not be empty")
+	}
+
+	input, err := ToJSON(snapshot, includeMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(c.argv[0], c.argv[1:]...) //nolint:gosec,noctx // G204: Command comes from project configuration
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("serializer %s failed: %w: %s", c.argv[0], err, msg)
+		}
+		return nil, fmt.Errorf("serializer %s failed: %w", c.argv[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// pluginSerializer calls the Serialize function exported by a Go plugin.
+type pluginSerializer struct {
+	serialize func([]byte) ([]byte, error)
+}
+
+// LoadPlugin opens the Go plugin at path and returns a Serializer backed by
+// its exported Serialize function, which must have the signature
+// func([]byte) ([]byte, error). The function receives the canonical JSON
+// snapshot and returns the formatted output.
+//
+// Go plugins are only supported on platforms where the standard library
+// plugin package is (Linux, FreeBSD and macOS with cgo enabled).
+func LoadPlugin(path string) (Serializer, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serializer plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup("Serialize")
+	if err != nil {
+		return nil, fmt.Errorf("serializer plugin %s: %w", path, err)
+	}
+
+	fn, ok := sym.(func([]byte) ([]byte, error))
+	if !ok {
+		return nil, fmt.Errorf("serializer plugin %s: Serialize has type %T, want func([]byte) ([]byte, error)", path, sym)
+	}
+
+	return &pluginSerializer{serialize: fn}, nil
+}
+
+func (p *pluginSerializer) Serialize(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	input, err := ToJSON(snapshot, includeMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return p.serialize(input)
+}
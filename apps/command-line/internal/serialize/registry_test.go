@@ -0,0 +1,109 @@
+package serialize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestRegistry_RegisterLookup tests registration and case-insensitive lookup.
+func TestRegistry_RegisterLookup(t *testing.T) {
+	r := NewRegistry()
+	upper := SerializerFunc(func(s compiler.Snapshot, _ bool) ([]byte, error) {
+		return []byte(strings.ToUpper(s.Data["app"].(string))), nil
+	})
+
+	if err := r.Register("Shout", upper); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := r.Register("shout", upper); err == nil {
+		t.Error("Register() expected error for duplicate name")
+	}
+	if err := r.Register(" ", upper); err == nil {
+		t.Error("Register() expected error for empty name")
+	}
+	if err := r.Register("nil", nil); err == nil {
+		t.Error("Register() expected error for nil serializer")
+	}
+
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"shout"}) {
+		t.Errorf("Names() = %v, want [shout]", got)
+	}
+
+	s, err := r.Lookup("SHOUT")
+	if err != nil {
+		t.Fatalf("Lookup() unexpected error: %v", err)
+	}
+	out, err := s.Serialize(compiler.Snapshot{Data: map[string]any{"app": "demo"}}, false)
+	if err != nil {
+		t.Fatalf("Serialize() unexpected error: %v", err)
+	}
+	if string(out) != "DEMO" {
+		t.Errorf("Serialize() = %q, want %q", out, "DEMO")
+	}
+}
+
+// TestRegistry_LookupUnknown tests the error for unregistered serializers.
+func TestRegistry_LookupUnknown(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := NewRegistry().Lookup("missing")
+	if err == nil {
+		t.Fatal("Lookup() expected error for unknown serializer")
+	}
+	if !strings.Contains(err.Error(), CommandPrefix+"missing") {
+		t.Errorf("error should mention the PATH fallback, got: %v", err)
+	}
+}
+
+// TestCommandSerializer tests the stdin/stdout subprocess protocol,
+// including discovery of nomos-serializer-<name> programs on PATH.
+func TestCommandSerializer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not supported on windows")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, CommandPrefix+"upper")
+	//nolint:gosec // G306: Test script must be executable
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntr a-z A-Z\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	failing := filepath.Join(dir, "fail")
+	//nolint:gosec // G306: Test script must be executable
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\necho boom >&2\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	snapshot := compiler.Snapshot{Data: map[string]any{"app": "demo"}}
+
+	s, err := NewRegistry().Lookup("upper")
+	if err != nil {
+		t.Fatalf("Lookup() unexpected error: %v", err)
+	}
+	out, err := s.Serialize(snapshot, false)
+	if err != nil {
+		t.Fatalf("Serialize() unexpected error: %v", err)
+	}
+	if want := "{\n  \"APP\": \"DEMO\"\n}"; string(out) != want {
+		t.Errorf("Serialize() = %q, want %q", out, want)
+	}
+
+	_, err = NewCommandSerializer([]string{failing}).Serialize(snapshot, false)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Serialize() error = %v, want stderr in message", err)
+	}
+}
+
+// TestLoadPlugin_Missing tests that plugin load failures are reported.
+func TestLoadPlugin_Missing(t *testing.T) {
+	if _, err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Fatal("LoadPlugin() expected error for missing plugin")
+	}
+}
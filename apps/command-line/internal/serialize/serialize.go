@@ -14,15 +14,55 @@
 package serialize
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
 )
 
+// Options configures the built-in serializers. The zero value produces
+// canonical output with alphabetically sorted keys, as ToJSON, ToYAML and
+// ToTfvars do.
+type Options struct {
+	// KeyOrder orders map keys in the data section.
+	KeyOrder KeyOrder
+
+	// Comments writes the .csl comment recorded in the snapshot's source map
+	// above each data key. It applies to YAML only and needs a source map.
+	Comments bool
+
+	// JSON controls whitespace and escaping in JSON output.
+	JSON JSONFormat
+
+	// Template renders the template format, which needs one.
+	Template *Template
+}
+
+// JSONFormat controls the whitespace and escaping of JSON output. The zero
+// value is the canonical form: two-space indentation, no trailing newline,
+// and HTML characters written unescaped.
+type JSONFormat struct {
+	// Indent is the indentation of each nesting level; empty means two
+	// spaces.
+	Indent string
+
+	// Minify writes no whitespace between tokens. Indent is ignored.
+	Minify bool
+
+	// TrailingNewline ends the document with a newline.
+	TrailingNewline bool
+
+	// EscapeHTML writes <, > and & in strings as \u003c, \u003e and
+	// \u0026, as encoding/json does by default, for output embedded in HTML.
+	EscapeHTML bool
+}
+
 // ToJSON serializes a snapshot to canonical JSON with deterministic ordering.
 // Maps are serialized with sorted keys, and values are normalized for stability.
 //
@@ -31,97 +71,198 @@ import (
 //   - includeMetadata: When false, serializes only snapshot.Data at root level.
 //     When true, serializes full snapshot with "data" and "metadata" sections.
 func ToJSON(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	return Options{}.ToJSON(snapshot, includeMetadata)
+}
+
+// ToJSON is ToJSON with data keys ordered by o.KeyOrder and formatted by
+// o.JSON.
+func (o Options) ToJSON(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
 	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false)
-	enc.SetIndent("", "  ")
+	if err := o.WriteJSON(&buf, snapshot, includeMetadata); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
 
-	// Canonicalize the snapshot structure
-	var canonical any
-	if includeMetadata {
-		// Include full snapshot with "data" and "metadata" sections
-		canonical = canonicalizeValue(snapshot)
-	} else {
-		// Serialize only the data section at root level
-		canonical = canonicalizeValue(snapshot.Data)
+// WriteJSON writes the same output as ToJSON to w, encoding one value at a
+// time so that memory use does not grow with the size of the output.
+// Nothing is buffered beyond the current value, so a failed write may leave
+// partial output in w.
+func WriteJSON(w io.Writer, snapshot compiler.Snapshot, includeMetadata bool) error {
+	return Options{}.WriteJSON(w, snapshot, includeMetadata)
+}
+
+// WriteJSON is WriteJSON with data keys ordered by o.KeyOrder and formatted
+// by o.JSON.
+func (o Options) WriteJSON(w io.Writer, snapshot compiler.Snapshot, includeMetadata bool) error {
+	order, err := newKeyOrderer(o.KeyOrder, snapshot)
+	if err != nil {
+		return err
 	}
 
-	if err := enc.Encode(canonical); err != nil {
-		return nil, fmt.Errorf("failed to encode JSON: %w", err)
+	bw := bufio.NewWriter(w)
+	s := newJSONStreamer(bw, order, o.JSON)
+
+	var root any = snapshot.Data
+	if includeMetadata {
+		root = snapshot
+	}
+	if err := s.value(root, "", 0); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if o.JSON.TrailingNewline {
+		bw.WriteByte('\n')
 	}
+	return bw.Flush()
+}
+
+// jsonStreamer writes values as JSON with map keys in the order of order
+// (sorted when nil), matching json.Encoder with SetIndent("", "  ") and HTML
+// escaping disabled for the zero JSONFormat.
+type jsonStreamer struct {
+	w       *bufio.Writer
+	order   *keyOrderer
+	scratch bytes.Buffer
+	enc     *json.Encoder
+	indents []string
+
+	// unit is the indentation of one level; minify drops newlines and
+	// indentation altogether.
+	unit   string
+	minify bool
+}
 
-	// Trim trailing newline added by Encoder
-	result := bytes.TrimRight(buf.Bytes(), "\n")
-	return result, nil
+func newJSONStreamer(w *bufio.Writer, order *keyOrderer, format JSONFormat) *jsonStreamer {
+	s := &jsonStreamer{w: w, order: order, unit: cmp.Or(format.Indent, "  "), minify: format.Minify}
+	s.enc = json.NewEncoder(&s.scratch)
+	s.enc.SetEscapeHTML(format.EscapeHTML)
+	return s
 }
 
-// canonicalizeValue recursively canonicalizes a value by sorting map keys
-// and normalizing types for deterministic JSON output.
-func canonicalizeValue(v any) any {
+// value writes v, found at keyPath in the data, at the given nesting depth.
+func (s *jsonStreamer) value(v any, keyPath string, depth int) error {
 	switch val := v.(type) {
 	case map[string]any:
-		return canonicalizeMap(val)
+		if val == nil {
+			_, err := s.w.WriteString("null")
+			return err
+		}
+		return s.object(s.order.keys(keyPath, val), func(k string) any { return val[k] }, keyPath, depth)
 	case []any:
-		return canonicalizeSlice(val)
+		if val == nil {
+			_, err := s.w.WriteString("null")
+			return err
+		}
+		if len(val) == 0 {
+			_, err := s.w.WriteString("[]")
+			return err
+		}
+		s.w.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				s.w.WriteByte(',')
+			}
+			s.newline(depth + 1)
+			if err := s.value(item, s.order.listChild(keyPath, i), depth+1); err != nil {
+				return err
+			}
+		}
+		s.newline(depth)
+		_, err := s.w.WriteString("]")
+		return err
 	case string:
-		return normalizeString(val)
+		return s.leaf(normalizeString(val), depth)
 	case compiler.Snapshot:
-		// For Snapshot, canonicalize both Data and Metadata
-		return map[string]any{
-			"data":     canonicalizeValue(val.Data),
-			"metadata": canonicalizeValue(val.Metadata),
-		}
+		return s.object([]string{"data", "metadata"}, func(k string) any {
+			if k == "data" {
+				return dataRoot(val.Data)
+			}
+			return val.Metadata
+		}, "", depth)
+	case dataRoot:
+		return s.value(map[string]any(val), "", depth)
 	case compiler.Metadata:
-		// Ensure metadata fields are in deterministic order
-		meta := make(map[string]any)
-		meta["end_time"] = val.EndTime
-		meta["errors"] = canonicalizeValue(val.Errors)
-		meta["input_files"] = canonicalizeValue(val.InputFiles)
-		meta["per_key_provenance"] = canonicalizeValue(val.PerKeyProvenance)
-		meta["provider_aliases"] = canonicalizeValue(val.ProviderAliases)
-		meta["start_time"] = val.StartTime
-		meta["warnings"] = canonicalizeValue(val.Warnings)
-		return meta
+		// Metadata follows its schema's field order, whatever the data order
+		order := s.order
+		s.order = nil
+		defer func() { s.order = order }()
+		return s.value(metadataValue(val), "", depth)
 	case compiler.Provenance:
-		return map[string]any{
+		return s.value(map[string]any{
 			"provider_alias": val.ProviderAlias,
 			"source":         val.Source,
-		}
+		}, "", depth)
 	default:
-		// For primitives (numbers, bools, nil), return as-is
-		return v
+		return s.leaf(v, depth)
 	}
 }
 
-// canonicalizeMap creates a new map with the same content but guaranteed
-// to serialize with sorted keys.
-func canonicalizeMap(m map[string]any) map[string]any {
-	if m == nil {
-		return nil
+// object writes a JSON object with the given keys in order; keyPath is the
+// object's own key path.
+func (s *jsonStreamer) object(keys []string, field func(string) any, keyPath string, depth int) error {
+	if len(keys) == 0 {
+		_, err := s.w.WriteString("{}")
+		return err
 	}
-
-	// Create a new map with canonicalized values
-	result := make(map[string]any, len(m))
-	for k, v := range m {
-		result[k] = canonicalizeValue(v)
+	s.w.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			s.w.WriteByte(',')
+		}
+		s.newline(depth + 1)
+		if err := s.leaf(k, depth+1); err != nil {
+			return err
+		}
+		if s.minify {
+			s.w.WriteByte(':')
+		} else {
+			s.w.WriteString(": ")
+		}
+		if err := s.value(field(k), s.order.mapChild(keyPath, k), depth+1); err != nil {
+			return err
+		}
 	}
+	s.newline(depth)
+	_, err := s.w.WriteString("}")
+	return err
+}
 
-	return result
+// newline starts a new line indented to depth, unless minifying.
+func (s *jsonStreamer) newline(depth int) {
+	if s.minify {
+		return
+	}
+	s.w.WriteByte('\n')
+	s.w.WriteString(s.indent(depth))
 }
 
-// canonicalizeSlice creates a new slice with canonicalized elements.
-func canonicalizeSlice(s []any) []any {
-	if s == nil {
-		return nil
+// leaf encodes v with encoding/json, indented to continue at depth.
+func (s *jsonStreamer) leaf(v any, depth int) error {
+	s.scratch.Reset()
+	if s.minify {
+		s.enc.SetIndent("", "")
+	} else {
+		s.enc.SetIndent(s.indent(depth), s.unit)
+	}
+	if err := s.enc.Encode(v); err != nil {
+		return err
 	}
+	_, err := s.w.Write(bytes.TrimSuffix(s.scratch.Bytes(), []byte("\n")))
+	return err
+}
 
-	result := make([]any, len(s))
-	for i, v := range s {
-		result[i] = canonicalizeValue(v)
+// indent returns the indentation for depth, caching each level.
+func (s *jsonStreamer) indent(depth int) string {
+	for len(s.indents) <= depth {
+		s.indents = append(s.indents, strings.Repeat(s.unit, len(s.indents)))
 	}
-	return result
+	return s.indents[depth]
 }
 
+// dataRoot marks the data section of a snapshot, whose key paths start
+// afresh beneath the "data" key.
+type dataRoot map[string]any
+
 // normalizeString ensures UTF-8 validity and normalization.
 func normalizeString(s string) string {
 	// Check if string is valid UTF-8
@@ -336,3 +336,60 @@ func BenchmarkToTfvars_Large(b *testing.B) {
 		}
 	}
 }
+
+// generateKeysSnapshot creates a snapshot with n leaf keys grouped into
+// sections of 100 keys, mixing string, integer, and boolean values.
+func generateKeysSnapshot(n int) compiler.Snapshot {
+	data := make(map[string]any, n/100)
+	for s := 0; s < n/100; s++ {
+		section := make(map[string]any, 100)
+		for k := 0; k < 100; k++ {
+			switch k % 3 {
+			case 0:
+				section[fmt.Sprintf("key%d", k)] = fmt.Sprintf("value%d-%d", s, k)
+			case 1:
+				section[fmt.Sprintf("key%d", k)] = s*100 + k
+			default:
+				section[fmt.Sprintf("key%d", k)] = k%2 == 0
+			}
+		}
+		data[fmt.Sprintf("section%d", s)] = section
+	}
+	return compiler.Snapshot{Data: data}
+}
+
+// BenchmarkSerialize_Keys benchmarks every built-in format with 10k and 100k
+// leaf keys and reports output throughput.
+func BenchmarkSerialize_Keys(b *testing.B) {
+	formats := []struct {
+		name string
+		fn   func(compiler.Snapshot, bool) ([]byte, error)
+	}{
+		{"json", ToJSON},
+		{"yaml", ToYAML},
+		{"tfvars", ToTfvars},
+	}
+
+	for _, n := range []int{10_000, 100_000} {
+		snapshot := generateKeysSnapshot(n)
+		for _, f := range formats {
+			b.Run(fmt.Sprintf("%s/keys=%d", f.name, n), func(b *testing.B) {
+				out, err := f.fn(snapshot, false)
+				if err != nil {
+					b.Fatalf("%s failed: %v", f.name, err)
+				}
+				b.SetBytes(int64(len(out)))
+
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					if _, err := f.fn(snapshot, false); err != nil {
+						b.Fatalf("%s failed: %v", f.name, err)
+					}
+				}
+				b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "keys/s")
+			})
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package serialize
 
 import (
+	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -519,3 +521,67 @@ func indexOfAfter(s, substr string, start int) int {
 	}
 	return start + idx
 }
+
+// TestOptions_JSONFormat tests that each JSON format option matches
+// encoding/json with the equivalent settings.
+func TestOptions_JSONFormat(t *testing.T) {
+	data := map[string]any{
+		"app": map[string]any{
+			"html":  "<a href=\"x\">&</a>",
+			"tags":  []any{"web", map[string]any{"b": "2", "a": []any{}}},
+			"empty": map[string]any{},
+		},
+		"count": 3.5,
+	}
+	snapshot := compiler.Snapshot{Data: data}
+
+	encode := func(prefix, indent string, escapeHTML bool) string {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		enc.SetIndent(prefix, indent)
+		if err := enc.Encode(data); err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	tests := []struct {
+		name   string
+		format JSONFormat
+		want   string
+	}{
+		{name: "default", want: encode("", "  ", false)},
+		{name: "tab indent", format: JSONFormat{Indent: "\t"}, want: encode("", "\t", false)},
+		{name: "four spaces", format: JSONFormat{Indent: "    "}, want: encode("", "    ", false)},
+		{name: "minify", format: JSONFormat{Minify: true, Indent: "\t"}, want: encode("", "", false)},
+		{name: "escape html", format: JSONFormat{EscapeHTML: true}, want: encode("", "  ", true)},
+		{name: "trailing newline", format: JSONFormat{TrailingNewline: true}, want: encode("", "  ", false) + "\n"},
+		{name: "minify with newline", format: JSONFormat{Minify: true, TrailingNewline: true, EscapeHTML: true}, want: encode("", "", true) + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Options{JSON: tt.format}.ToJSON(snapshot, false)
+			if err != nil {
+				t.Fatalf("ToJSON error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToJSON =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// Metadata follows the same format
+	got, err := Options{JSON: JSONFormat{Minify: true}}.ToJSON(snapshot, true)
+	if err != nil {
+		t.Fatalf("ToJSON error = %v", err)
+	}
+	indented, _ := ToJSON(snapshot, true)
+	var want bytes.Buffer
+	if err := json.Compact(&want, indented); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("minified ToJSON with metadata =\n%s\nwant\n%s", got, want.Bytes())
+	}
+}
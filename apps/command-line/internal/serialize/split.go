@@ -0,0 +1,100 @@
+package serialize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// Document is one part of a snapshot divided by Split.
+type Document struct {
+	// Path holds the keys leading to the document's data, e.g. ["app"] or
+	// ["services", "api"].
+	Path []string
+
+	// Snapshot holds the data under Path. Its source map, when the original
+	// has one, is rebased onto the document so key order and comments work
+	// as they do for the whole snapshot.
+	Snapshot compiler.Snapshot
+}
+
+// Key returns the dotted key path of the document, e.g. "services.api".
+func (d Document) Key() string {
+	return strings.Join(d.Path, ".")
+}
+
+// Split divides snapshot into one document per map at depth, where depth 1
+// makes a document of each top-level key. Documents are returned sorted by
+// path. Every value down to depth must be a map, since other values have no
+// document of their own; empty maps above depth produce no documents.
+func Split(snapshot compiler.Snapshot, depth int) ([]Document, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("invalid split depth %d: must be at least 1", depth)
+	}
+
+	var docs []Document
+	var walk func(data map[string]any, path []string) error
+	walk = func(data map[string]any, path []string) error {
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := append(path[:len(path):len(path)], k)
+			child, ok := data[k].(map[string]any)
+			if !ok {
+				return fmt.Errorf("cannot split at depth %d: %s is %s, not a map", depth, strings.Join(childPath, "."), describeValue(data[k]))
+			}
+			if len(childPath) < depth {
+				if err := walk(child, childPath); err != nil {
+					return err
+				}
+				continue
+			}
+			docs = append(docs, Document{
+				Path: childPath,
+				Snapshot: compiler.Snapshot{
+					Data:      child,
+					Metadata:  snapshot.Metadata,
+					SourceMap: rebaseSourceMap(snapshot.SourceMap, strings.Join(childPath, ".")),
+				},
+			})
+		}
+		return nil
+	}
+	if err := walk(snapshot.Data, nil); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// rebaseSourceMap returns the entries of sm below prefix with the prefix
+// removed from their key paths, or nil if sm is nil.
+func rebaseSourceMap(sm *compiler.SourceMap, prefix string) *compiler.SourceMap {
+	if sm == nil {
+		return nil
+	}
+	rebased := &compiler.SourceMap{Version: sm.Version, Entries: map[string]compiler.SourceMapEntry{}}
+	for path, entry := range sm.Entries {
+		if rest, ok := strings.CutPrefix(path, prefix+"."); ok {
+			rebased.Entries[rest] = entry
+		}
+	}
+	return rebased
+}
+
+// describeValue names the kind of a non-map snapshot value for errors.
+func describeValue(v any) string {
+	switch v.(type) {
+	case []any:
+		return "a list"
+	case nil:
+		return "null"
+	default:
+		return "a scalar"
+	}
+}
@@ -0,0 +1,89 @@
+package serialize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// splitSnapshot returns a snapshot with services nested two levels deep.
+func splitSnapshot() compiler.Snapshot {
+	return compiler.Snapshot{
+		Data: map[string]any{
+			"services": map[string]any{
+				"web": map[string]any{"port": "80"},
+				"api": map[string]any{"port": "8080"},
+			},
+			"database": map[string]any{"primary": map[string]any{"host": "db"}},
+			"empty":    map[string]any{},
+		},
+		SourceMap: &compiler.SourceMap{Version: compiler.SourceMapVersion, Entries: map[string]compiler.SourceMapEntry{
+			"services":          {Comment: "All services"},
+			"services.api":      {Comment: "The API"},
+			"services.api.port": {Comment: "Listen port"},
+			"servicesx.other":   {Comment: "Shares a prefix"},
+		}},
+	}
+}
+
+// TestSplit tests dividing a snapshot into documents at each depth.
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		depth    int
+		wantKeys []string
+	}{
+		{depth: 1, wantKeys: []string{"database", "empty", "services"}},
+		{depth: 2, wantKeys: []string{"database.primary", "services.api", "services.web"}},
+	}
+	for _, tt := range tests {
+		docs, err := Split(splitSnapshot(), tt.depth)
+		if err != nil {
+			t.Fatalf("Split(%d) error = %v", tt.depth, err)
+		}
+		var keys []string
+		for _, d := range docs {
+			keys = append(keys, d.Key())
+		}
+		if !reflect.DeepEqual(keys, tt.wantKeys) {
+			t.Errorf("Split(%d) keys = %v, want %v", tt.depth, keys, tt.wantKeys)
+		}
+	}
+
+	// The source map is rebased onto each document
+	docs, _ := Split(splitSnapshot(), 1)
+	services := docs[2].Snapshot
+	want := map[string]compiler.SourceMapEntry{
+		"api":      {Comment: "The API"},
+		"api.port": {Comment: "Listen port"},
+	}
+	if !reflect.DeepEqual(services.SourceMap.Entries, want) {
+		t.Errorf("services source map = %v, want %v", services.SourceMap.Entries, want)
+	}
+	if !reflect.DeepEqual(services.Data, splitSnapshot().Data["services"]) {
+		t.Errorf("services data = %v", services.Data)
+	}
+}
+
+// TestSplit_Errors tests that values without a document of their own are
+// refused.
+func TestSplit_Errors(t *testing.T) {
+	tests := []struct {
+		depth   int
+		wantErr string
+	}{
+		{depth: 0, wantErr: "must be at least 1"},
+		{depth: 3, wantErr: "database.primary.host is a scalar, not a map"},
+	}
+	for _, tt := range tests {
+		if _, err := Split(splitSnapshot(), tt.depth); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Split(%d) error = %v, want %q", tt.depth, err, tt.wantErr)
+		}
+	}
+
+	snapshot := compiler.Snapshot{Data: map[string]any{"hosts": []any{"a"}}}
+	if _, err := Split(snapshot, 1); err == nil || !strings.Contains(err.Error(), "hosts is a list") {
+		t.Errorf("Split() error = %v, want list error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// Template renders snapshots through a user-provided Go text/template for
+// the template output format. Besides the text/template built-ins, templates
+// can call:
+//
+//	get "key.path"  the value at a key path, like 'nomos get'; a missing key
+//	                fails rendering
+//	indent n s      s with every line indented by n spaces
+//	toJson v        v as compact JSON
+//	quote v         v as a double-quoted, escaped string
+//
+// The template's dot is the snapshot data, or a map holding "data" and
+// "metadata" when metadata is included. Referencing a missing map key
+// through dot is an error rather than "<no value>".
+type Template struct {
+	tmpl *template.Template
+}
+
+// ParseTemplate parses text as a template named name.
+func ParseTemplate(name, text string) (*Template, error) {
+	// get is bound to a snapshot per render; parsing only needs its name
+	tmpl, err := template.New(name).Funcs(templateFuncs(nil)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// LoadTemplate reads and parses the template file at path.
+func LoadTemplate(path string) (*Template, error) {
+	text, err := os.ReadFile(path) //nolint:gosec // G304: Path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read template: %w", err)
+	}
+	return ParseTemplate(filepath.Base(path), string(text))
+}
+
+// Render executes the template against snapshot.
+func (t *Template) Render(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	var dot any = snapshot.Data
+	if includeMetadata {
+		dot = map[string]any{"data": snapshot.Data, "metadata": metadataValue(snapshot.Metadata)}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(templateFuncs(&snapshot)).Execute(&buf, dot); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// templateFuncs returns the helper functions available to templates, with
+// get reading from snapshot.
+func templateFuncs(snapshot *compiler.Snapshot) template.FuncMap {
+	return template.FuncMap{
+		"get": func(keyPath string) (any, error) {
+			if snapshot == nil {
+				return nil, fmt.Errorf("no snapshot")
+			}
+			value, ok := snapshot.Lookup(keyPath)
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", keyPath)
+			}
+			return value, nil
+		},
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"toJson": func(v any) (string, error) {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		},
+		"quote": func(v any) string {
+			return strconv.Quote(fmt.Sprint(v))
+		},
+	}
+}
+
+// ToTemplate renders snapshot through o.Template.
+func (o Options) ToTemplate(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	if o.Template == nil {
+		return nil, fmt.Errorf("template output needs a template (--template)")
+	}
+	return o.Template.Render(snapshot, includeMetadata)
+}
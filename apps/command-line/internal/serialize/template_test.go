@@ -0,0 +1,97 @@
+package serialize
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestTemplate_Render tests rendering with each helper function.
+func TestTemplate_Render(t *testing.T) {
+	snapshot := compiler.Snapshot{
+		Data: map[string]any{
+			"server": map[string]any{"port": "8080", "name": `edge "1"`},
+			"upstreams": []any{
+				map[string]any{"host": "a.internal", "weight": 2},
+				map[string]any{"host": "b.internal", "weight": 1},
+			},
+		},
+		Metadata: compiler.Metadata{InputFiles: []string{"/src/app.csl"}},
+	}
+
+	tests := []struct {
+		name            string
+		text            string
+		includeMetadata bool
+		want            string
+	}{
+		{name: "dot", text: "listen {{ .server.port }};", want: "listen 8080;"},
+		{name: "get", text: `{{ get "upstreams[1].host" }}`, want: "b.internal"},
+		{name: "quote", text: `name = {{ quote .server.name }}`, want: `name = "edge \"1\""`},
+		{name: "toJson", text: `{{ toJson (index .upstreams 0) }}`, want: `{"host":"a.internal","weight":2}`},
+		{name: "indent", text: "server {\n{{ indent 2 \"a;\\nb;\" }}\n}", want: "server {\n  a;\n  b;\n}"},
+		{
+			name: "range sorts keys",
+			text: "{{ range $k, $v := .server }}{{ $k }}={{ $v }}\n{{ end }}",
+			want: "name=edge \"1\"\nport=8080\n",
+		},
+		{
+			name:            "metadata",
+			text:            "{{ .data.server.port }} {{ index .metadata.input_files 0 }}",
+			includeMetadata: true,
+			want:            "8080 /src/app.csl",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate("test", tt.text)
+			if err != nil {
+				t.Fatalf("ParseTemplate() error = %v", err)
+			}
+			got, err := Options{Template: tmpl}.ToTemplate(snapshot, tt.includeMetadata)
+			if err != nil {
+				t.Fatalf("ToTemplate() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTemplate_Errors tests parse, missing key, and missing template errors,
+// and loading a template file.
+func TestTemplate_Errors(t *testing.T) {
+	snapshot := compiler.Snapshot{Data: map[string]any{"app": map[string]any{}}}
+
+	if _, err := ParseTemplate("bad", "{{ .app "); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("ParseTemplate() error = %v, want invalid template", err)
+	}
+	for _, text := range []string{`{{ get "app.port" }}`, `{{ .app.port }}`} {
+		tmpl, err := ParseTemplate("missing", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpl.Render(snapshot, false); err == nil {
+			t.Errorf("Render(%s) succeeded for a missing key", text)
+		}
+	}
+	if _, err := (Options{}).ToTemplate(snapshot, false); err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("ToTemplate() error = %v, want --template hint", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "nginx.conf.tmpl")
+	if err := os.WriteFile(path, []byte("{{ len .app }}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	if got, err := tmpl.Render(snapshot, false); err != nil || string(got) != "0" {
+		t.Errorf("Render() = %q, %v", got, err)
+	}
+}
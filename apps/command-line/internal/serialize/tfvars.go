@@ -36,7 +36,13 @@ var hclIdentifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
 //	vpc = {
 //	  cidr = "10.0.0.0/16"
 //	}
-func ToTfvars(snapshot compiler.Snapshot, _ bool) ([]byte, error) {
+func ToTfvars(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	return Options{}.ToTfvars(snapshot, includeMetadata)
+}
+
+// ToTfvars is ToTfvars with variables, and the attributes of object values,
+// ordered by o.KeyOrder.
+func (o Options) ToTfvars(snapshot compiler.Snapshot, _ bool) ([]byte, error) {
 	// Note: includeMetadata parameter is ignored. Tfvars format has no standard
 	// metadata representation, so metadata is always excluded.
 
@@ -45,21 +51,30 @@ func ToTfvars(snapshot compiler.Snapshot, _ bool) ([]byte, error) {
 		return nil, err
 	}
 
+	order, err := newKeyOrderer(o.KeyOrder, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create HCL file
 	f := hclwrite.NewEmptyFile()
 	rootBody := f.Body()
 
-	// Get sorted keys for deterministic output
-	keys := make([]string, 0, len(snapshot.Data))
-	for k := range snapshot.Data {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// Set attributes in sorted order
-	for _, key := range keys {
+	// Set attributes in order
+	for _, key := range order.keys("", snapshot.Data) {
 		value := snapshot.Data[key]
 
+		// cty objects always write their attributes sorted, so other orders
+		// are written as tokens
+		if order != nil {
+			tokens, err := orderedTokens(value, key, order)
+			if err != nil {
+				return nil, err
+			}
+			rootBody.SetAttributeRaw(key, tokens)
+			continue
+		}
+
 		// Convert to cty.Value
 		ctyVal, err := goToCty(value, key)
 		if err != nil {
@@ -182,6 +197,46 @@ func goToCty(v any, path string) (cty.Value, error) {
 	}
 }
 
+// orderedTokens returns the HCL expression for v, found at path, with object
+// attributes in the order of order. Scalars are converted with goToCty.
+func orderedTokens(v any, path string, order *keyOrderer) (hclwrite.Tokens, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(val))
+		for _, k := range order.keys(path, val) {
+			keyPath := k
+			if path != "" {
+				keyPath = path + "." + k
+			}
+			tokens, err := orderedTokens(val[k], keyPath, order)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: hclwrite.TokensForIdentifier(k), Value: tokens})
+		}
+		return hclwrite.TokensForObject(attrs), nil
+	case []any:
+		if len(val) == 0 {
+			return hclwrite.TokensForValue(cty.ListValEmpty(cty.DynamicPseudoType)), nil
+		}
+		elems := make([]hclwrite.Tokens, len(val))
+		for i, item := range val {
+			tokens, err := orderedTokens(item, fmt.Sprintf("%s[%d]", path, i), order)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = tokens
+		}
+		return hclwrite.TokensForTuple(elems), nil
+	default:
+		ctyVal, err := goToCty(v, path)
+		if err != nil {
+			return nil, err
+		}
+		return hclwrite.TokensForValue(ctyVal), nil
+	}
+}
+
 // convertSliceToCty converts a Go slice to a cty tuple value.
 // Empty slices are converted to empty list values.
 func convertSliceToCty(s []any, path string) (cty.Value, error) {
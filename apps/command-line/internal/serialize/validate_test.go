@@ -1,8 +1,11 @@
 package serialize
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
 )
 
 // TestValidateKeyName tests validation of individual key names for each format.
@@ -368,7 +371,7 @@ func TestCircularReferenceDetection(t *testing.T) {
 	t.Log("Circular reference detection is handled by libs/compiler.ResolveImports()")
 	t.Log("Serializer assumes input from compiler is acyclic")
 
-	// Verify canonicalization doesn't create cycles (basic smoke test)
+	// Verify serialization doesn't create cycles (basic smoke test)
 	data := map[string]any{
 		"a": map[string]any{
 			"b": map[string]any{
@@ -377,19 +380,24 @@ func TestCircularReferenceDetection(t *testing.T) {
 		},
 	}
 
-	// Canonicalize should not introduce cycles
-	result := canonicalizeValue(data)
+	output, err := ToJSON(compiler.Snapshot{Data: data}, false)
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
 
-	// If we can access nested values without panic, no cycles were introduced
-	resultMap := result.(map[string]any)
+	// If we can access nested values after a round trip, no cycles were introduced
+	var resultMap map[string]any
+	if err := json.Unmarshal(output, &resultMap); err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
 	aMap := resultMap["a"].(map[string]any)
 	bMap := aMap["b"].(map[string]any)
 	cValue := bMap["c"].(string)
 
 	if cValue != "value" {
-		t.Errorf("canonicalizeValue() corrupted data: got %q, want %q", cValue, "value")
+		t.Errorf("ToJSON() corrupted data: got %q, want %q", cValue, "value")
 	}
 
-	t.Log("✓ Canonicalization preserves acyclic structure")
+	t.Log("✓ Serialization preserves acyclic structure")
 	t.Log("✓ Contract verified: circular references must be detected by compiler")
 }
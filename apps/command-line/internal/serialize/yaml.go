@@ -1,9 +1,11 @@
 package serialize
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
-	"sort"
+	"io"
+	"strings"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
 	"gopkg.in/yaml.v3"
@@ -35,51 +37,109 @@ import (
 //   - Ansible playbooks
 //   - GitHub Actions workflows
 func ToYAML(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	return Options{}.ToYAML(snapshot, includeMetadata)
+}
+
+// ToYAML is ToYAML with data keys ordered by o.KeyOrder and, with
+// o.Comments, preceded by their .csl comments.
+func (o Options) ToYAML(snapshot compiler.Snapshot, includeMetadata bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := o.WriteYAML(&buf, snapshot, includeMetadata); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteYAML writes the same output as ToYAML to w. Without metadata, each
+// top-level key is converted and encoded on its own, so only the node tree
+// of the largest top-level value is held in memory at once. Validation runs
+// before anything is written, but an encoding failure may leave partial
+// output in w.
+func WriteYAML(w io.Writer, snapshot compiler.Snapshot, includeMetadata bool) error {
+	return Options{}.WriteYAML(w, snapshot, includeMetadata)
+}
+
+// WriteYAML is WriteYAML with data keys ordered by o.KeyOrder and, with
+// o.Comments, preceded by their .csl comments.
+func (o Options) WriteYAML(w io.Writer, snapshot compiler.Snapshot, includeMetadata bool) error {
+	order, err := newKeyOrderer(o.KeyOrder, snapshot)
+	if err != nil {
+		return err
+	}
+	if o.Comments {
+		if order, err = order.withComments(snapshot); err != nil {
+			return err
+		}
+	}
+
 	// Validate top-level keys for YAML compatibility
 	if err := validateAllKeys(snapshot.Data, FormatYAML); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Validate data doesn't contain unsupported types
 	if err := validateYAMLTypes(snapshot.Data); err != nil {
-		return nil, err
+		return err
 	}
 
-	// Canonicalize the snapshot structure (sorts maps, preserves arrays)
+	if includeMetadata || len(snapshot.Data) == 0 {
+		return writeYAMLDocument(w, snapshot, includeMetadata, order)
+	}
+
+	// A block mapping is a concatenation of its entries, so encoding each
+	// entry as a one-key document yields the same bytes as the whole map
+	bw := bufio.NewWriter(w)
+	for _, k := range order.keys("", snapshot.Data) {
+		entry := &yaml.Node{Kind: yaml.MappingNode}
+		path := order.mapChild("", k)
+		entry.Content = append(entry.Content, yamlKey(k, order.comment(path)), canonicalizeForYAML(snapshot.Data[k], order, path))
+		if err := encodeYAML(bw, entry); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// writeYAMLDocument encodes the snapshot as a single YAML document.
+func writeYAMLDocument(w io.Writer, snapshot compiler.Snapshot, includeMetadata bool, order *keyOrderer) error {
+	// Canonicalize the snapshot structure (orders maps, preserves arrays)
 	var canonical *yaml.Node
 	if includeMetadata {
 		// Include full snapshot with "data" and "metadata" sections
-		canonical = canonicalizeForYAML(snapshot)
+		canonical = canonicalizeForYAML(snapshot, order, "")
 	} else {
 		// Serialize only the data section at root level
-		canonical = canonicalizeForYAML(snapshot.Data)
+		canonical = canonicalizeForYAML(snapshot.Data, order, "")
 	}
+	return encodeYAML(w, canonical)
+}
 
-	var buf bytes.Buffer
-	enc := yaml.NewEncoder(&buf)
+// encodeYAML writes node to w as one YAML document.
+func encodeYAML(w io.Writer, node *yaml.Node) error {
+	enc := yaml.NewEncoder(w)
 	enc.SetIndent(2) // Use 2-space indent (YAML convention)
 
-	if err := enc.Encode(canonical); err != nil {
+	if err := enc.Encode(node); err != nil {
 		// Enhance error message for unsupported types
-		return nil, fmt.Errorf("failed to encode YAML (unsupported type or invalid structure): %w", err)
+		return fmt.Errorf("failed to encode YAML (unsupported type or invalid structure): %w", err)
 	}
 
 	if err := enc.Close(); err != nil {
-		return nil, fmt.Errorf("failed to finalize YAML encoding: %w", err)
+		return fmt.Errorf("failed to finalize YAML encoding: %w", err)
 	}
-
-	return buf.Bytes(), nil
+	return nil
 }
 
-// canonicalizeForYAML recursively canonicalizes a value for deterministic YAML output.
-// It sorts all map keys alphabetically while preserving array order.
+// canonicalizeForYAML recursively canonicalizes a value, found at keyPath in
+// the data, for deterministic YAML output. Map keys are ordered by order
+// (sorted when nil) while array order is preserved.
 // Uses yaml.Node for precise control over key ordering in the output.
-func canonicalizeForYAML(v any) *yaml.Node {
+func canonicalizeForYAML(v any, order *keyOrderer, keyPath string) *yaml.Node {
 	switch val := v.(type) {
 	case map[string]any:
-		return canonicalizeYAMLMap(val)
+		return canonicalizeYAMLMap(val, order, keyPath)
 	case []any:
-		return canonicalizeYAMLSlice(val)
+		return canonicalizeYAMLSlice(val, order, keyPath)
 	case compiler.Snapshot:
 		// Create mapping node for snapshot
 		node := &yaml.Node{Kind: yaml.MappingNode}
@@ -87,33 +147,14 @@ func canonicalizeForYAML(v any) *yaml.Node {
 		// Add fields in alphabetical order
 		node.Content = append(node.Content,
 			&yaml.Node{Kind: yaml.ScalarNode, Value: "data"},
-			canonicalizeForYAML(val.Data),
+			canonicalizeForYAML(val.Data, order, ""),
 			&yaml.Node{Kind: yaml.ScalarNode, Value: "metadata"},
-			canonicalizeForYAML(val.Metadata),
+			canonicalizeForYAML(val.Metadata, nil, ""),
 		)
 		return node
 	case compiler.Metadata:
-		// Create mapping node for metadata
-		node := &yaml.Node{Kind: yaml.MappingNode}
-
-		// Fields in alphabetical order
-		node.Content = append(node.Content,
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "end_time"},
-			scalarNode(val.EndTime),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "errors"},
-			canonicalizeForYAML(val.Errors),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "input_files"},
-			canonicalizeForYAML(val.InputFiles),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "per_key_provenance"},
-			canonicalizeForYAML(val.PerKeyProvenance),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "provider_aliases"},
-			canonicalizeForYAML(val.ProviderAliases),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "start_time"},
-			scalarNode(val.StartTime),
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "warnings"},
-			canonicalizeForYAML(val.Warnings),
-		)
-		return node
+		// Metadata follows its schema's field order, whatever the data order
+		return canonicalizeForYAML(metadataValue(val), nil, "")
 	case compiler.Provenance:
 		// Create mapping node for provenance
 		node := &yaml.Node{Kind: yaml.MappingNode}
@@ -127,7 +168,9 @@ func canonicalizeForYAML(v any) *yaml.Node {
 		)
 		return node
 	case string:
-		return &yaml.Node{Kind: yaml.ScalarNode, Value: normalizeString(val)}
+		// Encoding quotes strings YAML would otherwise read as another
+		// type ("8080", "true", ""), so string values round-trip as strings
+		return scalarNode(normalizeString(val))
 	case nil:
 		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
 	default:
@@ -136,25 +179,18 @@ func canonicalizeForYAML(v any) *yaml.Node {
 	}
 }
 
-// canonicalizeYAMLMap creates a YAML mapping node with sorted keys.
-func canonicalizeYAMLMap(m map[string]any) *yaml.Node {
+// canonicalizeYAMLMap creates a YAML mapping node with keys in order.
+func canonicalizeYAMLMap(m map[string]any, order *keyOrderer, keyPath string) *yaml.Node {
 	if m == nil {
 		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
 	}
 
-	// Extract and sort keys
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// Build mapping node with sorted keys
 	node := &yaml.Node{Kind: yaml.MappingNode}
-	for _, k := range keys {
+	for _, k := range order.keys(keyPath, m) {
+		path := order.mapChild(keyPath, k)
 		node.Content = append(node.Content,
-			&yaml.Node{Kind: yaml.ScalarNode, Value: k},
-			canonicalizeForYAML(m[k]),
+			yamlKey(k, order.comment(path)),
+			canonicalizeForYAML(m[k], order, path),
 		)
 	}
 
@@ -163,14 +199,27 @@ func canonicalizeYAMLMap(m map[string]any) *yaml.Node {
 
 // canonicalizeYAMLSlice creates a YAML sequence node with canonicalized elements.
 // Array order is preserved (not sorted).
-func canonicalizeYAMLSlice(s []any) *yaml.Node {
+func canonicalizeYAMLSlice(s []any, order *keyOrderer, keyPath string) *yaml.Node {
 	if s == nil {
 		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
 	}
 
 	node := &yaml.Node{Kind: yaml.SequenceNode}
-	for _, v := range s {
-		node.Content = append(node.Content, canonicalizeForYAML(v))
+	for i, v := range s {
+		node.Content = append(node.Content, canonicalizeForYAML(v, order, order.listChild(keyPath, i)))
+	}
+	return node
+}
+
+// yamlKey creates the node of map key k, with comment written above it.
+func yamlKey(k, comment string) *yaml.Node {
+	node := scalarNode(k)
+	if comment != "" {
+		lines := strings.Split(comment, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("# "+line, " ")
+		}
+		node.HeadComment = strings.Join(lines, "\n")
 	}
 	return node
 }
@@ -178,6 +227,11 @@ func canonicalizeYAMLSlice(s []any) *yaml.Node {
 // scalarNode creates a yaml.Node from a primitive value.
 // Lets the yaml encoder determine the appropriate tag and formatting.
 func scalarNode(v any) *yaml.Node {
+	// The encoder writes multi-line strings as literal blocks, which cannot
+	// hold leading line breaks; it drops them. Quote those strings instead.
+	if s, ok := v.(string); ok && strings.HasPrefix(s, "\n") {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: yaml.DoubleQuotedStyle}
+	}
 	node := &yaml.Node{}
 	if err := node.Encode(v); err != nil {
 		// Fallback for unsupported types
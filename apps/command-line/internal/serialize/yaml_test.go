@@ -1,6 +1,7 @@
 package serialize
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 	"time"
@@ -344,6 +345,26 @@ func TestToYAML_EdgeCases(t *testing.T) {
 	}
 }
 
+// TestToYAML_LeadingNewlines tests that strings starting with line breaks
+// survive a round trip; literal blocks cannot hold them.
+func TestToYAML_LeadingNewlines(t *testing.T) {
+	data := map[string]any{"one": "\nA", "two": "\n\nB\n", "plain": "a\nb"}
+
+	out, err := ToYAML(compiler.Snapshot{Data: data}, false)
+	if err != nil {
+		t.Fatalf("ToYAML() error = %v", err)
+	}
+	var got map[string]any
+	if err := yaml.Unmarshal(out, &got); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	for k, want := range data {
+		if got[k] != want {
+			t.Errorf("%s = %q, want %q\n%s", k, got[k], want, out)
+		}
+	}
+}
+
 // TestToYAML_ArraysPreserveOrder tests that arrays maintain their order.
 func TestToYAML_ArraysPreserveOrder(t *testing.T) {
 	snapshot := compiler.Snapshot{
@@ -538,6 +559,34 @@ func TestToYAML_TypePreservation(t *testing.T) {
 	}
 }
 
+// TestToYAML_AmbiguousStrings tests that strings YAML would read as another
+// type are quoted, so they decode as strings like they do in JSON.
+func TestToYAML_AmbiguousStrings(t *testing.T) {
+	data := map[string]any{
+		"port":  "8080",
+		"flag":  "true",
+		"yes":   "yes",
+		"null":  "null",
+		"empty": "",
+		"float": "3.14",
+	}
+
+	output, err := ToYAML(compiler.Snapshot{Data: data}, false)
+	if err != nil {
+		t.Fatalf("ToYAML failed: %v", err)
+	}
+
+	var result map[string]any
+	if err := yaml.Unmarshal(output, &result); err != nil {
+		t.Fatalf("failed to parse YAML output: %v", err)
+	}
+	for key, want := range data {
+		if result[key] != want {
+			t.Errorf("%s: expected string %q, got %#v\n%s", key, want, result[key], output)
+		}
+	}
+}
+
 // TestToYAML_ExcludeMetadata tests that when includeMetadata=false,
 // the output contains ONLY the data section at root level (no "data:" wrapper,
 // no "metadata:" section). This enables cleaner output for tools that only
@@ -738,3 +787,86 @@ func TestToYAML_ExcludeMetadata_KeyOrdering(t *testing.T) {
 		t.Logf("Output:\n%s", outputStr)
 	}
 }
+
+// TestOptions_Comments tests that source map comments are written above
+// their keys and that the output still parses to the same data.
+func TestOptions_Comments(t *testing.T) {
+	snapshot := compiler.Snapshot{
+		Data: map[string]any{
+			"app": map[string]any{
+				"name":    "api",
+				"port":    "8080",
+				"servers": []any{map[string]any{"zone": "eu"}},
+			},
+			"region": "us-east-1",
+		},
+		SourceMap: &compiler.SourceMap{Version: compiler.SourceMapVersion, Entries: map[string]compiler.SourceMapEntry{
+			"app":                 {Comment: "The application.\n\nServed behind the gateway."},
+			"app.port":            {Comment: "Listen port"},
+			"app.servers[0].zone": {Comment: "Zone of the server"},
+			"region":              {},
+		}},
+	}
+
+	want := `# The application.
+#
+# Served behind the gateway.
+app:
+  name: api
+  # Listen port
+  port: "8080"
+  servers:
+    - # Zone of the server
+      zone: eu
+region: us-east-1
+`
+	for name, write := range map[string]func(Options) ([]byte, error){
+		"streamed": func(o Options) ([]byte, error) { return o.ToYAML(snapshot, false) },
+		"document": func(Options) ([]byte, error) {
+			var buf bytes.Buffer
+			err := writeYAMLDocument(&buf, snapshot, false, mustWithComments(t, snapshot))
+			return buf.Bytes(), err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			output, err := write(Options{Comments: true})
+			if err != nil {
+				t.Fatalf("ToYAML error = %v", err)
+			}
+			if string(output) != want {
+				t.Errorf("ToYAML =\n%s\nwant\n%s", output, want)
+			}
+
+			var decoded map[string]any
+			if err := yaml.Unmarshal(output, &decoded); err != nil {
+				t.Fatalf("output does not parse: %v", err)
+			}
+			if decoded["region"] != "us-east-1" || decoded["app"].(map[string]any)["port"] != "8080" {
+				t.Errorf("decoded = %v", decoded)
+			}
+		})
+	}
+
+	// With metadata, only the data section is commented
+	output, err := Options{Comments: true, KeyOrder: KeyOrder{Policy: KeyOrderPriority, Priority: []string{"region"}}}.ToYAML(snapshot, true)
+	if err != nil {
+		t.Fatalf("ToYAML error = %v", err)
+	}
+	if !strings.Contains(string(output), "data:\n  region: us-east-1\n  # The application.\n") {
+		t.Errorf("ToYAML with metadata =\n%s", output)
+	}
+
+	snapshot.SourceMap = nil
+	if _, err := (Options{Comments: true}).ToYAML(snapshot, false); err == nil || !strings.Contains(err.Error(), "need a source map") {
+		t.Errorf("ToYAML without a source map error = %v", err)
+	}
+}
+
+func mustWithComments(t *testing.T, snapshot compiler.Snapshot) *keyOrderer {
+	t.Helper()
+	order, err := (*keyOrderer)(nil).withComments(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
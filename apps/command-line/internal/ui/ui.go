@@ -0,0 +1,168 @@
+// Package ui decides whether the CLI styles its terminal output and applies
+// the styles.
+//
+// Every command colors output through a Style from StyleFor, so the --color
+// flag, the NO_COLOR convention (https://no-color.org), and terminal
+// detection apply the same way to diagnostics, diffs, and anything added
+// later. In the default auto mode, output is colored only when it goes to a
+// terminal, NO_COLOR is unset or empty, and TERM is not "dumb", so escape
+// sequences never leak into pipes, files, or CI logs.
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode is a value of the --color flag.
+type ColorMode string
+
+// Color modes.
+const (
+	// ColorAuto colors output written to a terminal unless NO_COLOR is set
+	// or TERM is "dumb" (default).
+	ColorAuto ColorMode = "auto"
+
+	// ColorAlways colors output wherever it goes, even with NO_COLOR set.
+	ColorAlways ColorMode = "always"
+
+	// ColorNever never colors output.
+	ColorNever ColorMode = "never"
+)
+
+// NoColorEnvVar is the environment variable that disables colors in auto
+// mode when set to a non-empty value.
+const NoColorEnvVar = "NO_COLOR"
+
+// ParseColorMode parses a --color value. The empty string yields ColorAuto.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch m := ColorMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid --color %q (expected auto, always, or never)", s)
+	}
+}
+
+// ColorEnabled reports whether output written to w is colored in mode.
+func ColorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv(NoColorEnvVar) != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// IsTerminal reports whether v is a file attached to a terminal.
+func IsTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SGR parameters of the styles.
+const (
+	sgrBold   = "1"
+	sgrRed    = "31"
+	sgrGreen  = "32"
+	sgrYellow = "33"
+	sgrCyan   = "36"
+)
+
+// Style applies terminal styles to text, or returns text unchanged when
+// colors are disabled. The zero value is disabled.
+type Style struct {
+	enabled bool
+}
+
+// NewStyle returns a Style that colors text if enabled is true.
+func NewStyle(enabled bool) Style {
+	return Style{enabled: enabled}
+}
+
+// StyleFor returns the Style for output written to w in mode.
+func StyleFor(mode ColorMode, w io.Writer) Style {
+	return NewStyle(ColorEnabled(mode, w))
+}
+
+// Enabled reports whether s colors text.
+func (s Style) Enabled() bool {
+	return s.enabled
+}
+
+// Error styles an error message: bold red.
+func (s Style) Error(text string) string {
+	return s.apply(text, sgrBold, sgrRed)
+}
+
+// Warning styles a warning message: bold yellow.
+func (s Style) Warning(text string) string {
+	return s.apply(text, sgrBold, sgrYellow)
+}
+
+// Bold styles emphasized text.
+func (s Style) Bold(text string) string {
+	return s.apply(text, sgrBold)
+}
+
+// Added styles text that was added: green.
+func (s Style) Added(text string) string {
+	return s.apply(text, sgrGreen)
+}
+
+// Removed styles text that was removed: red.
+func (s Style) Removed(text string) string {
+	return s.apply(text, sgrRed)
+}
+
+// Header styles a section header, such as the file names of a diff: cyan.
+func (s Style) Header(text string) string {
+	return s.apply(text, sgrCyan)
+}
+
+// Diff styles the added ("+") and removed ("-") lines of a diff body,
+// given without its "---" and "+++" headers. Other lines are left
+// unchanged.
+func (s Style) Diff(diff string) string {
+	if !s.enabled {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "-"):
+			text = s.Removed(text)
+		case strings.HasPrefix(text, "+"):
+			text = s.Added(text)
+		default:
+			continue
+		}
+		if strings.HasSuffix(line, "\n") {
+			text += "\n"
+		}
+		lines[i] = text
+	}
+	return strings.Join(lines, "")
+}
+
+// apply wraps text in the SGR escape sequence of params and a reset.
+// Empty text stays empty, so no stray escape sequences are written.
+func (s Style) apply(text string, params ...string) string {
+	if !s.enabled || text == "" {
+		return text
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m" + text + "\x1b[0m"
+}
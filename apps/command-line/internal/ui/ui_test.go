@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ColorMode
+		wantErr bool
+	}{
+		{input: "", want: ColorAuto},
+		{input: "auto", want: ColorAuto},
+		{input: "Always", want: ColorAlways},
+		{input: " never ", want: ColorNever},
+		{input: "yes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseColorMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColorMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseColorMode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+
+	tests := []struct {
+		name    string
+		mode    ColorMode
+		noColor string
+		w       io.Writer
+		want    bool
+	}{
+		{name: "always on a pipe", mode: ColorAlways, w: w, want: true},
+		{name: "always overrides NO_COLOR", mode: ColorAlways, noColor: "1", w: w, want: true},
+		{name: "never", mode: ColorNever, w: w},
+		{name: "auto on a pipe", mode: ColorAuto, w: w},
+		{name: "auto on a buffer", mode: ColorAuto, w: &bytes.Buffer{}},
+		{name: "auto with NO_COLOR", mode: ColorAuto, noColor: "1", w: w},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(NoColorEnvVar, tt.noColor)
+			if got := ColorEnabled(tt.mode, tt.w); got != tt.want {
+				t.Errorf("ColorEnabled(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = r.Close()
+		_ = w.Close()
+	}()
+
+	for _, v := range []any{r, w, &bytes.Buffer{}, (*os.File)(nil), nil} {
+		if IsTerminal(v) {
+			t.Errorf("IsTerminal(%T) = true, want false", v)
+		}
+	}
+}
+
+func TestStyle(t *testing.T) {
+	plain := NewStyle(false)
+	for _, got := range []string{plain.Error("e"), plain.Warning("e"), plain.Bold("e"), plain.Added("e"), plain.Removed("e"), plain.Header("e")} {
+		if got != "e" {
+			t.Errorf("disabled style = %q, want plain text", got)
+		}
+	}
+	if (Style{}).Enabled() {
+		t.Error("zero Style is enabled")
+	}
+
+	color := NewStyle(true)
+	tests := []struct {
+		got, want string
+	}{
+		{color.Error("e"), "\x1b[1;31me\x1b[0m"},
+		{color.Warning("w"), "\x1b[1;33mw\x1b[0m"},
+		{color.Bold("b"), "\x1b[1mb\x1b[0m"},
+		{color.Added("a"), "\x1b[32ma\x1b[0m"},
+		{color.Removed("r"), "\x1b[31mr\x1b[0m"},
+		{color.Header("h"), "\x1b[36mh\x1b[0m"},
+		{color.Error(""), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestStyle_Diff(t *testing.T) {
+	diff := " {\n-  \"a\": 1\n+  \"a\": 2\n }\n"
+
+	if got := NewStyle(false).Diff(diff); got != diff {
+		t.Errorf("disabled Diff() = %q, want unchanged", got)
+	}
+
+	want := " {\n\x1b[31m-  \"a\": 1\x1b[0m\n\x1b[32m+  \"a\": 2\x1b[0m\n }\n"
+	if got := NewStyle(true).Diff(diff); got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
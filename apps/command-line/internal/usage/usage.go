@@ -0,0 +1,316 @@
+// Package usage compares compiled configuration against the schemas of its
+// consumers to find keys no consumer reads and required keys that are
+// missing.
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the keys one consumer reads.
+type Schema struct {
+	// Name identifies the schema in reports, normally its file name.
+	Name string
+
+	root *node
+}
+
+// node is the part of a schema describing the value at one key path.
+type node struct {
+	// properties are the map keys the consumer reads. A node without
+	// properties or items reads its whole value.
+	properties map[string]*node
+
+	// additional describes map keys not listed in properties; nil means
+	// the consumer does not read them.
+	additional *node
+
+	// items describes the elements of a list.
+	items *node
+
+	// required are the property names the consumer needs.
+	required []string
+
+	// whole marks an allowlisted path, read in full even when longer
+	// paths beneath it are listed too.
+	whole bool
+}
+
+// consumesAll reports whether the consumer reads the whole value at n.
+func (n *node) consumesAll() bool {
+	return n.whole || (n.properties == nil && n.additional == nil && n.items == nil)
+}
+
+// LoadSchema reads a consumer schema from a JSON or YAML file. The file
+// holds either a JSON Schema, of which properties, required, items, and
+// additionalProperties are used, or an allowlist: a list of key paths the
+// consumer reads, where a "*" segment matches any key (e.g. "app.*.host").
+func LoadSchema(path string) (*Schema, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read consumer schema: %w", err)
+	}
+	var document any
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: invalid consumer schema: %w", path, err)
+	}
+
+	schema := &Schema{Name: filepath.Base(path)}
+	switch doc := document.(type) {
+	case []any:
+		schema.root, err = allowlistNode(doc)
+	case map[string]any:
+		schema.root, err = schemaNode(doc, "")
+	default:
+		err = fmt.Errorf("expected a JSON Schema object or a list of key paths")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid consumer schema: %w", path, err)
+	}
+	return schema, nil
+}
+
+// schemaNode converts the JSON Schema object at path.
+func schemaNode(schema map[string]any, path string) (*node, error) {
+	n := &node{}
+	if properties, ok := schema["properties"]; ok {
+		m, ok := properties.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%sproperties must be an object", pathPrefix(path))
+		}
+		n.properties = make(map[string]*node, len(m))
+		for name, value := range m {
+			child, err := childSchemaNode(value, joinPath(path, name))
+			if err != nil {
+				return nil, err
+			}
+			n.properties[name] = child
+		}
+	}
+
+	switch additional := schema["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		if additional {
+			n.additional = &node{}
+		}
+	default:
+		child, err := childSchemaNode(additional, joinPath(path, "*"))
+		if err != nil {
+			return nil, err
+		}
+		n.additional = child
+	}
+	if n.properties == nil && n.additional != nil && n.additional.consumesAll() {
+		// Any key is read in full, as if nothing were listed
+		n.additional = nil
+	}
+
+	if items, ok := schema["items"]; ok {
+		child, err := childSchemaNode(items, path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		if !child.consumesAll() {
+			n.items = child
+		}
+	}
+
+	if required, ok := schema["required"]; ok {
+		list, ok := required.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%srequired must be a list of property names", pathPrefix(path))
+		}
+		for _, name := range list {
+			s, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%srequired must be a list of property names", pathPrefix(path))
+			}
+			n.required = append(n.required, s)
+		}
+	}
+	return n, nil
+}
+
+func childSchemaNode(value any, path string) (*node, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return schemaNode(v, path)
+	case bool:
+		// true and false schemas constrain nothing to read into
+		return &node{}, nil
+	default:
+		return nil, fmt.Errorf("%sschema must be an object", pathPrefix(path))
+	}
+}
+
+// allowlistNode converts a list of key paths.
+func allowlistNode(paths []any) (*node, error) {
+	root := &node{properties: map[string]*node{}}
+	for _, entry := range paths {
+		path, ok := entry.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("allowlist entries must be key paths, got %v", entry)
+		}
+		n := root
+		for _, segment := range strings.Split(path, ".") {
+			if segment == "" {
+				return nil, fmt.Errorf("invalid key path %q", path)
+			}
+			if n.whole {
+				break
+			}
+			if segment == "*" {
+				if n.additional == nil {
+					n.additional = &node{}
+				}
+				n = n.additional
+				continue
+			}
+			if n.properties == nil {
+				n.properties = make(map[string]*node)
+			}
+			if n.properties[segment] == nil {
+				n.properties[segment] = &node{}
+			}
+			n = n.properties[segment]
+		}
+		n.whole = true
+	}
+	return root, nil
+}
+
+// Missing is a required key absent from the data.
+type Missing struct {
+	Schema string `json:"schema"`
+	Path   string `json:"path"`
+}
+
+// Report lists the findings of Analyze.
+type Report struct {
+	// Unused are the key paths no schema reads, sorted. A map nobody reads
+	// is listed once, not key by key.
+	Unused []string `json:"unused"`
+
+	// Missing are the required keys absent from the data, sorted by schema
+	// and path.
+	Missing []Missing `json:"missing"`
+}
+
+// Empty reports whether the report has no findings.
+func (r Report) Empty() bool {
+	return len(r.Unused) == 0 && len(r.Missing) == 0
+}
+
+// Analyze compares data with the schemas of its consumers. Key paths use
+// dots between map keys and [n] for list indices, as in source maps.
+func Analyze(data map[string]any, schemas []*Schema) Report {
+	report := Report{Unused: []string{}, Missing: []Missing{}}
+
+	// The root is a map, so without schemas each top-level key is unused
+	roots := []*node{{properties: map[string]*node{}}}
+	if len(schemas) > 0 {
+		roots = roots[:0]
+	}
+	for _, schema := range schemas {
+		roots = append(roots, schema.root)
+		missing(schema.Name, schema.root, data, "", &report.Missing)
+	}
+	unused(roots, data, "", &report.Unused)
+
+	sort.Strings(report.Unused)
+	sort.Slice(report.Missing, func(i, j int) bool {
+		if report.Missing[i].Schema != report.Missing[j].Schema {
+			return report.Missing[i].Schema < report.Missing[j].Schema
+		}
+		return report.Missing[i].Path < report.Missing[j].Path
+	})
+	return report
+}
+
+// unused appends the paths beneath value that none of nodes reads.
+func unused(nodes []*node, value any, path string, out *[]string) {
+	if len(nodes) == 0 {
+		*out = append(*out, path)
+		return
+	}
+	for _, n := range nodes {
+		if n.consumesAll() {
+			return
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			var childNodes []*node
+			for _, n := range nodes {
+				if p, ok := n.properties[key]; ok {
+					childNodes = append(childNodes, p)
+				} else if n.additional != nil {
+					childNodes = append(childNodes, n.additional)
+				}
+			}
+			unused(childNodes, child, joinPath(path, key), out)
+		}
+	case []any:
+		// Without items, list elements are described by the node itself
+		var itemNodes []*node
+		for _, n := range nodes {
+			if n.items != nil {
+				itemNodes = append(itemNodes, n.items)
+			} else {
+				itemNodes = append(itemNodes, n)
+			}
+		}
+		for i, child := range v {
+			unused(itemNodes, child, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+// missing appends the required keys of n absent from value.
+func missing(schema string, n *node, value any, path string, out *[]Missing) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range n.required {
+			if _, ok := v[name]; !ok {
+				*out = append(*out, Missing{Schema: schema, Path: joinPath(path, name)})
+			}
+		}
+		for key, child := range v {
+			if p, ok := n.properties[key]; ok {
+				missing(schema, p, child, joinPath(path, key), out)
+			} else if n.additional != nil {
+				missing(schema, n.additional, child, joinPath(path, key), out)
+			}
+		}
+	case []any:
+		if n.items == nil {
+			return
+		}
+		for i, child := range v {
+			missing(schema, n.items, child, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func pathPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
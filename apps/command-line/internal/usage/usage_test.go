@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeSchema(t *testing.T, name, content string) *Schema {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatalf("LoadSchema() error = %v", err)
+	}
+	return schema
+}
+
+func TestAnalyze(t *testing.T) {
+	data := map[string]any{
+		"app": map[string]any{
+			"name":   "demo",
+			"legacy": map[string]any{"flag": "on", "mode": "old"},
+			"hosts": []any{
+				map[string]any{"name": "a", "port": "80"},
+			},
+		},
+		"db": map[string]any{
+			"host":  "localhost",
+			"users": map[string]any{"alice": map[string]any{"role": "admin", "quota": "1"}},
+		},
+		"unused": "value",
+	}
+
+	api := writeSchema(t, "api.schema.json", `{
+  "type": "object",
+  "required": ["app", "billing"],
+  "properties": {
+    "app": {
+      "type": "object",
+      "required": ["name", "version"],
+      "properties": {
+        "name": {"type": "string"},
+        "hosts": {"type": "array", "items": {"properties": {"name": {"type": "string"}}, "required": ["name", "tls"]}}
+      }
+    }
+  }
+}`)
+	jobs := writeSchema(t, "jobs.yaml", "- db.host\n- db.users.*.role\n")
+
+	report := Analyze(data, []*Schema{api, jobs})
+
+	wantUnused := []string{"app.hosts[0].port", "app.legacy", "db.users.alice.quota", "unused"}
+	if !reflect.DeepEqual(report.Unused, wantUnused) {
+		t.Errorf("Unused = %v, want %v", report.Unused, wantUnused)
+	}
+	wantMissing := []Missing{
+		{Schema: "api.schema.json", Path: "app.hosts[0].tls"},
+		{Schema: "api.schema.json", Path: "app.version"},
+		{Schema: "api.schema.json", Path: "billing"},
+	}
+	if !reflect.DeepEqual(report.Missing, wantMissing) {
+		t.Errorf("Missing = %v, want %v", report.Missing, wantMissing)
+	}
+	if report.Empty() {
+		t.Error("Empty() = true for a report with findings")
+	}
+}
+
+func TestAnalyze_WholeValues(t *testing.T) {
+	data := map[string]any{
+		"app":  map[string]any{"name": "demo", "extra": map[string]any{"a": "1"}},
+		"db":   map[string]any{"host": "localhost"},
+		"tags": []any{"a", "b"},
+	}
+
+	// A listed path covers everything beneath it, even when longer paths
+	// are listed too; additionalProperties covers unlisted keys
+	allowlist := writeSchema(t, "allow.json", `["app.name", "app", "tags"]`)
+	schema := writeSchema(t, "db.json", `{"properties": {"db": {"additionalProperties": true}}}`)
+
+	if report := Analyze(data, []*Schema{allowlist, schema}); !report.Empty() {
+		t.Errorf("Analyze() = %+v, want no findings", report)
+	}
+	if report := Analyze(data, nil); !reflect.DeepEqual(report.Unused, []string{"app", "db", "tags"}) {
+		t.Errorf("Analyze() without schemas = %v, want every top-level key", report.Unused)
+	}
+}
+
+func TestLoadSchema_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"scalar":     `"app"`,
+		"properties": `{"properties": ["app"]}`,
+		"required":   `{"required": "app"}`,
+		"entry":      `["app..name"]`,
+		"child":      `{"properties": {"app": "string"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "schema.json")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadSchema(path); err == nil {
+				t.Errorf("LoadSchema(%s) expected an error", content)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnalyzeUnused verifies that analyze unused reports unread and
+// missing keys, and fails only with --strict.
+func TestAnalyzeUnused(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"config.csl":      "app:\n  name: 'demo'\n  legacy: 'on'\n",
+		"api.schema.json": `{"properties": {"app": {"properties": {"name": {}}, "required": ["name", "port"]}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analyze := func(args ...string) (string, string, int) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"analyze", "unused", "-p", "config.csl", "--consumer-schema", "api.schema.json"}, args...)...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := analyze()
+	if exitCode != 0 {
+		t.Fatalf("analyze unused failed with exit code %d: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"Unused keys (1):\n  app.legacy", "app.port (required by api.schema.json)"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output lacks %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, exitCode = analyze("--json", "--strict")
+	if exitCode != 1 {
+		t.Errorf("exit code with --strict = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stdout, `"unused": [`) || !strings.Contains(stdout, `"path": "app.port"`) {
+		t.Errorf("JSON report is wrong:\n%s", stdout)
+	}
+}
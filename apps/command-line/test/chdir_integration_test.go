@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_ChdirFlag verifies that --chdir anchors input, output, and
+// .nomos paths to the given directory, whatever the caller's working
+// directory, and records it as project_root in the metadata.
+func TestBuild_ChdirFlag(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	for _, dir := range []string{".nomos", "out"} {
+		if err := os.Mkdir(filepath.Join(projectDir, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"app.csl":            "app:\n  name: 'demo'\n  port: '8080'\n",
+		".nomos/config.yaml": "type_coercion: strict\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run from an unrelated directory, as a CI wrapper would
+	callerDir := t.TempDir()
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "--chdir", projectDir, "build", "-p", "app.csl", "-o", "out/snapshot.json", "--include-metadata")
+	cmd.Dir = callerDir
+	_, stderr, exitCode := runCommand(t, cmd)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+
+	//nolint:gosec // G304: Test output path
+	output, err := os.ReadFile(filepath.Join(projectDir, "out", "snapshot.json"))
+	if err != nil {
+		t.Fatalf("output not written under --chdir directory: %v", err)
+	}
+	var doc struct {
+		Data     map[string]map[string]any `json:"data"`
+		Metadata map[string]any            `json:"metadata"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+
+	// The project's .nomos/config.yaml was loaded
+	if port := doc.Data["app"]["port"]; port != 8080.0 {
+		t.Errorf("app.port = %#v, want 8080 (strict coercion from project config)", port)
+	}
+
+	wantRoot, err := filepath.EvalSymlinks(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Metadata["project_root"]; got != wantRoot {
+		t.Errorf("project_root = %v, want %q", got, wantRoot)
+	}
+
+	entries, err := os.ReadDir(callerDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("caller directory was written to: %v", entries)
+	}
+}
+
+// TestBuild_ChdirFlag_Invalid verifies that a missing --chdir directory is
+// reported before any command runs.
+func TestBuild_ChdirFlag_Invalid(t *testing.T) {
+	binPath := buildCLI(t)
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "-C", filepath.Join(t.TempDir(), "missing"), "build", "-p", "app.csl")
+	_, stderr, exitCode := runCommand(t, cmd)
+	if exitCode == 0 {
+		t.Fatal("expected build to fail for a missing --chdir directory")
+	}
+	if !strings.Contains(stderr, "invalid --chdir") {
+		t.Errorf("stderr = %q, want it to mention invalid --chdir", stderr)
+	}
+}
@@ -0,0 +1,83 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Checksums verifies that --checksums lists every written file
+// with its digest, for single-file and split builds.
+func TestBuild_Checksums(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := "app:\n  port: '8080'\ndatabase:\n  host: 'db'\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl", "--checksums"}, args...)...)
+		cmd.Dir = projectDir
+		if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+			t.Fatalf("build %v failed with exit code %d: %s", args, exitCode, stderr)
+		}
+	}
+	// verify checks every manifest line against the file it names and
+	// returns the listed paths
+	verify := func(dir string) []string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "outputs.sha256"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if !strings.HasPrefix(lines[0], "# snapshot sha256:") {
+			t.Errorf("manifest header = %q", lines[0])
+		}
+		var paths []string
+		for _, line := range lines[1:] {
+			digest, path, ok := strings.Cut(line, "  ")
+			if !ok {
+				t.Fatalf("malformed line %q", line)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != digest {
+				t.Errorf("%s: digest mismatch", path)
+			}
+			paths = append(paths, path)
+		}
+		return paths
+	}
+
+	run("-o", "build/config.json", "--source-map", "build/config.map.json")
+	if got := verify(filepath.Join(projectDir, "build")); strings.Join(got, ",") != "config.json,config.map.json" {
+		t.Errorf("single build lists %v", got)
+	}
+	single, _ := os.ReadFile(filepath.Join(projectDir, "build", "outputs.sha256"))
+
+	run("-f", "yaml", "--split-depth", "1", "-o", "split")
+	if got := verify(filepath.Join(projectDir, "split")); strings.Join(got, ",") != "app.yaml,database.yaml,index.json" {
+		t.Errorf("split build lists %v", got)
+	}
+	split, _ := os.ReadFile(filepath.Join(projectDir, "split", "outputs.sha256"))
+
+	// The snapshot hash does not depend on the output format
+	header := func(b []byte) string { return strings.SplitN(string(b), "\n", 2)[0] }
+	if header(single) != header(split) {
+		t.Errorf("snapshot hash differs: %q vs %q", header(single), header(split))
+	}
+}
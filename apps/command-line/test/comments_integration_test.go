@@ -0,0 +1,60 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Comments verifies that --comments carries .csl comments into
+// YAML output.
+func TestBuild_Comments(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := `# Generated by nomos; edit app.csl instead.
+
+# The API service.
+service:
+  # Listen port
+  port: '8080'
+  name: 'api'
+# Deployment region
+region: 'us-east-1'
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "app.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, string, int) {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "app.csl"}, args...)...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := run("-f", "yaml", "--comments", "--key-order", "source")
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	want := "# The API service.\nservice:\n  # Listen port\n  port: \"8080\"\n  name: api\n# Deployment region\nregion: us-east-1\n"
+	if got := strings.TrimSuffix(stdout, "\n"); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without the flag the output is unchanged
+	stdout, _, _ = run("-f", "yaml")
+	if strings.Contains(stdout, "#") {
+		t.Errorf("output without --comments has comments:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = run("-f", "json", "--comments")
+	if exitCode == 0 || !strings.Contains(stderr, "--comments applies to yaml output") {
+		t.Errorf("json with --comments: exit %d, stderr %s", exitCode, stderr)
+	}
+}
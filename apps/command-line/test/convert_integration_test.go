@@ -0,0 +1,50 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConvert_JSONSnapshotToTfvars tests converting a built snapshot to another
+// format without recompiling.
+func TestConvert_JSONSnapshotToTfvars(t *testing.T) {
+	binPath := buildCLI(t)
+
+	tmpDir := t.TempDir()
+	fixturePath := filepath.Join(tmpDir, "test.csl")
+	//nolint:gosec // G306: Test file with non-sensitive content
+	if err := os.WriteFile(fixturePath, []byte("app:\n  name: 'demo'\n  port: 8080\n"), 0644); err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+
+	snapshotPath := filepath.Join(tmpDir, "snapshot.json")
+	//nolint:gosec,noctx // G204: Test with controlled input
+	build := exec.Command(binPath, "build", "-p", fixturePath, "--include-metadata", "-o", snapshotPath)
+	if _, stderr, exitCode := runCommand(t, build); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// Remove the source to prove convert does not recompile.
+	if err := os.Remove(fixturePath); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:gosec,noctx // G204: Test with controlled input
+	convert := exec.Command(binPath, "convert", snapshotPath, "--format", "tfvars")
+	stdout, stderr, exitCode := runCommand(t, convert)
+	if exitCode != 0 {
+		t.Fatalf("convert failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	for _, want := range []string{"app = {", "name = \"demo\"", "port = \"8080\""} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_DebugDump verifies that --debug-dump writes the intermediate
+// state of a build, including when the build fails.
+func TestBuild_DebugDump(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	for name, content := range map[string]string{
+		"config.csl": "source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './platform.json'\n\n" +
+			"app:\n  owner: @shared:platform.owner\n  port: 8080\n",
+		"platform.json": `{"platform": {"owner": "infra"}}`,
+	} {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "build", "-p", "config.csl", "--debug-dump", "dump", "--set", "app.port=9090")
+	cmd.Dir = projectDir
+	_, stderr, exitCode := runCommand(t, cmd)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "Debug dump written to dump") {
+		t.Errorf("stderr = %q, want the dump location", stderr)
+	}
+
+	for name, want := range map[string]string{
+		"manifest.json":           `"ast": "ast/001-config.csl.json"`,
+		"ast/001-config.csl.json": `"statements"`,
+		"references.json":         `"value": "infra"`,
+		"merges.json":             `"kind": "override"`,
+	} {
+		//nolint:gosec // G304: Test reads its own output
+		data, err := os.ReadFile(filepath.Join(projectDir, "dump", name))
+		if err != nil {
+			t.Fatalf("dump file missing: %v", err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s does not contain %q:\n%s", name, want, data)
+		}
+	}
+
+	// A failed build still writes the dump
+	//nolint:gosec // G306: Test file with non-sensitive content
+	if err := os.WriteFile(filepath.Join(projectDir, "broken.csl"), []byte("app:\n  owner: @missing:key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd = exec.Command(binPath, "build", "-p", "broken.csl", "--debug-dump", "failed")
+	cmd.Dir = projectDir
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode == 0 {
+		t.Fatalf("expected build to fail, stderr: %s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "failed", "manifest.json")); err != nil {
+		t.Errorf("dump of failed build not written: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDiagnosticsFormatGitHub verifies that --diagnostics-format github
+// prints errors as workflow commands with paths relative to
+// GITHUB_WORKSPACE.
+func TestDiagnosticsFormatGitHub(t *testing.T) {
+	binPath := buildCLI(t)
+
+	workspace := t.TempDir()
+	configDir := filepath.Join(workspace, "config")
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "app.csl"), []byte("app: 'demo'\nbad: 'unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "validate", "-p", "config/app.csl", "--diagnostics-format", "github")
+	cmd.Dir = workspace
+	cmd.Env = append(os.Environ(), "GITHUB_WORKSPACE="+workspace)
+	_, stderr, exitCode := runCommand(t, cmd)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(stderr, "::error file=config/app.csl,line=2,col=6::") {
+		t.Errorf("stderr lacks a workflow command for config/app.csl:2:6:\n%s", stderr)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_FileTypes verifies that formats in .nomos/config.yaml set the
+// extension appended to --out paths.
+func TestBuild_FileTypes(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "app.csl"), []byte("region: 'us-east-1'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectDir, ".nomos"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	config := "formats:\n  yaml:\n    extensions: [.yml, .yaml]\n  json:\n    extensions: [.json, .snapshot]\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".nomos", "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, int) {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, args...)
+		cmd.Dir = projectDir
+		_, stderr, exitCode := runCommand(t, cmd)
+		return stderr, exitCode
+	}
+
+	if stderr, exitCode := run("build", "-p", "app.csl", "-f", "yaml", "-o", "out"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "out.yml")); err != nil {
+		t.Errorf("configured extension not appended: %v", err)
+	}
+
+	// An added extension is preserved and detected by convert
+	if stderr, exitCode := run("build", "-p", "app.csl", "-o", "app.snapshot"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if stderr, exitCode := run("convert", "app.snapshot", "-f", "yaml", "-o", "converted"); exitCode != 0 {
+		t.Fatalf("convert failed with exit code %d: %s", exitCode, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(projectDir, "converted.yml"))
+	if err != nil || !strings.Contains(string(data), "region: us-east-1") {
+		t.Errorf("converted.yml = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGet_Integration verifies that nomos get prints single values from a
+// compile or a snapshot, and exits with code 3 for absent keys.
+func TestGet_Integration(t *testing.T) {
+	binPath := buildCLI(t)
+
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "app.csl")
+	if err := os.WriteFile(fixture, []byte("app:\n  name: 'demo'\n  database:\n    host: 'db.internal'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := filepath.Join(dir, "snapshot.yaml")
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	build := exec.Command(binPath, "build", "-p", fixture, "-f", "yaml", "-o", snapshot, "--include-metadata")
+	if _, stderr, exitCode := runCommand(t, build); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantCode int
+	}{
+		{name: "compile raw", args: []string{"get", "app.database.host", "-p", fixture}, wantOut: "db.internal\n"},
+		{name: "compile json", args: []string{"get", "app.name", "--json", "-p", fixture}, wantOut: "\"demo\"\n"},
+		{name: "compile override", args: []string{"get", "app.name", "-p", fixture, "--set", "app.name=prod"}, wantOut: "prod\n"},
+		{name: "snapshot", args: []string{"get", "app.database", "--from-snapshot", snapshot}, wantOut: "{\n  \"host\": \"db.internal\"\n}\n"},
+		{name: "absent key", args: []string{"get", "app.missing", "-p", fixture}, wantCode: 3},
+		{name: "no source", args: []string{"get", "app.name"}, wantCode: 1},
+		{name: "missing snapshot", args: []string{"get", "app.name", "--from-snapshot", filepath.Join(dir, "missing.json")}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//nolint:gosec,noctx // G204: Test command with controlled input
+			cmd := exec.Command(binPath, tt.args...)
+			stdout, stderr, exitCode := runCommand(t, cmd)
+			if exitCode != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr: %s)", exitCode, tt.wantCode, stderr)
+			}
+			if tt.wantCode == 0 && stdout != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGitRevisions verifies that build --at compiles committed files and
+// diff --from-ref compares a revision with the working tree, neither
+// touching uncommitted changes.
+func TestGitRevisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	configPath := filepath.Join(projectDir, "config.csl")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = projectDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(configPath, []byte("app:\n  port: '80'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "base")
+	if err := os.WriteFile(configPath, []byte("app:\n  port: '8080'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	nomos := func(args ...string) (string, string, int) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, args...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := nomos("build", "-p", "config.csl", "--at", "base")
+	if exitCode != 0 {
+		t.Fatalf("build --at failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"port": "80"`) {
+		t.Errorf("build --at base = %s, want the committed port", stdout)
+	}
+
+	stdout, stderr, exitCode = nomos("diff", "-p", "config.csl", "--from-ref", "base", "--exit-code")
+	if exitCode != 1 {
+		t.Errorf("diff --exit-code exit code = %d, want 1: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"--- base", "+++ working tree", `-    "port": "80"`, `+    "port": "8080"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("diff lacks %q:\n%s", want, stdout)
+		}
+	}
+
+	if content, _ := os.ReadFile(configPath); !strings.Contains(string(content), "8080") {
+		t.Errorf("working tree changed: %s", content)
+	}
+
+	if _, _, exitCode = nomos("diff", "-p", "config.csl", "--from-ref", "no-such-ref"); exitCode != 1 {
+		t.Errorf("diff with an unknown revision exit code = %d, want 1", exitCode)
+	}
+}
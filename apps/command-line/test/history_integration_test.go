@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHistory verifies that builds are recorded when history is enabled,
+// pruned by max_entries, and can be listed, shown, and diffed.
+func TestHistory(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".nomos"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".nomos", "config.yaml"), []byte("history:\n  enabled: true\n  max_entries: 2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	nomos := func(args ...string) string {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, args...)
+		cmd.Dir = projectDir
+		stdout, stderr, exitCode := runCommand(t, cmd)
+		if exitCode != 0 {
+			t.Fatalf("nomos %v failed with exit code %d: %s", args, exitCode, stderr)
+		}
+		return stdout
+	}
+
+	for _, port := range []string{"80", "443", "8080"} {
+		if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte("app:\n  port: '"+port+"'\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		nomos("build", "-p", "config.csl", "-o", "out.json")
+	}
+
+	list := nomos("history", "list")
+	if strings.Contains(list, "\n1 ") || !strings.Contains(list, "\n2 ") || !strings.Contains(list, "\n3 ") {
+		t.Errorf("history list should hold builds 2 and 3:\n%s", list)
+	}
+
+	if show := nomos("history", "show", "2"); !strings.Contains(show, `"port": "443"`) {
+		t.Errorf("history show 2 = %s, want port 443", show)
+	}
+
+	diff := nomos("history", "diff", "2")
+	for _, want := range []string{"--- #2", "+++ #3", `-    "port": "443"`, `+    "port": "8080"`} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("history diff lacks %q:\n%s", want, diff)
+		}
+	}
+}
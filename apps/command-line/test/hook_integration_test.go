@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHookInstall verifies that the installed pre-commit hook blocks
+// commits of invalid .csl files and lets valid ones through.
+func TestHookInstall(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	binPath := buildCLI(t)
+
+	repoDir := t.TempDir()
+	configPath := filepath.Join(repoDir, "config", "app.csl")
+	git := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	if out, err := git("init", "-q"); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "hook", "install", "--command", binPath)
+	cmd.Dir = repoDir
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+		t.Fatalf("hook install failed with exit code %d: %s", exitCode, stderr)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("app: 'demo'\nbad: 'unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := git("add", "."); err != nil {
+		t.Fatalf("git add: %v: %s", err, out)
+	}
+	out, err := git("commit", "-q", "-m", "invalid")
+	if err == nil {
+		t.Fatalf("commit of an invalid .csl file succeeded: %s", out)
+	}
+	if !strings.Contains(out, "unterminated string") {
+		t.Errorf("hook output lacks the syntax error:\n%s", out)
+	}
+
+	if err := os.WriteFile(configPath, []byte("app: 'demo'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := git("add", "."); err != nil {
+		t.Fatalf("git add: %v: %s", err, out)
+	}
+	if out, err := git("commit", "-q", "-m", "valid"); err != nil {
+		t.Errorf("commit of a valid .csl file failed: %v: %s", err, out)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd = exec.Command(binPath, "hook", "uninstall")
+	cmd.Dir = repoDir
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+		t.Errorf("hook uninstall failed with exit code %d: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "hooks", "pre-commit")); !os.IsNotExist(err) {
+		t.Errorf("pre-commit hook still exists after uninstall: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuild_JSONFormat verifies the JSON whitespace and escaping flags on
+// stdout and in files.
+func TestBuild_JSONFormat(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "app.csl"), []byte("app:\n  link: '<a>&'\n  port: '8080'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantFile string
+	}{
+		{
+			name:    "stdout default",
+			wantOut: "{\n  \"app\": {\n    \"link\": \"<a>&\",\n    \"port\": \"8080\"\n  }\n}\n",
+		},
+		{
+			name:     "file default",
+			args:     []string{"-o", "out.json"},
+			wantFile: "{\n  \"app\": {\n    \"link\": \"<a>&\",\n    \"port\": \"8080\"\n  }\n}",
+		},
+		{
+			name:    "minified without newline",
+			args:    []string{"--json-minify", "--json-trailing-newline=false"},
+			wantOut: `{"app":{"link":"<a>&","port":"8080"}}`,
+		},
+		{
+			name:     "tab indent, escaped, newline in file",
+			args:     []string{"-o", "out.json", "--json-indent", "tab", "--json-escape-html", "--json-trailing-newline"},
+			wantFile: "{\n\t\"app\": {\n\t\t\"link\": \"\\u003ca\\u003e\\u0026\",\n\t\t\"port\": \"8080\"\n\t}\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		for _, bench := range []bool{false, true} {
+			name := tt.name
+			args := append([]string{"build", "-p", "app.csl", "-f", "json", "--quiet"}, tt.args...)
+			if bench {
+				// --bench takes the buffered write path instead of streaming
+				name += " buffered"
+				args = append(args, "--bench")
+			}
+			t.Run(name, func(t *testing.T) {
+				_ = os.Remove(filepath.Join(projectDir, "out.json"))
+
+				//nolint:gosec,noctx // G204: Test command with controlled input
+				cmd := exec.Command(binPath, args...)
+				cmd.Dir = projectDir
+				stdout, stderr, exitCode := runCommand(t, cmd)
+				if exitCode != 0 {
+					t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+				}
+				if stdout != tt.wantOut {
+					t.Errorf("stdout = %q, want %q", stdout, tt.wantOut)
+				}
+				if tt.wantFile != "" {
+					got, err := os.ReadFile(filepath.Join(projectDir, "out.json"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(got) != tt.wantFile {
+						t.Errorf("file = %q, want %q", got, tt.wantFile)
+					}
+				}
+			})
+		}
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "build", "-p", "app.csl", "-f", "yaml", "--json-minify")
+	cmd.Dir = projectDir
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode == 0 {
+		t.Errorf("--json-minify with yaml succeeded; stderr %s", stderr)
+	}
+}
@@ -0,0 +1,174 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_KeyOrder verifies --key-order and key_order in
+// .nomos/config.yaml against real source maps.
+func TestBuild_KeyOrder(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(projectDir, ".nomos"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	source := "service:\n  port: '8080'\n  name: 'api'\n  debug: 'false'\nkind: 'Service'\napiVersion: 'v1'\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "app.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   string
+	}{
+		{
+			name: "alphabetical by default",
+			args: []string{"-f", "yaml"},
+			want: "apiVersion: v1\nkind: Service\nservice:\n  debug: \"false\"\n  name: api\n  port: \"8080\"\n",
+		},
+		{
+			name: "source",
+			args: []string{"-f", "yaml", "--key-order", "source"},
+			want: "service:\n  port: \"8080\"\n  name: api\n  debug: \"false\"\nkind: Service\napiVersion: v1\n",
+		},
+		{
+			name: "priority",
+			args: []string{"-f", "json", "--key-order", "priority:kind,apiVersion,name"},
+			want: "{\n  \"kind\": \"Service\",\n  \"apiVersion\": \"v1\",\n  \"service\": {\n    \"name\": \"api\",\n    \"debug\": \"false\",\n    \"port\": \"8080\"\n  }\n}",
+		},
+		{
+			name:   "project config per format",
+			config: "key_order:\n  tfvars: source\n",
+			args:   []string{"-f", "tfvars"},
+			want:   "service = {\n  port = \"8080\"\n  name = \"api\"\n  debug = \"false\"\n}\nkind = \"Service\"\napiVersion = \"v1\"\n",
+		},
+		{
+			name:   "flag overrides project config",
+			config: "key_order:\n  yaml: source\n",
+			args:   []string{"-f", "yaml", "--key-order", "alphabetical"},
+			want:   "apiVersion: v1\nkind: Service\nservice:\n  debug: \"false\"\n  name: api\n  port: \"8080\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(projectDir, ".nomos", "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			args := append([]string{"build", "-p", "app.csl"}, tt.args...)
+			//nolint:gosec,noctx // G204: Test command with controlled input
+			cmd := exec.Command(binPath, args...)
+			cmd.Dir = projectDir
+			stdout, stderr, exitCode := runCommand(t, cmd)
+			if exitCode != 0 {
+				t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+			}
+			if got := strings.TrimSuffix(stdout, "\n"); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// Source order compiles a source map but only writes one when asked
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("project directory has %d entries, want app.csl and .nomos only", len(entries))
+	}
+}
+
+// TestConvertMerge_KeyOrder verifies that convert and merge follow key_order
+// in .nomos/config.yaml and refuse source order, which needs a source map
+// snapshot files do not carry.
+func TestConvertMerge_KeyOrder(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(projectDir, ".nomos"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"base.json": `{"service": {"port": "8080", "name": "api"}, "apiVersion": "v1"}`,
+		"kind.json": `{"kind": "Service"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "convert follows project config",
+			config: "key_order:\n  yaml: priority:kind,apiVersion,name\n",
+			args:   []string{"convert", "base.json", "-f", "yaml"},
+			want:   "apiVersion: v1\nservice:\n  name: api\n  port: \"8080\"\n",
+		},
+		{
+			name:   "merge follows project config",
+			config: "key_order:\n  yaml: priority:kind,apiVersion,name\n",
+			args:   []string{"merge", "base.json", "kind.json", "-f", "yaml"},
+			want:   "kind: Service\napiVersion: v1\nservice:\n  name: api\n  port: \"8080\"\n",
+		},
+		{
+			name:   "flag overrides project config",
+			config: "key_order:\n  yaml: priority:kind\n",
+			args:   []string{"merge", "base.json", "kind.json", "-f", "yaml", "--key-order", "alphabetical"},
+			want:   "apiVersion: v1\nkind: Service\nservice:\n  name: api\n  port: \"8080\"\n",
+		},
+		{
+			name:    "convert refuses source order from project config",
+			config:  "key_order:\n  yaml: source\n",
+			args:    []string{"convert", "base.json", "-f", "yaml"},
+			wantErr: "source key order needs a source map",
+		},
+		{
+			name:    "merge refuses source order flag",
+			args:    []string{"merge", "base.json", "kind.json", "--key-order", "source"},
+			wantErr: "source key order needs a source map",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(projectDir, ".nomos", "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			//nolint:gosec,noctx // G204: Test command with controlled input
+			cmd := exec.Command(binPath, tt.args...)
+			cmd.Dir = projectDir
+			stdout, stderr, exitCode := runCommand(t, cmd)
+			if tt.wantErr != "" {
+				if exitCode == 0 || !strings.Contains(stderr, tt.wantErr) {
+					t.Errorf("exit code %d, stderr %q; want a failure mentioning %q", exitCode, stderr, tt.wantErr)
+				}
+				return
+			}
+			if exitCode != 0 {
+				t.Fatalf("%s failed with exit code %d: %s", tt.args[0], exitCode, stderr)
+			}
+			if got := strings.TrimSuffix(stdout, "\n"); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
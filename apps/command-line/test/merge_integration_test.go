@@ -0,0 +1,61 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMerge_Snapshots tests combining snapshots built separately, failing
+// on conflicting keys unless a strategy picks a side.
+func TestMerge_Snapshots(t *testing.T) {
+	binPath := buildCLI(t)
+
+	tmpDir := t.TempDir()
+	build := func(name, content string) string {
+		fixturePath := filepath.Join(tmpDir, name+".csl")
+		//nolint:gosec // G306: Test file with non-sensitive content
+		if err := os.WriteFile(fixturePath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create fixture: %v", err)
+		}
+		snapshotPath := filepath.Join(tmpDir, name+".json")
+		//nolint:gosec,noctx // G204: Test with controlled input
+		cmd := exec.Command(binPath, "build", "-p", fixturePath, "--include-metadata", "-o", snapshotPath)
+		if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+			t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+		}
+		return snapshotPath
+	}
+	network := build("network", "app:\n  name: 'demo'\n  port: 8080\nnetwork:\n  cidr: '10.0.0.0/16'\n")
+	data := build("data", "app:\n  port: 9090\ndb:\n  host: 'db.internal'\n")
+
+	//nolint:gosec,noctx // G204: Test with controlled input
+	merge := exec.Command(binPath, "merge", network, data)
+	_, stderr, exitCode := runCommand(t, merge)
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1 for conflicting keys, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "app.port") {
+		t.Errorf("expected stderr to name the conflicting key, got:\n%s", stderr)
+	}
+
+	//nolint:gosec,noctx // G204: Test with controlled input
+	merge = exec.Command(binPath, "merge", network, data, "--strategy", "last-wins", "--include-metadata")
+	stdout, stderr, exitCode := runCommand(t, merge)
+	if exitCode != 0 {
+		t.Fatalf("merge failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "app.port") {
+		t.Errorf("expected a conflict warning on stderr, got:\n%s", stderr)
+	}
+	for _, want := range []string{`"port": "9090"`, `"cidr": "10.0.0.0/16"`, `"host": "db.internal"`, "network.csl", "data.csl"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_OutputFileSafety verifies --no-clobber and --backup.
+func TestBuild_OutputFileSafety(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "app.csl"), []byte("region: 'us-east-1'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(projectDir, "out.json")
+	if err := os.WriteFile(outPath, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, int) {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "app.csl", "-o", "out.json"}, args...)...)
+		cmd.Dir = projectDir
+		_, stderr, exitCode := runCommand(t, cmd)
+		return stderr, exitCode
+	}
+
+	stderr, exitCode := run("--no-clobber")
+	if exitCode != 1 || !strings.Contains(stderr, "already exists") {
+		t.Errorf("--no-clobber: exit %d, stderr %s", exitCode, stderr)
+	}
+	if data, _ := os.ReadFile(outPath); string(data) != "previous" {
+		t.Errorf("--no-clobber replaced the file: %q", data)
+	}
+
+	if stderr, exitCode = run("--backup"); exitCode != 0 {
+		t.Fatalf("--backup failed with exit code %d: %s", exitCode, stderr)
+	}
+	backups, _ := filepath.Glob(outPath + ".*.bak")
+	if len(backups) != 1 || !strings.Contains(stderr, "Backup written to") {
+		t.Fatalf("backups = %v, stderr %s", backups, stderr)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "previous" {
+		t.Errorf("backup = %q, want previous output", data)
+	}
+	if data, _ := os.ReadFile(outPath); !strings.Contains(string(data), "us-east-1") {
+		t.Errorf("output = %q", data)
+	}
+
+	if _, exitCode = run("--no-clobber", "--backup"); exitCode == 0 {
+		t.Error("--no-clobber with --backup succeeded")
+	}
+}
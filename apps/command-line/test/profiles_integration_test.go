@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_Profiles verifies that --profile merges the selected profiles
+// and records them in the metadata.
+func TestBuild_Profiles(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := "app:\n  replicas: '1'\n\nprofile:\n  prod:\n    app:\n      replicas: '3'\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func(args ...string) (string, string, int) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl", "--include-metadata"}, args...)...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := build("--profile", "prod")
+	if exitCode != 0 {
+		t.Fatalf("build --profile prod failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"replicas": "3"`) || strings.Contains(stdout, `"profile": {`) {
+		t.Errorf("output does not apply the prod profile:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, `"profiles": [`) || !strings.Contains(stdout, `"prod"`) {
+		t.Errorf("metadata does not record the profile:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = build()
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"replicas": "1"`) {
+		t.Errorf("output without --profile is not the base data:\n%s", stdout)
+	}
+
+	if _, stderr, exitCode = build("--profile", "qa"); exitCode == 0 || !strings.Contains(stderr, "unknown profile") {
+		t.Errorf("exit code = %d, stderr = %q; want unknown profile error", exitCode, stderr)
+	}
+}
@@ -0,0 +1,66 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_ProviderPermissions verifies that provider_permissions in the
+// project config rejects forbidden source declarations with located
+// errors, before any provider is installed.
+func TestBuild_ProviderPermissions(t *testing.T) {
+	binPath := buildCLI(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".nomos/config.yaml": "provider_permissions:\n  types:\n    datafile:\n      config:\n        path:\n          allow: [./data/**]\n",
+		"data/app.yaml":      "owner: platform\n",
+		"secrets.yaml":       "owner: root\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build := func(source string) (string, string, int) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "app.csl"), []byte(source+"\napp:\n  owner: @shared:owner\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, "build", "-p", "app.csl")
+		cmd.Dir = dir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := build("source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './data/app.yaml'\n")
+	if exitCode != 0 {
+		t.Fatalf("permitted build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"owner": "platform"`) {
+		t.Errorf("permitted build = %s, want the data file's owner", stdout)
+	}
+
+	_, stderr, exitCode = build("source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './data/../secrets.yaml'\n")
+	if exitCode == 0 || !strings.Contains(stderr, "app.csl:4:9") || !strings.Contains(stderr, `value "./data/../secrets.yaml" matches none of ./data/**`) {
+		t.Errorf("escaping path exit code = %d, stderr = %s; want a located permission error", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = build("source:\n  alias: 'shared'\n  type: 'acme/nomos-provider-shell'\n  version: '1.0.0'\n")
+	if exitCode == 0 || !strings.Contains(stderr, "provider type is not permitted (permitted: datafile)") {
+		t.Errorf("forbidden type exit code = %d, stderr = %s; want a permission error", exitCode, stderr)
+	}
+	if strings.Contains(stderr, "provider management failed") {
+		t.Errorf("forbidden provider was installed: %s", stderr)
+	}
+}
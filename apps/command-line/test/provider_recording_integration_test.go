@@ -0,0 +1,72 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_RecordAndReplayProviders verifies that a build replayed from
+// --record-providers produces the same output without reaching the
+// provider's data.
+func TestBuild_RecordAndReplayProviders(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := "source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './platform.json'\n\n" +
+		"app:\n  owner: @shared:platform.owner\n"
+	for name, content := range map[string]string{
+		"config.csl":    source,
+		"platform.json": `{"platform": {"owner": "infra"}}`,
+	} {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	build := func(args ...string) (string, string, int) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl"}, args...)...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	recorded, stderr, exitCode := build("--record-providers", "recording")
+	if exitCode != 0 {
+		t.Fatalf("recording build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "recording", "shared.json")); err != nil {
+		t.Fatalf("recording not written: %v", err)
+	}
+
+	// The replay must not read the data file
+	if err := os.Remove(filepath.Join(projectDir, "platform.json")); err != nil {
+		t.Fatal(err)
+	}
+	replayed, stderr, exitCode := build("--replay-providers", "recording")
+	if exitCode != 0 {
+		t.Fatalf("replayed build failed with exit code %d: %s", exitCode, stderr)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed output = %q, want %q", replayed, recorded)
+	}
+
+	// A reference outside the recording fails instead of fetching
+	source += "  region: @shared:platform.region\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, exitCode := build("--replay-providers", "recording"); exitCode == 0 || !strings.Contains(stderr, "not in the recording") {
+		t.Errorf("exit code = %d, stderr = %q; want failure naming the recording", exitCode, stderr)
+	}
+
+	if _, stderr, exitCode := build("--record-providers", "a", "--replay-providers", "b"); exitCode == 0 || !strings.Contains(stderr, "none of the others can be") {
+		t.Errorf("exit code = %d, stderr = %q; want mutually exclusive flag error", exitCode, stderr)
+	}
+}
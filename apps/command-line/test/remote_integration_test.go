@@ -0,0 +1,234 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startNomosd builds and starts nomosd with args on a free port and
+// returns its address.
+func startNomosd(t *testing.T, args ...string) string {
+	t.Helper()
+	binPath := filepath.Join(t.TempDir(), "nomosd")
+	//nolint:gosec,noctx // G204: Test helper, controlled input
+	build := exec.Command("go", "build", "-o", binPath, "./cmd/nomosd")
+	build.Dir = ".."
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build nomosd: %v\noutput: %s", err, output)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, append([]string{"--listen", "127.0.0.1:0"}, args...)...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start nomosd: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	})
+
+	addr := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if _, after, ok := strings.Cut(scanner.Text(), "listening on "); ok {
+				addr <- after
+			}
+		}
+	}()
+	select {
+	case a := <-addr:
+		return a
+	case <-time.After(30 * time.Second):
+		t.Fatal("nomosd did not start listening")
+		return ""
+	}
+}
+
+// TestRemoteBuild verifies that build --remote compiles the local files,
+// or a revision of the server's repository, on nomosd.
+func TestRemoteBuild(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	binPath := buildCLI(t)
+
+	// The server's repository holds a committed revision
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, "config"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "config", "app.csl"), []byte("app:\n  port: '80'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1")
+
+	addr := startNomosd(t, "-C", t.TempDir(), "--repo", repoDir)
+
+	// The client's working tree differs from the revision
+	clientDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(clientDir, "config"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(clientDir, "config", "app.csl"), []byte("app:\n  port: '8080'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nomos := func(args ...string) (string, string, int) {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, args...)
+		cmd.Dir = clientDir
+		return runCommand(t, cmd)
+	}
+
+	stdout, stderr, exitCode := nomos("build", "-p", "config", "--remote", addr, "--set", "app.env=prod")
+	if exitCode != 0 {
+		t.Fatalf("build --remote failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"port": "8080"`) || !strings.Contains(stdout, `"env": "prod"`) {
+		t.Errorf("build --remote = %s, want the working tree with the override", stdout)
+	}
+
+	stdout, stderr, exitCode = nomos("build", "-p", "config", "--remote", addr, "--at", "v1")
+	if exitCode != 0 {
+		t.Fatalf("build --remote --at failed with exit code %d: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"port": "80"`) {
+		t.Errorf("build --remote --at v1 = %s, want the committed port", stdout)
+	}
+
+	if _, stderr, exitCode = nomos("build", "-p", "config", "--remote", addr, "--policy", "p.yaml"); exitCode == 0 || !strings.Contains(stderr, "--policy cannot be used with --remote") {
+		t.Errorf("build --remote --policy exit code = %d, stderr = %s; want rejected", exitCode, stderr)
+	}
+}
+
+// TestNomosdWebhook verifies that nomosd rebuilds and publishes the targets
+// a signed GitHub push affects, recording the published commit under the
+// target's lock.
+func TestNomosdWebhook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, "config"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "config", "app.csl"), []byte("app:\n  port: '80'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	commit := git("rev-parse", "HEAD")
+
+	etcDir := t.TempDir()
+	targetsFile := filepath.Join(etcDir, "targets.yaml")
+	if err := os.WriteFile(targetsFile, []byte("branch: main\ntargets:\n  - name: app\n    path: config\n    format: yaml\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secretFile := filepath.Join(etcDir, "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	publishDir := t.TempDir()
+
+	addr := startNomosd(t, "-C", t.TempDir(), "--repo", repoDir, "--targets", targetsFile,
+		"--webhook-secret-file", secretFile, "--publish-dir", publishDir, "--publish-lock", "file")
+
+	payload, _ := json.Marshal(map[string]any{
+		"ref":     "refs/heads/main",
+		"after":   commit,
+		"commits": []map[string]any{{"modified": []string{"config/app.csl"}}},
+	})
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(payload)
+	//nolint:noctx // Test request
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/v1/webhooks/github", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("webhook status = %d, want 202", resp.StatusCode)
+	}
+
+	artifact := filepath.Join(publishDir, "app.yaml")
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		content, err := os.ReadFile(artifact)
+		if err == nil {
+			if !strings.Contains(string(content), "port: \"80\"") {
+				t.Errorf("published artifact = %s, want the pushed configuration", content)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("artifact was not published: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// The commit is recorded as the lock is released, just after publishing
+	for {
+		recorded, err := os.ReadFile(filepath.Join(publishDir, ".app.published"))
+		if err == nil && strings.TrimSpace(string(recorded)) == commit {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("published commit = %q (%v), want %s", recorded, err, commit)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := os.Stat(filepath.Join(publishDir, ".app.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock was not released (stat error = %v)", err)
+	}
+}
@@ -0,0 +1,100 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReportOwnership verifies that report ownership groups keys by source
+// file with the file's last git change, in JSON and HTML.
+func TestReportOwnership(t *testing.T) {
+	binPath := buildCLI(t)
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"network.csl": "network:\n  cidr: '10.0.0.0/16'\ndns:\n  zone: 'example.com'\n",
+		"data.csl":    "database:\n  host: 'db'\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "network.csl"},
+		{"-c", "user.name=Network Team", "-c", "user.email=net@example.com", "commit", "-q", "-m", "network"},
+	} {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command("git", args...)
+		cmd.Dir = projectDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "report", "ownership", "-p", ".")
+	cmd.Dir = projectDir
+	stdout, stderr, exitCode := runCommand(t, cmd)
+	if exitCode != 0 {
+		t.Fatalf("report ownership failed with exit code %d: %s", exitCode, stderr)
+	}
+
+	var report struct {
+		TotalKeys int `json:"total_keys"`
+		Sources   []struct {
+			Source     string   `json:"source"`
+			KeyCount   int      `json:"key_count"`
+			Keys       []string `json:"keys"`
+			LastChange *struct {
+				Commit string `json:"commit"`
+				Author string `json:"author"`
+			} `json:"last_change"`
+		} `json:"sources"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, stdout)
+	}
+	if report.TotalKeys != 3 || len(report.Sources) != 2 {
+		t.Fatalf("report = %+v, want 3 keys from 2 sources", report)
+	}
+	for _, source := range report.Sources {
+		switch filepath.Base(source.Source) {
+		case "network.csl":
+			if source.KeyCount != 2 || source.LastChange == nil || source.LastChange.Author != "Network Team" || source.LastChange.Commit == "" {
+				t.Errorf("network.csl = %+v, want 2 keys committed by Network Team", source)
+			}
+		case "data.csl":
+			if source.KeyCount != 1 || source.LastChange != nil {
+				t.Errorf("data.csl = %+v, want 1 key and no history for an untracked file", source)
+			}
+		default:
+			t.Errorf("unexpected source %q", source.Source)
+		}
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd = exec.Command(binPath, "report", "ownership", "-p", ".", "--format", "html", "--no-git", "-o", "ownership.html")
+	cmd.Dir = projectDir
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+		t.Fatalf("report ownership --format html failed with exit code %d: %s", exitCode, stderr)
+	}
+	html, err := os.ReadFile(filepath.Join(projectDir, "ownership.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<h1>Key ownership</h1>") || strings.Contains(string(html), "Network Team") {
+		t.Errorf("HTML report without git history is wrong:\n%s", html)
+	}
+}
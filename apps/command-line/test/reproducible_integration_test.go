@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_ReproducibleMetadata verifies that builds with fixed metadata
+// timestamps are byte-identical.
+func TestBuild_ReproducibleMetadata(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte("app:\n  name: 'demo'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func(env []string, args ...string) string {
+		t.Helper()
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl", "--include-metadata"}, args...)...)
+		cmd.Dir = projectDir
+		cmd.Env = append(os.Environ(), env...)
+		stdout, stderr, exitCode := runCommand(t, cmd)
+		if exitCode != 0 {
+			t.Fatalf("build %v failed with exit code %d: %s", args, exitCode, stderr)
+		}
+		return stdout
+	}
+
+	first := build([]string{"SOURCE_DATE_EPOCH="}, "--reproducible")
+	if second := build([]string{"SOURCE_DATE_EPOCH="}, "--reproducible"); second != first {
+		t.Errorf("--reproducible builds differ:\n%s\n%s", first, second)
+	}
+	if !strings.Contains(first, `"start_time": "1970-01-01T00:00:00Z"`) {
+		t.Errorf("--reproducible output lacks epoch start_time:\n%s", first)
+	}
+
+	epoch := build([]string{"SOURCE_DATE_EPOCH=1700000000"})
+	if !strings.Contains(epoch, `"start_time": "2023-11-14T22:13:20Z"`) || !strings.Contains(epoch, `"end_time": "2023-11-14T22:13:20Z"`) {
+		t.Errorf("SOURCE_DATE_EPOCH output lacks fixed timestamps:\n%s", epoch)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "build", "-p", "config.csl")
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), "SOURCE_DATE_EPOCH=soon")
+	if _, stderr, exitCode := runCommand(t, cmd); exitCode == 0 || !strings.Contains(stderr, "SOURCE_DATE_EPOCH") {
+		t.Errorf("exit code = %d, stderr = %q; want invalid SOURCE_DATE_EPOCH error", exitCode, stderr)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestValidateSARIF verifies that validate --sarif writes a SARIF log with
+// repository-relative locations even when validation fails.
+func TestValidateSARIF(t *testing.T) {
+	binPath := buildCLI(t)
+
+	workspace := t.TempDir()
+	configDir := filepath.Join(workspace, "config")
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "app.csl"), []byte("app: 'demo'\nbad: 'unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(workspace, "nomos.sarif")
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "validate", "-p", "config/app.csl", "--sarif", reportPath)
+	cmd.Dir = workspace
+	cmd.Env = append(os.Environ(), "GITHUB_WORKSPACE="+workspace)
+	_, stderr, exitCode := runCommand(t, cmd)
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1: %s", exitCode, stderr)
+	}
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("SARIF report not written: %v", err)
+	}
+	var log map[string]any
+	if err := json.Unmarshal(content, &log); err != nil {
+		t.Fatalf("SARIF report is not JSON: %v\n%s", err, content)
+	}
+	if log["version"] != "2.1.0" {
+		t.Errorf("SARIF version = %v, want 2.1.0", log["version"])
+	}
+	for _, want := range []string{`"ruleId": "error"`, `"uri": "config/app.csl"`, `"startLine": 2`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("SARIF report lacks %s:\n%s", want, content)
+		}
+	}
+}
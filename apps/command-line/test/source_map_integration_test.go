@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuild_SourceMapFlag tests that --source-map writes a JSON source map
+// mapping output keys back to their .csl definitions.
+func TestBuild_SourceMapFlag(t *testing.T) {
+	binPath := buildCLI(t)
+
+	tmpDir := t.TempDir()
+	fixturePath := filepath.Join(tmpDir, "test.csl")
+	fixtureContent := "app:\n  name: 'test-app'\n  port: 8080\n"
+	//nolint:gosec // G306: Test file with non-sensitive content
+	if err := os.WriteFile(fixturePath, []byte(fixtureContent), 0644); err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+
+	mapPath := filepath.Join(tmpDir, "maps", "out.map.json")
+
+	//nolint:gosec,noctx // G204: Test with controlled input
+	cmd := exec.Command(binPath, "build", "-p", fixturePath, "--source-map", mapPath, "-o", filepath.Join(tmpDir, "out.json"))
+	_, stderr, exitCode := runCommand(t, cmd)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	//nolint:gosec // G304: Test reading file from temp directory
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		t.Fatalf("failed to read source map: %v", err)
+	}
+
+	var sm struct {
+		Version int `json:"version"`
+		Entries map[string]struct {
+			Location struct {
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"location"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &sm); err != nil {
+		t.Fatalf("failed to parse source map: %v\n%s", err, data)
+	}
+
+	if sm.Version != 1 {
+		t.Errorf("expected version 1, got %d", sm.Version)
+	}
+	port, ok := sm.Entries["app.port"]
+	if !ok {
+		t.Fatalf("source map missing app.port entry: %s", data)
+	}
+	if port.Location.Line != 3 {
+		t.Errorf("expected app.port on line 3, got %d", port.Location.Line)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_SplitDepth verifies that --split-depth writes one file per
+// top-level key plus an index manifest.
+func TestBuild_SplitDepth(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := `app:
+  # Listen port
+  port: '8080'
+database:
+  host: 'db.internal'
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "config.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, int) {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl"}, args...)...)
+		cmd.Dir = projectDir
+		_, stderr, exitCode := runCommand(t, cmd)
+		return stderr, exitCode
+	}
+
+	if stderr, exitCode := run("-f", "yaml", "--comments", "--split-depth", "1", "-o", "out"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+
+	app, err := os.ReadFile(filepath.Join(projectDir, "out", "app.yaml"))
+	if err != nil || string(app) != "# Listen port\nport: \"8080\"\n" {
+		t.Errorf("app.yaml = %q, %v", app, err)
+	}
+	database, err := os.ReadFile(filepath.Join(projectDir, "out", "database.yaml"))
+	if err != nil || !strings.Contains(string(database), "host: db.internal") {
+		t.Errorf("database.yaml = %q, %v", database, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, "out", "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index struct {
+		Documents []struct {
+			Key  string `json:"key"`
+			Path string `json:"path"`
+		} `json:"documents"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index.json: %v\n%s", err, data)
+	}
+	if len(index.Documents) != 2 || index.Documents[0].Path != "app.yaml" || index.Documents[1].Key != "database" {
+		t.Errorf("index.json =\n%s", data)
+	}
+
+	if stderr, exitCode := run("--split-depth", "1"); exitCode == 0 || !strings.Contains(stderr, "needs --out") {
+		t.Errorf("--split-depth without --out: exit %d, stderr %s", exitCode, stderr)
+	}
+	if stderr, exitCode := run("--split-depth", "2", "-o", "deep"); exitCode == 0 || !strings.Contains(stderr, "not a map") {
+		t.Errorf("--split-depth 2: exit %d, stderr %s", exitCode, stderr)
+	}
+}
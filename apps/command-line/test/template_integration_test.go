@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_TemplateFormat verifies rendering through --format template.
+func TestBuild_TemplateFormat(t *testing.T) {
+	binPath := buildCLI(t)
+
+	projectDir := t.TempDir()
+	source := `server:
+  port: '8080'
+  name: 'edge'
+upstreams:
+  api:
+    host: 'api.internal'
+  web:
+    host: 'web.internal'
+`
+	tmpl := `server {
+  listen {{ .server.port }};
+  server_name {{ quote (get "server.name") }};
+}
+{{ range $name, $u := .upstreams }}upstream {{ $name }} {
+{{ indent 2 (printf "server %s;" $u.host) }}
+}
+{{ end }}`
+	for name, content := range map[string]string{"config.csl": source, "nginx.tmpl": tmpl} {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) (string, string, int) {
+		//nolint:gosec,noctx // G204: Test command with controlled input
+		cmd := exec.Command(binPath, append([]string{"build", "-p", "config.csl"}, args...)...)
+		cmd.Dir = projectDir
+		return runCommand(t, cmd)
+	}
+
+	if _, stderr, exitCode := run("-f", "template", "--template", "nginx.tmpl", "-o", "nginx.conf"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d: %s", exitCode, stderr)
+	}
+	got, err := os.ReadFile(filepath.Join(projectDir, "nginx.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "server {\n  listen 8080;\n  server_name \"edge\";\n}\nupstream api {\n  server api.internal;\n}\nupstream web {\n  server web.internal;\n}\n"
+	if string(got) != want {
+		t.Errorf("nginx.conf =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, stderr, exitCode := run("-f", "template"); exitCode == 0 || !strings.Contains(stderr, "needs --template") {
+		t.Errorf("template without --template: exit %d, stderr %s", exitCode, stderr)
+	}
+	if _, stderr, exitCode := run("--template", "nginx.tmpl"); exitCode == 0 || !strings.Contains(stderr, "--template applies to template output") {
+		t.Errorf("json with --template: exit %d, stderr %s", exitCode, stderr)
+	}
+}
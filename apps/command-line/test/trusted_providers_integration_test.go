@@ -0,0 +1,41 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuild_TrustedProviders verifies that trusted_providers in the project
+// config fails builds declaring other providers, before any download.
+func TestBuild_TrustedProviders(t *testing.T) {
+	binPath := buildCLI(t)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".nomos"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".nomos", "config.yaml"), []byte("trusted_providers:\n  - source: autonomous-bits/*\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source := "source:\n  alias: 'shell'\n  type: 'acme/nomos-provider-shell'\n  version: '1.0.0'\n\napp:\n  name: 'demo'\n"
+	if err := os.WriteFile(filepath.Join(dir, "app.csl"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:gosec,noctx // G204: Test command with controlled input
+	cmd := exec.Command(binPath, "build", "-p", "app.csl")
+	cmd.Dir = dir
+	_, stderr, exitCode := runCommand(t, cmd)
+	if exitCode == 0 || !strings.Contains(stderr, `provider not trusted: provider "shell" (type "acme/nomos-provider-shell")`) {
+		t.Errorf("exit code = %d, stderr = %s; want an untrusted provider error", exitCode, stderr)
+	}
+	if strings.Contains(stderr, "Downloading") {
+		t.Errorf("untrusted provider was downloaded: %s", stderr)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestBuild_TypeCoercion tests that JSON and YAML agree on value types for
+// each --type-coercion policy.
+func TestBuild_TypeCoercion(t *testing.T) {
+	binPath := buildCLI(t)
+
+	tmpDir := t.TempDir()
+	fixturePath := filepath.Join(tmpDir, "test.csl")
+	//nolint:gosec // G306: Test file with non-sensitive content
+	if err := os.WriteFile(fixturePath, []byte("app:\n  port: 8080\n  debug: true\n"), 0644); err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+
+	tests := []struct {
+		coercion  string
+		wantPort  any
+		wantDebug any
+	}{
+		{"off", "8080", "true"},
+		{"strict", 8080, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coercion, func(t *testing.T) {
+			decoded := map[string]map[string]any{}
+			for _, format := range []string{"json", "yaml"} {
+				outPath := filepath.Join(tmpDir, tt.coercion+"."+format)
+				//nolint:gosec,noctx // G204: Test with controlled input
+				cmd := exec.Command(binPath, "build", "-p", fixturePath, "-f", format, "--type-coercion", tt.coercion, "-o", outPath)
+				if _, stderr, exitCode := runCommand(t, cmd); exitCode != 0 {
+					t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+				}
+
+				//nolint:gosec // G304: Test reading file from temp directory
+				data, err := os.ReadFile(outPath)
+				if err != nil {
+					t.Fatalf("failed to read output: %v", err)
+				}
+				var out map[string]map[string]any
+				if format == "json" {
+					err = json.Unmarshal(data, &out)
+				} else {
+					err = yaml.Unmarshal(data, &out)
+				}
+				if err != nil {
+					t.Fatalf("failed to parse %s output: %v\n%s", format, err, data)
+				}
+				decoded[format] = out["app"]
+			}
+
+			for format, app := range decoded {
+				port := app["port"]
+				if f, ok := port.(float64); ok {
+					port = int(f)
+				}
+				if port != tt.wantPort {
+					t.Errorf("%s: port = %#v, want %#v", format, app["port"], tt.wantPort)
+				}
+				if app["debug"] != tt.wantDebug {
+					t.Errorf("%s: debug = %#v, want %#v", format, app["debug"], tt.wantDebug)
+				}
+			}
+		})
+	}
+}
+
+// TestBuild_TypeCoercionInvalid tests that unknown policies are rejected.
+func TestBuild_TypeCoercionInvalid(t *testing.T) {
+	binPath := buildCLI(t)
+
+	fixturePath := filepath.Join(t.TempDir(), "test.csl")
+	//nolint:gosec // G306: Test file with non-sensitive content
+	if err := os.WriteFile(fixturePath, []byte("a: 1\n"), 0644); err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+
+	//nolint:gosec,noctx // G204: Test with controlled input
+	cmd := exec.Command(binPath, "build", "-p", fixturePath, "--type-coercion", "loose")
+	if _, _, exitCode := runCommand(t, cmd); exitCode == 0 {
+		t.Error("expected non-zero exit code for invalid --type-coercion")
+	}
+}
@@ -0,0 +1,51 @@
+// Package main provides the nomos-provider-consul entry point.
+//
+// The compiler starts the binary, reads the PROVIDER_PORT=<port> line it
+// prints on stdout, and talks to it over gRPC on that loopback port.
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/autonomous-bits/nomos/apps/provider-consul/internal/provider"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	p := provider.New(version)
+	server := grpc.NewServer()
+	providerv1.RegisterProviderServiceServer(server, p)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-p.Done():
+		case <-signals:
+		}
+		server.GracefulStop()
+	}()
+
+	fmt.Printf("PROVIDER_PORT=%d\n", listener.Addr().(*net.TCPAddr).Port)
+	return server.Serve(listener)
+}
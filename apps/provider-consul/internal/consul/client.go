@@ -0,0 +1,174 @@
+// Package consul is a minimal client for the Consul KV HTTP API.
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is the agent address used when neither the configuration
+// nor CONSUL_HTTP_ADDR sets one.
+const DefaultAddress = "http://127.0.0.1:8500"
+
+// Error is an unexpected response from the Consul API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("consul returned HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Config configures a Client. An empty Address selects DefaultAddress; a
+// Token takes precedence over a TokenFile.
+type Config struct {
+	Address    string
+	Token      string
+	TokenFile  string
+	Datacenter string
+	Namespace  string
+	CACert     string
+}
+
+// ConfigFromEnv returns the configuration in the environment variables
+// read by the consul CLI: CONSUL_HTTP_ADDR, CONSUL_HTTP_SSL,
+// CONSUL_HTTP_TOKEN, CONSUL_HTTP_TOKEN_FILE, CONSUL_NAMESPACE and
+// CONSUL_CACERT.
+func ConfigFromEnv() Config {
+	address := os.Getenv("CONSUL_HTTP_ADDR")
+	if address != "" && !strings.Contains(address, "://") {
+		scheme := "http://"
+		if os.Getenv("CONSUL_HTTP_SSL") == "true" {
+			scheme = "https://"
+		}
+		address = scheme + address
+	}
+	return Config{
+		Address:   address,
+		Token:     os.Getenv("CONSUL_HTTP_TOKEN"),
+		TokenFile: os.Getenv("CONSUL_HTTP_TOKEN_FILE"),
+		Namespace: os.Getenv("CONSUL_NAMESPACE"),
+		CACert:    os.Getenv("CONSUL_CACERT"),
+	}
+}
+
+// Client reads keys from the Consul KV store.
+type Client struct {
+	address    string
+	token      string
+	datacenter string
+	namespace  string
+	http       *http.Client
+}
+
+// NewClient returns a client for cfg.
+func NewClient(cfg Config) (*Client, error) {
+	address := cfg.Address
+	if address == "" {
+		address = DefaultAddress
+	}
+	if _, err := url.Parse(address); err != nil {
+		return nil, fmt.Errorf("invalid consul address %q: %w", address, err)
+	}
+
+	token := cfg.Token
+	if token == "" && cfg.TokenFile != "" {
+		data, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read consul token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read consul CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	}
+
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		datacenter: cfg.Datacenter,
+		namespace:  cfg.Namespace,
+		http:       &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}, nil
+}
+
+// List returns the values of every key under prefix, by key. Folder
+// entries (keys ending in "/") and keys without a value are omitted. An
+// empty prefix lists the whole store.
+func (c *Client) List(ctx context.Context, prefix string) (map[string]string, error) {
+	query := url.Values{"recurse": {"true"}}
+	if c.datacenter != "" {
+		query.Set("dc", c.datacenter)
+	}
+	if c.namespace != "" {
+		query.Set("ns", c.namespace)
+	}
+	endpoint := c.address + "/v1/kv/" + (&url.URL{Path: prefix}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("consul request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Consul answers 404 for a prefix without keys
+		return map[string]string{}, nil
+	default:
+		return nil, &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+
+	var entries []struct {
+		Key   string  `json:"Key"`
+		Value *string `json:"Value"`
+	}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Value == nil || strings.HasSuffix(entry.Key, "/") {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(*entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value of key %q: %w", entry.Key, err)
+		}
+		values[entry.Key] = string(value)
+	}
+	return values, nil
+}
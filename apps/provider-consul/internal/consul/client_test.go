@@ -0,0 +1,75 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClient_List(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("Permission denied"))
+			return
+		}
+		if r.URL.Query().Get("recurse") != "true" || r.URL.Query().Get("dc") != "dc2" {
+			t.Errorf("query = %v", r.URL.Query())
+		}
+		switch r.URL.Path {
+		case "/v1/kv/app/":
+			_, _ = w.Write([]byte(`[
+				{"Key":"app/","Value":null},
+				{"Key":"app/db/host","Value":"ZGIuaW50ZXJuYWw="},
+				{"Key":"app/db/port","Value":"NTQzMg=="},
+				{"Key":"app/empty","Value":null}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(Config{Address: server.URL, TokenFile: tokenFile, Datacenter: "dc2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.List(context.Background(), "app/")
+	want := map[string]string{"app/db/host": "db.internal", "app/db/port": "5432"}
+	if err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("List(app/) = %v, %v; want %v", values, err, want)
+	}
+
+	values, err = client.List(context.Background(), "missing/")
+	if err != nil || len(values) != 0 {
+		t.Errorf("List(missing/) = %v, %v; want empty", values, err)
+	}
+
+	client.token = "wrong"
+	var apiErr *Error
+	if _, err := client.List(context.Background(), "app/"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("List() with a bad token error = %v, want HTTP 403", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CONSUL_HTTP_ADDR", "consul.example.com:8501")
+	t.Setenv("CONSUL_HTTP_SSL", "true")
+	t.Setenv("CONSUL_HTTP_TOKEN", "token")
+	t.Setenv("CONSUL_NAMESPACE", "team")
+
+	cfg := ConfigFromEnv()
+	if cfg.Address != "https://consul.example.com:8501" || cfg.Token != "token" || cfg.Namespace != "team" {
+		t.Errorf("ConfigFromEnv() = %+v", cfg)
+	}
+}
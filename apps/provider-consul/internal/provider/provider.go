@@ -0,0 +1,190 @@
+// Package provider implements the Nomos provider gRPC service over the
+// Consul KV store.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/apps/provider-consul/internal/consul"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Type is the provider type reported by Info.
+const Type = "consul"
+
+// Provider serves the keys under one Consul KV prefix as a nested map: each
+// "/"-separated key segment below the prefix becomes a map level.
+type Provider struct {
+	providerv1.UnimplementedProviderServiceServer
+
+	version  string
+	shutdown chan struct{}
+	once     sync.Once
+
+	mu    sync.RWMutex
+	alias string
+	data  map[string]any
+}
+
+// New returns an uninitialized provider that reports version from Info.
+func New(version string) *Provider {
+	return &Provider{version: version, shutdown: make(chan struct{})}
+}
+
+// Init implements providerv1.ProviderServiceServer. It reads every key under
+// the configured prefix once; Fetch serves them from memory.
+//
+// Config keys (all strings, all optional):
+//   - prefix: the KV prefix to read; default the whole store
+//   - address: the agent URL; default CONSUL_HTTP_ADDR or consul.DefaultAddress
+//   - datacenter: the datacenter to read; default the agent's
+//   - namespace: the Enterprise namespace; default CONSUL_NAMESPACE
+//   - token, token_file: the ACL token; default CONSUL_HTTP_TOKEN(_FILE)
+//   - ca_cert: a PEM CA bundle for HTTPS; default CONSUL_CACERT
+func (p *Provider) Init(ctx context.Context, req *providerv1.InitRequest) (*providerv1.InitResponse, error) {
+	cfg := consul.ConfigFromEnv()
+	var prefix string
+	for key, value := range req.GetConfig().AsMap() {
+		s, ok := value.(string)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "config %q must be a string", key)
+		}
+		switch key {
+		case "prefix":
+			prefix = s
+		case "address":
+			cfg.Address = s
+		case "datacenter":
+			cfg.Datacenter = s
+		case "namespace":
+			cfg.Namespace = s
+		case "token":
+			cfg.Token = s
+		case "token_file":
+			cfg.Token, cfg.TokenFile = "", s
+		case "ca_cert":
+			cfg.CACert = s
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown config %q", key)
+		}
+	}
+
+	client, err := consul.NewClient(cfg)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		// Consul prefixes are plain string prefixes; "app" would also match "apple"
+		prefix += "/"
+	}
+	values, err := client.List(ctx, prefix)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "reading prefix %q: %v", prefix, err)
+	}
+	data, err := nest(values, prefix)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alias = req.GetAlias()
+	p.data = data
+	return &providerv1.InitResponse{}, nil
+}
+
+// nest turns the keys under prefix into nested maps.
+func nest(values map[string]string, prefix string) (map[string]any, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := make(map[string]any)
+	for _, key := range keys {
+		segments := strings.Split(strings.TrimPrefix(key, prefix), "/")
+		node := root
+		for i, segment := range segments[:len(segments)-1] {
+			switch child := node[segment].(type) {
+			case nil:
+				next := make(map[string]any)
+				node[segment] = next
+				node = next
+			case map[string]any:
+				node = child
+			default:
+				return nil, fmt.Errorf("key %q is both a value and a folder", prefix+strings.Join(segments[:i+1], "/"))
+			}
+		}
+		leaf := segments[len(segments)-1]
+		if _, ok := node[leaf].(map[string]any); ok {
+			return nil, fmt.Errorf("key %q is both a value and a folder", key)
+		}
+		node[leaf] = values[key]
+	}
+	return root, nil
+}
+
+// Fetch implements providerv1.ProviderServiceServer. The path selects a
+// folder, returned as a map, or a key, returned as {"value": ...}.
+func (p *Provider) Fetch(_ context.Context, req *providerv1.FetchRequest) (*providerv1.FetchResponse, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.data == nil {
+		return nil, status.Error(codes.FailedPrecondition, "provider not initialized")
+	}
+
+	var current any = p.data
+	for i, segment := range req.GetPath() {
+		folder, ok := current.(map[string]any)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "key %q has no children", strings.Join(req.GetPath()[:i], "/"))
+		}
+		if current, ok = folder[segment]; !ok {
+			return nil, status.Errorf(codes.NotFound, "key %q not found", strings.Join(req.GetPath()[:i+1], "/"))
+		}
+	}
+
+	folder, ok := current.(map[string]any)
+	if !ok {
+		folder = map[string]any{"value": current}
+	}
+	value, err := structpb.NewStruct(folder)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &providerv1.FetchResponse{Value: value}, nil
+}
+
+// Info implements providerv1.ProviderServiceServer.
+func (p *Provider) Info(context.Context, *providerv1.InfoRequest) (*providerv1.InfoResponse, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return &providerv1.InfoResponse{Alias: p.alias, Version: p.version, Type: Type}, nil
+}
+
+// Health implements providerv1.ProviderServiceServer.
+func (p *Provider) Health(context.Context, *providerv1.HealthRequest) (*providerv1.HealthResponse, error) {
+	return &providerv1.HealthResponse{Status: providerv1.HealthResponse_STATUS_OK}, nil
+}
+
+// Shutdown implements providerv1.ProviderServiceServer. It closes Done so
+// the server can stop once the response is sent.
+func (p *Provider) Shutdown(context.Context, *providerv1.ShutdownRequest) (*providerv1.ShutdownResponse, error) {
+	p.once.Do(func() { close(p.shutdown) })
+	return &providerv1.ShutdownResponse{}, nil
+}
+
+// Done is closed when the compiler requests a shutdown.
+func (p *Provider) Done() <-chan struct{} {
+	return p.shutdown
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeConsul serves keys from a KV store with the given contents.
+func fakeConsul(t *testing.T, kv map[string]string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		var entries []map[string]any
+		for key, value := range kv {
+			if strings.HasPrefix(key, prefix) {
+				entries = append(entries, map[string]any{"Key": key, "Value": base64.StdEncoding.EncodeToString([]byte(value))})
+			}
+		}
+		if len(entries) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func initProvider(t *testing.T, config map[string]any) (*Provider, error) {
+	t.Helper()
+	t.Setenv("CONSUL_HTTP_ADDR", "")
+	t.Setenv("CONSUL_HTTP_TOKEN", "")
+	cfg, err := structpb.NewStruct(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New("1.2.3")
+	_, err = p.Init(context.Background(), &providerv1.InitRequest{Alias: "kv", Config: cfg})
+	return p, err
+}
+
+func TestProvider_Fetch(t *testing.T) {
+	address := fakeConsul(t, map[string]string{
+		"app/db/host":     "db.internal",
+		"app/db/port":     "5432",
+		"app/log_level":   "debug",
+		"apple/unrelated": "x",
+	})
+	p, err := initProvider(t, map[string]any{"address": address, "prefix": "app"})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		want map[string]any
+		code codes.Code
+	}{
+		{name: "root", want: map[string]any{
+			"db":        map[string]any{"host": "db.internal", "port": "5432"},
+			"log_level": "debug",
+		}},
+		{name: "folder", path: []string{"db"}, want: map[string]any{"host": "db.internal", "port": "5432"}},
+		{name: "key", path: []string{"db", "host"}, want: map[string]any{"value": "db.internal"}},
+		{name: "missing key", path: []string{"db", "user"}, code: codes.NotFound},
+		{name: "below a key", path: []string{"log_level", "x"}, code: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Fetch(context.Background(), &providerv1.FetchRequest{Path: tt.path})
+			if tt.code != codes.OK {
+				if status.Code(err) != tt.code {
+					t.Fatalf("Fetch() error = %v, want code %v", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := resp.GetValue().AsMap(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	info, _ := p.Info(context.Background(), &providerv1.InfoRequest{})
+	if info.GetAlias() != "kv" || info.GetType() != Type || info.GetVersion() != "1.2.3" {
+		t.Errorf("Info() = %v", info)
+	}
+}
+
+func TestProvider_Init(t *testing.T) {
+	conflict := fakeConsul(t, map[string]string{"app/db": "x", "app/db/host": "y"})
+
+	tests := []struct {
+		name    string
+		config  map[string]any
+		code    codes.Code
+		wantErr string
+	}{
+		{name: "unknown key", config: map[string]any{"directory": "x"}, code: codes.InvalidArgument, wantErr: `unknown config "directory"`},
+		{name: "non-string value", config: map[string]any{"prefix": 1.0}, code: codes.InvalidArgument, wantErr: "must be a string"},
+		{name: "value and folder", config: map[string]any{"address": conflict, "prefix": "app"}, code: codes.FailedPrecondition, wantErr: `key "app/db" is both a value and a folder`},
+		{name: "unreachable", config: map[string]any{"address": "http://127.0.0.1:1"}, code: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := initProvider(t, tt.config)
+			if status.Code(err) != tt.code || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want code %v containing %q", err, tt.code, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvider_Shutdown(t *testing.T) {
+	p := New("dev")
+	if _, err := p.Fetch(context.Background(), &providerv1.FetchRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Fetch() before Init error = %v, want FailedPrecondition", err)
+	}
+	for range 2 {
+		if _, err := p.Shutdown(context.Background(), &providerv1.ShutdownRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-p.Done():
+	default:
+		t.Error("Done() not closed after Shutdown")
+	}
+}
@@ -0,0 +1,375 @@
+// Package provider implements the Nomos provider gRPC service over
+// read-only SQL queries.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/apps/provider-sql/internal/credentials"
+	"github.com/autonomous-bits/nomos/apps/provider-sql/internal/query"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Type is the provider type reported by Info.
+const Type = "sql"
+
+// Provider serves the results of the queries in its configuration, each
+// under its query name.
+type Provider struct {
+	providerv1.UnimplementedProviderServiceServer
+
+	version  string
+	shutdown chan struct{}
+	once     sync.Once
+
+	mu    sync.RWMutex
+	alias string
+	data  map[string]any
+}
+
+// New returns an uninitialized provider that reports version from Info.
+func New(version string) *Provider {
+	return &Provider{version: version, shutdown: make(chan struct{})}
+}
+
+// config is the parsed InitRequest configuration.
+type config struct {
+	driver          string
+	dsn             string
+	dsnEnv          string
+	profile         string
+	credentialsFile string
+	queries         map[string]querySpec
+}
+
+// querySpec is one entry of the queries map.
+type querySpec struct {
+	statement string
+	key       string
+	single    bool
+}
+
+// parseConfig validates the InitRequest configuration:
+//
+//	queries:                     # required; name -> statement or settings
+//	  flags: 'SELECT name, enabled FROM feature_flags'
+//	  tenants:
+//	    sql: 'SELECT id, plan, region FROM tenants'
+//	    key: 'id'                # optional; rows keyed by this column
+//	  settings:
+//	    sql: 'SELECT * FROM settings WHERE id = 1'
+//	    single: 'true'           # optional; exactly one row, as a map
+//	driver: 'postgres'           # postgres, mysql or sqlite; inferred from URL DSNs
+//	dsn_env: 'APP_DATABASE_URL'  # one of dsn_env, profile or dsn; default NOMOS_SQL_DSN
+//	profile: 'tenants'           # a section of the credentials file
+//	credentials_file: '...'      # default NOMOS_SQL_CREDENTIALS_FILE or the user config dir
+//	dsn: './settings.db'         # literal connection string; avoid for credentials
+func parseConfig(values map[string]any) (*config, error) {
+	cfg := &config{queries: make(map[string]querySpec)}
+	for key, value := range values {
+		if key == "queries" {
+			queries, ok := value.(map[string]any)
+			if !ok {
+				return nil, errors.New("config 'queries' must be a map of query names to statements")
+			}
+			for name, q := range queries {
+				spec, err := parseQuery(q)
+				if err != nil {
+					return nil, fmt.Errorf("query %q: %w", name, err)
+				}
+				cfg.queries[name] = spec
+			}
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("config %q must be a string", key)
+		}
+		switch key {
+		case "driver":
+			cfg.driver = s
+		case "dsn":
+			cfg.dsn = s
+		case "dsn_env":
+			cfg.dsnEnv = s
+		case "profile":
+			cfg.profile = s
+		case "credentials_file":
+			cfg.credentialsFile = s
+		default:
+			return nil, fmt.Errorf("unknown config %q", key)
+		}
+	}
+
+	if len(cfg.queries) == 0 {
+		return nil, errors.New("config 'queries' must define at least one query")
+	}
+	sources := 0
+	for _, s := range []string{cfg.dsn, cfg.dsnEnv, cfg.profile} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, errors.New("config 'dsn', 'dsn_env' and 'profile' are mutually exclusive")
+	}
+	return cfg, nil
+}
+
+// parseQuery parses a statement string or a map with sql, key and single.
+func parseQuery(value any) (querySpec, error) {
+	var spec querySpec
+	switch v := value.(type) {
+	case string:
+		spec.statement = v
+	case map[string]any:
+		for key, setting := range v {
+			s, ok := setting.(string)
+			if !ok {
+				return spec, fmt.Errorf("%q must be a string", key)
+			}
+			switch key {
+			case "sql":
+				spec.statement = s
+			case "key":
+				spec.key = s
+			case "single":
+				b, err := strconv.ParseBool(s)
+				if err != nil {
+					return spec, fmt.Errorf("'single' must be 'true' or 'false' (got %q)", s)
+				}
+				spec.single = b
+			default:
+				return spec, fmt.Errorf("unknown setting %q", key)
+			}
+		}
+	default:
+		return spec, errors.New("must be a statement or a map with 'sql'")
+	}
+	if spec.key != "" && spec.single {
+		return spec, errors.New("'key' and 'single' are mutually exclusive")
+	}
+	if err := query.CheckReadOnly(spec.statement); err != nil {
+		return spec, err
+	}
+	return spec, nil
+}
+
+// connection returns the driver and connection string of cfg.
+func (cfg *config) connection() (driver, dsn string, err error) {
+	driver = cfg.driver
+	switch {
+	case cfg.dsn != "":
+		dsn = cfg.dsn
+	case cfg.profile != "":
+		path := cfg.credentialsFile
+		if path == "" {
+			if path, err = credentials.DefaultFile(); err != nil {
+				return "", "", err
+			}
+		}
+		profile, err := credentials.Load(path, cfg.profile)
+		if err != nil {
+			return "", "", err
+		}
+		dsn = profile.DSN
+		if driver == "" {
+			driver = profile.Driver
+		}
+	default:
+		env := cfg.dsnEnv
+		if env == "" {
+			env = "NOMOS_SQL_DSN"
+		}
+		if dsn = os.Getenv(env); dsn == "" {
+			return "", "", fmt.Errorf("no connection configured: environment variable %s is not set", env)
+		}
+	}
+
+	if driver == "" {
+		driver = query.InferDriver(dsn)
+	}
+	if driver == "" {
+		return "", "", errors.New("config 'driver' is required for this connection string (postgres, mysql or sqlite)")
+	}
+	return driver, dsn, nil
+}
+
+// Init implements providerv1.ProviderServiceServer. It runs every query
+// once, in one read-only transaction; Fetch serves the results from memory.
+func (p *Provider) Init(ctx context.Context, req *providerv1.InitRequest) (*providerv1.InitResponse, error) {
+	cfg, err := parseConfig(req.GetConfig().AsMap())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	driver, dsn, err := cfg.connection()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if driver == query.DriverSQLite {
+		dsn = resolveSQLitePath(dsn, req.GetSourceFilePath())
+	}
+
+	db, err := query.Open(ctx, driver, dsn)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "connecting to %s database: %v", driver, err)
+	}
+	defer func() { _ = db.Close() }()
+	runner, err := query.Begin(ctx, db)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer func() { _ = runner.Close() }()
+
+	names := make([]string, 0, len(cfg.queries))
+	for name := range cfg.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make(map[string]any, len(names))
+	for _, name := range names {
+		spec := cfg.queries[name]
+		rows, err := runner.Rows(ctx, spec.statement)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "query %q: %v", name, err)
+		}
+		if data[name], err = shape(rows, spec); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "query %q: %v", name, err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alias = req.GetAlias()
+	p.data = data
+	return &providerv1.InitResponse{}, nil
+}
+
+// resolveSQLitePath resolves a relative SQLite database path against the
+// directory of the .csl file declaring the source.
+func resolveSQLitePath(dsn, sourceFile string) string {
+	for _, scheme := range []string{"sqlite3://", "sqlite://", "file:"} {
+		dsn = strings.TrimPrefix(dsn, scheme)
+	}
+	path, params, _ := strings.Cut(dsn, "?")
+	if sourceFile != "" && path != ":memory:" && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(sourceFile), path)
+	}
+	if params != "" {
+		return path + "?" + params
+	}
+	return path
+}
+
+// shape returns rows as a list, as a map keyed by spec.key, or as the single
+// row.
+func shape(rows []map[string]any, spec querySpec) (any, error) {
+	switch {
+	case spec.single:
+		if len(rows) != 1 {
+			return nil, fmt.Errorf("single: returned %d rows, want 1", len(rows))
+		}
+		return rows[0], nil
+	case spec.key != "":
+		keyed := make(map[string]any, len(rows))
+		for _, row := range rows {
+			value, ok := row[spec.key]
+			if !ok {
+				return nil, fmt.Errorf("key column %q not in result", spec.key)
+			}
+			if value == nil {
+				return nil, fmt.Errorf("key column %q is NULL", spec.key)
+			}
+			key := fmt.Sprint(value)
+			if _, dup := keyed[key]; dup {
+				return nil, fmt.Errorf("key column %q has duplicate value %q", spec.key, key)
+			}
+			keyed[key] = row
+		}
+		return keyed, nil
+	default:
+		list := make([]any, len(rows))
+		for i, row := range rows {
+			list[i] = row
+		}
+		return list, nil
+	}
+}
+
+// Fetch implements providerv1.ProviderServiceServer. The first path segment
+// names a query; further segments select keys, columns, or list indexes.
+// Maps are returned as-is, other values as {"value": ...}.
+func (p *Provider) Fetch(_ context.Context, req *providerv1.FetchRequest) (*providerv1.FetchResponse, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.data == nil {
+		return nil, status.Error(codes.FailedPrecondition, "provider not initialized")
+	}
+
+	var current any = p.data
+	for i, segment := range req.GetPath() {
+		at := strings.Join(req.GetPath()[:i+1], ".")
+		switch node := current.(type) {
+		case map[string]any:
+			var ok bool
+			if current, ok = node[segment]; !ok {
+				return nil, status.Errorf(codes.NotFound, "%q not found", at)
+			}
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, status.Errorf(codes.NotFound, "%q: no row %s (%d rows)", at, segment, len(node))
+			}
+			current = node[index]
+		default:
+			return nil, status.Errorf(codes.NotFound, "%q not found: %q is a column value", at, strings.Join(req.GetPath()[:i], "."))
+		}
+	}
+
+	object, ok := current.(map[string]any)
+	if !ok {
+		object = map[string]any{"value": current}
+	}
+	value, err := structpb.NewStruct(object)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &providerv1.FetchResponse{Value: value}, nil
+}
+
+// Info implements providerv1.ProviderServiceServer.
+func (p *Provider) Info(context.Context, *providerv1.InfoRequest) (*providerv1.InfoResponse, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return &providerv1.InfoResponse{Alias: p.alias, Version: p.version, Type: Type}, nil
+}
+
+// Health implements providerv1.ProviderServiceServer.
+func (p *Provider) Health(context.Context, *providerv1.HealthRequest) (*providerv1.HealthResponse, error) {
+	return &providerv1.HealthResponse{Status: providerv1.HealthResponse_STATUS_OK}, nil
+}
+
+// Shutdown implements providerv1.ProviderServiceServer. It closes Done so
+// the server can stop once the response is sent.
+func (p *Provider) Shutdown(context.Context, *providerv1.ShutdownRequest) (*providerv1.ShutdownResponse, error) {
+	p.once.Do(func() { close(p.shutdown) })
+	return &providerv1.ShutdownResponse{}, nil
+}
+
+// Done is closed when the compiler requests a shutdown.
+func (p *Provider) Done() <-chan struct{} {
+	return p.shutdown
+}
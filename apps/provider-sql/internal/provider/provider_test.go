@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// createDatabase writes an SQLite database of feature flags and tenants to
+// dir/app.db.
+func createDatabase(t *testing.T, dir string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	for _, statement := range []string{
+		"CREATE TABLE flags (name TEXT, enabled BOOLEAN)",
+		"INSERT INTO flags VALUES ('beta', 1), ('dark-mode', 0)",
+		"CREATE TABLE tenants (id TEXT, plan TEXT, seats INTEGER)",
+		"INSERT INTO tenants VALUES ('acme', 'enterprise', 250), ('globex', 'team', 10)",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func initProvider(t *testing.T, sourceFile string, config map[string]any) (*Provider, error) {
+	t.Helper()
+	cfg, err := structpb.NewStruct(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New("1.2.3")
+	_, err = p.Init(context.Background(), &providerv1.InitRequest{Alias: "db", Config: cfg, SourceFilePath: sourceFile})
+	return p, err
+}
+
+func TestProvider_Fetch(t *testing.T) {
+	dir := t.TempDir()
+	createDatabase(t, dir)
+
+	// The relative database path resolves against the .csl file
+	p, err := initProvider(t, filepath.Join(dir, "app.csl"), map[string]any{
+		"driver": "sqlite",
+		"dsn":    "app.db",
+		"queries": map[string]any{
+			"flags":   "SELECT name, enabled FROM flags ORDER BY name",
+			"tenants": map[string]any{"sql": "SELECT id, plan, seats FROM tenants", "key": "id"},
+			"acme":    map[string]any{"sql": "SELECT plan, seats FROM tenants WHERE id = 'acme'", "single": "true"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		want map[string]any
+		code codes.Code
+	}{
+		{name: "rows", path: []string{"flags"}, want: map[string]any{"value": []any{
+			map[string]any{"name": "beta", "enabled": true},
+			map[string]any{"name": "dark-mode", "enabled": false},
+		}}},
+		{name: "row by index", path: []string{"flags", "1", "name"}, want: map[string]any{"value": "dark-mode"}},
+		{name: "keyed rows", path: []string{"tenants", "globex"}, want: map[string]any{"id": "globex", "plan": "team", "seats": 10.0}},
+		{name: "single row", path: []string{"acme"}, want: map[string]any{"plan": "enterprise", "seats": 250.0}},
+		{name: "column", path: []string{"acme", "seats"}, want: map[string]any{"value": 250.0}},
+		{name: "missing query", path: []string{"users"}, code: codes.NotFound},
+		{name: "index out of range", path: []string{"flags", "5"}, code: codes.NotFound},
+		{name: "below a column", path: []string{"acme", "plan", "x"}, code: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Fetch(context.Background(), &providerv1.FetchRequest{Path: tt.path})
+			if tt.code != codes.OK {
+				if status.Code(err) != tt.code {
+					t.Fatalf("Fetch() error = %v, want code %v", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := resp.GetValue().AsMap(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_Connection(t *testing.T) {
+	dir := t.TempDir()
+	createDatabase(t, dir)
+	database := filepath.Join(dir, "app.db")
+	credentials := filepath.Join(dir, "sql_credentials")
+	if err := os.WriteFile(credentials, []byte("[app]\ndriver = sqlite\ndsn = "+database+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	queries := map[string]any{"flags": "SELECT name FROM flags"}
+
+	t.Run("default environment variable", func(t *testing.T) {
+		t.Setenv("NOMOS_SQL_DSN", "file:"+database)
+		if _, err := initProvider(t, "", map[string]any{"queries": queries}); err != nil {
+			t.Errorf("Init() error = %v", err)
+		}
+	})
+	t.Run("dsn_env", func(t *testing.T) {
+		t.Setenv("APP_DATABASE_URL", "sqlite://"+database)
+		if _, err := initProvider(t, "", map[string]any{"dsn_env": "APP_DATABASE_URL", "queries": queries}); err != nil {
+			t.Errorf("Init() error = %v", err)
+		}
+	})
+	t.Run("profile", func(t *testing.T) {
+		if _, err := initProvider(t, "", map[string]any{"profile": "app", "credentials_file": credentials, "queries": queries}); err != nil {
+			t.Errorf("Init() error = %v", err)
+		}
+	})
+	t.Run("profile from NOMOS_SQL_CREDENTIALS_FILE", func(t *testing.T) {
+		t.Setenv("NOMOS_SQL_CREDENTIALS_FILE", credentials)
+		if _, err := initProvider(t, "", map[string]any{"profile": "app", "queries": queries}); err != nil {
+			t.Errorf("Init() error = %v", err)
+		}
+	})
+}
+
+func TestProvider_InitErrors(t *testing.T) {
+	dir := t.TempDir()
+	createDatabase(t, dir)
+	database := filepath.Join(dir, "app.db")
+	t.Setenv("NOMOS_SQL_DSN", "")
+
+	tests := []struct {
+		name    string
+		config  map[string]any
+		code    codes.Code
+		wantErr string
+	}{
+		{name: "no queries", config: map[string]any{"dsn": database, "driver": "sqlite"}, code: codes.InvalidArgument, wantErr: "at least one query"},
+		{name: "write statement", config: map[string]any{"dsn": database, "queries": map[string]any{"x": "UPDATE flags SET enabled = 1"}}, code: codes.InvalidArgument, wantErr: "only single SELECT"},
+		{name: "unknown key", config: map[string]any{"table": "flags"}, code: codes.InvalidArgument, wantErr: `unknown config "table"`},
+		{name: "two connections", config: map[string]any{"dsn": database, "profile": "app", "queries": map[string]any{"x": "SELECT 1"}}, code: codes.InvalidArgument, wantErr: "mutually exclusive"},
+		{name: "no connection", config: map[string]any{"queries": map[string]any{"x": "SELECT 1"}}, code: codes.FailedPrecondition, wantErr: "NOMOS_SQL_DSN is not set"},
+		{name: "unknown driver", config: map[string]any{"dsn": database, "queries": map[string]any{"x": "SELECT 1"}}, code: codes.FailedPrecondition, wantErr: "'driver' is required"},
+		{name: "single with many rows", config: map[string]any{"dsn": database, "driver": "sqlite", "queries": map[string]any{"x": map[string]any{"sql": "SELECT * FROM flags", "single": "true"}}}, code: codes.FailedPrecondition, wantErr: "returned 2 rows"},
+		{name: "duplicate key", config: map[string]any{"dsn": database, "driver": "sqlite", "queries": map[string]any{"x": map[string]any{"sql": "SELECT 'a' AS k UNION ALL SELECT 'a'", "key": "k"}}}, code: codes.FailedPrecondition, wantErr: `duplicate value "a"`},
+		{name: "bad statement", config: map[string]any{"dsn": database, "driver": "sqlite", "queries": map[string]any{"x": "SELECT * FROM users"}}, code: codes.InvalidArgument, wantErr: "no such table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := initProvider(t, "", tt.config)
+			if status.Code(err) != tt.code || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want code %v containing %q", err, tt.code, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package query runs read-only SQL queries against Postgres, MySQL and
+// SQLite and converts their rows to Nomos values.
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "github.com/go-sql-driver/mysql" // registers "mysql"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers "pgx"
+	_ "github.com/mattn/go-sqlite3"    // registers "sqlite3"
+)
+
+// Supported drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// ErrNotReadOnly is returned for statements other than a single SELECT.
+var ErrNotReadOnly = errors.New("only single SELECT (or WITH ... SELECT) statements are allowed")
+
+// driverNames maps supported drivers to their database/sql names.
+var driverNames = map[string]string{
+	DriverPostgres: "pgx",
+	DriverMySQL:    "mysql",
+	DriverSQLite:   "sqlite3",
+}
+
+// InferDriver returns the driver of a URL-style dsn, or "".
+func InferDriver(dsn string) string {
+	scheme, _, ok := strings.Cut(dsn, "://")
+	if !ok {
+		if strings.HasPrefix(dsn, "file:") {
+			return DriverSQLite
+		}
+		return ""
+	}
+	switch scheme {
+	case "postgres", "postgresql":
+		return DriverPostgres
+	case "mysql":
+		return DriverMySQL
+	case "sqlite", "sqlite3":
+		return DriverSQLite
+	}
+	return ""
+}
+
+// Open connects to the database. SQLite databases are opened read-only;
+// mysql:// URLs are converted to the driver's DSN format.
+func Open(ctx context.Context, driver, dsn string) (*sql.DB, error) {
+	name, ok := driverNames[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver %q (supported: postgres, mysql, sqlite)", driver)
+	}
+	switch driver {
+	case DriverSQLite:
+		dsn = sqliteReadOnly(dsn)
+	case DriverMySQL:
+		dsn = strings.TrimPrefix(dsn, "mysql://")
+	}
+
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteReadOnly returns a file: URI for dsn that opens the database
+// read-only.
+func sqliteReadOnly(dsn string) string {
+	for _, scheme := range []string{"sqlite3://", "sqlite://"} {
+		dsn = strings.TrimPrefix(dsn, scheme)
+	}
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "mode=ro"
+}
+
+// CheckReadOnly returns ErrNotReadOnly unless statement is a single SELECT
+// or WITH statement. The database also enforces it: queries run in a
+// read-only transaction.
+func CheckReadOnly(statement string) error {
+	statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+	if strings.Contains(statement, ";") {
+		return ErrNotReadOnly
+	}
+	end := strings.IndexFunc(statement, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(statement)
+	}
+	switch strings.ToUpper(statement[:end]) {
+	case "SELECT", "WITH":
+		return nil
+	}
+	return ErrNotReadOnly
+}
+
+// Runner runs queries in one read-only transaction, so every query sees the
+// same snapshot where the database supports it.
+type Runner struct {
+	tx *sql.Tx
+}
+
+// Begin starts a read-only transaction on db.
+func Begin(ctx context.Context, db *sql.DB) (*Runner, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("starting read-only transaction: %w", err)
+	}
+	return &Runner{tx: tx}, nil
+}
+
+// Close ends the transaction without committing anything.
+func (r *Runner) Close() error {
+	return r.tx.Rollback()
+}
+
+// Rows runs statement and returns its rows as column-name maps.
+func (r *Runner) Rows(ctx context.Context, statement string) ([]map[string]any, error) {
+	if err := CheckReadOnly(statement); err != nil {
+		return nil, err
+	}
+	rows, err := r.tx.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	result := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column.Name()] = convert(values[i], column.DatabaseTypeName())
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// convert returns value as a string, number, bool or nil. Drivers that
+// return text for every column (MySQL) are converted by column type.
+func convert(value any, typeName string) any {
+	switch v := value.(type) {
+	case nil, bool, int64, float64, string:
+		return v
+	case []byte:
+		return convertText(string(v), typeName)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// convertText converts a textual column value by its database type.
+func convertText(text, typeName string) any {
+	switch strings.ToUpper(typeName) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR",
+		"UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT", "DOUBLE", "REAL":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	case "BOOL", "BOOLEAN":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	return text
+}
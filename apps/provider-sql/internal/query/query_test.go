@@ -0,0 +1,113 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		statement string
+		wantErr   bool
+	}{
+		{statement: "SELECT 1"},
+		{statement: "  select id from t;  "},
+		{statement: "WITH x AS (SELECT 1) SELECT * FROM x"},
+		{statement: "SELECT\n1"},
+		{statement: "DELETE FROM t", wantErr: true},
+		{statement: "SELECT 1; DROP TABLE t", wantErr: true},
+		{statement: "PRAGMA writable_schema = 1", wantErr: true},
+		{statement: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckReadOnly(tt.statement); (err != nil) != tt.wantErr {
+			t.Errorf("CheckReadOnly(%q) = %v, wantErr %v", tt.statement, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInferDriver(t *testing.T) {
+	tests := map[string]string{
+		"postgres://u:p@db/app":      DriverPostgres,
+		"postgresql://db/app":        DriverPostgres,
+		"mysql://u:p@tcp(db)/app":    DriverMySQL,
+		"file:settings.db":           DriverSQLite,
+		"sqlite://./settings.db":     DriverSQLite,
+		"u:p@tcp(db:3306)/app":       "",
+		"host=db user=reader dbname": "",
+	}
+	for dsn, want := range tests {
+		if got := InferDriver(dsn); got != want {
+			t.Errorf("InferDriver(%q) = %q, want %q", dsn, got, want)
+		}
+	}
+}
+
+func TestConvertText(t *testing.T) {
+	tests := []struct {
+		text, typeName string
+		want           any
+	}{
+		{"42", "BIGINT", int64(42)},
+		{"7", "UNSIGNED INT", int64(7)},
+		{"1.5", "DOUBLE", 1.5},
+		{"12.30", "DECIMAL", "12.30"},
+		{"true", "BOOLEAN", true},
+		{"hello", "VARCHAR", "hello"},
+	}
+	for _, tt := range tests {
+		if got := convertText(tt.text, tt.typeName); got != tt.want {
+			t.Errorf("convertText(%q, %q) = %#v, want %#v", tt.text, tt.typeName, got, tt.want)
+		}
+	}
+}
+
+func TestRunner_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	writable, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE flags (name TEXT, enabled BOOLEAN, rollout REAL, owner TEXT)",
+		"INSERT INTO flags VALUES ('beta', 1, 0.5, NULL), ('dark-mode', 0, 1, 'web')",
+	} {
+		if _, err := writable.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = writable.Close()
+
+	ctx := context.Background()
+	db, err := Open(ctx, DriverSQLite, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	runner, err := Begin(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = runner.Close() }()
+
+	rows, err := runner.Rows(ctx, "SELECT name, enabled, rollout, owner FROM flags ORDER BY name")
+	want := []map[string]any{
+		{"name": "beta", "enabled": true, "rollout": 0.5, "owner": nil},
+		{"name": "dark-mode", "enabled": false, "rollout": 1.0, "owner": "web"},
+	}
+	if err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("Rows() = %v, %v; want %v", rows, err, want)
+	}
+
+	if _, err := runner.Rows(ctx, "DELETE FROM flags"); !errors.Is(err, ErrNotReadOnly) {
+		t.Errorf("Rows(DELETE) error = %v, want ErrNotReadOnly", err)
+	}
+	// The database is opened read-only, whatever the statement check allows
+	if _, err := db.Exec("INSERT INTO flags VALUES ('x', 1, 1, NULL)"); err == nil {
+		t.Error("INSERT on the read-only database succeeded")
+	}
+}
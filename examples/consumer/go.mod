@@ -11,5 +11,5 @@ go 1.26.0
 // require (
 //     github.com/autonomous-bits/nomos/libs/compiler v0.1.0
 //     github.com/autonomous-bits/nomos/libs/parser v0.1.0
-//     github.com/autonomous-bits/nomos/libs/provider-proto v0.1.0
+//     github.com/autonomous-bits/nomos/libs/provider-proto/gen/go v0.1.0
 // )
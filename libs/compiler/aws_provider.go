@@ -0,0 +1,314 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// AWSSSMProviderType is the built-in provider type that reads every SSM
+// Parameter Store parameter under a path:
+//
+//	source:
+//	  alias: 'params'
+//	  type: 'aws-ssm'
+//	  path: '/myapp/prod'  # required; parameters are fetched recursively
+//	  recursive: 'true'    # optional; 'false' reads only direct children
+//	  decrypt: 'true'      # optional; 'false' returns SecureString ciphertext
+//	  sensitive: 'auto'    # optional; auto (SecureString only), all, or none
+//	  region: 'us-east-1'  # optional; plus profile and endpoint
+//
+// Parameter names below the path become nested keys, so /myapp/prod/db/host
+// is referenced as @params:db.host. StringList parameters become lists.
+const AWSSSMProviderType = "aws-ssm"
+
+// AWSSecretsManagerProviderType is the built-in provider type that reads
+// every Secrets Manager secret whose name starts with a prefix:
+//
+//	source:
+//	  alias: 'secrets'
+//	  type: 'aws-secretsmanager'
+//	  path: 'myapp/prod/'  # required name prefix
+//	  json: 'true'         # optional; 'false' keeps JSON secrets as strings
+//	  sensitive: 'auto'    # optional; auto and all mark every value, none marks none
+//
+// Names below the prefix become nested keys split on "/", so the JSON secret
+// myapp/prod/db is referenced as @secrets:db.password.
+const AWSSecretsManagerProviderType = "aws-secretsmanager"
+
+// awsProvider implements core.Provider for the built-in AWS provider types.
+// Region and credentials come from the AWS SDK's default chain; see
+// awsProvider.loadConfig.
+type awsProvider struct {
+	typeName string
+	path     string
+	region   string
+	profile  string
+	endpoint string
+
+	sensitive string
+	recursive bool // SSM only
+	decrypt   bool // SSM only
+	parseJSON bool // Secrets Manager only
+
+	data map[string]any
+}
+
+func newAWSSSMProvider(config map[string]any) (core.Provider, error) {
+	p := &awsProvider{typeName: AWSSSMProviderType, recursive: true, decrypt: true}
+	if err := p.configure(config, map[string]*bool{"recursive": &p.recursive, "decrypt": &p.decrypt}); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(p.path, "/") {
+		return nil, fmt.Errorf("%s config 'path' must start with '/' (got %q)", p.typeName, p.path)
+	}
+	return p, nil
+}
+
+func newAWSSecretsManagerProvider(config map[string]any) (core.Provider, error) {
+	p := &awsProvider{typeName: AWSSecretsManagerProviderType, parseJSON: true}
+	if err := p.configure(config, map[string]*bool{"json": &p.parseJSON}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// configure applies the settings shared by the AWS providers plus the
+// boolean settings in flags.
+func (p *awsProvider) configure(config map[string]any, flags map[string]*bool) error {
+	p.sensitive = sensitiveAuto
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s config %q must be a string", p.typeName, key)
+		}
+		switch key {
+		case "path":
+			p.path = s
+		case "region":
+			p.region = s
+		case "profile":
+			p.profile = s
+		case "endpoint":
+			p.endpoint = s
+		case "sensitive":
+			if err := checkSensitive(p.typeName, s); err != nil {
+				return err
+			}
+			p.sensitive = s
+		default:
+			flag, ok := flags[key]
+			if !ok {
+				return fmt.Errorf("unknown %s config %q", p.typeName, key)
+			}
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("%s config %q must be 'true' or 'false' (got %q)", p.typeName, key, s)
+			}
+			*flag = b
+		}
+	}
+	if p.path == "" {
+		return fmt.Errorf("%s config requires 'path'", p.typeName)
+	}
+	return nil
+}
+
+// Init implements core.Provider. It reads every value under the configured
+// path once; references are then served from memory.
+func (p *awsProvider) Init(ctx context.Context, _ core.ProviderInitOptions) error {
+	cfg, err := p.loadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.typeName, err)
+	}
+
+	if p.typeName == AWSSSMProviderType {
+		p.data, err = p.loadParameters(ctx, ssm.NewFromConfig(cfg))
+	} else {
+		p.data, err = p.loadSecrets(ctx, secretsmanager.NewFromConfig(cfg))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.typeName, err)
+	}
+	return nil
+}
+
+// loadConfig resolves the region and credentials with the AWS SDK's default
+// chain, as the AWS CLI does: environment variables, the shared config and
+// credentials files (including SSO, credential_process, and assume-role
+// profiles), web identity tokens, ECS container credentials, and EC2
+// instance metadata. The region, profile, and endpoint settings take
+// precedence over AWS_REGION, AWS_PROFILE, and AWS_ENDPOINT_URL.
+func (p *awsProvider) loadConfig(ctx context.Context) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+	if p.region != "" {
+		opts = append(opts, config.WithRegion(p.region))
+	}
+	if p.profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(p.profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, errors.New("no AWS region configured: set 'region' or AWS_REGION")
+	}
+	if p.endpoint != "" {
+		cfg.BaseEndpoint = aws.String(p.endpoint)
+	}
+	return cfg, nil
+}
+
+// Fetch implements core.Provider.
+func (p *awsProvider) Fetch(_ context.Context, path []string) (any, error) {
+	kind := "parameter"
+	if p.typeName == AWSSecretsManagerProviderType {
+		kind = "secret"
+	}
+	return lookupPath(p.data, path, kind)
+}
+
+// loadParameters reads every SSM parameter under the path, following
+// pagination.
+func (p *awsProvider) loadParameters(ctx context.Context, client *ssm.Client) (map[string]any, error) {
+	data := make(map[string]any)
+	pages := ssm.NewGetParametersByPathPaginator(client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(p.path),
+		Recursive:      aws.Bool(p.recursive),
+		WithDecryption: aws.Bool(p.decrypt),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range page.Parameters {
+			name := aws.ToString(param.Name)
+			var value any = aws.ToString(param.Value)
+			if param.Type == ssmtypes.ParameterTypeStringList {
+				var items []any
+				for item := range strings.SplitSeq(aws.ToString(param.Value), ",") {
+					items = append(items, item)
+				}
+				value = items
+			}
+			if p.sensitive == sensitiveAll || (p.sensitive == sensitiveAuto && param.Type == ssmtypes.ParameterTypeSecureString) {
+				value = models.Secret{Value: value}
+			}
+			if err := setNested(data, awsKeyPath(name, p.path), value); err != nil {
+				return nil, fmt.Errorf("parameter %q: %w", name, err)
+			}
+		}
+	}
+	return data, nil
+}
+
+// loadSecrets reads the current value of every secret whose name starts
+// with the path, following pagination. Binary secrets are returned
+// base64-encoded.
+func (p *awsProvider) loadSecrets(ctx context.Context, client *secretsmanager.Client) (map[string]any, error) {
+	data := make(map[string]any)
+	pages := secretsmanager.NewBatchGetSecretValuePaginator(client, &secretsmanager.BatchGetSecretValueInput{
+		Filters: []smtypes.Filter{{Key: smtypes.FilterNameStringTypeName, Values: []string{p.path}}},
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Errors) > 0 {
+			e := page.Errors[0]
+			return nil, fmt.Errorf("failed to read secret %q: %s: %s", aws.ToString(e.SecretId), aws.ToString(e.ErrorCode), aws.ToString(e.Message))
+		}
+		for _, secret := range page.SecretValues {
+			name := aws.ToString(secret.Name)
+			// The name filter is case-insensitive; keep exact prefix matches
+			if !strings.HasPrefix(name, p.path) {
+				continue
+			}
+			var value any = aws.ToString(secret.SecretString)
+			if secret.SecretString == nil {
+				value = base64.StdEncoding.EncodeToString(secret.SecretBinary)
+			} else if p.parseJSON {
+				var object map[string]any
+				if json.Unmarshal([]byte(*secret.SecretString), &object) == nil && object != nil {
+					value = object
+				}
+			}
+			if p.sensitive != sensitiveNone {
+				value = secretLeaves(value)
+			}
+			if err := setNested(data, awsKeyPath(name, p.path), value); err != nil {
+				return nil, fmt.Errorf("secret %q: %w", name, err)
+			}
+		}
+	}
+	return data, nil
+}
+
+// awsKeyPath splits the part of name below prefix into keys. A name equal
+// to the prefix is keyed by its last segment.
+func awsKeyPath(name, prefix string) []string {
+	var keys []string
+	for key := range strings.SplitSeq(strings.TrimPrefix(name, prefix), "/") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		keys = []string{name[strings.LastIndex(name, "/")+1:]}
+	}
+	return keys
+}
+
+// setNested stores value at keys in data, creating intermediate maps.
+func setNested(data map[string]any, keys []string, value any) error {
+	for i, key := range keys[:len(keys)-1] {
+		next, exists := data[key]
+		if !exists {
+			m := make(map[string]any)
+			data[key] = m
+			data = m
+			continue
+		}
+		m, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("conflicts with the value at %q", strings.Join(keys[:i+1], "."))
+		}
+		data = m
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := data[last]; exists {
+		return errors.New("conflicts with a nested value of the same name")
+	}
+	data[last] = value
+	return nil
+}
+
+// secretLeaves marks every scalar and list in value as a secret, keeping
+// maps navigable so references can select individual keys.
+func secretLeaves(value any) any {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return models.Secret{Value: value}
+	}
+	for k, v := range m {
+		m[k] = secretLeaves(v)
+	}
+	return m
+}
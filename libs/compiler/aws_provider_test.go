@@ -0,0 +1,215 @@
+package compiler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// fakeAWSEndpoint serves SSM and Secrets Manager requests and configures
+// credentials for the built-in AWS providers through the environment, with
+// no shared config files. The returned function reports the authorization
+// header of the last request.
+func fakeAWSEndpoint(t *testing.T) (string, func() string) {
+	t.Helper()
+	var mu sync.Mutex
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+
+		var output any
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParametersByPath":
+			output = map[string]any{"Parameters": []any{
+				map[string]any{"Name": "/app/prod/db/host", "Type": "String", "Value": "db.internal"},
+				map[string]any{"Name": "/app/prod/db/password", "Type": "SecureString", "Value": "hunter2"},
+				map[string]any{"Name": "/app/prod/zones", "Type": "StringList", "Value": "a,b"},
+			}}
+		case "secretsmanager.BatchGetSecretValue":
+			output = map[string]any{"SecretValues": []any{
+				map[string]any{"Name": "app/prod/db", "SecretString": `{"username":"admin","password":"s3cr3t"}`},
+				map[string]any{"Name": "app/prod/api-key", "SecretString": "key-123"},
+			}}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_ = json.NewEncoder(w).Encode(output)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIATEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "us-east-1")
+	return server.URL, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return authorization
+	}
+}
+
+// TestCompile_AWSProviders verifies that the built-in AWS providers expose
+// values under a path as nested keys and mark sensitive values as secrets.
+func TestCompile_AWSProviders(t *testing.T) {
+	endpoint, _ := fakeAWSEndpoint(t)
+	key := make([]byte, 32)
+
+	tests := []struct {
+		name      string
+		source    string
+		body      string
+		want      map[string]any
+		encrypted []string
+		wantErr   string
+	}{
+		{
+			name:      "ssm",
+			source:    "type: 'aws-ssm'\n  path: '/app/prod'",
+			body:      "host: @aws:db.host\npassword: @aws:db.password\nzones: @aws:zones\n",
+			want:      map[string]any{"host": "db.internal", "zones": []any{"a", "b"}},
+			encrypted: []string{"password"},
+		},
+		{
+			name:   "ssm not sensitive",
+			source: "type: 'aws-ssm'\n  path: '/app/prod'\n  sensitive: 'none'",
+			body:   "password: @aws:db.password\n",
+			want:   map[string]any{"password": "hunter2"},
+		},
+		{
+			name:      "ssm all sensitive",
+			source:    "type: 'aws-ssm'\n  path: '/app/prod'\n  sensitive: 'all'",
+			body:      "host: @aws:db.host\n",
+			want:      map[string]any{},
+			encrypted: []string{"host"},
+		},
+		{
+			name:      "secrets manager",
+			source:    "type: 'aws-secretsmanager'\n  path: 'app/prod/'",
+			body:      "password: @aws:db.password\napi: @aws:api-key\n",
+			want:      map[string]any{},
+			encrypted: []string{"password", "api"},
+		},
+		{
+			name:   "secrets manager not sensitive",
+			source: "type: 'aws-secretsmanager'\n  path: 'app/prod/'\n  sensitive: 'none'",
+			body:   "user: @aws:db.username\n",
+			want:   map[string]any{"user": "admin"},
+		},
+		{
+			name:    "missing path",
+			source:  "type: 'aws-ssm'",
+			body:    "host: @aws:db.host\n",
+			wantErr: "requires 'path'",
+		},
+		{
+			name:    "invalid sensitive",
+			source:  "type: 'aws-ssm'\n  path: '/app'\n  sensitive: 'maybe'",
+			body:    "host: @aws:db.host\n",
+			wantErr: "must be auto, all, or none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			src := "source:\n  alias: 'aws'\n  " + tt.source + "\n  endpoint: '" + endpoint + "'\n\nout:\n" + indent(tt.body)
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+				EncryptionKey:        key,
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			out, _ := result.Snapshot.Data["out"].(map[string]any)
+			for _, k := range tt.encrypted {
+				if s, ok := out[k].(string); !ok || s == "" || strings.Contains(s, "hunter2") || strings.Contains(s, "s3cr3t") {
+					t.Errorf("out[%q] = %#v, want ciphertext", k, out[k])
+				}
+				delete(out, k)
+			}
+			if !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("out = %#v, want %#v", out, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompile_AWSProviders_CredentialProcess verifies that credentials come
+// from the AWS SDK's default chain, here a profile's credential_process.
+func TestCompile_AWSProviders_CredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential_process script requires a POSIX shell")
+	}
+	endpoint, authorization := fakeAWSEndpoint(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "credentials.sh")
+	//nolint:gosec // G306: Test script must be executable
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho '{\"Version\": 1, \"AccessKeyId\": \"AKIAPROCESS\", \"SecretAccessKey\": \"secret\"}'\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(os.Getenv("AWS_CONFIG_FILE"), "[profile build]\ncredential_process = "+script+"\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "app.csl")
+	src := "source:\n  alias: 'aws'\n  type: 'aws-ssm'\n  path: '/app/prod'\n  profile: 'build'\n  endpoint: '" + endpoint + "'\n\n" +
+		"out:\n  host: @aws:db.host\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 path,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+	if got := result.Snapshot.Data["out"]; !reflect.DeepEqual(got, map[string]any{"host": "db.internal"}) {
+		t.Errorf("out = %#v", got)
+	}
+	if got := authorization(); !strings.Contains(got, "Credential=AKIAPROCESS/") {
+		t.Errorf("Authorization = %q, want it signed with the credential_process keys", got)
+	}
+}
+
+func indent(body string) string {
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ") + "\n"
+}
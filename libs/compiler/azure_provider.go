@@ -0,0 +1,391 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azappconfig/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// AzureKeyVaultProviderType is the built-in provider type that reads Azure
+// Key Vault secrets by name:
+//
+//	source:
+//	  alias: 'kv'
+//	  type: 'azure-keyvault'
+//	  vault: 'myvault'  # required; vault name or URL
+//
+// References name a secret, e.g. @kv:db-password, and may select a key of a
+// JSON secret, e.g. @kv:db-credentials.username. Secrets are read on first
+// reference and every value is returned as a secret.
+//
+// Both Azure providers authenticate with azidentity.DefaultAzureCredential:
+// a service principal or workload identity from the environment, a managed
+// identity, or the Azure CLI or Azure Developer CLI account.
+const AzureKeyVaultProviderType = "azure-keyvault"
+
+// AzureAppConfigProviderType is the built-in provider type that reads Azure
+// App Configuration key-values:
+//
+//	source:
+//	  alias: 'appconfig'
+//	  type: 'azure-appconfig'
+//	  store: 'mystore'          # required; store name or endpoint URL
+//	  key_filter: 'myapp:*'     # optional; default '*'
+//	  labels: ',prod'           # optional; later labels override earlier ones
+//	  trim_prefix: 'myapp:'     # optional; removed from keys
+//	  separator: ':'            # optional; splits keys into nested keys
+//	  sensitive: 'auto'         # optional; auto (Key Vault references), all, or none
+//
+// An empty label selects key-values without a label, the default. Key Vault
+// references are resolved to the secret they point to.
+const AzureAppConfigProviderType = "azure-appconfig"
+
+// azureKeyVaultReferenceContentType marks an App Configuration key-value
+// whose value points to a Key Vault secret.
+const azureKeyVaultReferenceContentType = "application/vnd.microsoft.appconfig.keyvaultref+json"
+
+// azureClientOptions configure the Azure SDK credential and clients; tests
+// route their requests to a fake server.
+var azureClientOptions azcore.ClientOptions
+
+// newAzureCredential returns the credential of the Azure providers. It
+// reaches no service until a token is needed.
+func newAzureCredential() (azcore.TokenCredential, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: azureClientOptions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up Azure credentials: %w", err)
+	}
+	return cred, nil
+}
+
+// azureSecrets reads Key Vault secrets, with a client per vault.
+type azureSecrets struct {
+	cred    azcore.TokenCredential
+	clients map[string]*azsecrets.Client
+}
+
+func newAzureSecrets(cred azcore.TokenCredential) *azureSecrets {
+	return &azureSecrets{cred: cred, clients: make(map[string]*azsecrets.Client)}
+}
+
+// get returns the value of the secret name in the vault at vaultURL. An
+// empty version reads the current version.
+func (s *azureSecrets) get(ctx context.Context, vaultURL, name, version string) (string, error) {
+	client, ok := s.clients[vaultURL]
+	if !ok {
+		var err error
+		client, err = azsecrets.NewClient(vaultURL, s.cred, &azsecrets.ClientOptions{ClientOptions: azureClientOptions})
+		if err != nil {
+			return "", err
+		}
+		s.clients[vaultURL] = client
+	}
+	resp, err := client.GetSecret(ctx, name, version, nil)
+	if err != nil {
+		return "", azureError(err)
+	}
+	if resp.Value == nil {
+		return "", nil
+	}
+	return *resp.Value, nil
+}
+
+// azureError shortens the multi-line errors of Azure services to their
+// error code and status.
+func azureError(err error) error {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+	if respErr.ErrorCode == "" {
+		return fmt.Errorf("HTTP %d", respErr.StatusCode)
+	}
+	return fmt.Errorf("%s (HTTP %d)", respErr.ErrorCode, respErr.StatusCode)
+}
+
+// azureKeyVaultProvider implements core.Provider over one Key Vault.
+type azureKeyVaultProvider struct {
+	vaultURL string
+	secrets  *azureSecrets
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newAzureKeyVaultProvider(config map[string]any) (core.Provider, error) {
+	p := &azureKeyVaultProvider{values: make(map[string]string)}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s config %q must be a string", AzureKeyVaultProviderType, key)
+		}
+		switch key {
+		case "vault":
+			p.vaultURL = azureServiceURL(s, ".vault.azure.net")
+		default:
+			return nil, fmt.Errorf("unknown %s config %q", AzureKeyVaultProviderType, key)
+		}
+	}
+	if p.vaultURL == "" {
+		return nil, fmt.Errorf("%s config requires 'vault'", AzureKeyVaultProviderType)
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. Tokens are requested on the first
+// reference.
+func (p *azureKeyVaultProvider) Init(context.Context, core.ProviderInitOptions) error {
+	cred, err := newAzureCredential()
+	if err != nil {
+		return fmt.Errorf("%s: %w", AzureKeyVaultProviderType, err)
+	}
+	p.secrets = newAzureSecrets(cred)
+	return nil
+}
+
+// Fetch implements core.Provider. The first path segment names the secret;
+// further segments select keys of a JSON secret.
+func (p *azureKeyVaultProvider) Fetch(ctx context.Context, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%s references must name a secret: %w", AzureKeyVaultProviderType, core.ErrPropertyPathInvalid)
+	}
+	value, err := p.secret(ctx, path[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 {
+		return models.Secret{Value: value}, nil
+	}
+
+	var object map[string]any
+	if json.Unmarshal([]byte(value), &object) != nil || object == nil {
+		return nil, fmt.Errorf("secret %q is not a JSON object: %w", path[0], core.ErrPropertyPathInvalid)
+	}
+	return lookupPath(secretLeaves(object).(map[string]any), path[1:], "key")
+}
+
+// secret returns the current value of the secret name, reading it once.
+func (p *azureKeyVaultProvider) secret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if value, ok := p.values[name]; ok {
+		return value, nil
+	}
+	value, err := p.secrets.get(ctx, p.vaultURL, name, "")
+	if err != nil {
+		return "", fmt.Errorf("%s: reading secret %q: %w", AzureKeyVaultProviderType, name, err)
+	}
+	p.values[name] = value
+	return value, nil
+}
+
+// azureAppConfigProvider implements core.Provider over the key-values of an
+// App Configuration store.
+type azureAppConfigProvider struct {
+	endpoint   string
+	keyFilter  string
+	labels     []string
+	trimPrefix string
+	separator  string
+	sensitive  string
+
+	data map[string]any
+}
+
+func newAzureAppConfigProvider(config map[string]any) (core.Provider, error) {
+	p := &azureAppConfigProvider{keyFilter: "*", labels: []string{""}, separator: ":", sensitive: sensitiveAuto}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s config %q must be a string", AzureAppConfigProviderType, key)
+		}
+		switch key {
+		case "store":
+			p.endpoint = azureServiceURL(s, ".azconfig.io")
+		case "key_filter":
+			p.keyFilter = s
+		case "labels":
+			if strings.ContainsAny(s, "*\\") {
+				return nil, fmt.Errorf("%s config 'labels' must list labels in precedence order, not a pattern (got %q)", AzureAppConfigProviderType, s)
+			}
+			p.labels = strings.Split(s, ",")
+			for i := range p.labels {
+				p.labels[i] = strings.TrimSpace(p.labels[i])
+			}
+		case "trim_prefix":
+			p.trimPrefix = s
+		case "separator":
+			p.separator = s
+		case "sensitive":
+			if err := checkSensitive(AzureAppConfigProviderType, s); err != nil {
+				return nil, err
+			}
+			p.sensitive = s
+		default:
+			return nil, fmt.Errorf("unknown %s config %q", AzureAppConfigProviderType, key)
+		}
+	}
+	if p.endpoint == "" {
+		return nil, fmt.Errorf("%s config requires 'store'", AzureAppConfigProviderType)
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. It reads every matching key-value once;
+// references are then served from memory.
+func (p *azureAppConfigProvider) Init(ctx context.Context, _ core.ProviderInitOptions) error {
+	cred, err := newAzureCredential()
+	if err != nil {
+		return fmt.Errorf("%s: %w", AzureAppConfigProviderType, err)
+	}
+	client, err := azappconfig.NewClient(p.endpoint, cred, &azappconfig.ClientOptions{ClientOptions: azureClientOptions})
+	if err != nil {
+		return fmt.Errorf("%s: %w", AzureAppConfigProviderType, err)
+	}
+	secrets := newAzureSecrets(cred)
+
+	// Later labels override earlier ones
+	values := make(map[string]any)
+	for _, label := range p.labels {
+		settings, err := listAzureSettings(ctx, client, p.keyFilter, label)
+		if err != nil {
+			return fmt.Errorf("%s: %w", AzureAppConfigProviderType, err)
+		}
+		for _, setting := range settings {
+			key := azureString(setting.Key)
+			value, err := p.value(ctx, secrets, setting)
+			if err != nil {
+				return fmt.Errorf("%s: key %q: %w", AzureAppConfigProviderType, key, err)
+			}
+			values[key] = value
+		}
+	}
+
+	p.data = make(map[string]any)
+	for _, key := range sortedKeys(values) {
+		path := p.keyPath(key)
+		if len(path) == 0 {
+			continue
+		}
+		if err := setNested(p.data, path, values[key]); err != nil {
+			return fmt.Errorf("%s: key %q: %w", AzureAppConfigProviderType, key, err)
+		}
+	}
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *azureAppConfigProvider) Fetch(_ context.Context, path []string) (any, error) {
+	return lookupPath(p.data, path, "key")
+}
+
+// listAzureSettings returns the key-values whose keys match keyFilter and
+// whose label is label, following pagination. An empty label selects
+// key-values without a label.
+func listAzureSettings(ctx context.Context, client *azappconfig.Client, keyFilter, label string) ([]azappconfig.Setting, error) {
+	if label == "" {
+		label = "\x00"
+	}
+	pages := client.NewListSettingsPager(azappconfig.SettingSelector{
+		KeyFilter:   &keyFilter,
+		LabelFilter: &label,
+		Fields:      []azappconfig.SettingFields{azappconfig.SettingFieldsKey, azappconfig.SettingFieldsValue, azappconfig.SettingFieldsContentType},
+	}, nil)
+	var settings []azappconfig.Setting
+	for pages.More() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, azureError(err)
+		}
+		settings = append(settings, page.Settings...)
+	}
+	return settings, nil
+}
+
+// value returns the value of setting, resolving Key Vault references.
+func (p *azureAppConfigProvider) value(ctx context.Context, secrets *azureSecrets, setting azappconfig.Setting) (any, error) {
+	var value any = azureString(setting.Value)
+	isReference := strings.HasPrefix(azureString(setting.ContentType), azureKeyVaultReferenceContentType)
+	if isReference {
+		var ref struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal([]byte(azureString(setting.Value)), &ref); err != nil || ref.URI == "" {
+			return nil, errors.New("invalid Key Vault reference")
+		}
+		vaultURL, name, version, err := parseKeyVaultSecretURI(ref.URI)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := secrets.get(ctx, vaultURL, name, version)
+		if err != nil {
+			return nil, fmt.Errorf("resolving Key Vault reference %s: %w", ref.URI, err)
+		}
+		value = secret
+	}
+	if p.sensitive == sensitiveAll || (p.sensitive == sensitiveAuto && isReference) {
+		value = models.Secret{Value: value}
+	}
+	return value, nil
+}
+
+// keyPath removes the prefix from key and splits it into nested keys.
+func (p *azureAppConfigProvider) keyPath(key string) []string {
+	key = strings.TrimPrefix(key, p.trimPrefix)
+	if p.separator == "" {
+		return []string{key}
+	}
+	var path []string
+	for segment := range strings.SplitSeq(key, p.separator) {
+		if segment != "" {
+			path = append(path, segment)
+		}
+	}
+	return path
+}
+
+// parseKeyVaultSecretURI splits a Key Vault secret identifier such as
+// https://myvault.vault.azure.net/secrets/name/version.
+func parseKeyVaultSecretURI(uri string) (vaultURL, name, version string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid Key Vault secret URI %q", uri)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || len(segments) > 3 || segments[0] != "secrets" {
+		return "", "", "", fmt.Errorf("invalid Key Vault secret URI %q", uri)
+	}
+	if len(segments) == 3 {
+		version = segments[2]
+	}
+	return u.Scheme + "://" + u.Host, segments[1], version, nil
+}
+
+// azureString returns the string s points to, or "" for nil.
+func azureString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// azureServiceURL returns value if it is a URL, and otherwise the URL of the
+// resource named value in the public cloud domain.
+func azureServiceURL(value, domain string) string {
+	if value == "" || strings.Contains(value, "://") {
+		return value
+	}
+	return "https://" + value + domain
+}
@@ -0,0 +1,187 @@
+package compiler_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// fakeAzure serves Entra ID tokens, Key Vault secrets of the vault
+// myvault, and App Configuration key-values of the store mystore. It routes
+// the Azure providers' requests to itself and configures service principal
+// credentials through the environment.
+func fakeAzure(t *testing.T) {
+	t.Helper()
+	const login = "https://login.microsoftonline.com"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Host {
+		case "login.microsoftonline.com":
+			switch r.URL.Path {
+			case "/common/discovery/instance":
+				_, _ = fmt.Fprintf(w, `{"tenant_discovery_endpoint":%q,"metadata":[{"preferred_network":"login.microsoftonline.com","preferred_cache":"login.windows.net","aliases":["login.microsoftonline.com"]}]}`,
+					login+"/tenant/v2.0/.well-known/openid-configuration")
+			case "/tenant/v2.0/.well-known/openid-configuration":
+				_, _ = fmt.Fprintf(w, `{"token_endpoint":%q,"authorization_endpoint":%q,"issuer":%q}`,
+					login+"/tenant/oauth2/v2.0/token", login+"/tenant/oauth2/v2.0/authorize", login+"/tenant/v2.0")
+			case "/tenant/oauth2/v2.0/token":
+				_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3599}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		case "myvault.vault.azure.net":
+			// Key Vault clients learn the tenant and scope from a challenge
+			if r.Header.Get("Authorization") == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer authorization="`+login+`/tenant", resource="https://vault.azure.net"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		case "mystore.azconfig.io":
+			w.Header().Set("Sync-Token", "id=1;sn=1")
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+		case path == "/secrets/db-password":
+			_, _ = w.Write([]byte(`{"value":"s3cr3t"}`))
+		case path == "/secrets/db-credentials":
+			_, _ = w.Write([]byte(`{"value":"{\"username\":\"admin\",\"password\":\"hunter2\"}"}`))
+		case path == "/kv" && r.URL.Query().Get("label") == "\x00":
+			_, _ = w.Write([]byte(`{"items":[
+				{"key":"myapp:db:host","label":null,"value":"db.internal"},
+				{"key":"myapp:db:port","label":null,"value":"5432"},
+				{"key":"myapp:db:password","label":null,"value":"{\"uri\":\"https://myvault.vault.azure.net/secrets/db-password\"}",
+				 "content_type":"application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"}
+			]}`))
+		case path == "/kv" && r.URL.Query().Get("label") == "prod":
+			_, _ = w.Write([]byte(`{"items":[{"key":"myapp:db:host","label":"prod","value":"prod.internal"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"SecretNotFound","message":"not found"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Connect to the server whatever the host, trusting its certificate
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig.ServerName = "example.com"
+	compiler.SetAzureTransport(t, &http.Client{Transport: transport})
+
+	t.Setenv("AZURE_AUTHORITY_HOST", "")
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_CLIENT_ID", "client")
+	t.Setenv("AZURE_CLIENT_SECRET", "secret")
+}
+
+// TestCompile_AzureProviders verifies that the built-in Azure providers
+// read Key Vault secrets and App Configuration key-values.
+func TestCompile_AzureProviders(t *testing.T) {
+	fakeAzure(t)
+	key := make([]byte, 32)
+
+	tests := []struct {
+		name      string
+		source    string
+		body      string
+		want      map[string]any
+		encrypted []string
+		wantErr   string
+	}{
+		{
+			name:      "key vault",
+			source:    "type: 'azure-keyvault'\n  vault: 'myvault'",
+			body:      "password: @azure:db-password\nuser: @azure:db-credentials.username\n",
+			want:      map[string]any{},
+			encrypted: []string{"password", "user"},
+		},
+		{
+			name:    "key vault missing secret",
+			source:  "type: 'azure-keyvault'\n  vault: 'myvault'",
+			body:    "password: @azure:nope\n",
+			wantErr: "SecretNotFound",
+		},
+		{
+			name:    "key vault not json",
+			source:  "type: 'azure-keyvault'\n  vault: 'myvault'",
+			body:    "user: @azure:db-password.username\n",
+			wantErr: "is not a JSON object",
+		},
+		{
+			name:      "app configuration",
+			source:    "type: 'azure-appconfig'\n  store: 'mystore'\n  key_filter: 'myapp:*'\n  trim_prefix: 'myapp:'",
+			body:      "host: @azure:db.host\nport: @azure:db.port\npassword: @azure:db.password\n",
+			want:      map[string]any{"host": "db.internal", "port": "5432"},
+			encrypted: []string{"password"},
+		},
+		{
+			name:   "app configuration labels",
+			source: "type: 'azure-appconfig'\n  store: 'mystore'\n  labels: ',prod'\n  trim_prefix: 'myapp:'\n  sensitive: 'none'",
+			body:   "host: @azure:db.host\npassword: @azure:db.password\n",
+			want:   map[string]any{"host": "prod.internal", "password": "s3cr3t"},
+		},
+		{
+			name:    "app configuration label pattern",
+			source:  "type: 'azure-appconfig'\n  store: 'mystore'\n  labels: 'prod*'",
+			body:    "host: @azure:db.host\n",
+			wantErr: "not a pattern",
+		},
+		{
+			name:    "missing store",
+			source:  "type: 'azure-appconfig'",
+			body:    "host: @azure:db.host\n",
+			wantErr: "requires 'store'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			src := "source:\n  alias: 'azure'\n  " + tt.source + "\n\nout:\n" + indent(tt.body)
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+				EncryptionKey:        key,
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			out, _ := result.Snapshot.Data["out"].(map[string]any)
+			for _, k := range tt.encrypted {
+				if s, ok := out[k].(string); !ok || s == "" || strings.Contains(s, "s3cr3t") || s == "admin" {
+					t.Errorf("out[%q] = %#v, want ciphertext", k, out[k])
+				}
+				delete(out, k)
+			}
+			if !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("out = %#v, want %#v", out, tt.want)
+			}
+		})
+	}
+}
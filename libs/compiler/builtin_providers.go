@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+)
+
+// builtinProviderTypes maps built-in provider types to their constructors.
+// Built-in types run in-process and need no provider binary or lockfile entry.
+var builtinProviderTypes = map[string]core.ProviderTypeConstructor{
+	TfstateProviderType:           newTfstateProvider,
+	AWSSSMProviderType:            newAWSSSMProvider,
+	AWSSecretsManagerProviderType: newAWSSecretsManagerProvider,
+	VaultProviderType:             newVaultProvider,
+	AzureKeyVaultProviderType:     newAzureKeyVaultProvider,
+	AzureAppConfigProviderType:    newAzureAppConfigProvider,
+	GCPSecretManagerProviderType:  newGCPSecretManagerProvider,
+	KubernetesProviderType:        newKubernetesProvider,
+	DatafileProviderType:          newDatafileProvider,
+}
+
+// IsBuiltinProviderType reports whether typeName is a provider type served
+// in-process by the compiler, so no provider binary needs to be installed.
+func IsBuiltinProviderType(typeName string) bool {
+	_, ok := builtinProviderTypes[typeName]
+	return ok
+}
+
+// registerBuiltinProviderTypes registers the built-in provider types that
+// registry does not already provide.
+func registerBuiltinProviderTypes(registry ProviderTypeRegistry) {
+	for typeName, constructor := range builtinProviderTypes {
+		if !registry.IsTypeRegistered(typeName) {
+			registry.RegisterType(typeName, constructor)
+		}
+	}
+}
+
+// Values of the "sensitive" setting of the built-in secret store providers.
+// Sensitive values are returned as secrets, so they are encrypted when an
+// encryption key is configured; what auto covers depends on the provider.
+const (
+	sensitiveAuto = "auto"
+	sensitiveAll  = "all"
+	sensitiveNone = "none"
+)
+
+// checkSensitive validates a "sensitive" setting of typeName.
+func checkSensitive(typeName, value string) error {
+	if value != sensitiveAuto && value != sensitiveAll && value != sensitiveNone {
+		return fmt.Errorf("%s config 'sensitive' must be auto, all, or none (got %q)", typeName, value)
+	}
+	return nil
+}
+
+// lookupPath navigates data by path for a built-in provider's Fetch. An
+// empty path returns all of data; kind names the top-level entries (e.g.
+// "terraform output") in error messages.
+func lookupPath(data map[string]any, path []string, kind string) (any, error) {
+	if len(path) == 0 {
+		return data, nil
+	}
+
+	value, ok := data[path[0]]
+	if !ok {
+		return nil, fmt.Errorf("%s %q not found (available: %s): %w", kind, path[0], strings.Join(sortedKeys(data), ", "), core.ErrPropertyPathInvalid)
+	}
+	for i, segment := range path[1:] {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path segment %d is not a map (got %T): %w", i+1, value, core.ErrPropertyPathInvalid)
+		}
+		if value, ok = m[segment]; !ok {
+			return nil, fmt.Errorf("key %q not found in %s %q: %w", strings.Join(path[1:i+2], "."), kind, path[0], core.ErrPropertyPathInvalid)
+		}
+	}
+	return value, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -11,6 +11,14 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// Provider protocol versions the compiler speaks. Version N is the
+// nomos.provider.vN package of libs/provider-proto; external providers must
+// serve a version in this range.
+const (
+	MinProviderProtocolVersion = 1
+	MaxProviderProtocolVersion = 1
+)
+
 // Client implements the Provider interface by delegating to a gRPC provider service.
 // It wraps a gRPC client connection and translates between the local Provider interface
 // and the remote gRPC calls.
@@ -59,10 +67,15 @@ func (c *Client) Fetch(ctx context.Context, path []string) (any, error) {
 
 	resp, err := c.client.Fetch(ctx, req)
 	if err != nil {
-		// Check for NotFound error
+		// Classify gRPC status codes
 		if st, ok := status.FromError(err); ok {
-			if st.Code() == codes.NotFound {
+			switch st.Code() {
+			case codes.NotFound:
 				return nil, fmt.Errorf("path not found: %v", path)
+			case codes.DeadlineExceeded:
+				return nil, fmt.Errorf("%w: provider fetch failed: %w", ErrTimeout, err)
+			case codes.Unavailable:
+				return nil, fmt.Errorf("%w: provider fetch failed: %w", ErrProviderUnavailable, err)
 			}
 		}
 		return nil, fmt.Errorf("provider fetch failed: %w", err)
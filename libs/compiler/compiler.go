@@ -13,9 +13,10 @@ import (
 	"time"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
-	"github.com/autonomous-bits/nomos/libs/compiler/internal/parse"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/resolver"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/validator"
 )
 
@@ -37,12 +38,110 @@ type Options struct {
 	// Timeouts configures timeout behavior for compilation operations.
 	Timeouts OptionsTimeouts
 
+	// ParseConcurrency limits how many input files are parsed in parallel
+	// when Path is a directory. Zero uses GOMAXPROCS; 1 parses serially.
+	// Files are always merged in lexicographic order, so output does not
+	// depend on this setting.
+	ParseConcurrency int
+
 	// AllowMissingProvider, if true, prevents errors when a provider is not found.
 	AllowMissingProvider bool
 
+	// PartialFailureMode controls whether a reference whose provider is
+	// unavailable or whose fetch fails aborts the compilation. The zero
+	// value is PartialFailureFailFast. AllowMissingProvider implies
+	// PartialFailureBestEffort.
+	PartialFailureMode PartialFailureMode
+
+	// FetchRetry retries provider fetches that fail with transient errors
+	// before the reference counts as failed. The zero value disables
+	// retries.
+	FetchRetry RetryPolicy
+
 	// EncryptionKey is the AES-256 key used to encrypt marked secrets.
 	// If nil or empty, secrets will not be encrypted (or result in error if strictly required).
 	EncryptionKey []byte
+
+	// SuppressWarnings lists warning codes silenced for the whole compilation,
+	// typically loaded from project configuration. Inline "# nomos:ignore"
+	// directives in .csl files are honored in addition to this list.
+	SuppressWarnings []WarningCode
+
+	// SourceMap, if true, populates Snapshot.SourceMap with the origin of
+	// every output key.
+	SourceMap bool
+
+	// Policies are evaluated against the resolved data. Violations of
+	// error-severity policies fail the compilation with *PolicyError.
+	Policies []Policy
+
+	// TypeCoercion controls conversion of numeric and boolean strings to
+	// native types. The zero value is TypeCoercionOff.
+	TypeCoercion TypeCoercion
+
+	// MaxSnapshotBytes, if positive, fails the compilation with
+	// ErrSnapshotTooLarge when the resolved data would exceed this many
+	// bytes encoded as compact JSON. It lets callers with bounded memory
+	// reject oversized provider outputs before serializing them.
+	MaxSnapshotBytes int64
+
+	// Limits bounds input file sizes and the nesting depth, keys, and
+	// reference fan-out of provider values and the resolved data. The zero
+	// value imposes no limits.
+	Limits Limits
+
+	// MaxProviderMessageBytes is the largest response accepted from a gRPC
+	// provider. Zero means DefaultMaxProviderMessageBytes, the gRPC default
+	// of 4 MiB. Larger responses fail their references with
+	// *MessageSizeError.
+	MaxProviderMessageBytes int
+
+	// Overrides are deep-merged over the resolved data, before type coercion
+	// and policies, so callers can adjust values without editing sources.
+	// Nested maps merge; any other value replaces what the sources produced.
+	// Overridden keys are attributed to OverrideSource in provenance and the
+	// source map.
+	Overrides map[string]any
+
+	// ProviderConfigOverrides replaces configuration keys of source
+	// declarations, keyed by provider alias, before the providers are
+	// initialized, so callers can point a provider elsewhere without editing
+	// sources. Naming an alias no input file declares is an error. The
+	// overrides are recorded in Metadata.ProviderConfigOverrides.
+	ProviderConfigOverrides map[string]map[string]any
+
+	// ProviderPermissions, when set, restricts the provider types, aliases,
+	// and config values source declarations may use. Forbidden
+	// declarations fail the compilation with *ProviderPermissionError
+	// before any provider starts.
+	ProviderPermissions *ProviderPermissions
+
+	// Profiles selects profiles declared in the ProfileSection of the input
+	// files, merged over the rest of the data in order before references
+	// are resolved; later profiles win. Profiles not selected are dropped
+	// without fetching their references. Naming a profile no input file
+	// declares fails with ErrUnknownProfile. The selection is recorded in
+	// Metadata.Profiles.
+	Profiles []string
+
+	// ProjectRoot is the directory the caller anchored relative paths to,
+	// such as the CLI's --chdir directory. It is recorded in
+	// Metadata.ProjectRoot; the compiler does not resolve paths against it.
+	ProjectRoot string
+
+	// PreviousListOrders holds the Metadata.ListOrders of an earlier build
+	// of the same input. A reference whose lists now hold the same
+	// elements in a different order is reported with a
+	// WarnNondeterministicOrder warning.
+	PreviousListOrders []ListOrder
+
+	// Clock returns the current time for Metadata.StartTime and EndTime. Nil
+	// uses time.Now; a fixed clock makes metadata reproducible across builds.
+	Clock func() time.Time
+
+	// DebugDump, if set, collects the parsed ASTs, reference lookups, and
+	// merge decisions of the compilation for bug reports.
+	DebugDump *DebugDump
 }
 
 // OptionsTimeouts configures timeout behavior for compilation operations.
@@ -50,7 +149,9 @@ type OptionsTimeouts struct {
 	// PerProviderFetch sets the default timeout for each provider Fetch call.
 	PerProviderFetch time.Duration
 
-	// MaxConcurrentProviders limits concurrent provider fetch operations.
+	// MaxConcurrentProviders limits the providers fetched from at once.
+	// References are fetched in one batch per provider, so this bounds the
+	// concurrent batches. Zero means no limit.
 	MaxConcurrentProviders int
 }
 
@@ -61,6 +162,10 @@ type Snapshot struct {
 
 	// Metadata provides provenance and diagnostic information.
 	Metadata Metadata `json:"metadata"`
+
+	// SourceMap maps output key paths to their source locations.
+	// Populated only when Options.SourceMap is set.
+	SourceMap *SourceMap `json:"source_map,omitempty"`
 }
 
 // CompilationResult wraps a Snapshot and provides convenience methods
@@ -68,6 +173,20 @@ type Snapshot struct {
 type CompilationResult struct {
 	// Snapshot contains the compilation output and metadata.
 	Snapshot Snapshot
+
+	// errs holds the typed error behind each entry of Snapshot.Metadata.Errors.
+	errs []error
+
+	// references counts the references resolved, including those served
+	// from the cache.
+	references int
+}
+
+// ReferencesResolved returns the number of references resolved during
+// compilation, counting each reference expression once even when its value
+// was served from the cache.
+func (r CompilationResult) ReferencesResolved() int {
+	return r.references
 }
 
 // HasErrors returns true if the compilation encountered any errors.
@@ -92,16 +211,46 @@ func (r CompilationResult) Warnings() []string {
 
 // Error implements the error interface, returning a combined error message
 // if any errors occurred, or nil if compilation succeeded.
+//
+// The returned error wraps the typed errors recorded during compilation, so
+// errors.Is and errors.As can match sentinels such as ErrCircularReference
+// and structured types such as *ReferenceError.
 func (r CompilationResult) Error() error {
 	if !r.HasErrors() {
 		return nil
 	}
-	if len(r.Snapshot.Metadata.Errors) == 1 {
-		return stderrors.New(r.Snapshot.Metadata.Errors[0])
+	errs := r.errs
+	if len(errs) != len(r.Snapshot.Metadata.Errors) {
+		// Metadata was populated without typed errors (e.g. constructed by hand).
+		errs = make([]error, len(r.Snapshot.Metadata.Errors))
+		for i, msg := range r.Snapshot.Metadata.Errors {
+			errs[i] = stderrors.New(msg)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &compilationError{
+		msg: fmt.Sprintf("compilation failed with %d errors: %v",
+			len(r.Snapshot.Metadata.Errors),
+			r.Snapshot.Metadata.Errors),
+		errs: errs,
 	}
-	return fmt.Errorf("compilation failed with %d errors: %v",
-		len(r.Snapshot.Metadata.Errors),
-		r.Snapshot.Metadata.Errors)
+}
+
+// addWarning records w in the snapshot metadata unless it is suppressed.
+func (r *CompilationResult) addWarning(w Warning, filter *warningFilter) {
+	if filter.suppressed(w) {
+		return
+	}
+	r.Snapshot.Metadata.Warnings = append(r.Snapshot.Metadata.Warnings, w.String())
+	r.Snapshot.Metadata.WarningDetails = append(r.Snapshot.Metadata.WarningDetails, w)
+}
+
+// addError records err in the snapshot metadata and keeps the typed value for Error.
+func (r *CompilationResult) addError(err error) {
+	r.Snapshot.Metadata.Errors = append(r.Snapshot.Metadata.Errors, err.Error())
+	r.errs = append(r.errs, withMigrateHint(err))
 }
 
 // Metadata contains provenance and diagnostic information for a compilation run.
@@ -124,13 +273,45 @@ type Metadata struct {
 	// Warnings contains non-fatal issues encountered during compilation.
 	Warnings []string `json:"warnings"`
 
+	// WarningDetails holds the structured form of each entry in Warnings,
+	// including its code and source location.
+	WarningDetails []Warning `json:"warning_details"`
+
 	// PerKeyProvenance maps each top-level configuration key to its origin.
 	PerKeyProvenance map[string]Provenance `json:"per_key_provenance"`
+
+	// TypeCoercion records the coercion policy applied to the data.
+	TypeCoercion TypeCoercion `json:"type_coercion"`
+
+	// SensitiveKeys lists the key paths of values marked as secrets, such as
+	// values read from a secret store, whether or not they were encrypted.
+	SensitiveKeys []string `json:"sensitive_keys"`
+
+	// ProjectRoot records Options.ProjectRoot, the directory relative paths
+	// were resolved against. Empty if the caller did not set one.
+	ProjectRoot string `json:"project_root"`
+
+	// ProviderConfigOverrides records Options.ProviderConfigOverrides, the
+	// source declaration configuration replaced at build time. Nil if none
+	// were given.
+	ProviderConfigOverrides map[string]map[string]any `json:"provider_config_overrides"`
+
+	// Profiles records Options.Profiles, the profiles merged into the data,
+	// in order.
+	Profiles []string `json:"profiles"`
+
+	// ListOrders records, for each provider reference whose value holds
+	// lists, digests of their order and of their elements, sorted by
+	// reference. Lists keep the order the provider returned them in.
+	ListOrders []ListOrder `json:"list_orders"`
 }
 
 // Provenance records the origin of a configuration value.
 type Provenance struct {
-	// Source identifies the .csl file that contributed this value.
+	// Source identifies the .csl file that contributed this value,
+	// OverrideSource for values supplied through Options.Overrides, or
+	// ProviderDefaultSource for defaults published by the provider named in
+	// ProviderAlias.
 	Source string `json:"source"`
 
 	// ProviderAlias identifies the provider that resolved this value.
@@ -141,78 +322,146 @@ type Provenance struct {
 // Returns a CompilationResult containing the snapshot and all collected errors/warnings.
 // The compilation process attempts to continue through recoverable errors to collect
 // as many issues as possible in a single run.
+//
+// Compile is safe for concurrent use, including with shared registries;
+// see the package documentation for what concurrent compilations share.
 func Compile(ctx context.Context, opts Options) CompilationResult {
+	now := opts.Clock
+	if now == nil {
+		now = time.Now
+	}
+
 	// Create result with empty snapshot
 	result := CompilationResult{
 		Snapshot: Snapshot{
 			Data: make(map[string]any),
 			Metadata: Metadata{
-				InputFiles:       []string{},
-				ProviderAliases:  []string{},
-				StartTime:        time.Now(),
-				Errors:           []string{},
-				Warnings:         []string{},
-				PerKeyProvenance: make(map[string]Provenance),
+				InputFiles:              []string{},
+				ProviderAliases:         []string{},
+				StartTime:               now(),
+				Errors:                  []string{},
+				Warnings:                []string{},
+				WarningDetails:          []Warning{},
+				PerKeyProvenance:        make(map[string]Provenance),
+				TypeCoercion:            TypeCoercionOff,
+				SensitiveKeys:           []string{},
+				ProjectRoot:             opts.ProjectRoot,
+				ProviderConfigOverrides: copyProviderConfigOverrides(opts.ProviderConfigOverrides),
+				Profiles:                append([]string{}, opts.Profiles...),
 			},
 		},
 	}
 
 	// Validate context
 	if ctx == nil {
-		result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors, "context must not be nil")
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(stderrors.New("context must not be nil"))
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
 
 	// Validate options
 	if opts.Path == "" {
-		result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors, "options.Path must not be empty")
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(stderrors.New("options.Path must not be empty"))
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
 
 	if opts.ProviderRegistry == nil {
-		result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors, "options.ProviderRegistry must not be nil")
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(stderrors.New("options.ProviderRegistry must not be nil"))
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
 
-	// Register "var" provider for variable access
-	opts.ProviderRegistry.Register("var", func(_ ProviderInitOptions) (Provider, error) {
-		return &varProvider{vars: opts.Vars}, nil
-	})
+	coercionMode, err := opts.TypeCoercion.coercionMode()
+	if err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+	if err := opts.PartialFailureMode.validate(); err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+	if err := opts.Limits.Validate(); err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+	if opts.MaxProviderMessageBytes < 0 {
+		result.addError(fmt.Errorf("MaxProviderMessageBytes must not be negative (got %d)", opts.MaxProviderMessageBytes))
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+	ctx = core.WithMaxProviderMessageBytes(ctx, opts.MaxProviderMessageBytes)
+	if opts.TypeCoercion != "" {
+		result.Snapshot.Metadata.TypeCoercion = opts.TypeCoercion
+	}
+
+	// Serve the "var" provider for variable access from this compilation
+	// only, leaving a shared registry untouched
+	opts.ProviderRegistry = newVarRegistry(opts.ProviderRegistry, opts.Vars)
+
+	// Built-in provider types need no installed binary
+	if opts.ProviderTypeRegistry != nil {
+		registerBuiltinProviderTypes(opts.ProviderTypeRegistry)
+	}
 
 	// Discover input files
 	inputFiles, err := pipeline.DiscoverInputFiles(opts.Path)
 	if err != nil {
-		result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors,
-			fmt.Sprintf("failed to discover input files: %v", err))
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(fmt.Errorf("failed to discover input files: %w", err))
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
 	result.Snapshot.Metadata.InputFiles = inputFiles
 
-	// Initialize error and warning slices (already initialized in result)
-	errors := &result.Snapshot.Metadata.Errors
-	warnings := &result.Snapshot.Metadata.Warnings
+	// Reject oversized files before reading any of them
+	if opts.Limits.MaxFileBytes > 0 {
+		if errs := checkFileSizes(inputFiles, opts.Limits.MaxFileBytes); len(errs) > 0 {
+			for _, err := range errs {
+				result.addError(err)
+			}
+			result.Snapshot.Metadata.EndTime = now()
+			return result
+		}
+	}
+
+	// Warnings are filtered through project-level and inline suppressions
+	warningFilter := newWarningFilter(opts.SuppressWarnings)
+
+	// Reject forbidden source declarations before any provider starts
+	if opts.ProviderPermissions != nil {
+		errs := checkProviderPermissions(pipeline.ParseFiles(ctx, inputFiles, opts.ParseConcurrency), opts.ProviderPermissions)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				result.addError(err)
+			}
+			result.Snapshot.Metadata.EndTime = now()
+			return result
+		}
+	}
 
 	// Special case: If compiling a single file and type registry is provided,
 	// check for imports and resolve them first
 	var data map[string]any
 	var provenance map[string]Provenance
+	var aliases []string
+	var providerScopes *core.ProviderScopes
 
 	if len(inputFiles) == 1 && opts.ProviderTypeRegistry != nil {
 		// Try to resolve imports for this file
-		importData, err := resolveFileImports(ctx, inputFiles[0], opts)
+		importData, importAliases, err := resolveFileImports(ctx, inputFiles[0], opts)
 		if err != nil && !stderrors.Is(err, ErrImportResolutionNotAvailable) {
-			*errors = append(*errors, fmt.Sprintf("failed to resolve imports: %v", err))
-			result.Snapshot.Metadata.EndTime = time.Now()
+			result.addError(fmt.Errorf("failed to resolve imports: %w", err))
+			result.Snapshot.Metadata.EndTime = now()
 			return result
 		}
 
 		if err == nil {
 			// Successfully resolved with imports
 			data = importData
+			aliases = importAliases
 			provenance = make(map[string]Provenance)
 			// TODO: Track provenance for imported data
 			// Currently all keys are attributed to the root file, but they may originate from:
@@ -229,35 +478,39 @@ func Compile(ctx context.Context, opts Options) CompilationResult {
 	}
 
 	// If we didn't resolve via imports, use regular flow
+	var parsedFiles []pipeline.ParsedFile
 	if data == nil {
-		// Parse files and collect diagnostics
+		// Parse files concurrently; results come back in input order
+		parsedFiles = pipeline.ParseFiles(ctx, inputFiles, opts.ParseConcurrency)
+		if opts.DebugDump != nil {
+			opts.DebugDump.addFiles(parsedFiles)
+		}
+
+		// Collect diagnostics
 		var allDiags []diagnostic.Diagnostic
 		parseErrors := false
 
-		for _, filePath := range inputFiles {
-			_, diags, err := parse.ParseFile(filePath)
-			if err != nil {
-				*errors = append(*errors, fmt.Sprintf("fatal parse error for %q: %v", filePath, err))
+		for _, file := range parsedFiles {
+			if file.Err != nil {
+				result.addError(fmt.Errorf("fatal parse error for %q: %w", file.Path, file.Err))
 				parseErrors = true
-				continue // Continue parsing other files to collect all errors
+				continue // Report errors from all files
 			}
-
-			// Collect diagnostics
-			allDiags = append(allDiags, diags...)
+			allDiags = append(allDiags, file.Diagnostics...)
 		}
 
 		// If we had fatal parse errors, stop here
 		if parseErrors {
-			result.Snapshot.Metadata.EndTime = time.Now()
+			result.Snapshot.Metadata.EndTime = now()
 			return result
 		}
 
 		// Separate errors and warnings from diagnostics
 		for _, diag := range allDiags {
 			if diag.IsError() {
-				*errors = append(*errors, diag.FormattedMessage)
+				result.addError(&diag)
 			} else if diag.IsWarning() {
-				*warnings = append(*warnings, diag.FormattedMessage)
+				result.addWarning(warningFromDiagnostic(diag), warningFilter)
 			}
 		}
 
@@ -266,39 +519,62 @@ func Compile(ctx context.Context, opts Options) CompilationResult {
 		data = make(map[string]any)
 		provenance = make(map[string]Provenance)
 
-		for _, filePath := range inputFiles {
-			// Parse file again to get AST for conversion
-			ast, _, err := parse.ParseFile(filePath)
-			if err != nil {
-				// Already collected this error above, skip
-				continue
-			}
-			if ast == nil {
+		for _, file := range parsedFiles {
+			filePath := file.Path
+			if file.AST == nil {
+				// Parse errors were already collected above
 				continue
 			}
 
 			// Convert AST to data
-			fileData, err := converter.ASTToData(ast)
+			fileData, err := converter.ASTToData(file.AST)
 			if err != nil {
-				*errors = append(*errors, fmt.Sprintf("failed to convert AST for %q: %v", filePath, err))
+				result.addError(fmt.Errorf("failed to convert AST for %q: %w", filePath, err))
 				continue // Continue with other files
 			}
 
-			// Merge using DeepMergeWithProvenance
-			data = DeepMergeWithProvenance(data, "", fileData, filePath, provenance)
+			// Merge in input order; later files win
+			if opts.DebugDump != nil {
+				opts.DebugDump.recordDataMerge(MergeKindFile, data, fileData, filePath, provenance)
+			}
+			mergeInto(data, fileData, filePath, provenance)
 		}
 
 		// Initialize providers from source declarations in all input files
 		if opts.ProviderTypeRegistry != nil {
 			// Convert ProviderTypeRegistry to core.ProviderTypeRegistry interface
 			// This works because ProviderTypeRegistry is an alias for core.ProviderTypeRegistry
-			if err := pipeline.InitializeProvidersFromSources(ctx, inputFiles, opts.ProviderRegistry, opts.ProviderTypeRegistry); err != nil {
-				*errors = append(*errors, fmt.Sprintf("failed to initialize providers: %v", err))
+			scopes, err := pipeline.InitializeProvidersFromSources(ctx, parsedFiles, opts.ProviderRegistry, opts.ProviderTypeRegistry, opts.ProviderConfigOverrides)
+			if err != nil {
+				result.addError(fmt.Errorf("failed to initialize providers: %w", err))
 				// Continue - some validation may still be useful
 			}
+			providerScopes = scopes
+			aliases = sourceAliases(parsedFiles)
 		}
 	}
 
+	if err := checkProviderConfigOverrides(opts.ProviderConfigOverrides, aliases); err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+
+	// Merge the selected profiles and drop the others before validation,
+	// so references in inactive profiles are never fetched
+	if err := applyProfiles(data, opts.Profiles, provenance); err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+
+	// Files resolved through imports were parsed there; parse them again
+	// for the dump
+	if opts.DebugDump != nil && parsedFiles == nil {
+		parsedFiles = pipeline.ParseFiles(ctx, inputFiles, opts.ParseConcurrency)
+		opts.DebugDump.addFiles(parsedFiles)
+	}
+
 	// Store the data and provenance
 	result.Snapshot.Data = data
 	result.Snapshot.Metadata.PerKeyProvenance = provenance
@@ -313,53 +589,132 @@ func Compile(ctx context.Context, opts Options) CompilationResult {
 		// Check if it's an unresolved reference error
 		var unresolvedErr *validator.ErrUnresolvedReference
 		if stderrors.As(err, &unresolvedErr) {
-			*errors = append(*errors, unresolvedErr.Error())
-			result.Snapshot.Metadata.EndTime = time.Now()
+			result.addError(unresolvedErr)
+			result.Snapshot.Metadata.EndTime = now()
 			return result
 		}
 
 		// Check if it's a cycle detection error
 		var cycleErr *validator.ErrCycleDetected
 		if stderrors.As(err, &cycleErr) {
-			*errors = append(*errors, cycleErr.Error())
-			result.Snapshot.Metadata.EndTime = time.Now()
+			result.addError(cycleErr)
+			result.Snapshot.Metadata.EndTime = now()
 			return result
 		}
 
 		// Unknown validation error
-		*errors = append(*errors, fmt.Sprintf("semantic validation failed: %v", err))
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(fmt.Errorf("semantic validation failed: %w", err))
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
 
 	// Resolve references in the data using the resolver
-	resolvedData, resolveErr := pipeline.ResolveReferences(ctx, data, pipeline.ResolveOptions{
+	var failedRefs []error
+	listOrders := &listOrderRecorder{}
+	resolveOpts := pipeline.ResolveOptions{
 		ProviderRegistry:     opts.ProviderRegistry,
-		AllowMissingProvider: opts.AllowMissingProvider,
-		OnWarning: func(warning string) {
-			*warnings = append(*warnings, warning)
+		AllowMissingProvider: opts.AllowMissingProvider || opts.PartialFailureMode == PartialFailureBestEffort,
+		FetchTimeout:         opts.Timeouts.PerProviderFetch,
+		Retry:                opts.FetchRetry,
+		MaxConcurrency:       opts.Timeouts.MaxConcurrentProviders,
+		Scopes:               providerScopes,
+		Limits:               opts.Limits,
+		OnWarning: func(warning diagnostic.Diagnostic) {
+			result.addWarning(warningFromDiagnostic(warning), warningFilter)
 		},
-	})
+		OnReference: func(e resolver.ReferenceEvent) {
+			if e.Err == nil {
+				result.references++
+			}
+			listOrders.record(e)
+			if opts.DebugDump != nil {
+				opts.DebugDump.recordReference(e)
+			}
+		},
+	}
+	if opts.PartialFailureMode == PartialFailureCollectAll {
+		resolveOpts.OnFailure = func(err error) { failedRefs = append(failedRefs, err) }
+	}
+	if opts.DebugDump != nil {
+		resolveOpts.OnMerge = opts.DebugDump.recordMerge
+	}
+	resolvedData, resolveErr := pipeline.ResolveReferences(ctx, data, resolveOpts)
+	for _, err := range failedRefs {
+		result.addError(fmt.Errorf("resolution failed: %w", err))
+	}
 	if resolveErr != nil {
-		result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors, fmt.Sprintf("resolution failed: %v", resolveErr))
-		result.Snapshot.Metadata.EndTime = time.Now()
+		result.addError(fmt.Errorf("resolution failed: %w", resolveErr))
+	}
+	if resolveErr != nil || len(failedRefs) > 0 {
+		result.Snapshot.Metadata.EndTime = now()
 		return result
 	}
+	result.Snapshot.Metadata.ListOrders = listOrders.sorted()
+	listOrders.compare(opts.PreviousListOrders, func(warning diagnostic.Diagnostic) {
+		result.addWarning(warningFromDiagnostic(warning), warningFilter)
+	})
+
+	// Fill in provider-published defaults beneath the resolved data
+	defaults := collectProviderDefaults(ctx, opts.ProviderRegistry, aliases)
+	resolvedData, defaulted := applyProviderDefaults(resolvedData, defaults, result.Snapshot.Metadata.PerKeyProvenance)
+
+	// Overlay caller-supplied values over the resolved data
+	if opts.DebugDump != nil {
+		opts.DebugDump.recordDataMerge(MergeKindOverride, resolvedData, opts.Overrides, OverrideSource, result.Snapshot.Metadata.PerKeyProvenance)
+	}
+	resolvedData = applyOverrides(resolvedData, opts.Overrides, result.Snapshot.Metadata.PerKeyProvenance)
+
+	// Coerce types before policies so they see the same values as the output
+	resolvedData = pipeline.CoerceTypes(resolvedData, coercionMode)
+
+	// Evaluate policies against the resolved, still-unencrypted data
+	if len(opts.Policies) > 0 {
+		if !evaluatePolicies(opts.Policies, resolvedData, &result, warningFilter) {
+			result.Snapshot.Metadata.EndTime = now()
+			return result
+		}
+	}
+
+	result.Snapshot.Metadata.SensitiveKeys = sensitiveKeys(resolvedData)
 
 	// Encrypt secrets if key is provided
 	if len(opts.EncryptionKey) > 0 {
 		encryptedData, encryptErr := pipeline.EncryptSecrets(resolvedData, opts.EncryptionKey)
 		if encryptErr != nil {
-			result.Snapshot.Metadata.Errors = append(result.Snapshot.Metadata.Errors, fmt.Sprintf("encryption failed: %v", encryptErr))
-			result.Snapshot.Metadata.EndTime = time.Now()
+			result.addError(fmt.Errorf("encryption failed: %w", encryptErr))
+			result.Snapshot.Metadata.EndTime = now()
 			return result
 		}
 		resolvedData = encryptedData
 	}
 
+	if err := opts.Limits.CheckValue(resolvedData, "resolved data"); err != nil {
+		result.addError(err)
+		result.Snapshot.Metadata.EndTime = now()
+		return result
+	}
+
+	if opts.MaxSnapshotBytes > 0 {
+		if err := checkSnapshotSize(resolvedData, opts.MaxSnapshotBytes); err != nil {
+			result.addError(err)
+			result.Snapshot.Metadata.EndTime = now()
+			return result
+		}
+	}
+
 	// Update with resolved (and potentially encrypted) data
 	result.Snapshot.Data = resolvedData
-	result.Snapshot.Metadata.EndTime = time.Now()
+
+	if opts.SourceMap {
+		sourceMap, err := buildSourceMap(ctx, inputFiles, parsedFiles, opts.Profiles, resolvedData)
+		if err != nil {
+			result.addError(fmt.Errorf("source map generation failed: %w", err))
+		}
+		markProviderDefaults(sourceMap, defaulted)
+		markOverrides(sourceMap, "", opts.Overrides)
+		result.Snapshot.SourceMap = sourceMap
+	}
+	result.Snapshot.Metadata.EndTime = now()
 
 	return result
 }
@@ -2,8 +2,12 @@ package compiler_test
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
 	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
@@ -90,6 +94,87 @@ func TestCompile_DeterministicDirectoryTraversal(t *testing.T) {
 	}
 }
 
+// TestCompile_ConcurrentParsingIsDeterministic verifies that later files
+// override earlier ones in lexicographic order regardless of how many files
+// are parsed in parallel.
+func TestCompile_ConcurrentParsingIsDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		content := fmt.Sprintf("app:\n  winner: 'f%02d'\n  f%02d: 'set'\n", i, i)
+		if err := writeFile(filepath.Join(tmpDir, fmt.Sprintf("f%02d.csl", i)), content); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	var want map[string]any
+	for _, workers := range []int{1, 0, 8, 64} {
+		result := compiler.Compile(context.Background(), compiler.Options{
+			Path:             tmpDir,
+			ProviderRegistry: testutil.NewFakeProviderRegistry(),
+			ParseConcurrency: workers,
+		})
+		if result.HasErrors() {
+			t.Fatalf("workers=%d: %v", workers, result.Error())
+		}
+
+		app := result.Snapshot.Data["app"].(map[string]any)
+		if app["winner"] != "f39" || len(app) != 41 {
+			t.Fatalf("workers=%d: winner = %v with %d keys, want f39 with 41", workers, app["winner"], len(app))
+		}
+		if want == nil {
+			want = result.Snapshot.Data
+		} else if !reflect.DeepEqual(result.Snapshot.Data, want) {
+			t.Errorf("workers=%d: data differs from serial parse", workers)
+		}
+	}
+}
+
+// TestCompile_ProjectRoot verifies that Options.ProjectRoot is recorded in
+// the metadata.
+func TestCompile_ProjectRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n"); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	for _, root := range []string{"", tmpDir} {
+		result := compiler.Compile(context.Background(), compiler.Options{
+			Path:             path,
+			ProviderRegistry: testutil.NewFakeProviderRegistry(),
+			ProjectRoot:      root,
+		})
+		if result.HasErrors() {
+			t.Fatalf("unexpected errors: %v", result.Error())
+		}
+		if got := result.Snapshot.Metadata.ProjectRoot; got != root {
+			t.Errorf("ProjectRoot = %q, want %q", got, root)
+		}
+	}
+}
+
+// TestCompile_Clock verifies that Options.Clock supplies the metadata
+// timestamps.
+func TestCompile_Clock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n"); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	fixed := time.Unix(1700000000, 0).UTC()
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: testutil.NewFakeProviderRegistry(),
+		Clock:            func() time.Time { return fixed },
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+	if got := result.Snapshot.Metadata; !got.StartTime.Equal(fixed) || !got.EndTime.Equal(fixed) {
+		t.Errorf("StartTime, EndTime = %v, %v, want %v", got.StartTime, got.EndTime, fixed)
+	}
+}
+
 // writeFile is a helper to write content to a file.
 func writeFile(path, content string) error {
 	file, err := os.Create(path) //nolint:gosec // G304: Path is from test temp directory
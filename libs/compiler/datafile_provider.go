@@ -0,0 +1,234 @@
+package compiler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/jsonpath"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DatafileProviderType is the built-in provider type that reads a JSON, YAML
+// or TOML file, optionally exposing only parts of it selected by JSONPath:
+//
+//	source:
+//	  alias: 'shared'
+//	  type: 'datafile'
+//	  path: '../shared/platform.yaml' # relative to the declaring .csl file
+//	  format: 'yaml'                  # optional; json, yaml or toml; default from the extension
+//	  select:                         # optional; name -> JSONPath expression
+//	    owner: '$.platform.owner'
+//	    regions: '$.platform.regions[*].name'
+//	    prod_db: "$.environments[?(@.name == 'prod')].database"
+//
+// Without select, references navigate the whole document
+// (@shared:platform.owner). With it, only the selections are exposed, each
+// under its name (@shared:prod_db.host). A definite expression (only member
+// names and indexes) selects a single value and must match; any other selects
+// the list of its matches.
+const DatafileProviderType = "datafile"
+
+// datafileFormats maps file extensions to formats.
+var datafileFormats = map[string]string{
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+}
+
+// datafileProvider implements core.Provider over a data file.
+type datafileProvider struct {
+	path       string
+	format     string
+	selections map[string]*jsonpath.Path
+
+	data map[string]any
+}
+
+// newDatafileProvider validates the source configuration, including the
+// JSONPath expressions; the file is read in Init.
+func newDatafileProvider(config map[string]any) (core.Provider, error) {
+	p := &datafileProvider{}
+	for key, value := range config {
+		if key == "select" {
+			selections, ok := value.(map[string]any)
+			if !ok {
+				return nil, errors.New("datafile config 'select' must be a map of names to JSONPath expressions")
+			}
+			p.selections = make(map[string]*jsonpath.Path, len(selections))
+			for name, expr := range selections {
+				s, ok := expr.(string)
+				if !ok {
+					return nil, fmt.Errorf("datafile selection %q must be a JSONPath string", name)
+				}
+				path, err := jsonpath.Parse(s)
+				if err != nil {
+					return nil, fmt.Errorf("datafile selection %q: %w", name, err)
+				}
+				p.selections[name] = path
+			}
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("datafile config %q must be a string", key)
+		}
+		switch key {
+		case "path":
+			p.path = s
+		case "format":
+			if s != "json" && s != "yaml" && s != "toml" {
+				return nil, fmt.Errorf("datafile config 'format' must be json, yaml, or toml (got %q)", s)
+			}
+			p.format = s
+		default:
+			return nil, fmt.Errorf("unknown datafile config %q (supported: path, format, select)", key)
+		}
+	}
+	if p.path == "" {
+		return nil, errors.New("datafile config requires 'path'")
+	}
+	if p.format == "" {
+		p.format = datafileFormats[strings.ToLower(filepath.Ext(p.path))]
+		if p.format == "" {
+			return nil, fmt.Errorf("datafile config 'format' is required for %q (json, yaml, or toml)", p.path)
+		}
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. It reads and decodes the file and evaluates
+// the selections once; references are then served from memory.
+func (p *datafileProvider) Init(_ context.Context, opts core.ProviderInitOptions) error {
+	path := p.path
+	if !filepath.IsAbs(path) && opts.SourceFilePath != "" {
+		path = filepath.Join(filepath.Dir(opts.SourceFilePath), path)
+	}
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Path is declared in the source file
+	if err != nil {
+		return fmt.Errorf("failed to read data file: %w", err)
+	}
+	doc, err := decodeDatafile(content, p.format)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s file %s: %w", p.format, path, err)
+	}
+
+	if p.selections == nil {
+		data, ok := doc.(map[string]any)
+		if !ok {
+			return fmt.Errorf("data file %s is not a map at the top level; use 'select' to expose parts of it", path)
+		}
+		p.data = data
+		return nil
+	}
+
+	p.data = make(map[string]any, len(p.selections))
+	for _, name := range sortedSelectionNames(p.selections) {
+		selection := p.selections[name]
+		matches := selection.Select(doc)
+		if !selection.Definite() {
+			if matches == nil {
+				matches = []any{}
+			}
+			p.data[name] = matches
+			continue
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("datafile selection %q: %s matches nothing in %s", name, selection, path)
+		}
+		p.data[name] = matches[0]
+	}
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *datafileProvider) Fetch(_ context.Context, path []string) (any, error) {
+	kind := "key"
+	if p.selections != nil {
+		kind = "selection"
+	}
+	return lookupPath(p.data, path, kind)
+}
+
+// decodeDatafile decodes content and normalizes it to JSON values, so that
+// every format selects and compares the same way.
+func decodeDatafile(content []byte, format string) (any, error) {
+	var doc any
+	var err error
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		err = decoder.Decode(&doc)
+		if err == nil && decoder.More() {
+			err = errors.New("unexpected data after the top-level value")
+		}
+	case "yaml":
+		err = yaml.Unmarshal(content, &doc)
+	case "toml":
+		err = toml.Unmarshal(content, &doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return normalizeDatafileValue(doc), nil
+}
+
+// normalizeDatafileValue converts decoded YAML and TOML values to their JSON
+// equivalents: maps with string keys, float64 numbers, and timestamps as
+// RFC 3339 strings.
+func normalizeDatafileValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeDatafileValue(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalizeDatafileValue(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeDatafileValue(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case nil, string, float64, bool:
+		return v
+	case fmt.Stringer:
+		// TOML local dates and times
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sortedSelectionNames returns the names of selections in order.
+func sortedSelectionNames(selections map[string]*jsonpath.Path) []string {
+	names := make([]string, 0, len(selections))
+	for name := range selections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
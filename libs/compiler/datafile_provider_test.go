@@ -0,0 +1,111 @@
+package compiler_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+const testPlatformYAML = `platform:
+  owner: infra
+  regions:
+    - name: eu-west-1
+      zones: 3
+    - name: us-east-1
+      zones: 6
+environments:
+  - name: dev
+    database: {host: dev-db}
+  - name: prod
+    database: {host: prod-db, port: 5432}
+`
+
+const testPlatformTOML = `[platform]
+owner = "infra"
+released = 2024-03-01
+
+[[platform.regions]]
+name = "eu-west-1"
+zones = 3
+`
+
+// TestCompile_DatafileProvider verifies that the built-in datafile provider
+// exposes JSON, YAML and TOML documents and JSONPath selections of them.
+func TestCompile_DatafileProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "shared"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"shared/platform.yaml": testPlatformYAML,
+		"shared/platform.toml": testPlatformTOML,
+		"shared/platform.json": `{"platform": {"owner": "infra", "tags": ["a", "b"]}}`,
+		"shared/regions.json":  `[{"name": "eu-west-1"}]`,
+		"shared/platform.conf": `{"platform": {"owner": "infra"}}`,
+	} {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		ref     string
+		want    any
+		wantErr string
+	}{
+		{name: "yaml document", config: "path: './shared/platform.yaml'", ref: "@shared:platform.owner", want: "infra"},
+		{name: "toml document", config: "path: './shared/platform.toml'", ref: "@shared:platform.released", want: "2024-03-01"},
+		{name: "json list", config: "path: './shared/platform.json'", ref: "@shared:platform.tags", want: []any{"a", "b"}},
+		{name: "explicit format", config: "path: './shared/platform.conf'\n  format: 'json'", ref: "@shared:platform.owner", want: "infra"},
+		{name: "definite selection", config: "path: './shared/platform.yaml'\n  select:\n    owner: '$.platform.owner'", ref: "@shared:owner", want: "infra"},
+		{name: "wildcard selection", config: "path: './shared/platform.yaml'\n  select:\n    regions: '$.platform.regions[*].name'", ref: "@shared:regions", want: []any{"eu-west-1", "us-east-1"}},
+		{name: "filter selection", config: "path: './shared/platform.yaml'\n  select:\n    prod: \"$.environments[?(@.name == 'prod')].database.port\"", ref: "@shared:prod", want: []any{5432.0}},
+		{name: "navigate into selection", config: "path: './shared/platform.yaml'\n  select:\n    prod_db: '$.environments[1].database'", ref: "@shared:prod_db.host", want: "prod-db"},
+		{name: "toml selection", config: "path: './shared/platform.toml'\n  select:\n    zones: '$..zones'", ref: "@shared:zones", want: []any{3.0}},
+		{name: "list document with selection", config: "path: './shared/regions.json'\n  select:\n    first: '$[0].name'", ref: "@shared:first", want: "eu-west-1"},
+		{name: "list document", config: "path: './shared/regions.json'", ref: "@shared:name", wantErr: "not a map at the top level"},
+		{name: "only selections exposed", config: "path: './shared/platform.yaml'\n  select:\n    owner: '$.platform.owner'", ref: "@shared:platform", wantErr: `selection "platform" not found`},
+		{name: "definite selection without match", config: "path: './shared/platform.yaml'\n  select:\n    owner: '$.platform.team'", ref: "@shared:owner", wantErr: "matches nothing"},
+		{name: "invalid expression", config: "path: './shared/platform.yaml'\n  select:\n    owner: 'platform.owner'", ref: "@shared:owner", wantErr: "must start with '$'"},
+		{name: "unknown extension", config: "path: './shared/platform.conf'", ref: "@shared:platform", wantErr: "'format' is required"},
+		{name: "missing file", config: "path: './shared/missing.yaml'", ref: "@shared:platform", wantErr: "failed to read data file"},
+		{name: "unknown config", config: "path: './shared/platform.yaml'\n  query: 'x'", ref: "@shared:platform", wantErr: `unknown datafile config "query"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "app.csl")
+			src := "source:\n  alias: 'shared'\n  type: 'datafile'\n  " + tt.config + "\n\nplatform:\n  value: " + tt.ref + "\n"
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+			want := map[string]any{"value": tt.want}
+			if got := result.Snapshot.Data["platform"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("platform = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
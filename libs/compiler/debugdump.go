@@ -0,0 +1,334 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/resolver"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// DebugDumpVersion identifies the format of debug dump directories.
+const DebugDumpVersion = 1
+
+// debugDumpRedacted replaces secret values in a debug dump.
+const debugDumpRedacted = "<redacted>"
+
+// MergeKind identifies what set a key path a second time.
+type MergeKind string
+
+const (
+	// MergeKindFile is a later input file setting a key an earlier file set.
+	MergeKindFile MergeKind = "file"
+
+	// MergeKindSpread is a spread reference setting a key an earlier entry
+	// of the same map set.
+	MergeKindSpread MergeKind = "spread"
+
+	// MergeKindKey is a key repeated after a spread set it.
+	MergeKindKey MergeKind = "key"
+
+	// MergeKindOverride is Options.Overrides setting a resolved key.
+	MergeKindOverride MergeKind = "override"
+)
+
+// MergeDecision records a key path set more than once and how the values
+// were combined: "merge" when both were maps and merged key by key, or
+// "replace" when the later value replaced the earlier one.
+type MergeDecision struct {
+	Path   string    `json:"path"`
+	Kind   MergeKind `json:"kind"`
+	Action string    `json:"action"`
+
+	// Source is the file of the later value, or the location of the spread
+	// reference as file:line:col.
+	Source string `json:"source,omitempty"`
+
+	// Previous is the file of the earlier value, when known.
+	Previous string `json:"previous,omitempty"`
+}
+
+// DebugReference records one reference lookup during resolution.
+type DebugReference struct {
+	// Reference is the reference as written, such as "@configs:app.port".
+	Reference string `json:"reference"`
+
+	// Location is where the reference appears, as file:line:col.
+	Location string `json:"location"`
+
+	// Path is the key path holding the reference; empty for a root spread.
+	Path string `json:"path,omitempty"`
+
+	// Provider is the registry key of the provider that served it, which is
+	// alias@version for aliases declared at several versions.
+	Provider string `json:"provider"`
+
+	// Value is the resolved value with secrets redacted.
+	Value  any    `json:"value,omitempty"`
+	Cached bool   `json:"cached,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DebugDump collects the intermediate state of a compilation for bug
+// reports: the parsed AST of each input file, every reference looked up
+// with the provider that served it, and each merge decision where a key
+// path was set more than once. Pass one in Options.DebugDump and write it
+// with Save after Compile returns, whether or not compilation succeeded.
+//
+// Resolved values are kept in plain text except secrets, which are
+// redacted. It is safe for concurrent use.
+type DebugDump struct {
+	mu         sync.Mutex
+	files      []debugFile
+	references []DebugReference
+
+	// Merge decisions by compilation step: input files merge before
+	// resolution, overrides after it
+	fileMerges     []MergeDecision
+	resolveMerges  []MergeDecision
+	overrideMerges []MergeDecision
+}
+
+// debugFile is the parse result of one input file.
+type debugFile struct {
+	path string
+	tree *ast.AST
+	err  error
+}
+
+// debugManifest describes the contents of a debug dump directory.
+type debugManifest struct {
+	Version    int                 `json:"version"`
+	InputFiles []debugManifestFile `json:"input_files"`
+	References int                 `json:"references"`
+	Merges     int                 `json:"merges"`
+}
+
+// debugManifestFile names the AST dump of one input file, or the reason
+// there is none.
+type debugManifestFile struct {
+	Path  string `json:"path"`
+	AST   string `json:"ast,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewDebugDump returns an empty debug dump.
+func NewDebugDump() *DebugDump {
+	return &DebugDump{}
+}
+
+// References returns the recorded reference lookups, sorted by location
+// and key path.
+func (d *DebugDump) References() []DebugReference {
+	d.mu.Lock()
+	refs := append([]DebugReference{}, d.references...)
+	d.mu.Unlock()
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Location != refs[j].Location {
+			return refs[i].Location < refs[j].Location
+		}
+		return refs[i].Path < refs[j].Path
+	})
+	return refs
+}
+
+// Merges returns the recorded merge decisions in the order of the
+// compilation steps that made them: input files in input order, then
+// spreads and repeated keys during resolution sorted by key path, then
+// overrides.
+func (d *DebugDump) Merges() []MergeDecision {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resolved := append([]MergeDecision{}, d.resolveMerges...)
+	sort.SliceStable(resolved, func(i, j int) bool { return resolved[i].Path < resolved[j].Path })
+
+	merges := make([]MergeDecision, 0, len(d.fileMerges)+len(resolved)+len(d.overrideMerges))
+	merges = append(merges, d.fileMerges...)
+	merges = append(merges, resolved...)
+	return append(merges, d.overrideMerges...)
+}
+
+// Save writes the dump to dir, creating it if needed:
+//
+//	manifest.json    format version, input files, and counts
+//	ast/NNN-<name>.json  parsed AST of each input file, in input order
+//	references.json  reference lookups, see DebugReference
+//	merges.json      merge decisions, see MergeDecision
+//
+// Files are readable by the owner only, since they may hold private data.
+func (d *DebugDump) Save(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "ast"), 0750); err != nil {
+		return fmt.Errorf("cannot create debug dump directory: %w", err)
+	}
+
+	d.mu.Lock()
+	files := append([]debugFile{}, d.files...)
+	d.mu.Unlock()
+	references := d.References()
+	merges := d.Merges()
+
+	manifest := debugManifest{
+		Version:    DebugDumpVersion,
+		InputFiles: make([]debugManifestFile, 0, len(files)),
+		References: len(references),
+		Merges:     len(merges),
+	}
+	for i, f := range files {
+		entry := debugManifestFile{Path: f.path}
+		switch {
+		case f.err != nil:
+			entry.Error = f.err.Error()
+		case f.tree == nil:
+			entry.Error = "file has parse errors"
+		default:
+			entry.AST = filepath.ToSlash(filepath.Join("ast", fmt.Sprintf("%03d-%s.json", i+1, filepath.Base(f.path))))
+			if err := writeDebugJSON(filepath.Join(dir, entry.AST), f.tree); err != nil {
+				return err
+			}
+		}
+		manifest.InputFiles = append(manifest.InputFiles, entry)
+	}
+
+	if err := writeDebugJSON(filepath.Join(dir, "references.json"), references); err != nil {
+		return err
+	}
+	if err := writeDebugJSON(filepath.Join(dir, "merges.json"), merges); err != nil {
+		return err
+	}
+	return writeDebugJSON(filepath.Join(dir, "manifest.json"), manifest)
+}
+
+// writeDebugJSON writes v as indented JSON to path.
+func writeDebugJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode debug dump %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("cannot write debug dump: %w", err)
+	}
+	return nil
+}
+
+// addFiles records the parse results of the input files.
+func (d *DebugDump) addFiles(parsed []pipeline.ParsedFile) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range parsed {
+		d.files = append(d.files, debugFile{path: f.Path, tree: f.AST, err: f.Err})
+	}
+}
+
+// recordReference records a reference lookup reported by the resolver.
+func (d *DebugDump) recordReference(e resolver.ReferenceEvent) {
+	ref := DebugReference{
+		Reference: "@" + e.Ref.Alias + ":" + strings.Join(e.Ref.Path, "."),
+		Location:  spanLocation(e.Ref.SourceSpan),
+		Path:      e.Path,
+		Provider:  e.Provider,
+		Value:     debugValue(e.Value.Any()),
+		Cached:    e.Cached,
+	}
+	if e.Err != nil {
+		ref.Error = e.Err.Error()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.references = append(d.references, ref)
+}
+
+// recordMerge records a spread or repeated key reported by the resolver.
+func (d *DebugDump) recordMerge(e resolver.MergeEvent) {
+	decision := MergeDecision{Path: e.Path, Kind: MergeKindKey, Action: mergeAction(e.Merged)}
+	if e.Ref != nil {
+		decision.Kind = MergeKindSpread
+		decision.Source = spanLocation(e.Ref.SourceSpan)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resolveMerges = append(d.resolveMerges, decision)
+}
+
+// recordDataMerge records the keys of src that are already set in dst,
+// before src is merged over dst. provenance gives the source of each
+// top-level key of dst.
+func (d *DebugDump) recordDataMerge(kind MergeKind, dst, src map[string]any, source string, provenance map[string]Provenance) {
+	var decisions []MergeDecision
+	for _, k := range sortedKeys(src) {
+		if prev, ok := dst[k]; ok {
+			decisions = appendDataMerges(decisions, kind, k, prev, src[k], source, provenance[k].Source)
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if kind == MergeKindOverride {
+		d.overrideMerges = append(d.overrideMerges, decisions...)
+		return
+	}
+	d.fileMerges = append(d.fileMerges, decisions...)
+}
+
+// appendDataMerges appends the decision for path, where src is merged over
+// prev, and for each nested key both maps set.
+func appendDataMerges(decisions []MergeDecision, kind MergeKind, path string, prev, src any, source, previous string) []MergeDecision {
+	prevMap, prevIsMap := prev.(map[string]any)
+	srcMap, srcIsMap := src.(map[string]any)
+	decisions = append(decisions, MergeDecision{
+		Path: path, Kind: kind, Action: mergeAction(prevIsMap && srcIsMap), Source: source, Previous: previous,
+	})
+	if !prevIsMap || !srcIsMap {
+		return decisions
+	}
+	for _, k := range sortedKeys(srcMap) {
+		if k == converter.OrderedEntriesKey {
+			continue
+		}
+		if child, ok := prevMap[k]; ok {
+			decisions = appendDataMerges(decisions, kind, joinKeyPath(path, k), child, srcMap[k], source, previous)
+		}
+	}
+	return decisions
+}
+
+// mergeAction names how two values for one key path were combined.
+func mergeAction(merged bool) string {
+	if merged {
+		return "merge"
+	}
+	return "replace"
+}
+
+// spanLocation formats the start of a source span as file:line:col.
+func spanLocation(span ast.SourceSpan) string {
+	return fmt.Sprintf("%s:%d:%d", span.Filename, span.StartLine, span.StartCol)
+}
+
+// debugValue copies a resolved value for a debug dump, redacting secrets.
+func debugValue(v any) any {
+	switch val := v.(type) {
+	case models.Secret:
+		return debugDumpRedacted
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = debugValue(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = debugValue(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
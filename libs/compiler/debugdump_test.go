@@ -0,0 +1,151 @@
+package compiler_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// TestCompile_DebugDump verifies that a debug dump records the parsed ASTs,
+// every reference lookup, and the merge decisions of a compilation.
+func TestCompile_DebugDump(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a-base.csl": "app:\n  @defaults:app.*\n  port: '9090'\n  name: 'base'\n\n" +
+			"mirror:\n  host: 'mine'\n  @defaults:app.*\n\n" +
+			"db:\n  host: @defaults:db.host\n",
+		"b-team.csl": "db:\n  host: 'db.internal'\n  port: '5432'\n",
+	} {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := compiler.NewProviderRegistry()
+	registry.Register("defaults", func(compiler.ProviderInitOptions) (compiler.Provider, error) {
+		return &nameProvider{name: map[string]any{"port": 8080, "host": "localhost"}}, nil
+	})
+
+	dump := compiler.NewDebugDump()
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             dir,
+		ProviderRegistry: registry,
+		Overrides:        map[string]any{"app": map[string]any{"port": "1"}},
+		DebugDump:        dump,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	base := filepath.Join(dir, "a-base.csl")
+	team := filepath.Join(dir, "b-team.csl")
+	refs := dump.References()
+	// The db.host reference of the first file is replaced by the second
+	// file before resolution, so only the two spreads are looked up
+	if len(refs) != 2 {
+		t.Fatalf("References() = %+v, want the two spreads", refs)
+	}
+	value := map[string]any{"port": 8080, "host": "localhost"}
+	for i, want := range []compiler.DebugReference{
+		{Reference: "@defaults:app.*", Location: base + ":2:3", Path: "app", Provider: "defaults", Value: value},
+		{Reference: "@defaults:app.*", Location: base + ":8:3", Path: "mirror", Provider: "defaults", Value: value},
+	} {
+		got := refs[i]
+		got.Cached = false
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("References()[%d] = %+v, want %+v", i, refs[i], want)
+		}
+	}
+	if refs[0].Cached == refs[1].Cached {
+		t.Errorf("want exactly one cached lookup, got %+v", refs)
+	}
+
+	wantMerges := []compiler.MergeDecision{
+		{Path: "db", Kind: compiler.MergeKindFile, Action: "merge", Source: team, Previous: base},
+		{Path: "db.host", Kind: compiler.MergeKindFile, Action: "replace", Source: team, Previous: base},
+		{Path: "app.port", Kind: compiler.MergeKindKey, Action: "replace"},
+		{Path: "mirror.host", Kind: compiler.MergeKindSpread, Action: "replace", Source: base + ":8:3"},
+		{Path: "app", Kind: compiler.MergeKindOverride, Action: "merge", Source: compiler.OverrideSource, Previous: base},
+		{Path: "app.port", Kind: compiler.MergeKindOverride, Action: "replace", Source: compiler.OverrideSource, Previous: base},
+	}
+	if got := dump.Merges(); !reflect.DeepEqual(got, wantMerges) {
+		t.Errorf("Merges() =\n%+v\nwant\n%+v", got, wantMerges)
+	}
+
+	out := filepath.Join(t.TempDir(), "dump")
+	if err := dump.Save(out); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	var manifest struct {
+		Version    int `json:"version"`
+		InputFiles []struct {
+			Path string `json:"path"`
+			AST  string `json:"ast"`
+		} `json:"input_files"`
+		References int `json:"references"`
+		Merges     int `json:"merges"`
+	}
+	readJSON(t, filepath.Join(out, "manifest.json"), &manifest)
+	if manifest.Version != compiler.DebugDumpVersion || len(manifest.InputFiles) != 2 || manifest.References != 2 || manifest.Merges != len(wantMerges) {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if manifest.InputFiles[1].AST != "ast/002-b-team.csl.json" {
+		t.Errorf("second AST file = %q", manifest.InputFiles[1].AST)
+	}
+
+	var tree struct {
+		Statements []map[string]any `json:"statements"`
+	}
+	readJSON(t, filepath.Join(out, manifest.InputFiles[0].AST), &tree)
+	if len(tree.Statements) != 3 {
+		t.Errorf("AST of a-base.csl has %d statements, want 3", len(tree.Statements))
+	}
+	var merges []compiler.MergeDecision
+	readJSON(t, filepath.Join(out, "merges.json"), &merges)
+	if !reflect.DeepEqual(merges, wantMerges) {
+		t.Errorf("merges.json = %+v", merges)
+	}
+}
+
+// TestDebugDump_RedactsSecrets verifies resolved secrets are not written to
+// a debug dump.
+func TestDebugDump_RedactsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.csl")
+	if err := writeFile(path, "db:\n  password: @var:password\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	dump := compiler.NewDebugDump()
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: compiler.NewProviderRegistry(),
+		Vars:             map[string]any{"password": models.Secret{Value: "hunter2"}},
+		DebugDump:        dump,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	refs := dump.References()
+	if len(refs) != 1 || refs[0].Value != "<redacted>" || refs[0].Path != "db.password" {
+		t.Errorf("References() = %+v, want the redacted password", refs)
+	}
+}
+
+// readJSON decodes the JSON file at path into v.
+func readJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Test reads its own output
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
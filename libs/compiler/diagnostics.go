@@ -0,0 +1,131 @@
+package compiler
+
+import (
+	stderrors "errors"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/validator"
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// Severity values of a Diagnostic.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a compilation error or warning with its source location,
+// for tools that present diagnostics in their own format, such as CI
+// annotations.
+type Diagnostic struct {
+	// Severity is SeverityError or SeverityWarning.
+	Severity string `json:"severity"`
+
+	// Code identifies the warning class, if any. Errors have no code.
+	Code WarningCode `json:"code,omitempty"`
+
+	// Message describes the problem. It repeats the location only when the
+	// location is not known separately.
+	Message string `json:"message"`
+
+	// File, Line and Column locate the diagnostic in source, when known.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	// EndLine and EndColumn locate the last character of the offending
+	// source, when the compiler knows its extent.
+	EndLine   int `json:"end_line,omitempty"`
+	EndColumn int `json:"end_column,omitempty"`
+}
+
+// Diagnostics returns the errors of the compilation followed by its
+// warnings, each located in source where the compiler knows the location.
+// Errors and Warnings hold the same diagnostics as formatted strings.
+func (r CompilationResult) Diagnostics() []Diagnostic {
+	metadata := r.Snapshot.Metadata
+	diags := make([]Diagnostic, 0, len(metadata.Errors)+len(metadata.Warnings))
+
+	for i, msg := range metadata.Errors {
+		d := Diagnostic{Severity: SeverityError, Message: msg}
+		if len(r.errs) == len(metadata.Errors) {
+			d = errorDiagnostic(r.errs[i])
+		}
+		diags = append(diags, d)
+	}
+
+	if len(metadata.WarningDetails) == len(metadata.Warnings) {
+		for _, w := range metadata.WarningDetails {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityWarning,
+				Code:     w.Code,
+				Message:  w.Message,
+				File:     w.File,
+				Line:     w.Line,
+				Column:   w.Column,
+			})
+		}
+	} else {
+		// Metadata was populated without details (e.g. constructed by hand)
+		for _, msg := range metadata.Warnings {
+			diags = append(diags, Diagnostic{Severity: SeverityWarning, Message: msg})
+		}
+	}
+	return diags
+}
+
+// errorDiagnostic locates err using the first error in its chain that
+// carries a source location.
+func errorDiagnostic(err error) Diagnostic {
+	d := Diagnostic{Severity: SeverityError, Message: err.Error()}
+
+	var located *diagnostic.LocatedError
+	var diag *diagnostic.Diagnostic
+	var parseErr *parser.ParseError
+	var refErr *ReferenceError
+	var fnErr *FunctionError
+	var permErr *ProviderPermissionError
+	var unresolvedErr *validator.ErrUnresolvedReference
+	var cycleErr *validator.ErrCycleDetected
+	switch {
+	case stderrors.As(err, &located):
+		diag = &located.Diagnostic
+		fallthrough
+	case stderrors.As(err, &diag):
+		d.setSpan(diag.SourceSpan)
+		d.Message = diag.Message
+	case stderrors.As(err, &parseErr):
+		d.File, d.Line, d.Column = parseErr.Filename(), parseErr.Line(), parseErr.Column()
+		d.Message = parseErr.Message()
+	case stderrors.As(err, &refErr):
+		d.File, d.Line, d.Column = refErr.Filename, refErr.Line, refErr.Column
+	case stderrors.As(err, &fnErr):
+		d.File, d.Line, d.Column = fnErr.Filename, fnErr.Line, fnErr.Column
+	case stderrors.As(err, &permErr):
+		d.File, d.Line, d.Column = permErr.Filename, permErr.Line, permErr.Column
+	case stderrors.As(err, &unresolvedErr):
+		d.setSpan(unresolvedErr.SourceSpan)
+	case stderrors.As(err, &cycleErr) && len(cycleErr.Chain) > 0:
+		d.setSpan(cycleErr.Chain[0].SourceSpan)
+	}
+	if d.File == "" {
+		d.Line, d.Column, d.EndLine, d.EndColumn = 0, 0, 0, 0
+	}
+
+	// Formatted messages may carry a source snippet; the location replaces it
+	if d.File != "" {
+		d.Message, _, _ = strings.Cut(d.Message, "\n")
+	}
+	return d
+}
+
+// setSpan locates d at span. The end is kept only when it does not precede
+// the start, since some spans record just their starting position.
+func (d *Diagnostic) setSpan(span ast.SourceSpan) {
+	d.File, d.Line, d.Column = span.Filename, span.StartLine, span.StartCol
+	if span.EndLine > span.StartLine || (span.EndLine == span.StartLine && span.EndCol >= span.StartCol) {
+		d.EndLine, d.EndColumn = span.EndLine, span.EndCol
+	}
+}
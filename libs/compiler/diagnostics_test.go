@@ -0,0 +1,71 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCompilationResult_Diagnostics verifies that errors and warnings are
+// reported with their source locations.
+func TestCompilationResult_Diagnostics(t *testing.T) {
+	compile := func(t *testing.T, source string, allowMissing bool) compiler.CompilationResult {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "app.csl")
+		if err := writeFile(path, source); err != nil {
+			t.Fatalf("failed to write source: %v", err)
+		}
+		registry := compiler.NewProviderRegistry()
+		registry.Register("broken", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+			return nil, errors.New("binary missing")
+		})
+		return compiler.Compile(context.Background(), compiler.Options{
+			Path:                 path,
+			ProviderRegistry:     registry,
+			AllowMissingProvider: allowMissing,
+		})
+	}
+
+	t.Run("warning", func(t *testing.T) {
+		result := compile(t, "app: 'demo'\nvalue: @broken:key\n", true)
+		diags := result.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("Diagnostics() = %v, want one warning", diags)
+		}
+		d := diags[0]
+		if d.Severity != compiler.SeverityWarning || d.Code != compiler.WarnMissingProvider || d.Line != 2 || !strings.HasSuffix(d.File, "app.csl") {
+			t.Errorf("Diagnostics()[0] = %+v, want W001 at app.csl:2", d)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		result := compile(t, "app: 'demo'\nbad: 'unterminated\n", true)
+		diags := result.Diagnostics()
+		if len(diags) == 0 || len(diags) != len(result.Errors()) {
+			t.Fatalf("Diagnostics() = %v, want one per error %v", diags, result.Errors())
+		}
+		d := diags[0]
+		if d.Severity != compiler.SeverityError || d.Line != 2 || !strings.HasSuffix(d.File, "app.csl") {
+			t.Errorf("Diagnostics()[0] = %+v, want an error at app.csl:2", d)
+		}
+		if strings.Contains(d.Message, "\n") || strings.Contains(d.Message, "app.csl") {
+			t.Errorf("Message %q repeats the location", d.Message)
+		}
+	})
+
+	t.Run("reference extent", func(t *testing.T) {
+		result := compile(t, "app: 'demo'\nvalue: @nowhere:key\n", false)
+		diags := result.Diagnostics()
+		if len(diags) != 1 {
+			t.Fatalf("Diagnostics() = %v, want one error", diags)
+		}
+		d := diags[0]
+		if d.Line != 2 || d.Column != 8 || d.EndLine != 2 || d.EndColumn != 19 {
+			t.Errorf("Diagnostics()[0] = %+v, want the span of @nowhere:key (2:8-2:19)", d)
+		}
+	})
+}
@@ -13,8 +13,8 @@
 //
 // # Architecture
 //
-// The Manager follows a per-alias process model: one subprocess per provider alias
-// per compilation run. Processes are started lazily on first use and cached for
+// The Manager follows a per-alias process model: one subprocess per provider alias,
+// binary, and config. Processes are started lazily on first use and cached for
 // subsequent calls. All processes are gracefully terminated when Shutdown is called.
 //
 // # Usage Example
@@ -54,12 +54,38 @@
 //
 // Errors are surfaced at multiple levels:
 //   - Binary not found: Immediate error with remediation
-//   - Connection failures: Retry with exponential backoff (future enhancement)
+//   - Transient fetch failures: Retried with exponential backoff per Options.FetchRetry
 //   - RPC failures: Delegate to gRPC error codes with context
 //   - Subprocess crashes: Captured stderr included in error messages
 //
 // # Thread Safety
 //
-// The Manager is safe for concurrent use. Multiple goroutines can call GetProvider
-// simultaneously; the Manager ensures only one subprocess is started per alias.
+// Compile is safe to call from multiple goroutines, including compilations
+// that share a ProviderRegistry, a ProviderTypeRegistry, or a Manager. Each
+// compilation keeps its own parse results, reference cache, and Vars; what
+// the shared values hold is shared as follows:
+//
+//   - ProviderRegistry: the registry is one alias namespace. The registry of
+//     NewProviderRegistry creates the provider of each source alias once,
+//     however many compilations declare it concurrently, and later
+//     compilations reuse it even if they declare the alias with another
+//     config. Use one registry per compilation for tenants whose
+//     declarations differ; custom registries get this guarantee by
+//     implementing SourceProviderRegistry.
+//   - ProviderTypeRegistry: the registries of NewProviderTypeRegistry and its
+//     variants share one subprocess among aliases with identical binary and
+//     config, across compilations. The process is initialized once, and
+//     compilations sharing it wait until it is.
+//   - Manager: GetProvider starts one subprocess per alias, binary, and
+//     config, so aliases with different configs never share a process. The
+//     context passed to GetProvider bounds the startup only; processes run
+//     until Shutdown, which must not be called while compilations use them.
+//
+// Providers themselves must be safe for concurrent Fetch calls once
+// initialized, since one compilation already fetches in parallel.
+//
+// A Session bundles a Manager, a ProviderTypeRegistry, and base options for
+// one tenant or workspace, gives each of its compilations a new
+// ProviderRegistry, and shuts its providers down on Close independently of
+// other sessions.
 package compiler
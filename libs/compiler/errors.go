@@ -0,0 +1,215 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/parser"
+)
+
+// Sentinel errors for compilation failures.
+//
+// Errors returned by CompilationResult.Error and by the resolution helpers
+// wrap these values, so callers can classify failures with errors.Is instead
+// of matching on message text:
+//
+//	if errors.Is(result.Error(), compiler.ErrCircularReference) {
+//	    // report the cycle
+//	}
+var (
+	// ErrCircularReference indicates a cycle was detected in the resolution chain.
+	// Use errors.As with *CycleError to inspect the chain.
+	ErrCircularReference = core.ErrCircularReference
+
+	// ErrUnknownAlias indicates a reference names a provider alias that is not configured.
+	ErrUnknownAlias = core.ErrUnknownAlias
+
+	// ErrPropertyPathInvalid indicates a property path does not exist in the data.
+	ErrPropertyPathInvalid = core.ErrPropertyPathInvalid
+
+	// ErrProviderUnavailable indicates a provider is configured but could not be
+	// constructed, initialized, or reached.
+	ErrProviderUnavailable = core.ErrProviderUnavailable
+
+	// ErrTimeout indicates a provider call or other operation exceeded its deadline.
+	ErrTimeout = core.ErrTimeout
+
+	// ErrUnresolvedReference indicates a reference could not be resolved.
+	ErrUnresolvedReference = core.ErrUnresolvedReference
+
+	// ErrProviderNotRegistered indicates a provider alias is not registered.
+	ErrProviderNotRegistered = core.ErrProviderNotRegistered
+
+	// ErrFunctionCall indicates a built-in function call (fn:name(...)) failed.
+	// Use errors.As with *FunctionError for details.
+	ErrFunctionCall = core.ErrFunctionCall
+
+	// ErrProviderChecksumMismatch indicates a provider binary does not have
+	// the checksum its source declaration pins, so it was not started.
+	ErrProviderChecksumMismatch = core.ErrProviderChecksumMismatch
+
+	// ErrLimitExceeded indicates an input file, provider value, or the
+	// resolved data exceeds Options.Limits. Use errors.As with *LimitError
+	// for details.
+	ErrLimitExceeded = core.ErrLimitExceeded
+
+	// ErrProviderMessageTooLarge indicates a provider response exceeds
+	// Options.MaxProviderMessageBytes. Use errors.As with *MessageSizeError
+	// for the alias, path, and sizes.
+	ErrProviderMessageTooLarge = core.ErrProviderMessageTooLarge
+
+	// ErrCycleDetected indicates a cycle was detected in imports or references.
+	ErrCycleDetected = errors.New("cycle detected")
+
+	// ErrPathNotFound indicates a provider cannot resolve the path.
+	ErrPathNotFound = errors.New("path not found")
+
+	// ErrPolicyViolation indicates the compiled data violates a policy with
+	// severity "error". Use errors.As with *PolicyError for details.
+	ErrPolicyViolation = errors.New("policy violation")
+
+	// ErrSnapshotTooLarge indicates the resolved data exceeds
+	// Options.MaxSnapshotBytes.
+	ErrSnapshotTooLarge = errors.New("snapshot too large")
+
+	// ErrInvalidSnapshot indicates a file passed to LoadSnapshot is not a
+	// snapshot: not JSON or YAML, not a map, or with malformed metadata.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+	// ErrMergeConflict indicates MergeSnapshots found key paths both
+	// snapshots set to different values. Use errors.As with
+	// *MergeConflictError for the paths.
+	ErrMergeConflict = errors.New("merge conflict")
+
+	// ErrUnknownProfile indicates Options.Profiles names a profile that no
+	// input file declares.
+	ErrUnknownProfile = errors.New("unknown profile")
+
+	// ErrProviderNotPermitted indicates a source declaration breaks
+	// Options.ProviderPermissions. Use errors.As with
+	// *ProviderPermissionError for details.
+	ErrProviderNotPermitted = errors.New("provider not permitted")
+
+	// ErrSessionClosed indicates Session.Compile was called after
+	// Session.Close.
+	ErrSessionClosed = errors.New("session closed")
+
+	// ErrAliasNotFound indicates a source alias is not configured.
+	//
+	// Deprecated: Use ErrUnknownAlias.
+	ErrAliasNotFound = ErrUnknownAlias
+)
+
+// Structured error types carrying failure details. Use errors.As to extract them.
+type (
+	// ReferenceError describes a failure to resolve a single reference expression,
+	// including its alias, path, and source location.
+	ReferenceError = core.ReferenceError
+
+	// CycleError reports the chain of references forming a circular reference.
+	CycleError = core.CycleError
+
+	// CycleHop locates one reference of a circular reference.
+	CycleHop = core.CycleHop
+
+	// FunctionError describes a failed built-in function call, including the
+	// function name and source location.
+	FunctionError = core.FunctionError
+
+	// LimitError reports what exceeded which of Options.Limits. Limits
+	// exceeded by a provider value are wrapped in a *ReferenceError naming
+	// the reference.
+	LimitError = core.LimitError
+
+	// MessageSizeError reports a provider response larger than
+	// Options.MaxProviderMessageBytes, naming the alias, the fetched path,
+	// the response size, and the limit.
+	MessageSizeError = core.MessageSizeError
+)
+
+// Remediation hints. Typed errors such as *ReferenceError, *CycleError,
+// *LimitError, and *MessageSizeError implement Hinter, and errors from
+// other places carry hints attached with WithHint. Front ends render the
+// hints of an error apart from its message:
+//
+//	for _, hint := range compiler.Hints(err) {
+//	    fmt.Fprintf(os.Stderr, "help: %s\n", hint.Text)
+//	}
+type (
+	// Hint is remediation advice for an error, with an optional link to
+	// documentation.
+	Hint = core.Hint
+
+	// Hinter is implemented by errors that carry a Hint.
+	Hinter = core.Hinter
+)
+
+// WithHint returns err carrying hint, or nil if err is nil. The message of
+// err is unchanged, and errors.Is and errors.As see through the wrapper.
+func WithHint(err error, hint Hint) error {
+	return core.WithHint(err, hint)
+}
+
+// Hints returns the hints carried anywhere in the tree of err, outermost
+// first, without duplicates.
+func Hints(err error) []Hint {
+	return core.Hints(err)
+}
+
+// migrateHint is the hint of deprecated syntax the parser rejects.
+var migrateHint = Hint{Text: "run 'nomos migrate' to rewrite the deprecated syntax"}
+
+// withMigrateHint attaches migrateHint to err if it rejects deprecated
+// syntax that 'nomos migrate' rewrites.
+func withMigrateHint(err error) error {
+	if parser.IsLegacySyntax(err) {
+		return WithHint(err, migrateHint)
+	}
+	return err
+}
+
+// PolicyError reports a policy that the compiled data does not satisfy.
+// A policy whose expression cannot be evaluated against the data (for
+// example because it accesses a missing key) is also reported as violated,
+// with the cause in Err.
+type PolicyError struct {
+	// Policy is the name of the violated policy.
+	Policy string
+
+	// Message is the policy's message, or its expression if it has none.
+	Message string
+
+	// Err is the evaluation failure, if any.
+	Err error
+}
+
+func (e *PolicyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("policy %q violated: %s: %v", e.Policy, e.Message, e.Err)
+	}
+	return fmt.Sprintf("policy %q violated: %s", e.Policy, e.Message)
+}
+
+// Unwrap returns ErrPolicyViolation and the evaluation failure, if any.
+func (e *PolicyError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrPolicyViolation, e.Err}
+	}
+	return []error{ErrPolicyViolation}
+}
+
+// compilationError combines several compilation errors while preserving
+// each one for errors.Is and errors.As.
+type compilationError struct {
+	msg  string
+	errs []error
+}
+
+func (e *compilationError) Error() string {
+	return e.msg
+}
+
+func (e *compilationError) Unwrap() []error {
+	return e.errs
+}
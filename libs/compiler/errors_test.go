@@ -0,0 +1,216 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// blockingProvider never returns from Fetch until its context is done.
+type blockingProvider struct{}
+
+func (p *blockingProvider) Init(_ context.Context, _ compiler.ProviderInitOptions) error {
+	return nil
+}
+
+func (p *blockingProvider) Fetch(ctx context.Context, _ []string) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestCompile_TypedErrors verifies that compilation failures can be classified
+// with errors.Is and inspected with errors.As.
+func TestCompile_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		register func(compiler.ProviderRegistry)
+		timeouts compiler.OptionsTimeouts
+		want     error
+		alias    string
+		hint     string
+	}{
+		{
+			name:   "unknown alias",
+			source: "value: @nope:key\n",
+			want:   compiler.ErrUnknownAlias,
+			hint:   `declare a source with alias "nope"`,
+		},
+		{
+			name:   "provider unavailable",
+			source: "value: @broken:key\n",
+			register: func(r compiler.ProviderRegistry) {
+				r.Register("broken", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+					return nil, errors.New("binary missing")
+				})
+			},
+			want:  compiler.ErrProviderUnavailable,
+			alias: "broken",
+			hint:  "nomos providers doctor",
+		},
+		{
+			name:   "fetch timeout",
+			source: "value: @slow:key\n",
+			register: func(r compiler.ProviderRegistry) {
+				r.Register("slow", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+					return &blockingProvider{}, nil
+				})
+			},
+			timeouts: compiler.OptionsTimeouts{PerProviderFetch: 10 * time.Millisecond},
+			want:     compiler.ErrTimeout,
+			alias:    "slow",
+			hint:     "--timeout-per-provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			if err := writeFile(path, tt.source); err != nil {
+				t.Fatalf("failed to write source: %v", err)
+			}
+
+			registry := compiler.NewProviderRegistry()
+			if tt.register != nil {
+				tt.register(registry)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: registry,
+				Timeouts:         tt.timeouts,
+			})
+
+			err := result.Error()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected errors.Is(err, %v), got: %v", tt.want, err)
+			}
+			if hints := compiler.Hints(err); len(hints) == 0 || !strings.Contains(hints[0].Text, tt.hint) {
+				t.Errorf("Hints() = %+v, want a hint containing %q", hints, tt.hint)
+			}
+
+			if tt.alias == "" {
+				return
+			}
+			var refErr *compiler.ReferenceError
+			if !errors.As(err, &refErr) {
+				t.Fatalf("expected *compiler.ReferenceError, got: %T", err)
+			}
+			if refErr.Alias != tt.alias {
+				t.Errorf("ReferenceError.Alias = %q, want %q", refErr.Alias, tt.alias)
+			}
+			if refErr.Filename != path || refErr.Line != 1 {
+				t.Errorf("ReferenceError location = %s:%d, want %s:1", refErr.Filename, refErr.Line, path)
+			}
+		})
+	}
+}
+
+// TestCompile_LegacySyntaxHint verifies that deprecated syntax the parser
+// rejects points at 'nomos migrate', and other parse errors do not.
+func TestCompile_LegacySyntaxHint(t *testing.T) {
+	for source, want := range map[string]bool{
+		"reference:base:app.name\n": true,
+		"import:base\n":             true,
+		"$bad\n":                    false,
+	} {
+		path := filepath.Join(t.TempDir(), "app.csl")
+		if err := writeFile(path, source); err != nil {
+			t.Fatalf("failed to write source: %v", err)
+		}
+
+		err := compiler.Compile(context.Background(), compiler.Options{
+			Path:             path,
+			ProviderRegistry: compiler.NewProviderRegistry(),
+		}).Error()
+		if err == nil {
+			t.Fatalf("expected a parse error for %q, got nil", source)
+		}
+		hints := compiler.Hints(err)
+		if got := len(hints) > 0 && strings.Contains(hints[0].Text, "nomos migrate"); got != want {
+			t.Errorf("Hints(%q) = %+v, want migrate hint %t", source, hints, want)
+		}
+	}
+}
+
+// TestResolveReference_PropertyPathInvalid verifies navigation failures are typed.
+func TestResolveReference_PropertyPathInvalid(t *testing.T) {
+	ref := &ast.ReferenceExpr{
+		Alias:      "base",
+		Path:       []string{"app", "missing"},
+		SourceSpan: ast.SourceSpan{Filename: "app.csl", StartLine: 3, StartCol: 7},
+	}
+
+	_, err := compiler.ResolveReference(ref, map[string]any{"app": map[string]any{"port": 8080}}, &compiler.ResolutionContext{})
+	if !errors.Is(err, compiler.ErrPropertyPathInvalid) {
+		t.Fatalf("expected ErrPropertyPathInvalid, got: %v", err)
+	}
+
+	var refErr *compiler.ReferenceError
+	if !errors.As(err, &refErr) {
+		t.Fatalf("expected *compiler.ReferenceError, got: %T", err)
+	}
+	if refErr.Alias != "base" {
+		t.Errorf("ReferenceError.Alias = %q, want %q", refErr.Alias, "base")
+	}
+}
+
+// TestCompilationResult_ErrorUntyped verifies results built without typed
+// errors still report their messages.
+func TestCompilationResult_ErrorUntyped(t *testing.T) {
+	result := compiler.CompilationResult{}
+	result.Snapshot.Metadata.Errors = []string{"first", "second"}
+
+	want := "compilation failed with 2 errors: [first second]"
+	if got := result.Error().Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// TestHints verifies hints are collected from the whole error tree.
+func TestHints(t *testing.T) {
+	limit := &compiler.LimitError{Limit: compiler.LimitMaxDepth, Max: 4, Subject: "provider value"}
+	ref := &compiler.ReferenceError{Alias: "cfg", Path: []string{"hosts"}, Kind: compiler.ErrLimitExceeded, Err: limit}
+	attached := compiler.WithHint(errors.New("binary missing"), compiler.Hint{Text: "install it", DocsURL: "https://example.com"})
+
+	tests := []struct {
+		name string
+		err  error
+		want []compiler.Hint
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "no hint", err: errors.New("plain"), want: nil},
+		{name: "attached", err: attached, want: []compiler.Hint{{Text: "install it", DocsURL: "https://example.com"}}},
+		{name: "wrapped cause", err: ref, want: []compiler.Hint{{Text: "raise limits.max_depth in .nomos/config.yaml"}}},
+		{
+			name: "joined, without duplicates",
+			err:  errors.Join(fmt.Errorf("first: %w", attached), attached, &compiler.CycleError{Chain: []string{"a:x", "a:x"}}),
+			want: []compiler.Hint{
+				{Text: "install it", DocsURL: "https://example.com"},
+				{Text: "replace one reference of the cycle with a literal value"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compiler.Hints(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hints() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if compiler.WithHint(nil, compiler.Hint{Text: "unused"}) != nil {
+		t.Error("WithHint(nil) != nil")
+	}
+	if attached.Error() != "binary missing" || !errors.Is(compiler.WithHint(limit, compiler.Hint{}), compiler.ErrLimitExceeded) {
+		t.Error("WithHint changed the message or hid the wrapped error")
+	}
+}
@@ -0,0 +1,15 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// SetAzureTransport sends the requests of the built-in Azure providers,
+// their credential's included, through transport until t ends.
+func SetAzureTransport(t testing.TB, transport policy.Transporter) {
+	saved := azureClientOptions
+	azureClientOptions.Transport = transport
+	t.Cleanup(func() { azureClientOptions = saved })
+}
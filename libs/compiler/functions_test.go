@@ -0,0 +1,86 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_Functions verifies that fn: calls are evaluated after their
+// reference arguments are resolved.
+func TestCompile_Functions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	source := `source:
+  alias: 'base'
+  type: 'fake'
+
+app:
+  name: fn:upper(@base:app.name)
+  hosts: fn:join(@base:app.hosts, ',')
+  subnet: fn:cidrsubnet('10.0.0.0/16', 8, 3)
+  token: fn:base64(fn:lower('ABC'))
+`
+	if err := writeFile(path, source); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	provider := testutil.NewFakeProvider("base")
+	provider.FetchResponses["app/name"] = "web"
+	provider.FetchResponses["app/hosts"] = []any{"a", "b"}
+	registry := testutil.NewFakeProviderRegistry()
+	registry.AddProvider("base", provider)
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: registry,
+	})
+	if err := result.Error(); err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	app, ok := result.Snapshot.Data["app"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected app map, got %T", result.Snapshot.Data["app"])
+	}
+	want := map[string]any{
+		"name":   "WEB",
+		"hosts":  "a,b",
+		"subnet": "10.0.3.0/24",
+		"token":  "YWJj",
+	}
+	for key, value := range want {
+		if app[key] != value {
+			t.Errorf("app.%s = %#v, want %#v", key, app[key], value)
+		}
+	}
+}
+
+// TestCompile_FunctionError verifies that failed calls are located in source.
+func TestCompile_FunctionError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  ip: fn:cidrhost('10.0.0.0/30', 9)\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: compiler.NewProviderRegistry(),
+	})
+
+	err := result.Error()
+	if !errors.Is(err, compiler.ErrFunctionCall) {
+		t.Fatalf("expected errors.Is(err, ErrFunctionCall), got: %v", err)
+	}
+	var fnErr *compiler.FunctionError
+	if !errors.As(err, &fnErr) || fnErr.Name != "cidrhost" || fnErr.Line != 2 {
+		t.Errorf("expected *FunctionError for cidrhost on line 2, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "app.csl:2:") {
+		t.Errorf("expected source location in error, got: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package compiler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// FuzzNavigatePath checks that navigatePath follows any dot-separated path
+// through any JSON document without panicking, failing only with
+// ErrPropertyPathInvalid, and that a found value is reached again by
+// navigating its parent.
+//
+// The seed corpus is in testdata/fuzz/FuzzNavigatePath. Run with:
+//
+//	go test -run '^$' -fuzz FuzzNavigatePath .
+func FuzzNavigatePath(f *testing.F) {
+	f.Fuzz(func(t *testing.T, document []byte, path string) {
+		var data map[string]any
+		if err := json.Unmarshal(document, &data); err != nil {
+			return
+		}
+		var segments []string
+		if path != "" {
+			segments = strings.Split(path, ".")
+		}
+
+		got, err := navigatePath(data, segments)
+		if err != nil {
+			if !errors.Is(err, ErrPropertyPathInvalid) {
+				t.Fatalf("navigatePath(%v) error = %v, want ErrPropertyPathInvalid", segments, err)
+			}
+			return
+		}
+		if len(segments) == 0 {
+			return
+		}
+
+		parent, err := navigatePath(data, segments[:len(segments)-1])
+		if err != nil {
+			t.Fatalf("navigatePath() found %v but not its parent: %v", segments, err)
+		}
+		parentMap, ok := parent.(map[string]any)
+		if !ok {
+			t.Fatalf("navigatePath() found %v through a %T", segments, parent)
+		}
+		if want := parentMap[segments[len(segments)-1]]; !reflect.DeepEqual(got, want) {
+			t.Errorf("navigatePath(%v) = %v, want %v", segments, got, want)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package compiler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/gcpapi"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// GCPSecretManagerProviderType is the built-in provider type that reads
+// Google Secret Manager secrets by name:
+//
+//	source:
+//	  alias: 'gsm'
+//	  type: 'gcp-secretmanager'
+//	  project: 'my-project'           # optional; default GOOGLE_CLOUD_PROJECT or the credentials' project
+//	  versions: 'db-password=3'       # optional; pins versions, others read 'latest'
+//	  json: 'true'                    # optional; expands JSON payloads into nested keys
+//	  sensitive: 'auto'               # optional; auto and all mark every value, none marks none
+//
+// References name a secret, e.g. @gsm:db-password, or with json a key of a
+// JSON payload, e.g. @gsm:db-credentials.username. Secrets are read on
+// first reference with Application Default Credentials, found as
+// google.FindDefaultCredentials does: service account keys, gcloud user
+// credentials, workload identity federation and impersonation files, and
+// the metadata server.
+const GCPSecretManagerProviderType = "gcp-secretmanager"
+
+// gcpCloudPlatformScope is the OAuth scope of Secret Manager access tokens.
+const gcpCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// gcpSecretManagerProvider implements core.Provider over the secrets of one
+// Google Cloud project.
+type gcpSecretManagerProvider struct {
+	project   string
+	endpoint  string
+	versions  map[string]string
+	parseJSON bool
+	sensitive string
+
+	client *gcpapi.Client
+
+	mu       sync.Mutex
+	payloads map[string]any
+}
+
+func newGCPSecretManagerProvider(config map[string]any) (core.Provider, error) {
+	p := &gcpSecretManagerProvider{
+		versions:  make(map[string]string),
+		sensitive: sensitiveAuto,
+		payloads:  make(map[string]any),
+	}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s config %q must be a string", GCPSecretManagerProviderType, key)
+		}
+		switch key {
+		case "project":
+			p.project = s
+		case "endpoint":
+			p.endpoint = s
+		case "versions":
+			for pin := range strings.SplitSeq(s, ",") {
+				name, version, ok := strings.Cut(strings.TrimSpace(pin), "=")
+				if !ok || name == "" || !validSecretVersion(version) {
+					return nil, fmt.Errorf("%s config 'versions' must be a comma-separated list of secret=version pins (got %q)", GCPSecretManagerProviderType, pin)
+				}
+				p.versions[name] = version
+			}
+		case "json":
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s config 'json' must be 'true' or 'false' (got %q)", GCPSecretManagerProviderType, s)
+			}
+			p.parseJSON = b
+		case "sensitive":
+			if err := checkSensitive(GCPSecretManagerProviderType, s); err != nil {
+				return nil, err
+			}
+			p.sensitive = s
+		default:
+			return nil, fmt.Errorf("unknown %s config %q", GCPSecretManagerProviderType, key)
+		}
+	}
+	return p, nil
+}
+
+// validSecretVersion reports whether version is "latest" or a version
+// number.
+func validSecretVersion(version string) bool {
+	if version == "latest" {
+		return true
+	}
+	n, err := strconv.Atoi(version)
+	return err == nil && n > 0
+}
+
+// Init implements core.Provider. It locates credentials and the project;
+// secrets are read on first reference.
+func (p *gcpSecretManagerProvider) Init(ctx context.Context, _ core.ProviderInitOptions) error {
+	// The token source refreshes tokens with this context long after Init
+	// returns, so it must not be cancelled with ctx
+	creds, err := google.FindDefaultCredentials(context.WithoutCancel(ctx), gcpCloudPlatformScope)
+	if err != nil {
+		return fmt.Errorf("%s: %w", GCPSecretManagerProviderType, err)
+	}
+	p.project = cmp.Or(p.project, os.Getenv("GOOGLE_CLOUD_PROJECT"), os.Getenv("CLOUDSDK_CORE_PROJECT"), creds.ProjectID)
+	if p.project == "" {
+		return fmt.Errorf("%s: no project configured: set 'project' or GOOGLE_CLOUD_PROJECT", GCPSecretManagerProviderType)
+	}
+	p.client = gcpapi.NewClient(oauth2.NewClient(context.WithoutCancel(ctx), creds.TokenSource), p.endpoint)
+	return nil
+}
+
+// Fetch implements core.Provider. The first path segment names the secret;
+// with json, further segments select keys of its payload.
+func (p *gcpSecretManagerProvider) Fetch(ctx context.Context, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%s references must name a secret: %w", GCPSecretManagerProviderType, core.ErrPropertyPathInvalid)
+	}
+	value, err := p.payload(ctx, path[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 {
+		return value, nil
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		if !p.parseJSON {
+			return nil, fmt.Errorf("secret %q has no keys; set json: 'true' to expand JSON payloads: %w", path[0], core.ErrPropertyPathInvalid)
+		}
+		return nil, fmt.Errorf("secret %q is not a JSON object: %w", path[0], core.ErrPropertyPathInvalid)
+	}
+	return lookupPath(object, path[1:], "key")
+}
+
+// payload returns the value of the secret name, reading it once.
+func (p *gcpSecretManagerProvider) payload(ctx context.Context, name string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if value, ok := p.payloads[name]; ok {
+		return value, nil
+	}
+	version := cmp.Or(p.versions[name], "latest")
+	data, err := p.client.AccessSecretVersion(ctx, p.project, name, version)
+	if err != nil {
+		var apiErr *gcpapi.Error
+		if errors.As(err, &apiErr) && apiErr.Status == "NOT_FOUND" {
+			return nil, fmt.Errorf("%s: secret %q version %s not found in project %s: %w", GCPSecretManagerProviderType, name, version, p.project, core.ErrPropertyPathInvalid)
+		}
+		return nil, fmt.Errorf("%s: reading secret %q: %w", GCPSecretManagerProviderType, name, err)
+	}
+
+	var value any = string(data)
+	if p.parseJSON {
+		var object map[string]any
+		if json.Unmarshal(data, &object) == nil && object != nil {
+			value = object
+		}
+	}
+	if p.sensitive != sensitiveNone {
+		value = secretLeaves(value)
+	}
+	p.payloads[name] = value
+	return value, nil
+}
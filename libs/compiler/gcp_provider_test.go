@@ -0,0 +1,193 @@
+package compiler_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// fakeGCPEndpoint serves OAuth tokens, service account impersonation, and
+// Secret Manager payloads, and configures authorized user credentials
+// through the environment. Secrets need the access token "token", which
+// the "refresh" refresh token and impersonation grant; the "source"
+// refresh token grants only the "source-token" impersonation needs.
+func fakeGCPEndpoint(t *testing.T) string {
+	t.Helper()
+	payloads := map[string]string{
+		"projects/my-project/secrets/db-password/versions/latest": "s3cr3t",
+		"projects/my-project/secrets/db-password/versions/1":      "old-secret",
+		"projects/my-project/secrets/db/versions/latest":          `{"username":"admin","password":"hunter2"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/token":
+			token := map[string]string{"refresh": "token", "source": "source-token"}[r.FormValue("refresh_token")]
+			_, _ = fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3599}`, token)
+			return
+		case strings.HasSuffix(r.URL.Path, ":generateAccessToken"):
+			if r.Header.Get("Authorization") != "Bearer source-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"accessToken":"token","expireTime":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), ":access")
+		payload, ok := payloads[name]
+		if r.Header.Get("Authorization") != "Bearer token" || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not found","status":"NOT_FOUND"}}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"name":%q,"payload":{"data":%q}}`, name, base64.StdEncoding.EncodeToString([]byte(payload)))
+	}))
+	t.Cleanup(server.Close)
+
+	writeGCPCredentials(t, `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh","token_uri":"`+server.URL+`/token"}`)
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	return server.URL
+}
+
+// writeGCPCredentials points GOOGLE_APPLICATION_CREDENTIALS at a file
+// holding content.
+func writeGCPCredentials(t *testing.T, content string) {
+	t.Helper()
+	creds := filepath.Join(t.TempDir(), "adc.json")
+	if err := os.WriteFile(creds, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", creds)
+}
+
+// TestCompile_GCPSecretManagerProvider verifies that the built-in
+// gcp-secretmanager provider reads pinned and latest versions, expands JSON
+// payloads, and marks values sensitive.
+func TestCompile_GCPSecretManagerProvider(t *testing.T) {
+	endpoint := fakeGCPEndpoint(t)
+
+	tests := []struct {
+		name      string
+		source    string
+		body      string
+		want      map[string]any
+		sensitive []string
+		wantErr   string
+	}{
+		{
+			name:      "latest",
+			body:      "password: @gsm:db-password\n",
+			want:      map[string]any{"password": map[string]any{"value": "s3cr3t"}},
+			sensitive: []string{"out.password"},
+		},
+		{
+			name:      "pinned version",
+			source:    "\n  versions: 'db-password=1'",
+			body:      "password: @gsm:db-password\n",
+			want:      map[string]any{"password": map[string]any{"value": "old-secret"}},
+			sensitive: []string{"out.password"},
+		},
+		{
+			name:      "json expansion",
+			source:    "\n  json: 'true'",
+			body:      "user: @gsm:db.username\npassword: @gsm:db.password\n",
+			want:      map[string]any{"user": map[string]any{"value": "admin"}, "password": map[string]any{"value": "hunter2"}},
+			sensitive: []string{"out.password", "out.user"},
+		},
+		{
+			name:      "not sensitive",
+			source:    "\n  sensitive: 'none'",
+			body:      "password: @gsm:db-password\n",
+			want:      map[string]any{"password": "s3cr3t"},
+			sensitive: []string{},
+		},
+		{
+			name:    "keys without json",
+			body:    "user: @gsm:db.username\n",
+			wantErr: "set json: 'true'",
+		},
+		{
+			name:    "missing secret",
+			body:    "password: @gsm:nope\n",
+			wantErr: `secret "nope" version latest not found in project my-project`,
+		},
+		{
+			name:    "invalid version pin",
+			source:  "\n  versions: 'db-password=first'",
+			body:    "password: @gsm:db-password\n",
+			wantErr: "secret=version pins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			src := "source:\n  alias: 'gsm'\n  type: 'gcp-secretmanager'\n  endpoint: '" + endpoint + "'" + tt.source + "\n\nout:\n" + indent(tt.body)
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			if out := jsonRoundTrip(t, result.Snapshot.Data["out"]); !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("out = %#v, want %#v", out, tt.want)
+			}
+			if got := result.Snapshot.Metadata.SensitiveKeys; !reflect.DeepEqual(got, tt.sensitive) {
+				t.Errorf("SensitiveKeys = %v, want %v", got, tt.sensitive)
+			}
+		})
+	}
+}
+
+// TestCompile_GCPSecretManagerProvider_Impersonation verifies that
+// impersonated service account credentials, which gcloud writes for
+// --impersonate-service-account, authenticate Secret Manager requests.
+func TestCompile_GCPSecretManagerProvider_Impersonation(t *testing.T) {
+	endpoint := fakeGCPEndpoint(t)
+	writeGCPCredentials(t, `{
+  "type": "impersonated_service_account",
+  "service_account_impersonation_url": "`+endpoint+`/v1/projects/-/serviceAccounts/reader@my-project.iam.gserviceaccount.com:generateAccessToken",
+  "source_credentials": {"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "source", "token_uri": "`+endpoint+`/token"}
+}`)
+
+	path := filepath.Join(t.TempDir(), "app.csl")
+	src := "source:\n  alias: 'gsm'\n  type: 'gcp-secretmanager'\n  endpoint: '" + endpoint + "'\n  sensitive: 'none'\n\nout:\n  password: @gsm:db-password\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatal(err)
+	}
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 path,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+	if got := result.Snapshot.Data["out"]; !reflect.DeepEqual(got, map[string]any{"password": "s3cr3t"}) {
+		t.Errorf("out = %#v, want the secret", got)
+	}
+}
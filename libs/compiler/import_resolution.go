@@ -11,21 +11,26 @@ import (
 // due to missing dependencies (e.g., no ProviderTypeRegistry).
 var ErrImportResolutionNotAvailable = errors.New("import resolution not available: ProviderTypeRegistry required")
 
-// resolveFileImports processes a single file's imports and returns merged data.
+// resolveFileImports processes a single file's imports and returns merged data
+// and the aliases of the file's source declarations, in declaration order.
 // Returns ErrImportResolutionNotAvailable if the file has no type registry
 // for dynamic provider creation.
-func resolveFileImports(ctx context.Context, filePath string, opts Options) (map[string]any, error) {
+func resolveFileImports(ctx context.Context, filePath string, opts Options) (map[string]any, []string, error) {
 	// Check if we have a type registry for dynamic provider creation
 	if opts.ProviderTypeRegistry == nil {
 		// No type registry - can't process source declarations
-		return nil, ErrImportResolutionNotAvailable
+		return nil, nil, ErrImportResolutionNotAvailable
 	}
 
 	// Resolve imports directly - no adapters needed since all use core interfaces
-	data, err := imports.ResolveImports(ctx, filePath, opts.ProviderRegistry, opts.ProviderTypeRegistry)
+	extracted, err := imports.ResolveImports(ctx, filePath, opts.ProviderRegistry, opts.ProviderTypeRegistry, opts.ProviderConfigOverrides)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return data, nil
+	aliases := make([]string, 0, len(extracted.Sources))
+	for _, src := range extracted.Sources {
+		aliases = append(aliases, src.Alias)
+	}
+	return extracted.Data, aliases, nil
 }
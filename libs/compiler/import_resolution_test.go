@@ -138,7 +138,7 @@ func TestResolveFileImports_SimpleImport(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), filePath, opts)
+	data, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert - import statements are deprecated
 	if err == nil {
@@ -171,7 +171,7 @@ func TestResolveFileImports_MultiLevelImportChain(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), filePath, opts)
+	data, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert - import statements are deprecated
 	if err == nil {
@@ -204,7 +204,7 @@ func TestResolveFileImports_NoTypeRegistry(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), filePath, opts)
+	data, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert - should return ErrImportResolutionNotAvailable
 	if !errors.Is(err, ErrImportResolutionNotAvailable) {
@@ -232,7 +232,7 @@ func TestResolveFileImports_FileNotFound(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), filePath, opts)
+	data, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert
 	// Note: This currently returns empty data instead of an error when imports fail.
@@ -265,7 +265,7 @@ func TestResolveFileImports_ParseError(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), filePath, opts)
+	data, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert
 	// Note: Parse errors should bubble up from imports.ResolveImports.
@@ -300,7 +300,7 @@ func TestResolveFileImports_MissingProvider(t *testing.T) {
 	}
 
 	// Act
-	_, err := resolveFileImports(context.Background(), filePath, opts)
+	_, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert
 	if err == nil {
@@ -329,7 +329,7 @@ func TestResolveFileImports_NoPathImport(t *testing.T) {
 	}
 
 	// Act
-	_, err := resolveFileImports(context.Background(), filePath, opts)
+	_, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert
 	if err == nil {
@@ -362,7 +362,7 @@ func TestResolveFileImports_ContextCancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	// Act
-	_, err := resolveFileImports(ctx, filePath, opts)
+	_, _, err := resolveFileImports(ctx, filePath, opts)
 
 	// Assert
 	// Note: Currently the implementation may not check context during simple operations,
@@ -396,7 +396,7 @@ func TestResolveFileImports_ProviderInitError(t *testing.T) {
 	}
 
 	// Act
-	_, err := resolveFileImports(context.Background(), filePath, opts)
+	_, _, err := resolveFileImports(context.Background(), filePath, opts)
 
 	// Assert
 	if err == nil {
@@ -429,7 +429,7 @@ func TestResolveFileImports_EmptyFile(t *testing.T) {
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), emptyFile, opts)
+	data, _, err := resolveFileImports(context.Background(), emptyFile, opts)
 
 	// Assert
 	if err != nil {
@@ -502,7 +502,7 @@ shared:
 	}
 
 	// Act
-	data, err := resolveFileImports(context.Background(), mainFile, opts)
+	data, _, err := resolveFileImports(context.Background(), mainFile, opts)
 
 	// Assert - expect error due to deprecated import: syntax
 	if err == nil {
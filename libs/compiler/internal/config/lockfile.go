@@ -16,10 +16,20 @@ import (
 	"path/filepath"
 )
 
+// LockfileVersion is the newest lockfile schema version this package
+// reads. Version 2 installs binaries at {owner}/{repo}/{version}/{os}_{arch}/provider;
+// lockfiles without a version use {os}-{arch} directories. Both record each
+// binary's path, so either resolves.
+const LockfileVersion = 2
+
 // Lockfile represents the .nomos/providers.lock.json structure.
 // It records the exact provider binaries used for a project with their
 // versions, sources, checksums, and installation paths.
 type Lockfile struct {
+	// Version is the lockfile schema version; zero for lockfiles written
+	// before versioning.
+	Version int `json:"version,omitempty"`
+
 	// Providers is the list of provider entries in the lockfile.
 	Providers []Provider `json:"providers"`
 }
@@ -53,6 +63,20 @@ type Provider struct {
 
 	// Path is the relative path to the installed provider binary.
 	Path string `json:"path"`
+
+	// Aliases lists every alias sharing this binary when more than one
+	// source declaration uses the same type and version. Alias is the first.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Channel is the release channel ("latest" or "prerelease") the source
+	// declaration used; Version is the release it resolved to.
+	Channel string `json:"channel,omitempty"`
+}
+
+// MatchesVersion reports whether the entry satisfies a declared version,
+// which may be the release channel it was resolved from.
+func (p *Provider) MatchesVersion(version string) bool {
+	return p.Version == version || (p.Channel != "" && p.Channel == version)
 }
 
 // ProviderSource describes where a provider binary was obtained from.
@@ -90,7 +114,10 @@ func (l *Lockfile) Validate() error {
 		return errors.New("lockfile must contain at least one provider")
 	}
 
-	// Check for duplicate aliases
+	// An alias may be pinned at several versions of one type, each scoped to
+	// the files declaring it, and for several platforms, but only once per
+	// version and platform
+	types := make(map[string]string)
 	seen := make(map[string]bool)
 	for i, provider := range l.Providers {
 		if provider.Alias == "" {
@@ -106,10 +133,16 @@ func (l *Lockfile) Validate() error {
 			return fmt.Errorf("provider %q: path is required", provider.Alias)
 		}
 
-		if seen[provider.Alias] {
+		if typ, ok := types[provider.Alias]; ok && typ != provider.Type {
 			return fmt.Errorf("duplicate provider alias: %q", provider.Alias)
 		}
-		seen[provider.Alias] = true
+		types[provider.Alias] = provider.Type
+
+		key := provider.Alias + "\x00" + provider.Version + "\x00" + provider.OS + "\x00" + provider.Arch
+		if seen[key] {
+			return fmt.Errorf("duplicate provider alias: %q", provider.Alias)
+		}
+		seen[key] = true
 	}
 
 	return nil
@@ -154,6 +187,9 @@ func LoadLockfile(path string) (*Lockfile, error) {
 	if err := json.Unmarshal(data, &lockfile); err != nil {
 		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
 	}
+	if lockfile.Version > LockfileVersion {
+		return nil, fmt.Errorf("lockfile version %d is newer than supported version %d", lockfile.Version, LockfileVersion)
+	}
 
 	// Validate after loading
 	if err := lockfile.Validate(); err != nil {
@@ -164,8 +200,8 @@ func LoadLockfile(path string) (*Lockfile, error) {
 }
 
 // BinaryPath constructs the standard installation path for a provider binary
-// given a base directory. The path follows the convention:
-// {baseDir}/{type}/{version}/{os}-{arch}/provider
+// given a base directory. The path follows the version 2 layout:
+// {baseDir}/{type}/{version}/{os}_{arch}/provider
 func (p *Provider) BinaryPath(baseDir string) string {
-	return filepath.Join(baseDir, p.Type, p.Version, fmt.Sprintf("%s-%s", p.OS, p.Arch), "provider")
+	return filepath.Join(baseDir, p.Type, p.Version, p.OS+"_"+p.Arch, "provider")
 }
@@ -2,8 +2,10 @@ package config_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/config"
@@ -229,6 +231,30 @@ func TestLockfile_Validate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "alias pinned at several versions",
+			lockfile: config.Lockfile{
+				Providers: []config.Provider{
+					{
+						Alias:   "configs",
+						Type:    "file",
+						Version: "0.2.0",
+						OS:      "darwin",
+						Arch:    "arm64",
+						Path:    ".nomos/providers/file/0.2.0/darwin_arm64/provider",
+					},
+					{
+						Alias:   "configs",
+						Type:    "file",
+						Version: "0.3.0",
+						OS:      "darwin",
+						Arch:    "arm64",
+						Path:    ".nomos/providers/file/0.3.0/darwin_arm64/provider",
+					},
+				},
+			},
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -292,6 +318,21 @@ func TestLockfile_ReadWrite(t *testing.T) {
 	}
 }
 
+// TestLoadLockfile_NewerVersion tests that lockfiles with a schema newer
+// than LockfileVersion are rejected.
+func TestLoadLockfile_NewerVersion(t *testing.T) {
+	lockfilePath := filepath.Join(t.TempDir(), "providers.lock.json")
+	data := fmt.Sprintf(`{"version": %d, "providers": [{"alias": "configs", "type": "file", "version": "0.2.0", "path": "p"}]}`, config.LockfileVersion+1)
+	if err := os.WriteFile(lockfilePath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := config.LoadLockfile(lockfilePath)
+	if err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("LoadLockfile() error = %v, want newer version error", err)
+	}
+}
+
 // TestProvider_BinaryPath tests the path helper for constructing binary paths.
 // RED: This test will fail until we implement BinaryPath() method.
 func TestProvider_BinaryPath(t *testing.T) {
@@ -310,7 +351,7 @@ func TestProvider_BinaryPath(t *testing.T) {
 				Arch:    "arm64",
 			},
 			baseDir: ".nomos/providers",
-			want:    ".nomos/providers/file/0.2.0/darwin-arm64/provider",
+			want:    ".nomos/providers/file/0.2.0/darwin_arm64/provider",
 		},
 		{
 			name: "linux amd64",
@@ -321,7 +362,7 @@ func TestProvider_BinaryPath(t *testing.T) {
 				Arch:    "amd64",
 			},
 			baseDir: "/tmp/providers",
-			want:    "/tmp/providers/http/1.0.0/linux-amd64/provider",
+			want:    "/tmp/providers/http/1.0.0/linux_amd64/provider",
 		},
 	}
 
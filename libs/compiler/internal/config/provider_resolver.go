@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 )
 
 // LockfileProviderResolver implements compiler.ProviderResolver using a lockfile.
@@ -52,32 +53,69 @@ func (r *LockfileProviderResolver) ResolveBinaryPath(_ context.Context, provider
 
 	for _, p := range allProviders {
 		if p.Type == providerType {
-			// Determine absolute path
-			var binaryPath string
-			if filepath.IsAbs(p.Path) {
-				binaryPath = p.Path
-			} else {
-				// Resolve relative to base directory
-				baseDir := r.baseDirFunc()
-				binaryPath = filepath.Join(baseDir, p.Path)
-			}
+			return r.verifiedBinaryPath(providerType, p.Path, p.Checksum)
+		}
+	}
 
-			// Verify the binary exists
-			if _, err := os.Stat(binaryPath); err != nil {
-				return "", fmt.Errorf("provider binary not found at %s: %w (run 'nomos build' to install providers)", binaryPath, err)
-			}
+	return "", fmt.Errorf("provider type %q not found in lockfile", providerType)
+}
 
-			// Validate checksum (CRITICAL for security - MANDATORY)
-			if p.Checksum == "" {
-				return "", fmt.Errorf("provider binary for %s has no checksum in lockfile - refusing to execute (security risk); run 'nomos build' to regenerate lockfile with checksums", providerType)
+// ResolveVersionedBinaryPath resolves a provider type at a declared version
+// to its binary path, for projects that pin several versions of one type.
+// The entry for the host platform is preferred. When the lockfile holds a
+// single version of the type, it is used whatever the declared version, as
+// ResolveBinaryPath would.
+func (r *LockfileProviderResolver) ResolveVersionedBinaryPath(ctx context.Context, providerType, version string) (string, error) {
+	var match *Provider
+	versions := make(map[string]bool)
+	if r.resolver.lockfile != nil {
+		for i := range r.resolver.lockfile.Providers {
+			p := &r.resolver.lockfile.Providers[i]
+			if p.Type != providerType {
+				continue
 			}
-			if err := ValidateChecksum(binaryPath, p.Checksum); err != nil {
-				return "", fmt.Errorf("provider binary checksum validation failed for %s: %w", providerType, err)
+			versions[p.Version] = true
+			if !p.MatchesVersion(version) {
+				continue
+			}
+			if match == nil || (p.OS == runtime.GOOS && p.Arch == runtime.GOARCH) {
+				match = p
 			}
-
-			return binaryPath, nil
 		}
 	}
 
-	return "", fmt.Errorf("provider type %q not found in lockfile; run 'nomos build' to install providers", providerType)
+	switch {
+	case match != nil:
+		return r.verifiedBinaryPath(providerType, match.Path, match.Checksum)
+	case len(versions) <= 1:
+		return r.ResolveBinaryPath(ctx, providerType)
+	default:
+		return "", fmt.Errorf("provider type %q version %s not found in lockfile", providerType, version)
+	}
+}
+
+// verifiedBinaryPath returns the absolute path of a lockfile binary after
+// checking that it exists and matches its checksum.
+func (r *LockfileProviderResolver) verifiedBinaryPath(providerType, path, checksum string) (string, error) {
+	// Determine absolute path
+	binaryPath := path
+	if !filepath.IsAbs(path) {
+		// Resolve relative to base directory
+		binaryPath = filepath.Join(r.baseDirFunc(), path)
+	}
+
+	// Verify the binary exists
+	if _, err := os.Stat(binaryPath); err != nil {
+		return "", fmt.Errorf("provider binary not found at %s: %w", binaryPath, err)
+	}
+
+	// Validate checksum (CRITICAL for security - MANDATORY)
+	if checksum == "" {
+		return "", fmt.Errorf("provider binary for %s has no checksum in lockfile - refusing to execute (security risk)", providerType)
+	}
+	if err := ValidateChecksum(binaryPath, checksum); err != nil {
+		return "", fmt.Errorf("provider binary checksum validation failed for %s: %w", providerType, err)
+	}
+
+	return binaryPath, nil
 }
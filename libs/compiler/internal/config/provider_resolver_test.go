@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -313,6 +314,71 @@ func TestLockfileProviderResolver_ChecksumValidation(t *testing.T) {
 	})
 }
 
+// TestLockfileProviderResolver_ResolveVersionedBinaryPath tests resolving
+// one of several pinned versions of a provider type.
+func TestLockfileProviderResolver_ResolveVersionedBinaryPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "providers")
+
+	lockfile := &Lockfile{}
+	paths := make(map[string]string)
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		rel := filepath.Join("owner", "repo", version, runtime.GOOS+"_"+runtime.GOARCH, "provider")
+		path := filepath.Join(baseDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: Test fixture directory
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("provider "+version), 0755); err != nil { //nolint:gosec // G306: Test provider binary
+			t.Fatal(err)
+		}
+		checksum, err := ComputeChecksum(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		channel := ""
+		if version == "2.0.0" {
+			channel = "latest"
+		}
+		lockfile.Providers = append(lockfile.Providers, Provider{
+			Alias: "configs", Type: "owner/repo", Version: version, Channel: channel,
+			OS: runtime.GOOS, Arch: runtime.GOARCH, Path: rel, Checksum: checksum,
+		})
+		paths[version] = path
+	}
+	lockfilePath := filepath.Join(tmpDir, "providers.lock.json")
+	if err := lockfile.Save(lockfilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver, err := NewLockfileProviderResolver(lockfilePath, "", func() string { return baseDir })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.0.0", want: paths["1.0.0"]},
+		{version: "2.0.0", want: paths["2.0.0"]},
+		{version: "latest", want: paths["2.0.0"]},
+	}
+	for _, tt := range tests {
+		got, err := resolver.ResolveVersionedBinaryPath(context.Background(), "owner/repo", tt.version)
+		if err != nil {
+			t.Errorf("ResolveVersionedBinaryPath(%q) error = %v", tt.version, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveVersionedBinaryPath(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+
+	if _, err := resolver.ResolveVersionedBinaryPath(context.Background(), "owner/repo", "3.0.0"); err == nil || !contains(err.Error(), "version 3.0.0 not found") {
+		t.Errorf("expected version not found error, got %v", err)
+	}
+}
+
 // contains is a helper function to check if a string contains a substring.
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && anyContains(s, substr))
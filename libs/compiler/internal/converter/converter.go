@@ -50,6 +50,17 @@ func ASTToData(tree *ast.AST) (map[string]any, error) {
 			})
 
 		case *ast.SpreadStmt:
+			if node.Prefix != "" {
+				// A prefixed spread places the referenced tree under its
+				// key prefix, like a section
+				key, value := prefixedValue(node)
+				result[key] = MergeValue(result[key], value)
+				rootOrdered = append(rootOrdered, OrderedEntry{
+					Key:   key,
+					Value: value,
+				})
+				continue
+			}
 			isSpread := shouldSpread(node.Reference)
 			hasRootSpread = true
 			rootOrdered = append(rootOrdered, OrderedEntry{
@@ -142,6 +153,19 @@ func exprToValue(expr ast.Expr) (any, error) {
 		// Identifiers become strings
 		return e.Name, nil
 
+	case *ast.CallExpr:
+		// Function calls are evaluated by the resolver once their
+		// arguments' references are resolved
+		args := make([]any, 0, len(e.Args))
+		for idx, arg := range e.Args {
+			val, err := exprToValue(arg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert argument %d of fn:%s: %w", idx+1, e.Name, err)
+			}
+			args = append(args, val)
+		}
+		return models.Call{Name: e.Name, Args: args, SourceSpan: e.SourceSpan}, nil
+
 	case *ast.MarkedExpr:
 		// Marked expressions become Secrets
 		val, err := exprToValue(e.Expr)
@@ -203,6 +227,42 @@ func shouldSpread(ref *ast.ReferenceExpr) bool {
 	return false
 }
 
+// prefixedValue returns the top-level key and the value that place a
+// prefixed spread's reference under its key prefix: the prefix "a.b"
+// yields "a" and {"b": reference}.
+func prefixedValue(node *ast.SpreadStmt) (string, any) {
+	segments := strings.Split(node.Prefix, ".")
+	var value any = node.Reference
+	for i := len(segments) - 1; i > 0; i-- {
+		value = map[string]any{segments[i]: value}
+	}
+	return segments[0], value
+}
+
+// MergeValue merges src over dst so maps combine key by key, as prefixes
+// sharing leading keys (teams.network and teams.data) do. Keys merged into
+// a map with spreads are appended to its ordered entries. Anything else
+// replaces dst, as a later section of the same name does.
+func MergeValue(dst, src any) any {
+	dstMap, dstIsMap := dst.(map[string]any)
+	srcMap, srcIsMap := src.(map[string]any)
+	if !dstIsMap || !srcIsMap {
+		return src
+	}
+	if ordered, ok := dstMap[OrderedEntriesKey].([]OrderedEntry); ok {
+		// The map has spreads and resolves from its ordered entries
+		for k, v := range srcMap {
+			ordered = append(ordered, OrderedEntry{Key: k, Value: v})
+		}
+		dstMap[OrderedEntriesKey] = ordered
+		return dstMap
+	}
+	for k, v := range srcMap {
+		dstMap[k] = MergeValue(dstMap[k], v)
+	}
+	return dstMap
+}
+
 // pathExprToString converts a PathExpr to a dot-separated string.
 func pathExprToString(p *ast.PathExpr) string {
 	if len(p.Components) == 0 {
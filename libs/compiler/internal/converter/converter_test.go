@@ -353,6 +353,37 @@ func TestASTToData_SpreadStatement_WildcardLogic(t *testing.T) {
 	}
 }
 
+func TestASTToData_PrefixedSpread(t *testing.T) {
+	network := &ast.ReferenceExpr{Alias: "network", Path: []string{"*"}}
+	data := &ast.ReferenceExpr{Alias: "data", Path: []string{"platform"}}
+	tree := &ast.AST{
+		Statements: []ast.Stmt{
+			&ast.SectionDecl{
+				Name:    "teams",
+				Entries: []ast.MapEntry{{Key: "owner", Value: &ast.StringLiteral{Value: "platform"}}},
+			},
+			&ast.SpreadStmt{Reference: network, Prefix: "teams.network"},
+			&ast.SpreadStmt{Reference: data, Prefix: "data"},
+		},
+		SourceSpan: ast.SourceSpan{Filename: "test.csl"},
+	}
+
+	result, err := ASTToData(tree)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Prefixed spreads are placed like sections and do not make the root
+	// resolve from ordered entries
+	expected := map[string]any{
+		"teams": map[string]any{"owner": "platform", "network": network},
+		"data":  data,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ASTToData() = %v, want %v", result, expected)
+	}
+}
+
 func TestASTToData_MapEntry_WildcardLogic(t *testing.T) {
 	tests := []struct {
 		name       string
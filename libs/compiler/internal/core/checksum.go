@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/config"
+)
+
+// VerifyProviderChecksum checks that the binary serving the given version
+// of typeName (any version when empty) has the checksum the source
+// declaration of alias pins. Types that run without a binary have nothing
+// to verify.
+func VerifyProviderChecksum(ctx context.Context, typeRegistry ProviderTypeRegistry, alias, typeName, version, checksum string) error {
+	binaries, ok := typeRegistry.(BinaryProviderTypeRegistry)
+	if !ok {
+		return fmt.Errorf("provider %q pins checksum %s, but the provider type registry cannot locate its binary", alias, checksum)
+	}
+	binaryPath, err := binaries.ProviderBinary(ctx, typeName, version)
+	if err != nil {
+		return fmt.Errorf("failed to create provider %q of type %q: %w", alias, typeName, err)
+	}
+	if binaryPath == "" {
+		return nil
+	}
+	actual, err := config.ComputeChecksum(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to verify provider %q: %w", alias, err)
+	}
+	if actual != checksum {
+		return WithHint(fmt.Errorf("%w: provider %q binary %s has checksum %s, declaration pins %s",
+			ErrProviderChecksumMismatch, alias, binaryPath, actual, checksum),
+			Hint{Text: "if the provider was upgraded on purpose, pin its new checksum in the source declaration"})
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors shared by the compiler and its internal subsystems.
+//
+// The compiler package re-exports these values so library consumers can
+// classify failures with errors.Is without importing internal packages.
+var (
+	// ErrCircularReference indicates a cycle was detected in the resolution chain.
+	ErrCircularReference = errors.New("circular reference detected")
+
+	// ErrUnknownAlias indicates a reference names a provider alias that is not configured.
+	ErrUnknownAlias = errors.New("unknown provider alias")
+
+	// ErrPropertyPathInvalid indicates a property path does not exist in the data.
+	ErrPropertyPathInvalid = errors.New("property path invalid")
+
+	// ErrProviderUnavailable indicates a provider is configured but could not be
+	// constructed, initialized, or reached.
+	ErrProviderUnavailable = errors.New("provider unavailable")
+
+	// ErrTimeout indicates an operation exceeded its deadline.
+	ErrTimeout = errors.New("operation timed out")
+
+	// ErrUnresolvedReference indicates a reference could not be resolved.
+	ErrUnresolvedReference = errors.New("unresolved reference")
+
+	// ErrProviderNotRegistered indicates a provider alias is not registered.
+	ErrProviderNotRegistered = errors.New("provider not registered")
+
+	// ErrFunctionCall indicates a built-in function call in a value failed.
+	ErrFunctionCall = errors.New("function call failed")
+
+	// ErrProviderChecksumMismatch indicates a provider binary does not have
+	// the checksum its source declaration pins.
+	ErrProviderChecksumMismatch = errors.New("provider binary checksum mismatch")
+)
+
+// ReferenceError describes a failure to resolve a single reference expression.
+//
+// Kind classifies the failure using one of the sentinel errors above and Err
+// carries the underlying cause. Both participate in errors.Is and errors.As.
+type ReferenceError struct {
+	// Alias is the provider alias of the reference.
+	Alias string
+
+	// Path is the reference path being resolved.
+	Path []string
+
+	// Filename, Line and Column locate the reference in source.
+	Filename string
+	Line     int
+	Column   int
+
+	// Kind is the sentinel error classifying the failure.
+	Kind error
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ReferenceError) Error() string {
+	path := "*"
+	if len(e.Path) > 0 {
+		path = strings.Join(e.Path, ".")
+	}
+	return fmt.Sprintf("resolving @%s:%s at %s:%d:%d: %v", e.Alias, path, e.Filename, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the classifying sentinel and the underlying cause.
+func (e *ReferenceError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// Hint suggests how to fix the failure Kind classifies. Hints carried by
+// Err are reported by Hints alongside it.
+func (e *ReferenceError) Hint() Hint {
+	switch {
+	case errors.Is(e.Kind, ErrUnknownAlias):
+		return Hint{Text: fmt.Sprintf("declare a source with alias %q, or correct the reference", e.Alias)}
+	case errors.Is(e.Kind, ErrProviderUnavailable):
+		return Hint{
+			Text:    "run 'nomos providers doctor' to check that the provider starts",
+			DocsURL: cliReadmeURL + "#nomos-providers-doctor",
+		}
+	case errors.Is(e.Kind, ErrTimeout):
+		return Hint{Text: "raise --timeout-per-provider, or check that the provider responds"}
+	}
+	return Hint{}
+}
+
+// CycleError reports a circular reference chain.
+type CycleError struct {
+	// Chain lists the references forming the cycle in resolution order,
+	// formatted as "alias:path". The last entry repeats an earlier one.
+	Chain []string
+
+	// Hops locate the reference leading to each entry of Chain, when the
+	// resolver knows them; Hops[i] explains Chain[i].
+	Hops []CycleHop
+}
+
+// CycleHop is one reference of a cycle with its place in source.
+type CycleHop struct {
+	// Ref is the referenced "alias:path".
+	Ref string
+
+	// Filename, Line and Column locate the reference.
+	Filename string
+	Line     int
+	Column   int
+
+	// Via is the "alias:path" whose fetched value holds the reference, or
+	// empty when the reference is written in a source file.
+	Via string
+}
+
+// Error implements the error interface. The first line names the chain;
+// each hop, when known, follows on a line of its own.
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %s", ErrCircularReference, strings.Join(e.Chain, " → "))
+	for _, hop := range e.Hops {
+		fmt.Fprintf(&b, "\n  %s referenced at %s:%d:%d", hop.Ref, hop.Filename, hop.Line, hop.Column)
+		if hop.Via != "" {
+			fmt.Fprintf(&b, " in the value of %s", hop.Via)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns ErrCircularReference so errors.Is matches the sentinel.
+func (e *CycleError) Unwrap() error {
+	return ErrCircularReference
+}
+
+// Hint suggests how to break the cycle.
+func (e *CycleError) Hint() Hint {
+	return Hint{Text: "replace one reference of the cycle with a literal value"}
+}
+
+// FunctionError describes a failed built-in function call (fn:name(...)).
+type FunctionError struct {
+	// Name is the called function.
+	Name string
+
+	// Filename, Line and Column locate the call in source.
+	Filename string
+	Line     int
+	Column   int
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FunctionError) Error() string {
+	return fmt.Sprintf("calling fn:%s at %s:%d:%d: %v", e.Name, e.Filename, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns ErrFunctionCall and the underlying cause.
+func (e *FunctionError) Unwrap() []error {
+	return []error{ErrFunctionCall, e.Err}
+}
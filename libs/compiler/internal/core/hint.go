@@ -0,0 +1,76 @@
+package core
+
+// cliReadmeURL is the CLI documentation that hints link to.
+const cliReadmeURL = "https://github.com/autonomous-bits/nomos/blob/main/apps/command-line/README.md"
+
+// Hint is remediation advice for an error: what the user can do about it,
+// and optionally where to read more. Hints are kept out of error messages
+// so every front end can render them its own way.
+type Hint struct {
+	// Text is the advice, phrased as an instruction such as
+	// "run 'nomos providers doctor' to check the provider".
+	Text string
+
+	// DocsURL links to documentation about the failure, if any.
+	DocsURL string
+}
+
+// IsZero reports whether h holds no advice.
+func (h Hint) IsZero() bool {
+	return h.Text == "" && h.DocsURL == ""
+}
+
+// Hinter is implemented by errors that carry a Hint.
+type Hinter interface {
+	Hint() Hint
+}
+
+// hintError attaches a Hint to an error.
+type hintError struct {
+	err  error
+	hint Hint
+}
+
+func (e *hintError) Error() string { return e.err.Error() }
+
+func (e *hintError) Unwrap() error { return e.err }
+
+func (e *hintError) Hint() Hint { return e.hint }
+
+// WithHint returns err carrying hint, or nil if err is nil. The message of
+// err is unchanged, and errors.Is and errors.As see through the wrapper.
+func WithHint(err error, hint Hint) error {
+	if err == nil {
+		return nil
+	}
+	return &hintError{err: err, hint: hint}
+}
+
+// Hints returns the hints carried anywhere in the tree of err, outermost
+// first, without duplicates.
+func Hints(err error) []Hint {
+	var hints []Hint
+	seen := make(map[Hint]bool)
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		if h, ok := err.(Hinter); ok {
+			if hint := h.Hint(); !hint.IsZero() && !seen[hint] {
+				seen[hint] = true
+				hints = append(hints, hint)
+			}
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+		}
+	}
+	walk(err)
+	return hints
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded indicates an input file, provider value, or the resolved
+// data exceeds one of the configured Limits.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits bounds the inputs a compilation accepts and the data it builds.
+// Zero fields impose no limit.
+type Limits struct {
+	// MaxFileBytes bounds the size of each input .csl file, checked before
+	// the file is read.
+	MaxFileBytes int64 `yaml:"max_file_bytes,omitempty" json:"max_file_bytes,omitempty"`
+
+	// MaxDepth bounds the nesting of maps and lists, counting the outermost
+	// map or list as 1, in each provider value and in the resolved data.
+	MaxDepth int `yaml:"max_depth,omitempty" json:"max_depth,omitempty"`
+
+	// MaxReferenceFanOut bounds the references one provider value may hold.
+	MaxReferenceFanOut int `yaml:"max_reference_fan_out,omitempty" json:"max_reference_fan_out,omitempty"`
+
+	// MaxKeys bounds the map keys, at every level, in each provider value
+	// and in the resolved data.
+	MaxKeys int `yaml:"max_keys,omitempty" json:"max_keys,omitempty"`
+}
+
+// Validate reports negative limits.
+func (l Limits) Validate() error {
+	for _, f := range []struct {
+		name  string
+		value int64
+	}{
+		{"max_file_bytes", l.MaxFileBytes},
+		{"max_depth", int64(l.MaxDepth)},
+		{"max_reference_fan_out", int64(l.MaxReferenceFanOut)},
+		{"max_keys", int64(l.MaxKeys)},
+	} {
+		if f.value < 0 {
+			return fmt.Errorf("limits %s must not be negative (got %d)", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// Names of the limits, as reported in LimitError.Limit.
+const (
+	LimitMaxFileBytes       = "MaxFileBytes"
+	LimitMaxDepth           = "MaxDepth"
+	LimitMaxReferenceFanOut = "MaxReferenceFanOut"
+	LimitMaxKeys            = "MaxKeys"
+)
+
+// LimitError reports what exceeded which of the Limits.
+type LimitError struct {
+	// Limit names the exceeded field of Limits, such as LimitMaxDepth.
+	Limit string
+
+	// Max is the configured limit.
+	Max int64
+
+	// Subject is what exceeded it: an input file path, "provider value",
+	// or "resolved data".
+	Subject string
+
+	// Size is the measured size of a file for LimitMaxFileBytes. Other
+	// checks stop at the first excess and leave it zero.
+	Size int64
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	var detail string
+	switch e.Limit {
+	case LimitMaxFileBytes:
+		detail = fmt.Sprintf("%s is %d bytes", e.Subject, e.Size)
+	case LimitMaxDepth:
+		detail = e.Subject + " nests deeper"
+	case LimitMaxReferenceFanOut:
+		detail = e.Subject + " holds more references"
+	case LimitMaxKeys:
+		detail = e.Subject + " holds more keys"
+	default:
+		detail = e.Subject + " is larger"
+	}
+	return fmt.Sprintf("%v: %s than Limits.%s allows (%d)", ErrLimitExceeded, detail, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded so errors.Is matches the sentinel.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Hint names the setting that raises the limit.
+func (e *LimitError) Hint() Hint {
+	key := e.Limit
+	switch e.Limit {
+	case LimitMaxFileBytes:
+		key = "max_file_bytes"
+	case LimitMaxDepth:
+		key = "max_depth"
+	case LimitMaxReferenceFanOut:
+		key = "max_reference_fan_out"
+	case LimitMaxKeys:
+		key = "max_keys"
+	}
+	return Hint{Text: fmt.Sprintf("raise limits.%s in .nomos/config.yaml", key)}
+}
+
+// CheckValue returns a *LimitError naming subject if v nests deeper than
+// MaxDepth or holds more than MaxKeys map keys. The walk stops at the first
+// excess, so very large values, and with MaxDepth set self-referencing
+// ones, are rejected without visiting all of them.
+func (l Limits) CheckValue(v any, subject string) error {
+	if l.MaxDepth <= 0 && l.MaxKeys <= 0 {
+		return nil
+	}
+	var keys int
+	var err error
+	var walk func(v any, depth int)
+	walk = func(v any, depth int) {
+		if err != nil {
+			return
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			if err = l.checkDepth(depth, subject); err != nil {
+				return
+			}
+			keys += len(val)
+			if l.MaxKeys > 0 && keys > l.MaxKeys {
+				err = &LimitError{Limit: LimitMaxKeys, Max: int64(l.MaxKeys), Subject: subject}
+				return
+			}
+			for _, item := range val {
+				walk(item, depth+1)
+			}
+		case []any:
+			if err = l.checkDepth(depth, subject); err != nil {
+				return
+			}
+			for _, item := range val {
+				walk(item, depth+1)
+			}
+		}
+	}
+	walk(v, 1)
+	return err
+}
+
+// checkDepth returns a *LimitError naming subject if a map or list at depth
+// nests deeper than MaxDepth.
+func (l Limits) checkDepth(depth int, subject string) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &LimitError{Limit: LimitMaxDepth, Max: int64(l.MaxDepth), Subject: subject}
+	}
+	return nil
+}
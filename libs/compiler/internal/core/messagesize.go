@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxProviderMessageBytes is the largest provider response accepted
+// when no other limit is configured: the gRPC default of 4 MiB.
+const DefaultMaxProviderMessageBytes = 4 << 20
+
+// ErrProviderMessageTooLarge indicates a provider response exceeds the gRPC
+// message size the compiler accepts.
+var ErrProviderMessageTooLarge = errors.New("provider message too large")
+
+// MessageSizeError reports a provider response rejected for its size.
+type MessageSizeError struct {
+	// Alias is the provider alias fetched from.
+	Alias string
+
+	// Path is the fetched path.
+	Path []string
+
+	// Size is the size of the response in bytes, or zero if gRPC did not
+	// report it.
+	Size int
+
+	// Max is the largest response accepted, in bytes.
+	Max int
+}
+
+// Error implements the error interface.
+func (e *MessageSizeError) Error() string {
+	var size string
+	if e.Size > 0 {
+		size = fmt.Sprintf("%d bytes, ", e.Size)
+	}
+	return fmt.Sprintf("%v: fetching @%s:%s returned %smore than the %d bytes allowed",
+		ErrProviderMessageTooLarge, e.Alias, strings.Join(e.Path, "."), size, e.Max)
+}
+
+// Unwrap returns ErrProviderMessageTooLarge so errors.Is matches the sentinel.
+func (e *MessageSizeError) Unwrap() error {
+	return ErrProviderMessageTooLarge
+}
+
+// Hint names the setting that raises the limit.
+func (e *MessageSizeError) Hint() Hint {
+	return Hint{Text: "raise max_provider_message_bytes in .nomos/config.yaml"}
+}
+
+// maxMessageBytesKey is the context key of WithMaxProviderMessageBytes.
+type maxMessageBytesKey struct{}
+
+// WithMaxProviderMessageBytes returns a context under which gRPC providers
+// accept responses of up to n bytes. A non-positive n leaves ctx unchanged.
+func WithMaxProviderMessageBytes(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxMessageBytesKey{}, n)
+}
+
+// MaxProviderMessageBytes returns the limit set on ctx by
+// WithMaxProviderMessageBytes, or DefaultMaxProviderMessageBytes.
+func MaxProviderMessageBytes(ctx context.Context) int {
+	if n, ok := ctx.Value(maxMessageBytesKey{}).(int); ok {
+		return n
+	}
+	return DefaultMaxProviderMessageBytes
+}
@@ -39,6 +39,33 @@ type ProviderWithInfo interface {
 	Info() (alias string, version string)
 }
 
+// ProviderWithDefaults is an optional interface providers can implement to
+// publish default values. The compiler merges them beneath the compiled data
+// at the lowest precedence.
+type ProviderWithDefaults interface {
+	Provider
+	// Defaults returns the default values published by Init, keyed by
+	// top-level configuration key, or nil if there are none. The compiler
+	// does not modify the returned map.
+	Defaults() map[string]any
+}
+
+// BatchProvider is an optional interface providers can implement to fetch
+// several paths in one call. The resolver fetches every path a compilation
+// needs from the provider at once, one batch per level of nested
+// references, instead of calling Fetch per path.
+type BatchProvider interface {
+	Provider
+	// FetchBatch returns the result of each path, in the order of paths.
+	FetchBatch(ctx context.Context, paths [][]string) []FetchResult
+}
+
+// FetchResult is the outcome of fetching one path of a batch.
+type FetchResult struct {
+	Value any
+	Err   error
+}
+
 // ProviderInitOptions configures a provider during initialization.
 type ProviderInitOptions struct {
 	// Alias is the provider's registered alias in the ProviderRegistry.
@@ -52,6 +79,23 @@ type ProviderInitOptions struct {
 	SourceFilePath string
 }
 
+// OverrideConfig returns config with the keys of overrides replacing its
+// own, leaving config unchanged. It returns config itself when overrides is
+// empty.
+func OverrideConfig(config, overrides map[string]any) map[string]any {
+	if len(overrides) == 0 {
+		return config
+	}
+	merged := make(map[string]any, len(config)+len(overrides))
+	for k, v := range config {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
 // ProviderConstructor is a function that creates a new Provider instance.
 type ProviderConstructor func(opts ProviderInitOptions) (Provider, error)
 
@@ -74,6 +118,42 @@ type ProviderRegistry interface {
 	RegisteredAliases() []string
 }
 
+// SourceProviderRegistry is implemented by provider registries that can
+// register the provider of a source declaration atomically. Compilations
+// sharing such a registry create each alias's provider once, however many
+// of them declare it concurrently.
+type SourceProviderRegistry interface {
+	ProviderRegistry
+
+	// RegisterOnce returns the provider registered under alias. If the
+	// alias has none, or its constructor fails, it calls create and
+	// registers the returned provider, which is already initialized.
+	// Concurrent calls for an alias wait for the one calling create.
+	RegisterOnce(ctx context.Context, alias string, create func() (Provider, error)) (Provider, error)
+}
+
+// RegisterSourceProvider returns the provider registry serves under alias,
+// creating and registering one with create if it has none. It uses
+// SourceProviderRegistry.RegisterOnce when registry implements it; with
+// other registries, concurrent calls may each call create, and the last
+// registration wins.
+func RegisterSourceProvider(ctx context.Context, registry ProviderRegistry, alias string, create func() (Provider, error)) (Provider, error) {
+	if once, ok := registry.(SourceProviderRegistry); ok {
+		return once.RegisterOnce(ctx, alias, create)
+	}
+	if provider, err := registry.GetProvider(ctx, alias); err == nil {
+		return provider, nil
+	}
+	provider, err := create()
+	if err != nil {
+		return nil, err
+	}
+	registry.Register(alias, func(_ ProviderInitOptions) (Provider, error) {
+		return provider, nil
+	})
+	return provider, nil
+}
+
 // ProviderTypeConstructor creates a Provider from configuration.
 // Used when registering provider types that can be instantiated dynamically
 // from source declarations in .csl files.
@@ -100,3 +180,27 @@ type ProviderTypeRegistry interface {
 	// RegisteredTypes returns a list of all registered provider type names.
 	RegisteredTypes() []string
 }
+
+// VersionedProviderTypeRegistry is implemented by provider type registries
+// that can create a provider from a specific version of its type. The
+// compiler uses it when source declarations pin more than one version of a
+// type, so each declaration runs the binary of its own version.
+type VersionedProviderTypeRegistry interface {
+	ProviderTypeRegistry
+
+	// CreateVersionedProvider is CreateProvider for the given version of
+	// typeName, as declared in the source declaration.
+	CreateVersionedProvider(ctx context.Context, typeName, version, alias string, config map[string]any) (Provider, error)
+}
+
+// BinaryProviderTypeRegistry is implemented by provider type registries
+// that run providers from binaries. The compiler uses it to verify the
+// binary checksum a source declaration pins before the provider starts.
+type BinaryProviderTypeRegistry interface {
+	ProviderTypeRegistry
+
+	// ProviderBinary returns the path of the binary serving the given
+	// version of typeName (any version when empty), or "" when the type
+	// runs without one.
+	ProviderBinary(ctx context.Context, typeName, version string) (string, error)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of RetryPolicy fields left zero.
+const (
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// RetryPolicy retries provider fetches that fail with a transient error:
+// an unavailable provider, a fetch that timed out, or a gRPC Unavailable,
+// ResourceExhausted, or Aborted status. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the most fetches made for one reference, including the
+	// first. Zero or one disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry, doubled before
+	// each further retry. Zero means DefaultRetryInitialBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Zero means
+	// DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// Backoff returns the wait before retry n, counting from 1.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	for i := 1; i < n && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// Transient reports whether a failed fetch may succeed when retried.
+func Transient(err error) bool {
+	if errors.Is(err, ErrProviderUnavailable) || errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := p.Backoff(n); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+	if got := (RetryPolicy{}).Backoff(1); got != DefaultRetryInitialBackoff {
+		t.Errorf("zero policy Backoff(1) = %v, want %v", got, DefaultRetryInitialBackoff)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("%w: fetch failed", ErrProviderUnavailable), true},
+		{fmt.Errorf("%w: fetch failed", ErrTimeout), true},
+		{fmt.Errorf("fetch failed: %w", status.Error(codes.ResourceExhausted, "rate limited")), true},
+		{fmt.Errorf("fetch failed: %w", status.Error(codes.PermissionDenied, "denied")), false},
+		{errors.New("path not found"), false},
+	}
+	for _, tt := range tests {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
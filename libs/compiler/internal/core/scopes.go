@@ -0,0 +1,65 @@
+package core
+
+import "path/filepath"
+
+// ProviderScopes maps the aliases used in each source file to the provider
+// registry key serving them. An alias declared at several versions is
+// registered once per version; the scopes route a reference to the
+// declaration in its own file, else to the first declaration in its
+// directory or the nearest parent directory, else to the alias itself.
+type ProviderScopes struct {
+	files map[string]map[string]string // file → alias → registry key
+	dirs  map[string]map[string]string // directory → alias → registry key
+}
+
+// NewProviderScopes returns an empty scope table.
+func NewProviderScopes() *ProviderScopes {
+	return &ProviderScopes{
+		files: make(map[string]map[string]string),
+		dirs:  make(map[string]map[string]string),
+	}
+}
+
+// Declare records that file declares alias, served by the registry under
+// key. The first declaration in a directory also serves the directory's
+// other files and its subdirectories.
+func (s *ProviderScopes) Declare(file, alias, key string) {
+	if s.files[file] == nil {
+		s.files[file] = make(map[string]string)
+	}
+	if _, ok := s.files[file][alias]; !ok {
+		s.files[file][alias] = key
+	}
+
+	dir := filepath.Dir(file)
+	if s.dirs[dir] == nil {
+		s.dirs[dir] = make(map[string]string)
+	}
+	if _, ok := s.dirs[dir][alias]; !ok {
+		s.dirs[dir][alias] = key
+	}
+}
+
+// Lookup returns the registry key serving alias in file.
+func (s *ProviderScopes) Lookup(alias, file string) string {
+	if s == nil {
+		return alias
+	}
+	if key, ok := s.files[file][alias]; ok {
+		return key
+	}
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if key, ok := s.dirs[dir][alias]; ok {
+			return key
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return alias
+		}
+	}
+}
+
+// ScopedAlias returns the registry key of a provider declared as alias at
+// version when another declaration of alias uses a different version.
+func ScopedAlias(alias, version string) string {
+	return alias + "@" + version
+}
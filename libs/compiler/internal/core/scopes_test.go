@@ -0,0 +1,40 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProviderScopes_Lookup(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "repo")
+	scopes := NewProviderScopes()
+	scopes.Declare(filepath.Join(root, "a.csl"), "cfg", "cfg")
+	scopes.Declare(filepath.Join(root, "svc", "b.csl"), "cfg", ScopedAlias("cfg", "2.0.0"))
+	scopes.Declare(filepath.Join(root, "svc", "c.csl"), "cfg", ScopedAlias("cfg", "3.0.0"))
+
+	tests := []struct {
+		name  string
+		alias string
+		file  string
+		want  string
+	}{
+		{"own declaration", "cfg", filepath.Join(root, "svc", "c.csl"), "cfg@3.0.0"},
+		{"first declaration in directory", "cfg", filepath.Join(root, "svc", "d.csl"), "cfg@2.0.0"},
+		{"parent directory", "cfg", filepath.Join(root, "svc", "nested", "e.csl"), "cfg@2.0.0"},
+		{"root directory", "cfg", filepath.Join(root, "f.csl"), "cfg"},
+		{"undeclared alias", "other", filepath.Join(root, "a.csl"), "other"},
+		{"outside declarations", "cfg", filepath.Join(string(filepath.Separator), "elsewhere", "g.csl"), "cfg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopes.Lookup(tt.alias, tt.file); got != tt.want {
+				t.Errorf("Lookup(%q, %q) = %q, want %q", tt.alias, tt.file, got, tt.want)
+			}
+		})
+	}
+
+	var nilScopes *ProviderScopes
+	if got := nilScopes.Lookup("cfg", "x.csl"); got != "cfg" {
+		t.Errorf("nil Lookup = %q, want %q", got, "cfg")
+	}
+}
@@ -34,11 +34,34 @@ func (s Severity) String() string {
 	}
 }
 
+// Warning codes emitted by the compiler. Codes are stable across releases so
+// they can be referenced by suppression directives and project configuration.
+const (
+	// CodeMissingProvider reports a reference to a provider that could not be
+	// obtained, tolerated because missing providers are allowed.
+	CodeMissingProvider = "W001"
+
+	// CodeFetchFailed reports a provider fetch failure, tolerated because
+	// missing providers are allowed.
+	CodeFetchFailed = "W002"
+
+	// CodePolicyViolation reports a violated policy whose severity is warning.
+	CodePolicyViolation = "W003"
+
+	// CodeNondeterministicOrder reports a provider that returned the lists
+	// of a value in a different order than in the previous build.
+	CodeNondeterministicOrder = "W004"
+)
+
 // Diagnostic represents a structured compiler diagnostic with source location.
 type Diagnostic struct {
 	// Severity indicates the diagnostic level.
 	Severity Severity
 
+	// Code is a stable identifier for the diagnostic class (e.g., "W001").
+	// Coded warnings can be silenced with suppression directives.
+	Code string
+
 	// Message contains the human-readable diagnostic message.
 	Message string
 
@@ -48,6 +71,15 @@ type Diagnostic struct {
 	// FormattedMessage contains the formatted output with snippet and caret.
 	// This is populated by the parser's FormatParseError for parse errors.
 	FormattedMessage string
+
+	// Cause is the error the diagnostic was made from, if any, such as a
+	// *parser.ParseError.
+	Cause error
+}
+
+// Unwrap returns the error the diagnostic was made from, if any.
+func (d *Diagnostic) Unwrap() error {
+	return d.Cause
 }
 
 // Error implements the error interface.
@@ -63,6 +95,27 @@ func (d *Diagnostic) Error() string {
 		d.Message)
 }
 
+// LocatedError is an error whose message was written for people but which
+// stems from a diagnostic, keeping the diagnostic's location reachable
+// through errors.As for machine-readable output.
+type LocatedError struct {
+	// Msg is the error message.
+	Msg string
+
+	// Diagnostic is the diagnostic the error reports.
+	Diagnostic Diagnostic
+}
+
+// Error implements the error interface.
+func (e *LocatedError) Error() string {
+	return e.Msg
+}
+
+// Unwrap returns the diagnostic the error reports.
+func (e *LocatedError) Unwrap() error {
+	return &e.Diagnostic
+}
+
 // IsError returns true if this diagnostic is an error.
 func (d *Diagnostic) IsError() bool {
 	return d.Severity == SeverityError
@@ -193,8 +246,14 @@ func splitLines(text string) []string {
 	var lines []string
 	var current strings.Builder
 
-	for _, ch := range text {
-		if ch == '\n' {
+	// Match the parser, which drops a leading byte order mark and treats
+	// CRLF and lone CR as line breaks.
+	text = strings.TrimPrefix(text, "\uFEFF")
+	for i, ch := range text {
+		if ch == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			continue
+		}
+		if ch == '\n' || ch == '\r' {
 			lines = append(lines, current.String())
 			current.Reset()
 		} else {
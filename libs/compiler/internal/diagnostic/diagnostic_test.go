@@ -136,6 +136,36 @@ func TestFormatDiagnostic_Warning(t *testing.T) {
 	}
 }
 
+// TestFormatDiagnostic_CRLF tests that snippets of CRLF sources with a byte
+// order mark carry neither into the output.
+func TestFormatDiagnostic_CRLF(t *testing.T) {
+	// Arrange
+	sourceText := "\uFEFFdatabase:\r\n  timeout: 30\r\n"
+
+	diag := &diagnostic.Diagnostic{
+		Severity: diagnostic.SeverityError,
+		Message:  "invalid timeout",
+		SourceSpan: ast.SourceSpan{
+			Filename:  "config.csl",
+			StartLine: 2,
+			StartCol:  3,
+			EndLine:   2,
+			EndCol:    14,
+		},
+	}
+
+	// Act
+	formatted := diagnostic.FormatDiagnostic(diag, sourceText, nil)
+
+	// Assert
+	if strings.ContainsAny(formatted, "\r\uFEFF") {
+		t.Errorf("Expected no carriage returns or byte order mark, got:\n%q", formatted)
+	}
+	if !strings.Contains(formatted, "   1 | database:\n   2 |   timeout: 30\n") {
+		t.Errorf("Expected lines 1 and 2 as context, got:\n%q", formatted)
+	}
+}
+
 // TestFormatDiagnostic_NoSourceText tests behavior when source text is not available.
 func TestFormatDiagnostic_NoSourceText(t *testing.T) {
 	// Arrange
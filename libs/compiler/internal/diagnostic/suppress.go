@@ -0,0 +1,128 @@
+package diagnostic
+
+import (
+	"strings"
+)
+
+// Suppression directive prefixes recognized in .csl comments.
+//
+//	key: value  # nomos:ignore W001        silences W001 on this line
+//	# nomos:ignore W001, W002              silences the codes on the next line
+//	# nomos:ignore-file W001               silences W001 anywhere in the file
+const (
+	directiveIgnore     = "nomos:ignore"
+	directiveIgnoreFile = "nomos:ignore-file"
+)
+
+// Suppressions records the warning codes silenced by suppression directives
+// in a single source file.
+type Suppressions struct {
+	file  map[string]bool
+	lines map[int]map[string]bool
+}
+
+// ParseSuppressions scans source text for nomos:ignore directives.
+//
+// A directive in a trailing comment applies to its own line. A directive on a
+// line of its own applies to the next line that is neither blank nor a comment.
+// Codes are separated by commas or whitespace and matched case-insensitively.
+func ParseSuppressions(source string) Suppressions {
+	s := Suppressions{
+		file:  make(map[string]bool),
+		lines: make(map[int]map[string]bool),
+	}
+
+	var pending []string
+	for i, line := range strings.Split(source, "\n") {
+		lineNo := i + 1
+		code, comment := splitComment(line)
+		standalone := strings.TrimSpace(code) == ""
+
+		if !standalone && len(pending) > 0 {
+			s.add(lineNo, pending)
+			pending = nil
+		}
+
+		kind, codes := parseDirective(comment)
+		switch {
+		case kind == directiveIgnoreFile:
+			for _, c := range codes {
+				s.file[c] = true
+			}
+		case kind == directiveIgnore && standalone:
+			pending = append(pending, codes...)
+		case kind == directiveIgnore:
+			s.add(lineNo, codes)
+		}
+	}
+
+	return s
+}
+
+// Suppressed reports whether code is silenced at the given 1-based line.
+func (s Suppressions) Suppressed(code string, line int) bool {
+	code = strings.ToUpper(code)
+	if code == "" {
+		return false
+	}
+	if s.file[code] {
+		return true
+	}
+	return s.lines[line][code]
+}
+
+func (s Suppressions) add(line int, codes []string) {
+	if s.lines[line] == nil {
+		s.lines[line] = make(map[string]bool)
+	}
+	for _, c := range codes {
+		s.lines[line][c] = true
+	}
+}
+
+// splitComment splits a line into its code and the text after an unquoted '#'.
+func splitComment(line string) (code, comment string) {
+	var quote rune
+	for i, ch := range line {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '#':
+			return line[:i], line[i+1:]
+		}
+	}
+	return line, ""
+}
+
+// parseDirective extracts a suppression directive and its codes from comment text.
+func parseDirective(comment string) (string, []string) {
+	comment = strings.TrimSpace(comment)
+
+	var kind string
+	switch {
+	case strings.HasPrefix(comment, directiveIgnoreFile):
+		kind = directiveIgnoreFile
+	case strings.HasPrefix(comment, directiveIgnore):
+		kind = directiveIgnore
+	default:
+		return "", nil
+	}
+
+	rest := comment[len(kind):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", nil
+	}
+
+	fields := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	codes := make([]string, 0, len(fields))
+	for _, f := range fields {
+		codes = append(codes, strings.ToUpper(f))
+	}
+	return kind, codes
+}
@@ -0,0 +1,47 @@
+package diagnostic_test
+
+import (
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+)
+
+// TestParseSuppressions tests recognition of nomos:ignore directives.
+func TestParseSuppressions(t *testing.T) {
+	source := `# nomos:ignore-file W009
+app: 'demo'  # nomos:ignore W001
+# nomos:ignore w002, W003
+
+# unrelated comment
+db: @base:db
+url: 'http://x#nomos:ignore W004'
+port: @base:port # nomos:ignored W005
+`
+	s := diagnostic.ParseSuppressions(source)
+
+	tests := []struct {
+		name string
+		code string
+		line int
+		want bool
+	}{
+		{"file-wide directive", "W009", 42, true},
+		{"trailing directive", "W001", 2, true},
+		{"trailing directive other line", "W001", 3, false},
+		{"standalone directive skips blank and comment lines", "W002", 6, true},
+		{"standalone directive second code", "W003", 6, true},
+		{"standalone directive does not apply to directive line", "W002", 3, false},
+		{"hash inside quotes is not a comment", "W004", 7, false},
+		{"directive prefix must be followed by codes", "W005", 8, false},
+		{"lowercase lookup", "w001", 2, true},
+		{"empty code", "", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Suppressed(tt.code, tt.line); got != tt.want {
+				t.Errorf("Suppressed(%q, %d) = %v, want %v", tt.code, tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Package functions implements the built-in function library callable from
+// .csl value positions, e.g. "name: fn:upper(@base:app.name)".
+//
+// Every function is pure: results depend only on the arguments, and no
+// function performs I/O or reads the clock, so compilation stays reproducible.
+package functions
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownFunction indicates a call names a function that does not exist.
+var ErrUnknownFunction = errors.New("unknown function")
+
+// function is a built-in implementation with its accepted argument count.
+type function struct {
+	minArgs int
+	maxArgs int // -1 for variadic
+	call    func(args []any) (any, error)
+}
+
+var builtins = map[string]function{
+	"upper":        {1, 1, strFn(strings.ToUpper)},
+	"lower":        {1, 1, strFn(strings.ToLower)},
+	"trim":         {1, 1, strFn(strings.TrimSpace)},
+	"replace":      {3, 3, replace},
+	"concat":       {1, -1, concat},
+	"join":         {2, 2, join},
+	"split":        {2, 2, split},
+	"base64encode": {1, 1, strFn(func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) })},
+	"base64decode": {1, 1, base64decode},
+	"sha256":       {1, 1, strFn(func(s string) string { sum := sha256.Sum256([]byte(s)); return hex.EncodeToString(sum[:]) })},
+	"cidrhost":     {2, 2, cidrhost},
+	"cidrsubnet":   {3, 3, cidrsubnet},
+	"cidrnetmask":  {1, 1, cidrnetmask},
+}
+
+// aliases maps alternative names to their canonical function.
+var aliases = map[string]string{
+	"base64": "base64encode",
+}
+
+// Call invokes the named function with already-resolved arguments.
+func Call(name string, args []any) (any, error) {
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+
+	fn, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownFunction, name, strings.Join(Names(), ", "))
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%s expects %s, got %d", name, arity(fn), len(args))
+	}
+
+	return fn.call(args)
+}
+
+// Names returns the names of all built-in functions in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(builtins)+len(aliases))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func arity(fn function) string {
+	switch {
+	case fn.maxArgs < 0:
+		return fmt.Sprintf("at least %d argument(s)", fn.minArgs)
+	case fn.minArgs == fn.maxArgs:
+		return fmt.Sprintf("%d argument(s)", fn.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", fn.minArgs, fn.maxArgs)
+	}
+}
+
+// strFn adapts a string transform to a single-argument function.
+func strFn(f func(string) string) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		s, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return f(s), nil
+	}
+}
+
+func replace(args []any) (any, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	old, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	repl, err := stringArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(s, old, repl), nil
+}
+
+func concat(args []any) (any, error) {
+	var b strings.Builder
+	for i := range args {
+		s, err := stringArg(args, i)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+func join(args []any) (any, error) {
+	list, ok := args[0].([]any)
+	if !ok {
+		return nil, fmt.Errorf("argument 1 must be a list, got %T", args[0])
+	}
+	sep, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(list))
+	for i := range list {
+		s, err := stringArg(list, i)
+		if err != nil {
+			return nil, fmt.Errorf("list element %d: %w", i, err)
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func split(args []any) (any, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, sep)
+	out := make([]any, len(parts))
+	for i, p := range parts {
+		out[i] = p
+	}
+	return out, nil
+}
+
+func base64decode(args []any) (any, error) {
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// cidrhost returns the address of host number hostnum within prefix.
+func cidrhost(args []any) (any, error) {
+	prefix, err := prefixArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	hostnum, err := intArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostnum < 0 || (hostBits < 63 && hostnum >= int64(1)<<hostBits) {
+		return nil, fmt.Errorf("host number %d does not fit in %s", hostnum, prefix)
+	}
+
+	addr, err := offsetAddr(prefix.Addr(), big.NewInt(hostnum))
+	if err != nil {
+		return nil, err
+	}
+	return addr.String(), nil
+}
+
+// cidrsubnet returns subnet netnum of prefix, extended by newbits bits.
+func cidrsubnet(args []any) (any, error) {
+	prefix, err := prefixArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	newbits, err := intArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	netnum, err := intArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	bitLen := prefix.Addr().BitLen()
+	newLen := int64(prefix.Bits()) + newbits
+	if newbits < 0 || newLen > int64(bitLen) {
+		return nil, fmt.Errorf("cannot extend %s by %d bits", prefix, newbits)
+	}
+	if netnum < 0 || (newbits < 63 && netnum >= int64(1)<<newbits) {
+		return nil, fmt.Errorf("network number %d does not fit in %d bits", netnum, newbits)
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(netnum), uint(int64(bitLen)-newLen))
+	addr, err := offsetAddr(prefix.Addr(), offset)
+	if err != nil {
+		return nil, err
+	}
+	return netip.PrefixFrom(addr, int(newLen)).String(), nil
+}
+
+// cidrnetmask returns the dotted-decimal netmask of an IPv4 prefix.
+func cidrnetmask(args []any) (any, error) {
+	prefix, err := prefixArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !prefix.Addr().Is4() {
+		return nil, fmt.Errorf("netmask is only defined for IPv4 prefixes, got %s", prefix)
+	}
+
+	mask := uint32(math.MaxUint32) << (32 - prefix.Bits())
+	if prefix.Bits() == 0 {
+		mask = 0
+	}
+	return netip.AddrFrom4([4]byte{byte(mask >> 24), byte(mask >> 16), byte(mask >> 8), byte(mask)}).String(), nil
+}
+
+// offsetAddr adds offset to addr.
+func offsetAddr(addr netip.Addr, offset *big.Int) (netip.Addr, error) {
+	sum := new(big.Int).SetBytes(addr.AsSlice())
+	sum.Add(sum, offset)
+
+	buf := make([]byte, addr.BitLen()/8)
+	if sum.BitLen() > len(buf)*8 {
+		return netip.Addr{}, fmt.Errorf("address out of range")
+	}
+	sum.FillBytes(buf)
+
+	out, _ := netip.AddrFromSlice(buf)
+	return out, nil
+}
+
+func stringArg(args []any, i int) (string, error) {
+	switch v := args[i].(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("argument %d must be a string, got %T", i+1, args[i])
+	}
+}
+
+func intArg(args []any, i int) (int64, error) {
+	switch v := args[i].(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("argument %d must be an integer, got %v", i+1, args[i])
+}
+
+func prefixArg(args []any, i int) (netip.Prefix, error) {
+	s, err := stringArg(args, i)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("argument %d must be a CIDR prefix: %w", i+1, err)
+	}
+	return prefix.Masked(), nil
+}
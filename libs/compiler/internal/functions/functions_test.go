@@ -0,0 +1,86 @@
+package functions_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/functions"
+)
+
+// TestCall tests each built-in function with valid arguments.
+func TestCall(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want any
+	}{
+		{"upper", []any{"web"}, "WEB"},
+		{"lower", []any{"WEB"}, "web"},
+		{"trim", []any{"  web \n"}, "web"},
+		{"replace", []any{"a-b-c", "-", "."}, "a.b.c"},
+		{"concat", []any{"api", ".", "example.com"}, "api.example.com"},
+		{"join", []any{[]any{"a", "b", "c"}, ","}, "a,b,c"},
+		{"split", []any{"a,b", ","}, []any{"a", "b"}},
+		{"base64encode", []any{"hello"}, "aGVsbG8="},
+		{"base64", []any{"hello"}, "aGVsbG8="},
+		{"base64decode", []any{"aGVsbG8="}, "hello"},
+		{"sha256", []any{"hello"}, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{"cidrhost", []any{"10.0.0.0/24", "5"}, "10.0.0.5"},
+		{"cidrhost", []any{"fd00::/64", int64(1)}, "fd00::1"},
+		{"cidrsubnet", []any{"10.0.0.0/16", "8", "2"}, "10.0.2.0/24"},
+		{"cidrsubnet", []any{"10.0.0.0/16", 4, 15}, "10.0.240.0/20"},
+		{"cidrsubnet", []any{"fd00::/48", "16", "1"}, "fd00:0:0:1::/64"},
+		{"cidrnetmask", []any{"172.16.0.0/12"}, "255.240.0.0"},
+		{"cidrnetmask", []any{"0.0.0.0/0"}, "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := functions.Call(tt.name, tt.args)
+			if err != nil {
+				t.Fatalf("Call(%q) unexpected error: %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Call(%q) = %#v, want %#v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCall_Errors tests that invalid calls are rejected.
+func TestCall_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   string
+		args []any
+	}{
+		{"too few arguments", "replace", []any{"a", "b"}},
+		{"too many arguments", "upper", []any{"a", "b"}},
+		{"join non-list", "join", []any{"a", ","}},
+		{"invalid base64", "base64decode", []any{"%%%"}},
+		{"invalid prefix", "cidrhost", []any{"10.0.0.0", "1"}},
+		{"host out of range", "cidrhost", []any{"10.0.0.0/24", "256"}},
+		{"non-integer host", "cidrhost", []any{"10.0.0.0/24", "one"}},
+		{"subnet too long", "cidrsubnet", []any{"10.0.0.0/30", "4", "0"}},
+		{"netnum out of range", "cidrsubnet", []any{"10.0.0.0/16", "2", "4"}},
+		{"ipv6 netmask", "cidrnetmask", []any{"fd00::/64"}},
+		{"map argument", "upper", []any{map[string]any{"a": "b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := functions.Call(tt.fn, tt.args); err == nil {
+				t.Errorf("Call(%q, %v) expected error, got nil", tt.fn, tt.args)
+			}
+		})
+	}
+}
+
+// TestCall_Unknown tests that unknown functions report ErrUnknownFunction.
+func TestCall_Unknown(t *testing.T) {
+	_, err := functions.Call("now", nil)
+	if !errors.Is(err, functions.ErrUnknownFunction) {
+		t.Errorf("expected ErrUnknownFunction, got: %v", err)
+	}
+}
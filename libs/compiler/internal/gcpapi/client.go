@@ -0,0 +1,106 @@
+// Package gcpapi is a minimal client for the Google Secret Manager API used
+// by the built-in gcp-secretmanager provider.
+//
+// Requests are authenticated by the HTTP client passed to NewClient, such
+// as one from golang.org/x/oauth2 carrying Application Default Credentials.
+package gcpapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultEndpoint is the Secret Manager API endpoint.
+const DefaultEndpoint = "https://secretmanager.googleapis.com"
+
+// ErrChecksumMismatch is returned when a payload does not match the CRC32C
+// checksum sent with it.
+var ErrChecksumMismatch = errors.New("secret payload checksum mismatch")
+
+// Error is an error response from a Google API.
+type Error struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Status, e.Message, e.StatusCode)
+}
+
+// Client calls the Secret Manager API.
+type Client struct {
+	endpoint string
+	http     *http.Client
+}
+
+// NewClient returns a client that sends requests with httpClient, which
+// adds their credentials. An empty endpoint selects DefaultEndpoint.
+func NewClient(httpClient *http.Client, endpoint string) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(cmp.Or(endpoint, DefaultEndpoint), "/"),
+		http:     httpClient,
+	}
+}
+
+// AccessSecretVersion returns the payload of version ("latest" or a version
+// number) of secret in project.
+func (c *Client) AccessSecretVersion(ctx context.Context, project, secret, version string) ([]byte, error) {
+	name := "projects/" + url.PathEscape(project) + "/secrets/" + url.PathEscape(secret) + "/versions/" + url.PathEscape(version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("secret manager request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("secret manager request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return nil, &Error{StatusCode: resp.StatusCode, Status: cmp.Or(apiErr.Error.Status, resp.Status), Message: apiErr.Error.Message}
+	}
+
+	var output struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &output); err != nil {
+		return nil, fmt.Errorf("failed to decode secret manager response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(output.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret payload: %w", err)
+	}
+	if output.Payload.DataCrc32c != "" {
+		want, err := strconv.ParseUint(output.Payload.DataCrc32c, 10, 32)
+		if err != nil || crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) != uint32(want) {
+			return nil, ErrChecksumMismatch
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,56 @@
+package gcpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAccessSecretVersion(t *testing.T) {
+	payload := []byte("s3cr3t")
+	checksum := crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		data := base64.StdEncoding.EncodeToString(payload)
+		switch r.URL.Path {
+		case "/v1/projects/p/secrets/db-password/versions/latest:access":
+			_, _ = fmt.Fprintf(w, `{"name":"projects/1/secrets/db-password/versions/2","payload":{"data":%q,"dataCrc32c":"%d"}}`, data, checksum)
+		case "/v1/projects/p/secrets/db-password/versions/1:access":
+			_, _ = fmt.Fprintf(w, `{"payload":{"data":%q}}`, base64.StdEncoding.EncodeToString([]byte("old")))
+		case "/v1/projects/p/secrets/corrupt/versions/latest:access":
+			_, _ = fmt.Fprintf(w, `{"payload":{"data":%q,"dataCrc32c":"1"}}`, data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Secret [projects/1/secrets/missing] not found or has no versions.","status":"NOT_FOUND"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})), server.URL+"/")
+
+	if got, err := client.AccessSecretVersion(context.Background(), "p", "db-password", "latest"); err != nil || string(got) != "s3cr3t" {
+		t.Errorf("AccessSecretVersion(latest) = %q, %v", got, err)
+	}
+	if got, err := client.AccessSecretVersion(context.Background(), "p", "db-password", "1"); err != nil || string(got) != "old" {
+		t.Errorf("AccessSecretVersion(1) = %q, %v", got, err)
+	}
+	if _, err := client.AccessSecretVersion(context.Background(), "p", "corrupt", "latest"); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("AccessSecretVersion(corrupt) error = %v, want %v", err, ErrChecksumMismatch)
+	}
+
+	_, err := client.AccessSecretVersion(context.Background(), "p", "missing", "latest")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != "NOT_FOUND" || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("AccessSecretVersion(missing) error = %v", err)
+	}
+}
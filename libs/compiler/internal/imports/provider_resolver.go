@@ -39,9 +39,10 @@ type ExtractedData struct {
 
 // SourceDecl represents a source provider declaration extracted from AST.
 type SourceDecl struct {
-	Alias  string
-	Type   string
-	Config map[string]any
+	Alias    string
+	Type     string
+	Checksum string // optional pinned binary checksum
+	Config   map[string]any
 }
 
 // ExtractImports extracts source declarations and data from a parsed AST.
@@ -69,9 +70,10 @@ func ExtractImports(tree *ast.AST) (ExtractedData, error) {
 				config[k] = value
 			}
 			sources = append(sources, SourceDecl{
-				Alias:  s.Alias,
-				Type:   s.Type,
-				Config: config,
+				Alias:    s.Alias,
+				Type:     s.Type,
+				Checksum: s.Checksum,
+				Config:   config,
 			})
 		}
 	}
@@ -146,14 +148,18 @@ func pathExprToString(p *ast.PathExpr) string {
 	return result
 }
 
-// ResolveImports initializes providers from source declarations and returns the file's data.
+// ResolveImports initializes providers from source declarations and returns the file's data
+// together with its source declarations, in declaration order.
 // Note: Import statements are no longer supported. References (@alias:path) are now
 // used for cross-file dependencies and are resolved separately during compilation.
-func ResolveImports(ctx context.Context, filePath string, registry ProviderRegistry, typeRegistry ProviderTypeRegistry) (map[string]any, error) {
+//
+// configOverrides replaces configuration keys of the declarations of each
+// alias it names.
+func ResolveImports(ctx context.Context, filePath string, registry ProviderRegistry, typeRegistry ProviderTypeRegistry, configOverrides map[string]map[string]any) (ExtractedData, error) {
 	// Parse the file
 	tree, diags, err := parse.ParseFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse %q: %w", filePath, err)
+		return ExtractedData{}, fmt.Errorf("failed to parse %q: %w", filePath, err)
 	}
 
 	// Check for parse errors in diagnostics
@@ -161,72 +167,76 @@ func ResolveImports(ctx context.Context, filePath string, registry ProviderRegis
 		// Return first error diagnostic as the error
 		for _, d := range diags {
 			if d.Severity == diagnostic.SeverityError {
-				return nil, fmt.Errorf("parse error in %q: %s", filePath, d.Message)
+				return ExtractedData{}, &diagnostic.LocatedError{
+					Msg:        fmt.Sprintf("parse error in %q: %s", filePath, d.Message),
+					Diagnostic: d,
+				}
 			}
 		}
 	}
 
 	// Check for nil tree (can happen with parse errors)
 	if tree == nil {
-		return nil, fmt.Errorf("failed to parse %q: no AST returned", filePath)
+		return ExtractedData{}, fmt.Errorf("failed to parse %q: no AST returned", filePath)
 	}
 
 	// Extract declarations
 	extracted, err := ExtractImports(tree)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract data for %q: %w", filePath, err)
+		return ExtractedData{}, fmt.Errorf("failed to extract data for %q: %w", filePath, err)
 	}
 
 	// Initialize providers from source declarations
 	for _, src := range extracted.Sources {
+		src.Config = core.OverrideConfig(src.Config, configOverrides[src.Alias])
 		if err := initializeProvider(ctx, src, filePath, registry, typeRegistry); err != nil {
-			return nil, fmt.Errorf("failed to initialize provider %q: %w", src.Alias, err)
+			return ExtractedData{}, fmt.Errorf("failed to initialize provider %q: %w", src.Alias, err)
 		}
 	}
 
 	// Return the file's data (references will be resolved separately)
-	return extracted.Data, nil
+	return extracted, nil
 }
 
-// initializeProvider initializes a provider from a source declaration.
+// initializeProvider initializes a provider from a source declaration,
+// unless the alias is already registered, by an earlier declaration or a
+// concurrent compilation sharing the registry.
 func initializeProvider(ctx context.Context, src SourceDecl, sourceFilePath string, registry ProviderRegistry, typeRegistry ProviderTypeRegistry) error {
-	// Check if provider already exists
-	if _, err := registry.GetProvider(ctx, src.Alias); err == nil {
-		// Already initialized
-		return nil
-	}
+	_, err := core.RegisterSourceProvider(ctx, registry, src.Alias, func() (Provider, error) {
+		// Check if type registry is available
+		if typeRegistry == nil {
+			return nil, fmt.Errorf("cannot create provider %q: type registry not provided", src.Alias)
+		}
 
-	// Check if type registry is available
-	if typeRegistry == nil {
-		return fmt.Errorf("cannot create provider %q: type registry not provided", src.Alias)
-	}
+		// A pinned checksum is verified before the binary starts
+		if src.Checksum != "" {
+			if err := core.VerifyProviderChecksum(ctx, typeRegistry, src.Alias, src.Type, "", src.Checksum); err != nil {
+				return nil, err
+			}
+		}
 
-	// Create and initialize the provider once
-	provider, err := typeRegistry.CreateProvider(ctx, src.Type, src.Alias, src.Config)
-	if err != nil {
-		return fmt.Errorf("failed to create provider %q of type %q: %w", src.Alias, src.Type, err)
-	}
+		// Create and initialize the provider once
+		provider, err := typeRegistry.CreateProvider(ctx, src.Type, src.Alias, src.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %q of type %q: %w", src.Alias, src.Type, err)
+		}
 
-	// Initialize the provider with the config from source declaration
-	initOpts := ProviderInitOptions{
-		Alias:          src.Alias,
-		Config:         src.Config,
-		SourceFilePath: sourceFilePath,
-	}
+		// Initialize the provider with the config from source declaration
+		initOpts := ProviderInitOptions{
+			Alias:          src.Alias,
+			Config:         src.Config,
+			SourceFilePath: sourceFilePath,
+		}
 
-	if err := provider.Init(ctx, initOpts); err != nil {
-		return fmt.Errorf("failed to initialize provider %q: %w", src.Alias, err)
-	}
+		if err := provider.Init(ctx, initOpts); err != nil {
+			return nil, fmt.Errorf("failed to initialize provider %q: %w", src.Alias, err)
+		}
 
-	// Register a constructor that returns the already-initialized provider instance
-	// This avoids double-initialization when the registry's GetProvider is called
-	registry.Register(src.Alias, func(_ ProviderInitOptions) (Provider, error) {
-		// Return the cached, already-initialized provider
-		// The registry will call Init on it, but we'll wrap it to make Init a no-op
+		// Registries that construct the provider on demand call Init again;
+		// the wrapper makes that a no-op
 		return &alreadyInitializedProvider{provider: provider}, nil
 	})
-
-	return nil
+	return err
 }
 
 // alreadyInitializedProvider wraps a provider that's already initialized
@@ -243,3 +253,11 @@ func (p *alreadyInitializedProvider) Init(_ context.Context, _ ProviderInitOptio
 func (p *alreadyInitializedProvider) Fetch(ctx context.Context, path []string) (any, error) {
 	return p.provider.Fetch(ctx, path)
 }
+
+// Defaults implements core.ProviderWithDefaults for the wrapped provider.
+func (p *alreadyInitializedProvider) Defaults() map[string]any {
+	if withDefaults, ok := p.provider.(core.ProviderWithDefaults); ok {
+		return withDefaults.Defaults()
+	}
+	return nil
+}
@@ -0,0 +1,542 @@
+// Package jsonpath evaluates a subset of JSONPath over decoded JSON values
+// (map[string]any, []any, string, float64, bool and nil).
+//
+// Supported syntax:
+//
+//	$                    the root value
+//	.name  ['name']      a member; bracket form for names with special characters
+//	.*  [*]              every member or element
+//	[0]  [-1]  [0,2]     elements by index; negative indexes count from the end
+//	[1:3]  [::2]         slices, with optional start, end and step
+//	['a','b']            several members
+//	..name  ..[0]  ..*   recursive descent
+//	[?(@.key)]           elements that have key
+//	[?(@.key == 'v')]    elements compared with ==, !=, <, <=, >, >= to a string,
+//	                     number, true, false or null
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// segment selects from each input node, or from each node and all of its
+// descendants.
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+// selector appends the values it selects from node to out.
+type selector interface {
+	apply(node any, out []any) []any
+}
+
+// Parse compiles expr, which must start with "$".
+func Parse(expr string) (*Path, error) {
+	p := &parser{s: strings.TrimSpace(expr)}
+	if !strings.HasPrefix(p.s, "$") {
+		return nil, fmt.Errorf("jsonpath %q: must start with '$'", expr)
+	}
+	p.i = 1
+
+	path := &Path{expr: expr}
+	for p.i < len(p.s) {
+		seg, err := p.segment()
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath %q: %w", expr, err)
+		}
+		path.segments = append(path.segments, seg)
+	}
+	return path, nil
+}
+
+// String returns the expression the path was parsed from.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Definite reports whether the path selects at most one value: it uses only
+// single member names and indexes.
+func (p *Path) Definite() bool {
+	for _, seg := range p.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the values matched in doc, in document order. Map members
+// are visited in sorted key order.
+func (p *Path) Select(doc any) []any {
+	nodes := []any{doc}
+	for _, seg := range p.segments {
+		var next []any
+		for _, node := range nodes {
+			if !seg.descendant {
+				for _, sel := range seg.selectors {
+					next = sel.apply(node, next)
+				}
+				continue
+			}
+			for _, d := range descendants(node, nil) {
+				for _, sel := range seg.selectors {
+					next = sel.apply(d, next)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants appends node and everything nested in it to out.
+func descendants(node any, out []any) []any {
+	out = append(out, node)
+	for _, child := range children(node) {
+		out = descendants(child, out)
+	}
+	return out
+}
+
+// children returns the members of a map in key order or the elements of a
+// list.
+func children(node any) []any {
+	switch v := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, k := range keys {
+			values[i] = v[k]
+		}
+		return values
+	case []any:
+		return v
+	}
+	return nil
+}
+
+type nameSelector string
+
+func (s nameSelector) apply(node any, out []any) []any {
+	if m, ok := node.(map[string]any); ok {
+		if v, ok := m[string(s)]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(node any, out []any) []any {
+	return append(out, children(node)...)
+}
+
+type indexSelector int
+
+func (s indexSelector) apply(node any, out []any) []any {
+	list, ok := node.([]any)
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(list)
+	}
+	if i >= 0 && i < len(list) {
+		out = append(out, list[i])
+	}
+	return out
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(node any, out []any) []any {
+	list, ok := node.([]any)
+	if !ok {
+		return out
+	}
+	n := len(list)
+	bound := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		v := *i
+		if v < 0 {
+			v += n
+		}
+		return max(-1, min(v, n))
+	}
+
+	if s.step > 0 {
+		for i := max(bound(s.start, 0), 0); i < bound(s.end, n); i += s.step {
+			out = append(out, list[i])
+		}
+		return out
+	}
+	for i := min(bound(s.start, n-1), n-1); i > bound(s.end, -1); i += s.step {
+		out = append(out, list[i])
+	}
+	return out
+}
+
+// filterSelector selects the members or elements of a node for which the
+// relative path exists and, with an operator, compares true to value.
+type filterSelector struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (s filterSelector) apply(node any, out []any) []any {
+	for _, child := range children(node) {
+		if s.matches(child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+func (s filterSelector) matches(node any) bool {
+	for _, name := range s.path {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+		if node, ok = m[name]; !ok {
+			return false
+		}
+	}
+	if s.op == "" {
+		return true
+	}
+
+	switch s.op {
+	case "==":
+		return equal(node, s.value)
+	case "!=":
+		return !equal(node, s.value)
+	}
+	switch want := s.value.(type) {
+	case float64:
+		got, ok := node.(float64)
+		return ok && compare(got, want, s.op)
+	case string:
+		got, ok := node.(string)
+		return ok && compare(got, want, s.op)
+	}
+	return false
+}
+
+// equal reports whether node is the scalar value.
+func equal(node, value any) bool {
+	switch node.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return node == value
+}
+
+func compare[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// parser is a cursor over an expression.
+type parser struct {
+	s string
+	i int
+}
+
+var errUnterminated = errors.New("unterminated expression")
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.i, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// consume advances past prefix if the input continues with it.
+func (p *parser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.i:], prefix) {
+		p.i += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(prefix string) error {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return errUnterminated
+	}
+	if !p.consume(prefix) {
+		return p.errorf("expected %q, found %q", prefix, p.s[p.i:])
+	}
+	return nil
+}
+
+func (p *parser) segment() (segment, error) {
+	var seg segment
+	switch {
+	case p.consume(".."):
+		seg.descendant = true
+		if p.i < len(p.s) && p.s[p.i] == '[' {
+			p.i++
+			sels, err := p.bracket()
+			seg.selectors = sels
+			return seg, err
+		}
+	case p.consume("."):
+	case p.consume("["):
+		sels, err := p.bracket()
+		seg.selectors = sels
+		return seg, err
+	default:
+		return seg, p.errorf("expected '.' or '[', found %q", p.s[p.i:])
+	}
+
+	if p.consume("*") {
+		seg.selectors = []selector{wildcardSelector{}}
+		return seg, nil
+	}
+	name := p.name()
+	if name == "" {
+		return seg, p.errorf("expected a member name")
+	}
+	seg.selectors = []selector{nameSelector(name)}
+	return seg, nil
+}
+
+// name reads a dot-notation member name.
+func (p *parser) name() string {
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(".[]() =!<>", rune(p.s[p.i])) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// bracket reads the selectors of a bracket after its "[".
+func (p *parser) bracket() ([]selector, error) {
+	p.skipSpace()
+	var sels []selector
+	switch {
+	case p.consume("*"):
+		sels = []selector{wildcardSelector{}}
+	case p.consume("?"):
+		sel, err := p.filter()
+		if err != nil {
+			return nil, err
+		}
+		sels = []selector{sel}
+	default:
+		for {
+			sel, err := p.bracketItem()
+			if err != nil {
+				return nil, err
+			}
+			sels = append(sels, sel)
+			p.skipSpace()
+			if !p.consume(",") {
+				break
+			}
+			p.skipSpace()
+		}
+	}
+	return sels, p.expect("]")
+}
+
+// bracketItem reads a quoted name, an index or a slice.
+func (p *parser) bracketItem() (selector, error) {
+	if p.i < len(p.s) && (p.s[p.i] == '\'' || p.s[p.i] == '"') {
+		name, err := p.quoted()
+		return nameSelector(name), err
+	}
+
+	var bounds [3]*int
+	colons := 0
+	for {
+		p.skipSpace()
+		if n, ok := p.integer(); ok {
+			bounds[colons] = &n
+		}
+		p.skipSpace()
+		if colons == 2 || !p.consume(":") {
+			break
+		}
+		colons++
+	}
+	if colons == 0 {
+		if bounds[0] == nil {
+			if p.i >= len(p.s) {
+				return nil, errUnterminated
+			}
+			return nil, p.errorf("expected a name, index or slice, found %q", p.s[p.i:])
+		}
+		return indexSelector(*bounds[0]), nil
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil, p.errorf("slice step must not be 0")
+	}
+	return sliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+// integer reads an optionally negative decimal integer.
+func (p *parser) integer() (int, bool) {
+	start := p.i
+	p.consume("-")
+	for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	n, err := strconv.Atoi(p.s[start:p.i])
+	if err != nil {
+		p.i = start
+		return 0, false
+	}
+	return n, true
+}
+
+// quoted reads a single- or double-quoted string; a backslash escapes the
+// next character.
+func (p *parser) quoted() (string, error) {
+	quote := p.s[p.i]
+	p.i++
+	var b strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && p.i < len(p.s):
+			b.WriteByte(p.s[p.i])
+			p.i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", errUnterminated
+}
+
+// filter reads "(@.path)" or "(@.path op literal)" after a "?".
+func (p *parser) filter() (selector, error) {
+	var sel filterSelector
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if err := p.expect("@"); err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.consume("."):
+			name := p.name()
+			if name == "" {
+				return nil, p.errorf("expected a member name")
+			}
+			sel.path = append(sel.path, name)
+			continue
+		case p.consume("["):
+			p.skipSpace()
+			if p.i >= len(p.s) || (p.s[p.i] != '\'' && p.s[p.i] != '"') {
+				return nil, p.errorf("filter paths support only member names")
+			}
+			name, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			sel.path = append(sel.path, name)
+			continue
+		}
+		break
+	}
+
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			sel.op = op
+			break
+		}
+	}
+	if sel.op != "" {
+		p.skipSpace()
+		value, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		sel.value = value
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+// literal reads a quoted string, a number, true, false or null.
+func (p *parser) literal() (any, error) {
+	if p.i >= len(p.s) {
+		return nil, errUnterminated
+	}
+	if c := p.s[p.i]; c == '\'' || c == '"' {
+		return p.quoted()
+	}
+	for word, value := range map[string]any{"true": true, "false": false, "null": nil} {
+		if p.consume(word) {
+			return value, nil
+		}
+	}
+	start := p.i
+	for p.i < len(p.s) && strings.ContainsRune("+-.0123456789eE", rune(p.s[p.i])) {
+		p.i++
+	}
+	n, err := strconv.ParseFloat(p.s[start:p.i], 64)
+	if err != nil {
+		p.i = start
+		return nil, p.errorf("expected a string, number, true, false or null, found %q", p.s[p.i:])
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const document = `{
+  "platform": {
+    "owner": "infra",
+    "regions": [
+      {"name": "eu-west-1", "primary": true, "zones": 3},
+      {"name": "us-east-1", "zones": 6},
+      {"name": "ap-south-1", "zones": 2}
+    ]
+  },
+  "environments": [
+    {"name": "dev", "database": {"host": "dev-db"}},
+    {"name": "prod", "database": {"host": "prod-db", "replicas": 2}}
+  ],
+  "odd.key": "dotted"
+}`
+
+func TestSelect(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr     string
+		want     []any
+		definite bool
+	}{
+		{expr: "$.platform.owner", want: []any{"infra"}, definite: true},
+		{expr: "$['odd.key']", want: []any{"dotted"}, definite: true},
+		{expr: "$.platform.regions[0].name", want: []any{"eu-west-1"}, definite: true},
+		{expr: "$.platform.regions[-1].name", want: []any{"ap-south-1"}, definite: true},
+		{expr: "$.platform.regions[5]", want: nil, definite: true},
+		{expr: "$.platform.regions[*].name", want: []any{"eu-west-1", "us-east-1", "ap-south-1"}},
+		{expr: "$.platform.regions[0,2].zones", want: []any{3.0, 2.0}},
+		{expr: "$.platform.regions[1:].name", want: []any{"us-east-1", "ap-south-1"}},
+		{expr: "$.platform.regions[::-1].zones", want: []any{2.0, 6.0, 3.0}},
+		{expr: "$.platform['owner','missing']", want: []any{"infra"}},
+		{expr: "$..host", want: []any{"dev-db", "prod-db"}},
+		{expr: "$.platform.regions[1].*", want: []any{"us-east-1", 6.0}},
+		{expr: "$.platform.regions[?(@.primary)].name", want: []any{"eu-west-1"}},
+		{expr: "$.platform.regions[?(@.zones >= 3)].name", want: []any{"eu-west-1", "us-east-1"}},
+		{expr: "$.environments[?(@.name == 'prod')].database.host", want: []any{"prod-db"}},
+		{expr: `$.environments[?(@.name != "prod")].name`, want: []any{"dev"}},
+		{expr: "$.environments[?(@['database'].replicas)].name", want: []any{"prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := path.Select(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+			if path.Definite() != tt.definite {
+				t.Errorf("Definite() = %v, want %v", path.Definite(), tt.definite)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"platform.owner":          "must start with '$'",
+		"$.":                      "expected a member name",
+		"$[":                      "unterminated",
+		"$['owner'":               "unterminated",
+		"$[::0]":                  "step must not be 0",
+		"$[?(@.a == )]":           "expected a string, number",
+		"$[?(@[0])]":              "only member names",
+		"$.a b":                   "expected '.' or '['",
+		"$.regions[?(@.x == 'a']": `expected ")"`,
+	}
+	for expr, want := range tests {
+		if _, err := Parse(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want containing %q", expr, err, want)
+		}
+	}
+}
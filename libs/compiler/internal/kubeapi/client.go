@@ -0,0 +1,201 @@
+package kubeapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Error is a Status error response from the API server.
+type Error struct {
+	StatusCode int
+	Reason     string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Reason, e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a NotFound response.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client reads objects from the Kubernetes API.
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient returns a client for cfg. A credential plugin in cfg runs once,
+// here.
+func NewClient(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.Server == "" {
+		return nil, errors.New("no Kubernetes API server configured")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLS != nil {
+		transport.TLSClientConfig = cfg.TLS.Clone()
+	}
+	if cfg.Exec != nil {
+		token, cert, err := execCredential(ctx, cfg.Exec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Token = token
+		if cert != nil {
+			transport.TLSClientConfig.Certificates = append(transport.TLSClientConfig.Certificates, *cert)
+		}
+	}
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 30 * time.Second, Transport: transport}}, nil
+}
+
+// Namespace returns the default namespace of the configuration, or
+// "default".
+func (c *Client) Namespace() string {
+	if c.cfg.Namespace != "" {
+		return c.cfg.Namespace
+	}
+	return "default"
+}
+
+// apiResource is an entry of an API discovery document.
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// resourcePath returns the URL path of the collection of kind in apiVersion
+// (e.g. "v1" or "apps/v1"), scoped to namespace when kind is namespaced.
+func (c *Client) resourcePath(ctx context.Context, apiVersion, kind, namespace string) (string, error) {
+	prefix := "/apis/" + apiVersion
+	if !strings.Contains(apiVersion, "/") {
+		prefix = "/api/" + apiVersion
+	}
+
+	var discovery struct {
+		Resources []apiResource `json:"resources"`
+	}
+	if err := c.get(ctx, prefix, nil, &discovery); err != nil {
+		return "", fmt.Errorf("discovering %s resources: %w", apiVersion, err)
+	}
+	for _, r := range discovery.Resources {
+		// Subresources such as pods/log share the kind of their parent
+		if r.Kind != kind || strings.Contains(r.Name, "/") {
+			continue
+		}
+		if r.Namespaced {
+			return prefix + "/namespaces/" + url.PathEscape(namespace) + "/" + r.Name, nil
+		}
+		return prefix + "/" + r.Name, nil
+	}
+	return "", fmt.Errorf("kind %s not found in API version %s", kind, apiVersion)
+}
+
+// Get returns the object name of kind in namespace. The namespace is
+// ignored for cluster-scoped kinds.
+func (c *Client) Get(ctx context.Context, apiVersion, kind, namespace, name string) (map[string]any, error) {
+	path, err := c.resourcePath(ctx, apiVersion, kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := c.get(ctx, path+"/"+url.PathEscape(name), nil, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// List returns the objects of kind in namespace matching labelSelector,
+// following pagination.
+func (c *Client) List(ctx context.Context, apiVersion, kind, namespace, labelSelector string) ([]map[string]any, error) {
+	path, err := c.resourcePath(ctx, apiVersion, kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"limit": {"500"}}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+
+	var items []map[string]any
+	for {
+		var page struct {
+			Items    []map[string]any `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := c.get(ctx, path, query, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.Metadata.Continue == "" {
+			return items, nil
+		}
+		query.Set("continue", page.Metadata.Continue)
+	}
+}
+
+// get sends an authenticated GET request and decodes the response into
+// output.
+func (c *Client) get(ctx context.Context, path string, query url.Values, output any) error {
+	endpoint := strings.TrimSuffix(c.cfg.Server, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	token := c.cfg.Token
+	if c.cfg.TokenFile != "" {
+		data, err := os.ReadFile(c.cfg.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("kubernetes request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("kubernetes request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var status struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &status)
+		if status.Reason == "" {
+			status.Reason = http.StatusText(resp.StatusCode)
+		}
+		return &Error{StatusCode: resp.StatusCode, Reason: status.Reason, Message: status.Message}
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("failed to decode kubernetes response: %w", err)
+	}
+	return nil
+}
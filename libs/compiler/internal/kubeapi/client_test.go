@@ -0,0 +1,94 @@
+package kubeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fakeCluster serves discovery, ConfigMaps in namespace "apps", the
+// cluster-scoped Namespace kind, and Deployments.
+func fakeCluster(t *testing.T, token string) string {
+	t.Helper()
+	respond := func(w http.ResponseWriter, v any) { _ = json.NewEncoder(w).Encode(v) }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			respond(w, map[string]any{"kind": "Status", "reason": "Unauthorized", "message": "Unauthorized"})
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1":
+			respond(w, map[string]any{"resources": []any{
+				map[string]any{"name": "configmaps", "kind": "ConfigMap", "namespaced": true},
+				map[string]any{"name": "secrets", "kind": "Secret", "namespaced": true},
+				map[string]any{"name": "namespaces", "kind": "Namespace", "namespaced": false},
+				map[string]any{"name": "namespaces/status", "kind": "Namespace", "namespaced": false},
+			}})
+		case "/apis/apps/v1":
+			respond(w, map[string]any{"resources": []any{
+				map[string]any{"name": "deployments", "kind": "Deployment", "namespaced": true},
+			}})
+		case "/api/v1/namespaces/apps/configmaps/app-config":
+			respond(w, map[string]any{"kind": "ConfigMap", "metadata": map[string]any{"name": "app-config"}, "data": map[string]any{"LOG_LEVEL": "debug"}})
+		case "/api/v1/namespaces/apps/configmaps":
+			if r.URL.Query().Get("labelSelector") != "team=web" {
+				t.Errorf("labelSelector = %q", r.URL.Query().Get("labelSelector"))
+			}
+			if r.URL.Query().Get("continue") == "" {
+				respond(w, map[string]any{"items": []any{map[string]any{"metadata": map[string]any{"name": "a"}}}, "metadata": map[string]any{"continue": "next"}})
+				return
+			}
+			respond(w, map[string]any{"items": []any{map[string]any{"metadata": map[string]any{"name": "b"}}}, "metadata": map[string]any{}})
+		case "/api/v1/namespaces/prod":
+			respond(w, map[string]any{"kind": "Namespace", "metadata": map[string]any{"name": "prod", "labels": map[string]any{"env": "prod"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			respond(w, map[string]any{"kind": "Status", "reason": "NotFound", "message": "not found"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestClient(t *testing.T) {
+	server := fakeCluster(t, "token")
+	client, err := NewClient(context.Background(), Config{Server: server, Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	cm, err := client.Get(ctx, "v1", "ConfigMap", "apps", "app-config")
+	if err != nil || !reflect.DeepEqual(cm["data"], map[string]any{"LOG_LEVEL": "debug"}) {
+		t.Errorf("Get(ConfigMap) = %v, %v", cm, err)
+	}
+
+	ns, err := client.Get(ctx, "v1", "Namespace", "ignored", "prod")
+	if err != nil || ns["kind"] != "Namespace" {
+		t.Errorf("Get(Namespace) = %v, %v", ns, err)
+	}
+
+	items, err := client.List(ctx, "v1", "ConfigMap", "apps", "team=web")
+	if err != nil || len(items) != 2 {
+		t.Errorf("List() = %v, %v", items, err)
+	}
+
+	if _, err := client.Get(ctx, "apps/v1", "Deployment", "apps", "missing"); !IsNotFound(err) {
+		t.Errorf("Get(missing) error = %v, want NotFound", err)
+	}
+	if _, err := client.Get(ctx, "v1", "Widget", "apps", "w"); err == nil || err.Error() != "kind Widget not found in API version v1" {
+		t.Errorf("Get(unknown kind) error = %v", err)
+	}
+
+	denied, err := NewClient(ctx, Config{Server: server, Token: "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := denied.Get(ctx, "v1", "ConfigMap", "apps", "app-config"); err == nil || IsNotFound(err) {
+		t.Errorf("Get(unauthorized) error = %v", err)
+	}
+}
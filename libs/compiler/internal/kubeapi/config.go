@@ -0,0 +1,358 @@
+// Package kubeapi is a minimal read-only client for the Kubernetes API used
+// by the built-in kubernetes provider.
+//
+// Connection settings come from a kubeconfig file or, inside a pod, from the
+// pod's service account; see LoadConfig.
+package kubeapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoConfig is returned when neither a kubeconfig file nor an in-cluster
+// service account is available.
+var ErrNoConfig = errors.New("no Kubernetes configuration found: set 'kubeconfig' or KUBECONFIG, or run inside a cluster")
+
+// serviceAccountDir holds the credentials mounted into pods, a variable so
+// tests can replace it.
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Config is what a Client needs to reach the API server.
+type Config struct {
+	// Server is the API server URL.
+	Server string
+
+	// Namespace is the default namespace of the selected context.
+	Namespace string
+
+	// Token is a bearer token; TokenFile is re-read on every request so
+	// rotated service account tokens are picked up.
+	Token     string
+	TokenFile string
+
+	// Exec runs a credential plugin for a token or client certificate.
+	Exec *ExecConfig
+
+	// TLS holds the CA, client certificate, and server name settings.
+	TLS *tls.Config
+}
+
+// LoadOptions selects a kubeconfig file and context over the defaults.
+type LoadOptions struct {
+	Kubeconfig string
+	Context    string
+}
+
+// kubeconfig is the subset of the kubeconfig file format the client uses.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  *ExecConfig `yaml:"exec"`
+			AuthProvider          any         `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// ExecConfig is a kubeconfig credential plugin, as used by the EKS, GKE, and
+// AKS command-line tools.
+type ExecConfig struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+
+	// dir resolves a relative command against the kubeconfig's directory.
+	dir string
+}
+
+// LoadConfig resolves connection settings, in order:
+//
+//  1. The kubeconfig file opts.Kubeconfig, or the files listed in
+//     KUBECONFIG (the first file to define a setting wins)
+//  2. The in-cluster service account, when KUBERNETES_SERVICE_HOST is set
+//  3. ~/.kube/config
+//
+// opts.Context selects a context other than the current one.
+func LoadConfig(opts LoadOptions) (Config, error) {
+	var paths []string
+	switch {
+	case opts.Kubeconfig != "":
+		paths = []string{opts.Kubeconfig}
+	case os.Getenv("KUBECONFIG") != "":
+		paths = filepath.SplitList(os.Getenv("KUBECONFIG"))
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && opts.Context == "":
+		return inClusterConfig()
+	default:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Config{}, ErrNoConfig
+		}
+		paths = []string{filepath.Join(home, ".kube", "config")}
+	}
+	return kubeconfigConfig(paths, opts.Context)
+}
+
+func inClusterConfig() (Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	tokenFile := filepath.Join(serviceAccountDir, "token")
+	if _, err := os.Stat(tokenFile); err != nil {
+		return Config{}, ErrNoConfig
+	}
+	pool, err := certPool(filepath.Join(serviceAccountDir, "ca.crt"), "")
+	if err != nil {
+		return Config{}, err
+	}
+	namespace, _ := os.ReadFile(filepath.Join(serviceAccountDir, "namespace")) //nolint:gosec // G304: Standard service account mount
+	return Config{
+		Server:    "https://" + net.JoinHostPort(host, port),
+		Namespace: strings.TrimSpace(string(namespace)),
+		TokenFile: tokenFile,
+		TLS:       &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+	}, nil
+}
+
+func kubeconfigConfig(paths []string, contextName string) (Config, error) {
+	var merged kubeconfig
+	dirs := map[string]string{} // cluster/user name -> directory of the defining file
+	found := false
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: User-selected kubeconfig
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		var kc kubeconfig
+		if err := yaml.Unmarshal(data, &kc); err != nil {
+			return Config{}, fmt.Errorf("invalid kubeconfig %s: %w", path, err)
+		}
+		found = true
+		dir := filepath.Dir(path)
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = kc.CurrentContext
+		}
+		for _, c := range kc.Clusters {
+			if _, ok := dirs["cluster/"+c.Name]; !ok {
+				dirs["cluster/"+c.Name] = dir
+				merged.Clusters = append(merged.Clusters, c)
+			}
+		}
+		for _, u := range kc.Users {
+			if _, ok := dirs["user/"+u.Name]; !ok {
+				dirs["user/"+u.Name] = dir
+				merged.Users = append(merged.Users, u)
+			}
+		}
+		merged.Contexts = append(merged.Contexts, kc.Contexts...)
+	}
+	if !found {
+		return Config{}, ErrNoConfig
+	}
+
+	if contextName == "" {
+		contextName = merged.CurrentContext
+	}
+	if contextName == "" {
+		return Config{}, errors.New("kubeconfig has no current-context; set 'context'")
+	}
+	var cfg Config
+	var clusterName, userName string
+	for _, c := range merged.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, cfg.Namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			break
+		}
+	}
+	if clusterName == "" {
+		return Config{}, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	clusterFound := false
+	for _, c := range merged.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		clusterFound = true
+		dir := dirs["cluster/"+c.Name]
+		cfg.Server = c.Cluster.Server
+		tlsConfig.ServerName = c.Cluster.TLSServerName
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify //nolint:gosec // G402: Explicitly requested by the kubeconfig
+		if c.Cluster.CertificateAuthority != "" || c.Cluster.CertificateAuthorityData != "" {
+			pool, err := certPool(resolvePath(dir, c.Cluster.CertificateAuthority), c.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return Config{}, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !clusterFound {
+		return Config{}, fmt.Errorf("cluster %q of context %q not found in kubeconfig", clusterName, contextName)
+	}
+
+	for _, u := range merged.Users {
+		if u.Name != userName {
+			continue
+		}
+		dir := dirs["user/"+u.Name]
+		if u.User.AuthProvider != nil {
+			return Config{}, fmt.Errorf("user %q uses an auth-provider, which is not supported; use an exec credential plugin", userName)
+		}
+		cfg.Token = u.User.Token
+		cfg.TokenFile = resolvePath(dir, u.User.TokenFile)
+		if u.User.Exec != nil {
+			cfg.Exec = u.User.Exec
+			cfg.Exec.dir = dir
+		}
+		if u.User.ClientCertificate != "" || u.User.ClientCertificateData != "" {
+			certPEM, err := fileOrData(resolvePath(dir, u.User.ClientCertificate), u.User.ClientCertificateData)
+			if err != nil {
+				return Config{}, err
+			}
+			keyPEM, err := fileOrData(resolvePath(dir, u.User.ClientKey), u.User.ClientKeyData)
+			if err != nil {
+				return Config{}, err
+			}
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid client certificate for user %q: %w", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+	cfg.TLS = tlsConfig
+	return cfg, nil
+}
+
+// execCredential runs the credential plugin and returns its token and client
+// certificate.
+func execCredential(ctx context.Context, e *ExecConfig) (token string, cert *tls.Certificate, err error) {
+	command := e.Command
+	if strings.Contains(command, string(filepath.Separator)) {
+		command = resolvePath(e.dir, command)
+	}
+	cmd := exec.CommandContext(ctx, command, e.Args...) //nolint:gosec // G204: Command configured in the user's kubeconfig
+	cmd.Env = os.Environ()
+	for _, env := range e.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	info, err := json.Marshal(map[string]any{
+		"apiVersion": e.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]any{"interactive": false},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	cmd.Env = append(cmd.Env, "KUBERNETES_EXEC_INFO="+string(info))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("credential plugin %s failed: %w: %s", e.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	var doc struct {
+		Status struct {
+			Token                 string `json:"token"`
+			ClientCertificateData string `json:"clientCertificateData"`
+			ClientKeyData         string `json:"clientKeyData"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		return "", nil, fmt.Errorf("credential plugin %s returned invalid output: %w", e.Command, err)
+	}
+	if doc.Status.ClientCertificateData != "" {
+		c, err := tls.X509KeyPair([]byte(doc.Status.ClientCertificateData), []byte(doc.Status.ClientKeyData))
+		if err != nil {
+			return "", nil, fmt.Errorf("credential plugin %s returned an invalid certificate: %w", e.Command, err)
+		}
+		cert = &c
+	}
+	if doc.Status.Token == "" && cert == nil {
+		return "", nil, fmt.Errorf("credential plugin %s returned no credentials", e.Command)
+	}
+	return doc.Status.Token, cert, nil
+}
+
+// certPool builds a pool from a PEM file or base64-encoded PEM data.
+func certPool(file, data string) (*x509.CertPool, error) {
+	pemData, err := fileOrData(file, data)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, errors.New("no certificates found in certificate-authority")
+	}
+	return pool, nil
+}
+
+// fileOrData returns base64-decoded data if set, and otherwise the contents
+// of file.
+func fileOrData(file, data string) ([]byte, error) {
+	if data != "" {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 kubeconfig data: %w", err)
+		}
+		return decoded, nil
+	}
+	content, err := os.ReadFile(file) //nolint:gosec // G304: File referenced by the user's kubeconfig
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return content, nil
+}
+
+// resolvePath resolves a relative kubeconfig path against dir.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,170 @@
+package kubeapi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKubeconfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+    insecure-skip-tls-verify: true
+contexts:
+- name: dev
+  context: {cluster: dev, user: dev, namespace: apps}
+- name: prod
+  context: {cluster: prod, user: prod}
+- name: legacy
+  context: {cluster: prod, user: legacy}
+users:
+- name: dev
+  user: {token: dev-token}
+- name: prod
+  user: {tokenFile: prod-token}
+- name: legacy
+  user:
+    auth-provider: {name: gcp}
+`
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	dir := t.TempDir()
+	path := writeKubeconfig(t, dir, "config", testKubeconfig)
+
+	cfg, err := LoadConfig(LoadOptions{Kubeconfig: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server != "https://dev.example.com" || cfg.Token != "dev-token" || cfg.Namespace != "apps" {
+		t.Errorf("current context = %+v", cfg)
+	}
+
+	cfg, err = LoadConfig(LoadOptions{Kubeconfig: path, Context: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server != "https://prod.example.com" || cfg.TokenFile != filepath.Join(dir, "prod-token") || !cfg.TLS.InsecureSkipVerify {
+		t.Errorf("prod context = %+v", cfg)
+	}
+
+	if _, err := LoadConfig(LoadOptions{Kubeconfig: path, Context: "legacy"}); err == nil || !strings.Contains(err.Error(), "auth-provider") {
+		t.Errorf("legacy context error = %v", err)
+	}
+	if _, err := LoadConfig(LoadOptions{Kubeconfig: path, Context: "nope"}); err == nil || !strings.Contains(err.Error(), `context "nope" not found`) {
+		t.Errorf("missing context error = %v", err)
+	}
+}
+
+func TestLoadConfig_KubeconfigList(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "first", "current-context: prod\n")
+	second := writeKubeconfig(t, dir, "second", testKubeconfig)
+	t.Setenv("KUBECONFIG", first+string(os.PathListSeparator)+filepath.Join(dir, "missing")+string(os.PathListSeparator)+second)
+
+	cfg, err := LoadConfig(LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server != "https://prod.example.com" {
+		t.Errorf("Server = %q, want the first file's current-context", cfg.Server)
+	}
+}
+
+func TestLoadConfig_InCluster(t *testing.T) {
+	dir := t.TempDir()
+	old := serviceAccountDir
+	serviceAccountDir = dir
+	t.Cleanup(func() { serviceAccountDir = old })
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	if _, err := LoadConfig(LoadOptions{}); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("LoadConfig() without token error = %v, want %v", err, ErrNoConfig)
+	}
+
+	writeKubeconfig(t, dir, "token", "sa-token\n")
+	writeKubeconfig(t, dir, "namespace", "apps")
+	writeKubeconfig(t, dir, "ca.crt", testCA)
+	cfg, err := LoadConfig(LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server != "https://10.0.0.1:443" || cfg.Namespace != "apps" || cfg.TokenFile != filepath.Join(dir, "token") || cfg.TLS.RootCAs == nil {
+		t.Errorf("in-cluster config = %+v", cfg)
+	}
+}
+
+func TestExecCredential(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "get-token")
+	body := "#!/bin/sh\n[ \"$1\" = \"--cluster\" ] && [ \"$TEAM\" = \"web\" ] || exit 1\n" +
+		"echo '{\"apiVersion\":\"client.authentication.k8s.io/v1\",\"kind\":\"ExecCredential\",\"status\":{\"token\":\"exec-token\"}}'\n"
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil { //nolint:gosec // G306: Test script must be executable
+		t.Fatal(err)
+	}
+	server := fakeCluster(t, "exec-token")
+	path := writeKubeconfig(t, dir, "config", `current-context: eks
+clusters:
+- name: eks
+  cluster: {server: `+server+`}
+contexts:
+- name: eks
+  context: {cluster: eks, user: eks}
+users:
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: ./get-token
+      args: [--cluster]
+      env: [{name: TEAM, value: web}]
+`)
+
+	cfg, err := LoadConfig(LoadOptions{Kubeconfig: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	if _, err := client.Get(context.Background(), "v1", "ConfigMap", "apps", "app-config"); err != nil {
+		t.Errorf("Get() with exec token error: %v", err)
+	}
+}
+
+// testCA is a self-signed certificate used only to populate a CA pool.
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
+7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
+5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
+BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
+NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
+Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
+6MF9+Yw1Yy0t
+-----END CERTIFICATE-----
+`
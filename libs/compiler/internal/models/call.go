@@ -0,0 +1,11 @@
+package models
+
+import "github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+
+// Call is a built-in function call awaiting evaluation. Args may contain
+// unresolved references, which are resolved before the function runs.
+type Call struct {
+	Name       string
+	Args       []any
+	SourceSpan ast.SourceSpan
+}
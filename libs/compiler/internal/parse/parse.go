@@ -18,6 +18,12 @@ import (
 //
 //nolint:revive // Parse prefix is part of public API and matches parser package naming
 func ParseFile(path string) (*ast.AST, []diagnostic.Diagnostic, error) {
+	return ParseFileWith(parser.NewParser(), path)
+}
+
+// ParseFileWith is like ParseFile but parses with p, so callers can reuse a
+// configured parser across files. p must not be used concurrently.
+func ParseFileWith(p *parser.Parser, path string) (*ast.AST, []diagnostic.Diagnostic, error) {
 	// Read source text for error formatting
 	sourceBytes, err := os.ReadFile(path) //nolint:gosec // G304: Path from compilation input, validated by caller
 	if err != nil {
@@ -38,8 +44,8 @@ func ParseFile(path string) (*ast.AST, []diagnostic.Diagnostic, error) {
 	}
 	sourceText := string(sourceBytes)
 
-	// Call parser
-	astNode, err := parser.ParseFile(path)
+	// Parse the text already read instead of opening the file again
+	astNode, err := p.Parse(strings.NewReader(sourceText), path)
 	if err != nil {
 		// Transform parser error to diagnostic
 		diags := transformParseError(err, sourceText)
@@ -98,6 +104,7 @@ func transformParseError(err error, sourceText string) []diagnostic.Diagnostic {
 			Message:          parseErr.Message(),
 			SourceSpan:       parseErr.Span(),
 			FormattedMessage: formattedMsg,
+			Cause:            parseErr,
 		}
 		return []diagnostic.Diagnostic{diag}
 	}
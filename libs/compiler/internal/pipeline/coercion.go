@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// CoercionMode selects which string scalars CoerceTypes converts.
+type CoercionMode int
+
+const (
+	// CoerceNone leaves every string unchanged.
+	CoerceNone CoercionMode = iota
+
+	// CoerceStrict converts only canonical forms: "8080", "-1", "3.14",
+	// "true", and "false". Leading zeros, signs, exponents, and other
+	// spellings stay strings.
+	CoerceStrict
+
+	// CoerceLenient additionally converts anything that parses as a finite
+	// decimal number ("+1", "007", "1e3") and the booleans "yes", "no",
+	// "on", and "off" in any case.
+	CoerceLenient
+)
+
+var (
+	canonicalInt   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	canonicalFloat = regexp.MustCompile(`^-?(0|[1-9][0-9]*)\.[0-9]+$`)
+)
+
+// CoerceTypes returns a copy of data with numeric and boolean strings
+// converted to int64, float64, or bool according to mode. Secrets are left
+// untouched.
+func CoerceTypes(data map[string]any, mode CoercionMode) map[string]any {
+	if mode == CoerceNone {
+		return data
+	}
+	return coerceValue(data, mode).(map[string]any)
+}
+
+func coerceValue(val any, mode CoercionMode) any {
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			out[k] = coerceValue(child, mode)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = coerceValue(child, mode)
+		}
+		return out
+	case string:
+		return coerceString(v, mode)
+	case models.Secret:
+		return v
+	default:
+		return val
+	}
+}
+
+func coerceString(s string, mode CoercionMode) any {
+	switch mode {
+	case CoerceStrict:
+		switch {
+		case s == "true":
+			return true
+		case s == "false":
+			return false
+		case canonicalInt.MatchString(s):
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		case canonicalFloat.MatchString(s):
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		}
+
+	case CoerceLenient:
+		switch strings.ToLower(s) {
+		case "true", "yes", "on":
+			return true
+		case "false", "no", "off":
+			return false
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		// ParseFloat also accepts hex, underscores, "inf", and "nan"; only
+		// plain decimal forms are coerced.
+		if strings.ContainsAny(s, "xX_pPiInN") {
+			return s
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+			return f
+		}
+	}
+	return s
+}
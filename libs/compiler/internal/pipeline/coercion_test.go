@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+func TestCoerceTypes(t *testing.T) {
+	inputs := []string{"8080", "-1", "3.14", "true", "false", "007", "+1", "1e3", "Yes", "OFF", "0x10", "NaN", "inf", "1_000", " 1", "v1", ""}
+
+	tests := []struct {
+		name string
+		mode CoercionMode
+		want []any
+	}{
+		{
+			name: "none",
+			mode: CoerceNone,
+			want: []any{"8080", "-1", "3.14", "true", "false", "007", "+1", "1e3", "Yes", "OFF", "0x10", "NaN", "inf", "1_000", " 1", "v1", ""},
+		},
+		{
+			name: "strict",
+			mode: CoerceStrict,
+			want: []any{int64(8080), int64(-1), 3.14, true, false, "007", "+1", "1e3", "Yes", "OFF", "0x10", "NaN", "inf", "1_000", " 1", "v1", ""},
+		},
+		{
+			name: "lenient",
+			mode: CoerceLenient,
+			want: []any{int64(8080), int64(-1), 3.14, true, false, int64(7), int64(1), 1000.0, true, false, "0x10", "NaN", "inf", "1_000", " 1", "v1", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, in := range inputs {
+				got := CoerceTypes(map[string]any{"v": in}, tt.mode)["v"]
+				if !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("coerce %q = %#v, want %#v", in, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCoerceTypes_Nested(t *testing.T) {
+	data := map[string]any{
+		"server": map[string]any{"port": "8080", "tags": []any{"1", "a"}},
+		"secret": models.Secret{Value: "42"},
+	}
+
+	got := CoerceTypes(data, CoerceStrict)
+
+	want := map[string]any{
+		"server": map[string]any{"port": int64(8080), "tags": []any{int64(1), "a"}},
+		"secret": models.Secret{Value: "42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CoerceTypes() = %#v, want %#v", got, want)
+	}
+	if data["server"].(map[string]any)["port"] != "8080" {
+		t.Error("CoerceTypes modified its input")
+	}
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/parse"
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// ParsedFile is the result of parsing one input file.
+type ParsedFile struct {
+	// Path is the input file path.
+	Path string
+
+	// AST is the parsed tree, or nil if the file had parse errors.
+	AST *ast.AST
+
+	// Diagnostics holds parse errors reported for the file.
+	Diagnostics []diagnostic.Diagnostic
+
+	// Err is a fatal error that prevented parsing, including cancellation.
+	Err error
+}
+
+// ParseFiles parses files with up to workers goroutines and returns one
+// result per file in input order, so callers that merge results in that
+// order stay deterministic. A workers value of 0 or less uses GOMAXPROCS.
+//
+// Files not yet started when ctx is cancelled report ctx.Err().
+func ParseFiles(ctx context.Context, files []string, workers int) []ParsedFile {
+	results := make([]ParsedFile, len(files))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker owns its parser, so the arena's scratch buffers are
+			// reused across that worker's files.
+			p := parser.NewParser(parser.WithNodeArena(true))
+			for i := range jobs {
+				results[i] = parseOne(ctx, p, files[i])
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func parseOne(ctx context.Context, p *parser.Parser, path string) ParsedFile {
+	if err := ctx.Err(); err != nil {
+		return ParsedFile{Path: path, Err: err}
+	}
+	tree, diags, err := parse.ParseFileWith(p, path)
+	return ParsedFile{Path: path, AST: tree, Diagnostics: diags, Err: err}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+func TestParseFiles_PreservesOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	var files []string
+	for i := 0; i < 50; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("f%02d.csl", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("section%d:\n  key: 'v'\n", i)), 0600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		files = append(files, path)
+	}
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			results := ParseFiles(context.Background(), files, workers)
+			if len(results) != len(files) {
+				t.Fatalf("got %d results, want %d", len(results), len(files))
+			}
+			for i, r := range results {
+				if r.Path != files[i] || r.Err != nil || r.AST == nil {
+					t.Fatalf("result %d = {Path: %q, Err: %v, AST: %v}", i, r.Path, r.Err, r.AST != nil)
+				}
+				want := fmt.Sprintf("section%d", i)
+				if got := r.AST.Statements[0].(*ast.SectionDecl).Name; got != want {
+					t.Errorf("result %d section = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFiles_Diagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+	good := filepath.Join(tmpDir, "good.csl")
+	bad := filepath.Join(tmpDir, "bad.csl")
+	if err := os.WriteFile(good, []byte("app: 'x'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("app: 'unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	results := ParseFiles(context.Background(), []string{bad, good}, 2)
+
+	if results[0].AST != nil || len(results[0].Diagnostics) == 0 {
+		t.Errorf("bad file: AST = %v, diagnostics = %v", results[0].AST, results[0].Diagnostics)
+	}
+	if results[1].AST == nil || len(results[1].Diagnostics) != 0 {
+		t.Errorf("good file: AST = %v, diagnostics = %v", results[1].AST, results[1].Diagnostics)
+	}
+}
+
+func TestParseFiles_Cancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := os.WriteFile(path, []byte("app: 'x'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := ParseFiles(ctx, []string{path}, 1)
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("Err = %v, want context.Canceled", results[0].Err)
+	}
+}
+
+func TestParseFiles_Empty(t *testing.T) {
+	if results := ParseFiles(context.Background(), nil, 0); len(results) != 0 {
+		t.Errorf("got %d results, want 0", len(results))
+	}
+}
@@ -3,26 +3,38 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
-	"github.com/autonomous-bits/nomos/libs/compiler/internal/parse"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
-// InitializeProvidersFromSources parses input files, extracts source declarations,
-// and initializes providers in the registry. This ensures providers are available
-// for inline reference resolution, even without import statements.
+// InitializeProvidersFromSources extracts source declarations from parsed input
+// files and initializes providers in the registry. This ensures providers are
+// available for inline reference resolution, even without import statements.
+//
+// An alias declared at one version is registered once, by its first
+// declaration. An alias declared at several versions is registered once per
+// version: the first declaration's version under the alias itself, others
+// under core.ScopedAlias. Types declared at several versions are created
+// through core.VersionedProviderTypeRegistry so that each declaration runs
+// its own version. The returned scopes route each file's references to the
+// declaration serving it. configOverrides replaces configuration keys of
+// the declarations of each alias it names.
 func InitializeProvidersFromSources(
 	ctx context.Context,
-	inputFiles []string,
+	files []ParsedFile,
 	registry core.ProviderRegistry,
 	typeRegistry core.ProviderTypeRegistry,
-) error {
-	for _, filePath := range inputFiles {
-		// Parse the file
-		tree, _, err := parse.ParseFile(filePath)
-		if err != nil {
-			// Skip files that can't be parsed - they'll fail in the main compilation flow
+	configOverrides map[string]map[string]any,
+) (*core.ProviderScopes, error) {
+	aliasVersions, typeVersions := declaredVersions(files)
+	scopes := core.NewProviderScopes()
+
+	for _, file := range files {
+		filePath, tree := file.Path, file.AST
+		if tree == nil {
+			// Skip files that can't be parsed - they fail in the main compilation flow
 			continue
 		}
 
@@ -33,10 +45,13 @@ func InitializeProvidersFromSources(
 				continue
 			}
 
-			// Check if provider is already registered
-			if _, err := registry.GetProvider(ctx, sourceDecl.Alias); err == nil {
-				// Already registered, skip
-				continue
+			// Versions after the first of an alias get their own key
+			key := sourceDecl.Alias
+			if versions := aliasVersions[sourceDecl.Alias]; len(versions) > 1 {
+				if sourceDecl.Version != versions[0] {
+					key = core.ScopedAlias(sourceDecl.Alias, sourceDecl.Version)
+				}
+				scopes.Declare(filePath, sourceDecl.Alias, key)
 			}
 
 			// Convert config expressions to values
@@ -44,34 +59,100 @@ func InitializeProvidersFromSources(
 			for k, expr := range sourceDecl.Config {
 				config[k] = exprToConfigValue(expr)
 			}
+			config = core.OverrideConfig(config, configOverrides[sourceDecl.Alias])
 
-			// Create provider from type using the type registry
-			provider, err := typeRegistry.CreateProvider(ctx, sourceDecl.Type, sourceDecl.Alias, config)
+			// Create and initialize the provider unless the alias is
+			// already registered, by an earlier file or a concurrent
+			// compilation sharing the registry
+			_, err := core.RegisterSourceProvider(ctx, registry, key, func() (core.Provider, error) {
+				return createSourceProvider(ctx, typeRegistry, sourceDecl, key, config, typeVersions, filePath)
+			})
 			if err != nil {
-				return fmt.Errorf("failed to create provider %q of type %q: %w", sourceDecl.Alias, sourceDecl.Type, err)
+				return nil, err
 			}
+		}
+	}
 
-			// Initialize the provider
-			initOpts := core.ProviderInitOptions{
-				Alias:          sourceDecl.Alias,
-				Config:         config,
-				SourceFilePath: filePath,
-			}
+	return scopes, nil
+}
 
-			if err := provider.Init(ctx, initOpts); err != nil {
-				return fmt.Errorf("failed to initialize provider %q: %w", sourceDecl.Alias, err)
-			}
+// createSourceProvider creates the provider of sourceDecl under key from
+// typeRegistry and initializes it with config.
+func createSourceProvider(
+	ctx context.Context,
+	typeRegistry core.ProviderTypeRegistry,
+	sourceDecl *ast.SourceDecl,
+	key string,
+	config map[string]any,
+	typeVersions map[string][]string,
+	filePath string,
+) (core.Provider, error) {
+	// A type declared at several versions runs the binary of the declared
+	// one
+	multiVersion := len(typeVersions[sourceDecl.Type]) > 1
+	version := ""
+	if multiVersion {
+		version = sourceDecl.Version
+	}
 
-			// Register the provider with a constructor function
-			// Capture the provider in a closure
-			capturedProvider := provider
-			registry.Register(sourceDecl.Alias, func(_ core.ProviderInitOptions) (core.Provider, error) {
-				return capturedProvider, nil
-			})
+	// A pinned checksum is verified before the binary starts
+	if sourceDecl.Checksum != "" {
+		if err := core.VerifyProviderChecksum(ctx, typeRegistry, sourceDecl.Alias, sourceDecl.Type, version, sourceDecl.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create provider from type using the type registry
+	var provider core.Provider
+	var err error
+	if multiVersion {
+		versioned, ok := typeRegistry.(core.VersionedProviderTypeRegistry)
+		if !ok {
+			return nil, fmt.Errorf("provider %q of type %q is declared at versions %v, but the provider type registry cannot select versions",
+				sourceDecl.Alias, sourceDecl.Type, typeVersions[sourceDecl.Type])
 		}
+		provider, err = versioned.CreateVersionedProvider(ctx, sourceDecl.Type, sourceDecl.Version, key, config)
+	} else {
+		provider, err = typeRegistry.CreateProvider(ctx, sourceDecl.Type, key, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider %q of type %q: %w", sourceDecl.Alias, sourceDecl.Type, err)
 	}
 
-	return nil
+	// Initialize the provider
+	initOpts := core.ProviderInitOptions{
+		Alias:          sourceDecl.Alias,
+		Config:         config,
+		SourceFilePath: filePath,
+	}
+
+	if err := provider.Init(ctx, initOpts); err != nil {
+		return nil, fmt.Errorf("failed to initialize provider %q: %w", sourceDecl.Alias, err)
+	}
+	return provider, nil
+}
+
+// declaredVersions returns the distinct versions each alias and each type
+// is declared at, in declaration order.
+func declaredVersions(files []ParsedFile) (aliases, types map[string][]string) {
+	aliases = make(map[string][]string)
+	types = make(map[string][]string)
+	for _, file := range files {
+		if file.AST == nil {
+			continue
+		}
+		for _, stmt := range file.AST.Statements {
+			if decl, ok := stmt.(*ast.SourceDecl); ok {
+				if !slices.Contains(aliases[decl.Alias], decl.Version) {
+					aliases[decl.Alias] = append(aliases[decl.Alias], decl.Version)
+				}
+				if !slices.Contains(types[decl.Type], decl.Version) {
+					types[decl.Type] = append(types[decl.Type], decl.Version)
+				}
+			}
+		}
+	}
+	return aliases, types
 }
 
 // exprToConfigValue converts an AST expression to a configuration value.
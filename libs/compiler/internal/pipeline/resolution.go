@@ -3,8 +3,10 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/resolver"
 )
 
@@ -12,7 +14,25 @@ import (
 type ResolveOptions struct {
 	ProviderRegistry     core.ProviderRegistry
 	AllowMissingProvider bool
-	OnWarning            func(string)
+	FetchTimeout         time.Duration
+	Retry                core.RetryPolicy
+	MaxConcurrency       int
+	OnWarning            func(diagnostic.Diagnostic)
+
+	// OnFailure collects failed references instead of stopping at the
+	// first; see resolver.ResolverOptions.
+	OnFailure func(error)
+
+	// Scopes routes references to aliases declared at several versions;
+	// nil resolves every alias globally.
+	Scopes *core.ProviderScopes
+
+	// Limits bounds each fetched value; see resolver.ResolverOptions.
+	Limits core.Limits
+
+	// OnReference and OnMerge observe resolution; see resolver.ResolverOptions.
+	OnReference func(resolver.ReferenceEvent)
+	OnMerge     func(resolver.MergeEvent)
 }
 
 // ResolveReferences resolves all ReferenceExpr nodes in the data using the resolver.
@@ -30,7 +50,15 @@ func ResolveReferences(ctx context.Context, data map[string]any, opts ResolveOpt
 	resolverOpts := resolver.ResolverOptions{
 		ProviderRegistry:     registryAdapter,
 		AllowMissingProvider: opts.AllowMissingProvider,
+		FetchTimeout:         opts.FetchTimeout,
+		Retry:                opts.Retry,
+		MaxConcurrency:       opts.MaxConcurrency,
 		OnWarning:            opts.OnWarning,
+		OnFailure:            opts.OnFailure,
+		ProviderScope:        opts.Scopes.Lookup,
+		Limits:               opts.Limits,
+		OnReference:          opts.OnReference,
+		OnMerge:              opts.OnMerge,
 	}
 
 	r := resolver.New(resolverOpts)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
 )
 
 // Mock provider for resolution tests
@@ -212,8 +213,8 @@ func TestResolveReferences_AllowMissingProvider(t *testing.T) {
 	opts := ResolveOptions{
 		ProviderRegistry:     registry,
 		AllowMissingProvider: true,
-		OnWarning: func(msg string) {
-			receivedWarnings = append(receivedWarnings, msg)
+		OnWarning: func(msg diagnostic.Diagnostic) {
+			receivedWarnings = append(receivedWarnings, msg.Message)
 		},
 	}
 
@@ -243,8 +244,8 @@ func TestResolveReferences_OnWarningCallback(t *testing.T) {
 	var warnings []string
 	opts := ResolveOptions{
 		ProviderRegistry: registry,
-		OnWarning: func(msg string) {
-			warnings = append(warnings, msg)
+		OnWarning: func(msg diagnostic.Diagnostic) {
+			warnings = append(warnings, msg.Message)
 		},
 	}
 
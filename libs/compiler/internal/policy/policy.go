@@ -0,0 +1,126 @@
+// Package policy evaluates CEL rules against compiled configuration data.
+//
+// Each rule is a CEL expression that must evaluate to true for the data to
+// comply. The compiled data is bound to the variable "data":
+//
+//	data.prod.database.ssl == true
+//	data.services.all(name, data.services[name].replicas >= 2)
+//
+// Secret values are evaluated in plaintext, before encryption.
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/google/cel-go/cel"
+)
+
+// Rule is a named CEL expression.
+type Rule struct {
+	// Name identifies the rule in diagnostics.
+	Name string
+
+	// Expression is a CEL expression that evaluates to a bool.
+	Expression string
+}
+
+// Result is the outcome of evaluating a single rule.
+type Result struct {
+	// Rule is the evaluated rule.
+	Rule Rule
+
+	// Passed reports whether the expression evaluated to true.
+	Passed bool
+
+	// Err is set if the rule could not be compiled or evaluated, for example
+	// when it accesses a key missing from the data. Passed is false.
+	Err error
+}
+
+// Evaluator holds compiled rules.
+type Evaluator struct {
+	rules    []Rule
+	programs []cel.Program
+}
+
+// New compiles rules. Syntax and type errors are reported together.
+func New(rules []Rule) (*Evaluator, error) {
+	env, err := cel.NewEnv(cel.Variable("data", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy environment: %w", err)
+	}
+
+	e := &Evaluator{rules: rules, programs: make([]cel.Program, len(rules))}
+	var errs []error
+	for i, rule := range rules {
+		ast, iss := env.Compile(rule.Expression)
+		if iss.Err() != nil {
+			errs = append(errs, fmt.Errorf("policy %q: %w", rule.Name, iss.Err()))
+			continue
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			errs = append(errs, fmt.Errorf("policy %q: expression must evaluate to bool, got %s", rule.Name, ast.OutputType()))
+			continue
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %q: %w", rule.Name, err))
+			continue
+		}
+		e.programs[i] = prg
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return e, nil
+}
+
+// Evaluate runs every rule against data, in rule order.
+func (e *Evaluator) Evaluate(data map[string]any) []Result {
+	activation := map[string]any{"data": plain(data)}
+
+	results := make([]Result, len(e.rules))
+	for i, rule := range e.rules {
+		results[i] = Result{Rule: rule}
+
+		out, _, err := e.programs[i].Eval(activation)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		passed, ok := out.Value().(bool)
+		if !ok {
+			results[i].Err = fmt.Errorf("expression evaluated to %v, want bool", out.Value())
+			continue
+		}
+		results[i].Passed = passed
+	}
+	return results
+}
+
+// plain converts compiled data into values CEL understands, unwrapping
+// secrets and widening integers.
+func plain(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = plain(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = plain(child)
+		}
+		return out
+	case models.Secret:
+		return plain(val.Value)
+	case int:
+		return int64(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,71 @@
+package policy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/policy"
+)
+
+// TestEvaluate tests rule evaluation against compiled data.
+func TestEvaluate(t *testing.T) {
+	data := map[string]any{
+		"prod": map[string]any{
+			"database": map[string]any{"ssl": false, "port": 5432},
+			"password": models.Secret{Value: "hunter2"},
+		},
+		"services": map[string]any{
+			"api": map[string]any{"replicas": int64(3)},
+			"web": map[string]any{"replicas": int64(1)},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		passed     bool
+		wantErr    bool
+	}{
+		{"passing rule", "data.prod.database.port == 5432", true, false},
+		{"failing rule", "data.prod.database.ssl == true", false, false},
+		{"macro over map", "data.services.all(s, data.services[s].replicas >= 2)", false, false},
+		{"secret unwrapped", "size(data.prod.password) >= 7", true, false},
+		{"has guards missing key", "!has(data.staging) || data.staging.ssl", true, false},
+		{"missing key", "data.staging.ssl == true", false, true},
+		{"non-bool result", "data.prod.database", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := policy.New([]policy.Rule{{Name: tt.name, Expression: tt.expression}})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			r := e.Evaluate(data)[0]
+			if r.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v (err: %v)", r.Passed, tt.passed, r.Err)
+			}
+			if (r.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, wantErr %v", r.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestNew_InvalidRules tests that compile errors for every rule are reported.
+func TestNew_InvalidRules(t *testing.T) {
+	_, err := policy.New([]policy.Rule{
+		{Name: "syntax", Expression: "data.a =="},
+		{Name: "type", Expression: "'text'"},
+	})
+	if err == nil {
+		t.Fatal("New() expected error for invalid rules")
+	}
+	for _, name := range []string{`"syntax"`, `"type"`} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error should mention policy %s, got: %v", name, err)
+		}
+	}
+}
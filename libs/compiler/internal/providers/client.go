@@ -4,6 +4,10 @@ package providers
 import (
 	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
 	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
@@ -14,12 +18,15 @@ import (
 )
 
 // Client implements the ProviderClient interface by delegating to a gRPC provider service.
-// It wraps a gRPC client connection and translates between the local Provider interface
+// It wraps a gRPC client connection, or a WASM module serving the same
+// service in-process, and translates between the local Provider interface
 // and the remote gRPC calls.
 type Client struct {
-	conn   *grpc.ClientConn
-	client providerv1.ProviderServiceClient
-	alias  string
+	conn     *grpc.ClientConn
+	module   io.Closer // WASM module closed instead of conn
+	client   providerv1.ProviderServiceClient
+	alias    string
+	defaults map[string]any
 }
 
 // NewClient creates a new Client that wraps a gRPC connection to a provider service.
@@ -45,26 +52,44 @@ func (c *Client) Init(ctx context.Context, opts core.ProviderInitOptions) error
 		SourceFilePath: opts.SourceFilePath,
 	}
 
-	_, err = c.client.Init(ctx, req)
+	resp, err := c.client.Init(ctx, req, maxRecvMsgSize(ctx))
 	if err != nil {
 		return fmt.Errorf("provider init failed: %w", err)
 	}
 
+	if resp.GetDefaults() != nil {
+		c.defaults = resp.GetDefaults().AsMap()
+	}
 	return nil
 }
 
+// Defaults returns the default values the provider published in its Init
+// response, or nil.
+func (c *Client) Defaults() map[string]any {
+	return c.defaults
+}
+
 // Fetch retrieves data from the provider via the gRPC Fetch RPC.
 func (c *Client) Fetch(ctx context.Context, path []string) (any, error) {
 	req := &providerv1.FetchRequest{
 		Path: path,
 	}
 
-	resp, err := c.client.Fetch(ctx, req)
+	resp, err := c.client.Fetch(ctx, req, maxRecvMsgSize(ctx))
 	if err != nil {
-		// Check for NOT_FOUND status
+		// Classify gRPC status codes
 		if st, ok := status.FromError(err); ok {
-			if st.Code() == codes.NotFound {
+			switch st.Code() {
+			case codes.ResourceExhausted:
+				if sizeErr := c.messageSizeError(ctx, path, st); sizeErr != nil {
+					return nil, sizeErr
+				}
+			case codes.NotFound:
 				return nil, fmt.Errorf("path not found: %s", st.Message())
+			case codes.DeadlineExceeded:
+				return nil, fmt.Errorf("%w: fetch failed: %w", core.ErrTimeout, err)
+			case codes.Unavailable:
+				return nil, fmt.Errorf("%w: fetch failed: %w", core.ErrProviderUnavailable, err)
 			}
 		}
 		return nil, fmt.Errorf("fetch failed: %w", err)
@@ -78,6 +103,31 @@ func (c *Client) Fetch(ctx context.Context, path []string) (any, error) {
 	return resp.Value.AsMap(), nil
 }
 
+// sizeMismatch matches the sizes in gRPC's message size errors, such as
+// "grpc: received message larger than max (5242893 vs. 4194304)".
+var sizeMismatch = regexp.MustCompile(`\((\d+) vs\. (\d+)\)`)
+
+// maxRecvMsgSize returns the call option accepting responses of up to
+// core.MaxProviderMessageBytes(ctx) bytes.
+func maxRecvMsgSize(ctx context.Context) grpc.CallOption {
+	return grpc.MaxCallRecvMsgSize(core.MaxProviderMessageBytes(ctx))
+}
+
+// messageSizeError returns a *core.MessageSizeError if st reports a message
+// over gRPC's size limit, or nil for other ResourceExhausted failures such
+// as provider quotas.
+func (c *Client) messageSizeError(ctx context.Context, path []string, st *status.Status) error {
+	if !strings.Contains(st.Message(), "larger than max") {
+		return nil
+	}
+	sizeErr := &core.MessageSizeError{Alias: c.alias, Path: path, Max: core.MaxProviderMessageBytes(ctx)}
+	if m := sizeMismatch.FindStringSubmatch(st.Message()); m != nil {
+		sizeErr.Size, _ = strconv.Atoi(m[1])
+		sizeErr.Max, _ = strconv.Atoi(m[2])
+	}
+	return sizeErr
+}
+
 // Shutdown sends a graceful shutdown request to the provider.
 func (c *Client) Shutdown(ctx context.Context) error {
 	_, err := c.client.Shutdown(ctx, &providerv1.ShutdownRequest{})
@@ -87,8 +137,11 @@ func (c *Client) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// Close closes the gRPC connection.
+// Close closes the gRPC connection, or releases the WASM module.
 func (c *Client) Close() error {
+	if c.module != nil {
+		return c.module.Close()
+	}
 	if c.conn != nil {
 		return c.conn.Close()
 	}
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// defaultsServer answers Init with fixed defaults.
+type defaultsServer struct {
+	providerv1.UnimplementedProviderServiceServer
+	defaults *structpb.Struct
+}
+
+func (s *defaultsServer) Init(context.Context, *providerv1.InitRequest) (*providerv1.InitResponse, error) {
+	return &providerv1.InitResponse{Defaults: s.defaults}, nil
+}
+
+func startServer(t *testing.T, server providerv1.ProviderServiceServer) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	providerv1.RegisterProviderServiceServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestClient_Defaults(t *testing.T) {
+	defaults, err := structpb.NewStruct(map[string]any{"database": map[string]any{"port": 5432}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		defaults *structpb.Struct
+		want     map[string]any
+	}{
+		{name: "published", defaults: defaults, want: map[string]any{"database": map[string]any{"port": 5432.0}}},
+		{name: "none", defaults: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(startServer(t, &defaultsServer{defaults: tt.defaults}), "db")
+			if err := client.Init(context.Background(), core.ProviderInitOptions{Alias: "db"}); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+			if got := client.Defaults(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Defaults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var _ core.ProviderWithDefaults = (*Client)(nil)
+}
+
+// largeServer answers Fetch with a value of about size bytes.
+type largeServer struct {
+	providerv1.UnimplementedProviderServiceServer
+	size int
+}
+
+func (s *largeServer) Fetch(context.Context, *providerv1.FetchRequest) (*providerv1.FetchResponse, error) {
+	value, err := structpb.NewStruct(map[string]any{"blob": strings.Repeat("x", s.size)})
+	if err != nil {
+		return nil, err
+	}
+	return &providerv1.FetchResponse{Value: value}, nil
+}
+
+func TestClient_FetchMessageSize(t *testing.T) {
+	client := NewClient(startServer(t, &largeServer{size: 2048}), "configs")
+	path := []string{"app", "settings"}
+
+	tests := []struct {
+		name    string
+		max     int
+		wantErr bool
+	}{
+		{name: "over limit", max: 1024, wantErr: true},
+		{name: "raised limit", max: 4096},
+		{name: "default limit", max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := core.WithMaxProviderMessageBytes(context.Background(), tt.max)
+			_, err := client.Fetch(ctx, path)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Fetch() error = %v", err)
+				}
+				return
+			}
+
+			var sizeErr *core.MessageSizeError
+			if !errors.As(err, &sizeErr) {
+				t.Fatalf("Fetch() error = %v, want *core.MessageSizeError", err)
+			}
+			if !errors.Is(err, core.ErrProviderMessageTooLarge) {
+				t.Errorf("errors.Is(err, ErrProviderMessageTooLarge) = false")
+			}
+			if core.Transient(err) {
+				t.Errorf("Transient(err) = true, want false")
+			}
+			if sizeErr.Alias != "configs" || !reflect.DeepEqual(sizeErr.Path, path) {
+				t.Errorf("error names @%s:%v, want @configs:%v", sizeErr.Alias, sizeErr.Path, path)
+			}
+			if sizeErr.Max != tt.max || sizeErr.Size <= 2048 {
+				t.Errorf("Size, Max = %d, %d; want > 2048, %d", sizeErr.Size, sizeErr.Max, tt.max)
+			}
+			want := fmt.Sprintf("provider message too large: fetching @configs:app.settings returned %d bytes, more than the %d bytes allowed", sizeErr.Size, tt.max)
+			if err.Error() != want {
+				t.Errorf("Error() = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
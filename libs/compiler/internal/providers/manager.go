@@ -3,7 +3,9 @@ package providers
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -26,11 +28,20 @@ type ManagerOptions struct {
 	// After this timeout, providers are forcefully terminated.
 	// Default: 5 seconds.
 	ShutdownTimeout time.Duration
+
+	// UnaryInterceptors wrap every unary RPC to a provider, including the
+	// Health check made at startup, in order: the first is outermost. Calls
+	// to WASM providers pass a nil ClientConn.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+
+	// StreamInterceptors wrap every streaming RPC to a provider, in order.
+	StreamInterceptors []grpc.StreamClientInterceptor
 }
 
-// providerProcess represents a running provider subprocess.
+// providerProcess represents a running provider subprocess, or a WASM
+// provider module running in-process.
 type providerProcess struct {
-	cmd    *exec.Cmd
+	cmd    *exec.Cmd // nil for WASM modules
 	client ProviderClient
 	alias  string
 	conn   *grpc.ClientConn
@@ -44,13 +55,17 @@ type ProviderClient interface {
 	Shutdown(ctx context.Context) error
 }
 
-// Manager manages the lifecycle of external provider subprocesses.
-// It starts providers on-demand, caches them per alias, and handles
-// graceful shutdown with configurable timeouts.
+// Manager manages the lifecycle of external provider subprocesses and of
+// providers compiled to WASM, which it runs in-process instead.
+// It starts providers on-demand, caches them per alias, binary, and config,
+// and handles graceful shutdown with configurable timeouts. It is safe for
+// concurrent use.
 type Manager struct {
 	mu              sync.RWMutex
-	processes       map[string]*providerProcess // keyed by alias
+	processes       map[string]*providerProcess // keyed by processKey
 	shutdownTimeout time.Duration
+	dialOptions     []grpc.DialOption
+	interceptors    []grpc.UnaryClientInterceptor // for WASM modules
 }
 
 // NewManager creates a new Manager instance with the given options.
@@ -61,31 +76,49 @@ func NewManager(opts *ManagerOptions) *Manager {
 		timeout = opts.ShutdownTimeout
 	}
 
+	var dialOptions []grpc.DialOption
+	var interceptors []grpc.UnaryClientInterceptor
+	if opts != nil && len(opts.UnaryInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(opts.UnaryInterceptors...))
+		interceptors = opts.UnaryInterceptors
+	}
+	if opts != nil && len(opts.StreamInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(opts.StreamInterceptors...))
+	}
+
 	return &Manager{
 		processes:       make(map[string]*providerProcess),
 		shutdownTimeout: timeout,
+		dialOptions:     dialOptions,
+		interceptors:    interceptors,
 	}
 }
 
 // GetProvider returns a Provider instance for the given alias.
 // If the provider subprocess is not already running, it starts it
-// and establishes a gRPC connection.
+// and establishes a gRPC connection; a binary that is a WASM module is
+// instantiated in-process instead. Calls for one alias with a different
+// binary or config get their own subprocess, so compilations sharing the
+// Manager never initialize each other's processes. ctx bounds the startup
+// only; the subprocess keeps running until Shutdown.
 //
 // On error, any partially initialized resources (subprocess, connection) are cleaned up.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
 //   - alias: Provider alias (e.g., "configs")
-//   - binaryPath: Absolute path to the provider executable
-//   - opts: Provider initialization options (currently unused, reserved for future)
+//   - binaryPath: Absolute path to the provider executable or WASM module
+//   - opts: Provider initialization options; only Config is used, to key the cache
 //
 // Returns:
 //   - Provider instance that delegates to the gRPC service
 //   - Error if the subprocess cannot be started or connection fails
-func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath string, _ core.ProviderInitOptions) (core.Provider, error) {
+func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath string, opts core.ProviderInitOptions) (core.Provider, error) {
+	key := processKey(alias, binaryPath, opts.Config)
+
 	// Check if process already exists (fast path)
 	m.mu.RLock()
-	if proc, ok := m.processes[alias]; ok {
+	if proc, ok := m.processes[key]; ok {
 		m.mu.RUnlock()
 		return proc.client, nil
 	}
@@ -96,18 +129,78 @@ func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath stri
 	defer m.mu.Unlock()
 
 	// Double-check after acquiring write lock
-	if proc, ok := m.processes[alias]; ok {
+	if proc, ok := m.processes[key]; ok {
 		return proc.client, nil
 	}
 
+	started, err := m.start(ctx, binaryPath, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create client
+	client := started.newClient(alias)
+
+	// Store process
+	proc := &providerProcess{
+		cmd:    started.cmd,
+		client: client,
+		alias:  alias,
+		conn:   started.conn,
+	}
+	m.processes[key] = proc
+
+	return client, nil
+}
+
+// processKey returns the key under which the process of alias is cached.
+// encoding/json sorts map keys, so equal configs yield equal keys; a config
+// that cannot be encoded is keyed by alias and binary alone.
+func processKey(alias, binaryPath string, config map[string]any) string {
+	data, _ := json.Marshal(config)
+	return alias + "\x00" + binaryPath + "\x00" + string(data)
+}
+
+// startedProvider is a provider subprocess or WASM module that answered
+// the Health RPC.
+type startedProvider struct {
+	cmd    *exec.Cmd
+	conn   *grpc.ClientConn
+	module *wasmModule // set instead of cmd and conn for WASM modules
+	health *providerv1.HealthResponse
+}
+
+// newClient returns a Client for the started provider.
+func (s *startedProvider) newClient(alias string) *Client {
+	if s.module != nil {
+		return &Client{module: s.module, client: s.module, alias: alias}
+	}
+	return NewClient(s.conn, alias)
+}
+
+// start starts the provider at binaryPath: in-process if it is a WASM
+// module, as a subprocess otherwise.
+func (m *Manager) start(ctx context.Context, binaryPath string, stderr io.Writer) (*startedProvider, error) {
+	if IsWASM(binaryPath) {
+		return startWASM(ctx, binaryPath, stderr, m.interceptors)
+	}
+	return startProvider(ctx, binaryPath, stderr, m.dialOptions...)
+}
+
+// startProvider starts the provider binary, connects to the port it reports
+// on stdout with dialOptions, and verifies the connection with the Health
+// RPC. The provider's stderr goes to stderr. On error the process is killed
+// and reaped.
+func startProvider(ctx context.Context, binaryPath string, stderr io.Writer, dialOptions ...grpc.DialOption) (*startedProvider, error) {
 	// Verify binary exists
 	if _, err := os.Stat(binaryPath); err != nil {
 		return nil, fmt.Errorf("provider binary not found at %s: %w", binaryPath, err)
 	}
 
-	// Start the subprocess
-	cmd := exec.CommandContext(ctx, binaryPath)
-	cmd.Stderr = os.Stderr // Capture provider stderr
+	// Start the subprocess. It outlives ctx, since cached providers serve
+	// later compilations, so ctx only aborts the startup.
+	cmd := exec.Command(binaryPath)
+	cmd.Stderr = stderr
 
 	// Create a pipe to read stdout (provider will print port)
 	stdout, err := cmd.StdoutPipe()
@@ -118,6 +211,8 @@ func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath stri
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start provider process: %w", err)
 	}
+	stopKill := context.AfterFunc(ctx, func() { _ = cmd.Process.Kill() })
+	defer stopKill()
 
 	// Ensure cleanup on error paths
 	var conn *grpc.ClientConn
@@ -150,6 +245,9 @@ func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath stri
 
 	if port == 0 {
 		cleanup()
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("provider startup aborted: %w", err)
+		}
 		return nil, fmt.Errorf("provider did not report port")
 	}
 
@@ -157,7 +255,7 @@ func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath stri
 	target := fmt.Sprintf("127.0.0.1:%d", port)
 	conn, err = grpc.NewClient(
 		target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOptions...)...,
 	)
 	if err != nil {
 		cleanup()
@@ -166,25 +264,13 @@ func (m *Manager) GetProvider(ctx context.Context, alias string, binaryPath stri
 
 	// Verify connection by calling Health
 	healthClient := providerv1.NewProviderServiceClient(conn)
-	_, err = healthClient.Health(ctx, &providerv1.HealthRequest{})
+	health, err := healthClient.Health(ctx, &providerv1.HealthRequest{})
 	if err != nil {
 		cleanup()
 		return nil, fmt.Errorf("provider health check failed: %w", err)
 	}
 
-	// Create client
-	client := NewClient(conn, alias)
-
-	// Store process
-	proc := &providerProcess{
-		cmd:    cmd,
-		client: client,
-		alias:  alias,
-		conn:   conn,
-	}
-	m.processes[alias] = proc
-
-	return client, nil
+	return &startedProvider{cmd: cmd, conn: conn, health: health}, nil
 }
 
 // Shutdown gracefully shuts down all running provider processes.
@@ -200,8 +286,8 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 
 	var errs []error
 
-	for alias, proc := range m.processes {
-		if err := m.shutdownProvider(ctx, alias, proc); err != nil {
+	for _, proc := range m.processes {
+		if err := m.shutdownProvider(ctx, proc.alias, proc); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -222,11 +308,19 @@ func (m *Manager) shutdownProvider(ctx context.Context, alias string, proc *prov
 	shutdownCtx, cancel := context.WithTimeout(ctx, m.shutdownTimeout)
 	defer cancel()
 
+	// WASM modules have no process to wait for; closing releases them
+	if proc.cmd == nil {
+		defer func() { _ = proc.client.Close() }()
+	}
+
 	// Step 1: Call Shutdown RPC (graceful)
 	if err := proc.client.Shutdown(shutdownCtx); err != nil {
 		// Log but continue - we'll still try to clean up
 		return fmt.Errorf("shutdown RPC failed for %s: %w", alias, err)
 	}
+	if proc.cmd == nil {
+		return nil
+	}
 
 	// Step 2: Wait for process to exit gracefully
 	// Use WaitDelay to ensure we don't hold references too long
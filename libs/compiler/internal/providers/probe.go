@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+)
+
+// probeStderrLimit bounds the provider stderr kept by Probe.
+const probeStderrLimit = 8 << 10
+
+// ProbeResult reports how a provider binary answered Probe.
+type ProbeResult struct {
+	// Health is the Health RPC response received during startup.
+	Health *providerv1.HealthResponse
+
+	// Info is the Info RPC response, or nil when Info failed.
+	Info *providerv1.InfoResponse
+
+	// InitErr is the error of the Init RPC, or nil when Init succeeded or
+	// was not requested.
+	InitErr error
+
+	// Stderr holds the end of what the provider wrote to stderr.
+	Stderr string
+}
+
+// Probe starts the provider binary or WASM module at binaryPath, calls Init with opts when
+// opts is non-nil, calls Info, and shuts the provider down. Health is called
+// during startup. Failures to start the provider or answer Health are
+// returned as errors; the result's Stderr is set either way.
+func Probe(ctx context.Context, binaryPath string, opts *core.ProviderInitOptions) (ProbeResult, error) {
+	stderr := &tailBuffer{limit: probeStderrLimit}
+
+	manager := NewManager(nil)
+	started, err := manager.start(ctx, binaryPath, stderr)
+	if err != nil {
+		return ProbeResult{Stderr: stderr.String()}, err
+	}
+
+	result := ProbeResult{Health: started.health}
+	client := started.newClient("")
+	if opts != nil {
+		result.InitErr = client.Init(ctx, *opts)
+	}
+	if info, err := client.client.Info(ctx, &providerv1.InfoRequest{}); err == nil {
+		result.Info = info
+	}
+
+	proc := &providerProcess{cmd: started.cmd, client: client, conn: started.conn}
+	if err := manager.shutdownProvider(ctx, binaryPath, proc); err != nil && started.cmd != nil && started.cmd.ProcessState == nil {
+		// The Shutdown RPC failed, so the process was not reaped
+		_ = client.Close()
+		_ = started.cmd.Process.Kill()
+		_ = started.cmd.Wait()
+	}
+
+	result.Stderr = stderr.String()
+	return result, nil
+}
+
+// tailBuffer is an io.Writer that keeps the last limit bytes written.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.limit; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the bytes kept.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
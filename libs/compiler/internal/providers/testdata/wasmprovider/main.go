@@ -0,0 +1,49 @@
+// Command wasmprovider is a provider module for the WASM runtime tests,
+// built with GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	providersdk "github.com/autonomous-bits/nomos/libs/provider-sdk"
+)
+
+type echoProvider struct {
+	alias  string
+	config map[string]any
+}
+
+func (p *echoProvider) Init(_ context.Context, req providersdk.InitRequest) error {
+	if req.Config["fail"] == true {
+		return fmt.Errorf("%w: fail requested", providersdk.ErrInvalidConfig)
+	}
+	p.alias = req.Alias
+	p.config = req.Config
+	return nil
+}
+
+func (p *echoProvider) Fetch(_ context.Context, path []string) (any, error) {
+	switch strings.Join(path, "/") {
+	case "config":
+		return p.config, nil
+	case "alias":
+		return p.alias, nil
+	case "large":
+		return strings.Repeat("x", 1<<20), nil
+	case "panic":
+		panic("fetch panicked")
+	}
+	return nil, fmt.Errorf("%w: %s", providersdk.ErrNotFound, strings.Join(path, "/"))
+}
+
+func (p *echoProvider) Defaults() map[string]any {
+	return map[string]any{"region": "local"}
+}
+
+func init() {
+	providersdk.ServeWASM(&echoProvider{}, providersdk.Options{Type: "echo", Version: "1.0.0"})
+}
+
+func main() {}
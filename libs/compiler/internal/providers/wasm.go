@@ -0,0 +1,287 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// wasmABIVersion is the version of the module interface the compiler
+// calls, which providersdk.ServeWASM implements. Modules export it as
+// nomos_abi_version.
+const wasmABIVersion = 1
+
+// wasmMagic starts every WASM binary module.
+var wasmMagic = []byte("\x00asm")
+
+// wasmExports maps the provider service methods to the module functions
+// implementing them. Each takes the address and length of a serialized
+// request message and returns the address and length of its result,
+// packed as ptr<<32 | len: a gRPC status code byte followed by the
+// serialized response message for codes.OK, or by the error message
+// otherwise. The host passes requests in buffers from nomos_alloc and
+// releases both buffers with nomos_free.
+var wasmExports = map[string]string{
+	providerv1.ProviderService_Init_FullMethodName:     "nomos_init",
+	providerv1.ProviderService_Fetch_FullMethodName:    "nomos_fetch",
+	providerv1.ProviderService_Info_FullMethodName:     "nomos_info",
+	providerv1.ProviderService_Health_FullMethodName:   "nomos_health",
+	providerv1.ProviderService_Shutdown_FullMethodName: "nomos_shutdown",
+}
+
+// wasmCache shares compiled modules between runtimes, so a module serving
+// several aliases is compiled once.
+var wasmCache = wazero.NewCompilationCache()
+
+// IsWASM reports whether the provider binary at path is a WASM module
+// rather than a native executable.
+func IsWASM(path string) bool {
+	f, err := os.Open(path) //nolint:gosec // G304: provider binary path from the lockfile
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(wasmMagic))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, wasmMagic)
+}
+
+// wasmModule runs a provider compiled to WASM (WASI preview 1) in-process
+// with wazero and implements the provider service client by calling the
+// functions in wasmExports, so Client serves it like a subprocess. The
+// module gets no filesystem, network, environment, or arguments; what it
+// writes to stdout or stderr goes to the stderr it was started with.
+//
+// A module runs one call at a time, so calls are serialized. Cancelling a
+// call's context stops the module; later calls fail as unavailable.
+type wasmModule struct {
+	mu           sync.Mutex
+	runtime      wazero.Runtime
+	module       api.Module
+	interceptors []grpc.UnaryClientInterceptor
+}
+
+// startWASM instantiates the module at path and verifies it with a Health
+// call, which interceptors wrap like every later call. The module outlives
+// ctx, which only aborts the startup.
+func startWASM(ctx context.Context, path string, stderr io.Writer, interceptors []grpc.UnaryClientInterceptor) (*startedProvider, error) {
+	code, err := os.ReadFile(path) //nolint:gosec // G304: provider binary path from the lockfile
+	if err != nil {
+		return nil, fmt.Errorf("provider binary not found at %s: %w", path, err)
+	}
+
+	config := wazero.NewRuntimeConfig().WithCompilationCache(wasmCache).WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(context.Background(), config)
+	module, err := instantiateWASM(ctx, runtime, code, stderr)
+	if err != nil {
+		_ = runtime.Close(context.Background())
+		return nil, err
+	}
+
+	m := &wasmModule{runtime: runtime, module: module, interceptors: interceptors}
+	health, err := m.Health(ctx, &providerv1.HealthRequest{})
+	if err != nil {
+		_ = m.Close()
+		return nil, fmt.Errorf("provider health check failed: %w", err)
+	}
+	return &startedProvider{module: m, health: health}, nil
+}
+
+// instantiateWASM compiles and instantiates a provider module built with
+// -buildmode=c-shared, whose _initialize function runs its package
+// initializers, and checks its ABI version.
+func instantiateWASM(ctx context.Context, runtime wazero.Runtime, code []byte, stderr io.Writer) (api.Module, error) {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile provider module: %w", err)
+	}
+
+	config := wazero.NewModuleConfig().
+		WithStdout(stderr).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep().
+		WithRandSource(rand.Reader).
+		WithStartFunctions("_initialize")
+	module, err := runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("provider startup aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("failed to start provider module: %w", err)
+	}
+
+	abi := module.ExportedFunction("nomos_abi_version")
+	if abi == nil {
+		return nil, fmt.Errorf("provider module does not export nomos_abi_version; build it with providersdk.ServeWASM and -buildmode=c-shared")
+	}
+	results, err := abi.Call(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider module ABI version: %w", err)
+	}
+	if version := api.DecodeU32(results[0]); version != wasmABIVersion {
+		return nil, fmt.Errorf("provider module uses ABI version %d, want %d", version, wasmABIVersion)
+	}
+	return module, nil
+}
+
+// Init implements providerv1.ProviderServiceClient.
+func (m *wasmModule) Init(ctx context.Context, in *providerv1.InitRequest, _ ...grpc.CallOption) (*providerv1.InitResponse, error) {
+	out := &providerv1.InitResponse{}
+	if err := m.invoke(ctx, providerv1.ProviderService_Init_FullMethodName, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Fetch implements providerv1.ProviderServiceClient.
+func (m *wasmModule) Fetch(ctx context.Context, in *providerv1.FetchRequest, _ ...grpc.CallOption) (*providerv1.FetchResponse, error) {
+	out := &providerv1.FetchResponse{}
+	if err := m.invoke(ctx, providerv1.ProviderService_Fetch_FullMethodName, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Info implements providerv1.ProviderServiceClient.
+func (m *wasmModule) Info(ctx context.Context, in *providerv1.InfoRequest, _ ...grpc.CallOption) (*providerv1.InfoResponse, error) {
+	out := &providerv1.InfoResponse{}
+	if err := m.invoke(ctx, providerv1.ProviderService_Info_FullMethodName, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Health implements providerv1.ProviderServiceClient.
+func (m *wasmModule) Health(ctx context.Context, in *providerv1.HealthRequest, _ ...grpc.CallOption) (*providerv1.HealthResponse, error) {
+	out := &providerv1.HealthResponse{}
+	if err := m.invoke(ctx, providerv1.ProviderService_Health_FullMethodName, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Shutdown implements providerv1.ProviderServiceClient.
+func (m *wasmModule) Shutdown(ctx context.Context, in *providerv1.ShutdownRequest, _ ...grpc.CallOption) (*providerv1.ShutdownResponse, error) {
+	out := &providerv1.ShutdownResponse{}
+	if err := m.invoke(ctx, providerv1.ProviderService_Shutdown_FullMethodName, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close releases the module and its runtime.
+func (m *wasmModule) Close() error {
+	return m.runtime.Close(context.Background())
+}
+
+// invoke calls method through the interceptors, which get a nil
+// ClientConn.
+func (m *wasmModule) invoke(ctx context.Context, method string, req, reply proto.Message) error {
+	var invoker grpc.UnaryInvoker = func(ctx context.Context, method string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		return m.call(ctx, method, req.(proto.Message), reply.(proto.Message))
+	}
+	for i := len(m.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := m.interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	return invoker(ctx, method, req, reply, nil)
+}
+
+// call passes req to the module function implementing method and decodes
+// its result into reply. Failures are gRPC status errors, so Client
+// classifies them as it does for subprocesses; results over
+// core.MaxProviderMessageBytes(ctx) fail like gRPC's receive limit.
+func (m *wasmModule) call(ctx context.Context, method string, req, reply proto.Message) error {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode request: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ptr, err := m.callFunction(ctx, "nomos_alloc", uint64(len(data)))
+	if err != nil {
+		return err
+	}
+	defer m.free(ptr)
+	if !m.module.Memory().Write(uint32(ptr), data) {
+		return status.Errorf(codes.Internal, "provider module returned an invalid request buffer")
+	}
+
+	result, err := m.callFunction(ctx, wasmExports[method], ptr, uint64(len(data)))
+	if err != nil {
+		return err
+	}
+	defer m.free(result >> 32)
+	out, ok := m.module.Memory().Read(uint32(result>>32), uint32(result))
+	if !ok || len(out) == 0 {
+		return status.Errorf(codes.Internal, "provider module returned an invalid result from %s", wasmExports[method])
+	}
+
+	if code := codes.Code(out[0]); code != codes.OK {
+		return status.Error(code, string(out[1:]))
+	}
+	if limit := core.MaxProviderMessageBytes(ctx); len(out)-1 > limit {
+		return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", len(out)-1, limit)
+	}
+	if err := proto.Unmarshal(out[1:], reply); err != nil {
+		return status.Errorf(codes.Internal, "failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// callFunction calls the exported function name, which returns a single
+// value. A module that fails or is stopped by ctx reports the failure as
+// a status error. A trap, such as a Go panic, leaves the module's state
+// undefined, so the module is closed and later calls fail too.
+func (m *wasmModule) callFunction(ctx context.Context, name string, params ...uint64) (uint64, error) {
+	fn := m.module.ExportedFunction(name)
+	if fn == nil {
+		return 0, status.Errorf(codes.Unimplemented, "provider module does not export %s", name)
+	}
+	results, err := fn.Call(ctx, params...)
+	if err != nil {
+		_ = m.module.Close(context.Background())
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, status.FromContextError(ctxErr).Err()
+		}
+		return 0, status.Errorf(codes.Unavailable, "provider module failed in %s: %v", name, err)
+	}
+	if len(results) != 1 {
+		return 0, status.Errorf(codes.Internal, "provider module function %s returned %d values, want 1", name, len(results))
+	}
+	return results[0], nil
+}
+
+// free releases a buffer the module allocated. It runs without the call's
+// context, which may be done.
+func (m *wasmModule) free(ptr uint64) {
+	if fn := m.module.ExportedFunction("nomos_free"); fn != nil {
+		_, _ = fn.Call(context.Background(), api.EncodeU32(uint32(ptr)))
+	}
+}
@@ -0,0 +1,191 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// buildWASMProvider builds testdata/wasmprovider as a WASM module. The
+// file has no .wasm extension, since installed providers need none.
+func buildWASMProvider(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "provider")
+	//nolint:gosec // G204: Test helper building controlled test module with known args
+	cmd := exec.CommandContext(t.Context(), "go", "build", "-buildmode=c-shared", "-o", path, "./testdata/wasmprovider")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build WASM provider: %v\nOutput: %s", err, out)
+	}
+	return path
+}
+
+func TestManager_GetProvider_WASM(t *testing.T) {
+	path := buildWASMProvider(t)
+
+	var mu sync.Mutex
+	var methods []string
+	record := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	manager := NewManager(&ManagerOptions{UnaryInterceptors: []grpc.UnaryClientInterceptor{record}})
+	ctx := context.Background()
+	config := map[string]any{"greeting": "hello"}
+
+	provider, err := manager.GetProvider(ctx, "echo", path, core.ProviderInitOptions{Config: config})
+	if err != nil {
+		t.Fatalf("GetProvider() error = %v", err)
+	}
+	again, err := manager.GetProvider(ctx, "echo", path, core.ProviderInitOptions{Config: config})
+	if err != nil || again != provider {
+		t.Fatalf("GetProvider() second call = %v, %v; want the cached provider", again, err)
+	}
+
+	if err := provider.Init(ctx, core.ProviderInitOptions{Alias: "echo", Config: config}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defaults := provider.(*Client).Defaults()
+	if !reflect.DeepEqual(defaults, map[string]any{"region": "local"}) {
+		t.Errorf("Defaults() = %v, want region local", defaults)
+	}
+
+	got, err := provider.Fetch(ctx, []string{"config"})
+	if err != nil {
+		t.Fatalf("Fetch(config) error = %v", err)
+	}
+	if !reflect.DeepEqual(got, config) {
+		t.Errorf("Fetch(config) = %v, want %v", got, config)
+	}
+	got, err = provider.Fetch(ctx, []string{"alias"})
+	if err != nil {
+		t.Fatalf("Fetch(alias) error = %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]any{"value": "echo"}) {
+		t.Errorf("Fetch(alias) = %v, want the alias under value", got)
+	}
+
+	_, err = provider.Fetch(ctx, []string{"missing", "key"})
+	if err == nil || !strings.Contains(err.Error(), "path not found: not found: missing/key") {
+		t.Errorf("Fetch(missing) error = %v, want path not found", err)
+	}
+
+	_, err = provider.Fetch(core.WithMaxProviderMessageBytes(ctx, 1024), []string{"large"})
+	var sizeErr *core.MessageSizeError
+	if !errors.As(err, &sizeErr) {
+		t.Fatalf("Fetch(large) error = %v, want *core.MessageSizeError", err)
+	}
+	if sizeErr.Alias != "echo" || sizeErr.Max != 1024 || sizeErr.Size <= 1<<20 {
+		t.Errorf("Fetch(large) error = %+v, want alias echo, max 1024, size over 1 MiB", sizeErr)
+	}
+
+	if err := manager.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if _, err := provider.Fetch(ctx, []string{"config"}); err == nil {
+		t.Error("Fetch() after Shutdown succeeded, want an error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) == 0 || !strings.HasSuffix(methods[0], "/Health") {
+		t.Errorf("interceptor saw %v, want the startup Health call first", methods)
+	}
+}
+
+func TestWASMProvider_Errors(t *testing.T) {
+	path := buildWASMProvider(t)
+	ctx := context.Background()
+
+	t.Run("init error", func(t *testing.T) {
+		started, err := NewManager(nil).start(ctx, path, os.Stderr)
+		if err != nil {
+			t.Fatalf("start() error = %v", err)
+		}
+		client := started.newClient("echo")
+		defer func() { _ = client.Close() }()
+
+		err = client.Init(ctx, core.ProviderInitOptions{Config: map[string]any{"fail": true}})
+		if status.Code(errors.Unwrap(err)) != codes.InvalidArgument {
+			t.Errorf("Init() error = %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		started, err := NewManager(nil).start(ctx, path, &tailBuffer{limit: probeStderrLimit})
+		if err != nil {
+			t.Fatalf("start() error = %v", err)
+		}
+		client := started.newClient("echo")
+		defer func() { _ = client.Close() }()
+		if err := client.Init(ctx, core.ProviderInitOptions{}); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+
+		_, err = client.Fetch(ctx, []string{"panic"})
+		if !errors.Is(err, core.ErrProviderUnavailable) {
+			t.Errorf("Fetch(panic) error = %v, want ErrProviderUnavailable", err)
+		}
+		if _, err := client.Fetch(ctx, []string{"config"}); !errors.Is(err, core.ErrProviderUnavailable) {
+			t.Errorf("Fetch() after panic error = %v, want ErrProviderUnavailable", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := NewManager(nil).start(cancelled, path, os.Stderr); err == nil {
+			t.Error("start() with a cancelled context succeeded, want an error")
+		}
+	})
+}
+
+func TestProbe_WASM(t *testing.T) {
+	path := buildWASMProvider(t)
+
+	result, err := Probe(context.Background(), path, &core.ProviderInitOptions{Alias: "echo"})
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if result.InitErr != nil {
+		t.Errorf("InitErr = %v", result.InitErr)
+	}
+	if result.Info.GetType() != "echo" || result.Info.GetVersion() != "1.0.0" || result.Info.GetAlias() != "echo" {
+		t.Errorf("Info = %v, want echo 1.0.0 for alias echo", result.Info)
+	}
+}
+
+func TestIsWASM(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "module")
+	script := filepath.Join(dir, "script")
+	if err := os.WriteFile(module, []byte("\x00asm\x01\x00\x00\x00"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsWASM(module) {
+		t.Error("IsWASM(module) = false, want true")
+	}
+	if IsWASM(script) {
+		t.Error("IsWASM(script) = true, want false")
+	}
+	if IsWASM(filepath.Join(dir, "missing")) {
+		t.Error("IsWASM(missing) = true, want false")
+	}
+}
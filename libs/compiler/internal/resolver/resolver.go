@@ -1,30 +1,44 @@
 // Package resolver implements reference resolution for the Nomos compiler.
 //
 // The resolver walks AST values, identifies ReferenceExpr nodes, and resolves
-// them by calling appropriate providers. Results are cached per compilation run
-// to avoid redundant fetches.
+// them by calling appropriate providers. It first builds the graph of the
+// references and of those in the values they fetch, fetching each level of
+// the graph in one batch per provider, then resolves the graph in dependency
+// order. Results are cached per compilation run to avoid redundant fetches.
+//
+// Resolved data is held as value.Value, which is immutable, so cached
+// provider values and merged maps share subtrees safely; ResolveValue
+// converts the result back to plain Go data once, at the end.
 package resolver
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/functions"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/pkg/value"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
 // Sentinel errors for reference resolution failures.
 var (
 	// ErrUnresolvedReference indicates a reference could not be resolved.
-	ErrUnresolvedReference = errors.New("unresolved reference")
+	ErrUnresolvedReference = core.ErrUnresolvedReference
 
 	// ErrProviderNotRegistered indicates a provider alias is not registered.
-	ErrProviderNotRegistered = errors.New("provider not registered")
+	ErrProviderNotRegistered = core.ErrProviderNotRegistered
+
+	// ErrCircularReference indicates a cycle was detected in the resolution chain.
+	ErrCircularReference = core.ErrCircularReference
 )
 
 // Provider is an alias to core.Provider for backward compatibility.
@@ -49,82 +63,83 @@ type ResolverOptions struct {
 	// If false (default), fetch failures cause the compilation to fail.
 	AllowMissingProvider bool
 
-	// OnWarning is called when a non-fatal warning occurs.
-	// Only used when AllowMissingProvider is true.
-	OnWarning func(warning string)
-}
+	// FetchTimeout bounds each provider Fetch call. Zero means no timeout
+	// beyond the caller's context.
+	FetchTimeout time.Duration
 
-// Resolver resolves ReferenceExpr nodes to their actual values using providers.
-type Resolver struct {
-	opts   ResolverOptions
-	cache  *fetchCache
-	resCtx *ResolutionContext
-}
-
-// ResolutionContext tracks active resolution stack to detect circular references.
-// This is a minimal version that mirrors the public API in reference_resolution.go.
-type ResolutionContext struct {
-	mu    sync.Mutex
-	Stack []PathRef
-}
+	// Retry retries fetches failing with transient errors.
+	Retry core.RetryPolicy
 
-// PathRef identifies a unique reference path being resolved.
-type PathRef struct {
-	Alias string
-	Path  string
-}
+	// MaxConcurrency bounds the providers fetched from at once. Zero means
+	// no bound.
+	MaxConcurrency int
 
-// String returns a human-readable representation of the PathRef.
-func (r PathRef) String() string {
-	return fmt.Sprintf("%s:%s", r.Alias, r.Path)
+	// OnWarning is called when a non-fatal warning occurs.
+	// Only used when AllowMissingProvider is true.
+	OnWarning func(warning diagnostic.Diagnostic)
+
+	// OnFailure, if set, receives the error of each reference whose
+	// provider is unavailable or whose fetch fails, and resolution continues
+	// with a nil value in its place. AllowMissingProvider takes precedence.
+	OnFailure func(err error)
+
+	// ProviderScope returns the registry key of the provider serving alias
+	// in the given source file, for aliases declared at several versions.
+	// If nil, references use their alias as the key.
+	ProviderScope func(alias, filename string) string
+
+	// Limits bounds the depth, keys, and references of each fetched value.
+	// A value over a limit fails its references even when
+	// AllowMissingProvider is set.
+	Limits core.Limits
+
+	// OnReference, if set, is called after each reference is looked up,
+	// including lookups served from the cache and lookups that fail.
+	OnReference func(ReferenceEvent)
+
+	// OnMerge, if set, is called when a spread or a repeated key sets a key
+	// that an earlier entry of the same map already set.
+	OnMerge func(MergeEvent)
 }
 
-// Push adds a path to the resolution stack.
-func (ctx *ResolutionContext) Push(alias string, path []string) error {
-	ctx.mu.Lock()
-	defer ctx.mu.Unlock()
+// ReferenceEvent describes one reference lookup.
+type ReferenceEvent struct {
+	Ref *ast.ReferenceExpr
 
-	ref := PathRef{Alias: alias, Path: pathKey(path)}
+	// Path is the key path holding the reference, in source map syntax;
+	// empty for a root spread.
+	Path string
 
-	for _, existing := range ctx.Stack {
-		if existing == ref {
-			return fmt.Errorf("%w: %s", ErrCircularReference, ctx.formatCycle(ref))
-		}
-	}
+	// Provider is the registry key of the provider serving the reference.
+	Provider string
 
-	ctx.Stack = append(ctx.Stack, ref)
-	return nil
+	Value  value.Value
+	Cached bool
+	Err    error
 }
 
-// Pop removes the most recent path from the resolution stack.
-func (ctx *ResolutionContext) Pop() {
-	ctx.mu.Lock()
-	defer ctx.mu.Unlock()
+// MergeEvent describes a key set by more than one entry of a map.
+type MergeEvent struct {
+	// Path is the key path, in source map syntax.
+	Path string
 
-	if len(ctx.Stack) > 0 {
-		ctx.Stack = ctx.Stack[:len(ctx.Stack)-1]
-	}
-}
+	// Ref is the spread reference that set the key again, or nil for a
+	// repeated key.
+	Ref *ast.ReferenceExpr
 
-// formatCycle creates a human-readable cycle path for error messages.
-func (ctx *ResolutionContext) formatCycle(ref PathRef) string {
-	parts := make([]string, 0, len(ctx.Stack)+1)
-	for _, r := range ctx.Stack {
-		parts = append(parts, r.String())
-	}
-	parts = append(parts, ref.String())
-	return strings.Join(parts, " → ")
+	// Merged reports whether both values were maps and merged key by key;
+	// otherwise the later value replaced the earlier one.
+	Merged bool
 }
 
-func pathKey(path []string) string {
-	if len(path) == 0 {
-		return "*"
-	}
-	return strings.Join(path, ":")
-}
+// Resolver resolves ReferenceExpr nodes to their actual values using providers.
+type Resolver struct {
+	opts ResolverOptions
 
-// ErrCircularReference indicates a cycle was detected in the resolution chain.
-var ErrCircularReference = errors.New("circular reference detected")
+	// mu serializes ResolveValue calls, which share the reference graph.
+	mu    sync.Mutex
+	nodes map[string]*node
+}
 
 // New creates a new Resolver with the given options.
 func New(opts ResolverOptions) *Resolver {
@@ -133,302 +148,353 @@ func New(opts ResolverOptions) *Resolver {
 	}
 
 	return &Resolver{
-		opts:   opts,
-		cache:  newFetchCache(),
-		resCtx: &ResolutionContext{Stack: []PathRef{}},
+		opts:  opts,
+		nodes: make(map[string]*node),
 	}
 }
 
 // ResolveValue resolves a single value, replacing ReferenceExpr nodes with their resolved values.
-// Returns the resolved value or an error if resolution fails.
+// Returns the resolved value as plain Go data (see value.Value.Any) or an error if resolution fails.
 func (r *Resolver) ResolveValue(ctx context.Context, val any) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schedule(ctx, val, "")
+	resolved, err := r.resolveValue(ctx, val, "")
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Any(), nil
+}
+
+// resolveValue resolves val found at key path, which is reported to the
+// OnReference and OnMerge callbacks.
+func (r *Resolver) resolveValue(ctx context.Context, val any, path string) (value.Value, error) {
 	switch v := val.(type) {
 	case *ast.ReferenceExpr:
 		// Resolve reference expression
-		return r.resolveReference(ctx, v)
+		return r.resolveReference(ctx, v, path)
 
 	case map[string]any:
 		// Recursively resolve map entries
-		return r.resolveMap(ctx, v)
+		return r.resolveMap(ctx, v, path)
 
 	case []any:
 		// Recursively resolve slice elements
-		return r.resolveSlice(ctx, v)
+		return r.resolveSlice(ctx, v, path)
 
 	case models.Secret:
 		// Resolve the inner value of the secret
-		resolved, err := r.ResolveValue(ctx, v.Value)
+		resolved, err := r.resolveValue(ctx, v.Value, path)
 		if err != nil {
-			return nil, err
+			return value.Value{}, err
 		}
-		return models.Secret{Value: resolved}, nil
+		return value.Secret(resolved), nil
+
+	case models.Call:
+		return r.resolveCall(ctx, v, path)
 
 	default:
-		// Scalar values and other types pass through
-		return val, nil
+		// Scalar values
+		return value.Of(val)
 	}
 }
 
 // resolveReference resolves a single ReferenceExpr by calling the appropriate provider.
-func (r *Resolver) resolveReference(ctx context.Context, ref *ast.ReferenceExpr) (any, error) {
-	if err := r.resCtx.Push(ref.Alias, ref.Path); err != nil {
-		return nil, fmt.Errorf("resolving @%s:%s at %s:%d: %w",
-			ref.Alias, pathKey(ref.Path),
-			ref.SourceSpan.Filename, ref.SourceSpan.StartLine,
-			err)
+func (r *Resolver) resolveReference(ctx context.Context, ref *ast.ReferenceExpr, path string) (value.Value, error) {
+	key := r.providerKey(ref)
+	val, cached, err := r.lookupReference(ctx, ref, key, path)
+	if r.opts.OnReference != nil {
+		r.opts.OnReference(ReferenceEvent{Ref: ref, Path: path, Provider: key, Value: val, Cached: cached, Err: err})
 	}
-	defer r.resCtx.Pop()
-
-	// Build cache key
-	cacheKey := buildCacheKey(ref.Alias, ref.Path)
+	return val, err
+}
 
-	// Check cache first
-	if val, ok := r.cache.get(cacheKey); ok {
-		return val, nil
+// providerKey returns the registry key of the provider serving ref in its
+// source file.
+func (r *Resolver) providerKey(ref *ast.ReferenceExpr) string {
+	if r.opts.ProviderScope != nil {
+		return r.opts.ProviderScope(ref.Alias, ref.SourceSpan.Filename)
 	}
+	return ref.Alias
+}
 
-	// Get provider
-	provider, err := r.opts.ProviderRegistry.GetProvider(ref.Alias)
-	if err != nil {
-		return nil, r.handleProviderError(ref, err)
+// lookupReference returns the resolved value of ref's node in the reference
+// graph, which schedule fetched from the provider registered as key. It
+// reports whether the value was already returned by an earlier lookup.
+func (r *Resolver) lookupReference(ctx context.Context, ref *ast.ReferenceExpr, key, path string) (value.Value, bool, error) {
+	n := r.nodes[buildCacheKey(key, ref.Path)]
+	switch {
+	case n.providerErr != nil:
+		return value.Null(), false, r.handleProviderError(ref, n.providerErr)
+	case n.fetchErr != nil:
+		return value.Null(), false, r.handleFetchError(ref, ref.Path, n.fetchErr)
 	}
 
-	// Fetch value from provider
-	val, err := provider.Fetch(ctx, ref.Path)
-	if err != nil {
-		return nil, r.handleFetchError(ref, ref.Path, err)
+	r.resolveNode(ctx, n)
+	switch {
+	case n.cycle != nil:
+		return value.Null(), false, newReferenceError(ref, nil, n.cycle)
+	case !n.done:
+		// Only a cycle the graph missed can reach a node being resolved
+		return value.Null(), false, newReferenceError(ref, nil, &core.CycleError{Chain: []string{n.name(), n.name()}})
+	case n.err != nil:
+		// Errors of the references in the value are reported as they are;
+		// any other error means the provider returned data resolution
+		// cannot represent, which fails like the fetch.
+		var refErr *core.ReferenceError
+		if errors.As(n.err, &refErr) {
+			return value.Null(), false, n.err
+		}
+		return value.Null(), false, r.handleFetchError(ref, ref.Path, n.err)
 	}
 
-	// Unwrap single-key "value" objects that some providers return for scalars
-	// This is a workaround for provider implementations that wrap scalar values
-	if m, ok := val.(map[string]any); ok && len(m) == 1 {
-		if unwrapped, exists := m["value"]; exists {
-			val = unwrapped
+	cached := n.reported
+	n.reported = true
+	return n.resolved, cached, nil
+}
+
+// resolveCall resolves a function call's arguments and evaluates it.
+// Secret arguments taint the result, so it is also emitted as a secret.
+func (r *Resolver) resolveCall(ctx context.Context, call models.Call, path string) (value.Value, error) {
+	args := make([]any, len(call.Args))
+	secret := false
+	for i, arg := range call.Args {
+		resolved, err := r.resolveValue(ctx, arg, path)
+		if err != nil {
+			return value.Value{}, err
+		}
+		if inner, ok := resolved.Secret(); ok {
+			resolved = inner
+			secret = true
 		}
+		args[i] = resolved.Any()
 	}
 
-	// Resolve any nested references returned by the provider.
-	resolved, err := r.ResolveValue(ctx, val)
+	result, err := functions.Call(call.Name, args)
+	var v value.Value
+	if err == nil {
+		v, err = value.Of(result)
+	}
 	if err != nil {
-		return nil, err
+		return value.Value{}, &core.FunctionError{
+			Name:     call.Name,
+			Filename: call.SourceSpan.Filename,
+			Line:     call.SourceSpan.StartLine,
+			Column:   call.SourceSpan.StartCol,
+			Err:      err,
+		}
 	}
-
-	// Cache result
-	r.cache.set(cacheKey, resolved)
-
-	return resolved, nil
+	if secret {
+		return value.Secret(v), nil
+	}
+	return v, nil
 }
 
 // resolveMap resolves all values in a map.
-func (r *Resolver) resolveMap(ctx context.Context, m map[string]any) (map[string]any, error) {
+func (r *Resolver) resolveMap(ctx context.Context, m map[string]any, path string) (value.Value, error) {
 	if ordered, ok := m[converter.OrderedEntriesKey]; ok {
 		entries, ok := ordered.([]converter.OrderedEntry)
 		if !ok {
-			return nil, fmt.Errorf("invalid ordered entries payload")
+			return value.Value{}, fmt.Errorf("invalid ordered entries payload")
 		}
-		return r.resolveOrderedEntries(ctx, entries)
+		return r.resolveOrderedEntries(ctx, entries, path)
 	}
 
-	result := make(map[string]any, len(m))
-
-	for k, v := range m {
-		if k == converter.OrderedEntriesKey {
-			continue
+	// Resolve keys in sorted order, so observers see references and the
+	// first error is reported in the same order on every run
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != converter.OrderedEntriesKey {
+			keys = append(keys, k)
 		}
-		resolved, err := r.ResolveValue(ctx, v)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]value.Value, len(keys))
+	for _, k := range keys {
+		resolved, err := r.resolveValue(ctx, m[k], joinPath(path, k))
 		if err != nil {
-			return nil, fmt.Errorf("resolving key %q: %w", k, err)
+			return value.Value{}, fmt.Errorf("resolving key %q: %w", k, err)
 		}
 		result[k] = resolved
 	}
 
-	return result, nil
+	return value.Map(result), nil
 }
 
-func (r *Resolver) resolveOrderedEntries(ctx context.Context, entries []converter.OrderedEntry) (map[string]any, error) {
-	result := make(map[string]any)
+func (r *Resolver) resolveOrderedEntries(ctx context.Context, entries []converter.OrderedEntry, path string) (value.Value, error) {
+	result := make(map[string]value.Value, len(entries))
 
 	for _, entry := range entries {
-		resolved, err := r.ResolveValue(ctx, entry.Value)
+		entryPath := path
+		if !entry.Spread {
+			entryPath = joinPath(path, entry.Key)
+		}
+		resolved, err := r.resolveValue(ctx, entry.Value, entryPath)
 		if err != nil {
-			return nil, err
+			return value.Value{}, err
 		}
 		if entry.Spread {
-			mapValue, ok := resolved.(map[string]any)
-			if !ok {
-				return nil, fmt.Errorf("spread reference must resolve to map, got %T", resolved)
+			if resolved.Kind() != value.KindMap {
+				return value.Value{}, fmt.Errorf("spread reference must resolve to map, got %s", resolved.Kind())
+			}
+			ref, _ := entry.Value.(*ast.ReferenceExpr)
+			for _, k := range resolved.Keys() {
+				v, _ := resolved.Field(k)
+				if existing, ok := result[k]; ok {
+					r.reportMerge(joinPath(path, k), ref, existing, v)
+					v = value.Merge(existing, v)
+				}
+				result[k] = v
 			}
-			result = deepMergeMap(result, mapValue)
 			continue
 		}
 
 		if existing, ok := result[entry.Key]; ok {
-			existingMap, existingIsMap := existing.(map[string]any)
-			resolvedMap, resolvedIsMap := resolved.(map[string]any)
-			if existingIsMap && resolvedIsMap {
-				result[entry.Key] = deepMergeMap(existingMap, resolvedMap)
-				continue
-			}
+			r.reportMerge(entryPath, nil, existing, resolved)
+			resolved = value.Merge(existing, resolved)
 		}
-
 		result[entry.Key] = resolved
 	}
 
-	return result, nil
+	return value.Map(result), nil
 }
 
-func deepMergeMap(dst, src map[string]any) map[string]any {
-	result := make(map[string]any, len(dst)+len(src))
-	for k, v := range dst {
-		result[k] = deepCopyValue(v)
-	}
-	for k, v := range src {
-		if existing, ok := result[k]; ok {
-			result[k] = mergeValues(existing, v)
-			continue
-		}
-		result[k] = deepCopyValue(v)
+// reportMerge passes a key set again by a later entry to OnMerge.
+func (r *Resolver) reportMerge(path string, ref *ast.ReferenceExpr, dst, src value.Value) {
+	if r.opts.OnMerge == nil {
+		return
 	}
-	return result
+	r.opts.OnMerge(MergeEvent{Path: path, Ref: ref, Merged: dst.Kind() == value.KindMap && src.Kind() == value.KindMap})
 }
 
-func mergeValues(dst, src any) any {
-	if src == nil {
-		return nil
-	}
-	if dst == nil {
-		return deepCopyValue(src)
-	}
-	leftMap, leftIsMap := dst.(map[string]any)
-	rightMap, rightIsMap := src.(map[string]any)
-	if leftIsMap && rightIsMap {
-		return deepMergeMap(leftMap, rightMap)
+// joinPath appends key to a key path in source map syntax.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
 	}
-	return deepCopyValue(src)
-}
-
-func deepCopyValue(val any) any {
-	if val == nil {
-		return nil
-	}
-	if mapVal, ok := val.(map[string]any); ok {
-		copied := make(map[string]any, len(mapVal))
-		for k, v := range mapVal {
-			copied[k] = deepCopyValue(v)
-		}
-		return copied
-	}
-	if sliceVal, ok := val.([]any); ok {
-		copied := make([]any, len(sliceVal))
-		for i, v := range sliceVal {
-			copied[i] = deepCopyValue(v)
-		}
-		return copied
-	}
-	return val
+	return path + "." + key
 }
 
 // resolveSlice resolves all elements in a slice.
-func (r *Resolver) resolveSlice(ctx context.Context, s []any) ([]any, error) {
-	result := make([]any, len(s))
+func (r *Resolver) resolveSlice(ctx context.Context, s []any, path string) (value.Value, error) {
+	result := make([]value.Value, len(s))
 
 	for i, v := range s {
-		resolved, err := r.ResolveValue(ctx, v)
+		resolved, err := r.resolveValue(ctx, v, fmt.Sprintf("%s[%d]", path, i))
 		if err != nil {
-			return nil, fmt.Errorf("resolving index %d: %w", i, err)
+			return value.Value{}, fmt.Errorf("resolving index %d: %w", i, err)
 		}
 		result[i] = resolved
 	}
 
-	return result, nil
+	return value.List(result...), nil
+}
+
+// fetch calls provider.Fetch, bounded by FetchTimeout when configured, and
+// retries transient failures as the Retry policy allows.
+func (r *Resolver) fetch(ctx context.Context, provider core.Provider, path []string) (any, error) {
+	for attempt := 1; ; attempt++ {
+		val, err := r.fetchOnce(ctx, provider, path)
+		if err == nil || attempt >= r.opts.Retry.MaxAttempts || !core.Transient(err) || ctx.Err() != nil {
+			if err != nil && attempt > 1 {
+				err = fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return val, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (after %d attempts)", err, attempt)
+		case <-time.After(r.opts.Retry.Backoff(attempt)):
+		}
+	}
+}
+
+// fetchOnce calls provider.Fetch, bounded by FetchTimeout when configured.
+func (r *Resolver) fetchOnce(ctx context.Context, provider core.Provider, path []string) (any, error) {
+	if r.opts.FetchTimeout <= 0 {
+		return provider.Fetch(ctx, path)
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, r.opts.FetchTimeout)
+	defer cancel()
+	return provider.Fetch(fetchCtx, path)
 }
 
 // handleProviderError handles errors from GetProvider.
-func (r *Resolver) handleProviderError(ref *ast.ReferenceExpr, _ error) error {
+func (r *Resolver) handleProviderError(ref *ast.ReferenceExpr, err error) error {
 	if r.opts.AllowMissingProvider && r.opts.OnWarning != nil {
-		warning := fmt.Sprintf("provider %q not found for reference at %s:%d:%d",
-			ref.Alias,
-			ref.SourceSpan.Filename,
-			ref.SourceSpan.StartLine,
-			ref.SourceSpan.StartCol,
-		)
-		r.opts.OnWarning(warning)
+		r.opts.OnWarning(diagnostic.Diagnostic{
+			Severity:   diagnostic.SeverityWarning,
+			Code:       diagnostic.CodeMissingProvider,
+			Message:    fmt.Sprintf("provider %q not found for reference @%s:%s", ref.Alias, ref.Alias, strings.Join(ref.Path, ".")),
+			SourceSpan: ref.SourceSpan,
+		})
 		return nil // Return nil value for missing provider
 	}
 
-	// Fatal error
-	return fmt.Errorf("%w: provider %q at %s:%d:%d",
-		ErrProviderNotRegistered,
-		ref.Alias,
-		ref.SourceSpan.Filename,
-		ref.SourceSpan.StartLine,
-		ref.SourceSpan.StartCol,
-	)
+	// Fatal error: a provider that exists but failed to start is unavailable;
+	// anything else means the alias is unknown.
+	if errors.Is(err, core.ErrProviderUnavailable) {
+		return r.fail(newReferenceError(ref, core.ErrProviderUnavailable, err))
+	}
+	return r.fail(newReferenceError(ref, core.ErrUnknownAlias,
+		fmt.Errorf("%w: %q", ErrProviderNotRegistered, ref.Alias)))
 }
 
 // handleFetchError handles errors from provider.Fetch.
 func (r *Resolver) handleFetchError(ref *ast.ReferenceExpr, path []string, err error) error {
+	if errors.Is(err, core.ErrLimitExceeded) {
+		return r.fail(newReferenceError(ref, core.ErrLimitExceeded, err))
+	}
+
 	if r.opts.AllowMissingProvider && r.opts.OnWarning != nil {
-		warning := fmt.Sprintf("failed to fetch reference %q:%v at %s:%d:%d: %v",
-			ref.Alias,
-			path,
-			ref.SourceSpan.Filename,
-			ref.SourceSpan.StartLine,
-			ref.SourceSpan.StartCol,
-			err,
-		)
-		r.opts.OnWarning(warning)
+		r.opts.OnWarning(diagnostic.Diagnostic{
+			Severity:   diagnostic.SeverityWarning,
+			Code:       diagnostic.CodeFetchFailed,
+			Message:    fmt.Sprintf("failed to fetch reference @%s:%s: %v", ref.Alias, strings.Join(path, "."), err),
+			SourceSpan: ref.SourceSpan,
+		})
 		return nil // Return nil value for failed fetch
 	}
 
 	// Fatal error
-	return fmt.Errorf("%w: failed to fetch %q:%v at %s:%d:%d: %w",
-		ErrUnresolvedReference,
-		ref.Alias,
-		path,
-		ref.SourceSpan.Filename,
-		ref.SourceSpan.StartLine,
-		ref.SourceSpan.StartCol,
-		err,
-	)
-}
-
-// buildCacheKey creates a cache key from provider alias and path.
-func buildCacheKey(alias string, path []string) string {
-	return alias + ":" + strings.Join(path, "/")
-}
-
-// fetchCache stores provider fetch results for the compilation run.
-//
-// The cache is thread-safe using read-write locks for concurrent access.
-// For high-concurrency scenarios with many duplicate references, consider
-// upgrading to use golang.org/x/sync/singleflight to deduplicate in-flight
-// requests and avoid thundering herd on cold cache.
-type fetchCache struct {
-	mu    sync.RWMutex
-	store map[string]any
+	kind := ErrUnresolvedReference
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, core.ErrTimeout):
+		kind = core.ErrTimeout
+	case errors.Is(err, core.ErrProviderUnavailable):
+		kind = core.ErrProviderUnavailable
+	case errors.Is(err, core.ErrProviderMessageTooLarge):
+		kind = core.ErrProviderMessageTooLarge
+	}
+	return r.fail(newReferenceError(ref, kind, fmt.Errorf("%w: failed to fetch: %w", ErrUnresolvedReference, err)))
 }
 
-// newFetchCache creates a new fetchCache.
-func newFetchCache() *fetchCache {
-	return &fetchCache{
-		store: make(map[string]any),
+// fail passes err to OnFailure and returns nil when it is set, so
+// resolution continues past the failed reference; otherwise it returns err.
+func (r *Resolver) fail(err error) error {
+	if r.opts.OnFailure == nil {
+		return err
 	}
+	r.opts.OnFailure(err)
+	return nil
 }
 
-// get retrieves a cached value.
-func (c *fetchCache) get(key string) (any, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	val, ok := c.store[key]
-	return val, ok
+// newReferenceError builds a *core.ReferenceError locating ref in source.
+func newReferenceError(ref *ast.ReferenceExpr, kind, err error) error {
+	return &core.ReferenceError{
+		Alias:    ref.Alias,
+		Path:     ref.Path,
+		Filename: ref.SourceSpan.Filename,
+		Line:     ref.SourceSpan.StartLine,
+		Column:   ref.SourceSpan.StartCol,
+		Kind:     kind,
+		Err:      err,
+	}
 }
 
-// set stores a value in the cache.
-func (c *fetchCache) set(key string, val any) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.store[key] = val
+// buildCacheKey creates a cache key from provider alias and path.
+func buildCacheKey(alias string, path []string) string {
+	return alias + ":" + strings.Join(path, "/")
 }
@@ -3,13 +3,16 @@ package resolver
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/pkg/value"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
@@ -239,8 +242,8 @@ func TestResolveValue_ReferenceExpr_AllowMissingProvider(t *testing.T) {
 	resolver := New(ResolverOptions{
 		ProviderRegistry:     registry,
 		AllowMissingProvider: true,
-		OnWarning: func(warning string) {
-			warnings = append(warnings, warning)
+		OnWarning: func(warning diagnostic.Diagnostic) {
+			warnings = append(warnings, warning.Message)
 		},
 	})
 
@@ -325,8 +328,8 @@ func TestResolveValue_ReferenceExpr_FetchError_AllowMissing(t *testing.T) {
 	resolver := New(ResolverOptions{
 		ProviderRegistry:     registry,
 		AllowMissingProvider: true,
-		OnWarning: func(warning string) {
-			warnings = append(warnings, warning)
+		OnWarning: func(warning diagnostic.Diagnostic) {
+			warnings = append(warnings, warning.Message)
 		},
 	})
 
@@ -656,3 +659,112 @@ func TestResolveValue_OrderedEntries(t *testing.T) {
 		t.Errorf("expected b=2, got %v", m["b"])
 	}
 }
+
+// TestResolveValue_SharedTarget tests that keys resolved from one cached
+// provider value, and merged over, share no maps with each other or with
+// the provider's data.
+func TestResolveValue_SharedTarget(t *testing.T) {
+	registry := newFakeProviderRegistry()
+	provider := newFakeProvider("cfg")
+	db := map[string]any{"host": "prod-db", "pool": map[string]any{"size": 5}}
+	provider.FetchResponses["db"] = db
+	registry.addProvider("cfg", provider)
+	resolver := New(ResolverOptions{ProviderRegistry: registry})
+
+	ref := &ast.ReferenceExpr{Alias: "cfg", Path: []string{"db"}}
+	input := map[string]any{
+		"a": ref,
+		"b": map[string]any{
+			converter.OrderedEntriesKey: []converter.OrderedEntry{
+				{Value: ref, Spread: true},
+				{Key: "pool", Value: map[string]any{"idle": 1}},
+			},
+		},
+	}
+	result, err := resolver.ResolveValue(context.Background(), input)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	m := result.(map[string]any)
+	a := m["a"].(map[string]any)
+	a["host"] = "changed"
+	a["pool"].(map[string]any)["size"] = 10
+
+	wantB := map[string]any{"host": "prod-db", "pool": map[string]any{"size": 5, "idle": 1}}
+	if !reflect.DeepEqual(m["b"], wantB) {
+		t.Errorf("b = %v, want %v", m["b"], wantB)
+	}
+	wantDB := map[string]any{"host": "prod-db", "pool": map[string]any{"size": 5}}
+	if !reflect.DeepEqual(db, wantDB) {
+		t.Errorf("provider data = %v, want %v", db, wantDB)
+	}
+	if provider.FetchCount != 1 {
+		t.Errorf("expected 1 fetch, got %d", provider.FetchCount)
+	}
+}
+
+// TestResolveValue_UnsupportedProviderValue tests that a provider value
+// holding a type resolution cannot represent fails its reference.
+func TestResolveValue_UnsupportedProviderValue(t *testing.T) {
+	registry := newFakeProviderRegistry()
+	provider := newFakeProvider("cfg")
+	provider.FetchResponses["tags"] = []string{"a", "b"}
+	registry.addProvider("cfg", provider)
+	resolver := New(ResolverOptions{ProviderRegistry: registry})
+
+	input := map[string]any{"tags": &ast.ReferenceExpr{Alias: "cfg", Path: []string{"tags"}}}
+	_, err := resolver.ResolveValue(context.Background(), input)
+	if !errors.Is(err, ErrUnresolvedReference) || !strings.Contains(err.Error(), "unsupported type []string") {
+		t.Errorf("expected unresolved reference for the unsupported type, got %v", err)
+	}
+}
+
+// TestResolveValue_Observers tests that OnReference and OnMerge report
+// lookups and repeated keys with their key paths.
+func TestResolveValue_Observers(t *testing.T) {
+	provider := newFakeProvider("cfg")
+	provider.FetchResponses["app/*"] = map[string]any{"a": 10, "c": 30}
+	provider.FetchResponses["name"] = "demo"
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", provider)
+
+	var refs []ReferenceEvent
+	var merges []MergeEvent
+	resolver := New(ResolverOptions{
+		ProviderRegistry: registry,
+		OnReference:      func(e ReferenceEvent) { refs = append(refs, e) },
+		OnMerge:          func(e MergeEvent) { merges = append(merges, e) },
+	})
+
+	spread := &ast.ReferenceExpr{Alias: "cfg", Path: []string{"app", "*"}}
+	input := map[string]any{
+		"svc": map[string]any{
+			converter.OrderedEntriesKey: []converter.OrderedEntry{
+				{Key: "a", Value: 1},
+				{Value: spread, Spread: true},
+				{Key: "c", Value: 3},
+			},
+		},
+		"list": []any{&ast.ReferenceExpr{Alias: "cfg", Path: []string{"name"}}},
+	}
+	if _, err := resolver.ResolveValue(context.Background(), input); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	paths := make(map[string]ReferenceEvent)
+	for _, e := range refs {
+		paths[e.Path] = e
+	}
+	if len(refs) != 2 || paths["svc"].Ref != spread || paths["svc"].Provider != "cfg" || !value.Equal(paths["list[0]"].Value, value.String("demo")) {
+		t.Errorf("OnReference events = %+v", refs)
+	}
+
+	want := []MergeEvent{
+		{Path: "svc.a", Ref: spread},
+		{Path: "svc.c"},
+	}
+	if !reflect.DeepEqual(merges, want) {
+		t.Errorf("OnMerge events = %+v, want %+v", merges, want)
+	}
+}
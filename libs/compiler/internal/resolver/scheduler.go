@@ -0,0 +1,415 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/pkg/value"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// node is one reference target of the graph: a path of the provider
+// registered under a key. References to the same target share its node, so
+// the target is fetched and resolved once per compilation run.
+type node struct {
+	key   string
+	alias string // of the first reference, naming the node in cycles
+	path  []string
+
+	// site is the key path of the first reference, reported to observers
+	// for the references in the fetched value.
+	site string
+
+	// raw is the fetched value, without the "value" wrapper some providers
+	// add to scalars; deps are the references it holds, in document order.
+	raw         any
+	providerErr error
+	fetchErr    error
+	deps        []edge
+
+	// cycle is set when the node is part of a circular reference.
+	cycle *core.CycleError
+
+	resolving bool
+	done      bool
+	resolved  value.Value
+	err       error
+
+	// reported records that a lookup returned the value, so later lookups
+	// report it as cached.
+	reported bool
+}
+
+// name returns the node as "alias:path".
+func (n *node) name() string {
+	return n.alias + ":" + pathKey(n.path)
+}
+
+// edge is a reference to a node, written in a source file or held by the
+// fetched value of another node.
+type edge struct {
+	ref *ast.ReferenceExpr
+	to  *node
+}
+
+// schedule adds the references in val, found at key path, to the graph.
+// It fetches the new nodes level by level, in one batch per provider, then
+// resolves them in dependency order so that each node's references are
+// resolved before it.
+func (r *Resolver) schedule(ctx context.Context, val any, path string) {
+	var roots []edge
+	var pending []*node
+	collectReferences(val, path, func(ref *ast.ReferenceExpr, site string) {
+		n, created := r.node(ref, site)
+		if created {
+			pending = append(pending, n)
+		}
+		roots = append(roots, edge{ref: ref, to: n})
+	})
+
+	for len(pending) > 0 {
+		r.fetchNodes(ctx, pending)
+		var next []*node
+		for _, n := range pending {
+			if err := r.checkFanOut(n.raw); err != nil {
+				n.raw, n.fetchErr = nil, err
+				continue
+			}
+			collectReferences(n.raw, n.site, func(ref *ast.ReferenceExpr, site string) {
+				dep, created := r.node(ref, site)
+				if created {
+					next = append(next, dep)
+				}
+				n.deps = append(n.deps, edge{ref: ref, to: dep})
+			})
+		}
+		pending = next
+	}
+
+	for _, n := range sortNodes(roots) {
+		r.resolveNode(ctx, n)
+	}
+}
+
+// node returns the node ref targets, creating it with site as its first
+// reference's key path if the graph has none.
+func (r *Resolver) node(ref *ast.ReferenceExpr, site string) (*node, bool) {
+	key := r.providerKey(ref)
+	id := buildCacheKey(key, ref.Path)
+	if n, ok := r.nodes[id]; ok {
+		return n, false
+	}
+	n := &node{key: key, alias: ref.Alias, path: ref.Path, site: site}
+	r.nodes[id] = n
+	return n, true
+}
+
+// resolveNode resolves the references in the fetched value of n, once.
+func (r *Resolver) resolveNode(ctx context.Context, n *node) {
+	if n.done || n.resolving || n.providerErr != nil || n.fetchErr != nil {
+		return
+	}
+	if n.cycle != nil {
+		n.done = true
+		return
+	}
+	n.resolving = true
+	n.resolved, n.err = r.resolveValue(ctx, n.raw, n.site)
+	n.resolving, n.done = false, true
+}
+
+// sortNodes returns the unresolved nodes reachable from roots, each after
+// the nodes its value references. The nodes of each cycle are marked with
+// a CycleError locating every reference forming it.
+func sortNodes(roots []edge) []*node {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*node]int)
+	var stack []edge
+	var sorted []*node
+
+	var visit func(e edge)
+	visit = func(e edge) {
+		n := e.to
+		switch {
+		case state[n] == visited:
+			return
+		case state[n] == visiting:
+			markCycle(stack, e)
+			return
+		case n.done:
+			state[n] = visited
+			return
+		}
+		state[n] = visiting
+		stack = append(stack, e)
+		for _, dep := range n.deps {
+			visit(dep)
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = visited
+		sorted = append(sorted, n)
+	}
+	for _, e := range roots {
+		visit(e)
+	}
+	return sorted
+}
+
+// markCycle marks the nodes of the cycle closed by edge back, from the node
+// back targets to the top of the stack of edges being visited.
+func markCycle(stack []edge, back edge) {
+	start := 0
+	for i, e := range stack {
+		if e.to == back.to {
+			start = i
+		}
+	}
+
+	cycle := &core.CycleError{}
+	for i := start; i <= len(stack); i++ {
+		e := back
+		if i < len(stack) {
+			e = stack[i]
+		}
+		hop := core.CycleHop{
+			Ref:      refName(e.ref),
+			Filename: e.ref.SourceSpan.Filename,
+			Line:     e.ref.SourceSpan.StartLine,
+			Column:   e.ref.SourceSpan.StartCol,
+		}
+		if i > 0 {
+			hop.Via = refName(stack[i-1].ref)
+		}
+		cycle.Chain = append(cycle.Chain, hop.Ref)
+		cycle.Hops = append(cycle.Hops, hop)
+	}
+
+	for _, e := range stack[start:] {
+		if e.to.cycle == nil {
+			e.to.cycle = cycle
+		}
+	}
+}
+
+// refName returns ref as "alias:path".
+func refName(ref *ast.ReferenceExpr) string {
+	return ref.Alias + ":" + pathKey(ref.Path)
+}
+
+func pathKey(path []string) string {
+	if len(path) == 0 {
+		return "*"
+	}
+	return strings.Join(path, ":")
+}
+
+// fetchNodes fetches the values of nodes in one batch per provider. The
+// batches run concurrently, at most MaxConcurrency at a time.
+func (r *Resolver) fetchNodes(ctx context.Context, nodes []*node) {
+	var keys []string
+	batches := make(map[string][]*node)
+	for _, n := range nodes {
+		if _, ok := batches[n.key]; !ok {
+			keys = append(keys, n.key)
+		}
+		batches[n.key] = append(batches[n.key], n)
+	}
+
+	var sem chan struct{}
+	if r.opts.MaxConcurrency > 0 {
+		sem = make(chan struct{}, r.opts.MaxConcurrency)
+	}
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		wg.Go(func() {
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			r.fetchBatch(ctx, key, batches[key])
+		})
+	}
+	wg.Wait()
+}
+
+// fetchBatch fetches the values of nodes from the provider registered as
+// key, with one FetchBatch call if the provider supports it and one Fetch
+// call per node otherwise.
+func (r *Resolver) fetchBatch(ctx context.Context, key string, nodes []*node) {
+	provider, err := r.opts.ProviderRegistry.GetProvider(key)
+	if err != nil {
+		for _, n := range nodes {
+			n.providerErr = err
+		}
+		return
+	}
+
+	results := make([]core.FetchResult, len(nodes))
+	if batcher, ok := provider.(core.BatchProvider); ok {
+		paths := make([][]string, len(nodes))
+		for i, n := range nodes {
+			paths[i] = n.path
+		}
+		results = r.fetchMany(ctx, batcher, paths)
+	} else {
+		for i, n := range nodes {
+			results[i].Value, results[i].Err = r.fetch(ctx, provider, n.path)
+		}
+	}
+
+	for i, n := range nodes {
+		n.raw, n.fetchErr = unwrapValue(results[i].Value), results[i].Err
+		if n.fetchErr == nil {
+			if err := r.opts.Limits.CheckValue(n.raw, "provider value"); err != nil {
+				n.raw, n.fetchErr = nil, err
+			}
+		}
+	}
+}
+
+// checkFanOut returns a *core.LimitError if val holds more references than
+// Limits.MaxReferenceFanOut.
+func (r *Resolver) checkFanOut(val any) error {
+	limit := r.opts.Limits.MaxReferenceFanOut
+	if limit <= 0 {
+		return nil
+	}
+	refs := 0
+	collectReferences(val, "", func(*ast.ReferenceExpr, string) { refs++ })
+	if refs > limit {
+		return &core.LimitError{Limit: core.LimitMaxReferenceFanOut, Max: int64(limit), Subject: "provider value"}
+	}
+	return nil
+}
+
+// fetchMany calls provider.FetchBatch, bounded by FetchTimeout when
+// configured, and fetches again the paths that failed with transient
+// errors as the Retry policy allows.
+func (r *Resolver) fetchMany(ctx context.Context, provider core.BatchProvider, paths [][]string) []core.FetchResult {
+	results := make([]core.FetchResult, len(paths))
+	pending := make([]int, len(paths))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for attempt := 1; ; attempt++ {
+		batch := make([][]string, len(pending))
+		for j, i := range pending {
+			batch[j] = paths[i]
+		}
+		fetched := r.fetchBatchOnce(ctx, provider, batch)
+
+		var retry []int
+		for j, i := range pending {
+			results[i] = fetched[j]
+			if fetched[j].Err != nil && core.Transient(fetched[j].Err) {
+				retry = append(retry, i)
+			}
+		}
+		done := len(retry) == 0 || attempt >= r.opts.Retry.MaxAttempts || ctx.Err() != nil
+		if !done {
+			select {
+			case <-ctx.Done():
+				done = true
+			case <-time.After(r.opts.Retry.Backoff(attempt)):
+			}
+		}
+		if done {
+			if attempt > 1 {
+				for _, i := range pending {
+					if results[i].Err != nil {
+						results[i].Err = fmt.Errorf("%w (after %d attempts)", results[i].Err, attempt)
+					}
+				}
+			}
+			return results
+		}
+		pending = retry
+	}
+}
+
+// fetchBatchOnce calls provider.FetchBatch, bounded by FetchTimeout when
+// configured.
+func (r *Resolver) fetchBatchOnce(ctx context.Context, provider core.BatchProvider, paths [][]string) []core.FetchResult {
+	if r.opts.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.FetchTimeout)
+		defer cancel()
+	}
+	results := provider.FetchBatch(ctx, paths)
+	if len(results) != len(paths) {
+		err := fmt.Errorf("provider returned %d results for %d paths", len(results), len(paths))
+		results = make([]core.FetchResult, len(paths))
+		for i := range results {
+			results[i].Err = err
+		}
+	}
+	return results
+}
+
+// unwrapValue unwraps single-key "value" objects that some providers return
+// for scalars.
+func unwrapValue(val any) any {
+	if m, ok := val.(map[string]any); ok && len(m) == 1 {
+		if unwrapped, exists := m["value"]; exists {
+			return unwrapped
+		}
+	}
+	return val
+}
+
+// collectReferences calls visit with each reference in val, found at key
+// path, and the key path holding it, as resolveValue reports them.
+func collectReferences(val any, path string, visit func(ref *ast.ReferenceExpr, path string)) {
+	switch v := val.(type) {
+	case *ast.ReferenceExpr:
+		visit(v, path)
+
+	case map[string]any:
+		if entries, ok := v[converter.OrderedEntriesKey].([]converter.OrderedEntry); ok {
+			for _, entry := range entries {
+				entryPath := path
+				if !entry.Spread {
+					entryPath = joinPath(path, entry.Key)
+				}
+				collectReferences(entry.Value, entryPath, visit)
+			}
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if k != converter.OrderedEntriesKey {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectReferences(v[k], joinPath(path, k), visit)
+		}
+
+	case []any:
+		for i, elem := range v {
+			collectReferences(elem, fmt.Sprintf("%s[%d]", path, i), visit)
+		}
+
+	case models.Secret:
+		collectReferences(v.Value, path, visit)
+
+	case models.Call:
+		for _, arg := range v.Args {
+			collectReferences(arg, path, visit)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// fakeBatchProvider is a fakeProvider that also fetches in batches,
+// recording the paths of each batch.
+type fakeBatchProvider struct {
+	*fakeProvider
+	batches [][]string
+	errs    map[string]error // path to the error of its next fetch
+	mu      sync.Mutex
+}
+
+func (f *fakeBatchProvider) FetchBatch(ctx context.Context, paths [][]string) []core.FetchResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var batch []string
+	results := make([]core.FetchResult, len(paths))
+	for i, path := range paths {
+		key := strings.Join(path, "/")
+		batch = append(batch, key)
+		if err, ok := f.errs[key]; ok {
+			delete(f.errs, key)
+			results[i].Err = err
+			continue
+		}
+		results[i].Value, results[i].Err = f.Fetch(ctx, path)
+	}
+	f.batches = append(f.batches, batch)
+	return results
+}
+
+func ref(alias, path string, line int) *ast.ReferenceExpr {
+	return &ast.ReferenceExpr{
+		Alias:      alias,
+		Path:       strings.Split(path, "."),
+		SourceSpan: ast.SourceSpan{Filename: "test.csl", StartLine: line, StartCol: 5},
+	}
+}
+
+func TestSchedule_BatchesPerAlias(t *testing.T) {
+	cfg := &fakeBatchProvider{fakeProvider: newFakeProvider("cfg")}
+	cfg.FetchResponses["a"] = "A"
+	cfg.FetchResponses["b"] = map[string]any{"c": ref("cfg", "c", 1), "d": ref("plain", "d", 2)}
+	cfg.FetchResponses["c"] = "C"
+	plain := newFakeProvider("plain")
+	plain.FetchResponses["d"] = "D"
+	plain.FetchResponses["e"] = "E"
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", cfg)
+	registry.addProvider("plain", plain)
+
+	input := map[string]any{
+		converter.OrderedEntriesKey: []converter.OrderedEntry{
+			{Key: "a", Value: ref("cfg", "a", 1)},
+			{Key: "b", Value: ref("cfg", "b", 2)},
+			{Key: "again", Value: ref("cfg", "a", 3)},
+			{Key: "e", Value: ref("plain", "e", 4)},
+		},
+	}
+	result, err := New(ResolverOptions{ProviderRegistry: registry, MaxConcurrency: 1}).ResolveValue(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ResolveValue() error = %v", err)
+	}
+
+	want := map[string]any{"a": "A", "b": map[string]any{"c": "C", "d": "D"}, "again": "A", "e": "E"}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %v, want %v", result, want)
+	}
+	if wantBatches := [][]string{{"a", "b"}, {"c"}}; !reflect.DeepEqual(cfg.batches, wantBatches) {
+		t.Errorf("batches = %v, want %v", cfg.batches, wantBatches)
+	}
+	if plain.FetchCount != 2 {
+		t.Errorf("plain provider fetched %d times, want 2", plain.FetchCount)
+	}
+}
+
+func TestSchedule_BatchRetry(t *testing.T) {
+	cfg := &fakeBatchProvider{
+		fakeProvider: newFakeProvider("cfg"),
+		errs:         map[string]error{"b": core.ErrProviderUnavailable},
+	}
+	cfg.FetchResponses["a"] = "A"
+	cfg.FetchResponses["b"] = "B"
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", cfg)
+
+	input := []any{ref("cfg", "a", 1), ref("cfg", "b", 2)}
+	r := New(ResolverOptions{
+		ProviderRegistry: registry,
+		Retry:            core.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	result, err := r.ResolveValue(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ResolveValue() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, []any{"A", "B"}) {
+		t.Errorf("result = %v, want [A B]", result)
+	}
+	if want := [][]string{{"a", "b"}, {"b"}}; !reflect.DeepEqual(cfg.batches, want) {
+		t.Errorf("batches = %v, want only the failed path retried", cfg.batches)
+	}
+}
+
+func TestSchedule_CycleHops(t *testing.T) {
+	// root -> a -> b -> a, with root outside the cycle
+	provider := newFakeProvider("cfg")
+	provider.FetchResponses["root"] = ref("cfg", "a", 10)
+	provider.FetchResponses["a"] = ref("cfg", "b", 20)
+	provider.FetchResponses["b"] = ref("cfg", "a", 30)
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", provider)
+
+	input := map[string]any{"svc": ref("cfg", "root", 1)}
+	_, err := New(ResolverOptions{ProviderRegistry: registry}).ResolveValue(context.Background(), input)
+
+	var cycle *core.CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("ResolveValue() error = %v, want a CycleError", err)
+	}
+	wantHops := []core.CycleHop{
+		{Ref: "cfg:a", Filename: "test.csl", Line: 10, Column: 5, Via: "cfg:root"},
+		{Ref: "cfg:b", Filename: "test.csl", Line: 20, Column: 5, Via: "cfg:a"},
+		{Ref: "cfg:a", Filename: "test.csl", Line: 30, Column: 5, Via: "cfg:b"},
+	}
+	if !reflect.DeepEqual(cycle.Hops, wantHops) {
+		t.Errorf("Hops = %+v, want %+v", cycle.Hops, wantHops)
+	}
+	if want := []string{"cfg:a", "cfg:b", "cfg:a"}; !reflect.DeepEqual(cycle.Chain, want) {
+		t.Errorf("Chain = %v, want %v", cycle.Chain, want)
+	}
+	lines := strings.Split(cycle.Error(), "\n")
+	if len(lines) != 4 || lines[0] != "circular reference detected: cfg:a → cfg:b → cfg:a" ||
+		lines[2] != "  cfg:b referenced at test.csl:20:5 in the value of cfg:a" {
+		t.Errorf("Error() = %q", cycle.Error())
+	}
+}
+
+func TestSchedule_DependencyOrder(t *testing.T) {
+	provider := newFakeProvider("cfg")
+	provider.FetchResponses["outer"] = map[string]any{"inner": ref("cfg", "inner", 1)}
+	provider.FetchResponses["inner"] = "value"
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", provider)
+
+	var resolved []string
+	r := New(ResolverOptions{
+		ProviderRegistry: registry,
+		OnReference: func(e ReferenceEvent) {
+			resolved = append(resolved, e.Path+"="+refName(e.Ref))
+		},
+	})
+	input := map[string]any{"first": ref("cfg", "inner", 2), "second": ref("cfg", "outer", 3)}
+	if _, err := r.ResolveValue(context.Background(), input); err != nil {
+		t.Fatalf("ResolveValue() error = %v", err)
+	}
+
+	// The value of cfg:outer is resolved before the keys referencing it
+	want := []string{"second.inner=cfg:inner", "first=cfg:inner", "second=cfg:outer"}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("resolution order = %v, want %v", resolved, want)
+	}
+}
+
+func TestSchedule_Limits(t *testing.T) {
+	recursive := map[string]any{"name": "loop"}
+	recursive["self"] = recursive
+
+	cfg := &fakeBatchProvider{fakeProvider: newFakeProvider("cfg")}
+	cfg.FetchResponses["fan"] = []any{ref("cfg", "a", 1), ref("cfg", "b", 2), ref("cfg", "c", 3)}
+	cfg.FetchResponses["loop"] = recursive
+	cfg.FetchResponses["a"] = "A"
+	cfg.FetchResponses["b"] = "B"
+	cfg.FetchResponses["c"] = "C"
+	registry := newFakeProviderRegistry()
+	registry.addProvider("cfg", cfg)
+
+	tests := []struct {
+		name      string
+		path      string
+		limits    core.Limits
+		wantLimit string
+	}{
+		{name: "fan-out within limit", path: "fan", limits: core.Limits{MaxReferenceFanOut: 3}},
+		{name: "fan-out over limit", path: "fan", limits: core.Limits{MaxReferenceFanOut: 2}, wantLimit: core.LimitMaxReferenceFanOut},
+		{name: "recursive value", path: "loop", limits: core.Limits{MaxDepth: 32}, wantLimit: core.LimitMaxDepth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(ResolverOptions{ProviderRegistry: registry, Limits: tt.limits, AllowMissingProvider: true})
+			_, err := r.ResolveValue(context.Background(), ref("cfg", tt.path, 1))
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Fatalf("ResolveValue() error = %v", err)
+				}
+				return
+			}
+			var limitErr *core.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.wantLimit {
+				t.Fatalf("ResolveValue() error = %v, want a %s limit error despite AllowMissingProvider", err, tt.wantLimit)
+			}
+			var refErr *core.ReferenceError
+			if !errors.As(err, &refErr) || refErr.Alias != "cfg" {
+				t.Errorf("ResolveValue() error = %v, want it to name the reference", err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
@@ -98,13 +99,31 @@ func (e *ErrCycleDetected) Error() string {
 }
 
 // Is implements error matching for errors.Is.
+// An unresolved reference also matches core.ErrUnknownAlias.
 func (e *ErrUnresolvedReference) Is(target error) bool {
+	if target == core.ErrUnknownAlias {
+		return true
+	}
 	_, ok := target.(*ErrUnresolvedReference)
 	return ok
 }
 
 // Is implements error matching for errors.Is.
+// A detected cycle also matches core.ErrCircularReference.
 func (e *ErrCycleDetected) Is(target error) bool {
+	if target == core.ErrCircularReference {
+		return true
+	}
 	_, ok := target.(*ErrCycleDetected)
 	return ok
 }
+
+// Hint suggests declaring the missing source.
+func (e *ErrUnresolvedReference) Hint() core.Hint {
+	return core.Hint{Text: fmt.Sprintf("declare a source with alias %q, or correct the reference", e.Alias)}
+}
+
+// Hint suggests how to break the cycle.
+func (e *ErrCycleDetected) Hint() core.Hint {
+	return core.Hint{Text: "remove one of the imports or references forming the cycle"}
+}
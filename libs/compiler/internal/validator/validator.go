@@ -85,6 +85,13 @@ func (v *Validator) validateValue(ctx context.Context, val any, path string) err
 
 	case models.Secret:
 		return v.validateValue(ctx, value.Value, path)
+
+	case models.Call:
+		for _, arg := range value.Args {
+			if err := v.validateValue(ctx, arg, path); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
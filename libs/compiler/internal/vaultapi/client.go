@@ -0,0 +1,241 @@
+// Package vaultapi is a minimal client for the HashiCorp Vault HTTP API used
+// by the built-in vault provider: token and AppRole authentication and KV
+// secrets engine reads.
+package vaultapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoToken is returned when no authentication method is configured.
+var ErrNoToken = errors.New("no Vault token found: set VAULT_TOKEN, or VAULT_ROLE_ID and VAULT_SECRET_ID for AppRole")
+
+// ErrNotFound is returned when a secret does not exist.
+var ErrNotFound = errors.New("secret not found")
+
+// Error is an error response from the Vault API.
+type Error struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *Error) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("vault returned HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", strings.Join(e.Errors, "; "), e.StatusCode)
+}
+
+// Config is what a Client needs to reach and authenticate to Vault.
+type Config struct {
+	// Address is the Vault server URL.
+	Address string
+
+	// Namespace is the Vault Enterprise namespace sent with every request.
+	Namespace string
+
+	// Token authenticates requests directly.
+	Token string
+
+	// RoleID and SecretID authenticate through AppRole when Token is empty.
+	RoleID   string
+	SecretID string
+
+	// AppRoleMount is the mount path of the AppRole auth method.
+	AppRoleMount string
+
+	// CACert is a PEM file of CA certificates to trust.
+	CACert string
+}
+
+// ConfigFromEnv reads the configuration from the environment variables the
+// Vault CLI uses: VAULT_ADDR, VAULT_NAMESPACE, VAULT_TOKEN, and VAULT_CACERT,
+// plus VAULT_ROLE_ID, VAULT_SECRET_ID, and VAULT_APPROLE_MOUNT for AppRole.
+// Without VAULT_TOKEN or AppRole credentials, the token left in ~/.vault-token
+// by "vault login" is used.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Address:      firstNonEmpty(os.Getenv("VAULT_ADDR"), "https://127.0.0.1:8200"),
+		Namespace:    os.Getenv("VAULT_NAMESPACE"),
+		Token:        os.Getenv("VAULT_TOKEN"),
+		RoleID:       os.Getenv("VAULT_ROLE_ID"),
+		SecretID:     os.Getenv("VAULT_SECRET_ID"),
+		AppRoleMount: firstNonEmpty(os.Getenv("VAULT_APPROLE_MOUNT"), "approle"),
+		CACert:       os.Getenv("VAULT_CACERT"),
+	}
+	if cfg.Token == "" && cfg.RoleID == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if token, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil { //nolint:gosec // G304: Standard Vault token helper location
+				cfg.Token = strings.TrimSpace(string(token))
+			}
+		}
+	}
+	return cfg
+}
+
+// Client calls the Vault HTTP API.
+type Client struct {
+	cfg   Config
+	http  *http.Client
+	token string
+}
+
+// NewClient returns a client for cfg. Call Login before reading secrets.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("no Vault address configured: set 'address' or VAULT_ADDR")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 30 * time.Second, Transport: transport}}, nil
+}
+
+// Login obtains the token used for subsequent requests: Config.Token if set,
+// otherwise an AppRole login with Config.RoleID and Config.SecretID.
+func (c *Client) Login(ctx context.Context) error {
+	if c.cfg.Token != "" {
+		c.token = c.cfg.Token
+		return nil
+	}
+	if c.cfg.RoleID == "" {
+		return ErrNoToken
+	}
+
+	input := map[string]string{"role_id": c.cfg.RoleID, "secret_id": c.cfg.SecretID}
+	var output struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	mount := strings.Trim(c.cfg.AppRoleMount, "/")
+	if err := c.do(ctx, http.MethodPost, "auth/"+mount+"/login", nil, input, &output); err != nil {
+		return fmt.Errorf("approle login failed: %w", err)
+	}
+	if output.Auth.ClientToken == "" {
+		return errors.New("approle login returned no token")
+	}
+	c.token = output.Auth.ClientToken
+	return nil
+}
+
+// ReadKV returns the data of the secret at path in the KV secrets engine
+// mounted at mount. kvVersion selects the KV v1 or v2 API; for v2, version
+// pins a specific secret version and 0 reads the latest.
+func (c *Client) ReadKV(ctx context.Context, mount, path string, kvVersion, version int) (map[string]any, error) {
+	mount, path = strings.Trim(mount, "/"), strings.Trim(path, "/")
+
+	if kvVersion == 1 {
+		var output struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := c.do(ctx, http.MethodGet, mount+"/"+path, nil, nil, &output); err != nil {
+			return nil, err
+		}
+		return output.Data, nil
+	}
+
+	var query url.Values
+	if version > 0 {
+		query = url.Values{"version": {fmt.Sprint(version)}}
+	}
+	var output struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, mount+"/data/"+path, query, nil, &output); err != nil {
+		return nil, err
+	}
+	if output.Data.Data == nil {
+		// KV v2 returns null data for deleted or destroyed versions
+		return nil, ErrNotFound
+	}
+	return output.Data.Data, nil
+}
+
+// do sends a request to /v1/path and decodes the response into output.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, input, output any) error {
+	var body io.Reader
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	endpoint := strings.TrimSuffix(c.cfg.Address, "/") + "/v1/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	if c.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.cfg.Namespace)
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if resp.StatusCode == http.StatusNotFound && len(apiErr.Errors) == 0 {
+			return ErrNotFound
+		}
+		return &Error{StatusCode: resp.StatusCode, Errors: apiErr.Errors}
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
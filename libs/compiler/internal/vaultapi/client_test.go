@@ -0,0 +1,142 @@
+package vaultapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeVault serves KV v1 at kv/, KV v2 at secret/, and AppRole logins.
+func fakeVault(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var input map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&input)
+			if input["role_id"] != "role" || input["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+			return
+		}
+
+		if tok := r.Header.Get("X-Vault-Token"); tok != "root" && tok != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if ns := r.Header.Get("X-Vault-Namespace"); ns != "" && ns != "team-a" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1"}}`))
+		case "/v1/secret/data/app":
+			if r.URL.Query().Get("version") == "1" {
+				_, _ = w.Write([]byte(`{"data":{"data":{"password":"old"}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"new"},"metadata":{"version":2}}}`))
+		case "/v1/secret/data/deleted":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"data":{"data":null,"metadata":{"deletion_time":"2024-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestReadKV(t *testing.T) {
+	address := fakeVault(t)
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		mount     string
+		path      string
+		kvVersion int
+		version   int
+		want      map[string]any
+		wantErr   error
+		errText   string
+	}{
+		{name: "kv v1", cfg: Config{Token: "root"}, mount: "kv", path: "app", kvVersion: 1, want: map[string]any{"password": "v1"}},
+		{name: "kv v2 latest", cfg: Config{Token: "root"}, mount: "secret", path: "/app/", kvVersion: 2, want: map[string]any{"password": "new"}},
+		{name: "kv v2 pinned", cfg: Config{Token: "root"}, mount: "secret", path: "app", kvVersion: 2, version: 1, want: map[string]any{"password": "old"}},
+		{name: "namespace", cfg: Config{Token: "root", Namespace: "team-a"}, mount: "secret", path: "app", kvVersion: 2, want: map[string]any{"password": "new"}},
+		{name: "approle", cfg: Config{RoleID: "role", SecretID: "secret", AppRoleMount: "approle"}, mount: "secret", path: "app", kvVersion: 2, want: map[string]any{"password": "new"}},
+		{name: "missing", cfg: Config{Token: "root"}, mount: "secret", path: "nope", kvVersion: 2, wantErr: ErrNotFound},
+		{name: "deleted", cfg: Config{Token: "root"}, mount: "secret", path: "deleted", kvVersion: 2, wantErr: ErrNotFound},
+		{name: "denied", cfg: Config{Token: "wrong"}, mount: "secret", path: "app", kvVersion: 2, errText: "permission denied (HTTP 403)"},
+		{name: "bad approle", cfg: Config{RoleID: "role", SecretID: "wrong", AppRoleMount: "approle"}, errText: "approle login failed: invalid role or secret ID (HTTP 400)"},
+		{name: "no auth", cfg: Config{}, wantErr: ErrNoToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Address = address
+			client, err := NewClient(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = client.Login(context.Background())
+			var got map[string]any
+			if err == nil {
+				got, err = client.ReadKV(context.Background(), tt.mount, tt.path, tt.kvVersion, tt.version)
+			}
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || err.Error() != tt.errText {
+					t.Fatalf("error = %v, want %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !reflect.DeepEqual(got, tt.want):
+				t.Errorf("ReadKV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VAULT_ADDR", "https://vault.example.com")
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("VAULT_ROLE_ID", "")
+	t.Setenv("VAULT_APPROLE_MOUNT", "")
+
+	if cfg := ConfigFromEnv(); cfg.Token != "" || cfg.AppRoleMount != "approle" || cfg.Address != "https://vault.example.com" {
+		t.Errorf("ConfigFromEnv() = %+v", cfg)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, ".vault-token"), []byte("cli-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := ConfigFromEnv(); cfg.Token != "cli-token" {
+		t.Errorf("Token = %q, want the token from ~/.vault-token", cfg.Token)
+	}
+
+	t.Setenv("VAULT_TOKEN", "env-token")
+	if cfg := ConfigFromEnv(); cfg.Token != "env-token" {
+		t.Errorf("Token = %q, want VAULT_TOKEN", cfg.Token)
+	}
+}
@@ -0,0 +1,173 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/kubeapi"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// KubernetesProviderType is the built-in provider type that reads objects
+// from a Kubernetes cluster:
+//
+//	source:
+//	  alias: 'cluster'
+//	  type: 'kubernetes'
+//	  kind: 'ConfigMap'          # optional; ConfigMap (default), Secret, or any kind
+//	  api_version: 'v1'          # optional; e.g. 'apps/v1' for other kinds
+//	  name: 'app-config'         # optional; without it, every matching object by name
+//	  label_selector: 'team=web' # optional; filters objects when name is unset
+//	  namespace: 'apps'          # optional; default the context's namespace
+//	  kubeconfig: '~/.kube/prod' # optional; default KUBECONFIG, in-cluster, ~/.kube/config
+//	  context: 'prod'            # optional; default the current context
+//
+// ConfigMaps expose their data and binaryData keys, Secrets their decoded
+// data keys as secrets, and other kinds the whole object. With a name,
+// references address keys directly (@cluster:LOG_LEVEL); without one, they
+// start with the object name (@cluster:app-config.LOG_LEVEL).
+const KubernetesProviderType = "kubernetes"
+
+// kubernetesProvider implements core.Provider over Kubernetes objects.
+type kubernetesProvider struct {
+	kind          string
+	apiVersion    string
+	name          string
+	labelSelector string
+	namespace     string
+	kube          kubeapi.LoadOptions
+
+	data map[string]any
+}
+
+func newKubernetesProvider(config map[string]any) (core.Provider, error) {
+	p := &kubernetesProvider{kind: "ConfigMap", apiVersion: "v1"}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("kubernetes config %q must be a string", key)
+		}
+		switch key {
+		case "kind":
+			p.kind = s
+		case "api_version":
+			p.apiVersion = s
+		case "name":
+			p.name = s
+		case "label_selector":
+			p.labelSelector = s
+		case "namespace":
+			p.namespace = s
+		case "kubeconfig":
+			p.kube.Kubeconfig = s
+		case "context":
+			p.kube.Context = s
+		default:
+			return nil, fmt.Errorf("unknown kubernetes config %q", key)
+		}
+	}
+	if p.name != "" && p.labelSelector != "" {
+		return nil, errors.New("kubernetes config 'name' and 'label_selector' are mutually exclusive")
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. It reads the selected objects once;
+// references are then served from memory.
+func (p *kubernetesProvider) Init(ctx context.Context, opts core.ProviderInitOptions) error {
+	kube := p.kube
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(kube.Kubeconfig, "~/") {
+		kube.Kubeconfig = filepath.Join(home, kube.Kubeconfig[2:])
+	}
+	if kube.Kubeconfig != "" && !filepath.IsAbs(kube.Kubeconfig) && opts.SourceFilePath != "" {
+		kube.Kubeconfig = filepath.Join(filepath.Dir(opts.SourceFilePath), kube.Kubeconfig)
+	}
+
+	cfg, err := kubeapi.LoadConfig(kube)
+	if err != nil {
+		return fmt.Errorf("kubernetes: %w", err)
+	}
+	client, err := kubeapi.NewClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("kubernetes: %w", err)
+	}
+	namespace := p.namespace
+	if namespace == "" {
+		namespace = client.Namespace()
+	}
+
+	if p.name != "" {
+		object, err := client.Get(ctx, p.apiVersion, p.kind, namespace, p.name)
+		if err != nil {
+			return fmt.Errorf("kubernetes: reading %s %s/%s: %w", p.kind, namespace, p.name, err)
+		}
+		p.data, err = p.expose(object)
+		if err != nil {
+			return fmt.Errorf("kubernetes: %s %s/%s: %w", p.kind, namespace, p.name, err)
+		}
+		return nil
+	}
+
+	objects, err := client.List(ctx, p.apiVersion, p.kind, namespace, p.labelSelector)
+	if err != nil {
+		return fmt.Errorf("kubernetes: listing %s in %s: %w", p.kind, namespace, err)
+	}
+	p.data = make(map[string]any, len(objects))
+	for _, object := range objects {
+		metadata, _ := object["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		exposed, err := p.expose(object)
+		if err != nil {
+			return fmt.Errorf("kubernetes: %s %s/%s: %w", p.kind, namespace, name, err)
+		}
+		p.data[name] = exposed
+	}
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *kubernetesProvider) Fetch(_ context.Context, path []string) (any, error) {
+	kind := "key"
+	if p.name == "" {
+		kind = p.kind
+	}
+	return lookupPath(p.data, path, kind)
+}
+
+// expose returns the referenceable content of object.
+func (p *kubernetesProvider) expose(object map[string]any) (map[string]any, error) {
+	if p.apiVersion != "v1" || (p.kind != "ConfigMap" && p.kind != "Secret") {
+		if metadata, ok := object["metadata"].(map[string]any); ok {
+			delete(metadata, "managedFields")
+		}
+		return object, nil
+	}
+
+	data := make(map[string]any)
+	if p.kind == "ConfigMap" {
+		for _, field := range []string{"data", "binaryData"} {
+			values, _ := object[field].(map[string]any)
+			for k, v := range values {
+				data[k] = v
+			}
+		}
+		return data, nil
+	}
+
+	values, _ := object["data"].(map[string]any)
+	for k, v := range values {
+		encoded, _ := v.(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("secret key %q is not base64: %w", k, err)
+		}
+		data[k] = models.Secret{Value: string(decoded)}
+	}
+	return data, nil
+}
@@ -0,0 +1,176 @@
+package compiler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// fakeKubernetesAPI serves discovery, ConfigMaps, Secrets and Deployments in
+// namespace "apps", and returns the server URL.
+func fakeKubernetesAPI(t *testing.T) string {
+	t.Helper()
+	respond := func(w http.ResponseWriter, v any) { _ = json.NewEncoder(w).Encode(v) }
+	configMap := func(name, level string) map[string]any {
+		return map[string]any{"kind": "ConfigMap", "metadata": map[string]any{"name": name}, "data": map[string]any{"LOG_LEVEL": level}}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			respond(w, map[string]any{"kind": "Status", "reason": "Unauthorized", "message": "Unauthorized"})
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1":
+			respond(w, map[string]any{"resources": []any{
+				map[string]any{"name": "configmaps", "kind": "ConfigMap", "namespaced": true},
+				map[string]any{"name": "secrets", "kind": "Secret", "namespaced": true},
+			}})
+		case "/apis/apps/v1":
+			respond(w, map[string]any{"resources": []any{
+				map[string]any{"name": "deployments", "kind": "Deployment", "namespaced": true},
+			}})
+		case "/api/v1/namespaces/apps/configmaps/app-config":
+			respond(w, map[string]any{
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "app-config"},
+				"data":       map[string]any{"LOG_LEVEL": "debug"},
+				"binaryData": map[string]any{"logo": "iVBORw0K"},
+			})
+		case "/api/v1/namespaces/apps/configmaps":
+			if r.URL.Query().Get("labelSelector") != "team=web" {
+				respond(w, map[string]any{"items": []any{}})
+				return
+			}
+			respond(w, map[string]any{"items": []any{configMap("web", "info"), configMap("api", "warn")}})
+		case "/api/v1/namespaces/apps/secrets/db":
+			respond(w, map[string]any{"kind": "Secret", "metadata": map[string]any{"name": "db"}, "data": map[string]any{"password": "aHVudGVyMg=="}})
+		case "/apis/apps/v1/namespaces/apps/deployments/web":
+			respond(w, map[string]any{
+				"kind":     "Deployment",
+				"metadata": map[string]any{"name": "web", "managedFields": []any{map[string]any{"manager": "kubectl"}}},
+				"spec":     map[string]any{"replicas": 3},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			respond(w, map[string]any{"kind": "Status", "reason": "NotFound", "message": "not found"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// TestCompile_KubernetesProvider verifies that the built-in kubernetes
+// provider reads ConfigMaps, decodes Secrets as sensitive values, exposes
+// other kinds whole, and lists objects by label.
+func TestCompile_KubernetesProvider(t *testing.T) {
+	server := fakeKubernetesAPI(t)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", "")
+
+	tests := []struct {
+		name      string
+		source    string
+		body      string
+		want      map[string]any
+		sensitive []string
+		wantErr   string
+	}{
+		{
+			name:      "config map",
+			source:    "\n  name: 'app-config'",
+			body:      "level: @cluster:LOG_LEVEL\nlogo: @cluster:logo\n",
+			want:      map[string]any{"level": "debug", "logo": "iVBORw0K"},
+			sensitive: []string{},
+		},
+		{
+			name:      "secret",
+			source:    "\n  kind: 'Secret'\n  name: 'db'",
+			body:      "password: @cluster:password\n",
+			want:      map[string]any{"password": map[string]any{"value": "hunter2"}},
+			sensitive: []string{"out.password"},
+		},
+		{
+			name:      "other kind",
+			source:    "\n  kind: 'Deployment'\n  api_version: 'apps/v1'\n  name: 'web'",
+			body:      "replicas: @cluster:spec.replicas\nmetadata: @cluster:metadata\n",
+			want:      map[string]any{"replicas": float64(3), "metadata": map[string]any{"name": "web"}},
+			sensitive: []string{},
+		},
+		{
+			name:      "list by label",
+			source:    "\n  label_selector: 'team=web'",
+			body:      "web: @cluster:web.LOG_LEVEL\napi: @cluster:api.LOG_LEVEL\n",
+			want:      map[string]any{"web": "info", "api": "warn"},
+			sensitive: []string{},
+		},
+		{
+			name:    "missing object",
+			source:  "\n  name: 'missing'",
+			body:    "level: @cluster:LOG_LEVEL\n",
+			wantErr: "reading ConfigMap apps/missing: NotFound",
+		},
+		{
+			name:    "unknown kind",
+			source:  "\n  kind: 'Widget'\n  name: 'web'",
+			body:    "level: @cluster:LOG_LEVEL\n",
+			wantErr: "kind Widget not found in API version v1",
+		},
+		{
+			name:    "name and label selector",
+			source:  "\n  name: 'app-config'\n  label_selector: 'team=web'",
+			body:    "level: @cluster:LOG_LEVEL\n",
+			wantErr: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			kubeconfig := "apiVersion: v1\nkind: Config\ncurrent-context: test\n" +
+				"clusters:\n- name: test\n  cluster:\n    server: " + server + "\n" +
+				"users:\n- name: test\n  user:\n    token: token\n" +
+				"contexts:\n- name: test\n  context:\n    cluster: test\n    user: test\n    namespace: apps\n"
+			if err := writeFile(filepath.Join(dir, "kubeconfig"), kubeconfig); err != nil {
+				t.Fatal(err)
+			}
+
+			// The relative kubeconfig path resolves against the .csl file
+			path := filepath.Join(dir, "app.csl")
+			src := "source:\n  alias: 'cluster'\n  type: 'kubernetes'\n  kubeconfig: 'kubeconfig'" + tt.source + "\n\nout:\n" + indent(tt.body)
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			if out := jsonRoundTrip(t, result.Snapshot.Data["out"]); !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("out = %#v, want %#v", out, tt.want)
+			}
+			if got := result.Snapshot.Metadata.SensitiveKeys; !reflect.DeepEqual(got, tt.sensitive) {
+				t.Errorf("SensitiveKeys = %v, want %v", got, tt.sensitive)
+			}
+		})
+	}
+}
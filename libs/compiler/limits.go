@@ -0,0 +1,44 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+)
+
+// Limits bounds the inputs a compilation accepts and the data it builds,
+// protecting the compiler and serializers from runaway inputs such as a
+// provider returning a huge or self-referencing structure. Exceeding a limit
+// fails the compilation with *LimitError, even when AllowMissingProvider or
+// PartialFailureBestEffort is set. Zero fields impose no limit.
+type Limits = core.Limits
+
+// Names of the limits, as reported in LimitError.Limit.
+const (
+	LimitMaxFileBytes       = core.LimitMaxFileBytes
+	LimitMaxDepth           = core.LimitMaxDepth
+	LimitMaxReferenceFanOut = core.LimitMaxReferenceFanOut
+	LimitMaxKeys            = core.LimitMaxKeys
+)
+
+// DefaultMaxProviderMessageBytes is the largest gRPC provider response
+// accepted when Options.MaxProviderMessageBytes is zero.
+const DefaultMaxProviderMessageBytes = core.DefaultMaxProviderMessageBytes
+
+// checkFileSizes returns a *LimitError for each of files larger than limit
+// bytes.
+func checkFileSizes(files []string, limit int64) []error {
+	var errs []error
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stat input file %q: %w", path, err))
+			continue
+		}
+		if info.Size() > limit {
+			errs = append(errs, &LimitError{Limit: LimitMaxFileBytes, Max: limit, Subject: path, Size: info.Size()})
+		}
+	}
+	return errs
+}
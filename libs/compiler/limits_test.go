@@ -0,0 +1,142 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+func TestCompile_Limits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	src := "app:\n  name: 'web'\n  db:\n    host: 'localhost'\n    port: '5432'\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		limits    compiler.Limits
+		wantLimit string
+	}{
+		{name: "unlimited"},
+		{name: "within limits", limits: compiler.Limits{MaxFileBytes: 1024, MaxDepth: 3, MaxKeys: 5}},
+		{name: "file too large", limits: compiler.Limits{MaxFileBytes: 16}, wantLimit: compiler.LimitMaxFileBytes},
+		{name: "too deep", limits: compiler.Limits{MaxDepth: 2}, wantLimit: compiler.LimitMaxDepth},
+		{name: "too many keys", limits: compiler.Limits{MaxKeys: 4}, wantLimit: compiler.LimitMaxKeys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: testutil.NewFakeProviderRegistry(),
+				Limits:           tt.limits,
+			})
+
+			if tt.wantLimit == "" {
+				if result.HasErrors() {
+					t.Fatalf("unexpected error: %v", result.Error())
+				}
+				return
+			}
+			var limitErr *compiler.LimitError
+			if !errors.As(result.Error(), &limitErr) || limitErr.Limit != tt.wantLimit {
+				t.Fatalf("error = %v, want a %s LimitError", result.Error(), tt.wantLimit)
+			}
+			if !errors.Is(result.Error(), compiler.ErrLimitExceeded) {
+				t.Errorf("error = %v, want ErrLimitExceeded", result.Error())
+			}
+		})
+	}
+}
+
+func TestCompile_LimitsProviderValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "value: @huge:key\n"); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	registry := compiler.NewProviderRegistry()
+	registry.Register("huge", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+		return &listProvider{hosts: []any{"a", "b"}}, nil
+	})
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:               path,
+		ProviderRegistry:   registry,
+		PartialFailureMode: compiler.PartialFailureBestEffort,
+		Limits:             compiler.Limits{MaxKeys: 1},
+	})
+
+	var refErr *compiler.ReferenceError
+	if !errors.As(result.Error(), &refErr) || refErr.Alias != "huge" || !errors.Is(result.Error(), compiler.ErrLimitExceeded) {
+		t.Fatalf("error = %v, want a limit error naming @huge:key", result.Error())
+	}
+	if want := "provider value holds more keys than Limits.MaxKeys allows (1)"; !strings.Contains(result.Error().Error(), want) {
+		t.Errorf("error = %v, want it to contain %q", result.Error(), want)
+	}
+}
+
+func TestCompile_LimitsNegative(t *testing.T) {
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             "app.csl",
+		ProviderRegistry: testutil.NewFakeProviderRegistry(),
+		Limits:           compiler.Limits{MaxDepth: -1},
+	})
+	if !result.HasErrors() || !strings.Contains(result.Error().Error(), "max_depth must not be negative") {
+		t.Errorf("error = %v, want a negative max_depth error", result.Error())
+	}
+}
+
+// oversizedProvider fails every fetch as a gRPC provider does when its
+// response exceeds the message size limit.
+type oversizedProvider struct{}
+
+func (oversizedProvider) Init(context.Context, compiler.ProviderInitOptions) error { return nil }
+
+func (oversizedProvider) Fetch(_ context.Context, path []string) (any, error) {
+	return nil, &compiler.MessageSizeError{Alias: "big", Path: path, Size: 5 << 20, Max: compiler.DefaultMaxProviderMessageBytes}
+}
+
+func TestCompile_ProviderMessageTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "value: @big:data.blob\n"); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	registry := compiler.NewProviderRegistry()
+	registry.Register("big", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+		return oversizedProvider{}, nil
+	})
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: registry,
+	})
+	err := result.Error()
+	var refErr *compiler.ReferenceError
+	if !errors.As(err, &refErr) || refErr.Kind != compiler.ErrProviderMessageTooLarge {
+		t.Fatalf("error = %v, want a *ReferenceError of kind ErrProviderMessageTooLarge", err)
+	}
+	var sizeErr *compiler.MessageSizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Alias != "big" || strings.Join(sizeErr.Path, ".") != "data.blob" {
+		t.Errorf("error = %v, want a *MessageSizeError naming @big:data.blob", err)
+	}
+	if !strings.Contains(err.Error(), "returned 5242880 bytes, more than the 4194304 bytes allowed") {
+		t.Errorf("error = %v, want the payload size and limit", err)
+	}
+}
+
+func TestCompile_MaxProviderMessageBytesNegative(t *testing.T) {
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                    "app.csl",
+		ProviderRegistry:        testutil.NewFakeProviderRegistry(),
+		MaxProviderMessageBytes: -1,
+	})
+	if !result.HasErrors() || !strings.Contains(result.Error().Error(), "MaxProviderMessageBytes must not be negative") {
+		t.Errorf("error = %v, want a negative MaxProviderMessageBytes error", result.Error())
+	}
+}
@@ -0,0 +1,156 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/resolver"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// ListOrder records the order of the lists in the value a provider
+// returned for one reference. The compiler keeps lists in the order
+// providers return them; comparing the records of two builds shows
+// whether a provider returned the same elements in another order, as
+// providers building lists from map iteration do.
+type ListOrder struct {
+	// Reference is the reference as written, "@alias:path".
+	Reference string `json:"reference"`
+
+	// Provider is the registry key of the provider serving the reference.
+	Provider string `json:"provider"`
+
+	// OrderHash digests every list in the value with its elements in the
+	// order the provider returned them.
+	OrderHash string `json:"order_hash"`
+
+	// ContentHash digests the same lists with their elements sorted, so it
+	// is the same for any order of the same elements.
+	ContentHash string `json:"content_hash"`
+}
+
+// listOrderRecorder collects the ListOrder of each reference whose value
+// holds a list, and where the reference was first seen.
+type listOrderRecorder struct {
+	orders []ListOrder
+	spans  map[string]ast.SourceSpan
+}
+
+// record observes a reference lookup. Cached lookups repeat a value already
+// recorded.
+func (r *listOrderRecorder) record(e resolver.ReferenceEvent) {
+	if e.Err != nil || e.Cached {
+		return
+	}
+	val := e.Value.Any()
+	if !hasList(val) {
+		return
+	}
+	reference := "@" + e.Ref.Alias + ":" + strings.Join(e.Ref.Path, ".")
+	if _, seen := r.spans[reference]; seen {
+		return
+	}
+	if r.spans == nil {
+		r.spans = make(map[string]ast.SourceSpan)
+	}
+	r.spans[reference] = e.Ref.SourceSpan
+	r.orders = append(r.orders, ListOrder{
+		Reference:   reference,
+		Provider:    e.Provider,
+		OrderHash:   digest(hashable(val, false)),
+		ContentHash: digest(hashable(val, true)),
+	})
+}
+
+// sorted returns the records ordered by reference.
+func (r *listOrderRecorder) sorted() []ListOrder {
+	orders := slices.Clone(r.orders)
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Reference < orders[j].Reference })
+	return orders
+}
+
+// compare reports each reference whose lists hold the elements they held in
+// previous, in a different order.
+func (r *listOrderRecorder) compare(previous []ListOrder, report func(diagnostic.Diagnostic)) {
+	before := make(map[string]ListOrder, len(previous))
+	for _, o := range previous {
+		before[o.Reference] = o
+	}
+	for _, o := range r.sorted() {
+		prev, ok := before[o.Reference]
+		if !ok || prev.ContentHash != o.ContentHash || prev.OrderHash == o.OrderHash {
+			continue
+		}
+		report(diagnostic.Diagnostic{
+			Severity: diagnostic.SeverityWarning,
+			Code:     diagnostic.CodeNondeterministicOrder,
+			Message: fmt.Sprintf("provider %q returned the lists of %s in a different order than the previous build; the provider's list order is not deterministic",
+				o.Provider, o.Reference),
+			SourceSpan: r.spans[o.Reference],
+		})
+	}
+}
+
+// hasList reports whether val holds a list at any depth outside secrets.
+func hasList(val any) bool {
+	switch v := val.(type) {
+	case []any:
+		return true
+	case map[string]any:
+		for _, elem := range v {
+			if hasList(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hashable returns a copy of val to digest, with secrets replaced by a
+// placeholder so no digest depends on them. With sorted, the elements of
+// every list are sorted by their JSON encoding, innermost lists first.
+func hashable(val any, sorted bool) any {
+	switch v := val.(type) {
+	case []any:
+		elems := make([]any, len(v))
+		for i, elem := range v {
+			elems[i] = hashable(elem, sorted)
+		}
+		if sorted {
+			sort.SliceStable(elems, func(i, j int) bool { return canonicalJSON(elems[i]) < canonicalJSON(elems[j]) })
+		}
+		return elems
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, elem := range v {
+			m[k] = hashable(elem, sorted)
+		}
+		return m
+	case models.Secret:
+		return "<secret>"
+	}
+	return val
+}
+
+// digest returns the SHA-256 digest of the JSON encoding of val.
+func digest(val any) string {
+	sum := sha256.Sum256([]byte(canonicalJSON(val)))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON encodes val as JSON with sorted map keys, falling back to
+// its Go syntax for values JSON cannot encode.
+func canonicalJSON(val any) string {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%#v", val)
+	}
+	return string(b)
+}
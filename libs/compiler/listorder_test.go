@@ -0,0 +1,109 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// listProvider returns hosts for every path.
+type listProvider struct {
+	hosts []any
+}
+
+func (p *listProvider) Init(_ context.Context, _ compiler.ProviderInitOptions) error {
+	return nil
+}
+
+func (p *listProvider) Fetch(_ context.Context, _ []string) (any, error) {
+	return map[string]any{"hosts": p.hosts, "port": 80}, nil
+}
+
+func TestCompile_ListOrders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "svc: @cfg:service\nname: 'demo'\n"); err != nil {
+		t.Fatal(err)
+	}
+	compile := func(hosts []any, previous []compiler.ListOrder) compiler.CompilationResult {
+		t.Helper()
+		registry := compiler.NewProviderRegistry()
+		registry.Register("cfg", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+			return &listProvider{hosts: hosts}, nil
+		})
+		result := compiler.Compile(context.Background(), compiler.Options{
+			Path:               path,
+			ProviderRegistry:   registry,
+			PreviousListOrders: previous,
+		})
+		if result.HasErrors() {
+			t.Fatalf("Compile() errors = %v", result.Snapshot.Metadata.Errors)
+		}
+		return result
+	}
+
+	first := compile([]any{"a", "b", "c"}, nil)
+	orders := first.Snapshot.Metadata.ListOrders
+	if len(orders) != 1 || orders[0].Reference != "@cfg:service" || orders[0].Provider != "cfg" {
+		t.Fatalf("ListOrders = %+v, want one record of @cfg:service", orders)
+	}
+	if len(first.Snapshot.Metadata.Warnings) != 0 {
+		t.Errorf("first build warnings = %v", first.Snapshot.Metadata.Warnings)
+	}
+
+	tests := []struct {
+		name        string
+		hosts       []any
+		sameContent bool
+		wantWarn    bool
+	}{
+		{name: "same order", hosts: []any{"a", "b", "c"}, sameContent: true},
+		{name: "reordered", hosts: []any{"c", "a", "b"}, sameContent: true, wantWarn: true},
+		{name: "changed elements", hosts: []any{"a", "b", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compile(tt.hosts, orders)
+			got := result.Snapshot.Metadata.ListOrders[0]
+			if (got.ContentHash == orders[0].ContentHash) != tt.sameContent {
+				t.Errorf("ContentHash = %s, previous %s", got.ContentHash, orders[0].ContentHash)
+			}
+			if result.Snapshot.Data["svc"].(map[string]any)["hosts"].([]any)[0] != tt.hosts[0] {
+				t.Errorf("hosts = %v, want the provider order %v", result.Snapshot.Data["svc"], tt.hosts)
+			}
+
+			details := result.Snapshot.Metadata.WarningDetails
+			if !tt.wantWarn {
+				if len(details) != 0 {
+					t.Errorf("warnings = %v, want none", result.Snapshot.Metadata.Warnings)
+				}
+				return
+			}
+			if len(details) != 1 || details[0].Code != compiler.WarnNondeterministicOrder || details[0].Line != 1 ||
+				!strings.Contains(details[0].Message, `provider "cfg" returned the lists of @cfg:service in a different order`) {
+				t.Errorf("warnings = %+v, want one W004 at line 1", details)
+			}
+		})
+	}
+}
+
+func TestCompilationResult_ReferencesResolved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "svc: @cfg:service\ncopy: @cfg:service\nname: 'demo'\n"); err != nil {
+		t.Fatal(err)
+	}
+	registry := compiler.NewProviderRegistry()
+	registry.Register("cfg", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+		return &listProvider{hosts: []any{"a"}}, nil
+	})
+
+	result := compiler.Compile(context.Background(), compiler.Options{Path: path, ProviderRegistry: registry})
+	if result.HasErrors() {
+		t.Fatalf("Compile() errors = %v", result.Snapshot.Metadata.Errors)
+	}
+	if got := result.ReferencesResolved(); got != 2 {
+		t.Errorf("ReferencesResolved() = %d, want 2", got)
+	}
+}
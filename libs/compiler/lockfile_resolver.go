@@ -7,6 +7,9 @@ import (
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/config"
 )
 
+// lockfileHint is the hint of a provider binary the lockfile cannot supply.
+var lockfileHint = Hint{Text: "run 'nomos build' to install providers and record their checksums"}
+
 // LockfileProviderResolver wraps the internal config.LockfileProviderResolver
 // and provides a public API for resolving provider binaries from lockfiles.
 type LockfileProviderResolver struct {
@@ -37,7 +40,18 @@ func NewLockfileProviderResolver(lockfilePath, manifestPath string, baseDirFunc
 // ResolveBinaryPath resolves a provider type to its binary path using the lockfile.
 // It returns the absolute path to the provider executable.
 //
-// This implements the ProviderResolver interface.
+// This implements the ProviderResolver interface. Errors carry a Hint.
 func (r *LockfileProviderResolver) ResolveBinaryPath(ctx context.Context, providerType string) (string, error) {
-	return r.resolver.ResolveBinaryPath(ctx, providerType)
+	path, err := r.resolver.ResolveBinaryPath(ctx, providerType)
+	return path, WithHint(err, lockfileHint)
+}
+
+// ResolveVersionedBinaryPath resolves a provider type at a declared version
+// to its binary path using the lockfile.
+//
+// This implements the VersionedProviderResolver interface. Errors carry a
+// Hint.
+func (r *LockfileProviderResolver) ResolveVersionedBinaryPath(ctx context.Context, providerType, version string) (string, error) {
+	path, err := r.resolver.ResolveVersionedBinaryPath(ctx, providerType, version)
+	return path, WithHint(err, lockfileHint)
 }
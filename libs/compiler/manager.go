@@ -6,6 +6,7 @@ import (
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/providers"
+	"google.golang.org/grpc"
 )
 
 // ManagerOptions configures the Manager behavior.
@@ -14,11 +15,21 @@ type ManagerOptions struct {
 	// After this timeout, providers are forcefully terminated.
 	// Default: 5 seconds.
 	ShutdownTimeout time.Duration
+
+	// UnaryInterceptors wrap every unary RPC to a provider, including the
+	// Health check made at startup, in order: the first is outermost. Use
+	// them to add authentication, logging, metrics, or fault injection
+	// around provider calls.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+
+	// StreamInterceptors wrap every streaming RPC to a provider, in order.
+	StreamInterceptors []grpc.StreamClientInterceptor
 }
 
 // Manager manages the lifecycle of external provider subprocesses.
-// It starts providers on-demand, caches them per alias, and handles
-// graceful shutdown with configurable timeouts.
+// It starts providers on-demand, caches them per alias, binary, and config,
+// and handles graceful shutdown with configurable timeouts. It is safe for
+// concurrent use.
 //
 // This is a public wrapper around the internal implementation.
 type Manager struct {
@@ -35,7 +46,9 @@ func NewManager() *Manager {
 // NewManagerWithOptions creates a new Manager instance with the given options.
 func NewManagerWithOptions(opts ManagerOptions) *Manager {
 	providerOpts := &providers.ManagerOptions{
-		ShutdownTimeout: opts.ShutdownTimeout,
+		ShutdownTimeout:    opts.ShutdownTimeout,
+		UnaryInterceptors:  opts.UnaryInterceptors,
+		StreamInterceptors: opts.StreamInterceptors,
 	}
 	return &Manager{
 		impl: providers.NewManager(providerOpts),
@@ -44,12 +57,14 @@ func NewManagerWithOptions(opts ManagerOptions) *Manager {
 
 // GetProvider returns a Provider instance for the given alias.
 // If the provider subprocess is not already running, it starts it
-// and establishes a gRPC connection.
+// and establishes a gRPC connection. Calls for one alias with a different
+// binary or config get their own subprocess.
 //
 // On error, any partially initialized resources (subprocess, connection) are cleaned up.
 //
 // Parameters:
-//   - ctx: Context for cancellation and timeout
+//   - ctx: Context for cancellation and timeout of the startup; the
+//     subprocess keeps running until Shutdown
 //   - alias: Provider alias (e.g., "configs")
 //   - binaryPath: Absolute path to the provider executable
 //   - opts: Provider initialization options
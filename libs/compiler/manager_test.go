@@ -0,0 +1,110 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"google.golang.org/grpc"
+)
+
+// TestManager_UnaryInterceptors verifies that interceptors wrap every RPC to
+// a provider in order, and can fail calls before they reach it.
+func TestManager_UnaryInterceptors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			mu.Lock()
+			calls = append(calls, name+" "+method)
+			mu.Unlock()
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	errDenied := errors.New("denied")
+	deny := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if method == "/nomos.provider.v1.ProviderService/Fetch" {
+			return errDenied
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	manager := compiler.NewManagerWithOptions(compiler.ManagerOptions{
+		UnaryInterceptors: []grpc.UnaryClientInterceptor{record("outer"), record("inner"), deny},
+	})
+	provider, err := manager.GetProvider(ctx, "configs", probeHelper(t, "ok"), compiler.ProviderInitOptions{})
+	if err != nil {
+		t.Fatalf("GetProvider() error = %v", err)
+	}
+	if err := provider.Init(ctx, compiler.ProviderInitOptions{Alias: "configs"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if _, err := provider.Fetch(ctx, []string{"app"}); !errors.Is(err, errDenied) {
+		t.Errorf("Fetch() error = %v, want the interceptor's error", err)
+	}
+	if err := manager.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"outer /nomos.provider.v1.ProviderService/Health",
+		"inner /nomos.provider.v1.ProviderService/Health",
+		"outer /nomos.provider.v1.ProviderService/Init",
+		"inner /nomos.provider.v1.ProviderService/Init",
+		"outer /nomos.provider.v1.ProviderService/Fetch",
+		"inner /nomos.provider.v1.ProviderService/Fetch",
+		"outer /nomos.provider.v1.ProviderService/Shutdown",
+		"inner /nomos.provider.v1.ProviderService/Shutdown",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("intercepted calls =\n%v\nwant\n%v", calls, want)
+	}
+}
+
+// TestManager_ProcessPerConfig verifies that one alias with different
+// configs gets one process per config, and that processes outlive the
+// context they were started with.
+func TestManager_ProcessPerConfig(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	manager := compiler.NewManager()
+	defer func() { _ = manager.Shutdown(context.Background()) }()
+	binary := probeHelper(t, "ok")
+
+	start := func(directory string) compiler.Provider {
+		t.Helper()
+		startCtx, cancelStart := context.WithCancel(ctx)
+		defer cancelStart()
+		opts := compiler.ProviderInitOptions{Alias: "configs", Config: map[string]any{"directory": directory}}
+		provider, err := manager.GetProvider(startCtx, "configs", binary, opts)
+		if err != nil {
+			t.Fatalf("GetProvider() error = %v", err)
+		}
+		return provider
+	}
+
+	tenantA, again, tenantB := start("./a"), start("./a"), start("./b")
+	if tenantA != again {
+		t.Error("GetProvider() started a second process for the same config")
+	}
+	if tenantA == tenantB {
+		t.Error("GetProvider() shared a process between different configs")
+	}
+
+	// The start contexts are cancelled; the processes still answer
+	for _, provider := range []compiler.Provider{tenantA, tenantB} {
+		if err := provider.Init(ctx, compiler.ProviderInitOptions{Alias: "configs"}); err != nil {
+			t.Errorf("Init() after the start context ended: %v", err)
+		}
+	}
+}
@@ -120,3 +120,29 @@ func deepCopyValue(val any) any {
 		return v
 	}
 }
+
+// mergeInto merges src into dst in place with the same semantics as
+// DeepMergeWithProvenance, recording srcSource for each top-level key of src.
+//
+// Compile owns both maps (dst accumulates converted files; src is freshly
+// converted), so nothing is copied. Merging each file into the accumulated
+// data therefore costs O(len(src)) rather than O(len(dst)+len(src)).
+func mergeInto(dst, src map[string]any, srcSource string, provenance map[string]Provenance) {
+	for k, srcVal := range src {
+		provenance[k] = Provenance{Source: srcSource}
+		dst[k] = mergeOwned(dst[k], srcVal)
+	}
+}
+
+// mergeOwned merges src over dst, reusing dst when both are maps.
+func mergeOwned(dst, src any) any {
+	dstMap, dstIsMap := dst.(map[string]any)
+	srcMap, srcIsMap := src.(map[string]any)
+	if !dstIsMap || !srcIsMap {
+		return src
+	}
+	for k, v := range srcMap {
+		dstMap[k] = mergeOwned(dstMap[k], v)
+	}
+	return dstMap
+}
@@ -0,0 +1,103 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// OverrideSource is the provenance source recorded for keys set through
+// Options.Overrides, such as values passed with "nomos build --set".
+const OverrideSource = "cli-override"
+
+// applyOverrides deep-merges overrides over data with the usual composition
+// semantics and attributes each overridden top-level key to OverrideSource.
+// overrides is copied, so the caller's map is never aliased into the output.
+func applyOverrides(data, overrides map[string]any, provenance map[string]Provenance) map[string]any {
+	if len(overrides) == 0 {
+		return data
+	}
+	if data == nil {
+		data = make(map[string]any, len(overrides))
+	}
+	copied, _ := deepCopyValue(overrides).(map[string]any)
+	mergeInto(data, copied, OverrideSource, provenance)
+	return data
+}
+
+// markOverrides attributes the source map entries of overridden key paths
+// to OverrideSource. Maps in overrides merge, so a map key keeps its
+// original entry when the source files defined it; any other value replaces
+// the key and everything beneath it.
+func markOverrides(sm *SourceMap, prefix string, overrides map[string]any) {
+	if sm == nil {
+		return
+	}
+	for k, v := range overrides {
+		key := joinKeyPath(prefix, k)
+		if m, ok := v.(map[string]any); ok {
+			if e := sm.Entries[key]; e.Location.File == "" {
+				sm.Entries[key] = overrideEntry()
+			}
+			markOverrides(sm, key, m)
+			continue
+		}
+		for existing := range sm.Entries {
+			if isDescendant(existing, key) {
+				delete(sm.Entries, existing)
+			}
+		}
+		markValue(sm, key, v, overrideEntry())
+	}
+}
+
+// markValue attributes key and every path nested in value to entry.
+func markValue(sm *SourceMap, key string, value any, entry SourceMapEntry) {
+	sm.Entries[key] = entry
+	switch v := value.(type) {
+	case map[string]any:
+		for k, child := range v {
+			markValue(sm, joinKeyPath(key, k), child, entry)
+		}
+	case []any:
+		for i, child := range v {
+			markValue(sm, fmt.Sprintf("%s[%d]", key, i), child, entry)
+		}
+	}
+}
+
+func overrideEntry() SourceMapEntry {
+	return SourceMapEntry{Location: SourceLocation{File: OverrideSource}}
+}
+
+// checkProviderConfigOverrides reports an error naming the aliases of
+// Options.ProviderConfigOverrides that no source declaration in aliases
+// uses, since such an override would silently have no effect.
+func checkProviderConfigOverrides(overrides map[string]map[string]any, aliases []string) error {
+	var unknown []string
+	for alias := range overrides {
+		if !slices.Contains(aliases, alias) {
+			unknown = append(unknown, alias)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("provider config override for undeclared source alias %s", strings.Join(unknown, ", "))
+}
+
+// copyProviderConfigOverrides deep-copies overrides for the metadata, so
+// later changes to the caller's maps do not alter the record. It returns
+// nil when there are none.
+func copyProviderConfigOverrides(overrides map[string]map[string]any) map[string]map[string]any {
+	if len(overrides) == 0 {
+		return nil
+	}
+	copied := make(map[string]map[string]any, len(overrides))
+	for alias, config := range overrides {
+		copied[alias], _ = deepCopyValue(config).(map[string]any)
+	}
+	return copied
+}
@@ -0,0 +1,183 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_Overrides verifies that overrides are merged over the compiled
+// data and attributed to OverrideSource.
+func TestCompile_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n  port: 8080\n  tags:\n    - 'a'\n    - 'b'\ndb:\n  host: 'localhost'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	overrides := map[string]any{
+		"app":     map[string]any{"port": "9090", "tags": []any{"c"}},
+		"feature": map[string]any{"enabled": "true"},
+	}
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: testutil.NewFakeProviderRegistry(),
+		SourceMap:        true,
+		TypeCoercion:     compiler.TypeCoercionLenient,
+		Overrides:        overrides,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{
+		"app":     map[string]any{"name": "demo", "port": int64(9090), "tags": []any{"c"}},
+		"db":      map[string]any{"host": "localhost"},
+		"feature": map[string]any{"enabled": true},
+	}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+
+	// The caller's overrides are not aliased into the output
+	if _, ok := overrides["app"].(map[string]any)["name"]; ok {
+		t.Error("overrides map was mutated")
+	}
+
+	provenance := result.Snapshot.Metadata.PerKeyProvenance
+	if got := provenance["app"].Source; got != compiler.OverrideSource {
+		t.Errorf("provenance[app] = %q, want %q", got, compiler.OverrideSource)
+	}
+	if got := provenance["db"].Source; got != path {
+		t.Errorf("provenance[db] = %q, want %q", got, path)
+	}
+
+	sm := result.Snapshot.SourceMap
+	for key, wantFile := range map[string]string{
+		"app":             path,
+		"app.name":        path,
+		"app.port":        compiler.OverrideSource,
+		"app.tags":        compiler.OverrideSource,
+		"app.tags[0]":     compiler.OverrideSource,
+		"feature":         compiler.OverrideSource,
+		"feature.enabled": compiler.OverrideSource,
+	} {
+		entry, ok := sm.Lookup(key)
+		if !ok {
+			t.Errorf("source map has no entry for %q", key)
+			continue
+		}
+		if entry.Location.File != wantFile {
+			t.Errorf("source map %q file = %q, want %q", key, entry.Location.File, wantFile)
+		}
+	}
+	if _, ok := sm.Lookup("app.tags[1]"); ok {
+		t.Error("source map keeps an entry for a replaced list element")
+	}
+}
+
+// TestCompile_Overrides_SharedReference verifies that an override beneath
+// one key leaves other keys referencing the same provider path unchanged,
+// although both resolve from one cached provider value.
+func TestCompile_Overrides_SharedReference(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeFile(filepath.Join(dir, "shared.yaml"), "db:\n  host: prod-db\n  port: 5432\n"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app.csl")
+	src := "source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './shared.yaml'\n\n" +
+		"a: @shared:db\nb: @shared:db\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 path,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+		Overrides:            map[string]any{"a": map[string]any{"host": "changed"}},
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{
+		"a": map[string]any{"host": "changed", "port": 5432.0},
+		"b": map[string]any{"host": "prod-db", "port": 5432.0},
+	}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+}
+
+// TestCompile_ProviderConfigOverrides verifies that provider config
+// overrides replace source declaration config, for single files and
+// directories alike, and are recorded in the metadata.
+func TestCompile_ProviderConfigOverrides(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'cfg'\n  type: 'named'\n  name: 'one'\n\na:\n  value: @cfg:value\n",
+		"b.csl": "b:\n  value: @cfg:value\n",
+	}
+	for name, content := range files {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{filepath.Join(dir, "a.csl"), dir} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			registry := compiler.NewProviderTypeRegistry()
+			registry.RegisterType("named", func(config map[string]any) (compiler.Provider, error) {
+				return &nameProvider{name: config["name"]}, nil
+			})
+			overrides := map[string]map[string]any{"cfg": {"name": "two"}}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                    path,
+				ProviderRegistry:        testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry:    registry,
+				ProviderConfigOverrides: overrides,
+			})
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			if got := result.Snapshot.Data["a"]; !reflect.DeepEqual(got, map[string]any{"value": "two"}) {
+				t.Errorf("Data[a] = %#v, want the overridden name", got)
+			}
+
+			overrides["cfg"]["name"] = "three"
+			want := map[string]map[string]any{"cfg": {"name": "two"}}
+			if got := result.Snapshot.Metadata.ProviderConfigOverrides; !reflect.DeepEqual(got, want) {
+				t.Errorf("Metadata.ProviderConfigOverrides = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestCompile_ProviderConfigOverrides_UnknownAlias verifies that an
+// override for an alias no file declares is an error.
+func TestCompile_ProviderConfigOverrides_UnknownAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                    path,
+		ProviderRegistry:        testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry:    compiler.NewProviderTypeRegistry(),
+		ProviderConfigOverrides: map[string]map[string]any{"files": {"directory": "/tmp"}},
+	})
+	if !result.HasErrors() {
+		t.Fatal("expected an error for an undeclared alias")
+	}
+	if got := result.Error().Error(); !strings.Contains(got, `undeclared source alias files`) {
+		t.Errorf("error = %q, want it to name the alias", got)
+	}
+}
@@ -0,0 +1,57 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+)
+
+// RetryPolicy retries provider fetches that fail with a transient error:
+// an unavailable provider, a fetch that timed out, or a gRPC Unavailable,
+// ResourceExhausted, or Aborted status. Waits between attempts double from
+// InitialBackoff up to MaxBackoff. The zero value disables retries.
+type RetryPolicy = core.RetryPolicy
+
+// PartialFailureMode controls whether a reference that cannot be resolved,
+// because its provider is unavailable or its fetch fails, aborts the
+// compilation. Other errors, such as circular references, always do.
+type PartialFailureMode string
+
+const (
+	// PartialFailureFailFast stops at the first failed reference (default).
+	PartialFailureFailFast PartialFailureMode = "fail-fast"
+
+	// PartialFailureCollectAll resolves every reference and reports each
+	// failed one as a separate error, so a single run lists all of them.
+	// The compilation fails when any reference did.
+	PartialFailureCollectAll PartialFailureMode = "collect-all"
+
+	// PartialFailureBestEffort replaces failed references with null
+	// placeholders and reports them as warnings, like AllowMissingProvider,
+	// so the compilation succeeds with incomplete data.
+	PartialFailureBestEffort PartialFailureMode = "best-effort"
+)
+
+// ParsePartialFailureMode parses a mode name. The empty string yields
+// PartialFailureFailFast.
+func ParsePartialFailureMode(s string) (PartialFailureMode, error) {
+	switch m := PartialFailureMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return PartialFailureFailFast, nil
+	case PartialFailureFailFast, PartialFailureCollectAll, PartialFailureBestEffort:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid partial failure mode %q (want fail-fast, collect-all, or best-effort)", s)
+	}
+}
+
+// validate reports a mode that is not one of the constants.
+func (m PartialFailureMode) validate() error {
+	switch m {
+	case "", PartialFailureFailFast, PartialFailureCollectAll, PartialFailureBestEffort:
+		return nil
+	default:
+		return fmt.Errorf("invalid options.PartialFailureMode %q (want fail-fast, collect-all, or best-effort)", m)
+	}
+}
@@ -0,0 +1,144 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// flakyProvider fails its first failures fetches with err.
+type flakyProvider struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (p *flakyProvider) Init(_ context.Context, _ compiler.ProviderInitOptions) error {
+	return nil
+}
+
+func (p *flakyProvider) Fetch(_ context.Context, path []string) (any, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return nil, p.err
+	}
+	return strings.Join(path, "."), nil
+}
+
+func TestCompile_FetchRetry(t *testing.T) {
+	unavailable := fmt.Errorf("%w: connection refused", compiler.ErrProviderUnavailable)
+	tests := []struct {
+		name      string
+		err       error
+		attempts  int
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "transient error retried", err: unavailable, attempts: 3, wantCalls: 3},
+		{name: "attempts exhausted", err: unavailable, attempts: 2, wantCalls: 2, wantErr: "after 2 attempts"},
+		{name: "retries disabled", err: unavailable, wantCalls: 1, wantErr: "connection refused"},
+		{name: "permanent error not retried", err: errors.New("path not found"), attempts: 3, wantCalls: 1, wantErr: "path not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			if err := writeFile(path, "value: @flaky:key\n"); err != nil {
+				t.Fatal(err)
+			}
+			provider := &flakyProvider{failures: 2, err: tt.err}
+			registry := compiler.NewProviderRegistry()
+			registry.Register("flaky", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+				return provider, nil
+			})
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: registry,
+				FetchRetry:       compiler.RetryPolicy{MaxAttempts: tt.attempts, InitialBackoff: time.Millisecond},
+			})
+			if provider.calls != tt.wantCalls {
+				t.Errorf("Fetch called %d times, want %d", provider.calls, tt.wantCalls)
+			}
+			if tt.wantErr == "" {
+				if result.HasErrors() {
+					t.Fatalf("unexpected errors: %v", result.Snapshot.Metadata.Errors)
+				}
+				if result.Snapshot.Data["value"] != "key" {
+					t.Errorf("value = %v, want key", result.Snapshot.Data["value"])
+				}
+				return
+			}
+			if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+				t.Errorf("errors = %v, want one containing %q", result.Snapshot.Metadata.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile_PartialFailureMode(t *testing.T) {
+	source := "first: @broken:one\nsecond: @down:two\nok: 'yes'\n"
+	tests := []struct {
+		mode         compiler.PartialFailureMode
+		wantErrors   int
+		wantWarnings int
+	}{
+		{mode: "", wantErrors: 1},
+		{mode: compiler.PartialFailureFailFast, wantErrors: 1},
+		{mode: compiler.PartialFailureCollectAll, wantErrors: 2},
+		{mode: compiler.PartialFailureBestEffort, wantWarnings: 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			if err := writeFile(path, source); err != nil {
+				t.Fatal(err)
+			}
+			registry := compiler.NewProviderRegistry()
+			registry.Register("broken", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+				return &flakyProvider{failures: 1, err: errors.New("access denied")}, nil
+			})
+			registry.Register("down", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+				return nil, errors.New("binary missing")
+			})
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:               path,
+				ProviderRegistry:   registry,
+				PartialFailureMode: tt.mode,
+			})
+			if got := len(result.Snapshot.Metadata.Errors); got != tt.wantErrors {
+				t.Errorf("errors = %v, want %d", result.Snapshot.Metadata.Errors, tt.wantErrors)
+			}
+			if got := len(result.Snapshot.Metadata.Warnings); got != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", result.Snapshot.Metadata.Warnings, tt.wantWarnings)
+			}
+			if tt.mode == compiler.PartialFailureCollectAll && !errors.Is(result.Error(), compiler.ErrProviderUnavailable) {
+				t.Errorf("Error() = %v, want the unavailable provider among the failures", result.Error())
+			}
+			if tt.mode == compiler.PartialFailureBestEffort {
+				data := result.Snapshot.Data
+				if v, ok := data["first"]; !ok || v != nil || data["ok"] != "yes" {
+					t.Errorf("data = %v, want a null placeholder for first and ok resolved", data)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePartialFailureMode(t *testing.T) {
+	if mode, err := compiler.ParsePartialFailureMode(" Collect-All "); err != nil || mode != compiler.PartialFailureCollectAll {
+		t.Errorf("ParsePartialFailureMode(Collect-All) = %q, %v", mode, err)
+	}
+	if mode, err := compiler.ParsePartialFailureMode(""); err != nil || mode != compiler.PartialFailureFailFast {
+		t.Errorf("ParsePartialFailureMode(\"\") = %q, %v", mode, err)
+	}
+	if _, err := compiler.ParsePartialFailureMode("ignore"); err == nil {
+		t.Error("ParsePartialFailureMode(ignore) expected an error")
+	}
+}
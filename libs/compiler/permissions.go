@@ -0,0 +1,237 @@
+package compiler
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// ProviderPermissions restricts the source declarations a compilation
+// accepts, so configuration contributed by untrusted authors cannot point
+// providers at arbitrary locations. In YAML:
+//
+//	types:
+//	  file:
+//	    aliases: [configs, shared]
+//	    config:
+//	      directory:
+//	        allow: [./data/**, ./shared/**]
+//	  autonomous-bits/nomos-provider-terraform:
+//	    config:
+//	      state:
+//	        deny: [/**, ../**]
+//
+// Declarations are checked before any provider starts. Config overrides
+// (Options.ProviderConfigOverrides) come from the caller and are not
+// checked.
+type ProviderPermissions struct {
+	// Types maps each permitted provider type to the rules its
+	// declarations follow. Declarations of other types are rejected.
+	Types map[string]ProviderTypePermission `yaml:"types" json:"types"`
+}
+
+// ProviderTypePermission holds the rules for the declarations of one
+// provider type.
+type ProviderTypePermission struct {
+	// Aliases, when set, lists the aliases declarations may use.
+	Aliases []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+
+	// Config, when set, lists the config keys declarations may set and the
+	// values each may take. Other keys are rejected.
+	Config map[string]ConfigValuePermission `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// ConfigValuePermission restricts the values of a config key. Patterns use
+// path.Match syntax, plus a trailing "/**" that matches a path and
+// everything beneath it, and are matched against the value cleaned as a
+// slash-separated path, so "./data/../../etc" is matched as "../etc".
+// Restricted values must be string literals, since references are only
+// known once providers run.
+type ConfigValuePermission struct {
+	// Allow, when set, lists patterns a value must match.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+
+	// Deny lists patterns a value must not match. It wins over Allow.
+	Deny []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// ProviderPermissionError reports a source declaration that
+// Options.ProviderPermissions forbids.
+type ProviderPermissionError struct {
+	// Alias and Type identify the declaration.
+	Alias string
+	Type  string
+
+	// Key is the forbidden config key, or empty when the type or alias is
+	// forbidden.
+	Key string
+
+	// Reason describes the rule the declaration breaks.
+	Reason string
+
+	// Filename, Line and Column locate the declaration, or its config
+	// value, in source.
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (e *ProviderPermissionError) Error() string {
+	subject := fmt.Sprintf("source %q of type %q", e.Alias, e.Type)
+	if e.Key != "" {
+		subject += fmt.Sprintf(", config %q,", e.Key)
+	}
+	return fmt.Sprintf("%s not permitted at %s:%d:%d: %s", subject, e.Filename, e.Line, e.Column, e.Reason)
+}
+
+// Unwrap returns ErrProviderNotPermitted.
+func (e *ProviderPermissionError) Unwrap() error {
+	return ErrProviderNotPermitted
+}
+
+// Hint points to where the permissions are configured.
+func (e *ProviderPermissionError) Hint() Hint {
+	return Hint{Text: "change the declaration, or ask the project maintainers to permit it in provider_permissions"}
+}
+
+// Validate reports malformed patterns.
+func (p *ProviderPermissions) Validate() error {
+	for typ, rule := range p.Types {
+		for key, values := range rule.Config {
+			for _, pattern := range append(slices.Clone(values.Allow), values.Deny...) {
+				if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+					return fmt.Errorf("provider permissions for %s config %q: invalid pattern %q: %w", typ, key, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckProviderPermissions checks the source declarations of the input
+// files at inputPath (a file or directory, as for Options.Path) against
+// perms without starting any provider, so callers can refuse to install
+// forbidden providers. Files that do not parse are skipped; compiling them
+// reports the parse errors. The returned error joins a
+// *ProviderPermissionError per violation.
+func CheckProviderPermissions(ctx context.Context, inputPath string, perms *ProviderPermissions) error {
+	if perms == nil {
+		return nil
+	}
+	inputFiles, err := pipeline.DiscoverInputFiles(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to discover input files: %w", err)
+	}
+	return stderrors.Join(checkProviderPermissions(pipeline.ParseFiles(ctx, inputFiles, 0), perms)...)
+}
+
+// checkProviderPermissions returns a *ProviderPermissionError for each
+// violation of perms by the source declarations in files.
+func checkProviderPermissions(files []pipeline.ParsedFile, perms *ProviderPermissions) []error {
+	var errs []error
+	for _, file := range files {
+		if file.AST == nil {
+			continue
+		}
+		for _, stmt := range file.AST.Statements {
+			if decl, ok := stmt.(*ast.SourceDecl); ok {
+				errs = append(errs, perms.check(decl)...)
+			}
+		}
+	}
+	return errs
+}
+
+// check returns the violations of perms by decl.
+func (p *ProviderPermissions) check(decl *ast.SourceDecl) []error {
+	violation := func(node ast.Node, key, format string, args ...any) error {
+		span := node.Span()
+		return &ProviderPermissionError{
+			Alias:    decl.Alias,
+			Type:     decl.Type,
+			Key:      key,
+			Reason:   fmt.Sprintf(format, args...),
+			Filename: span.Filename,
+			Line:     span.StartLine,
+			Column:   span.StartCol,
+		}
+	}
+
+	rule, ok := p.Types[decl.Type]
+	if !ok {
+		return []error{violation(decl, "", "provider type is not permitted (permitted: %s)", listOrNone(slices.Sorted(maps.Keys(p.Types))))}
+	}
+	if len(rule.Aliases) > 0 && !slices.Contains(rule.Aliases, decl.Alias) {
+		return []error{violation(decl, "", "alias is not permitted for this type (permitted: %s)", strings.Join(rule.Aliases, ", "))}
+	}
+	if rule.Config == nil {
+		return nil
+	}
+
+	var errs []error
+	keys := slices.Sorted(maps.Keys(decl.Config))
+	for _, key := range keys {
+		expr := decl.Config[key]
+		values, ok := rule.Config[key]
+		if !ok {
+			errs = append(errs, violation(expr, key, "config key is not permitted (permitted: %s)", listOrNone(slices.Sorted(maps.Keys(rule.Config)))))
+			continue
+		}
+		if len(values.Allow) == 0 && len(values.Deny) == 0 {
+			continue
+		}
+		literal, ok := expr.(*ast.StringLiteral)
+		if !ok {
+			errs = append(errs, violation(expr, key, "value must be a string literal, since its permitted values are restricted"))
+			continue
+		}
+		value := path.Clean(literal.Value)
+		if i := slices.IndexFunc(values.Deny, func(pattern string) bool { return matchPermission(pattern, value) }); i >= 0 {
+			errs = append(errs, violation(expr, key, "value %q is denied by %q", literal.Value, values.Deny[i]))
+			continue
+		}
+		if len(values.Allow) > 0 && !slices.ContainsFunc(values.Allow, func(pattern string) bool { return matchPermission(pattern, value) }) {
+			errs = append(errs, violation(expr, key, "value %q matches none of %s", literal.Value, strings.Join(values.Allow, ", ")))
+		}
+	}
+	return errs
+}
+
+// matchPermission reports whether the cleaned value matches pattern. A
+// pattern ending in "/**" also matches everything beneath a match.
+func matchPermission(pattern, value string) bool {
+	prefix, recursive := strings.CutSuffix(pattern, "/**")
+	switch {
+	case pattern == "**":
+		return true
+	case !recursive:
+		ok, _ := path.Match(path.Clean(pattern), value)
+		return ok
+	case prefix == "":
+		prefix = "/"
+	}
+	prefix = path.Clean(prefix)
+	for candidate := value; ; candidate = path.Dir(candidate) {
+		if ok, _ := path.Match(prefix, candidate); ok {
+			return true
+		}
+		if path.Dir(candidate) == candidate {
+			return false
+		}
+	}
+}
+
+// listOrNone joins items, or returns "none".
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
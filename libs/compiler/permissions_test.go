@@ -0,0 +1,126 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_ProviderPermissions verifies that forbidden source
+// declarations fail the compilation, located in source, before any
+// provider is created.
+func TestCompile_ProviderPermissions(t *testing.T) {
+	perms := &compiler.ProviderPermissions{Types: map[string]compiler.ProviderTypePermission{
+		"named": {
+			Aliases: []string{"cfg"},
+			Config: map[string]compiler.ConfigValuePermission{
+				"name":      {},
+				"directory": {Allow: []string{"./data/**"}, Deny: []string{"data/secrets/**"}},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+		wantPos [2]int
+	}{
+		{name: "permitted", source: "source:\n  alias: 'cfg'\n  type: 'named'\n  name: 'one'\n  directory: './data/app'\n"},
+		{name: "type", source: "source:\n  alias: 'cfg'\n  type: 'shell'\n", wantErr: `source "cfg" of type "shell" not permitted`, wantPos: [2]int{1, 1}},
+		{name: "alias", source: "source:\n  alias: 'other'\n  type: 'named'\n", wantErr: "alias is not permitted for this type (permitted: cfg)", wantPos: [2]int{1, 1}},
+		{name: "key", source: "source:\n  alias: 'cfg'\n  type: 'named'\n  command: 'rm'\n", wantErr: `config "command", not permitted`, wantPos: [2]int{4, 12}},
+		{name: "escaping value", source: "source:\n  alias: 'cfg'\n  type: 'named'\n  directory: './data/../../etc'\n", wantErr: `value "./data/../../etc" matches none of ./data/**`, wantPos: [2]int{4, 14}},
+		{name: "denied value", source: "source:\n  alias: 'cfg'\n  type: 'named'\n  directory: 'data/secrets/prod'\n", wantErr: `denied by "data/secrets/**"`, wantPos: [2]int{4, 14}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			if err := writeFile(path, tt.source+"\napp:\n  name: 'demo'\n"); err != nil {
+				t.Fatal(err)
+			}
+			created := 0
+			registry := compiler.NewProviderTypeRegistry()
+			registry.RegisterType("named", func(config map[string]any) (compiler.Provider, error) {
+				created++
+				return &nameProvider{name: config["name"]}, nil
+			})
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: registry,
+				ProviderPermissions:  perms,
+			})
+			if tt.wantErr == "" {
+				if result.HasErrors() {
+					t.Fatalf("unexpected errors: %v", result.Error())
+				}
+				return
+			}
+
+			err := result.Error()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, compiler.ErrProviderNotPermitted) {
+				t.Errorf("error = %v, want ErrProviderNotPermitted", err)
+			}
+			if created != 0 {
+				t.Errorf("%d providers created, want none", created)
+			}
+			d := result.Diagnostics()[0]
+			if d.File != path || [2]int{d.Line, d.Column} != tt.wantPos {
+				t.Errorf("diagnostic at %s:%d:%d, want %s:%d:%d", d.File, d.Line, d.Column, path, tt.wantPos[0], tt.wantPos[1])
+			}
+		})
+	}
+}
+
+// TestCheckProviderPermissions verifies the check run before providers
+// are installed, and the recursive pattern forms.
+func TestCheckProviderPermissions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'abs'\n  type: 'file'\n  directory: '/etc'\n",
+		"b.csl": "source:\n  alias: 'up'\n  type: 'file'\n  directory: '../shared'\n",
+		"c.csl": "source:\n  alias: 'ok'\n  type: 'file'\n  directory: './data'\n",
+	}
+	for name, content := range files {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	perms := &compiler.ProviderPermissions{Types: map[string]compiler.ProviderTypePermission{
+		"file": {Config: map[string]compiler.ConfigValuePermission{
+			"directory": {Deny: []string{"/**", "../**"}},
+		}},
+	}}
+
+	err := compiler.CheckProviderPermissions(context.Background(), dir, perms)
+	var aliases []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var permErr *compiler.ProviderPermissionError
+		if errors.As(e, &permErr) {
+			aliases = append(aliases, permErr.Alias)
+		}
+	}
+	if want := []string{"abs", "up"}; !reflect.DeepEqual(aliases, want) {
+		t.Errorf("violations = %v, want %v (error %v)", aliases, want, err)
+	}
+
+	if err := compiler.CheckProviderPermissions(context.Background(), filepath.Join(dir, "c.csl"), perms); err != nil {
+		t.Errorf("CheckProviderPermissions(c.csl) = %v, want nil", err)
+	}
+	if err := (&compiler.ProviderPermissions{Types: map[string]compiler.ProviderTypePermission{
+		"file": {Config: map[string]compiler.ConfigValuePermission{"directory": {Allow: []string{"[data"}}}},
+	}}).Validate(); err == nil {
+		t.Error("Validate() = nil, want an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,186 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// Of converts plain Go data, as returned by providers and produced by the
+// compiler, into a Value. Supported types are nil, string, bool, the integer
+// and float types, []any, map[string]any, secrets, and Value itself.
+// Unsigned integers above math.MaxInt64 are an error.
+func Of(data any) (Value, error) {
+	switch d := data.(type) {
+	case nil:
+		return Value{}, nil
+	case Value:
+		return d, nil
+	case string:
+		return String(d), nil
+	case bool:
+		return Bool(d), nil
+	case int:
+		return Int(int64(d)), nil
+	case int8:
+		return Int(int64(d)), nil
+	case int16:
+		return Int(int64(d)), nil
+	case int32:
+		return Int(int64(d)), nil
+	case int64:
+		return Int(d), nil
+	case uint8:
+		return Int(int64(d)), nil
+	case uint16:
+		return Int(int64(d)), nil
+	case uint32:
+		return Int(int64(d)), nil
+	case uint:
+		return ofUint(uint64(d))
+	case uint64:
+		return ofUint(d)
+	case float32:
+		return Float(float64(d)), nil
+	case float64:
+		return Float(d), nil
+	case []any:
+		items := make([]Value, len(d))
+		for i, elem := range d {
+			v, err := Of(elem)
+			if err != nil {
+				return Value{}, fmt.Errorf("list element %d: %w", i, err)
+			}
+			items[i] = v
+		}
+		return Value{kind: KindList, items: items}, nil
+	case map[string]any:
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		items := make([]Value, len(keys))
+		for i, k := range keys {
+			v, err := Of(d[k])
+			if err != nil {
+				return Value{}, fmt.Errorf("key %q: %w", k, err)
+			}
+			items[i] = v
+		}
+		return Value{kind: KindMap, keys: keys, items: items}, nil
+	case models.Secret:
+		v, err := Of(d.Value)
+		if err != nil {
+			return Value{}, fmt.Errorf("secret: %w", err)
+		}
+		return Secret(v), nil
+	default:
+		return Value{}, fmt.Errorf("unsupported type %T", data)
+	}
+}
+
+// ofUint converts an unsigned integer, which must fit in an int64.
+func ofUint(n uint64) (Value, error) {
+	if n > math.MaxInt64 {
+		return Value{}, fmt.Errorf("integer %d overflows int64", n)
+	}
+	return Int(int64(n)), nil
+}
+
+// Any converts v back to plain Go data: nil, string, int, float64, bool,
+// []any, map[string]any, or models.Secret. Integers that do not fit in an
+// int, on platforms where it is narrower, are returned as int64.
+func (v Value) Any() any {
+	switch v.kind {
+	case KindString:
+		return v.str
+	case KindInt:
+		n, _ := v.Int()
+		if n != int64(int(n)) {
+			return n
+		}
+		return int(n)
+	case KindFloat:
+		f, _ := v.Float()
+		return f
+	case KindBool:
+		return v.bits == 1
+	case KindList:
+		out := make([]any, len(v.items))
+		for i, elem := range v.items {
+			out[i] = elem.Any()
+		}
+		return out
+	case KindMap:
+		out := make(map[string]any, len(v.keys))
+		for i, k := range v.keys {
+			out[k] = v.items[i].Any()
+		}
+		return out
+	case KindSecret:
+		return models.Secret{Value: v.items[0].Any()}
+	default:
+		return nil
+	}
+}
+
+// Merge deep-merges src over dst using Nomos composition semantics: maps
+// merge key by key, and any other src value (including lists and null)
+// replaces dst. Subtrees present in only one input are shared, not copied.
+func Merge(dst, src Value) Value {
+	if dst.kind != KindMap || src.kind != KindMap {
+		return src
+	}
+	if len(dst.keys) == 0 {
+		return src
+	}
+	if len(src.keys) == 0 {
+		return dst
+	}
+
+	keys := make([]string, 0, len(dst.keys)+len(src.keys))
+	items := make([]Value, 0, len(dst.keys)+len(src.keys))
+
+	// Both key slices are sorted, so a single merge walk keeps the result sorted.
+	i, j := 0, 0
+	for i < len(dst.keys) || j < len(src.keys) {
+		switch {
+		case j == len(src.keys) || (i < len(dst.keys) && dst.keys[i] < src.keys[j]):
+			keys = append(keys, dst.keys[i])
+			items = append(items, dst.items[i])
+			i++
+		case i == len(dst.keys) || src.keys[j] < dst.keys[i]:
+			keys = append(keys, src.keys[j])
+			items = append(items, src.items[j])
+			j++
+		default:
+			keys = append(keys, src.keys[j])
+			items = append(items, Merge(dst.items[i], src.items[j]))
+			i++
+			j++
+		}
+	}
+	return Value{kind: KindMap, keys: keys, items: items}
+}
+
+// Equal reports whether a and b hold the same data.
+func Equal(a, b Value) bool {
+	if a.kind != b.kind || a.bits != b.bits || a.str != b.str || len(a.items) != len(b.items) || len(a.keys) != len(b.keys) {
+		return false
+	}
+	for i := range a.keys {
+		if a.keys[i] != b.keys[i] {
+			return false
+		}
+	}
+	for i := range a.items {
+		if !Equal(a.items[i], b.items[i]) {
+			return false
+		}
+	}
+	return true
+}
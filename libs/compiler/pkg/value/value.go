@@ -0,0 +1,198 @@
+// Package value provides a compact, typed representation of resolved
+// configuration data.
+//
+// A Value is a small union of null, string, integer, float, boolean, list,
+// map, and secret, which wraps a value to be encrypted in the output.
+// Unlike AST literals, scalars keep their type, and maps are stored
+// as sorted key/value slices rather than Go maps, so large configurations
+// need two allocations per map and iterate in deterministic order.
+//
+// Values are immutable once built: Merge and the other operations return new
+// values that share unchanged subtrees with their inputs instead of copying
+// them.
+package value
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Kind identifies the type held by a Value.
+type Kind uint8
+
+const (
+	// KindNull is the zero Value.
+	KindNull Kind = iota
+	KindString
+	KindInt
+	KindFloat
+	KindBool
+	KindList
+	KindMap
+	KindSecret
+)
+
+// String returns the lowercase name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	case KindSecret:
+		return "secret"
+	default:
+		return fmt.Sprintf("kind(%d)", k)
+	}
+}
+
+// Value is a resolved configuration value. The zero Value is null.
+type Value struct {
+	kind  Kind
+	bits  uint64  // int64, float64 bits, or bool
+	str   string  // string payload
+	items []Value // list elements, map values aligned with keys, or the secret
+	keys  []string
+}
+
+// Null returns the null value.
+func Null() Value { return Value{} }
+
+// String returns a string value.
+func String(s string) Value { return Value{kind: KindString, str: s} }
+
+// Int returns an integer value.
+func Int(n int64) Value { return Value{kind: KindInt, bits: uint64(n)} } //nolint:gosec // G115: Bit-preserving storage
+
+// Float returns a floating-point value.
+func Float(f float64) Value { return Value{kind: KindFloat, bits: math.Float64bits(f)} }
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	v := Value{kind: KindBool}
+	if b {
+		v.bits = 1
+	}
+	return v
+}
+
+// List returns a list value holding elems. The slice is retained.
+func List(elems ...Value) Value { return Value{kind: KindList, items: elems} }
+
+// Map returns a map value holding a copy of m.
+func Map(m map[string]Value) Value {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]Value, len(keys))
+	for i, k := range keys {
+		items[i] = m[k]
+	}
+	return Value{kind: KindMap, keys: keys, items: items}
+}
+
+// Secret returns a secret value wrapping v.
+func Secret(v Value) Value { return Value{kind: KindSecret, items: []Value{v}} }
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind { return v.kind }
+
+// IsNull reports whether v is null.
+func (v Value) IsNull() bool { return v.kind == KindNull }
+
+// Str returns the string held by v and whether v is a string.
+func (v Value) Str() (string, bool) { return v.str, v.kind == KindString }
+
+// Int returns the integer held by v and whether v is an integer.
+func (v Value) Int() (int64, bool) { return int64(v.bits), v.kind == KindInt } //nolint:gosec // G115: Bit-preserving storage
+
+// Float returns the float held by v and whether v is a float.
+func (v Value) Float() (float64, bool) { return math.Float64frombits(v.bits), v.kind == KindFloat }
+
+// Bool returns the boolean held by v and whether v is a boolean.
+func (v Value) Bool() (bool, bool) { return v.bits == 1, v.kind == KindBool }
+
+// Secret returns the value wrapped by a secret and whether v is a secret.
+func (v Value) Secret() (Value, bool) {
+	if v.kind != KindSecret {
+		return Value{}, false
+	}
+	return v.items[0], true
+}
+
+// Len returns the number of elements of a list or entries of a map, and 0
+// for scalars and secrets.
+func (v Value) Len() int {
+	if v.kind != KindList && v.kind != KindMap {
+		return 0
+	}
+	return len(v.items)
+}
+
+// Index returns element i of a list. It panics if v is not a list or i is
+// out of range.
+func (v Value) Index(i int) Value {
+	if v.kind != KindList {
+		panic(fmt.Sprintf("value: Index on %s", v.kind))
+	}
+	return v.items[i]
+}
+
+// Keys returns the keys of a map in sorted order, or nil for other kinds.
+// The returned slice must not be modified.
+func (v Value) Keys() []string {
+	if v.kind != KindMap {
+		return nil
+	}
+	return v.keys
+}
+
+// Field returns the value stored under key in a map, and whether it exists.
+func (v Value) Field(key string) (Value, bool) {
+	if v.kind != KindMap {
+		return Value{}, false
+	}
+	i := sort.SearchStrings(v.keys, key)
+	if i < len(v.keys) && v.keys[i] == key {
+		return v.items[i], true
+	}
+	return Value{}, false
+}
+
+// Lookup follows path through nested maps and returns the value at its end.
+func (v Value) Lookup(path ...string) (Value, bool) {
+	for _, key := range path {
+		var ok bool
+		if v, ok = v.Field(key); !ok {
+			return Value{}, false
+		}
+	}
+	return v, true
+}
+
+// String formats scalars as they appear in configuration text; lists and
+// maps use Go's %v formatting of Any.
+func (v Value) String() string {
+	switch v.kind {
+	case KindNull:
+		return ""
+	case KindString:
+		return v.str
+	default:
+		return fmt.Sprint(v.Any())
+	}
+}
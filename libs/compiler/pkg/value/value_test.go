@@ -0,0 +1,176 @@
+package value
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+func TestOf_RoundTripPreservesTypes(t *testing.T) {
+	input := map[string]any{
+		"name":    "api",
+		"port":    8080,
+		"ratio":   0.5,
+		"enabled": true,
+		"none":    nil,
+		"tags":    []any{"a", int64(2)},
+		"nested":  map[string]any{"count": int32(3)},
+	}
+
+	v, err := Of(input)
+	if err != nil {
+		t.Fatalf("Of() error = %v", err)
+	}
+
+	want := map[string]any{
+		"name":    "api",
+		"port":    8080,
+		"ratio":   0.5,
+		"enabled": true,
+		"none":    nil,
+		"tags":    []any{"a", 2},
+		"nested":  map[string]any{"count": 3},
+	}
+	if got := v.Any(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Any() = %#v, want %#v", got, want)
+	}
+
+	port, _ := v.Field("port")
+	if n, ok := port.Int(); !ok || n != 8080 {
+		t.Errorf("port = %v (%s), want int 8080", port, port.Kind())
+	}
+}
+
+func TestOf_UnsupportedType(t *testing.T) {
+	if _, err := Of(map[string]any{"bad": struct{}{}}); err == nil {
+		t.Fatal("expected error for unsupported type")
+	}
+}
+
+func TestOf_UnsignedIntegers(t *testing.T) {
+	if got := mustOf(t, uint(7)); !Equal(got, Int(7)) {
+		t.Errorf("Of(uint(7)) = %v (%s), want int 7", got, got.Kind())
+	}
+	if got := mustOf(t, uint64(math.MaxInt64)); !Equal(got, Int(math.MaxInt64)) {
+		t.Errorf("Of(MaxInt64) = %v (%s), want int %d", got, got.Kind(), int64(math.MaxInt64))
+	}
+	if _, err := Of(uint64(math.MaxInt64) + 1); err == nil {
+		t.Error("Of(MaxInt64+1) succeeded, want an overflow error")
+	}
+}
+
+func TestOf_Secret(t *testing.T) {
+	input := map[string]any{
+		"password": models.Secret{Value: "hunter2"},
+		"tls":      models.Secret{Value: map[string]any{"key": "k"}},
+	}
+
+	v := mustOf(t, input)
+	password, _ := v.Field("password")
+	if password.Kind() != KindSecret {
+		t.Fatalf("password kind = %s, want secret", password.Kind())
+	}
+	if inner, ok := password.Secret(); !ok || !Equal(inner, String("hunter2")) {
+		t.Errorf("password.Secret() = %v, %v; want hunter2", inner, ok)
+	}
+	tls, _ := v.Field("tls")
+	if _, ok := tls.Field("key"); ok || tls.Len() != 0 {
+		t.Error("a secret map exposed its fields")
+	}
+
+	if got := v.Any(); !reflect.DeepEqual(got, input) {
+		t.Errorf("Any() = %#v, want %#v", got, input)
+	}
+	if _, err := Of(models.Secret{Value: struct{}{}}); err == nil {
+		t.Error("Of(secret of unsupported type) succeeded, want an error")
+	}
+}
+
+func TestMap_SortsKeys(t *testing.T) {
+	v := Map(map[string]Value{"b": Int(2), "a": Int(1), "c": Int(3)})
+
+	if got, want := v.Keys(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if _, ok := v.Field("missing"); ok {
+		t.Error("Field(missing) reported ok")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	v := Map(map[string]Value{
+		"db": Map(map[string]Value{"host": String("localhost")}),
+	})
+
+	got, ok := v.Lookup("db", "host")
+	if s, _ := got.Str(); !ok || s != "localhost" {
+		t.Errorf("Lookup(db, host) = %v, %v", got, ok)
+	}
+	if _, ok := v.Lookup("db", "host", "deeper"); ok {
+		t.Error("Lookup through a scalar reported ok")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := mustOf(t, map[string]any{
+		"db":    map[string]any{"host": "localhost", "port": 5432},
+		"tags":  []any{"a", "b"},
+		"debug": true,
+	})
+	src := mustOf(t, map[string]any{
+		"db":    map[string]any{"port": 6432, "user": "app"},
+		"tags":  []any{"c"},
+		"debug": nil,
+	})
+	before := dst.Any()
+
+	got := Merge(dst, src)
+
+	want := mustOf(t, map[string]any{
+		"db":    map[string]any{"host": "localhost", "port": 6432, "user": "app"},
+		"tags":  []any{"c"},
+		"debug": nil,
+	})
+	if !Equal(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(dst.Any(), before) {
+		t.Error("Merge() modified dst")
+	}
+}
+
+func TestMerge_NonMapReplaces(t *testing.T) {
+	dst := mustOf(t, map[string]any{"a": 1})
+	if got := Merge(dst, String("x")); !Equal(got, String("x")) {
+		t.Errorf("Merge(map, string) = %v, want x", got)
+	}
+	if got := Merge(String("x"), dst); !Equal(got, dst) {
+		t.Errorf("Merge(string, map) = %v, want %v", got, dst)
+	}
+}
+
+func TestEqual_DistinguishesKinds(t *testing.T) {
+	if Equal(Int(1), Float(1)) {
+		t.Error("Equal(Int(1), Float(1)) = true")
+	}
+	if Equal(String("1"), Int(1)) {
+		t.Error(`Equal(String("1"), Int(1)) = true`)
+	}
+	if Equal(Secret(String("x")), String("x")) {
+		t.Error(`Equal(Secret(String("x")), String("x")) = true`)
+	}
+	if !Equal(List(Int(1), Bool(true)), List(Int(1), Bool(true))) {
+		t.Error("equal lists compared unequal")
+	}
+}
+
+func mustOf(t *testing.T, data any) Value {
+	t.Helper()
+	v, err := Of(data)
+	if err != nil {
+		t.Fatalf("Of() error = %v", err)
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicySeverity controls how a policy violation is reported.
+type PolicySeverity string
+
+const (
+	// PolicySeverityError reports violations as compilation errors (default).
+	PolicySeverityError PolicySeverity = "error"
+
+	// PolicySeverityWarning reports violations as W003 warnings.
+	PolicySeverityWarning PolicySeverity = "warning"
+)
+
+// Policy is a rule the compiled data must satisfy.
+//
+// Expression is a CEL expression (https://cel.dev) evaluated against the
+// resolved data, bound to the variable "data", and must yield true:
+//
+//	data.prod.database.ssl == true
+//	data.services.all(s, data.services[s].replicas >= 2)
+//
+// Policies run after reference resolution and before secret encryption.
+type Policy struct {
+	// Name identifies the policy in diagnostics.
+	Name string `yaml:"name" json:"name"`
+
+	// Expression is the CEL rule.
+	Expression string `yaml:"expr" json:"expr"`
+
+	// Message describes the requirement, e.g. "prod databases must have ssl=true".
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Severity is "error" (default) or "warning".
+	Severity PolicySeverity `yaml:"severity,omitempty" json:"severity,omitempty"`
+}
+
+// policyFile is the on-disk format read by LoadPolicies.
+type policyFile struct {
+	Policies []Policy `yaml:"policies"`
+}
+
+// LoadPolicies reads policies from a YAML file of the form:
+//
+//	policies:
+//	  - name: prod-db-ssl
+//	    expr: data.prod.database.ssl == true
+//	    message: prod databases must have ssl=true
+//	    severity: error
+func LoadPolicies(path string) ([]Policy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: Path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file policyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	for i, p := range file.Policies {
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, fmt.Errorf("policy file %s: policy %d has no name", path, i+1)
+		}
+		if strings.TrimSpace(p.Expression) == "" {
+			return nil, fmt.Errorf("policy file %s: policy %q has no expr", path, p.Name)
+		}
+		switch p.Severity {
+		case "", PolicySeverityError, PolicySeverityWarning:
+		default:
+			return nil, fmt.Errorf("policy file %s: policy %q has invalid severity %q (want error or warning)", path, p.Name, p.Severity)
+		}
+	}
+
+	return file.Policies, nil
+}
+
+// evaluatePolicies checks data against policies, recording violations as
+// errors or warnings on result. It returns false if any policy could not be
+// compiled.
+func evaluatePolicies(policies []Policy, data map[string]any, result *CompilationResult, filter *warningFilter) bool {
+	rules := make([]policy.Rule, len(policies))
+	for i, p := range policies {
+		rules[i] = policy.Rule{Name: p.Name, Expression: p.Expression}
+	}
+
+	evaluator, err := policy.New(rules)
+	if err != nil {
+		result.addError(fmt.Errorf("invalid policies: %w", err))
+		return false
+	}
+
+	for i, r := range evaluator.Evaluate(data) {
+		if r.Passed {
+			continue
+		}
+
+		p := policies[i]
+		message := p.Message
+		if message == "" {
+			message = p.Expression
+		}
+		violation := &PolicyError{Policy: p.Name, Message: message, Err: r.Err}
+
+		if p.Severity == PolicySeverityWarning {
+			result.addWarning(Warning{Code: WarnPolicyViolation, Message: violation.Error()}, filter)
+			continue
+		}
+		result.addError(violation)
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCompile_Policies verifies that policy violations are reported as errors
+// or warnings according to their severity.
+func TestCompile_Policies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "database:\n  ssl: 'false'\n  host: 'db.internal'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		policies     []compiler.Policy
+		wantErr      bool
+		wantWarnings int
+	}{
+		{
+			name:     "passing policy",
+			policies: []compiler.Policy{{Name: "host", Expression: "data.database.host != ''"}},
+		},
+		{
+			name:     "error severity",
+			policies: []compiler.Policy{{Name: "ssl", Expression: "data.database.ssl == 'true'", Message: "databases must have ssl=true"}},
+			wantErr:  true,
+		},
+		{
+			name:         "warning severity",
+			policies:     []compiler.Policy{{Name: "ssl", Expression: "data.database.ssl == 'true'", Severity: compiler.PolicySeverityWarning}},
+			wantWarnings: 1,
+		},
+		{
+			name:     "evaluation failure fails closed",
+			policies: []compiler.Policy{{Name: "missing", Expression: "data.cache.ttl == '60'"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: compiler.NewProviderRegistry(),
+				Policies:         tt.policies,
+			})
+
+			err := result.Error()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(result.Snapshot.Metadata.WarningDetails) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", result.Snapshot.Metadata.Warnings, tt.wantWarnings)
+			}
+			for _, w := range result.Snapshot.Metadata.WarningDetails {
+				if w.Code != compiler.WarnPolicyViolation {
+					t.Errorf("warning code = %q, want %q", w.Code, compiler.WarnPolicyViolation)
+				}
+			}
+
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, compiler.ErrPolicyViolation) {
+				t.Errorf("expected errors.Is(err, ErrPolicyViolation), got: %v", err)
+			}
+			var policyErr *compiler.PolicyError
+			if !errors.As(err, &policyErr) || policyErr.Policy != tt.policies[0].Name {
+				t.Errorf("expected *PolicyError for %q, got: %v", tt.policies[0].Name, err)
+			}
+		})
+	}
+}
+
+// TestCompile_InvalidPolicy verifies that policies with invalid CEL fail compilation.
+func TestCompile_InvalidPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app: 'demo'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: compiler.NewProviderRegistry(),
+		Policies:         []compiler.Policy{{Name: "broken", Expression: "data.app =="}},
+	})
+	if !result.HasErrors() {
+		t.Fatal("expected error for invalid policy expression")
+	}
+}
+
+// TestLoadPolicies verifies parsing and validation of policy files.
+func TestLoadPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: "policies:\n  - name: ssl\n    expr: data.db.ssl == true\n    severity: warning\n",
+			want:    1,
+		},
+		{name: "missing name", content: "policies:\n  - expr: 'true'\n", wantErr: true},
+		{name: "missing expr", content: "policies:\n  - name: x\n", wantErr: true},
+		{name: "bad severity", content: "policies:\n  - name: x\n    expr: 'true'\n    severity: fatal\n", wantErr: true},
+		{name: "invalid yaml", content: "policies: [\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policies.yaml")
+			if err := writeFile(path, tt.content); err != nil {
+				t.Fatalf("failed to write policy file: %v", err)
+			}
+
+			policies, err := compiler.LoadPolicies(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPolicies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(policies) != tt.want {
+				t.Errorf("LoadPolicies() returned %d policies, want %d", len(policies), tt.want)
+			}
+		})
+	}
+}
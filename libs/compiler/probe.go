@@ -0,0 +1,52 @@
+package compiler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/providers"
+)
+
+// ProviderProbe reports how a provider binary answered ProbeProvider.
+type ProviderProbe struct {
+	// Health is the status reported by the Health RPC: "ok", "degraded",
+	// "starting", or "unspecified".
+	Health string `json:"health"`
+
+	// HealthMessage is the message reported with Health.
+	HealthMessage string `json:"health_message,omitempty"`
+
+	// Type and Version are reported by the Info RPC; both are empty when
+	// the provider does not answer Info.
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// InitError is the error of the Init RPC, or nil when Init succeeded
+	// or was not requested.
+	InitError error `json:"-"`
+
+	// Stderr holds the end of what the provider wrote to stderr.
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// ProbeProvider starts the provider binary at binaryPath and checks that it
+// is ready to serve: it reads the port the provider reports, calls Health,
+// calls Init with opts when opts is non-nil so the provider validates its
+// configuration, calls Info, and shuts the provider down.
+//
+// An error is returned when the binary cannot be started or does not answer
+// Health; the returned probe's Stderr then holds what the provider wrote
+// before failing. Init failures are reported in InitError instead.
+func ProbeProvider(ctx context.Context, binaryPath string, opts *ProviderInitOptions) (ProviderProbe, error) {
+	result, err := providers.Probe(ctx, binaryPath, opts)
+	probe := ProviderProbe{InitError: result.InitErr, Stderr: result.Stderr}
+	if result.Health != nil {
+		probe.Health = strings.ToLower(strings.TrimPrefix(result.Health.GetStatus().String(), "STATUS_"))
+		probe.HealthMessage = result.Health.GetMessage()
+	}
+	if result.Info != nil {
+		probe.Type = result.Info.GetType()
+		probe.Version = result.Info.GetVersion()
+	}
+	return probe, err
+}
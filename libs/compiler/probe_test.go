@@ -0,0 +1,131 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestProbeHelperProcess serves a fake provider when run as a probed
+// provider binary.
+func TestProbeHelperProcess(t *testing.T) {
+	if os.Getenv("NOMOS_PROBE_HELPER") == "" {
+		return
+	}
+
+	fake := testutil.NewFakeProviderServer("", "1.2.0", "owner/repo")
+	switch os.Getenv("NOMOS_PROBE_HELPER") {
+	case "degraded":
+		fake.SetHealthStatus(providerv1.HealthResponse_STATUS_DEGRADED, "cache is cold")
+	case "bad-config":
+		fake.SetInitError(status.Error(codes.InvalidArgument, "missing required config 'directory'"))
+	case "crash":
+		fmt.Fprintln(os.Stderr, "panic: cannot load plugin")
+		os.Exit(2)
+	}
+
+	server, addr, err := testutil.StartFakeProviderServer(fake)
+	if err != nil {
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "provider listening")
+	fmt.Printf("PROVIDER_PORT=%s\n", addr[strings.LastIndex(addr, ":")+1:])
+
+	// Exit once the probe calls Shutdown, or is interrupted
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	for !fake.ShutdownCalled() {
+		select {
+		case <-stop:
+			os.Exit(0)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	server.Stop()
+	os.Exit(0)
+}
+
+// probeHelper writes a provider binary running TestProbeHelperProcess with
+// the given behavior.
+func probeHelper(t *testing.T, behavior string) string {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "provider")
+	script := fmt.Sprintf("#!/bin/sh\nNOMOS_PROBE_HELPER=%s exec %q -test.run=TestProbeHelperProcess\n", behavior, exe)
+	//nolint:gosec // G306: The provider script must be executable
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProbeProvider(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	opts := &compiler.ProviderInitOptions{Alias: "configs", Config: map[string]any{"directory": "./data"}}
+
+	t.Run("healthy", func(t *testing.T) {
+		probe, err := compiler.ProbeProvider(ctx, probeHelper(t, "ok"), opts)
+		if err != nil {
+			t.Fatalf("ProbeProvider() error = %v", err)
+		}
+		if probe.Health != "ok" || probe.Type != "owner/repo" || probe.Version != "1.2.0" || probe.InitError != nil {
+			t.Errorf("probe = %+v, want a healthy owner/repo 1.2.0", probe)
+		}
+		if !strings.Contains(probe.Stderr, "provider listening") {
+			t.Errorf("Stderr = %q, want the provider's output", probe.Stderr)
+		}
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		probe, err := compiler.ProbeProvider(ctx, probeHelper(t, "degraded"), nil)
+		if err != nil {
+			t.Fatalf("ProbeProvider() error = %v", err)
+		}
+		if probe.Health != "degraded" || probe.HealthMessage != "cache is cold" {
+			t.Errorf("Health = %q (%q), want degraded (cache is cold)", probe.Health, probe.HealthMessage)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		probe, err := compiler.ProbeProvider(ctx, probeHelper(t, "bad-config"), opts)
+		if err != nil {
+			t.Fatalf("ProbeProvider() error = %v", err)
+		}
+		if probe.InitError == nil || !strings.Contains(probe.InitError.Error(), "missing required config") {
+			t.Errorf("InitError = %v, want the provider's config error", probe.InitError)
+		}
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		probe, err := compiler.ProbeProvider(ctx, probeHelper(t, "crash"), opts)
+		if err == nil {
+			t.Fatal("ProbeProvider() succeeded for a crashing provider")
+		}
+		if !strings.Contains(probe.Stderr, "cannot load plugin") {
+			t.Errorf("Stderr = %q, want the crash output", probe.Stderr)
+		}
+	})
+
+	t.Run("missing binary", func(t *testing.T) {
+		_, err := compiler.ProbeProvider(ctx, filepath.Join(t.TempDir(), "missing"), nil)
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("ProbeProvider() error = %v, want not exist", err)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+)
+
+// ProfileSection is the top-level section declaring profiles: named sets of
+// sections merged over the rest of the data when selected through
+// Options.Profiles, for example
+//
+//	app:
+//	  replicas: 1
+//
+//	profile:
+//	  prod:
+//	    app:
+//	      replicas: 3
+const ProfileSection = "profile"
+
+// applyProfiles removes the profile section from data and deep-merges the
+// selected profiles over the remaining data in order, so later profiles
+// win. Keys a profile sets are attributed to the file declaring the profile
+// section. Profiles that are not selected are discarded before references
+// are resolved, so their references are never fetched.
+func applyProfiles(data map[string]any, profiles []string, provenance map[string]Provenance) error {
+	section, ok := data[ProfileSection]
+	source := provenance[ProfileSection].Source
+	delete(data, ProfileSection)
+	delete(provenance, ProfileSection)
+	if ordered, ok := data[converter.OrderedEntriesKey].([]converter.OrderedEntry); ok {
+		data[converter.OrderedEntriesKey] = slices.DeleteFunc(slices.Clone(ordered), func(entry converter.OrderedEntry) bool {
+			return !entry.Spread && entry.Key == ProfileSection
+		})
+	}
+
+	declared, _ := section.(map[string]any)
+	if ok && declared == nil {
+		return fmt.Errorf("%q section must map profile names to sections", ProfileSection)
+	}
+	if _, ok := declared[converter.OrderedEntriesKey]; ok {
+		return fmt.Errorf("%q section must not contain spreads", ProfileSection)
+	}
+
+	var unknown []string
+	for _, name := range profiles {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, strings.Join(unknown, ", "))
+	}
+
+	for _, name := range profiles {
+		sections, ok := declared[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("profile %q must contain sections", name)
+		}
+		if _, ok := sections[converter.OrderedEntriesKey]; ok {
+			return fmt.Errorf("profile %q must not spread directly into the root; place spreads inside a section", name)
+		}
+		for k := range sections {
+			provenance[k] = Provenance{Source: source}
+		}
+		converter.MergeValue(data, sections)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+const profilesSource = `app:
+  name: 'demo'
+  replicas: '1'
+
+profile:
+  prod:
+    app:
+      replicas: '3'
+    db:
+      host: @cfg:prod-host
+  staging:
+    app:
+      replicas: '2'
+      name: 'demo-staging'
+`
+
+// TestCompile_Profiles verifies that selected profiles merge over the data
+// in order, and that inactive profiles are dropped without fetching.
+func TestCompile_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, profilesSource); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		profiles  []string
+		want      map[string]any
+		wantFetch int
+	}{
+		{
+			name:     "none",
+			profiles: nil,
+			want:     map[string]any{"app": map[string]any{"name": "demo", "replicas": "1"}},
+		},
+		{
+			name:     "staging",
+			profiles: []string{"staging"},
+			want:     map[string]any{"app": map[string]any{"name": "demo-staging", "replicas": "2"}},
+		},
+		{
+			name:     "later profiles win",
+			profiles: []string{"staging", "prod"},
+			want: map[string]any{
+				"app": map[string]any{"name": "demo-staging", "replicas": "3"},
+				"db":  map[string]any{"host": "db.prod"},
+			},
+			wantFetch: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := testutil.NewFakeProvider("cfg")
+			provider.FetchResponses["prod-host"] = "db.prod"
+			registry := testutil.NewFakeProviderRegistry()
+			registry.AddProvider("cfg", provider)
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: registry,
+				Profiles:         tt.profiles,
+				SourceMap:        true,
+			})
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			if got := result.Snapshot.Data; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Data = %#v, want %#v", got, tt.want)
+			}
+			if provider.FetchCount != tt.wantFetch {
+				t.Errorf("FetchCount = %d, want %d", provider.FetchCount, tt.wantFetch)
+			}
+			if got := result.Snapshot.Metadata.Profiles; !reflect.DeepEqual(got, append([]string{}, tt.profiles...)) {
+				t.Errorf("Metadata.Profiles = %v, want %v", got, tt.profiles)
+			}
+			if _, ok := result.Snapshot.Metadata.PerKeyProvenance[compiler.ProfileSection]; ok {
+				t.Error("provenance keeps an entry for the profile section")
+			}
+
+			// Keys set by a profile point at its definition
+			wantLine := map[string]int{"none": 3, "staging": 13, "later profiles win": 8}[tt.name]
+			entry, ok := result.Snapshot.SourceMap.Lookup("app.replicas")
+			if !ok {
+				t.Fatal("source map has no entry for app.replicas")
+			}
+			if entry.Location.Line != wantLine {
+				t.Errorf("app.replicas line = %d, want %d", entry.Location.Line, wantLine)
+			}
+		})
+	}
+}
+
+// TestCompile_Profiles_Unknown verifies that selecting a profile no file
+// declares is an error.
+func TestCompile_Profiles_Unknown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: testutil.NewFakeProviderRegistry(),
+		Profiles:         []string{"prod"},
+	})
+	if !result.HasErrors() {
+		t.Fatal("expected an error for an undeclared profile")
+	}
+	if err := result.Error(); !errors.Is(err, compiler.ErrUnknownProfile) {
+		t.Errorf("error = %v, want %v", err, compiler.ErrUnknownProfile)
+	}
+}
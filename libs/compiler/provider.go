@@ -2,25 +2,12 @@ package compiler
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
 )
 
-// Sentinel errors for provider and compilation failures.
-var (
-	// ErrUnresolvedReference indicates a reference could not be resolved.
-	ErrUnresolvedReference = errors.New("unresolved reference")
-
-	// ErrCycleDetected indicates a cycle was detected in imports or references.
-	ErrCycleDetected = errors.New("cycle detected")
-
-	// ErrProviderNotRegistered indicates a provider alias is not registered.
-	ErrProviderNotRegistered = errors.New("provider not registered")
-)
-
 // Type aliases for backward compatibility with existing code.
 // These aliases allow external code to continue using compiler.Provider, etc.
 // while internally using the core package's definitions.
@@ -29,6 +16,12 @@ type (
 	Provider = core.Provider
 	// ProviderWithInfo extends Provider with metadata.
 	ProviderWithInfo = core.ProviderWithInfo
+	// ProviderWithDefaults extends Provider with published default values.
+	ProviderWithDefaults = core.ProviderWithDefaults
+	// BatchProvider extends Provider with fetching several paths per call.
+	BatchProvider = core.BatchProvider
+	// FetchResult is the outcome of one path of a batch fetch.
+	FetchResult = core.FetchResult
 	// ProviderInitOptions configures provider initialization.
 	ProviderInitOptions = core.ProviderInitOptions
 	// ProviderConstructor creates provider instances.
@@ -37,9 +30,16 @@ type (
 	ProviderRegistry = core.ProviderRegistry
 	// ProviderTypeRegistry manages provider type constructors.
 	ProviderTypeRegistry = core.ProviderTypeRegistry
+	// VersionedProviderTypeRegistry creates providers of a specific version.
+	VersionedProviderTypeRegistry = core.VersionedProviderTypeRegistry
+	// BinaryProviderTypeRegistry reports the binaries providers run from.
+	BinaryProviderTypeRegistry = core.BinaryProviderTypeRegistry
+	// SourceProviderRegistry registers source declaration providers atomically.
+	SourceProviderRegistry = core.SourceProviderRegistry
 )
 
-// providerRegistry is the default implementation of ProviderRegistry.
+// providerRegistry is the default implementation of ProviderRegistry. It
+// implements SourceProviderRegistry and is safe for concurrent use.
 type providerRegistry struct {
 	mu            sync.RWMutex
 	constructors  map[string]core.ProviderConstructor
@@ -98,12 +98,12 @@ func (r *providerRegistry) GetProvider(ctx context.Context, alias string) (core.
 
 	provider, err := constructor(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct provider %q: %w", alias, err)
+		return nil, fmt.Errorf("%w: failed to construct provider %q: %w", core.ErrProviderUnavailable, alias, err)
 	}
 
 	// Initialize provider with context from caller
 	if err := provider.Init(ctx, opts); err != nil {
-		return nil, fmt.Errorf("failed to initialize provider %q: %w", alias, err)
+		return nil, fmt.Errorf("%w: failed to initialize provider %q: %w", core.ErrProviderUnavailable, alias, err)
 	}
 
 	// Cache instance
@@ -112,6 +112,36 @@ func (r *providerRegistry) GetProvider(ctx context.Context, alias string) (core.
 	return provider, nil
 }
 
+// RegisterOnce implements SourceProviderRegistry.RegisterOnce. Holding the
+// instance lock while create runs ensures one provider per alias.
+func (r *providerRegistry) RegisterOnce(ctx context.Context, alias string, create func() (core.Provider, error)) (core.Provider, error) {
+	if instance, err := r.GetProvider(ctx, alias); err == nil {
+		return instance, nil
+	}
+
+	r.instanceMutex.Lock()
+	defer r.instanceMutex.Unlock()
+
+	// Another goroutine may have registered the alias in the meantime
+	if instance, ok := r.instances[alias]; ok {
+		return instance, nil
+	}
+
+	provider, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.constructors[alias] = func(_ core.ProviderInitOptions) (core.Provider, error) {
+		return provider, nil
+	}
+	r.mu.Unlock()
+	r.instances[alias] = provider
+
+	return provider, nil
+}
+
 // RegisteredAliases implements ProviderRegistry.RegisteredAliases.
 func (r *providerRegistry) RegisteredAliases() []string {
 	r.mu.RLock()
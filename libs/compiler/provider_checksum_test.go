@@ -0,0 +1,79 @@
+package compiler_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_ProviderChecksum verifies that a provider binary is started
+// only when it has the checksum its source declaration pins.
+func TestCompile_ProviderChecksum(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "provider")
+	content := []byte("provider binary")
+	if err := os.WriteFile(binary, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	other := "sha256:" + hex.EncodeToString(make([]byte, 32))
+
+	// A single file and a directory of files initialize providers
+	// separately
+	tests := []struct {
+		name     string
+		checksum string
+		files    int
+		wantErr  bool
+	}{
+		{name: "matching", checksum: checksum, files: 1},
+		{name: "differing", checksum: other, files: 1, wantErr: true},
+		{name: "matching in directory", checksum: checksum, files: 2},
+		{name: "differing in directory", checksum: other, files: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir()
+			source := "source:\n  alias: 'tool'\n  type: 'acme/tool'\n  checksum: '" + tt.checksum + "'\n\napp:\n  name: 'demo'\n"
+			if err := writeFile(filepath.Join(path, "app.csl"), source); err != nil {
+				t.Fatal(err)
+			}
+			if tt.files == 1 {
+				path = filepath.Join(path, "app.csl")
+			} else if err := writeFile(filepath.Join(path, "other.csl"), "other:\n  name: 'demo'\n"); err != nil {
+				t.Fatal(err)
+			}
+			manager := &countingManager{}
+			registry := compiler.NewProviderTypeRegistryWithResolver(&fakeResolver{entries: map[string]string{"acme/tool": binary}}, manager)
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: registry,
+			})
+			if !tt.wantErr {
+				if result.HasErrors() {
+					t.Fatalf("unexpected errors: %v", result.Error())
+				}
+				if len(manager.aliases) != 1 {
+					t.Errorf("started %v, want the tool provider", manager.aliases)
+				}
+				return
+			}
+			if err := result.Error(); !errors.Is(err, compiler.ErrProviderChecksumMismatch) {
+				t.Fatalf("error = %v, want ErrProviderChecksumMismatch", err)
+			}
+			if len(manager.aliases) != 0 {
+				t.Errorf("started %v, want no provider", manager.aliases)
+			}
+		})
+	}
+}
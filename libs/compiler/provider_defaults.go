@@ -0,0 +1,130 @@
+package compiler
+
+import (
+	"context"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// ProviderDefaultSource is the provenance source recorded for keys taken
+// from the default values a provider publishes (see ProviderWithDefaults).
+// Provenance.ProviderAlias names the publishing provider.
+const ProviderDefaultSource = "provider-default"
+
+// providerDefaults holds the default values published by one provider.
+type providerDefaults struct {
+	alias  string
+	values map[string]any
+}
+
+// defaultedValue is a value taken from provider defaults at keyPath.
+type defaultedValue struct {
+	keyPath string
+	value   any
+}
+
+// sourceAliases returns the aliases of the source declarations in files, in
+// declaration order.
+func sourceAliases(files []pipeline.ParsedFile) []string {
+	var aliases []string
+	for _, file := range files {
+		if file.AST == nil {
+			continue
+		}
+		for _, stmt := range file.AST.Statements {
+			if decl, ok := stmt.(*ast.SourceDecl); ok {
+				aliases = append(aliases, decl.Alias)
+			}
+		}
+	}
+	return aliases
+}
+
+// collectProviderDefaults returns the defaults published by the providers of
+// aliases, in order. Providers that publish none are skipped, as are those
+// that failed to initialize, whose errors were already reported.
+func collectProviderDefaults(ctx context.Context, registry ProviderRegistry, aliases []string) []providerDefaults {
+	var collected []providerDefaults
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		if seen[alias] {
+			continue
+		}
+		seen[alias] = true
+
+		provider, err := registry.GetProvider(ctx, alias)
+		if err != nil {
+			continue
+		}
+		withDefaults, ok := provider.(core.ProviderWithDefaults)
+		if !ok {
+			continue
+		}
+		if values := withDefaults.Defaults(); len(values) > 0 {
+			collected = append(collected, providerDefaults{alias: alias, values: values})
+		}
+	}
+	return collected
+}
+
+// applyProviderDefaults merges defaults beneath data in place: data wins
+// over every default, and the defaults of later sources win over those of
+// earlier ones. Maps merge key by key. Top-level keys taken from defaults are
+// attributed to ProviderDefaultSource; the returned values are every key path
+// taken from defaults, for the source map.
+func applyProviderDefaults(data map[string]any, defaults []providerDefaults, provenance map[string]Provenance) (map[string]any, []defaultedValue) {
+	if len(defaults) == 0 {
+		return data, nil
+	}
+	if data == nil {
+		data = make(map[string]any)
+	}
+
+	var added []defaultedValue
+	for i := len(defaults) - 1; i >= 0; i-- {
+		d := defaults[i]
+		for k := range d.values {
+			if _, ok := data[k]; !ok {
+				provenance[k] = Provenance{Source: ProviderDefaultSource, ProviderAlias: d.alias}
+			}
+		}
+		added = append(added, mergeBeneath(data, d.values, "")...)
+	}
+	return data, added
+}
+
+// mergeBeneath adds the entries of defaults that dst lacks, recursing where
+// both hold maps, and returns the key paths it added beneath prefix.
+// defaults is copied, so the provider's map is never aliased into dst.
+func mergeBeneath(dst, defaults map[string]any, prefix string) []defaultedValue {
+	var added []defaultedValue
+	for k, value := range defaults {
+		keyPath := joinKeyPath(prefix, k)
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = deepCopyValue(value)
+			added = append(added, defaultedValue{keyPath: keyPath, value: dst[k]})
+			continue
+		}
+		existingMap, existingIsMap := existing.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if existingIsMap && valueIsMap {
+			added = append(added, mergeBeneath(existingMap, valueMap, keyPath)...)
+		}
+	}
+	return added
+}
+
+// markProviderDefaults attributes the source map entries of values taken
+// from provider defaults to ProviderDefaultSource.
+func markProviderDefaults(sm *SourceMap, added []defaultedValue) {
+	if sm == nil {
+		return
+	}
+	entry := SourceMapEntry{Location: SourceLocation{File: ProviderDefaultSource}}
+	for _, v := range added {
+		markValue(sm, v.keyPath, v.value, entry)
+	}
+}
@@ -0,0 +1,179 @@
+package compiler_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// defaultsProvider publishes fixed defaults and serves no data.
+type defaultsProvider struct {
+	defaults map[string]any
+}
+
+func (p *defaultsProvider) Init(context.Context, compiler.ProviderInitOptions) error { return nil }
+
+func (p *defaultsProvider) Fetch(context.Context, []string) (any, error) {
+	return map[string]any{}, nil
+}
+
+func (p *defaultsProvider) Defaults() map[string]any { return p.defaults }
+
+// defaultsTypeRegistry registers provider types "db-defaults" and
+// "app-defaults" that publish the given defaults.
+func defaultsTypeRegistry(db, app map[string]any) compiler.ProviderTypeRegistry {
+	registry := compiler.NewProviderTypeRegistry()
+	registry.RegisterType("db-defaults", func(map[string]any) (compiler.Provider, error) {
+		return &defaultsProvider{defaults: db}, nil
+	})
+	registry.RegisterType("app-defaults", func(map[string]any) (compiler.Provider, error) {
+		return &defaultsProvider{defaults: app}, nil
+	})
+	return registry
+}
+
+// TestCompile_ProviderDefaults verifies that provider defaults fill in keys
+// beneath the compiled data and are attributed to ProviderDefaultSource.
+func TestCompile_ProviderDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	src := "source:\n  alias: 'db'\n  type: 'db-defaults'\n\n" +
+		"source:\n  alias: 'app'\n  type: 'app-defaults'\n\n" +
+		"database:\n  host: 'prod-db'\n  port: '6432'\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	dbDefaults := map[string]any{
+		"database": map[string]any{"port": "5432", "pool": map[string]any{"max": "10"}},
+		"logging":  map[string]any{"level": "info", "format": "text"},
+	}
+	appDefaults := map[string]any{
+		"logging": map[string]any{"format": "json"},
+		"tags":    []any{"managed"},
+	}
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 path,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: defaultsTypeRegistry(dbDefaults, appDefaults),
+		SourceMap:            true,
+		Overrides:            map[string]any{"logging": map[string]any{"level": "debug"}},
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	// Source data wins over defaults, later sources' defaults over earlier
+	// ones, and overrides over everything
+	want := map[string]any{
+		"database": map[string]any{"host": "prod-db", "port": "6432", "pool": map[string]any{"max": "10"}},
+		"logging":  map[string]any{"level": "debug", "format": "json"},
+		"tags":     []any{"managed"},
+	}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+
+	// The providers' maps are not aliased into the output
+	if _, ok := dbDefaults["database"].(map[string]any)["host"]; ok {
+		t.Error("provider defaults were mutated")
+	}
+
+	provenance := result.Snapshot.Metadata.PerKeyProvenance
+	for key, want := range map[string]compiler.Provenance{
+		"database": {Source: path},
+		"tags":     {Source: compiler.ProviderDefaultSource, ProviderAlias: "app"},
+		"logging":  {Source: compiler.OverrideSource},
+	} {
+		if got := provenance[key]; got != want {
+			t.Errorf("provenance[%s] = %+v, want %+v", key, got, want)
+		}
+	}
+
+	sm := result.Snapshot.SourceMap
+	for key, wantFile := range map[string]string{
+		"database.host":     path,
+		"database.port":     path,
+		"database.pool":     compiler.ProviderDefaultSource,
+		"database.pool.max": compiler.ProviderDefaultSource,
+		"logging.format":    compiler.ProviderDefaultSource,
+		"logging.level":     compiler.OverrideSource,
+		"tags[0]":           compiler.ProviderDefaultSource,
+	} {
+		entry, ok := sm.Lookup(key)
+		if !ok {
+			t.Errorf("source map has no entry for %q", key)
+			continue
+		}
+		if entry.Location.File != wantFile {
+			t.Errorf("source map %q file = %q, want %q", key, entry.Location.File, wantFile)
+		}
+	}
+}
+
+// TestCompile_ProviderDefaults_Directory verifies defaults when compiling
+// several files, where sources are declared across files.
+func TestCompile_ProviderDefaults_Directory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"01-sources.csl": "source:\n  alias: 'db'\n  type: 'db-defaults'\n",
+		"02-app.csl":     "database:\n  host: 'prod-db'\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 dir,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: defaultsTypeRegistry(map[string]any{"database": map[string]any{"host": "localhost", "port": "5432"}}, nil),
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{"database": map[string]any{"host": "prod-db", "port": "5432"}}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+}
+
+// TestCompile_ProviderDefaults_SharedReference verifies that defaults added
+// beneath one key leave other keys referencing the same provider path
+// unchanged, although both resolve from one cached provider value.
+func TestCompile_ProviderDefaults_SharedReference(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeFile(filepath.Join(dir, "shared.yaml"), "db:\n  host: prod-db\n"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app.csl")
+	src := "source:\n  alias: 'shared'\n  type: 'datafile'\n  path: './shared.yaml'\n\n" +
+		"source:\n  alias: 'db'\n  type: 'db-defaults'\n\n" +
+		"a: @shared:db\nb: @shared:db\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatal(err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 path,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: defaultsTypeRegistry(map[string]any{"a": map[string]any{"port": "5432"}}, nil),
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{
+		"a": map[string]any{"host": "prod-db", "port": "5432"},
+		"b": map[string]any{"host": "prod-db"},
+	}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+}
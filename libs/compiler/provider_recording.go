@@ -0,0 +1,451 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// ProviderRecordingVersion identifies the format of provider recording files.
+const ProviderRecordingVersion = 1
+
+// recordedSecretKey marks a secret value in a recording file, so replay
+// returns it as a secret again.
+const recordedSecretKey = "$secret"
+
+// ProviderRecording holds the responses of the providers used by a
+// compilation: what Fetch returned for each path and the defaults each
+// provider published. Record one with NewRecordingProviderTypeRegistry and
+// compile from it later with NewReplayProviderTypeRegistry, without
+// starting any provider.
+//
+// Recordings store fetched values, secrets included, in plain text. It is
+// safe for concurrent use.
+type ProviderRecording struct {
+	mu        sync.Mutex
+	providers map[string]*RecordedProvider
+}
+
+// RecordedProvider holds the recorded responses of one provider alias.
+type RecordedProvider struct {
+	Version  int             `json:"version"`
+	Alias    string          `json:"alias"`
+	Type     string          `json:"type"`
+	Defaults map[string]any  `json:"defaults,omitempty"`
+	Fetches  []RecordedFetch `json:"fetches"`
+	byPath   map[string]int  // index into Fetches by path key
+}
+
+// RecordedFetch is one recorded Fetch call. Exactly one of Value and Error
+// is meaningful: a fetch that failed replays as an error with the same
+// message.
+type RecordedFetch struct {
+	Path  []string `json:"path"`
+	Value any      `json:"value,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// NewProviderRecording returns an empty recording.
+func NewProviderRecording() *ProviderRecording {
+	return &ProviderRecording{providers: make(map[string]*RecordedProvider)}
+}
+
+// Provider returns the recorded responses of alias.
+func (r *ProviderRecording) Provider(alias string) (*RecordedProvider, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.providers[alias]
+	return p, ok
+}
+
+// Aliases returns the recorded provider aliases in sorted order.
+func (r *ProviderRecording) Aliases() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	aliases := make([]string, 0, len(r.providers))
+	for alias := range r.providers {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// provider returns the entry for alias, creating it on first use.
+func (r *ProviderRecording) provider(alias, typeName string) *RecordedProvider {
+	p, ok := r.providers[alias]
+	if !ok {
+		p = &RecordedProvider{Version: ProviderRecordingVersion, Alias: alias, Type: typeName, Fetches: []RecordedFetch{}}
+		r.providers[alias] = p
+	}
+	return p
+}
+
+func (r *ProviderRecording) recordDefaults(alias, typeName string, defaults map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.provider(alias, typeName).Defaults = recordedValue(defaults).(map[string]any)
+}
+
+func (r *ProviderRecording) recordFetch(alias, typeName string, path []string, value any, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.provider(alias, typeName)
+	fetch := RecordedFetch{Path: append([]string{}, path...)}
+	if err != nil {
+		fetch.Error = err.Error()
+	} else {
+		fetch.Value = recordedValue(value)
+	}
+	if p.byPath == nil {
+		p.byPath = make(map[string]int)
+	}
+	key := strings.Join(path, "\x00")
+	if i, ok := p.byPath[key]; ok {
+		p.Fetches[i] = fetch
+		return
+	}
+	p.byPath[key] = len(p.Fetches)
+	p.Fetches = append(p.Fetches, fetch)
+}
+
+// lookup returns the recorded fetch of path.
+func (p *RecordedProvider) lookup(path []string) (RecordedFetch, bool) {
+	if p.byPath == nil {
+		p.byPath = make(map[string]int, len(p.Fetches))
+		for i, f := range p.Fetches {
+			p.byPath[strings.Join(f.Path, "\x00")] = i
+		}
+	}
+	i, ok := p.byPath[strings.Join(path, "\x00")]
+	if !ok {
+		return RecordedFetch{}, false
+	}
+	return p.Fetches[i], true
+}
+
+// Save writes the recording to dir, one <alias>.json file per provider
+// with fetches sorted by path, creating dir if needed. A namespaced alias
+// such as "team1/shared" is written to team1/shared.json beneath dir. Files
+// are readable by the owner only, since they may hold secrets.
+func (r *ProviderRecording) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("cannot create recording directory: %w", err)
+	}
+	for _, alias := range r.Aliases() {
+		p, _ := r.Provider(alias)
+		r.mu.Lock()
+		fetches := append([]RecordedFetch{}, p.Fetches...)
+		out := RecordedProvider{Version: p.Version, Alias: p.Alias, Type: p.Type, Defaults: p.Defaults, Fetches: fetches}
+		r.mu.Unlock()
+		sort.Slice(out.Fetches, func(i, j int) bool {
+			return strings.Join(out.Fetches[i].Path, "\x00") < strings.Join(out.Fetches[j].Path, "\x00")
+		})
+
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("cannot encode recording of provider %q: %w", alias, err)
+		}
+		file := recordingFile(dir, alias)
+		if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+			return fmt.Errorf("cannot write recording of provider %q: %w", alias, err)
+		}
+		if err := os.WriteFile(file, append(data, '\n'), 0600); err != nil {
+			return fmt.Errorf("cannot write recording of provider %q: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// recordingFile returns the file Save writes the recording of alias to.
+// The segments of a namespaced alias become directories.
+func recordingFile(dir, alias string) string {
+	return filepath.Join(dir, filepath.FromSlash(alias)+".json")
+}
+
+// LoadProviderRecording reads a recording written by Save, including the
+// recordings of namespaced aliases in subdirectories of dir.
+func LoadProviderRecording(dir string) (*ProviderRecording, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("cannot read provider recording: %w", err)
+	}
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read provider recording: %w", err)
+	}
+
+	r := NewProviderRecording()
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: Path is inside the user's recording directory
+		if err != nil {
+			return nil, fmt.Errorf("cannot read provider recording: %w", err)
+		}
+		var p RecordedProvider
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("invalid provider recording %s: %w", path, err)
+		}
+		if p.Version != ProviderRecordingVersion {
+			return nil, fmt.Errorf("invalid provider recording %s: unsupported version %d (want %d)", path, p.Version, ProviderRecordingVersion)
+		}
+		if p.Alias == "" {
+			return nil, fmt.Errorf("invalid provider recording %s: missing alias", path)
+		}
+		r.providers[p.Alias] = &p
+	}
+	return r, nil
+}
+
+// recordedValue converts a provider value to its recorded form, marking
+// secrets with recordedSecretKey.
+func recordedValue(v any) any {
+	switch val := v.(type) {
+	case models.Secret:
+		return map[string]any{recordedSecretKey: recordedValue(val.Value)}
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = recordedValue(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = recordedValue(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// replayedValue reverses recordedValue.
+func replayedValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if secret, ok := val[recordedSecretKey]; ok && len(val) == 1 {
+			return models.Secret{Value: replayedValue(secret)}
+		}
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = replayedValue(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = replayedValue(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// NewRecordingProviderTypeRegistry returns a ProviderTypeRegistry that
+// creates providers through inner and records every response in rec.
+func NewRecordingProviderTypeRegistry(inner ProviderTypeRegistry, rec *ProviderRecording) ProviderTypeRegistry {
+	return &recordingTypeRegistry{ProviderTypeRegistry: inner, rec: rec}
+}
+
+type recordingTypeRegistry struct {
+	ProviderTypeRegistry
+	rec *ProviderRecording
+}
+
+// CreateProvider implements ProviderTypeRegistry.CreateProvider.
+func (r *recordingTypeRegistry) CreateProvider(ctx context.Context, typeName string, alias string, config map[string]any) (core.Provider, error) {
+	provider, err := r.ProviderTypeRegistry.CreateProvider(ctx, typeName, alias, config)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingProvider{provider: provider, alias: alias, typeName: typeName, rec: r.rec}, nil
+}
+
+// CreateVersionedProvider implements VersionedProviderTypeRegistry when the
+// inner registry does.
+func (r *recordingTypeRegistry) CreateVersionedProvider(ctx context.Context, typeName, version, alias string, config map[string]any) (core.Provider, error) {
+	versioned, ok := r.ProviderTypeRegistry.(core.VersionedProviderTypeRegistry)
+	if !ok {
+		return nil, fmt.Errorf("provider type registry cannot select version %s of %q", version, typeName)
+	}
+	provider, err := versioned.CreateVersionedProvider(ctx, typeName, version, alias, config)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingProvider{provider: provider, alias: alias, typeName: typeName, rec: r.rec}, nil
+}
+
+// ProviderBinary implements BinaryProviderTypeRegistry when the inner
+// registry does.
+func (r *recordingTypeRegistry) ProviderBinary(ctx context.Context, typeName, version string) (string, error) {
+	binaries, ok := r.ProviderTypeRegistry.(core.BinaryProviderTypeRegistry)
+	if !ok {
+		return "", fmt.Errorf("provider type registry cannot locate the binary of %q", typeName)
+	}
+	return binaries.ProviderBinary(ctx, typeName, version)
+}
+
+// recordingProvider delegates to a provider and records its responses.
+type recordingProvider struct {
+	provider core.Provider
+	alias    string
+	typeName string
+	rec      *ProviderRecording
+}
+
+// Init implements core.Provider. Defaults are published by Init, so they
+// are recorded once it succeeds.
+func (p *recordingProvider) Init(ctx context.Context, opts core.ProviderInitOptions) error {
+	if err := p.provider.Init(ctx, opts); err != nil {
+		return err
+	}
+	p.rec.recordDefaults(p.alias, p.typeName, p.Defaults())
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *recordingProvider) Fetch(ctx context.Context, path []string) (any, error) {
+	value, err := p.provider.Fetch(ctx, path)
+	// Cancellation says nothing about the provider, so it is not replayed
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		p.rec.recordFetch(p.alias, p.typeName, path, value, err)
+	}
+	return value, err
+}
+
+// Defaults implements core.ProviderWithDefaults.
+func (p *recordingProvider) Defaults() map[string]any {
+	if withDefaults, ok := p.provider.(core.ProviderWithDefaults); ok {
+		return withDefaults.Defaults()
+	}
+	return nil
+}
+
+// Info implements core.ProviderWithInfo.
+func (p *recordingProvider) Info() (string, string) {
+	if withInfo, ok := p.provider.(core.ProviderWithInfo); ok {
+		return withInfo.Info()
+	}
+	return p.alias, ""
+}
+
+// NewReplayProviderTypeRegistry returns a ProviderTypeRegistry that serves
+// every provider from rec. No provider is started or contacted: a source
+// whose alias is not in the recording, or whose type differs from the
+// recorded one, fails to create, and a fetch of an unrecorded path fails.
+func NewReplayProviderTypeRegistry(rec *ProviderRecording) ProviderTypeRegistry {
+	return &replayTypeRegistry{rec: rec}
+}
+
+type replayTypeRegistry struct {
+	rec *ProviderRecording
+}
+
+// RegisterType implements ProviderTypeRegistry.RegisterType. Replay serves
+// all types from the recording, so constructors are ignored.
+func (r *replayTypeRegistry) RegisterType(string, core.ProviderTypeConstructor) {}
+
+// IsTypeRegistered implements ProviderTypeRegistry.IsTypeRegistered.
+func (r *replayTypeRegistry) IsTypeRegistered(typeName string) bool {
+	for _, alias := range r.rec.Aliases() {
+		if p, _ := r.rec.Provider(alias); p.Type == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredTypes implements ProviderTypeRegistry.RegisteredTypes.
+func (r *replayTypeRegistry) RegisteredTypes() []string {
+	seen := map[string]bool{}
+	var types []string
+	for _, alias := range r.rec.Aliases() {
+		if p, _ := r.rec.Provider(alias); !seen[p.Type] {
+			seen[p.Type] = true
+			types = append(types, p.Type)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CreateProvider implements ProviderTypeRegistry.CreateProvider.
+func (r *replayTypeRegistry) CreateProvider(_ context.Context, typeName string, alias string, _ map[string]any) (core.Provider, error) {
+	p, ok := r.rec.Provider(alias)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %q is not in the recording", core.ErrProviderUnavailable, alias)
+	}
+	if p.Type != typeName {
+		return nil, fmt.Errorf("%w: provider %q was recorded with type %q, not %q", core.ErrProviderUnavailable, alias, p.Type, typeName)
+	}
+	return &replayProvider{recorded: p, rec: r.rec}, nil
+}
+
+// CreateVersionedProvider implements VersionedProviderTypeRegistry. Aliases
+// declared at several versions are recorded under their scoped registry
+// keys, so the alias alone selects the recording.
+func (r *replayTypeRegistry) CreateVersionedProvider(ctx context.Context, typeName, _ string, alias string, config map[string]any) (core.Provider, error) {
+	return r.CreateProvider(ctx, typeName, alias, config)
+}
+
+// ProviderBinary implements BinaryProviderTypeRegistry. Replayed providers
+// run no binary.
+func (r *replayTypeRegistry) ProviderBinary(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+// replayProvider answers fetches from a recording.
+type replayProvider struct {
+	recorded *RecordedProvider
+	rec      *ProviderRecording
+}
+
+// Init implements core.Provider.
+func (p *replayProvider) Init(context.Context, core.ProviderInitOptions) error {
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *replayProvider) Fetch(_ context.Context, path []string) (any, error) {
+	p.rec.mu.Lock()
+	fetch, ok := p.recorded.lookup(path)
+	p.rec.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("path %q of provider %q is not in the recording", strings.Join(path, "."), p.recorded.Alias)
+	}
+	if fetch.Error != "" {
+		return nil, errors.New(fetch.Error)
+	}
+	return replayedValue(fetch.Value), nil
+}
+
+// Defaults implements core.ProviderWithDefaults.
+func (p *replayProvider) Defaults() map[string]any {
+	if p.recorded.Defaults == nil {
+		return nil
+	}
+	return replayedValue(p.recorded.Defaults).(map[string]any)
+}
+
+// Info implements core.ProviderWithInfo.
+func (p *replayProvider) Info() (string, string) {
+	return p.recorded.Alias, ""
+}
@@ -0,0 +1,210 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// recordedFixtureProvider serves fixed data, including a secret, and
+// publishes defaults. It fails on every call once closed, so replays that
+// reach it are detected.
+type recordedFixtureProvider struct {
+	closed *bool
+}
+
+func (p *recordedFixtureProvider) Init(context.Context, core.ProviderInitOptions) error {
+	return nil
+}
+
+func (p *recordedFixtureProvider) Fetch(_ context.Context, path []string) (any, error) {
+	if *p.closed {
+		return nil, errors.New("provider contacted during replay")
+	}
+	switch strings.Join(path, ".") {
+	case "db":
+		return map[string]any{"host": "prod-db", "password": models.Secret{Value: "hunter2"}}, nil
+	case "regions":
+		return []any{"eu-west-1", "us-east-1"}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (p *recordedFixtureProvider) Defaults() map[string]any {
+	return map[string]any{"logging": map[string]any{"level": "info"}}
+}
+
+func TestProviderRecording_RecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.csl")
+	src := "source:\n  alias: 'infra'\n  type: 'fixture'\n\n" +
+		"app:\n  db: @infra:db\n  regions: @infra:regions\n"
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := false
+	inner := NewProviderTypeRegistry()
+	inner.RegisterType("fixture", func(map[string]any) (core.Provider, error) {
+		return &recordedFixtureProvider{closed: &closed}, nil
+	})
+
+	rec := NewProviderRecording()
+	recorded := Compile(context.Background(), Options{
+		Path:                 path,
+		ProviderRegistry:     NewProviderRegistry(),
+		ProviderTypeRegistry: NewRecordingProviderTypeRegistry(inner, rec),
+	})
+	if recorded.HasErrors() {
+		t.Fatalf("unexpected errors: %v", recorded.Error())
+	}
+
+	recordingDir := filepath.Join(dir, "recording")
+	if err := rec.Save(recordingDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(recordingDir, "infra.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("recording mode = %v, want 0600", mode)
+	}
+
+	loaded, err := LoadProviderRecording(recordingDir)
+	if err != nil {
+		t.Fatalf("LoadProviderRecording() error = %v", err)
+	}
+	p, ok := loaded.Provider("infra")
+	if !ok {
+		t.Fatal("recording has no provider infra")
+	}
+	if p.Type != "fixture" || len(p.Fetches) != 2 {
+		t.Errorf("recorded provider = %+v, want type fixture with 2 fetches", p)
+	}
+
+	closed = true
+	replayed := Compile(context.Background(), Options{
+		Path:                 path,
+		ProviderRegistry:     NewProviderRegistry(),
+		ProviderTypeRegistry: NewReplayProviderTypeRegistry(loaded),
+	})
+	if replayed.HasErrors() {
+		t.Fatalf("unexpected replay errors: %v", replayed.Error())
+	}
+	if !reflect.DeepEqual(replayed.Snapshot.Data, recorded.Snapshot.Data) {
+		t.Errorf("replayed data = %#v, want %#v", replayed.Snapshot.Data, recorded.Snapshot.Data)
+	}
+}
+
+// TestProviderRecording_NamespacedAlias verifies that a namespaced alias is
+// saved beneath a directory per namespace and replays from there.
+func TestProviderRecording_NamespacedAlias(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.csl")
+	src := "source:\n  alias: 'team1/shared'\n  type: 'fixture'\n\n" +
+		"app:\n  regions: @team1/shared:regions\n"
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := false
+	inner := NewProviderTypeRegistry()
+	inner.RegisterType("fixture", func(map[string]any) (core.Provider, error) {
+		return &recordedFixtureProvider{closed: &closed}, nil
+	})
+
+	rec := NewProviderRecording()
+	recorded := Compile(context.Background(), Options{
+		Path:                 path,
+		ProviderRegistry:     NewProviderRegistry(),
+		ProviderTypeRegistry: NewRecordingProviderTypeRegistry(inner, rec),
+	})
+	if recorded.HasErrors() {
+		t.Fatalf("unexpected errors: %v", recorded.Error())
+	}
+
+	recordingDir := filepath.Join(dir, "recording")
+	if err := rec.Save(recordingDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(recordingDir, "team1", "shared.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProviderRecording(recordingDir)
+	if err != nil {
+		t.Fatalf("LoadProviderRecording() error = %v", err)
+	}
+	closed = true
+	replayed := Compile(context.Background(), Options{
+		Path:                 path,
+		ProviderRegistry:     NewProviderRegistry(),
+		ProviderTypeRegistry: NewReplayProviderTypeRegistry(loaded),
+	})
+	if replayed.HasErrors() {
+		t.Fatalf("unexpected replay errors: %v", replayed.Error())
+	}
+	if !reflect.DeepEqual(replayed.Snapshot.Data, recorded.Snapshot.Data) {
+		t.Errorf("replayed data = %#v, want %#v", replayed.Snapshot.Data, recorded.Snapshot.Data)
+	}
+}
+
+func TestProviderRecording_SecretsRoundTrip(t *testing.T) {
+	value := map[string]any{
+		"password": models.Secret{Value: "hunter2"},
+		"tokens":   []any{models.Secret{Value: "a"}, "plain"},
+	}
+	if got := replayedValue(recordedValue(value)); !reflect.DeepEqual(got, value) {
+		t.Errorf("round trip = %#v, want %#v", got, value)
+	}
+}
+
+func TestReplayProviderTypeRegistry_Errors(t *testing.T) {
+	rec := NewProviderRecording()
+	rec.recordFetch("infra", "fixture", []string{"db"}, "value", nil)
+	rec.recordFetch("infra", "fixture", []string{"gone"}, nil, errors.New("not found"))
+	registry := NewReplayProviderTypeRegistry(rec)
+	ctx := context.Background()
+
+	if _, err := registry.CreateProvider(ctx, "fixture", "other", nil); !errors.Is(err, ErrProviderUnavailable) || !strings.Contains(err.Error(), "not in the recording") {
+		t.Errorf("unknown alias error = %v", err)
+	}
+	if _, err := registry.CreateProvider(ctx, "http", "infra", nil); err == nil || !strings.Contains(err.Error(), `recorded with type "fixture"`) {
+		t.Errorf("type mismatch error = %v", err)
+	}
+
+	provider, err := registry.CreateProvider(ctx, "fixture", "infra", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := provider.Fetch(ctx, []string{"db"}); err != nil || got != "value" {
+		t.Errorf("Fetch(db) = %v, %v", got, err)
+	}
+	if _, err := provider.Fetch(ctx, []string{"gone"}); err == nil || err.Error() != "not found" {
+		t.Errorf("Fetch(gone) error = %v, want recorded error", err)
+	}
+	if _, err := provider.Fetch(ctx, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "not in the recording") {
+		t.Errorf("Fetch(missing) error = %v", err)
+	}
+}
+
+func TestLoadProviderRecording_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "infra.json"), []byte(`{"version": 9, "alias": "infra"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProviderRecording(dir); err == nil || !strings.Contains(err.Error(), "unsupported version") {
+		t.Errorf("error = %v, want unsupported version", err)
+	}
+	if _, err := LoadProviderRecording(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
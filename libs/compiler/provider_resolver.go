@@ -14,6 +14,18 @@ type ProviderResolver interface {
 	ResolveBinaryPath(ctx context.Context, providerType string) (string, error)
 }
 
+// VersionedProviderResolver is implemented by ProviderResolvers that can
+// select among several installed versions of one provider type. The type
+// registry uses it for types that source declarations pin at more than one
+// version.
+type VersionedProviderResolver interface {
+	ProviderResolver
+
+	// ResolveVersionedBinaryPath returns the absolute path to the binary of
+	// the given version of the provider type.
+	ResolveVersionedBinaryPath(ctx context.Context, providerType, version string) (string, error)
+}
+
 // ProviderManager manages the lifecycle of external provider subprocesses.
 // This interface abstracts the providerproc.Manager to avoid import cycles.
 type ProviderManager interface {
@@ -0,0 +1,116 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// nameProvider serves its configured name for every path.
+type nameProvider struct {
+	name any
+}
+
+func (p *nameProvider) Init(context.Context, compiler.ProviderInitOptions) error { return nil }
+
+func (p *nameProvider) Fetch(context.Context, []string) (any, error) { return p.name, nil }
+
+// versionRecordingRegistry records the versions providers are created at.
+type versionRecordingRegistry struct {
+	compiler.VersionedProviderTypeRegistry
+	created map[string]string // registry key → version
+}
+
+func (r *versionRecordingRegistry) CreateVersionedProvider(ctx context.Context, typeName, version, alias string, config map[string]any) (compiler.Provider, error) {
+	r.created[alias] = version
+	return r.VersionedProviderTypeRegistry.CreateVersionedProvider(ctx, typeName, version, alias, config)
+}
+
+// TestCompile_ProviderVersionScopes verifies that an alias declared at
+// different versions in different files gets one provider per version and
+// that references use the declaration of their own file, else the first of
+// their directory.
+func TestCompile_ProviderVersionScopes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'cfg'\n  type: 'named'\n  version: '1.0.0'\n  name: 'one'\n\na:\n  value: @cfg:value\n",
+		"b.csl": "source:\n  alias: 'cfg'\n  type: 'named'\n  version: '2.0.0'\n  name: 'two'\n\nb:\n  value: @cfg:value\n",
+		"c.csl": "c:\n  value: @cfg:value\n",
+	}
+	for name, content := range files {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inner := compiler.NewProviderTypeRegistry()
+	inner.RegisterType("named", func(config map[string]any) (compiler.Provider, error) {
+		return &nameProvider{name: config["name"]}, nil
+	})
+	registry := &versionRecordingRegistry{
+		VersionedProviderTypeRegistry: inner.(compiler.VersionedProviderTypeRegistry),
+		created:                       map[string]string{},
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 dir,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: registry,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{
+		"a": map[string]any{"value": "one"},
+		"b": map[string]any{"value": "two"},
+		"c": map[string]any{"value": "one"},
+	}
+	if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("Data = %#v, want %#v", got, want)
+	}
+
+	wantCreated := map[string]string{"cfg": "1.0.0", "cfg@2.0.0": "2.0.0"}
+	if !reflect.DeepEqual(registry.created, wantCreated) {
+		t.Errorf("created = %v, want %v", registry.created, wantCreated)
+	}
+
+	for _, alias := range []string{"cfg", "cfg@2.0.0"} {
+		if !slices.Contains(result.Snapshot.Metadata.ProviderAliases, alias) {
+			t.Errorf("ProviderAliases = %v, want it to contain %q", result.Snapshot.Metadata.ProviderAliases, alias)
+		}
+	}
+}
+
+// TestCompile_ProviderVersionScopes_Unversioned verifies that registries
+// that cannot select versions report an error instead of running one
+// version for every declaration.
+func TestCompile_ProviderVersionScopes_Unversioned(t *testing.T) {
+	dir := t.TempDir()
+	for name, version := range map[string]string{"a.csl": "1.0.0", "b.csl": "2.0.0"} {
+		src := "source:\n  alias: 'cfg'\n  type: 'named'\n  version: '" + version + "'\n"
+		if err := writeFile(filepath.Join(dir, name), src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 dir,
+		ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+		ProviderTypeRegistry: unversionedRegistry{compiler.NewProviderTypeRegistry()},
+	})
+	if !result.HasErrors() {
+		t.Fatal("expected an error for a registry without version support")
+	}
+}
+
+// unversionedRegistry hides the VersionedProviderTypeRegistry methods of
+// the registry it wraps.
+type unversionedRegistry struct {
+	compiler.ProviderTypeRegistry
+}
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -19,6 +20,13 @@ type providerTypeRegistry struct {
 	constructors map[string]core.ProviderTypeConstructor
 	resolver     ProviderResolver // optional: resolves types to binary paths
 	manager      ProviderManager  // optional: manages provider subprocesses
+
+	// instances maps a binary path plus canonical config to the first remote
+	// provider started for it, so aliases with identical type, version, and
+	// config share one subprocess. startMu serializes starting them, so
+	// concurrent compilations never start a process twice.
+	startMu   sync.Mutex
+	instances map[string]*sharedInstance
 }
 
 // NewProviderTypeRegistry creates a new ProviderTypeRegistry.
@@ -38,6 +46,7 @@ func NewProviderTypeRegistryWithResolver(resolver ProviderResolver, manager Prov
 		constructors: make(map[string]core.ProviderTypeConstructor),
 		resolver:     resolver,
 		manager:      manager,
+		instances:    make(map[string]*sharedInstance),
 	}
 }
 
@@ -55,6 +64,7 @@ func NewProviderTypeRegistryWithLockfile(resolver ProviderResolver) core.Provide
 		constructors: make(map[string]core.ProviderTypeConstructor),
 		resolver:     resolver,
 		manager:      manager,
+		instances:    make(map[string]*sharedInstance),
 	}
 }
 
@@ -68,6 +78,19 @@ func (r *providerTypeRegistry) RegisterType(typeName string, constructor core.Pr
 
 // CreateProvider implements ProviderTypeRegistry.CreateProvider.
 func (r *providerTypeRegistry) CreateProvider(ctx context.Context, typeName string, alias string, config map[string]any) (core.Provider, error) {
+	return r.createProvider(ctx, typeName, "", alias, config)
+}
+
+// CreateVersionedProvider implements VersionedProviderTypeRegistry. In-process
+// constructors serve every version; remote providers need a resolver that
+// implements VersionedProviderResolver.
+func (r *providerTypeRegistry) CreateVersionedProvider(ctx context.Context, typeName, version, alias string, config map[string]any) (core.Provider, error) {
+	return r.createProvider(ctx, typeName, version, alias, config)
+}
+
+// createProvider creates a provider of typeName, using the binary of
+// version when it is not empty.
+func (r *providerTypeRegistry) createProvider(ctx context.Context, typeName, version, alias string, config map[string]any) (core.Provider, error) {
 	// First, check for in-process constructor
 	r.mu.RLock()
 	constructor, hasConstructor := r.constructors[typeName]
@@ -85,7 +108,7 @@ func (r *providerTypeRegistry) CreateProvider(ctx context.Context, typeName stri
 
 	// Fall back to remote provider if resolver+manager available
 	if hasResolver {
-		binaryPath, err := r.resolver.ResolveBinaryPath(ctx, typeName)
+		binaryPath, err := r.resolveBinaryPath(ctx, typeName, version)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve provider type %q: %w", typeName, err)
 		}
@@ -96,12 +119,29 @@ func (r *providerTypeRegistry) CreateProvider(ctx context.Context, typeName stri
 			Config: config,
 		}
 
-		provider, err := r.manager.GetProvider(ctx, alias, binaryPath, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start remote provider %q (alias %q): %w", typeName, alias, err)
+		// Reuse the process of an earlier alias with the same binary and config
+		key, shareable := instanceKey(binaryPath, config)
+		if !shareable {
+			provider, err := r.manager.GetProvider(ctx, alias, binaryPath, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start remote provider %q (alias %q): %w", typeName, alias, err)
+			}
+			return provider, nil
 		}
 
-		return provider, nil
+		r.startMu.Lock()
+		defer r.startMu.Unlock()
+		instance, ok := r.instances[key]
+		if !ok {
+			provider, err := r.manager.GetProvider(ctx, alias, binaryPath, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start remote provider %q (alias %q): %w", typeName, alias, err)
+			}
+			instance = &sharedInstance{provider: provider}
+			r.instances[key] = instance
+		}
+
+		return &sharedProvider{instance: instance, alias: alias}, nil
 	}
 
 	// No constructor or resolver available
@@ -112,6 +152,40 @@ func (r *providerTypeRegistry) CreateProvider(ctx context.Context, typeName stri
 		"See migration guide: https://github.com/autonomous-bits/nomos/blob/main/docs/guides/external-providers-migration.md", typeName)
 }
 
+// ProviderBinary implements BinaryProviderTypeRegistry. Types with an
+// in-process constructor run without a binary.
+func (r *providerTypeRegistry) ProviderBinary(ctx context.Context, typeName, version string) (string, error) {
+	r.mu.RLock()
+	_, hasConstructor := r.constructors[typeName]
+	hasResolver := r.resolver != nil && r.manager != nil
+	r.mu.RUnlock()
+
+	switch {
+	case hasConstructor:
+		return "", nil
+	case !hasResolver:
+		return "", fmt.Errorf("provider type %q not found", typeName)
+	}
+	binaryPath, err := r.resolveBinaryPath(ctx, typeName, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve provider type %q: %w", typeName, err)
+	}
+	return binaryPath, nil
+}
+
+// resolveBinaryPath returns the binary of typeName, of the given version
+// when it is not empty.
+func (r *providerTypeRegistry) resolveBinaryPath(ctx context.Context, typeName, version string) (string, error) {
+	if version == "" {
+		return r.resolver.ResolveBinaryPath(ctx, typeName)
+	}
+	versioned, ok := r.resolver.(VersionedProviderResolver)
+	if !ok {
+		return "", fmt.Errorf("provider resolver cannot select version %s", version)
+	}
+	return versioned.ResolveVersionedBinaryPath(ctx, typeName, version)
+}
+
 // IsTypeRegistered implements ProviderTypeRegistry.IsTypeRegistered.
 func (r *providerTypeRegistry) IsTypeRegistered(typeName string) bool {
 	r.mu.RLock()
@@ -133,3 +207,76 @@ func (r *providerTypeRegistry) RegisteredTypes() []string {
 
 	return types
 }
+
+// instanceKey returns the key under which a remote provider instance is
+// shared. encoding/json sorts map keys, so equal configs yield equal keys.
+// The second result is false when the config cannot be encoded, in which
+// case the provider gets its own process.
+func instanceKey(binaryPath string, config map[string]any) (string, bool) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", false
+	}
+	return binaryPath + "\x00" + string(data), true
+}
+
+// sharedInstance is a remote provider process shared by the aliases with
+// identical binary and config. It is initialized by the first Init to
+// succeed; concurrent Init calls wait for it.
+type sharedInstance struct {
+	provider core.Provider
+
+	mu          sync.Mutex
+	initialized bool
+}
+
+// init initializes the shared process unless it already is.
+func (s *sharedInstance) init(ctx context.Context, opts core.ProviderInitOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initialized {
+		return nil
+	}
+	if err := s.provider.Init(ctx, opts); err != nil {
+		return err
+	}
+	s.initialized = true
+	return nil
+}
+
+// sharedProvider exposes a remote provider process for one alias. Init
+// initializes the process only once across the aliases sharing it, so no
+// alias fetches from it before it is initialized; Fetch delegates to the
+// shared instance.
+type sharedProvider struct {
+	instance *sharedInstance
+	alias    string
+}
+
+// Init implements core.Provider.
+func (p *sharedProvider) Init(ctx context.Context, opts core.ProviderInitOptions) error {
+	return p.instance.init(ctx, opts)
+}
+
+// Fetch implements core.Provider.
+func (p *sharedProvider) Fetch(ctx context.Context, path []string) (any, error) {
+	return p.instance.provider.Fetch(ctx, path)
+}
+
+// Defaults implements core.ProviderWithDefaults.
+func (p *sharedProvider) Defaults() map[string]any {
+	if withDefaults, ok := p.instance.provider.(core.ProviderWithDefaults); ok {
+		return withDefaults.Defaults()
+	}
+	return nil
+}
+
+// Info implements core.ProviderWithInfo, reporting this alias rather than
+// the alias that started the shared process.
+func (p *sharedProvider) Info() (string, string) {
+	var version string
+	if withInfo, ok := p.instance.provider.(core.ProviderWithInfo); ok {
+		_, version = withInfo.Info()
+	}
+	return p.alias, version
+}
@@ -2,6 +2,10 @@ package compiler_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
@@ -66,13 +70,140 @@ func TestProviderTypeRegistry_CreateRemoteProvider(t *testing.T) {
 	})
 }
 
+// TestProviderTypeRegistry_SharesIdenticalInstances verifies that aliases with
+// the same provider type and config share one remote process.
+func TestProviderTypeRegistry_SharesIdenticalInstances(t *testing.T) {
+	resolver := &fakeResolver{
+		entries: map[string]string{
+			"file": "/fake/path/to/provider",
+		},
+	}
+	manager := &countingManager{}
+	registry := compiler.NewProviderTypeRegistryWithResolver(resolver, manager)
+	ctx := context.Background()
+
+	config := map[string]any{"directory": "./shared", "format": "csl"}
+	first, err := registry.CreateProvider(ctx, "file", "team1/configs", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := registry.CreateProvider(ctx, "file", "team2/configs",
+		map[string]any{"format": "csl", "directory": "./shared"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := registry.CreateProvider(ctx, "file", "other", map[string]any{"directory": "./other"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := manager.aliases; len(got) != 2 || got[0] != "team1/configs" || got[1] != "other" {
+		t.Fatalf("expected processes for [team1/configs other], got %v", got)
+	}
+
+	// The shared instance must not be initialized a second time
+	if err := first.Init(ctx, core.ProviderInitOptions{Alias: "team1/configs"}); err != nil {
+		t.Fatalf("unexpected Init error: %v", err)
+	}
+	if err := second.Init(ctx, core.ProviderInitOptions{Alias: "team2/configs"}); err != nil {
+		t.Fatalf("unexpected Init error: %v", err)
+	}
+	if got := manager.providers[0].inits; got != 1 {
+		t.Errorf("expected the shared instance to be initialized once, underlying Init called %d times", got)
+	}
+
+	value, err := second.Fetch(ctx, []string{"any"})
+	if err != nil {
+		t.Fatalf("unexpected Fetch error: %v", err)
+	}
+	if value.(map[string]any)["test"] != "data" {
+		t.Errorf("expected Fetch to delegate to shared instance, got %v", value)
+	}
+
+	info, ok := second.(core.ProviderWithInfo)
+	if !ok {
+		t.Fatal("expected shared provider to implement ProviderWithInfo")
+	}
+	if alias, _ := info.Info(); alias != "team2/configs" {
+		t.Errorf("expected Info alias %q, got %q", "team2/configs", alias)
+	}
+}
+
+// countingManager records the aliases for which a process was started.
+type countingManager struct {
+	mu        sync.Mutex
+	aliases   []string
+	providers []*fakeProvider
+}
+
+func (m *countingManager) GetProvider(_ context.Context, alias string, _ string, _ core.ProviderInitOptions) (core.Provider, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.aliases = append(m.aliases, alias)
+	provider := &fakeProvider{}
+	m.providers = append(m.providers, provider)
+	return provider, nil
+}
+
+func (m *countingManager) Shutdown(_ context.Context) error {
+	return nil
+}
+
 // fakeProvider implements core.Provider for testing.
-type fakeProvider struct{}
+type fakeProvider struct {
+	inits       int
+	initialized atomic.Bool
+}
 
 func (f *fakeProvider) Init(_ context.Context, _ core.ProviderInitOptions) error {
+	f.inits++
+	f.initialized.Store(true)
 	return nil
 }
 
 func (f *fakeProvider) Fetch(_ context.Context, _ []string) (any, error) {
+	if !f.initialized.Load() {
+		return nil, errors.New("fetch before init")
+	}
 	return map[string]any{"test": "data"}, nil
 }
+
+// TestProviderTypeRegistry_ConcurrentSharedInstances verifies that
+// concurrent compilations declaring the same remote provider start one
+// process, initialize it once, and never fetch before it is initialized.
+func TestProviderTypeRegistry_ConcurrentSharedInstances(t *testing.T) {
+	resolver := &fakeResolver{
+		entries: map[string]string{
+			"file": "/fake/path/to/provider",
+		},
+	}
+	manager := &countingManager{}
+	registry := compiler.NewProviderTypeRegistryWithResolver(resolver, manager)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			alias := fmt.Sprintf("configs%d", i)
+			provider, err := registry.CreateProvider(ctx, "file", alias, map[string]any{"directory": "./shared"})
+			if err != nil {
+				t.Errorf("CreateProvider(%s) error: %v", alias, err)
+				return
+			}
+			if err := provider.Init(ctx, core.ProviderInitOptions{Alias: alias}); err != nil {
+				t.Errorf("Init(%s) error: %v", alias, err)
+				return
+			}
+			if _, err := provider.Fetch(ctx, []string{"any"}); err != nil {
+				t.Errorf("Fetch(%s) error: %v", alias, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(manager.providers) != 1 {
+		t.Fatalf("started %d processes, want 1", len(manager.providers))
+	}
+	if got := manager.providers[0].inits; got != 1 {
+		t.Errorf("shared process initialized %d times, want 1", got)
+	}
+}
@@ -1,10 +1,10 @@
 package compiler
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/autonomous-bits/nomos/libs/compiler/pkg/value"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
@@ -44,13 +44,12 @@ func (m ReferenceMode) String() string {
 
 // ResolvedReference represents the result of resolving a reference expression during compilation.
 //
-// The Mode field determines which of Value or Entries is populated:
-//   - PropertyMode: Value contains the single resolved value, Entries is nil
-//   - MapMode/RootMode: Entries contains the resolved map, Value is nil
+// Value keeps the provider's types (an integer stays an integer). For
+// MapMode and RootMode it is always a map whose entries are merged into the
+// referencing map; for PropertyMode it is the single resolved value.
 type ResolvedReference struct {
-	Mode    ReferenceMode  // Resolution mode (determines which field is populated)
-	Value   ast.Expr       // For PropertyMode: the single resolved value
-	Entries []ast.MapEntry // For Root/MapMode: resolved entries to merge
+	Mode  ReferenceMode // Resolution mode
+	Value value.Value   // Resolved value; a map for MapMode and RootMode
 }
 
 // ResolutionContext tracks active resolution stack to detect circular references.
@@ -83,7 +82,7 @@ func (ctx *ResolutionContext) Push(alias string, path []string) error {
 	// Check for cycle
 	for _, existing := range ctx.Stack {
 		if existing == ref {
-			return fmt.Errorf("%w: %s", ErrCircularReference, ctx.formatCycle(ref))
+			return &CycleError{Chain: ctx.cycleChain(ref)}
 		}
 	}
 
@@ -109,12 +108,19 @@ func (ctx *ResolutionContext) Pop() {
 //
 // Example: "base:app → base:common → base:app"
 func (ctx *ResolutionContext) formatCycle(ref PathRef) string {
+	return strings.Join(ctx.cycleChain(ref), " → ")
+}
+
+// cycleChain returns the resolution stack followed by ref, forming the cycle
+// reported in CycleError.
+//
+// Example: ["base:app", "base:common", "base:app"]
+func (ctx *ResolutionContext) cycleChain(ref PathRef) []string {
 	parts := make([]string, 0, len(ctx.Stack)+1)
 	for _, r := range ctx.Stack {
 		parts = append(parts, r.String())
 	}
-	parts = append(parts, ref.String())
-	return strings.Join(parts, " → ")
+	return append(parts, ref.String())
 }
 
 func pathKey(path []string) string {
@@ -124,21 +130,6 @@ func pathKey(path []string) string {
 	return strings.Join(path, ":")
 }
 
-// Sentinel errors for reference resolution failures.
-var (
-	// ErrAliasNotFound indicates a source alias is not configured.
-	ErrAliasNotFound = errors.New("alias not found")
-
-	// ErrPathNotFound indicates a provider cannot resolve the path.
-	ErrPathNotFound = errors.New("path not found")
-
-	// ErrPropertyPathInvalid indicates a property path does not exist in the data.
-	ErrPropertyPathInvalid = errors.New("property path invalid")
-
-	// ErrCircularReference indicates a cycle was detected in the resolution chain.
-	ErrCircularReference = errors.New("circular reference detected")
-)
-
 // DetermineReferenceMode determines the resolution mode for a reference based on
 // its path and the structure of the resolved data.
 //
@@ -181,7 +172,7 @@ func ResolveReference(ref *ast.ReferenceExpr, resourceData map[string]any, resCt
 	// Detect circular references by tracking this path in the resolution stack
 	if err := resCtx.Push(ref.Alias, ref.Path); err != nil {
 		// T089: Include source span in circular reference errors
-		return nil, formatReferenceError(ref, "", err)
+		return nil, formatReferenceError(ref, ref.Path, err)
 	}
 	defer resCtx.Pop()
 
@@ -199,70 +190,70 @@ func ResolveReference(ref *ast.ReferenceExpr, resourceData map[string]any, resCt
 
 	switch mode {
 	case RootMode:
-		// Convert entire provider data to AST expressions
+		// Convert entire provider data
 		// T084: Empty provider data is valid - returns empty map
-		entries, err := convertToASTExprs(resourceData)
+		v, err := value.Of(mapOrEmpty(resourceData))
 		if err != nil {
 			// T087: Wrap provider errors with full context (alias, path, operation)
 			// T089: Include source span
-			return nil, formatReferenceError(ref, "root",
+			return nil, formatReferenceError(ref, nil,
 				fmt.Errorf("failed to convert root data for alias %q: %w",
 					ref.Alias, err))
 		}
-		resolved.Entries = entries
+		resolved.Value = v
 
 	case MapMode:
 		// Navigate to the map at the path
-		value, err := navigatePath(resourceData, pathForNavigation)
+		data, err := navigatePath(resourceData, pathForNavigation)
 		if err != nil {
 			// T087: Wrap errors with alias, path context
 			// T089: Include source span
-			return nil, formatReferenceError(ref, strings.Join(pathForNavigation, "."),
+			return nil, formatReferenceError(ref, pathForNavigation,
 				fmt.Errorf("failed to navigate in alias %q: %w",
 					ref.Alias, err))
 		}
 
 		// Type assert to map
-		mapValue, ok := value.(map[string]any)
+		mapValue, ok := data.(map[string]any)
 		if !ok {
 			// T089: Include source span for type mismatch
-			return nil, formatReferenceError(ref, strings.Join(pathForNavigation, "."),
+			return nil, formatReferenceError(ref, pathForNavigation,
 				fmt.Errorf("expected map at alias %q path %q, got %T",
-					ref.Alias, strings.Join(pathForNavigation, "."), value))
+					ref.Alias, strings.Join(pathForNavigation, "."), data))
 		}
 
-		// Convert map to AST expressions
-		entries, err := convertToASTExprs(mapValue)
+		// Convert map data
+		v, err := value.Of(mapOrEmpty(mapValue))
 		if err != nil {
 			// T087: Include alias and path in conversion errors
 			// T089: Include source span
-			return nil, formatReferenceError(ref, strings.Join(pathForNavigation, "."),
+			return nil, formatReferenceError(ref, pathForNavigation,
 				fmt.Errorf("failed to convert map data for alias %q: %w",
 					ref.Alias, err))
 		}
-		resolved.Entries = entries
+		resolved.Value = v
 
 	case PropertyMode:
 		// Navigate to the scalar value at the path
-		value, err := navigatePath(resourceData, pathForNavigation)
+		data, err := navigatePath(resourceData, pathForNavigation)
 		if err != nil {
 			// T087: Full context for navigation errors
 			// T089: Include source span
-			return nil, formatReferenceError(ref, strings.Join(pathForNavigation, "."),
+			return nil, formatReferenceError(ref, pathForNavigation,
 				fmt.Errorf("failed to navigate in alias %q: %w",
 					ref.Alias, err))
 		}
 
-		// Convert to AST expression
-		expr, err := valueToASTExpr(value)
+		// Convert the value, keeping its type
+		v, err := value.Of(data)
 		if err != nil {
 			// T087: Include operation context
 			// T089: Include source span
-			return nil, formatReferenceError(ref, strings.Join(pathForNavigation, "."),
+			return nil, formatReferenceError(ref, pathForNavigation,
 				fmt.Errorf("failed to convert value for alias %q: %w",
 					ref.Alias, err))
 		}
-		resolved.Value = expr
+		resolved.Value = v
 	}
 
 	return resolved, nil
@@ -273,22 +264,19 @@ func ResolveReference(ref *ast.ReferenceExpr, resourceData map[string]any, resCt
 //
 // Parameters:
 //   - ref: The reference expression being resolved
-//   - path: The path being accessed (may be empty for root or partial path on error)
+//   - path: The path being accessed (nil for root or partial path on error)
 //   - err: The underlying error to wrap
 //
-// Returns an error with format: "resolving @alias:path at filename:line: error message"
-func formatReferenceError(ref *ast.ReferenceExpr, path string, err error) error {
-	// Build reference description
-	refDesc := fmt.Sprintf("@%s:%s", ref.Alias, pathKey(ref.Path))
-	if path != "" {
-		refDesc = fmt.Sprintf("@%s:%s", ref.Alias, path)
+// Returns a *ReferenceError formatted as "resolving @alias:path at filename:line:col: error message"
+func formatReferenceError(ref *ast.ReferenceExpr, path []string, err error) error {
+	return &ReferenceError{
+		Alias:    ref.Alias,
+		Path:     path,
+		Filename: ref.SourceSpan.Filename,
+		Line:     ref.SourceSpan.StartLine,
+		Column:   ref.SourceSpan.StartCol,
+		Err:      err,
 	}
-
-	// Include source location for precise error reporting
-	return fmt.Errorf("resolving %s at %s:%d: %w",
-		refDesc,
-		ref.SourceSpan.Filename, ref.SourceSpan.StartLine,
-		err)
 }
 
 // navigatePath navigates through nested maps following the path segments.
@@ -330,70 +318,11 @@ func navigatePath(data map[string]any, path []string) (any, error) {
 	return current, nil
 }
 
-// convertToASTExprs converts a map[string]any to ordered AST map entries.
-func convertToASTExprs(data map[string]any) ([]ast.MapEntry, error) {
-	result := make([]ast.MapEntry, 0, len(data))
-	for key, value := range data {
-		expr, err := valueToASTExpr(value)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert key %q: %w", key, err)
-		}
-		result = append(result, ast.MapEntry{
-			Key:   key,
-			Value: expr,
-		})
-	}
-	return result, nil
-}
-
-// valueToASTExpr converts a runtime value to an AST expression.
-func valueToASTExpr(value any) (ast.Expr, error) {
-	if value == nil {
-		return &ast.StringLiteral{Value: ""}, nil
-	}
-
-	switch v := value.(type) {
-	case string:
-		return &ast.StringLiteral{Value: v}, nil
-
-	case int:
-		return &ast.StringLiteral{Value: fmt.Sprintf("%d", v)}, nil
-
-	case int64:
-		return &ast.StringLiteral{Value: fmt.Sprintf("%d", v)}, nil
-
-	case float64:
-		return &ast.StringLiteral{Value: fmt.Sprintf("%g", v)}, nil
-
-	case bool:
-		return &ast.StringLiteral{Value: fmt.Sprintf("%t", v)}, nil
-
-	case map[string]any:
-		// Recursively convert nested map
-		entries, err := convertToASTExprs(v)
-		if err != nil {
-			return nil, err
-		}
-		return &ast.MapExpr{Entries: entries}, nil
-
-	case []any:
-		// Convert list to ListExpr
-		elements := make([]ast.Expr, 0, len(v))
-		for i, elem := range v {
-			expr, err := valueToASTExpr(elem)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert list element %d: %w", i, err)
-			}
-			elements = append(elements, expr)
-		}
-		return &ast.ListExpr{Elements: elements}, nil
-
-	case *ast.ReferenceExpr:
-		// Already an AST expression - pass through
-		return v, nil
-
-	default:
-		// For other types, convert to string representation
-		return &ast.StringLiteral{Value: fmt.Sprintf("%v", v)}, nil
+// mapOrEmpty returns m, or an empty map if m is nil, so map references
+// always resolve to a map value.
+func mapOrEmpty(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
 	}
+	return m
 }
@@ -1,10 +1,11 @@
 package compiler
 
 import (
-	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+	"github.com/autonomous-bits/nomos/libs/compiler/pkg/value"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
@@ -287,77 +288,60 @@ func TestResolutionContext_formatCycle(t *testing.T) {
 }
 
 func TestResolvedReference_PropertyMode(t *testing.T) {
-	// Test that PropertyMode uses Value field
+	// Test that PropertyMode keeps the scalar's type
 	resolved := ResolvedReference{
-		Mode: PropertyMode,
-		Value: &ast.StringLiteral{
-			Value: "8080",
-		},
-		Entries: nil,
+		Mode:  PropertyMode,
+		Value: value.Int(8080),
 	}
 
 	if resolved.Mode != PropertyMode {
 		t.Errorf("Mode = %v, want PropertyMode", resolved.Mode)
 	}
 
-	if resolved.Value == nil {
-		t.Error("Value should be populated for PropertyMode")
-	}
-
-	if resolved.Entries != nil {
-		t.Error("Entries should be nil for PropertyMode")
+	if n, ok := resolved.Value.Int(); !ok || n != 8080 {
+		t.Errorf("Value = %v (%s), want int 8080", resolved.Value, resolved.Value.Kind())
 	}
 }
 
 func TestResolvedReference_MapMode(t *testing.T) {
-	// Test that MapMode uses Entries field
+	// Test that MapMode holds a map value
 	resolved := ResolvedReference{
-		Mode:  MapMode,
-		Value: nil,
-		Entries: []ast.MapEntry{
-			{Key: "host", Value: &ast.StringLiteral{Value: "localhost"}},
-			{Key: "port", Value: &ast.StringLiteral{Value: "5432"}},
-		},
+		Mode: MapMode,
+		Value: value.Map(map[string]value.Value{
+			"host": value.String("localhost"),
+			"port": value.Int(5432),
+		}),
 	}
 
 	if resolved.Mode != MapMode {
 		t.Errorf("Mode = %v, want MapMode", resolved.Mode)
 	}
 
-	if resolved.Value != nil {
-		t.Error("Value should be nil for MapMode")
+	if resolved.Value.Kind() != value.KindMap {
+		t.Errorf("Value kind = %s, want map", resolved.Value.Kind())
 	}
 
-	if resolved.Entries == nil {
-		t.Error("Entries should be populated for MapMode")
-	}
-
-	if len(resolved.Entries) != 2 {
-		t.Errorf("Entries length = %d, want 2", len(resolved.Entries))
+	if resolved.Value.Len() != 2 {
+		t.Errorf("Value length = %d, want 2", resolved.Value.Len())
 	}
 }
 
 func TestResolvedReference_RootMode(t *testing.T) {
-	// Test that RootMode uses Entries field
+	// Test that RootMode holds a map value
 	resolved := ResolvedReference{
-		Mode:  RootMode,
-		Value: nil,
-		Entries: []ast.MapEntry{
-			{Key: "host", Value: &ast.StringLiteral{Value: "prod.example.com"}},
-			{Key: "port", Value: &ast.StringLiteral{Value: "5432"}},
-		},
+		Mode: RootMode,
+		Value: value.Map(map[string]value.Value{
+			"host": value.String("prod.example.com"),
+			"port": value.Int(5432),
+		}),
 	}
 
 	if resolved.Mode != RootMode {
 		t.Errorf("Mode = %v, want RootMode", resolved.Mode)
 	}
 
-	if resolved.Value != nil {
-		t.Error("Value should be nil for RootMode")
-	}
-
-	if resolved.Entries == nil {
-		t.Error("Entries should be populated for RootMode")
+	if resolved.Value.Kind() != value.KindMap {
+		t.Errorf("Value kind = %s, want map", resolved.Value.Kind())
 	}
 }
 
@@ -527,13 +511,16 @@ func TestResolveReference_RootMode(t *testing.T) {
 				t.Errorf("Mode = %v, want RootMode", resolved.Mode)
 			}
 
-			if resolved.Entries == nil {
-				t.Fatal("Entries should not be nil for RootMode")
+			if resolved.Value.Kind() != value.KindMap {
+				t.Fatalf("Value kind = %s, want map for RootMode", resolved.Value.Kind())
 			}
 
-			// Compare entries (would need deep comparison helper in real implementation)
-			if len(resolved.Entries) != len(tt.wantEntries) {
-				t.Errorf("Entries count = %d, want %d", len(resolved.Entries), len(tt.wantEntries))
+			want, err := value.Of(tt.wantEntries)
+			if err != nil {
+				t.Fatalf("value.Of(wantEntries): %v", err)
+			}
+			if !value.Equal(resolved.Value, want) {
+				t.Errorf("Value = %v, want %v", resolved.Value, want)
 			}
 		})
 	}
@@ -904,26 +891,21 @@ func TestResolveReference_MapMode(t *testing.T) {
 				t.Errorf("Mode = %v, want MapMode", resolved.Mode)
 			}
 
-			if resolved.Entries == nil {
-				t.Fatal("Entries should not be nil for MapMode")
+			if resolved.Value.Kind() != value.KindMap {
+				t.Fatalf("Value kind = %s, want map for MapMode", resolved.Value.Kind())
 			}
 
 			// Verify only targeted map keys are present
-			if len(resolved.Entries) != len(tt.wantKeys) {
-				t.Errorf("Entries count = %d, want %d (got keys: %v, want: %v)",
-					len(resolved.Entries), len(tt.wantKeys), mapKeys(resolved.Entries), tt.wantKeys)
+			if resolved.Value.Len() != len(tt.wantKeys) {
+				t.Errorf("entry count = %d, want %d (got keys: %v, want: %v)",
+					resolved.Value.Len(), len(tt.wantKeys), resolved.Value.Keys(), tt.wantKeys)
 			}
 
 			for _, key := range tt.wantKeys {
-				if _, exists := findEntry(resolved.Entries, key); !exists {
-					t.Errorf("expected key %q in Entries, not found", key)
+				if _, exists := resolved.Value.Field(key); !exists {
+					t.Errorf("expected key %q in Value, not found", key)
 				}
 			}
-
-			// Verify Value is nil (not used for MapMode)
-			if resolved.Value != nil {
-				t.Error("Value should be nil for MapMode")
-			}
 		})
 	}
 }
@@ -1095,32 +1077,17 @@ func TestResolveReference_MapMode_WithMerge(t *testing.T) {
 				t.Errorf("Mode = %v, want MapMode", resolved.Mode)
 			}
 
-			// Verify Entries are populated
-			if resolved.Entries == nil {
-				t.Fatal("Entries should not be nil for MapMode")
+			// Verify the map value matches the provider data, nested maps included
+			if resolved.Value.Kind() != value.KindMap {
+				t.Fatalf("Value kind = %s, want map for MapMode", resolved.Value.Kind())
 			}
-
-			// Verify Entries contain AST expressions
-			for _, entry := range resolved.Entries {
-				key := entry.Key
-				expr := entry.Value
-				if expr == nil {
-					t.Errorf("Entry[%q] is nil, expected AST expression", key)
-					continue
-				}
-
-				// Verify AST expressions have correct types
-				switch e := expr.(type) {
-				case *ast.StringLiteral:
-					// Simple values are converted to StringLiteral
-				case *ast.MapExpr:
-					// Nested maps are converted to MapExpr
-					if e.Entries == nil {
-						t.Errorf("MapExpr for key %q has nil Entries", key)
-					}
-				default:
-					// Other types are acceptable (ListExpr, etc.)
-				}
+			want, err := value.Of(tt.resourceData)
+			if err != nil {
+				t.Fatalf("value.Of(resourceData): %v", err)
+			}
+			want, _ = want.Lookup(tt.ref.Path...)
+			if !value.Equal(resolved.Value, want) {
+				t.Errorf("Value = %v, want %v", resolved.Value, want)
 			}
 
 			// Note: Actual merge behavior is tested in merge_test.go
@@ -1357,28 +1324,18 @@ func TestResolveReference_PropertyMode(t *testing.T) {
 				t.Errorf("Mode = %v, want PropertyMode", resolved.Mode)
 			}
 
-			// Verify Value field is populated (not Entries)
-			if resolved.Value == nil {
-				t.Fatal("Value should be populated for PropertyMode")
+			// Verify Value is populated with a single value, not a map
+			if resolved.Value.IsNull() || resolved.Value.Kind() == value.KindMap {
+				t.Fatalf("Value = %v (%s), want a single value for PropertyMode", resolved.Value, resolved.Value.Kind())
 			}
 
-			if resolved.Entries != nil {
-				t.Error("Entries should be nil for PropertyMode")
-			}
-
-			// Verify Value is the correct AST expression
-			// Convert expected value to string for comparison
-			var gotValue string
-			switch v := resolved.Value.(type) {
-			case *ast.StringLiteral:
-				gotValue = v.Value
-			default:
-				t.Fatalf("expected StringLiteral, got %T", resolved.Value)
+			// Verify Value holds the scalar with its original type
+			want, err := value.Of(tt.wantValue)
+			if err != nil {
+				t.Fatalf("value.Of(wantValue): %v", err)
 			}
-
-			wantStr := toString(tt.wantValue)
-			if gotValue != wantStr {
-				t.Errorf("Value = %q, want %q", gotValue, wantStr)
+			if !value.Equal(resolved.Value, want) {
+				t.Errorf("Value = %v (%s), want %v (%s)", resolved.Value, resolved.Value.Kind(), want, want.Kind())
 			}
 		})
 	}
@@ -1656,26 +1613,18 @@ func TestResolveReference_PropertyMode_AllScalarTypes(t *testing.T) {
 				t.Errorf("Mode = %v, want PropertyMode", resolved.Mode)
 			}
 
-			// Verify Value is populated
-			if resolved.Value == nil {
-				t.Fatal("Value should be populated for PropertyMode")
-			}
-
-			// Verify Entries is nil
-			if resolved.Entries != nil {
-				t.Error("Entries should be nil for PropertyMode")
+			// Verify Value is populated with a single value, not a map
+			if resolved.Value.IsNull() || resolved.Value.Kind() == value.KindMap {
+				t.Fatalf("Value = %v (%s), want a single value for PropertyMode", resolved.Value, resolved.Value.Kind())
 			}
 
-			// Verify Value is a StringLiteral (all scalars converted to string in AST)
-			strLit, ok := resolved.Value.(*ast.StringLiteral)
-			if !ok {
-				t.Fatalf("expected StringLiteral, got %T", resolved.Value)
+			// Verify Value keeps the scalar's type (no string conversion)
+			want, err := value.Of(tt.wantValue)
+			if err != nil {
+				t.Fatalf("value.Of(wantValue): %v", err)
 			}
-
-			// Compare string representation
-			wantStr := toString(tt.wantValue)
-			if strLit.Value != wantStr {
-				t.Errorf("Value = %q, want %q (type: %s)", strLit.Value, wantStr, tt.wantType)
+			if !value.Equal(resolved.Value, want) {
+				t.Errorf("Value = %v (%s), want %v (%s)", resolved.Value, resolved.Value.Kind(), want, tt.wantType)
 			}
 		})
 	}
@@ -1895,11 +1844,11 @@ func TestResolveReference_EmptyResource(t *testing.T) {
 			// Verify empty map is returned (not error)
 			switch resolved.Mode {
 			case RootMode, MapMode:
-				if resolved.Entries == nil {
-					t.Error("Entries should not be nil for empty resource")
+				if resolved.Value.Kind() != value.KindMap {
+					t.Errorf("Value kind = %s, want map for empty resource", resolved.Value.Kind())
 				}
-				if len(resolved.Entries) != 0 {
-					t.Errorf("expected empty Entries, got %d entries", len(resolved.Entries))
+				if resolved.Value.Len() != 0 {
+					t.Errorf("expected empty map, got %d entries", resolved.Value.Len())
 				}
 			case PropertyMode:
 				t.Error("empty resource should not resolve to PropertyMode")
@@ -1908,184 +1857,62 @@ func TestResolveReference_EmptyResource(t *testing.T) {
 	}
 }
 
-// Helper functions
+// Unit tests for the conversion of provider data
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > 0 && len(substr) > 0 && containsHelper(s, substr)))
-}
-
-func containsHelper(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
-func deepEqualany(a, b any) bool {
-	// Simple deep comparison for maps and scalars
-	switch va := a.(type) {
-	case map[string]any:
-		vb, ok := b.(map[string]any)
-		if !ok {
-			return false
-		}
-		if len(va) != len(vb) {
-			return false
-		}
-		for k, v := range va {
-			if !deepEqualany(v, vb[k]) {
-				return false
-			}
-		}
-		return true
-	default:
-		return a == b
-	}
-}
-
-func mapKeys(entries []ast.MapEntry) []string {
-	keys := make([]string, 0, len(entries))
-	for _, entry := range entries {
-		if entry.Key == "" {
-			continue
-		}
-		keys = append(keys, entry.Key)
-	}
-	return keys
-}
-
-func findEntry(entries []ast.MapEntry, key string) (ast.MapEntry, bool) {
-	for _, entry := range entries {
-		if entry.Key == key {
-			return entry, true
-		}
-	}
-	return ast.MapEntry{}, false
-}
-
-func toString(v any) string {
-	switch val := v.(type) {
-	case string:
-		return val
-	case int:
-		return fmt.Sprintf("%d", val)
-	case int64:
-		return fmt.Sprintf("%d", val)
-	case float64:
-		return fmt.Sprintf("%g", val)
-	case bool:
-		return fmt.Sprintf("%t", val)
-	default:
-		return fmt.Sprintf("%v", val)
-	}
-}
-
-// Unit tests for internal helper functions
-
-func TestValueToASTExpr_AllTypes(t *testing.T) {
+func TestResolveReference_ValueTypes(t *testing.T) {
 	tests := []struct {
-		name     string
-		value    any
-		wantType string
-		wantVal  string
+		name  string
+		value any
+		want  value.Value
 	}{
+		{name: "nil value", value: nil, want: value.Null()},
+		{name: "string value", value: "hello", want: value.String("hello")},
+		{name: "int value", value: 42, want: value.Int(42)},
+		{name: "int64 value", value: int64(9223372036854775807), want: value.Int(9223372036854775807)},
+		{name: "uint value", value: uint(7), want: value.Int(7)},
+		{name: "uint64 value", value: uint64(9223372036854775807), want: value.Int(9223372036854775807)},
+		{name: "float64 value", value: 3.14, want: value.Float(3.14)},
+		{name: "bool true", value: true, want: value.Bool(true)},
+		{name: "bool false", value: false, want: value.Bool(false)},
 		{
-			name:     "nil value",
-			value:    nil,
-			wantType: "*ast.StringLiteral",
-			wantVal:  "",
-		},
-		{
-			name:     "string value",
-			value:    "hello",
-			wantType: "*ast.StringLiteral",
-			wantVal:  "hello",
-		},
-		{
-			name:     "int value",
-			value:    42,
-			wantType: "*ast.StringLiteral",
-			wantVal:  "42",
-		},
-		{
-			name:     "int64 value",
-			value:    int64(9223372036854775807),
-			wantType: "*ast.StringLiteral",
-			wantVal:  "9223372036854775807",
-		},
-		{
-			name:     "float64 value",
-			value:    3.14,
-			wantType: "*ast.StringLiteral",
-			wantVal:  "3.14",
-		},
-		{
-			name:     "bool true",
-			value:    true,
-			wantType: "*ast.StringLiteral",
-			wantVal:  "true",
-		},
-		{
-			name:     "bool false",
-			value:    false,
-			wantType: "*ast.StringLiteral",
-			wantVal:  "false",
-		},
-		{
-			name: "map value",
-			value: map[string]any{
-				"key": "value",
-			},
-			wantType: "*ast.MapExpr",
-		},
-		{
-			name:     "empty map",
-			value:    map[string]any{},
-			wantType: "*ast.MapExpr",
+			name:  "map value",
+			value: map[string]any{"key": "value"},
+			want:  value.Map(map[string]value.Value{"key": value.String("value")}),
 		},
+		{name: "empty map", value: map[string]any{}, want: value.Map(nil)},
 		{
-			name: "list value",
-			value: []any{
-				"item1",
-				"item2",
-			},
-			wantType: "*ast.ListExpr",
+			name:  "list value",
+			value: []any{"item1", "item2"},
+			want:  value.List(value.String("item1"), value.String("item2")),
 		},
+		{name: "empty list", value: []any{}, want: value.List()},
 		{
-			name:     "empty list",
-			value:    []any{},
-			wantType: "*ast.ListExpr",
+			name:  "secret value",
+			value: models.Secret{Value: "s3cr3t"},
+			want:  value.Secret(value.String("s3cr3t")),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			expr, err := valueToASTExpr(tt.value)
+			ref := &ast.ReferenceExpr{Alias: "base", Path: []string{"v"}}
+			resolved, err := ResolveReference(ref, map[string]any{"v": tt.value}, &ResolutionContext{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			// Check type
-			gotType := fmt.Sprintf("%T", expr)
-			if gotType != tt.wantType {
-				t.Errorf("type = %s, want %s", gotType, tt.wantType)
+			if resolved.Value.Kind() != tt.want.Kind() {
+				t.Errorf("kind = %s, want %s", resolved.Value.Kind(), tt.want.Kind())
 			}
-
-			// Check value for string literals
-			if strLit, ok := expr.(*ast.StringLiteral); ok && tt.wantVal != "" {
-				if strLit.Value != tt.wantVal {
-					t.Errorf("value = %q, want %q", strLit.Value, tt.wantVal)
-				}
+			if !value.Equal(resolved.Value, tt.want) {
+				t.Errorf("Value = %v, want %v", resolved.Value, tt.want)
 			}
 		})
 	}
 }
 
-func TestValueToASTExpr_NestedMap(t *testing.T) {
-	value := map[string]any{
+func TestResolveReference_ValueTypes_NestedMap(t *testing.T) {
+	data := map[string]any{
 		"database": map[string]any{
 			"host": "localhost",
 			"port": 5432,
@@ -2093,138 +1920,114 @@ func TestValueToASTExpr_NestedMap(t *testing.T) {
 		"api_url": "http://api.example.com",
 	}
 
-	expr, err := valueToASTExpr(value)
+	resolved, err := ResolveReference(&ast.ReferenceExpr{Alias: "base"}, data, &ResolutionContext{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	mapExpr, ok := expr.(*ast.MapExpr)
-	if !ok {
-		t.Fatalf("expected *ast.MapExpr, got %T", expr)
-	}
-
-	if len(mapExpr.Entries) != 2 {
-		t.Errorf("entries count = %d, want 2", len(mapExpr.Entries))
+	if resolved.Value.Len() != 2 {
+		t.Errorf("entries count = %d, want 2", resolved.Value.Len())
 	}
 
 	// Check database entry is also a map
-	dbEntry, exists := findEntry(mapExpr.Entries, "database")
+	db, exists := resolved.Value.Field("database")
 	if !exists {
 		t.Fatal("expected 'database' entry")
 	}
-	if dbEntry.Value == nil {
-		t.Fatal("expected 'database' entry value")
+	if db.Kind() != value.KindMap {
+		t.Fatalf("expected database to be a map, got %s", db.Kind())
 	}
-
-	dbMap, ok := dbEntry.Value.(*ast.MapExpr)
-	if !ok {
-		t.Errorf("expected database to be *ast.MapExpr, got %T", dbEntry.Value)
+	if db.Len() != 2 {
+		t.Errorf("database entries count = %d, want 2", db.Len())
 	}
-
-	if ok && len(dbMap.Entries) != 2 {
-		t.Errorf("database entries count = %d, want 2", len(dbMap.Entries))
+	if port, _ := db.Field("port"); !value.Equal(port, value.Int(5432)) {
+		t.Errorf("database port = %v (%s), want int 5432", port, port.Kind())
 	}
 }
 
-func TestValueToASTExpr_NestedList(t *testing.T) {
-	value := []any{
-		"string",
-		42,
-		true,
-		[]any{"nested", "list"},
-		map[string]any{"key": "value"},
+func TestResolveReference_ValueTypes_NestedList(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			"string",
+			42,
+			true,
+			[]any{"nested", "list"},
+			map[string]any{"key": "value"},
+		},
 	}
 
-	expr, err := valueToASTExpr(value)
+	resolved, err := ResolveReference(&ast.ReferenceExpr{Alias: "base", Path: []string{"items"}}, data, &ResolutionContext{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	listExpr, ok := expr.(*ast.ListExpr)
-	if !ok {
-		t.Fatalf("expected *ast.ListExpr, got %T", expr)
+	list := resolved.Value
+	if list.Kind() != value.KindList {
+		t.Fatalf("expected a list, got %s", list.Kind())
 	}
-
-	if len(listExpr.Elements) != 5 {
-		t.Errorf("elements count = %d, want 5", len(listExpr.Elements))
+	if list.Len() != 5 {
+		t.Errorf("elements count = %d, want 5", list.Len())
 	}
 
 	// Check nested list
-	nestedList, ok := listExpr.Elements[3].(*ast.ListExpr)
-	if !ok {
-		t.Errorf("expected element[3] to be *ast.ListExpr, got %T", listExpr.Elements[3])
-	}
-
-	if ok && len(nestedList.Elements) != 2 {
-		t.Errorf("nested list elements count = %d, want 2", len(nestedList.Elements))
+	if nested := list.Index(3); nested.Kind() != value.KindList || nested.Len() != 2 {
+		t.Errorf("element[3] = %v (%s), want a list of 2 elements", nested, nested.Kind())
 	}
 
 	// Check nested map
-	nestedMap, ok := listExpr.Elements[4].(*ast.MapExpr)
-	if !ok {
-		t.Errorf("expected element[4] to be *ast.MapExpr, got %T", listExpr.Elements[4])
-	}
-
-	if ok && len(nestedMap.Entries) != 1 {
-		t.Errorf("nested map entries count = %d, want 1", len(nestedMap.Entries))
-	}
-}
-
-func TestValueToASTExpr_ReferenceExpr(t *testing.T) {
-	refExpr := &ast.ReferenceExpr{
-		Alias: "base",
-		Path:  []string{"database", "host"},
-	}
-
-	expr, err := valueToASTExpr(refExpr)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	gotRef, ok := expr.(*ast.ReferenceExpr)
-	if !ok {
-		t.Fatalf("expected *ast.ReferenceExpr, got %T", expr)
-	}
-
-	if gotRef.Alias != "base" {
-		t.Errorf("alias = %q, want %q", gotRef.Alias, "base")
-	}
-
-	if len(gotRef.Path) != 2 || gotRef.Path[0] != "database" || gotRef.Path[1] != "host" {
-		t.Errorf("path = %v, want [database host]", gotRef.Path)
+	if nested := list.Index(4); nested.Kind() != value.KindMap || nested.Len() != 1 {
+		t.Errorf("element[4] = %v (%s), want a map of 1 entry", nested, nested.Kind())
 	}
 }
 
-func TestValueToASTExpr_DefaultCase(t *testing.T) {
-	// Test with a type that doesn't have explicit handling (falls to default case)
+func TestResolveReference_ValueTypes_Unsupported(t *testing.T) {
+	// Provider data is plain data: references must be resolved before, and
+	// other Go types are rejected rather than formatted as strings
 	type customType struct {
 		field string
 	}
 
-	value := customType{field: "test"}
-
-	expr, err := valueToASTExpr(value)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	strLit, ok := expr.(*ast.StringLiteral)
-	if !ok {
-		t.Fatalf("expected *ast.StringLiteral for default case, got %T", expr)
+	tests := []struct {
+		name     string
+		value    any
+		wantType string
+	}{
+		{
+			name:     "reference",
+			value:    &ast.ReferenceExpr{Alias: "base", Path: []string{"database", "host"}},
+			wantType: "*ast.ReferenceExpr",
+		},
+		{
+			name:     "custom type",
+			value:    customType{field: "test"},
+			wantType: "compiler.customType",
+		},
+		{
+			name:     "uint64 overflow",
+			value:    uint64(1) << 63,
+			wantType: "overflows int64",
+		},
 	}
 
-	// Should contain string representation of the struct
-	if !contains(strLit.Value, "test") {
-		t.Errorf("value = %q, should contain 'test'", strLit.Value)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := &ast.ReferenceExpr{Alias: "base", Path: []string{"v"}}
+			_, err := ResolveReference(ref, map[string]any{"v": tt.value}, &ResolutionContext{})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !contains(err.Error(), tt.wantType) || !contains(err.Error(), `alias "base"`) {
+				t.Errorf("error = %q, want it to name %s and the alias", err, tt.wantType)
+			}
+		})
 	}
 }
 
-func TestConvertToASTExprs(t *testing.T) {
+func TestResolveReference_RootMode_Keys(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    map[string]any
 		wantKeys []string
-		wantErr  bool
 	}{
 		{
 			name: "simple map",
@@ -2233,7 +2036,6 @@ func TestConvertToASTExprs(t *testing.T) {
 				"port": 5432,
 			},
 			wantKeys: []string{"host", "port"},
-			wantErr:  false,
 		},
 		{
 			name: "nested map",
@@ -2245,13 +2047,11 @@ func TestConvertToASTExprs(t *testing.T) {
 				"api_url": "http://api.example.com",
 			},
 			wantKeys: []string{"database", "api_url"},
-			wantErr:  false,
 		},
 		{
 			name:     "empty map",
 			input:    map[string]any{},
 			wantKeys: []string{},
-			wantErr:  false,
 		},
 		{
 			name: "map with various types",
@@ -2264,34 +2064,63 @@ func TestConvertToASTExprs(t *testing.T) {
 				"map":    map[string]any{"nested": "value"},
 			},
 			wantKeys: []string{"string", "int", "bool", "float", "list", "map"},
-			wantErr:  false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := convertToASTExprs(tt.input)
-
-			if tt.wantErr {
-				if err == nil {
-					t.Fatal("expected error, got nil")
-				}
-				return
-			}
-
+			resolved, err := ResolveReference(&ast.ReferenceExpr{Alias: "base"}, tt.input, &ResolutionContext{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			if len(result) != len(tt.wantKeys) {
-				t.Errorf("result length = %d, want %d", len(result), len(tt.wantKeys))
+			if resolved.Value.Len() != len(tt.wantKeys) {
+				t.Errorf("result length = %d, want %d", resolved.Value.Len(), len(tt.wantKeys))
 			}
 
 			for _, key := range tt.wantKeys {
-				if _, exists := findEntry(result, key); !exists {
+				if _, exists := resolved.Value.Field(key); !exists {
 					t.Errorf("expected key %q in result, not found", key)
 				}
 			}
 		})
 	}
 }
+
+// Helper functions
+
+func contains(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
+		(len(s) > 0 && len(substr) > 0 && containsHelper(s, substr)))
+}
+
+func containsHelper(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+func deepEqualany(a, b any) bool {
+	// Simple deep comparison for maps and scalars
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		if len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			if !deepEqualany(v, vb[k]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
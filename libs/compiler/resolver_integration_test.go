@@ -6,10 +6,10 @@ package compiler
 import (
 	"context"
 	"errors"
-	"strings"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/converter"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
 
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
@@ -53,8 +53,8 @@ func TestResolveReferences_Integration(t *testing.T) {
 		resolved, err := pipeline.ResolveReferences(ctx, data, pipeline.ResolveOptions{
 			ProviderRegistry:     registry,
 			AllowMissingProvider: false,
-			OnWarning: func(warning string) {
-				warnings = append(warnings, warning)
+			OnWarning: func(warning diagnostic.Diagnostic) {
+				warnings = append(warnings, warning.Message)
 			},
 		})
 
@@ -99,8 +99,8 @@ func TestResolveReferences_Integration(t *testing.T) {
 		resolved, err := pipeline.ResolveReferences(ctx, data, pipeline.ResolveOptions{
 			ProviderRegistry:     registry,
 			AllowMissingProvider: true,
-			OnWarning: func(warning string) {
-				warnings = append(warnings, warning)
+			OnWarning: func(warning diagnostic.Diagnostic) {
+				warnings = append(warnings, warning.Message)
 			},
 		})
 
@@ -140,8 +140,8 @@ func TestResolveReferences_Integration(t *testing.T) {
 		_, err := pipeline.ResolveReferences(ctx, data, pipeline.ResolveOptions{
 			ProviderRegistry:     registry,
 			AllowMissingProvider: false,
-			OnWarning: func(warning string) {
-				warnings = append(warnings, warning)
+			OnWarning: func(warning diagnostic.Diagnostic) {
+				warnings = append(warnings, warning.Message)
 			},
 		})
 
@@ -150,10 +150,13 @@ func TestResolveReferences_Integration(t *testing.T) {
 			t.Fatal("expected error for missing provider, got nil")
 		}
 
-		// Error should mention provider not registered
-		errMsg := err.Error()
-		if !strings.Contains(errMsg, "provider not registered") && !strings.Contains(errMsg, "missing") {
-			t.Errorf("error should mention provider issue, got: %v", err)
+		// Error should classify as an unknown alias and name it
+		if !errors.Is(err, ErrUnknownAlias) {
+			t.Errorf("expected ErrUnknownAlias, got: %v", err)
+		}
+		var refErr *ReferenceError
+		if !errors.As(err, &refErr) || refErr.Alias != "missing" {
+			t.Errorf("expected *ReferenceError for alias %q, got: %v", "missing", err)
 		}
 	})
 
@@ -191,8 +194,8 @@ func TestResolveReferences_Integration(t *testing.T) {
 		resolved, err := pipeline.ResolveReferences(ctx, data, pipeline.ResolveOptions{
 			ProviderRegistry:     registry,
 			AllowMissingProvider: false,
-			OnWarning: func(warning string) {
-				warnings = append(warnings, warning)
+			OnWarning: func(warning diagnostic.Diagnostic) {
+				warnings = append(warnings, warning.Message)
 			},
 		})
 
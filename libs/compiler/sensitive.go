@@ -0,0 +1,32 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/models"
+)
+
+// sensitiveKeys returns the sorted key paths of the values in data that are
+// marked as secrets, in the notation used by SourceMap.
+func sensitiveKeys(data map[string]any) []string {
+	keys := []string{}
+	var walk func(key string, value any)
+	walk = func(key string, value any) {
+		switch v := value.(type) {
+		case models.Secret:
+			keys = append(keys, key)
+		case map[string]any:
+			for k, child := range v {
+				walk(joinKeyPath(key, k), child)
+			}
+		case []any:
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", key, i), child)
+			}
+		}
+	}
+	walk("", data)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+)
+
+// SessionOptions configures a Session.
+type SessionOptions struct {
+	// Resolver locates external provider binaries for the session, such as
+	// a LockfileProviderResolver over the tenant's lockfile. If nil, the
+	// session serves built-in provider types and types registered with
+	// Session.RegisterType only.
+	Resolver ProviderResolver
+
+	// Manager configures the session's provider subprocesses.
+	Manager ManagerOptions
+
+	// Base holds the options every compilation of the session starts from,
+	// such as Timeouts, Policies, or EncryptionKey. Its Path and provider
+	// registries are ignored.
+	Base Options
+}
+
+// Session isolates the compilations of one logical tenant or workspace.
+// It owns its provider subprocesses, its cache of shared provider
+// instances, and its base options, none of which other sessions see, and
+// can be closed independently of them. Build a multi-tenant service by
+// creating one Session per tenant.
+//
+// A Session is safe for concurrent use. Each compilation gets its own
+// provider registry, so source aliases never leak between compilations,
+// while provider processes with equal binary and configuration are shared
+// within the session.
+type Session struct {
+	base         Options
+	manager      *Manager
+	typeRegistry ProviderTypeRegistry
+
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+}
+
+// NewSession creates a Session from opts.
+func NewSession(opts SessionOptions) *Session {
+	manager := NewManagerWithOptions(opts.Manager)
+
+	var typeRegistry ProviderTypeRegistry
+	if opts.Resolver != nil {
+		typeRegistry = NewProviderTypeRegistryWithResolver(opts.Resolver, manager)
+	} else {
+		typeRegistry = NewProviderTypeRegistry()
+	}
+
+	base := opts.Base
+	base.Path = ""
+	base.ProviderRegistry = nil
+	base.ProviderTypeRegistry = nil
+
+	return &Session{
+		base:         base,
+		manager:      manager,
+		typeRegistry: typeRegistry,
+	}
+}
+
+// RegisterType registers an in-process provider type for this session only.
+func (s *Session) RegisterType(typeName string, constructor ProviderTypeConstructor) {
+	s.typeRegistry.RegisterType(typeName, constructor)
+}
+
+// Options returns a copy of the session's base options for compiling path.
+// Adjust the copy, for example to set Vars or ProviderConfigOverrides, and
+// pass it to Compile.
+func (s *Session) Options(path string) Options {
+	opts := s.base
+	opts.Path = path
+	return opts
+}
+
+// Compile compiles opts within the session. A nil opts.ProviderTypeRegistry
+// selects the session's type registry, and a nil opts.ProviderRegistry a
+// new registry for this compilation alone.
+//
+// Compile returns ErrSessionClosed once Close has been called; otherwise
+// the error is nil and failures are reported in the result as by the
+// package-level Compile.
+func (s *Session) Compile(ctx context.Context, opts Options) (CompilationResult, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return CompilationResult{}, ErrSessionClosed
+	}
+	s.inflight.Add(1)
+	s.mu.Unlock()
+	defer s.inflight.Done()
+
+	if opts.ProviderTypeRegistry == nil {
+		opts.ProviderTypeRegistry = s.typeRegistry
+	}
+	if opts.ProviderRegistry == nil {
+		opts.ProviderRegistry = NewProviderRegistry()
+	}
+	return Compile(ctx, opts), nil
+}
+
+// Close rejects new compilations, waits for in-flight ones to finish, and
+// shuts down the session's provider subprocesses. If ctx ends first, Close
+// shuts the providers down without waiting further and returns ctx's error
+// alongside any shutdown error. Calling Close again returns nil.
+func (s *Session) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	var waitErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+	}
+
+	// Shut down with a fresh context so a cancelled ctx still stops the
+	// processes; the manager bounds shutdown by its own timeout
+	return stderrors.Join(waitErr, s.manager.Shutdown(context.WithoutCancel(ctx)))
+}
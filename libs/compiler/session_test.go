@@ -0,0 +1,116 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestSession_Isolation verifies that sessions keep their provider types
+// and base options to themselves.
+func TestSession_Isolation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "source:\n  alias: 'cfg'\n  type: 'named'\n\napp:\n  owner: @cfg:owner\n  env: @var:env\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	newTenant := func(name string) *compiler.Session {
+		session := compiler.NewSession(compiler.SessionOptions{
+			Base: compiler.Options{Vars: map[string]any{"env": name + "-env"}},
+		})
+		session.RegisterType("named", func(map[string]any) (compiler.Provider, error) {
+			return &nameProvider{name: name}, nil
+		})
+		t.Cleanup(func() { _ = session.Close(context.Background()) })
+		return session
+	}
+	alpha, beta := newTenant("alpha"), newTenant("beta")
+
+	for _, tc := range []struct {
+		session *compiler.Session
+		name    string
+	}{{alpha, "alpha"}, {beta, "beta"}, {alpha, "alpha"}} {
+		result, err := tc.session.Compile(context.Background(), tc.session.Options(path))
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if result.HasErrors() {
+			t.Fatalf("unexpected errors: %v", result.Error())
+		}
+		want := map[string]any{"owner": tc.name, "env": tc.name + "-env"}
+		if got := result.Snapshot.Data["app"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Data[app] = %#v, want %#v", got, want)
+		}
+	}
+
+	// A type registered with one session is unknown to a fresh one
+	other := compiler.NewSession(compiler.SessionOptions{})
+	defer func() { _ = other.Close(context.Background()) }()
+	result, err := other.Compile(context.Background(), other.Options(path))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !result.HasErrors() {
+		t.Error("expected an error for a type registered with another session")
+	}
+}
+
+// TestSession_Close verifies that Close waits for in-flight compilations,
+// honours its context, and makes later compilations fail.
+func TestSession_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "source:\n  alias: 'slow'\n  type: 'blocking'\n\napp:\n  value: @slow:value\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	session := compiler.NewSession(compiler.SessionOptions{})
+	session.RegisterType("blocking", func(map[string]any) (compiler.Provider, error) {
+		return &gatedProvider{started: started, release: release}, nil
+	})
+
+	compiled := make(chan error, 1)
+	go func() {
+		_, err := session.Compile(context.Background(), session.Options(path))
+		compiled <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := session.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() with a compilation in flight = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := session.Compile(context.Background(), session.Options(path)); !errors.Is(err, compiler.ErrSessionClosed) {
+		t.Errorf("Compile() after Close = %v, want %v", err, compiler.ErrSessionClosed)
+	}
+
+	close(release)
+	if err := <-compiled; err != nil {
+		t.Errorf("in-flight Compile() error = %v", err)
+	}
+	if err := session.Close(context.Background()); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+}
+
+// gatedProvider signals started from Fetch and blocks until release is closed.
+type gatedProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *gatedProvider) Init(context.Context, compiler.ProviderInitOptions) error { return nil }
+
+func (p *gatedProvider) Fetch(context.Context, []string) (any, error) {
+	close(p.started)
+	<-p.release
+	return "done", nil
+}
@@ -0,0 +1,160 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// snapshotSchemaVersion is the newest metadata schema version LoadSnapshot
+// reads; see libs/snapshotmeta for the published schema.
+const snapshotSchemaVersion = 1
+
+// LoadSnapshot reads a snapshot previously written by the CLI as JSON or
+// YAML, with or without the --include-metadata wrapper, so tools can query
+// compiled output without walking it by hand:
+//
+//	snapshot, err := compiler.LoadSnapshot("build/config.json")
+//	if err != nil {
+//	    return err
+//	}
+//	port, ok := snapshot.Lookup("app.server.port")
+//
+// A document whose only top-level keys are "data" and "metadata", where
+// metadata carries a schema_version, is read as a wrapped snapshot;
+// anything else is read as bare data with empty Metadata. Numbers load as
+// float64 whichever format the file uses. Errors wrap ErrInvalidSnapshot
+// when the file is readable but not a snapshot.
+func LoadSnapshot(path string) (Snapshot, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: Path is provided by the caller
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	snapshot, err := ParseSnapshot(content)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// ParseSnapshot is LoadSnapshot for content already in memory. JSON content
+// is recognized by its leading '{'; anything else is parsed as YAML.
+func ParseSnapshot(content []byte) (Snapshot, error) {
+	format := "yaml"
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		format = "json"
+	}
+	doc, err := decodeDatafile(content, format)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return Snapshot{}, fmt.Errorf("%w: not a map at the top level (got %T)", ErrInvalidSnapshot, doc)
+	}
+
+	metadata, wrapped := snapshotMetadata(root)
+	if !wrapped {
+		return Snapshot{Data: root}, nil
+	}
+
+	data, ok := root["data"].(map[string]any)
+	if !ok {
+		return Snapshot{}, fmt.Errorf("%w: data is not a map (got %T)", ErrInvalidSnapshot, root["data"])
+	}
+	version, ok := metadata["schema_version"].(float64)
+	if !ok || version < 1 || version > snapshotSchemaVersion {
+		return Snapshot{}, fmt.Errorf("%w: unsupported metadata schema_version %v (supported: 1 to %d)", ErrInvalidSnapshot, metadata["schema_version"], snapshotSchemaVersion)
+	}
+
+	// The envelope's field names are the JSON tags of Metadata
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	snapshot := Snapshot{Data: data}
+	if err := json.Unmarshal(encoded, &snapshot.Metadata); err != nil {
+		return Snapshot{}, fmt.Errorf("%w: invalid metadata: %w", ErrInvalidSnapshot, err)
+	}
+	return snapshot, nil
+}
+
+// snapshotMetadata returns the metadata of a wrapped snapshot and whether
+// root is one.
+func snapshotMetadata(root map[string]any) (map[string]any, bool) {
+	if len(root) != 2 {
+		return nil, false
+	}
+	if _, ok := root["data"]; !ok {
+		return nil, false
+	}
+	metadata, ok := root["metadata"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := metadata["schema_version"]; !ok {
+		return nil, false
+	}
+	return metadata, true
+}
+
+// Lookup returns the value at keyPath in the snapshot data. Key paths use
+// the source map syntax: dots between map keys and [n] for list indices,
+// e.g. "app.server.port" or "app.tags[0]".
+func (s Snapshot) Lookup(keyPath string) (any, bool) {
+	if keyPath == "" {
+		return nil, false
+	}
+	var value any = s.Data
+	for _, segment := range strings.Split(keyPath, ".") {
+		key, indexes, ok := splitIndexes(segment)
+		if !ok {
+			return nil, false
+		}
+		m, isMap := value.(map[string]any)
+		if !isMap {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+		for _, i := range indexes {
+			list, isList := value.([]any)
+			if !isList || i >= len(list) {
+				return nil, false
+			}
+			value = list[i]
+		}
+	}
+	return value, true
+}
+
+// splitIndexes splits a key path segment such as "tags[0][1]" into its key
+// and list indices.
+func splitIndexes(segment string) (string, []int, bool) {
+	open := strings.IndexByte(segment, '[')
+	if open < 0 {
+		return segment, nil, segment != ""
+	}
+	key, rest := segment[:open], segment[open:]
+	if key == "" {
+		return "", nil, false
+	}
+	var indexes []int
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, false
+		}
+		i, err := strconv.Atoi(rest[1:end])
+		if err != nil || i < 0 {
+			return "", nil, false
+		}
+		indexes = append(indexes, i)
+		rest = rest[end+1:]
+	}
+	return key, indexes, true
+}
@@ -0,0 +1,179 @@
+package compiler_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+const testWrappedSnapshotJSON = `{
+  "data": {"app": {"name": "demo", "port": 8080, "tags": ["web", ["a", "b"]]}},
+  "metadata": {
+    "schema_version": 1,
+    "start_time": "2026-02-14T10:00:00Z",
+    "end_time": "2026-02-14T10:00:01Z",
+    "input_files": ["/src/app.csl"],
+    "provider_aliases": [],
+    "per_key_provenance": {"app": {"source": "/src/app.csl", "provider_alias": ""}},
+    "errors": [],
+    "warnings": ["W001: unused source"],
+    "type_coercion": "strict",
+    "sensitive_keys": ["app.name"],
+    "project_root": "/src"
+  }
+}`
+
+const testWrappedSnapshotYAML = `data:
+  app:
+    name: demo
+    port: 8080
+    tags:
+      - web
+      - [a, b]
+metadata:
+  schema_version: 1
+  start_time: "2026-02-14T10:00:00Z"
+  end_time: 2026-02-14T10:00:01Z
+  input_files:
+    - /src/app.csl
+  provider_aliases: []
+  per_key_provenance:
+    app:
+      source: /src/app.csl
+      provider_alias: ""
+  errors: []
+  warnings:
+    - "W001: unused source"
+  type_coercion: strict
+  sensitive_keys:
+    - app.name
+  project_root: /src
+`
+
+// TestLoadSnapshot verifies that snapshots written with and without the
+// metadata wrapper load as JSON and YAML.
+func TestLoadSnapshot(t *testing.T) {
+	wantData := map[string]any{"app": map[string]any{
+		"name": "demo",
+		"port": 8080.0,
+		"tags": []any{"web", []any{"a", "b"}},
+	}}
+	wantMetadata := compiler.Metadata{
+		StartTime:        time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC),
+		EndTime:          time.Date(2026, 2, 14, 10, 0, 1, 0, time.UTC),
+		InputFiles:       []string{"/src/app.csl"},
+		ProviderAliases:  []string{},
+		Errors:           []string{},
+		Warnings:         []string{"W001: unused source"},
+		PerKeyProvenance: map[string]compiler.Provenance{"app": {Source: "/src/app.csl"}},
+		TypeCoercion:     compiler.TypeCoercionStrict,
+		SensitiveKeys:    []string{"app.name"},
+		ProjectRoot:      "/src",
+	}
+
+	tests := []struct {
+		name         string
+		file         string
+		content      string
+		wantMetadata compiler.Metadata
+	}{
+		{name: "json with metadata", file: "out.json", content: testWrappedSnapshotJSON, wantMetadata: wantMetadata},
+		{name: "yaml with metadata", file: "out.yaml", content: testWrappedSnapshotYAML, wantMetadata: wantMetadata},
+		{name: "json", file: "out.json", content: `{"app": {"name": "demo", "port": 8080, "tags": ["web", ["a", "b"]]}}`},
+		{name: "yaml", file: "out.yaml", content: "app:\n  name: demo\n  port: 8080\n  tags: [web, [a, b]]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := writeFile(path, tt.content); err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, err := compiler.LoadSnapshot(path)
+			if err != nil {
+				t.Fatalf("LoadSnapshot() error = %v", err)
+			}
+			if !reflect.DeepEqual(snapshot.Data, wantData) {
+				t.Errorf("Data = %#v, want %#v", snapshot.Data, wantData)
+			}
+			if !reflect.DeepEqual(snapshot.Metadata, tt.wantMetadata) {
+				t.Errorf("Metadata = %+v, want %+v", snapshot.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+// TestLoadSnapshot_Invalid verifies that files that are not snapshots are
+// rejected with ErrInvalidSnapshot.
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "malformed json", content: `{"app": `, wantErr: "unexpected EOF"},
+		{name: "list", content: "- a\n- b\n", wantErr: "not a map at the top level"},
+		{name: "data not a map", content: `{"data": [1], "metadata": {"schema_version": 1}}`, wantErr: "data is not a map"},
+		{name: "newer schema", content: `{"data": {}, "metadata": {"schema_version": 2}}`, wantErr: "unsupported metadata schema_version 2"},
+		{name: "malformed metadata", content: `{"data": {}, "metadata": {"schema_version": 1, "errors": "none"}}`, wantErr: "invalid metadata"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.json")
+			if err := writeFile(path, tt.content); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := compiler.LoadSnapshot(path)
+			if !errors.Is(err, compiler.ErrInvalidSnapshot) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSnapshot() error = %v, want ErrInvalidSnapshot containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := compiler.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil || errors.Is(err, compiler.ErrInvalidSnapshot) {
+		t.Errorf("LoadSnapshot() error = %v, want a read error", err)
+	}
+}
+
+// TestSnapshot_Lookup verifies key path lookup with map keys and list
+// indices.
+func TestSnapshot_Lookup(t *testing.T) {
+	snapshot, err := compiler.ParseSnapshot([]byte(testWrappedSnapshotJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keyPath string
+		want    any
+		wantOK  bool
+	}{
+		{keyPath: "app.name", want: "demo", wantOK: true},
+		{keyPath: "app.port", want: 8080.0, wantOK: true},
+		{keyPath: "app.tags[0]", want: "web", wantOK: true},
+		{keyPath: "app.tags[1][1]", want: "b", wantOK: true},
+		{keyPath: "app", want: snapshot.Data["app"], wantOK: true},
+		{keyPath: "app.missing"},
+		{keyPath: "app.tags[2]"},
+		{keyPath: "app.name.first"},
+		{keyPath: "app.tags[x]"},
+		{keyPath: "app.tags[-1]"},
+		{keyPath: "app..name"},
+		{keyPath: "[0]"},
+		{keyPath: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyPath, func(t *testing.T) {
+			got, ok := snapshot.Lookup(tt.keyPath)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%q) = %#v, %v, want %#v, %v", tt.keyPath, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package compiler
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeStrategy selects what MergeSnapshots does with a key path that both
+// snapshots set to different values.
+//
+// Maps merge key by key whatever the strategy; a conflict is a path where
+// at least one side is not a map, such as two different scalars, two
+// different lists, or a map against a scalar. Equal values never conflict.
+type MergeStrategy string
+
+const (
+	// MergeStrategyError fails the merge with a *MergeConflictError listing
+	// every conflicting key path (default).
+	MergeStrategyError MergeStrategy = "error"
+
+	// MergeStrategyLastWins keeps the second snapshot's value, as the
+	// compiler does for later .csl files.
+	MergeStrategyLastWins MergeStrategy = "last-wins"
+
+	// MergeStrategyFirstWins keeps the first snapshot's value.
+	MergeStrategyFirstWins MergeStrategy = "first-wins"
+)
+
+// ParseMergeStrategy parses a merge strategy name. The empty string yields
+// MergeStrategyError.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case "":
+		return MergeStrategyError, nil
+	case MergeStrategyError, MergeStrategyLastWins, MergeStrategyFirstWins:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid merge strategy %q (valid: error, last-wins, first-wins)", s)
+	}
+}
+
+// MergeConflict describes a key path that two snapshots set to different
+// values.
+type MergeConflict struct {
+	// Path is the conflicting key path, in source map syntax.
+	Path string `json:"path"`
+
+	// First and Second are the values of the first and second snapshot.
+	First  any `json:"first"`
+	Second any `json:"second"`
+
+	// FirstSource and SecondSource are the provenance sources of the
+	// path's top-level key in each snapshot, when recorded.
+	FirstSource  string `json:"first_source,omitempty"`
+	SecondSource string `json:"second_source,omitempty"`
+}
+
+// String formats the conflict as "path: first (source) vs second (source)".
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: %s vs %s", c.Path, describeMergeSide(c.First, c.FirstSource), describeMergeSide(c.Second, c.SecondSource))
+}
+
+// describeMergeSide formats one side of a conflict.
+func describeMergeSide(value any, source string) string {
+	if source == "" {
+		return fmt.Sprintf("%v", value)
+	}
+	return fmt.Sprintf("%v (%s)", value, source)
+}
+
+// MergeConflictError reports the conflicting key paths of a merge with
+// MergeStrategyError. It matches ErrMergeConflict.
+type MergeConflictError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		lines[i] = c.String()
+	}
+	return fmt.Sprintf("%d conflicting key(s): %s", len(e.Conflicts), strings.Join(lines, "; "))
+}
+
+// Unwrap returns ErrMergeConflict.
+func (e *MergeConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
+
+// MergeSnapshots deep-merges two snapshots built separately, such as
+// per-domain snapshots of one platform, with the composition semantics of
+// DeepMerge: maps merge and other values replace. Values set by both
+// snapshots are resolved by strategy; with MergeStrategyLastWins and
+// MergeStrategyFirstWins each conflict is recorded as a warning in the
+// result instead.
+//
+// Provenance is combined: each top-level key keeps the PerKeyProvenance of
+// the snapshot whose value it holds, or of the second snapshot when both
+// contributed to a map. Source maps, when present, are combined the same
+// way key path by key path. Input files, provider aliases, profiles,
+// sensitive keys, errors, and warnings are concatenated without duplicates,
+// and the time range covers both builds. Provider config overrides are
+// combined alias by alias, the second snapshot's winning. TypeCoercion and
+// ProjectRoot are kept when both snapshots agree and left empty otherwise.
+//
+// Neither input is modified.
+func MergeSnapshots(first, second Snapshot, strategy MergeStrategy) (Snapshot, error) {
+	if strategy == "" {
+		strategy = MergeStrategyError
+	}
+	if _, err := ParseMergeStrategy(string(strategy)); err != nil {
+		return Snapshot{}, err
+	}
+
+	m := &snapshotMerger{first: first, second: second, strategy: strategy}
+	if first.SourceMap != nil || second.SourceMap != nil {
+		m.sourceMap = &SourceMap{Version: SourceMapVersion, Entries: make(map[string]SourceMapEntry)}
+	}
+
+	data := make(map[string]any, len(first.Data)+len(second.Data))
+	provenance := make(map[string]Provenance)
+	for _, k := range unionKeys(first.Data, second.Data) {
+		value, owner := m.merge(k, k, first.Data, second.Data)
+		data[k] = value
+		if p, ok := m.provenance(k, owner); ok {
+			provenance[k] = p
+		}
+	}
+
+	if len(m.conflicts) > 0 && strategy == MergeStrategyError {
+		return Snapshot{}, &MergeConflictError{Conflicts: m.conflicts}
+	}
+
+	merged := Snapshot{
+		Data:      data,
+		Metadata:  mergeMetadata(first.Metadata, second.Metadata),
+		SourceMap: m.sourceMap,
+	}
+	merged.Metadata.PerKeyProvenance = provenance
+	for _, c := range m.conflicts {
+		w := Warning{Message: fmt.Sprintf("conflicting values for %s; kept the %s snapshot's value", c, m.keptSide())}
+		merged.Metadata.Warnings = append(merged.Metadata.Warnings, w.String())
+		merged.Metadata.WarningDetails = append(merged.Metadata.WarningDetails, w)
+	}
+	return merged, nil
+}
+
+// mergeSide identifies which snapshot a merged value came from.
+type mergeSide int
+
+const (
+	sideBoth mergeSide = iota
+	sideFirst
+	sideSecond
+)
+
+// snapshotMerger carries the state of one MergeSnapshots call.
+type snapshotMerger struct {
+	first, second Snapshot
+	strategy      MergeStrategy
+	sourceMap     *SourceMap
+	conflicts     []MergeConflict
+}
+
+// keptSide names the snapshot whose value a conflict keeps.
+func (m *snapshotMerger) keptSide() string {
+	if m.strategy == MergeStrategyFirstWins {
+		return "first"
+	}
+	return "second"
+}
+
+// merge merges key of the first and second parent maps at path and
+// returns the merged value and the side it came from.
+func (m *snapshotMerger) merge(path, key string, first, second map[string]any) (any, mergeSide) {
+	a, inFirst := first[key]
+	b, inSecond := second[key]
+	switch {
+	case !inSecond:
+		m.copySourceMap(m.first.SourceMap, path, a)
+		return deepCopyValue(a), sideFirst
+	case !inFirst:
+		m.copySourceMap(m.second.SourceMap, path, b)
+		return deepCopyValue(b), sideSecond
+	}
+
+	aMap, aIsMap := a.(map[string]any)
+	bMap, bIsMap := b.(map[string]any)
+	if aIsMap && bIsMap {
+		if e, ok := m.second.SourceMap.Lookup(path); ok {
+			m.setSourceMap(path, e)
+		} else if e, ok := m.first.SourceMap.Lookup(path); ok {
+			m.setSourceMap(path, e)
+		}
+		merged := make(map[string]any, len(aMap)+len(bMap))
+		for _, k := range unionKeys(aMap, bMap) {
+			merged[k], _ = m.merge(joinKeyPath(path, k), k, aMap, bMap)
+		}
+		return merged, sideBoth
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		top, _, _ := strings.Cut(path, ".")
+		top, _, _ = strings.Cut(top, "[")
+		m.conflicts = append(m.conflicts, MergeConflict{
+			Path:         path,
+			First:        a,
+			Second:       b,
+			FirstSource:  m.first.Metadata.PerKeyProvenance[top].Source,
+			SecondSource: m.second.Metadata.PerKeyProvenance[top].Source,
+		})
+		if m.strategy == MergeStrategyFirstWins {
+			m.copySourceMap(m.first.SourceMap, path, a)
+			return deepCopyValue(a), sideFirst
+		}
+	}
+	m.copySourceMap(m.second.SourceMap, path, b)
+	return deepCopyValue(b), sideSecond
+}
+
+// provenance returns the provenance of top-level key k given the side its
+// value came from.
+func (m *snapshotMerger) provenance(k string, owner mergeSide) (Provenance, bool) {
+	if owner != sideFirst {
+		if p, ok := m.second.Metadata.PerKeyProvenance[k]; ok {
+			return p, true
+		}
+	}
+	if owner != sideSecond {
+		p, ok := m.first.Metadata.PerKeyProvenance[k]
+		return p, ok
+	}
+	return Provenance{}, false
+}
+
+// copySourceMap copies the entries of src for path and every path nested
+// in value.
+func (m *snapshotMerger) copySourceMap(src *SourceMap, path string, value any) {
+	if m.sourceMap == nil {
+		return
+	}
+	if e, ok := src.Lookup(path); ok {
+		m.setSourceMap(path, e)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, child := range v {
+			m.copySourceMap(src, joinKeyPath(path, k), child)
+		}
+	case []any:
+		for i, child := range v {
+			m.copySourceMap(src, fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	}
+}
+
+// setSourceMap records the merged source map entry for path.
+func (m *snapshotMerger) setSourceMap(path string, e SourceMapEntry) {
+	if m.sourceMap != nil {
+		m.sourceMap.Entries[path] = e
+	}
+}
+
+// unionKeys returns the keys of a and b in sorted order, so conflicts are
+// reported deterministically.
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeMetadata combines the metadata of two snapshots, except for
+// PerKeyProvenance, which depends on the merged data.
+func mergeMetadata(a, b Metadata) Metadata {
+	merged := Metadata{
+		InputFiles:      appendUnique(a.InputFiles, b.InputFiles),
+		ProviderAliases: appendUnique(a.ProviderAliases, b.ProviderAliases),
+		StartTime:       earliest(a.StartTime, b.StartTime),
+		EndTime:         latest(a.EndTime, b.EndTime),
+		Errors:          appendUnique(a.Errors, b.Errors),
+		Warnings:        appendUnique(a.Warnings, b.Warnings),
+		WarningDetails:  appendUnique(a.WarningDetails, b.WarningDetails),
+		SensitiveKeys:   appendUnique(a.SensitiveKeys, b.SensitiveKeys),
+		Profiles:        appendUnique(a.Profiles, b.Profiles),
+	}
+	sort.Strings(merged.SensitiveKeys)
+	if a.TypeCoercion == b.TypeCoercion {
+		merged.TypeCoercion = a.TypeCoercion
+	}
+	if a.ProjectRoot == b.ProjectRoot {
+		merged.ProjectRoot = a.ProjectRoot
+	}
+	if len(a.ProviderConfigOverrides)+len(b.ProviderConfigOverrides) > 0 {
+		merged.ProviderConfigOverrides = make(map[string]map[string]any)
+		for _, overrides := range []map[string]map[string]any{a.ProviderConfigOverrides, b.ProviderConfigOverrides} {
+			for alias, config := range copyProviderConfigOverrides(overrides) {
+				merged.ProviderConfigOverrides[alias] = config
+			}
+		}
+	}
+	return merged
+}
+
+// appendUnique returns the elements of a followed by those of b that are
+// not already present. The result is never nil.
+func appendUnique[T comparable](a, b []T) []T {
+	merged := make([]T, 0, len(a)+len(b))
+	for _, list := range [][]T{a, b} {
+		for _, v := range list {
+			if !slices.Contains(merged, v) {
+				merged = append(merged, v)
+			}
+		}
+	}
+	return merged
+}
+
+// earliest returns the earlier of two times, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
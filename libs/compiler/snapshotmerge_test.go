@@ -0,0 +1,226 @@
+package compiler_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// mergeTestSnapshots returns two per-domain snapshots that share the app
+// map and disagree on app.port.
+func mergeTestSnapshots() (compiler.Snapshot, compiler.Snapshot) {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	network := compiler.Snapshot{
+		Data: map[string]any{
+			"app":     map[string]any{"name": "demo", "port": 8080},
+			"network": map[string]any{"cidr": "10.0.0.0/16"},
+		},
+		Metadata: compiler.Metadata{
+			InputFiles:      []string{"/net/app.csl", "/net/network.csl"},
+			ProviderAliases: []string{"vpc"},
+			StartTime:       t0,
+			EndTime:         t0.Add(time.Second),
+			SensitiveKeys:   []string{"network.key"},
+			TypeCoercion:    compiler.TypeCoercionOff,
+			PerKeyProvenance: map[string]compiler.Provenance{
+				"app":     {Source: "/net/app.csl"},
+				"network": {Source: "/net/network.csl", ProviderAlias: "vpc"},
+			},
+		},
+		SourceMap: &compiler.SourceMap{Version: compiler.SourceMapVersion, Entries: map[string]compiler.SourceMapEntry{
+			"app":          {Location: compiler.SourceLocation{File: "/net/app.csl", Line: 1}},
+			"app.name":     {Location: compiler.SourceLocation{File: "/net/app.csl", Line: 2}},
+			"app.port":     {Location: compiler.SourceLocation{File: "/net/app.csl", Line: 3}},
+			"network":      {Location: compiler.SourceLocation{File: "/net/network.csl", Line: 1}},
+			"network.cidr": {Location: compiler.SourceLocation{File: "/net/network.csl", Line: 2}},
+		}},
+	}
+	data := compiler.Snapshot{
+		Data: map[string]any{
+			"app": map[string]any{"name": "demo", "port": 9090, "replicas": 3},
+			"db":  map[string]any{"host": "db.internal"},
+		},
+		Metadata: compiler.Metadata{
+			InputFiles:      []string{"/data/app.csl", "/data/db.csl"},
+			ProviderAliases: []string{"vpc", "vault"},
+			StartTime:       t0.Add(-time.Minute),
+			EndTime:         t0.Add(2 * time.Second),
+			SensitiveKeys:   []string{"db.password"},
+			TypeCoercion:    compiler.TypeCoercionStrict,
+			PerKeyProvenance: map[string]compiler.Provenance{
+				"app": {Source: "/data/app.csl"},
+				"db":  {Source: "/data/db.csl", ProviderAlias: "vault"},
+			},
+		},
+		SourceMap: &compiler.SourceMap{Version: compiler.SourceMapVersion, Entries: map[string]compiler.SourceMapEntry{
+			"app":          {Location: compiler.SourceLocation{File: "/data/app.csl", Line: 1}},
+			"app.name":     {Location: compiler.SourceLocation{File: "/data/app.csl", Line: 2}},
+			"app.port":     {Location: compiler.SourceLocation{File: "/data/app.csl", Line: 3}},
+			"app.replicas": {Location: compiler.SourceLocation{File: "/data/app.csl", Line: 4}},
+			"db":           {Location: compiler.SourceLocation{File: "/data/db.csl", Line: 1}},
+			"db.host":      {Location: compiler.SourceLocation{File: "/data/db.csl", Line: 2}},
+		}},
+	}
+	return network, data
+}
+
+func TestMergeSnapshots_ConflictError(t *testing.T) {
+	network, data := mergeTestSnapshots()
+
+	_, err := compiler.MergeSnapshots(network, data, compiler.MergeStrategyError)
+	if !errors.Is(err, compiler.ErrMergeConflict) {
+		t.Fatalf("MergeSnapshots() error = %v, want ErrMergeConflict", err)
+	}
+	var conflictErr *compiler.MergeConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("error %T is not a *MergeConflictError", err)
+	}
+	want := []compiler.MergeConflict{{
+		Path: "app.port", First: 8080, Second: 9090,
+		FirstSource: "/net/app.csl", SecondSource: "/data/app.csl",
+	}}
+	if !reflect.DeepEqual(conflictErr.Conflicts, want) {
+		t.Errorf("Conflicts = %+v, want %+v", conflictErr.Conflicts, want)
+	}
+	if !strings.Contains(err.Error(), "app.port: 8080 (/net/app.csl) vs 9090 (/data/app.csl)") {
+		t.Errorf("error message = %q", err.Error())
+	}
+
+	// The default strategy is the same
+	if _, err := compiler.MergeSnapshots(network, data, ""); !errors.Is(err, compiler.ErrMergeConflict) {
+		t.Errorf("MergeSnapshots() with empty strategy error = %v, want ErrMergeConflict", err)
+	}
+}
+
+func TestMergeSnapshots_LastWins(t *testing.T) {
+	network, data := mergeTestSnapshots()
+
+	merged, err := compiler.MergeSnapshots(network, data, compiler.MergeStrategyLastWins)
+	if err != nil {
+		t.Fatalf("MergeSnapshots() error = %v", err)
+	}
+
+	wantData := map[string]any{
+		"app":     map[string]any{"name": "demo", "port": 9090, "replicas": 3},
+		"db":      map[string]any{"host": "db.internal"},
+		"network": map[string]any{"cidr": "10.0.0.0/16"},
+	}
+	if !reflect.DeepEqual(merged.Data, wantData) {
+		t.Errorf("Data = %v, want %v", merged.Data, wantData)
+	}
+
+	meta := merged.Metadata
+	wantProvenance := map[string]compiler.Provenance{
+		"app":     {Source: "/data/app.csl"},
+		"db":      {Source: "/data/db.csl", ProviderAlias: "vault"},
+		"network": {Source: "/net/network.csl", ProviderAlias: "vpc"},
+	}
+	if !reflect.DeepEqual(meta.PerKeyProvenance, wantProvenance) {
+		t.Errorf("PerKeyProvenance = %v, want %v", meta.PerKeyProvenance, wantProvenance)
+	}
+	if want := []string{"/net/app.csl", "/net/network.csl", "/data/app.csl", "/data/db.csl"}; !reflect.DeepEqual(meta.InputFiles, want) {
+		t.Errorf("InputFiles = %v, want %v", meta.InputFiles, want)
+	}
+	if want := []string{"vpc", "vault"}; !reflect.DeepEqual(meta.ProviderAliases, want) {
+		t.Errorf("ProviderAliases = %v, want %v", meta.ProviderAliases, want)
+	}
+	if want := []string{"db.password", "network.key"}; !reflect.DeepEqual(meta.SensitiveKeys, want) {
+		t.Errorf("SensitiveKeys = %v, want %v", meta.SensitiveKeys, want)
+	}
+	if !meta.StartTime.Equal(data.Metadata.StartTime) || !meta.EndTime.Equal(data.Metadata.EndTime) {
+		t.Errorf("time range = %v..%v, want the range covering both builds", meta.StartTime, meta.EndTime)
+	}
+	if meta.TypeCoercion != "" {
+		t.Errorf("TypeCoercion = %q, want empty for differing policies", meta.TypeCoercion)
+	}
+	if len(meta.WarningDetails) != 1 || !strings.Contains(meta.Warnings[0], "app.port") || !strings.Contains(meta.Warnings[0], "second") {
+		t.Errorf("Warnings = %v, want one conflict warning keeping the second value", meta.Warnings)
+	}
+
+	entries := merged.SourceMap.Entries
+	for path, file := range map[string]string{
+		"app": "/data/app.csl", "app.name": "/data/app.csl", "app.port": "/data/app.csl",
+		"app.replicas": "/data/app.csl", "network.cidr": "/net/network.csl", "db.host": "/data/db.csl",
+	} {
+		if got := entries[path].Location.File; got != file {
+			t.Errorf("SourceMap[%s] = %q, want %q", path, got, file)
+		}
+	}
+
+	// Inputs are not modified
+	if network.Data["app"].(map[string]any)["port"] != 8080 || len(network.SourceMap.Entries) != 5 {
+		t.Error("MergeSnapshots modified its first input")
+	}
+}
+
+func TestMergeSnapshots_FirstWins(t *testing.T) {
+	network, data := mergeTestSnapshots()
+
+	merged, err := compiler.MergeSnapshots(network, data, compiler.MergeStrategyFirstWins)
+	if err != nil {
+		t.Fatalf("MergeSnapshots() error = %v", err)
+	}
+	if port, _ := merged.Lookup("app.port"); port != 8080 {
+		t.Errorf("app.port = %v, want 8080", port)
+	}
+	if replicas, _ := merged.Lookup("app.replicas"); replicas != 3 {
+		t.Errorf("app.replicas = %v, want 3", replicas)
+	}
+	if got := merged.SourceMap.Entries["app.port"].Location.File; got != "/net/app.csl" {
+		t.Errorf("SourceMap[app.port] = %q, want %q", got, "/net/app.csl")
+	}
+	if !strings.Contains(merged.Metadata.Warnings[0], "first") {
+		t.Errorf("Warnings = %v, want a conflict warning keeping the first value", merged.Metadata.Warnings)
+	}
+}
+
+func TestMergeSnapshots_ReplacedValues(t *testing.T) {
+	first := compiler.Snapshot{
+		Data: map[string]any{"tags": []any{"a", "b", "c"}, "same": []any{"x"}, "region": "eu"},
+		SourceMap: &compiler.SourceMap{Entries: map[string]compiler.SourceMapEntry{
+			"tags": {}, "tags[0]": {}, "tags[1]": {}, "tags[2]": {Location: compiler.SourceLocation{File: "first.csl"}},
+		}},
+	}
+	second := compiler.Snapshot{
+		Data: map[string]any{"tags": []any{"z"}, "same": []any{"x"}, "region": map[string]any{"name": "eu"}},
+	}
+
+	merged, err := compiler.MergeSnapshots(first, second, compiler.MergeStrategyLastWins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"tags": []any{"z"}, "same": []any{"x"}, "region": map[string]any{"name": "eu"}}; !reflect.DeepEqual(merged.Data, want) {
+		t.Errorf("Data = %v, want %v", merged.Data, want)
+	}
+	// Equal lists do not conflict; a list and a map against a scalar do
+	if len(merged.Metadata.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want conflicts for region and tags", merged.Metadata.Warnings)
+	}
+	if _, ok := merged.SourceMap.Lookup("tags[2]"); ok {
+		t.Error("source map keeps an entry for a replaced list element")
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	for in, want := range map[string]compiler.MergeStrategy{
+		"":           compiler.MergeStrategyError,
+		"error":      compiler.MergeStrategyError,
+		"Last-Wins":  compiler.MergeStrategyLastWins,
+		"first-wins": compiler.MergeStrategyFirstWins,
+	} {
+		got, err := compiler.ParseMergeStrategy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMergeStrategy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := compiler.ParseMergeStrategy("union"); err == nil {
+		t.Error("ParseMergeStrategy(\"union\") succeeded, want error")
+	}
+	if _, err := compiler.MergeSnapshots(compiler.Snapshot{}, compiler.Snapshot{}, "union"); err == nil {
+		t.Error("MergeSnapshots() with an invalid strategy succeeded, want error")
+	}
+}
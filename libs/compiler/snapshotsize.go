@@ -0,0 +1,57 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// checkSnapshotSize returns an error wrapping ErrSnapshotTooLarge if data
+// would encode to more than limit bytes of compact JSON.
+func checkSnapshotSize(data map[string]any, limit int64) error {
+	if size := estimateSize(data, limit); size > limit {
+		return fmt.Errorf("%w: resolved data exceeds %d bytes (Options.MaxSnapshotBytes)", ErrSnapshotTooLarge, limit)
+	}
+	return nil
+}
+
+// estimateSize approximates the compact JSON size of v without encoding it.
+// Escapes are not counted, so the estimate is a lower bound for strings
+// that need them. The walk stops early once the total passes limit.
+func estimateSize(v any, limit int64) int64 {
+	var size int64
+	var walk func(v any)
+	walk = func(v any) {
+		if size > limit {
+			return
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			size += 2 + int64(max(len(val)-1, 0)) // braces and commas
+			for k, item := range val {
+				size += int64(len(k)) + 3 // quotes and colon
+				walk(item)
+			}
+		case []any:
+			size += 2 + int64(max(len(val)-1, 0))
+			for _, item := range val {
+				walk(item)
+			}
+		case string:
+			size += int64(len(val)) + 2
+		case bool:
+			size += 5
+		case nil:
+			size += 4
+		case int:
+			size += int64(len(strconv.Itoa(val)))
+		case int64:
+			size += int64(len(strconv.FormatInt(val, 10)))
+		case float64:
+			size += int64(len(strconv.FormatFloat(val, 'g', -1, 64)))
+		default:
+			size += int64(len(fmt.Sprint(val)))
+		}
+	}
+	walk(v)
+	return size
+}
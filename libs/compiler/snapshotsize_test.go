@@ -0,0 +1,50 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+func TestCompile_MaxSnapshotBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	src := "app:\n  blob: '" + strings.Repeat("x", 1000) + "'\n"
+	if err := writeFile(path, src); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "unlimited", limit: 0},
+		{name: "within limit", limit: 2000},
+		{name: "exceeds limit", limit: 500, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: testutil.NewFakeProviderRegistry(),
+				MaxSnapshotBytes: tt.limit,
+			})
+
+			if !tt.wantErr {
+				if result.HasErrors() {
+					t.Fatalf("unexpected error: %v", result.Error())
+				}
+				return
+			}
+			if !errors.Is(result.Error(), compiler.ErrSnapshotTooLarge) {
+				t.Errorf("error = %v, want ErrSnapshotTooLarge", result.Error())
+			}
+		})
+	}
+}
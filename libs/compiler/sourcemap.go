@@ -0,0 +1,349 @@
+package compiler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// SourceMapVersion is the version of the source map format produced by the compiler.
+const SourceMapVersion = 1
+
+// SourceMap maps every output key path of a snapshot back to the .csl source
+// that defined it.
+//
+// Key paths use dots between map keys and [n] for list indices, e.g.
+// "app.server.port" or "app.tags[0]". Keys produced by a reference (for example
+// the children of "db: @base:database") are attributed to the reference site.
+type SourceMap struct {
+	// Version identifies the source map format.
+	Version int `json:"version"`
+
+	// Entries maps each output key path to its origin.
+	Entries map[string]SourceMapEntry `json:"entries"`
+}
+
+// SourceMapEntry records the origin of a single output key.
+type SourceMapEntry struct {
+	// Location is the source range of the entry that produced the key.
+	Location SourceLocation `json:"location"`
+
+	// References lists the reference expressions (formatted "@alias:path")
+	// that contributed the key's value, in source order.
+	References []string `json:"references,omitempty"`
+
+	// Comment is the .csl comment block directly above the key's definition,
+	// without the '#' markers. A later definition without a comment keeps
+	// the earlier one's; keys inherited from an ancestor or a reference have
+	// no comment.
+	Comment string `json:"comment,omitempty"`
+}
+
+// SourceLocation identifies a range in a .csl source file. Lines and columns are 1-based.
+type SourceLocation struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+}
+
+// Lookup returns the entry for keyPath.
+func (m *SourceMap) Lookup(keyPath string) (SourceMapEntry, bool) {
+	if m == nil {
+		return SourceMapEntry{}, false
+	}
+	e, ok := m.Entries[keyPath]
+	return e, ok
+}
+
+// Keys returns all key paths in the source map in sorted order.
+func (m *SourceMap) Keys() []string {
+	if m == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(m.Entries))
+	for k := range m.Entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sourceMapBuilder accumulates source map entries while walking ASTs in
+// merge order, mirroring the compiler's last-wins composition semantics.
+type sourceMapBuilder struct {
+	entries map[string]SourceMapEntry
+	// spreads records spread references by the key path they expand into
+	// ("" for the root).
+	spreads map[string][]spreadSite
+}
+
+type spreadSite struct {
+	location  SourceLocation
+	reference string
+}
+
+func newSourceMapBuilder() *sourceMapBuilder {
+	return &sourceMapBuilder{
+		entries: make(map[string]SourceMapEntry),
+		spreads: make(map[string][]spreadSite),
+	}
+}
+
+// buildSourceMap maps every key path present in data to its origin, walking
+// the parsed input files in order. Files are parsed here if the compilation
+// did not parse them (e.g. when imports were resolved). Key paths set by the
+// selected profiles are attributed to their definitions in the profile.
+func buildSourceMap(ctx context.Context, inputFiles []string, parsed []pipeline.ParsedFile, profiles []string, data map[string]any) (*SourceMap, error) {
+	if parsed == nil {
+		parsed = pipeline.ParseFiles(ctx, inputFiles, 0)
+	}
+
+	b := newSourceMapBuilder()
+	for _, file := range parsed {
+		if file.Err != nil {
+			return nil, fmt.Errorf("failed to parse %q for source map: %w", file.Path, file.Err)
+		}
+		if file.AST == nil {
+			continue
+		}
+		b.addFile(file.AST)
+	}
+	for _, name := range profiles {
+		b.applyProfile(name)
+	}
+	return b.finalize(data), nil
+}
+
+// addFile records all sections and root spreads of a parsed file.
+func (b *sourceMapBuilder) addFile(tree *ast.AST) {
+	for _, stmt := range tree.Statements {
+		switch node := stmt.(type) {
+		case *ast.SectionDecl:
+			if node.Value != nil {
+				b.addValue(node.Name, node.SourceSpan, node.Doc, node.Value)
+				continue
+			}
+			b.addEntries(node.Name, node.SourceSpan, node.Doc, node.Entries)
+		case *ast.SpreadStmt:
+			if node.Prefix != "" {
+				b.addValue(node.Prefix, node.SourceSpan, "", node.Reference)
+				continue
+			}
+			b.addSpread("", node.Reference)
+		}
+	}
+}
+
+// applyProfile moves the entries and spreads recorded beneath the profile
+// name onto the key paths the profile sets. Maps in the profile merge with
+// what is already recorded; any other value replaces it.
+func (b *sourceMapBuilder) applyProfile(name string) {
+	prefix := joinKeyPath(ProfileSection, name)
+	var keys []string
+	for k := range b.entries {
+		if isDescendant(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	// Parents sort before their children
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		target := strings.TrimPrefix(k, prefix+".")
+		if !slices.ContainsFunc(keys, func(child string) bool { return strings.HasPrefix(child, k+".") }) {
+			b.dropDescendants(target)
+		}
+		b.entries[target] = b.entries[k]
+	}
+	for k, sites := range b.spreads {
+		if isDescendant(k, prefix) {
+			target := strings.TrimPrefix(k, prefix+".")
+			b.spreads[target] = append(b.spreads[target], sites...)
+		}
+	}
+}
+
+// addValue records key with the given definition span and doc comment and
+// descends into value.
+func (b *sourceMapBuilder) addValue(key string, span ast.SourceSpan, doc string, value ast.Expr) {
+	if m, ok := value.(*ast.MapExpr); ok {
+		b.addEntries(key, span, doc, m.Entries)
+		return
+	}
+
+	// A non-map value replaces whatever was defined beneath key before.
+	b.dropDescendants(key)
+
+	entry := SourceMapEntry{Location: locationOf(span), Comment: cmp.Or(doc, b.entries[key].Comment)}
+	switch v := unmark(value).(type) {
+	case *ast.ReferenceExpr:
+		entry.References = []string{formatReference(v)}
+	case *ast.CallExpr:
+		entry.References = callReferences(v, nil)
+	case *ast.ListExpr:
+		for i, elem := range v.Elements {
+			b.addValue(fmt.Sprintf("%s[%d]", key, i), elem.Span(), "", elem)
+		}
+	}
+	b.entries[key] = entry
+}
+
+// addEntries records a map-valued key. Maps deep-merge, so existing children
+// are kept unless overridden by the new entries.
+func (b *sourceMapBuilder) addEntries(key string, span ast.SourceSpan, doc string, entries []ast.MapEntry) {
+	if existing, ok := b.entries[key]; ok && len(existing.References) > 0 {
+		// A reference previously produced this key; literal entries now own it.
+		b.dropDescendants(key)
+	}
+	b.entries[key] = SourceMapEntry{Location: locationOf(span), Comment: cmp.Or(doc, b.entries[key].Comment)}
+
+	for _, entry := range entries {
+		if entry.Spread {
+			if ref, ok := entry.Value.(*ast.ReferenceExpr); ok {
+				b.addSpread(key, ref)
+			}
+			continue
+		}
+		b.addValue(joinKeyPath(key, entry.Key), entry.SourceSpan, entry.Doc, entry.Value)
+	}
+}
+
+// callReferences appends the references among call's arguments, including
+// those of nested calls, to refs.
+func callReferences(call *ast.CallExpr, refs []string) []string {
+	for _, arg := range call.Args {
+		switch a := unmark(arg).(type) {
+		case *ast.ReferenceExpr:
+			refs = append(refs, formatReference(a))
+		case *ast.CallExpr:
+			refs = callReferences(a, refs)
+		}
+	}
+	return refs
+}
+
+func (b *sourceMapBuilder) addSpread(key string, ref *ast.ReferenceExpr) {
+	b.spreads[key] = append(b.spreads[key], spreadSite{
+		location:  locationOf(ref.SourceSpan),
+		reference: formatReference(ref),
+	})
+}
+
+// dropDescendants removes entries and spreads nested beneath key.
+func (b *sourceMapBuilder) dropDescendants(key string) {
+	for k := range b.entries {
+		if isDescendant(k, key) {
+			delete(b.entries, k)
+		}
+	}
+	for k := range b.spreads {
+		if k == key || isDescendant(k, key) {
+			delete(b.spreads, k)
+		}
+	}
+}
+
+// finalize produces entries for exactly the key paths present in data.
+// Keys without a literal definition inherit from the nearest ancestor: a
+// spread into that ancestor, or the reference that produced it.
+func (b *sourceMapBuilder) finalize(data map[string]any) *SourceMap {
+	sm := &SourceMap{
+		Version: SourceMapVersion,
+		Entries: make(map[string]SourceMapEntry),
+	}
+	b.walk("", data, sm.Entries)
+	return sm
+}
+
+func (b *sourceMapBuilder) walk(key string, value any, out map[string]SourceMapEntry) {
+	if key != "" {
+		out[key] = b.attribute(key)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for k, child := range v {
+			b.walk(joinKeyPath(key, k), child, out)
+		}
+	case []any:
+		for i, child := range v {
+			b.walk(fmt.Sprintf("%s[%d]", key, i), child, out)
+		}
+	}
+}
+
+func (b *sourceMapBuilder) attribute(key string) SourceMapEntry {
+	if e, ok := b.entries[key]; ok {
+		return e
+	}
+
+	for ancestor := parentKeyPath(key); ; ancestor = parentKeyPath(ancestor) {
+		if sites := b.spreads[ancestor]; len(sites) > 0 {
+			refs := make([]string, 0, len(sites))
+			for _, s := range sites {
+				refs = append(refs, s.reference)
+			}
+			return SourceMapEntry{Location: sites[len(sites)-1].location, References: refs}
+		}
+		if e, ok := b.entries[ancestor]; ok && ancestor != "" {
+			e.Comment = ""
+			return e
+		}
+		if ancestor == "" {
+			return SourceMapEntry{}
+		}
+	}
+}
+
+func locationOf(span ast.SourceSpan) SourceLocation {
+	return SourceLocation{
+		File:      span.Filename,
+		Line:      span.StartLine,
+		Column:    span.StartCol,
+		EndLine:   span.EndLine,
+		EndColumn: span.EndCol,
+	}
+}
+
+func unmark(expr ast.Expr) ast.Expr {
+	if m, ok := expr.(*ast.MarkedExpr); ok {
+		return m.Expr
+	}
+	return expr
+}
+
+func formatReference(ref *ast.ReferenceExpr) string {
+	return fmt.Sprintf("@%s:%s", ref.Alias, strings.Join(ref.Path, "."))
+}
+
+func joinKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// parentKeyPath returns the key path one level up, or "" at the top level.
+func parentKeyPath(key string) string {
+	if strings.HasSuffix(key, "]") {
+		if i := strings.LastIndex(key, "["); i >= 0 {
+			return key[:i]
+		}
+	}
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+func isDescendant(key, ancestor string) bool {
+	return strings.HasPrefix(key, ancestor+".") || strings.HasPrefix(key, ancestor+"[")
+}
@@ -0,0 +1,146 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_SourceMap verifies that every output key is attributed to the
+// source entry, or reference, that produced it.
+func TestCompile_SourceMap(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "01-base.csl")
+	second := filepath.Join(dir, "02-override.csl")
+
+	if err := writeFile(first, "app:\n  name: 'demo'\n  port: 8080\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	if err := writeFile(second, "app:\n  port: 9090\n  db: @base:database\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	provider := testutil.NewFakeProvider("base")
+	provider.FetchResponses["database"] = map[string]any{"host": "localhost"}
+	registry := testutil.NewFakeProviderRegistry()
+	registry.AddProvider("base", provider)
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             dir,
+		ProviderRegistry: registry,
+		SourceMap:        true,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	sm := result.Snapshot.SourceMap
+	if sm == nil {
+		t.Fatal("expected source map")
+	}
+	if sm.Version != compiler.SourceMapVersion {
+		t.Errorf("Version = %d, want %d", sm.Version, compiler.SourceMapVersion)
+	}
+
+	wantKeys := []string{"app", "app.db", "app.db.host", "app.name", "app.port"}
+	if got := sm.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Fatalf("Keys() = %v, want %v", got, wantKeys)
+	}
+
+	tests := []struct {
+		key  string
+		file string
+		line int
+		refs []string
+	}{
+		{"app.name", first, 2, nil},
+		{"app.port", second, 2, nil},
+		{"app.db", second, 3, []string{"@base:database"}},
+		{"app.db.host", second, 3, []string{"@base:database"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			entry, ok := sm.Lookup(tt.key)
+			if !ok {
+				t.Fatalf("no entry for %q", tt.key)
+			}
+			if entry.Location.File != tt.file || entry.Location.Line != tt.line {
+				t.Errorf("location = %s:%d, want %s:%d", entry.Location.File, entry.Location.Line, tt.file, tt.line)
+			}
+			if !reflect.DeepEqual(entry.References, tt.refs) {
+				t.Errorf("References = %v, want %v", entry.References, tt.refs)
+			}
+		})
+	}
+}
+
+// TestCompile_SourceMapDisabled verifies no source map is produced by default.
+func TestCompile_SourceMapDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "app:\n  name: 'demo'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: compiler.NewProviderRegistry(),
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+	if result.Snapshot.SourceMap != nil {
+		t.Errorf("expected no source map, got %+v", result.Snapshot.SourceMap)
+	}
+}
+
+// TestCompile_SourceMapComments verifies that comment blocks above .csl keys
+// are recorded on the keys they document, survive redefinitions without a
+// comment, and are not inherited by keys beneath a reference.
+func TestCompile_SourceMapComments(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "01-base.csl")
+	second := filepath.Join(dir, "02-override.csl")
+
+	if err := writeFile(first, "# The application\napp:\n  # Display name\n  name: 'demo'\n  # Listen port\n  port: 8080\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	if err := writeFile(second, "app:\n  port: 9090\n  # Database from the base provider\n  db: @base:database\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	provider := testutil.NewFakeProvider("base")
+	provider.FetchResponses["database"] = map[string]any{"host": "localhost"}
+	registry := testutil.NewFakeProviderRegistry()
+	registry.AddProvider("base", provider)
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             dir,
+		ProviderRegistry: registry,
+		SourceMap:        true,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]string{
+		"app":         "The application",
+		"app.name":    "Display name",
+		"app.port":    "Listen port",
+		"app.db":      "Database from the base provider",
+		"app.db.host": "",
+	}
+	for key, comment := range want {
+		entry, ok := result.Snapshot.SourceMap.Lookup(key)
+		if !ok {
+			t.Errorf("no entry for %q", key)
+			continue
+		}
+		if entry.Comment != comment {
+			t.Errorf("%s Comment = %q, want %q", key, entry.Comment, comment)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCompile_PrefixedSpread verifies that '@alias:path as prefix' places
+// each referenced tree under its prefix, so trees with the same keys do
+// not collide.
+func TestCompile_PrefixedSpread(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"network.yaml": "network:\n  app:\n    owner: network\n  vpc:\n    cidr: 10.0.0.0/16\n",
+		"data.yaml":    "app:\n  owner: data\n",
+		"platform.csl": "source:\n  alias: 'network'\n  type: 'datafile'\n  path: '" + filepath.Join(dir, "network.yaml") + "'\n\n" +
+			"source:\n  alias: 'data'\n  type: 'datafile'\n  path: '" + filepath.Join(dir, "data.yaml") + "'\n\n" +
+			"teams:\n  count: 2\n\n" +
+			"@network:network as teams.network\n" +
+			"@data:app as teams.data\n",
+		"zz-app.csl": "app:\n  name: 'demo'\n",
+	} {
+		if err := writeFile(filepath.Join(dir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:                 dir,
+		ProviderRegistry:     compiler.NewProviderRegistry(),
+		ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+		SourceMap:            true,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Error())
+	}
+
+	want := map[string]any{
+		"app": map[string]any{"name": "demo"},
+		"teams": map[string]any{
+			"count": "2",
+			"network": map[string]any{
+				"app": map[string]any{"owner": "network"},
+				"vpc": map[string]any{"cidr": "10.0.0.0/16"},
+			},
+			"data": map[string]any{"owner": "data"},
+		},
+	}
+	if !reflect.DeepEqual(result.Snapshot.Data, want) {
+		t.Errorf("Data = %#v, want %#v", result.Snapshot.Data, want)
+	}
+
+	entry, ok := result.Snapshot.SourceMap.Lookup("teams.network.vpc.cidr")
+	if !ok {
+		t.Fatal("source map has no entry for teams.network.vpc.cidr")
+	}
+	if entry.Location.Line != 14 || !reflect.DeepEqual(entry.References, []string{"@network:network"}) {
+		t.Errorf("SourceMap[teams.network.vpc.cidr] = %+v, want line 14 referencing @network:network", entry)
+	}
+}
@@ -14,6 +14,9 @@ package bench
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
@@ -171,6 +174,38 @@ func BenchmarkCompileEmpty(b *testing.B) {
 	}
 }
 
+// BenchmarkCompileLayeredSpreads benchmarks a section built from many
+// spread references over large provider maps, exercising the resolver's
+// merge pass.
+func BenchmarkCompileLayeredSpreads(b *testing.B) {
+	const layers = 20
+
+	provider := testutil.NewFakeProvider("base")
+	var source strings.Builder
+	source.WriteString("source:\n  alias: 'base'\n  type: 'fake'\n\napp:\n")
+	for i := 0; i < layers; i++ {
+		provider.FetchResponses[fmt.Sprintf("layer%d", i)] = generateLargeConfig(100, 3)
+		fmt.Fprintf(&source, "  @base:layer%d\n", i)
+	}
+
+	path := filepath.Join(b.TempDir(), "app.csl")
+	if err := os.WriteFile(path, []byte(source.String()), 0600); err != nil {
+		b.Fatal(err)
+	}
+
+	registry := testutil.NewFakeProviderRegistry()
+	registry.AddProvider("base", provider)
+	opts := compiler.Options{Path: path, ProviderRegistry: registry}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if result := compiler.Compile(context.Background(), opts); result.HasErrors() {
+			b.Fatal(result.Error())
+		}
+	}
+}
+
 // Helper functions
 
 // generateLargeConfig creates a nested map with specified depth and keys per level.
@@ -0,0 +1,162 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// scaleSizes are the leaf-key counts used by the scale benchmarks.
+var scaleSizes = []int{10_000, 100_000}
+
+// BenchmarkScaleMerge benchmarks deep-merging two configurations of n leaf
+// keys that overlap on half of their sections.
+func BenchmarkScaleMerge(b *testing.B) {
+	for _, n := range scaleSizes {
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			dst := generateScaleConfig(n, "")
+			src := generateScaleConfig(n, "override")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = compiler.DeepMerge(dst, src)
+			}
+			reportKeys(b, n)
+		})
+	}
+}
+
+// BenchmarkScaleCompile benchmarks parsing and compiling a single .csl file
+// declaring n literal leaf keys.
+func BenchmarkScaleCompile(b *testing.B) {
+	for _, n := range scaleSizes {
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "app.csl")
+			if err := os.WriteFile(path, []byte(generateScaleSource(n)), 0600); err != nil {
+				b.Fatal(err)
+			}
+			opts := compiler.Options{Path: path, ProviderRegistry: compiler.NewProviderRegistry()}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if result := compiler.Compile(context.Background(), opts); result.HasErrors() {
+					b.Fatal(result.Error())
+				}
+			}
+			reportKeys(b, n)
+		})
+	}
+}
+
+// BenchmarkScaleResolve benchmarks resolving references into a provider that
+// serves n leaf keys, split across one map reference per section.
+func BenchmarkScaleResolve(b *testing.B) {
+	for _, n := range scaleSizes {
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			data := generateScaleConfig(n, "")
+			provider := testutil.NewFakeProvider("base")
+
+			var source strings.Builder
+			source.WriteString("source:\n  alias: 'base'\n  type: 'fake'\n\n")
+			for section, values := range data {
+				provider.FetchResponses["config/"+section] = values
+				fmt.Fprintf(&source, "%s:\n  @base:config.%s\n", section, section)
+			}
+
+			path := filepath.Join(b.TempDir(), "app.csl")
+			if err := os.WriteFile(path, []byte(source.String()), 0600); err != nil {
+				b.Fatal(err)
+			}
+
+			registry := testutil.NewFakeProviderRegistry()
+			registry.AddProvider("base", provider)
+			opts := compiler.Options{Path: path, ProviderRegistry: registry}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if result := compiler.Compile(context.Background(), opts); result.HasErrors() {
+					b.Fatal(result.Error())
+				}
+			}
+			reportKeys(b, n)
+		})
+	}
+}
+
+// scaleSectionKeys is the number of leaf keys per section in scale fixtures.
+const scaleSectionKeys = 100
+
+// generateScaleConfig creates n leaf keys grouped into sections of
+// scaleSectionKeys keys each. A non-empty suffix is appended to values, and
+// only every second section is generated so that merges overlap partially.
+func generateScaleConfig(n int, suffix string) map[string]any {
+	sections := n / scaleSectionKeys
+	result := make(map[string]any, sections)
+	for s := 0; s < sections; s++ {
+		if suffix != "" && s%2 == 1 {
+			continue
+		}
+		section := make(map[string]any, scaleSectionKeys)
+		for k := 0; k < scaleSectionKeys; k++ {
+			section[fmt.Sprintf("key%d", k)] = fmt.Sprintf("value%d-%d%s", s, k, suffix)
+		}
+		result[fmt.Sprintf("section%d", s)] = section
+	}
+	return result
+}
+
+// generateScaleSource renders n leaf keys as .csl source, using the same
+// section layout as generateScaleConfig.
+func generateScaleSource(n int) string {
+	var b strings.Builder
+	for s := 0; s < n/scaleSectionKeys; s++ {
+		fmt.Fprintf(&b, "section%d:\n", s)
+		for k := 0; k < scaleSectionKeys; k++ {
+			fmt.Fprintf(&b, "  key%d: 'value%d-%d'\n", k, s, k)
+		}
+	}
+	return b.String()
+}
+
+// reportKeys reports throughput in leaf keys per second.
+func reportKeys(b *testing.B, n int) {
+	b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "keys/s")
+}
+
+// BenchmarkCompileDirectory benchmarks compiling a directory of 500 files,
+// parsing them serially and with the default worker pool.
+func BenchmarkCompileDirectory(b *testing.B) {
+	const files = 500
+
+	dir := b.TempDir()
+	for f := 0; f < files; f++ {
+		source := generateScaleSource(scaleSectionKeys * 2)
+		source = strings.ReplaceAll(source, "section", fmt.Sprintf("file%d_section", f))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.csl", f)), []byte(source), 0600); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			opts := compiler.Options{Path: dir, ProviderRegistry: compiler.NewProviderRegistry(), ParseConcurrency: workers}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if result := compiler.Compile(context.Background(), opts); result.HasErrors() {
+					b.Fatal(result.Error())
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -47,7 +48,7 @@ config: @base:app`
 		t.Fatal("expected circular reference error, got nil")
 	}
 
-	if !strings.Contains(result.Error().Error(), "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("error should indicate circular reference, got: %v", result.Error())
 	}
 
@@ -98,7 +99,7 @@ shared: @base:app
 	}
 
 	errMsg := result.Error().Error()
-	if !strings.Contains(errMsg, "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("error should indicate circular reference, got: %v", result.Error())
 	}
 
@@ -112,6 +113,15 @@ shared: @base:app
 		t.Errorf("error should include cycle path separator →, got: %v", result.Error())
 	}
 
+	// Verify the structured chain is available to callers
+	var cycleErr *compiler.CycleError
+	if !errors.As(result.Error(), &cycleErr) {
+		t.Fatalf("expected *compiler.CycleError, got: %v", result.Error())
+	}
+	if len(cycleErr.Chain) < 3 {
+		t.Errorf("expected cycle chain of at least 3 entries, got: %v", cycleErr.Chain)
+	}
+
 	t.Logf("✓ Two-file cycle detected: %v", result.Error())
 }
 
@@ -160,7 +170,7 @@ base: @base:app
 	}
 
 	errMsg := result.Error().Error()
-	if !strings.Contains(errMsg, "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("error should indicate circular reference, got: %v", result.Error())
 	}
 
@@ -214,7 +224,7 @@ config:
 		t.Fatal("expected circular reference error, got nil")
 	}
 
-	if !strings.Contains(result.Error().Error(), "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("error should indicate circular reference, got: %v", result.Error())
 	}
 
@@ -259,7 +269,7 @@ infrastructure:
 		t.Fatal("expected circular reference error, got nil")
 	}
 
-	if !strings.Contains(result.Error().Error(), "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("error should indicate circular reference, got: %v", result.Error())
 	}
 
@@ -380,7 +390,7 @@ config: @base:app
 		t.Fatal("expected circular reference error, got nil")
 	}
 
-	if !strings.Contains(result.Error().Error(), "circular reference") {
+	if !errors.Is(result.Error(), compiler.ErrCircularReference) {
 		t.Errorf("expected circular reference error, got: %v", result.Error())
 	}
 
@@ -3,7 +3,11 @@ package test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/compiler"
@@ -223,3 +227,58 @@ func TestCompile_ConcurrentCalls(t *testing.T) {
 		t.Errorf("concurrent compile error: %v", err)
 	}
 }
+
+// TestCompile_ConcurrentSharedRegistries tests that compilations sharing a
+// provider registry and provider type registry create each source alias's
+// provider once and each see their own Vars.
+func TestCompile_ConcurrentSharedRegistries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.csl": "source:\n  alias: 'cfg'\n  type: 'fake'\n\na:\n  value: @cfg:value\n",
+		"b.csl": "b:\n  tenant: @var:tenant\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var created atomic.Int64
+	typeRegistry := compiler.NewProviderTypeRegistry()
+	typeRegistry.RegisterType("fake", func(_ map[string]any) (compiler.Provider, error) {
+		created.Add(1)
+		provider := testutil.NewFakeProvider("cfg")
+		provider.FetchResponses["value"] = "shared"
+		return provider, nil
+	})
+	registry := compiler.NewProviderRegistry()
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			tenant := fmt.Sprintf("tenant-%d", i)
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 dir,
+				ProviderRegistry:     registry,
+				ProviderTypeRegistry: typeRegistry,
+				Vars:                 map[string]any{"tenant": tenant},
+			})
+			if result.HasErrors() {
+				t.Errorf("%s: unexpected errors: %v", tenant, result.Error())
+				return
+			}
+			want := map[string]any{
+				"a": map[string]any{"value": "shared"},
+				"b": map[string]any{"tenant": tenant},
+			}
+			if got := result.Snapshot.Data; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Data = %#v, want %#v", tenant, got, want)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := created.Load(); got != 1 {
+		t.Errorf("created %d providers for alias cfg, want 1", got)
+	}
+}
@@ -191,16 +191,16 @@ func createFakeProviderBinary(t *testing.T) string {
 	tmpDir := t.TempDir()
 	binaryPath := filepath.Join(tmpDir, "fake-provider")
 
-	// Determine absolute path to provider-proto module
+	// Determine absolute path to the generated provider-proto stubs module
 	// Tests run from within the test package directory
 	currentDir, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("Failed to get current directory: %v", err)
 	}
-	providerProtoPath := filepath.Join(currentDir, "../../provider-proto")
+	providerProtoPath := filepath.Join(currentDir, "../../provider-proto/gen/go")
 	providerProtoPath, err = filepath.Abs(providerProtoPath)
 	if err != nil {
-		t.Fatalf("Failed to resolve provider-proto path: %v", err)
+		t.Fatalf("Failed to resolve provider-proto stubs path: %v", err)
 	}
 
 	// Create a go.mod for the fake provider
@@ -209,12 +209,12 @@ func createFakeProviderBinary(t *testing.T) string {
 go 1.26.0
 
 require (
-	github.com/autonomous-bits/nomos/libs/provider-proto v0.0.0
+	github.com/autonomous-bits/nomos/libs/provider-proto/gen/go v0.0.0
 	google.golang.org/grpc v1.70.0
 	google.golang.org/protobuf v1.36.0
 )
 
-replace github.com/autonomous-bits/nomos/libs/provider-proto => %s
+replace github.com/autonomous-bits/nomos/libs/provider-proto/gen/go => %s
 `, providerProtoPath)
 	goModPath := filepath.Join(tmpDir, "go.mod")
 	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil { //nolint:gosec // G306: Test fixture file
@@ -0,0 +1,166 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+)
+
+// TfstateProviderType is the built-in provider type that reads Terraform
+// outputs from a local state file or a "terraform output -json" dump:
+//
+//	source:
+//	  alias: 'network'
+//	  type: 'tfstate'
+//	  path: '../infra'          # state file, output dump, or working directory
+//	  workspace: 'prod'         # optional; reads terraform.tfstate.d/prod
+//	  outputs: 'vpc_id,subnets' # optional; comma-separated outputs to expose
+//
+// References address outputs by name, e.g. @network:vpc_id. A relative path
+// is resolved against the directory of the declaring .csl file. Sensitive
+// outputs are exposed like any other output.
+const TfstateProviderType = "tfstate"
+
+// terraformWorkspaceDir is where Terraform's local backend keeps the state
+// of non-default workspaces.
+const terraformWorkspaceDir = "terraform.tfstate.d"
+
+// tfstateProvider implements core.Provider over Terraform outputs.
+type tfstateProvider struct {
+	path      string
+	workspace string
+	filter    []string
+
+	outputs map[string]any
+}
+
+// newTfstateProvider validates the source configuration; the state is read
+// in Init, once the declaring file is known.
+func newTfstateProvider(config map[string]any) (core.Provider, error) {
+	p := &tfstateProvider{}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("tfstate config %q must be a string", key)
+		}
+		switch key {
+		case "path":
+			p.path = s
+		case "workspace":
+			p.workspace = s
+		case "outputs":
+			for name := range strings.SplitSeq(s, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					p.filter = append(p.filter, name)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown tfstate config %q (supported: path, workspace, outputs)", key)
+		}
+	}
+	if p.path == "" {
+		return nil, errors.New("tfstate config requires 'path'")
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. It reads and decodes the state file.
+func (p *tfstateProvider) Init(_ context.Context, opts core.ProviderInitOptions) error {
+	path := p.path
+	if !filepath.IsAbs(path) && opts.SourceFilePath != "" {
+		path = filepath.Join(filepath.Dir(opts.SourceFilePath), path)
+	}
+
+	statePath, err := tfstatePath(path, p.workspace)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(statePath) //nolint:gosec // G304: State path is declared in the source file
+	if err != nil {
+		return fmt.Errorf("failed to read terraform state: %w", err)
+	}
+	outputs, err := decodeTerraformOutputs(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode terraform outputs in %s: %w", statePath, err)
+	}
+
+	if len(p.filter) > 0 {
+		filtered := make(map[string]any, len(p.filter))
+		for _, name := range p.filter {
+			value, ok := outputs[name]
+			if !ok {
+				return fmt.Errorf("terraform output %q not found in %s (available: %s)", name, statePath, strings.Join(sortedKeys(outputs), ", "))
+			}
+			filtered[name] = value
+		}
+		outputs = filtered
+	}
+	p.outputs = outputs
+	return nil
+}
+
+// Fetch implements core.Provider. The first path segment names an output;
+// later segments navigate into its value.
+func (p *tfstateProvider) Fetch(_ context.Context, path []string) (any, error) {
+	return lookupPath(p.outputs, path, "terraform output")
+}
+
+// tfstatePath returns the state file to read. A directory is treated as a
+// Terraform working directory using the local backend; a workspace other
+// than "default" selects that workspace's state beside the given path.
+func tfstatePath(path, workspace string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read terraform state: %w", err)
+	}
+	dir := path
+	if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	switch {
+	case workspace != "" && workspace != "default":
+		if strings.ContainsAny(workspace, `/\`) || workspace == ".." {
+			return "", fmt.Errorf("invalid terraform workspace %q", workspace)
+		}
+		return filepath.Join(dir, terraformWorkspaceDir, workspace, "terraform.tfstate"), nil
+	case info.IsDir():
+		return filepath.Join(dir, "terraform.tfstate"), nil
+	default:
+		return path, nil
+	}
+}
+
+// terraformOutput is an output as stored in state files and printed by
+// "terraform output -json".
+type terraformOutput struct {
+	Value any `json:"value"`
+}
+
+// decodeTerraformOutputs returns output values by name from either a state
+// file (which nests outputs under "outputs" beside a numeric "version") or a
+// "terraform output -json" dump.
+func decodeTerraformOutputs(data []byte) (map[string]any, error) {
+	var state struct {
+		Version *int                       `json:"version"`
+		Outputs map[string]terraformOutput `json:"outputs"`
+	}
+	raw := map[string]terraformOutput{}
+	if err := json.Unmarshal(data, &state); err == nil && state.Version != nil {
+		raw = state.Outputs
+	} else if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.New("expected a terraform state file or 'terraform output -json' output")
+	}
+
+	outputs := make(map[string]any, len(raw))
+	for name, output := range raw {
+		outputs[name] = output.Value
+	}
+	return outputs, nil
+}
@@ -0,0 +1,99 @@
+package compiler_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+const testTerraformState = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "outputs": {
+    "vpc_id": {"value": "vpc-123", "type": "string"},
+    "subnets": {"value": {"private": "subnet-a"}, "type": ["object", {"private": "string"}]},
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true}
+  },
+  "resources": []
+}`
+
+// TestCompile_TfstateProvider verifies that the built-in tfstate provider
+// reads outputs from state files, workspaces, and output dumps.
+func TestCompile_TfstateProvider(t *testing.T) {
+	dir := t.TempDir()
+	infra := filepath.Join(dir, "infra")
+	if err := os.MkdirAll(filepath.Join(infra, "terraform.tfstate.d", "prod"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(infra, "terraform.tfstate"), testTerraformState); err != nil {
+		t.Fatal(err)
+	}
+	prodState := strings.ReplaceAll(testTerraformState, "vpc-123", "vpc-prod")
+	if err := writeFile(filepath.Join(infra, "terraform.tfstate.d", "prod", "terraform.tfstate"), prodState); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dir, "outputs.json"), `{"vpc_id": {"sensitive": false, "type": "string", "value": "vpc-dump"}}`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		ref     string
+		want    any
+		wantErr string
+	}{
+		{name: "working directory", config: "path: './infra'", ref: "@net:vpc_id", want: "vpc-123"},
+		{name: "state file", config: "path: './infra/terraform.tfstate'", ref: "@net:subnets.private", want: "subnet-a"},
+		{name: "workspace", config: "path: './infra'\n  workspace: 'prod'", ref: "@net:vpc_id", want: "vpc-prod"},
+		{name: "output dump", config: "path: './outputs.json'", ref: "@net:vpc_id", want: "vpc-dump"},
+		{name: "filtered out", config: "path: './infra'\n  outputs: 'subnets'", ref: "@net:vpc_id", wantErr: "not found"},
+		{name: "missing filtered output", config: "path: './infra'\n  outputs: 'vpc_id, nat_ip'", ref: "@net:vpc_id", wantErr: `"nat_ip" not found`},
+		{name: "missing workspace", config: "path: './infra'\n  workspace: 'staging'", ref: "@net:vpc_id", wantErr: "failed to read terraform state"},
+		{name: "unknown config", config: "path: './infra'\n  backend: 's3'", ref: "@net:vpc_id", wantErr: `unknown tfstate config "backend"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "app.csl")
+			src := "source:\n  alias: 'net'\n  type: 'tfstate'\n  " + tt.config + "\n\nnetwork:\n  value: " + tt.ref + "\n"
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+			want := map[string]any{"value": tt.want}
+			if got := result.Snapshot.Data["network"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("network = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestIsBuiltinProviderType(t *testing.T) {
+	if !compiler.IsBuiltinProviderType(compiler.TfstateProviderType) {
+		t.Errorf("%q should be a built-in provider type", compiler.TfstateProviderType)
+	}
+	if compiler.IsBuiltinProviderType("autonomous-bits/nomos-provider-file") {
+		t.Error("external provider types are not built in")
+	}
+}
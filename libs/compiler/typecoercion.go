@@ -0,0 +1,58 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/pipeline"
+)
+
+// TypeCoercion controls whether string values that look like numbers or
+// booleans are converted to native types in the snapshot.
+//
+// Values in .csl files and many provider responses are strings, so
+// "port: 8080" compiles to the string "8080" unless coercion is enabled.
+// Coercion happens once, after reference resolution, so every serializer
+// and policy sees the same types. Secret values are never coerced.
+type TypeCoercion string
+
+const (
+	// TypeCoercionOff keeps every string as a string (default).
+	TypeCoercionOff TypeCoercion = "off"
+
+	// TypeCoercionStrict converts only canonical forms: integers without
+	// leading zeros or signs ("8080", "-1"), decimals ("3.14"), and the
+	// lowercase booleans "true" and "false".
+	TypeCoercionStrict TypeCoercion = "strict"
+
+	// TypeCoercionLenient also converts any finite decimal number ("+1",
+	// "007", "1e3") and the booleans yes/no/on/off in any case.
+	TypeCoercionLenient TypeCoercion = "lenient"
+)
+
+// ParseTypeCoercion parses a coercion policy name. The empty string yields
+// TypeCoercionOff.
+func ParseTypeCoercion(s string) (TypeCoercion, error) {
+	switch c := TypeCoercion(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return TypeCoercionOff, nil
+	case TypeCoercionOff, TypeCoercionStrict, TypeCoercionLenient:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid type coercion %q (want strict, lenient, or off)", s)
+	}
+}
+
+// coercionMode maps a policy to the pipeline mode.
+func (c TypeCoercion) coercionMode() (pipeline.CoercionMode, error) {
+	switch c {
+	case "", TypeCoercionOff:
+		return pipeline.CoerceNone, nil
+	case TypeCoercionStrict:
+		return pipeline.CoerceStrict, nil
+	case TypeCoercionLenient:
+		return pipeline.CoerceLenient, nil
+	default:
+		return 0, fmt.Errorf("invalid options.TypeCoercion %q (want strict, lenient, or off)", c)
+	}
+}
@@ -0,0 +1,86 @@
+package compiler_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCompile_TypeCoercion verifies that the coercion policy controls the
+// types of scalar values and is recorded in metadata.
+func TestCompile_TypeCoercion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "server:\n  port: 8080\n  debug: true\n  zip: '007'\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	tests := []struct {
+		coercion compiler.TypeCoercion
+		want     map[string]any
+		wantMeta compiler.TypeCoercion
+	}{
+		{"", map[string]any{"port": "8080", "debug": "true", "zip": "007"}, compiler.TypeCoercionOff},
+		{compiler.TypeCoercionOff, map[string]any{"port": "8080", "debug": "true", "zip": "007"}, compiler.TypeCoercionOff},
+		{compiler.TypeCoercionStrict, map[string]any{"port": int64(8080), "debug": true, "zip": "007"}, compiler.TypeCoercionStrict},
+		{compiler.TypeCoercionLenient, map[string]any{"port": int64(8080), "debug": true, "zip": int64(7)}, compiler.TypeCoercionLenient},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.wantMeta)+"/"+string(tt.coercion), func(t *testing.T) {
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:             path,
+				ProviderRegistry: compiler.NewProviderRegistry(),
+				TypeCoercion:     tt.coercion,
+			})
+			if err := result.Error(); err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+
+			server := result.Snapshot.Data["server"].(map[string]any)
+			for key, want := range tt.want {
+				if server[key] != want {
+					t.Errorf("server.%s = %#v, want %#v", key, server[key], want)
+				}
+			}
+			if result.Snapshot.Metadata.TypeCoercion != tt.wantMeta {
+				t.Errorf("Metadata.TypeCoercion = %q, want %q", result.Snapshot.Metadata.TypeCoercion, tt.wantMeta)
+			}
+		})
+	}
+}
+
+// TestCompile_TypeCoercionInvalid verifies that unknown policies are rejected.
+func TestCompile_TypeCoercionInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csl")
+	if err := writeFile(path, "a: 1\n"); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	result := compiler.Compile(context.Background(), compiler.Options{
+		Path:             path,
+		ProviderRegistry: compiler.NewProviderRegistry(),
+		TypeCoercion:     "loose",
+	})
+	if result.Error() == nil {
+		t.Fatal("expected error for invalid TypeCoercion")
+	}
+}
+
+func TestParseTypeCoercion(t *testing.T) {
+	for in, want := range map[string]compiler.TypeCoercion{
+		"":        compiler.TypeCoercionOff,
+		"off":     compiler.TypeCoercionOff,
+		"Strict":  compiler.TypeCoercionStrict,
+		"lenient": compiler.TypeCoercionLenient,
+	} {
+		got, err := compiler.ParseTypeCoercion(in)
+		if err != nil || got != want {
+			t.Errorf("ParseTypeCoercion(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := compiler.ParseTypeCoercion("loose"); err == nil {
+		t.Error("ParseTypeCoercion(\"loose\") expected error")
+	}
+}
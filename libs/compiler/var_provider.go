@@ -3,6 +3,7 @@ package compiler
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
@@ -41,7 +42,7 @@ func navigateVars(data map[string]any, path []string) (any, error) {
 	for i, segment := range path {
 		currentMap, ok := current.(map[string]any)
 		if !ok {
-			return nil, fmt.Errorf("path segment %d is not a map (got %T): %w", i, current, fmt.Errorf("property path invalid"))
+			return nil, fmt.Errorf("path segment %d is not a map (got %T): %w", i, current, core.ErrPropertyPathInvalid)
 		}
 
 		value, exists := currentMap[segment]
@@ -50,7 +51,7 @@ func navigateVars(data map[string]any, path []string) (any, error) {
 			for k := range currentMap {
 				availableKeys = append(availableKeys, k)
 			}
-			return nil, fmt.Errorf("variable %q not found (available: %s)", strings.Join(path[:i+1], "."), strings.Join(availableKeys, ", "))
+			return nil, fmt.Errorf("variable %q not found (available: %s): %w", strings.Join(path[:i+1], "."), strings.Join(availableKeys, ", "), core.ErrPropertyPathInvalid)
 		}
 
 		current = value
@@ -58,3 +59,39 @@ func navigateVars(data map[string]any, path []string) (any, error) {
 
 	return current, nil
 }
+
+// varRegistry serves the "var" alias of one compilation in front of the
+// caller's registry, so that compilations sharing a registry each see their
+// own Options.Vars. Every other alias is delegated to the caller's registry.
+type varRegistry struct {
+	core.ProviderRegistry
+	vars *varProvider
+}
+
+// newVarRegistry returns registry with the "var" alias serving vars.
+func newVarRegistry(registry core.ProviderRegistry, vars map[string]any) *varRegistry {
+	return &varRegistry{ProviderRegistry: registry, vars: &varProvider{vars: vars}}
+}
+
+// GetProvider implements core.ProviderRegistry.
+func (r *varRegistry) GetProvider(ctx context.Context, alias string) (core.Provider, error) {
+	if alias == "var" {
+		return r.vars, nil
+	}
+	return r.ProviderRegistry.GetProvider(ctx, alias)
+}
+
+// RegisteredAliases implements core.ProviderRegistry.
+func (r *varRegistry) RegisteredAliases() []string {
+	aliases := r.ProviderRegistry.RegisteredAliases()
+	if !slices.Contains(aliases, "var") {
+		aliases = append(aliases, "var")
+	}
+	return aliases
+}
+
+// RegisterOnce implements core.SourceProviderRegistry, atomically when the
+// caller's registry does.
+func (r *varRegistry) RegisterOnce(ctx context.Context, alias string, create func() (core.Provider, error)) (core.Provider, error) {
+	return core.RegisterSourceProvider(ctx, r.ProviderRegistry, alias, create)
+}
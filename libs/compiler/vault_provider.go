@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/core"
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/vaultapi"
+)
+
+// VaultProviderType is the built-in provider type that reads a secret from a
+// HashiCorp Vault KV secrets engine:
+//
+//	source:
+//	  alias: 'vault'
+//	  type: 'vault'
+//	  path: 'myapp/prod'     # required; secret path within the mount
+//	  mount: 'secret'        # optional; KV mount path, default 'secret'
+//	  kv_version: '2'        # optional; '1' or '2', default '2'
+//	  secret_version: '3'    # optional; pins a KV v2 secret version
+//	  address: 'https://vault.example.com:8200'  # optional; default VAULT_ADDR
+//	  namespace: 'team-a'    # optional; default VAULT_NAMESPACE
+//	  approle_mount: 'ci'    # optional; default VAULT_APPROLE_MOUNT or 'approle'
+//
+// References address the secret's fields, e.g. @vault:password. Every value
+// is returned as a secret, so it is encrypted when an encryption key is
+// configured and listed in Metadata.SensitiveKeys. Authentication comes from
+// the environment; see vaultapi.ConfigFromEnv.
+const VaultProviderType = "vault"
+
+// vaultProvider implements core.Provider over one Vault KV secret.
+type vaultProvider struct {
+	cfg           vaultapi.Config
+	mount         string
+	path          string
+	kvVersion     int
+	secretVersion int
+
+	data map[string]any
+}
+
+func newVaultProvider(config map[string]any) (core.Provider, error) {
+	p := &vaultProvider{cfg: vaultapi.ConfigFromEnv(), mount: "secret", kvVersion: 2}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("vault config %q must be a string", key)
+		}
+		switch key {
+		case "path":
+			p.path = s
+		case "mount":
+			p.mount = s
+		case "kv_version":
+			if s != "1" && s != "2" {
+				return nil, fmt.Errorf("vault config 'kv_version' must be '1' or '2' (got %q)", s)
+			}
+			p.kvVersion, _ = strconv.Atoi(s)
+		case "secret_version":
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("vault config 'secret_version' must be a positive integer (got %q)", s)
+			}
+			p.secretVersion = n
+		case "address":
+			p.cfg.Address = s
+		case "namespace":
+			p.cfg.Namespace = s
+		case "approle_mount":
+			p.cfg.AppRoleMount = s
+		default:
+			return nil, fmt.Errorf("unknown vault config %q", key)
+		}
+	}
+	if p.path == "" {
+		return nil, fmt.Errorf("vault config requires 'path'")
+	}
+	if p.secretVersion > 0 && p.kvVersion == 1 {
+		return nil, fmt.Errorf("vault config 'secret_version' requires kv_version '2'")
+	}
+	return p, nil
+}
+
+// Init implements core.Provider. It authenticates and reads the secret once;
+// references are then served from memory.
+func (p *vaultProvider) Init(ctx context.Context, _ core.ProviderInitOptions) error {
+	client, err := vaultapi.NewClient(p.cfg)
+	if err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	if err := client.Login(ctx); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	data, err := client.ReadKV(ctx, p.mount, p.path, p.kvVersion, p.secretVersion)
+	if err != nil {
+		return fmt.Errorf("vault: reading %s/%s: %w", p.mount, p.path, err)
+	}
+	p.data, _ = secretLeaves(data).(map[string]any)
+	return nil
+}
+
+// Fetch implements core.Provider.
+func (p *vaultProvider) Fetch(_ context.Context, path []string) (any, error) {
+	return lookupPath(p.data, path, "vault field")
+}
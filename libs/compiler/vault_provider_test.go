@@ -0,0 +1,135 @@
+package compiler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+	"github.com/autonomous-bits/nomos/libs/compiler/testutil"
+)
+
+// TestCompile_VaultProvider verifies that the built-in vault provider reads
+// KV secrets and records their keys as sensitive.
+func TestCompile_VaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team-a" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app/prod":
+			_, _ = w.Write([]byte(`{"data":{"data":{"username":"admin","password":"s3cr3t","tls":{"cert":"PEM"}}}}`))
+		case "/v1/kv/app/prod":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_NAMESPACE", "team-a")
+
+	tests := []struct {
+		name      string
+		source    string
+		body      string
+		want      map[string]any
+		sensitive []string
+		wantErr   string
+	}{
+		{
+			name:      "kv v2",
+			source:    "path: 'app/prod'",
+			body:      "user: @vault:username\npassword: @vault:password\ncert: @vault:tls.cert\n",
+			want:      map[string]any{"user": map[string]any{"value": "admin"}, "password": map[string]any{"value": "s3cr3t"}, "cert": map[string]any{"value": "PEM"}},
+			sensitive: []string{"out.cert", "out.password", "out.user"},
+		},
+		{
+			name:      "kv v1",
+			source:    "mount: 'kv'\n  path: 'app/prod'\n  kv_version: '1'",
+			body:      "password: @vault:password\n",
+			want:      map[string]any{"password": map[string]any{"value": "v1-secret"}},
+			sensitive: []string{"out.password"},
+		},
+		{
+			name:    "wrong namespace",
+			source:  "path: 'app/prod'\n  namespace: 'team-b'",
+			body:    "password: @vault:password\n",
+			wantErr: "permission denied",
+		},
+		{
+			name:    "missing secret",
+			source:  "path: 'app/dev'",
+			body:    "password: @vault:password\n",
+			wantErr: "secret not found",
+		},
+		{
+			name:    "missing path",
+			source:  "mount: 'secret'",
+			body:    "password: @vault:password\n",
+			wantErr: "requires 'path'",
+		},
+		{
+			name:    "secret version with kv v1",
+			source:  "path: 'app/prod'\n  kv_version: '1'\n  secret_version: '2'",
+			body:    "password: @vault:password\n",
+			wantErr: "requires kv_version '2'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			src := "source:\n  alias: 'vault'\n  type: 'vault'\n  " + tt.source + "\n\nout:\n" + indent(tt.body)
+			if err := writeFile(path, src); err != nil {
+				t.Fatal(err)
+			}
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     testutil.NewFakeProviderRegistry(),
+				ProviderTypeRegistry: compiler.NewProviderTypeRegistry(),
+			})
+			if tt.wantErr != "" {
+				if !result.HasErrors() || !strings.Contains(result.Error().Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", result.Error(), tt.wantErr)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			if out := jsonRoundTrip(t, result.Snapshot.Data["out"]); !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("out = %#v, want %#v", out, tt.want)
+			}
+			if got := result.Snapshot.Metadata.SensitiveKeys; !reflect.DeepEqual(got, tt.sensitive) {
+				t.Errorf("SensitiveKeys = %v, want %v", got, tt.sensitive)
+			}
+		})
+	}
+}
+
+// jsonRoundTrip returns value as decoded JSON. Unencrypted secrets serialize
+// as {"value": ...}.
+func jsonRoundTrip(t *testing.T, value any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package compiler
+
+import "strconv"
+
+// WalkDataFunc is called by WalkData for each leaf value. path holds the map
+// keys leading to the value, with list indices as decimal strings
+// ("app", "tags", "0"). The slice is reused between calls; copy it to keep
+// it. Returning a non-nil error stops the walk.
+type WalkDataFunc func(path []string, value any) error
+
+// WalkData calls fn for every leaf of the snapshot data, depth first, with
+// map keys in sorted order, so embedders can stream keys into their own
+// stores without building a flattened copy of the whole map:
+//
+//	err := result.WalkData(func(path []string, value any) error {
+//	    _, err := kv.Put(ctx, strings.Join(path, "/"), fmt.Sprint(value))
+//	    return err
+//	})
+//
+// Leaves are scalars and empty maps or lists. The error returned by fn, if
+// any, is returned unchanged.
+func (s Snapshot) WalkData(fn WalkDataFunc) error {
+	path := make([]string, 0, 16)
+	for _, key := range sortedKeys(s.Data) {
+		if err := walkValue(append(path, key), s.Data[key], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkData is Snapshot.WalkData on the compiled snapshot.
+func (r CompilationResult) WalkData(fn WalkDataFunc) error {
+	return r.Snapshot.WalkData(fn)
+}
+
+func walkValue(path []string, value any, fn WalkDataFunc) error {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			break
+		}
+		for _, key := range sortedKeys(v) {
+			if err := walkValue(append(path, key), v[key], fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		if len(v) == 0 {
+			break
+		}
+		for i, item := range v {
+			if err := walkValue(append(path, strconv.Itoa(i)), item, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fn(path, value)
+}
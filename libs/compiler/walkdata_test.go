@@ -0,0 +1,92 @@
+package compiler_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestSnapshot_WalkData verifies that every leaf is visited depth first in
+// sorted key order, with empty containers reported as leaves.
+func TestSnapshot_WalkData(t *testing.T) {
+	snapshot := compiler.Snapshot{Data: map[string]any{
+		"zone": "eu",
+		"app": map[string]any{
+			"tags":    []any{"web", map[string]any{"tier": "1"}},
+			"name":    "demo",
+			"port":    int64(8080),
+			"empty":   map[string]any{},
+			"none":    []any{},
+			"enabled": true,
+		},
+	}}
+
+	var got []string
+	err := snapshot.WalkData(func(path []string, value any) error {
+		got = append(got, strings.Join(path, "/")+"="+compactValue(value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkData() error = %v", err)
+	}
+
+	want := []string{
+		"app/empty={}",
+		"app/enabled=true",
+		"app/name=demo",
+		"app/none=[]",
+		"app/port=8080",
+		"app/tags/0=web",
+		"app/tags/1/tier=1",
+		"zone=eu",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkData() visited\n%v\nwant\n%v", got, want)
+	}
+}
+
+// TestCompilationResult_WalkData verifies that an error from the callback
+// stops the walk and is returned unchanged.
+func TestCompilationResult_WalkData(t *testing.T) {
+	result := compiler.CompilationResult{Snapshot: compiler.Snapshot{Data: map[string]any{
+		"a": "1", "b": "2", "c": "3",
+	}}}
+	errStop := errors.New("store full")
+
+	var visited []string
+	err := result.WalkData(func(path []string, _ any) error {
+		visited = append(visited, path[0])
+		if path[0] == "b" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("WalkData() error = %v, want %v", err, errStop)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %v, want %v", visited, want)
+	}
+
+	if err := (compiler.CompilationResult{}).WalkData(func([]string, any) error {
+		t.Error("callback called for empty data")
+		return nil
+	}); err != nil {
+		t.Errorf("WalkData() on empty data error = %v", err)
+	}
+}
+
+func compactValue(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "{}"
+	case []any:
+		return "[]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
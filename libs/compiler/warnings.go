@@ -0,0 +1,119 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/compiler/internal/diagnostic"
+)
+
+// WarningCode identifies a class of compiler warning.
+//
+// Codes are stable across releases. A warning can be silenced for a single
+// line with an inline directive in .csl source:
+//
+//	db: @base:database  # nomos:ignore W001
+//
+// for a whole file with "# nomos:ignore-file W001", or for an entire project
+// through Options.SuppressWarnings.
+type WarningCode string
+
+// Warning codes emitted by the compiler.
+const (
+	// WarnMissingProvider reports a reference to a provider that could not be
+	// obtained, tolerated because Options.AllowMissingProvider is set.
+	WarnMissingProvider WarningCode = diagnostic.CodeMissingProvider
+
+	// WarnFetchFailed reports a provider fetch failure, tolerated because
+	// Options.AllowMissingProvider is set.
+	WarnFetchFailed WarningCode = diagnostic.CodeFetchFailed
+
+	// WarnPolicyViolation reports a violated policy with severity "warning".
+	WarnPolicyViolation WarningCode = diagnostic.CodePolicyViolation
+
+	// WarnNondeterministicOrder reports a provider that returned the same
+	// list elements as in the build of Options.PreviousListOrders, but in a
+	// different order.
+	WarnNondeterministicOrder WarningCode = diagnostic.CodeNondeterministicOrder
+)
+
+// Warning is a structured, non-fatal compiler diagnostic.
+type Warning struct {
+	// Code identifies the warning class. Empty for uncoded warnings,
+	// which cannot be suppressed.
+	Code WarningCode `json:"code,omitempty"`
+
+	// Message is the human-readable description without location.
+	Message string `json:"message"`
+
+	// File, Line and Column locate the warning in source, when known.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// String formats the warning as "file:line:col: warning[CODE]: message".
+func (w Warning) String() string {
+	label := "warning"
+	if w.Code != "" {
+		label = fmt.Sprintf("warning[%s]", w.Code)
+	}
+	if w.File == "" {
+		return fmt.Sprintf("%s: %s", label, w.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", w.File, w.Line, w.Column, label, w.Message)
+}
+
+// warningFromDiagnostic converts an internal diagnostic into a Warning.
+func warningFromDiagnostic(d diagnostic.Diagnostic) Warning {
+	return Warning{
+		Code:    WarningCode(d.Code),
+		Message: d.Message,
+		File:    d.SourceSpan.Filename,
+		Line:    d.SourceSpan.StartLine,
+		Column:  d.SourceSpan.StartCol,
+	}
+}
+
+// warningFilter decides whether a warning is silenced by project-level
+// configuration or by directives in the source file it points at.
+type warningFilter struct {
+	codes map[WarningCode]bool
+	files map[string]diagnostic.Suppressions
+}
+
+func newWarningFilter(codes []WarningCode) *warningFilter {
+	f := &warningFilter{
+		codes: make(map[WarningCode]bool, len(codes)),
+		files: make(map[string]diagnostic.Suppressions),
+	}
+	for _, c := range codes {
+		f.codes[WarningCode(strings.ToUpper(string(c)))] = true
+	}
+	return f
+}
+
+// suppressed reports whether w should be dropped. Source files are read
+// lazily and their directives cached for the rest of the compilation.
+func (f *warningFilter) suppressed(w Warning) bool {
+	if w.Code == "" {
+		return false
+	}
+	if f.codes[w.Code] {
+		return true
+	}
+	if w.File == "" {
+		return false
+	}
+
+	s, ok := f.files[w.File]
+	if !ok {
+		source, err := os.ReadFile(w.File) //nolint:gosec // G304: Path comes from compiled input files
+		if err == nil {
+			s = diagnostic.ParseSuppressions(string(source))
+		}
+		f.files[w.File] = s
+	}
+	return s.Suppressed(string(w.Code), w.Line)
+}
@@ -0,0 +1,96 @@
+package compiler_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/compiler"
+)
+
+// TestCompile_WarningSuppression verifies coded warnings and the inline and
+// project-level suppression mechanisms.
+func TestCompile_WarningSuppression(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		suppress []compiler.WarningCode
+		want     int
+	}{
+		{
+			name:   "warning reported",
+			source: "app: 'demo'\nvalue: @broken:key\n",
+			want:   1,
+		},
+		{
+			name:   "inline directive on same line",
+			source: "app: 'demo'\nvalue: @broken:key  # nomos:ignore W001\n",
+			want:   0,
+		},
+		{
+			name:   "inline directive on previous line",
+			source: "app: 'demo'\n# nomos:ignore W001\nvalue: @broken:key\n",
+			want:   0,
+		},
+		{
+			name:   "file directive",
+			source: "# nomos:ignore-file W001\napp: 'demo'\nvalue: @broken:key\n",
+			want:   0,
+		},
+		{
+			name:   "directive for another code",
+			source: "app: 'demo'\nvalue: @broken:key  # nomos:ignore W002\n",
+			want:   1,
+		},
+		{
+			name:     "project-level suppression",
+			source:   "app: 'demo'\nvalue: @broken:key\n",
+			suppress: []compiler.WarningCode{"w001"},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.csl")
+			if err := writeFile(path, tt.source); err != nil {
+				t.Fatalf("failed to write source: %v", err)
+			}
+
+			registry := compiler.NewProviderRegistry()
+			registry.Register("broken", func(_ compiler.ProviderInitOptions) (compiler.Provider, error) {
+				return nil, errors.New("binary missing")
+			})
+
+			result := compiler.Compile(context.Background(), compiler.Options{
+				Path:                 path,
+				ProviderRegistry:     registry,
+				AllowMissingProvider: true,
+				SuppressWarnings:     tt.suppress,
+			})
+			if result.HasErrors() {
+				t.Fatalf("unexpected errors: %v", result.Error())
+			}
+
+			meta := result.Snapshot.Metadata
+			if len(meta.Warnings) != tt.want || len(meta.WarningDetails) != tt.want {
+				t.Fatalf("expected %d warnings, got %d (%v)", tt.want, len(meta.WarningDetails), meta.Warnings)
+			}
+			if tt.want == 0 {
+				return
+			}
+
+			w := meta.WarningDetails[0]
+			if w.Code != compiler.WarnMissingProvider {
+				t.Errorf("Code = %q, want %q", w.Code, compiler.WarnMissingProvider)
+			}
+			if w.File != path || w.Line != 2 {
+				t.Errorf("location = %s:%d, want %s:2", w.File, w.Line, path)
+			}
+			if meta.Warnings[0] != w.String() {
+				t.Errorf("Warnings[0] = %q, want %q", meta.Warnings[0], w.String())
+			}
+		})
+	}
+}
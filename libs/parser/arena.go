@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// arenaChunkSize is the number of nodes allocated at once by a nodeArena.
+const arenaChunkSize = 256
+
+// WithStringInterning makes the parser intern map keys, section names,
+// source aliases, and reference aliases and path segments.
+//
+// Interned strings are copied out of the source text and shared across every
+// parse made with the same Parser, so a monorepo build that reuses one Parser
+// for thousands of files stores each distinct key once. The intern table
+// lives as long as the Parser; use a new Parser to release it.
+func WithStringInterning(enabled bool) Option {
+	return func(p *Parser) {
+		if enabled {
+			p.interned = make(map[string]string)
+		} else {
+			p.interned = nil
+		}
+	}
+}
+
+// WithNodeArena makes the parser allocate string literals, maps, and map
+// entry slices in chunks rather than one at a time, and reuse its scratch
+// buffers between parses.
+//
+// Nodes from one chunk are freed together, so holding on to any node of an
+// AST keeps its neighbours alive. Chunks are never shared between parses.
+func WithNodeArena(enabled bool) Option {
+	return func(p *Parser) {
+		p.useArena = enabled
+	}
+}
+
+// nodeArena hands out AST nodes for a single parse from preallocated chunks.
+type nodeArena struct {
+	literals []ast.StringLiteral
+	maps     []ast.MapExpr
+	entries  []ast.MapEntry
+}
+
+func (a *nodeArena) stringLiteral() *ast.StringLiteral {
+	if len(a.literals) == cap(a.literals) {
+		a.literals = make([]ast.StringLiteral, 0, arenaChunkSize)
+	}
+	a.literals = a.literals[:len(a.literals)+1]
+	return &a.literals[len(a.literals)-1]
+}
+
+func (a *nodeArena) mapExpr() *ast.MapExpr {
+	if len(a.maps) == cap(a.maps) {
+		a.maps = make([]ast.MapExpr, 0, arenaChunkSize)
+	}
+	a.maps = a.maps[:len(a.maps)+1]
+	return &a.maps[len(a.maps)-1]
+}
+
+// copyEntries returns an exactly sized copy of src. Small slices are carved
+// out of a shared chunk with their capacity capped, so appending to one never
+// overwrites its neighbour.
+func (a *nodeArena) copyEntries(src []ast.MapEntry) []ast.MapEntry {
+	n := len(src)
+	if n == 0 {
+		return []ast.MapEntry{}
+	}
+	if n > arenaChunkSize/4 {
+		out := make([]ast.MapEntry, n)
+		copy(out, src)
+		return out
+	}
+	if cap(a.entries)-len(a.entries) < n {
+		a.entries = make([]ast.MapEntry, 0, arenaChunkSize)
+	}
+	start := len(a.entries)
+	a.entries = append(a.entries, src...)
+	return a.entries[start:len(a.entries):len(a.entries)]
+}
+
+// intern returns the canonical copy of s when string interning is enabled,
+// and s itself otherwise.
+func (p *Parser) intern(s string) string {
+	if p.interned == nil {
+		return s
+	}
+	if canonical, ok := p.interned[s]; ok {
+		return canonical
+	}
+	s = strings.Clone(s)
+	p.interned[s] = s
+	return s
+}
+
+// newStringLiteral returns a string literal node, from the arena if enabled.
+func (p *Parser) newStringLiteral(value string, span ast.SourceSpan) *ast.StringLiteral {
+	if !p.useArena {
+		return &ast.StringLiteral{Value: value, SourceSpan: span}
+	}
+	lit := p.nodes.stringLiteral()
+	lit.Value = value
+	lit.SourceSpan = span
+	return lit
+}
+
+// newMapExpr returns a map node, from the arena if enabled.
+func (p *Parser) newMapExpr(entries []ast.MapEntry, span ast.SourceSpan) *ast.MapExpr {
+	if !p.useArena {
+		return &ast.MapExpr{Entries: entries, SourceSpan: span}
+	}
+	m := p.nodes.mapExpr()
+	m.Entries = entries
+	m.SourceSpan = span
+	return m
+}
+
+// takeEntries returns an empty slice to collect map entries into. With the
+// arena enabled it is a reused scratch buffer that must be passed to
+// finishEntries.
+func (p *Parser) takeEntries() []ast.MapEntry {
+	if !p.useArena {
+		return make([]ast.MapEntry, 0)
+	}
+	if n := len(p.scratch); n > 0 {
+		buf := p.scratch[n-1]
+		p.scratch = p.scratch[:n-1]
+		return buf
+	}
+	return make([]ast.MapEntry, 0, 16)
+}
+
+// finishEntries returns the entries collected in a buffer from takeEntries
+// and releases the buffer for reuse.
+func (p *Parser) finishEntries(entries []ast.MapEntry) []ast.MapEntry {
+	if !p.useArena {
+		return entries
+	}
+	out := p.nodes.copyEntries(entries)
+	clear(entries) // don't keep this AST reachable from the scratch buffer
+	p.scratch = append(p.scratch, entries[:0])
+	return out
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// callPrefix introduces a function call in a value position.
+const callPrefix = "fn:"
+
+// parseCallExpr parses a function call value of the form fn:name(arg, ...).
+// Arguments are quoted strings, bare tokens, @alias:path references, or
+// nested calls, separated by commas. The call must span the whole value.
+func (p *Parser) parseCallExpr(text, filename string, line, col int) (*ast.CallExpr, error) {
+	c := &callParser{p: p, text: text, filename: filename, line: line, col: col}
+
+	call, err := c.parseCall()
+	if err != nil {
+		return nil, err
+	}
+
+	c.skipSpaces()
+	if c.pos != len(c.text) {
+		return nil, c.errorf(c.pos, "unexpected %q after function call", c.text[c.pos:])
+	}
+	return call, nil
+}
+
+// callParser is a cursor over the single-line text of a function call.
+type callParser struct {
+	p        *Parser
+	text     string
+	filename string
+	line     int
+	col      int
+	pos      int
+}
+
+func (c *callParser) parseCall() (*ast.CallExpr, error) {
+	start := c.pos
+	c.pos += len(callPrefix)
+
+	nameStart := c.pos
+	for c.pos < len(c.text) && isCallNameChar(c.text[c.pos]) {
+		c.pos++
+	}
+	name := c.text[nameStart:c.pos]
+	if !isValidAliasName(name) {
+		return nil, c.errorf(nameStart, "function name must start with letter or underscore and contain only letters, numbers, underscores, or hyphens")
+	}
+
+	if c.peek() != '(' {
+		return nil, c.errorf(c.pos, "expected '(' after function name %q", name)
+	}
+	c.pos++
+
+	args := []ast.Expr{}
+	c.skipSpaces()
+	if c.peek() == ')' {
+		c.pos++
+		return c.newCall(name, args, start), nil
+	}
+
+	for {
+		arg, err := c.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		c.skipSpaces()
+		switch c.peek() {
+		case ',':
+			c.pos++
+		case ')':
+			c.pos++
+			return c.newCall(name, args, start), nil
+		case 0:
+			return nil, c.errorf(c.pos, "unterminated call to %q (missing ')')", name)
+		default:
+			return nil, c.errorf(c.pos, "expected ',' or ')' in call to %q", name)
+		}
+	}
+}
+
+func (c *callParser) parseArg() (ast.Expr, error) {
+	c.skipSpaces()
+	start := c.pos
+
+	switch ch := c.peek(); {
+	case ch == '\'' || ch == '"':
+		end := strings.IndexByte(c.text[start+1:], ch)
+		if end < 0 {
+			return nil, c.errorf(start, "unterminated string (missing closing %c)", ch)
+		}
+		c.pos = start + 1 + end + 1
+		return &ast.StringLiteral{Value: c.text[start+1 : c.pos-1], SourceSpan: c.span(start, c.pos)}, nil
+
+	case strings.HasPrefix(c.text[start:], callPrefix):
+		return c.parseCall()
+
+	default:
+		for c.pos < len(c.text) && !strings.ContainsRune(",() \t", rune(c.text[c.pos])) {
+			c.pos++
+		}
+		token := c.text[start:c.pos]
+		if token == "" {
+			return nil, c.errorf(start, "expected argument")
+		}
+		if strings.HasPrefix(token, "@") {
+			return c.p.parseReferenceText(token, c.filename, c.line, c.col+start)
+		}
+		return &ast.StringLiteral{Value: token, SourceSpan: c.span(start, c.pos)}, nil
+	}
+}
+
+func (c *callParser) newCall(name string, args []ast.Expr, start int) *ast.CallExpr {
+	return &ast.CallExpr{Name: name, Args: args, SourceSpan: c.span(start, c.pos)}
+}
+
+// span returns the source span of text[start:end].
+func (c *callParser) span(start, end int) ast.SourceSpan {
+	return ast.SourceSpan{
+		Filename:  c.filename,
+		StartLine: c.line,
+		StartCol:  c.col + start,
+		EndLine:   c.line,
+		EndCol:    c.col + end - 1,
+	}
+}
+
+func (c *callParser) peek() byte {
+	if c.pos >= len(c.text) {
+		return 0
+	}
+	return c.text[c.pos]
+}
+
+func (c *callParser) skipSpaces() {
+	for c.pos < len(c.text) && (c.text[c.pos] == ' ' || c.text[c.pos] == '\t') {
+		c.pos++
+	}
+}
+
+func (c *callParser) errorf(pos int, format string, args ...any) error {
+	col := c.col + pos
+	err := NewParseError(SyntaxError, c.filename, c.line, col, "invalid syntax: "+fmt.Sprintf(format, args...))
+	err.SetSnippet(generateSnippetFromSource(c.p.sourceText, c.line, col))
+	return err
+}
+
+func isCallNameChar(ch byte) bool {
+	return ch == '_' || ch == '-' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
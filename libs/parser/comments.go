@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// attachDocComments sets the Doc field of every section and map entry whose
+// key starts its line and is directly preceded by comment lines.
+//
+// Only the comment lines immediately above a key form its doc comment: a
+// blank line or any other content ends the block, so a file header
+// separated from the first section by a blank line is not attached.
+func attachDocComments(tree *ast.AST, source string) {
+	if !strings.Contains(source, "#") {
+		return
+	}
+	d := docComments{lines: strings.Split(source, "\n")}
+	for _, stmt := range tree.Statements {
+		if section, ok := stmt.(*ast.SectionDecl); ok {
+			section.Doc = d.above(section.SourceSpan)
+			d.attachEntries(section.Entries)
+			d.attachExpr(section.Value)
+		}
+	}
+}
+
+// docComments looks up comment blocks in the lines of one source file.
+type docComments struct {
+	lines []string
+}
+
+func (d docComments) attachEntries(entries []ast.MapEntry) {
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.Spread {
+			entry.Doc = d.above(entry.SourceSpan)
+		}
+		d.attachExpr(entry.Value)
+	}
+}
+
+func (d docComments) attachExpr(expr ast.Expr) {
+	switch e := expr.(type) {
+	case *ast.MapExpr:
+		d.attachEntries(e.Entries)
+	case *ast.ListExpr:
+		for _, elem := range e.Elements {
+			d.attachExpr(elem)
+		}
+	}
+}
+
+// above returns the comment block directly above the key at span, with the
+// '#' and one following space removed from each line and empty lines
+// trimmed from both ends. Keys that do not start their line, such as the
+// first key of a list item, have no doc.
+func (d docComments) above(span ast.SourceSpan) string {
+	line := span.StartLine - 1 // 0-based
+	if line < 1 || line >= len(d.lines) {
+		return ""
+	}
+	if prefix := d.lines[line]; span.StartCol-1 > len(prefix) || strings.TrimSpace(prefix[:span.StartCol-1]) != "" {
+		return ""
+	}
+
+	first := line
+	for first > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[first-1]), "#") {
+		first--
+	}
+	if first == line {
+		return ""
+	}
+
+	doc := make([]string, 0, line-first)
+	for _, l := range d.lines[first:line] {
+		text := strings.TrimPrefix(strings.TrimSpace(l), "#")
+		doc = append(doc, strings.TrimRight(strings.TrimPrefix(text, " "), " \t\r"))
+	}
+	// Bare '#' lines frame the block rather than belong to it
+	for len(doc) > 0 && doc[0] == "" {
+		doc = doc[1:]
+	}
+	for len(doc) > 0 && doc[len(doc)-1] == "" {
+		doc = doc[:len(doc)-1]
+	}
+	return strings.Join(doc, "\n")
+}
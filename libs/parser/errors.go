@@ -6,6 +6,7 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/autonomous-bits/nomos/libs/parser/internal/scanner"
 	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
@@ -72,6 +73,7 @@ type ParseError struct {
 	col      int
 	message  string
 	snippet  string // Context lines showing the error location
+	legacy   bool   // Rejects a statement WithLegacyStatements accepts
 }
 
 // NewParseError creates a new ParseError.
@@ -190,7 +192,7 @@ func FormatParseError(err error, sourceText string) string {
 // generateSnippet creates a context snippet with a caret pointing to the error.
 // It shows 1-3 lines of context centered around the error line.
 func generateSnippet(sourceText string, line, col int) string {
-	lines := strings.Split(sourceText, "\n")
+	lines := strings.Split(scanner.Normalize(sourceText), "\n")
 	if line < 1 || line > len(lines) {
 		return ""
 	}
@@ -0,0 +1,48 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// FuzzParse checks that Parse returns an AST or a ParseError, never
+// panicking, that the AST locates every statement, and that a pooled parser
+// with interning and the node arena builds the same AST.
+//
+// The seed corpus is in testdata/fuzz/FuzzParse. Run with:
+//
+//	go test -run '^$' -fuzz FuzzParse .
+func FuzzParse(f *testing.F) {
+	pooled := parser.NewParser(parser.WithStringInterning(true), parser.WithNodeArena(true))
+	f.Fuzz(func(t *testing.T, input string) {
+		tree, err := parser.Parse(strings.NewReader(input), "fuzz.csl")
+		if err != nil {
+			_ = parser.FormatParseError(err, input)
+			if _, pooledErr := pooled.Parse(strings.NewReader(input), "fuzz.csl"); pooledErr == nil {
+				t.Fatalf("pooled parser accepted input the default parser rejected: %v", err)
+			}
+			return
+		}
+
+		for i, stmt := range tree.Statements {
+			if span := stmt.Span(); span.StartLine < 1 || span.StartCol < 1 || span.EndLine < span.StartLine {
+				t.Errorf("statement %d (%T) has invalid span %+v", i, stmt, span)
+			}
+			if section, ok := stmt.(*ast.SectionDecl); ok && section.Name == "" {
+				t.Errorf("statement %d is a section without a name", i)
+			}
+		}
+
+		pooledTree, err := pooled.Parse(strings.NewReader(input), "fuzz.csl")
+		if err != nil {
+			t.Fatalf("pooled parser rejected input the default parser accepted: %v", err)
+		}
+		if !reflect.DeepEqual(tree, pooledTree) {
+			t.Errorf("pooled parser built a different AST")
+		}
+	})
+}
@@ -2,4 +2,7 @@ module github.com/autonomous-bits/nomos/libs/parser
 
 go 1.26.0
 
-require github.com/Masterminds/semver/v3 v3.4.0
+require (
+	github.com/Masterminds/semver/v3 v3.4.0
+	golang.org/x/text v0.27.0
+)
@@ -35,7 +35,38 @@ type Snapshot struct {
 	lineStart int
 }
 
-// New creates a new Scanner for the given input.
+// byteOrderMark is the UTF-8 encoding of U+FEFF, which some Windows editors
+// write at the start of a file.
+const byteOrderMark = "\uFEFF"
+
+// Normalize returns input without a leading byte order mark and with CRLF
+// and lone CR line endings replaced by LF. Lines and columns the scanner
+// reports for the result match what an editor shows for the original, and
+// snippets cut from it carry no stray carriage returns. Input that needs no
+// change is returned as is, without copying.
+func Normalize(input string) string {
+	input = strings.TrimPrefix(input, byteOrderMark)
+	if strings.IndexByte(input, '\r') < 0 {
+		return input
+	}
+	var b strings.Builder
+	b.Grow(len(input))
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		if ch != '\r' {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('\n')
+		if i+1 < len(input) && input[i+1] == '\n' {
+			i++
+		}
+	}
+	return b.String()
+}
+
+// New creates a new Scanner for the given input. Callers pass input through
+// Normalize first; the scanner itself only treats LF as a line break.
 func New(input, filename string) *Scanner {
 	return &Scanner{
 		input:     input,
@@ -297,12 +328,14 @@ func (s *Scanner) ConsumeToken() string {
 	return s.ReadIdentifier()
 }
 
-// ReadIdentifier reads an identifier (alphanumeric + dash).
+// ReadIdentifier reads an identifier (alphanumeric + dash). Combining marks
+// are accepted after the first character, so decomposed spellings such as
+// "e\u0301" stay part of the identifier.
 func (s *Scanner) ReadIdentifier() string {
 	start := s.pos
 	for !s.IsEOF() {
 		ch := s.PeekChar()
-		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '-' || ch == '_' {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '-' || ch == '_' || (s.pos > start && unicode.IsMark(ch)) {
 			s.Advance()
 		} else {
 			break
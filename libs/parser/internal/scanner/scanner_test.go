@@ -560,3 +560,27 @@ func TestScanner_ReadValue_PreservesHashInQuotedStrings(t *testing.T) {
 		})
 	}
 }
+
+// TestNormalize tests byte order mark removal and line ending normalization.
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "LF unchanged", input: "a: 1\nb: 2\n", expected: "a: 1\nb: 2\n"},
+		{name: "CRLF", input: "a: 1\r\nb: 2\r\n", expected: "a: 1\nb: 2\n"},
+		{name: "lone CR", input: "a: 1\rb: 2\r", expected: "a: 1\nb: 2\n"},
+		{name: "mixed", input: "a: 1\r\n\rb: 2\n", expected: "a: 1\n\nb: 2\n"},
+		{name: "byte order mark", input: "\uFEFFa: 1\r\n", expected: "a: 1\n"},
+		{name: "inner U+FEFF kept", input: "a: '\uFEFF'\n", expected: "a: '\uFEFF'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanner.Normalize(tt.input); got != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/autonomous-bits/nomos/libs/parser/internal/scanner"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+	"golang.org/x/text/unicode/norm"
+)
+
+// WithASCIIKeys restricts map keys and section names to ASCII characters.
+// A key with any other character is a syntax error pointing at the first
+// one.
+//
+// Without it, keys may use any Unicode letters and digits and are
+// normalized to NFC, so "café" typed with a precomposed é and with e
+// followed by a combining accent is the same key.
+func WithASCIIKeys(enabled bool) Option {
+	return func(p *Parser) {
+		p.asciiKeys = enabled
+	}
+}
+
+// keyPos is the position of a key in the current source.
+type keyPos struct {
+	line, col int
+}
+
+// key returns the key or section name raw read at line and col, normalized
+// to NFC. Keys whose spelling changed are remembered so checkKeyCollisions
+// can report two spellings of the same key in one map.
+func (p *Parser) key(s *scanner.Scanner, raw string, line, col int) (string, error) {
+	ascii := true
+	for i, r := range raw {
+		if r < utf8.RuneSelf {
+			continue
+		}
+		ascii = false
+		if p.asciiKeys {
+			errCol := col + utf8.RuneCountInString(raw[:i])
+			err := NewParseError(SyntaxError, s.Filename(), line, errCol,
+				fmt.Sprintf("invalid syntax: non-ASCII character %s in key %s; keys are restricted to ASCII",
+					strconv.QuoteRuneToASCII(r), strconv.QuoteToASCII(raw)))
+			err.SetSnippet(generateSnippetFromSource(p.sourceText, line, errCol))
+			return "", err
+		}
+		break
+	}
+	if ascii {
+		return p.intern(raw), nil
+	}
+
+	key := norm.NFC.String(raw)
+	if key != raw {
+		if p.spellings == nil {
+			p.spellings = make(map[keyPos]string)
+		}
+		p.spellings[keyPos{line, col}] = raw
+	}
+	return p.intern(key), nil
+}
+
+// keyUse is where a key was first seen in a map, and how it was spelled.
+type keyUse struct {
+	raw string
+	pos keyPos
+}
+
+// checkKeyCollisions reports a map holding two spellings of a key that
+// normalize to the same string. Such keys would otherwise merge silently,
+// the later value replacing the earlier one.
+func (p *Parser) checkKeyCollisions(filename string, statements []ast.Stmt) error {
+	if len(p.spellings) == 0 {
+		return nil
+	}
+	sections := make(map[string]keyUse)
+	for _, stmt := range statements {
+		section, ok := stmt.(*ast.SectionDecl)
+		if !ok {
+			continue
+		}
+		if err := p.checkKey(filename, sections, section.Name, section.SourceSpan); err != nil {
+			return err
+		}
+		if err := p.checkEntries(filename, section.Entries); err != nil {
+			return err
+		}
+		if err := p.checkExpr(filename, section.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) checkEntries(filename string, entries []ast.MapEntry) error {
+	keys := make(map[string]keyUse, len(entries))
+	for _, entry := range entries {
+		if !entry.Spread {
+			if err := p.checkKey(filename, keys, entry.Key, entry.SourceSpan); err != nil {
+				return err
+			}
+		}
+		if err := p.checkExpr(filename, entry.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) checkExpr(filename string, expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.MapExpr:
+		return p.checkEntries(filename, e.Entries)
+	case *ast.ListExpr:
+		for _, elem := range e.Elements {
+			if err := p.checkExpr(filename, elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkKey records key, spelled as in the source at span, in keys and
+// fails if keys already holds it with another spelling.
+func (p *Parser) checkKey(filename string, keys map[string]keyUse, key string, span ast.SourceSpan) error {
+	pos := keyPos{span.StartLine, span.StartCol}
+	raw, ok := p.spellings[pos]
+	if !ok {
+		raw = key
+	}
+	first, seen := keys[key]
+	if !seen {
+		keys[key] = keyUse{raw: raw, pos: pos}
+		return nil
+	}
+	if first.raw == raw {
+		return nil
+	}
+	err := NewParseError(SyntaxError, filename, pos.line, pos.col,
+		fmt.Sprintf("invalid syntax: key %s collides with key %s at line %d, column %d; both normalize to %s",
+			strconv.QuoteToASCII(raw), strconv.QuoteToASCII(first.raw), first.pos.line, first.pos.col, strconv.QuoteToASCII(key)))
+	err.SetSnippet(generateSnippetFromSource(p.sourceText, pos.line, pos.col))
+	return err
+}
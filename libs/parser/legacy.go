@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/autonomous-bits/nomos/libs/parser/internal/scanner"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// WithLegacyStatements makes the parser return the top-level statements the
+// language no longer supports, "reference:alias:path" and
+// "import:alias:path", as ast.LegacyStmt nodes instead of syntax errors.
+//
+// It exists for tools that migrate old files to the supported syntax, such
+// as 'nomos migrate'. Compilers must leave it off: legacy statements have
+// no meaning.
+func WithLegacyStatements(enabled bool) Option {
+	return func(p *Parser) {
+		p.legacy = enabled
+	}
+}
+
+// IsLegacySyntax reports whether err rejects a top-level statement that
+// WithLegacyStatements accepts, so a migration tool can rewrite it.
+func IsLegacySyntax(err error) bool {
+	var parseErr *ParseError
+	return errors.As(err, &parseErr) && parseErr.legacy
+}
+
+// parseLegacyStmt parses a legacy statement introduced by keyword.
+func (p *Parser) parseLegacyStmt(s *scanner.Scanner, keyword string, startLine, startCol int) (*ast.LegacyStmt, error) {
+	s.ConsumeToken()
+	if err := p.expectColonAfterKeyword(s, keyword); err != nil {
+		return nil, err
+	}
+
+	start := s.Pos()
+	value := s.ReadValue()
+	raw := p.sourceText[start:s.Pos()]
+	endCol := s.Column() - 1 - (len(raw) - len(strings.TrimRight(raw, " \t")))
+
+	fields := strings.Split(value, ":")
+	for _, field := range fields {
+		if field == "" || strings.ContainsAny(field, " \t'\"\x00") {
+			err := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
+				fmt.Sprintf("invalid syntax: expected %s:alias:path", keyword))
+			err.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
+			return nil, err
+		}
+	}
+	s.SkipToNextLine()
+
+	stmt := &ast.LegacyStmt{
+		Keyword: keyword,
+		Alias:   fields[0],
+		SourceSpan: ast.SourceSpan{
+			Filename:  s.Filename(),
+			StartLine: startLine,
+			StartCol:  startCol,
+			EndLine:   startLine,
+			EndCol:    endCol,
+		},
+	}
+	if len(fields) > 1 {
+		stmt.Path = fields[1:]
+	}
+	return stmt, nil
+}
@@ -5,7 +5,8 @@
 // (for io.Reader). All parse errors include precise source location information.
 //
 // The parser supports YAML-style comments using the '#' notation. Comments extend
-// from the '#' character to the end of the line and are ignored during parsing.
+// from the '#' character to the end of the line. Comment lines directly above a
+// section or map key are kept as that node's Doc; all other comments are ignored.
 // The '#' character is treated as a comment delimiter only when it appears outside
 // quoted strings; within strings, '#' is preserved as literal content.
 //
@@ -19,6 +20,7 @@
 package parser //nolint:revive // public package name is intentional and descriptive
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -33,24 +35,44 @@ import (
 // Parser instances are safe for concurrent use and can be pooled via sync.Pool for
 // high-throughput scenarios.
 //
-// The parser stores source text internally during parsing for error context generation,
-// but maintains no state between Parse/ParseFile calls.
+// The parser stores source text internally during parsing for error context generation.
+// Between Parse/ParseFile calls it keeps only its read and scratch buffers and, with
+// WithStringInterning, the intern table; returned ASTs never share mutable state.
 type Parser struct {
 	// sourceText stores the source text for the current parse operation.
 	// It is used for error formatting and context generation.
 	// This field is set at the start of each Parse/ParseFile call.
 	sourceText string
-	// Future fields for configuration options can be added here.
-	// Examples: strict mode flags, custom error handlers, debug options.
+
+	// input is the read buffer, reused across parses.
+	input bytes.Buffer
+
+	// interned is the intern table; nil unless WithStringInterning is set.
+	interned map[string]string
+
+	// useArena enables chunked node allocation (WithNodeArena). nodes is
+	// reset at the start of each parse; scratch holds entry buffers reused
+	// across parses.
+	useArena bool
+	nodes    nodeArena
+	scratch  [][]ast.MapEntry
+
+	// asciiKeys rejects non-ASCII keys (WithASCIIKeys). spellings maps the
+	// position of each key that NFC normalization changed to its spelling
+	// in the source; it is reset at the start of each parse.
+	asciiKeys bool
+	spellings map[keyPos]string
+
+	// legacy records unsupported top-level statements as ast.LegacyStmt
+	// instead of rejecting them (WithLegacyStatements).
+	legacy bool
 }
 
 // Option is a functional option for configuring a Parser.
-// Currently no options are implemented, but this pattern provides a forward-compatible
-// extension point for future parser configuration without breaking the API.
 //
-// Example future usage:
+// Example usage for builds that parse many files with one parser:
 //
-//	p := NewParser(WithStrictMode(true), WithMaxDepth(100))
+//	p := NewParser(WithStringInterning(true), WithNodeArena(true))
 //
 // The parser can be used without any options:
 //
@@ -58,7 +80,6 @@ type Parser struct {
 type Option func(*Parser)
 
 // NewParser creates a new Parser with the given options.
-// Currently accepts options for future extensibility but none are implemented yet.
 // Parser instances can be reused across multiple Parse/ParseFile calls and are
 // safe for concurrent use when each goroutine has its own instance.
 func NewParser(opts ...Option) *Parser {
@@ -99,14 +120,17 @@ func Parse(r io.Reader, filename string) (*ast.AST, error) {
 
 // Parse parses input using this parser instance.
 func (p *Parser) Parse(r io.Reader, filename string) (*ast.AST, error) {
-	// Read all input
-	content, err := io.ReadAll(r)
-	if err != nil {
+	// Read all input into the reused buffer
+	p.input.Reset()
+	if _, err := p.input.ReadFrom(r); err != nil {
 		return nil, NewParseError(IOError, filename, 0, 0, fmt.Sprintf("failed to read input: %v", err))
 	}
 
-	// Store source text for error formatting
-	p.sourceText = string(content)
+	// Store source text for error formatting, with CRLF line endings and
+	// any byte order mark normalized away so spans and snippets line up
+	p.sourceText = scanner.Normalize(p.input.String())
+	p.nodes = nodeArena{}
+	p.spellings = nil
 
 	// Create scanner
 	s := scanner.New(p.sourceText, filename)
@@ -116,6 +140,9 @@ func (p *Parser) Parse(r io.Reader, filename string) (*ast.AST, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkKeyCollisions(filename, statements); err != nil {
+		return nil, err
+	}
 
 	// Build AST
 	astNode := &ast.AST{
@@ -128,6 +155,7 @@ func (p *Parser) Parse(r io.Reader, filename string) (*ast.AST, error) {
 			EndCol:    s.Column(),
 		},
 	}
+	attachDocComments(astNode, p.sourceText)
 
 	return astNode, nil
 }
@@ -188,22 +216,7 @@ func (p *Parser) parseStatement(s *scanner.Scanner) (ast.Stmt, error) {
 	}
 
 	if ch == '@' {
-		refExpr, err := p.parseValueExpr(s, startLine, startCol)
-		if err != nil {
-			return nil, err
-		}
-		ref, ok := refExpr.(*ast.ReferenceExpr)
-		if !ok {
-			parseErr := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: standalone references must use @alias:path")
-			parseErr.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
-			return nil, parseErr
-		}
-		s.SkipToNextLine()
-		return &ast.SpreadStmt{
-			Reference:  ref,
-			SourceSpan: ref.SourceSpan,
-		}, nil
+		return p.parseSpreadStmt(s, startLine, startCol)
 	}
 
 	// Peek at the first token to determine statement type
@@ -219,12 +232,19 @@ func (p *Parser) parseStatement(s *scanner.Scanner) (ast.Stmt, error) {
 	case "source":
 		return p.parseSourceDecl(s, startLine, startCol)
 	case "import":
+		if p.legacy {
+			return p.parseLegacyStmt(s, token, startLine, startCol)
+		}
 		// Import statement no longer supported - return clear error
 		err := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-			"import statement no longer supported; use @alias:path syntax instead")
+			"import statement no longer supported; use @alias:path syntax instead, with 'as prefix' to place the tree under a key")
 		err.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
+		err.legacy = true
 		return nil, err
 	case "reference":
+		if p.legacy {
+			return p.parseLegacyStmt(s, token, startLine, startCol)
+		}
 		return nil, p.parseReferenceStmt(s, startLine, startCol)
 	default:
 		// Try to parse as a section declaration
@@ -237,6 +257,65 @@ func (p *Parser) parseStatement(s *scanner.Scanner) (ast.Stmt, error) {
 	}
 }
 
+// parseSpreadStmt parses a top-level spread reference, optionally placed
+// under a key prefix: @alias:path as prefix.
+func (p *Parser) parseSpreadStmt(s *scanner.Scanner, startLine, startCol int) (*ast.SpreadStmt, error) {
+	snapshot := s.Snapshot()
+	line := s.ReadValue()
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] != "as" {
+		s.Restore(snapshot)
+		refExpr, err := p.parseValueExpr(s, startLine, startCol)
+		if err != nil {
+			return nil, err
+		}
+		ref, ok := refExpr.(*ast.ReferenceExpr)
+		if !ok {
+			parseErr := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
+				"invalid syntax: standalone references must use @alias:path")
+			parseErr.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
+			return nil, parseErr
+		}
+		s.SkipToNextLine()
+		return &ast.SpreadStmt{
+			Reference:  ref,
+			SourceSpan: ref.SourceSpan,
+		}, nil
+	}
+
+	if len(fields) != 3 || !isValidKeyPrefix(fields[2]) {
+		err := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
+			"invalid syntax: 'as' must be followed by a key prefix of dot-separated names (@alias:path as prefix)")
+		err.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
+		return nil, err
+	}
+
+	ref, err := p.parseReferenceText(fields[0], s.Filename(), startLine, startCol)
+	if err != nil {
+		return nil, err
+	}
+	s.SkipToNextLine()
+
+	span := ref.SourceSpan
+	span.EndCol = startCol + len(line) - 1
+	return &ast.SpreadStmt{
+		Reference:  ref,
+		Prefix:     p.intern(fields[2]),
+		SourceSpan: span,
+	}, nil
+}
+
+// isValidKeyPrefix reports whether prefix is a dot-separated path of names,
+// each following the alias name pattern.
+func isValidKeyPrefix(prefix string) bool {
+	for _, segment := range strings.Split(prefix, ".") {
+		if !isValidAliasName(segment) {
+			return false
+		}
+	}
+	return true
+}
+
 // parseSourceDecl parses a source declaration.
 func (p *Parser) parseSourceDecl(s *scanner.Scanner, startLine, startCol int) (*ast.SourceDecl, error) {
 	s.ConsumeToken() // consume "source"
@@ -281,7 +360,7 @@ func (p *Parser) parseSourceDecl(s *scanner.Scanner, startLine, startCol int) (*
 		return nil, err
 	}
 
-	alias := aliasLiteral.Value
+	alias := p.intern(aliasLiteral.Value)
 	if alias == "" {
 		err := NewParseError(SyntaxError, s.Filename(), startLine, startCol,
 			"invalid syntax: 'source' declaration requires a non-empty 'alias' field")
@@ -305,9 +384,34 @@ func (p *Parser) parseSourceDecl(s *scanner.Scanner, startLine, startCol int) (*
 		}
 	}
 
-	// Validate semver format if version is provided
-	if err := validateSemver(version); err != nil {
-		parseErr := NewParseError(SyntaxError, s.Filename(), startLine, startCol, err.Error())
+	// Extract digest (optional); pins the release asset by content
+	digest := ""
+	if digestExpr, ok := config["digest"]; ok {
+		if digestLiteral, ok := digestExpr.(*ast.StringLiteral); ok {
+			digest = digestLiteral.Value
+		}
+	}
+
+	// Extract checksum (optional); pins the installed provider binary by
+	// content
+	checksum := ""
+	if checksumExpr, ok := config["checksum"]; ok {
+		if checksumLiteral, ok := checksumExpr.(*ast.StringLiteral); ok {
+			checksum = checksumLiteral.Value
+		}
+	}
+
+	// Validate semver format if version is provided; a digest-pinned
+	// version may be any release tag
+	versionErr := validateSemver(version)
+	if digest != "" {
+		versionErr = validatePinnedRelease(version, digest)
+	}
+	if versionErr == nil && checksum != "" {
+		versionErr = validateChecksum(checksum)
+	}
+	if versionErr != nil {
+		parseErr := NewParseError(SyntaxError, s.Filename(), startLine, startCol, versionErr.Error())
 		parseErr.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
 		return nil, parseErr
 	}
@@ -316,14 +420,18 @@ func (p *Parser) parseSourceDecl(s *scanner.Scanner, startLine, startCol int) (*
 	delete(config, "alias")
 	delete(config, "type")
 	delete(config, "version")
+	delete(config, "digest")
+	delete(config, "checksum")
 
 	endLine, endCol := s.Line(), s.Column()
 
 	return &ast.SourceDecl{
-		Alias:   alias,
-		Type:    typeName,
-		Version: version,
-		Config:  config,
+		Alias:    alias,
+		Type:     typeName,
+		Version:  version,
+		Digest:   digest,
+		Checksum: checksum,
+		Config:   config,
 		SourceSpan: ast.SourceSpan{
 			Filename:  s.Filename(),
 			StartLine: startLine,
@@ -343,12 +451,16 @@ func (p *Parser) parseReferenceStmt(s *scanner.Scanner, startLine, startCol int)
 
 	err := NewParseError(SyntaxError, s.Filename(), startLine, startCol, errorMessage)
 	err.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
+	err.legacy = true
 	return err
 }
 
 // parseSectionDecl parses a configuration section.
 func (p *Parser) parseSectionDecl(s *scanner.Scanner, startLine, startCol int) (*ast.SectionDecl, error) {
-	name := s.ReadIdentifier()
+	name, err := p.key(s, s.ReadIdentifier(), startLine, startCol)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check for unexpected characters after identifier (FR-014)
 	ch := s.PeekChar()
@@ -417,7 +529,7 @@ func (p *Parser) parseSectionDecl(s *scanner.Scanner, startLine, startCol int) (
 // parseConfigBlock parses an indented block of key-value pairs.
 // It can now handle nested map structures and direct lists (when a section contains only list items).
 func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
-	config := make([]ast.MapEntry, 0)
+	config := p.takeEntries()
 
 	s.SkipToNextLine()
 	if p.isWhitespaceOnlyIndentedBlock(s) {
@@ -435,13 +547,13 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 			err.SetSnippet(generateSnippetFromSource(p.sourceText, startLine, startCol))
 			return nil, err
 		}
-		return config, nil
+		return p.finishEntries(config), nil
 	}
 
 	listOnly := p.isListOnlyBlock(s, baseIndent)
 	if listOnly {
 		if !p.seekToIndentedContent(s, baseIndent) {
-			return config, nil
+			return p.finishEntries(config), nil
 		}
 		listLine, listCol := s.Line(), s.Column()
 		listExpr, err := p.parseListExpr(s, baseIndent, 1, listLine, listCol)
@@ -459,7 +571,7 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 				EndCol:    listExpr.Span().EndCol,
 			},
 		})
-		return config, nil
+		return p.finishEntries(config), nil
 	}
 
 	listSnapshot := s.Snapshot()
@@ -480,7 +592,7 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 				EndCol:    listExpr.Span().EndCol,
 			},
 		})
-		return config, nil
+		return p.finishEntries(config), nil
 	}
 	s.Restore(listSnapshot)
 
@@ -550,7 +662,10 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 
 		keyStartLine := s.Line()
 		keyStart := s.Column()
-		key := s.ReadIdentifier()
+		key, err := p.key(s, s.ReadIdentifier(), keyStartLine, keyStart)
+		if err != nil {
+			return nil, err
+		}
 
 		// Validate key is not empty
 		if key == "" {
@@ -626,16 +741,13 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 					endCol := p.mapEndColumn(s)
 					config = append(config, ast.MapEntry{
 						Key: key,
-						Value: &ast.MapExpr{
-							Entries: nestedEntries,
-							SourceSpan: ast.SourceSpan{
-								Filename:  s.Filename(),
-								StartLine: keyStartLine,
-								StartCol:  keyStart,
-								EndLine:   endLine,
-								EndCol:    endCol,
-							},
-						},
+						Value: p.newMapExpr(nestedEntries, ast.SourceSpan{
+							Filename:  s.Filename(),
+							StartLine: keyStartLine,
+							StartCol:  keyStart,
+							EndLine:   endLine,
+							EndCol:    endCol,
+						}),
 						SourceSpan: ast.SourceSpan{
 							Filename:  s.Filename(),
 							StartLine: keyStartLine,
@@ -651,16 +763,13 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 			// Empty value (newline but no nested content)
 			config = append(config, ast.MapEntry{
 				Key: key,
-				Value: &ast.StringLiteral{
-					Value: "",
-					SourceSpan: ast.SourceSpan{
-						Filename:  s.Filename(),
-						StartLine: keyStartLine,
-						StartCol:  keyStart,
-						EndLine:   keyStartLine,
-						EndCol:    keyStart + len(key) + 1,
-					},
-				},
+				Value: p.newStringLiteral("", ast.SourceSpan{
+					Filename:  s.Filename(),
+					StartLine: keyStartLine,
+					StartCol:  keyStart,
+					EndLine:   keyStartLine,
+					EndCol:    keyStart + len(key) + 1,
+				}),
 				SourceSpan: ast.SourceSpan{
 					Filename:  s.Filename(),
 					StartLine: keyStartLine,
@@ -693,7 +802,7 @@ func (p *Parser) parseConfigBlock(s *scanner.Scanner) ([]ast.MapEntry, error) {
 		s.SkipToNextLine()
 	}
 
-	return config, nil
+	return p.finishEntries(config), nil
 }
 
 // parseNestedMap parses a nested map at a specific indentation level.
@@ -704,7 +813,7 @@ func (p *Parser) parseNestedMap(s *scanner.Scanner, expectedIndent int) ([]ast.M
 // parseNestedMapWithListDepth parses a nested map at a specific indentation level,
 // using listDepth for any lists encountered within the map.
 func (p *Parser) parseNestedMapWithListDepth(s *scanner.Scanner, expectedIndent int, listDepth int) ([]ast.MapEntry, error) {
-	entries := make([]ast.MapEntry, 0)
+	entries := p.takeEntries()
 
 	for !s.IsEOF() {
 		// Check if we're still at the correct indentation level
@@ -777,7 +886,10 @@ func (p *Parser) parseNestedMapWithListDepth(s *scanner.Scanner, expectedIndent
 
 		keyStartLine := s.Line()
 		keyStart := s.Column()
-		key := s.ReadIdentifier()
+		key, err := p.key(s, s.ReadIdentifier(), keyStartLine, keyStart)
+		if err != nil {
+			return nil, err
+		}
 
 		if key == "" {
 			return nil, NewParseError(SyntaxError, s.Filename(), s.Line(), keyStart,
@@ -847,16 +959,13 @@ func (p *Parser) parseNestedMapWithListDepth(s *scanner.Scanner, expectedIndent
 					endCol := p.mapEndColumn(s)
 					entries = append(entries, ast.MapEntry{
 						Key: key,
-						Value: &ast.MapExpr{
-							Entries: nestedEntries,
-							SourceSpan: ast.SourceSpan{
-								Filename:  s.Filename(),
-								StartLine: keyStartLine,
-								StartCol:  keyStart,
-								EndLine:   endLine,
-								EndCol:    endCol,
-							},
-						},
+						Value: p.newMapExpr(nestedEntries, ast.SourceSpan{
+							Filename:  s.Filename(),
+							StartLine: keyStartLine,
+							StartCol:  keyStart,
+							EndLine:   endLine,
+							EndCol:    endCol,
+						}),
 						SourceSpan: ast.SourceSpan{
 							Filename:  s.Filename(),
 							StartLine: keyStartLine,
@@ -872,16 +981,13 @@ func (p *Parser) parseNestedMapWithListDepth(s *scanner.Scanner, expectedIndent
 			// Empty value
 			entries = append(entries, ast.MapEntry{
 				Key: key,
-				Value: &ast.StringLiteral{
-					Value: "",
-					SourceSpan: ast.SourceSpan{
-						Filename:  s.Filename(),
-						StartLine: keyStartLine,
-						StartCol:  keyStart,
-						EndLine:   keyStartLine,
-						EndCol:    keyStart + len(key) + 1,
-					},
-				},
+				Value: p.newStringLiteral("", ast.SourceSpan{
+					Filename:  s.Filename(),
+					StartLine: keyStartLine,
+					StartCol:  keyStart,
+					EndLine:   keyStartLine,
+					EndCol:    keyStart + len(key) + 1,
+				}),
 				SourceSpan: ast.SourceSpan{
 					Filename:  s.Filename(),
 					StartLine: keyStartLine,
@@ -914,7 +1020,7 @@ func (p *Parser) parseNestedMapWithListDepth(s *scanner.Scanner, expectedIndent
 		s.SkipToNextLine()
 	}
 
-	return entries, nil
+	return p.finishEntries(entries), nil
 }
 
 // parseValueExpr parses a value expression, which can be either a string literal,
@@ -992,85 +1098,19 @@ func (p *Parser) parseValueExpr(s *scanner.Scanner, startLine, startCol int) (as
 
 	var expr ast.Expr
 
-	// Check if this is an inline reference expression
-	if strings.HasPrefix(valueText, "@") {
-		// Validate no whitespace in reference
-		if strings.ContainsAny(valueText, " \t\n\r") {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: whitespace not allowed in @ reference")
-		}
-
-		// Validate not just "@" alone
-		if len(valueText) == 1 {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: incomplete @ reference expression")
-		}
-
-		// Check for double @@
-		if strings.HasPrefix(valueText, "@@") {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: double @ in reference expression")
-		}
-
-		// Parse reference expression: @alias:path
-		// T033: Parse inline reference syntax @alias:path
-		refText := valueText[1:] // Remove @
-
-		// Split by ":" to get alias and path
-		parts := strings.SplitN(refText, ":", 2)
-		if len(parts) != 2 {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: @ reference must use format @alias:path")
-		}
-
-		aliasName := parts[0]
-		pathStr := parts[1]
-
-		// T035: Validate alias identifier
-		if aliasName == "" {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: alias cannot be empty (@alias:path)")
-		}
-
-		// Validate alias name pattern: ^[a-zA-Z_][a-zA-Z0-9_-]*$
-		if !isValidAliasName(aliasName) {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: alias name must start with letter or underscore and contain only letters, numbers, underscores, or hyphens")
-		}
-
-		// Validate path exists
-		if pathStr == "" {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: path cannot be empty; use '*' for root (@alias:*)")
-		}
-
-		if strings.Contains(pathStr, ":") {
-			return nil, NewParseError(SyntaxError, s.Filename(), startLine, startCol,
-				"invalid syntax: @ reference path must use '.' only (no additional ':')")
-		}
-
-		// Parse path segments (dot-separated segments with optional bracket notation)
-		pathParts, err := p.parseInlineReferencePath(pathStr, s.Filename(), startLine, startCol)
+	// Check if this is an inline reference expression or a function call
+	if !wasQuoted && strings.HasPrefix(valueText, "fn:") {
+		call, err := p.parseCallExpr(valueText, s.Filename(), startLine, startCol)
 		if err != nil {
 			return nil, err
 		}
-
-		// Calculate the end column (1-indexed, inclusive)
-		// startCol is the 1-indexed column where the value starts
-		// EndCol should point to the last character of the value (inclusive)
-		refEndCol := startCol + len(valueText) - 1
-
-		expr = &ast.ReferenceExpr{
-			Alias: aliasName,
-			Path:  pathParts,
-			SourceSpan: ast.SourceSpan{
-				Filename:  s.Filename(),
-				StartLine: startLine,
-				StartCol:  startCol,
-				EndLine:   startLine, // Inline references are single-line
-				EndCol:    refEndCol,
-			},
+		expr = call
+	} else if strings.HasPrefix(valueText, "@") {
+		ref, err := p.parseReferenceText(valueText, s.Filename(), startLine, startCol)
+		if err != nil {
+			return nil, err
 		}
+		expr = ref
 	} else {
 		// Plain string literal (ReadValue has already stripped quotes if any)
 		// EndCol is 1-indexed and inclusive (points to last character)
@@ -1079,16 +1119,13 @@ func (p *Parser) parseValueExpr(s *scanner.Scanner, startLine, startCol int) (as
 			literalEndCol = startCol
 		}
 
-		expr = &ast.StringLiteral{
-			Value: valueText,
-			SourceSpan: ast.SourceSpan{
-				Filename:  s.Filename(),
-				StartLine: startLine,
-				StartCol:  startCol,
-				EndLine:   startLine,
-				EndCol:    literalEndCol,
-			},
-		}
+		expr = p.newStringLiteral(valueText, ast.SourceSpan{
+			Filename:  s.Filename(),
+			StartLine: startLine,
+			StartCol:  startCol,
+			EndLine:   startLine,
+			EndCol:    literalEndCol,
+		})
 	}
 
 	if isMarked {
@@ -1107,6 +1144,93 @@ func (p *Parser) parseValueExpr(s *scanner.Scanner, startLine, startCol int) (as
 	return expr, nil
 }
 
+// parseReferenceText parses inline reference text of the form @alias:dotted.path
+// starting at the given line and column.
+func (p *Parser) parseReferenceText(valueText, filename string, startLine, startCol int) (*ast.ReferenceExpr, error) {
+	// Validate no whitespace in reference
+	if strings.ContainsAny(valueText, " \t\n\r") {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: whitespace not allowed in @ reference")
+	}
+
+	// Validate not just "@" alone
+	if len(valueText) == 1 {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: incomplete @ reference expression")
+	}
+
+	// Check for double @@
+	if strings.HasPrefix(valueText, "@@") {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: double @ in reference expression")
+	}
+
+	// Parse reference expression: @alias:path
+	// T033: Parse inline reference syntax @alias:path
+	refText := valueText[1:] // Remove @
+
+	// Split by ":" to get alias and path
+	parts := strings.SplitN(refText, ":", 2)
+	if len(parts) != 2 {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: @ reference must use format @alias:path")
+	}
+
+	aliasName := parts[0]
+	pathStr := parts[1]
+
+	// T035: Validate alias identifier
+	if aliasName == "" {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: alias cannot be empty (@alias:path)")
+	}
+
+	// Validate alias name pattern: ^[a-zA-Z_][a-zA-Z0-9_-]*$, optionally
+	// namespaced with '/' (e.g. team1/configs)
+	if !isValidNamespacedAlias(aliasName) {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: alias name must start with letter or underscore and contain only letters, numbers, underscores, or hyphens (namespaces are separated by '/')")
+	}
+
+	// Validate path exists
+	if pathStr == "" {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: path cannot be empty; use '*' for root (@alias:*)")
+	}
+
+	if strings.Contains(pathStr, ":") {
+		return nil, NewParseError(SyntaxError, filename, startLine, startCol,
+			"invalid syntax: @ reference path must use '.' only (no additional ':')")
+	}
+
+	// Parse path segments (dot-separated segments with optional bracket notation)
+	pathParts, err := p.parseInlineReferencePath(pathStr, filename, startLine, startCol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate the end column (1-indexed, inclusive)
+	// startCol is the 1-indexed column where the value starts
+	// EndCol should point to the last character of the value (inclusive)
+	refEndCol := startCol + len(valueText) - 1
+
+	for i, part := range pathParts {
+		pathParts[i] = p.intern(part)
+	}
+
+	return &ast.ReferenceExpr{
+		Alias: p.intern(aliasName),
+		Path:  pathParts,
+		SourceSpan: ast.SourceSpan{
+			Filename:  filename,
+			StartLine: startLine,
+			StartCol:  startCol,
+			EndLine:   startLine, // Inline references are single-line
+			EndCol:    refEndCol,
+		},
+	}, nil
+}
+
 // parseInlineReferencePath splits an inline reference path into components.
 // It supports dot-separated components with optional list index notation, e.g. "matrix[0][1]".
 // Indexes are appended to the current component (e.g., "matrix[0][1]").
@@ -1387,12 +1511,16 @@ func (p *Parser) parseListExpr(s *scanner.Scanner, baseIndent int, depth int, st
 		// Inline object list item (e.g., "- name: alice")
 		mapSnapshot := s.Snapshot()
 		mapKeyStartLine, mapKeyStartCol := s.Line(), s.Column()
-		mapKey := s.ReadIdentifier()
-		if mapKey != "" {
+		rawMapKey := s.ReadIdentifier()
+		if rawMapKey != "" {
 			s.SkipWhitespace()
 			if s.PeekChar() == ':' {
 				_ = s.Expect(':')
 				if p.isInlineMapDelimiter(s.PeekChar()) {
+					mapKey, err := p.key(s, rawMapKey, mapKeyStartLine, mapKeyStartCol)
+					if err != nil {
+						return nil, err
+					}
 					s.SkipWhitespace()
 					keyIndent := mapKeyStartCol - 1
 
@@ -1815,16 +1943,63 @@ func isValidAliasName(name string) bool {
 	return true
 }
 
+// isValidNamespacedAlias checks if an alias is one or more '/'-separated
+// segments that each satisfy isValidAliasName (e.g. "configs", "team1/configs").
+func isValidNamespacedAlias(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if !isValidAliasName(segment) {
+			return false
+		}
+	}
+	return true
+}
+
 // validateSemver validates that a version string is valid semantic versioning format.
-// Empty strings are valid (representing unversioned providers).
+// Empty strings are valid (representing unversioned providers), as are the
+// release channels "latest" and "prerelease".
 // Returns an error with actionable guidance if the version is invalid.
 func validateSemver(version string) error {
-	if version == "" {
+	if version == "" || isReleaseChannel(version) {
 		return nil // Empty is valid (unversioned provider)
 	}
 	_, err := semver.StrictNewVersion(version)
 	if err != nil {
-		return fmt.Errorf("invalid version format: %q - must be valid semantic version (e.g., \"1.2.3\", \"2.0.0-beta.1\"). See https://semver.org", version)
+		return fmt.Errorf("invalid version format: %q - must be valid semantic version (e.g., \"1.2.3\", \"2.0.0-beta.1\") or a release channel (\"latest\", \"prerelease\"). See https://semver.org", version)
 	}
 	return nil
 }
+
+// isReleaseChannel reports whether version names a release channel that is
+// resolved to a concrete release when providers are installed.
+func isReleaseChannel(version string) bool {
+	return version == "latest" || version == "prerelease"
+}
+
+// validatePinnedRelease validates a version pinned by an asset digest. The
+// version must name one release tag (not a channel), and the digest must be
+// a SHA-256 digest in "sha256:<64 hex>" form.
+func validatePinnedRelease(version, digest string) error {
+	if version == "" || isReleaseChannel(version) || strings.ContainsAny(version, " \t") {
+		return fmt.Errorf("invalid version %q: a 'digest' pins one release, so 'version' must be a release tag (e.g., \"v1.2.3\")", version)
+	}
+	if !isSHA256Digest(digest) {
+		return fmt.Errorf("invalid digest %q: must be \"sha256:\" followed by 64 lowercase hex characters", digest)
+	}
+	return nil
+}
+
+// validateChecksum validates a provider binary checksum, which must be a
+// SHA-256 digest in "sha256:<64 hex>" form.
+func validateChecksum(checksum string) error {
+	if !isSHA256Digest(checksum) {
+		return fmt.Errorf("invalid checksum %q: must be \"sha256:\" followed by 64 lowercase hex characters", checksum)
+	}
+	return nil
+}
+
+// isSHA256Digest reports whether s is "sha256:" followed by 64 lowercase
+// hex characters.
+func isSHA256Digest(s string) bool {
+	hexDigest, ok := strings.CutPrefix(s, "sha256:")
+	return ok && len(hexDigest) == 64 && strings.Trim(hexDigest, "0123456789abcdef") == ""
+}
@@ -524,3 +524,42 @@ func BenchmarkParseListNested(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkParse_Keys benchmarks parsing synthetic files with 10k and 100k
+// leaf keys, grouped into sections of 100 keys, and reports throughput. The
+// pooled variant reuses one parser with string interning and the node arena.
+func BenchmarkParse_Keys(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		var builder strings.Builder
+		for s := 0; s < n/100; s++ {
+			fmt.Fprintf(&builder, "section%d:\n", s)
+			for k := 0; k < 100; k++ {
+				fmt.Fprintf(&builder, "  key%d: 'value%d-%d'\n", k, s, k)
+			}
+		}
+		source := []byte(builder.String())
+
+		variants := []struct {
+			name string
+			opts []parser.Option
+		}{
+			{"default", nil},
+			{"pooled", []parser.Option{parser.WithStringInterning(true), parser.WithNodeArena(true)}},
+		}
+		for _, v := range variants {
+			b.Run(fmt.Sprintf("keys=%d/%s", n, v.name), func(b *testing.B) {
+				p := parser.NewParser(v.opts...)
+
+				b.SetBytes(int64(len(source)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := p.Parse(bytes.NewReader(source), "test.csl"); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "keys/s")
+			})
+		}
+	}
+}
@@ -41,8 +41,13 @@ type Stmt interface {
 
 // SpreadStmt represents a top-level spread reference statement.
 // Example: @alias:path.to.map
+//
+// With a prefix the referenced tree is placed under that key instead of
+// merging at the root.
+// Example: @alias:path.to.map as teams.network
 type SpreadStmt struct {
 	Reference  *ReferenceExpr `json:"reference"`
+	Prefix     string         `json:"prefix,omitempty"` // Dot-separated key path, empty for root
 	SourceSpan SourceSpan     `json:"source_span"`
 }
 
@@ -61,8 +66,10 @@ func (s *SpreadStmt) stmt()            {}
 type SourceDecl struct {
 	Alias      string          `json:"alias"`
 	Type       string          `json:"type"`
-	Version    string          `json:"version"` // Semantic version or empty string for unversioned providers
-	Config     map[string]Expr `json:"config"`  // Key-value configuration (excludes reserved fields: alias, type, version)
+	Version    string          `json:"version"`            // Semantic version, release channel ("latest", "prerelease"), or empty string for unversioned providers
+	Digest     string          `json:"digest,omitempty"`   // Optional "sha256:<hex>" asset digest pinning Version to one release asset
+	Checksum   string          `json:"checksum,omitempty"` // Optional "sha256:<hex>" digest of the provider binary the compiler may run
+	Config     map[string]Expr `json:"config"`             // Key-value configuration (excludes reserved fields: alias, type, version, digest, checksum)
 	SourceSpan SourceSpan      `json:"source_span"`
 }
 
@@ -84,6 +91,7 @@ type SectionDecl struct {
 	Name       string     `json:"name"`
 	Value      Expr       `json:"value,omitempty"`   // For inline scalar values (mutually exclusive with Entries)
 	Entries    []MapEntry `json:"entries,omitempty"` // For nested maps (mutually exclusive with Value)
+	Doc        string     `json:"doc,omitempty"`     // Comment lines directly above the section, without '#'
 	SourceSpan SourceSpan `json:"source_span"`
 }
 
@@ -92,6 +100,23 @@ func (s *SectionDecl) Span() SourceSpan { return s.SourceSpan }
 func (s *SectionDecl) node()            {}
 func (s *SectionDecl) stmt()            {}
 
+// LegacyStmt represents a top-level statement the language no longer
+// supports. The parser rejects these unless WithLegacyStatements is set,
+// which migration tools use to rewrite them.
+// Example: reference:alias:path.to.value
+// Example: import:alias:path.to.map
+type LegacyStmt struct {
+	Keyword    string     `json:"keyword"` // "reference" or "import"
+	Alias      string     `json:"alias"`
+	Path       []string   `json:"path,omitempty"` // Colon-separated fields after the alias
+	SourceSpan SourceSpan `json:"source_span"`
+}
+
+// Span implements Node for LegacyStmt.
+func (l *LegacyStmt) Span() SourceSpan { return l.SourceSpan }
+func (l *LegacyStmt) node()            {}
+func (l *LegacyStmt) stmt()            {}
+
 // Expr represents expressions (currently minimal; expanded as needed).
 type Expr interface {
 	Node
@@ -153,10 +178,14 @@ func (r *ReferenceExpr) expr()            {}
 //
 // For spread entries, Spread is true and Key is empty. Value must be a ReferenceExpr.
 // For normal entries, Spread is false and Key is required.
+//
+// Doc holds the comment lines directly above the key, without their '#',
+// joined by newlines.
 type MapEntry struct {
 	Key        string     `json:"key,omitempty"`
 	Value      Expr       `json:"value"`
 	Spread     bool       `json:"spread,omitempty"`
+	Doc        string     `json:"doc,omitempty"`
 	SourceSpan SourceSpan `json:"source_span"`
 }
 
@@ -212,3 +241,20 @@ type MarkedExpr struct {
 func (m *MarkedExpr) Span() SourceSpan { return m.SourceSpan }
 func (m *MarkedExpr) node()            {}
 func (m *MarkedExpr) expr()            {}
+
+// CallExpr represents a call to a built-in function in a value position.
+// Example: fn:upper(@base:app.name)
+//
+// Arguments may be string literals (quoted or bare), inline references, or
+// nested calls. Functions are evaluated by the compiler after references
+// are resolved.
+type CallExpr struct {
+	Name       string     `json:"name"`        // Function name without the "fn:" prefix
+	Args       []Expr     `json:"args"`        // Arguments in source order
+	SourceSpan SourceSpan `json:"source_span"` // Precise source location
+}
+
+// Span implements Node for CallExpr.
+func (c *CallExpr) Span() SourceSpan { return c.SourceSpan }
+func (c *CallExpr) node()            {}
+func (c *CallExpr) expr()            {}
@@ -0,0 +1,116 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParse_CallExpr tests parsing of function calls in value positions.
+func TestParse_CallExpr(t *testing.T) {
+	input := `config:
+  name: fn:upper(@base:app.name)
+  hosts: fn:join(@base:hosts, ', ')
+  subnet: fn:cidrsubnet('10.0.0.0/16', 8, fn:lower(A))
+  empty: fn:now()
+  literal: 'fn:upper(x)'
+`
+	result, err := parser.Parse(strings.NewReader(input), "test.csl")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	section := result.Statements[0].(*ast.SectionDecl)
+	entryMap := entryMapHelper(section.Entries)
+
+	name, ok := entryMap["name"].(*ast.CallExpr)
+	if !ok {
+		t.Fatalf("expected CallExpr for name, got %T", entryMap["name"])
+	}
+	if name.Name != "upper" || len(name.Args) != 1 {
+		t.Fatalf("unexpected call: %+v", name)
+	}
+	ref, ok := name.Args[0].(*ast.ReferenceExpr)
+	if !ok || ref.Alias != "base" || strings.Join(ref.Path, ".") != "app.name" {
+		t.Errorf("expected reference @base:app.name, got %#v", name.Args[0])
+	}
+	if span := name.SourceSpan; span.StartLine != 2 || span.StartCol != 9 || span.EndCol != 32 {
+		t.Errorf("call span = %d:%d-%d, want 2:9-32", span.StartLine, span.StartCol, span.EndCol)
+	}
+	if span := ref.SourceSpan; span.StartCol != 18 || span.EndCol != 31 {
+		t.Errorf("argument span = %d-%d, want 18-31", span.StartCol, span.EndCol)
+	}
+
+	hosts := entryMap["hosts"].(*ast.CallExpr)
+	if sep, ok := hosts.Args[1].(*ast.StringLiteral); !ok || sep.Value != ", " {
+		t.Errorf("expected quoted separator ', ', got %#v", hosts.Args[1])
+	}
+
+	subnet := entryMap["subnet"].(*ast.CallExpr)
+	if len(subnet.Args) != 3 {
+		t.Fatalf("expected 3 args, got %d", len(subnet.Args))
+	}
+	if bits, ok := subnet.Args[1].(*ast.StringLiteral); !ok || bits.Value != "8" {
+		t.Errorf("expected bare literal 8, got %#v", subnet.Args[1])
+	}
+	if nested, ok := subnet.Args[2].(*ast.CallExpr); !ok || nested.Name != "lower" {
+		t.Errorf("expected nested call, got %#v", subnet.Args[2])
+	}
+
+	if empty := entryMap["empty"].(*ast.CallExpr); len(empty.Args) != 0 {
+		t.Errorf("expected no args, got %d", len(empty.Args))
+	}
+
+	if _, ok := entryMap["literal"].(*ast.StringLiteral); !ok {
+		t.Errorf("expected quoted call text to stay a string literal, got %T", entryMap["literal"])
+	}
+}
+
+// TestParse_CallExpr_Marked tests that calls can be marked for encryption.
+func TestParse_CallExpr_Marked(t *testing.T) {
+	result, err := parser.Parse(strings.NewReader("token: fn:base64encode(@vault:token)!\n"), "test.csl")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	section := result.Statements[0].(*ast.SectionDecl)
+	marked, ok := section.Value.(*ast.MarkedExpr)
+	if !ok {
+		t.Fatalf("expected MarkedExpr, got %T", section.Value)
+	}
+	if _, ok := marked.Expr.(*ast.CallExpr); !ok {
+		t.Errorf("expected CallExpr inside MarkedExpr, got %T", marked.Expr)
+	}
+}
+
+// TestParse_CallExpr_Errors tests syntax errors in function calls.
+func TestParse_CallExpr_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"missing parens", "a: fn:upper\n", "expected '('"},
+		{"missing close", "a: fn:upper(x\n", "missing ')'"},
+		{"bad separator", "a: fn:join(x y)\n", "expected ',' or ')'"},
+		{"empty argument", "a: fn:join(x,)\n", "expected argument"},
+		{"trailing text", "a: fn:upper(x) y\n", "after function call"},
+		{"bad name", "a: fn:1up(x)\n", "function name"},
+		{"unterminated string", "a: fn:upper('x)\n", "unterminated string"},
+		{"bad reference", "a: fn:upper(@:x)\n", "alias cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(strings.NewReader(tt.input), "test.csl")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParse_DocComments tests that comment lines directly above a section or
+// key become its Doc.
+func TestParse_DocComments(t *testing.T) {
+	input := `# File header, separated by a blank line
+
+# The application.
+#
+#   Indented detail survives.
+app:
+  # Listen port
+  port: 8080 # trailing comments are not docs
+  host: localhost
+  # Replica settings
+  replicas:
+    # Minimum count
+    min: 2
+  servers:
+    # Not attached: the key shares its line with the list marker
+    - name: a
+      # Zone of the server
+      zone: eu
+region: 'us-east-1'
+#
+# Framed comment
+#
+db: @base:db
+`
+	tree, err := parser.Parse(strings.NewReader(input), "docs.csl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	sections := map[string]*ast.SectionDecl{}
+	for _, stmt := range tree.Statements {
+		if s, ok := stmt.(*ast.SectionDecl); ok {
+			sections[s.Name] = s
+		}
+	}
+	app := sections["app"]
+	if app == nil {
+		t.Fatal("app section missing")
+	}
+
+	docs := map[string]string{}
+	var collect func(prefix string, entries []ast.MapEntry)
+	collect = func(prefix string, entries []ast.MapEntry) {
+		for _, e := range entries {
+			docs[prefix+e.Key] = e.Doc
+			switch v := e.Value.(type) {
+			case *ast.MapExpr:
+				collect(prefix+e.Key+".", v.Entries)
+			case *ast.ListExpr:
+				for _, elem := range v.Elements {
+					if m, ok := elem.(*ast.MapExpr); ok {
+						collect(prefix+e.Key+"[].", m.Entries)
+					}
+				}
+			}
+		}
+	}
+	collect("", app.Entries)
+
+	tests := []struct {
+		key  string
+		got  string
+		want string
+	}{
+		{key: "app", got: app.Doc, want: "The application.\n\n  Indented detail survives."},
+		{key: "port", got: docs["port"], want: "Listen port"},
+		{key: "host", got: docs["host"], want: ""},
+		{key: "replicas", got: docs["replicas"], want: "Replica settings"},
+		{key: "replicas.min", got: docs["replicas.min"], want: "Minimum count"},
+		{key: "servers", got: docs["servers"], want: ""},
+		{key: "servers[].name", got: docs["servers[].name"], want: ""},
+		{key: "servers[].zone", got: docs["servers[].zone"], want: "Zone of the server"},
+		{key: "region", got: sections["region"].Doc, want: ""},
+		{key: "db", got: sections["db"].Doc, want: "Framed comment"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s doc = %q, want %q", tt.key, tt.got, tt.want)
+		}
+	}
+}
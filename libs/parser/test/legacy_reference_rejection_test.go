@@ -2,10 +2,12 @@
 package parser_test
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
 )
 
 // TestParseLegacyTopLevelReference_Rejected tests that top-level reference: statements
@@ -78,3 +80,49 @@ config:
 		t.Errorf("expected error message to suggest inline reference syntax, got: %s", errMsg)
 	}
 }
+
+// TestParseLegacyStatements_Option tests that WithLegacyStatements returns
+// legacy statements as nodes, with spans covering the statement text.
+func TestParseLegacyStatements_Option(t *testing.T) {
+	input := "import:base\nreference:network:config:vpc.cidr   # comment\n\napp:\n  name: 'demo'\n"
+
+	p := parser.NewParser(parser.WithLegacyStatements(true))
+	tree, err := p.Parse(strings.NewReader(input), "test.csl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree.Statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(tree.Statements))
+	}
+
+	want := []*ast.LegacyStmt{
+		{Keyword: "import", Alias: "base", SourceSpan: ast.SourceSpan{Filename: "test.csl", StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 11}},
+		{Keyword: "reference", Alias: "network", Path: []string{"config", "vpc.cidr"}, SourceSpan: ast.SourceSpan{Filename: "test.csl", StartLine: 2, StartCol: 1, EndLine: 2, EndCol: 33}},
+	}
+	for i, w := range want {
+		got, ok := tree.Statements[i].(*ast.LegacyStmt)
+		if !ok {
+			t.Fatalf("statement %d: expected *ast.LegacyStmt, got %T", i, tree.Statements[i])
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("statement %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// Without the option the same input is still rejected, as legacy syntax
+	for _, legacy := range []string{"import:base\n", "reference:network:vpc.cidr\n"} {
+		_, err := parser.Parse(strings.NewReader(legacy), "test.csl")
+		if err == nil {
+			t.Fatalf("expected parse error for %q without WithLegacyStatements, got nil", legacy)
+		}
+		if !parser.IsLegacySyntax(err) {
+			t.Errorf("IsLegacySyntax(%v) = false, want true", err)
+		}
+	}
+	if _, err := parser.Parse(strings.NewReader("app: {\n"), "test.csl"); parser.IsLegacySyntax(err) {
+		t.Errorf("IsLegacySyntax(%v) = true, want false", err)
+	}
+	if _, err := p.Parse(strings.NewReader("reference:\n"), "test.csl"); err == nil {
+		t.Error("expected parse error for a legacy statement without alias, got nil")
+	}
+}
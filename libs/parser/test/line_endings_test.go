@@ -0,0 +1,66 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParse_LineEndings tests that CRLF, lone CR, and byte order marked
+// sources produce the same AST spans as their LF equivalent.
+func TestParse_LineEndings(t *testing.T) {
+	lf := "app:\n  name: 'x'\n  items:\n    - a\n    - b\n"
+	want, err := parser.Parse(strings.NewReader(lf), "app.csl")
+	if err != nil {
+		t.Fatalf("Parse() LF error = %v", err)
+	}
+
+	for name, input := range map[string]string{
+		"CRLF":            strings.ReplaceAll(lf, "\n", "\r\n"),
+		"lone CR":         strings.ReplaceAll(lf, "\n", "\r"),
+		"byte order mark": "\uFEFF" + lf,
+		"both":            "\uFEFF" + strings.ReplaceAll(lf, "\n", "\r\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := parser.Parse(strings.NewReader(input), "app.csl")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			wantSection := want.Statements[0].(*ast.SectionDecl)
+			section := got.Statements[0].(*ast.SectionDecl)
+			if section.Name != "app" || section.SourceSpan != wantSection.SourceSpan {
+				t.Errorf("section = %q %+v, want %q %+v", section.Name, section.SourceSpan, "app", wantSection.SourceSpan)
+			}
+			for i, entry := range section.Entries {
+				if entry.SourceSpan != wantSection.Entries[i].SourceSpan {
+					t.Errorf("entry %q span = %+v, want %+v", entry.Key, entry.SourceSpan, wantSection.Entries[i].SourceSpan)
+				}
+			}
+			if got.SourceSpan != want.SourceSpan {
+				t.Errorf("AST span = %+v, want %+v", got.SourceSpan, want.SourceSpan)
+			}
+		})
+	}
+}
+
+// TestFormatParseError_CRLF tests that snippets of CRLF sources carry no
+// carriage returns and the caret lines up with the error column.
+func TestFormatParseError_CRLF(t *testing.T) {
+	input := "\uFEFFapp:\r\n  name: 'x'\r\n  bad key: 1\r\n"
+
+	_, err := parser.Parse(strings.NewReader(input), "app.csl")
+	if err == nil {
+		t.Fatal("Parse() error = nil, want a syntax error")
+	}
+
+	want := "app.csl:3:7: invalid syntax: expected ':' after key\n" +
+		"   2 |   name: 'x'\n" +
+		"   3 |   bad key: 1\n" +
+		"   4 | \n" +
+		"     |       ^\n"
+	if got := parser.FormatParseError(err, input); got != want {
+		t.Errorf("FormatParseError() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package parser_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParserOptions_SameAST verifies that interning and the node arena do not
+// change the AST produced for any fixture, including when one parser instance
+// is reused for every file.
+func TestParserOptions_SameAST(t *testing.T) {
+	files, err := filepath.Glob("../testdata/fixtures/*.csl")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found: %v", err)
+	}
+
+	pooled := parser.NewParser(parser.WithStringInterning(true), parser.WithNodeArena(true))
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			want, wantErr := parser.ParseFile(file)
+			got, gotErr := pooled.ParseFile(file)
+
+			if (wantErr == nil) != (gotErr == nil) {
+				t.Fatalf("error mismatch: default %v, pooled %v", wantErr, gotErr)
+			}
+			if wantErr != nil {
+				if wantErr.Error() != gotErr.Error() {
+					t.Errorf("error = %q, want %q", gotErr, wantErr)
+				}
+				return
+			}
+			if !bytes.Equal(mustJSON(t, got), mustJSON(t, want)) {
+				t.Errorf("pooled AST differs from default AST")
+			}
+		})
+	}
+}
+
+// TestParserOptions_ReuseKeepsEarlierAST verifies that reusing an arena-backed
+// parser does not modify ASTs returned by earlier parses.
+func TestParserOptions_ReuseKeepsEarlierAST(t *testing.T) {
+	p := parser.NewParser(parser.WithNodeArena(true))
+
+	first, err := p.ParseFile("../testdata/fixtures/complex_config.csl")
+	if err != nil {
+		t.Fatalf("first parse: %v", err)
+	}
+	before := mustJSON(t, first)
+
+	data, err := os.ReadFile("../testdata/fixtures/all_grammar.csl")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if _, err := p.Parse(bytes.NewReader(data), "all_grammar.csl"); err != nil {
+		t.Fatalf("second parse: %v", err)
+	}
+
+	if !bytes.Equal(mustJSON(t, first), before) {
+		t.Error("first AST changed after reusing the parser")
+	}
+}
+
+// TestWithStringInterning_SharesKeys verifies that equal keys parsed from
+// different files share one backing string.
+func TestWithStringInterning_SharesKeys(t *testing.T) {
+	p := parser.NewParser(parser.WithStringInterning(true))
+
+	key := func(src string) string {
+		t.Helper()
+		tree, err := p.Parse(bytes.NewReader([]byte(src)), "app.csl")
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		return tree.Statements[0].(*ast.SectionDecl).Entries[0].Key
+	}
+
+	a := key("app:\n  region: 'us-east-1'\n")
+	b := key("db:\n  region: 'eu-west-1'\n")
+	if a != "region" || b != "region" {
+		t.Fatalf("keys = %q, %q, want region", a, b)
+	}
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("expected interned keys to share storage")
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal AST: %v", err)
+	}
+	return data
+}
@@ -68,6 +68,15 @@ func TestParseReferenceExpr_RootReference(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "root reference with namespaced alias",
+			input: "@team1/configs:*",
+			want: &ast.ReferenceExpr{
+				Alias: "team1/configs",
+				Path:  []string{"*"},
+			},
+			wantErr: false,
+		},
 		{
 			name:  "T021: root reference with nested wildcard",
 			input: "@base:database.*",
@@ -101,6 +110,18 @@ func TestParseReferenceExpr_RootReference(t *testing.T) {
 			wantErr: true,
 			errMsg:  "alias cannot be empty",
 		},
+		{
+			name:    "malformed - empty namespace segment",
+			input:   "@team1//configs:*",
+			wantErr: true,
+			errMsg:  "alias name must start with letter or underscore",
+		},
+		{
+			name:    "malformed - trailing namespace separator",
+			input:   "@team1/:*",
+			wantErr: true,
+			errMsg:  "alias name must start with letter or underscore",
+		},
 		{
 			name:    "T022: malformed - root dot no longer allowed",
 			input:   "@base:.",
@@ -0,0 +1,114 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParseSpreadStmt_Prefix tests placing a top-level spread under a key
+// prefix with 'as'.
+func TestParseSpreadStmt_Prefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantAlias  string
+		wantPath   []string
+		wantPrefix string
+		wantErr    string
+	}{
+		{
+			name:      "no prefix",
+			input:     "@network:*\n",
+			wantAlias: "network",
+			wantPath:  []string{"*"},
+		},
+		{
+			name:       "single key prefix",
+			input:      "@network:* as network\n",
+			wantAlias:  "network",
+			wantPath:   []string{"*"},
+			wantPrefix: "network",
+		},
+		{
+			name:       "dotted prefix with comment",
+			input:      "@team1/configs:platform.vpc as teams.team1  # team 1's tree\n",
+			wantAlias:  "team1/configs",
+			wantPath:   []string{"platform", "vpc"},
+			wantPrefix: "teams.team1",
+		},
+		{
+			name:    "missing prefix",
+			input:   "@network:* as\n",
+			wantErr: "'as' must be followed by a key prefix",
+		},
+		{
+			name:    "extra tokens",
+			input:   "@network:* as net extra\n",
+			wantErr: "'as' must be followed by a key prefix",
+		},
+		{
+			name:    "empty prefix segment",
+			input:   "@network:* as teams..net\n",
+			wantErr: "'as' must be followed by a key prefix",
+		},
+		{
+			name:    "invalid reference",
+			input:   "@network as net\n",
+			wantErr: "must use format @alias:path",
+		},
+		{
+			name:    "other trailing text",
+			input:   "@network:* into net\n",
+			wantErr: "whitespace not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(strings.NewReader(tt.input), "test.csl")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if len(result.Statements) != 1 {
+				t.Fatalf("expected 1 statement, got %d", len(result.Statements))
+			}
+
+			spread, ok := result.Statements[0].(*ast.SpreadStmt)
+			if !ok {
+				t.Fatalf("expected SpreadStmt, got %T", result.Statements[0])
+			}
+			if spread.Reference.Alias != tt.wantAlias || strings.Join(spread.Reference.Path, ".") != strings.Join(tt.wantPath, ".") {
+				t.Errorf("Reference = @%s:%v, want @%s:%v", spread.Reference.Alias, spread.Reference.Path, tt.wantAlias, tt.wantPath)
+			}
+			if spread.Prefix != tt.wantPrefix {
+				t.Errorf("Prefix = %q, want %q", spread.Prefix, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+// TestParseSpreadStmt_PrefixSpan tests that a prefixed spread's span covers
+// the prefix.
+func TestParseSpreadStmt_PrefixSpan(t *testing.T) {
+	result, err := parser.Parse(strings.NewReader("app:\n  name: 'demo'\n@base:* as shared\n"), "test.csl")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	spread := result.Statements[1].(*ast.SpreadStmt)
+	want := ast.SourceSpan{Filename: "test.csl", StartLine: 3, StartCol: 1, EndLine: 3, EndCol: 17}
+	if spread.SourceSpan != want {
+		t.Errorf("SourceSpan = %+v, want %+v", spread.SourceSpan, want)
+	}
+	if got := spread.Reference.SourceSpan.EndCol; got != 7 {
+		t.Errorf("Reference.SourceSpan.EndCol = %d, want 7", got)
+	}
+}
@@ -0,0 +1,99 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/parser"
+	"github.com/autonomous-bits/nomos/libs/parser/pkg/ast"
+)
+
+// TestParse_NormalizesKeys tests that keys and section names spelled with
+// combining characters are normalized to NFC.
+func TestParse_NormalizesKeys(t *testing.T) {
+	input := "cafe\u0301:\n  nin\u0303o: 1\n  items:\n    - re\u0301sume\u0301: 'cv'\n"
+
+	tree, err := parser.Parse(strings.NewReader(input), "keys.csl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	section := tree.Statements[0].(*ast.SectionDecl)
+	if section.Name != "caf\u00e9" {
+		t.Errorf("section name = %q, want %q", section.Name, "caf\u00e9")
+	}
+	if section.Entries[0].Key != "ni\u00f1o" {
+		t.Errorf("key = %q, want %q", section.Entries[0].Key, "ni\u00f1o")
+	}
+	item := section.Entries[1].Value.(*ast.ListExpr).Elements[0].(*ast.MapExpr)
+	if item.Entries[0].Key != "r\u00e9sum\u00e9" {
+		t.Errorf("list item key = %q, want %q", item.Entries[0].Key, "r\u00e9sum\u00e9")
+	}
+}
+
+// TestParse_KeyCollisions tests that two spellings of one key in the same
+// map are rejected, while equal spellings and keys in different maps are not.
+func TestParse_KeyCollisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "nested keys",
+			input:   "app:\n  caf\u00e9: 1\n  cafe\u0301: 2\n",
+			wantErr: `keys.csl:3:3: invalid syntax: key "cafe\u0301" collides with key "caf\u00e9" at line 2, column 3; both normalize to "caf\u00e9"`,
+		},
+		{
+			name:    "section names",
+			input:   "cafe\u0301: 1\ncaf\u00e9: 2\n",
+			wantErr: `keys.csl:2:1: invalid syntax: key "caf\u00e9" collides with key "cafe\u0301" at line 1, column 1; both normalize to "caf\u00e9"`,
+		},
+		{
+			name:  "same spelling",
+			input: "app:\n  cafe\u0301: 1\n  cafe\u0301: 2\n",
+		},
+		{
+			name:  "different maps",
+			input: "a:\n  caf\u00e9: 1\nb:\n  cafe\u0301: 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(strings.NewReader(tt.input), "keys.csl")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWithASCIIKeys tests that ASCII-only mode rejects keys with other
+// characters at the first of them, and leaves values alone.
+func TestWithASCIIKeys(t *testing.T) {
+	p := parser.NewParser(parser.WithASCIIKeys(true))
+
+	if _, err := p.Parse(strings.NewReader("app:\n  name: 'caf\u00e9'\n"), "ascii.csl"); err != nil {
+		t.Errorf("Parse() of a non-ASCII value error = %v", err)
+	}
+
+	for _, input := range []string{
+		"app:\n  caf\u00e9: 1\n",
+		"app:\n  items:\n    - caf\u00e9: 1\n",
+	} {
+		_, err := p.Parse(strings.NewReader(input), "ascii.csl")
+		if err == nil || !strings.Contains(err.Error(), `non-ASCII character '\u00e9' in key "caf\u00e9"`) {
+			t.Errorf("Parse(%q) error = %v, want a non-ASCII key error", input, err)
+		}
+	}
+
+	_, err := p.Parse(strings.NewReader("re\u0301sume\u0301: 1\n"), "ascii.csl")
+	if err == nil || !strings.Contains(err.Error(), "ascii.csl:1:3:") {
+		t.Errorf("Parse() error = %v, want one at the combining accent, column 3", err)
+	}
+}
@@ -226,6 +226,77 @@ func TestParseSourceDecl_InvalidVersion(t *testing.T) {
 	}
 }
 
+// TestParseSourceDecl_ReleasePins tests release channels, digest-pinned
+// release tags, and binary checksums.
+func TestParseSourceDecl_ReleasePins(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name          string
+		version       string
+		digest        string
+		checksum      string
+		expectedError string
+	}{
+		{name: "latest channel", version: "latest"},
+		{name: "prerelease channel", version: "prerelease"},
+		{name: "digest pins semver", version: "1.2.3", digest: digest},
+		{name: "digest pins release tag", version: "v1.2.3", digest: digest},
+		{name: "digest pins non-semver tag", version: "nightly-2026-01-01", digest: digest},
+		{name: "digest with channel", version: "latest", digest: digest, expectedError: "must be a release tag"},
+		{name: "digest without version", digest: digest, expectedError: "must be a release tag"},
+		{name: "digest not sha256", version: "1.2.3", digest: "md5:abc", expectedError: "invalid digest"},
+		{name: "digest too short", version: "1.2.3", digest: "sha256:abc", expectedError: "invalid digest"},
+		{name: "checksum pins binary", version: "1.2.3", checksum: digest},
+		{name: "checksum with digest", version: "v1.2.3", digest: digest, checksum: digest},
+		{name: "checksum not sha256", version: "1.2.3", checksum: "sha512:abc", expectedError: "invalid checksum"},
+		{name: "digest uppercase", version: "1.2.3", digest: strings.ToUpper(digest[:7]) + strings.ToUpper(digest[7:]), expectedError: "invalid digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "source:\n\talias: 'pinned'\n\ttype: 'owner/repo'\n"
+			if tt.version != "" {
+				input += "\tversion: '" + tt.version + "'\n"
+			}
+			if tt.digest != "" {
+				input += "\tdigest: '" + tt.digest + "'\n"
+			}
+			if tt.checksum != "" {
+				input += "\tchecksum: '" + tt.checksum + "'\n"
+			}
+			input += "\tdirectory: './data'\n"
+
+			result, err := parser.Parse(strings.NewReader(input), "test.csl")
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			decl := result.Statements[0].(*ast.SourceDecl)
+			if decl.Version != tt.version {
+				t.Errorf("Version = %q, want %q", decl.Version, tt.version)
+			}
+			if decl.Digest != tt.digest {
+				t.Errorf("Digest = %q, want %q", decl.Digest, tt.digest)
+			}
+			if decl.Checksum != tt.checksum {
+				t.Errorf("Checksum = %q, want %q", decl.Checksum, tt.checksum)
+			}
+			if _, exists := decl.Config["digest"]; exists {
+				t.Error("digest should be removed from Config")
+			}
+			if _, exists := decl.Config["checksum"]; exists {
+				t.Error("checksum should be removed from Config")
+			}
+		})
+	}
+}
+
 // TestParseSourceDecl_VersionFieldRemovalFromConfig tests that version is removed from Config after extraction.
 func TestParseSourceDecl_VersionFieldRemovalFromConfig(t *testing.T) {
 	input := `source:
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -85,6 +86,47 @@ func TestDownloadAndInstall_TarGzExtraction(t *testing.T) {
 	}
 }
 
+// TestDownloadAndInstall_BinaryChecksum tests that a pinned binary checksum
+// is checked against the extracted binary, not the archive.
+func TestDownloadAndInstall_BinaryChecksum(t *testing.T) {
+	providerContent := []byte("#!/bin/bash\necho 'fake provider'\n")
+	archiveBytes := createTarGzArchive(t, map[string][]byte{
+		"provider": providerContent,
+	})
+
+	//nolint:revive // unused parameter 'r' required by http.HandlerFunc signature
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archiveBytes)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{
+		HTTPClient: server.Client(),
+	})
+	install := func(binaryChecksum string) (string, error) {
+		destDir := t.TempDir()
+		_, err := client.DownloadAndInstall(context.Background(), &AssetInfo{
+			URL:            server.URL + "/provider.tar.gz",
+			Name:           "test-provider-linux-amd64.tar.gz",
+			BinaryChecksum: binaryChecksum,
+		}, destDir)
+		return filepath.Join(destDir, "provider"), err
+	}
+
+	if _, err := install(computeSHA256(providerContent)); err != nil {
+		t.Fatalf("expected no error pinning the binary checksum, got %v", err)
+	}
+
+	path, err := install(computeSHA256(archiveBytes))
+	var checksumErr *ChecksumMismatchError
+	if !errors.As(err, &checksumErr) || checksumErr.Actual != computeSHA256(providerContent) {
+		t.Fatalf("expected ChecksumMismatchError with the binary checksum, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("mismatched binary was installed (stat error = %v)", err)
+	}
+}
+
 // TestDownloadAndInstall_TarGzWithNestedDirectories tests extraction with nested directories.
 func TestDownloadAndInstall_TarGzWithNestedDirectories(t *testing.T) {
 	// Arrange: Create a tar.gz with nested directory structure
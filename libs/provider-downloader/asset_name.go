@@ -0,0 +1,213 @@
+package downloader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AssetExtensions are the extensions a canonical release asset name may
+// end with: none for a raw binary, ".exe" for a raw Windows binary, or an
+// archive holding the binary.
+var AssetExtensions = []string{"", ".exe", ".tar.gz", ".tgz", ".zip"}
+
+// canonicalOS lists the operating systems a canonical name may use, by
+// their Go names.
+var canonicalOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true,
+}
+
+// canonicalArch lists the architectures a canonical name may use, by their
+// Go names, plus "armv6" and "armv7" for arm binaries built with GOARM=6
+// and GOARM=7.
+var canonicalArch = map[string]bool{
+	"386": true, "amd64": true, "arm": true, "armv6": true, "armv7": true,
+	"arm64": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "ppc64": true, "ppc64le": true, "riscv64": true,
+	"s390x": true, "wasm": true,
+}
+
+// osAliases maps other common operating system names to Go names.
+var osAliases = map[string]string{
+	"macos": "darwin",
+	"osx":   "darwin",
+	"win":   "windows",
+}
+
+// archAliases maps other common architecture names to Go names.
+var archAliases = map[string]string{
+	"x86_64":    "amd64",
+	"x86-64":    "amd64",
+	"x64":       "amd64",
+	"aarch64":   "arm64",
+	"i386":      "386",
+	"i686":      "386",
+	"x86":       "386",
+	"armv7l":    "armv7",
+	"armv6l":    "armv6",
+	"riscv64gc": "riscv64",
+}
+
+// versionSegment matches the first "-"-separated segment of a version.
+var versionSegment = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*(\+[0-9A-Za-z.]+)?$`)
+
+// AssetName is a release asset name split into its parts. The canonical
+// form, returned by String, is
+//
+//	{repo}-{version}-{os}-{arch}[-{libc}]{ext}
+//
+// where version has no "v" prefix, os and arch are Go names (GOOS and
+// GOARCH, so "amd64" rather than "x86_64", with "armv6" and "armv7" for
+// arm binaries of a known ARM version), libc is LibcMusl or LibcGNU for
+// Linux binaries that are not static, and ext is one of AssetExtensions.
+// Windows raw binaries end in ".exe"; no other asset does. For example:
+// nomos-provider-consul-1.2.0-linux-amd64.tar.gz.
+type AssetName struct {
+	Repo    string
+	Version string
+	OS      string
+	Arch    string
+	Libc    string
+	Ext     string
+}
+
+// String returns the canonical asset name.
+func (a AssetName) String() string {
+	libc := ""
+	if a.Libc != "" {
+		libc = "-" + a.Libc
+	}
+	return fmt.Sprintf("%s-%s-%s-%s%s%s", a.Repo, strings.TrimPrefix(a.Version, "v"), a.OS, a.Arch, libc, a.Ext)
+}
+
+// CanonicalOS returns the Go name of an operating system name, accepting
+// aliases such as "macos" and any letter case. ok is false for unknown
+// names.
+func CanonicalOS(name string) (goos string, ok bool) {
+	name = strings.ToLower(name)
+	if canonicalOS[name] {
+		return name, true
+	}
+	goos, ok = osAliases[name]
+	return goos, ok
+}
+
+// CanonicalArch returns the Go name of an architecture name, accepting
+// aliases such as "x86_64", "aarch64", and "armv7l" and any letter case.
+// ok is false for unknown names.
+func CanonicalArch(name string) (goarch string, ok bool) {
+	name = strings.ToLower(name)
+	if canonicalArch[name] {
+		return name, true
+	}
+	goarch, ok = archAliases[name]
+	return goarch, ok
+}
+
+// ParseAssetName splits a release asset name into its parts, mapping
+// aliases to Go names, so "tool-v1.0.0-Linux-x86_64.tar.gz" parses as
+// repo "tool", version "1.0.0", os "linux", arch "amd64". A trailing
+// "-musl", "-gnu", or "-glibc" sets Libc. Names that do not end in
+// {version}-{os}-{arch} with a known extension, such as checksum files,
+// are an error.
+func ParseAssetName(name string) (AssetName, error) {
+	stem, ext := splitAssetExt(name)
+	if strings.Contains(stem[strings.LastIndex(stem, "-")+1:], ".") {
+		return AssetName{}, fmt.Errorf("asset %q: unknown extension (want one of .exe, .tar.gz, .tgz, .zip, or none)", name)
+	}
+
+	// "x86-64" is the only alias containing the separator
+	segments := strings.Split(stem, "-")
+	if n := len(segments); n >= 2 && strings.EqualFold(segments[n-2]+"-"+segments[n-1], "x86-64") {
+		segments = append(segments[:n-2], "x86-64")
+	}
+	libc := ""
+	if n := len(segments); n > 0 {
+		if l, ok := libcAliases[strings.ToLower(segments[n-1])]; ok {
+			libc, segments = l, segments[:n-1]
+		}
+	}
+	if len(segments) < 4 {
+		return AssetName{}, fmt.Errorf("asset %q: want {repo}-{version}-{os}-{arch}", name)
+	}
+
+	n := len(segments)
+	arch, ok := CanonicalArch(segments[n-1])
+	if !ok {
+		return AssetName{}, fmt.Errorf("asset %q: unknown architecture %q", name, segments[n-1])
+	}
+	goos, ok := CanonicalOS(segments[n-2])
+	if !ok {
+		return AssetName{}, fmt.Errorf("asset %q: unknown operating system %q", name, segments[n-2])
+	}
+
+	// The version is everything from its first segment up to the os, so
+	// pre-release versions may contain "-"
+	for i := 1; i < n-2; i++ {
+		if versionSegment.MatchString(segments[i]) {
+			return AssetName{
+				Repo:    strings.Join(segments[:i], "-"),
+				Version: strings.TrimPrefix(strings.Join(segments[i:n-2], "-"), "v"),
+				OS:      goos,
+				Arch:    arch,
+				Libc:    libc,
+				Ext:     strings.ToLower(ext),
+			}, nil
+		}
+	}
+	return AssetName{}, fmt.Errorf("asset %q: missing version before %q", name, segments[n-2])
+}
+
+// ValidateAssetName reports whether name is a canonical release asset
+// name, see AssetName. The error names the canonical form when name only
+// differs from it by aliases, letter case, or a "v" version prefix.
+func ValidateAssetName(name string) error {
+	a, err := ParseAssetName(name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.Ext == ".exe" && a.OS != "windows":
+		return fmt.Errorf("asset %q: .exe is only used for windows binaries", name)
+	case a.Ext == "" && a.OS == "windows":
+		return fmt.Errorf("asset %q: windows binaries end in .exe", name)
+	case a.Libc != "" && a.OS != "linux":
+		return fmt.Errorf("asset %q: a libc suffix is only used for linux binaries", name)
+	case a.String() != name:
+		return fmt.Errorf("asset %q is not canonical; want %q", name, a.String())
+	}
+	return nil
+}
+
+// splitAssetExt splits a known asset extension off name, matching it in
+// any letter case.
+func splitAssetExt(name string) (stem, ext string) {
+	lower := strings.ToLower(name)
+	for _, e := range []string{".tar.gz", ".tgz", ".zip", ".exe"} {
+		if strings.HasSuffix(lower, e) {
+			return name[:len(name)-len(e)], name[len(name)-len(e):]
+		}
+	}
+	return name, ""
+}
+
+// canonicalAssetNames returns the canonical names of the assets of repo at
+// version that run on a platform, in order of preference: by architecture
+// variant, then libc, then raw binaries, which need no extraction, before
+// archives.
+func canonicalAssetNames(repo, version string, p platform) []string {
+	var names []string
+	for _, arch := range p.arches() {
+		for _, libc := range p.libcs() {
+			for _, ext := range AssetExtensions {
+				if (ext == "" && p.OS == "windows") || (ext == ".exe" && p.OS != "windows") {
+					continue
+				}
+				names = append(names, AssetName{Repo: repo, Version: version, OS: p.OS, Arch: arch, Libc: libc, Ext: ext}.String())
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,93 @@
+package downloader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAssetName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    AssetName
+		wantErr string
+	}{
+		{
+			name: "nomos-provider-consul-1.2.0-linux-amd64.tar.gz",
+			want: AssetName{Repo: "nomos-provider-consul", Version: "1.2.0", OS: "linux", Arch: "amd64", Ext: ".tar.gz"},
+		},
+		{
+			name: "tool-v1.0.0-Linux-x86_64.tgz",
+			want: AssetName{Repo: "tool", Version: "1.0.0", OS: "linux", Arch: "amd64", Ext: ".tgz"},
+		},
+		{
+			name: "tool-2.0.0-rc.1-macos-aarch64",
+			want: AssetName{Repo: "tool", Version: "2.0.0-rc.1", OS: "darwin", Arch: "arm64"},
+		},
+		{
+			name: "tool-1.0.0-windows-x86-64.exe",
+			want: AssetName{Repo: "tool", Version: "1.0.0", OS: "windows", Arch: "amd64", Ext: ".exe"},
+		},
+		{
+			name: "tool-1.0.0-linux-armv7l-musl.tar.gz",
+			want: AssetName{Repo: "tool", Version: "1.0.0", OS: "linux", Arch: "armv7", Libc: "musl", Ext: ".tar.gz"},
+		},
+		{
+			name: "tool-1.0.0-linux-x86_64-glibc",
+			want: AssetName{Repo: "tool", Version: "1.0.0", OS: "linux", Arch: "amd64", Libc: "gnu"},
+		},
+		{name: "tool-1.0.0-linux-amd64.sha256", wantErr: "unknown extension"},
+		{name: "checksums.txt", wantErr: "unknown extension"},
+		{name: "tool-linux-amd64", wantErr: "want {repo}-{version}-{os}-{arch}"},
+		{name: "my-tool-linux-amd64", wantErr: "missing version"},
+		{name: "tool-1.0.0-beos-amd64", wantErr: "unknown operating system"},
+		{name: "tool-1.0.0-linux-sparc", wantErr: "unknown architecture"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAssetName(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseAssetName() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAssetName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAssetName() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAssetName(t *testing.T) {
+	for _, name := range []string{
+		"nomos-provider-consul-1.2.0-linux-amd64",
+		"nomos-provider-consul-1.2.0-linux-amd64.tar.gz",
+		"nomos-provider-consul-1.2.0-windows-arm64.exe",
+		"nomos-provider-consul-1.2.0-windows-arm64.zip",
+		"nomos-provider-consul-1.2.0-linux-armv7-musl.tar.gz",
+		"nomos-provider-consul-1.2.0-linux-riscv64",
+	} {
+		if err := ValidateAssetName(name); err != nil {
+			t.Errorf("ValidateAssetName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	tests := map[string]string{
+		"nomos-provider-consul-1.2.0-linux-x86_64":       `want "nomos-provider-consul-1.2.0-linux-amd64"`,
+		"nomos-provider-consul-v1.2.0-linux-amd64":       `want "nomos-provider-consul-1.2.0-linux-amd64"`,
+		"nomos-provider-consul-1.2.0-Darwin-arm64.ZIP":   `want "nomos-provider-consul-1.2.0-darwin-arm64.zip"`,
+		"nomos-provider-consul-1.2.0-linux-amd64.exe":    "only used for windows",
+		"nomos-provider-consul-1.2.0-windows-amd64":      "end in .exe",
+		"nomos-provider-consul-1.2.0-linux-amd64.sha256": "unknown extension",
+		"nomos-provider-consul-1.2.0-darwin-arm64-musl":  "only used for linux",
+	}
+	for name, want := range tests {
+		if err := ValidateAssetName(name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateAssetName(%q) = %v, want error containing %s", name, err, want)
+		}
+	}
+}
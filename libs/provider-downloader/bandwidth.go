@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bandwidth limits the download rate and the total bytes downloaded by
+// every Client sharing it, so one Bandwidth can cap a whole build that
+// creates a client per provider. It is safe for concurrent use.
+type Bandwidth struct {
+	rate   int64
+	budget int64
+	used   atomic.Int64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewBandwidth returns a Bandwidth allowing bytesPerSecond across all
+// downloads and budget bytes in total. Zero disables either limit.
+func NewBandwidth(bytesPerSecond, budget int64) *Bandwidth {
+	return &Bandwidth{rate: bytesPerSecond, budget: budget}
+}
+
+// Used returns the number of bytes downloaded so far.
+func (b *Bandwidth) Used() int64 {
+	return b.used.Load()
+}
+
+// check returns a BudgetExceededError if downloading n more bytes would
+// exceed the budget, so a download whose size is known fails before it
+// starts. A nil Bandwidth allows everything.
+func (b *Bandwidth) check(n int64) error {
+	if b == nil || b.budget <= 0 {
+		return nil
+	}
+	if used := b.used.Load(); used+n > b.budget {
+		return &BudgetExceededError{Budget: b.budget, Used: used, Requested: n}
+	}
+	return nil
+}
+
+// take records n downloaded bytes, returning a BudgetExceededError once
+// the budget is exceeded, and waits until the rate limit allows them.
+func (b *Bandwidth) take(ctx context.Context, n int) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	used := b.used.Add(int64(n))
+	if b.budget > 0 && used > b.budget {
+		return &BudgetExceededError{Budget: b.budget, Used: used - int64(n), Requested: int64(n)}
+	}
+	if b.rate <= 0 {
+		return nil
+	}
+
+	// Reserve the time the bytes take at the limit, after earlier
+	// reservations, and wait for it to pass
+	b.mu.Lock()
+	now := time.Now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	b.next = b.next.Add(time.Duration(int64(n) * int64(time.Second) / b.rate))
+	wait := b.next.Sub(now)
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestBandwidth_BudgetFailsFast verifies that a download whose size exceeds
+// the remaining budget fails before its body is read.
+func TestBandwidth_BudgetFailsFast(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	bw := NewBandwidth(0, 1500)
+	client := NewClient(&ClientOptions{HTTPClient: server.Client(), Bandwidth: bw})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64"}
+
+	if _, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir()); err != nil {
+		t.Fatalf("first download: %v", err)
+	}
+	_, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir())
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second download error = %v, want BudgetExceededError", err)
+	}
+	if budgetErr.Used != 1024 || budgetErr.Requested != 1024 {
+		t.Errorf("BudgetExceededError = %+v, want 1024 used and requested", budgetErr)
+	}
+	if got := bw.Used(); got != 1024 {
+		t.Errorf("Used() = %d, want 1024 after a refused download", got)
+	}
+}
+
+// TestBandwidth_BudgetWithoutContentLength verifies that a streamed
+// download of unknown size stops once it exceeds the budget.
+func TestBandwidth_BudgetWithoutContentLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for i := 0; i < 8; i++ {
+			_, _ = w.Write(bytes.Repeat([]byte("x"), 512))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{HTTPClient: server.Client(), Bandwidth: NewBandwidth(0, 1000)})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64"}
+	_, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir())
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("error = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+// TestBandwidth_RateLimit verifies that downloads are paced to the rate.
+func TestBandwidth_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for i := 0; i < 4; i++ {
+			_, _ = w.Write(bytes.Repeat([]byte("x"), 1000))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{HTTPClient: server.Client(), Bandwidth: NewBandwidth(10000, 0)})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64"}
+	start := time.Now()
+	if _, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndInstall() error = %v", err)
+	}
+	// 4000 bytes at 10000 bytes/s take 400ms
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+		t.Errorf("download took %v, want it paced to at least 350ms", elapsed)
+	}
+}
@@ -4,20 +4,35 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Client handles resolving, downloading, and installing provider binaries
-// from GitHub Releases.
+// from GitHub Releases. A Client is safe for concurrent use by multiple
+// goroutines, which share its HTTP connections, per-host request limit,
+// token lookups, and Stats counters.
 type Client struct {
 	httpClient       *http.Client
 	githubToken      string
 	baseURL          string
-	retryAttempts    int
-	retryDelay       time.Duration
+	uploadURL        string
+	hostTokens       map[string]string
+	tokenSource      TokenSource
+	tokenMu          sync.Mutex
+	sourcedTokens    map[string]string // tokens from tokenSource by host
+	retry            RetryPolicy
 	logger           Logger
 	cacheDir         string
+	storeDir         string
 	progressCallback ProgressCallback
+	strictAssets     bool
+	archFallbacks    map[string][]string
+	limiter          *hostLimiter
+	bandwidth        *Bandwidth
+	stats            clientStats
 }
 
 // NewClient creates a new downloader client with the given options.
@@ -49,30 +64,38 @@ func NewClient(opts *ClientOptions) *Client {
 		}
 	}
 
-	retryAttempts := opts.RetryAttempts
-	if retryAttempts <= 0 {
-		retryAttempts = 3
+	baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
 	}
 
-	retryDelay := opts.RetryDelay
-	if retryDelay <= 0 {
-		retryDelay = 1 * time.Second
+	uploadURL := strings.TrimSuffix(opts.UploadURL, "/")
+	if uploadURL == "" {
+		uploadURL = defaultUploadURL(baseURL)
 	}
 
-	baseURL := opts.BaseURL
-	if baseURL == "" {
-		baseURL = "https://api.github.com"
+	hostTokens := make(map[string]string, len(opts.HostTokens))
+	for host, token := range opts.HostTokens {
+		hostTokens[strings.ToLower(host)] = token
 	}
 
 	return &Client{
 		httpClient:       httpClient,
 		githubToken:      opts.GitHubToken,
 		baseURL:          baseURL,
-		retryAttempts:    retryAttempts,
-		retryDelay:       retryDelay,
+		uploadURL:        uploadURL,
+		hostTokens:       hostTokens,
+		tokenSource:      opts.TokenSource,
+		sourcedTokens:    make(map[string]string),
+		retry:            retryPolicy(opts),
 		logger:           opts.Logger,
 		cacheDir:         opts.CacheDir,
+		storeDir:         opts.StoreDir,
 		progressCallback: opts.ProgressCallback,
+		strictAssets:     opts.StrictAssetNames,
+		archFallbacks:    opts.ArchFallbacks,
+		limiter:          &hostLimiter{limit: opts.MaxConnsPerHost, slots: make(map[string]chan struct{})},
+		bandwidth:        opts.Bandwidth,
 	}
 }
 
@@ -157,6 +180,87 @@ func (c *Client) DownloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 	return c.downloadAndInstall(ctx, asset, destDir)
 }
 
+// UploadURL returns the uploads API base URL of the GitHub instance the
+// client talks to.
+func (c *Client) UploadURL() string {
+	return c.uploadURL
+}
+
+// EnterpriseURLs returns the API and uploads base URLs of the GitHub
+// Enterprise Server at serverURL, e.g. "https://ghe.example.com" gives
+// "https://ghe.example.com/api/v3" and "https://ghe.example.com/api/uploads".
+// A serverURL that already ends in either API path is accepted.
+//
+// Example:
+//
+//	baseURL, uploadURL, err := downloader.EnterpriseURLs("https://ghe.example.com")
+//	client := downloader.NewClient(&downloader.ClientOptions{
+//		BaseURL:   baseURL,
+//		UploadURL: uploadURL,
+//	})
+func EnterpriseURLs(serverURL string) (baseURL, uploadURL string, err error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", fmt.Errorf("invalid GitHub Enterprise URL %q: must be an http or https URL with a host", serverURL)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	path = strings.TrimSuffix(path, "/api/v3")
+	path = strings.TrimSuffix(path, "/api/uploads")
+	server := u.Scheme + "://" + u.Host + path
+	return server + "/api/v3", server + "/api/uploads", nil
+}
+
+// defaultUploadURL derives the uploads API base URL from the API base URL.
+func defaultUploadURL(baseURL string) string {
+	if baseURL == "https://api.github.com" {
+		return "https://uploads.github.com"
+	}
+	if server, ok := strings.CutSuffix(baseURL, "/api/v3"); ok {
+		return server + "/api/uploads"
+	}
+	return baseURL
+}
+
+// authorize adds the token for the request's host, if any, to req. The
+// token is taken, in order, from HostTokens, GitHubToken, and TokenSource.
+func (c *Client) authorize(req *http.Request) error {
+	token, ok := c.hostTokens[strings.ToLower(req.URL.Host)]
+	if !ok {
+		token, ok = c.hostTokens[strings.ToLower(req.URL.Hostname())]
+	}
+	if !ok {
+		token = c.githubToken
+	}
+	if token == "" && c.tokenSource != nil {
+		var err error
+		token, err = c.sourcedToken(req)
+		if err != nil {
+			return err
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// sourcedToken returns the token of tokenSource for the request's host,
+// asking the source only once per host.
+func (c *Client) sourcedToken(req *http.Request) (string, error) {
+	host := strings.ToLower(req.URL.Host)
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if token, ok := c.sourcedTokens[host]; ok {
+		return token, nil
+	}
+	token, err := c.tokenSource.Token(req.Context(), host)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub token for %s: %w", host, err)
+	}
+	c.sourcedTokens[host] = token
+	return token, nil
+}
+
 // debugf logs a debug message if a logger is configured.
 func (c *Client) debugf(format string, args ...interface{}) {
 	if c.logger != nil {
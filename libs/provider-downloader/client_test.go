@@ -2,7 +2,11 @@ package downloader
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,12 +27,12 @@ func TestNewClient_WithNilOptions(t *testing.T) {
 		t.Errorf("expected default baseURL, got %s", client.baseURL)
 	}
 
-	if client.retryAttempts != 3 {
-		t.Errorf("expected default retryAttempts 3, got %d", client.retryAttempts)
+	if client.retry.MaxAttempts != 4 {
+		t.Errorf("expected default 4 attempts (3 retries), got %d", client.retry.MaxAttempts)
 	}
 
-	if client.retryDelay != 1*time.Second {
-		t.Errorf("expected default retryDelay 1s, got %v", client.retryDelay)
+	if client.retry.BaseDelay != 1*time.Second {
+		t.Errorf("expected default retry delay 1s, got %v", client.retry.BaseDelay)
 	}
 }
 
@@ -51,12 +55,12 @@ func TestNewClient_WithCustomOptions(t *testing.T) {
 		t.Errorf("expected githubToken test-token, got %s", client.githubToken)
 	}
 
-	if client.retryAttempts != 5 {
-		t.Errorf("expected retryAttempts 5, got %d", client.retryAttempts)
+	if client.retry.MaxAttempts != 6 {
+		t.Errorf("expected 6 attempts (5 retries), got %d", client.retry.MaxAttempts)
 	}
 
-	if client.retryDelay != 2*time.Second {
-		t.Errorf("expected retryDelay 2s, got %v", client.retryDelay)
+	if client.retry.BaseDelay != 2*time.Second {
+		t.Errorf("expected retry delay 2s, got %v", client.retry.BaseDelay)
 	}
 
 	if client.baseURL != "https://custom.github.com" {
@@ -218,3 +222,116 @@ func TestDefaultClientOptions(t *testing.T) {
 		t.Errorf("expected BaseURL https://api.github.com, got %s", opts.BaseURL)
 	}
 }
+
+// TestEnterpriseURLs tests deriving API and uploads URLs from a GitHub
+// Enterprise Server URL.
+func TestEnterpriseURLs(t *testing.T) {
+	tests := []struct {
+		serverURL  string
+		wantBase   string
+		wantUpload string
+		wantErr    bool
+	}{
+		{serverURL: "https://ghe.example.com", wantBase: "https://ghe.example.com/api/v3", wantUpload: "https://ghe.example.com/api/uploads"},
+		{serverURL: "https://ghe.example.com/", wantBase: "https://ghe.example.com/api/v3", wantUpload: "https://ghe.example.com/api/uploads"},
+		{serverURL: "https://ghe.example.com/api/v3/", wantBase: "https://ghe.example.com/api/v3", wantUpload: "https://ghe.example.com/api/uploads"},
+		{serverURL: "http://ghe.internal:8080/github", wantBase: "http://ghe.internal:8080/github/api/v3", wantUpload: "http://ghe.internal:8080/github/api/uploads"},
+		{serverURL: "ghe.example.com", wantErr: true},
+		{serverURL: "ftp://ghe.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverURL, func(t *testing.T) {
+			base, upload, err := EnterpriseURLs(tt.serverURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s, %s", base, upload)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if base != tt.wantBase || upload != tt.wantUpload {
+				t.Errorf("expected %s, %s; got %s, %s", tt.wantBase, tt.wantUpload, base, upload)
+			}
+		})
+	}
+}
+
+// TestNewClient_UploadURL tests that the uploads URL defaults from BaseURL.
+func TestNewClient_UploadURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		uploadURL string
+		want      string
+	}{
+		{name: "github.com", want: "https://uploads.github.com"},
+		{name: "enterprise", baseURL: "https://ghe.example.com/api/v3/", want: "https://ghe.example.com/api/uploads"},
+		{name: "explicit", baseURL: "https://ghe.example.com/api/v3", uploadURL: "https://uploads.ghe.example.com/", want: "https://uploads.ghe.example.com"},
+		{name: "other", baseURL: "http://127.0.0.1:8080", want: "http://127.0.0.1:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&ClientOptions{BaseURL: tt.baseURL, UploadURL: tt.uploadURL})
+			if got := client.UploadURL(); got != tt.want {
+				t.Errorf("expected UploadURL %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestResolveAsset_EnterpriseHostTokens tests that requests to a GitHub
+// Enterprise Server use its API path and the token configured for its host.
+func TestResolveAsset_EnterpriseHostTokens(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		release := mockRelease{
+			TagName: "v1.0.0",
+			Assets:  []mockAsset{{Name: "test-provider-linux-amd64", BrowserDownloadURL: "http://" + r.Host + "/owner/test-provider/releases/download/v1.0.0/test-provider-linux-amd64"}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(release) //nolint:errcheck // test helper, error not critical
+	}))
+	defer server.Close()
+
+	baseURL, uploadURL, err := EnterpriseURLs(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	spec := &ProviderSpec{Owner: "owner", Repo: "test-provider", Version: "v1.0.0", OS: "linux", Arch: "amd64"}
+
+	tests := []struct {
+		name       string
+		hostTokens map[string]string
+		wantAuth   string
+	}{
+		{name: "host with port", hostTokens: map[string]string{host: "ghe-token"}, wantAuth: "Bearer ghe-token"},
+		{name: "host name", hostTokens: map[string]string{"127.0.0.1": "ghe-token"}, wantAuth: "Bearer ghe-token"},
+		{name: "unlisted host", hostTokens: map[string]string{"github.com": "other-token"}, wantAuth: "Bearer public-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&ClientOptions{
+				BaseURL:     baseURL,
+				UploadURL:   uploadURL,
+				GitHubToken: "public-token",
+				HostTokens:  tt.hostTokens,
+			})
+			if _, err := client.ResolveAsset(context.Background(), spec); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if gotPath != "/api/v3/repos/owner/test-provider/releases/tags/v1.0.0" {
+				t.Errorf("expected enterprise API path, got %s", gotPath)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("expected Authorization %q, got %q", tt.wantAuth, gotAuth)
+			}
+		})
+	}
+}
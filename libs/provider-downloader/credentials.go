@@ -0,0 +1,300 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TokenSource looks up the GitHub token to send to a host. Token returns ""
+// and a nil error when the source has no token for host, so the next source
+// of a chain is consulted.
+type TokenSource interface {
+	Token(ctx context.Context, host string) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context, host string) (string, error)
+
+// Token implements TokenSource.
+func (f TokenSourceFunc) Token(ctx context.Context, host string) (string, error) {
+	return f(ctx, host)
+}
+
+// DefaultTokenSource returns the token sources developers commonly have
+// set up, consulted in this order:
+//
+//  1. the GITHUB_TOKEN, then GH_TOKEN environment variables
+//  2. helper, a git credential helper command, if not empty
+//  3. the GitHub CLI's configuration (gh auth login)
+//  4. the user's .netrc file
+//
+// The first source with a token for the host wins.
+func DefaultTokenSource(helper string) TokenSource {
+	sources := []TokenSource{EnvTokenSource()}
+	if helper != "" {
+		sources = append(sources, CredentialHelperTokenSource(helper))
+	}
+	sources = append(sources, GHConfigTokenSource(""), NetrcTokenSource(""))
+	return ChainTokenSources(sources...)
+}
+
+// ChainTokenSources returns a TokenSource that consults sources in order
+// and returns the first token found. An error from a source stops the
+// chain, since a configured source that fails should not be silently
+// skipped.
+func ChainTokenSources(sources ...TokenSource) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context, host string) (string, error) {
+		for _, source := range sources {
+			token, err := source.Token(ctx, host)
+			if err != nil || token != "" {
+				return token, err
+			}
+		}
+		return "", nil
+	})
+}
+
+// EnvTokenSource returns a TokenSource reading the GITHUB_TOKEN, then
+// GH_TOKEN environment variables, for every host.
+func EnvTokenSource() TokenSource {
+	return TokenSourceFunc(func(context.Context, string) (string, error) {
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			return token, nil
+		}
+		return os.Getenv("GH_TOKEN"), nil
+	})
+}
+
+// CredentialHelperTokenSource returns a TokenSource that asks a git
+// credential helper for the host's password. command is split on spaces and
+// run with the "get" action, receiving the request on stdin in git's
+// credential format, e.g. "gh auth git-credential" or
+// "git credential-osxkeychain". A helper that answers without a password
+// has no token for the host.
+func CredentialHelperTokenSource(command string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context, host string) (string, error) {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return "", nil
+		}
+		//nolint:gosec // G204: The helper command is configured by the user
+		cmd := exec.CommandContext(ctx, args[0], append(args[1:], "get")...)
+		cmd.Stdin = strings.NewReader("protocol=https\nhost=" + webHost(host) + "\n\n")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("credential helper %q failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok {
+				return password, nil
+			}
+		}
+		return "", nil
+	})
+}
+
+// GHConfigTokenSource returns a TokenSource reading the oauth_token entries
+// of the GitHub CLI's hosts.yml in configDir. An empty configDir uses the
+// GitHub CLI's own location: GH_CONFIG_DIR, XDG_CONFIG_HOME/gh, or the
+// platform default. Tokens the GitHub CLI keeps in the system keyring are
+// not visible here; use CredentialHelperTokenSource("gh auth git-credential")
+// for those.
+func GHConfigTokenSource(configDir string) TokenSource {
+	return TokenSourceFunc(func(_ context.Context, host string) (string, error) {
+		dir := configDir
+		if dir == "" {
+			dir = ghConfigDir()
+		}
+		if dir == "" {
+			return "", nil
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "hosts.yml")) //nolint:gosec // G304: Path is the GitHub CLI's configuration
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read GitHub CLI configuration: %w", err)
+		}
+		return parseGHHosts(data)[webHost(host)], nil
+	})
+}
+
+// ghConfigDir returns the GitHub CLI's configuration directory.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
+
+// parseGHHosts returns the oauth_token of each host in a GitHub CLI
+// hosts.yml. The file is a map of host names to settings; only unindented
+// host keys and their oauth_token values are read, so no YAML library is
+// needed.
+func parseGHHosts(data []byte) map[string]string {
+	tokens := map[string]string{}
+	var host string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		if line == trimmed {
+			host = strings.ToLower(unquoteYAML(key))
+			continue
+		}
+		if host != "" && unquoteYAML(key) == "oauth_token" {
+			if token := unquoteYAML(strings.TrimSpace(value)); token != "" {
+				tokens[host] = token
+			}
+		}
+	}
+	return tokens
+}
+
+// unquoteYAML strips the quotes of a quoted YAML scalar.
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// NetrcTokenSource returns a TokenSource reading the password of the
+// host's machine entry in the netrc file at path, falling back to the
+// default entry. An empty path uses NETRC or ~/.netrc (~/_netrc on Windows).
+// For github.com, entries for api.github.com and github.com both match.
+func NetrcTokenSource(path string) TokenSource {
+	return TokenSourceFunc(func(_ context.Context, host string) (string, error) {
+		file := path
+		if file == "" {
+			file = netrcPath()
+		}
+		if file == "" {
+			return "", nil
+		}
+		data, err := os.ReadFile(file) //nolint:gosec // G304: Path is the user's netrc file
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read netrc: %w", err)
+		}
+
+		machines := parseNetrc(data)
+		candidates := []string{strings.ToLower(host)}
+		if web := webHost(host); web == "github.com" {
+			candidates = append(candidates, "api.github.com", "github.com")
+		} else {
+			candidates = append(candidates, web)
+		}
+		for _, candidate := range candidates {
+			if password, ok := machines[candidate]; ok {
+				return password, nil
+			}
+		}
+		return machines[""], nil
+	})
+}
+
+// netrcPath returns the location of the user's netrc file.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// parseNetrc returns the password of each machine in a netrc file, with
+// the default entry under "". Macro definitions are skipped.
+func parseNetrc(data []byte) map[string]string {
+	passwords := map[string]string{}
+	var machine string
+	inMachine := false
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			switch fields[j] {
+			case "machine":
+				if j+1 < len(fields) {
+					j++
+					machine, inMachine = strings.ToLower(fields[j]), true
+				}
+			case "default":
+				machine, inMachine = "", true
+			case "password":
+				if j+1 < len(fields) {
+					j++
+					if _, seen := passwords[machine]; inMachine && !seen {
+						passwords[machine] = fields[j]
+					}
+				}
+			case "login", "account":
+				j++
+			case "macdef":
+				// A macro runs until the next empty line
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	return passwords
+}
+
+// webHost maps GitHub's API, uploads, and download hosts to github.com,
+// the host credential stores key github.com tokens by. Other hosts, such as
+// GitHub Enterprise Server instances, are returned without port.
+func webHost(host string) string {
+	host = strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	switch host {
+	case "api.github.com", "uploads.github.com", "objects.githubusercontent.com":
+		return "github.com"
+	}
+	return host
+}
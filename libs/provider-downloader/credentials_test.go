@@ -0,0 +1,200 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// staticTokens is a TokenSource serving fixed tokens by host and counting
+// lookups.
+type staticTokens struct {
+	tokens  map[string]string
+	lookups int
+}
+
+func (s *staticTokens) Token(_ context.Context, host string) (string, error) {
+	s.lookups++
+	return s.tokens[host], nil
+}
+
+// TestChainTokenSources tests that the first source with a token wins and
+// that errors stop the chain.
+func TestChainTokenSources(t *testing.T) {
+	first := &staticTokens{tokens: map[string]string{"ghe.example.com": "ghe-token"}}
+	second := &staticTokens{tokens: map[string]string{"ghe.example.com": "other", "api.github.com": "gh-token"}}
+	chain := ChainTokenSources(first, second)
+
+	for host, want := range map[string]string{"ghe.example.com": "ghe-token", "api.github.com": "gh-token", "unknown": ""} {
+		if got, err := chain.Token(context.Background(), host); err != nil || got != want {
+			t.Errorf("Token(%s) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+
+	failing := TokenSourceFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("helper broke")
+	})
+	if _, err := ChainTokenSources(failing, second).Token(context.Background(), "api.github.com"); err == nil {
+		t.Error("expected error from failing source")
+	}
+}
+
+// TestEnvTokenSource tests GITHUB_TOKEN precedence over GH_TOKEN.
+func TestEnvTokenSource(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh-token")
+	if got, _ := EnvTokenSource().Token(context.Background(), "api.github.com"); got != "gh-token" {
+		t.Errorf("expected GH_TOKEN, got %q", got)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "github-token")
+	if got, _ := EnvTokenSource().Token(context.Background(), "api.github.com"); got != "github-token" {
+		t.Errorf("expected GITHUB_TOKEN, got %q", got)
+	}
+}
+
+// TestGHConfigTokenSource tests reading tokens from the GitHub CLI's
+// hosts.yml.
+func TestGHConfigTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	hosts := `# written by gh
+github.com:
+    user: octocat
+    oauth_token: gho_public
+    git_protocol: https
+"ghe.example.com":
+    oauth_token: 'gho_enterprise'
+keyring.example.com:
+    user: someone
+`
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := GHConfigTokenSource(dir)
+	for host, want := range map[string]string{
+		"api.github.com":      "gho_public",
+		"github.com":          "gho_public",
+		"ghe.example.com:443": "gho_enterprise",
+		"keyring.example.com": "",
+	} {
+		if got, err := source.Token(context.Background(), host); err != nil || got != want {
+			t.Errorf("Token(%s) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+
+	if got, err := GHConfigTokenSource(t.TempDir()).Token(context.Background(), "github.com"); err != nil || got != "" {
+		t.Errorf("missing hosts.yml: Token = %q, %v; want no token", got, err)
+	}
+}
+
+// TestNetrcTokenSource tests reading passwords from a netrc file.
+func TestNetrcTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	netrc := `machine github.com login octocat password ghp_public
+macdef init
+machine ignored.example.com password not-a-machine
+
+machine ghe.example.com
+  login someone
+  password ghp_enterprise
+default login anonymous password ghp_default
+`
+	if err := os.WriteFile(path, []byte(netrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NetrcTokenSource(path)
+	for host, want := range map[string]string{
+		"api.github.com":      "ghp_public",
+		"ghe.example.com":     "ghp_enterprise",
+		"ignored.example.com": "ghp_default",
+		"other.example.com":   "ghp_default",
+	} {
+		if got, err := source.Token(context.Background(), host); err != nil || got != want {
+			t.Errorf("Token(%s) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+
+	if got, err := NetrcTokenSource(filepath.Join(t.TempDir(), "missing")).Token(context.Background(), "github.com"); err != nil || got != "" {
+		t.Errorf("missing netrc: Token = %q, %v; want no token", got, err)
+	}
+}
+
+// TestCredentialHelperTokenSource tests asking a git credential helper for
+// a token.
+func TestCredentialHelperTokenSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper")
+	script := `#!/bin/sh
+[ "$1" = get ] || exit 1
+while read -r line && [ -n "$line" ]; do
+  case "$line" in host=github.com) found=1 ;; esac
+done
+[ -n "$found" ] && printf 'username=x-access-token\npassword=ghs_helper\n'
+exit 0
+`
+	if err := os.WriteFile(helper, []byte(script), 0700); err != nil { //nolint:gosec // G306: The helper must be executable
+		t.Fatal(err)
+	}
+
+	source := CredentialHelperTokenSource(helper)
+	if got, err := source.Token(context.Background(), "api.github.com"); err != nil || got != "ghs_helper" {
+		t.Errorf("Token(api.github.com) = %q, %v; want ghs_helper", got, err)
+	}
+	if got, err := source.Token(context.Background(), "ghe.example.com"); err != nil || got != "" {
+		t.Errorf("Token(ghe.example.com) = %q, %v; want no token", got, err)
+	}
+
+	_, err := CredentialHelperTokenSource(filepath.Join(dir, "missing")).Token(context.Background(), "github.com")
+	if err == nil || !strings.Contains(err.Error(), "credential helper") {
+		t.Errorf("expected credential helper error, got %v", err)
+	}
+}
+
+// TestClient_TokenSource tests that the client asks its token source once
+// per host, and only when no explicit token applies.
+func TestClient_TokenSource(t *testing.T) {
+	var gotAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	spec := &ProviderSpec{Owner: "owner", Repo: "repo", Version: "1.0.0", OS: "linux", Arch: "amd64"}
+	source := &staticTokens{tokens: map[string]string{host: "sourced-token"}}
+	client := NewClient(&ClientOptions{BaseURL: server.URL, TokenSource: source})
+	_, _ = client.ResolveAsset(context.Background(), spec)
+	_, _ = client.ResolveAsset(context.Background(), spec)
+
+	if len(gotAuth) == 0 {
+		t.Fatal("expected requests")
+	}
+	for _, auth := range gotAuth {
+		if auth != "Bearer sourced-token" {
+			t.Errorf("expected sourced token, got %q", auth)
+		}
+	}
+	if source.lookups != 1 {
+		t.Errorf("expected 1 token lookup, got %d", source.lookups)
+	}
+
+	gotAuth = nil
+	client = NewClient(&ClientOptions{BaseURL: server.URL, GitHubToken: "explicit-token", TokenSource: source})
+	_, _ = client.ResolveAsset(context.Background(), spec)
+	if len(gotAuth) == 0 || gotAuth[0] != "Bearer explicit-token" {
+		t.Errorf("expected GitHubToken to take precedence, got %v", gotAuth)
+	}
+}
@@ -19,13 +19,14 @@ import (
 // and installs it atomically to the destination directory.
 //
 // The process:
-//  1. Checks cache if caching is enabled
+//  1. Installs from the store if it holds the asset's digest
 //  2. Creates a temporary file
 //  3. Streams the HTTP response body to the temp file while computing SHA256
 //  4. Verifies checksum if provided in AssetInfo
 //  5. Extracts archive if needed
 //  6. Sets executable permissions (0755)
-//  7. Atomically renames to final destination
+//  7. Atomically renames to final destination, or adds the binary to the
+//     store and links it from there
 //  8. Saves to cache if caching is enabled
 //
 // Returns InstallResult with path, checksum, and size on success.
@@ -38,6 +39,14 @@ func (c *Client) downloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 		return nil, fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
+	// A stored binary installs without a download
+	if c.storeDir != "" && asset.Checksum != "" {
+		if result, ok := c.installFromStore(asset, destDir); ok {
+			c.stats.storeHits.Add(1)
+			return result, nil
+		}
+	}
+
 	// Create temporary directory for download
 	tmpDir := filepath.Join(filepath.Dir(destDir), ".nomos-tmp")
 	//nolint:gosec // G301: Standard directory permissions (0755) are appropriate for temporary directories
@@ -76,6 +85,7 @@ func (c *Client) downloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 	}
 
 	// If the asset is an archive (tar.gz, zip), extract it
+	assetChecksum := actualChecksum
 	if needsExtraction(asset.Name) {
 		// Create temporary extraction directory
 		extractDir, err := os.MkdirTemp(tmpDir, "extract-*")
@@ -122,16 +132,26 @@ func (c *Client) downloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 		size = fileInfo.Size()
 	}
 
+	// A pinned binary checksum is verified before anything is installed
+	if asset.BinaryChecksum != "" && asset.BinaryChecksum != actualChecksum {
+		return nil, &ChecksumMismatchError{
+			Expected: asset.BinaryChecksum,
+			Actual:   actualChecksum,
+		}
+	}
+
 	// Check cache using the binary checksum (after extraction if archive)
 	// For non-archives, actualChecksum is the downloaded file's checksum
 	// For archives, actualChecksum is the extracted binary's checksum
 	if c.cacheDir != "" {
 		cachedPath := c.getCachePath(actualChecksum)
 		if _, err := os.Stat(cachedPath); err == nil {
+			c.stats.cacheHits.Add(1)
 			c.debugf("Cache hit for binary checksum: %s", actualChecksum)
 			// Copy from cache to destination
 			return c.installFromCache(cachedPath, destDir, actualChecksum)
 		}
+		c.stats.cacheMisses.Add(1)
 		c.debugf("Cache miss for binary checksum: %s", actualChecksum)
 	}
 
@@ -141,9 +161,18 @@ func (c *Client) downloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 		return nil, fmt.Errorf("failed to set permissions: %w", err)
 	}
 
-	// Atomic rename to destination
+	// Atomic rename to destination, through the store if enabled
 	finalPath := filepath.Join(destDir, "provider")
-	if err := os.Rename(tmpPath, finalPath); err != nil {
+	link := ""
+	if c.storeDir != "" {
+		objectPath, err := c.addToStore(tmpPath, actualChecksum, assetChecksum)
+		if err != nil {
+			return nil, err
+		}
+		if link, err = linkFromStore(objectPath, finalPath); err != nil {
+			return nil, err
+		}
+	} else if err := os.Rename(tmpPath, finalPath); err != nil {
 		return nil, fmt.Errorf("failed to install provider: %w", err)
 	}
 
@@ -161,17 +190,21 @@ func (c *Client) downloadAndInstall(ctx context.Context, asset *AssetInfo, destD
 		Path:     finalPath,
 		Checksum: actualChecksum,
 		Size:     size,
+		Link:     link,
 	}, nil
 }
 
-// downloadWithRetry downloads content from URL to file with retry logic.
-// Implements exponential backoff with jitter for transient failures.
-// Returns checksum, size, and error.
+// downloadWithRetry downloads content from URL to file, retrying transient
+// failures with exponential backoff and jitter as the client's RetryPolicy
+// directs. Returns checksum, size, and error; a RetriesExhaustedError once
+// the last attempt fails.
 func (c *Client) downloadWithRetry(ctx context.Context, url string, f *os.File) (checksum string, size int64, err error) {
 	var lastErr error
 
-	for attempt := 0; attempt <= c.retryAttempts; attempt++ {
-		if attempt > 0 {
+	for attempt := 1; attempt <= c.retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			c.stats.retries.Add(1)
+
 			// Reset file for retry
 			if _, err := f.Seek(0, 0); err != nil {
 				return "", 0, fmt.Errorf("failed to reset file for retry: %w", err)
@@ -180,19 +213,17 @@ func (c *Client) downloadWithRetry(ctx context.Context, url string, f *os.File)
 				return "", 0, fmt.Errorf("failed to truncate file for retry: %w", err)
 			}
 
-			// Exponential backoff with jitter
-			backoff := c.retryDelay * (1 << (attempt - 1))
-			jitter := backoff / 10 // 10% jitter
-
+			timer := time.NewTimer(c.retry.backoff(attempt - 1))
 			select {
 			case <-ctx.Done():
+				timer.Stop()
 				return "", 0, ctx.Err()
-			case <-time.After(backoff + jitter):
+			case <-timer.C:
 			}
 		}
 
 		// Attempt download
-		chk, sz, err := c.attemptDownload(ctx, url, f)
+		chk, sz, err := c.attemptDownloadWithTimeout(ctx, url, f)
 		if err == nil {
 			return chk, sz, nil
 		}
@@ -200,12 +231,23 @@ func (c *Client) downloadWithRetry(ctx context.Context, url string, f *os.File)
 		lastErr = err
 
 		// Check if error is retryable
-		if !isRetryable(err) {
+		if !c.retry.retryable(ctx, err) {
 			return "", 0, lastErr
 		}
 	}
 
-	return "", 0, fmt.Errorf("download failed after %d retries: %w", c.retryAttempts, lastErr)
+	return "", 0, &RetriesExhaustedError{URL: url, Attempts: c.retry.MaxAttempts, Err: lastErr}
+}
+
+// attemptDownloadWithTimeout runs one download attempt bounded by the retry
+// policy's AttemptTimeout, if any.
+func (c *Client) attemptDownloadWithTimeout(ctx context.Context, url string, w io.Writer) (checksum string, size int64, err error) {
+	if c.retry.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.retry.AttemptTimeout)
+		defer cancel()
+	}
+	return c.attemptDownload(ctx, url, w)
 }
 
 // attemptDownload performs a single download attempt.
@@ -216,13 +258,12 @@ func (c *Client) attemptDownload(ctx context.Context, url string, w io.Writer) (
 		return "", 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add authentication if token is available
-	if c.githubToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.githubToken)
+	if err := c.authorize(req); err != nil {
+		return "", 0, err
 	}
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to download: %w", err)
 	}
@@ -230,12 +271,19 @@ func (c *Client) attemptDownload(ctx context.Context, url string, w io.Writer) (
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("download failed with status %d: %s", resp.StatusCode, resp.Status)
+		return "", 0, &httpStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	// Get total size from Content-Length header (0 if not available)
 	totalSize := resp.ContentLength
 
+	// Fail before streaming a download the budget cannot hold
+	if totalSize > 0 {
+		if err := c.bandwidth.check(totalSize); err != nil {
+			return "", 0, err
+		}
+	}
+
 	// Stream response body to file while computing checksum
 	hasher := sha256.New()
 	multiWriter := io.MultiWriter(w, hasher)
@@ -262,31 +310,6 @@ func (c *Client) attemptDownload(ctx context.Context, url string, w io.Writer) (
 	return "sha256:" + checksumHex, written, nil
 }
 
-// isRetryable determines if an error is retryable.
-func isRetryable(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// Network errors and incomplete reads are retryable
-	// HTTP 5xx errors are retryable, 4xx are not
-	errStr := err.Error()
-
-	// Retryable conditions:
-	// - Connection errors
-	// - Timeout errors
-	// - Incomplete reads (io.ErrUnexpectedEOF)
-	// - 5xx server errors
-	if strings.Contains(errStr, "connection") ||
-		strings.Contains(errStr, "timeout") ||
-		strings.Contains(errStr, "unexpected EOF") ||
-		strings.Contains(errStr, "status 5") {
-		return true
-	}
-
-	return false
-}
-
 // needsExtraction checks if the asset file needs to be extracted.
 func needsExtraction(filename string) bool {
 	return strings.Contains(filename, ".tar.gz") || strings.Contains(filename, ".tgz") || strings.Contains(filename, ".zip")
@@ -335,10 +358,26 @@ func (c *Client) saveToCache(providerPath, checksum string) error {
 		return fmt.Errorf("failed to read provider: %w", err)
 	}
 
-	// Write to cache
+	// Write to cache through a temporary file, so concurrent installs of the
+	// same binary never read a partly written cache entry
 	cachePath := c.getCachePath(checksum)
-	//nolint:gosec // G306: Cache files should be readable (0644) but not executable
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(c.cacheDir, ".cache-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	//nolint:gosec // G302: Cache files should be readable (0644) but not executable
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cachePath); err != nil {
 		return fmt.Errorf("failed to write to cache: %w", err)
 	}
 
@@ -27,6 +27,18 @@ var (
 
 	// ErrNotImplemented is returned for operations that are not yet implemented.
 	ErrNotImplemented = errors.New("not implemented")
+
+	// ErrRetriesExhausted is returned when a download still fails after the
+	// retry policy's last attempt.
+	ErrRetriesExhausted = errors.New("retries exhausted")
+
+	// ErrReleaseYanked is returned when the requested release has been
+	// yanked by its author and ProviderSpec.AllowYanked is not set.
+	ErrReleaseYanked = errors.New("release yanked")
+
+	// ErrBudgetExceeded is returned when a download would exceed the byte
+	// budget of ClientOptions.Bandwidth.
+	ErrBudgetExceeded = errors.New("download budget exceeded")
 )
 
 // AssetNotFoundError provides details when an asset cannot be found.
@@ -74,3 +86,61 @@ func (e *InvalidSpecError) Error() string {
 func (e *InvalidSpecError) Unwrap() error {
 	return ErrInvalidSpec
 }
+
+// ReleaseYankedError provides details when a release has been yanked.
+type ReleaseYankedError struct {
+	Owner   string
+	Repo    string
+	Version string
+	Message string
+}
+
+func (e *ReleaseYankedError) Error() string {
+	msg := fmt.Sprintf("release %s/%s@%s has been yanked", e.Owner, e.Repo, e.Version)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *ReleaseYankedError) Unwrap() error {
+	return ErrReleaseYanked
+}
+
+// BudgetExceededError reports a download stopped by the byte budget of a
+// Bandwidth: Used bytes were already downloaded and Requested more were
+// needed.
+type BudgetExceededError struct {
+	Budget    int64
+	Used      int64
+	Requested int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("download budget of %d bytes exceeded: %d bytes used, %d more needed", e.Budget, e.Used, e.Requested)
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// RetriesExhaustedError reports a download that failed on every attempt.
+// It matches ErrRetriesExhausted and unwraps to the last attempt's error.
+type RetriesExhaustedError struct {
+	URL      string
+	Attempts int
+	Err      error
+}
+
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("download of %s failed after %d attempts: %v", e.URL, e.Attempts, e.Err)
+}
+
+func (e *RetriesExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrRetriesExhausted.
+func (e *RetriesExhaustedError) Is(target error) bool {
+	return target == ErrRetriesExhausted
+}
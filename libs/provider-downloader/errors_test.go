@@ -101,6 +101,7 @@ func TestSentinelErrors_Distinct(t *testing.T) {
 		ErrRateLimitExceeded,
 		ErrNetworkFailure,
 		ErrNotImplemented,
+		ErrRetriesExhausted,
 	}
 
 	for i, err1 := range sentinels {
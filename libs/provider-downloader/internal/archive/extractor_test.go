@@ -87,6 +87,50 @@ func TestZipExtractor_Extract(t *testing.T) {
 	}
 }
 
+// TestExtract_SingleFile tests that the only file of an archive is taken as
+// the binary whatever its name, as in nomos CLI release archives.
+func TestExtract_SingleFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		create    func(string, map[string][]byte) error
+		extractor Extractor
+	}{
+		{name: "tar.gz", create: createTarGzFile, extractor: &TarGzExtractor{}},
+		{name: "zip", create: createZipFile, extractor: &ZipExtractor{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, "nomos."+tt.name)
+			if err := tt.create(archivePath, map[string][]byte{
+				"nomos-v1.2.0-linux-amd64": []byte("binary"),
+			}); err != nil {
+				t.Fatalf("failed to create test archive: %v", err)
+			}
+
+			extractedPath, err := tt.extractor.Extract(archivePath, tmpDir)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if want := filepath.Join(tmpDir, "nomos-v1.2.0-linux-amd64"); extractedPath != want {
+				t.Errorf("expected path %s, got %s", want, extractedPath)
+			}
+
+			// With a second file, the archive is ambiguous.
+			if err := tt.create(archivePath, map[string][]byte{
+				"nomos-v1.2.0-linux-amd64": []byte("binary"),
+				"README.md":                []byte("docs"),
+			}); err != nil {
+				t.Fatalf("failed to create test archive: %v", err)
+			}
+			if _, err := tt.extractor.Extract(archivePath, t.TempDir()); err == nil {
+				t.Error("expected error for an archive with several files, got nil")
+			}
+		})
+	}
+}
+
 // TestGetExtractor tests the extractor factory function.
 func TestGetExtractor(t *testing.T) {
 	tests := []struct {
@@ -14,7 +14,8 @@ import (
 type TarGzExtractor struct{}
 
 // Extract extracts a provider binary from a tar.gz archive.
-// It searches for a file named "provider" or matching "nomos-provider-*" pattern.
+// It searches for a file named "provider" or matching "nomos-provider-*" pattern,
+// and otherwise takes the only file of an archive holding a single file.
 // Files are flattened to the destination directory (directory structure is not preserved).
 func (e *TarGzExtractor) Extract(archivePath, destDir string) (string, error) {
 	// Open the archive file
@@ -38,6 +39,7 @@ func (e *TarGzExtractor) Extract(archivePath, destDir string) (string, error) {
 	// Track extracted files and find the provider binary
 	var providerPath string
 	var fallbackPath string // For "nomos-provider-*" names
+	var files []string
 
 	for {
 		header, err := tr.Next()
@@ -72,6 +74,8 @@ func (e *TarGzExtractor) Extract(archivePath, destDir string) (string, error) {
 			return "", fmt.Errorf("failed to close extracted file %s: %w", target, err)
 		}
 
+		files = append(files, target)
+
 		// Check if this is a provider binary
 		// Priority: exact "provider" match > "nomos-provider-*" match
 		if baseName == "provider" {
@@ -88,6 +92,9 @@ func (e *TarGzExtractor) Extract(archivePath, destDir string) (string, error) {
 	if fallbackPath != "" {
 		return fallbackPath, nil
 	}
+	if len(files) == 1 {
+		return files[0], nil
+	}
 
 	return "", fmt.Errorf("provider binary not found in archive")
 }
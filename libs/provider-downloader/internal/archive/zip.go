@@ -13,7 +13,8 @@ import (
 type ZipExtractor struct{}
 
 // Extract extracts a provider binary from a zip archive.
-// It searches for a file named "provider" or matching "nomos-provider-*" pattern.
+// It searches for a file named "provider" or matching "nomos-provider-*" pattern,
+// and otherwise takes the only file of an archive holding a single file.
 // Files are flattened to the destination directory (directory structure is not preserved).
 func (e *ZipExtractor) Extract(archivePath, destDir string) (string, error) {
 	// Open the zip archive
@@ -27,6 +28,7 @@ func (e *ZipExtractor) Extract(archivePath, destDir string) (string, error) {
 	// Track extracted files and find the provider binary
 	var providerPath string
 	var fallbackPath string // For "nomos-provider-*" names
+	var files []string
 
 	for _, file := range reader.File {
 		// Skip directories
@@ -66,6 +68,8 @@ func (e *ZipExtractor) Extract(archivePath, destDir string) (string, error) {
 			return "", fmt.Errorf("failed to close extracted file %s: %w", target, err)
 		}
 
+		files = append(files, target)
+
 		// Check if this is a provider binary
 		// Priority: exact "provider" match > "nomos-provider-*" match
 		if baseName == "provider" {
@@ -82,6 +86,9 @@ func (e *ZipExtractor) Extract(archivePath, destDir string) (string, error) {
 	if fallbackPath != "" {
 		return fallbackPath, nil
 	}
+	if len(files) == 1 {
+		return files[0], nil
+	}
 
 	return "", fmt.Errorf("provider binary not found in zip archive")
 }
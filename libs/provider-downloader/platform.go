@@ -0,0 +1,135 @@
+package downloader
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// C libraries a Linux asset may be linked against, as used in
+// ProviderSpec.Libc and AssetName.Libc.
+const (
+	// LibcMusl is musl, used by Alpine Linux.
+	LibcMusl = "musl"
+
+	// LibcGNU is the GNU C library (glibc), used by most distributions.
+	LibcGNU = "gnu"
+)
+
+// libcAliases maps the libc names release assets use to LibcMusl and
+// LibcGNU.
+var libcAliases = map[string]string{
+	"musl":  LibcMusl,
+	"gnu":   LibcGNU,
+	"glibc": LibcGNU,
+}
+
+// Paths the detection reads, replaced in tests.
+var (
+	muslLoaderGlob = "/lib/ld-musl-*.so.1"
+	cpuInfoPath    = "/proc/cpuinfo"
+)
+
+// DetectLibc returns the C library of the running system: LibcMusl when
+// the musl dynamic loader is installed, LibcGNU otherwise. It returns ""
+// on operating systems other than Linux.
+func DetectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	if matches, _ := filepath.Glob(muslLoaderGlob); len(matches) > 0 {
+		return LibcMusl
+	}
+	return LibcGNU
+}
+
+// DetectARM returns the ARM variant of the running system, "armv7" or
+// "armv6", from the CPU architecture the kernel reports, falling back to
+// the GOARM this binary was built with. It returns "arm" when neither is
+// known, and runtime.GOARCH on other architectures.
+func DetectARM() string {
+	if runtime.GOARCH != "arm" {
+		return runtime.GOARCH
+	}
+	if f, err := os.Open(cpuInfoPath); err == nil {
+		defer func() { _ = f.Close() }()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), ":")
+			if ok && strings.TrimSpace(key) == "CPU architecture" {
+				if v := armVariant(strings.TrimSpace(value)); v != "" {
+					return v
+				}
+			}
+		}
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "GOARM" {
+				if v := armVariant(strings.SplitN(s.Value, ",", 2)[0]); v != "" {
+					return v
+				}
+			}
+		}
+	}
+	return "arm"
+}
+
+// armVariant maps an ARM architecture version to the asset arch it runs:
+// ARMv8 CPUs in 32-bit mode run armv7 binaries.
+func armVariant(version string) string {
+	switch version {
+	case "6":
+		return "armv6"
+	case "7", "8":
+		return "armv7"
+	}
+	return ""
+}
+
+// RosettaArchFallbacks returns ClientOptions.ArchFallbacks that resolve
+// darwin/amd64 assets for darwin/arm64 when a release has no arm64 build,
+// since Rosetta 2 runs them on Apple Silicon.
+func RosettaArchFallbacks() map[string][]string {
+	return map[string][]string{"darwin/arm64": {"amd64"}}
+}
+
+// platform is the target an asset is resolved for.
+type platform struct {
+	OS   string
+	Arch string
+	Libc string
+}
+
+// arches returns the asset architectures that run on the platform, most
+// preferred first: ARMv7 also runs ARMv6 binaries, and a plain "arm"
+// binary is tried last since its ARM version is unknown.
+func (p platform) arches() []string {
+	switch p.Arch {
+	case "armv7":
+		return []string{"armv7", "armv6", "arm"}
+	case "armv6":
+		return []string{"armv6", "arm"}
+	case "arm":
+		return []string{"arm", "armv6"}
+	}
+	return []string{p.Arch}
+}
+
+// libcs returns the libc suffixes of the assets that run on the platform,
+// most preferred first, "" for assets without one. Unsuffixed assets are
+// usually static Go binaries that run anywhere; on musl systems glibc
+// binaries do not run at all, while on glibc systems musl binaries are a
+// last resort since they are typically static.
+func (p platform) libcs() []string {
+	if p.OS != "linux" {
+		return []string{""}
+	}
+	if p.Libc == LibcMusl {
+		return []string{LibcMusl, ""}
+	}
+	return []string{"", LibcGNU, LibcMusl}
+}
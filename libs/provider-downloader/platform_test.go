@@ -0,0 +1,40 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDetectLibc(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		if got := DetectLibc(); got != "" {
+			t.Errorf("DetectLibc() = %q, want empty off linux", got)
+		}
+		return
+	}
+
+	dir := t.TempDir()
+	orig := muslLoaderGlob
+	t.Cleanup(func() { muslLoaderGlob = orig })
+	muslLoaderGlob = filepath.Join(dir, "ld-musl-*.so.1")
+
+	if got := DetectLibc(); got != LibcGNU {
+		t.Errorf("DetectLibc() = %q, want %q", got, LibcGNU)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ld-musl-x86_64.so.1"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectLibc(); got != LibcMusl {
+		t.Errorf("DetectLibc() = %q, want %q", got, LibcMusl)
+	}
+}
+
+func TestArmVariant(t *testing.T) {
+	for version, want := range map[string]string{"6": "armv6", "7": "armv7", "8": "armv7", "5": ""} {
+		if got := armVariant(version); got != want {
+			t.Errorf("armVariant(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package downloader
+
+import "errors"
+
+// reflink is only implemented on Linux; elsewhere the store falls back to
+// hard links.
+func reflink(_, _ string) error {
+	return errors.New("reflink not supported")
+}
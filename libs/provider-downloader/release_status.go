@@ -0,0 +1,87 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ReleaseManifestAssetName is the well-known release asset through which a
+// provider release can mark itself deprecated or yanked. It contains a JSON
+// object such as:
+//
+//	{"status": "yanked", "message": "corrupts output on empty maps; use v1.2.4"}
+const ReleaseManifestAssetName = "nomos-release.json"
+
+// ReleaseStatus is the lifecycle status a release declares for itself.
+type ReleaseStatus string
+
+const (
+	// ReleaseStatusActive is the status of releases without a manifest.
+	ReleaseStatusActive ReleaseStatus = ""
+
+	// ReleaseStatusDeprecated marks a release that still works but should
+	// be upgraded from. Callers should warn.
+	ReleaseStatusDeprecated ReleaseStatus = "deprecated"
+
+	// ReleaseStatusYanked marks a release that must not be installed.
+	// ResolveAsset refuses it unless ProviderSpec.AllowYanked is set.
+	ReleaseStatusYanked ReleaseStatus = "yanked"
+)
+
+// releaseManifest is the content of the ReleaseManifestAssetName asset.
+type releaseManifest struct {
+	Status  ReleaseStatus `json:"status"`
+	Message string        `json:"message"`
+}
+
+// fetchReleaseStatus reads the release manifest asset, if the release has
+// one. Releases without the asset are active.
+func (c *Client) fetchReleaseStatus(ctx context.Context, release *githubRelease) (releaseManifest, error) {
+	var url string
+	for _, asset := range release.Assets {
+		if asset.Name == ReleaseManifestAssetName {
+			url = asset.BrowserDownloadURL
+			break
+		}
+	}
+	if url == "" {
+		return releaseManifest{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return releaseManifest{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.authorize(req); err != nil {
+		return releaseManifest{}, err
+	}
+
+	c.debugf("Release manifest request: %s", url)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return releaseManifest{}, fmt.Errorf("failed to fetch %s: %w", ReleaseManifestAssetName, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return releaseManifest{}, fmt.Errorf("failed to fetch %s: HTTP %d", ReleaseManifestAssetName, resp.StatusCode)
+	}
+
+	var manifest releaseManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&manifest); err != nil {
+		return releaseManifest{}, fmt.Errorf("failed to parse %s: %w", ReleaseManifestAssetName, err)
+	}
+
+	switch manifest.Status {
+	case ReleaseStatusActive, ReleaseStatusDeprecated, ReleaseStatusYanked:
+	default:
+		return releaseManifest{}, fmt.Errorf("invalid %s: unknown status %q", ReleaseManifestAssetName, manifest.Status)
+	}
+
+	c.debugf("Release status: %q (%s)", manifest.Status, manifest.Message)
+	return manifest, nil
+}
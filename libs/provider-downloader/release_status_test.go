@@ -0,0 +1,118 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newReleaseStatusServer serves release v1.0.0 of owner/test-provider with a
+// release manifest asset holding manifest, or none when manifest is empty.
+func newReleaseStatusServer(t *testing.T, manifest string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/test-provider/releases/tags/v1.0.0":
+			release := githubRelease{
+				TagName: "v1.0.0",
+				Assets: []githubAsset{{
+					Name:               "test-provider-1.0.0-linux-amd64",
+					BrowserDownloadURL: server.URL + "/download/binary",
+				}},
+			}
+			if manifest != "" {
+				release.Assets = append(release.Assets, githubAsset{
+					Name:               ReleaseManifestAssetName,
+					BrowserDownloadURL: server.URL + "/download/manifest",
+				})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(release)
+		case "/download/manifest":
+			_, _ = w.Write([]byte(manifest))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+// TestResolveAsset_ReleaseStatus tests deprecated and yanked releases.
+func TestResolveAsset_ReleaseStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		manifest    string
+		allowYanked bool
+		wantStatus  ReleaseStatus
+		wantMessage string
+		wantErr     error
+	}{
+		{name: "no manifest", wantStatus: ReleaseStatusActive},
+		{
+			name:        "deprecated",
+			manifest:    `{"status": "deprecated", "message": "upgrade to v2"}`,
+			wantStatus:  ReleaseStatusDeprecated,
+			wantMessage: "upgrade to v2",
+		},
+		{
+			name:     "yanked refused",
+			manifest: `{"status": "yanked", "message": "corrupts output"}`,
+			wantErr:  ErrReleaseYanked,
+		},
+		{
+			name:        "yanked allowed",
+			manifest:    `{"status": "yanked", "message": "corrupts output"}`,
+			allowYanked: true,
+			wantStatus:  ReleaseStatusYanked,
+			wantMessage: "corrupts output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newReleaseStatusServer(t, tt.manifest)
+			defer server.Close()
+
+			client := NewClient(&ClientOptions{BaseURL: server.URL})
+			asset, err := client.ResolveAsset(context.Background(), &ProviderSpec{
+				Owner: "owner", Repo: "test-provider", Version: "1.0.0", OS: "linux", Arch: "amd64",
+				AllowYanked: tt.allowYanked,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				var yanked *ReleaseYankedError
+				if !errors.As(err, &yanked) || yanked.Message != "corrupts output" {
+					t.Errorf("expected ReleaseYankedError with message, got %#v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if asset.Status != tt.wantStatus || asset.StatusMessage != tt.wantMessage {
+				t.Errorf("status = %q %q, want %q %q", asset.Status, asset.StatusMessage, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+// TestResolveAsset_InvalidReleaseStatus tests that an unknown status fails
+// resolution rather than being ignored.
+func TestResolveAsset_InvalidReleaseStatus(t *testing.T) {
+	server := newReleaseStatusServer(t, `{"status": "retired"}`)
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{BaseURL: server.URL})
+	_, err := client.ResolveAsset(context.Background(), &ProviderSpec{
+		Owner: "owner", Repo: "test-provider", Version: "1.0.0", OS: "linux", Arch: "amd64",
+	})
+	if err == nil {
+		t.Fatal("expected error for unknown release status")
+	}
+}
@@ -12,8 +12,10 @@ import (
 
 // githubRelease represents a GitHub release response from the API.
 type githubRelease struct {
-	TagName string        `json:"tag_name"`
-	Assets  []githubAsset `json:"assets"`
+	TagName    string        `json:"tag_name"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []githubAsset `json:"assets"`
 }
 
 // githubAsset represents a release asset from the GitHub API.
@@ -22,6 +24,7 @@ type githubAsset struct {
 	BrowserDownloadURL string `json:"browser_download_url"`
 	Size               int64  `json:"size"`
 	ContentType        string `json:"content_type"`
+	Digest             string `json:"digest"`
 }
 
 // resolveAssetFromGitHub resolves an asset by querying the GitHub Releases API.
@@ -39,6 +42,20 @@ func (c *Client) resolveAssetFromGitHub(ctx context.Context, spec *ProviderSpec)
 		targetArch = runtime.GOARCH
 	}
 
+	// Fallbacks are keyed by the architecture as given
+	fallbacks := c.archFallbacks[targetOS+"/"+targetArch]
+
+	// On the running system, narrow arm to its ARM version and detect libc
+	targetLibc := spec.Libc
+	if targetOS == runtime.GOOS && targetArch == runtime.GOARCH {
+		if targetArch == "arm" {
+			targetArch = DetectARM()
+		}
+		if targetLibc == "" {
+			targetLibc = DetectLibc()
+		}
+	}
+
 	// Normalize version (handle "v" prefix)
 	version := normalizeVersion(spec.Version)
 
@@ -47,7 +64,7 @@ func (c *Client) resolveAssetFromGitHub(ctx context.Context, spec *ProviderSpec)
 	// the resolved target OS/Arch (those are determined here). Wrap that
 	// error so callers get consistent diagnostics showing the OS/Arch
 	// that were used to attempt resolution.
-	release, err := c.fetchRelease(ctx, spec.Owner, spec.Repo, version)
+	release, err := c.fetchRelease(ctx, spec.Owner, spec.Repo, spec.TagPrefix, version)
 	if err != nil {
 		// If the error indicates the release/tag wasn't found, return an
 		// AssetNotFoundError that includes the target OS/Arch for better
@@ -64,9 +81,38 @@ func (c *Client) resolveAssetFromGitHub(ctx context.Context, spec *ProviderSpec)
 		return nil, err
 	}
 
+	// Channels resolve to a concrete release; match assets against its tag
+	tag := strings.TrimPrefix(release.TagName, spec.TagPrefix)
+	if IsChannel(version) {
+		version = tag
+	}
+
+	// Honor the release's own deprecated/yanked status
+	status, err := c.fetchReleaseStatus(ctx, release)
+	if err != nil {
+		return nil, err
+	}
+	if status.Status == ReleaseStatusYanked && !spec.AllowYanked {
+		return nil, &ReleaseYankedError{
+			Owner:   spec.Owner,
+			Repo:    spec.Repo,
+			Version: release.TagName,
+			Message: status.Message,
+		}
+	}
+
 	// Try to find matching asset using ordered matchers
-	c.debugf("Searching for asset matching: repo=%s, version=%s, os=%s, arch=%s", spec.Repo, version, targetOS, targetArch)
-	assetName := c.findMatchingAsset(release.Assets, spec.Repo, version, targetOS, targetArch)
+	c.debugf("Searching for asset matching: repo=%s, version=%s, os=%s, arch=%s, libc=%s", spec.Repo, version, targetOS, targetArch, targetLibc)
+	assetName := c.findMatchingAsset(release.Assets, spec.Repo, version, platform{OS: targetOS, Arch: targetArch, Libc: targetLibc})
+	fallbackArch := ""
+	for _, arch := range fallbacks {
+		if assetName != "" {
+			break
+		}
+		c.debugf("No asset for %s/%s, trying fallback architecture %s", targetOS, targetArch, arch)
+		assetName = c.findMatchingAsset(release.Assets, spec.Repo, version, platform{OS: targetOS, Arch: arch, Libc: targetLibc})
+		fallbackArch = arch
+	}
 	if assetName == "" {
 		c.debugf("No matching asset found")
 		return nil, &AssetNotFoundError{
@@ -83,10 +129,15 @@ func (c *Client) resolveAssetFromGitHub(ctx context.Context, spec *ProviderSpec)
 	for _, asset := range release.Assets {
 		if asset.Name == assetName {
 			return &AssetInfo{
-				URL:         asset.BrowserDownloadURL,
-				Name:        asset.Name,
-				Size:        asset.Size,
-				ContentType: asset.ContentType,
+				URL:           asset.BrowserDownloadURL,
+				Name:          asset.Name,
+				Size:          asset.Size,
+				Checksum:      assetChecksum(asset.Digest),
+				ContentType:   asset.ContentType,
+				Version:       tag,
+				Status:        status.Status,
+				StatusMessage: status.Message,
+				FallbackArch:  fallbackArch,
 			}, nil
 		}
 	}
@@ -95,16 +146,31 @@ func (c *Client) resolveAssetFromGitHub(ctx context.Context, spec *ProviderSpec)
 	return nil, fmt.Errorf("asset %q found but details missing", assetName)
 }
 
+// assetChecksum converts a GitHub asset digest to the downloader's checksum
+// format. Only SHA-256 digests are used; others yield "".
+func assetChecksum(digest string) string {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return ""
+	}
+	return digest
+}
+
 // fetchRelease fetches a release from the GitHub API.
 // If version is empty or "latest", it fetches the latest release.
-// Otherwise, it fetches the release by tag.
-func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string) (*githubRelease, error) {
+// If version is "prerelease", it fetches the newest release, including
+// pre-releases. Otherwise, it fetches the release tagged tagPrefix+version.
+// With a tagPrefix, channels consider only releases tagged with it.
+func (c *Client) fetchRelease(ctx context.Context, owner, repo, tagPrefix, version string) (*githubRelease, error) {
+	if version == ChannelPrerelease || (tagPrefix != "" && (version == "" || version == ChannelLatest)) {
+		return c.fetchNewestRelease(ctx, owner, repo, tagPrefix, version == ChannelPrerelease)
+	}
+
 	var url string
-	if version == "" || version == "latest" {
+	if version == "" || version == ChannelLatest {
 		url = fmt.Sprintf("%s/repos/%s/%s/releases/latest", c.baseURL, owner, repo)
 	} else {
 		// Try both with and without "v" prefix
-		url = fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", c.baseURL, owner, repo, version)
+		url = fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s%s", c.baseURL, owner, repo, tagPrefix, version)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -112,16 +178,15 @@ func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add GitHub token if provided
-	if c.githubToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.githubToken)
+	if err := c.authorize(req); err != nil {
+		return nil, err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 
 	// Log the request URL
 	c.debugf("GitHub API request: %s", url)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GitHub API request failed: %w", err)
 	}
@@ -131,6 +196,10 @@ func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string)
 	c.debugf("GitHub API response: HTTP %d", resp.StatusCode)
 
 	if resp.StatusCode == http.StatusNotFound {
+		// Release the first response before the retry, which may need its
+		// host slot
+		_ = resp.Body.Close()
+
 		// Try alternate version format (add/remove "v" prefix)
 		var altVersion string
 		if strings.HasPrefix(version, "v") {
@@ -139,7 +208,7 @@ func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string)
 			altVersion = "v" + version
 		}
 
-		altURL := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", c.baseURL, owner, repo, altVersion)
+		altURL := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s%s", c.baseURL, owner, repo, tagPrefix, altVersion)
 		altReq, err := http.NewRequestWithContext(ctx, "GET", altURL, nil)
 		if err != nil {
 			return nil, &AssetNotFoundError{
@@ -149,12 +218,12 @@ func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string)
 			}
 		}
 
-		if c.githubToken != "" {
-			altReq.Header.Set("Authorization", "Bearer "+c.githubToken)
+		if err := c.authorize(altReq); err != nil {
+			return nil, err
 		}
 		altReq.Header.Set("Accept", "application/vnd.github+json")
 
-		altResp, err := c.httpClient.Do(altReq)
+		altResp, err := c.do(altReq)
 		if err != nil || altResp.StatusCode != http.StatusOK {
 			if altResp != nil {
 				_ = altResp.Body.Close()
@@ -197,27 +266,111 @@ func (c *Client) fetchRelease(ctx context.Context, owner, repo, version string)
 	return &release, nil
 }
 
+// fetchNewestRelease returns the most recently created non-draft release
+// tagged with tagPrefix, which is a pre-release only if prerelease is true.
+func (c *Client) fetchNewestRelease(ctx context.Context, owner, repo, tagPrefix string, prerelease bool) (*githubRelease, error) {
+	// Prefixed tags share the list with other modules' releases
+	perPage := 30
+	if tagPrefix != "" {
+		perPage = 100
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d", c.baseURL, owner, repo, perPage)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	c.debugf("GitHub API request: %s", url)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	c.debugf("GitHub API response: HTTP %d", resp.StatusCode)
+
+	if resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return nil, ErrRateLimitExceeded
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+
+	// GitHub lists releases newest first
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && !prerelease) || !strings.HasPrefix(r.TagName, tagPrefix) {
+			continue
+		}
+		c.debugf("Newest release: %s (prerelease: %t)", r.TagName, r.Prerelease)
+		return r, nil
+	}
+	channel := ChannelLatest
+	if prerelease {
+		channel = ChannelPrerelease
+	}
+	return nil, &AssetNotFoundError{Owner: owner, Repo: repo, Version: channel}
+}
+
 // findMatchingAsset applies ordered matching rules to find the best asset.
-// Returns the asset name if found, or empty string if no match.
-func (c *Client) findMatchingAsset(assets []githubAsset, repo, version, targetOS, targetArch string) string {
-	assetNames := make([]string, len(assets))
-	for i, asset := range assets {
-		assetNames[i] = asset.Name
+// Returns the asset name if found, or empty string if no match. Within each
+// rule, assets are preferred by architecture variant and then libc, see
+// platform. In strict mode only canonical names match.
+func (c *Client) findMatchingAsset(assets []githubAsset, repo, version string, target platform) string {
+	assetNames := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		// Checksums, signatures, and manifests never hold the binary
+		if isAuxiliaryAsset(asset.Name) {
+			continue
+		}
+		assetNames = append(assetNames, asset.Name)
 	}
 
 	// Strip "v" prefix from version for pattern matching (e.g., "v0.1.0" -> "0.1.0")
 	versionNumber := strings.TrimPrefix(version, "v")
 
+	if c.strictAssets {
+		c.debugf("Strict mode: accepting only canonical asset names")
+		for _, want := range canonicalAssetNames(repo, versionNumber, target) {
+			c.debugf("  %s", want)
+			for _, name := range assetNames {
+				if name == want {
+					return name
+				}
+			}
+		}
+		return ""
+	}
+
 	// 1. Try exact patterns in priority order
-	// Pattern format: {repo}-{version}-{os}-{arch}[.extension]
-	patterns := []string{
-		// With version: repo-version-os-arch (most specific, matches actual releases)
-		fmt.Sprintf("%s-%s-%s-%s", repo, versionNumber, targetOS, targetArch),
-		// Legacy patterns (for backwards compatibility)
-		fmt.Sprintf("%s-%s-%s", repo, targetOS, targetArch),
-		fmt.Sprintf("nomos-provider-%s-%s", targetOS, targetArch),
-		fmt.Sprintf("%s-%s", repo, targetOS),
+	// Pattern format: {repo}-{version}-{os}-{arch}[-{libc}][.extension]
+	var patterns []string
+	for _, arch := range target.arches() {
+		for _, libc := range target.libcs() {
+			if libc != "" {
+				libc = "-" + libc
+			}
+			patterns = append(patterns,
+				// With version: repo-version-os-arch (most specific, matches actual releases)
+				fmt.Sprintf("%s-%s-%s-%s%s", repo, versionNumber, target.OS, arch, libc),
+				// Legacy patterns (for backwards compatibility)
+				fmt.Sprintf("%s-%s-%s%s", repo, target.OS, arch, libc),
+				fmt.Sprintf("nomos-provider-%s-%s%s", target.OS, arch, libc),
+			)
+		}
 	}
+	patterns = append(patterns, fmt.Sprintf("%s-%s", repo, target.OS))
 
 	c.debugf("Trying exact patterns:")
 	for i, pattern := range patterns {
@@ -226,83 +379,136 @@ func (c *Client) findMatchingAsset(assets []githubAsset, repo, version, targetOS
 
 	for _, pattern := range patterns {
 		for _, name := range assetNames {
-			// Check exact match
-			if name == pattern {
-				c.debugf("Found exact match: %s", name)
-				return name
-			}
-			// Also check with common extensions (.tar.gz, .zip, .tgz, etc.)
-			if strings.HasPrefix(name, pattern+".") {
-				c.debugf("Found pattern match with extension: %s (pattern: %s)", name, pattern)
-				return name
+			// Check exact match, or the pattern with an asset extension
+			for _, ext := range AssetExtensions {
+				if name == pattern+ext {
+					c.debugf("Found exact match: %s (pattern: %s)", name, pattern)
+					return name
+				}
 			}
 		}
 	}
 	c.debugf("No exact pattern matches found")
 
-	// 2. Fallback: substring matching (case-insensitive)
-	// Normalize arch names for matching (amd64 == x86_64)
-	archVariants := []string{targetArch}
-	switch targetArch {
-	case "amd64":
-		archVariants = append(archVariants, "x86_64", "x86-64")
-	case "arm64":
-		archVariants = append(archVariants, "aarch64")
+	// 2. Names following the spec with aliases, e.g. repo-v1.0.0-Linux-x86_64
+	for _, arch := range target.arches() {
+		for _, libc := range target.libcs() {
+			for _, name := range assetNames {
+				a, err := ParseAssetName(name)
+				if err == nil && strings.EqualFold(a.Repo, repo) && a.Version == versionNumber && a.OS == target.OS && a.Arch == arch && a.Libc == libc {
+					c.debugf("Found alias match: %s (canonical: %s)", name, a)
+					return name
+				}
+			}
+		}
 	}
 
-	c.debugf("Trying substring matching (case-insensitive) - looking for: os=%s, arch=%v, version=%s", targetOS, archVariants, versionNumber)
+	// 3. Fallback: substring matching (case-insensitive)
+	c.debugf("Trying substring matching (case-insensitive) - looking for: os=%s, arch=%v, libc=%v, version=%s", target.OS, target.arches(), target.libcs(), versionNumber)
 
 	// First pass: prefer matches that include version in filename
 	c.debugf("First pass: looking for assets with version in name")
-	for _, name := range assetNames {
-		nameLower := strings.ToLower(name)
-		osMatch := strings.Contains(nameLower, strings.ToLower(targetOS))
-		versionMatch := strings.Contains(nameLower, strings.ToLower(versionNumber))
-
-		archMatch := false
-		for _, arch := range archVariants {
-			if strings.Contains(nameLower, strings.ToLower(arch)) {
-				archMatch = true
-				break
-			}
-		}
-
-		if osMatch && archMatch && versionMatch {
-			c.debugf("Found substring match (with version): %s", name)
-			return name
-		}
+	if name := substringMatch(assetNames, target, versionNumber); name != "" {
+		c.debugf("Found substring match (with version): %s", name)
+		return name
 	}
 	c.debugf("No matches found with version in filename")
 
 	// Second pass: match without version requirement (legacy support)
 	c.debugf("Second pass: looking for assets without version requirement")
-	for _, name := range assetNames {
-		nameLower := strings.ToLower(name)
-		osMatch := strings.Contains(nameLower, strings.ToLower(targetOS))
-
-		archMatch := false
-		for _, arch := range archVariants {
-			if strings.Contains(nameLower, strings.ToLower(arch)) {
-				archMatch = true
-				break
+	if name := substringMatch(assetNames, target, ""); name != "" {
+		c.debugf("Found substring match (legacy): %s", name)
+		return name
+	}
+
+	c.debugf("No substring matches found")
+	return ""
+}
+
+// substringMatch returns the first name containing the target OS, one of
+// its architectures, and version, trying architectures and then libcs in
+// order of preference.
+func substringMatch(names []string, target platform, version string) string {
+	for _, arch := range target.arches() {
+		for _, libc := range target.libcs() {
+			for _, name := range names {
+				nameLower := strings.ToLower(name)
+				if strings.Contains(nameLower, strings.ToLower(target.OS)) &&
+					strings.Contains(nameLower, strings.ToLower(version)) &&
+					containsArch(nameLower, arch) &&
+					assetLibc(nameLower) == libc {
+					return name
+				}
 			}
 		}
+	}
+	return ""
+}
 
-		if osMatch && archMatch {
-			c.debugf("Found substring match (legacy): %s", name)
-			return name
+// containsArch reports whether a lower-case asset name names arch,
+// accepting common variations (amd64 == x86_64). ARM names are matched as
+// whole "-", "_", or "." separated words, since "arm" is a substring of
+// "arm64" and "armv7".
+func containsArch(nameLower, arch string) bool {
+	switch arch {
+	case "amd64":
+		return strings.Contains(nameLower, "amd64") || strings.Contains(nameLower, "x86_64") || strings.Contains(nameLower, "x86-64")
+	case "arm64":
+		return strings.Contains(nameLower, "arm64") || strings.Contains(nameLower, "aarch64")
+	case "arm", "armv6", "armv7":
+		for _, word := range assetWords(nameLower) {
+			if word == arch || word == arch+"l" {
+				return true
+			}
 		}
+		return false
 	}
+	return strings.Contains(nameLower, arch)
+}
 
-	c.debugf("No substring matches found")
+// assetLibc returns the libc a lower-case asset name is suffixed with, or
+// "" for none.
+func assetLibc(nameLower string) string {
+	for _, word := range assetWords(nameLower) {
+		if libc, ok := libcAliases[word]; ok {
+			return libc
+		}
+	}
 	return ""
 }
 
+// assetWords splits an asset name at "-", "_", and ".".
+func assetWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+}
+
+// auxiliaryExtensions end the names of release assets that accompany a
+// binary rather than hold one.
+var auxiliaryExtensions = []string{".sha256", ".sha256sum", ".sha512", ".md5", ".sig", ".asc", ".pem", ".txt", ".json", ".sbom"}
+
+// isAuxiliaryAsset reports whether an asset is a checksum file, signature,
+// or manifest rather than a provider binary.
+func isAuxiliaryAsset(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.Contains(lower, "checksum") {
+		return true
+	}
+	for _, ext := range auxiliaryExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // normalizeVersion normalizes version strings by ensuring they have a "v" prefix.
-// If the version is empty, it returns "latest".
+// If the version is empty, it returns "latest". Channels are returned unchanged.
 func normalizeVersion(version string) string {
 	if version == "" {
-		return "latest"
+		return ChannelLatest
+	}
+	if IsChannel(version) {
+		return version
 	}
 	if !strings.HasPrefix(version, "v") {
 		return "v" + version
@@ -0,0 +1,150 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestResolveAsset_Channels tests that release channels resolve to a
+// concrete release and report its tag.
+func TestResolveAsset_Channels(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	release := func(tag string, draft, prerelease bool) githubRelease {
+		return githubRelease{
+			TagName:    tag,
+			Draft:      draft,
+			Prerelease: prerelease,
+			Assets: []githubAsset{{
+				Name:               "test-provider-" + tag[1:] + "-linux-amd64",
+				BrowserDownloadURL: "https://example.invalid/" + tag,
+				Digest:             digest,
+			}},
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/test-provider/releases/latest":
+			_ = json.NewEncoder(w).Encode(release("v1.2.0", false, false))
+		case "/repos/owner/test-provider/releases":
+			_ = json.NewEncoder(w).Encode([]githubRelease{
+				release("v1.4.0-rc.1", true, true),
+				release("v1.3.0-beta.2", false, true),
+				release("v1.2.0", false, false),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{BaseURL: server.URL})
+
+	tests := []struct {
+		channel     string
+		wantVersion string
+	}{
+		{channel: ChannelLatest, wantVersion: "v1.2.0"},
+		{channel: ChannelPrerelease, wantVersion: "v1.3.0-beta.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			asset, err := client.ResolveAsset(context.Background(), &ProviderSpec{
+				Owner: "owner", Repo: "test-provider", Version: tt.channel, OS: "linux", Arch: "amd64",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if asset.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", asset.Version, tt.wantVersion)
+			}
+			if asset.Name != "test-provider-"+tt.wantVersion[1:]+"-linux-amd64" {
+				t.Errorf("Name = %q, want asset of %s", asset.Name, tt.wantVersion)
+			}
+			if asset.Checksum != digest {
+				t.Errorf("Checksum = %q, want asset digest %q", asset.Checksum, digest)
+			}
+		})
+	}
+}
+
+// TestIsChannel tests channel detection.
+func TestIsChannel(t *testing.T) {
+	for version, want := range map[string]bool{
+		"latest":     true,
+		"prerelease": true,
+		"1.0.0":      false,
+		"v1.0.0":     false,
+		"":           false,
+	} {
+		if got := IsChannel(version); got != want {
+			t.Errorf("IsChannel(%q) = %t, want %t", version, got, want)
+		}
+	}
+}
+
+// TestResolveAsset_TagPrefix tests resolving releases of a monorepo module
+// whose tags carry a path prefix, such as the nomos CLI.
+func TestResolveAsset_TagPrefix(t *testing.T) {
+	release := func(tag, version string, prerelease bool) githubRelease {
+		return githubRelease{
+			TagName:    tag,
+			Prerelease: prerelease,
+			Assets: []githubAsset{{
+				Name:               "nomos-" + version + "-linux-amd64.tar.gz",
+				BrowserDownloadURL: "https://example.invalid/" + tag,
+			}},
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/nomos/releases":
+			_ = json.NewEncoder(w).Encode([]githubRelease{
+				release("libs/compiler/v0.9.0", "v0.9.0", false),
+				release("apps/command-line/v1.3.0-rc.1", "v1.3.0-rc.1", true),
+				release("apps/command-line/v1.2.0", "v1.2.0", false),
+				release("apps/command-line/v1.1.0", "v1.1.0", false),
+			})
+		case "/repos/owner/nomos/releases/tags/apps/command-line/v1.1.0":
+			_ = json.NewEncoder(w).Encode(release("apps/command-line/v1.1.0", "v1.1.0", false))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{BaseURL: server.URL})
+
+	tests := []struct {
+		version     string
+		wantVersion string
+	}{
+		{version: ChannelLatest, wantVersion: "v1.2.0"},
+		{version: ChannelPrerelease, wantVersion: "v1.3.0-rc.1"},
+		{version: "1.1.0", wantVersion: "v1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			asset, err := client.ResolveAsset(context.Background(), &ProviderSpec{
+				Owner: "owner", Repo: "nomos", TagPrefix: "apps/command-line/", Version: tt.version, OS: "linux", Arch: "amd64",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if asset.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", asset.Version, tt.wantVersion)
+			}
+			if want := "nomos-" + tt.wantVersion + "-linux-amd64.tar.gz"; asset.Name != want {
+				t.Errorf("Name = %q, want %q", asset.Name, want)
+			}
+		})
+	}
+}
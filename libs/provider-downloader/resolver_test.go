@@ -423,3 +423,38 @@ type mockAsset struct {
 	Size               int64  `json:"size"`
 	ContentType        string `json:"content_type"`
 }
+
+// TestResolveAsset_ArchFallbacks tests that a missing darwin/arm64 asset
+// falls back to darwin/amd64 only when ArchFallbacks allows it.
+func TestResolveAsset_ArchFallbacks(t *testing.T) {
+	spec := &ProviderSpec{Owner: "test-owner", Repo: "test-provider", Version: "1.0.0", OS: "darwin", Arch: "arm64"}
+	server := newMockGitHubServer(t, spec.Owner, spec.Repo, spec.Version, []string{
+		"test-provider-1.0.0-darwin-amd64.tar.gz",
+		"test-provider-1.0.0-linux-arm64.tar.gz",
+	})
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{BaseURL: server.URL})
+	if _, err := client.ResolveAsset(context.Background(), spec); !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("ResolveAsset() without fallbacks error = %v, want ErrAssetNotFound", err)
+	}
+
+	client = NewClient(&ClientOptions{BaseURL: server.URL, ArchFallbacks: RosettaArchFallbacks()})
+	asset, err := client.ResolveAsset(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("ResolveAsset() error = %v", err)
+	}
+	if asset.Name != "test-provider-1.0.0-darwin-amd64.tar.gz" || asset.FallbackArch != "amd64" {
+		t.Errorf("ResolveAsset() = %s (fallback %q), want the darwin-amd64 asset with fallback amd64", asset.Name, asset.FallbackArch)
+	}
+
+	// A native asset needs no fallback
+	spec.OS = "linux"
+	asset, err = client.ResolveAsset(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("ResolveAsset() error = %v", err)
+	}
+	if asset.FallbackArch != "" {
+		t.Errorf("FallbackArch = %q for a native asset, want empty", asset.FallbackArch)
+	}
+}
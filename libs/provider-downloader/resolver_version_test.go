@@ -79,7 +79,7 @@ func TestFindMatchingAsset_WithVersion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := &Client{}
-			got := client.findMatchingAsset(tt.assets, tt.repo, tt.version, tt.targetOS, tt.targetArch)
+			got := client.findMatchingAsset(tt.assets, tt.repo, tt.version, platform{OS: tt.targetOS, Arch: tt.targetArch})
 			if got != tt.want {
 				t.Errorf("findMatchingAsset() = %v, want %v", got, tt.want)
 			}
@@ -124,10 +124,169 @@ func TestFindMatchingAsset_ArchVariants(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := &Client{}
-			got := client.findMatchingAsset(tt.assets, tt.repo, tt.version, tt.targetOS, tt.targetArch)
+			got := client.findMatchingAsset(tt.assets, tt.repo, tt.version, platform{OS: tt.targetOS, Arch: tt.targetArch})
 			if got != tt.want {
 				t.Errorf("findMatchingAsset() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestFindMatchingAsset_SkipsAuxiliaryAssets(t *testing.T) {
+	assets := []githubAsset{
+		{Name: "nomos-provider-file-1.0.0-linux-amd64.sha256"},
+		{Name: "nomos-provider-file-1.0.0-checksums-linux-amd64"},
+		{Name: "nomos-provider-file-1.0.0-linux-amd64.tar.gz"},
+	}
+	client := &Client{}
+	if got := client.findMatchingAsset(assets, "nomos-provider-file", "v1.0.0", platform{OS: "linux", Arch: "amd64"}); got != "nomos-provider-file-1.0.0-linux-amd64.tar.gz" {
+		t.Errorf("findMatchingAsset() = %v, want the archive", got)
+	}
+}
+
+func TestFindMatchingAsset_Strict(t *testing.T) {
+	tests := []struct {
+		name       string
+		assets     []githubAsset
+		targetOS   string
+		targetArch string
+		want       string
+	}{
+		{
+			name: "prefers raw binary over archive",
+			assets: []githubAsset{
+				{Name: "nomos-provider-file-1.0.0-linux-amd64.tar.gz"},
+				{Name: "nomos-provider-file-1.0.0-linux-amd64"},
+			},
+			targetOS:   "linux",
+			targetArch: "amd64",
+			want:       "nomos-provider-file-1.0.0-linux-amd64",
+		},
+		{
+			name: "windows binary ends in exe",
+			assets: []githubAsset{
+				{Name: "nomos-provider-file-1.0.0-windows-amd64"},
+				{Name: "nomos-provider-file-1.0.0-windows-amd64.zip"},
+			},
+			targetOS:   "windows",
+			targetArch: "amd64",
+			want:       "nomos-provider-file-1.0.0-windows-amd64.zip",
+		},
+		{
+			name: "rejects aliases and legacy names",
+			assets: []githubAsset{
+				{Name: "nomos-provider-file-1.0.0-linux-x86_64"},
+				{Name: "nomos-provider-file-linux-amd64"},
+				{Name: "provider_linux_amd64"},
+			},
+			targetOS:   "linux",
+			targetArch: "amd64",
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{strictAssets: true}
+			got := client.findMatchingAsset(tt.assets, "nomos-provider-file", "v1.0.0", platform{OS: tt.targetOS, Arch: tt.targetArch})
+			if got != tt.want {
+				t.Errorf("findMatchingAsset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMatchingAsset_Aliases(t *testing.T) {
+	assets := []githubAsset{
+		{Name: "nomos-provider-file-v1.0.0-macOS-aarch64.tar.gz"},
+	}
+	client := &Client{}
+	if got := client.findMatchingAsset(assets, "nomos-provider-file", "v1.0.0", platform{OS: "darwin", Arch: "arm64"}); got != assets[0].Name {
+		t.Errorf("findMatchingAsset() = %q, want %q", got, assets[0].Name)
+	}
+}
+
+func TestFindMatchingAsset_PlatformVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []string
+		target platform
+		strict bool
+		want   string
+	}{
+		{
+			name:   "armv7 prefers armv7 over armv6 and arm",
+			assets: []string{"nomos-provider-file-1.0.0-linux-arm.tar.gz", "nomos-provider-file-1.0.0-linux-armv6.tar.gz", "nomos-provider-file-1.0.0-linux-armv7.tar.gz"},
+			target: platform{OS: "linux", Arch: "armv7", Libc: LibcGNU},
+			want:   "nomos-provider-file-1.0.0-linux-armv7.tar.gz",
+		},
+		{
+			name:   "armv7 runs armv6",
+			assets: []string{"nomos-provider-file-1.0.0-linux-arm64", "nomos-provider-file-1.0.0-linux-armv6"},
+			target: platform{OS: "linux", Arch: "armv7", Libc: LibcGNU},
+			want:   "nomos-provider-file-1.0.0-linux-armv6",
+		},
+		{
+			name:   "armv6 never matches armv7 or arm64",
+			assets: []string{"provider_linux_armv7.tar.gz", "provider_linux_arm64.tar.gz"},
+			target: platform{OS: "linux", Arch: "armv6", Libc: LibcGNU},
+			want:   "",
+		},
+		{
+			name:   "substring match of armv7l",
+			assets: []string{"provider_Linux_arm64.tar.gz", "provider_Linux_armv7l.tar.gz"},
+			target: platform{OS: "linux", Arch: "armv7", Libc: LibcGNU},
+			want:   "provider_Linux_armv7l.tar.gz",
+		},
+		{
+			name:   "riscv64 alias",
+			assets: []string{"nomos-provider-file-v1.0.0-linux-riscv64gc.tar.gz"},
+			target: platform{OS: "linux", Arch: "riscv64", Libc: LibcGNU},
+			want:   "nomos-provider-file-v1.0.0-linux-riscv64gc.tar.gz",
+		},
+		{
+			name:   "musl prefers musl asset",
+			assets: []string{"nomos-provider-file-1.0.0-linux-amd64", "nomos-provider-file-1.0.0-linux-amd64-musl"},
+			target: platform{OS: "linux", Arch: "amd64", Libc: LibcMusl},
+			want:   "nomos-provider-file-1.0.0-linux-amd64-musl",
+		},
+		{
+			name:   "musl falls back to unsuffixed asset",
+			assets: []string{"nomos-provider-file-1.0.0-linux-amd64-gnu.tar.gz", "nomos-provider-file-1.0.0-linux-amd64.tar.gz"},
+			target: platform{OS: "linux", Arch: "amd64", Libc: LibcMusl},
+			want:   "nomos-provider-file-1.0.0-linux-amd64.tar.gz",
+		},
+		{
+			name:   "musl never matches glibc asset",
+			assets: []string{"provider_linux_x86_64_glibc.tar.gz"},
+			target: platform{OS: "linux", Arch: "amd64", Libc: LibcMusl},
+			want:   "",
+		},
+		{
+			name:   "glibc prefers unsuffixed, then gnu, then musl",
+			assets: []string{"nomos-provider-file-1.0.0-linux-amd64-musl", "nomos-provider-file-1.0.0-linux-amd64-gnu"},
+			target: platform{OS: "linux", Arch: "amd64", Libc: LibcGNU},
+			want:   "nomos-provider-file-1.0.0-linux-amd64-gnu",
+		},
+		{
+			name:   "strict mode orders variants",
+			assets: []string{"nomos-provider-file-1.0.0-linux-arm.tar.gz", "nomos-provider-file-1.0.0-linux-armv6-musl.tar.gz"},
+			target: platform{OS: "linux", Arch: "armv7", Libc: LibcMusl},
+			strict: true,
+			want:   "nomos-provider-file-1.0.0-linux-armv6-musl.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := make([]githubAsset, len(tt.assets))
+			for i, name := range tt.assets {
+				assets[i] = githubAsset{Name: name}
+			}
+			client := &Client{strictAssets: tt.strict}
+			if got := client.findMatchingAsset(assets, "nomos-provider-file", "v1.0.0", tt.target); got != tt.want {
+				t.Errorf("findMatchingAsset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how downloads are retried after transient failures.
+// Zero fields take their defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of download attempts, the first one
+	// included. Default: 4
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry; each further retry
+	// doubles it. Default: 1 second
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. Default: 30 seconds
+	MaxDelay time.Duration
+
+	// RetryableStatusCodes lists the HTTP status codes that are retried.
+	// Default: all 5xx status codes
+	RetryableStatusCodes []int
+
+	// AttemptTimeout bounds each attempt separately from the caller's
+	// context; an attempt that times out is retried. Default: no limit
+	// besides the HTTP client's timeout
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when ClientOptions sets
+// neither RetryPolicy nor RetryAttempts and RetryDelay.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// retryPolicy returns the effective retry policy for opts. RetryPolicy
+// takes precedence; otherwise RetryAttempts and RetryDelay, which count
+// retries rather than attempts, fill in the defaults.
+func retryPolicy(opts *ClientOptions) RetryPolicy {
+	defaults := DefaultRetryPolicy()
+
+	var policy RetryPolicy
+	if opts.RetryPolicy != nil {
+		policy = *opts.RetryPolicy
+		policy.RetryableStatusCodes = slices.Clone(policy.RetryableStatusCodes)
+	} else {
+		if opts.RetryAttempts > 0 {
+			policy.MaxAttempts = opts.RetryAttempts + 1
+		}
+		policy.BaseDelay = opts.RetryDelay
+	}
+
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaults.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+	return policy
+}
+
+// backoff returns the delay before the given retry (1 for the first),
+// doubling from BaseDelay up to MaxDelay with up to 10% jitter.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.MaxDelay
+	if shift := retry - 1; shift < 31 && p.BaseDelay<<shift < p.MaxDelay {
+		delay = p.BaseDelay << shift
+	}
+	if jitter := int64(delay / 10); jitter > 0 {
+		delay += time.Duration(rand.Int64N(jitter + 1)) //nolint:gosec // G404: Jitter does not need a secure source
+	}
+	return delay
+}
+
+// retryable reports whether a failed attempt should be repeated. ctx is the
+// caller's context: once it is done, nothing is retried.
+func (p RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		if p.RetryableStatusCodes == nil {
+			return statusErr.StatusCode >= 500
+		}
+		return slices.Contains(p.RetryableStatusCodes, statusErr.StatusCode)
+	}
+
+	// An attempt that hit its own AttemptTimeout is retried
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Network errors and incomplete reads are retryable
+	errStr := err.Error()
+	return strings.Contains(errStr, "connection") ||
+		strings.Contains(errStr, "timeout") ||
+		strings.Contains(errStr, "unexpected EOF")
+}
+
+// httpStatusError reports a download answered with a non-200 status.
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("download failed with status %d: %s", e.StatusCode, e.Status)
+}
@@ -0,0 +1,151 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicy_Defaults tests how RetryPolicy, RetryAttempts and
+// RetryDelay combine into the client's policy.
+func TestRetryPolicy_Defaults(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *ClientOptions
+		want RetryPolicy
+	}{
+		{
+			name: "zero options",
+			opts: &ClientOptions{},
+			want: DefaultRetryPolicy(),
+		},
+		{
+			name: "legacy fields",
+			opts: &ClientOptions{RetryAttempts: 1, RetryDelay: time.Millisecond},
+			want: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 30 * time.Second},
+		},
+		{
+			name: "policy wins over legacy fields",
+			opts: &ClientOptions{
+				RetryAttempts: 5,
+				RetryPolicy:   &RetryPolicy{MaxAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Second},
+			},
+			want: RetryPolicy{MaxAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retryPolicy(tt.opts)
+			if got.MaxAttempts != tt.want.MaxAttempts || got.BaseDelay != tt.want.BaseDelay || got.MaxDelay != tt.want.MaxDelay {
+				t.Errorf("retryPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRetryPolicy_Backoff tests that delays double from BaseDelay, stay
+// within 10% jitter, and are capped by MaxDelay.
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for retry, base := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		64: time.Second,
+	} {
+		if got := policy.backoff(retry); got < base || got > base+base/10 {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", retry, got, base, base+base/10)
+		}
+	}
+}
+
+// TestDownloadAndInstall_RetryableStatusCodes tests that only the configured
+// status codes are retried and that exhausted retries are reported as
+// ErrRetriesExhausted wrapping the last error.
+func TestDownloadAndInstall_RetryableStatusCodes(t *testing.T) {
+	var attempts atomic.Int64
+	status := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{
+		HTTPClient: server.Client(),
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts:          3,
+			BaseDelay:            time.Millisecond,
+			RetryableStatusCodes: []int{http.StatusTooManyRequests},
+		},
+	})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider"}
+
+	_, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir())
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Fatalf("expected ErrRetriesExhausted, got %v", err)
+	}
+	var exhausted *RetriesExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 3 {
+		t.Errorf("expected RetriesExhaustedError with 3 attempts, got %v", err)
+	}
+	var statusErr *httpStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the last status error to be wrapped, got %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+
+	// 503 is not in the list, so it fails at once
+	attempts.Store(0)
+	status = http.StatusServiceUnavailable
+	_, err = client.DownloadAndInstall(context.Background(), asset, t.TempDir())
+	if err == nil || errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("expected a non-retried failure, got %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+// TestDownloadAndInstall_AttemptTimeout tests that an attempt exceeding
+// AttemptTimeout is retried while the caller's context is still live.
+func TestDownloadAndInstall_AttemptTimeout(t *testing.T) {
+	content := []byte("provider-content")
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{
+		HTTPClient: server.Client(),
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts:    2,
+			BaseDelay:      time.Millisecond,
+			AttemptTimeout: 50 * time.Millisecond,
+		},
+	})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider", Checksum: computeSHA256(content)}
+
+	if _, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir()); err != nil {
+		t.Fatalf("expected success on the second attempt, got %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
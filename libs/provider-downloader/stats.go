@@ -0,0 +1,138 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats counts the work a Client has done since it was created.
+type Stats struct {
+	// Requests is the number of HTTP requests sent, including retries and
+	// requests that failed.
+	Requests int64
+
+	// Retries is the number of download attempts repeated after a
+	// retryable failure.
+	Retries int64
+
+	// CacheHits and CacheMisses count binary cache lookups; both stay zero
+	// without ClientOptions.CacheDir.
+	CacheHits   int64
+	CacheMisses int64
+
+	// StoreHits counts installations served from ClientOptions.StoreDir
+	// without a download.
+	StoreHits int64
+
+	// Bytes is the number of response body bytes read, API responses
+	// included.
+	Bytes int64
+}
+
+// clientStats holds the counters behind Client.Stats.
+type clientStats struct {
+	requests    atomic.Int64
+	retries     atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+	storeHits   atomic.Int64
+	bytes       atomic.Int64
+}
+
+// Stats returns a snapshot of the client's counters. It is safe to call
+// while requests are in flight.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:    c.stats.requests.Load(),
+		Retries:     c.stats.retries.Load(),
+		CacheHits:   c.stats.cacheHits.Load(),
+		CacheMisses: c.stats.cacheMisses.Load(),
+		StoreHits:   c.stats.storeHits.Load(),
+		Bytes:       c.stats.bytes.Load(),
+	}
+}
+
+// hostLimiter bounds the number of requests in flight to each host.
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// acquire waits for a slot for host and returns the function that gives it
+// back. A nil or unlimited limiter returns at once.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil || l.limit <= 0 {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[host] = slots
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	release := func() { once.Do(func() { <-slots }) }
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// do sends req through the shared HTTP client, holding a per-host slot
+// until the response body is closed, counting the request and the body
+// bytes read, and applying the client's Bandwidth to the body.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	release, err := c.limiter.acquire(req.Context(), strings.ToLower(req.URL.Host))
+	if err != nil {
+		return nil, err
+	}
+	c.stats.requests.Add(1)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, bytes: &c.stats.bytes, release: release, ctx: req.Context(), bandwidth: c.bandwidth}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body, charges them to
+// the client's Bandwidth, and releases the request's host slot when
+// closed.
+type countingBody struct {
+	io.ReadCloser
+	bytes     *atomic.Int64
+	release   func()
+	ctx       context.Context
+	bandwidth *Bandwidth
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes.Add(int64(n))
+	if limitErr := b.bandwidth.take(b.ctx, n); limitErr != nil {
+		return n, limitErr
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,130 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestClient_ConcurrentDownloads verifies that one client serves parallel
+// downloads within its per-host limit and counts the work in Stats.
+func TestClient_ConcurrentDownloads(t *testing.T) {
+	content := []byte("fake-provider-binary")
+	var inFlight, maxInFlight atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{
+		HTTPClient:      server.Client(),
+		BaseURL:         server.URL,
+		CacheDir:        filepath.Join(t.TempDir(), "cache"),
+		MaxConnsPerHost: 2,
+	})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64", Checksum: computeSHA256(content)}
+
+	const downloads = 6
+	root := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, downloads)
+	for i := 0; i < downloads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := client.DownloadAndInstall(context.Background(), asset, filepath.Join(root, fmt.Sprint(i)))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if peak := maxInFlight.Load(); peak > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", peak)
+	}
+	stats := client.Stats()
+	if stats.Requests != downloads {
+		t.Errorf("expected %d requests, got %d", downloads, stats.Requests)
+	}
+	if stats.Bytes != int64(downloads*len(content)) {
+		t.Errorf("expected %d bytes, got %d", downloads*len(content), stats.Bytes)
+	}
+	if stats.CacheHits+stats.CacheMisses != downloads || stats.CacheMisses == 0 {
+		t.Errorf("expected %d cache lookups with at least one miss, got %+v", downloads, stats)
+	}
+}
+
+// TestClient_StatsRetries verifies that repeated download attempts are
+// counted as retries.
+func TestClient_StatsRetries(t *testing.T) {
+	content := []byte("fake-provider-binary")
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{
+		HTTPClient: server.Client(),
+		BaseURL:    server.URL,
+		RetryDelay: time.Millisecond,
+	})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64"}
+	if _, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stats := client.Stats()
+	if stats.Requests != 3 || stats.Retries != 2 {
+		t.Errorf("expected 3 requests and 2 retries, got %+v", stats)
+	}
+	if stats.CacheHits != 0 || stats.CacheMisses != 0 {
+		t.Errorf("expected no cache lookups without CacheDir, got %+v", stats)
+	}
+}
+
+// TestHostLimiter_ContextCanceled verifies that waiting for a host slot
+// stops when the context is canceled.
+func TestHostLimiter_ContextCanceled(t *testing.T) {
+	limiter := &hostLimiter{limit: 1, slots: make(map[string]chan struct{})}
+	release, err := limiter.acquire(context.Background(), "api.github.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.acquire(ctx, "api.github.com"); err == nil {
+		t.Error("expected error waiting for a busy host with a canceled context")
+	}
+	if other, err := limiter.acquire(ctx, "github.com"); err != nil {
+		t.Errorf("expected a free slot for another host, got %v", err)
+	} else {
+		other()
+	}
+}
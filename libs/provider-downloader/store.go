@@ -0,0 +1,229 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Ways a binary is placed from the store, reported in InstallResult.Link.
+const (
+	// LinkReflink is a copy-on-write clone sharing the store's blocks.
+	LinkReflink = "reflink"
+
+	// LinkHardlink is a hard link to the store object.
+	LinkHardlink = "hardlink"
+
+	// LinkCopy is a plain copy, used when the filesystem supports neither.
+	LinkCopy = "copy"
+)
+
+// The store set by ClientOptions.StoreDir is laid out as
+//
+//	objects/sha256/{hex[:2]}/{hex}   provider binaries, read-only
+//	assets/sha256/{hex}              the binary digest an archive extracted to
+//
+// so identical binaries shipped by several versions or providers are kept
+// once, and an asset whose digest is known installs without a download.
+
+// storeObjectPath returns the store path of the binary with the given
+// "sha256:<hex>" digest, and false for a malformed digest.
+func (c *Client) storeObjectPath(digest string) (string, bool) {
+	sum, ok := digestHex(digest)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(c.storeDir, "objects", "sha256", sum[:2], sum), true
+}
+
+// storeAssetPath returns the store path of the index entry for an archive
+// with the given digest.
+func (c *Client) storeAssetPath(digest string) (string, bool) {
+	sum, ok := digestHex(digest)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(c.storeDir, "assets", "sha256", sum), true
+}
+
+// digestHex returns the hex part of a "sha256:<hex>" digest.
+func digestHex(digest string) (string, bool) {
+	sum, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok || len(sum) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", false
+	}
+	return strings.ToLower(sum), true
+}
+
+// installFromStore installs the binary of an asset with a known digest
+// from the store, returning false when the store does not hold it. The
+// object is verified against its digest first; a corrupt object is removed
+// and reported as missing.
+func (c *Client) installFromStore(asset *AssetInfo, destDir string) (*InstallResult, bool) {
+	binaryDigest := asset.Checksum
+	if needsExtraction(asset.Name) {
+		indexPath, ok := c.storeAssetPath(asset.Checksum)
+		if !ok {
+			return nil, false
+		}
+		//nolint:gosec // G304: indexPath is inside the store directory
+		data, err := os.ReadFile(indexPath)
+		if err != nil {
+			return nil, false
+		}
+		binaryDigest = strings.TrimSpace(string(data))
+	}
+
+	if asset.BinaryChecksum != "" && asset.BinaryChecksum != binaryDigest {
+		return nil, false
+	}
+	objectPath, ok := c.storeObjectPath(binaryDigest)
+	if !ok {
+		return nil, false
+	}
+	actual, size, err := fileDigest(objectPath)
+	if err != nil {
+		return nil, false
+	}
+	if actual != binaryDigest {
+		c.debugf("Store object %s is corrupt (digest %s), removing it", objectPath, actual)
+		_ = os.Remove(objectPath)
+		return nil, false
+	}
+
+	finalPath := filepath.Join(destDir, "provider")
+	link, err := linkFromStore(objectPath, finalPath)
+	if err != nil {
+		c.debugf("Failed to install from store: %v", err)
+		return nil, false
+	}
+	c.debugf("Installed %s from store (%s)", binaryDigest, link)
+	return &InstallResult{Path: finalPath, Checksum: binaryDigest, Size: size, Link: link}, true
+}
+
+// addToStore moves the binary at path into the store under its digest,
+// unless the store already holds it, and records which binary an archive
+// with assetDigest extracted to. It returns the object's path.
+func (c *Client) addToStore(path, binaryDigest, assetDigest string) (string, error) {
+	objectPath, ok := c.storeObjectPath(binaryDigest)
+	if !ok {
+		return "", fmt.Errorf("invalid binary digest %q", binaryDigest)
+	}
+	//nolint:gosec // G301: Standard directory permissions (0755) are appropriate for store directories
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	// Objects are read-only, since hard links share them with every
+	// installation. An existing object with the same digest is identical.
+	if _, err := os.Stat(objectPath); err != nil {
+		//nolint:gosec // G302: Store objects must stay executable (0555) for hard-linked installations
+		if err := os.Chmod(path, 0555); err != nil {
+			return "", fmt.Errorf("failed to add to store: %w", err)
+		}
+		if err := os.Rename(path, objectPath); err != nil {
+			return "", fmt.Errorf("failed to add to store: %w", err)
+		}
+	}
+
+	if assetDigest != binaryDigest {
+		indexPath, ok := c.storeAssetPath(assetDigest)
+		if ok {
+			if err := writeStoreIndex(indexPath, binaryDigest); err != nil {
+				c.debugf("Failed to index asset in store: %v", err)
+			}
+		}
+	}
+	c.debugf("Stored %s: %s", binaryDigest, objectPath)
+	return objectPath, nil
+}
+
+// writeStoreIndex atomically writes binaryDigest to indexPath.
+func writeStoreIndex(indexPath, binaryDigest string) error {
+	//nolint:gosec // G301: Standard directory permissions (0755) are appropriate for store directories
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(indexPath), ".index-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(binaryDigest + "\n"); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), indexPath)
+}
+
+// linkFromStore places the store object at dest, replacing any file there,
+// by the cheapest method the filesystem supports: a reflink, then a hard
+// link, then a copy. It returns the method used.
+func linkFromStore(objectPath, dest string) (string, error) {
+	tmp := filepath.Join(filepath.Dir(dest), fmt.Sprintf(".%s-%d.tmp", filepath.Base(dest), os.Getpid()))
+	_ = os.Remove(tmp)
+
+	link := LinkReflink
+	if err := reflink(objectPath, tmp); err != nil {
+		_ = os.Remove(tmp)
+		link = LinkHardlink
+		if err := os.Link(objectPath, tmp); err != nil {
+			link = LinkCopy
+			if err := copyExecutable(objectPath, tmp); err != nil {
+				_ = os.Remove(tmp)
+				return "", fmt.Errorf("failed to install from store: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to install from store: %w", err)
+	}
+	return link, nil
+}
+
+// copyExecutable copies src to a new executable file at dst.
+func copyExecutable(src, dst string) error {
+	//nolint:gosec // G304: src is a store object
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	//nolint:gosec // G302: Executable permissions (0755) required for provider binaries
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// fileDigest returns the "sha256:<hex>" digest and size of a file.
+func fileDigest(path string) (string, int64, error) {
+	//nolint:gosec // G304: path is a store object
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// TestDownloadAndInstall_Store verifies that binaries are stored once by
+// digest and reinstalled from the store without a download.
+func TestDownloadAndInstall_Store(t *testing.T) {
+	content := []byte("#!/bin/sh\necho provider\n")
+	archive := createTarGzArchive(t, map[string][]byte{"provider": content})
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if filepath.Ext(r.URL.Path) == ".gz" {
+			_, _ = w.Write(archive)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	storeDir := t.TempDir()
+	client := NewClient(&ClientOptions{HTTPClient: server.Client(), StoreDir: storeDir})
+	raw := &AssetInfo{URL: server.URL + "/provider", Name: "provider-1.0.0-linux-amd64", Checksum: computeSHA256(content)}
+	tgz := &AssetInfo{URL: server.URL + "/provider.tar.gz", Name: "provider-1.1.0-linux-amd64.tar.gz", Checksum: computeSHA256(archive)}
+
+	// Two versions shipping the same binary share one object
+	first, err := client.DownloadAndInstall(context.Background(), raw, filepath.Join(t.TempDir(), "1.0.0"))
+	if err != nil {
+		t.Fatalf("DownloadAndInstall(raw) error = %v", err)
+	}
+	second, err := client.DownloadAndInstall(context.Background(), tgz, filepath.Join(t.TempDir(), "1.1.0"))
+	if err != nil {
+		t.Fatalf("DownloadAndInstall(tgz) error = %v", err)
+	}
+	if first.Link == "" || first.Checksum != second.Checksum {
+		t.Fatalf("results = %+v, %+v; want linked installs of one binary", first, second)
+	}
+	objects, _ := filepath.Glob(filepath.Join(storeDir, "objects", "sha256", "*", "*"))
+	if len(objects) != 1 {
+		t.Fatalf("store objects = %v, want one", objects)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("requests = %d, want 2", requests.Load())
+	}
+
+	// After the installations are removed, both assets reinstall from the
+	// store without a request
+	for _, asset := range []*AssetInfo{raw, tgz} {
+		destDir := filepath.Join(t.TempDir(), "again")
+		result, err := client.DownloadAndInstall(context.Background(), asset, destDir)
+		if err != nil {
+			t.Fatalf("reinstall %s: %v", asset.Name, err)
+		}
+		data, err := os.ReadFile(result.Path)
+		if err != nil || string(data) != string(content) {
+			t.Fatalf("reinstalled binary = %q, %v; want the provider", data, err)
+		}
+	}
+	if requests.Load() != 2 {
+		t.Errorf("requests = %d after reinstalls, want 2", requests.Load())
+	}
+	if stats := client.Stats(); stats.StoreHits != 2 {
+		t.Errorf("StoreHits = %d, want 2", stats.StoreHits)
+	}
+}
+
+// TestDownloadAndInstall_StoreCorruptObject verifies that an object that no
+// longer matches its digest is replaced by a fresh download.
+func TestDownloadAndInstall_StoreCorruptObject(t *testing.T) {
+	content := []byte("provider-binary")
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientOptions{HTTPClient: server.Client(), StoreDir: t.TempDir()})
+	asset := &AssetInfo{URL: server.URL + "/provider", Name: "provider-linux-amd64", Checksum: computeSHA256(content)}
+	if _, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir()); err != nil {
+		t.Fatalf("DownloadAndInstall() error = %v", err)
+	}
+
+	objectPath, _ := client.storeObjectPath(asset.Checksum)
+	if err := os.Chmod(objectPath, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(objectPath, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.DownloadAndInstall(context.Background(), asset, t.TempDir())
+	if err != nil {
+		t.Fatalf("DownloadAndInstall() error = %v", err)
+	}
+	if data, _ := os.ReadFile(result.Path); string(data) != string(content) || requests.Load() != 2 {
+		t.Errorf("installed %q after %d requests, want a fresh download", data, requests.Load())
+	}
+}
@@ -10,6 +10,21 @@ import (
 // total is the total number of bytes to download (0 if unknown).
 type ProgressCallback func(downloaded, total int64)
 
+// Release channels accepted as ProviderSpec.Version.
+const (
+	// ChannelLatest resolves to the newest stable release.
+	ChannelLatest = "latest"
+
+	// ChannelPrerelease resolves to the newest release, including pre-releases.
+	ChannelPrerelease = "prerelease"
+)
+
+// IsChannel reports whether version names a release channel rather than a
+// concrete release.
+func IsChannel(version string) bool {
+	return version == ChannelLatest || version == ChannelPrerelease
+}
+
 // ProviderSpec describes a provider binary to download from GitHub Releases.
 // It contains the repository information, version, and target platform.
 type ProviderSpec struct {
@@ -19,7 +34,8 @@ type ProviderSpec struct {
 	// Repo is the GitHub repository name (e.g., "nomos-provider-file").
 	Repo string
 
-	// Version is the semantic version or release tag (e.g., "1.0.0" or "v1.0.0").
+	// Version is the semantic version or release tag (e.g., "1.0.0" or "v1.0.0"),
+	// or a release channel (ChannelLatest or ChannelPrerelease).
 	// The resolver will normalize version formats automatically.
 	Version string
 
@@ -27,9 +43,25 @@ type ProviderSpec struct {
 	// If empty, runtime.GOOS is used for auto-detection.
 	OS string
 
-	// Arch is the target architecture (e.g., "amd64", "arm64").
-	// If empty, runtime.GOARCH is used for auto-detection.
+	// Arch is the target architecture (e.g., "amd64", "arm64", "armv7").
+	// If empty, runtime.GOARCH is used for auto-detection. On the running
+	// system, "arm" is narrowed to "armv6" or "armv7" with DetectARM.
 	Arch string
+
+	// Libc is the C library of a Linux target, LibcMusl or LibcGNU, which
+	// decides between "-musl" and "-gnu" suffixed assets. If empty, it is
+	// detected with DetectLibc when the target is the running system.
+	Libc string
+
+	// AllowYanked resolves releases marked yanked instead of returning
+	// ErrReleaseYanked.
+	AllowYanked bool
+
+	// TagPrefix is prepended to Version to form the release tag, for
+	// repositories that release several modules under prefixed tags such
+	// as "apps/command-line/v1.2.0". Channels then resolve to the newest
+	// release whose tag starts with TagPrefix.
+	TagPrefix string
 }
 
 // AssetInfo describes a resolved GitHub Release asset.
@@ -44,12 +76,36 @@ type AssetInfo struct {
 	// Size is the asset size in bytes.
 	Size int64
 
-	// Checksum is the SHA256 checksum if available from release notes.
-	// May be empty if checksum is not provided by the release.
+	// Checksum is the SHA256 checksum of the asset ("sha256:<hex>") if the
+	// release publishes an asset digest. May be empty if checksum is not
+	// provided by the release. Callers pinning a digest may set it before
+	// DownloadAndInstall, which then rejects any other content.
 	Checksum string
 
+	// BinaryChecksum, when set by the caller, is the SHA256 checksum
+	// ("sha256:<hex>") the installed binary must have: the asset itself, or
+	// the binary extracted from an archive asset. DownloadAndInstall rejects
+	// any other binary before installing it.
+	BinaryChecksum string
+
 	// ContentType is the MIME type of the asset.
 	ContentType string
+
+	// Version is the tag of the release the asset belongs to, without the
+	// spec's TagPrefix. For channel specs it is the concrete release the
+	// channel resolved to.
+	Version string
+
+	// Status is the lifecycle status the release declares in its
+	// ReleaseManifestAssetName asset, and StatusMessage its explanation.
+	Status        ReleaseStatus
+	StatusMessage string
+
+	// FallbackArch is the architecture the asset was built for when the
+	// release has none for the target architecture and
+	// ClientOptions.ArchFallbacks supplied this one instead, such as an
+	// amd64 build run by Rosetta on Apple Silicon. Empty otherwise.
+	FallbackArch string
 }
 
 // InstallResult contains the result of a successful installation.
@@ -62,6 +118,10 @@ type InstallResult struct {
 
 	// Size is the size of the installed binary in bytes.
 	Size int64
+
+	// Link is how the binary was placed from ClientOptions.StoreDir:
+	// LinkReflink, LinkHardlink, or LinkCopy. Empty without a store.
+	Link string
 }
 
 // Logger is an optional interface for debug logging.
@@ -83,19 +143,47 @@ type ClientOptions struct {
 	HTTPClient *http.Client
 
 	// RetryAttempts is the number of retry attempts for failed downloads.
+	// Ignored when RetryPolicy is set.
 	// Default: 3
 	RetryAttempts int
 
 	// RetryDelay is the initial delay between retry attempts.
 	// Exponential backoff is applied on subsequent retries.
+	// Ignored when RetryPolicy is set.
 	// Default: 1 second
 	RetryDelay time.Duration
 
-	// BaseURL is the GitHub API base URL.
+	// RetryPolicy configures download retries in full: attempts, backoff,
+	// retryable status codes, and per-attempt timeout. If nil, the policy is
+	// built from RetryAttempts and RetryDelay.
+	RetryPolicy *RetryPolicy
+
+	// BaseURL is the GitHub API base URL, used as given.
 	// Default: "https://api.github.com"
-	// Can be overridden for testing or GitHub Enterprise.
+	// GitHub Enterprise Server serves the API below /api/v3 on its own host
+	// (e.g. "https://ghe.example.com/api/v3"); EnterpriseURLs derives it from
+	// the server URL.
 	BaseURL string
 
+	// UploadURL is the GitHub uploads API base URL of the same instance. The
+	// downloader never uploads; the value is reported by Client.UploadURL for
+	// callers that publish providers to the instance they download from.
+	// Default: derived from BaseURL ("https://uploads.github.com" for
+	// github.com, ".../api/uploads" for a BaseURL ending in /api/v3).
+	UploadURL string
+
+	// HostTokens maps host names (optionally with a port) to the token sent
+	// to that host, for setups that download from several GitHub instances.
+	// Requests to hosts not listed use GitHubToken.
+	HostTokens map[string]string
+
+	// TokenSource supplies tokens for hosts that have none in HostTokens when
+	// GitHubToken is empty, e.g. DefaultTokenSource to read GITHUB_TOKEN, a
+	// credential helper, the GitHub CLI's configuration, or .netrc. Tokens
+	// are looked up once per host and client. If nil, such requests are
+	// unauthenticated.
+	TokenSource TokenSource
+
 	// Logger is an optional logger for debug output.
 	// If nil, no debug logging is performed.
 	Logger Logger
@@ -105,13 +193,44 @@ type ClientOptions struct {
 	// Cache key is based on the asset checksum.
 	CacheDir string
 
+	// StoreDir is an optional content-addressable store of provider
+	// binaries, keyed by digest and shared between projects. Installations
+	// are reflinked or hard-linked from it, so identical binaries are kept
+	// once, and an asset whose AssetInfo.Checksum the store already holds
+	// installs without a download. Objects are read-only.
+	StoreDir string
+
 	// HTTPTimeout is the timeout for HTTP requests.
 	// Default: 30 seconds
 	HTTPTimeout time.Duration
 
+	// MaxConnsPerHost limits the requests the client has in flight to each
+	// host, across all goroutines using it; further requests wait for a
+	// slot. Zero means no limit.
+	MaxConnsPerHost int
+
+	// Bandwidth limits the download rate and total bytes downloaded, for
+	// example for CI environments that meter egress. Share one Bandwidth
+	// between clients to cap them together. If nil, downloads are
+	// unlimited.
+	Bandwidth *Bandwidth
+
+	// StrictAssetNames makes ResolveAsset accept only canonical asset names
+	// (see AssetName), preferring a raw binary over an archive, instead of
+	// falling back to legacy patterns and substring matching.
+	StrictAssetNames bool
+
+	// ArchFallbacks maps a target "os/arch" to the architectures whose
+	// assets the resolver tries, in order, when a release has no asset for
+	// the target itself, for example RosettaArchFallbacks. The resolved
+	// AssetInfo reports the architecture used in FallbackArch. If nil, a
+	// missing asset is an error.
+	ArchFallbacks map[string][]string
+
 	// ProgressCallback is an optional callback for download progress updates.
 	// Called periodically during download with bytes downloaded and total size.
-	// If nil, no progress reporting is performed.
+	// Concurrent downloads call it from their own goroutines, so it must be
+	// safe for concurrent use. If nil, no progress reporting is performed.
 	ProgressCallback ProgressCallback
 }
 
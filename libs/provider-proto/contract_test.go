@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -29,6 +30,34 @@ func TestInitRequest_MessageStructure(t *testing.T) {
 	}
 }
 
+// TestInitResponse_Defaults verifies that published defaults survive a
+// wire round trip.
+func TestInitResponse_Defaults(t *testing.T) {
+	defaults, err := structpb.NewStruct(map[string]any{
+		"database": map[string]any{"port": 5432, "ssl": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wire, err := proto.Marshal(&providerv1.InitResponse{Defaults: defaults})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp providerv1.InitResponse
+	if err := proto.Unmarshal(wire, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	database, _ := resp.GetDefaults().AsMap()["database"].(map[string]any)
+	if database["port"] != 5432.0 || database["ssl"] != true {
+		t.Errorf("defaults = %v, want database.port 5432 and database.ssl true", resp.GetDefaults().AsMap())
+	}
+	if (&providerv1.InitResponse{}).GetDefaults() != nil {
+		t.Error("expected unset defaults to be nil")
+	}
+}
+
 // TestHealthResponse_StatusEnum verifies the health status enum values.
 func TestHealthResponse_StatusEnum(t *testing.T) {
 	tests := []struct {
@@ -152,9 +152,15 @@ func (x *InitRequest) GetSourceFilePath() string {
 }
 
 // InitResponse indicates successful provider initialization.
-// Currently empty but reserved for future extension.
 type InitResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// defaults contains default values the provider publishes for
+	// configuration paths, keyed by top-level configuration key.
+	// The compiler merges them beneath the compiled data at the lowest
+	// precedence: values from source files, references, and overrides win,
+	// and maps merge key by key. Keys taken from defaults are attributed to
+	// "provider-default" in provenance. Optional; most providers leave it unset.
+	Defaults      *structpb.Struct `protobuf:"bytes,1,opt,name=defaults,proto3" json:"defaults,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -189,6 +195,13 @@ func (*InitResponse) Descriptor() ([]byte, []int) {
 	return file_nomos_provider_v1_provider_proto_rawDescGZIP(), []int{1}
 }
 
+func (x *InitResponse) GetDefaults() *structpb.Struct {
+	if x != nil {
+		return x.Defaults
+	}
+	return nil
+}
+
 // FetchRequest specifies the data path to retrieve.
 type FetchRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -567,8 +580,9 @@ const file_nomos_provider_v1_provider_proto_rawDesc = "" +
 	"\vInitRequest\x12\x14\n" +
 	"\x05alias\x18\x01 \x01(\tR\x05alias\x12/\n" +
 	"\x06config\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06config\x12(\n" +
-	"\x10source_file_path\x18\x03 \x01(\tR\x0esourceFilePathJ\x04\b\x04\x10\v\"\x14\n" +
-	"\fInitResponseJ\x04\b\x01\x10\v\"(\n" +
+	"\x10source_file_path\x18\x03 \x01(\tR\x0esourceFilePathJ\x04\b\x04\x10\v\"I\n" +
+	"\fInitResponse\x123\n" +
+	"\bdefaults\x18\x01 \x01(\v2\x17.google.protobuf.StructR\bdefaultsJ\x04\b\x02\x10\v\"(\n" +
 	"\fFetchRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x03(\tR\x04pathJ\x04\b\x02\x10\v\"D\n" +
 	"\rFetchResponse\x12-\n" +
@@ -627,23 +641,24 @@ var file_nomos_provider_v1_provider_proto_goTypes = []any{
 }
 var file_nomos_provider_v1_provider_proto_depIdxs = []int32{
 	11, // 0: nomos.provider.v1.InitRequest.config:type_name -> google.protobuf.Struct
-	11, // 1: nomos.provider.v1.FetchResponse.value:type_name -> google.protobuf.Struct
-	0,  // 2: nomos.provider.v1.HealthResponse.status:type_name -> nomos.provider.v1.HealthResponse.Status
-	1,  // 3: nomos.provider.v1.ProviderService.Init:input_type -> nomos.provider.v1.InitRequest
-	3,  // 4: nomos.provider.v1.ProviderService.Fetch:input_type -> nomos.provider.v1.FetchRequest
-	5,  // 5: nomos.provider.v1.ProviderService.Info:input_type -> nomos.provider.v1.InfoRequest
-	7,  // 6: nomos.provider.v1.ProviderService.Health:input_type -> nomos.provider.v1.HealthRequest
-	9,  // 7: nomos.provider.v1.ProviderService.Shutdown:input_type -> nomos.provider.v1.ShutdownRequest
-	2,  // 8: nomos.provider.v1.ProviderService.Init:output_type -> nomos.provider.v1.InitResponse
-	4,  // 9: nomos.provider.v1.ProviderService.Fetch:output_type -> nomos.provider.v1.FetchResponse
-	6,  // 10: nomos.provider.v1.ProviderService.Info:output_type -> nomos.provider.v1.InfoResponse
-	8,  // 11: nomos.provider.v1.ProviderService.Health:output_type -> nomos.provider.v1.HealthResponse
-	10, // 12: nomos.provider.v1.ProviderService.Shutdown:output_type -> nomos.provider.v1.ShutdownResponse
-	8,  // [8:13] is the sub-list for method output_type
-	3,  // [3:8] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	11, // 1: nomos.provider.v1.InitResponse.defaults:type_name -> google.protobuf.Struct
+	11, // 2: nomos.provider.v1.FetchResponse.value:type_name -> google.protobuf.Struct
+	0,  // 3: nomos.provider.v1.HealthResponse.status:type_name -> nomos.provider.v1.HealthResponse.Status
+	1,  // 4: nomos.provider.v1.ProviderService.Init:input_type -> nomos.provider.v1.InitRequest
+	3,  // 5: nomos.provider.v1.ProviderService.Fetch:input_type -> nomos.provider.v1.FetchRequest
+	5,  // 6: nomos.provider.v1.ProviderService.Info:input_type -> nomos.provider.v1.InfoRequest
+	7,  // 7: nomos.provider.v1.ProviderService.Health:input_type -> nomos.provider.v1.HealthRequest
+	9,  // 8: nomos.provider.v1.ProviderService.Shutdown:input_type -> nomos.provider.v1.ShutdownRequest
+	2,  // 9: nomos.provider.v1.ProviderService.Init:output_type -> nomos.provider.v1.InitResponse
+	4,  // 10: nomos.provider.v1.ProviderService.Fetch:output_type -> nomos.provider.v1.FetchResponse
+	6,  // 11: nomos.provider.v1.ProviderService.Info:output_type -> nomos.provider.v1.InfoResponse
+	8,  // 12: nomos.provider.v1.ProviderService.Health:output_type -> nomos.provider.v1.HealthResponse
+	10, // 13: nomos.provider.v1.ProviderService.Shutdown:output_type -> nomos.provider.v1.ShutdownResponse
+	9,  // [9:14] is the sub-list for method output_type
+	4,  // [4:9] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_nomos_provider_v1_provider_proto_init() }
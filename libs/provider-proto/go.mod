@@ -3,6 +3,7 @@ module github.com/autonomous-bits/nomos/libs/provider-proto
 go 1.26.0
 
 require (
+	github.com/autonomous-bits/nomos/libs/provider-proto/gen/go v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.76.0
 	google.golang.org/protobuf v1.36.10
 )
@@ -13,3 +14,5 @@ require (
 	golang.org/x/text v0.27.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
 )
+
+replace github.com/autonomous-bits/nomos/libs/provider-proto/gen/go => ./gen/go
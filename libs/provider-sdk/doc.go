@@ -0,0 +1,52 @@
+// Package providersdk implements the provider side of the Nomos provider
+// protocol, so provider authors write only how to load and fetch their data.
+//
+// The compiler starts a provider binary, reads the PROVIDER_PORT=<port>
+// line it prints on stdout, and calls the gRPC service defined in
+// libs/provider-proto on that loopback port. Serve handles all of it: the
+// listener and handshake, Info and Health, rejecting Fetch before Init,
+// mapping errors to the gRPC status codes the compiler expects, and
+// stopping gracefully on the Shutdown RPC or an interrupt.
+//
+// # Basic Usage
+//
+//	type files struct{ data map[string]any }
+//
+//	func (f *files) Init(ctx context.Context, req providersdk.InitRequest) error {
+//		var cfg struct {
+//			Directory string `json:"directory"`
+//		}
+//		if err := req.Config.Decode(&cfg); err != nil {
+//			return err
+//		}
+//		f.data = load(cfg.Directory)
+//		return nil
+//	}
+//
+//	func (f *files) Fetch(ctx context.Context, path []string) (any, error) {
+//		value, ok := lookup(f.data, path)
+//		if !ok {
+//			return nil, fmt.Errorf("%w: %s", providersdk.ErrNotFound, strings.Join(path, "."))
+//		}
+//		return value, nil
+//	}
+//
+//	func main() {
+//		err := providersdk.Serve(&files{}, providersdk.Options{Type: "files", Version: version})
+//		if err != nil {
+//			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+//			os.Exit(1)
+//		}
+//	}
+//
+// # WASM
+//
+// A provider may instead be built as a WASM module, which the compiler runs
+// in-process on every platform: see ServeWASM.
+//
+// # Logging
+//
+// Stdout carries the handshake and must not be written to. Providers log to
+// stderr, which the compiler forwards to the user; Options.Logger, a text
+// slog.Logger on stderr by default, is the logger Serve itself uses.
+package providersdk
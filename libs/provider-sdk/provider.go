@@ -0,0 +1,83 @@
+package providersdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Errors a provider wraps to choose the gRPC status code the compiler sees.
+// Errors wrapping none of them, and not already gRPC status errors, are
+// reported as Internal.
+var (
+	// ErrNotFound reports that a fetched path does not exist (NotFound).
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidConfig reports a malformed or invalid source configuration
+	// (InvalidArgument).
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrUnavailable reports that the data source cannot be reached right
+	// now (Unavailable).
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Provider is implemented by provider authors. Serve calls Init once per
+// source declaration before any Fetch, and never calls Fetch concurrently
+// with Init.
+type Provider interface {
+	// Init configures the provider from its source declaration.
+	Init(ctx context.Context, req InitRequest) error
+
+	// Fetch returns the value at path. Maps are returned as they are; any
+	// other value is returned to the compiler as {"value": v}. Values must
+	// be strings, numbers, bools, nil, or maps and slices of them.
+	Fetch(ctx context.Context, path []string) (any, error)
+}
+
+// HealthChecker is implemented by providers that can report they are not
+// fully ready. A non-nil error is reported as degraded with its message.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// Defaulter is implemented by providers that publish default values for
+// the source declaration. Defaults is called after a successful Init.
+type Defaulter interface {
+	Defaults() map[string]any
+}
+
+// InitRequest holds the source declaration a provider is initialized from.
+type InitRequest struct {
+	// Alias is the source alias, such as "configs".
+	Alias string
+
+	// SourceFilePath is the .csl file declaring the source, for resolving
+	// relative paths.
+	SourceFilePath string
+
+	// Config holds the declaration's keys other than alias, type, and
+	// version.
+	Config Config
+}
+
+// Config is the configuration of a source declaration.
+type Config map[string]any
+
+// Decode stores the configuration in the struct pointed to by v, using its
+// json tags. Keys v has no field for are an error, so misspelled keys are
+// reported instead of ignored. Errors wrap ErrInvalidConfig.
+func (c Config) Decode(v any) error {
+	data, err := json.Marshal(map[string]any(c))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package providersdk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfig_Decode(t *testing.T) {
+	var cfg struct {
+		Directory string            `json:"directory"`
+		Recursive bool              `json:"recursive"`
+		Options   map[string]string `json:"options"`
+	}
+	config := Config{"directory": "./data", "recursive": true, "options": map[string]any{"ext": "yaml"}}
+	if err := config.Decode(&cfg); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if cfg.Directory != "./data" || !cfg.Recursive || cfg.Options["ext"] != "yaml" {
+		t.Errorf("Decode() = %+v", cfg)
+	}
+
+	for name, config := range map[string]Config{
+		"unknown key": {"directroy": "./data"},
+		"wrong type":  {"recursive": "yes"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := config.Decode(&cfg); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Decode() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package providersdk
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc"
+)
+
+// Options configures Serve.
+type Options struct {
+	// Type is the provider type reported by Info, such as "consul".
+	Type string
+
+	// Version is the provider version reported by Info. The compiler and
+	// 'nomos providers doctor' compare it with the locked version, so set
+	// it at build time, for example with -ldflags "-X main.version=...".
+	Version string
+
+	// Logger receives the SDK's own log records (default: text records of
+	// level Info and above on stderr).
+	Logger *slog.Logger
+
+	// Stdout receives the PROVIDER_PORT handshake line (default os.Stdout).
+	Stdout io.Writer
+
+	// ServerOptions are passed to grpc.NewServer, for example to add
+	// interceptors.
+	ServerOptions []grpc.ServerOption
+}
+
+// withDefaults fills in the unset options.
+func (o Options) withDefaults() Options {
+	if o.Logger == nil {
+		o.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	if o.Stdout == nil {
+		o.Stdout = os.Stdout
+	}
+	return o
+}
+
+// Serve runs p as a provider process: it listens on a loopback port,
+// prints PROVIDER_PORT=<port> for the compiler, and serves the provider
+// service until the compiler calls Shutdown or the process receives an
+// interrupt or SIGTERM. In-flight calls finish before Serve returns nil.
+func Serve(p Provider, opts Options) error {
+	opts = opts.withDefaults()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	s := NewServer(p, opts)
+	server := grpc.NewServer(opts.ServerOptions...)
+	providerv1.RegisterProviderServiceServer(server, s)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-s.Done():
+		case sig := <-signals:
+			opts.Logger.Info("stopping", "signal", sig.String())
+		}
+		server.GracefulStop()
+	}()
+
+	if _, err := fmt.Fprintf(opts.Stdout, "PROVIDER_PORT=%d\n", listener.Addr().(*net.TCPAddr).Port); err != nil {
+		server.Stop()
+		return fmt.Errorf("failed to write handshake: %w", err)
+	}
+	return server.Serve(listener)
+}
@@ -0,0 +1,72 @@
+package providersdk
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TestServe verifies the handshake and that Serve returns after the
+// Shutdown RPC.
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdoutR, stdoutW := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(&mapProvider{data: map[string]any{"app": map[string]any{"name": "demo"}}}, Options{
+			Type:    "maps",
+			Version: "1.2.0",
+			Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Stdout:  stdoutW,
+		})
+	}()
+
+	line, err := bufio.NewReader(stdoutR).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading handshake: %v", err)
+	}
+	port, ok := strings.CutPrefix(strings.TrimSpace(line), "PROVIDER_PORT=")
+	if !ok {
+		t.Fatalf("handshake = %q, want PROVIDER_PORT=<port>", line)
+	}
+
+	conn, err := grpc.NewClient("127.0.0.1:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := providerv1.NewProviderServiceClient(conn)
+
+	if _, err := client.Init(ctx, &providerv1.InitRequest{Alias: "configs"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	resp, err := client.Fetch(ctx, &providerv1.FetchRequest{Path: []string{"app", "name"}})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := resp.GetValue().AsMap()["value"]; got != "demo" {
+		t.Errorf("Fetch() value = %v, want demo", got)
+	}
+	if _, err := client.Shutdown(ctx, &providerv1.ShutdownRequest{}); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Serve() did not return after Shutdown")
+	}
+}
@@ -0,0 +1,139 @@
+package providersdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Server adapts a Provider to the provider gRPC service. Serve creates one;
+// use NewServer directly only to register the service on a gRPC server of
+// your own.
+type Server struct {
+	providerv1.UnimplementedProviderServiceServer
+
+	provider Provider
+	opts     Options
+
+	shutdown chan struct{}
+	once     sync.Once
+
+	mu          sync.RWMutex
+	alias       string
+	initialized bool
+}
+
+// NewServer returns a Server for p. Options.Type and Options.Version are
+// reported by Info.
+func NewServer(p Provider, opts Options) *Server {
+	return &Server{provider: p, opts: opts.withDefaults(), shutdown: make(chan struct{})}
+}
+
+// Init implements providerv1.ProviderServiceServer.
+func (s *Server) Init(ctx context.Context, req *providerv1.InitRequest) (*providerv1.InitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.provider.Init(ctx, InitRequest{
+		Alias:          req.GetAlias(),
+		SourceFilePath: req.GetSourceFilePath(),
+		Config:         Config(req.GetConfig().AsMap()),
+	})
+	if err != nil {
+		s.opts.Logger.Error("init failed", "alias", req.GetAlias(), "error", err)
+		return nil, statusError(err)
+	}
+	s.alias = req.GetAlias()
+	s.initialized = true
+
+	resp := &providerv1.InitResponse{}
+	if d, ok := s.provider.(Defaulter); ok {
+		if defaults := d.Defaults(); defaults != nil {
+			resp.Defaults, err = structpb.NewStruct(defaults)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "invalid defaults: %v", err)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// Fetch implements providerv1.ProviderServiceServer.
+func (s *Server) Fetch(ctx context.Context, req *providerv1.FetchRequest) (*providerv1.FetchResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.initialized {
+		return nil, status.Error(codes.FailedPrecondition, "provider not initialized")
+	}
+
+	value, err := s.provider.Fetch(ctx, req.GetPath())
+	if err != nil {
+		s.opts.Logger.Debug("fetch failed", "path", req.GetPath(), "error", err)
+		return nil, statusError(err)
+	}
+	folder, ok := value.(map[string]any)
+	if !ok {
+		folder = map[string]any{"value": value}
+	}
+	result, err := structpb.NewStruct(folder)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid value: %v", err)
+	}
+	return &providerv1.FetchResponse{Value: result}, nil
+}
+
+// Info implements providerv1.ProviderServiceServer.
+func (s *Server) Info(context.Context, *providerv1.InfoRequest) (*providerv1.InfoResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &providerv1.InfoResponse{Alias: s.alias, Version: s.opts.Version, Type: s.opts.Type}, nil
+}
+
+// Health implements providerv1.ProviderServiceServer.
+func (s *Server) Health(ctx context.Context, _ *providerv1.HealthRequest) (*providerv1.HealthResponse, error) {
+	if h, ok := s.provider.(HealthChecker); ok {
+		if err := h.Health(ctx); err != nil {
+			return &providerv1.HealthResponse{Status: providerv1.HealthResponse_STATUS_DEGRADED, Message: err.Error()}, nil
+		}
+	}
+	return &providerv1.HealthResponse{Status: providerv1.HealthResponse_STATUS_OK}, nil
+}
+
+// Shutdown implements providerv1.ProviderServiceServer. It closes Done so
+// the gRPC server can stop once the response is sent.
+func (s *Server) Shutdown(context.Context, *providerv1.ShutdownRequest) (*providerv1.ShutdownResponse, error) {
+	s.once.Do(func() { close(s.shutdown) })
+	return &providerv1.ShutdownResponse{}, nil
+}
+
+// Done is closed when the compiler requests a shutdown.
+func (s *Server) Done() <-chan struct{} {
+	return s.shutdown
+}
+
+// statusError converts a provider error to the gRPC status the compiler
+// classifies, keeping errors that already carry a status.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrInvalidConfig):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
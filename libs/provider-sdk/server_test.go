@@ -0,0 +1,167 @@
+package providersdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// mapProvider serves a fixed map after Init and reports it is degraded
+// until warm is set.
+type mapProvider struct {
+	data    map[string]any
+	initReq InitRequest
+	warm    bool
+}
+
+func (p *mapProvider) Init(_ context.Context, req InitRequest) error {
+	var cfg struct {
+		Region string `json:"region"`
+	}
+	if err := req.Config.Decode(&cfg); err != nil {
+		return err
+	}
+	if cfg.Region == "unreachable" {
+		return fmt.Errorf("%w: region %s", ErrUnavailable, cfg.Region)
+	}
+	p.initReq = req
+	return nil
+}
+
+func (p *mapProvider) Fetch(_ context.Context, path []string) (any, error) {
+	var current any = p.data
+	for _, segment := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(path, "."))
+		}
+		if current, ok = m[segment]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(path, "."))
+		}
+	}
+	return current, nil
+}
+
+func (p *mapProvider) Health(context.Context) error {
+	if !p.warm {
+		return errors.New("cache is cold")
+	}
+	return nil
+}
+
+func (p *mapProvider) Defaults() map[string]any {
+	return map[string]any{"timeout": "30s"}
+}
+
+func newTestServer(p Provider) *Server {
+	return NewServer(p, Options{Type: "maps", Version: "1.2.0", Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	p := &mapProvider{data: map[string]any{"app": map[string]any{"name": "demo", "port": 8080.0}}}
+	s := newTestServer(p)
+
+	if _, err := s.Fetch(ctx, &providerv1.FetchRequest{Path: []string{"app"}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Fetch() before Init error = %v, want FailedPrecondition", err)
+	}
+
+	config, _ := structpb.NewStruct(map[string]any{"region": "eu"})
+	resp, err := s.Init(ctx, &providerv1.InitRequest{Alias: "configs", Config: config, SourceFilePath: "app.csl"})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if got := resp.GetDefaults().AsMap(); !reflect.DeepEqual(got, map[string]any{"timeout": "30s"}) {
+		t.Errorf("Init() defaults = %v", got)
+	}
+	if want := (InitRequest{Alias: "configs", SourceFilePath: "app.csl", Config: Config{"region": "eu"}}); !reflect.DeepEqual(p.initReq, want) {
+		t.Errorf("provider Init got %+v, want %+v", p.initReq, want)
+	}
+
+	fetched, err := s.Fetch(ctx, &providerv1.FetchRequest{Path: []string{"app"}})
+	if err != nil {
+		t.Fatalf("Fetch(app) error = %v", err)
+	}
+	if got := fetched.GetValue().AsMap(); !reflect.DeepEqual(got, p.data["app"]) {
+		t.Errorf("Fetch(app) = %v", got)
+	}
+	fetched, err = s.Fetch(ctx, &providerv1.FetchRequest{Path: []string{"app", "name"}})
+	if err != nil {
+		t.Fatalf("Fetch(app.name) error = %v", err)
+	}
+	if got := fetched.GetValue().AsMap(); !reflect.DeepEqual(got, map[string]any{"value": "demo"}) {
+		t.Errorf("Fetch(app.name) = %v, want the scalar under \"value\"", got)
+	}
+
+	info, _ := s.Info(ctx, &providerv1.InfoRequest{})
+	if info.GetAlias() != "configs" || info.GetType() != "maps" || info.GetVersion() != "1.2.0" {
+		t.Errorf("Info() = %v", info)
+	}
+
+	health, _ := s.Health(ctx, &providerv1.HealthRequest{})
+	if health.GetStatus() != providerv1.HealthResponse_STATUS_DEGRADED || health.GetMessage() != "cache is cold" {
+		t.Errorf("Health() = %v, want degraded", health)
+	}
+	p.warm = true
+	if health, _ = s.Health(ctx, &providerv1.HealthRequest{}); health.GetStatus() != providerv1.HealthResponse_STATUS_OK {
+		t.Errorf("Health() = %v, want ok", health)
+	}
+
+	if _, err := s.Shutdown(ctx, &providerv1.ShutdownRequest{}); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case <-s.Done():
+	default:
+		t.Error("Done() not closed after Shutdown")
+	}
+}
+
+func TestServer_ErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(&mapProvider{data: map[string]any{}})
+
+	for name, tt := range map[string]struct {
+		config map[string]any
+		want   codes.Code
+	}{
+		"invalid config": {map[string]any{"regoin": "eu"}, codes.InvalidArgument},
+		"unavailable":    {map[string]any{"region": "unreachable"}, codes.Unavailable},
+	} {
+		config, _ := structpb.NewStruct(tt.config)
+		if _, err := s.Init(ctx, &providerv1.InitRequest{Config: config}); status.Code(err) != tt.want {
+			t.Errorf("%s: Init() error = %v, want %s", name, err, tt.want)
+		}
+	}
+
+	if _, err := s.Init(ctx, &providerv1.InitRequest{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	_, err := s.Fetch(ctx, &providerv1.FetchRequest{Path: []string{"missing"}})
+	if status.Code(err) != codes.NotFound || !strings.Contains(status.Convert(err).Message(), "missing") {
+		t.Errorf("Fetch(missing) error = %v, want NotFound naming the path", err)
+	}
+
+	for _, tt := range []struct {
+		err  error
+		want codes.Code
+	}{
+		{status.Error(codes.PermissionDenied, "no access"), codes.PermissionDenied},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{errors.New("boom"), codes.Internal},
+	} {
+		if got := status.Code(statusError(tt.err)); got != tt.want {
+			t.Errorf("statusError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+//go:build wasip1
+
+package providersdk
+
+import (
+	"context"
+	"unsafe"
+
+	providerv1 "github.com/autonomous-bits/nomos/libs/provider-proto/gen/go/nomos/provider/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// wasmABIVersion is the version of the module interface below, returned by
+// nomos_abi_version. The compiler refuses modules reporting another one.
+const wasmABIVersion = 1
+
+var (
+	// wasmServer serves the calls exported below; ServeWASM sets it.
+	wasmServer *Server
+
+	// wasmBuffers keeps the buffers handed to the host alive until it
+	// frees them, keyed by address.
+	wasmBuffers = map[uint32][]byte{}
+)
+
+// ServeWASM makes p the provider of a module built with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o provider.wasm
+//
+// The compiler runs such a module in-process, in a WASI sandbox without
+// filesystem, network, or environment access, and calls the provider
+// through functions the module exports instead of gRPC. A c-shared module
+// runs package initializers but not main, so call ServeWASM from an init
+// function:
+//
+//	func init() {
+//		providersdk.ServeWASM(&files{}, providersdk.Options{Type: "files", Version: version})
+//	}
+//
+//	func main() {}
+//
+// Options.Stdout and Options.ServerOptions are not used.
+func ServeWASM(p Provider, opts Options) {
+	wasmServer = NewServer(p, opts)
+}
+
+//go:wasmexport nomos_abi_version
+func wasmABI() uint32 {
+	return wasmABIVersion
+}
+
+// wasmAlloc returns a buffer of size bytes for the host to write a request
+// to.
+//
+//go:wasmexport nomos_alloc
+func wasmAlloc(size uint32) uint32 {
+	return wasmPin(make([]byte, max(size, 1)))
+}
+
+// wasmFree releases a buffer returned by nomos_alloc or by a call.
+//
+//go:wasmexport nomos_free
+func wasmFree(ptr uint32) {
+	delete(wasmBuffers, ptr)
+}
+
+// The calls below take a serialized request message and return the
+// address and length of their result, packed as ptr<<32 | len. The result
+// is a gRPC status code byte followed by the serialized response message
+// for codes.OK, or by the error message otherwise.
+
+//go:wasmexport nomos_init
+func wasmInit(ptr, size uint32) uint64 {
+	req := &providerv1.InitRequest{}
+	if err := wasmRequest(ptr, size, req); err != nil {
+		return wasmResult(nil, err)
+	}
+	return wasmResult(wasmServer.Init(context.Background(), req))
+}
+
+//go:wasmexport nomos_fetch
+func wasmFetch(ptr, size uint32) uint64 {
+	req := &providerv1.FetchRequest{}
+	if err := wasmRequest(ptr, size, req); err != nil {
+		return wasmResult(nil, err)
+	}
+	return wasmResult(wasmServer.Fetch(context.Background(), req))
+}
+
+//go:wasmexport nomos_info
+func wasmInfo(ptr, size uint32) uint64 {
+	req := &providerv1.InfoRequest{}
+	if err := wasmRequest(ptr, size, req); err != nil {
+		return wasmResult(nil, err)
+	}
+	return wasmResult(wasmServer.Info(context.Background(), req))
+}
+
+//go:wasmexport nomos_health
+func wasmHealth(ptr, size uint32) uint64 {
+	req := &providerv1.HealthRequest{}
+	if err := wasmRequest(ptr, size, req); err != nil {
+		return wasmResult(nil, err)
+	}
+	return wasmResult(wasmServer.Health(context.Background(), req))
+}
+
+//go:wasmexport nomos_shutdown
+func wasmShutdown(ptr, size uint32) uint64 {
+	req := &providerv1.ShutdownRequest{}
+	if err := wasmRequest(ptr, size, req); err != nil {
+		return wasmResult(nil, err)
+	}
+	return wasmResult(wasmServer.Shutdown(context.Background(), req))
+}
+
+// wasmRequest decodes the request the host wrote to the buffer at ptr,
+// which nomos_alloc returned.
+func wasmRequest(ptr, size uint32, req proto.Message) error {
+	if wasmServer == nil {
+		return status.Error(codes.FailedPrecondition, "no provider registered: call providersdk.ServeWASM from an init function")
+	}
+	buf, ok := wasmBuffers[ptr]
+	if !ok || int(size) > len(buf) {
+		return status.Errorf(codes.InvalidArgument, "request is not in a buffer from nomos_alloc")
+	}
+	if err := proto.Unmarshal(buf[:size], req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	return nil
+}
+
+// wasmResult encodes the result of a call and pins it for the host, which
+// frees it with nomos_free.
+func wasmResult(resp proto.Message, err error) uint64 {
+	var out []byte
+	if err == nil {
+		var data []byte
+		if data, err = proto.Marshal(resp); err == nil {
+			out = append([]byte{byte(codes.OK)}, data...)
+		}
+	}
+	if err != nil {
+		st := status.Convert(err)
+		out = append([]byte{byte(st.Code())}, st.Message()...)
+	}
+	return uint64(wasmPin(out))<<32 | uint64(len(out))
+}
+
+// wasmPin keeps buf alive until nomos_free and returns its address.
+func wasmPin(buf []byte) uint32 {
+	ptr := uint32(uintptr(unsafe.Pointer(unsafe.SliceData(buf))))
+	wasmBuffers[ptr] = buf
+	return ptr
+}
@@ -0,0 +1,26 @@
+// Package snapshotmeta defines the stable, versioned schema of the metadata
+// envelope that nomos writes with --include-metadata, and Go types for
+// reading it.
+//
+// The schema is published as JSON Schema (draft 2020-12) in
+// metadata.schema.json and is available at runtime as Schema. The Go types
+// in this package mirror it field for field; the schema is the source of
+// truth and tests keep the two in lockstep.
+//
+// # Versioning
+//
+// Every metadata envelope carries a schema_version. Fields may be added
+// without changing the version, so readers should ignore unknown fields.
+// Removing or renaming a field, or changing its type, increments
+// SchemaVersion.
+//
+// # Basic Usage
+//
+//	doc, err := snapshotmeta.Decode(output)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for key, p := range doc.Metadata.PerKeyProvenance {
+//		fmt.Printf("%s: %s\n", key, p.Source)
+//	}
+package snapshotmeta
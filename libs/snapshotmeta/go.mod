@@ -0,0 +1,3 @@
+module github.com/autonomous-bits/nomos/libs/snapshotmeta
+
+go 1.26.0
@@ -0,0 +1,119 @@
+package snapshotmeta
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the metadata schema version written by this release.
+const SchemaVersion = 1
+
+// Schema is the JSON Schema describing a snapshot written with metadata.
+//
+//go:embed metadata.schema.json
+var Schema []byte
+
+// ErrUnsupportedVersion indicates a metadata envelope written with a newer
+// schema version than this package understands.
+var ErrUnsupportedVersion = errors.New("unsupported metadata schema version")
+
+// Document is a snapshot written with metadata: the compiled data and its
+// metadata envelope.
+type Document struct {
+	// Data is the compiled configuration data.
+	Data map[string]any `json:"data"`
+
+	// Metadata describes the compilation that produced Data.
+	Metadata Metadata `json:"metadata"`
+}
+
+// Metadata is the metadata envelope of a compiled snapshot.
+type Metadata struct {
+	// SchemaVersion is the version of the schema the envelope conforms to.
+	SchemaVersion int `json:"schema_version"`
+
+	// StartTime records when compilation began.
+	StartTime time.Time `json:"start_time"`
+
+	// EndTime records when compilation completed.
+	EndTime time.Time `json:"end_time"`
+
+	// InputFiles lists all .csl source files processed during compilation.
+	InputFiles []string `json:"input_files"`
+
+	// ProviderAliases lists the aliases of all providers used.
+	ProviderAliases []string `json:"provider_aliases"`
+
+	// PerKeyProvenance maps each top-level configuration key to its origin.
+	PerKeyProvenance map[string]Provenance `json:"per_key_provenance"`
+
+	// Errors contains fatal parse or compilation errors.
+	Errors []string `json:"errors"`
+
+	// Warnings contains non-fatal issues encountered during compilation.
+	Warnings []string `json:"warnings"`
+
+	// TypeCoercion records the coercion policy applied to the data.
+	TypeCoercion string `json:"type_coercion"`
+
+	// SensitiveKeys lists the key paths of values marked as secrets.
+	SensitiveKeys []string `json:"sensitive_keys"`
+
+	// ProjectRoot is the directory relative paths were resolved against.
+	ProjectRoot string `json:"project_root"`
+
+	// ProviderConfigOverrides records source declaration configuration
+	// replaced at build time, keyed by provider alias. Nil if none were
+	// given.
+	ProviderConfigOverrides map[string]map[string]any `json:"provider_config_overrides"`
+
+	// Profiles lists the profiles merged into the data, in order.
+	Profiles []string `json:"profiles"`
+
+	// ListOrders records the order of the lists in each provider
+	// reference's value, sorted by reference.
+	ListOrders []ListOrder `json:"list_orders"`
+}
+
+// Provenance records the origin of a configuration value.
+type Provenance struct {
+	// Source identifies the .csl file that contributed this value.
+	Source string `json:"source"`
+
+	// ProviderAlias identifies the provider that resolved this value.
+	ProviderAlias string `json:"provider_alias"`
+}
+
+// ListOrder records the order of the lists in the value a provider returned
+// for one reference. Two builds with equal ContentHash and different
+// OrderHash saw the same list elements in different orders.
+type ListOrder struct {
+	// Reference is the reference as written, "@alias:path".
+	Reference string `json:"reference"`
+
+	// Provider is the provider that served the reference.
+	Provider string `json:"provider"`
+
+	// OrderHash digests the value with its lists in provider order.
+	OrderHash string `json:"order_hash"`
+
+	// ContentHash digests the value with the elements of its lists sorted.
+	ContentHash string `json:"content_hash"`
+}
+
+// Decode parses a JSON snapshot written with metadata. Unknown fields are
+// ignored. It returns an error wrapping ErrUnsupportedVersion if the
+// envelope has no schema_version or one newer than SchemaVersion.
+func Decode(data []byte) (Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Document{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if v := doc.Metadata.SchemaVersion; v < 1 || v > SchemaVersion {
+		return Document{}, fmt.Errorf("%w: %d (supported: 1 to %d)", ErrUnsupportedVersion, v, SchemaVersion)
+	}
+	return doc, nil
+}
@@ -0,0 +1,142 @@
+package snapshotmeta_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/autonomous-bits/nomos/libs/snapshotmeta"
+)
+
+// schemaObject is the subset of a JSON Schema object definition the tests
+// compare against the Go types.
+type schemaObject struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func loadSchema(t *testing.T) (root schemaObject, defs map[string]schemaObject) {
+	t.Helper()
+	var schema struct {
+		schemaObject
+		Defs map[string]schemaObject `json:"$defs"`
+	}
+	if err := json.Unmarshal(snapshotmeta.Schema, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	return schema.schemaObject, schema.Defs
+}
+
+// jsonFields returns the JSON field names of struct type t.
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sorted(s []string) []string {
+	s = append([]string(nil), s...)
+	sort.Strings(s)
+	return s
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TestSchemaMatchesTypes verifies every schema object lists exactly the
+// fields of its Go type, all of them required.
+func TestSchemaMatchesTypes(t *testing.T) {
+	root, defs := loadSchema(t)
+
+	tests := []struct {
+		name   string
+		schema schemaObject
+		typ    reflect.Type
+	}{
+		{name: "document", schema: root, typ: reflect.TypeFor[snapshotmeta.Document]()},
+		{name: "metadata", schema: defs["metadata"], typ: reflect.TypeFor[snapshotmeta.Metadata]()},
+		{name: "provenance", schema: defs["provenance"], typ: reflect.TypeFor[snapshotmeta.Provenance]()},
+		{name: "listOrder", schema: defs["listOrder"], typ: reflect.TypeFor[snapshotmeta.ListOrder]()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := jsonFields(tt.typ)
+			if got := keys(tt.schema.Properties); !reflect.DeepEqual(got, want) {
+				t.Errorf("schema properties = %v, want %v", got, want)
+			}
+			if got := sorted(tt.schema.Required); !reflect.DeepEqual(got, want) {
+				t.Errorf("schema required = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestSchemaVersionConst verifies the schema pins schema_version to
+// SchemaVersion.
+func TestSchemaVersionConst(t *testing.T) {
+	_, defs := loadSchema(t)
+	var prop struct {
+		Const int `json:"const"`
+	}
+	if err := json.Unmarshal(defs["metadata"].Properties["schema_version"], &prop); err != nil {
+		t.Fatal(err)
+	}
+	if prop.Const != snapshotmeta.SchemaVersion {
+		t.Errorf("schema_version const = %d, want %d", prop.Const, snapshotmeta.SchemaVersion)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{
+			name:  "current version",
+			input: `{"data":{"app":"demo"},"metadata":{"schema_version":1,"start_time":"2024-01-01T00:00:00Z","per_key_provenance":{"app":{"source":"app.csl","provider_alias":""}},"future_field":true}}`,
+		},
+		{
+			name:    "missing version",
+			input:   `{"data":{},"metadata":{}}`,
+			wantErr: snapshotmeta.ErrUnsupportedVersion,
+		},
+		{
+			name:    "newer version",
+			input:   `{"data":{},"metadata":{"schema_version":2}}`,
+			wantErr: snapshotmeta.ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := snapshotmeta.Decode([]byte(tt.input))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if doc.Metadata.PerKeyProvenance["app"].Source != "app.csl" {
+				t.Errorf("PerKeyProvenance = %v", doc.Metadata.PerKeyProvenance)
+			}
+		})
+	}
+}